@@ -0,0 +1,202 @@
+// Package analytics 基于交易事件日志计算交易表现统计（胜率、R 倍数、盈亏比、期望值等）
+package analytics
+
+import (
+	"math"
+	"nofx/store"
+	"sort"
+	"time"
+)
+
+// Stats 一组交易的统计指标
+type Stats struct {
+	Trades          int           `json:"trades"`
+	Wins            int           `json:"wins"`
+	Losses          int           `json:"losses"`
+	WinRate         float64       `json:"win_rate"`          // 盈利交易占比（百分比）
+	NetPnL          float64       `json:"net_pnl"`           // 扣除手续费后的总盈亏
+	GrossPnL        float64       `json:"gross_pnl"`         // 扣除手续费前的总盈亏
+	TotalFees       float64       `json:"total_fees"`        // 手续费合计
+	FeeDragPct      float64       `json:"fee_drag_pct"`      // 手续费占毛盈利的比例（百分比）
+	ProfitFactor    float64       `json:"profit_factor"`     // 总盈利 / 总亏损（没有亏损时为 0）
+	Expectancy      float64       `json:"expectancy"`        // 每笔交易的平均净盈亏
+	AvgWin          float64       `json:"avg_win"`           // 盈利交易的平均净盈亏
+	AvgLoss         float64       `json:"avg_loss"`          // 亏损交易的平均净盈亏（负数）
+	AvgR            float64       `json:"avg_r"`             // 平均 R 倍数（只统计设置了止损的交易）
+	RTrades         int           `json:"r_trades"`          // 参与 R 倍数统计的交易数
+	AvgHoldingTime  time.Duration `json:"avg_holding_time"`  // 平均持仓时间
+	AvgHoldingHours float64       `json:"avg_holding_hours"` // 平均持仓小时数（便于前端展示）
+	LargestWin      float64       `json:"largest_win"`
+	LargestLoss     float64       `json:"largest_loss"`
+}
+
+// MonthlyPnL 按平仓月份（UTC）汇总的盈亏
+type MonthlyPnL struct {
+	Month    string  `json:"month"` // YYYY-MM
+	Trades   int     `json:"trades"`
+	GrossPnL float64 `json:"gross_pnl"`
+	NetPnL   float64 `json:"net_pnl"`
+	Fees     float64 `json:"fees"`
+	WinRate  float64 `json:"win_rate"`
+}
+
+// Report 交易表现报告
+type Report struct {
+	Overall    Stats            `json:"overall"`
+	BySymbol   map[string]Stats `json:"by_symbol"`
+	ByTag      map[string]Stats `json:"by_tag"` // 按策略标签统计（没有标签的交易计入 untagged）
+	Monthly    []MonthlyPnL     `json:"monthly"`
+	Incomplete int              `json:"incomplete"` // 平仓价格未知、未计入统计的交易数
+	Trades     []Trade          `json:"trades"`
+}
+
+// Filter 统计范围（零值字段不过滤）
+type Filter struct {
+	Symbol string
+	Tag    string    // 只统计该策略标签的交易（untagged 表示没有标签）
+	Since  time.Time // 只统计在该时间之后平仓的交易
+	Until  time.Time // 只统计在该时间之前平仓的交易
+}
+
+// FromJournal 从事件日志计算交易员的表现报告
+func FromJournal(journal *store.Journal, traderID string, filter Filter) (*Report, error) {
+	events, err := journal.Events(store.EventFilter{
+		TraderID: traderID,
+		Symbol:   filter.Symbol,
+		Types:    []string{store.EventFill, store.EventProtection},
+	})
+	if err != nil {
+		return nil, err
+	}
+	trades, incomplete, err := BuildTrades(events)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := trades[:0]
+	for _, t := range trades {
+		if (!filter.Since.IsZero() && t.ClosedAt.Before(filter.Since)) || (!filter.Until.IsZero() && !t.ClosedAt.Before(filter.Until)) {
+			continue
+		}
+		if filter.Tag != "" && tradeTag(t) != filter.Tag {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	report := Analyze(filtered)
+	report.Incomplete = incomplete
+	return report, nil
+}
+
+// Analyze 计算一组已完成交易的整体、分币种和月度统计
+func Analyze(trades []Trade) *Report {
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ClosedAt.Before(sorted[j].ClosedAt) })
+
+	bySymbol := make(map[string][]Trade)
+	byTag := make(map[string][]Trade)
+	byMonth := make(map[string][]Trade)
+	for _, t := range sorted {
+		bySymbol[t.Symbol] = append(bySymbol[t.Symbol], t)
+		byTag[tradeTag(t)] = append(byTag[tradeTag(t)], t)
+		month := t.ClosedAt.UTC().Format("2006-01")
+		byMonth[month] = append(byMonth[month], t)
+	}
+
+	report := &Report{
+		Overall:  Compute(sorted),
+		BySymbol: make(map[string]Stats, len(bySymbol)),
+		ByTag:    make(map[string]Stats, len(byTag)),
+		Monthly:  make([]MonthlyPnL, 0, len(byMonth)),
+		Trades:   sorted,
+	}
+	for symbol, group := range bySymbol {
+		report.BySymbol[symbol] = Compute(group)
+	}
+	for tag, group := range byTag {
+		report.ByTag[tag] = Compute(group)
+	}
+	for month, group := range byMonth {
+		s := Compute(group)
+		report.Monthly = append(report.Monthly, MonthlyPnL{
+			Month:    month,
+			Trades:   s.Trades,
+			NetPnL:   s.NetPnL,
+			GrossPnL: s.GrossPnL,
+			Fees:     s.TotalFees,
+			WinRate:  s.WinRate,
+		})
+	}
+	sort.Slice(report.Monthly, func(i, j int) bool { return report.Monthly[i].Month < report.Monthly[j].Month })
+	return report
+}
+
+// UntaggedTag 没有策略标签的交易在按标签统计中的分组名
+const UntaggedTag = "untagged"
+
+// tradeTag 交易在按标签统计中的分组名
+func tradeTag(t Trade) string {
+	if t.Tag == "" {
+		return UntaggedTag
+	}
+	return t.Tag
+}
+
+// Compute 计算一组交易的统计指标
+func Compute(trades []Trade) Stats {
+	s := Stats{Trades: len(trades)}
+	if len(trades) == 0 {
+		return s
+	}
+
+	var grossProfit, grossLoss, winPnL, lossPnL, totalR, positiveGross float64
+	var holding time.Duration
+	for _, t := range trades {
+		s.NetPnL += t.NetPnL
+		s.GrossPnL += t.GrossPnL
+		s.TotalFees += t.Fees
+		holding += t.HoldingTime
+		if t.GrossPnL > 0 {
+			positiveGross += t.GrossPnL
+		}
+
+		switch {
+		case t.NetPnL > 0:
+			s.Wins++
+			grossProfit += t.NetPnL
+			winPnL += t.NetPnL
+			s.LargestWin = math.Max(s.LargestWin, t.NetPnL)
+		case t.NetPnL < 0:
+			s.Losses++
+			grossLoss += -t.NetPnL
+			lossPnL += t.NetPnL
+			s.LargestLoss = math.Min(s.LargestLoss, t.NetPnL)
+		}
+		if t.InitialRisk > 0 {
+			s.RTrades++
+			totalR += t.RMultiple
+		}
+	}
+
+	n := float64(len(trades))
+	s.WinRate = float64(s.Wins) / n * 100
+	s.Expectancy = s.NetPnL / n
+	s.AvgHoldingTime = holding / time.Duration(len(trades))
+	s.AvgHoldingHours = s.AvgHoldingTime.Hours()
+	if s.Wins > 0 {
+		s.AvgWin = winPnL / float64(s.Wins)
+	}
+	if s.Losses > 0 {
+		s.AvgLoss = lossPnL / float64(s.Losses)
+	}
+	if grossLoss > 0 {
+		s.ProfitFactor = grossProfit / grossLoss
+	}
+	if s.RTrades > 0 {
+		s.AvgR = totalR / float64(s.RTrades)
+	}
+	if positiveGross > 0 {
+		s.FeeDragPct = s.TotalFees / positiveGross * 100
+	}
+	return s
+}
@@ -0,0 +1,235 @@
+package analytics
+
+import (
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestBuildTrades(t *testing.T) {
+	base := time.Date(2026, 1, 31, 22, 0, 0, 0, time.UTC)
+	ev := func(id int64, typ, symbol, side string, hours int, payload interface{}) store.Event {
+		e := store.Event{ID: id, Type: typ, Symbol: symbol, Side: side, Time: base.Add(time.Duration(hours) * time.Hour)}
+		if err := marshalInto(&e, payload); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	events := []store.Event{
+		// BTC 多单：100 开 1，止损 95（风险 5），102 加仓 1，110 平 1，120 全平
+		ev(1, store.EventFill, "BTCUSDT", "long", 0, store.Fill{Action: "open", Quantity: 1, Price: 100, Fee: 0.5}),
+		ev(2, store.EventProtection, "BTCUSDT", "long", 0, store.Protection{Kind: "stop_loss", Price: 95}),
+		ev(3, store.EventFill, "BTCUSDT", "long", 1, store.Fill{Action: "open", Quantity: 1, Price: 102, Fee: 0.5}),
+		ev(4, store.EventProtection, "BTCUSDT", "long", 1, store.Protection{Kind: "stop_loss", Price: 90}),
+		ev(5, store.EventFill, "BTCUSDT", "long", 2, store.Fill{Action: "close", Quantity: 1, Price: 110, Fee: 0.5}),
+		ev(6, store.EventFill, "BTCUSDT", "long", 4, store.Fill{Action: "close", Full: true, Price: 120, Fee: 0.5}),
+		// ETH 空单亏损
+		ev(7, store.EventFill, "ETHUSDT", "short", 3, store.Fill{Action: "open", Quantity: 2, Price: 50}),
+		ev(8, store.EventFill, "ETHUSDT", "short", 5, store.Fill{Action: "close", Quantity: 2, Price: 55, Fee: 1}),
+		// 平仓价格未知
+		ev(9, store.EventFill, "SOLUSDT", "long", 3, store.Fill{Action: "open", Quantity: 1, Price: 10}),
+		ev(10, store.EventFill, "SOLUSDT", "long", 6, store.Fill{Action: "close", Full: true}),
+		// 未平仓的持仓不计入
+		ev(11, store.EventFill, "XRPUSDT", "long", 6, store.Fill{Action: "open", Quantity: 1, Price: 1}),
+	}
+
+	trades, incomplete, err := BuildTrades(events)
+	if err != nil {
+		t.Fatalf("BuildTrades: %v", err)
+	}
+	if incomplete != 1 {
+		t.Errorf("incomplete = %d, want 1", incomplete)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("expected 2 trades, got %+v", trades)
+	}
+
+	btc := trades[0]
+	// 均价 101：(110-101) + (120-101) = 28，手续费 2
+	if btc.Symbol != "BTCUSDT" || btc.Quantity != 2 || !approx(btc.EntryPrice, 101) || !approx(btc.ExitPrice, 115) {
+		t.Errorf("unexpected BTC trade: %+v", btc)
+	}
+	if !approx(btc.GrossPnL, 28) || !approx(btc.Fees, 2) || !approx(btc.NetPnL, 26) {
+		t.Errorf("BTC pnl = gross %v fees %v net %v", btc.GrossPnL, btc.Fees, btc.NetPnL)
+	}
+	if !approx(btc.InitialRisk, 5) || !approx(btc.RMultiple, 5.2) || btc.HoldingTime != 4*time.Hour {
+		t.Errorf("BTC risk %v R %v holding %v", btc.InitialRisk, btc.RMultiple, btc.HoldingTime)
+	}
+
+	eth := trades[1]
+	if !approx(eth.NetPnL, -11) || eth.InitialRisk != 0 || eth.RMultiple != 0 {
+		t.Errorf("unexpected ETH trade: %+v", eth)
+	}
+}
+
+func TestCompute(t *testing.T) {
+	trades := []Trade{
+		{NetPnL: 30, GrossPnL: 32, Fees: 2, InitialRisk: 10, RMultiple: 3, HoldingTime: 2 * time.Hour},
+		{NetPnL: 10, GrossPnL: 12, Fees: 2, HoldingTime: 4 * time.Hour},
+		{NetPnL: -20, GrossPnL: -18, Fees: 2, InitialRisk: 20, RMultiple: -1, HoldingTime: 6 * time.Hour},
+	}
+	s := Compute(trades)
+
+	if s.Trades != 3 || s.Wins != 2 || s.Losses != 1 || !approx(s.WinRate, 200.0/3) {
+		t.Errorf("counts: %+v", s)
+	}
+	if !approx(s.ProfitFactor, 2) || !approx(s.Expectancy, 20.0/3) || !approx(s.AvgR, 1) || s.RTrades != 2 {
+		t.Errorf("profit factor %v expectancy %v avgR %v", s.ProfitFactor, s.Expectancy, s.AvgR)
+	}
+	if !approx(s.TotalFees, 6) || !approx(s.FeeDragPct, 6.0/44*100) {
+		t.Errorf("fees %v drag %v", s.TotalFees, s.FeeDragPct)
+	}
+	if s.AvgHoldingTime != 4*time.Hour || s.AvgHoldingHours != 4 || s.LargestWin != 30 || s.LargestLoss != -20 {
+		t.Errorf("holding/extremes: %+v", s)
+	}
+
+	if empty := Compute(nil); empty.Trades != 0 || empty.WinRate != 0 {
+		t.Errorf("empty stats: %+v", empty)
+	}
+}
+
+func TestFromJournal(t *testing.T) {
+	j, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+
+	record := func(symbol, side string, fill store.Fill) {
+		if _, err := j.Record("t1", store.EventFill, symbol, side, fill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	record("BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100})
+	record("BTCUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 110})
+	record("ETHUSDT", "short", store.Fill{Action: "open", Quantity: 1, Price: 100})
+	record("ETHUSDT", "short", store.Fill{Action: "close", Quantity: 1, Price: 105})
+
+	report, err := FromJournal(j, "t1", Filter{})
+	if err != nil {
+		t.Fatalf("FromJournal: %v", err)
+	}
+	if report.Overall.Trades != 2 || !approx(report.Overall.NetPnL, 5) {
+		t.Errorf("overall: %+v", report.Overall)
+	}
+	if report.BySymbol["BTCUSDT"].Wins != 1 || report.BySymbol["ETHUSDT"].Losses != 1 {
+		t.Errorf("by symbol: %+v", report.BySymbol)
+	}
+	if len(report.Monthly) != 1 || report.Monthly[0].Trades != 2 {
+		t.Errorf("monthly: %+v", report.Monthly)
+	}
+
+	btcOnly, _ := FromJournal(j, "t1", Filter{Symbol: "btcusdt"})
+	if btcOnly.Overall.Trades != 1 {
+		t.Errorf("symbol filter: %+v", btcOnly.Overall)
+	}
+	future, _ := FromJournal(j, "t1", Filter{Since: time.Now().Add(time.Hour)})
+	if future.Overall.Trades != 0 {
+		t.Errorf("since filter: %+v", future.Overall)
+	}
+}
+
+func TestFromJournalByTag(t *testing.T) {
+	j, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+
+	record := func(symbol, side string, fill store.Fill) {
+		if _, err := j.Record("t1", store.EventFill, symbol, side, fill); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 平仓成交的标签不影响归因，以开仓成交为准
+	record("BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100, Tag: "ema_cross"})
+	record("BTCUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 110, Tag: "tradingview"})
+	record("ETHUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100, Tag: "ema_cross"})
+	record("ETHUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 95})
+	record("SOLUSDT", "short", store.Fill{Action: "open", Quantity: 1, Price: 100})
+	record("SOLUSDT", "short", store.Fill{Action: "close", Quantity: 1, Price: 90})
+
+	report, err := FromJournal(j, "t1", Filter{})
+	if err != nil {
+		t.Fatalf("FromJournal: %v", err)
+	}
+	if len(report.ByTag) != 2 {
+		t.Fatalf("by tag: %+v", report.ByTag)
+	}
+	if s := report.ByTag["ema_cross"]; s.Trades != 2 || !approx(s.NetPnL, 5) {
+		t.Errorf("ema_cross: %+v", s)
+	}
+	if s := report.ByTag[UntaggedTag]; s.Trades != 1 || !approx(s.NetPnL, 10) {
+		t.Errorf("untagged: %+v", s)
+	}
+
+	tagged, _ := FromJournal(j, "t1", Filter{Tag: "ema_cross"})
+	if tagged.Overall.Trades != 2 {
+		t.Errorf("tag filter: %+v", tagged.Overall)
+	}
+	untagged, _ := FromJournal(j, "t1", Filter{Tag: UntaggedTag})
+	if untagged.Overall.Trades != 1 || untagged.Trades[0].Symbol != "SOLUSDT" {
+		t.Errorf("untagged filter: %+v", untagged.Trades)
+	}
+}
+
+func TestSummaryFromJournal(t *testing.T) {
+	j, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+
+	j.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100})
+	j.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 110, Fee: 1})
+	j.Record("t1", store.EventRisk, "", "", store.RiskEvent{Severity: "WARNING", Title: "触发风险暂停", Message: "触发当日最大亏损"})
+	j.Record("t2", store.EventRisk, "", "", store.RiskEvent{Severity: "CRITICAL", Title: "紧急停止"})
+
+	now := time.Now()
+	summary, err := SummaryFromJournal(j, "t1", now.Add(-time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("SummaryFromJournal: %v", err)
+	}
+	if summary.Stats.Trades != 1 || !approx(summary.Stats.NetPnL, 9) || !approx(summary.Stats.TotalFees, 1) || len(summary.Trades) != 1 {
+		t.Errorf("unexpected stats: %+v", summary.Stats)
+	}
+	if len(summary.RiskEvents) != 1 || summary.RiskEvents[0].Title != "触发风险暂停" {
+		t.Errorf("unexpected risk events: %+v", summary.RiskEvents)
+	}
+
+	past, _ := SummaryFromJournal(j, "t1", now.Add(-48*time.Hour), now.Add(-24*time.Hour))
+	if past.Stats.Trades != 0 || len(past.RiskEvents) != 0 {
+		t.Errorf("window filter: %+v", past)
+	}
+}
+
+func TestAnalyzeMonthly(t *testing.T) {
+	jan := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2026, 2, 3, 0, 0, 0, 0, time.UTC)
+	report := Analyze([]Trade{
+		{Symbol: "BTCUSDT", ClosedAt: feb, NetPnL: -5},
+		{Symbol: "BTCUSDT", ClosedAt: jan, NetPnL: 10},
+		{Symbol: "ETHUSDT", ClosedAt: jan, NetPnL: 4},
+	})
+	if len(report.Monthly) != 2 || report.Monthly[0].Month != "2026-01" || report.Monthly[1].Month != "2026-02" {
+		t.Fatalf("monthly: %+v", report.Monthly)
+	}
+	if !approx(report.Monthly[0].NetPnL, 14) || report.Monthly[0].WinRate != 100 || !approx(report.Monthly[1].NetPnL, -5) {
+		t.Errorf("monthly values: %+v", report.Monthly)
+	}
+	if !report.Trades[0].ClosedAt.Equal(jan) {
+		t.Error("trades should be sorted by close time")
+	}
+}
+
+func marshalInto(e *store.Event, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	e.Payload = data
+	return err
+}
@@ -0,0 +1,213 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"nofx/market"
+	"nofx/store"
+	"sort"
+	"time"
+)
+
+// DefaultBenchmarkSymbols 默认的买入持有对比币种
+var DefaultBenchmarkSymbols = []string{"BTCUSDT", "ETHUSDT"}
+
+// benchmarkIntervals 从K线缓存读取对比价格时依次尝试的周期（取第一个有数据的）
+var benchmarkIntervals = []string{"1h", "4h", "15m", "1d", "5m", "3m", "1m"}
+
+// 策略收益的数据来源
+const (
+	ReturnSourceEquity = "equity_snapshots" // 定时净值快照（含未实现盈亏和资金费）
+	ReturnSourceTrades = "trades"           // 已平仓交易的净盈亏（相对初始余额）
+)
+
+// StrategyReturn 策略在对比区间内的收益
+type StrategyReturn struct {
+	Source         string  `json:"source"` // equity_snapshots / trades
+	StartEquity    float64 `json:"start_equity"`
+	EndEquity      float64 `json:"end_equity"`
+	PnL            float64 `json:"pnl"`
+	ReturnPct      float64 `json:"return_pct"`
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"`
+}
+
+// HoldReturn 同期买入持有一个币种的收益（投入与策略期初净值相同的资金、不加杠杆）
+type HoldReturn struct {
+	Symbol          string  `json:"symbol"`
+	Interval        string  `json:"interval"` // 计算使用的K线周期
+	StartPrice      float64 `json:"start_price"`
+	EndPrice        float64 `json:"end_price"`
+	PnL             float64 `json:"pnl"`
+	ReturnPct       float64 `json:"return_pct"`
+	MaxDrawdownPct  float64 `json:"max_drawdown_pct"`
+	ExcessReturnPct float64 `json:"excess_return_pct"` // 策略收益率 - 持有收益率（正数表示策略跑赢）
+}
+
+// Benchmark 策略与买入持有的对比
+type Benchmark struct {
+	Since    time.Time      `json:"since"`
+	Until    time.Time      `json:"until"`
+	Strategy StrategyReturn `json:"strategy"`
+	Holds    []HoldReturn   `json:"holds"`
+	Missing  []string       `json:"missing,omitempty"` // K线缓存中没有该区间价格的币种
+}
+
+// PriceLoader 读取币种在 [from, to] 区间的K线，返回使用的周期（没有数据时返回空切片）
+type PriceLoader func(symbol string, from, to time.Time) ([]market.Kline, string, error)
+
+// CachedPrices 从本地K线缓存（NOFX_KLINE_CACHE_DIR）读取对比价格
+func CachedPrices(source string) PriceLoader {
+	return func(symbol string, from, to time.Time) ([]market.Kline, string, error) {
+		for _, interval := range benchmarkIntervals {
+			klines, err := market.LoadCachedKlines(source, symbol, interval, from, to)
+			if err != nil {
+				return nil, "", err
+			}
+			if len(klines) > 0 {
+				return klines, interval, nil
+			}
+		}
+		return nil, "", nil
+	}
+}
+
+// BenchmarkFromJournal 对比交易员在 [since, until) 区间的收益与同期买入持有 symbols 的收益。
+// 有至少两个净值快照时以快照计算策略收益，否则以已平仓交易的净盈亏相对 initialBalance 计算；
+// since/until 为零值时使用数据的起止时间
+func BenchmarkFromJournal(journal *store.Journal, traderID string, initialBalance float64, symbols []string, since, until time.Time, prices PriceLoader) (*Benchmark, error) {
+	snapshots, err := journal.EquitySnapshots(store.EquityFilter{TraderID: traderID, Since: since, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	var strategy StrategyReturn
+	if len(snapshots) >= 2 {
+		strategy = StrategyFromSnapshots(snapshots)
+		if since.IsZero() {
+			since = snapshots[0].Time
+		}
+		if until.IsZero() {
+			until = snapshots[len(snapshots)-1].Time
+		}
+	} else {
+		if initialBalance <= 0 {
+			return nil, fmt.Errorf("没有净值快照时需要初始余额来计算策略收益率")
+		}
+		report, err := FromJournal(journal, traderID, Filter{Since: since, Until: until})
+		if err != nil {
+			return nil, err
+		}
+		if len(report.Trades) == 0 {
+			return nil, fmt.Errorf("区间内没有净值快照或已平仓交易")
+		}
+		strategy = StrategyFromTrades(report.Trades, initialBalance)
+		if since.IsZero() {
+			since = report.Trades[0].OpenedAt
+			for _, t := range report.Trades {
+				if t.OpenedAt.Before(since) {
+					since = t.OpenedAt
+				}
+			}
+		}
+		if until.IsZero() {
+			until = report.Trades[len(report.Trades)-1].ClosedAt
+		}
+	}
+	return CompareBuyAndHold(strategy, symbols, since, until, prices)
+}
+
+// StrategyFromSnapshots 用净值快照计算收益和最大回撤（snapshots 按时间正序）
+func StrategyFromSnapshots(snapshots []store.EquitySnapshot) StrategyReturn {
+	curve := make([]float64, len(snapshots))
+	for i, s := range snapshots {
+		curve[i] = s.TotalEquity()
+	}
+	r := StrategyReturn{Source: ReturnSourceEquity}
+	if len(curve) > 0 {
+		r.StartEquity, r.EndEquity = curve[0], curve[len(curve)-1]
+	}
+	r.PnL = r.EndEquity - r.StartEquity
+	if r.StartEquity > 0 {
+		r.ReturnPct = r.PnL / r.StartEquity * 100
+	}
+	r.MaxDrawdownPct = maxDrawdownPct(curve)
+	return r
+}
+
+// StrategyFromTrades 用已平仓交易的净盈亏计算收益（按平仓时间累加得到净值曲线）
+func StrategyFromTrades(trades []Trade, startEquity float64) StrategyReturn {
+	sorted := append([]Trade(nil), trades...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ClosedAt.Before(sorted[j].ClosedAt) })
+	curve := []float64{startEquity}
+	equity := startEquity
+	for _, t := range sorted {
+		equity += t.NetPnL
+		curve = append(curve, equity)
+	}
+	r := StrategyReturn{Source: ReturnSourceTrades, StartEquity: startEquity, EndEquity: equity, PnL: equity - startEquity}
+	if startEquity > 0 {
+		r.ReturnPct = r.PnL / startEquity * 100
+	}
+	r.MaxDrawdownPct = maxDrawdownPct(curve)
+	return r
+}
+
+// CompareBuyAndHold 计算同期买入持有各币种的收益（期初为区间内第一根K线开盘价，期末为最后一根K线收盘价）
+func CompareBuyAndHold(strategy StrategyReturn, symbols []string, since, until time.Time, prices PriceLoader) (*Benchmark, error) {
+	if since.IsZero() || until.IsZero() || !until.After(since) {
+		return nil, fmt.Errorf("无效的对比区间: %v - %v", since, until)
+	}
+	if len(symbols) == 0 {
+		symbols = DefaultBenchmarkSymbols
+	}
+	b := &Benchmark{Since: since, Until: until, Strategy: strategy, Holds: make([]HoldReturn, 0, len(symbols))}
+	for _, symbol := range symbols {
+		klines, interval, err := prices(symbol, since, until)
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s K线失败: %w", symbol, err)
+		}
+		hold, ok := holdReturn(symbol, klines, until, strategy.StartEquity)
+		if !ok {
+			b.Missing = append(b.Missing, symbol)
+			continue
+		}
+		hold.Interval = interval
+		hold.ExcessReturnPct = strategy.ReturnPct - hold.ReturnPct
+		b.Holds = append(b.Holds, hold)
+	}
+	return b, nil
+}
+
+// holdReturn 按K线计算买入持有收益（只使用在 until 之前收盘的K线）
+func holdReturn(symbol string, klines []market.Kline, until time.Time, capital float64) (HoldReturn, bool) {
+	var closes []float64
+	start := 0.0
+	for _, k := range klines {
+		if k.CloseTime > until.UnixMilli() {
+			break
+		}
+		if start <= 0 {
+			start = k.Open
+		}
+		closes = append(closes, k.Close)
+	}
+	if start <= 0 || len(closes) == 0 {
+		return HoldReturn{}, false
+	}
+	h := HoldReturn{Symbol: symbol, StartPrice: start, EndPrice: closes[len(closes)-1]}
+	h.ReturnPct = (h.EndPrice/h.StartPrice - 1) * 100
+	h.PnL = capital * h.ReturnPct / 100
+	h.MaxDrawdownPct = maxDrawdownPct(append([]float64{start}, closes...))
+	return h, true
+}
+
+// maxDrawdownPct 曲线相对此前最高点的最大回撤（百分比）
+func maxDrawdownPct(curve []float64) float64 {
+	peak, worst := 0.0, 0.0
+	for _, v := range curve {
+		peak = math.Max(peak, v)
+		if peak > 0 {
+			worst = math.Max(worst, (peak-v)/peak*100)
+		}
+	}
+	return worst
+}
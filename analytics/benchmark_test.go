@@ -0,0 +1,101 @@
+package analytics
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/market"
+	"nofx/store"
+)
+
+// hourlyKlines 从 start 开始的 1h K线（开盘价为上一根收盘价）
+func hourlyKlines(start time.Time, first float64, closes ...float64) []market.Kline {
+	klines := make([]market.Kline, len(closes))
+	open := first
+	for i, c := range closes {
+		t := start.Add(time.Duration(i) * time.Hour)
+		klines[i] = market.Kline{OpenTime: t.UnixMilli(), CloseTime: t.Add(time.Hour).UnixMilli() - 1, Open: open, Close: c}
+		open = c
+	}
+	return klines
+}
+
+func stubPrices(data map[string][]market.Kline) PriceLoader {
+	return func(symbol string, from, to time.Time) ([]market.Kline, string, error) {
+		return data[symbol], "1h", nil
+	}
+}
+
+func TestCompareBuyAndHold(t *testing.T) {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	prices := stubPrices(map[string][]market.Kline{
+		"BTCUSDT": hourlyKlines(start, 100, 110, 90, 120, 130), // 最后一根在区间外
+		"ETHUSDT": hourlyKlines(start, 50, 45, 40, 48),
+	})
+	strategy := StrategyReturn{StartEquity: 1000, EndEquity: 1100, PnL: 100, ReturnPct: 10}
+
+	b, err := CompareBuyAndHold(strategy, []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}, start, start.Add(3*time.Hour), prices)
+	if err != nil {
+		t.Fatalf("CompareBuyAndHold: %v", err)
+	}
+	if len(b.Holds) != 2 || len(b.Missing) != 1 || b.Missing[0] != "SOLUSDT" {
+		t.Fatalf("unexpected benchmark: %+v", b)
+	}
+	btc, eth := b.Holds[0], b.Holds[1]
+	if btc.StartPrice != 100 || btc.EndPrice != 120 || !approx(btc.ReturnPct, 20) || !approx(btc.PnL, 200) || btc.Interval != "1h" {
+		t.Errorf("unexpected BTC hold: %+v", btc)
+	}
+	if !approx(btc.MaxDrawdownPct, (110.0-90)/110*100) || !approx(btc.ExcessReturnPct, -10) {
+		t.Errorf("unexpected BTC drawdown/excess: %+v", btc)
+	}
+	if !approx(eth.ReturnPct, -4) || !approx(eth.MaxDrawdownPct, 20) || !approx(eth.ExcessReturnPct, 14) {
+		t.Errorf("unexpected ETH hold: %+v", eth)
+	}
+
+	if _, err := CompareBuyAndHold(strategy, nil, start, start, prices); err == nil {
+		t.Error("expected error for an empty range")
+	}
+}
+
+func TestBenchmarkFromJournal(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	prices := stubPrices(map[string][]market.Kline{"BTCUSDT": hourlyKlines(start, 100, 105, 110)})
+
+	// 没有净值快照：按已平仓交易相对初始余额计算
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100})
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 150})
+	if _, err := BenchmarkFromJournal(journal, "t1", 0, []string{"BTCUSDT"}, start, start.Add(2*time.Hour), prices); err == nil {
+		t.Error("expected error without snapshots or initial balance")
+	}
+	b, err := BenchmarkFromJournal(journal, "t1", 1000, []string{"BTCUSDT"}, start, time.Now().Add(time.Hour), prices)
+	if err != nil {
+		t.Fatalf("BenchmarkFromJournal: %v", err)
+	}
+	if b.Strategy.Source != ReturnSourceTrades || !approx(b.Strategy.ReturnPct, 5) || !approx(b.Holds[0].ReturnPct, 10) {
+		t.Errorf("unexpected trade-based benchmark: %+v", b)
+	}
+
+	// 有净值快照时优先使用快照，区间默认取快照起止时间
+	for i, equity := range []float64{1000, 900, 1200} {
+		journal.RecordEquity(store.EquitySnapshot{TraderID: "t1", Time: start.Add(time.Duration(i) * time.Hour), WalletBalance: equity})
+	}
+	b, err = BenchmarkFromJournal(journal, "t1", 0, []string{"BTCUSDT"}, time.Time{}, time.Time{}, prices)
+	if err != nil {
+		t.Fatalf("BenchmarkFromJournal: %v", err)
+	}
+	if b.Strategy.Source != ReturnSourceEquity || !approx(b.Strategy.ReturnPct, 20) || !approx(b.Strategy.MaxDrawdownPct, 10) {
+		t.Errorf("unexpected snapshot-based strategy return: %+v", b.Strategy)
+	}
+	if !b.Since.Equal(start) || !b.Until.Equal(start.Add(2*time.Hour)) {
+		t.Errorf("expected range from snapshots, got %v - %v", b.Since, b.Until)
+	}
+	if len(b.Holds) != 1 || !approx(b.Holds[0].ReturnPct, 10) || !approx(b.Holds[0].ExcessReturnPct, 10) {
+		t.Errorf("unexpected hold return: %+v", b.Holds)
+	}
+}
@@ -0,0 +1,137 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sort"
+)
+
+// 蒙特卡洛模拟默认参数
+const (
+	defaultMonteCarloRuns  = 10000
+	defaultRuinDrawdownPct = 50.0
+	defaultMonteCarloSeed  = 1
+	minMonteCarloTrades    = 10        // 样本太少时重抽样没有意义
+	maxMonteCarloSteps     = 5_000_000 // 模拟次数 × 每次交易笔数的上限
+)
+
+// MonteCarloConfig 蒙特卡洛风险模拟参数
+type MonteCarloConfig struct {
+	RiskPerTradePct float64 // 每笔交易承担的风险（1R）占当前净值的百分比，如 1 = 1%
+	Runs            int     // 模拟次数（默认 10000）
+	Trades          int     // 每次模拟的交易笔数（默认与历史交易笔数相同）
+	RuinDrawdownPct float64 // 回撤达到该百分比视为破产（默认 50）
+	Seed            uint64  // 随机种子（0=固定默认种子，同样的交易得到同样的结果）
+}
+
+// MonteCarloResult 蒙特卡洛风险模拟结果
+type MonteCarloResult struct {
+	Runs            int     `json:"runs"`
+	Trades          int     `json:"trades"` // 每次模拟的交易笔数
+	SampleTrades    int     `json:"sample_trades"`
+	RiskPerTradePct float64 `json:"risk_per_trade_pct"`
+	RuinDrawdownPct float64 `json:"ruin_drawdown_pct"`
+	RiskOfRuinPct   float64 `json:"risk_of_ruin_pct"` // 回撤达到破产线的模拟占比
+	DrawdownP50     float64 `json:"drawdown_p50"`     // 最大回撤的中位数（百分比）
+	DrawdownP95     float64 `json:"drawdown_p95"`
+	DrawdownP99     float64 `json:"drawdown_p99"`
+	ReturnP5        float64 `json:"return_p5"` // 期末收益率的 5% 分位数（百分比）
+	ReturnP50       float64 `json:"return_p50"`
+	ReturnP95       float64 `json:"return_p95"`
+}
+
+// RMultiplesFromPnL 将每笔交易盈亏换算为 R 倍数（1R = 亏损交易的平均亏损额；没有亏损交易时返回错误）
+func RMultiplesFromPnL(pnls []float64) ([]float64, error) {
+	loss, losses := 0.0, 0
+	for _, p := range pnls {
+		if p < 0 {
+			loss -= p
+			losses++
+		}
+	}
+	if losses == 0 {
+		return nil, fmt.Errorf("没有亏损交易，无法估计每笔交易的风险")
+	}
+	unit := loss / float64(losses)
+	out := make([]float64, len(pnls))
+	for i, p := range pnls {
+		out[i] = p / unit
+	}
+	return out, nil
+}
+
+// MonteCarlo 对历史交易的 R 倍数有放回重抽样，按固定风险比例复利模拟净值路径，
+// 估计最大回撤分位数和破产概率（回撤达到 RuinDrawdownPct 的模拟占比）
+func MonteCarlo(rMultiples []float64, cfg MonteCarloConfig) (*MonteCarloResult, error) {
+	if len(rMultiples) < minMonteCarloTrades {
+		return nil, fmt.Errorf("至少需要 %d 笔交易，当前只有 %d 笔", minMonteCarloTrades, len(rMultiples))
+	}
+	if cfg.RiskPerTradePct <= 0 || cfg.RiskPerTradePct >= 100 {
+		return nil, fmt.Errorf("每笔交易风险必须在 0-100%% 之间: %v", cfg.RiskPerTradePct)
+	}
+	if cfg.Runs <= 0 {
+		cfg.Runs = defaultMonteCarloRuns
+	}
+	if cfg.Trades <= 0 {
+		cfg.Trades = len(rMultiples)
+	}
+	if cfg.RuinDrawdownPct <= 0 || cfg.RuinDrawdownPct > 100 {
+		cfg.RuinDrawdownPct = defaultRuinDrawdownPct
+	}
+	if cfg.Seed == 0 {
+		cfg.Seed = defaultMonteCarloSeed
+	}
+	if cfg.Runs*cfg.Trades > maxMonteCarloSteps {
+		return nil, fmt.Errorf("模拟规模过大: %d 次 × %d 笔（上限 %d）", cfg.Runs, cfg.Trades, maxMonteCarloSteps)
+	}
+
+	rng := rand.New(rand.NewPCG(cfg.Seed, cfg.Seed^0x9e3779b97f4a7c15))
+	risk := cfg.RiskPerTradePct / 100
+	drawdowns := make([]float64, cfg.Runs)
+	returns := make([]float64, cfg.Runs)
+	ruined := 0
+	for run := 0; run < cfg.Runs; run++ {
+		equity, peak, worst := 1.0, 1.0, 0.0
+		for i := 0; i < cfg.Trades; i++ {
+			equity *= 1 + risk*rMultiples[rng.IntN(len(rMultiples))]
+			if equity <= 0 {
+				equity, worst = 0, 100
+				break
+			}
+			peak = math.Max(peak, equity)
+			worst = math.Max(worst, (peak-equity)/peak*100)
+		}
+		drawdowns[run] = worst
+		returns[run] = (equity - 1) * 100
+		if worst >= cfg.RuinDrawdownPct {
+			ruined++
+		}
+	}
+
+	sort.Float64s(drawdowns)
+	sort.Float64s(returns)
+	return &MonteCarloResult{
+		Runs:            cfg.Runs,
+		Trades:          cfg.Trades,
+		SampleTrades:    len(rMultiples),
+		RiskPerTradePct: cfg.RiskPerTradePct,
+		RuinDrawdownPct: cfg.RuinDrawdownPct,
+		RiskOfRuinPct:   float64(ruined) / float64(cfg.Runs) * 100,
+		DrawdownP50:     percentile(drawdowns, 0.50),
+		DrawdownP95:     percentile(drawdowns, 0.95),
+		DrawdownP99:     percentile(drawdowns, 0.99),
+		ReturnP5:        percentile(returns, 0.05),
+		ReturnP50:       percentile(returns, 0.50),
+		ReturnP95:       percentile(returns, 0.95),
+	}, nil
+}
+
+// percentile 已排序样本的分位数（最近秩法）
+func percentile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	return sorted[max(0, min(i, len(sorted)-1))]
+}
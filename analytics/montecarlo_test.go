@@ -0,0 +1,71 @@
+package analytics
+
+import (
+	"testing"
+)
+
+func TestRMultiplesFromPnL(t *testing.T) {
+	r, err := RMultiplesFromPnL([]float64{30, -10, -30, 0})
+	if err != nil {
+		t.Fatalf("RMultiplesFromPnL: %v", err)
+	}
+	// 平均亏损 20 = 1R
+	if !approx(r[0], 1.5) || !approx(r[1], -0.5) || !approx(r[2], -1.5) || r[3] != 0 {
+		t.Errorf("unexpected R multiples: %v", r)
+	}
+	if _, err := RMultiplesFromPnL([]float64{10, 20}); err == nil {
+		t.Error("expected error without losing trades")
+	}
+}
+
+func TestMonteCarlo(t *testing.T) {
+	// 胜率 50%、盈亏比 2:1 的正期望分布
+	var sample []float64
+	for i := 0; i < 20; i++ {
+		sample = append(sample, 2, -1)
+	}
+
+	low, err := MonteCarlo(sample, MonteCarloConfig{RiskPerTradePct: 1, Runs: 2000, Trades: 100})
+	if err != nil {
+		t.Fatalf("MonteCarlo: %v", err)
+	}
+	high, err := MonteCarlo(sample, MonteCarloConfig{RiskPerTradePct: 10, Runs: 2000, Trades: 100})
+	if err != nil {
+		t.Fatalf("MonteCarlo: %v", err)
+	}
+	if low.Runs != 2000 || low.Trades != 100 || low.SampleTrades != 40 || low.RuinDrawdownPct != 50 {
+		t.Errorf("unexpected defaults: %+v", low)
+	}
+	if !(low.DrawdownP50 <= low.DrawdownP95 && low.DrawdownP95 <= low.DrawdownP99) || !(low.ReturnP5 <= low.ReturnP50 && low.ReturnP50 <= low.ReturnP95) {
+		t.Errorf("percentiles out of order: %+v", low)
+	}
+	// 1% 风险下 100 笔交易几乎不可能回撤一半；10% 风险时回撤和破产概率明显更高
+	if low.RiskOfRuinPct != 0 || low.ReturnP50 <= 0 {
+		t.Errorf("unexpected low-risk result: %+v", low)
+	}
+	if high.DrawdownP95 <= low.DrawdownP95 || high.RiskOfRuinPct <= low.RiskOfRuinPct {
+		t.Errorf("expected higher risk to increase drawdowns: low %+v high %+v", low, high)
+	}
+
+	// 固定种子：结果可复现
+	again, _ := MonteCarlo(sample, MonteCarloConfig{RiskPerTradePct: 10, Runs: 2000, Trades: 100})
+	if *again != *high {
+		t.Errorf("expected deterministic results with the default seed")
+	}
+
+	// 每笔亏损超过 100% 净值时破产
+	ruin, err := MonteCarlo([]float64{-1, -1, -1, -1, -1, -1, -1, -1, -1, -1}, MonteCarloConfig{RiskPerTradePct: 60, Runs: 10, Trades: 3})
+	if err != nil {
+		t.Fatalf("MonteCarlo: %v", err)
+	}
+	if ruin.RiskOfRuinPct != 100 || ruin.DrawdownP50 < 50 {
+		t.Errorf("expected certain ruin: %+v", ruin)
+	}
+
+	if _, err := MonteCarlo(sample[:5], MonteCarloConfig{RiskPerTradePct: 1}); err == nil {
+		t.Error("expected error for too few trades")
+	}
+	if _, err := MonteCarlo(sample, MonteCarloConfig{}); err == nil {
+		t.Error("expected error without a risk setting")
+	}
+}
@@ -0,0 +1,333 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"nofx/store"
+	"sort"
+	"time"
+)
+
+// 成本计算方法
+const (
+	CostFIFO    = "fifo"    // 先进先出：平仓依次消耗最早的开仓批次
+	CostAverage = "average" // 加权平均：平仓按当前持仓均价计算成本
+)
+
+// 统计周期
+const (
+	PeriodMonth   = "month"   // YYYY-MM
+	PeriodQuarter = "quarter" // YYYY-Qn
+	PeriodYear    = "year"    // YYYY
+)
+
+// 资金流水类型
+const (
+	BillFunding = "funding" // 资金费
+	BillFee     = "fee"     // 交易手续费
+)
+
+// Bill 交易所资金流水（资金费、手续费）
+type Bill struct {
+	Time   time.Time `json:"time"`
+	Symbol string    `json:"symbol"`
+	Kind   string    `json:"kind"`   // funding / fee
+	Amount float64   `json:"amount"` // 正数为收入，负数为支出
+	Asset  string    `json:"asset,omitempty"`
+}
+
+// Disposal 一笔平仓的成本和已实现盈亏
+type Disposal struct {
+	Symbol      string    `json:"symbol"`
+	Side        string    `json:"side"`
+	AcquiredAt  time.Time `json:"acquired_at"` // 消耗的最早开仓批次时间（加权平均法为当前持仓首次开仓时间）
+	DisposedAt  time.Time `json:"disposed_at"`
+	Quantity    float64   `json:"quantity"`
+	Proceeds    float64   `json:"proceeds"`   // 卖出金额（多单为平仓金额，空单为开仓金额）
+	CostBasis   float64   `json:"cost_basis"` // 买入金额（多单为开仓金额，空单为平仓金额）
+	RealizedPnL float64   `json:"realized_pnl"`
+}
+
+// StatementTotals 盈亏、手续费和资金费合计
+type StatementTotals struct {
+	RealizedPnL float64 `json:"realized_pnl"` // 平仓已实现盈亏（不含手续费）
+	Fees        float64 `json:"fees"`         // 支付的手续费（正数）
+	Funding     float64 `json:"funding"`      // 资金费净收入（负数为净支出）
+	Net         float64 `json:"net"`          // RealizedPnL - Fees + Funding
+	Disposals   int     `json:"disposals"`
+}
+
+// PeriodStatement 单个币种在一个统计周期内的合计
+type PeriodStatement struct {
+	Period string `json:"period"`
+	Symbol string `json:"symbol"`
+	StatementTotals
+}
+
+// CoinStatement 单个币种的合计和期末持仓
+type CoinStatement struct {
+	Symbol string `json:"symbol"`
+	StatementTotals
+	OpenLong      float64 `json:"open_long"`       // 期末多单持仓数量
+	OpenShort     float64 `json:"open_short"`      // 期末空单持仓数量
+	OpenCostBasis float64 `json:"open_cost_basis"` // 期末持仓的开仓成本
+}
+
+// Statement 对账单：成本计算明细、按周期和按币种的已实现盈亏
+type Statement struct {
+	Method    string            `json:"method"`
+	Period    string            `json:"period"`
+	Since     time.Time         `json:"since"`
+	Until     time.Time         `json:"until"`
+	Disposals []Disposal        `json:"disposals"`
+	Periods   []PeriodStatement `json:"periods"`
+	Coins     []CoinStatement   `json:"coins"`
+	Total     StatementTotals   `json:"total"`
+	Unpriced  int               `json:"unpriced"`   // 平仓价格未知、未计入盈亏的平仓次数
+	FeeSource string            `json:"fee_source"` // fills（成交记录中的手续费）/ bills（交易所手续费流水）
+}
+
+// StatementOptions 对账单参数（零值：FIFO、按月、全部时间）
+type StatementOptions struct {
+	Method string
+	Period string
+	Symbol string
+	Since  time.Time // 只统计该时间之后的平仓和流水（之前的开仓仍计入成本）
+	Until  time.Time // 只统计该时间之前的平仓和流水
+}
+
+// lot 一个开仓批次
+type lot struct {
+	quantity float64
+	price    float64
+	at       time.Time
+}
+
+// StatementFromJournal 从事件日志的成交和交易所资金流水生成对账单
+func StatementFromJournal(journal *store.Journal, traderID string, bills []Bill, opts StatementOptions) (*Statement, error) {
+	events, err := journal.Events(store.EventFilter{
+		TraderID: traderID,
+		Symbol:   opts.Symbol,
+		Types:    []string{store.EventFill},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return BuildStatement(events, bills, opts)
+}
+
+// BuildStatement 按 FIFO 或加权平均计算成本，汇总每笔平仓、每个周期和每个币种的已实现盈亏。
+// bills 中有手续费流水时以流水为准，不再重复计入成交记录中的手续费
+func BuildStatement(events []store.Event, bills []Bill, opts StatementOptions) (*Statement, error) {
+	if opts.Method == "" {
+		opts.Method = CostFIFO
+	}
+	if opts.Period == "" {
+		opts.Period = PeriodMonth
+	}
+	if opts.Method != CostFIFO && opts.Method != CostAverage {
+		return nil, fmt.Errorf("不支持的成本计算方法: %s（可选: fifo, average）", opts.Method)
+	}
+	if _, err := periodLabel(time.Time{}, opts.Period); err != nil {
+		return nil, err
+	}
+
+	st := &Statement{Method: opts.Method, Period: opts.Period, Since: opts.Since, Until: opts.Until, FeeSource: "fills"}
+	feesFromBills := false
+	for _, b := range bills {
+		if b.Kind == BillFee {
+			feesFromBills = true
+			st.FeeSource = "bills"
+			break
+		}
+	}
+
+	inRange := func(t time.Time) bool {
+		return (opts.Since.IsZero() || !t.Before(opts.Since)) && (opts.Until.IsZero() || t.Before(opts.Until))
+	}
+	periods := make(map[[2]string]*PeriodStatement)
+	coins := make(map[string]*CoinStatement)
+	account := func(t time.Time, symbol string) (*StatementTotals, *StatementTotals) {
+		label, _ := periodLabel(t, opts.Period)
+		p, ok := periods[[2]string{label, symbol}]
+		if !ok {
+			p = &PeriodStatement{Period: label, Symbol: symbol}
+			periods[[2]string{label, symbol}] = p
+		}
+		return &p.StatementTotals, &coin(coins, symbol).StatementTotals
+	}
+
+	inventory := make(map[[2]string][]lot) // symbol, side
+	for _, e := range events {
+		if e.Type != store.EventFill || (!opts.Until.IsZero() && !e.Time.Before(opts.Until)) {
+			continue
+		}
+		var fill store.Fill
+		if err := e.Decode(&fill); err != nil {
+			return nil, fmt.Errorf("解析成交事件 #%d 失败: %w", e.ID, err)
+		}
+		if !feesFromBills && fill.Fee != 0 && inRange(e.Time) {
+			period, total := account(e.Time, e.Symbol)
+			period.Fees += fill.Fee
+			total.Fees += fill.Fee
+		}
+
+		key := [2]string{e.Symbol, e.Side}
+		if fill.Action == "open" {
+			if fill.Quantity <= 0 {
+				continue
+			}
+			lots := append(inventory[key], lot{quantity: fill.Quantity, price: fill.Price, at: e.Time})
+			if opts.Method == CostAverage && len(lots) > 1 {
+				lots = []lot{averageLot(lots)}
+			}
+			inventory[key] = lots
+			continue
+		}
+
+		lots := inventory[key]
+		held := 0.0
+		for _, l := range lots {
+			held += l.quantity
+		}
+		qty := fill.Quantity
+		if fill.Full || qty <= 0 || qty > held {
+			qty = held
+		}
+		if qty <= qtyEpsilon {
+			continue
+		}
+		consumed, rest := consumeLots(lots, qty)
+		inventory[key] = rest
+		if fill.Price <= 0 {
+			st.Unpriced++
+			continue
+		}
+		if !inRange(e.Time) {
+			continue
+		}
+
+		d := Disposal{Symbol: e.Symbol, Side: e.Side, DisposedAt: e.Time, Quantity: qty, AcquiredAt: consumed[0].at}
+		openValue := 0.0
+		for _, l := range consumed {
+			openValue += l.quantity * l.price
+		}
+		closeValue := qty * fill.Price
+		if e.Side == "short" {
+			d.Proceeds, d.CostBasis = openValue, closeValue
+		} else {
+			d.Proceeds, d.CostBasis = closeValue, openValue
+		}
+		d.RealizedPnL = d.Proceeds - d.CostBasis
+		st.Disposals = append(st.Disposals, d)
+
+		period, total := account(e.Time, e.Symbol)
+		period.RealizedPnL += d.RealizedPnL
+		period.Disposals++
+		total.RealizedPnL += d.RealizedPnL
+		total.Disposals++
+	}
+
+	for _, b := range bills {
+		if !inRange(b.Time) || (opts.Symbol != "" && b.Symbol != opts.Symbol) {
+			continue
+		}
+		period, total := account(b.Time, b.Symbol)
+		switch b.Kind {
+		case BillFunding:
+			period.Funding += b.Amount
+			total.Funding += b.Amount
+		case BillFee:
+			period.Fees -= b.Amount
+			total.Fees -= b.Amount
+		}
+	}
+
+	for key, lots := range inventory {
+		for _, l := range lots {
+			c := coin(coins, key[0])
+			if key[1] == "short" {
+				c.OpenShort += l.quantity
+			} else {
+				c.OpenLong += l.quantity
+			}
+			c.OpenCostBasis += l.quantity * l.price
+		}
+	}
+
+	for _, p := range periods {
+		p.Net = p.RealizedPnL - p.Fees + p.Funding
+		st.Periods = append(st.Periods, *p)
+	}
+	sort.Slice(st.Periods, func(i, j int) bool {
+		if st.Periods[i].Period != st.Periods[j].Period {
+			return st.Periods[i].Period < st.Periods[j].Period
+		}
+		return st.Periods[i].Symbol < st.Periods[j].Symbol
+	})
+	for _, c := range coins {
+		c.Net = c.RealizedPnL - c.Fees + c.Funding
+		st.Coins = append(st.Coins, *c)
+		st.Total.RealizedPnL += c.RealizedPnL
+		st.Total.Fees += c.Fees
+		st.Total.Funding += c.Funding
+		st.Total.Disposals += c.Disposals
+	}
+	st.Total.Net = st.Total.RealizedPnL - st.Total.Fees + st.Total.Funding
+	sort.Slice(st.Coins, func(i, j int) bool { return st.Coins[i].Symbol < st.Coins[j].Symbol })
+	return st, nil
+}
+
+func coin(coins map[string]*CoinStatement, symbol string) *CoinStatement {
+	c, ok := coins[symbol]
+	if !ok {
+		c = &CoinStatement{Symbol: symbol}
+		coins[symbol] = c
+	}
+	return c
+}
+
+// averageLot 合并为一个按数量加权的批次（时间取最早批次）
+func averageLot(lots []lot) lot {
+	merged := lot{at: lots[0].at}
+	value := 0.0
+	for _, l := range lots {
+		merged.quantity += l.quantity
+		value += l.quantity * l.price
+	}
+	if merged.quantity > 0 {
+		merged.price = value / merged.quantity
+	}
+	return merged
+}
+
+// consumeLots 按顺序消耗 qty 数量的批次，返回被消耗的部分和剩余批次
+func consumeLots(lots []lot, qty float64) (consumed, rest []lot) {
+	for i, l := range lots {
+		if qty <= qtyEpsilon {
+			return consumed, append([]lot(nil), lots[i:]...)
+		}
+		take := math.Min(l.quantity, qty)
+		consumed = append(consumed, lot{quantity: take, price: l.price, at: l.at})
+		qty -= take
+		if l.quantity-take > qtyEpsilon {
+			l.quantity -= take
+			return consumed, append([]lot{l}, lots[i+1:]...)
+		}
+	}
+	return consumed, nil
+}
+
+// periodLabel 时间所属统计周期（UTC）
+func periodLabel(t time.Time, period string) (string, error) {
+	t = t.UTC()
+	switch period {
+	case PeriodMonth:
+		return t.Format("2006-01"), nil
+	case PeriodQuarter:
+		return fmt.Sprintf("%d-Q%d", t.Year(), (int(t.Month())-1)/3+1), nil
+	case PeriodYear:
+		return t.Format("2006"), nil
+	}
+	return "", fmt.Errorf("不支持的统计周期: %s（可选: month, quarter, year）", period)
+}
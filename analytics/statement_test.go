@@ -0,0 +1,122 @@
+package analytics
+
+import (
+	"testing"
+	"time"
+
+	"nofx/store"
+)
+
+func statementEvents(t *testing.T) []store.Event {
+	day := func(month time.Month, d int) time.Time { return time.Date(2026, month, d, 12, 0, 0, 0, time.UTC) }
+	ev := func(id int64, symbol, side string, at time.Time, fill store.Fill) store.Event {
+		e := store.Event{ID: id, Type: store.EventFill, Symbol: symbol, Side: side, Time: at}
+		if err := marshalInto(&e, fill); err != nil {
+			t.Fatal(err)
+		}
+		return e
+	}
+	return []store.Event{
+		// BTC 多单：100 和 120 各买 1，2 月 130 卖 1，3 月 110 全部卖出
+		ev(1, "BTCUSDT", "long", day(1, 10), store.Fill{Action: "open", Quantity: 1, Price: 100, Fee: 1}),
+		ev(2, "BTCUSDT", "long", day(1, 20), store.Fill{Action: "open", Quantity: 1, Price: 120, Fee: 1}),
+		ev(3, "BTCUSDT", "long", day(2, 5), store.Fill{Action: "close", Quantity: 1, Price: 130, Fee: 1}),
+		ev(4, "BTCUSDT", "long", day(3, 5), store.Fill{Action: "close", Full: true, Price: 110, Fee: 1}),
+		// ETH 空单：50 开 2，4 月 40 平 1，剩余 1 个未平仓
+		ev(5, "ETHUSDT", "short", day(1, 15), store.Fill{Action: "open", Quantity: 2, Price: 50}),
+		ev(6, "ETHUSDT", "short", day(4, 2), store.Fill{Action: "close", Quantity: 1, Price: 40}),
+		// 平仓价格未知
+		ev(7, "SOLUSDT", "long", day(1, 15), store.Fill{Action: "open", Quantity: 1, Price: 10}),
+		ev(8, "SOLUSDT", "long", day(2, 15), store.Fill{Action: "close", Full: true}),
+	}
+}
+
+func TestBuildStatementFIFO(t *testing.T) {
+	st, err := BuildStatement(statementEvents(t), nil, StatementOptions{})
+	if err != nil {
+		t.Fatalf("BuildStatement: %v", err)
+	}
+	if len(st.Disposals) != 3 || st.Unpriced != 1 {
+		t.Fatalf("expected 3 disposals and 1 unpriced close, got %+v", st)
+	}
+	first, second, short := st.Disposals[0], st.Disposals[1], st.Disposals[2]
+	if !approx(first.CostBasis, 100) || !approx(first.RealizedPnL, 30) || !first.AcquiredAt.Equal(time.Date(2026, 1, 10, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("FIFO 先消耗最早的批次: %+v", first)
+	}
+	if !approx(second.CostBasis, 120) || !approx(second.RealizedPnL, -10) {
+		t.Errorf("second disposal: %+v", second)
+	}
+	if !approx(short.Proceeds, 50) || !approx(short.CostBasis, 40) || !approx(short.RealizedPnL, 10) {
+		t.Errorf("空单卖出金额为开仓金额: %+v", short)
+	}
+
+	want := map[string]float64{"2026-01/BTCUSDT": 0, "2026-02/BTCUSDT": 30, "2026-03/BTCUSDT": -10, "2026-04/ETHUSDT": 10}
+	if len(st.Periods) != len(want) {
+		t.Fatalf("unexpected periods: %+v", st.Periods)
+	}
+	for _, p := range st.Periods {
+		pnl, ok := want[p.Period+"/"+p.Symbol]
+		if !ok || !approx(p.RealizedPnL, pnl) {
+			t.Errorf("period %s %s: realized %v, want %v", p.Period, p.Symbol, p.RealizedPnL, pnl)
+		}
+	}
+	if !approx(st.Total.RealizedPnL, 30) || !approx(st.Total.Fees, 4) || !approx(st.Total.Net, 26) {
+		t.Errorf("unexpected totals: %+v", st.Total)
+	}
+
+	coins := make(map[string]CoinStatement)
+	for _, c := range st.Coins {
+		coins[c.Symbol] = c
+	}
+	if eth := coins["ETHUSDT"]; !approx(eth.OpenShort, 1) || !approx(eth.OpenCostBasis, 50) {
+		t.Errorf("ETH 期末空单持仓: %+v", eth)
+	}
+	if btc := coins["BTCUSDT"]; btc.OpenLong != 0 || btc.Disposals != 2 {
+		t.Errorf("BTC 已全部平仓: %+v", btc)
+	}
+}
+
+func TestBuildStatementAverageWithBills(t *testing.T) {
+	bills := []Bill{
+		{Time: time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC), Symbol: "BTCUSDT", Kind: BillFunding, Amount: -2},
+		{Time: time.Date(2026, 2, 8, 0, 0, 0, 0, time.UTC), Symbol: "BTCUSDT", Kind: BillFee, Amount: -0.5},
+		{Time: time.Date(2026, 4, 8, 0, 0, 0, 0, time.UTC), Symbol: "ETHUSDT", Kind: BillFunding, Amount: 1},
+		{Time: time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), Symbol: "ETHUSDT", Kind: BillFunding, Amount: 100}, // 超出范围
+	}
+	st, err := BuildStatement(statementEvents(t), bills, StatementOptions{
+		Method: CostAverage,
+		Period: PeriodQuarter,
+		Since:  time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC),
+		Until:  time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("BuildStatement: %v", err)
+	}
+	// 加权平均成本 110：130 卖出盈利 20，110 卖出盈亏为 0
+	if len(st.Disposals) != 3 || !approx(st.Disposals[0].RealizedPnL, 20) || !approx(st.Disposals[1].RealizedPnL, 0) {
+		t.Fatalf("unexpected disposals: %+v", st.Disposals)
+	}
+	if st.FeeSource != "bills" {
+		t.Errorf("有手续费流水时以流水为准, got %s", st.FeeSource)
+	}
+
+	q1, q2 := st.Periods[0], st.Periods[1]
+	if q1.Period != "2026-Q1" || q1.Symbol != "BTCUSDT" || !approx(q1.RealizedPnL, 20) || !approx(q1.Fees, 0.5) || !approx(q1.Funding, -2) || !approx(q1.Net, 17.5) {
+		t.Errorf("unexpected Q1: %+v", q1)
+	}
+	if q2.Period != "2026-Q2" || q2.Symbol != "ETHUSDT" || !approx(q2.Net, 11) {
+		t.Errorf("unexpected Q2: %+v", q2)
+	}
+	if !approx(st.Total.Net, 28.5) {
+		t.Errorf("unexpected totals: %+v", st.Total)
+	}
+}
+
+func TestBuildStatementRejectsUnknownOptions(t *testing.T) {
+	if _, err := BuildStatement(nil, nil, StatementOptions{Method: "lifo"}); err == nil {
+		t.Error("expected error for unknown method")
+	}
+	if _, err := BuildStatement(nil, nil, StatementOptions{Period: "week"}); err == nil {
+		t.Error("expected error for unknown period")
+	}
+}
@@ -0,0 +1,63 @@
+package analytics
+
+import (
+	"fmt"
+	"nofx/store"
+	"time"
+)
+
+// RiskEvent 汇总期间的风控事件
+type RiskEvent struct {
+	Time     time.Time `json:"time"`
+	Severity string    `json:"severity"`
+	Title    string    `json:"title"`
+	Message  string    `json:"message"`
+}
+
+// Summary 一个交易员在一段时间内的交易汇总（用于每日/每周报告）
+type Summary struct {
+	TraderID   string      `json:"trader_id"`
+	From       time.Time   `json:"from"`
+	Until      time.Time   `json:"until"`
+	Stats      Stats       `json:"stats"`
+	Trades     []Trade     `json:"trades"`
+	Incomplete int         `json:"incomplete"`
+	RiskEvents []RiskEvent `json:"risk_events"`
+}
+
+// SummaryFromJournal 汇总 [from, until) 期间平仓的交易和发生的风控事件
+func SummaryFromJournal(journal *store.Journal, traderID string, from, until time.Time) (*Summary, error) {
+	report, err := FromJournal(journal, traderID, Filter{Since: from, Until: until})
+	if err != nil {
+		return nil, err
+	}
+	summary := &Summary{
+		TraderID:   traderID,
+		From:       from,
+		Until:      until,
+		Stats:      report.Overall,
+		Trades:     report.Trades,
+		Incomplete: report.Incomplete,
+	}
+
+	events, err := journal.Events(store.EventFilter{TraderID: traderID, Types: []string{store.EventRisk}, Since: from})
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range events {
+		if !e.Time.Before(until) {
+			continue
+		}
+		var risk store.RiskEvent
+		if err := e.Decode(&risk); err != nil {
+			return nil, fmt.Errorf("解析风控事件 #%d 失败: %w", e.ID, err)
+		}
+		summary.RiskEvents = append(summary.RiskEvents, RiskEvent{
+			Time:     e.Time,
+			Severity: risk.Severity,
+			Title:    risk.Title,
+			Message:  risk.Message,
+		})
+	}
+	return summary, nil
+}
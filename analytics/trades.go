@@ -0,0 +1,126 @@
+package analytics
+
+import (
+	"fmt"
+	"math"
+	"nofx/store"
+	"time"
+)
+
+// qtyEpsilon 数量比较容差（浮点误差）
+const qtyEpsilon = 1e-9
+
+// Trade 一笔完整交易（从开仓到持仓归零，包含期间的加仓和部分平仓）
+type Trade struct {
+	Symbol      string        `json:"symbol"`
+	Side        string        `json:"side"`
+	Tag         string        `json:"tag,omitempty"` // 策略标签（取自开仓成交）
+	OpenedAt    time.Time     `json:"opened_at"`
+	ClosedAt    time.Time     `json:"closed_at"`
+	Quantity    float64       `json:"quantity"`    // 最大持仓数量
+	EntryPrice  float64       `json:"entry_price"` // 加权开仓均价
+	ExitPrice   float64       `json:"exit_price"`  // 加权平仓均价
+	GrossPnL    float64       `json:"gross_pnl"`   // 扣除手续费前的盈亏
+	Fees        float64       `json:"fees"`
+	NetPnL      float64       `json:"net_pnl"`
+	InitialRisk float64       `json:"initial_risk"` // 开仓后第一个止损对应的风险金额（未设置止损为 0）
+	RMultiple   float64       `json:"r_multiple"`   // NetPnL / InitialRisk（InitialRisk 为 0 时为 0）
+	HoldingTime time.Duration `json:"holding_time"`
+}
+
+// openTrade 重建过程中尚未平完的交易
+type openTrade struct {
+	Trade
+	quantity  float64 // 当前持仓数量
+	closedQty float64 // 累计平仓数量
+	exitValue float64 // 累计平仓金额
+	priced    bool    // 所有平仓成交都有价格（否则无法计算盈亏）
+}
+
+// BuildTrades 从事件日志的成交和止损事件重建已完成的交易（按平仓时间排序），
+// 同时返回因平仓价格未知（如离线期间被平仓）而无法计算盈亏的交易数量
+func BuildTrades(events []store.Event) ([]Trade, int, error) {
+	open := make(map[string]*openTrade)
+	var trades []Trade
+	incomplete := 0
+
+	for _, e := range events {
+		key := e.Symbol + "_" + e.Side
+		switch e.Type {
+		case store.EventProtection:
+			var p store.Protection
+			if err := e.Decode(&p); err != nil {
+				return nil, 0, fmt.Errorf("解析止盈止损事件 #%d 失败: %w", e.ID, err)
+			}
+			t, ok := open[key]
+			if ok && p.Kind == "stop_loss" && t.InitialRisk == 0 && t.closedQty == 0 && p.Price > 0 {
+				t.InitialRisk = math.Abs(t.EntryPrice-p.Price) * t.quantity
+			}
+
+		case store.EventFill:
+			var fill store.Fill
+			if err := e.Decode(&fill); err != nil {
+				return nil, 0, fmt.Errorf("解析成交事件 #%d 失败: %w", e.ID, err)
+			}
+			t, ok := open[key]
+			if fill.Action == "open" {
+				if !ok {
+					t = &openTrade{Trade: Trade{Symbol: e.Symbol, Side: e.Side, Tag: fill.Tag, OpenedAt: e.Time}, priced: true}
+					open[key] = t
+				}
+				if total := t.quantity + fill.Quantity; total > 0 {
+					t.EntryPrice = (t.EntryPrice*t.quantity + fill.Price*fill.Quantity) / total
+				}
+				t.quantity += fill.Quantity
+				t.Quantity = math.Max(t.Quantity, t.quantity)
+				t.Fees += fill.Fee
+				continue
+			}
+			if !ok {
+				continue
+			}
+
+			qty := fill.Quantity
+			if qty <= 0 || qty > t.quantity {
+				qty = t.quantity
+			}
+			if fill.Price > 0 {
+				t.GrossPnL += pnl(t.Side, t.EntryPrice, fill.Price, qty)
+				t.exitValue += fill.Price * qty
+			} else {
+				t.priced = false
+			}
+			t.closedQty += qty
+			t.quantity -= qty
+			t.Fees += fill.Fee
+			if !fill.Full && t.quantity > qtyEpsilon {
+				continue
+			}
+
+			delete(open, key)
+			if !t.priced {
+				incomplete++
+				continue
+			}
+			t.ClosedAt = e.Time
+			t.HoldingTime = t.ClosedAt.Sub(t.OpenedAt)
+			if t.closedQty > 0 {
+				t.ExitPrice = t.exitValue / t.closedQty
+			}
+			t.NetPnL = t.GrossPnL - t.Fees
+			if t.InitialRisk > 0 {
+				t.RMultiple = t.NetPnL / t.InitialRisk
+			}
+			trades = append(trades, t.Trade)
+		}
+	}
+	return trades, incomplete, nil
+}
+
+// pnl 平仓盈亏（不含手续费）
+func pnl(side string, entry, exit, qty float64) float64 {
+	if side == "short" {
+		return (entry - exit) * qty
+	}
+	return (exit - entry) * qty
+}
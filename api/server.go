@@ -8,13 +8,17 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"nofx/analytics"
 	"nofx/auth"
 	"nofx/config"
 	"nofx/crypto"
 	"nofx/decision"
+	"nofx/health"
 	"nofx/hook"
+	"nofx/logger"
 	"nofx/manager"
 	"nofx/middleware"
+	"nofx/store"
 	"nofx/trader"
 	"os"
 	"strconv"
@@ -369,6 +373,11 @@ func (s *Server) setupRoutes() {
 			protected.POST("/traders/:id/start", s.handleStartTrader)
 			protected.POST("/traders/:id/stop", s.handleStopTrader)
 			protected.PUT("/traders/:id/prompt", s.handleUpdateTraderPrompt)
+			protected.GET("/traders/:id/params", s.handleGetStrategyParams)
+			protected.PUT("/traders/:id/params/:name", s.handleUpdateStrategyParam)
+			protected.POST("/traders/:id/params/revert", s.handleRevertStrategyParam)
+			protected.GET("/traders/:id/reconciliation", s.handleGetReconciliation)
+			protected.POST("/traders/:id/reconciliation/ack", s.handleAckReconciliation)
 
 			// AI模型配置
 			protected.GET("/models", s.handleGetModelConfigs)
@@ -390,10 +399,16 @@ func (s *Server) setupRoutes() {
 			// 指定trader的数据（使用query参数 ?trader_id=xxx）
 			protected.GET("/status", s.handleStatus)
 			protected.GET("/account", s.handleAccount)
+			protected.GET("/accounts", s.handleAccountsSummary)
 			protected.GET("/positions", s.handlePositions)
 			protected.GET("/decisions", s.handleDecisions)
 			protected.GET("/decisions/latest", s.handleLatestDecisions)
+			protected.GET("/decisions/search", s.handleSearchDecisions)
+			protected.GET("/trades", s.handleTrades)
 			protected.GET("/statistics", s.handleStatistics)
+			protected.GET("/analytics", s.handleTradeAnalytics)
+			protected.GET("/analytics/benchmark", s.handleBenchmark)
+			protected.GET("/equity-snapshots", s.handleEquitySnapshots)
 			protected.GET("/performance", s.handlePerformance)
 		}
 	}
@@ -407,6 +422,12 @@ func (s *Server) handleHealth(c *gin.Context) {
 	})
 }
 
+// SetHealthRegistry 注册 Kubernetes 探针接口 /healthz（存活）和 /readyz（就绪，含各依赖状态），需在 Start 之前调用
+func (s *Server) SetHealthRegistry(registry *health.Registry) {
+	s.router.GET("/healthz", gin.WrapH(registry.LivenessHandler()))
+	s.router.GET("/readyz", gin.WrapH(registry.ReadinessHandler()))
+}
+
 // handleGetCSRFToken 获取 CSRF Token
 // 前端调用此接口获取 CSRF Token，用于后续 POST/PUT/DELETE 请求
 func (s *Server) handleGetCSRFToken(c *gin.Context) {
@@ -652,7 +673,7 @@ type CreateTraderRequest struct {
 	UseOITop             bool    `json:"use_oi_top"`
 	TakerFeeRate         float64 `json:"taker_fee_rate"`        // Taker fee rate, default 0.0004 (0.04%)
 	MakerFeeRate         float64 `json:"maker_fee_rate"`        // Maker fee rate, default 0.0002 (0.02%)
-	OrderStrategy        string  `json:"order_strategy"`        // Order strategy: market_only, conservative_hybrid, limit_only
+	OrderStrategy        string  `json:"order_strategy"`        // Order strategy: market_only, conservative_hybrid, limit_only, maker_then_taker
 	LimitPriceOffset     float64 `json:"limit_price_offset"`    // Limit price offset percentage, default -0.03 (-0.03%)
 	LimitTimeoutSeconds  int     `json:"limit_timeout_seconds"` // Limit order timeout in seconds, default 60
 	Timeframes           string  `json:"timeframes"`            // 时间线选择 (逗号分隔，例如: "1m,4h,1d")
@@ -757,7 +778,9 @@ func (s *Server) queryExchangeBalance(userID, exchangeID string, exchangeCfg *co
 	}
 
 	// 查詢實際餘額
-	balanceInfo, err := tempTrader.GetBalance()
+	ctx, cancel := context.WithTimeout(context.Background(), trader.DefaultCallTimeout)
+	defer cancel()
+	balanceInfo, err := tempTrader.GetBalance(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("查詢交易所余額失敗: %w", err)
 	}
@@ -1526,6 +1549,116 @@ func (s *Server) handleUpdateTraderPrompt(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "自定义prompt已更新"})
 }
 
+// getOwnedTrader 获取属于当前用户且已加载到内存中的交易员（失败时已写入响应）
+func (s *Server) getOwnedTrader(c *gin.Context) (*trader.AutoTrader, bool) {
+	userID := c.GetString("user_id")
+	traderID := c.Param("id")
+
+	// 确保用户的交易员已加载到内存中
+	if err := s.traderManager.LoadUserTraders(s.database, userID); err != nil {
+		log.Printf("⚠️ 加载用户 %s 的交易员失败: %v", userID, err)
+	}
+
+	// 校验交易员是否属于当前用户
+	if _, _, _, err := s.database.GetTraderConfig(userID, traderID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "交易员不存在或无访问权限"})
+		return nil, false
+	}
+
+	at, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "交易员不存在"})
+		return nil, false
+	}
+	return at, true
+}
+
+// handleGetStrategyParams 获取可调策略参数（类型、范围、当前值）及调整记录
+func (s *Server) handleGetStrategyParams(c *gin.Context) {
+	at, ok := s.getOwnedTrader(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"params":  at.GetStrategyParams(),
+		"changes": at.GetParamChanges(),
+	})
+}
+
+// handleUpdateStrategyParam 运行时调整策略参数（超出范围或类型不符时拒绝）
+func (s *Server) handleUpdateStrategyParam(c *gin.Context) {
+	var req struct {
+		Value  *float64 `json:"value"`
+		Reason string   `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if req.Value == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "缺少 value"})
+		return
+	}
+
+	at, ok := s.getOwnedTrader(c)
+	if !ok {
+		return
+	}
+
+	change, err := at.SetStrategyParam(c.Param("name"), *req.Value, c.GetString("user_id"), req.Reason)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "策略参数已更新", "change": change})
+}
+
+// handleRevertStrategyParam 回滚一次策略参数调整
+func (s *Server) handleRevertStrategyParam(c *gin.Context) {
+	var req struct {
+		ChangeID int `json:"change_id" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	at, ok := s.getOwnedTrader(c)
+	if !ok {
+		return
+	}
+
+	change, err := at.RevertParamChange(req.ChangeID, c.GetString("user_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "策略参数已回滚", "change": change})
+}
+
+// handleGetReconciliation 获取启动对账结果
+func (s *Server) handleGetReconciliation(c *gin.Context) {
+	at, ok := s.getOwnedTrader(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"report": at.GetReconcileReport()})
+}
+
+// handleAckReconciliation 确认启动对账发现的异常，恢复交易
+func (s *Server) handleAckReconciliation(c *gin.Context) {
+	at, ok := s.getOwnedTrader(c)
+	if !ok {
+		return
+	}
+	if err := at.AcknowledgeReconciliation(c.GetString("user_id")); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "已确认对账异常，交易已恢复", "report": at.GetReconcileReport()})
+}
+
 // handleSyncBalance 同步交易所余额到initial_balance（选项B：手动同步 + 选项C：智能检测）
 func (s *Server) handleSyncBalance(c *gin.Context) {
 	userID := c.GetString("user_id")
@@ -1582,8 +1715,10 @@ func (s *Server) handleSyncBalance(c *gin.Context) {
 		return
 	}
 
-	// 查询实际余额
-	balanceInfo, balanceErr := tempTrader.GetBalance()
+	// 查询实际余额（客户端断开时中止请求）
+	ctx, cancel := context.WithTimeout(c.Request.Context(), trader.DefaultCallTimeout)
+	defer cancel()
+	balanceInfo, balanceErr := tempTrader.GetBalance(ctx)
 	if balanceErr != nil {
 		log.Printf("⚠️ 查询交易所余额失败: %v", balanceErr)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("查询余额失败: %v", balanceErr)})
@@ -2342,6 +2477,234 @@ func (s *Server) handlePerformance(c *gin.Context) {
 	c.JSON(http.StatusOK, performance)
 }
 
+// handleTrades 历史交易查询（支持 symbol/side/result/from/to 筛选、sort/order 排序、offset/limit 分页）
+func (s *Server) handleTrades(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parseJournalRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	offset, limit := parseJournalPage(c)
+
+	page, err := trader.GetDecisionLogger().QueryTrades(logger.TradeQuery{
+		Symbol: c.Query("symbol"),
+		Side:   c.Query("side"),
+		Result: c.Query("result"),
+		From:   from,
+		To:     to,
+		SortBy: c.Query("sort"),
+		Desc:   c.DefaultQuery("order", "desc") == "desc",
+		Offset: offset,
+		Limit:  limit,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": fmt.Sprintf("查询历史交易失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// handleTradeAnalytics 交易表现统计（胜率、R 倍数、盈亏比、期望值、持仓时间、手续费占比、月度盈亏、按策略标签归因；支持 symbol/tag/from/to 筛选）
+func (s *Server) handleTradeAnalytics(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parseJournalRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	report, err := trader.GetTradeAnalytics(analytics.Filter{Symbol: c.Query("symbol"), Tag: c.Query("tag"), Since: from, Until: to})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("计算交易统计失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// handleBenchmark 策略收益与同期买入持有的对比（symbols 默认 BTCUSDT,ETHUSDT；支持 from/to 筛选；价格取自本地K线缓存）
+func (s *Server) handleBenchmark(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parseJournalRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	var symbols []string
+	for _, symbol := range strings.Split(c.Query("symbols"), ",") {
+		if symbol = strings.ToUpper(strings.TrimSpace(symbol)); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+
+	benchmark, err := trader.GetBuyAndHoldBenchmark(symbols, from, to)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("计算买入持有对比失败: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, benchmark)
+}
+
+// handleEquitySnapshots 定时净值快照（钱包余额、未实现盈亏、持仓数及回撤；支持 from/to 筛选、limit 取最近 N 条）
+func (s *Server) handleEquitySnapshots(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parseJournalRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	_, limit := parseJournalPage(c)
+
+	snapshots, err := trader.GetEquitySnapshots(from, to, limit)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("查询净值快照失败: %v", err)})
+		return
+	}
+
+	type EquitySnapshotPoint struct {
+		store.EquitySnapshot
+		TotalEquity float64 `json:"total_equity"` // 账户净值（wallet + unrealized）
+		DrawdownPct float64 `json:"drawdown_pct"` // 相对此前最高净值的回撤
+	}
+	points := make([]EquitySnapshotPoint, 0, len(snapshots))
+	peak, maxDrawdown := 0.0, 0.0
+	for _, snap := range snapshots {
+		point := EquitySnapshotPoint{EquitySnapshot: snap, TotalEquity: snap.TotalEquity()}
+		if point.TotalEquity > peak {
+			peak = point.TotalEquity
+		}
+		if peak > 0 {
+			point.DrawdownPct = (peak - point.TotalEquity) / peak * 100
+		}
+		if point.DrawdownPct > maxDrawdown {
+			maxDrawdown = point.DrawdownPct
+		}
+		points = append(points, point)
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"snapshots":        points,
+		"max_drawdown_pct": maxDrawdown,
+	})
+}
+
+// handleSearchDecisions 历史决策查询（支持 symbol/action/success/from/to 筛选、order 排序、offset/limit 分页）
+func (s *Server) handleSearchDecisions(c *gin.Context) {
+	_, traderID, err := s.getTraderFromQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	trader, err := s.traderManager.GetTrader(traderID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	from, to, err := parseJournalRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	offset, limit := parseJournalPage(c)
+
+	query := logger.DecisionQuery{
+		Symbol: c.Query("symbol"),
+		Action: c.Query("action"),
+		From:   from,
+		To:     to,
+		Desc:   c.DefaultQuery("order", "desc") == "desc",
+		Offset: offset,
+		Limit:  limit,
+	}
+	if successStr := c.Query("success"); successStr != "" {
+		success, err := strconv.ParseBool(successStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "success 参数必须为 true 或 false"})
+			return
+		}
+		query.Success = &success
+	}
+
+	page, err := trader.GetDecisionLogger().QueryDecisions(query)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": fmt.Sprintf("查询历史决策失败: %v", err),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// parseJournalRange 解析 from/to 查询参数（to 为日期时包含当天）
+func parseJournalRange(c *gin.Context) (time.Time, time.Time, error) {
+	from, err := logger.ParseJournalTime(c.Query("from"), false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := logger.ParseJournalTime(c.Query("to"), true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// parseJournalPage 解析 offset/limit 分页参数（无效值使用默认）
+func parseJournalPage(c *gin.Context) (int, int) {
+	offset, _ := strconv.Atoi(c.Query("offset"))
+	limit, _ := strconv.Atoi(c.Query("limit"))
+	return offset, limit
+}
+
 // authMiddleware JWT认证中间件
 func (s *Server) authMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
@@ -2801,6 +3164,7 @@ func (s *Server) Start() error {
 	log.Printf("🌐 API服务器启动在 http://localhost%s", addr)
 	log.Printf("📊 API文档:")
 	log.Printf("  • GET  /api/health           - 健康检查")
+	log.Printf("  • GET  /healthz | /readyz    - 存活/就绪探针（就绪探针含数据源、交易所、数据库、WebSocket 状态）")
 	log.Printf("  • GET  /api/traders          - 公开的AI交易员排行榜前50名（无需认证）")
 	log.Printf("  • GET  /api/competition      - 公开的竞赛数据（无需认证）")
 	log.Printf("  • GET  /api/top-traders      - 前5名交易员数据（无需认证，表现对比用）")
@@ -2811,6 +3175,9 @@ func (s *Server) Start() error {
 	log.Printf("  • DELETE /api/traders/:id    - 删除AI交易员")
 	log.Printf("  • POST /api/traders/:id/start - 启动AI交易员")
 	log.Printf("  • POST /api/traders/:id/stop  - 停止AI交易员")
+	log.Printf("  • GET  /api/traders/:id/params - 可调策略参数及调整记录")
+	log.Printf("  • PUT  /api/traders/:id/params/:name - 运行时调整策略参数")
+	log.Printf("  • POST /api/traders/:id/params/revert - 回滚策略参数调整")
 	log.Printf("  • GET  /api/models           - 获取AI模型配置")
 	log.Printf("  • PUT  /api/models           - 更新AI模型配置")
 	log.Printf("  • GET  /api/exchanges        - 获取交易所配置")
@@ -2820,6 +3187,10 @@ func (s *Server) Start() error {
 	log.Printf("  • GET  /api/positions?trader_id=xxx  - 指定trader的持仓列表")
 	log.Printf("  • GET  /api/decisions?trader_id=xxx  - 指定trader的决策日志")
 	log.Printf("  • GET  /api/decisions/latest?trader_id=xxx - 指定trader的最新决策")
+	log.Printf("  • GET  /api/decisions/search?trader_id=xxx - 按条件查询历史决策")
+	log.Printf("  • GET  /api/trades?trader_id=xxx     - 按条件查询历史交易")
+	log.Printf("  • GET  /api/equity-snapshots?trader_id=xxx - 定时净值快照与回撤")
+	log.Printf("  • GET  /api/analytics/benchmark?trader_id=xxx - 策略收益与买入持有 BTC/ETH 对比")
 	log.Printf("  • GET  /api/statistics?trader_id=xxx - 指定trader的统计信息")
 	log.Printf("  • GET  /api/performance?trader_id=xxx - 指定trader的AI学习表现分析")
 	log.Println()
@@ -2955,6 +3326,12 @@ func (s *Server) handleTopTraders(c *gin.Context) {
 	c.JSON(http.StatusOK, topTraders)
 }
 
+// handleAccountsSummary 按交易所账户汇总当前用户的净值、持仓和交易员（主账户、子账户分别统计）
+func (s *Server) handleAccountsSummary(c *gin.Context) {
+	userID := c.GetString("user_id")
+	c.JSON(http.StatusOK, s.traderManager.GetAccountsSummary(userID))
+}
+
 // handleEquityHistoryBatch 批量获取多个交易员的收益率历史数据（无需认证，用于表现对比）
 func (s *Server) handleEquityHistoryBatch(c *gin.Context) {
 	var requestBody struct {
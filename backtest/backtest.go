@@ -0,0 +1,283 @@
+// Package backtest 使用历史K线回放策略引擎（strategy 包），模拟成交（手续费、滑点、资金费率）并统计绩效
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"nofx/analytics"
+	"nofx/market"
+	"nofx/strategy"
+)
+
+// 默认回测参数
+const (
+	defaultInterval       = "15m"
+	defaultLookback       = 200
+	defaultInitialBalance = 10000.0
+	defaultTakerFeeRate   = 0.0004
+	defaultMakerFeeRate   = 0.0002
+)
+
+// Config 回测配置
+type Config struct {
+	Symbols   []string
+	Interval  string   // 回放步长：每根该周期K线收盘时调用一次策略（默认 15m）
+	Intervals []string // 提供给策略的K线周期（默认只有 Interval）
+	Lookback  int      // 每个周期提供给策略的最近K线数量（默认 200）
+
+	// 历史K线：币种 -> 周期 -> K线（按时间正序，必须包含 Interval 周期）
+	Klines map[string]map[string][]market.Kline
+
+	// 回放区间（按 Interval K线收盘时间；零值=不限制）。区间之前的K线只作为策略的历史数据
+	Start time.Time
+	End   time.Time
+
+	InitialBalance float64   // 初始资金（默认 10000 USDT）
+	Exchange       string    // 可选：交易所名称（按 ExchangeFees 取默认 maker/taker 费率）
+	TakerFeeRate   float64   // 市价成交手续费率（0=交易所默认，未知交易所 0.0004，<0=免手续费）
+	MakerFeeRate   float64   // 限价成交手续费率（0=交易所默认，未知交易所 0.0002，<0=免手续费）
+	SlippagePct    float64   // 市价成交滑点百分比（0.05 = 0.05%，0=无滑点；配置了 Fill.SlippageCurve 时不使用）
+	Fill           FillModel // 撮合模型：价差、按订单金额的滑点曲线、部分成交、限价止盈
+
+	// 资金费率：每 8 小时（00:00/08:00/16:00 UTC）结算一次
+	FundingRate  float64                         // 没有历史资金费率时使用的固定费率（0=不计资金费）
+	FundingRates map[string][]market.FundingRate // 历史资金费率：币种 -> 按结算时间正序
+
+	Strategies []strategy.Strategy
+	Risk       strategy.RiskManager
+	Churn      strategy.ChurnLimits // 每个币种的交易频率限制（零值=不限制）
+
+	// 蒙特卡洛风险模拟：对回测交易的盈亏重抽样估计回撤分位数和破产概率（nil=不模拟）
+	MonteCarlo *analytics.MonteCarloConfig
+}
+
+// Result 回测结果
+type Result struct {
+	InitialBalance float64       `json:"initial_balance"`
+	FinalEquity    float64       `json:"final_equity"`
+	TotalReturnPct float64       `json:"total_return_pct"`
+	MaxDrawdownPct float64       `json:"max_drawdown_pct"`
+	WinRate        float64       `json:"win_rate"` // 盈利交易占比（百分比）
+	SharpeRatio    float64       `json:"sharpe_ratio"`
+	TotalFees      float64       `json:"total_fees"`
+	TotalFunding   float64       `json:"total_funding"` // 资金费净收支（负数=支付）
+	PartialFills   int           `json:"partial_fills"` // 受K线成交额限制只部分成交的策略订单数
+	Trades         []Trade       `json:"trades"`
+	EquityCurve    []EquityPoint `json:"equity_curve"`
+
+	MonteCarlo      *analytics.MonteCarloResult `json:"monte_carlo,omitempty"`
+	MonteCarloError string                      `json:"monte_carlo_error,omitempty"` // 无法模拟的原因（如交易太少、没有亏损交易）
+}
+
+// EquityPoint 权益曲线上的一个点
+type EquityPoint struct {
+	Time   time.Time `json:"time"`
+	Equity float64   `json:"equity"`
+}
+
+// Summary 绩效摘要
+func (r *Result) Summary() string {
+	return fmt.Sprintf("收益 %+.2f%% | 最大回撤 %.2f%% | 胜率 %.1f%% (%d 笔) | 夏普 %.2f | 手续费 %.2f | 资金费 %+.2f",
+		r.TotalReturnPct, r.MaxDrawdownPct, r.WinRate, len(r.Trades), r.SharpeRatio, r.TotalFees, r.TotalFunding)
+}
+
+// MonteCarloSummary 蒙特卡洛风险模拟摘要（未模拟时为空）
+func (r *Result) MonteCarloSummary() string {
+	mc := r.MonteCarlo
+	if mc == nil {
+		if r.MonteCarloError != "" {
+			return "蒙特卡洛模拟未执行: " + r.MonteCarloError
+		}
+		return ""
+	}
+	return fmt.Sprintf("蒙特卡洛 %d 次 × %d 笔（每笔风险 %.2f%%）| 最大回撤 p50 %.2f%% / p95 %.2f%% / p99 %.2f%% | 收益 p5 %+.2f%% / p50 %+.2f%% / p95 %+.2f%% | 破产概率（回撤≥%.0f%%）%.2f%%",
+		mc.Runs, mc.Trades, mc.RiskPerTradePct, mc.DrawdownP50, mc.DrawdownP95, mc.DrawdownP99,
+		mc.ReturnP5, mc.ReturnP50, mc.ReturnP95, mc.RuinDrawdownPct, mc.RiskOfRuinPct)
+}
+
+// LoadKlines 从本地K线缓存（market.EnableKlineCache / market.Backfill）读取回测所需的K线
+func LoadKlines(source string, symbols, intervals []string, from, to time.Time) (map[string]map[string][]market.Kline, error) {
+	data := make(map[string]map[string][]market.Kline, len(symbols))
+	for _, symbol := range symbols {
+		data[symbol] = make(map[string][]market.Kline, len(intervals))
+		for _, interval := range intervals {
+			klines, err := market.LoadCachedKlines(source, symbol, interval, from, to)
+			if err != nil {
+				return nil, fmt.Errorf("读取 %s %s K线失败: %w", symbol, interval, err)
+			}
+			if len(klines) == 0 {
+				return nil, fmt.Errorf("缓存中没有 %s %s 的K线，请先执行回填", symbol, interval)
+			}
+			data[symbol][interval] = klines
+		}
+	}
+	return data, nil
+}
+
+// Run 执行回测
+// 每根 Interval K线收盘时：先按该K线的最高/最低价检查止损止盈并结算资金费，
+// 再调用策略，策略决策按收盘价（加滑点）立即成交；回测结束时按最后收盘价平掉所有持仓
+func Run(cfg Config) (*Result, error) {
+	cfg = withDefaults(cfg)
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("未指定回测币种")
+	}
+	intervalDur, ok := market.TimeframeDuration(cfg.Interval)
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", cfg.Interval)
+	}
+
+	// 回放时间轴：所有币种 Interval K线的收盘时间
+	bars := make(map[string]map[int64]market.Kline, len(cfg.Symbols))
+	seen := make(map[int64]bool)
+	var timeline []int64
+	for _, symbol := range cfg.Symbols {
+		klines := cfg.Klines[symbol][cfg.Interval]
+		if len(klines) == 0 {
+			return nil, fmt.Errorf("缺少 %s 的 %s K线", symbol, cfg.Interval)
+		}
+		bars[symbol] = make(map[int64]market.Kline, len(klines))
+		for _, k := range klines {
+			if (!cfg.Start.IsZero() && k.CloseTime < cfg.Start.UnixMilli()) || (!cfg.End.IsZero() && k.CloseTime > cfg.End.UnixMilli()) {
+				continue
+			}
+			bars[symbol][k.CloseTime] = k
+			if !seen[k.CloseTime] {
+				seen[k.CloseTime] = true
+				timeline = append(timeline, k.CloseTime)
+			}
+		}
+	}
+	if len(timeline) == 0 {
+		return nil, fmt.Errorf("回放区间内没有 %s K线", cfg.Interval)
+	}
+	sort.Slice(timeline, func(i, j int) bool { return timeline[i] < timeline[j] })
+
+	b := newBroker(cfg)
+	var now int64
+	runner, err := strategy.NewRunner(strategy.RunnerConfig{
+		Symbols:    cfg.Symbols,
+		Intervals:  cfg.Intervals,
+		Limit:      cfg.Lookback,
+		Strategies: cfg.Strategies,
+		Risk:       cfg.Risk,
+		Executor:   b,
+		Account:    b,
+		Feed:       replayFeed(cfg.Klines, &now),
+		Clock:      func() time.Time { return time.UnixMilli(now + 1) },
+		Churn:      cfg.Churn,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{InitialBalance: cfg.InitialBalance}
+	prev := timeline[0] - intervalDur.Milliseconds()
+	for _, t := range timeline {
+		now = t
+		b.now = t
+		for _, symbol := range cfg.Symbols {
+			if bar, ok := bars[symbol][t]; ok {
+				b.onBar(symbol, bar, prev)
+			}
+		}
+		if _, err := runner.RunOnce(context.Background()); err != nil {
+			return nil, fmt.Errorf("回测 %s 执行策略失败: %w", time.UnixMilli(t).UTC().Format(time.RFC3339), err)
+		}
+		result.EquityCurve = append(result.EquityCurve, EquityPoint{Time: time.UnixMilli(t + 1).UTC(), Equity: b.equity()})
+		prev = t
+	}
+	b.closeAll(reasonEndOfData)
+	if n := len(result.EquityCurve); n > 0 {
+		result.EquityCurve[n-1].Equity = b.equity()
+	}
+
+	result.Trades = b.trades
+	result.TotalFees = b.totalFees
+	result.TotalFunding = b.totalFunding
+	result.PartialFills = b.partialFills
+	result.FinalEquity = b.equity()
+	result.TotalReturnPct = (result.FinalEquity - cfg.InitialBalance) / cfg.InitialBalance * 100
+	result.MaxDrawdownPct = maxDrawdownPct(result.EquityCurve)
+	result.WinRate = winRate(result.Trades)
+	result.SharpeRatio = sharpeRatio(result.EquityCurve, intervalDur)
+	if cfg.MonteCarlo != nil {
+		result.MonteCarlo, err = monteCarlo(result.Trades, *cfg.MonteCarlo)
+		if err != nil {
+			result.MonteCarloError = err.Error()
+		}
+	}
+	return result, nil
+}
+
+// monteCarlo 以亏损交易的平均亏损为 1R 换算回测交易，重抽样模拟给定风险比例下的回撤分布
+func monteCarlo(trades []Trade, cfg analytics.MonteCarloConfig) (*analytics.MonteCarloResult, error) {
+	pnls := make([]float64, len(trades))
+	for i, t := range trades {
+		pnls[i] = t.PnL
+	}
+	rMultiples, err := analytics.RMultiplesFromPnL(pnls)
+	if err != nil {
+		return nil, err
+	}
+	return analytics.MonteCarlo(rMultiples, cfg)
+}
+
+// withDefaults 填充默认配置
+func withDefaults(cfg Config) Config {
+	if cfg.Interval == "" {
+		cfg.Interval = defaultInterval
+	}
+	if len(cfg.Intervals) == 0 {
+		cfg.Intervals = []string{cfg.Interval}
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = defaultLookback
+	}
+	if cfg.InitialBalance <= 0 {
+		cfg.InitialBalance = defaultInitialBalance
+	}
+	fees, known := ExchangeFees[strings.ToLower(cfg.Exchange)]
+	if !known {
+		fees = FeeSchedule{Maker: defaultMakerFeeRate, Taker: defaultTakerFeeRate}
+	}
+	cfg.TakerFeeRate = feeRateOrDefault(cfg.TakerFeeRate, fees.Taker)
+	cfg.MakerFeeRate = feeRateOrDefault(cfg.MakerFeeRate, fees.Maker)
+	return cfg
+}
+
+// feeRateOrDefault 0=默认费率，<0=免手续费
+func feeRateOrDefault(rate, fallback float64) float64 {
+	switch {
+	case rate == 0:
+		return fallback
+	case rate < 0:
+		return 0
+	}
+	return rate
+}
+
+// replayFeed 历史回放数据源：只返回在 *now 之前已收盘的K线（避免未来数据）
+func replayFeed(data map[string]map[string][]market.Kline, now *int64) strategy.Feed {
+	return func(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error) {
+		snapshot := &market.MultiTimeframeKlines{
+			Symbol: symbol,
+			Series: make(map[string][]market.Kline, len(intervals)),
+			Errors: make(map[string]error),
+		}
+		for _, interval := range intervals {
+			klines := data[symbol][interval]
+			end := sort.Search(len(klines), func(i int) bool { return klines[i].CloseTime > *now })
+			start := 0
+			if limit > 0 && end > limit {
+				start = end - limit
+			}
+			snapshot.Series[interval] = klines[start:end]
+		}
+		return snapshot, nil
+	}
+}
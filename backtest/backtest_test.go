@@ -0,0 +1,189 @@
+package backtest
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/analytics"
+	"nofx/market"
+	"nofx/strategy"
+)
+
+const bar15m = int64(15 * time.Minute / time.Millisecond)
+
+// makeKlines 按收盘价序列生成 15m K线（开盘价=前收盘，高低价上下浮动 0.5）
+func makeKlines(start int64, closes []float64) []market.Kline {
+	klines := make([]market.Kline, len(closes))
+	open := closes[0]
+	for i, c := range closes {
+		openTime := start + int64(i)*bar15m
+		klines[i] = market.Kline{
+			OpenTime:  openTime,
+			Open:      open,
+			High:      math.Max(open, c) + 0.5,
+			Low:       math.Min(open, c) - 0.5,
+			Close:     c,
+			CloseTime: openTime + bar15m - 1,
+		}
+		open = c
+	}
+	return klines
+}
+
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+
+func TestRunTakeProfitWithFeesAndSlippage(t *testing.T) {
+	closes := []float64{100, 100, 100, 101, 102, 103, 104, 106, 105}
+	opened := false
+	s := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		if opened || len(snap.Klines["15m"]) < 3 {
+			return nil
+		}
+		opened = true
+		return []strategy.Decision{{Action: "open_long", Leverage: 2, PositionSizeUSD: 1000, StopLoss: 95, TakeProfit: 105}}
+	})
+
+	result, err := Run(Config{
+		Symbols:      []string{"BTCUSDT"},
+		Klines:       map[string]map[string][]market.Kline{"BTCUSDT": {"15m": makeKlines(0, closes)}},
+		TakerFeeRate: 0.001,
+		SlippagePct:  0.1,
+		Strategies:   []strategy.Strategy{s},
+	})
+	if err != nil {
+		t.Fatalf("Run 失败: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("期望 1 笔交易，得到 %d: %+v", len(result.Trades), result.Trades)
+	}
+
+	tr := result.Trades[0]
+	entry := 100 * 1.001 // 第 3 根收盘价 + 0.1% 滑点
+	qty := 1000 / entry
+	exit := 105 * 0.999 // 第 8 根最高价触及止盈 105，卖出滑点
+	wantFees := 1000*0.001 + exit*qty*0.001
+	wantPnL := (exit-entry)*qty - wantFees
+	if tr.Reason != reasonTakeProfit || !approx(tr.EntryPrice, entry) || !approx(tr.ExitPrice, exit) {
+		t.Errorf("unexpected trade: %+v", tr)
+	}
+	if !approx(tr.Fees, wantFees) || !approx(tr.PnL, wantPnL) {
+		t.Errorf("fees=%v pnl=%v，期望 fees=%v pnl=%v", tr.Fees, tr.PnL, wantFees, wantPnL)
+	}
+	if !approx(result.FinalEquity, 10000+wantPnL) || !approx(result.TotalFees, wantFees) {
+		t.Errorf("final equity=%v fees=%v", result.FinalEquity, result.TotalFees)
+	}
+	if result.WinRate != 100 || len(result.EquityCurve) != len(closes) {
+		t.Errorf("win rate=%v curve=%d", result.WinRate, len(result.EquityCurve))
+	}
+}
+
+func TestRunFundingAndEndOfData(t *testing.T) {
+	// 从 07:00 开始持有空仓到 17:00，跨过 08:00 和 16:00 两次结算
+	start := int64(7 * time.Hour / time.Millisecond)
+	closes := make([]float64, 40) // 10 小时
+	for i := range closes {
+		closes[i] = 100
+	}
+	s := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		if _, ok := snap.Position("short"); ok || snap.Account.PositionCount > 0 {
+			return nil
+		}
+		return []strategy.Decision{{Action: "open_short", Leverage: 1, PositionSizeUSD: 1000}}
+	})
+
+	result, err := Run(Config{
+		Symbols:      []string{"ETHUSDT"},
+		Klines:       map[string]map[string][]market.Kline{"ETHUSDT": {"15m": makeKlines(start, closes)}},
+		TakerFeeRate: -1,
+		FundingRate:  0.0001,
+		FundingRates: map[string][]market.FundingRate{"ETHUSDT": {{FundingTime: 16 * 3600 * 1000, Rate: 0.0005}}},
+		Strategies:   []strategy.Strategy{s},
+	})
+	if err != nil {
+		t.Fatalf("Run 失败: %v", err)
+	}
+	if len(result.Trades) != 1 || result.Trades[0].Reason != reasonEndOfData {
+		t.Fatalf("unexpected trades: %+v", result.Trades)
+	}
+	// 08:00 使用固定费率 0.01%，16:00 使用历史费率 0.05%；费率为正时空头收取资金费
+	if !approx(result.TotalFunding, 0.6) || !approx(result.Trades[0].Funding, 0.6) || result.TotalFees != 0 {
+		t.Errorf("funding=%v trade=%+v", result.TotalFunding, result.Trades[0])
+	}
+	if !approx(result.FinalEquity, 10000.6) {
+		t.Errorf("final equity=%v", result.FinalEquity)
+	}
+}
+
+func TestReplayFeedHasNoLookahead(t *testing.T) {
+	klines := makeKlines(0, []float64{1, 2, 3, 4, 5})
+	var seen []int
+	s := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		series := snap.Klines["15m"]
+		last, _ := snap.Latest("15m")
+		if last.CloseTime >= snap.Time.UnixMilli() {
+			t.Errorf("策略看到了未收盘的K线: %+v at %v", last, snap.Time)
+		}
+		seen = append(seen, len(series))
+		return nil
+	})
+
+	if _, err := Run(Config{
+		Symbols:    []string{"BTCUSDT"},
+		Lookback:   3,
+		Klines:     map[string]map[string][]market.Kline{"BTCUSDT": {"15m": klines}},
+		Strategies: []strategy.Strategy{s},
+	}); err != nil {
+		t.Fatalf("Run 失败: %v", err)
+	}
+	if got := seen; len(got) != 5 || got[0] != 1 || got[2] != 3 || got[4] != 3 {
+		t.Errorf("unexpected lookback sizes: %v", got)
+	}
+}
+
+func TestMetrics(t *testing.T) {
+	curve := []EquityPoint{{Equity: 100}, {Equity: 120}, {Equity: 90}, {Equity: 110}, {Equity: 130}}
+	if dd := maxDrawdownPct(curve); !approx(dd, 25) {
+		t.Errorf("max drawdown=%v，期望 25", dd)
+	}
+	if sr := sharpeRatio(curve, 24*time.Hour); sr <= 0 {
+		t.Errorf("期望正的夏普比率，得到 %v", sr)
+	}
+	if wr := winRate([]Trade{{PnL: 1}, {PnL: -1}, {PnL: 2}, {PnL: 0}}); wr != 50 {
+		t.Errorf("win rate=%v，期望 50", wr)
+	}
+
+	r := &Result{Trades: []Trade{{Symbol: "BTCUSDT", Side: "long", PnL: 1.5, Reason: reasonSignal}}}
+	var buf bytes.Buffer
+	if err := r.WriteTradesCSV(&buf); err != nil {
+		t.Fatalf("WriteTradesCSV 失败: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != 2 || !strings.Contains(lines[1], "BTCUSDT,long") {
+		t.Errorf("unexpected csv: %q", buf.String())
+	}
+}
+
+func TestMonteCarloReport(t *testing.T) {
+	var trades []Trade
+	for i := 0; i < 12; i++ {
+		trades = append(trades, Trade{PnL: 30}, Trade{PnL: -10})
+	}
+	mc, err := monteCarlo(trades, analytics.MonteCarloConfig{RiskPerTradePct: 2, Runs: 500})
+	if err != nil {
+		t.Fatalf("monteCarlo 失败: %v", err)
+	}
+	r := &Result{MonteCarlo: mc}
+	if mc.SampleTrades != 24 || mc.Trades != 24 || !strings.Contains(r.MonteCarloSummary(), "蒙特卡洛 500 次 × 24 笔") {
+		t.Errorf("unexpected monte carlo report: %+v %q", mc, r.MonteCarloSummary())
+	}
+
+	if _, err := monteCarlo([]Trade{{PnL: 1}, {PnL: 2}}, analytics.MonteCarloConfig{RiskPerTradePct: 1}); err == nil {
+		t.Error("期望没有亏损交易时返回错误")
+	}
+	if (&Result{}).MonteCarloSummary() != "" {
+		t.Error("未模拟时摘要应为空")
+	}
+}
@@ -0,0 +1,394 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"nofx/decision"
+	"nofx/market"
+	"nofx/strategy"
+)
+
+// 平仓原因
+const (
+	reasonSignal     = "signal"
+	reasonStopLoss   = "stop_loss"
+	reasonTakeProfit = "take_profit"
+	reasonEndOfData  = "end_of_data"
+)
+
+// fundingIntervalMs 资金费结算间隔（8 小时）
+const fundingIntervalMs = int64(8 * time.Hour / time.Millisecond)
+
+// Trade 一笔已平仓交易（部分平仓单独记录）
+type Trade struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	EntryTime  time.Time `json:"entry_time"`
+	ExitTime   time.Time `json:"exit_time"`
+	EntryPrice float64   `json:"entry_price"`
+	ExitPrice  float64   `json:"exit_price"`
+	Quantity   float64   `json:"quantity"`
+	Fees       float64   `json:"fees"`    // 开仓+平仓手续费（按数量分摊）
+	Funding    float64   `json:"funding"` // 持仓期间资金费净收支（负数=支付）
+	PnL        float64   `json:"pnl"`     // 扣除手续费和资金费后的净盈亏
+	Reason     string    `json:"reason"`  // signal / stop_loss / take_profit / end_of_data
+}
+
+// simPosition 模拟持仓
+type simPosition struct {
+	symbol     string
+	side       string
+	quantity   float64
+	entryPrice float64
+	leverage   int
+	stopLoss   float64
+	takeProfit float64
+	openTime   int64
+	fees       float64 // 尚未结算到交易记录的开仓手续费
+	funding    float64 // 尚未结算到交易记录的资金费
+}
+
+// broker 模拟交易所：市价成交（含滑点和手续费）、止损止盈触发、资金费结算
+// 实现 strategy.Executor 和 strategy.AccountSource
+type broker struct {
+	cfg          Config
+	cash         float64 // 钱包余额（已扣除手续费和资金费，含已实现盈亏）
+	positions    map[string]*simPosition
+	prices       map[string]float64
+	bars         map[string]market.Kline // 各币种最新一根K线（部分成交按其成交额限制）
+	now          int64
+	trades       []Trade
+	totalFees    float64
+	totalFunding float64
+	partialFills int
+	onFill       func(Fill) // 可选：策略决策成交时回调（不含止损止盈和回测结束平仓）
+}
+
+func newBroker(cfg Config) *broker {
+	return &broker{
+		cfg:       cfg,
+		cash:      cfg.InitialBalance,
+		positions: make(map[string]*simPosition),
+		prices:    make(map[string]float64),
+		bars:      make(map[string]market.Kline),
+	}
+}
+
+// ExecuteDecision 按当前收盘价（加价差和滑点）成交策略决策
+func (b *broker) ExecuteDecision(d strategy.Decision) error {
+	price := b.prices[d.Symbol]
+	if price <= 0 && d.Action != "hold" && d.Action != "wait" {
+		return fmt.Errorf("%s 没有行情数据", d.Symbol)
+	}
+
+	switch d.Action {
+	case "open_long", "open_short":
+		return b.open(d, price)
+	case "close_long":
+		return b.closeSide(d.Symbol, "long", 100)
+	case "close_short":
+		return b.closeSide(d.Symbol, "short", 100)
+	case "partial_close":
+		if d.ClosePercentage <= 0 || d.ClosePercentage > 100 {
+			return fmt.Errorf("平仓百分比必须在 0-100 之间: %.1f", d.ClosePercentage)
+		}
+		for _, side := range []string{"long", "short"} {
+			if _, ok := b.positions[positionKey(d.Symbol, side)]; ok {
+				return b.closeSide(d.Symbol, side, d.ClosePercentage)
+			}
+		}
+		return fmt.Errorf("没有找到 %s 的持仓", d.Symbol)
+	case "update_stop_loss", "update_take_profit":
+		for _, side := range []string{"long", "short"} {
+			if p, ok := b.positions[positionKey(d.Symbol, side)]; ok {
+				if d.Action == "update_stop_loss" {
+					p.stopLoss = d.NewStopLoss
+				} else {
+					p.takeProfit = d.NewTakeProfit
+				}
+				return nil
+			}
+		}
+		return fmt.Errorf("没有找到 %s 的持仓", d.Symbol)
+	case "hold", "wait":
+		return nil
+	default:
+		return fmt.Errorf("未知的action: %s", d.Action)
+	}
+}
+
+// StrategyAccount 当前模拟账户与持仓
+func (b *broker) StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error) {
+	keys := make([]string, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var positions []decision.PositionInfo
+	unrealized, marginUsed := 0.0, 0.0
+	for _, key := range keys {
+		p := b.positions[key]
+		mark := b.prices[p.symbol]
+		pnl := p.unrealizedPnL(mark)
+		margin := p.quantity * p.entryPrice / float64(p.leverage)
+		unrealized += pnl
+		marginUsed += margin
+		positions = append(positions, decision.PositionInfo{
+			Symbol:           p.symbol,
+			Side:             p.side,
+			EntryPrice:       p.entryPrice,
+			MarkPrice:        mark,
+			Quantity:         p.quantity,
+			Leverage:         p.leverage,
+			UnrealizedPnL:    pnl,
+			UnrealizedPnLPct: pnl / margin * 100,
+			MarginUsed:       margin,
+			UpdateTime:       p.openTime,
+			StopLoss:         p.stopLoss,
+			TakeProfit:       p.takeProfit,
+		})
+	}
+
+	equity := b.cash + unrealized
+	account := decision.AccountInfo{
+		TotalEquity:      equity,
+		AvailableBalance: equity - marginUsed,
+		UnrealizedPnL:    unrealized,
+		TotalPnL:         equity - b.cfg.InitialBalance,
+		TotalPnLPct:      (equity - b.cfg.InitialBalance) / b.cfg.InitialBalance * 100,
+		MarginUsed:       marginUsed,
+		PositionCount:    len(positions),
+	}
+	if equity > 0 {
+		account.MarginUsedPct = marginUsed / equity * 100
+	}
+	return account, positions, nil
+}
+
+// open 开仓或同向加仓（与实盘一致：已有反向持仓时拒绝）
+func (b *broker) open(d strategy.Decision, price float64) error {
+	side, opposite := "long", "short"
+	if d.Action == "open_short" {
+		side, opposite = "short", "long"
+	}
+	if _, ok := b.positions[positionKey(d.Symbol, opposite)]; ok {
+		return fmt.Errorf("%s 已有反向持仓，请先平仓", d.Symbol)
+	}
+	if d.PositionSizeUSD <= 0 {
+		return fmt.Errorf("开仓金额必须大于0: %.2f", d.PositionSizeUSD)
+	}
+	leverage := d.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+
+	account, _, _ := b.StrategyAccount()
+	if margin := d.PositionSizeUSD / float64(leverage); margin > account.AvailableBalance {
+		return fmt.Errorf("可用余额不足: 需要保证金 %.2f USDT，可用 %.2f USDT", margin, account.AvailableBalance)
+	}
+
+	notional := d.PositionSizeUSD
+	if liquidity := b.liquidityUSD(d.Symbol); liquidity > 0 && notional > liquidity {
+		notional = liquidity
+		b.partialFills++
+	}
+	fill := b.marketPrice(price, notional, side == "long")
+	quantity := notional / fill
+	fee := notional * b.cfg.TakerFeeRate
+	b.cash -= fee
+	b.totalFees += fee
+
+	key := positionKey(d.Symbol, side)
+	p, exists := b.positions[key]
+	if !exists {
+		p = &simPosition{symbol: d.Symbol, side: side, leverage: leverage, openTime: b.now}
+		b.positions[key] = p
+	}
+	p.entryPrice = (p.entryPrice*p.quantity + fill*quantity) / (p.quantity + quantity)
+	p.quantity += quantity
+	p.fees += fee
+	b.fill(d.Symbol, side, "open", fill, quantity)
+	p.leverage = leverage
+	if d.StopLoss > 0 {
+		p.stopLoss = d.StopLoss
+	}
+	if d.TakeProfit > 0 {
+		p.takeProfit = d.TakeProfit
+	}
+	return nil
+}
+
+// closeSide 按当前收盘价（加价差和滑点）平掉 pct% 的持仓（受K线成交额限制时只平掉成交的部分）
+func (b *broker) closeSide(symbol, side string, pct float64) error {
+	p, ok := b.positions[positionKey(symbol, side)]
+	if !ok {
+		return fmt.Errorf("没有找到 %s 的%s仓", symbol, map[string]string{"long": "多", "short": "空"}[side])
+	}
+	price := b.prices[symbol]
+	quantity := math.Min(p.quantity*pct/100, p.quantity)
+	if liquidity := b.liquidityUSD(symbol); liquidity > 0 && quantity*price > liquidity {
+		quantity = liquidity / price
+		b.partialFills++
+	}
+	fill := b.marketPrice(price, quantity*price, side == "short")
+	b.closePosition(p, fill, quantity, reasonSignal)
+	b.fill(symbol, side, "close", fill, quantity)
+	return nil
+}
+
+// fill 通知策略决策成交
+func (b *broker) fill(symbol, side, action string, price, quantity float64) {
+	if b.onFill != nil {
+		b.onFill(Fill{Time: time.UnixMilli(b.now + 1).UTC(), Symbol: symbol, Side: side, Action: action, Price: price, Quantity: quantity})
+	}
+}
+
+// closePosition 以 exitPrice 平掉 quantity 数量，记录交易
+func (b *broker) closePosition(p *simPosition, exitPrice, quantity float64, reason string) {
+	if quantity > p.quantity || p.quantity-quantity < 1e-12 {
+		quantity = p.quantity
+	}
+	share := quantity / p.quantity
+
+	gross := (exitPrice - p.entryPrice) * quantity
+	if p.side == "short" {
+		gross = -gross
+	}
+	feeRate := b.cfg.TakerFeeRate
+	if reason == reasonTakeProfit && b.cfg.Fill.LimitTakeProfit {
+		feeRate = b.cfg.MakerFeeRate
+	}
+	closeFee := exitPrice * quantity * feeRate
+	openFee := p.fees * share
+	funding := p.funding * share
+
+	b.cash += gross - closeFee
+	b.totalFees += closeFee
+	p.fees -= openFee
+	p.funding -= funding
+	p.quantity -= quantity
+
+	b.trades = append(b.trades, Trade{
+		Symbol:     p.symbol,
+		Side:       p.side,
+		EntryTime:  time.UnixMilli(p.openTime + 1).UTC(),
+		ExitTime:   time.UnixMilli(b.now + 1).UTC(),
+		EntryPrice: p.entryPrice,
+		ExitPrice:  exitPrice,
+		Quantity:   quantity,
+		Fees:       openFee + closeFee,
+		Funding:    funding,
+		PnL:        gross - openFee - closeFee + funding,
+		Reason:     reason,
+	})
+	if p.quantity <= 0 {
+		delete(b.positions, positionKey(p.symbol, p.side))
+	}
+}
+
+// onBar 新K线收盘：结算 (prev, bar.CloseTime] 内的资金费，检查止损止盈，更新最新价格
+func (b *broker) onBar(symbol string, bar market.Kline, prev int64) {
+	for _, side := range []string{"long", "short"} {
+		p, ok := b.positions[positionKey(symbol, side)]
+		if !ok {
+			continue
+		}
+		b.settleFunding(p, bar.Close, prev, bar.CloseTime)
+		b.checkProtection(p, bar)
+	}
+	b.prices[symbol] = bar.Close
+	b.bars[symbol] = bar
+}
+
+// settleFunding 结算 (from, to] 内的资金费（费率为正时多头支付空头）
+func (b *broker) settleFunding(p *simPosition, price float64, from, to int64) {
+	first := (from/fundingIntervalMs + 1) * fundingIntervalMs
+	for ft := first; ft <= to; ft += fundingIntervalMs {
+		if ft <= p.openTime {
+			continue
+		}
+		payment := p.quantity * price * b.fundingRate(p.symbol, ft)
+		if p.side == "long" {
+			payment = -payment
+		}
+		b.cash += payment
+		b.totalFunding += payment
+		p.funding += payment
+	}
+}
+
+// fundingRate 结算时刻的资金费率（优先使用历史数据中不晚于该时刻的最近一条）
+func (b *broker) fundingRate(symbol string, fundingTime int64) float64 {
+	history := b.cfg.FundingRates[symbol]
+	i := sort.Search(len(history), func(i int) bool { return history[i].FundingTime > fundingTime })
+	if i > 0 {
+		return history[i-1].Rate
+	}
+	return b.cfg.FundingRate
+}
+
+// checkProtection 用K线最高/最低价检查止损止盈（同一根K线同时触发时按止损处理；跳空时按开盘价成交）
+func (b *broker) checkProtection(p *simPosition, bar market.Kline) {
+	if p.side == "long" {
+		switch {
+		case p.stopLoss > 0 && bar.Low <= p.stopLoss:
+			b.closePosition(p, b.exitPrice(p, math.Min(bar.Open, p.stopLoss), false), p.quantity, reasonStopLoss)
+		case p.takeProfit > 0 && bar.High >= p.takeProfit:
+			b.closePosition(p, b.exitPrice(p, math.Max(bar.Open, p.takeProfit), b.cfg.Fill.LimitTakeProfit), p.quantity, reasonTakeProfit)
+		}
+		return
+	}
+	switch {
+	case p.stopLoss > 0 && bar.High >= p.stopLoss:
+		b.closePosition(p, b.exitPrice(p, math.Max(bar.Open, p.stopLoss), false), p.quantity, reasonStopLoss)
+	case p.takeProfit > 0 && bar.Low <= p.takeProfit:
+		b.closePosition(p, b.exitPrice(p, math.Min(bar.Open, p.takeProfit), b.cfg.Fill.LimitTakeProfit), p.quantity, reasonTakeProfit)
+	}
+}
+
+// exitPrice 止损止盈的成交价：市价单加价差和滑点，限价单按触发价成交
+func (b *broker) exitPrice(p *simPosition, price float64, limit bool) float64 {
+	if limit {
+		return price
+	}
+	return b.marketPrice(price, price*p.quantity, p.side == "short")
+}
+
+// closeAll 按最新价格平掉全部持仓
+func (b *broker) closeAll(reason string) {
+	keys := make([]string, 0, len(b.positions))
+	for key := range b.positions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		p := b.positions[key]
+		b.closePosition(p, b.exitPrice(p, b.prices[p.symbol], false), p.quantity, reason)
+	}
+}
+
+// equity 账户净值（钱包余额 + 未实现盈亏）
+func (b *broker) equity() float64 {
+	equity := b.cash
+	for _, p := range b.positions {
+		equity += p.unrealizedPnL(b.prices[p.symbol])
+	}
+	return equity
+}
+
+// unrealizedPnL 按 mark 计算的未实现盈亏
+func (p *simPosition) unrealizedPnL(mark float64) float64 {
+	pnl := (mark - p.entryPrice) * p.quantity
+	if p.side == "short" {
+		return -pnl
+	}
+	return pnl
+}
+
+func positionKey(symbol, side string) string {
+	return symbol + "_" + side
+}
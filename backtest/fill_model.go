@@ -0,0 +1,108 @@
+package backtest
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// FeeSchedule 手续费率（小数，0.0004 = 0.04%）
+type FeeSchedule struct {
+	Maker float64
+	Taker float64
+}
+
+// ExchangeFees 各交易所永续合约的基础档位手续费（Config.Exchange 未单独指定费率时使用）
+var ExchangeFees = map[string]FeeSchedule{
+	"binance":     {Maker: 0.0002, Taker: 0.0005},
+	"okx":         {Maker: 0.0002, Taker: 0.0005},
+	"bybit":       {Maker: 0.0002, Taker: 0.00055},
+	"hyperliquid": {Maker: 0.00015, Taker: 0.00045},
+	"aster":       {Maker: 0.0001, Taker: 0.00035},
+}
+
+// SlippagePoint 滑点曲线上的一个点：订单名义价值达到 NotionalUSD 时的滑点百分比
+type SlippagePoint struct {
+	NotionalUSD float64
+	SlippagePct float64
+}
+
+// FillModel 模拟撮合模型（零值与旧行为一致：按收盘价加 Config.SlippagePct 全部成交）
+type FillModel struct {
+	SpreadBps float64 // 买一卖一价差（基点）：买入按收盘价上浮半个价差、卖出下浮半个价差成交
+
+	// SlippageCurve 按订单名义价值的滑点曲线（相邻点之间线性插值，小于第一个点按第一个点、超过最后一个点按最后一个点）
+	// 为空时使用 Config.SlippagePct
+	SlippageCurve []SlippagePoint
+
+	// MaxParticipationPct 策略市价单每次最多成交当前K线成交额的百分比（0=不限制；K线没有成交额数据时不限制）
+	// 超出部分不成交：开仓按实际成交金额建仓，平仓只平掉成交的数量
+	MaxParticipationPct float64
+
+	LimitTakeProfit bool // 止盈视为挂单限价成交：按止盈价（跳空时按开盘价）成交、不计价差和滑点、收 maker 手续费
+}
+
+// ParseSlippageCurve 解析 "10000:0.02,100000:0.1"（名义价值 USDT:滑点百分比）
+func ParseSlippageCurve(raw string) ([]SlippagePoint, error) {
+	var curve []SlippagePoint
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		notional, slippage, ok := strings.Cut(item, ":")
+		if !ok {
+			return nil, fmt.Errorf("滑点曲线格式错误: %q（应为 名义价值:滑点百分比）", item)
+		}
+		n, err := strconv.ParseFloat(strings.TrimSpace(notional), 64)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("滑点曲线的名义价值无效: %q", notional)
+		}
+		s, err := strconv.ParseFloat(strings.TrimSpace(slippage), 64)
+		if err != nil || s < 0 {
+			return nil, fmt.Errorf("滑点曲线的滑点百分比无效: %q", slippage)
+		}
+		curve = append(curve, SlippagePoint{NotionalUSD: n, SlippagePct: s})
+	}
+	sort.Slice(curve, func(i, j int) bool { return curve[i].NotionalUSD < curve[j].NotionalUSD })
+	return curve, nil
+}
+
+// slippagePct 按名义价值查滑点曲线
+func (m FillModel) slippagePct(notional, flat float64) float64 {
+	curve := m.SlippageCurve
+	if len(curve) == 0 {
+		return flat
+	}
+	if notional <= curve[0].NotionalUSD {
+		return curve[0].SlippagePct
+	}
+	for i := 1; i < len(curve); i++ {
+		lo, hi := curve[i-1], curve[i]
+		if notional <= hi.NotionalUSD {
+			if hi.NotionalUSD == lo.NotionalUSD {
+				return hi.SlippagePct
+			}
+			return lo.SlippagePct + (hi.SlippagePct-lo.SlippagePct)*(notional-lo.NotionalUSD)/(hi.NotionalUSD-lo.NotionalUSD)
+		}
+	}
+	return curve[len(curve)-1].SlippagePct
+}
+
+// marketPrice 市价成交价：半个价差 + 按名义价值的滑点，买入向上、卖出向下
+func (b *broker) marketPrice(price, notional float64, buy bool) float64 {
+	pct := b.cfg.Fill.SpreadBps/200 + b.cfg.Fill.slippagePct(notional, b.cfg.SlippagePct)
+	if buy {
+		return price * (1 + pct/100)
+	}
+	return price * (1 - pct/100)
+}
+
+// liquidityUSD 当前K线允许策略市价单成交的最大名义价值（0=不限制）
+func (b *broker) liquidityUSD(symbol string) float64 {
+	if b.cfg.Fill.MaxParticipationPct <= 0 {
+		return 0
+	}
+	return b.bars[symbol].QuoteVolume * b.cfg.Fill.MaxParticipationPct / 100
+}
@@ -0,0 +1,123 @@
+package backtest
+
+import (
+	"context"
+	"testing"
+
+	"nofx/market"
+	"nofx/strategy"
+)
+
+func TestSlippageCurve(t *testing.T) {
+	curve, err := ParseSlippageCurve("100000:0.1, 10000:0.02")
+	if err != nil {
+		t.Fatalf("解析滑点曲线失败: %v", err)
+	}
+	m := FillModel{SlippageCurve: curve}
+	tests := []struct {
+		notional float64
+		want     float64
+	}{
+		{notional: 1000, want: 0.02},    // 小于第一个点
+		{notional: 10000, want: 0.02},   // 正好在点上
+		{notional: 55000, want: 0.06},   // 线性插值
+		{notional: 1000000, want: 0.10}, // 超过最后一个点
+	}
+	for _, tt := range tests {
+		if got := m.slippagePct(tt.notional, 0.5); !approx(got, tt.want) {
+			t.Errorf("名义价值 %.0f 的滑点 = %.4f，期望 %.4f", tt.notional, got, tt.want)
+		}
+	}
+	if got := (FillModel{}).slippagePct(55000, 0.5); got != 0.5 {
+		t.Errorf("没有滑点曲线时应使用固定滑点，得到 %v", got)
+	}
+	if _, err := ParseSlippageCurve("10000=0.02"); err == nil {
+		t.Error("格式错误应返回错误")
+	}
+}
+
+func TestExchangeFeeDefaults(t *testing.T) {
+	cfg := withDefaults(Config{Exchange: "Hyperliquid"})
+	if cfg.TakerFeeRate != 0.00045 || cfg.MakerFeeRate != 0.00015 {
+		t.Errorf("应使用交易所默认费率: taker=%v maker=%v", cfg.TakerFeeRate, cfg.MakerFeeRate)
+	}
+	cfg = withDefaults(Config{Exchange: "unknown", MakerFeeRate: -1})
+	if cfg.TakerFeeRate != defaultTakerFeeRate || cfg.MakerFeeRate != 0 {
+		t.Errorf("未知交易所应使用默认 taker 费率、maker 免手续费: taker=%v maker=%v", cfg.TakerFeeRate, cfg.MakerFeeRate)
+	}
+}
+
+func TestRunLimitTakeProfitWithSpread(t *testing.T) {
+	closes := []float64{100, 100, 100, 101, 102, 103, 104, 106, 105}
+	opened := false
+	s := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		if opened || len(snap.Klines["15m"]) < 3 {
+			return nil
+		}
+		opened = true
+		return []strategy.Decision{{Action: "open_long", Leverage: 2, PositionSizeUSD: 1000, StopLoss: 95, TakeProfit: 105}}
+	})
+
+	result, err := Run(Config{
+		Symbols:      []string{"BTCUSDT"},
+		Klines:       map[string]map[string][]market.Kline{"BTCUSDT": {"15m": makeKlines(0, closes)}},
+		TakerFeeRate: 0.001,
+		MakerFeeRate: 0.0002,
+		Fill:         FillModel{SpreadBps: 10, LimitTakeProfit: true},
+		Strategies:   []strategy.Strategy{s},
+	})
+	if err != nil {
+		t.Fatalf("Run 失败: %v", err)
+	}
+	if len(result.Trades) != 1 {
+		t.Fatalf("期望 1 笔交易，得到 %d: %+v", len(result.Trades), result.Trades)
+	}
+	tr := result.Trades[0]
+	entry := 100 * 1.0005 // 收盘价 + 半个价差（5bp）
+	qty := 1000 / entry
+	wantFees := 1000*0.001 + 105*qty*0.0002 // 开仓 taker，限价止盈 maker
+	if tr.Reason != reasonTakeProfit || !approx(tr.EntryPrice, entry) || !approx(tr.ExitPrice, 105) {
+		t.Errorf("unexpected trade: %+v", tr)
+	}
+	if !approx(tr.Fees, wantFees) {
+		t.Errorf("fees=%v，期望 %v", tr.Fees, wantFees)
+	}
+}
+
+func TestRunPartialFills(t *testing.T) {
+	klines := makeKlines(0, []float64{100, 100, 100, 100, 100})
+	for i := range klines {
+		klines[i].QuoteVolume = 10000
+	}
+	klines[3].QuoteVolume = 4000
+	step := 0
+	s := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		step++
+		switch step {
+		case 1:
+			return []strategy.Decision{{Action: "open_long", Leverage: 1, PositionSizeUSD: 1000}}
+		case 4:
+			return []strategy.Decision{{Action: "close_long"}}
+		}
+		return nil
+	})
+
+	result, err := Run(Config{
+		Symbols:      []string{"BTCUSDT"},
+		Klines:       map[string]map[string][]market.Kline{"BTCUSDT": {"15m": klines}},
+		TakerFeeRate: -1,
+		Fill:         FillModel{MaxParticipationPct: 5},
+		Strategies:   []strategy.Strategy{s},
+	})
+	if err != nil {
+		t.Fatalf("Run 失败: %v", err)
+	}
+	// 开仓最多成交 10000×5% = 500 USDT（5 个），平仓K线最多成交 200 USDT（2 个），其余回测结束时平掉
+	if result.PartialFills != 2 || len(result.Trades) != 2 {
+		t.Fatalf("partial=%d trades=%+v", result.PartialFills, result.Trades)
+	}
+	if !approx(result.Trades[0].Quantity, 2) || result.Trades[0].Reason != reasonSignal ||
+		!approx(result.Trades[1].Quantity, 3) || result.Trades[1].Reason != reasonEndOfData {
+		t.Errorf("unexpected trades: %+v", result.Trades)
+	}
+}
@@ -0,0 +1,91 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// maxDrawdownPct 权益曲线的最大回撤（百分比）
+func maxDrawdownPct(curve []EquityPoint) float64 {
+	peak, maxDD := 0.0, 0.0
+	for _, p := range curve {
+		if p.Equity > peak {
+			peak = p.Equity
+		}
+		if peak > 0 {
+			maxDD = math.Max(maxDD, (peak-p.Equity)/peak*100)
+		}
+	}
+	return maxDD
+}
+
+// winRate 盈利交易占比（百分比）
+func winRate(trades []Trade) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+	wins := 0
+	for _, t := range trades {
+		if t.PnL > 0 {
+			wins++
+		}
+	}
+	return float64(wins) / float64(len(trades)) * 100
+}
+
+// sharpeRatio 年化夏普比率（按每根K线的权益收益率计算，无风险利率取 0）
+func sharpeRatio(curve []EquityPoint, interval time.Duration) float64 {
+	if len(curve) < 3 {
+		return 0
+	}
+	returns := make([]float64, 0, len(curve)-1)
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Equity > 0 {
+			returns = append(returns, curve[i].Equity/curve[i-1].Equity-1)
+		}
+	}
+	if len(returns) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, r := range returns {
+		mean += r
+	}
+	mean /= float64(len(returns))
+	variance := 0.0
+	for _, r := range returns {
+		variance += (r - mean) * (r - mean)
+	}
+	std := math.Sqrt(variance / float64(len(returns)-1))
+	if std == 0 {
+		return 0
+	}
+	periodsPerYear := float64(365*24*time.Hour) / float64(interval)
+	return mean / std * math.Sqrt(periodsPerYear)
+}
+
+// WriteTradesCSV 导出逐笔交易记录
+func (r *Result) WriteTradesCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"symbol", "side", "entry_time", "exit_time", "entry_price", "exit_price", "quantity", "fees", "funding", "pnl", "reason"}); err != nil {
+		return err
+	}
+	f := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, t := range r.Trades {
+		row := []string{
+			t.Symbol, t.Side,
+			t.EntryTime.Format(time.RFC3339), t.ExitTime.Format(time.RFC3339),
+			f(t.EntryPrice), f(t.ExitPrice), f(t.Quantity),
+			f(t.Fees), f(t.Funding), f(t.PnL), t.Reason,
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
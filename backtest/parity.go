@@ -0,0 +1,582 @@
+package backtest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/eventbus"
+	"nofx/market"
+	"nofx/strategy"
+)
+
+// 对账默认参数
+const (
+	defaultParityPriceTolerancePct = 0.1
+	defaultParityQtyTolerancePct   = 1.0
+)
+
+// Fill 一笔成交（实盘来自 eventbus.OrderFilled，影子模拟来自回测撮合）
+type Fill struct {
+	Time     time.Time `json:"time"`
+	Symbol   string    `json:"symbol"`
+	Side     string    `json:"side"`   // long / short
+	Action   string    `json:"action"` // open / close
+	Price    float64   `json:"price"`
+	Quantity float64   `json:"quantity"`
+}
+
+// ParityConfig 实盘-影子模拟对账配置
+type ParityConfig struct {
+	TraderID  string // 实盘交易员 ID（只统计该交易员的 OrderFilled 事件，空=不过滤）
+	Symbols   []string
+	Interval  string   // 影子模拟撮合使用的K线周期（默认 15m）
+	Intervals []string // 提供给策略的K线周期（默认只有 Interval，必须包含 Interval）
+	Lookback  int      // 每个周期的K线数量（默认 200）
+
+	// NewStrategies 创建策略实例：实盘和影子模拟各调用一次（策略可能有内部状态，不能共用实例）
+	NewStrategies func() ([]strategy.Strategy, error)
+	Risk          strategy.RiskManager
+
+	Live        strategy.Executor      // 实盘（或模拟盘）执行器，通常为 *trader.AutoTrader
+	LiveAccount strategy.AccountSource // 可选：实盘账户（同时决定影子模拟的初始资金）
+	Feed        strategy.Feed          // 可选：K线数据源（nil=实时行情）
+	Bus         *eventbus.Bus          // 可选：订阅实盘成交的事件总线（nil=eventbus.Default）
+
+	InitialBalance float64   // 影子模拟初始资金（0=实盘账户净值，无实盘账户时 10000 USDT）
+	Exchange       string    // 影子模拟按该交易所的默认费率收手续费（见 ExchangeFees）
+	TakerFeeRate   float64   // 影子模拟手续费率（0=交易所默认，<0=免手续费）
+	SlippagePct    float64   // 影子模拟滑点百分比
+	Fill           FillModel // 影子模拟撮合模型
+
+	PriceTolerancePct float64 // 成交价偏差超过该百分比时报告（默认 0.1）
+	QtyTolerancePct   float64 // 成交数量偏差超过该百分比时报告（默认 1）
+	ReportDir         string  // 每日对账报告目录（parity-YYYY-MM-DD.json，空=只写日志）
+}
+
+// DecisionMismatch 同一周期实盘与影子模拟的决策不一致
+type DecisionMismatch struct {
+	Time     time.Time `json:"time"`
+	Strategy string    `json:"strategy"`
+	Symbol   string    `json:"symbol"`
+	Action   string    `json:"action"`
+	Live     string    `json:"live"`   // executed / rejected: ... / failed: ... / none（没有给出该决策）
+	Shadow   string    `json:"shadow"` // 同上
+}
+
+// FillDrift 同一周期同一币种同方向的成交偏差（按数量加权均价比较）
+type FillDrift struct {
+	Time          time.Time `json:"time"`
+	Symbol        string    `json:"symbol"`
+	Side          string    `json:"side"`
+	Action        string    `json:"action"`
+	LivePrice     float64   `json:"live_price"`
+	ShadowPrice   float64   `json:"shadow_price"`
+	PriceDriftPct float64   `json:"price_drift_pct"` // (实盘 - 影子) / 影子
+	LiveQty       float64   `json:"live_qty"`
+	ShadowQty     float64   `json:"shadow_qty"`
+	QtyDriftPct   float64   `json:"qty_drift_pct"`
+	Missing       string    `json:"missing,omitempty"` // live / shadow：只有一边成交
+}
+
+// ParityReport 对账报告
+type ParityReport struct {
+	From               time.Time          `json:"from"`
+	To                 time.Time          `json:"to"`
+	Cycles             int                `json:"cycles"`
+	LiveDecisions      int                `json:"live_decisions"`   // 非 hold/wait 决策数
+	ShadowDecisions    int                `json:"shadow_decisions"` // 同上
+	MatchedFills       int                `json:"matched_fills"`
+	MaxPriceDriftPct   float64            `json:"max_price_drift_pct"` // 绝对值
+	AvgPriceDriftPct   float64            `json:"avg_price_drift_pct"` // 绝对值平均
+	DecisionMismatches []DecisionMismatch `json:"decision_mismatches"`
+	FillDrifts         []FillDrift        `json:"fill_drifts"` // 超出容差或只有一边成交
+}
+
+// Clean 没有决策差异和超出容差的成交偏差
+func (r *ParityReport) Clean() bool {
+	return len(r.DecisionMismatches) == 0 && len(r.FillDrifts) == 0
+}
+
+// Summary 对账摘要
+func (r *ParityReport) Summary() string {
+	return fmt.Sprintf("%d 个周期 | 决策 实盘 %d / 影子 %d，不一致 %d | 成交 %d 笔，价格偏差 平均 %.3f%% 最大 %.3f%%，超出容差 %d",
+		r.Cycles, r.LiveDecisions, r.ShadowDecisions, len(r.DecisionMismatches),
+		r.MatchedFills, r.AvgPriceDriftPct, r.MaxPriceDriftPct, len(r.FillDrifts))
+}
+
+// parityCycle 一个周期的实盘与影子模拟结果
+type parityCycle struct {
+	time        time.Time
+	live        []strategy.Result
+	shadow      []strategy.Result
+	liveFills   []Fill
+	shadowFills []Fill
+}
+
+// Parity 实盘-影子模拟对账：同一策略同时在实盘和回测撮合器中运行，两边使用同一批K线，
+// 影子模拟只能看到已收盘的K线，决策不一致通常说明策略用到了未收盘的数据（lookahead），
+// 成交偏差说明回测的滑点/手续费模型与实盘有偏离。每个 UTC 日结束时输出前一天的对账报告
+type Parity struct {
+	cfg         ParityConfig
+	live        *strategy.Runner
+	shadow      *strategy.Runner
+	broker      *broker
+	interval    time.Duration
+	symbols     map[string]bool
+	fills       <-chan eventbus.Event
+	unsubscribe func()
+	now         func() time.Time
+
+	// 以下字段只在 RunOnce 中使用（同一时间只有一个周期在执行）
+	cycle       time.Time
+	snapshots   map[string]*market.MultiTimeframeKlines // 本周期实盘获取的K线
+	lastBar     map[string]int64                        // 影子模拟已撮合到的K线收盘时间
+	shadowFills []Fill
+	day         time.Time // 当前报告日（UTC 零点）
+
+	mu     sync.Mutex
+	cycles []parityCycle
+}
+
+// NewParity 创建对账器（订阅实盘成交事件，用完后调用 Close）
+func NewParity(cfg ParityConfig) (*Parity, error) {
+	if cfg.NewStrategies == nil {
+		return nil, fmt.Errorf("未配置策略")
+	}
+	if cfg.Live == nil {
+		return nil, fmt.Errorf("未配置实盘执行器")
+	}
+	if cfg.Interval == "" {
+		cfg.Interval = defaultInterval
+	}
+	if len(cfg.Intervals) == 0 {
+		cfg.Intervals = []string{cfg.Interval}
+	}
+	if cfg.Lookback <= 0 {
+		cfg.Lookback = defaultLookback
+	}
+	if cfg.PriceTolerancePct <= 0 {
+		cfg.PriceTolerancePct = defaultParityPriceTolerancePct
+	}
+	if cfg.QtyTolerancePct <= 0 {
+		cfg.QtyTolerancePct = defaultParityQtyTolerancePct
+	}
+	if cfg.Feed == nil {
+		cfg.Feed = market.GetMultiTimeframe
+	}
+	if cfg.Bus == nil {
+		cfg.Bus = eventbus.Default
+	}
+	interval, ok := market.TimeframeDuration(cfg.Interval)
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", cfg.Interval)
+	}
+	included := false
+	for _, iv := range cfg.Intervals {
+		included = included || iv == cfg.Interval
+	}
+	if !included {
+		return nil, fmt.Errorf("Intervals 必须包含撮合周期 %s", cfg.Interval)
+	}
+	if cfg.InitialBalance <= 0 && cfg.LiveAccount != nil {
+		account, _, err := cfg.LiveAccount.StrategyAccount()
+		if err != nil {
+			return nil, fmt.Errorf("获取实盘账户失败: %w", err)
+		}
+		cfg.InitialBalance = account.TotalEquity
+	}
+
+	liveStrategies, err := cfg.NewStrategies()
+	if err != nil {
+		return nil, err
+	}
+	shadowStrategies, err := cfg.NewStrategies()
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Parity{
+		cfg:       cfg,
+		interval:  interval,
+		symbols:   make(map[string]bool, len(cfg.Symbols)),
+		now:       time.Now,
+		snapshots: make(map[string]*market.MultiTimeframeKlines),
+		lastBar:   make(map[string]int64),
+	}
+	for _, symbol := range cfg.Symbols {
+		p.symbols[symbol] = true
+	}
+	p.broker = newBroker(withDefaults(Config{
+		Symbols:        cfg.Symbols,
+		Interval:       cfg.Interval,
+		InitialBalance: cfg.InitialBalance,
+		Exchange:       cfg.Exchange,
+		TakerFeeRate:   cfg.TakerFeeRate,
+		SlippagePct:    cfg.SlippagePct,
+		Fill:           cfg.Fill,
+	}))
+	p.broker.onFill = func(f Fill) { p.shadowFills = append(p.shadowFills, f) }
+
+	clock := func() time.Time { return p.cycle }
+	p.live, err = strategy.NewRunner(strategy.RunnerConfig{
+		Symbols:    cfg.Symbols,
+		Intervals:  cfg.Intervals,
+		Limit:      cfg.Lookback,
+		Strategies: liveStrategies,
+		Risk:       cfg.Risk,
+		Executor:   cfg.Live,
+		Account:    cfg.LiveAccount,
+		Feed:       p.recordingFeed,
+		Clock:      clock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.shadow, err = strategy.NewRunner(strategy.RunnerConfig{
+		Symbols:    cfg.Symbols,
+		Intervals:  cfg.Intervals,
+		Limit:      cfg.Lookback,
+		Strategies: shadowStrategies,
+		Risk:       cfg.Risk,
+		Executor:   p.broker,
+		Account:    p.broker,
+		Feed:       p.shadowFeed,
+		Clock:      clock,
+	})
+	if err != nil {
+		return nil, err
+	}
+	p.fills, p.unsubscribe = cfg.Bus.Subscribe(256, eventbus.TopicOrderFilled)
+	return p, nil
+}
+
+// Close 取消订阅实盘成交事件
+func (p *Parity) Close() {
+	p.unsubscribe()
+}
+
+// Run 每隔 every 执行一轮，跨过 UTC 零点时输出前一天的对账报告，直到 ctx 取消
+func (p *Parity) Run(ctx context.Context, every time.Duration) error {
+	defer p.Close()
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if err := p.RunOnce(ctx); err != nil {
+			log.Printf("⚠️  对账周期执行失败: %v", err)
+		}
+		p.rollover(p.now())
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮：实盘运行策略（记录K线和成交）→ 影子模拟用同一批K线撮合到最新收盘 → 影子运行策略
+func (p *Parity) RunOnce(ctx context.Context) error {
+	p.drainFills() // 周期之外的实盘成交（如 AI 决策、止损单）不参与对账
+	p.cycle = p.now()
+	p.snapshots = make(map[string]*market.MultiTimeframeKlines, len(p.cfg.Symbols))
+
+	live, err := p.live.RunOnce(ctx)
+	if err != nil {
+		return fmt.Errorf("实盘策略执行失败: %w", err)
+	}
+	liveFills := p.drainFills()
+
+	p.stepShadow()
+	p.shadowFills = nil
+	shadow, err := p.shadow.RunOnce(ctx)
+	if err != nil {
+		return fmt.Errorf("影子模拟执行失败: %w", err)
+	}
+
+	p.mu.Lock()
+	p.cycles = append(p.cycles, parityCycle{time: p.cycle, live: live, shadow: shadow, liveFills: liveFills, shadowFills: p.shadowFills})
+	p.mu.Unlock()
+	return nil
+}
+
+// recordingFeed 实盘K线数据源：记录每个币种的K线供影子模拟使用
+func (p *Parity) recordingFeed(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error) {
+	series, err := p.cfg.Feed(symbol, intervals, limit)
+	if err != nil {
+		return nil, err
+	}
+	p.snapshots[symbol] = series
+	return series, nil
+}
+
+// shadowFeed 影子模拟数据源：实盘本周期的同一批K线，只保留周期时间之前已收盘的部分
+func (p *Parity) shadowFeed(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error) {
+	recorded, ok := p.snapshots[symbol]
+	if !ok {
+		return nil, fmt.Errorf("实盘本周期没有 %s 的K线", symbol)
+	}
+	snapshot := &market.MultiTimeframeKlines{
+		Symbol: recorded.Symbol,
+		Series: make(map[string][]market.Kline, len(intervals)),
+		Errors: make(map[string]error),
+	}
+	for _, interval := range intervals {
+		klines := closedKlines(recorded.Series[interval], p.cycle)
+		if limit > 0 && len(klines) > limit {
+			klines = klines[len(klines)-limit:]
+		}
+		snapshot.Series[interval] = klines
+	}
+	return snapshot, nil
+}
+
+// stepShadow 影子模拟撮合到本周期已收盘的最新K线（结算资金费、检查止损止盈、更新价格）
+func (p *Parity) stepShadow() {
+	p.broker.now = p.cycle.UnixMilli()
+	for _, symbol := range p.cfg.Symbols {
+		series, ok := p.snapshots[symbol]
+		if !ok {
+			continue
+		}
+		klines := closedKlines(series.Series[p.cfg.Interval], p.cycle)
+		if len(klines) == 0 {
+			continue
+		}
+		bar := klines[len(klines)-1]
+		last, seen := p.lastBar[symbol]
+		if seen && bar.CloseTime <= last {
+			continue
+		}
+		if !seen {
+			last = bar.CloseTime - p.interval.Milliseconds()
+		}
+		p.broker.onBar(symbol, bar, last)
+		p.lastBar[symbol] = bar.CloseTime
+	}
+}
+
+// closedKlines 在 now 之前已收盘的K线
+func closedKlines(klines []market.Kline, now time.Time) []market.Kline {
+	end := sort.Search(len(klines), func(i int) bool { return klines[i].CloseTime >= now.UnixMilli() })
+	return klines[:end]
+}
+
+// drainFills 取出已收到的实盘成交（只保留本交易员、对账币种的成交）
+func (p *Parity) drainFills() []Fill {
+	var fills []Fill
+	for {
+		select {
+		case ev, ok := <-p.fills:
+			if !ok {
+				return fills
+			}
+			f, isFill := ev.(eventbus.OrderFilled)
+			if !isFill || !p.symbols[f.Symbol] || (p.cfg.TraderID != "" && f.TraderID != p.cfg.TraderID) {
+				continue
+			}
+			fills = append(fills, Fill{Time: f.Time, Symbol: f.Symbol, Side: f.Side, Action: f.Action, Price: f.Price, Quantity: f.Quantity})
+		default:
+			return fills
+		}
+	}
+}
+
+// rollover 跨过 UTC 零点时输出前一天的报告并丢弃前一天的周期
+func (p *Parity) rollover(now time.Time) {
+	day := now.UTC().Truncate(24 * time.Hour)
+	if p.day.IsZero() {
+		p.day = day
+		return
+	}
+	if !day.After(p.day) {
+		return
+	}
+	report := p.Report(p.day, day)
+	if err := p.writeReport(report); err != nil {
+		log.Printf("⚠️  保存对账报告失败: %v", err)
+	}
+
+	p.mu.Lock()
+	kept := p.cycles[:0]
+	for _, c := range p.cycles {
+		if !c.time.Before(day) {
+			kept = append(kept, c)
+		}
+	}
+	p.cycles = kept
+	p.mu.Unlock()
+	p.day = day
+}
+
+// writeReport 记录报告摘要，配置了 ReportDir 时写入 parity-YYYY-MM-DD.json
+func (p *Parity) writeReport(report *ParityReport) error {
+	date := report.From.UTC().Format("2006-01-02")
+	if report.Clean() {
+		log.Printf("🔁 %s 实盘-影子对账一致: %s", date, report.Summary())
+	} else {
+		log.Printf("⚠️  %s 实盘-影子对账存在差异: %s", date, report.Summary())
+	}
+	if p.cfg.ReportDir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(p.cfg.ReportDir, 0755); err != nil {
+		return fmt.Errorf("创建对账报告目录失败: %w", err)
+	}
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化对账报告失败: %w", err)
+	}
+	return os.WriteFile(filepath.Join(p.cfg.ReportDir, "parity-"+date+".json"), data, 0644)
+}
+
+// Report 对比 [from, to) 内各周期的决策和成交（零值=不限制）
+func (p *Parity) Report(from, to time.Time) *ParityReport {
+	p.mu.Lock()
+	cycles := append([]parityCycle(nil), p.cycles...)
+	p.mu.Unlock()
+
+	report := &ParityReport{From: from, To: to}
+	var driftSum float64
+	for _, c := range cycles {
+		if (!from.IsZero() && c.time.Before(from)) || (!to.IsZero() && !c.time.Before(to)) {
+			continue
+		}
+		report.Cycles++
+
+		live, liveOrder := decisionOutcomes(c.live)
+		shadow, shadowOrder := decisionOutcomes(c.shadow)
+		report.LiveDecisions += len(live)
+		report.ShadowDecisions += len(shadow)
+		for _, key := range mergeKeys(liveOrder, shadowOrder) {
+			l, s := live[key], shadow[key]
+			if outcomeKind(l) == outcomeKind(s) {
+				continue
+			}
+			parts := strings.SplitN(key, "|", 3)
+			report.DecisionMismatches = append(report.DecisionMismatches, DecisionMismatch{
+				Time: c.time, Strategy: parts[0], Symbol: parts[1], Action: parts[2], Live: orNone(l), Shadow: orNone(s),
+			})
+		}
+
+		liveFills, liveFillOrder := aggregateFills(c.liveFills)
+		shadowFills, shadowFillOrder := aggregateFills(c.shadowFills)
+		for _, key := range mergeKeys(liveFillOrder, shadowFillOrder) {
+			l, okLive := liveFills[key]
+			s, okShadow := shadowFills[key]
+			drift := FillDrift{Time: c.time, LivePrice: l.Price, ShadowPrice: s.Price, LiveQty: l.Quantity, ShadowQty: s.Quantity}
+			ref := s
+			if !okShadow {
+				ref = l
+			}
+			drift.Symbol, drift.Side, drift.Action = ref.Symbol, ref.Side, ref.Action
+			switch {
+			case !okLive:
+				drift.Missing = "live"
+			case !okShadow:
+				drift.Missing = "shadow"
+			default:
+				report.MatchedFills++
+				drift.PriceDriftPct = pctDiff(l.Price, s.Price)
+				drift.QtyDriftPct = pctDiff(l.Quantity, s.Quantity)
+				abs := math.Abs(drift.PriceDriftPct)
+				driftSum += abs
+				report.MaxPriceDriftPct = math.Max(report.MaxPriceDriftPct, abs)
+				if abs <= p.cfg.PriceTolerancePct && math.Abs(drift.QtyDriftPct) <= p.cfg.QtyTolerancePct {
+					continue
+				}
+			}
+			report.FillDrifts = append(report.FillDrifts, drift)
+		}
+	}
+	if report.MatchedFills > 0 {
+		report.AvgPriceDriftPct = driftSum / float64(report.MatchedFills)
+	}
+	return report
+}
+
+// decisionOutcomes 各决策（策略|币种|动作）的处理结果，忽略 hold/wait
+func decisionOutcomes(results []strategy.Result) (map[string]string, []string) {
+	outcomes := make(map[string]string, len(results))
+	var order []string
+	for _, r := range results {
+		if r.Decision.Action == "hold" || r.Decision.Action == "wait" {
+			continue
+		}
+		key := r.Strategy + "|" + r.Decision.Symbol + "|" + r.Decision.Action
+		if _, ok := outcomes[key]; ok {
+			continue
+		}
+		outcome := "executed"
+		switch {
+		case r.Rejected:
+			outcome = "rejected: " + r.Err.Error()
+		case r.Err != nil:
+			outcome = "failed: " + r.Err.Error()
+		}
+		outcomes[key] = outcome
+		order = append(order, key)
+	}
+	return outcomes, order
+}
+
+// outcomeKind 处理结果的类别（原因文字不同不算不一致）
+func outcomeKind(outcome string) string {
+	kind, _, _ := strings.Cut(outcome, ":")
+	return kind
+}
+
+func orNone(outcome string) string {
+	if outcome == "" {
+		return "none"
+	}
+	return outcome
+}
+
+// aggregateFills 按 币种|方向|开平 汇总成交（数量加权均价）
+func aggregateFills(fills []Fill) (map[string]Fill, []string) {
+	totals := make(map[string]Fill, len(fills))
+	var order []string
+	for _, f := range fills {
+		key := f.Symbol + "|" + f.Side + "|" + f.Action
+		t, ok := totals[key]
+		if !ok {
+			order = append(order, key)
+			t = Fill{Time: f.Time, Symbol: f.Symbol, Side: f.Side, Action: f.Action}
+		}
+		if qty := t.Quantity + f.Quantity; qty > 0 {
+			t.Price = (t.Price*t.Quantity + f.Price*f.Quantity) / qty
+			t.Quantity = qty
+		}
+		totals[key] = t
+	}
+	return totals, order
+}
+
+// mergeKeys 合并两组键（保持首次出现顺序）
+func mergeKeys(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for _, key := range append(append([]string(nil), a...), b...) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// pctDiff (a - b) / b 百分比（b 为 0 时为 0）
+func pctDiff(a, b float64) float64 {
+	if b == 0 {
+		return 0
+	}
+	return (a - b) / b * 100
+}
@@ -0,0 +1,138 @@
+package backtest
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/eventbus"
+	"nofx/market"
+	"nofx/strategy"
+)
+
+// fillingExecutor 模拟实盘：每个开仓决策按固定价格发布 OrderFilled
+type fillingExecutor struct {
+	bus   *eventbus.Bus
+	price float64
+}
+
+func (e *fillingExecutor) ExecuteDecision(d strategy.Decision) error {
+	e.bus.Publish(eventbus.OrderFilled{TraderID: "t1", Symbol: d.Symbol, Side: "long", Action: "open",
+		Price: e.price, Quantity: d.PositionSizeUSD / e.price, Time: time.Now()})
+	return nil
+}
+
+// parityFixture 前三根K线收盘价 100，第四根尚未收盘、当前价 110；周期时间在第四根K线中间
+func parityFixture(t *testing.T, s strategy.Strategy, livePrice float64) (*Parity, *eventbus.Bus, time.Time) {
+	t.Helper()
+	klines := makeKlines(0, []float64{100, 100, 100, 110})
+	now := time.UnixMilli(3*bar15m + 5*60*1000)
+	bus := eventbus.New()
+	p, err := NewParity(ParityConfig{
+		TraderID:      "t1",
+		Symbols:       []string{"BTCUSDT"},
+		NewStrategies: func() ([]strategy.Strategy, error) { return []strategy.Strategy{s}, nil },
+		Live:          &fillingExecutor{bus: bus, price: livePrice},
+		Bus:           bus,
+		TakerFeeRate:  -1,
+		Feed: func(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error) {
+			return &market.MultiTimeframeKlines{Symbol: symbol, Series: map[string][]market.Kline{"15m": klines}}, nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewParity 失败: %v", err)
+	}
+	t.Cleanup(p.Close)
+	p.now = func() time.Time { return now }
+	return p, bus, now
+}
+
+func TestParityDetectsLookahead(t *testing.T) {
+	// 用最新一根K线（可能未收盘）的收盘价做判断：实盘看到 110 开仓，影子模拟只看到 100
+	peek := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		if last, ok := snap.Latest("15m"); ok && last.Close >= 110 {
+			return []strategy.Decision{{Action: "open_long", PositionSizeUSD: 1000, Leverage: 1}}
+		}
+		return nil
+	})
+	p, _, _ := parityFixture(t, peek, 110)
+	if err := p.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce 失败: %v", err)
+	}
+
+	report := p.Report(time.Time{}, time.Time{})
+	if report.Clean() || len(report.DecisionMismatches) != 1 {
+		t.Fatalf("应报告决策不一致: %+v", report)
+	}
+	m := report.DecisionMismatches[0]
+	if m.Action != "open_long" || m.Live != "executed" || m.Shadow != "none" {
+		t.Errorf("不一致内容错误: %+v", m)
+	}
+	if len(report.FillDrifts) != 1 || report.FillDrifts[0].Missing != "shadow" {
+		t.Errorf("应报告影子模拟缺少成交: %+v", report.FillDrifts)
+	}
+}
+
+func TestParityFillDrift(t *testing.T) {
+	always := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		if _, open := snap.Position("long"); open {
+			return nil
+		}
+		return []strategy.Decision{{Action: "open_long", PositionSizeUSD: 1000, Leverage: 1}}
+	})
+	p, bus, _ := parityFixture(t, always, 100.5)
+
+	// 周期之外的成交（如 AI 决策）和其他交易员的成交不参与对账
+	bus.Publish(eventbus.OrderFilled{TraderID: "t1", Symbol: "BTCUSDT", Side: "short", Action: "open", Price: 1, Quantity: 1})
+	if err := p.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce 失败: %v", err)
+	}
+	bus.Publish(eventbus.OrderFilled{TraderID: "t2", Symbol: "BTCUSDT", Side: "long", Action: "open", Price: 1, Quantity: 1})
+
+	report := p.Report(time.Time{}, time.Time{})
+	if len(report.DecisionMismatches) != 0 {
+		t.Errorf("决策应一致: %+v", report.DecisionMismatches)
+	}
+	if report.MatchedFills != 1 || !approx(report.MaxPriceDriftPct, 0.5) {
+		t.Fatalf("成交对比错误: %+v", report)
+	}
+	if len(report.FillDrifts) != 1 {
+		t.Fatalf("价格偏差 0.5%% 超过默认容差应被报告: %+v", report.FillDrifts)
+	}
+	if d := report.FillDrifts[0]; !approx(d.ShadowPrice, 100) || d.Missing != "" || d.QtyDriftPct >= 0 {
+		t.Errorf("偏差内容错误: %+v", d)
+	}
+
+	p.cfg.PriceTolerancePct = 1
+	if report := p.Report(time.Time{}, time.Time{}); !report.Clean() {
+		t.Errorf("偏差在容差内时报告应一致: %+v", report.FillDrifts)
+	}
+}
+
+func TestParityNightlyReport(t *testing.T) {
+	always := strategy.Func(func(ctx context.Context, snap strategy.MarketSnapshot) []strategy.Decision {
+		return nil
+	})
+	p, _, now := parityFixture(t, always, 100)
+	p.cfg.ReportDir = t.TempDir()
+
+	if err := p.RunOnce(context.Background()); err != nil {
+		t.Fatalf("RunOnce 失败: %v", err)
+	}
+	p.rollover(now)
+	p.rollover(now.Add(time.Hour))
+	if entries, _ := os.ReadDir(p.cfg.ReportDir); len(entries) != 0 {
+		t.Fatalf("同一天内不应输出报告: %v", entries)
+	}
+
+	p.rollover(now.Add(24 * time.Hour))
+	path := filepath.Join(p.cfg.ReportDir, "parity-"+now.UTC().Format("2006-01-02")+".json")
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("跨过零点后应写入前一天的报告: %v", err)
+	}
+	if report := p.Report(time.Time{}, time.Time{}); report.Cycles != 0 {
+		t.Errorf("已输出报告的周期应被丢弃，剩余 %d", report.Cycles)
+	}
+}
@@ -0,0 +1,372 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/strategy"
+)
+
+// 参数排名指标
+const (
+	RankBySharpe   = "sharpe"   // 夏普比率（默认）
+	RankByReturn   = "return"   // 收益率
+	RankByDrawdown = "drawdown" // 最大回撤（越小越好）
+)
+
+// Params 一组策略参数
+type Params map[string]float64
+
+// String 按参数名排序的 "name=value" 形式
+func (p Params) String() string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + strconv.FormatFloat(p[name], 'f', -1, 64)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ParamGrid 参数网格：参数名 -> 候选值
+type ParamGrid map[string][]float64
+
+// Combinations 展开为全部参数组合（按参数名排序，结果顺序确定）
+func (g ParamGrid) Combinations() []Params {
+	names := make([]string, 0, len(g))
+	for name := range g {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	combos := []Params{{}}
+	for _, name := range names {
+		var next []Params
+		for _, combo := range combos {
+			for _, v := range g[name] {
+				p := make(Params, len(combo)+1)
+				for k, cv := range combo {
+					p[k] = cv
+				}
+				p[name] = v
+				next = append(next, p)
+			}
+		}
+		combos = next
+	}
+	return combos
+}
+
+// StrategyFactory 按参数创建策略（每次回测都重新创建，避免并发回测共享策略内部状态）
+type StrategyFactory func(p Params) ([]strategy.Strategy, error)
+
+// SweepConfig 参数扫描 / 前推分析配置
+type SweepConfig struct {
+	Base    Config // 基础回测配置（Klines 为完整历史，Strategies/Start/End 会被忽略）
+	Grid    ParamGrid
+	Factory StrategyFactory
+
+	// 前推窗口（单位：Interval K线根数）：在 TrainBars 上选参，在紧随其后的 TestBars 上检验
+	TrainBars int // 0=不做前推，整段数据回测每组参数
+	TestBars  int // 测试窗口长度（TrainBars>0 时必须 >0）
+	StepBars  int // 窗口滚动步长（0=TestBars）
+
+	Workers int    // 并发回测数（0=CPU 核数）
+	RankBy  string // RankBySharpe（默认）/ RankByReturn / RankByDrawdown
+}
+
+// Window 一个前推窗口
+type Window struct {
+	TrainStart time.Time `json:"train_start"`
+	TrainEnd   time.Time `json:"train_end"`
+	TestStart  time.Time `json:"test_start"`
+	TestEnd    time.Time `json:"test_end"`
+}
+
+// ParamResult 一组参数在全部窗口上的表现
+type ParamResult struct {
+	Params           Params    `json:"params"`
+	Train            []*Result `json:"-"`                  // 每个窗口的训练期结果（未做前推时为 nil）
+	Test             []*Result `json:"-"`                  // 每个窗口的测试期结果
+	Score            float64   `json:"score"`              // 测试期排名指标的平均值
+	TrainScore       float64   `json:"train_score"`        // 训练期排名指标的平均值（与 Score 差距大说明过拟合）
+	AvgReturnPct     float64   `json:"avg_return_pct"`     // 测试期平均收益率
+	AvgSharpe        float64   `json:"avg_sharpe"`         // 测试期平均夏普
+	WorstDrawdownPct float64   `json:"worst_drawdown_pct"` // 测试期最大回撤
+	Trades           int       `json:"trades"`             // 测试期交易笔数
+	Err              error     `json:"-"`                  // 任一回测失败时的错误（不参与排名）
+}
+
+// WalkForwardStep 前推分析的一步：训练期表现最好的参数及其测试期结果
+type WalkForwardStep struct {
+	Window Window  `json:"window"`
+	Params Params  `json:"params"`
+	Train  *Result `json:"-"`
+	Test   *Result `json:"-"`
+}
+
+// SweepReport 参数扫描报告
+type SweepReport struct {
+	RankBy      string            `json:"rank_by"`
+	Windows     []Window          `json:"windows"`
+	Results     []ParamResult     `json:"results"`      // 按 Score 从高到低排序，失败的参数组合排在最后
+	WalkForward []WalkForwardStep `json:"walk_forward"` // 未做前推时为空
+}
+
+// Best 排名第一的参数组合
+func (r *SweepReport) Best() (ParamResult, bool) {
+	if len(r.Results) == 0 || r.Results[0].Err != nil {
+		return ParamResult{}, false
+	}
+	return r.Results[0], true
+}
+
+// WriteReport 输出排名表和前推分析结果
+func (r *SweepReport) WriteReport(w io.Writer) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "参数扫描结果（%d 组参数，%d 个窗口，按 %s 排名）\n", len(r.Results), len(r.Windows), r.RankBy)
+	fmt.Fprintf(&b, "%-4s %-32s %10s %10s %10s %10s %8s %10s\n", "排名", "参数", "得分", "训练得分", "平均收益%", "平均夏普", "交易数", "最大回撤%")
+	for i, res := range r.Results {
+		if res.Err != nil {
+			fmt.Fprintf(&b, "%-4d %-32s 失败: %v\n", i+1, res.Params, res.Err)
+			continue
+		}
+		fmt.Fprintf(&b, "%-4d %-32s %10.3f %10.3f %10.2f %10.2f %8d %10.2f\n",
+			i+1, res.Params, res.Score, res.TrainScore, res.AvgReturnPct, res.AvgSharpe, res.Trades, res.WorstDrawdownPct)
+	}
+	if len(r.WalkForward) > 0 {
+		b.WriteString("\n前推分析（训练期最优参数 → 测试期表现）\n")
+		for i, step := range r.WalkForward {
+			fmt.Fprintf(&b, "[%d] %s ~ %s | %-32s | 训练 %+.2f%% → 测试 %+.2f%% (回撤 %.2f%%)\n",
+				i+1, step.Window.TestStart.Format("2006-01-02 15:04"), step.Window.TestEnd.Format("2006-01-02 15:04"),
+				step.Params, step.Train.TotalReturnPct, step.Test.TotalReturnPct, step.Test.MaxDrawdownPct)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Sweep 并发回测参数网格中的每组参数：
+// 未配置前推窗口时在整段数据上回测；否则在每个滚动窗口的训练期和测试期分别回测，按测试期表现排名
+func Sweep(cfg SweepConfig) (*SweepReport, error) {
+	if cfg.Factory == nil {
+		return nil, fmt.Errorf("未配置策略工厂")
+	}
+	combos := cfg.Grid.Combinations()
+	if len(combos) == 0 || len(cfg.Grid) == 0 {
+		return nil, fmt.Errorf("参数网格为空")
+	}
+	rankBy := cfg.RankBy
+	if rankBy == "" {
+		rankBy = RankBySharpe
+	}
+	if rankBy != RankBySharpe && rankBy != RankByReturn && rankBy != RankByDrawdown {
+		return nil, fmt.Errorf("不支持的排名指标: %s", rankBy)
+	}
+
+	windows, err := sweepWindows(cfg)
+	if err != nil {
+		return nil, err
+	}
+	walkForward := cfg.TrainBars > 0
+
+	// 每组参数 × 每个窗口 × (训练/测试) 一个回测任务
+	type job struct {
+		combo, window int
+		train         bool
+	}
+	results := make([]ParamResult, len(combos))
+	for i, p := range combos {
+		results[i] = ParamResult{Params: p, Test: make([]*Result, len(windows))}
+		if walkForward {
+			results[i].Train = make([]*Result, len(windows))
+		}
+	}
+
+	workers := cfg.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	jobs := make(chan job)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				run := cfg.Base
+				win := windows[j.window]
+				run.Start, run.End = win.TestStart, win.TestEnd
+				if j.train {
+					run.Start, run.End = win.TrainStart, win.TrainEnd
+				}
+				res, err := runWithParams(run, cfg.Factory, combos[j.combo])
+
+				mu.Lock()
+				switch {
+				case err != nil:
+					if results[j.combo].Err == nil {
+						results[j.combo].Err = err
+					}
+				case j.train:
+					results[j.combo].Train[j.window] = res
+				default:
+					results[j.combo].Test[j.window] = res
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for c := range combos {
+		for w := range windows {
+			if walkForward {
+				jobs <- job{combo: c, window: w, train: true}
+			}
+			jobs <- job{combo: c, window: w}
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	report := &SweepReport{RankBy: rankBy, Windows: windows}
+	for i := range results {
+		summarizeParamResult(&results[i], rankBy)
+	}
+	if walkForward {
+		report.WalkForward = walkForwardSteps(results, windows, rankBy)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if (results[i].Err == nil) != (results[j].Err == nil) {
+			return results[i].Err == nil
+		}
+		return results[i].Score > results[j].Score
+	})
+	report.Results = results
+	return report, nil
+}
+
+// runWithParams 用参数创建策略并回测
+func runWithParams(cfg Config, factory StrategyFactory, p Params) (*Result, error) {
+	strategies, err := factory(p)
+	if err != nil {
+		return nil, fmt.Errorf("创建策略失败 [%s]: %w", p, err)
+	}
+	cfg.Strategies = strategies
+	res, err := Run(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("回测失败 [%s]: %w", p, err)
+	}
+	return res, nil
+}
+
+// sweepWindows 按第一个币种的 Interval K线划分前推窗口（未配置前推时为整段数据一个窗口）
+func sweepWindows(cfg SweepConfig) ([]Window, error) {
+	base := withDefaults(cfg.Base)
+	if len(base.Symbols) == 0 {
+		return nil, fmt.Errorf("未指定回测币种")
+	}
+	klines := base.Klines[base.Symbols[0]][base.Interval]
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("缺少 %s 的 %s K线", base.Symbols[0], base.Interval)
+	}
+	start := func(i int) time.Time { return time.UnixMilli(klines[i].OpenTime) }
+	end := func(i int) time.Time { return time.UnixMilli(klines[i].CloseTime) }
+
+	if cfg.TrainBars <= 0 {
+		return []Window{{TestStart: start(0), TestEnd: end(len(klines) - 1)}}, nil
+	}
+	if cfg.TestBars <= 0 {
+		return nil, fmt.Errorf("前推分析需要设置测试窗口长度")
+	}
+	step := cfg.StepBars
+	if step <= 0 {
+		step = cfg.TestBars
+	}
+
+	var windows []Window
+	for s := 0; s+cfg.TrainBars+cfg.TestBars <= len(klines); s += step {
+		trainEnd := s + cfg.TrainBars
+		windows = append(windows, Window{
+			TrainStart: start(s),
+			TrainEnd:   end(trainEnd - 1),
+			TestStart:  start(trainEnd),
+			TestEnd:    end(trainEnd + cfg.TestBars - 1),
+		})
+	}
+	if len(windows) == 0 {
+		return nil, fmt.Errorf("K线数量 %d 不足一个前推窗口（训练 %d + 测试 %d）", len(klines), cfg.TrainBars, cfg.TestBars)
+	}
+	return windows, nil
+}
+
+// score 排名指标（越大越好）
+func score(r *Result, rankBy string) float64 {
+	switch rankBy {
+	case RankByReturn:
+		return r.TotalReturnPct
+	case RankByDrawdown:
+		return -r.MaxDrawdownPct
+	default:
+		return r.SharpeRatio
+	}
+}
+
+// summarizeParamResult 汇总各窗口的测试/训练结果
+func summarizeParamResult(res *ParamResult, rankBy string) {
+	if res.Err != nil {
+		return
+	}
+	n := float64(len(res.Test))
+	for _, t := range res.Test {
+		res.Score += score(t, rankBy) / n
+		res.AvgReturnPct += t.TotalReturnPct / n
+		res.AvgSharpe += t.SharpeRatio / n
+		res.WorstDrawdownPct = math.Max(res.WorstDrawdownPct, t.MaxDrawdownPct)
+		res.Trades += len(t.Trades)
+	}
+	if len(res.Train) == 0 {
+		res.TrainScore = res.Score
+		return
+	}
+	for _, t := range res.Train {
+		res.TrainScore += score(t, rankBy) / float64(len(res.Train))
+	}
+}
+
+// walkForwardSteps 每个窗口选出训练期得分最高的参数，记录其测试期表现
+func walkForwardSteps(results []ParamResult, windows []Window, rankBy string) []WalkForwardStep {
+	var steps []WalkForwardStep
+	for w, win := range windows {
+		best := -1
+		for i, res := range results {
+			if res.Err != nil {
+				continue
+			}
+			if best < 0 || score(res.Train[w], rankBy) > score(results[best].Train[w], rankBy) {
+				best = i
+			}
+		}
+		if best < 0 {
+			continue
+		}
+		steps = append(steps, WalkForwardStep{
+			Window: win,
+			Params: results[best].Params,
+			Train:  results[best].Train[w],
+			Test:   results[best].Test[w],
+		})
+	}
+	return steps
+}
@@ -0,0 +1,116 @@
+package backtest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"nofx/market"
+	"nofx/strategy"
+)
+
+// takeProfitStrategy 空仓时开多，止盈距离为 tp%
+func takeProfitStrategy(p Params) ([]strategy.Strategy, error) {
+	if p["tp"] < 0 {
+		return nil, errors.New("tp must be positive")
+	}
+	return []strategy.Strategy{strategy.Func(func(ctx context.Context, s strategy.MarketSnapshot) []strategy.Decision {
+		if len(s.Positions) > 0 {
+			return nil
+		}
+		price := s.Price()
+		return []strategy.Decision{{Action: "open_long", Leverage: 1, PositionSizeUSD: 1000, TakeProfit: price * (1 + p["tp"]/100)}}
+	})}, nil
+}
+
+func TestParamGridCombinations(t *testing.T) {
+	combos := ParamGrid{"slow": {20, 50}, "fast": {5, 10, 15}}.Combinations()
+	if len(combos) != 6 {
+		t.Fatalf("期望 6 组参数，得到 %d", len(combos))
+	}
+	if combos[0].String() != "fast=5 slow=20" || combos[5].String() != "fast=15 slow=50" {
+		t.Errorf("unexpected order: %v ... %v", combos[0], combos[5])
+	}
+}
+
+func TestSweepWalkForward(t *testing.T) {
+	// 单边上涨：持有到结束的参数收益最高
+	closes := make([]float64, 60)
+	for i := range closes {
+		closes[i] = 100 + float64(i)
+	}
+	base := Config{
+		Symbols:      []string{"BTCUSDT"},
+		Klines:       map[string]map[string][]market.Kline{"BTCUSDT": {"15m": makeKlines(0, closes)}},
+		TakerFeeRate: -1,
+	}
+
+	report, err := Sweep(SweepConfig{
+		Base:      base,
+		Grid:      ParamGrid{"tp": {0.5, 2, 50, -1}},
+		Factory:   takeProfitStrategy,
+		TrainBars: 20,
+		TestBars:  10,
+		Workers:   4,
+		RankBy:    RankByReturn,
+	})
+	if err != nil {
+		t.Fatalf("Sweep 失败: %v", err)
+	}
+	if len(report.Windows) != 4 || len(report.WalkForward) != 4 {
+		t.Fatalf("期望 4 个窗口，得到 %d / %d", len(report.Windows), len(report.WalkForward))
+	}
+	if len(report.Results) != 4 || report.Results[0].Params["tp"] != 50 || report.Results[3].Err == nil {
+		t.Errorf("unexpected ranking: %+v", report.Results)
+	}
+	for i := 1; i < 3; i++ {
+		if report.Results[i].Score > report.Results[i-1].Score {
+			t.Errorf("排名未按得分降序: %v", report.Results)
+		}
+	}
+	for w, step := range report.WalkForward {
+		if step.Train == nil || step.Test == nil {
+			t.Fatalf("unexpected walk-forward step: %+v", step)
+		}
+		// 选出的参数在该窗口训练期的收益不低于其他任何参数
+		for _, res := range report.Results {
+			if res.Err == nil && res.Train[w].TotalReturnPct > step.Train.TotalReturnPct {
+				t.Errorf("窗口 %d 未选出训练期最优参数: 选中 %s，但 %s 更好", w, step.Params, res.Params)
+			}
+		}
+		if !step.Window.TestStart.After(step.Window.TrainEnd) {
+			t.Errorf("测试窗口必须在训练窗口之后: %+v", step.Window)
+		}
+	}
+	if best, ok := report.Best(); !ok || best.Params["tp"] != 50 {
+		t.Errorf("unexpected best: %+v", best)
+	}
+
+	var buf bytes.Buffer
+	if err := report.WriteReport(&buf); err != nil {
+		t.Fatalf("WriteReport 失败: %v", err)
+	}
+	if !strings.Contains(buf.String(), "tp=50") || !strings.Contains(buf.String(), "前推分析") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestSweepWithoutWalkForward(t *testing.T) {
+	closes := []float64{100, 101, 102, 103, 104, 105}
+	report, err := Sweep(SweepConfig{
+		Base:    Config{Symbols: []string{"BTCUSDT"}, Klines: map[string]map[string][]market.Kline{"BTCUSDT": {"15m": makeKlines(0, closes)}}},
+		Grid:    ParamGrid{"tp": {1, 10}},
+		Factory: takeProfitStrategy,
+	})
+	if err != nil {
+		t.Fatalf("Sweep 失败: %v", err)
+	}
+	if len(report.Windows) != 1 || len(report.WalkForward) != 0 || len(report.Results) != 2 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if report.Results[0].Train != nil || len(report.Results[0].Test) != 1 {
+		t.Errorf("未做前推时只应有整段测试结果: %+v", report.Results[0])
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// cliCommand 子命令（nofx <name> [参数]，参数 -h 查看说明）
+type cliCommand struct {
+	name    string
+	summary string
+	run     func(args []string, stdout io.Writer) error
+}
+
+// cliCommands 子命令列表（nofx run / 不带子命令时启动交易服务，见 main）
+var cliCommands = []cliCommand{
+	{"run", "启动交易服务（API + 交易员，默认命令）: nofx run [-config path] [dbPath]", nil},
+	{"backtest", "用本地缓存的K线回测内置策略（见 cli_backtest.go）", runBacktestCommand},
+	{"positions", "查看交易所当前持仓", runPositionsCommand},
+	{"balance", "查看交易所账户余额", runBalanceCommand},
+	{"close", "市价平仓: nofx close BTCUSDT [--side long|short] [--quantity N]", runCloseCommand},
+	{"export", "导出交易和K线（见 cli_export.go）", runExportCommand},
+	{"statement", "生成对账单/报税报告：FIFO 或加权平均成本、按周期已实现盈亏（见 cli_statement.go）", runStatementCommand},
+	{"benchmark", "对比策略收益与同期买入持有 BTC/ETH 的收益（见 cli_benchmark.go）", runBenchmarkCommand},
+	{"trades", "查询成交记录（见 cli_journal.go）", runTradesCommand},
+	{"decisions", "查询决策日志（见 cli_journal.go）", runDecisionsCommand},
+	{"explain", "查看一笔交易从输入数据到下单的完整链路: nofx explain <tradeID>", runExplainCommand},
+	{"keys", "管理本地加密密钥文件（见 cli_keys.go）", runKeysCommand},
+}
+
+// lookupCommand 按名称查找一次性子命令（run 不算，由 main 启动服务）
+func lookupCommand(name string) (cliCommand, bool) {
+	for _, cmd := range cliCommands {
+		if cmd.name == name && cmd.run != nil {
+			return cmd, true
+		}
+	}
+	return cliCommand{}, false
+}
+
+// runCommand 执行子命令，返回进程退出码
+func runCommand(name string, args []string, stdout, stderr io.Writer) int {
+	var err error
+	if cmd, ok := lookupCommand(name); ok {
+		err = cmd.run(args, stdout)
+	} else if name == "help" {
+		printUsage(stdout)
+		return 0
+	} else {
+		err = fmt.Errorf("未知命令: %s（nofx help 查看全部命令）", name)
+	}
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "❌ %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// printUsage 列出全部子命令
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "用法: nofx <命令> [参数]（nofx <命令> -h 查看参数说明）")
+	fmt.Fprintln(w)
+	for _, cmd := range cliCommands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"nofx/analytics"
+	"nofx/backtest"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/strategy"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// runBacktestCommand nofx backtest --strategy sma_cross --params fast=10,slow=30 --symbols BTCUSDT --from 2024-01-01 --to 2024-06-30
+// 从本地K线缓存（NOFX_KLINE_CACHE_DIR，由 market.Backfill 回填）读取历史K线回放内置策略
+func runBacktestCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("backtest", flag.ContinueOnError)
+	strategyName := fs.String("strategy", "sma_cross", "策略名称（可选: "+strings.Join(strategy.Registered(), ", ")+"）")
+	params := fs.String("params", "", "策略参数，如 fast=10,slow=30,size_usd=1000,leverage=2")
+	symbols := fs.String("symbols", "BTCUSDT", "回测币种（逗号分隔）")
+	interval := fs.String("interval", "15m", "回放周期")
+	from := fs.String("from", "", "回放开始时间（YYYY-MM-DD 或 RFC3339，之前的K线只作为历史数据）")
+	to := fs.String("to", "", "回放结束时间（YYYY-MM-DD 包含当天，或 RFC3339）")
+	source := fs.String("source", "binance", "K线缓存的数据源")
+	klineDir := fs.String("kline-dir", os.Getenv("NOFX_KLINE_CACHE_DIR"), "K线缓存目录")
+	balance := fs.Float64("balance", 10000, "初始资金（USDT）")
+	exchange := fs.String("exchange", "", "按该交易所的默认 maker/taker 费率计算手续费（binance、okx、bybit、hyperliquid、aster）")
+	fee := fs.Float64("fee", 0, "市价成交手续费率（0=交易所默认或 0.0004，<0=免手续费）")
+	makerFee := fs.Float64("maker-fee", 0, "限价止盈手续费率（0=交易所默认或 0.0002，<0=免手续费）")
+	slippage := fs.Float64("slippage", 0, "市价成交滑点百分比（0.05 = 0.05%）")
+	slippageCurve := fs.String("slippage-curve", "", "按订单金额的滑点曲线，如 10000:0.02,100000:0.1（USDT:百分比，设置后忽略 --slippage）")
+	spread := fs.Float64("spread-bps", 0, "买一卖一价差（基点），市价单按半个价差成交")
+	participation := fs.Float64("max-participation", 0, "策略订单最多成交当根K线成交额的百分比（0=不限制，超出部分不成交）")
+	limitTP := fs.Bool("limit-tp", false, "止盈按限价单成交（不计价差和滑点，收 maker 费率）")
+	funding := fs.Float64("funding", 0, "每 8 小时的固定资金费率（0=不计资金费）")
+	minEntryInterval := fs.Duration("min-entry-interval", 0, "同一币种两次开仓的最小间隔（如 1h，0=不限制）")
+	maxTradesPerDay := fs.Int("max-trades-per-day", 0, "同一币种每天最多开仓次数（0=不限制）")
+	stopCooldown := fs.Duration("stop-cooldown", 0, "止损后同一币种的冷却时间（如 4h，0=不限制）")
+	mcRisk := fs.Float64("mc-risk", 0, "蒙特卡洛风险模拟：每笔交易风险占净值的百分比（如 1，0=不模拟）")
+	mcRuns := fs.Int("mc-runs", 10000, "蒙特卡洛模拟次数")
+	mcTrades := fs.Int("mc-trades", 0, "每次模拟的交易笔数（0=与回测交易笔数相同）")
+	mcRuin := fs.Float64("mc-ruin", 50, "回撤达到该百分比视为破产")
+	showTrades := fs.Bool("trades", false, "列出每笔交易")
+	asJSON := fs.Bool("json", false, "以 JSON 输出完整结果（含权益曲线）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *klineDir == "" {
+		return fmt.Errorf("回测需要 --kline-dir（或设置 NOFX_KLINE_CACHE_DIR）")
+	}
+
+	strategyParams, err := parseStrategyParams(*params)
+	if err != nil {
+		return err
+	}
+	strat, err := strategy.New(*strategyName, strategyParams)
+	if err != nil {
+		return err
+	}
+	curve, err := backtest.ParseSlippageCurve(*slippageCurve)
+	if err != nil {
+		return err
+	}
+	fromTime, err := logger.ParseJournalTime(*from, false)
+	if err != nil {
+		return err
+	}
+	toTime, err := logger.ParseJournalTime(*to, true)
+	if err != nil {
+		return err
+	}
+	loadTo := toTime
+	if loadTo.IsZero() {
+		loadTo = time.Now()
+	}
+
+	var symbolList []string
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			symbolList = append(symbolList, s)
+		}
+	}
+	if err := market.EnableKlineCache(*klineDir, 0); err != nil {
+		return err
+	}
+	klines, err := backtest.LoadKlines(*source, symbolList, []string{*interval}, time.Time{}, loadTo)
+	if err != nil {
+		return err
+	}
+
+	var monteCarloConfig *analytics.MonteCarloConfig
+	if *mcRisk > 0 {
+		monteCarloConfig = &analytics.MonteCarloConfig{RiskPerTradePct: *mcRisk, Runs: *mcRuns, Trades: *mcTrades, RuinDrawdownPct: *mcRuin}
+	}
+
+	result, err := backtest.Run(backtest.Config{
+		Symbols:        symbolList,
+		Interval:       *interval,
+		Klines:         klines,
+		Start:          fromTime,
+		End:            toTime,
+		InitialBalance: *balance,
+		Exchange:       *exchange,
+		TakerFeeRate:   *fee,
+		MakerFeeRate:   *makerFee,
+		SlippagePct:    *slippage,
+		Fill: backtest.FillModel{
+			SpreadBps:           *spread,
+			SlippageCurve:       curve,
+			MaxParticipationPct: *participation,
+			LimitTakeProfit:     *limitTP,
+		},
+		FundingRate: *funding,
+		Strategies:  []strategy.Strategy{strat},
+		Churn: strategy.ChurnLimits{
+			MinEntryInterval: *minEntryInterval,
+			MaxTradesPerDay:  *maxTradesPerDay,
+			StopOutCooldown:  *stopCooldown,
+		},
+		MonteCarlo: monteCarloConfig,
+	})
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(stdout, result)
+	}
+
+	fmt.Fprintf(stdout, "📈 %s %s %s: %.2f → %.2f USDT\n", *strategyName, strings.Join(symbolList, ","), *interval, result.InitialBalance, result.FinalEquity)
+	fmt.Fprintln(stdout, result.Summary())
+	if summary := result.MonteCarloSummary(); summary != "" {
+		fmt.Fprintln(stdout, summary)
+	}
+	if result.PartialFills > 0 {
+		fmt.Fprintf(stdout, "⚠️  %d 笔订单受K线成交额限制只部分成交\n", result.PartialFills)
+	}
+	if *showTrades && len(result.Trades) > 0 {
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "\n币种\t方向\t开仓时间\t平仓时间\t开仓价\t平仓价\t数量\t盈亏\t原因")
+		for _, tr := range result.Trades {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%g\t%g\t%g\t%+.2f\t%s\n", tr.Symbol, tr.Side,
+				tr.EntryTime.UTC().Format("2006-01-02 15:04"), tr.ExitTime.UTC().Format("2006-01-02 15:04"),
+				tr.EntryPrice, tr.ExitPrice, tr.Quantity, tr.PnL, tr.Reason)
+		}
+		return w.Flush()
+	}
+	return nil
+}
+
+// parseStrategyParams 解析 "fast=10,slow=30"
+func parseStrategyParams(raw string) (map[string]float64, error) {
+	params := make(map[string]float64)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("策略参数格式错误: %q（应为 名称=数值）", item)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("策略参数 %s 的值无效: %q", name, value)
+		}
+		params[strings.TrimSpace(name)] = v
+	}
+	return params, nil
+}
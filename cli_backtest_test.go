@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"nofx/market"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBacktestCommand(t *testing.T) {
+	klineDir := t.TempDir()
+	cache, err := market.NewPersistentKlineCache(klineDir, 0)
+	require.NoError(t, err)
+	defer market.DisableKlineCache()
+
+	// 先跌后涨再跌：均线交叉产生一笔完整交易
+	closes := []float64{100, 99, 98, 97, 96, 95, 97, 100, 104, 108, 112, 115, 113, 109, 104, 99, 95, 92}
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	klines := make([]market.Kline, len(closes))
+	for i, c := range closes {
+		open := start + int64(i)*3600000
+		klines[i] = market.Kline{OpenTime: open, CloseTime: open + 3599999, Open: c, High: c, Low: c, Close: c}
+	}
+	require.NoError(t, cache.Store("binance", "BTCUSDT", "1h", klines))
+
+	args := []string{"--kline-dir", klineDir, "--interval", "1h", "--strategy", "sma_cross", "--params", "fast=2,slow=4", "--fee", "-1"}
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, runCommand("backtest", append(args, "--trades"), &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "sma_cross BTCUSDT 1h")
+	assert.Contains(t, stdout.String(), "signal")
+
+	// 交易太少时不做蒙特卡洛模拟，只提示原因
+	stdout.Reset()
+	require.Equal(t, 0, runCommand("backtest", append(args, "--mc-risk", "1"), &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "蒙特卡洛模拟未执行")
+
+	stdout.Reset()
+	require.Equal(t, 0, runCommand("backtest", append(args, "--json"), &stdout, &stderr), stderr.String())
+	var result struct {
+		FinalEquity float64 `json:"final_equity"`
+		Trades      []struct {
+			Side   string  `json:"side"`
+			PnL    float64 `json:"pnl"`
+			Reason string  `json:"reason"`
+		} `json:"trades"`
+	}
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &result))
+	require.NotEmpty(t, result.Trades)
+	assert.Equal(t, "long", result.Trades[0].Side)
+	assert.Greater(t, result.Trades[0].PnL, 0.0)
+
+	stderr.Reset()
+	assert.Equal(t, 1, runCommand("backtest", []string{"--kline-dir", klineDir, "--params", "fast"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "策略参数格式错误")
+	assert.Equal(t, 1, runCommand("backtest", []string{"--kline-dir", klineDir, "--strategy", "martingale"}, &stdout, &stderr))
+	stderr.Reset()
+	assert.Equal(t, 1, runCommand("backtest", append(args, "--slippage-curve", "10000=0.1"), &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "滑点曲线格式错误")
+}
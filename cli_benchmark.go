@@ -0,0 +1,102 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"nofx/analytics"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"os"
+	"strings"
+	"text/tabwriter"
+)
+
+// runBenchmarkCommand nofx benchmark --trader my_trader --from 2024-01-01 --symbols BTCUSDT,ETHUSDT
+// 对比交易员的收益（净值快照，没有快照时按已平仓交易）与同期买入持有 BTC/ETH 的收益，价格取自本地K线缓存
+func runBenchmarkCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("benchmark", flag.ContinueOnError)
+	traderID := fs.String("trader", "", "trader ID（为空时对比事件日志中的全部 trader）")
+	symbols := fs.String("symbols", strings.Join(analytics.DefaultBenchmarkSymbols, ","), "买入持有的对比币种（逗号分隔）")
+	from := fs.String("from", "", "开始时间（YYYY-MM-DD 或 RFC3339，默认为数据开始时间）")
+	to := fs.String("to", "", "结束时间（YYYY-MM-DD 包含当天，或 RFC3339，默认为数据结束时间）")
+	balance := fs.Float64("balance", 0, "初始余额（没有净值快照时用于计算策略收益率）")
+	source := fs.String("source", "binance", "K线缓存的数据源")
+	klineDir := fs.String("kline-dir", os.Getenv("NOFX_KLINE_CACHE_DIR"), "K线缓存目录")
+	journalDB := fs.String("journal-db", os.Getenv("NOFX_JOURNAL_DB"), "交易事件日志 SQLite 路径")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *journalDB == "" {
+		return fmt.Errorf("需要 --journal-db（或设置 NOFX_JOURNAL_DB）")
+	}
+	if *klineDir == "" {
+		return fmt.Errorf("需要 --kline-dir（或设置 NOFX_KLINE_CACHE_DIR）")
+	}
+	fromTime, err := logger.ParseJournalTime(*from, false)
+	if err != nil {
+		return err
+	}
+	toTime, err := logger.ParseJournalTime(*to, true)
+	if err != nil {
+		return err
+	}
+	var symbolList []string
+	for _, s := range strings.Split(*symbols, ",") {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			symbolList = append(symbolList, s)
+		}
+	}
+
+	if err := market.EnableKlineCache(*klineDir, 0); err != nil {
+		return err
+	}
+	journal, err := store.OpenJournal(*journalDB)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	traders := []string{*traderID}
+	if *traderID == "" {
+		if traders, err = journal.TraderIDs(); err != nil {
+			return err
+		}
+	}
+
+	results := make(map[string]*analytics.Benchmark, len(traders))
+	for _, id := range traders {
+		b, err := analytics.BenchmarkFromJournal(journal, id, *balance, symbolList, fromTime, toTime, analytics.CachedPrices(*source))
+		if err != nil {
+			return fmt.Errorf("%s: %w", id, err)
+		}
+		results[id] = b
+	}
+	if *asJSON {
+		return writeJSON(stdout, results)
+	}
+
+	for _, id := range traders {
+		b := results[id]
+		s := b.Strategy
+		fmt.Fprintf(stdout, "📊 %s %s ~ %s（策略收益来源: %s）\n", id,
+			b.Since.UTC().Format("2006-01-02 15:04"), b.Until.UTC().Format("2006-01-02 15:04"), s.Source)
+		w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "对比\t期初\t期末\t盈亏\t收益率\t最大回撤\t超额收益")
+		fmt.Fprintf(w, "策略\t%.2f\t%.2f\t%+.2f\t%+.2f%%\t%.2f%%\t-\n", s.StartEquity, s.EndEquity, s.PnL, s.ReturnPct, s.MaxDrawdownPct)
+		for _, h := range b.Holds {
+			fmt.Fprintf(w, "持有 %s\t%g\t%g\t%+.2f\t%+.2f%%\t%.2f%%\t%+.2f%%\n",
+				h.Symbol, h.StartPrice, h.EndPrice, h.PnL, h.ReturnPct, h.MaxDrawdownPct, h.ExcessReturnPct)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		if len(b.Missing) > 0 {
+			fmt.Fprintf(stdout, "⚠️  K线缓存中没有 %s 在该区间的价格，请先回填\n", strings.Join(b.Missing, ", "))
+		}
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
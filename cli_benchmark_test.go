@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/analytics"
+	"nofx/market"
+	"nofx/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunBenchmarkCommand(t *testing.T) {
+	defer market.DisableKlineCache()
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	klineDir := t.TempDir()
+	cache, err := market.NewPersistentKlineCache(klineDir, 0)
+	require.NoError(t, err)
+	var klines []market.Kline
+	for i, c := range []float64{104, 108, 112} {
+		open := start.Add(time.Duration(i) * time.Hour)
+		klines = append(klines, market.Kline{OpenTime: open.UnixMilli(), CloseTime: open.Add(time.Hour).UnixMilli() - 1, Open: c - 4, Close: c})
+	}
+	require.NoError(t, cache.Store("binance", "BTCUSDT", "1h", klines))
+
+	dbPath := filepath.Join(t.TempDir(), "journal.db")
+	journal, err := store.OpenJournal(dbPath)
+	require.NoError(t, err)
+	require.NoError(t, journal.RecordEquity(store.EquitySnapshot{TraderID: "t1", Time: start, WalletBalance: 1000}))
+	require.NoError(t, journal.RecordEquity(store.EquitySnapshot{TraderID: "t1", Time: start.Add(3 * time.Hour), WalletBalance: 1050, UnrealizedPnL: 10}))
+	require.NoError(t, journal.Close())
+
+	t.Run("表格输出", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("benchmark", []string{"--journal-db", dbPath, "--kline-dir", klineDir}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		assert.Contains(t, stdout.String(), "策略收益来源: equity_snapshots")
+		assert.Contains(t, stdout.String(), "+6.00%")  // 策略 1000 → 1060
+		assert.Contains(t, stdout.String(), "+12.00%") // 持有 BTC 100 → 112
+		assert.Contains(t, stdout.String(), "-6.00%")  // 超额收益
+		assert.Contains(t, stdout.String(), "ETHUSDT 在该区间的价格")
+	})
+
+	t.Run("JSON 输出", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("benchmark", []string{"--journal-db", dbPath, "--kline-dir", klineDir, "--trader", "t1", "--symbols", "btcusdt", "--json"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		var results map[string]analytics.Benchmark
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &results))
+		require.Len(t, results["t1"].Holds, 1)
+		assert.InDelta(t, 12, results["t1"].Holds[0].ReturnPct, 1e-9)
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 1, runCommand("benchmark", []string{"--journal-db", dbPath, "--kline-dir", ""}, &stdout, &stderr))
+		assert.Contains(t, stderr.String(), "--kline-dir")
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"nofx/decision"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// explainOutputLimit 默认显示的原始输出长度（字符）
+const explainOutputLimit = 2000
+
+// tradeExplanation nofx explain 的 JSON 输出
+type tradeExplanation struct {
+	TraderID string                 `json:"trader_id"`
+	Action   *logger.DecisionAction `json:"action"`
+	Decision *decision.Decision     `json:"decision,omitempty"`
+	Record   *logger.DecisionRecord `json:"record"`
+}
+
+// runExplainCommand nofx explain <tradeID>：打印一笔交易从输入数据、决策来源输出、决策到下单的完整链路
+func runExplainCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	logDir := fs.String("log-dir", defaultDecisionLogDir, "决策日志根目录")
+	traderID := fs.String("trader", "", "trader ID（省略时在全部 trader 中查找）")
+	asJSON := fs.Bool("json", false, "以 JSON 输出（包含完整决策记录）")
+	full := fs.Bool("full", false, "显示完整的提示词和原始输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	// 允许参数写在 tradeID 之后
+	var tradeID string
+	if fs.NArg() > 0 {
+		tradeID = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if tradeID == "" {
+		return fmt.Errorf("用法: nofx explain [--trader ID] [--json] [--full] <tradeID>")
+	}
+
+	explanation, err := findTradeExplanation(*logDir, *traderID, tradeID)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(stdout, explanation)
+	}
+	printExplanation(stdout, tradeID, explanation, *full)
+	return nil
+}
+
+// findTradeExplanation 在指定（或全部）trader 的决策日志中查找交易
+func findTradeExplanation(logDir, traderID, tradeID string) (*tradeExplanation, error) {
+	traders := []string{traderID}
+	if traderID == "" {
+		entries, err := os.ReadDir(logDir)
+		if err != nil {
+			return nil, fmt.Errorf("读取日志目录失败: %w", err)
+		}
+		traders = traders[:0]
+		for _, e := range entries {
+			if e.IsDir() {
+				traders = append(traders, e.Name())
+			}
+		}
+		sort.Strings(traders)
+	}
+
+	for _, id := range traders {
+		dir := filepath.Join(logDir, id)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			if traderID != "" {
+				return nil, fmt.Errorf("trader %s 的决策日志不存在: %s", id, dir)
+			}
+			continue
+		}
+		record, action, err := logger.NewDecisionLogger(dir).(*logger.DecisionLogger).FindTrade(tradeID)
+		if err != nil {
+			continue
+		}
+		return &tradeExplanation{TraderID: id, Action: action, Decision: matchDecision(record.DecisionJSON, action), Record: record}, nil
+	}
+	return nil, fmt.Errorf("未找到交易 %s（日志目录: %s）", tradeID, logDir)
+}
+
+// matchDecision 从周期的决策 JSON 中找出对应的决策（资金费率过滤可能已把开仓方向反转，动作不一致时按币种匹配）
+func matchDecision(decisionJSON string, action *logger.DecisionAction) *decision.Decision {
+	var decisions []decision.Decision
+	if json.Unmarshal([]byte(decisionJSON), &decisions) != nil {
+		return nil
+	}
+	var bySymbol *decision.Decision
+	for i := range decisions {
+		if decisions[i].Symbol != action.Symbol {
+			continue
+		}
+		if decisions[i].Action == action.Action {
+			return &decisions[i]
+		}
+		if bySymbol == nil {
+			bySymbol = &decisions[i]
+		}
+	}
+	return bySymbol
+}
+
+// printExplanation 按 输入 → 决策来源 → 决策 → 执行 的顺序打印
+func printExplanation(w io.Writer, tradeID string, e *tradeExplanation, full bool) {
+	r, a := e.Record, e.Action
+	fmt.Fprintf(w, "交易 %s | trader %s | 周期 #%d | %s\n", tradeID, e.TraderID, r.CycleNumber, r.Timestamp.Local().Format("2006-01-02 15:04:05"))
+
+	fmt.Fprintln(w, "\n── 1. 输入数据 ──")
+	acc := r.AccountState
+	fmt.Fprintf(w, "账户: 余额 %.2f | 可用 %.2f | 未实现盈亏 %+.2f | 保证金占用 %.1f%% | 持仓 %d\n",
+		acc.TotalBalance, acc.AvailableBalance, acc.TotalUnrealizedProfit, acc.MarginUsedPct, acc.PositionCount)
+	for _, p := range r.Positions {
+		fmt.Fprintf(w, "持仓: %s %s %.6g @ %.6g（标记价 %.6g，盈亏 %+.2f，%gx）\n",
+			p.Symbol, p.Side, p.PositionAmt, p.EntryPrice, p.MarkPrice, p.UnrealizedProfit, p.Leverage)
+	}
+	if len(r.CandidateCoins) > 0 {
+		fmt.Fprintf(w, "候选币种: %s\n", strings.Join(r.CandidateCoins, ", "))
+	}
+	if r.Inputs == nil {
+		fmt.Fprintln(w, "（该记录没有输入快照，只能查看提示词）")
+	} else if m, ok := r.Inputs.Market[a.Symbol]; ok {
+		fmt.Fprintf(w, "行情 %s: 价格 %.6g | 1h %+.2f%% | 4h %+.2f%% | EMA20 %.6g | MACD %.6g | RSI7 %.2f | ATR14 %.6g | 4h EMA50 %.6g | 资金费率 %.4f%% | 持仓量 %.0f\n",
+			a.Symbol, m.Price, m.PriceChange1h, m.PriceChange4h, m.EMA20, m.MACD, m.RSI7, m.ATR14, m.EMA50On4h, m.FundingRate*100, m.OpenInterest)
+		fmt.Fprintf(w, "数据哈希: %s\n", m.DataHash)
+	} else {
+		fmt.Fprintf(w, "（输入快照中没有 %s 的行情数据）\n", a.Symbol)
+	}
+
+	fmt.Fprintln(w, "\n── 2. 决策来源 ──")
+	if r.Inputs != nil {
+		fmt.Fprintf(w, "来源: %s", r.Inputs.Provider)
+		if r.AIRequestDurationMs > 0 {
+			fmt.Fprintf(w, " | 耗时 %d ms", r.AIRequestDurationMs)
+		}
+		fmt.Fprintln(w)
+		if r.Inputs.ParseError != "" {
+			fmt.Fprintf(w, "错误: %s\n", r.Inputs.ParseError)
+		}
+	}
+	if full {
+		fmt.Fprintf(w, "系统提示词:\n%s\n\n输入提示词:\n%s\n\n", r.SystemPrompt, r.InputPrompt)
+	}
+	raw := r.CoTTrace
+	if r.Inputs != nil && r.Inputs.RawOutput != "" {
+		raw = r.Inputs.RawOutput
+	}
+	fmt.Fprintf(w, "原始输出:\n%s\n", truncateOutput(raw, full))
+
+	fmt.Fprintln(w, "\n── 3. 决策 ──")
+	if e.Decision == nil {
+		fmt.Fprintln(w, "（决策 JSON 中没有对应的决策，可能是自动生成的动作）")
+	} else {
+		data, _ := json.MarshalIndent(e.Decision, "", "  ")
+		fmt.Fprintln(w, string(data))
+	}
+
+	fmt.Fprintln(w, "\n── 4. 执行 ──")
+	status := "成功"
+	if !a.Success {
+		status = "失败: " + a.Error
+	}
+	fmt.Fprintf(w, "%s %s | 数量 %.6g | 价格 %.6g | 杠杆 %dx | 订单 %d | 手续费 %.4f | %s | %s\n",
+		a.Action, a.Symbol, a.Quantity, a.Price, a.Leverage, a.OrderID, a.Fee, a.Timestamp.Local().Format("15:04:05"), status)
+	for _, line := range r.ExecutionLog {
+		if strings.Contains(line, a.Symbol) {
+			fmt.Fprintf(w, "  %s\n", line)
+		}
+	}
+}
+
+// truncateOutput 截断过长的输出（按字符）
+func truncateOutput(s string, full bool) string {
+	runes := []rune(s)
+	if full || len(runes) <= explainOutputLimit {
+		return s
+	}
+	return string(runes[:explainOutputLimit]) + fmt.Sprintf("\n…（共 %d 字符，--full 查看全部）", len(runes))
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeExplainFixture 写入一条带输入快照的开仓决策记录
+func writeExplainFixture(t *testing.T, dir string) {
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	ts := time.Date(2024, 3, 1, 8, 0, 0, 0, time.Local)
+	record := logger.DecisionRecord{
+		Timestamp:    ts,
+		CycleNumber:  12,
+		InputPrompt:  "BTCUSDT price 50000",
+		CoTTrace:     "breakout above resistance",
+		DecisionJSON: `[{"symbol":"ETHUSDT","action":"close_long"},{"symbol":"BTCUSDT","action":"open_long","leverage":5,"position_size_usd":500,"stop_loss":49000,"take_profit":53000}]`,
+		AccountState: logger.AccountSnapshot{TotalBalance: 1000, AvailableBalance: 800, PositionCount: 1},
+		Positions:    []logger.PositionSnapshot{{Symbol: "ETHUSDT", Side: "long", PositionAmt: 1, EntryPrice: 3000, MarkPrice: 3100}},
+		Decisions: []logger.DecisionAction{
+			{TradeID: "t-close", Action: "close_long", Symbol: "ETHUSDT", Success: true, Timestamp: ts},
+			{TradeID: "t-open", Action: "open_long", Symbol: "BTCUSDT", Quantity: 0.01, Leverage: 5, Price: 50010, OrderID: 42, Success: true, Timestamp: ts},
+		},
+		ExecutionLog: []string{"✓ BTCUSDT open_long 成功", "✓ ETHUSDT close_long 成功"},
+		Success:      true,
+		Inputs: &logger.InputSnapshot{
+			Provider:  "llm",
+			RawOutput: "<reasoning>breakout</reasoning> [...]",
+			Market:    map[string]logger.MarketInputs{"BTCUSDT": {Price: 50000, RSI7: 61.5, DataHash: "abc123"}},
+		},
+	}
+	data, err := json.Marshal(record)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "decision_20240301_080000.json"), data, 0600))
+}
+
+func TestRunExplainCommand(t *testing.T) {
+	logDir := t.TempDir()
+	writeExplainFixture(t, filepath.Join(logDir, "trader_b"))
+	require.NoError(t, os.MkdirAll(filepath.Join(logDir, "trader_a"), 0700))
+
+	t.Run("在全部 trader 中查找并打印链路", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("explain", []string{"--log-dir", logDir, "t-open"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		out := stdout.String()
+		assert.Contains(t, out, "trader trader_b | 周期 #12")
+		assert.Contains(t, out, "RSI7 61.50")
+		assert.Contains(t, out, "数据哈希: abc123")
+		assert.Contains(t, out, "来源: llm")
+		assert.Contains(t, out, "<reasoning>breakout</reasoning>")
+		assert.Contains(t, out, `"stop_loss": 49000`)
+		assert.Contains(t, out, "订单 42")
+		assert.Contains(t, out, "✓ BTCUSDT open_long 成功")
+		assert.NotContains(t, out, "ETHUSDT close_long 成功")
+	})
+
+	t.Run("JSON 输出包含匹配的决策", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("explain", []string{"--log-dir", logDir, "t-open", "--json"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		var e tradeExplanation
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &e))
+		assert.Equal(t, "trader_b", e.TraderID)
+		require.NotNil(t, e.Decision)
+		assert.Equal(t, 500.0, e.Decision.PositionSizeUSD)
+	})
+
+	t.Run("未找到交易", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("explain", []string{"--log-dir", logDir, "missing"}, &stdout, &stderr)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "未找到交易 missing")
+	})
+
+	t.Run("缺少 tradeID", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 1, runCommand("explain", []string{"--log-dir", logDir}, &stdout, &stderr))
+	})
+}
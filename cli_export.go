@@ -0,0 +1,201 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"nofx/analytics"
+	"nofx/export"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/store"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runExportCommand nofx export --from 2024-01-01 --to 2024-12-31 --format parquet --out ./export
+// 导出交易（有交易事件日志时从事件日志重建，否则从决策日志匹配）和本地缓存的K线
+func runExportCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := fs.String("from", "", "开始时间（YYYY-MM-DD 或 RFC3339）")
+	to := fs.String("to", "", "结束时间（YYYY-MM-DD 包含当天，或 RFC3339）")
+	format := fs.String("format", export.FormatCSV, "导出格式：csv / parquet / pdf")
+	outDir := fs.String("out", "export", "输出目录")
+	data := fs.String("data", "trades,klines", "导出内容：trades / klines（逗号分隔）")
+	traderID := fs.String("trader", "", "trader ID（为空时导出全部 trader）")
+	symbol := fs.String("symbol", "", "币种，如 BTCUSDT")
+	interval := fs.String("interval", "", "K线周期，如 1h（为空时导出全部周期）")
+	logDir := fs.String("log-dir", defaultDecisionLogDir, "决策日志根目录（未配置交易事件日志时使用）")
+	journalDB := fs.String("journal-db", os.Getenv("NOFX_JOURNAL_DB"), "交易事件日志 SQLite 路径")
+	klineDir := fs.String("kline-dir", os.Getenv("NOFX_KLINE_CACHE_DIR"), "K线缓存目录")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != export.FormatCSV && *format != export.FormatParquet && *format != export.FormatPDF {
+		return fmt.Errorf("不支持的导出格式: %s（可选: csv, parquet, pdf）", *format)
+	}
+
+	fromTime, err := logger.ParseJournalTime(*from, false)
+	if err != nil {
+		return err
+	}
+	toTime, err := logger.ParseJournalTime(*to, true)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+
+	ex := &exporter{out: *outDir, format: *format, stdout: stdout}
+	for _, item := range strings.Split(*data, ",") {
+		switch strings.TrimSpace(item) {
+		case "trades":
+			if *journalDB != "" {
+				err = ex.journalTrades(*journalDB, *traderID, *symbol, fromTime, toTime)
+			} else {
+				err = ex.decisionTrades(*logDir, *traderID, *symbol, fromTime, toTime)
+			}
+		case "klines":
+			if *klineDir == "" {
+				return fmt.Errorf("导出K线需要 --kline-dir（或设置 NOFX_KLINE_CACHE_DIR）")
+			}
+			err = ex.klines(*klineDir, *symbol, *interval, fromTime, toTime)
+		case "":
+			continue
+		default:
+			return fmt.Errorf("未知的导出内容: %s（可选: trades, klines）", item)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Fprintf(stdout, "\n共导出 %d 个文件到 %s\n", ex.files, *outDir)
+	return nil
+}
+
+// exporter 按格式写出表格文件
+type exporter struct {
+	out    string
+	format string
+	stdout io.Writer
+	files  int
+}
+
+// write 写出一个文件（name 不含扩展名）
+func (e *exporter) write(name string, table *export.Table) error {
+	path := filepath.Join(e.out, name+"."+e.format)
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %w", err)
+	}
+	if err := export.Write(f, table, e.format); err != nil {
+		f.Close()
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	e.files++
+	fmt.Fprintf(e.stdout, "✓ %s（%d 行）\n", path, len(table.Rows))
+	return nil
+}
+
+// journalTrades 从交易事件日志导出交易
+func (e *exporter) journalTrades(path, traderID, symbol string, from, to time.Time) error {
+	journal, err := store.OpenJournal(path)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	traders := []string{traderID}
+	if traderID == "" {
+		if traders, err = journal.TraderIDs(); err != nil {
+			return err
+		}
+	}
+	for _, id := range traders {
+		report, err := analytics.FromJournal(journal, id, analytics.Filter{Symbol: symbol, Since: from, Until: to})
+		if err != nil {
+			return err
+		}
+		if err := e.write("trades_"+id, export.JournalTradesTable(id, report.Trades)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// decisionTrades 从决策日志导出交易
+func (e *exporter) decisionTrades(logDir, traderID, symbol string, from, to time.Time) error {
+	traders := []string{traderID}
+	if traderID == "" {
+		entries, err := os.ReadDir(logDir)
+		if err != nil {
+			return fmt.Errorf("读取日志目录失败: %w", err)
+		}
+		traders = traders[:0]
+		for _, entry := range entries {
+			if entry.IsDir() {
+				traders = append(traders, entry.Name())
+			}
+		}
+	}
+
+	for _, id := range traders {
+		dir := filepath.Join(logDir, id)
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("trader %s 的决策日志不存在: %s", id, dir)
+		}
+		journal := logger.NewDecisionLogger(dir).(*logger.DecisionLogger)
+
+		// 分页读取全部交易
+		var trades []logger.TradeOutcome
+		for offset := 0; ; {
+			page, err := journal.QueryTrades(logger.TradeQuery{
+				Symbol: symbol, From: from, To: to, Offset: offset, Limit: logger.MaxJournalPageSize,
+			})
+			if err != nil {
+				return err
+			}
+			trades = append(trades, page.Trades...)
+			offset += len(page.Trades)
+			if len(page.Trades) == 0 || offset >= page.Total {
+				break
+			}
+		}
+		if err := e.write("trades_"+id, export.DecisionTradesTable(id, trades)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// klines 导出本地缓存的K线
+func (e *exporter) klines(dir, symbol, interval string, from, to time.Time) error {
+	cache, err := market.NewPersistentKlineCache(dir, 0)
+	if err != nil {
+		return err
+	}
+	series, err := cache.Series()
+	if err != nil {
+		return err
+	}
+	if to.IsZero() {
+		to = time.Now()
+	}
+	for _, s := range series {
+		if (symbol != "" && !strings.EqualFold(s.Symbol, symbol)) || (interval != "" && s.Interval != interval) {
+			continue
+		}
+		klines := cache.Range(s.Source, s.Symbol, s.Interval, from, to.Add(-time.Millisecond))
+		name := fmt.Sprintf("klines_%s_%s_%s", s.Source, s.Symbol, s.Interval)
+		if err := e.write(name, export.KlinesTable(s.Symbol, s.Interval, klines)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/market"
+	"nofx/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunExportCommand(t *testing.T) {
+	logDir := t.TempDir()
+	writeJournalFixture(t, filepath.Join(logDir, "trader_1"))
+
+	klineDir := t.TempDir()
+	cache, err := market.NewPersistentKlineCache(klineDir, 0)
+	require.NoError(t, err)
+	day := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC).UnixMilli()
+	require.NoError(t, cache.Store("binance", "BTCUSDT", "1h", []market.Kline{
+		{OpenTime: day - 3600000, CloseTime: day - 1, Open: 1, High: 1, Low: 1, Close: 1},
+		{OpenTime: day, CloseTime: day + 3599999, Open: 2, High: 2, Low: 2, Close: 2},
+	}))
+
+	t.Run("决策日志交易和K线导出为 CSV", func(t *testing.T) {
+		out := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := runCommand("export", []string{
+			"--log-dir", logDir, "--kline-dir", klineDir, "--journal-db", "", "--out", out,
+		}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		trades, err := os.ReadFile(filepath.Join(out, "trades_trader_1.csv"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(trades)), "\n")
+		require.Len(t, lines, 2)
+		assert.Contains(t, lines[1], "trader_1,BTCUSDT,long")
+
+		klines, err := os.ReadFile(filepath.Join(out, "klines_binance_BTCUSDT_1h.csv"))
+		require.NoError(t, err)
+		assert.Len(t, strings.Split(strings.TrimSpace(string(klines)), "\n"), 3)
+	})
+
+	t.Run("按时间范围导出K线", func(t *testing.T) {
+		out := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := runCommand("export", []string{
+			"--kline-dir", klineDir, "--data", "klines", "--from", "2024-01-02T00:00:00Z", "--out", out,
+		}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		klines, err := os.ReadFile(filepath.Join(out, "klines_binance_BTCUSDT_1h.csv"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(klines)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[1], "BTCUSDT,1h,2024-01-02T00:00:00Z"))
+	})
+
+	t.Run("交易事件日志导出为 Parquet", func(t *testing.T) {
+		dbPath := filepath.Join(t.TempDir(), "journal.db")
+		journal, err := store.OpenJournal(dbPath)
+		require.NoError(t, err)
+		journal.Record("t1", store.EventFill, "ETHUSDT", "short", store.Fill{Action: "open", Quantity: 1, Price: 100})
+		journal.Record("t1", store.EventFill, "ETHUSDT", "short", store.Fill{Action: "close", Quantity: 1, Price: 90})
+		require.NoError(t, journal.Close())
+
+		out := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := runCommand("export", []string{
+			"--journal-db", dbPath, "--data", "trades", "--format", "parquet", "--out", out,
+		}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		data, err := os.ReadFile(filepath.Join(out, "trades_t1.parquet"))
+		require.NoError(t, err)
+		assert.Equal(t, "PAR1", string(data[:4]))
+		assert.Contains(t, stdout.String(), "共导出 1 个文件")
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 1, runCommand("export", []string{"--format", "xlsx"}, &stdout, &stderr))
+		assert.Equal(t, 1, runCommand("export", []string{"--data", "klines", "--kline-dir", "", "--out", t.TempDir()}, &stdout, &stderr))
+	})
+}
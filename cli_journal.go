@@ -0,0 +1,258 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// defaultDecisionLogDir 决策日志根目录（每个 trader 一个子目录）
+const defaultDecisionLogDir = "decision_logs"
+
+// journalFlags 两个查询子命令共用的参数
+type journalFlags struct {
+	logDir   *string
+	traderID *string
+	symbol   *string
+	from     *string
+	to       *string
+	order    *string
+	offset   *int
+	limit    *int
+	asJSON   *bool
+}
+
+// newJournalFlagSet 创建带公共参数的子命令 FlagSet
+func newJournalFlagSet(name string) (*flag.FlagSet, *journalFlags) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	return fs, &journalFlags{
+		logDir:   fs.String("log-dir", defaultDecisionLogDir, "决策日志根目录"),
+		traderID: fs.String("trader", "", "trader ID（只有一个 trader 时可省略）"),
+		symbol:   fs.String("symbol", "", "币种，如 BTCUSDT"),
+		from:     fs.String("from", "", "开始时间（YYYY-MM-DD 或 RFC3339）"),
+		to:       fs.String("to", "", "结束时间（YYYY-MM-DD 包含当天，或 RFC3339）"),
+		order:    fs.String("order", "desc", "排序方向：asc / desc"),
+		offset:   fs.Int("offset", 0, "分页偏移"),
+		limit:    fs.Int("limit", logger.DefaultJournalPageSize, "每页条数"),
+		asJSON:   fs.Bool("json", false, "以 JSON 输出"),
+	}
+}
+
+// openJournal 打开指定 trader 的决策日志（不存在时不创建目录）
+func (f *journalFlags) openJournal() (*logger.DecisionLogger, error) {
+	traderID := *f.traderID
+	if traderID == "" {
+		entries, err := os.ReadDir(*f.logDir)
+		if err != nil {
+			return nil, fmt.Errorf("读取日志目录失败: %w", err)
+		}
+		var traders []string
+		for _, e := range entries {
+			if e.IsDir() {
+				traders = append(traders, e.Name())
+			}
+		}
+		sort.Strings(traders)
+		if len(traders) != 1 {
+			return nil, fmt.Errorf("请使用 --trader 指定 trader ID（可用: %s）", strings.Join(traders, ", "))
+		}
+		traderID = traders[0]
+	}
+
+	dir := filepath.Join(*f.logDir, traderID)
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("trader %s 的决策日志不存在: %s", traderID, dir)
+	}
+	return logger.NewDecisionLogger(dir).(*logger.DecisionLogger), nil
+}
+
+// timeRange 解析 --from / --to
+func (f *journalFlags) timeRange() (time.Time, time.Time, error) {
+	from, err := logger.ParseJournalTime(*f.from, false)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	to, err := logger.ParseJournalTime(*f.to, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// runTradesCommand nofx trades --symbol BTCUSDT --from 2024-01-01 --result loss
+func runTradesCommand(args []string, stdout io.Writer) error {
+	fs, f := newJournalFlagSet("trades")
+	side := fs.String("side", "", "方向：long / short")
+	result := fs.String("result", "", "结果：win / loss / breakeven")
+	sortBy := fs.String("sort", "close_time", "排序字段：close_time / open_time / pnl / pnl_pct / symbol")
+	locale := fs.String("locale", "", "数字格式语言，如 en / zh / de / fr（影响千位分隔符和小数点）")
+	decimals := fs.Int("decimals", 0, "金额小数位（0=默认2位）")
+	compact := fs.Bool("compact", false, "大额金额使用缩写（如 1.25M、3.2亿）")
+	asCSV := fs.Bool("csv", false, "以 CSV 输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	nf := logger.NumberFormatForLocale(*locale)
+	nf.Decimals = *decimals
+	nf.Compact = *compact
+
+	from, to, err := f.timeRange()
+	if err != nil {
+		return err
+	}
+	journal, err := f.openJournal()
+	if err != nil {
+		return err
+	}
+
+	page, err := journal.QueryTrades(logger.TradeQuery{
+		Symbol: *f.symbol,
+		Side:   *side,
+		Result: *result,
+		From:   from,
+		To:     to,
+		SortBy: *sortBy,
+		Desc:   *f.order == "desc",
+		Offset: *f.offset,
+		Limit:  *f.limit,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *f.asJSON {
+		return writeJSON(stdout, page)
+	}
+	if *asCSV {
+		return writeTradesCSV(stdout, page.Trades, nf)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CLOSE TIME\tSYMBOL\tSIDE\tLEV\tOPEN\tCLOSE\tVALUE\tPNL\tPNL%\tDURATION")
+	for _, t := range page.Trades {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%dx\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			t.CloseTime.Local().Format("2006-01-02 15:04:05"), t.Symbol, t.Side, t.Leverage,
+			nf.Price(t.OpenPrice), nf.Price(t.ClosePrice), nf.Amount(t.PositionValue),
+			nf.SignedAmount(t.PnL), nf.Percent(t.PnLPct), t.Duration)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "\n共 %d 笔交易，显示 %d-%d\n", page.Total, pageStart(page.Offset, len(page.Trades)), page.Offset+len(page.Trades))
+	return nil
+}
+
+// runDecisionsCommand nofx decisions --symbol BTCUSDT --action open_long --success=false
+func runDecisionsCommand(args []string, stdout io.Writer) error {
+	fs, f := newJournalFlagSet("decisions")
+	action := fs.String("action", "", "动作，如 open_long / close_short")
+	success := fs.String("success", "", "周期是否成功：true / false")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	from, to, err := f.timeRange()
+	if err != nil {
+		return err
+	}
+	query := logger.DecisionQuery{
+		Symbol: *f.symbol,
+		Action: *action,
+		From:   from,
+		To:     to,
+		Desc:   *f.order == "desc",
+		Offset: *f.offset,
+		Limit:  *f.limit,
+	}
+	switch *success {
+	case "":
+	case "true", "false":
+		ok := *success == "true"
+		query.Success = &ok
+	default:
+		return fmt.Errorf("--success 必须为 true 或 false")
+	}
+
+	journal, err := f.openJournal()
+	if err != nil {
+		return err
+	}
+	page, err := journal.QueryDecisions(query)
+	if err != nil {
+		return err
+	}
+
+	if *f.asJSON {
+		return writeJSON(stdout, page)
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tCYCLE\tOK\tACTIONS\tERROR")
+	for _, r := range page.Records {
+		actions := make([]string, 0, len(r.Decisions))
+		for _, a := range r.Decisions {
+			status := "✓"
+			if !a.Success {
+				status = "✗"
+			}
+			actions = append(actions, fmt.Sprintf("%s %s%s", a.Action, a.Symbol, status))
+		}
+		fmt.Fprintf(w, "%s\t#%d\t%t\t%s\t%s\n",
+			r.Timestamp.Local().Format("2006-01-02 15:04:05"), r.CycleNumber, r.Success,
+			strings.Join(actions, ", "), r.ErrorMessage)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "\n共 %d 条决策，显示 %d-%d\n", page.Total, pageStart(page.Offset, len(page.Records)), page.Offset+len(page.Records))
+	return nil
+}
+
+// writeTradesCSV 以 CSV 导出交易（小数点为 "," 时使用 ";" 作为分隔符）
+func writeTradesCSV(out io.Writer, trades []logger.TradeOutcome, nf logger.NumberFormat) error {
+	w := csv.NewWriter(out)
+	if nf.DecimalSeparator == "," {
+		w.Comma = ';'
+	}
+	if err := w.Write([]string{"open_time", "close_time", "symbol", "side", "leverage", "quantity",
+		"open_price", "close_price", "position_value", "pnl", "pnl_pct", "duration"}); err != nil {
+		return err
+	}
+	for _, t := range trades {
+		if err := w.Write([]string{
+			t.OpenTime.Local().Format(time.RFC3339), t.CloseTime.Local().Format(time.RFC3339),
+			t.Symbol, t.Side, strconv.Itoa(t.Leverage), nf.Quantity(t.Quantity),
+			nf.Price(t.OpenPrice), nf.Price(t.ClosePrice), nf.Amount(t.PositionValue),
+			nf.SignedAmount(t.PnL), nf.Percent(t.PnLPct), t.Duration,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// pageStart 当前页第一条的序号（从1开始，空页为0）
+func pageStart(offset, count int) int {
+	if count == 0 {
+		return 0
+	}
+	return offset + 1
+}
+
+// writeJSON 以缩进 JSON 输出
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"nofx/logger"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeJournalFixture 写入一笔 BTC 亏损交易的开平仓记录
+func writeJournalFixture(t *testing.T, dir string) {
+	require.NoError(t, os.MkdirAll(dir, 0700))
+	openTime := time.Date(2024, 1, 2, 10, 0, 0, 0, time.Local)
+	closeTime := openTime.Add(time.Hour)
+
+	records := []logger.DecisionRecord{
+		{Timestamp: openTime, CycleNumber: 1, Success: true, Decisions: []logger.DecisionAction{
+			{Action: "open_long", Symbol: "BTCUSDT", Quantity: 1, Leverage: 5, Price: 100, Timestamp: openTime, Success: true},
+		}},
+		{Timestamp: closeTime, CycleNumber: 2, Success: true, Decisions: []logger.DecisionAction{
+			{Action: "close_long", Symbol: "BTCUSDT", Price: 90, Timestamp: closeTime, Success: true},
+		}},
+	}
+	for _, r := range records {
+		data, err := json.Marshal(r)
+		require.NoError(t, err)
+		name := "decision_" + r.Timestamp.Format("20060102_150405") + ".json"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0600))
+	}
+}
+
+func TestRunJournalCommand(t *testing.T) {
+	logDir := t.TempDir()
+	writeJournalFixture(t, filepath.Join(logDir, "trader_1"))
+
+	t.Run("trades 使用唯一 trader 并输出 JSON", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("trades", []string{
+			"--log-dir", logDir, "--symbol", "BTCUSDT", "--from", "2024-01-01", "--result", "loss", "--json",
+		}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		var page logger.TradePage
+		require.NoError(t, json.Unmarshal(stdout.Bytes(), &page))
+		require.Equal(t, 1, page.Total)
+		assert.Equal(t, 90.0, page.Trades[0].ClosePrice)
+	})
+
+	t.Run("trades 表格输出", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("trades", []string{"--log-dir", logDir, "--result", "win"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		assert.Contains(t, stdout.String(), "共 0 笔交易")
+	})
+
+	t.Run("trades 按语言导出 CSV", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("trades", []string{"--log-dir", logDir, "--csv", "--locale", "de"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasPrefix(lines[0], "open_time;close_time;symbol"))
+		assert.Contains(t, lines[1], ";BTCUSDT;long;5;1;100,00;90,0000;100,00;-10,10;")
+	})
+
+	t.Run("decisions 按动作筛选", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("decisions", []string{"--log-dir", logDir, "--action", "open_long"}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+		assert.Contains(t, stdout.String(), "open_long BTCUSDT")
+		assert.Contains(t, stdout.String(), "共 1 条决策")
+	})
+
+	t.Run("多个 trader 时要求指定 --trader", func(t *testing.T) {
+		require.NoError(t, os.MkdirAll(filepath.Join(logDir, "trader_2"), 0700))
+		var stdout, stderr bytes.Buffer
+		code := runCommand("trades", []string{"--log-dir", logDir}, &stdout, &stderr)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr.String(), "trader_1, trader_2")
+	})
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"nofx/secretstore"
+	"os"
+	"strings"
+)
+
+// runKeysCommand nofx keys init|set|list|remove|passwd [--file keys.enc]
+// 管理本地加密密钥文件：配置和交易员中写 secret://NAME，启动时设置 SECRETS_PROVIDER=local 用主口令解锁
+func runKeysCommand(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("用法: nofx keys init|set NAME|list|remove NAME|passwd [--file %s]", secretstore.DefaultKeyFilePath)
+	}
+	sub := args[0]
+	fs := flag.NewFlagSet("keys "+sub, flag.ContinueOnError)
+	file := fs.String("file", secretstore.KeyFilePathFromEnv(), "密钥文件路径（默认 NOFX_KEYS_FILE 或 keys.enc）")
+	fromStdin := fs.Bool("stdin", false, "set: 从标准输入读取密钥值（默认在终端中输入，不回显）")
+	if err := fs.Parse(args[1:]); err != nil {
+		return err
+	}
+	name := fs.Arg(0)
+	if fs.NArg() > 1 { // 允许参数写在 NAME 之后：nofx keys set NAME --stdin
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+
+	switch sub {
+	case "init":
+		if _, err := os.Stat(*file); err == nil {
+			return fmt.Errorf("密钥文件 %s 已存在", *file)
+		}
+		passphrase, err := newKeysPassphrase()
+		if err != nil {
+			return err
+		}
+		if err := secretstore.WriteKeyFile(*file, passphrase, nil); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✅ 已创建加密密钥文件 %s\n", *file)
+		return nil
+
+	case "set", "remove":
+		if name == "" {
+			return fmt.Errorf("用法: nofx keys %s NAME", sub)
+		}
+		passphrase, values, err := openKeysFile(*file)
+		if err != nil {
+			return err
+		}
+		if sub == "remove" {
+			if _, ok := values[name]; !ok {
+				return fmt.Errorf("密钥 %s 不存在", name)
+			}
+			delete(values, name)
+		} else {
+			value, err := readKeyValue(name, *fromStdin)
+			if err != nil {
+				return err
+			}
+			values[name] = value
+		}
+		if err := secretstore.WriteKeyFile(*file, passphrase, values); err != nil {
+			return err
+		}
+		if sub == "remove" {
+			fmt.Fprintf(stdout, "🗑️  已删除 %s\n", name)
+		} else {
+			fmt.Fprintf(stdout, "✅ 已保存 %s（引用方式: %s%s）\n", name, secretstore.RefPrefix, name)
+		}
+		return nil
+
+	case "list":
+		_, values, err := openKeysFile(*file)
+		if err != nil {
+			return err
+		}
+		for _, key := range secretstore.SortedKeys(values) {
+			fmt.Fprintln(stdout, key)
+		}
+		return nil
+
+	case "passwd":
+		_, values, err := openKeysFile(*file)
+		if err != nil {
+			return err
+		}
+		passphrase, err := newKeysPassphrase()
+		if err != nil {
+			return err
+		}
+		if err := secretstore.WriteKeyFile(*file, passphrase, values); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "✅ 已更换口令（%d 个密钥已重新加密）\n", len(values))
+		return nil
+	}
+	return fmt.Errorf("未知的 keys 子命令: %s（可选: init, set, list, remove, passwd）", sub)
+}
+
+// openKeysFile 用 NOFX_KEYS_PASSPHRASE（或终端输入的口令）解密密钥文件
+func openKeysFile(path string) (string, map[string]string, error) {
+	if _, err := os.Stat(path); err != nil {
+		return "", nil, fmt.Errorf("密钥文件 %s 不存在，请先运行 nofx keys init", path)
+	}
+	passphrase, err := secretstore.KeyFilePassphraseFromEnv()
+	if err != nil {
+		return "", nil, err
+	}
+	values, err := secretstore.ReadKeyFile(path, passphrase)
+	if err != nil {
+		return "", nil, err
+	}
+	return passphrase, values, nil
+}
+
+// newKeysPassphrase 在终端中输入两次新口令
+func newKeysPassphrase() (string, error) {
+	passphrase, err := secretstore.PromptPassphrase("🔐 设置新口令: ")
+	if err != nil {
+		return "", err
+	}
+	if len(passphrase) < 8 {
+		return "", fmt.Errorf("口令至少需要 8 个字符")
+	}
+	confirm, err := secretstore.PromptPassphrase("🔐 再次输入新口令: ")
+	if err != nil {
+		return "", err
+	}
+	if confirm != passphrase {
+		return "", fmt.Errorf("两次输入的口令不一致")
+	}
+	return passphrase, nil
+}
+
+// readKeyValue 读取密钥值（--stdin 时读取标准输入的第一行，否则在终端中输入）
+func readKeyValue(name string, fromStdin bool) (string, error) {
+	var value string
+	if fromStdin {
+		line, err := bufio.NewReader(cliStdin).ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("读取标准输入失败: %w", err)
+		}
+		value = strings.TrimRight(line, "\r\n")
+	} else {
+		var err error
+		if value, err = secretstore.PromptPassphrase(fmt.Sprintf("🔑 %s: ", name)); err != nil {
+			return "", err
+		}
+	}
+	if value == "" {
+		return "", fmt.Errorf("密钥值不能为空")
+	}
+	return value, nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/secretstore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubPrompts 依次返回给定的终端输入
+func stubPrompts(t *testing.T, answers ...string) {
+	t.Helper()
+	original := secretstore.PromptPassphrase
+	secretstore.PromptPassphrase = func(prompt string) (string, error) {
+		require.NotEmpty(t, answers, "unexpected prompt %q", prompt)
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+	t.Cleanup(func() { secretstore.PromptPassphrase = original })
+}
+
+func TestRunKeysCommand(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keys.enc")
+	t.Setenv("NOFX_KEYS_PASSPHRASE", "")
+	t.Setenv("NOFX_KEYS_PASSPHRASE_FILE", "")
+	run := func(args ...string) (string, int) {
+		var stdout, stderr bytes.Buffer
+		code := runCommand("keys", append(args, "--file", file), &stdout, &stderr)
+		return stdout.String() + stderr.String(), code
+	}
+
+	stubPrompts(t, "short")
+	out, code := run("init")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "至少需要 8 个字符")
+
+	stubPrompts(t, "master-pass", "master-pass")
+	out, code = run("init")
+	require.Equal(t, 0, code, out)
+
+	// 终端输入密钥值
+	stubPrompts(t, "master-pass", "abc")
+	out, code = run("set", "BINANCE_API_KEY")
+	require.Equal(t, 0, code, out)
+	assert.Contains(t, out, "secret://BINANCE_API_KEY")
+
+	// 口令来自环境变量，密钥值来自标准输入
+	t.Setenv("NOFX_KEYS_PASSPHRASE", "master-pass")
+	originalStdin := cliStdin
+	cliStdin = strings.NewReader("xyz\n")
+	defer func() { cliStdin = originalStdin }()
+	out, code = run("set", "BINANCE_SECRET_KEY", "--stdin")
+	require.Equal(t, 0, code, out)
+
+	out, code = run("list")
+	require.Equal(t, 0, code, out)
+	assert.Equal(t, "BINANCE_API_KEY\nBINANCE_SECRET_KEY\n", out)
+
+	values, err := secretstore.ReadKeyFile(file, "master-pass")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"BINANCE_API_KEY": "abc", "BINANCE_SECRET_KEY": "xyz"}, values)
+
+	out, code = run("remove", "BINANCE_API_KEY")
+	require.Equal(t, 0, code, out)
+
+	stubPrompts(t, "new-master", "new-master")
+	out, code = run("passwd")
+	require.Equal(t, 0, code, out)
+	values, err = secretstore.ReadKeyFile(file, "new-master")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"BINANCE_SECRET_KEY": "xyz"}, values)
+
+	// 旧口令失效
+	out, code = run("list")
+	assert.Equal(t, 1, code)
+	assert.Contains(t, out, "口令错误")
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"nofx/analytics"
+	"nofx/export"
+	"nofx/logger"
+	"nofx/store"
+	"nofx/trader"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// runStatementCommand nofx statement --from 2024-01-01 --to 2024-12-31 --method fifo --period month --format pdf
+// 用交易事件日志中的成交（可选合并交易所资金费、手续费流水）按 FIFO / 加权平均计算成本，
+// 输出每笔平仓明细、按周期和按币种的已实现盈亏（报税用）
+func runStatementCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("statement", flag.ContinueOnError)
+	from := fs.String("from", "", "开始时间（YYYY-MM-DD 或 RFC3339）")
+	to := fs.String("to", "", "结束时间（YYYY-MM-DD 包含当天，或 RFC3339）")
+	method := fs.String("method", analytics.CostFIFO, "成本计算方法：fifo / average")
+	period := fs.String("period", analytics.PeriodMonth, "统计周期：month / quarter / year")
+	format := fs.String("format", export.FormatCSV, "输出格式：csv / pdf")
+	outDir := fs.String("out", "statement", "输出目录")
+	traderID := fs.String("trader", "", "trader ID（为空时每个 trader 各输出一份）")
+	symbol := fs.String("symbol", "", "币种，如 BTCUSDT")
+	journalDB := fs.String("journal-db", os.Getenv("NOFX_JOURNAL_DB"), "交易事件日志 SQLite 路径")
+	bills := fs.Bool("bills", false, "从交易所拉取资金费和手续费流水（目前支持 binance，需要 --trader）")
+	exFlags := addExchangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *format != export.FormatCSV && *format != export.FormatPDF {
+		return fmt.Errorf("不支持的输出格式: %s（可选: csv, pdf）", *format)
+	}
+	if *journalDB == "" {
+		return fmt.Errorf("需要 --journal-db（或设置 NOFX_JOURNAL_DB）")
+	}
+	fromTime, err := logger.ParseJournalTime(*from, false)
+	if err != nil {
+		return err
+	}
+	toTime, err := logger.ParseJournalTime(*to, true)
+	if err != nil {
+		return err
+	}
+	opts := analytics.StatementOptions{Method: *method, Period: *period, Symbol: *symbol, Since: fromTime, Until: toTime}
+
+	journal, err := store.OpenJournal(*journalDB)
+	if err != nil {
+		return err
+	}
+	defer journal.Close()
+
+	traders := []string{*traderID}
+	if *traderID == "" {
+		if traders, err = journal.TraderIDs(); err != nil {
+			return err
+		}
+	}
+
+	var billList []analytics.Bill
+	if *bills {
+		// 资金流水是账户级的，多个 trader 共用账户时无法区分
+		if *traderID == "" {
+			return fmt.Errorf("--bills 需要用 --trader 指定 trader ID")
+		}
+		if billList, err = fetchStatementBills(exFlags, fromTime, toTime); err != nil {
+			return err
+		}
+	}
+
+	statements := make(map[string]*analytics.Statement, len(traders))
+	for _, id := range traders {
+		st, err := analytics.StatementFromJournal(journal, id, billList, opts)
+		if err != nil {
+			return err
+		}
+		statements[id] = st
+	}
+	if *exFlags.asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(statements)
+	}
+
+	if err := os.MkdirAll(*outDir, 0755); err != nil {
+		return fmt.Errorf("创建输出目录失败: %w", err)
+	}
+	ex := &exporter{out: *outDir, format: *format, stdout: stdout}
+	for _, id := range traders {
+		st := statements[id]
+		if *format == export.FormatPDF {
+			err = ex.writePDF("statement_"+id, "NOFX Account Statement - "+id, statementSections(st)...)
+		} else {
+			err = ex.writeStatementCSV(id, st)
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "  %s: 已实现盈亏 %.2f | 手续费 %.2f | 资金费 %+.2f | 净额 %.2f（%d 笔平仓）\n",
+			id, st.Total.RealizedPnL, st.Total.Fees, st.Total.Funding, st.Total.Net, st.Total.Disposals)
+		if st.Unpriced > 0 {
+			fmt.Fprintf(stdout, "  ⚠️  %s 有 %d 笔平仓价格未知，未计入盈亏\n", id, st.Unpriced)
+		}
+	}
+	fmt.Fprintf(stdout, "\n共导出 %d 个文件到 %s\n", ex.files, *outDir)
+	return nil
+}
+
+// fetchStatementBills 从交易所拉取资金费和手续费流水
+func fetchStatementBills(f *exchangeFlags, from, to time.Time) ([]analytics.Bill, error) {
+	t, exchange, err := f.openTrader()
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := t.(trader.IncomeHistoryProvider)
+	if !ok {
+		return nil, fmt.Errorf("%s 暂不支持查询资金流水", exchange)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *f.timeout)
+	defer cancel()
+	records, err := provider.GetIncomeHistory(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	bills := make([]analytics.Bill, 0, len(records))
+	for _, r := range records {
+		kind := analytics.BillFunding
+		if r.Type == trader.IncomeCommission {
+			kind = analytics.BillFee
+		}
+		bills = append(bills, analytics.Bill{Time: r.Time, Symbol: r.Symbol, Kind: kind, Amount: r.Amount, Asset: r.Asset})
+	}
+	return bills, nil
+}
+
+// writeStatementCSV 对账单的三张表各写一个 CSV
+func (e *exporter) writeStatementCSV(traderID string, st *analytics.Statement) error {
+	prefix := "statement_" + traderID
+	if err := e.write(prefix+"_disposals", export.DisposalsTable(st.Disposals)); err != nil {
+		return err
+	}
+	if err := e.write(prefix+"_periods", export.StatementPeriodsTable(st.Periods)); err != nil {
+		return err
+	}
+	return e.write(prefix+"_coins", export.StatementCoinsTable(st.Coins))
+}
+
+// writePDF 写出一个多节 PDF 文件（name 不含扩展名）
+func (e *exporter) writePDF(name, title string, sections ...export.PDFSection) error {
+	path := filepath.Join(e.out, name+".pdf")
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("创建导出文件失败: %w", err)
+	}
+	if err := export.WritePDF(f, title, sections...); err != nil {
+		f.Close()
+		return fmt.Errorf("写入 %s 失败: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	e.files++
+	fmt.Fprintf(e.stdout, "✓ %s\n", path)
+	return nil
+}
+
+// statementSections 对账单 PDF 的各节（PDF 内置字体只支持 ASCII，使用英文）
+func statementSections(st *analytics.Statement) []export.PDFSection {
+	formatTime := func(t time.Time) string {
+		if t.IsZero() {
+			return "-"
+		}
+		return t.UTC().Format(time.RFC3339)
+	}
+	method := "FIFO"
+	if st.Method == analytics.CostAverage {
+		method = "Weighted average"
+	}
+	summary := []string{
+		fmt.Sprintf("Period:          %s to %s (UTC)", formatTime(st.Since), formatTime(st.Until)),
+		fmt.Sprintf("Cost basis:      %s, grouped by %s", method, st.Period),
+		fmt.Sprintf("Fees source:     %s", st.FeeSource),
+		fmt.Sprintf("Realized PnL:    %.2f", st.Total.RealizedPnL),
+		fmt.Sprintf("Fees:            %.2f", st.Total.Fees),
+		fmt.Sprintf("Funding:         %+.2f", st.Total.Funding),
+		fmt.Sprintf("Net:             %.2f (%d disposals)", st.Total.Net, st.Total.Disposals),
+	}
+	if st.Unpriced > 0 {
+		summary = append(summary, fmt.Sprintf("Excluded:        %d closes without a known fill price", st.Unpriced))
+	}
+	return []export.PDFSection{
+		{Title: "Summary", Lines: summary},
+		{Title: "By period", Table: export.StatementPeriodsTable(st.Periods)},
+		{Title: "By coin", Table: export.StatementCoinsTable(st.Coins)},
+		{Title: "Disposals", Table: export.DisposalsTable(st.Disposals)},
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunStatementCommand(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "journal.db")
+	journal, err := store.OpenJournal(dbPath)
+	require.NoError(t, err)
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 100, Fee: 0.1})
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 200, Fee: 0.1})
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "close", Quantity: 1, Price: 180, Fee: 0.1})
+	require.NoError(t, journal.Close())
+
+	t.Run("FIFO 对账单导出为 CSV", func(t *testing.T) {
+		out := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := runCommand("statement", []string{"--journal-db", dbPath, "--out", out}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		disposals, err := os.ReadFile(filepath.Join(out, "statement_t1_disposals.csv"))
+		require.NoError(t, err)
+		lines := strings.Split(strings.TrimSpace(string(disposals)), "\n")
+		require.Len(t, lines, 2)
+		assert.True(t, strings.HasSuffix(lines[1], ",1,180,100,80"), lines[1])
+
+		for _, name := range []string{"statement_t1_periods.csv", "statement_t1_coins.csv"} {
+			_, err := os.Stat(filepath.Join(out, name))
+			assert.NoError(t, err, name)
+		}
+		assert.Contains(t, stdout.String(), "共导出 3 个文件")
+	})
+
+	t.Run("加权平均对账单导出为 PDF", func(t *testing.T) {
+		out := t.TempDir()
+		var stdout, stderr bytes.Buffer
+		code := runCommand("statement", []string{
+			"--journal-db", dbPath, "--method", "average", "--period", "year", "--format", "pdf", "--out", out,
+		}, &stdout, &stderr)
+		require.Equal(t, 0, code, stderr.String())
+
+		data, err := os.ReadFile(filepath.Join(out, "statement_t1.pdf"))
+		require.NoError(t, err)
+		assert.True(t, bytes.HasPrefix(data, []byte("%PDF-")))
+		assert.Contains(t, string(data), "Weighted average")
+		assert.Contains(t, stdout.String(), "已实现盈亏 30.00")
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.Equal(t, 1, runCommand("statement", []string{"--journal-db", dbPath, "--format", "xlsx"}, &stdout, &stderr))
+		assert.Equal(t, 1, runCommand("statement", []string{"--journal-db", dbPath, "--method", "lifo", "--out", t.TempDir()}, &stdout, &stderr))
+		assert.Equal(t, 1, runCommand("statement", []string{"--journal-db", dbPath, "--bills"}, &stdout, &stderr))
+		assert.Equal(t, 1, runCommand("statement", []string{"--journal-db", ""}, &stdout, &stderr))
+	})
+}
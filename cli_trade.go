@@ -0,0 +1,317 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"nofx/config"
+	"nofx/crypto"
+	"nofx/secretstore"
+	"nofx/trader"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// cliStdin 子命令读取确认和密钥值的输入（测试中替换）
+var cliStdin io.Reader = os.Stdin
+
+// exchangeFlags positions / balance / close 共用的参数：交易所账户来自配置文件，--db 时来自数据库
+type exchangeFlags struct {
+	configPath *string
+	exchange   *string
+	user       *string
+	dbPath     *string
+	asJSON     *bool
+	timeout    *time.Duration
+}
+
+func addExchangeFlags(fs *flag.FlagSet) *exchangeFlags {
+	defaultConfigPath := "config.json"
+	if envPath := strings.TrimSpace(os.Getenv("NOFX_CONFIG_FILE")); envPath != "" {
+		defaultConfigPath = envPath
+	}
+	return &exchangeFlags{
+		configPath: fs.String("config", defaultConfigPath, "配置文件路径（读取 exchanges）"),
+		exchange:   fs.String("exchange", "", "交易所: binance / hyperliquid / aster（为空时使用第一个启用的账户）"),
+		user:       fs.String("user", "admin", "账户所属用户"),
+		dbPath:     fs.String("db", "", "从数据库读取交易所账户（如 config.db，需要 DATA_ENCRYPTION_KEY 和 secrets/rsa_key）"),
+		asJSON:     fs.Bool("json", false, "以 JSON 输出"),
+		timeout:    fs.Duration("timeout", 30*time.Second, "交易所请求超时"),
+	}
+}
+
+// newCLITrader 创建交易所交易器（测试中替换）
+var newCLITrader = func(cfg trader.AutoTraderConfig) (trader.Trader, error) {
+	return trader.NewExchangeTrader(cfg, cfg.ID)
+}
+
+// openTrader 读取交易所账户（解析 secret:// 引用）并创建交易器
+func (f *exchangeFlags) openTrader() (trader.Trader, string, error) {
+	_ = godotenv.Load()
+	if err := secretstore.InitFromEnv(); err != nil {
+		return nil, "", fmt.Errorf("初始化外部密钥提供方失败: %w", err)
+	}
+
+	accounts, err := f.accounts()
+	if err != nil {
+		return nil, "", err
+	}
+	var account *config.ExchangeFileConfig
+	for i := range accounts {
+		ex := &accounts[i]
+		if ex.Owner() == *f.user && ex.IsEnabled() && (*f.exchange == "" || ex.ID == *f.exchange) {
+			account = ex
+			break
+		}
+	}
+	if account == nil {
+		if *f.exchange == "" {
+			return nil, "", fmt.Errorf("用户 %s 没有启用的交易所账户", *f.user)
+		}
+		return nil, "", fmt.Errorf("用户 %s 没有启用的 %s 账户", *f.user, *f.exchange)
+	}
+
+	cfg := trader.AutoTraderConfig{
+		ID:                    *f.user + "_cli",
+		Name:                  "cli",
+		Exchange:              account.ID,
+		BinanceAPIKey:         account.APIKey,
+		BinanceSecretKey:      account.SecretKey,
+		HyperliquidPrivateKey: account.APIKey,
+		HyperliquidWalletAddr: account.HyperliquidWalletAddr,
+		HyperliquidTestnet:    account.Testnet,
+		AsterUser:             account.AsterUser,
+		AsterSigner:           account.AsterSigner,
+		AsterPrivateKey:       account.AsterPrivateKey,
+	}
+	if err := secretstore.ResolveFields(&cfg.BinanceAPIKey, &cfg.BinanceSecretKey, &cfg.HyperliquidPrivateKey, &cfg.AsterPrivateKey); err != nil {
+		return nil, "", err
+	}
+	t, err := newCLITrader(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	return t, account.ID, nil
+}
+
+// accounts 交易所账户列表（配置文件或数据库）
+func (f *exchangeFlags) accounts() ([]config.ExchangeFileConfig, error) {
+	if *f.dbPath == "" {
+		cfg, err := loadConfigFile(*f.configPath)
+		if err != nil {
+			return nil, err
+		}
+		if len(cfg.Exchanges) == 0 {
+			return nil, fmt.Errorf("%s 中没有配置 exchanges（或使用 --db 从数据库读取）", *f.configPath)
+		}
+		return cfg.Exchanges, nil
+	}
+
+	if _, err := os.Stat(*f.dbPath); err != nil {
+		return nil, fmt.Errorf("数据库 %s 不存在", *f.dbPath)
+	}
+	database, err := config.NewDatabase(*f.dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	defer database.Close()
+	cryptoService, err := crypto.NewCryptoService("secrets/rsa_key")
+	if err != nil {
+		return nil, fmt.Errorf("初始化加密服务失败: %w", err)
+	}
+	database.SetCryptoService(cryptoService)
+
+	exchanges, err := database.GetExchanges(*f.user)
+	if err != nil {
+		return nil, fmt.Errorf("读取交易所配置失败: %w", err)
+	}
+	accounts := make([]config.ExchangeFileConfig, 0, len(exchanges))
+	for _, ex := range exchanges {
+		enabled := ex.Enabled
+		accounts = append(accounts, config.ExchangeFileConfig{
+			ID: ex.ExchangeID, UserID: ex.UserID, Enabled: &enabled, Testnet: ex.Testnet,
+			APIKey: ex.APIKey, SecretKey: ex.SecretKey, HyperliquidWalletAddr: ex.HyperliquidWalletAddr,
+			AsterUser: ex.AsterUser, AsterSigner: ex.AsterSigner, AsterPrivateKey: ex.AsterPrivateKey,
+		})
+	}
+	return accounts, nil
+}
+
+// runBalanceCommand nofx balance [--exchange binance] [--json]
+func runBalanceCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("balance", flag.ContinueOnError)
+	flags := addExchangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, exchange, err := flags.openTrader()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	balance, err := t.GetBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("获取余额失败: %w", err)
+	}
+	if *flags.asJSON {
+		return writeJSON(stdout, balance)
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "交易所\t%s\n", exchange)
+	fmt.Fprintf(w, "钱包余额\t%.2f\n", floatField(balance, "totalWalletBalance"))
+	fmt.Fprintf(w, "可用余额\t%.2f\n", floatField(balance, "availableBalance"))
+	fmt.Fprintf(w, "未实现盈亏\t%+.2f\n", floatField(balance, "totalUnrealizedProfit"))
+	return w.Flush()
+}
+
+// runPositionsCommand nofx positions [--exchange binance] [--json]
+func runPositionsCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("positions", flag.ContinueOnError)
+	flags := addExchangeFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	t, _, err := flags.openTrader()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	positions, err := t.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	if *flags.asJSON {
+		if positions == nil {
+			positions = []map[string]interface{}{}
+		}
+		return writeJSON(stdout, positions)
+	}
+	if len(positions) == 0 {
+		fmt.Fprintln(stdout, "当前无持仓")
+		return nil
+	}
+	w := tabwriter.NewWriter(stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "币种\t方向\t数量\t开仓价\t标记价\t未实现盈亏\t杠杆\t强平价")
+	for _, pos := range positions {
+		fmt.Fprintf(w, "%v\t%v\t%g\t%g\t%g\t%+.2f\t%gx\t%g\n",
+			pos["symbol"], pos["side"], abs(floatField(pos, "positionAmt")), floatField(pos, "entryPrice"), floatField(pos, "markPrice"),
+			floatField(pos, "unRealizedProfit"), floatField(pos, "leverage"), floatField(pos, "liquidationPrice"))
+	}
+	return w.Flush()
+}
+
+// runCloseCommand nofx close BTCUSDT [--side long|short] [--quantity N] [--yes]
+// 未指定 --side 时平掉该币种的全部持仓；未加 --yes 时需在终端确认
+func runCloseCommand(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("close", flag.ContinueOnError)
+	flags := addExchangeFlags(fs)
+	side := fs.String("side", "", "平仓方向: long / short（为空时平掉该币种全部持仓）")
+	quantity := fs.Float64("quantity", 0, "平仓数量（0=全部）")
+	yes := fs.Bool("yes", false, "跳过确认")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	symbol := strings.ToUpper(fs.Arg(0))
+	if fs.NArg() > 1 { // 允许参数写在币种之后：nofx close BTCUSDT --side long
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if symbol == "" {
+		return fmt.Errorf("用法: nofx close SYMBOL [--side long|short] [--quantity N] [--yes]")
+	}
+	if *side != "" && *side != "long" && *side != "short" {
+		return fmt.Errorf("--side 只能是 long 或 short")
+	}
+	if *quantity < 0 {
+		return fmt.Errorf("--quantity 不能为负数")
+	}
+
+	t, exchange, err := flags.openTrader()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), *flags.timeout)
+	defer cancel()
+
+	positions, err := t.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	var sides []string
+	for _, pos := range positions {
+		posSide, _ := pos["side"].(string)
+		if pos["symbol"] == symbol && (*side == "" || posSide == *side) {
+			sides = append(sides, posSide)
+		}
+	}
+	if len(sides) == 0 {
+		return fmt.Errorf("%s 上没有 %s %s 持仓", exchange, symbol, *side)
+	}
+
+	if !*yes {
+		amount := "全部"
+		if *quantity > 0 {
+			amount = fmt.Sprintf("%g", *quantity)
+		}
+		fmt.Fprintf(stdout, "⚠️  将在 %s 市价平仓 %s %s（%s），输入 y 确认: ", exchange, symbol, strings.Join(sides, "/"), amount)
+		answer, _ := bufio.NewReader(cliStdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			return fmt.Errorf("已取消")
+		}
+	}
+
+	for _, s := range sides {
+		var report *trader.ExecutionReport
+		if s == "long" {
+			report, err = t.CloseLong(ctx, symbol, *quantity)
+		} else {
+			report, err = t.CloseShort(ctx, symbol, *quantity)
+		}
+		if err != nil {
+			return fmt.Errorf("平仓 %s %s 失败: %w", symbol, s, err)
+		}
+		if *flags.asJSON {
+			if err := writeJSON(stdout, report); err != nil {
+				return err
+			}
+			continue
+		}
+		if report != nil {
+			fmt.Fprintf(stdout, "✅ 已平仓 %s %s: 成交 %g @ %g（订单 %d，%s）\n", symbol, s, report.FilledQty, report.AvgPrice, report.OrderID, report.Status)
+		} else {
+			fmt.Fprintf(stdout, "✅ 已平仓 %s %s\n", symbol, s)
+		}
+	}
+	return nil
+}
+
+// floatField 读取交易器返回的 map 中的数值字段（缺失或类型不符时为 0）
+func floatField(m map[string]interface{}, key string) float64 {
+	switch v := m[key].(type) {
+	case float64:
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	}
+	return 0
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
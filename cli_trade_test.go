@@ -0,0 +1,138 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/trader"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// cliStubTrader 记录平仓调用的交易器（未覆盖的方法调用时 panic）
+type cliStubTrader struct {
+	trader.Trader
+	positions []map[string]interface{}
+	closed    []string
+}
+
+func (s *cliStubTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	return map[string]interface{}{"totalWalletBalance": 1000.0, "availableBalance": 800.0, "totalUnrealizedProfit": -12.5}, nil
+}
+
+func (s *cliStubTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	return s.positions, nil
+}
+
+func (s *cliStubTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*trader.ExecutionReport, error) {
+	s.closed = append(s.closed, symbol+" long")
+	return &trader.ExecutionReport{Symbol: symbol, FilledQty: 0.5, AvgPrice: 50000, OrderID: 1, Status: "FILLED"}, nil
+}
+
+func (s *cliStubTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*trader.ExecutionReport, error) {
+	s.closed = append(s.closed, symbol+" short")
+	return nil, nil
+}
+
+// withCLITrader 使用配置文件中的交易所账户和替身交易器
+func withCLITrader(t *testing.T, stub *cliStubTrader) (string, *trader.AutoTraderConfig) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`exchanges:
+  - id: hyperliquid
+    enabled: false
+    api_key: hl
+    hyperliquid_wallet_addr: "0x1"
+  - id: binance
+    api_key: ${CLI_TEST_BINANCE_KEY}
+    secret_key: sk
+`), 0600))
+	t.Setenv("CLI_TEST_BINANCE_KEY", "ak")
+	t.Setenv("SECRETS_PROVIDER", "")
+
+	var got trader.AutoTraderConfig
+	original := newCLITrader
+	newCLITrader = func(cfg trader.AutoTraderConfig) (trader.Trader, error) {
+		got = cfg
+		return stub, nil
+	}
+	t.Cleanup(func() { newCLITrader = original })
+	return path, &got
+}
+
+func TestRunBalanceAndPositionsCommands(t *testing.T) {
+	stub := &cliStubTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5, "entryPrice": 48000.0, "markPrice": 50000.0, "unRealizedProfit": 1000.0, "leverage": 5.0},
+	}}
+	configPath, cfg := withCLITrader(t, stub)
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, 0, runCommand("balance", []string{"--config", configPath}, &stdout, &stderr), stderr.String())
+	// 跳过未启用的 hyperliquid，使用 binance 账户（${ENV} 已展开）
+	assert.Equal(t, "binance", cfg.Exchange)
+	assert.Equal(t, "ak", cfg.BinanceAPIKey)
+	assert.Contains(t, stdout.String(), "800.00")
+	assert.Contains(t, stdout.String(), "-12.50")
+
+	stdout.Reset()
+	require.Equal(t, 0, runCommand("positions", []string{"--config", configPath}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "BTCUSDT")
+	assert.Contains(t, stdout.String(), "+1000.00")
+
+	stdout.Reset()
+	require.Equal(t, 0, runCommand("positions", []string{"--config", configPath, "--json"}, &stdout, &stderr))
+	assert.True(t, strings.HasPrefix(stdout.String(), "["))
+
+	stderr.Reset()
+	assert.Equal(t, 1, runCommand("balance", []string{"--config", configPath, "--exchange", "hyperliquid"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "没有启用的 hyperliquid 账户")
+}
+
+func TestRunCloseCommand(t *testing.T) {
+	stub := &cliStubTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long"},
+		{"symbol": "BTCUSDT", "side": "short"},
+		{"symbol": "ETHUSDT", "side": "long"},
+	}}
+	configPath, _ := withCLITrader(t, stub)
+	originalStdin := cliStdin
+	defer func() { cliStdin = originalStdin }()
+
+	var stdout, stderr bytes.Buffer
+
+	// 未确认时不平仓
+	cliStdin = strings.NewReader("n\n")
+	assert.Equal(t, 1, runCommand("close", []string{"btcusdt", "--config", configPath}, &stdout, &stderr))
+	assert.Empty(t, stub.closed)
+
+	// 确认后平掉该币种全部持仓
+	cliStdin = strings.NewReader("y\n")
+	require.Equal(t, 0, runCommand("close", []string{"BTCUSDT", "--config", configPath}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, []string{"BTCUSDT long", "BTCUSDT short"}, stub.closed)
+	assert.Contains(t, stdout.String(), "成交 0.5 @ 50000")
+
+	// --side 和 --yes
+	stub.closed = nil
+	require.Equal(t, 0, runCommand("close", []string{"ETHUSDT", "--side", "long", "--yes", "--config", configPath}, &stdout, &stderr))
+	assert.Equal(t, []string{"ETHUSDT long"}, stub.closed)
+
+	stderr.Reset()
+	assert.Equal(t, 1, runCommand("close", []string{"ETHUSDT", "--side", "short", "--yes", "--config", configPath}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "没有 ETHUSDT short 持仓")
+}
+
+func TestRunCommandHelp(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 0, runCommand("help", nil, &stdout, &stderr))
+	for _, name := range []string{"run", "backtest", "positions", "balance", "close", "export"} {
+		assert.Contains(t, stdout.String(), name)
+	}
+	assert.Equal(t, 1, runCommand("deploy", nil, &stdout, &stderr))
+	_, ok := lookupCommand("run")
+	assert.False(t, ok, "run 由 main 启动服务")
+}
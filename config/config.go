@@ -1,10 +1,17 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // LeverageConfig 杠杆配置
@@ -27,7 +34,57 @@ type TelegramConfig struct {
 	MinLevel string `json:"min_level"` // 最低日志级别，该级别及以上的日志会推送到Telegram（可选，默认: error）
 }
 
-// Config 总配置
+// ExchangeFileConfig 配置文件中的交易所账户（启动时同步到数据库，密钥可用 ${ENV} 引用环境变量）
+type ExchangeFileConfig struct {
+	ID      string `json:"id"`      // 交易所类型: binance, hyperliquid, aster
+	UserID  string `json:"user_id"` // 所属用户（默认 admin，即管理员模式用户）
+	Enabled *bool  `json:"enabled"` // 是否启用（默认: true）
+	Testnet bool   `json:"testnet"`
+
+	APIKey                string `json:"api_key"`    // Binance: API Key; Hyperliquid: Agent 私钥
+	SecretKey             string `json:"secret_key"` // Binance: Secret Key
+	HyperliquidWalletAddr string `json:"hyperliquid_wallet_addr"`
+	AsterUser             string `json:"aster_user"`
+	AsterSigner           string `json:"aster_signer"`
+	AsterPrivateKey       string `json:"aster_private_key"`
+}
+
+// IsEnabled 是否启用（未配置 enabled 时默认启用）
+func (e ExchangeFileConfig) IsEnabled() bool {
+	return e.Enabled == nil || *e.Enabled
+}
+
+// Owner 所属用户（未配置时为管理员模式用户 admin）
+func (e ExchangeFileConfig) Owner() string {
+	if e.UserID == "" {
+		return "admin"
+	}
+	return e.UserID
+}
+
+// NotifiersConfig 通知渠道配置（对应的 NOFX_SMTP_* / NOFX_TELEGRAM_* 环境变量优先）
+type NotifiersConfig struct {
+	SMTP     *SMTPNotifierConfig     `json:"smtp"`     // 交易汇总邮件
+	Telegram *TelegramNotifierConfig `json:"telegram"` // Telegram 命令机器人
+}
+
+// SMTPNotifierConfig 交易汇总邮件的 SMTP 配置
+type SMTPNotifierConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"` // 默认 587（STARTTLS）；465 使用 SMTPS
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"` // 发件人（空=Username）
+	To       []string `json:"to"`
+}
+
+// TelegramNotifierConfig Telegram 命令机器人配置
+type TelegramNotifierConfig struct {
+	BotToken string  `json:"bot_token"`
+	ChatIDs  []int64 `json:"chat_ids"` // 只响应这些 chat
+}
+
+// Config 总配置（config.json / config.yaml / config.toml，字符串值支持 ${ENV} 引用环境变量）
 type Config struct {
 	BetaMode           bool           `json:"beta_mode"`
 	APIServerPort      int            `json:"api_server_port"`
@@ -42,9 +99,97 @@ type Config struct {
 	JWTSecret          string         `json:"jwt_secret"`
 	DataKLineTime      string         `json:"data_k_line_time"`
 	Log                *LogConfig     `json:"log"` // 日志配置
+
+	Intervals   []string             `json:"intervals"`    // 行情订阅的K线周期（与运行中交易员的周期合并）
+	DataSources []string             `json:"data_sources"` // 数据源优先级（从高到低，名称见 market.RegisteredDataSources）
+	Exchanges   []ExchangeFileConfig `json:"exchanges"`    // 交易所账户
+	Notifiers   *NotifiersConfig     `json:"notifiers"`    // 通知渠道
+
+	Strategy map[string]float64 `json:"strategy"` // 运行中交易员的策略参数（名称同 GET /traders/:id/params，支持热更新）
 }
 
-// LoadConfig 从文件加载配置
+// 支持的配置文件格式
+const (
+	FormatJSON = "json"
+	FormatYAML = "yaml"
+	FormatTOML = "toml"
+)
+
+// FormatFromPath 按扩展名判断配置文件格式（.json / .yaml / .yml / .toml）
+func FormatFromPath(path string) (string, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return FormatJSON, nil
+	case ".yaml", ".yml":
+		return FormatYAML, nil
+	case ".toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("不支持的配置文件格式 %q（支持 .json、.yaml、.yml、.toml）", filepath.Ext(path))
+}
+
+// envRefPattern 配置值中的环境变量引用 ${NAME}
+var envRefPattern = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// ParseConfig 解析配置内容：YAML/TOML 先解码为通用结构，展开 ${ENV} 引用后统一按 JSON 字段名映射到 Config
+func ParseConfig(data []byte, format string) (*Config, error) {
+	var raw map[string]interface{}
+	var err error
+	switch format {
+	case FormatJSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		err = decoder.Decode(&raw)
+	case FormatYAML:
+		err = yaml.Unmarshal(data, &raw)
+	case FormatTOML:
+		err = toml.Unmarshal(data, &raw)
+	default:
+		return nil, fmt.Errorf("不支持的配置格式: %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s 语法错误: %w", format, err)
+	}
+
+	normalized, err := json.Marshal(expandEnvRefs(raw))
+	if err != nil {
+		return nil, fmt.Errorf("转换配置失败: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(normalized, &cfg); err != nil {
+		return nil, describeTypeError(err)
+	}
+	return &cfg, nil
+}
+
+// expandEnvRefs 递归展开字符串值中的 ${NAME}（未设置的环境变量展开为空字符串，由 Validate 报告缺失字段）
+func expandEnvRefs(v interface{}) interface{} {
+	switch val := v.(type) {
+	case string:
+		return envRefPattern.ReplaceAllStringFunc(val, func(ref string) string {
+			return os.Getenv(envRefPattern.FindStringSubmatch(ref)[1])
+		})
+	case map[string]interface{}:
+		for k, item := range val {
+			val[k] = expandEnvRefs(item)
+		}
+	case []interface{}:
+		for i, item := range val {
+			val[i] = expandEnvRefs(item)
+		}
+	}
+	return v
+}
+
+// describeTypeError 把字段类型错误转换为带字段路径的提示
+func describeTypeError(err error) error {
+	if typeErr, ok := err.(*json.UnmarshalTypeError); ok && typeErr.Field != "" {
+		return fmt.Errorf("%s: 类型错误，应为 %s，实际为 %s", typeErr.Field, typeErr.Type, typeErr.Value)
+	}
+	return fmt.Errorf("解析配置失败: %w", err)
+}
+
+// LoadConfig 从文件加载配置（格式按扩展名判断）
 func LoadConfig(filename string) (*Config, error) {
 	// 检查filename是否存在
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
@@ -58,11 +203,14 @@ func LoadConfig(filename string) (*Config, error) {
 		return nil, fmt.Errorf("读取%s失败: %w", filename, err)
 	}
 
-	// 解析JSON
-	var configFile Config
-	if err := json.Unmarshal(data, &configFile); err != nil {
+	format, err := FormatFromPath(filename)
+	if err != nil {
+		return nil, err
+	}
+	configFile, err := ParseConfig(data, format)
+	if err != nil {
 		return nil, fmt.Errorf("解析%s失败: %w", filename, err)
 	}
 
-	return &configFile, nil
+	return configFile, nil
 }
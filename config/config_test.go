@@ -0,0 +1,135 @@
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseConfigFormats(t *testing.T) {
+	t.Setenv("TEST_BINANCE_SECRET", "s3cret")
+
+	inputs := map[string]string{
+		FormatJSON: `{
+			"api_server_port": 9000,
+			"leverage": {"btc_eth_leverage": 10},
+			"default_coins": ["BTCUSDT", "ETHUSDT"],
+			"intervals": ["15m", "1h"],
+			"data_sources": ["okx", "binance"],
+			"exchanges": [{"id": "binance", "api_key": "key", "secret_key": "${TEST_BINANCE_SECRET}"}],
+			"notifiers": {"telegram": {"bot_token": "token", "chat_ids": [123456789012]}}
+		}`,
+		FormatYAML: `
+api_server_port: 9000
+leverage:
+  btc_eth_leverage: 10
+default_coins: [BTCUSDT, ETHUSDT]
+intervals: [15m, 1h]
+data_sources: [okx, binance]
+exchanges:
+  - id: binance
+    api_key: key
+    secret_key: ${TEST_BINANCE_SECRET}
+notifiers:
+  telegram:
+    bot_token: token
+    chat_ids: [123456789012]
+`,
+		FormatTOML: `
+api_server_port = 9000
+default_coins = ["BTCUSDT", "ETHUSDT"]
+intervals = ["15m", "1h"]
+data_sources = ["okx", "binance"]
+
+[leverage]
+btc_eth_leverage = 10
+
+[[exchanges]]
+id = "binance"
+api_key = "key"
+secret_key = "${TEST_BINANCE_SECRET}"
+
+[notifiers.telegram]
+bot_token = "token"
+chat_ids = [123456789012]
+`,
+	}
+
+	for format, input := range inputs {
+		t.Run(format, func(t *testing.T) {
+			cfg, err := ParseConfig([]byte(input), format)
+			if err != nil {
+				t.Fatalf("ParseConfig() error = %v", err)
+			}
+			if cfg.APIServerPort != 9000 || cfg.Leverage.BTCETHLeverage != 10 {
+				t.Errorf("基础字段解析错误: port=%d leverage=%d", cfg.APIServerPort, cfg.Leverage.BTCETHLeverage)
+			}
+			if strings.Join(cfg.DefaultCoins, ",") != "BTCUSDT,ETHUSDT" || strings.Join(cfg.DataSources, ",") != "okx,binance" {
+				t.Errorf("列表字段解析错误: coins=%v sources=%v", cfg.DefaultCoins, cfg.DataSources)
+			}
+			if len(cfg.Exchanges) != 1 || cfg.Exchanges[0].SecretKey != "s3cret" || !cfg.Exchanges[0].IsEnabled() || cfg.Exchanges[0].Owner() != "admin" {
+				t.Errorf("交易所解析错误: %+v", cfg.Exchanges)
+			}
+			if cfg.Notifiers == nil || cfg.Notifiers.Telegram == nil || cfg.Notifiers.Telegram.ChatIDs[0] != 123456789012 {
+				t.Errorf("通知配置解析错误: %+v", cfg.Notifiers)
+			}
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	if _, err := ParseConfig([]byte(`{"api_server_port": "abc"}`), FormatJSON); err == nil || !strings.Contains(err.Error(), "api_server_port") {
+		t.Errorf("类型错误应指出字段, got %v", err)
+	}
+	if _, err := ParseConfig([]byte("api_server_port: [1"), FormatYAML); err == nil {
+		t.Error("YAML 语法错误应返回错误")
+	}
+	if _, err := FormatFromPath("config.ini"); err == nil {
+		t.Error("不支持的扩展名应返回错误")
+	}
+}
+
+func TestValidateReportsAllProblems(t *testing.T) {
+	disabled := false
+	cfg := &Config{
+		APIServerPort: 70000,
+		MaxDrawdown:   150,
+		Leverage:      LeverageConfig{AltcoinLeverage: 200},
+		DefaultCoins:  []string{"btcusdt", "ETHUSDT", "ETHUSDT"},
+		Intervals:     []string{"7m"},
+		DataSources:   []string{"kraken"},
+		Exchanges: []ExchangeFileConfig{
+			{ID: "binance", APIKey: "key"},
+			{ID: "ftx"},
+			{ID: "aster", Enabled: &disabled},
+		},
+		Notifiers: &NotifiersConfig{SMTP: &SMTPNotifierConfig{Host: "smtp.example.com"}},
+	}
+
+	err := cfg.Validate()
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Validate() = %v, want ValidationError", err)
+	}
+	wantFields := []string{
+		"api_server_port", "max_drawdown", "leverage.altcoin_leverage",
+		"default_coins[0]", "default_coins[2]", "intervals[0]", "data_sources[0]",
+		"exchanges[0].secret_key", "exchanges[1].id", "notifiers.smtp.from", "notifiers.smtp.to",
+	}
+	for _, field := range wantFields {
+		if !strings.Contains(err.Error(), field+":") {
+			t.Errorf("校验结果缺少 %s:\n%v", field, err)
+		}
+	}
+	if len(validationErr.Problems) != len(wantFields) {
+		t.Errorf("问题数量 = %d, want %d:\n%v", len(validationErr.Problems), len(wantFields), err)
+	}
+
+	var nilConfig *Config
+	if err := nilConfig.Validate(); err != nil {
+		t.Errorf("nil 配置应视为有效, got %v", err)
+	}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"context"
 	"crypto/rand"
 	"database/sql"
 	"encoding/base32"
@@ -298,7 +299,7 @@ func (d *Database) createTables() error {
 		`ALTER TABLE traders ADD COLUMN system_prompt_template TEXT DEFAULT 'default'`,     // 系统提示词模板名称
 		`ALTER TABLE traders ADD COLUMN taker_fee_rate REAL DEFAULT 0.0004`,                // Taker fee rate, default 0.0004
 		`ALTER TABLE traders ADD COLUMN maker_fee_rate REAL DEFAULT 0.0002`,                // Maker fee rate, default 0.0002
-		`ALTER TABLE traders ADD COLUMN order_strategy TEXT DEFAULT 'conservative_hybrid'`, // Order strategy: market_only, conservative_hybrid, limit_only
+		`ALTER TABLE traders ADD COLUMN order_strategy TEXT DEFAULT 'conservative_hybrid'`, // Order strategy: market_only, conservative_hybrid, limit_only, maker_then_taker
 		`ALTER TABLE traders ADD COLUMN limit_price_offset REAL DEFAULT -0.03`,             // Limit order price offset percentage (e.g., -0.03 for -0.03%)
 		`ALTER TABLE traders ADD COLUMN limit_timeout_seconds INTEGER DEFAULT 60`,          // Timeout in seconds before converting to market order
 		`ALTER TABLE traders ADD COLUMN timeframes TEXT DEFAULT '4h'`,                      // 时间线选择 (逗号分隔，例如: "1m,4h,1d")
@@ -1088,7 +1089,7 @@ type TraderRecord struct {
 	IsCrossMargin        bool      `json:"is_cross_margin"`        // 是否为全仓模式（true=全仓，false=逐仓）
 	TakerFeeRate         float64   `json:"taker_fee_rate"`         // Taker fee rate, default 0.0004
 	MakerFeeRate         float64   `json:"maker_fee_rate"`         // Maker fee rate, default 0.0002
-	OrderStrategy        string    `json:"order_strategy"`         // Order strategy: "market_only", "conservative_hybrid", "limit_only"
+	OrderStrategy        string    `json:"order_strategy"`         // Order strategy: "market_only", "conservative_hybrid", "limit_only", "maker_then_taker"
 	LimitPriceOffset     float64   `json:"limit_price_offset"`     // Limit order price offset percentage (e.g., -0.03 for -0.03%)
 	LimitTimeoutSeconds  int       `json:"limit_timeout_seconds"`  // Timeout in seconds before converting to market order (default: 60)
 	Timeframes           string    `json:"timeframes"`             // 时间线选择 (逗号分隔，例如: "1m,4h,1d")
@@ -2013,6 +2014,11 @@ func (d *Database) GetAllTimeframes() []string {
 }
 
 // Close 关闭数据库连接
+// Ping 检查数据库连接是否可用
+func (d *Database) Ping(ctx context.Context) error {
+	return d.db.PingContext(ctx)
+}
+
 func (d *Database) Close() error {
 	return d.db.Close()
 }
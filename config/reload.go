@@ -0,0 +1,144 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// liveReloadFields 运行时可热更新的配置项（JSON 字段名），其余配置项变更需要重启进程才能生效
+var liveReloadFields = map[string]bool{
+	"use_default_coins":    true,
+	"default_coins":        true,
+	"max_daily_loss":       true,
+	"max_drawdown":         true,
+	"stop_trading_minutes": true,
+	"leverage":             true, // 新建交易员的默认杠杆（运行中交易员的杠杆通过 strategy 调整）
+	"strategy":             true,
+}
+
+// ChangedFields 比较两份配置，返回发生变化的配置项（JSON 字段名，按结构体字段顺序）
+func ChangedFields(old, new *Config) []string {
+	if old == nil {
+		old = &Config{}
+	}
+	if new == nil {
+		new = &Config{}
+	}
+	oldValue, newValue := reflect.ValueOf(old).Elem(), reflect.ValueOf(new).Elem()
+	var changed []string
+	for i := 0; i < oldValue.NumField(); i++ {
+		if !reflect.DeepEqual(oldValue.Field(i).Interface(), newValue.Field(i).Interface()) {
+			name, _, _ := strings.Cut(oldValue.Type().Field(i).Tag.Get("json"), ",")
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
+// RestartRequiredChanges 返回 old → new 中无法热更新的配置项（如交易所密钥、端口、数据源）
+func RestartRequiredChanges(old, new *Config) []string {
+	var fields []string
+	for _, name := range ChangedFields(old, new) {
+		if !liveReloadFields[name] {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
+
+// Watcher 轮询配置文件，变更通过校验后调用 apply 热更新；包含需重启配置项的变更整体拒绝
+type Watcher struct {
+	path     string
+	interval time.Duration
+	load     func() (*Config, error) // 读取配置（含环境变量覆盖）
+	apply    func(*Config) error     // 应用可热更新的配置项
+
+	mu      sync.Mutex
+	current *Config
+	modTime time.Time
+	size    int64
+
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher 创建配置文件监视器（current 为进程启动时使用的配置）
+func NewWatcher(path string, interval time.Duration, current *Config, load func() (*Config, error), apply func(*Config) error) *Watcher {
+	w := &Watcher{path: path, interval: interval, current: current, load: load, apply: apply, stopCh: make(chan struct{})}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime, w.size = info.ModTime(), info.Size()
+	}
+	return w
+}
+
+// Start 开始轮询（阻塞直到 Stop）
+func (w *Watcher) Start() {
+	log.Printf("👀 配置热更新已开启: %s（每 %v 检查一次）", w.path, w.interval)
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stopCh:
+			return
+		case <-ticker.C:
+			if _, err := w.Check(); err != nil {
+				log.Printf("❌ 配置热更新失败，继续使用当前配置: %v", err)
+			}
+		}
+	}
+}
+
+// Stop 停止轮询
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() { close(w.stopCh) })
+}
+
+// Current 当前生效的配置
+func (w *Watcher) Current() *Config {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.current
+}
+
+// Check 检查一次配置文件：未变化时返回 nil, nil；应用成功时返回变化的配置项
+func (w *Watcher) Check() ([]string, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return nil, fmt.Errorf("读取%s失败: %w", w.path, err)
+	}
+	if info.ModTime().Equal(w.modTime) && info.Size() == w.size {
+		return nil, nil
+	}
+	// 无论本次变更是否被接受都记录文件状态，避免对同一份无效配置反复报错
+	w.modTime, w.size = info.ModTime(), info.Size()
+
+	next, err := w.load()
+	if err != nil {
+		return nil, err
+	}
+	if err := next.Validate(); err != nil {
+		return nil, err
+	}
+	changed := ChangedFields(w.current, next)
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	if fields := RestartRequiredChanges(w.current, next); len(fields) > 0 {
+		return nil, fmt.Errorf("以下配置项无法热更新，需重启后生效，本次变更已全部拒绝: %s", strings.Join(fields, ", "))
+	}
+	if err := w.apply(next); err != nil {
+		return nil, err
+	}
+
+	w.current = next
+	log.Printf("🔄 配置已热更新: %s", strings.Join(changed, ", "))
+	return changed, nil
+}
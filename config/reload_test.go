@@ -0,0 +1,93 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRestartRequiredChanges(t *testing.T) {
+	old := &Config{MaxDailyLoss: 10, Exchanges: []ExchangeFileConfig{{ID: "binance", APIKey: "a", SecretKey: "b"}}}
+	live := &Config{MaxDailyLoss: 5, Exchanges: old.Exchanges, Strategy: map[string]float64{"scan_interval": 300}}
+	if fields := RestartRequiredChanges(old, live); len(fields) != 0 {
+		t.Errorf("风控和策略参数应可热更新, got %v", fields)
+	}
+	if changed := ChangedFields(old, live); strings.Join(changed, ",") != "max_daily_loss,strategy" {
+		t.Errorf("ChangedFields() = %v", changed)
+	}
+
+	rotated := &Config{MaxDailyLoss: 10, Exchanges: []ExchangeFileConfig{{ID: "binance", APIKey: "new", SecretKey: "b"}}, APIServerPort: 9090}
+	if fields := RestartRequiredChanges(old, rotated); strings.Join(fields, ",") != "api_server_port,exchanges" {
+		t.Errorf("RestartRequiredChanges() = %v", fields)
+	}
+}
+
+func TestWatcherCheck(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		// 保证修改时间变化（部分文件系统精度为秒）
+		next := time.Now().Add(time.Duration(len(content)) * time.Second)
+		os.Chtimes(path, next, next)
+	}
+	load := func() (*Config, error) { return LoadConfig(path) }
+
+	write("max_daily_loss: 10\nexchanges:\n  - id: binance\n    api_key: a\n    secret_key: b\n")
+	current, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var applied []*Config
+	applyErr := error(nil)
+	w := NewWatcher(path, time.Second, current, load, func(cfg *Config) error {
+		if applyErr != nil {
+			return applyErr
+		}
+		applied = append(applied, cfg)
+		return nil
+	})
+
+	if changed, err := w.Check(); err != nil || changed != nil {
+		t.Fatalf("文件未变化时不应重新加载: %v, %v", changed, err)
+	}
+
+	// 可热更新的变更被应用
+	write("max_daily_loss: 5\ndefault_coins: [BTCUSDT]\nexchanges:\n  - id: binance\n    api_key: a\n    secret_key: b\n")
+	changed, err := w.Check()
+	if err != nil || strings.Join(changed, ",") != "default_coins,max_daily_loss" {
+		t.Fatalf("Check() = %v, %v", changed, err)
+	}
+	if len(applied) != 1 || w.Current().MaxDailyLoss != 5 {
+		t.Errorf("配置未应用: applied=%d current=%+v", len(applied), w.Current())
+	}
+
+	// 修改密钥需要重启：整体拒绝，当前配置不变
+	write("max_daily_loss: 3\nexchanges:\n  - id: binance\n    api_key: rotated\n    secret_key: b\n")
+	if _, err := w.Check(); err == nil || !strings.Contains(err.Error(), "exchanges") {
+		t.Errorf("修改交易所密钥应被拒绝, got %v", err)
+	}
+	if len(applied) != 1 || w.Current().MaxDailyLoss != 5 {
+		t.Errorf("被拒绝的变更不应生效: current=%+v", w.Current())
+	}
+
+	// 校验失败的配置被拒绝
+	write("max_daily_loss: 500\nexchanges:\n  - id: binance\n    api_key: a\n    secret_key: b\n")
+	var validationErr *ValidationError
+	if _, err := w.Check(); !errors.As(err, &validationErr) {
+		t.Errorf("无效配置应返回 ValidationError, got %v", err)
+	}
+
+	// 应用失败时保留当前配置
+	applyErr = errors.New("策略参数无效")
+	write("max_daily_loss: 2\nexchanges:\n  - id: binance\n    api_key: a\n    secret_key: b\n")
+	if _, err := w.Check(); err == nil || w.Current().MaxDailyLoss != 5 {
+		t.Errorf("应用失败时应保留当前配置: err=%v current=%+v", err, w.Current())
+	}
+}
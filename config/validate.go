@@ -0,0 +1,185 @@
+package config
+
+import (
+	"fmt"
+	"nofx/market"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// ValidationError 配置校验错误，列出全部问题（字段路径: 说明）
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "配置校验失败:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// validator 收集校验问题
+type validator struct {
+	problems []string
+}
+
+func (v *validator) addf(field, format string, args ...interface{}) {
+	v.problems = append(v.problems, field+": "+fmt.Sprintf(format, args...))
+}
+
+// supportedExchanges 支持的交易所类型（与数据库预置的交易所一致）
+var supportedExchanges = []string{"binance", "hyperliquid", "aster"}
+
+// symbolPattern 交易对格式（大写字母和数字，如 BTCUSDT）
+var symbolPattern = regexp.MustCompile(`^[A-Z0-9]+$`)
+
+// maxLeverage 交易所允许的最大杠杆倍数
+const maxLeverage = 125
+
+// Validate 校验配置，一次返回全部问题（nil 配置视为有效）
+func (c *Config) Validate() error {
+	if c == nil {
+		return nil
+	}
+	v := &validator{}
+
+	if c.APIServerPort < 0 || c.APIServerPort > 65535 {
+		v.addf("api_server_port", "端口 %d 超出范围（1-65535）", c.APIServerPort)
+	}
+	if c.MaxDailyLoss < 0 || c.MaxDailyLoss > 100 {
+		v.addf("max_daily_loss", "%.2f 超出范围（0-100，百分比）", c.MaxDailyLoss)
+	}
+	if c.MaxDrawdown < 0 || c.MaxDrawdown > 100 {
+		v.addf("max_drawdown", "%.2f 超出范围（0-100，百分比）", c.MaxDrawdown)
+	}
+	if c.StopTradingMinutes < 0 {
+		v.addf("stop_trading_minutes", "不能为负数")
+	}
+	v.leverage("leverage.btc_eth_leverage", c.Leverage.BTCETHLeverage)
+	v.leverage("leverage.altcoin_leverage", c.Leverage.AltcoinLeverage)
+
+	seen := make(map[string]bool)
+	for i, symbol := range c.DefaultCoins {
+		field := fmt.Sprintf("default_coins[%d]", i)
+		switch {
+		case !symbolPattern.MatchString(symbol):
+			v.addf(field, "交易对 %q 格式错误（应为大写，如 BTCUSDT）", symbol)
+		case seen[symbol]:
+			v.addf(field, "交易对 %s 重复", symbol)
+		}
+		seen[symbol] = true
+	}
+
+	if c.DataKLineTime != "" {
+		v.interval("data_k_line_time", c.DataKLineTime)
+	}
+	for i, interval := range c.Intervals {
+		v.interval(fmt.Sprintf("intervals[%d]", i), interval)
+	}
+
+	if c.Log != nil && c.Log.Level != "" {
+		switch strings.ToLower(c.Log.Level) {
+		case "debug", "info", "warn", "warning", "error":
+		default:
+			v.addf("log.level", "未知日志级别 %q（支持 debug、info、warn、error）", c.Log.Level)
+		}
+	}
+
+	registered := market.RegisteredDataSources()
+	seenSources := make(map[string]bool)
+	for i, name := range c.DataSources {
+		field := fmt.Sprintf("data_sources[%d]", i)
+		switch {
+		case !slices.Contains(registered, name):
+			v.addf(field, "未注册的数据源 %q（可用: %s）", name, strings.Join(registered, ", "))
+		case seenSources[name]:
+			v.addf(field, "数据源 %s 重复", name)
+		}
+		seenSources[name] = true
+	}
+
+	seenExchanges := make(map[string]bool)
+	for i, ex := range c.Exchanges {
+		v.exchange(fmt.Sprintf("exchanges[%d]", i), ex)
+		key := ex.Owner() + "/" + ex.ID
+		if ex.ID != "" && seenExchanges[key] {
+			v.addf(fmt.Sprintf("exchanges[%d].id", i), "用户 %s 的交易所 %s 重复配置", ex.Owner(), ex.ID)
+		}
+		seenExchanges[key] = true
+	}
+
+	if c.Notifiers != nil {
+		v.notifiers(c.Notifiers)
+	}
+
+	if len(v.problems) > 0 {
+		return &ValidationError{Problems: v.problems}
+	}
+	return nil
+}
+
+func (v *validator) leverage(field string, leverage int) {
+	if leverage < 0 || leverage > maxLeverage {
+		v.addf(field, "杠杆 %d 超出范围（1-%d，0 表示使用默认值）", leverage, maxLeverage)
+	}
+}
+
+func (v *validator) interval(field, interval string) {
+	if _, ok := market.TimeframeDuration(interval); !ok {
+		v.addf(field, "不支持的K线周期 %q（如 1m、5m、15m、1h、4h、1d）", interval)
+	}
+}
+
+// exchange 校验交易所账户：按类型检查必需的密钥字段（禁用的账户只检查类型）
+func (v *validator) exchange(field string, ex ExchangeFileConfig) {
+	if ex.ID == "" {
+		v.addf(field+".id", "必填（支持 %s）", strings.Join(supportedExchanges, ", "))
+		return
+	}
+	if !slices.Contains(supportedExchanges, ex.ID) {
+		v.addf(field+".id", "不支持的交易所 %q（支持 %s）", ex.ID, strings.Join(supportedExchanges, ", "))
+		return
+	}
+	if !ex.IsEnabled() {
+		return
+	}
+
+	required := map[string]string{}
+	switch ex.ID {
+	case "binance":
+		required["api_key"], required["secret_key"] = ex.APIKey, ex.SecretKey
+	case "hyperliquid":
+		required["api_key"], required["hyperliquid_wallet_addr"] = ex.APIKey, ex.HyperliquidWalletAddr
+	case "aster":
+		required["aster_user"], required["aster_signer"], required["aster_private_key"] = ex.AsterUser, ex.AsterSigner, ex.AsterPrivateKey
+	}
+	for _, name := range []string{"api_key", "secret_key", "hyperliquid_wallet_addr", "aster_user", "aster_signer", "aster_private_key"} {
+		if value, ok := required[name]; ok && strings.TrimSpace(value) == "" {
+			v.addf(field+"."+name, "%s 必填（可用 ${ENV_NAME} 引用环境变量）", ex.ID)
+		}
+	}
+}
+
+func (v *validator) notifiers(n *NotifiersConfig) {
+	if smtp := n.SMTP; smtp != nil {
+		if smtp.Host == "" {
+			v.addf("notifiers.smtp.host", "必填")
+		}
+		if smtp.Port < 0 || smtp.Port > 65535 {
+			v.addf("notifiers.smtp.port", "端口 %d 超出范围", smtp.Port)
+		}
+		if smtp.From == "" && smtp.Username == "" {
+			v.addf("notifiers.smtp.from", "需要设置 from 或 username")
+		}
+		if len(smtp.To) == 0 {
+			v.addf("notifiers.smtp.to", "至少需要一个收件人")
+		}
+	}
+	if tg := n.Telegram; tg != nil {
+		if tg.BotToken == "" {
+			v.addf("notifiers.telegram.bot_token", "必填")
+		}
+		if len(tg.ChatIDs) == 0 {
+			v.addf("notifiers.telegram.chat_ids", "至少需要一个 chat ID（机器人只响应这些 chat）")
+		}
+	}
+}
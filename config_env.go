@@ -0,0 +1,191 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/health"
+	"nofx/trader"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 运行时配置来源优先级（从高到低）：
+//  1. 环境变量 NOFX_*（见 envConfigVars）
+//  2. 配置文件（-config 指定，默认 config.json；"-config -" 从标准输入读取 JSON）
+//  3. 数据库中已保存的系统配置
+//  4. 内置默认值
+//
+// 容器部署时无需挂载 config.json，只设置需要的环境变量即可。
+
+// stdinConfigPath 表示从标准输入读取配置
+const stdinConfigPath = "-"
+
+// envConfigVar 环境变量到配置字段的映射
+type envConfigVar struct {
+	name  string
+	apply func(cfg *config.Config, value string) error
+}
+
+// envConfigVars 支持的配置环境变量
+var envConfigVars = []envConfigVar{
+	{"NOFX_BETA_MODE", func(cfg *config.Config, v string) error { return parseEnvBool(v, &cfg.BetaMode) }},
+	{"NOFX_API_SERVER_PORT", func(cfg *config.Config, v string) error { return parseEnvInt(v, &cfg.APIServerPort) }},
+	{"NOFX_USE_DEFAULT_COINS", func(cfg *config.Config, v string) error { return parseEnvBool(v, &cfg.UseDefaultCoins) }},
+	{"NOFX_DEFAULT_COINS", func(cfg *config.Config, v string) error {
+		cfg.DefaultCoins = parseEnvList(v)
+		return nil
+	}},
+	{"NOFX_COIN_POOL_API_URL", func(cfg *config.Config, v string) error {
+		cfg.CoinPoolAPIURL = v
+		return nil
+	}},
+	{"NOFX_OI_TOP_API_URL", func(cfg *config.Config, v string) error {
+		cfg.OITopAPIURL = v
+		return nil
+	}},
+	{"NOFX_MAX_DAILY_LOSS", func(cfg *config.Config, v string) error { return parseEnvFloat(v, &cfg.MaxDailyLoss) }},
+	{"NOFX_MAX_DRAWDOWN", func(cfg *config.Config, v string) error { return parseEnvFloat(v, &cfg.MaxDrawdown) }},
+	{"NOFX_STOP_TRADING_MINUTES", func(cfg *config.Config, v string) error { return parseEnvInt(v, &cfg.StopTradingMinutes) }},
+	{"NOFX_BTC_ETH_LEVERAGE", func(cfg *config.Config, v string) error { return parseEnvInt(v, &cfg.Leverage.BTCETHLeverage) }},
+	{"NOFX_ALTCOIN_LEVERAGE", func(cfg *config.Config, v string) error { return parseEnvInt(v, &cfg.Leverage.AltcoinLeverage) }},
+	{"NOFX_DATA_K_LINE_TIME", func(cfg *config.Config, v string) error {
+		cfg.DataKLineTime = v
+		return nil
+	}},
+}
+
+// defaultConfigFile 内置默认配置（与 config.json.example 保持一致）
+// 未提供配置文件但设置了环境变量时，以此为基础叠加环境变量，避免未设置的字段以零值写入数据库
+func defaultConfigFile() *config.Config {
+	return &config.Config{
+		BetaMode:           false,
+		APIServerPort:      8080,
+		UseDefaultCoins:    true,
+		DefaultCoins:       []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT", "XRPUSDT", "DOGEUSDT", "ADAUSDT", "HYPEUSDT"},
+		MaxDailyLoss:       10.0,
+		MaxDrawdown:        20.0,
+		StopTradingMinutes: 60,
+		Leverage:           config.LeverageConfig{BTCETHLeverage: 5, AltcoinLeverage: 5},
+	}
+}
+
+// applyEnvOverrides 使用环境变量覆盖配置文件中的字段
+// configFile 为 nil 且没有任何配置环境变量时返回 nil（保持"不同步"的原有行为）
+func applyEnvOverrides(configFile *config.Config) (*config.Config, error) {
+	var applied []string
+
+	for _, v := range envConfigVars {
+		value, ok := os.LookupEnv(v.name)
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if configFile == nil {
+			configFile = defaultConfigFile()
+		}
+		if err := v.apply(configFile, value); err != nil {
+			return nil, fmt.Errorf("环境变量 %s 无效: %w", v.name, err)
+		}
+		applied = append(applied, v.name)
+	}
+
+	if len(applied) > 0 {
+		log.Printf("🌱 已应用 %d 个配置环境变量（优先于配置文件）: %s", len(applied), strings.Join(applied, ", "))
+	}
+
+	return configFile, nil
+}
+
+func parseEnvBool(value string, target *bool) error {
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*target = b
+	return nil
+}
+
+func parseEnvInt(value string, target *int) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*target = n
+	return nil
+}
+
+func parseEnvFloat(value string, target *float64) error {
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*target = f
+	return nil
+}
+
+// parseEnvList 解析逗号分隔的列表（自动去除空白和空项）
+func parseEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// configReloadIntervalFromEnv 读取 NOFX_CONFIG_RELOAD_INTERVAL（未设置或无效时不开启配置热更新）
+func configReloadIntervalFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("NOFX_CONFIG_RELOAD_INTERVAL"))
+	if v == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  NOFX_CONFIG_RELOAD_INTERVAL=%q 无效，配置热更新未开启", v)
+		return 0
+	}
+	return d
+}
+
+// healthCheckTimeoutFromEnv 读取 NOFX_HEALTH_CHECK_TIMEOUT（就绪探针单项依赖检查的超时，默认 5s）
+func healthCheckTimeoutFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("NOFX_HEALTH_CHECK_TIMEOUT"))
+	if v == "" {
+		return health.DefaultCheckTimeout
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  NOFX_HEALTH_CHECK_TIMEOUT=%q 无效，使用默认值 %v", v, health.DefaultCheckTimeout)
+		return health.DefaultCheckTimeout
+	}
+	return d
+}
+
+// shutdownOptionsFromEnv 读取 NOFX_SHUTDOWN_TIMEOUT（等待进行中订单的时间，默认 30s）和
+// NOFX_SHUTDOWN_CANCEL_ORDERS（true 时退出前撤销持仓的止盈止损单，默认保留）
+func shutdownOptionsFromEnv() trader.ShutdownOptions {
+	opts := trader.ShutdownOptions{DrainTimeout: trader.DefaultDrainTimeout}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SHUTDOWN_TIMEOUT")); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			opts.DrainTimeout = d
+		} else {
+			log.Printf("⚠️  NOFX_SHUTDOWN_TIMEOUT=%q 无效，使用默认值 %v", v, opts.DrainTimeout)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SHUTDOWN_CANCEL_ORDERS")); v != "" {
+		cancel, err := strconv.ParseBool(v)
+		if err != nil {
+			log.Printf("⚠️  NOFX_SHUTDOWN_CANCEL_ORDERS=%q 无效，保留止盈止损单", v)
+		}
+		opts.CancelProtectiveOrders = cancel
+	}
+	return opts
+}
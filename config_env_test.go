@@ -0,0 +1,131 @@
+package main
+
+import (
+	"nofx/config"
+	"nofx/trader"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyEnvOverrides(t *testing.T) {
+	t.Run("无配置文件且无环境变量时保持nil", func(t *testing.T) {
+		cfg, err := applyEnvOverrides(nil)
+		require.NoError(t, err)
+		assert.Nil(t, cfg)
+	})
+
+	t.Run("无配置文件时基于默认值叠加", func(t *testing.T) {
+		t.Setenv("NOFX_MAX_DRAWDOWN", "15.5")
+		t.Setenv("NOFX_DEFAULT_COINS", " BTCUSDT, ETHUSDT ,,")
+
+		cfg, err := applyEnvOverrides(nil)
+		require.NoError(t, err)
+		require.NotNil(t, cfg)
+		assert.Equal(t, 15.5, cfg.MaxDrawdown)
+		assert.Equal(t, []string{"BTCUSDT", "ETHUSDT"}, cfg.DefaultCoins)
+		// 未设置的字段保持默认值
+		assert.Equal(t, 8080, cfg.APIServerPort)
+		assert.Equal(t, 10.0, cfg.MaxDailyLoss)
+		assert.Equal(t, 5, cfg.Leverage.BTCETHLeverage)
+	})
+
+	t.Run("环境变量优先于配置文件", func(t *testing.T) {
+		t.Setenv("NOFX_BETA_MODE", "true")
+		t.Setenv("NOFX_ALTCOIN_LEVERAGE", "3")
+
+		cfg, err := applyEnvOverrides(&config.Config{APIServerPort: 9090, MaxDailyLoss: 5})
+		require.NoError(t, err)
+		assert.True(t, cfg.BetaMode)
+		assert.Equal(t, 3, cfg.Leverage.AltcoinLeverage)
+		assert.Equal(t, 9090, cfg.APIServerPort)
+		assert.Equal(t, 5.0, cfg.MaxDailyLoss)
+	})
+
+	t.Run("无效值返回错误", func(t *testing.T) {
+		t.Setenv("NOFX_STOP_TRADING_MINUTES", "abc")
+
+		_, err := applyEnvOverrides(&config.Config{})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "NOFX_STOP_TRADING_MINUTES")
+	})
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	dir := t.TempDir()
+
+	cfg, err := loadConfigFile(filepath.Join(dir, "missing.json"))
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	path := filepath.Join(dir, "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"api_server_port": 9000}`), 0600))
+	cfg, err = loadConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 9000, cfg.APIServerPort)
+}
+
+func TestLoadConfigFileFromStdin(t *testing.T) {
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	origStdin := os.Stdin
+	os.Stdin = r
+	defer func() { os.Stdin = origStdin }()
+
+	_, err = w.WriteString(`{"max_drawdown": 12, "default_coins": ["SOLUSDT"]}`)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	cfg, err := loadConfigFile(stdinConfigPath)
+	require.NoError(t, err)
+	assert.Equal(t, 12.0, cfg.MaxDrawdown)
+	assert.Equal(t, []string{"SOLUSDT"}, cfg.DefaultCoins)
+}
+
+func TestLoadConfigFileFormats(t *testing.T) {
+	dir := t.TempDir()
+
+	path := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("api_server_port: 9100\ndata_sources: [okx, binance]\n"), 0600))
+	cfg, err := loadConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, 9100, cfg.APIServerPort)
+	assert.Equal(t, []string{"okx", "binance"}, cfg.DataSources)
+
+	path = filepath.Join(dir, "config.ini")
+	require.NoError(t, os.WriteFile(path, []byte("api_server_port=9100"), 0600))
+	_, err = loadConfigFile(path)
+	require.Error(t, err)
+
+	// 示例配置必须通过校验
+	for _, example := range []string{"config.json.example", "config.yaml.example"} {
+		data, err := os.ReadFile(example)
+		require.NoError(t, err)
+		path = filepath.Join(dir, strings.TrimSuffix(example, ".example"))
+		require.NoError(t, os.WriteFile(path, data, 0600))
+		cfg, err = loadConfigFile(path)
+		require.NoError(t, err, example)
+		assert.NoError(t, cfg.Validate(), example)
+	}
+}
+
+func TestShutdownOptionsFromEnv(t *testing.T) {
+	opts := shutdownOptionsFromEnv()
+	assert.Equal(t, trader.DefaultDrainTimeout, opts.DrainTimeout)
+	assert.False(t, opts.CancelProtectiveOrders)
+
+	t.Setenv("NOFX_SHUTDOWN_TIMEOUT", "45s")
+	t.Setenv("NOFX_SHUTDOWN_CANCEL_ORDERS", "true")
+	opts = shutdownOptionsFromEnv()
+	assert.Equal(t, 45*time.Second, opts.DrainTimeout)
+	assert.True(t, opts.CancelProtectiveOrders)
+
+	t.Setenv("NOFX_SHUTDOWN_TIMEOUT", "soon")
+	assert.Equal(t, trader.DefaultDrainTimeout, shutdownOptionsFromEnv().DrainTimeout)
+}
@@ -0,0 +1,221 @@
+package control
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"nofx/logger"
+	"nofx/store"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed dashboard.html
+var dashboardHTML []byte
+
+// snapshotInterval SSE 推送快照的间隔（测试中可替换）
+var snapshotInterval = 5 * time.Second
+
+// 看板数据条数
+const (
+	dashboardDecisions = 10
+	dashboardLogLines  = 200
+	maxEquityPoints    = 10000
+)
+
+// EquityPoint 净值曲线上的一个点
+type EquityPoint struct {
+	Time          time.Time `json:"time"`
+	TotalEquity   float64   `json:"total_equity"`
+	WalletBalance float64   `json:"wallet_balance,omitempty"`
+	UnrealizedPnL float64   `json:"unrealized_pnl,omitempty"`
+	PositionCount int       `json:"position_count"`
+	DrawdownPct   float64   `json:"drawdown_pct"` // 相对此前最高净值的回撤（%）
+	CycleNumber   int       `json:"cycle_number,omitempty"`
+}
+
+// EquitySnapshotSource 支持查询定时净值快照的交易员（启用事件日志并设置 NOFX_EQUITY_SNAPSHOT_INTERVAL）
+type EquitySnapshotSource interface {
+	GetEquitySnapshots(since, until time.Time, limit int) ([]store.EquitySnapshot, error)
+}
+
+// DecisionSummary 看板展示的决策摘要（不含提示词和思维链）
+type DecisionSummary struct {
+	Time         time.Time               `json:"time"`
+	CycleNumber  int                     `json:"cycle_number"`
+	Success      bool                    `json:"success"`
+	ErrorMessage string                  `json:"error_message,omitempty"`
+	Decisions    []logger.DecisionAction `json:"decisions"`
+	ExecutionLog []string                `json:"execution_log"`
+}
+
+// TraderSnapshot 一个交易员的实时状态
+type TraderSnapshot struct {
+	ID        string                   `json:"trader_id"`
+	Name      string                   `json:"trader_name"`
+	Status    map[string]interface{}   `json:"status"`
+	Account   map[string]interface{}   `json:"account,omitempty"`
+	Positions []map[string]interface{} `json:"positions"`
+	Decisions []DecisionSummary        `json:"decisions"`
+	Error     string                   `json:"error,omitempty"`
+}
+
+// SetLogBuffer 设置看板展示的进程日志来源（nil 表示不推送日志）
+func (s *Server) SetLogBuffer(logs *LogBuffer) {
+	s.logs = logs
+}
+
+// handleDashboard 看板页面（静态页面，数据接口仍需 token）
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(dashboardHTML)
+}
+
+// handleEquity 净值曲线：优先使用定时净值快照，没有快照时从决策日志生成
+func (s *Server) handleEquity(w http.ResponseWriter, r *http.Request) {
+	s.withTrader(w, r, func(t Trader) {
+		if source, ok := t.(EquitySnapshotSource); ok {
+			if snapshots, err := source.GetEquitySnapshots(time.Time{}, time.Time{}, maxEquityPoints); err == nil && len(snapshots) > 0 {
+				points := make([]EquityPoint, 0, len(snapshots))
+				for _, snap := range snapshots {
+					points = append(points, EquityPoint{
+						Time:          snap.Time,
+						TotalEquity:   snap.TotalEquity(),
+						WalletBalance: snap.WalletBalance,
+						UnrealizedPnL: snap.UnrealizedPnL,
+						PositionCount: snap.PositionCount,
+					})
+				}
+				writeJSON(w, http.StatusOK, withDrawdown(points))
+				return
+			}
+		}
+
+		records, err := t.GetDecisionLogger().GetLatestRecords(maxEquityPoints)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("获取决策日志失败: %v", err))
+			return
+		}
+		points := make([]EquityPoint, 0, len(records))
+		for _, record := range records {
+			equity := record.AccountState.TotalBalance + record.AccountState.TotalUnrealizedProfit
+			if equity <= 0 {
+				continue // 获取账户失败的周期没有账户快照
+			}
+			points = append(points, EquityPoint{
+				Time:          record.Timestamp,
+				TotalEquity:   equity,
+				PositionCount: record.AccountState.PositionCount,
+				CycleNumber:   record.CycleNumber,
+			})
+		}
+		writeJSON(w, http.StatusOK, withDrawdown(points))
+	})
+}
+
+// withDrawdown 计算每个点相对此前最高净值的回撤
+func withDrawdown(points []EquityPoint) []EquityPoint {
+	peak := 0.0
+	for i := range points {
+		if points[i].TotalEquity > peak {
+			peak = points[i].TotalEquity
+		}
+		if peak > 0 {
+			points[i].DrawdownPct = (peak - points[i].TotalEquity) / peak * 100
+		}
+	}
+	return points
+}
+
+// handleEvents SSE：定时推送全部交易员快照（snapshot 事件），实时推送进程日志（log 事件）
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, "不支持流式响应")
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // 关闭 nginx 缓冲
+
+	var logCh <-chan LogLine
+	if s.logs != nil {
+		ch, cancel := s.logs.Subscribe()
+		defer cancel()
+		logCh = ch
+		for _, line := range s.logs.Recent(dashboardLogLines) {
+			writeEvent(w, "log", line)
+		}
+	}
+	writeEvent(w, "snapshot", s.snapshot())
+	flusher.Flush()
+
+	ticker := time.NewTicker(snapshotInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			writeEvent(w, "snapshot", s.snapshot())
+		case line := <-logCh:
+			writeEvent(w, "log", line)
+		}
+		flusher.Flush()
+	}
+}
+
+// snapshot 收集全部交易员的实时状态
+func (s *Server) snapshot() []TraderSnapshot {
+	traders := s.traders()
+	snapshots := make([]TraderSnapshot, 0, len(traders))
+	for _, t := range traders {
+		snap := TraderSnapshot{ID: t.GetID(), Name: t.GetName(), Status: t.GetStatus()}
+		var errs []string
+		if account, err := t.GetAccountInfo(); err != nil {
+			errs = append(errs, fmt.Sprintf("获取账户信息失败: %v", err))
+		} else {
+			snap.Account = account
+		}
+		if positions, err := t.GetPositions(); err != nil {
+			errs = append(errs, fmt.Sprintf("获取持仓失败: %v", err))
+		} else {
+			snap.Positions = positions
+		}
+		if dl := t.GetDecisionLogger(); dl != nil {
+			records, err := dl.GetLatestRecords(dashboardDecisions)
+			if err != nil {
+				errs = append(errs, fmt.Sprintf("获取决策日志失败: %v", err))
+			}
+			for i := len(records) - 1; i >= 0; i-- {
+				record := records[i]
+				snap.Decisions = append(snap.Decisions, DecisionSummary{
+					Time:         record.Timestamp,
+					CycleNumber:  record.CycleNumber,
+					Success:      record.Success,
+					ErrorMessage: record.ErrorMessage,
+					Decisions:    record.Decisions,
+					ExecutionLog: record.ExecutionLog,
+				})
+			}
+		}
+		if len(errs) > 0 {
+			snap.Error = strings.Join(errs, "; ")
+		}
+		snapshots = append(snapshots, snap)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].ID < snapshots[j].ID })
+	return snapshots
+}
+
+// writeEvent 写入一条 SSE 事件
+func writeEvent(w http.ResponseWriter, event string, v interface{}) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data)
+}
@@ -0,0 +1,175 @@
+package control
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDashboardPage(t *testing.T) {
+	s := newTestServer(t, &fakeTrader{id: "a"})
+	rec := do(s, "GET", "/", "", false)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "EventSource") {
+		t.Errorf("unexpected dashboard response: %d", rec.Code)
+	}
+	if rec := do(s, "GET", "/nope", "", false); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown path, got %d", rec.Code)
+	}
+}
+
+func TestEquity(t *testing.T) {
+	tr := &fakeTrader{id: "a", logger: logger.NewDecisionLogger(t.TempDir())}
+	tr.logger.LogDecision(&logger.DecisionRecord{AccountState: logger.AccountSnapshot{TotalBalance: 1000, TotalUnrealizedProfit: 5}})
+	tr.logger.LogDecision(&logger.DecisionRecord{}) // 账户获取失败的周期
+	tr.logger.LogDecision(&logger.DecisionRecord{AccountState: logger.AccountSnapshot{TotalBalance: 1010}})
+	s := newTestServer(t, tr)
+
+	rec := do(s, "GET", "/equity?token=secret", "", false)
+	var points []EquityPoint
+	if err := json.Unmarshal(rec.Body.Bytes(), &points); err != nil {
+		t.Fatalf("decode equity: %v (%s)", err, rec.Body)
+	}
+	if len(points) != 2 || points[0].TotalEquity != 1005 || points[1].CycleNumber != 3 {
+		t.Errorf("unexpected equity points: %+v", points)
+	}
+}
+
+// snapshotTrader 提供净值快照的 fakeTrader
+type snapshotTrader struct {
+	*fakeTrader
+	snapshots []store.EquitySnapshot
+}
+
+func (f *snapshotTrader) GetEquitySnapshots(since, until time.Time, limit int) ([]store.EquitySnapshot, error) {
+	return f.snapshots, nil
+}
+
+func TestEquityPrefersSnapshots(t *testing.T) {
+	tr := &fakeTrader{id: "a", logger: logger.NewDecisionLogger(t.TempDir())}
+	tr.logger.LogDecision(&logger.DecisionRecord{AccountState: logger.AccountSnapshot{TotalBalance: 1000}})
+	base := time.Unix(1700000000, 0)
+	st := &snapshotTrader{fakeTrader: tr, snapshots: []store.EquitySnapshot{
+		{Time: base, WalletBalance: 1000, UnrealizedPnL: 0, PositionCount: 0},
+		{Time: base.Add(15 * time.Minute), WalletBalance: 1000, UnrealizedPnL: 200, PositionCount: 1},
+		{Time: base.Add(30 * time.Minute), WalletBalance: 1050, UnrealizedPnL: -150, PositionCount: 1},
+	}}
+	s, err := NewServer(":0", "secret", func() []Trader { return []Trader{st} })
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	rec := do(s, "GET", "/equity?token=secret", "", false)
+	var points []EquityPoint
+	if err := json.Unmarshal(rec.Body.Bytes(), &points); err != nil {
+		t.Fatalf("decode equity: %v (%s)", err, rec.Body)
+	}
+	if len(points) != 3 || points[1].TotalEquity != 1200 || points[2].UnrealizedPnL != -150 || points[2].PositionCount != 1 {
+		t.Fatalf("unexpected equity points: %+v", points)
+	}
+	if points[1].DrawdownPct != 0 || points[2].DrawdownPct != 25 {
+		t.Errorf("expected 25%% drawdown from the 1200 peak, got %+v", points)
+	}
+}
+
+func TestEventsStream(t *testing.T) {
+	orig := snapshotInterval
+	snapshotInterval = 20 * time.Millisecond
+	defer func() { snapshotInterval = orig }()
+
+	tr := &fakeTrader{id: "a", logger: logger.NewDecisionLogger(t.TempDir())}
+	tr.logger.LogDecision(&logger.DecisionRecord{Success: true, InputPrompt: "long prompt"})
+	s := newTestServer(t, tr)
+	logs := NewLogBuffer(10)
+	fmt.Fprintln(logs, "earlier line")
+	s.SetLogBuffer(logs)
+
+	ts := httptest.NewServer(s.handler)
+	defer ts.Close()
+	if res, err := http.Get(ts.URL + "/events"); err != nil || res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("expected 401 without token: %v %v", res, err)
+	}
+	res, err := http.Get(ts.URL + "/events?token=secret")
+	if err != nil {
+		t.Fatalf("GET /events: %v", err)
+	}
+	defer res.Body.Close()
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+
+	events := make(chan [2]string, 10)
+	go func() {
+		scanner := bufio.NewScanner(res.Body)
+		var event string
+		for scanner.Scan() {
+			line := scanner.Text()
+			if v, ok := strings.CutPrefix(line, "event: "); ok {
+				event = v
+			} else if v, ok := strings.CutPrefix(line, "data: "); ok {
+				events <- [2]string{event, v}
+			}
+		}
+	}()
+	next := func() [2]string {
+		select {
+		case e := <-events:
+			return e
+		case <-time.After(2 * time.Second):
+			t.Fatal("timed out waiting for event")
+			return [2]string{}
+		}
+	}
+
+	if e := next(); e[0] != "log" || !strings.Contains(e[1], "earlier line") {
+		t.Errorf("expected buffered log first, got %v", e)
+	}
+	e := next()
+	var snaps []TraderSnapshot
+	if err := json.Unmarshal([]byte(e[1]), &snaps); e[0] != "snapshot" || err != nil {
+		t.Fatalf("expected snapshot, got %v (%v)", e, err)
+	}
+	if len(snaps) != 1 || snaps[0].Account["total_equity"] != 1000.0 || len(snaps[0].Decisions) != 1 || !snaps[0].Decisions[0].Success {
+		t.Errorf("unexpected snapshot: %+v", snaps)
+	}
+	if strings.Contains(e[1], "long prompt") {
+		t.Error("snapshot should not include prompts")
+	}
+
+	fmt.Fprintln(logs, "live line")
+	for i := 0; i < 10; i++ {
+		if e := next(); e[0] == "log" {
+			if !strings.Contains(e[1], "live line") {
+				t.Errorf("unexpected log event: %v", e)
+			}
+			return
+		}
+	}
+	t.Error("live log line was not pushed")
+}
+
+func TestLogBuffer(t *testing.T) {
+	b := NewLogBuffer(2)
+	ch, cancel := b.Subscribe()
+	b.Write([]byte("one\ntw"))
+	b.Write([]byte("o\n\nthree\n"))
+
+	recent := b.Recent(0)
+	if len(recent) != 2 || recent[0].Text != "two" || recent[1].Text != "three" {
+		t.Errorf("unexpected recent lines: %+v", recent)
+	}
+	if got := (<-ch).Text; got != "one" {
+		t.Errorf("first pushed line = %q", got)
+	}
+	cancel()
+	b.Write([]byte("four\n"))
+	if len(ch) != 2 {
+		t.Errorf("expected no lines after unsubscribe, %d buffered", len(ch))
+	}
+}
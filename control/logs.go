@@ -0,0 +1,85 @@
+package control
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogLine 一行进程日志
+type LogLine struct {
+	Time time.Time `json:"time"`
+	Text string    `json:"text"`
+}
+
+// LogBuffer 保存最近的进程日志并推送给订阅者（作为 log 输出的一个 io.Writer）
+type LogBuffer struct {
+	mu          sync.Mutex
+	size        int
+	lines       []LogLine
+	partial     []byte
+	subscribers map[chan LogLine]struct{}
+}
+
+// NewLogBuffer 创建日志缓冲（保留最近 size 行）
+func NewLogBuffer(size int) *LogBuffer {
+	if size <= 0 {
+		size = 500
+	}
+	return &LogBuffer{size: size, subscribers: make(map[chan LogLine]struct{})}
+}
+
+// Write 按行拆分写入的日志
+func (b *LogBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.partial = append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(b.partial, '\n')
+		if i < 0 {
+			break
+		}
+		text := strings.TrimRight(string(b.partial[:i]), "\r")
+		b.partial = b.partial[i+1:]
+		if text == "" {
+			continue
+		}
+		line := LogLine{Time: time.Now(), Text: text}
+		b.lines = append(b.lines, line)
+		if len(b.lines) > b.size {
+			b.lines = b.lines[len(b.lines)-b.size:]
+		}
+		for ch := range b.subscribers {
+			select {
+			case ch <- line:
+			default: // 订阅者处理不过来时丢弃，不阻塞日志输出
+			}
+		}
+	}
+	return len(p), nil
+}
+
+// Recent 最近 n 行日志（按时间正序）
+func (b *LogBuffer) Recent(n int) []LogLine {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if n <= 0 || n > len(b.lines) {
+		n = len(b.lines)
+	}
+	return append([]LogLine(nil), b.lines[len(b.lines)-n:]...)
+}
+
+// Subscribe 订阅新日志，返回的函数用于取消订阅
+func (b *LogBuffer) Subscribe() (<-chan LogLine, func()) {
+	ch := make(chan LogLine, 100)
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+	return ch, func() {
+		b.mu.Lock()
+		delete(b.subscribers, ch)
+		b.mu.Unlock()
+	}
+}
@@ -0,0 +1,336 @@
+package control
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"nofx/logger"
+	"nofx/manager"
+	"nofx/metrics"
+	"nofx/trader"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 决策查询条数
+const (
+	defaultDecisionLimit = 20
+	maxDecisionLimit     = 200
+)
+
+// Trader 控制接口可操作的交易员（*trader.AutoTrader 实现）
+type Trader interface {
+	GetID() string
+	GetName() string
+	GetStatus() map[string]interface{}
+	GetAccountInfo() (map[string]interface{}, error)
+	GetPositions() ([]map[string]interface{}, error)
+	GetDecisionLogger() logger.IDecisionLogger
+	GetPauseState() trader.PauseState
+	Pause(reason string)
+	Resume() error
+	ClosePosition(symbol string) error
+	KillSwitch(reason string) error
+}
+
+// TraderSource 返回当前已加载的交易员
+type TraderSource func() []Trader
+
+// FromManager 以 TraderManager 中已加载的交易员作为数据源
+func FromManager(tm *manager.TraderManager) TraderSource {
+	return func() []Trader {
+		all := tm.GetAllTraders()
+		traders := make([]Trader, 0, len(all))
+		for _, t := range all {
+			traders = append(traders, t)
+		}
+		return traders
+	}
+}
+
+// Server 运维用的状态查询与控制 HTTP 服务（独立于前端 API，使用固定 token 认证）
+type Server struct {
+	token      string
+	traders    TraderSource
+	logs       *LogBuffer
+	tvSecret   string // TradingView 告警的共享密钥（空=不接收告警）
+	handler    http.Handler
+	httpServer *http.Server
+	closeCtx   context.Context    // 关闭时取消，用于结束 SSE 长连接
+	closeAll   context.CancelFunc // 取消 closeCtx
+}
+
+// NewServer 创建控制服务（token 不能为空）
+func NewServer(addr, token string, traders TraderSource) (*Server, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("控制接口需要设置访问 token")
+	}
+	s := &Server{token: token, traders: traders}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.handleDashboard)
+	mux.HandleFunc("GET /health", s.handleHealth)
+	mux.Handle("GET /events", s.auth(s.handleEvents))
+	mux.Handle("GET /equity", s.auth(s.handleEquity))
+	mux.Handle("GET /metrics", s.auth(metrics.Default.Handler().ServeHTTP))
+	mux.Handle("GET /positions", s.auth(s.handlePositions))
+	mux.Handle("GET /balance", s.auth(s.handleBalance))
+	mux.Handle("GET /decisions", s.auth(s.handleDecisions))
+	mux.Handle("POST /pause", s.auth(s.handlePause))
+	mux.Handle("POST /resume", s.auth(s.handleResume))
+	mux.Handle("POST /close/{symbol}", s.auth(s.handleClose))
+	mux.Handle("POST /killswitch", s.auth(s.handleKillSwitch))
+	mux.HandleFunc("POST /webhook/tradingview", s.handleTradingView) // 使用告警内的共享密钥认证
+	s.handler = mux
+	s.closeCtx, s.closeAll = context.WithCancel(context.Background())
+	s.httpServer = &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return s.closeCtx },
+	}
+	return s, nil
+}
+
+// Start 启动控制服务（阻塞直到关闭）
+func (s *Server) Start() error {
+	log.Printf("🎛️  控制接口启动在 %s", s.httpServer.Addr)
+	log.Printf("  • GET  /                       - 实时看板（浏览器打开）")
+	log.Printf("  • GET  /health                 - 健康检查（无需认证）")
+	log.Printf("  • GET  /events                 - 实时推送（SSE）")
+	log.Printf("  • GET  /equity?trader_id=xxx    - 净值曲线")
+	log.Printf("  • GET  /metrics                - Prometheus 指标")
+	log.Printf("  • GET  /positions?trader_id=xxx - 当前持仓")
+	log.Printf("  • GET  /balance?trader_id=xxx   - 账户余额")
+	log.Printf("  • GET  /decisions?trader_id=xxx - 最近决策")
+	log.Printf("  • POST /pause | /resume         - 暂停/恢复交易")
+	log.Printf("  • POST /close/{symbol}          - 平掉指定币种持仓")
+	log.Printf("  • POST /killswitch              - 暂停并平掉全部持仓（不指定 trader_id 时作用于所有交易员）")
+	if s.tvSecret != "" {
+		log.Printf("  • POST /webhook/tradingview     - 接收 TradingView 告警（共享密钥认证）")
+	}
+	if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭控制服务
+func (s *Server) Shutdown() error {
+	s.closeAll()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return s.httpServer.Shutdown(ctx)
+}
+
+// auth 校验 Authorization: Bearer <token>（浏览器 EventSource 无法设置请求头，也接受 ?token= 参数）
+func (s *Server) auth(next http.HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok {
+			token = r.URL.Query().Get("token")
+			ok = token != ""
+		}
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			writeError(w, http.StatusUnauthorized, "无效的访问 token")
+			return
+		}
+		next(w, r)
+	})
+}
+
+// selectTrader 按 trader_id 查询参数选择交易员（只有一个交易员时可省略）
+func (s *Server) selectTrader(r *http.Request) (Trader, int, error) {
+	return s.findTrader(r.URL.Query().Get("trader_id"))
+}
+
+// findTrader 按 ID 查找交易员（只有一个交易员时 ID 可为空）
+func (s *Server) findTrader(id string) (Trader, int, error) {
+	traders := s.traders()
+	if id == "" {
+		if len(traders) == 1 {
+			return traders[0], 0, nil
+		}
+		ids := make([]string, 0, len(traders))
+		for _, t := range traders {
+			ids = append(ids, t.GetID())
+		}
+		sort.Strings(ids)
+		return nil, http.StatusBadRequest, fmt.Errorf("存在 %d 个交易员，请通过 trader_id 指定: %s", len(traders), strings.Join(ids, ", "))
+	}
+	for _, t := range traders {
+		if t.GetID() == id {
+			return t, 0, nil
+		}
+	}
+	return nil, http.StatusNotFound, fmt.Errorf("交易员不存在: %s", id)
+}
+
+// withTrader 选择交易员后调用处理函数
+func (s *Server) withTrader(w http.ResponseWriter, r *http.Request, fn func(Trader)) {
+	t, status, err := s.selectTrader(r)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	fn(t)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	traders := s.traders()
+	statuses := make([]map[string]interface{}, 0, len(traders))
+	for _, t := range traders {
+		status := t.GetStatus()
+		statuses = append(statuses, map[string]interface{}{
+			"trader_id":        t.GetID(),
+			"trader_name":      t.GetName(),
+			"is_running":       status["is_running"],
+			"paused":           status["paused"],
+			"reconcile_halted": status["reconcile_halted"],
+		})
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i]["trader_id"].(string) < statuses[j]["trader_id"].(string)
+	})
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"status":  "ok",
+		"time":    time.Now().Format(time.RFC3339),
+		"traders": statuses,
+	})
+}
+
+func (s *Server) handlePositions(w http.ResponseWriter, r *http.Request) {
+	s.withTrader(w, r, func(t Trader) {
+		positions, err := t.GetPositions()
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("获取持仓失败: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, positions)
+	})
+}
+
+func (s *Server) handleBalance(w http.ResponseWriter, r *http.Request) {
+	s.withTrader(w, r, func(t Trader) {
+		account, err := t.GetAccountInfo()
+		if err != nil {
+			writeError(w, http.StatusBadGateway, fmt.Sprintf("获取账户信息失败: %v", err))
+			return
+		}
+		writeJSON(w, http.StatusOK, account)
+	})
+}
+
+func (s *Server) handleDecisions(w http.ResponseWriter, r *http.Request) {
+	limit := defaultDecisionLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			writeError(w, http.StatusBadRequest, "limit 必须是正整数")
+			return
+		}
+		limit = min(n, maxDecisionLimit)
+	}
+	s.withTrader(w, r, func(t Trader) {
+		records, err := t.GetDecisionLogger().GetLatestRecords(limit)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, fmt.Sprintf("获取决策日志失败: %v", err))
+			return
+		}
+		// 最新的在前
+		for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+			records[i], records[j] = records[j], records[i]
+		}
+		writeJSON(w, http.StatusOK, records)
+	})
+}
+
+// controlRequest 控制操作的请求体（可选）
+type controlRequest struct {
+	Reason string `json:"reason"`
+}
+
+// reason 读取请求体中的操作原因
+func reason(r *http.Request, fallback string) string {
+	var req controlRequest
+	if r.Body != nil {
+		json.NewDecoder(r.Body).Decode(&req) // 请求体可省略
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return fallback
+	}
+	return req.Reason
+}
+
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	s.withTrader(w, r, func(t Trader) {
+		t.Pause(reason(r, "控制接口暂停"))
+		writeJSON(w, http.StatusOK, t.GetPauseState())
+	})
+}
+
+func (s *Server) handleResume(w http.ResponseWriter, r *http.Request) {
+	s.withTrader(w, r, func(t Trader) {
+		if err := t.Resume(); err != nil {
+			writeError(w, http.StatusConflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, t.GetPauseState())
+	})
+}
+
+func (s *Server) handleClose(w http.ResponseWriter, r *http.Request) {
+	symbol := strings.ToUpper(r.PathValue("symbol"))
+	s.withTrader(w, r, func(t Trader) {
+		if err := t.ClosePosition(symbol); err != nil {
+			writeError(w, http.StatusBadGateway, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"message": symbol + " 已平仓"})
+	})
+}
+
+// handleKillSwitch 不指定 trader_id 时作用于所有交易员
+func (s *Server) handleKillSwitch(w http.ResponseWriter, r *http.Request) {
+	why := reason(r, "控制接口紧急停止")
+	var targets []Trader
+	if r.URL.Query().Get("trader_id") == "" {
+		targets = s.traders()
+	} else {
+		t, status, err := s.selectTrader(r)
+		if err != nil {
+			writeError(w, status, err.Error())
+			return
+		}
+		targets = []Trader{t}
+	}
+
+	results := make(map[string]string, len(targets))
+	status := http.StatusOK
+	for _, t := range targets {
+		if err := t.KillSwitch(why); err != nil {
+			results[t.GetID()] = err.Error()
+			status = http.StatusBadGateway
+		} else {
+			results[t.GetID()] = "ok"
+		}
+	}
+	writeJSON(w, status, map[string]interface{}{"results": results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
@@ -0,0 +1,193 @@
+package control
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"nofx/logger"
+	"nofx/trader"
+	"strings"
+	"testing"
+)
+
+// fakeTrader 记录控制操作的测试交易员
+type fakeTrader struct {
+	id        string
+	logger    logger.IDecisionLogger
+	pause     trader.PauseState
+	closed    []string
+	killed    string
+	killError error
+}
+
+func (f *fakeTrader) GetID() string   { return f.id }
+func (f *fakeTrader) GetName() string { return "Trader " + f.id }
+func (f *fakeTrader) GetStatus() map[string]interface{} {
+	return map[string]interface{}{"is_running": true, "paused": f.pause.Paused, "reconcile_halted": false}
+}
+func (f *fakeTrader) GetAccountInfo() (map[string]interface{}, error) {
+	return map[string]interface{}{"total_equity": 1000.0}, nil
+}
+func (f *fakeTrader) GetPositions() ([]map[string]interface{}, error) {
+	return []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long"}}, nil
+}
+func (f *fakeTrader) GetDecisionLogger() logger.IDecisionLogger { return f.logger }
+func (f *fakeTrader) GetPauseState() trader.PauseState          { return f.pause }
+func (f *fakeTrader) Pause(reason string)                       { f.pause = trader.PauseState{Paused: true, Reason: reason} }
+func (f *fakeTrader) Resume() error {
+	if !f.pause.Paused {
+		return fmt.Errorf("not paused")
+	}
+	f.pause = trader.PauseState{}
+	return nil
+}
+func (f *fakeTrader) ClosePosition(symbol string) error {
+	if symbol != "BTCUSDT" {
+		return fmt.Errorf("没有 %s 的持仓", symbol)
+	}
+	f.closed = append(f.closed, symbol)
+	return nil
+}
+func (f *fakeTrader) KillSwitch(reason string) error {
+	f.killed = reason
+	f.pause = trader.PauseState{Paused: true, Reason: reason}
+	return f.killError
+}
+
+func newTestServer(t *testing.T, traders ...*fakeTrader) *Server {
+	t.Helper()
+	s, err := NewServer(":0", "secret", func() []Trader {
+		list := make([]Trader, len(traders))
+		for i, tr := range traders {
+			list[i] = tr
+		}
+		return list
+	})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	return s
+}
+
+func do(s *Server, method, path, body string, authorized bool) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if authorized {
+		req.Header.Set("Authorization", "Bearer secret")
+	}
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestNewServerRequiresToken(t *testing.T) {
+	if _, err := NewServer(":0", " ", func() []Trader { return nil }); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
+
+func TestAuth(t *testing.T) {
+	s := newTestServer(t, &fakeTrader{id: "a"})
+	if rec := do(s, "GET", "/health", "", false); rec.Code != http.StatusOK {
+		t.Errorf("/health should be public, got %d", rec.Code)
+	}
+	if rec := do(s, "GET", "/positions", "", false); rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 without token, got %d", rec.Code)
+	}
+	req := httptest.NewRequest("GET", "/positions", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	rec := httptest.NewRecorder()
+	s.handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with wrong token, got %d", rec.Code)
+	}
+	if rec := do(s, "GET", "/positions", "", true); rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with token, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestReadEndpoints(t *testing.T) {
+	tr := &fakeTrader{id: "a", logger: logger.NewDecisionLogger(t.TempDir())}
+	for i := 1; i <= 3; i++ {
+		tr.logger.LogDecision(&logger.DecisionRecord{CycleNumber: i, Success: true})
+	}
+	s := newTestServer(t, tr)
+
+	rec := do(s, "GET", "/balance", "", true)
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), "total_equity") {
+		t.Errorf("unexpected /balance response: %d %s", rec.Code, rec.Body)
+	}
+
+	rec = do(s, "GET", "/decisions?limit=2", "", true)
+	var records []logger.DecisionRecord
+	if err := json.Unmarshal(rec.Body.Bytes(), &records); err != nil {
+		t.Fatalf("decode decisions: %v", err)
+	}
+	if len(records) != 2 || records[0].CycleNumber != 3 {
+		t.Errorf("expected 2 newest-first decisions, got %+v", records)
+	}
+	if rec := do(s, "GET", "/decisions?limit=x", "", true); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 for bad limit, got %d", rec.Code)
+	}
+}
+
+func TestTraderSelection(t *testing.T) {
+	a, b := &fakeTrader{id: "a"}, &fakeTrader{id: "b"}
+	s := newTestServer(t, a, b)
+
+	if rec := do(s, "POST", "/pause", "", true); rec.Code != http.StatusBadRequest {
+		t.Errorf("expected 400 when trader_id is ambiguous, got %d", rec.Code)
+	}
+	if rec := do(s, "POST", "/pause?trader_id=c", "", true); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown trader, got %d", rec.Code)
+	}
+	if rec := do(s, "POST", "/pause?trader_id=b", `{"reason":"maintenance"}`, true); rec.Code != http.StatusOK {
+		t.Fatalf("pause failed: %d %s", rec.Code, rec.Body)
+	}
+	if a.pause.Paused || !b.pause.Paused || b.pause.Reason != "maintenance" {
+		t.Errorf("unexpected pause state: a=%+v b=%+v", a.pause, b.pause)
+	}
+}
+
+func TestControlEndpoints(t *testing.T) {
+	tr := &fakeTrader{id: "a"}
+	s := newTestServer(t, tr)
+
+	if rec := do(s, "POST", "/resume", "", true); rec.Code != http.StatusConflict {
+		t.Errorf("expected 409 when not paused, got %d", rec.Code)
+	}
+	do(s, "POST", "/pause", "", true)
+	if rec := do(s, "POST", "/resume", "", true); rec.Code != http.StatusOK || tr.pause.Paused {
+		t.Errorf("resume failed: %d paused=%v", rec.Code, tr.pause.Paused)
+	}
+
+	if rec := do(s, "POST", "/close/btcusdt", "", true); rec.Code != http.StatusOK || len(tr.closed) != 1 {
+		t.Errorf("close failed: %d %v", rec.Code, tr.closed)
+	}
+	if rec := do(s, "POST", "/close/ETHUSDT", "", true); rec.Code != http.StatusBadGateway {
+		t.Errorf("expected close error for symbol without position, got %d", rec.Code)
+	}
+	if rec := do(s, "GET", "/close/BTCUSDT", "", true); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("expected 405 for GET on control endpoint, got %d", rec.Code)
+	}
+}
+
+func TestKillSwitchAllTraders(t *testing.T) {
+	a, b := &fakeTrader{id: "a"}, &fakeTrader{id: "b", killError: fmt.Errorf("close failed")}
+	s := newTestServer(t, a, b)
+
+	rec := do(s, "POST", "/killswitch", `{"reason":"exchange outage"}`, true)
+	if rec.Code != http.StatusBadGateway {
+		t.Errorf("expected 502 when one trader fails, got %d", rec.Code)
+	}
+	var resp struct {
+		Results map[string]string `json:"results"`
+	}
+	json.Unmarshal(rec.Body.Bytes(), &resp)
+	if resp.Results["a"] != "ok" || resp.Results["b"] != "close failed" {
+		t.Errorf("unexpected results: %v", resp.Results)
+	}
+	if a.killed != "exchange outage" || !b.pause.Paused {
+		t.Errorf("killswitch not applied to all traders: a=%q b=%+v", a.killed, b.pause)
+	}
+}
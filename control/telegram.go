@@ -0,0 +1,405 @@
+package control
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// confirmTimeout 危险操作（平仓/暂停/恢复）等待 /confirm 的有效期
+var confirmTimeout = 60 * time.Second
+
+// telegramAPI 机器人用到的 Telegram 接口（*tgbotapi.BotAPI 实现，测试时替换）
+type telegramAPI interface {
+	GetUpdatesChan(config tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel
+	Send(c tgbotapi.Chattable) (tgbotapi.Message, error)
+	StopReceivingUpdates()
+}
+
+// pendingAction 等待确认的操作
+type pendingAction struct {
+	description string
+	run         func() string
+	expires     time.Time
+}
+
+// TelegramBot Telegram 命令机器人：只响应白名单 chat，平仓/暂停/恢复需要 /confirm 二次确认
+type TelegramBot struct {
+	api     telegramAPI
+	allowed map[int64]bool
+	traders TraderSource
+
+	mu       sync.Mutex
+	pending  map[int64]*pendingAction // chat ID → 待确认操作
+	selected map[int64]string         // chat ID → /use 选中的交易员
+}
+
+// NewTelegramBot 创建命令机器人（token 和 chat 白名单不能为空）
+func NewTelegramBot(token string, chatIDs []int64, traders TraderSource) (*TelegramBot, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("telegram 机器人需要设置 bot token")
+	}
+	if len(chatIDs) == 0 {
+		return nil, fmt.Errorf("telegram 机器人需要设置允许的 chat ID")
+	}
+	api, err := tgbotapi.NewBotAPI(token)
+	if err != nil {
+		return nil, fmt.Errorf("创建telegram bot失败: %w", err)
+	}
+	return newTelegramBot(api, chatIDs, traders), nil
+}
+
+func newTelegramBot(api telegramAPI, chatIDs []int64, traders TraderSource) *TelegramBot {
+	allowed := make(map[int64]bool, len(chatIDs))
+	for _, id := range chatIDs {
+		allowed[id] = true
+	}
+	return &TelegramBot{
+		api:      api,
+		allowed:  allowed,
+		traders:  traders,
+		pending:  make(map[int64]*pendingAction),
+		selected: make(map[int64]string),
+	}
+}
+
+// ParseChatIDs 解析逗号分隔的 chat ID 列表
+func ParseChatIDs(s string) ([]int64, error) {
+	var ids []int64
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseInt(part, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的 chat ID %q: %w", part, err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// Start 开始接收命令（阻塞直到 Stop）
+func (b *TelegramBot) Start() {
+	log.Printf("📱 Telegram 命令机器人已启动（允许 %d 个 chat）", len(b.allowed))
+	u := tgbotapi.NewUpdate(0)
+	u.Timeout = 30
+	for update := range b.api.GetUpdatesChan(u) {
+		if update.Message != nil {
+			b.handleMessage(update.Message)
+		}
+	}
+}
+
+// Stop 停止接收命令
+func (b *TelegramBot) Stop() {
+	b.api.StopReceivingUpdates()
+}
+
+// handleMessage 处理一条消息（非白名单 chat 直接忽略）
+func (b *TelegramBot) handleMessage(msg *tgbotapi.Message) {
+	if msg.Chat == nil {
+		return
+	}
+	chatID := msg.Chat.ID
+	if !b.allowed[chatID] {
+		log.Printf("⚠️ 忽略未授权 chat %d 的 Telegram 消息", chatID)
+		return
+	}
+	if !msg.IsCommand() {
+		return
+	}
+	log.Printf("📱 Telegram 命令 [chat %d]: /%s %s", chatID, msg.Command(), msg.CommandArguments())
+	b.reply(chatID, b.handleCommand(chatID, msg.Command(), strings.TrimSpace(msg.CommandArguments())))
+}
+
+// Broadcast 向所有白名单 chat 推送消息（用于交易员告警），返回第一个发送错误
+func (b *TelegramBot) Broadcast(text string) error {
+	ids := make([]int64, 0, len(b.allowed))
+	for id := range b.allowed {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var firstErr error
+	for _, id := range ids {
+		if _, err := b.api.Send(tgbotapi.NewMessage(id, text)); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("发送 Telegram 消息到 chat %d 失败: %w", id, err)
+		}
+	}
+	return firstErr
+}
+
+func (b *TelegramBot) reply(chatID int64, text string) {
+	if _, err := b.api.Send(tgbotapi.NewMessage(chatID, text)); err != nil {
+		log.Printf("⚠️ 发送 Telegram 回复失败: %v", err)
+	}
+}
+
+// handleCommand 执行命令并返回回复文本
+func (b *TelegramBot) handleCommand(chatID int64, command, args string) string {
+	switch command {
+	case "start", "help":
+		return telegramHelp
+	case "traders":
+		return b.cmdTraders(chatID)
+	case "use":
+		return b.cmdUse(chatID, args)
+	case "status":
+		return b.cmdStatus()
+	case "positions":
+		return b.cmdPositions(chatID)
+	case "close":
+		return b.cmdClose(chatID, args)
+	case "pause":
+		return b.cmdPause(chatID, args)
+	case "resume":
+		return b.cmdResume(chatID)
+	case "confirm":
+		return b.cmdConfirm(chatID)
+	case "cancel":
+		return b.cmdCancel(chatID)
+	default:
+		return fmt.Sprintf("未知命令 /%s，发送 /help 查看可用命令", command)
+	}
+}
+
+const telegramHelp = `可用命令:
+/status - 所有交易员状态
+/positions - 当前持仓
+/close BTCUSDT - 平掉指定币种持仓（需确认）
+/pause [原因] - 暂停交易（需确认）
+/resume - 恢复交易（需确认）
+/traders - 交易员列表
+/use <trader_id> - 选择要操作的交易员（多个交易员时）
+/confirm - 确认待执行操作
+/cancel - 取消待执行操作`
+
+// sortedTraders 按 ID 排序的交易员列表
+func (b *TelegramBot) sortedTraders() []Trader {
+	traders := b.traders()
+	sort.Slice(traders, func(i, j int) bool { return traders[i].GetID() < traders[j].GetID() })
+	return traders
+}
+
+// selectTrader 选择本 chat 要操作的交易员（只有一个交易员时无需 /use）
+func (b *TelegramBot) selectTrader(chatID int64) (Trader, error) {
+	traders := b.sortedTraders()
+	b.mu.Lock()
+	id := b.selected[chatID]
+	b.mu.Unlock()
+	if id != "" {
+		for _, t := range traders {
+			if t.GetID() == id {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("已选择的交易员 %s 不存在，请重新 /use", id)
+	}
+	switch len(traders) {
+	case 0:
+		return nil, fmt.Errorf("没有已加载的交易员")
+	case 1:
+		return traders[0], nil
+	}
+	ids := make([]string, 0, len(traders))
+	for _, t := range traders {
+		ids = append(ids, t.GetID())
+	}
+	return nil, fmt.Errorf("存在 %d 个交易员，请先 /use <trader_id> 选择: %s", len(traders), strings.Join(ids, ", "))
+}
+
+func (b *TelegramBot) cmdTraders(chatID int64) string {
+	traders := b.sortedTraders()
+	if len(traders) == 0 {
+		return "没有已加载的交易员"
+	}
+	b.mu.Lock()
+	selected := b.selected[chatID]
+	b.mu.Unlock()
+	var sb strings.Builder
+	sb.WriteString("交易员列表:")
+	for _, t := range traders {
+		mark := ""
+		if t.GetID() == selected {
+			mark = " ← 当前"
+		}
+		fmt.Fprintf(&sb, "\n• %s (%s)%s", t.GetName(), t.GetID(), mark)
+	}
+	return sb.String()
+}
+
+func (b *TelegramBot) cmdUse(chatID int64, id string) string {
+	if id == "" {
+		return "用法: /use <trader_id>"
+	}
+	for _, t := range b.traders() {
+		if t.GetID() == id {
+			b.mu.Lock()
+			b.selected[chatID] = id
+			b.mu.Unlock()
+			return fmt.Sprintf("✅ 已选择交易员 %s (%s)", t.GetName(), id)
+		}
+	}
+	return fmt.Sprintf("❌ 交易员不存在: %s", id)
+}
+
+func (b *TelegramBot) cmdStatus() string {
+	traders := b.sortedTraders()
+	if len(traders) == 0 {
+		return "没有已加载的交易员"
+	}
+	var sb strings.Builder
+	for i, t := range traders {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		status := t.GetStatus()
+		state := "⏹ 已停止"
+		if running, _ := status["is_running"].(bool); running {
+			state = "▶️ 运行中"
+		}
+		if pause := t.GetPauseState(); pause.Paused {
+			state = "⏸ 已暂停（" + pause.Reason + "）"
+		}
+		if halted, _ := status["reconcile_halted"].(bool); halted {
+			state += "，⛔ 对账暂停"
+		}
+		fmt.Fprintf(&sb, "%s (%s)\n状态: %s", t.GetName(), t.GetID(), state)
+
+		account, err := t.GetAccountInfo()
+		if err != nil {
+			fmt.Fprintf(&sb, "\n❌ 获取账户信息失败: %v", err)
+			continue
+		}
+		fmt.Fprintf(&sb, "\n净值: %.2f USDT，可用: %.2f USDT\n总盈亏: %+.2f USDT (%+.2f%%)，持仓: %d",
+			number(account["total_equity"]), number(account["available_balance"]),
+			number(account["total_pnl"]), number(account["total_pnl_pct"]), int(number(account["position_count"])))
+	}
+	return sb.String()
+}
+
+func (b *TelegramBot) cmdPositions(chatID int64) string {
+	t, err := b.selectTrader(chatID)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	positions, err := t.GetPositions()
+	if err != nil {
+		return fmt.Sprintf("❌ 获取持仓失败: %v", err)
+	}
+	if len(positions) == 0 {
+		return fmt.Sprintf("%s 当前没有持仓", t.GetName())
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "%s 持仓 (%d):", t.GetName(), len(positions))
+	for _, p := range positions {
+		fmt.Fprintf(&sb, "\n• %v %s %vx 数量 %.4f\n  开仓 %.4f → 标记 %.4f，盈亏 %+.2f USDT (%+.2f%%)",
+			p["symbol"], strings.ToUpper(fmt.Sprint(p["side"])), p["leverage"], number(p["quantity"]),
+			number(p["entry_price"]), number(p["mark_price"]),
+			number(p["unrealized_pnl"]), number(p["unrealized_pnl_pct"]))
+	}
+	return sb.String()
+}
+
+func (b *TelegramBot) cmdClose(chatID int64, args string) string {
+	symbol := strings.ToUpper(args)
+	if symbol == "" || strings.ContainsAny(symbol, " \t") {
+		return "用法: /close BTCUSDT"
+	}
+	t, err := b.selectTrader(chatID)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	return b.requestConfirm(chatID, fmt.Sprintf("平掉 %s 的 %s 持仓", t.GetName(), symbol), func() string {
+		if err := t.ClosePosition(symbol); err != nil {
+			return fmt.Sprintf("❌ 平仓失败: %v", err)
+		}
+		return fmt.Sprintf("✅ %s 已平仓", symbol)
+	})
+}
+
+func (b *TelegramBot) cmdPause(chatID int64, args string) string {
+	t, err := b.selectTrader(chatID)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	why := args
+	if why == "" {
+		why = "Telegram 暂停"
+	}
+	return b.requestConfirm(chatID, fmt.Sprintf("暂停 %s 的交易", t.GetName()), func() string {
+		t.Pause(why)
+		return fmt.Sprintf("⏸ %s 已暂停（%s）", t.GetName(), why)
+	})
+}
+
+func (b *TelegramBot) cmdResume(chatID int64) string {
+	t, err := b.selectTrader(chatID)
+	if err != nil {
+		return "❌ " + err.Error()
+	}
+	if !t.GetPauseState().Paused {
+		return fmt.Sprintf("%s 未处于暂停状态", t.GetName())
+	}
+	return b.requestConfirm(chatID, fmt.Sprintf("恢复 %s 的交易", t.GetName()), func() string {
+		if err := t.Resume(); err != nil {
+			return fmt.Sprintf("❌ 恢复失败: %v", err)
+		}
+		return fmt.Sprintf("▶️ %s 已恢复交易", t.GetName())
+	})
+}
+
+// requestConfirm 登记待确认操作（同一 chat 只保留最新一个）
+func (b *TelegramBot) requestConfirm(chatID int64, description string, run func() string) string {
+	b.mu.Lock()
+	b.pending[chatID] = &pendingAction{description: description, run: run, expires: time.Now().Add(confirmTimeout)}
+	b.mu.Unlock()
+	return fmt.Sprintf("⚠️ 确认%s？\n发送 /confirm 执行，/cancel 取消（%d 秒内有效）", description, int(confirmTimeout.Seconds()))
+}
+
+func (b *TelegramBot) cmdConfirm(chatID int64) string {
+	b.mu.Lock()
+	action := b.pending[chatID]
+	delete(b.pending, chatID)
+	b.mu.Unlock()
+	if action == nil {
+		return "没有待确认的操作"
+	}
+	if time.Now().After(action.expires) {
+		return fmt.Sprintf("⌛ 操作已过期: %s，请重新发送命令", action.description)
+	}
+	log.Printf("📱 Telegram 确认执行 [chat %d]: %s", chatID, action.description)
+	return action.run()
+}
+
+func (b *TelegramBot) cmdCancel(chatID int64) string {
+	b.mu.Lock()
+	action := b.pending[chatID]
+	delete(b.pending, chatID)
+	b.mu.Unlock()
+	if action == nil {
+		return "没有待确认的操作"
+	}
+	return "已取消: " + action.description
+}
+
+// number 把状态 map 中的数值转为 float64
+func number(v interface{}) float64 {
+	switch n := v.(type) {
+	case float64:
+		return n
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	}
+	return 0
+}
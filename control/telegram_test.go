@@ -0,0 +1,171 @@
+package control
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api/v5"
+)
+
+// fakeTelegram 记录发送的消息
+type fakeTelegram struct {
+	sent []tgbotapi.MessageConfig
+}
+
+func (f *fakeTelegram) GetUpdatesChan(tgbotapi.UpdateConfig) tgbotapi.UpdatesChannel {
+	return make(chan tgbotapi.Update)
+}
+func (f *fakeTelegram) Send(c tgbotapi.Chattable) (tgbotapi.Message, error) {
+	f.sent = append(f.sent, c.(tgbotapi.MessageConfig))
+	return tgbotapi.Message{}, nil
+}
+func (f *fakeTelegram) StopReceivingUpdates() {}
+
+func newTestBot(traders ...*fakeTrader) (*TelegramBot, *fakeTelegram) {
+	api := &fakeTelegram{}
+	bot := newTelegramBot(api, []int64{42}, func() []Trader {
+		list := make([]Trader, len(traders))
+		for i, tr := range traders {
+			list[i] = tr
+		}
+		return list
+	})
+	return bot, api
+}
+
+// send 模拟 chat 发送命令并返回机器人的回复（无回复时为空）
+func send(bot *TelegramBot, api *fakeTelegram, chatID int64, text string) string {
+	n := len(api.sent)
+	cmdLen := strings.IndexByte(text, ' ')
+	if cmdLen < 0 {
+		cmdLen = len(text)
+	}
+	bot.handleMessage(&tgbotapi.Message{
+		Chat:     &tgbotapi.Chat{ID: chatID},
+		Text:     text,
+		Entities: []tgbotapi.MessageEntity{{Type: "bot_command", Offset: 0, Length: cmdLen}},
+	})
+	if len(api.sent) == n {
+		return ""
+	}
+	return api.sent[len(api.sent)-1].Text
+}
+
+func TestTelegramBotIgnoresUnauthorizedChats(t *testing.T) {
+	ft := &fakeTrader{id: "t1"}
+	bot, api := newTestBot(ft)
+
+	if reply := send(bot, api, 7, "/close BTCUSDT"); reply != "" {
+		t.Errorf("unauthorized chat got reply %q", reply)
+	}
+	send(bot, api, 7, "/confirm")
+	if len(ft.closed) != 0 {
+		t.Error("unauthorized chat closed a position")
+	}
+}
+
+func TestTelegramBotCloseRequiresConfirmation(t *testing.T) {
+	ft := &fakeTrader{id: "t1"}
+	bot, api := newTestBot(ft)
+
+	reply := send(bot, api, 42, "/close btcusdt")
+	if !strings.Contains(reply, "BTCUSDT") || !strings.Contains(reply, "/confirm") {
+		t.Errorf("unexpected confirmation prompt %q", reply)
+	}
+	if len(ft.closed) != 0 {
+		t.Fatal("position closed before confirmation")
+	}
+	if reply := send(bot, api, 42, "/confirm"); !strings.Contains(reply, "已平仓") || len(ft.closed) != 1 {
+		t.Errorf("confirm reply %q, closed %v", reply, ft.closed)
+	}
+	if reply := send(bot, api, 42, "/confirm"); reply != "没有待确认的操作" {
+		t.Errorf("second confirm reply %q", reply)
+	}
+
+	send(bot, api, 42, "/close BTCUSDT")
+	if reply := send(bot, api, 42, "/cancel"); !strings.HasPrefix(reply, "已取消") {
+		t.Errorf("cancel reply %q", reply)
+	}
+	send(bot, api, 42, "/confirm")
+	if len(ft.closed) != 1 {
+		t.Error("cancelled close was executed")
+	}
+}
+
+func TestTelegramBotConfirmationExpires(t *testing.T) {
+	orig := confirmTimeout
+	confirmTimeout = -time.Second
+	defer func() { confirmTimeout = orig }()
+
+	ft := &fakeTrader{id: "t1"}
+	bot, api := newTestBot(ft)
+	send(bot, api, 42, "/pause")
+	if reply := send(bot, api, 42, "/confirm"); !strings.Contains(reply, "已过期") || ft.pause.Paused {
+		t.Errorf("expired confirm reply %q, paused=%v", reply, ft.pause.Paused)
+	}
+}
+
+func TestTelegramBotPauseResumeAndStatus(t *testing.T) {
+	ft := &fakeTrader{id: "t1"}
+	bot, api := newTestBot(ft)
+
+	if reply := send(bot, api, 42, "/resume"); !strings.Contains(reply, "未处于暂停状态") {
+		t.Errorf("resume while running reply %q", reply)
+	}
+	send(bot, api, 42, "/pause 行情剧烈")
+	send(bot, api, 42, "/confirm")
+	if !ft.pause.Paused || ft.pause.Reason != "行情剧烈" {
+		t.Fatalf("pause state %+v", ft.pause)
+	}
+	if reply := send(bot, api, 42, "/status"); !strings.Contains(reply, "已暂停（行情剧烈）") || !strings.Contains(reply, "净值: 1000.00") {
+		t.Errorf("status reply %q", reply)
+	}
+	send(bot, api, 42, "/resume")
+	if reply := send(bot, api, 42, "/confirm"); !strings.Contains(reply, "已恢复") || ft.pause.Paused {
+		t.Errorf("resume reply %q", reply)
+	}
+}
+
+func TestTelegramBotTraderSelection(t *testing.T) {
+	a, b := &fakeTrader{id: "a"}, &fakeTrader{id: "b"}
+	bot, api := newTestBot(a, b)
+
+	if reply := send(bot, api, 42, "/positions"); !strings.Contains(reply, "/use") {
+		t.Errorf("ambiguous trader reply %q", reply)
+	}
+	if reply := send(bot, api, 42, "/use c"); !strings.Contains(reply, "不存在") {
+		t.Errorf("unknown trader reply %q", reply)
+	}
+	send(bot, api, 42, "/use b")
+	if reply := send(bot, api, 42, "/positions"); !strings.Contains(reply, "Trader b 持仓") || !strings.Contains(reply, "BTCUSDT LONG") {
+		t.Errorf("positions reply %q", reply)
+	}
+	send(bot, api, 42, "/close BTCUSDT")
+	send(bot, api, 42, "/confirm")
+	if len(a.closed) != 0 || len(b.closed) != 1 {
+		t.Errorf("close applied to wrong trader: a=%v b=%v", a.closed, b.closed)
+	}
+}
+
+func TestParseChatIDs(t *testing.T) {
+	ids, err := ParseChatIDs("123, -100456,")
+	if err != nil || len(ids) != 2 || ids[0] != 123 || ids[1] != -100456 {
+		t.Errorf("ParseChatIDs = %v, %v", ids, err)
+	}
+	if _, err := ParseChatIDs("abc"); err == nil {
+		t.Error("expected error for invalid chat id")
+	}
+}
+
+func TestTelegramBotBroadcast(t *testing.T) {
+	api := &fakeTelegram{}
+	bot := newTelegramBot(api, []int64{42, 7}, func() []Trader { return nil })
+
+	if err := bot.Broadcast("[CRITICAL] t1: 止损单设置失败"); err != nil {
+		t.Fatalf("Broadcast() error = %v", err)
+	}
+	if len(api.sent) != 2 || api.sent[0].ChatID != 7 || api.sent[1].ChatID != 42 || api.sent[0].Text != "[CRITICAL] t1: 止损单设置失败" {
+		t.Errorf("应推送到所有白名单 chat: %+v", api.sent)
+	}
+}
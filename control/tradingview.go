@@ -0,0 +1,167 @@
+package control
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"nofx/decision"
+	"nofx/trader"
+	"strings"
+)
+
+// maxAlertBytes TradingView 告警请求体上限
+const maxAlertBytes = 64 << 10
+
+// SignalTrader 可执行外部交易信号的交易员（*trader.AutoTrader 实现）
+type SignalTrader interface {
+	ExecuteSignal(sig trader.Signal) (decision.Decision, error)
+}
+
+// TradingViewAlert TradingView 告警的 JSON 内容（在告警消息中填写，可使用 {{ticker}}、{{close}} 等占位符）
+type TradingViewAlert struct {
+	Secret        string  `json:"secret"`
+	TraderID      string  `json:"trader_id"`
+	Symbol        string  `json:"symbol"` // 也接受 ticker，如 BINANCE:BTCUSDT.P
+	Ticker        string  `json:"ticker"`
+	Side          string  `json:"side"` // buy/long、sell/short、close_long、close_short、close/flat
+	SizeUSD       float64 `json:"size_usd"`
+	SizePct       float64 `json:"size_pct"`
+	Quantity      float64 `json:"quantity"`
+	Leverage      int     `json:"leverage"`
+	StopLoss      float64 `json:"stop_loss"`
+	TakeProfit    float64 `json:"take_profit"`
+	StopLossPct   float64 `json:"stop_loss_pct"`
+	TakeProfitPct float64 `json:"take_profit_pct"`
+	ClosePct      float64 `json:"close_pct"` // 平仓时只平掉该比例（%）
+	Price         float64 `json:"price"`
+	Comment       string  `json:"comment"`
+	Tag           string  `json:"tag"` // 策略标签（为空时为 tradingview）
+}
+
+// SetTradingViewSecret 设置 TradingView 告警的共享密钥（空=不接收告警）
+func (s *Server) SetTradingViewSecret(secret string) {
+	s.tvSecret = strings.TrimSpace(secret)
+}
+
+// handleTradingView 接收 TradingView 告警：校验共享密钥（告警内容中的 secret 或 ?secret=）后换算为信号交给交易员执行
+func (s *Server) handleTradingView(w http.ResponseWriter, r *http.Request) {
+	if s.tvSecret == "" {
+		writeError(w, http.StatusNotFound, "未启用 TradingView 告警")
+		return
+	}
+	// TradingView 可能以 text/plain 发送 JSON，不检查 Content-Type
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxAlertBytes))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "读取告警失败")
+		return
+	}
+	var alert TradingViewAlert
+	if err := json.Unmarshal(body, &alert); err != nil {
+		writeError(w, http.StatusBadRequest, "告警内容必须是 JSON: "+err.Error())
+		return
+	}
+	secret := alert.Secret
+	if secret == "" {
+		secret = r.URL.Query().Get("secret")
+	}
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(s.tvSecret)) != 1 {
+		writeError(w, http.StatusUnauthorized, "无效的告警密钥")
+		return
+	}
+
+	traderID := alert.TraderID
+	if traderID == "" {
+		traderID = r.URL.Query().Get("trader_id")
+	}
+	t, status, err := s.findTrader(traderID)
+	if err != nil {
+		writeError(w, status, err.Error())
+		return
+	}
+	sig, closeAll, err := alert.signal()
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	log.Printf("📡 收到 TradingView 告警: %s %s → %s", sig.Symbol, alert.Side, t.GetID())
+
+	if closeAll {
+		if err := t.ClosePosition(sig.Symbol); err != nil {
+			writeError(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"trader_id": t.GetID(), "message": sig.Symbol + " 已平仓"})
+		return
+	}
+	st, ok := t.(SignalTrader)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "该交易员不支持外部信号")
+		return
+	}
+	d, err := st.ExecuteSignal(sig)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]interface{}{"trader_id": t.GetID(), "decision": d})
+}
+
+// signal 把告警换算为交易信号；close/flat 且未指定比例时返回 closeAll（平掉该币种多空全部持仓）
+func (a TradingViewAlert) signal() (trader.Signal, bool, error) {
+	symbol := normalizeTicker(a.Symbol)
+	if symbol == "" {
+		symbol = normalizeTicker(a.Ticker)
+	}
+	if symbol == "" {
+		return trader.Signal{}, false, fmt.Errorf("告警缺少 symbol")
+	}
+	reason := "TradingView: " + a.Comment
+	if a.Comment == "" {
+		reason = "TradingView 告警"
+	}
+	tag := strings.TrimSpace(a.Tag)
+	if tag == "" {
+		tag = "tradingview"
+	}
+	sig := trader.Signal{
+		Symbol: symbol, SizeUSD: a.SizeUSD, SizePct: a.SizePct, Quantity: a.Quantity, Leverage: a.Leverage,
+		StopLoss: a.StopLoss, TakeProfit: a.TakeProfit, StopLossPct: a.StopLossPct, TakeProfitPct: a.TakeProfitPct,
+		Price: a.Price, Reason: reason, Tag: tag,
+	}
+
+	switch strings.ToLower(strings.TrimSpace(a.Side)) {
+	case "buy", "long", "open_long":
+		sig.Action = "open_long"
+	case "sell", "short", "open_short":
+		sig.Action = "open_short"
+	case "close_long", "exit_long":
+		sig.Action = "close_long"
+	case "close_short", "exit_short":
+		sig.Action = "close_short"
+	case "close", "flat", "exit":
+		if a.ClosePct <= 0 || a.ClosePct >= 100 {
+			return sig, true, nil
+		}
+		sig.Action = "partial_close"
+	default:
+		return sig, false, fmt.Errorf("不支持的 side: %q（支持 buy、sell、close_long、close_short、close）", a.Side)
+	}
+	if a.ClosePct > 0 && a.ClosePct < 100 && (sig.Action == "close_long" || sig.Action == "close_short" || sig.Action == "partial_close") {
+		sig.Action = "partial_close"
+		sig.ClosePercentage = a.ClosePct
+	}
+	return sig, false, nil
+}
+
+// normalizeTicker TradingView 代码转换为交易所币种：去掉交易所前缀和永续后缀（BINANCE:BTCUSDT.P → BTCUSDT）
+func normalizeTicker(ticker string) string {
+	ticker = strings.ToUpper(strings.TrimSpace(ticker))
+	if i := strings.LastIndex(ticker, ":"); i >= 0 {
+		ticker = ticker[i+1:]
+	}
+	ticker = strings.TrimSuffix(ticker, ".P")
+	return strings.TrimSuffix(ticker, "PERP")
+}
@@ -0,0 +1,113 @@
+package control
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"nofx/decision"
+	"nofx/trader"
+)
+
+// signalTrader 记录外部信号的测试交易员
+type signalTrader struct {
+	fakeTrader
+	signals []trader.Signal
+	err     error
+}
+
+func (s *signalTrader) ExecuteSignal(sig trader.Signal) (decision.Decision, error) {
+	s.signals = append(s.signals, sig)
+	return decision.Decision{Symbol: sig.Symbol, Action: sig.Action}, s.err
+}
+
+func newTradingViewServer(t *testing.T, tr Trader) *Server {
+	t.Helper()
+	s, err := NewServer(":0", "secret", func() []Trader { return []Trader{tr} })
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.SetTradingViewSecret("tv-secret")
+	return s
+}
+
+func TestTradingViewWebhook(t *testing.T) {
+	tr := &signalTrader{fakeTrader: fakeTrader{id: "a"}}
+	s := newTradingViewServer(t, tr)
+
+	body := `{"secret":"tv-secret","ticker":"BINANCE:ETHUSDT.P","side":"buy","size_pct":10,"stop_loss_pct":2,"take_profit_pct":6,"price":3000,"comment":"breakout"}`
+	rec := do(s, "POST", "/webhook/tradingview", body, false)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if len(tr.signals) != 1 {
+		t.Fatalf("expected one signal, got %d", len(tr.signals))
+	}
+	sig := tr.signals[0]
+	if sig.Symbol != "ETHUSDT" || sig.Action != "open_long" || sig.SizePct != 10 || sig.StopLossPct != 2 || sig.Price != 3000 || !strings.Contains(sig.Reason, "breakout") {
+		t.Errorf("unexpected signal: %+v", sig)
+	}
+
+	// 部分平仓
+	rec = do(s, "POST", "/webhook/tradingview?secret=tv-secret", `{"symbol":"ETHUSDT","side":"close_long","close_pct":50}`, false)
+	if rec.Code != http.StatusOK || tr.signals[1].Action != "partial_close" || tr.signals[1].ClosePercentage != 50 {
+		t.Errorf("unexpected partial close: %d %+v", rec.Code, tr.signals)
+	}
+
+	// 全部平仓走 ClosePosition
+	rec = do(s, "POST", "/webhook/tradingview", `{"secret":"tv-secret","symbol":"BTCUSDT","side":"flat"}`, false)
+	if rec.Code != http.StatusOK || len(tr.closed) != 1 || tr.closed[0] != "BTCUSDT" {
+		t.Errorf("flat should close the position: %d %v", rec.Code, tr.closed)
+	}
+
+	// 风控拒绝
+	tr.err = errors.New("交易已人工暂停")
+	if rec := do(s, "POST", "/webhook/tradingview", `{"secret":"tv-secret","symbol":"BTCUSDT","side":"sell","size_usd":100}`, false); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected 422 when the trader rejects the signal, got %d", rec.Code)
+	}
+}
+
+func TestTradingViewWebhookRejects(t *testing.T) {
+	tr := &signalTrader{fakeTrader: fakeTrader{id: "a"}}
+	s := newTradingViewServer(t, tr)
+
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"wrong secret", `{"secret":"nope","symbol":"BTCUSDT","side":"buy"}`, http.StatusUnauthorized},
+		{"missing secret", `{"symbol":"BTCUSDT","side":"buy"}`, http.StatusUnauthorized},
+		{"not json", `buy BTCUSDT`, http.StatusBadRequest},
+		{"unknown side", `{"secret":"tv-secret","symbol":"BTCUSDT","side":"hodl"}`, http.StatusBadRequest},
+		{"missing symbol", `{"secret":"tv-secret","side":"buy"}`, http.StatusBadRequest},
+		{"unknown trader", `{"secret":"tv-secret","trader_id":"b","symbol":"BTCUSDT","side":"buy"}`, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if rec := do(s, "POST", "/webhook/tradingview", tt.body, false); rec.Code != tt.code {
+			t.Errorf("%s: expected %d, got %d: %s", tt.name, tt.code, rec.Code, rec.Body)
+		}
+	}
+	if len(tr.signals) != 0 {
+		t.Errorf("rejected alerts must not reach the trader: %+v", tr.signals)
+	}
+
+	// 未设置密钥时不接收告警
+	disabled := newTestServer(t, &fakeTrader{id: "a"})
+	if rec := do(disabled, "POST", "/webhook/tradingview", `{"symbol":"BTCUSDT","side":"buy"}`, false); rec.Code != http.StatusNotFound {
+		t.Errorf("expected 404 when disabled, got %d", rec.Code)
+	}
+}
+
+func TestNormalizeTicker(t *testing.T) {
+	for in, want := range map[string]string{
+		"BINANCE:BTCUSDT.P": "BTCUSDT",
+		"ethusdt":           "ETHUSDT",
+		"BYBIT:SOLUSDTPERP": "SOLUSDT",
+	} {
+		if got := normalizeTicker(in); got != want {
+			t.Errorf("normalizeTicker(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
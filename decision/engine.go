@@ -131,6 +131,9 @@ type Decision struct {
 	Confidence int     `json:"confidence,omitempty"` // 信心度 (0-100)
 	RiskUSD    float64 `json:"risk_usd,omitempty"`   // 最大美元风险
 	Reasoning  string  `json:"reasoning"`
+
+	// 策略标签（同一账户运行多个策略时按标签归因盈亏；为空时执行方填入决策来源名称）
+	Tag string `json:"tag,omitempty"`
 }
 
 // FullDecision AI的完整决策（包含思维链）
@@ -138,6 +141,7 @@ type FullDecision struct {
 	SystemPrompt string     `json:"system_prompt"` // 系统提示词（发送给AI的系统prompt）
 	UserPrompt   string     `json:"user_prompt"`   // 发送给AI的输入prompt
 	CoTTrace     string     `json:"cot_trace"`     // 思维链分析（AI输出）
+	RawResponse  string     `json:"raw_response"`  // 决策来源的原始输出（用于复现和排查）
 	Decisions    []Decision `json:"decisions"`     // 具体决策列表
 	Timestamp    time.Time  `json:"timestamp"`
 	// AIRequestDurationMs 记录 AI API 调用耗时（毫秒）方便排查延迟问题
@@ -189,6 +193,7 @@ func GetFullDecisionWithCustomPrompt(ctx *Context, mcpClient mcp.AIClient, custo
 		decision.Timestamp = time.Now()
 		decision.SystemPrompt = systemPrompt // 保存系统prompt
 		decision.UserPrompt = userPrompt     // 保存输入prompt
+		decision.RawResponse = aiResponse
 		decision.AIRequestDurationMs = aiCallDuration.Milliseconds()
 	}
 
@@ -942,6 +947,11 @@ func validateDecisions(decisions []Decision, accountEquity float64, btcEthLevera
 	return nil
 }
 
+// Validate 按 AI 决策的规则校验外部来源（信号、webhook）给出的单个决策，杠杆超限时修正为上限
+func (d *Decision) Validate(accountEquity float64, btcEthLeverage, altcoinLeverage int) error {
+	return validateDecision(d, accountEquity, btcEthLeverage, altcoinLeverage)
+}
+
 // findMatchingBracket 查找匹配的右括号
 func findMatchingBracket(s string, start int) int {
 	if start >= len(s) || s[start] != '[' {
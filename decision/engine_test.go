@@ -0,0 +1,57 @@
+package decision
+
+import (
+	"io"
+	"log"
+	"nofx/market"
+	"os"
+	"testing"
+)
+
+const benchAIResponse = `<reasoning>
+BTC 4h 趋势向上，回踩 EMA20 后放量，RSI 未超买，开多。ETH 持仓止盈接近，继续持有。
+</reasoning>
+<decision>
+[
+  {"symbol": "BTCUSDT", "action": "open_long", "leverage": 5, "position_size_usd": 500, "stop_loss": 40000, "take_profit": 60000, "confidence": 80, "risk_usd": 50, "reasoning": "趋势延续"},
+  {"symbol": "ETHUSDT", "action": "hold", "reasoning": "持有"},
+  {"symbol": "SOLUSDT", "action": "wait", "reasoning": "观望"}
+]
+</decision>`
+
+// BenchmarkParseFullDecisionResponse 解析并校验一次 AI 响应（对应 nofx_stage_latency_seconds{stage="decision"} 中的本地部分）
+func BenchmarkParseFullDecisionResponse(b *testing.B) {
+	log.SetOutput(io.Discard)
+	defer log.SetOutput(os.Stderr)
+	if _, err := parseFullDecisionResponse(benchAIResponse, 1000, 10, 5); err != nil {
+		b.Fatalf("parseFullDecisionResponse: %v", err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		parseFullDecisionResponse(benchAIResponse, 1000, 10, 5)
+	}
+}
+
+// BenchmarkBuildUserPrompt 构建一次用户提示词
+func BenchmarkBuildUserPrompt(b *testing.B) {
+	ctx := &Context{
+		CurrentTime:    "2025-01-01 00:00:00",
+		CallCount:      42,
+		RuntimeMinutes: 120,
+		Account:        AccountInfo{TotalEquity: 1000, AvailableBalance: 800, PositionCount: 1},
+		Positions: []PositionInfo{
+			{Symbol: "ETHUSDT", Side: "long", EntryPrice: 3000, MarkPrice: 3100, Quantity: 0.5, Leverage: 5},
+		},
+		MarketDataMap: make(map[string]*market.Data),
+	}
+	for _, symbol := range []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "BNBUSDT", "XRPUSDT"} {
+		ctx.CandidateCoins = append(ctx.CandidateCoins, CandidateCoin{Symbol: symbol})
+		ctx.MarketDataMap[symbol] = &market.Data{Symbol: symbol, CurrentPrice: 100, CurrentEMA20: 99, CurrentRSI7: 55}
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buildUserPrompt(ctx)
+	}
+}
@@ -0,0 +1,253 @@
+package decision
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DecisionProvider 决策来源：LLM 引擎、规则策略、外部 webhook 实现同一接口，可互相替换或组合投票
+type DecisionProvider interface {
+	// Name 来源名称（用于日志和投票说明）
+	Name() string
+	// Decide 根据交易上下文给出本周期的决策
+	Decide(ctx *Context) (*FullDecision, error)
+}
+
+// ProviderFunc 将普通函数适配为 DecisionProvider
+type ProviderFunc struct {
+	ProviderName string
+	Fn           func(ctx *Context) (*FullDecision, error)
+}
+
+// Name 实现 DecisionProvider
+func (p ProviderFunc) Name() string { return p.ProviderName }
+
+// Decide 实现 DecisionProvider
+func (p ProviderFunc) Decide(ctx *Context) (*FullDecision, error) { return p.Fn(ctx) }
+
+// webhookResponse 外部决策服务的响应
+type webhookResponse struct {
+	Decisions []Decision `json:"decisions"`
+	Reasoning string     `json:"reasoning"`
+}
+
+// WebhookProvider 外部决策服务：POST 交易上下文（JSON），响应 {"decisions": [...], "reasoning": "..."}
+// 返回的决策与 AI 决策一样经过动作、杠杆、仓位和止损止盈校验
+type WebhookProvider struct {
+	URL     string
+	Token   string        // 可选：以 Authorization: Bearer 发送
+	Timeout time.Duration // 请求超时（默认 30s）
+	Client  *http.Client  // 可选：测试中替换
+}
+
+// Name 实现 DecisionProvider
+func (p *WebhookProvider) Name() string { return "webhook" }
+
+// Decide 实现 DecisionProvider
+func (p *WebhookProvider) Decide(ctx *Context) (*FullDecision, error) {
+	body, err := json.Marshal(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("序列化交易上下文失败: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, p.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("创建 webhook 请求失败: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.Token)
+	}
+
+	client := p.Client
+	if client == nil {
+		timeout := p.Timeout
+		if timeout <= 0 {
+			timeout = 30 * time.Second
+		}
+		client = &http.Client{Timeout: timeout}
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 webhook 决策失败: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("读取 webhook 响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("webhook 返回 HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var parsed webhookResponse
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("解析 webhook 响应失败: %w", err)
+	}
+	full := &FullDecision{
+		UserPrompt:          string(body),
+		CoTTrace:            parsed.Reasoning,
+		RawResponse:         string(data),
+		Decisions:           parsed.Decisions,
+		Timestamp:           time.Now(),
+		AIRequestDurationMs: time.Since(start).Milliseconds(),
+	}
+	if err := validateDecisions(full.Decisions, ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage); err != nil {
+		return full, fmt.Errorf("webhook 决策验证失败: %w", err)
+	}
+	return full, nil
+}
+
+// Ensemble 多个决策来源投票：同一币种同一动作获得至少 Quorum 票、且没有来源给出相反动作时才执行；
+// 开仓参数取排在最前面的来源，仓位取各赞成票中最小的
+type Ensemble struct {
+	Providers []DecisionProvider
+	Quorum    int // 所需票数（默认过半数）
+}
+
+// Name 实现 DecisionProvider
+func (e *Ensemble) Name() string {
+	names := make([]string, len(e.Providers))
+	for i, p := range e.Providers {
+		names[i] = p.Name()
+	}
+	return "ensemble(" + strings.Join(names, ",") + ")"
+}
+
+// quorum 所需票数
+func (e *Ensemble) quorum() int {
+	if e.Quorum > 0 {
+		return e.Quorum
+	}
+	return len(e.Providers)/2 + 1
+}
+
+// Decide 实现 DecisionProvider：并发调用各来源，失败的来源视为弃权，全部失败时返回错误
+func (e *Ensemble) Decide(ctx *Context) (*FullDecision, error) {
+	if len(e.Providers) == 0 {
+		return nil, fmt.Errorf("未配置决策来源")
+	}
+	results := make([]*FullDecision, len(e.Providers))
+	errs := make([]error, len(e.Providers))
+	var wg sync.WaitGroup
+	start := time.Now()
+	for i, p := range e.Providers {
+		wg.Add(1)
+		go func(i int, p DecisionProvider) {
+			defer wg.Done()
+			results[i], errs[i] = p.Decide(ctx)
+		}(i, p)
+	}
+	wg.Wait()
+
+	var trace, raw strings.Builder
+	var votes []providerVotes
+	for i, p := range e.Providers {
+		if results[i] != nil && results[i].RawResponse != "" {
+			fmt.Fprintf(&raw, "[%s]\n%s\n", p.Name(), results[i].RawResponse)
+		}
+		if errs[i] != nil || results[i] == nil {
+			fmt.Fprintf(&trace, "[%s] 弃权: %v\n", p.Name(), errs[i])
+			continue
+		}
+		fmt.Fprintf(&trace, "[%s] %d 个决策\n", p.Name(), len(results[i].Decisions))
+		if results[i].CoTTrace != "" {
+			fmt.Fprintf(&trace, "%s\n", results[i].CoTTrace)
+		}
+		votes = append(votes, providerVotes{name: p.Name(), decisions: results[i].Decisions})
+	}
+	if len(votes) == 0 {
+		return nil, fmt.Errorf("所有决策来源均失败: %v", errs)
+	}
+
+	decisions, summary := tallyVotes(votes, e.quorum())
+	trace.WriteString(summary)
+	return &FullDecision{
+		CoTTrace:            trace.String(),
+		RawResponse:         raw.String(),
+		Decisions:           decisions,
+		Timestamp:           time.Now(),
+		AIRequestDurationMs: time.Since(start).Milliseconds(),
+	}, nil
+}
+
+// providerVotes 一个来源给出的决策
+type providerVotes struct {
+	name      string
+	decisions []Decision
+}
+
+// voteTally 同一币种同一动作的得票
+type voteTally struct {
+	decision Decision // 排在最前面的来源给出的参数（仓位取各赞成票中最小的）
+	voters   []string
+}
+
+// conflictingActions 与某一动作方向相反的动作（任一来源给出时该动作不算一致）
+var conflictingActions = map[string][]string{
+	"open_long":   {"open_short", "close_long"},
+	"open_short":  {"open_long", "close_short"},
+	"close_long":  {"open_long"},
+	"close_short": {"open_short"},
+}
+
+// tallyVotes 统计票数，返回获得足够票数且没有来源投出反向动作的决策（按首次出现顺序）及投票说明
+func tallyVotes(votes []providerVotes, quorum int) ([]Decision, string) {
+	var order []string
+	tallies := make(map[string]*voteTally)
+	for _, v := range votes {
+		voted := make(map[string]bool)
+		for _, d := range v.decisions {
+			if d.Action == "hold" || d.Action == "wait" {
+				continue
+			}
+			key := d.Symbol + "|" + d.Action
+			if voted[key] {
+				continue // 同一来源对同一动作只计一票
+			}
+			voted[key] = true
+			t, ok := tallies[key]
+			if !ok {
+				t = &voteTally{decision: d}
+				tallies[key] = t
+				order = append(order, key)
+			} else if d.PositionSizeUSD > 0 && d.PositionSizeUSD < t.decision.PositionSizeUSD {
+				t.decision.PositionSizeUSD = d.PositionSizeUSD
+			}
+			t.voters = append(t.voters, v.name)
+		}
+	}
+
+	var summary strings.Builder
+	fmt.Fprintf(&summary, "投票结果（需要 %d 票）:\n", quorum)
+	var accepted []Decision
+	for _, key := range order {
+		t := tallies[key]
+		d := t.decision
+		status := "通过"
+		if len(t.voters) < quorum {
+			status = "票数不足"
+		}
+		for _, opposite := range conflictingActions[d.Action] {
+			if _, ok := tallies[d.Symbol+"|"+opposite]; ok {
+				status = "与 " + opposite + " 冲突，放弃"
+				break
+			}
+		}
+		sort.Strings(t.voters)
+		fmt.Fprintf(&summary, "  %s %s: %d 票 (%s) → %s\n", d.Symbol, d.Action, len(t.voters), strings.Join(t.voters, ","), status)
+		if status != "通过" {
+			continue
+		}
+		d.Reasoning = fmt.Sprintf("[%d/%d 票: %s] %s", len(t.voters), len(votes), strings.Join(t.voters, ","), d.Reasoning)
+		accepted = append(accepted, d)
+	}
+	return accepted, summary.String()
+}
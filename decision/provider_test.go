@@ -0,0 +1,113 @@
+package decision
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWebhookProvider(t *testing.T) {
+	var gotAuth string
+	var gotCtx Context
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotCtx)
+		w.Write([]byte(`{"decisions":[{"symbol":"BTCUSDT","action":"close_long","reasoning":"trend broken"}],"reasoning":"external model"}`))
+	}))
+	defer srv.Close()
+
+	p := &WebhookProvider{URL: srv.URL, Token: "secret"}
+	full, err := p.Decide(&Context{CallCount: 7, Account: AccountInfo{TotalEquity: 1000}})
+	if err != nil {
+		t.Fatalf("webhook 决策失败: %v", err)
+	}
+	if gotAuth != "Bearer secret" || gotCtx.CallCount != 7 {
+		t.Errorf("请求内容错误: auth=%q ctx=%+v", gotAuth, gotCtx)
+	}
+	if len(full.Decisions) != 1 || full.Decisions[0].Action != "close_long" || full.CoTTrace != "external model" {
+		t.Errorf("解析结果错误: %+v", full)
+	}
+}
+
+func TestWebhookProviderRejectsInvalid(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/down" {
+			http.Error(w, "maintenance", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"decisions":[{"symbol":"BTCUSDT","action":"buy"}]}`))
+	}))
+	defer srv.Close()
+
+	ctx := &Context{Account: AccountInfo{TotalEquity: 1000}}
+	if _, err := (&WebhookProvider{URL: srv.URL}).Decide(ctx); err == nil {
+		t.Error("无效动作应返回验证错误")
+	}
+	if _, err := (&WebhookProvider{URL: srv.URL + "/down"}).Decide(ctx); err == nil || !strings.Contains(err.Error(), "503") {
+		t.Errorf("非 200 响应应返回错误: %v", err)
+	}
+}
+
+// fixedProvider 返回固定决策的测试来源
+func fixedProvider(name string, decisions ...Decision) DecisionProvider {
+	return ProviderFunc{ProviderName: name, Fn: func(*Context) (*FullDecision, error) {
+		return &FullDecision{Decisions: decisions}, nil
+	}}
+}
+
+func TestEnsembleVoting(t *testing.T) {
+	e := &Ensemble{Providers: []DecisionProvider{
+		fixedProvider("llm",
+			Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 500, StopLoss: 90, TakeProfit: 130},
+			Decision{Symbol: "ETHUSDT", Action: "open_short", PositionSizeUSD: 300},
+			Decision{Symbol: "SOLUSDT", Action: "close_long"}),
+		fixedProvider("strategy:sma_cross",
+			Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 200, StopLoss: 95, TakeProfit: 120},
+			Decision{Symbol: "ETHUSDT", Action: "open_short", PositionSizeUSD: 300}),
+		fixedProvider("webhook",
+			Decision{Symbol: "ETHUSDT", Action: "open_long"},
+			Decision{Symbol: "SOLUSDT", Action: "hold"}),
+	}}
+
+	full, err := e.Decide(&Context{})
+	if err != nil {
+		t.Fatalf("投票失败: %v", err)
+	}
+	// BTC 两票通过；ETH 开空两票但有来源开多，冲突放弃；SOL 平仓只有一票
+	if len(full.Decisions) != 1 {
+		t.Fatalf("应只有 BTC 开多通过，实际 %+v", full.Decisions)
+	}
+	d := full.Decisions[0]
+	if d.Symbol != "BTCUSDT" || d.PositionSizeUSD != 200 || d.StopLoss != 90 {
+		t.Errorf("通过的决策应使用首个来源的参数和最小仓位: %+v", d)
+	}
+	if !strings.HasPrefix(d.Reasoning, "[2/3 票: llm,strategy:sma_cross]") {
+		t.Errorf("理由应包含投票说明: %q", d.Reasoning)
+	}
+}
+
+func TestEnsembleAbstention(t *testing.T) {
+	failing := ProviderFunc{ProviderName: "webhook", Fn: func(*Context) (*FullDecision, error) {
+		return nil, errors.New("timeout")
+	}}
+	e := &Ensemble{Providers: []DecisionProvider{
+		fixedProvider("llm", Decision{Symbol: "BTCUSDT", Action: "close_long"}),
+		failing,
+	}}
+	// 两个来源默认需要 2 票：失败的来源弃权，单票不足
+	full, err := e.Decide(&Context{})
+	if err != nil || len(full.Decisions) != 0 {
+		t.Errorf("弃权后票数不足应不执行: %+v, %v", full, err)
+	}
+	e.Quorum = 1
+	if full, err = e.Decide(&Context{}); err != nil || len(full.Decisions) != 1 {
+		t.Errorf("Quorum=1 时单票应通过: %+v, %v", full, err)
+	}
+	e.Providers = []DecisionProvider{failing}
+	if _, err := e.Decide(&Context{}); err == nil {
+		t.Error("所有来源失败时应返回错误")
+	}
+}
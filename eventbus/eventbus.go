@@ -0,0 +1,102 @@
+// Package eventbus 进程内发布/订阅事件总线：行情、交易、风控、数据源等组件通过类型化事件解耦
+// 策略引擎、风控、通知、存储各自订阅关心的事件，不再通过函数调用硬连接
+package eventbus
+
+import (
+	"nofx/logging"
+	"sync"
+	"sync/atomic"
+)
+
+var log = logging.Module("eventbus")
+
+// Event 类型化事件（Topic 用于订阅过滤）
+type Event interface {
+	Topic() string
+}
+
+// Bus 事件总线
+// Publish 不阻塞：订阅者通道已满时丢弃该订阅者的事件，慢订阅者不会拖慢行情推送和下单流程
+type Bus struct {
+	mu      sync.RWMutex
+	subs    map[int]*subscription
+	nextID  int
+	dropped atomic.Int64
+}
+
+type subscription struct {
+	ch     chan Event
+	topics map[string]bool // 为空表示订阅全部事件
+}
+
+// Default 全局事件总线（测试中可替换为 New()）
+var Default = New()
+
+// New 创建事件总线
+func New() *Bus {
+	return &Bus{subs: make(map[int]*subscription)}
+}
+
+// Subscribe 订阅事件（topics 为空订阅全部；buffer 为通道缓冲大小），返回取消订阅函数
+func (b *Bus) Subscribe(buffer int, topics ...string) (<-chan Event, func()) {
+	sub := &subscription{ch: make(chan Event, buffer)}
+	if len(topics) > 0 {
+		sub.topics = make(map[string]bool, len(topics))
+		for _, topic := range topics {
+			sub.topics[topic] = true
+		}
+	}
+
+	b.mu.Lock()
+	id := b.nextID
+	b.nextID++
+	b.subs[id] = sub
+	b.mu.Unlock()
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, id)
+			close(sub.ch)
+		})
+	}
+}
+
+// Publish 发布事件（不阻塞）
+func (b *Bus) Publish(ev Event) {
+	if ev == nil {
+		return
+	}
+	topic := ev.Topic()
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subs {
+		if sub.topics != nil && !sub.topics[topic] {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+		default:
+			if n := b.dropped.Add(1); n%100 == 1 {
+				log.Warn("⚠️ 事件订阅者处理过慢，已丢弃事件", "dropped", n, "topic", topic)
+			}
+		}
+	}
+}
+
+// Dropped 因订阅者处理过慢而丢弃的事件总数
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Publish 发布事件到全局总线
+func Publish(ev Event) {
+	Default.Publish(ev)
+}
+
+// Subscribe 订阅全局总线
+func Subscribe(buffer int, topics ...string) (<-chan Event, func()) {
+	return Default.Subscribe(buffer, topics...)
+}
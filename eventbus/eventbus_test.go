@@ -0,0 +1,59 @@
+package eventbus
+
+import "testing"
+
+func TestBusTopicFilter(t *testing.T) {
+	bus := New()
+	all, unsubAll := bus.Subscribe(4)
+	defer unsubAll()
+	risk, unsubRisk := bus.Subscribe(4, TopicRiskTripped)
+	defer unsubRisk()
+
+	bus.Publish(CandleClosed{Symbol: "BTCUSDT", Interval: "1m"})
+	bus.Publish(RiskTripped{Reason: "drawdown"})
+
+	if ev := <-all; ev.Topic() != TopicCandleClosed {
+		t.Errorf("first event topic = %s", ev.Topic())
+	}
+	if ev := <-all; ev.Topic() != TopicRiskTripped {
+		t.Errorf("second event topic = %s", ev.Topic())
+	}
+	ev := <-risk
+	if r, ok := ev.(RiskTripped); !ok || r.Reason != "drawdown" {
+		t.Errorf("risk subscriber got %#v", ev)
+	}
+	select {
+	case ev := <-risk:
+		t.Errorf("unexpected event for filtered subscriber: %#v", ev)
+	default:
+	}
+}
+
+func TestBusDropsForSlowSubscriber(t *testing.T) {
+	bus := New()
+	slow, unsubSlow := bus.Subscribe(1)
+	defer unsubSlow()
+	fast, unsubFast := bus.Subscribe(8)
+	defer unsubFast()
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(OrderFilled{OrderID: int64(i)})
+	}
+	if got := bus.Dropped(); got != 2 {
+		t.Errorf("Dropped() = %d, want 2", got)
+	}
+	if len(slow) != 1 || len(fast) != 3 {
+		t.Errorf("slow=%d fast=%d, want 1 and 3", len(slow), len(fast))
+	}
+}
+
+func TestBusUnsubscribe(t *testing.T) {
+	bus := New()
+	ch, unsubscribe := bus.Subscribe(1)
+	unsubscribe()
+	unsubscribe() // 重复调用安全
+	bus.Publish(DataSourceSwitched{From: "binance", To: "okx"})
+	if _, ok := <-ch; ok {
+		t.Error("取消订阅后通道应关闭")
+	}
+}
@@ -0,0 +1,132 @@
+package eventbus
+
+import "time"
+
+// 事件主题
+const (
+	TopicCandleClosed       = "candle_closed"
+	TopicOrderFilled        = "order_filled"
+	TopicPositionOpened     = "position_opened"
+	TopicRiskTripped        = "risk_tripped"
+	TopicDataSourceSwitched = "datasource_switched"
+	TopicSpreadOpportunity  = "spread_opportunity"
+	TopicLeverageChanged    = "leverage_changed"
+	TopicRegimeChanged      = "regime_changed"
+)
+
+// CandleClosed K线收盘（WebSocket 推送收到下一根K线时发布上一根）
+type CandleClosed struct {
+	Source    string // 数据源名称
+	Symbol    string
+	Interval  string
+	OpenTime  int64 // 毫秒
+	CloseTime int64 // 毫秒
+	Open      float64
+	High      float64
+	Low       float64
+	Close     float64
+	Volume    float64
+}
+
+// Topic 实现 Event
+func (CandleClosed) Topic() string { return TopicCandleClosed }
+
+// OrderFilled 订单成交（开仓或平仓）
+type OrderFilled struct {
+	TraderID string
+	Exchange string
+	Symbol   string
+	Side     string // long / short
+	Action   string // open / close
+	OrderID  int64
+	Quantity float64 // 成交数量
+	Price    float64 // 成交均价
+	Fee      float64
+	Time     time.Time
+}
+
+// Topic 实现 Event
+func (OrderFilled) Topic() string { return TopicOrderFilled }
+
+// PositionOpened 开仓成交
+type PositionOpened struct {
+	TraderID   string
+	Exchange   string
+	Symbol     string
+	Side       string // long / short
+	Quantity   float64
+	EntryPrice float64
+	Leverage   int
+	Time       time.Time
+}
+
+// Topic 实现 Event
+func (PositionOpened) Topic() string { return TopicPositionOpened }
+
+// LeverageChanged 交易所杠杆已调整
+type LeverageChanged struct {
+	TraderID string
+	Exchange string
+	Symbol   string
+	From     int // 调整前杠杆（未知时为 0）
+	To       int
+	Time     time.Time
+}
+
+// Topic 实现 Event
+func (LeverageChanged) Topic() string { return TopicLeverageChanged }
+
+// RiskTripped 风控触发（账户级暂停或决策被拒绝）
+type RiskTripped struct {
+	Source   string // 触发方：交易员ID或策略名
+	Symbol   string // 账户级风控为空
+	Reason   string
+	Blocking bool // true=暂停交易，false=仅拒绝本次决策
+	Time     time.Time
+}
+
+// Topic 实现 Event
+func (RiskTripped) Topic() string { return TopicRiskTripped }
+
+// DataSourceSwitched 故障转移数据源切换
+type DataSourceSwitched struct {
+	From   string
+	To     string
+	Reason string
+	Time   time.Time
+}
+
+// Topic 实现 Event
+func (DataSourceSwitched) Topic() string { return TopicDataSourceSwitched }
+
+// SpreadOpportunity 同一币种在两个交易场所的价差超过手续费和滑点（跨交易所价差套利机会）
+type SpreadOpportunity struct {
+	Symbol    string
+	BuyVenue  string  // 价格较低的场所（买入/做多）
+	SellVenue string  // 价格较高的场所（卖出/做空）
+	BuyPrice  float64 // 买入场所卖一价（无盘口时为最新价）
+	SellPrice float64 // 卖出场所买一价（无盘口时为最新价）
+	GrossBps  float64 // 价差（基点）
+	CostBps   float64 // 两条腿开平仓的手续费和滑点（基点）
+	NetBps    float64 // 扣除成本后的收益（基点）
+	Time      time.Time
+}
+
+// Topic 实现 Event
+func (SpreadOpportunity) Topic() string { return TopicSpreadOpportunity }
+
+// RegimeChanged 币种在某个K线周期上的市场状态发生变化（首次判定时 From 为空）
+type RegimeChanged struct {
+	Symbol         string
+	Interval       string
+	From           string // trending / ranging / high_vol
+	To             string
+	ADX            float64
+	ATRPct         float64 // ATR 占收盘价的百分比
+	RealizedVolPct float64 // 年化已实现波动率（百分比）
+	VolRatio       float64 // 近期波动率 / 基准波动率
+	Time           time.Time
+}
+
+// Topic 实现 Event
+func (RegimeChanged) Topic() string { return TopicRegimeChanged }
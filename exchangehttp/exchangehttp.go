@@ -0,0 +1,278 @@
+// Package exchangehttp 交易所 REST 客户端的公共部分：组装请求、签名、限流、重试和响应外层结构解析
+// 新交易所只需提供签名函数和响应解析函数
+package exchangehttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"nofx/httprecord"
+	"nofx/logging"
+	"nofx/netconfig"
+	"nofx/ratelimit"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var log = logging.Module("exchangehttp")
+
+// NewHTTPClient 交易所 HTTP 客户端：代理/出口配置（netconfig）+ 请求录制（httprecord）+ 按接口组限流（group 为 nil 时不限流）
+func NewHTTPClient(name string, group ratelimit.GroupFunc, timeout time.Duration) *http.Client {
+	transport := httprecord.Wrap(netconfig.Transport(name))
+	if group != nil {
+		transport = ratelimit.NewTransport(transport, group)
+	}
+	return &http.Client{Timeout: timeout, Transport: transport}
+}
+
+// Request 一次请求；签名函数在请求副本上修改 Query/Form/Header，每次重发前重新签名
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Form   url.Values // 非空时作为 application/x-www-form-urlencoded 请求体
+	Body   []byte     // JSON 请求体（Form 为空时使用）
+	Header http.Header
+
+	// Idempotent 失败后重发是否安全（只有幂等请求才按 Client.Retry 重试临时错误）
+	Idempotent bool
+}
+
+// SetJSON 把 v 序列化为 JSON 请求体
+func (r *Request) SetJSON(v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %w", err)
+	}
+	r.Body = body
+	return nil
+}
+
+// RequestPath 路径加查询串（多数交易所的签名原文包含此部分）
+func (r *Request) RequestPath() string {
+	if len(r.Query) == 0 {
+		return r.Path
+	}
+	return r.Path + "?" + r.Query.Encode()
+}
+
+// clone 签名用的副本（不修改调用方的参数）
+func (r *Request) clone() *Request {
+	c := *r
+	c.Query = cloneValues(r.Query)
+	c.Form = cloneValues(r.Form)
+	c.Header = r.Header.Clone()
+	if c.Header == nil {
+		c.Header = http.Header{}
+	}
+	return &c
+}
+
+func cloneValues(v url.Values) url.Values {
+	if v == nil {
+		return nil
+	}
+	out := make(url.Values, len(v))
+	for k, vs := range v {
+		out[k] = append([]string(nil), vs...)
+	}
+	return out
+}
+
+// Signer 为请求签名（时间戳、nonce、签名参数或请求头）
+type Signer func(r *Request) error
+
+// Decoder 检查响应并把数据解析到 out（非 2xx、业务错误码等返回错误）
+type Decoder func(status int, body []byte, out interface{}) error
+
+// Retry 临时错误的重试策略
+type Retry struct {
+	MaxAttempts int                           // 最大尝试次数（含首次请求，<=1 表示不重试）
+	Backoff     func(retry int) time.Duration // 第 retry 次重试前的等待时间（nil 时立即重试）
+	Retryable   func(err error) bool          // 是否为可重试的临时错误（nil 时不重试）
+}
+
+// Client 交易所 REST 客户端（零值字段表示不签名、不重试、按 JSON 解析）
+type Client struct {
+	HTTP    *http.Client
+	BaseURL string
+	Header  http.Header // 每个请求都携带的请求头（如 User-Agent、x-simulated-trading）
+	Sign    Signer
+	Decode  Decoder // nil 时使用 DecodeJSON
+	Retry   Retry
+
+	// Resend 请求确定未被交易所执行时（如时间戳超出接收窗口）返回 true，重新签名后立即重发一次，不受 Idempotent 限制
+	Resend func(ctx context.Context, err error) bool
+}
+
+// StatusError 非 2xx 响应
+type StatusError struct {
+	Status int
+	Body   []byte
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Status, e.Body)
+}
+
+// RetryError 重发后仍然失败（Attempts 为已发送的次数）
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("请求失败（已重试%d次）: %v", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error { return e.Err }
+
+// Temporary 是否为可重试的临时错误：网络超时、连接被重置/拒绝、响应提前断开，以及 429 和 5xx 响应
+// （供只读查询的 Retry.Retryable 使用）
+func Temporary(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Status == http.StatusTooManyRequests || statusErr.Status >= 500
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// DecodeJSON 默认响应解析：非 2xx 返回 *StatusError，否则按 JSON 解析到 out（out 为 *[]byte 时返回原始响应体）
+func DecodeJSON(status int, body []byte, out interface{}) error {
+	if status < 200 || status >= 300 {
+		return &StatusError{Status: status, Body: body}
+	}
+	return Unmarshal(body, out)
+}
+
+// Unmarshal 把 data 解析到 out（out 为 nil 时忽略，为 *[]byte 时直接返回原始数据）
+func Unmarshal(data []byte, out interface{}) error {
+	switch v := out.(type) {
+	case nil:
+		return nil
+	case *[]byte:
+		*v = data
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
+
+// Do 发送请求并把响应解析到 out
+// 幂等请求遇到临时错误时按 Retry 退避重试；重发过的请求失败时返回 *RetryError
+func (c *Client) Do(ctx context.Context, req *Request, out interface{}) error {
+	maxAttempts := max(c.Retry.MaxAttempts, 1)
+	resent := false
+	for attempt := 1; ; attempt++ {
+		err := c.send(ctx, req, out)
+		if err == nil {
+			return nil
+		}
+		if !resent && c.Resend != nil && c.Resend(ctx, err) {
+			resent = true
+			attempt--
+			continue
+		}
+		if attempt > 1 {
+			err = &RetryError{Attempts: attempt, Err: err}
+		}
+
+		// 调用方已取消或超时，不再重试
+		if ctx.Err() != nil {
+			return err
+		}
+		if !req.Idempotent || c.Retry.Retryable == nil || !c.Retry.Retryable(err) || attempt >= maxAttempts {
+			return err
+		}
+
+		var wait time.Duration
+		if c.Retry.Backoff != nil {
+			wait = c.Retry.Backoff(attempt)
+		}
+		log.Warn("⚠️ 请求失败，稍后重试", "method", req.Method, "path", req.Path,
+			"attempt", attempt, "max_attempts", maxAttempts, "error", err, "wait", wait.String())
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+// send 签名并发送一次请求
+func (c *Client) send(ctx context.Context, req *Request, out interface{}) error {
+	r := req.clone()
+	for k, vs := range c.Header {
+		if _, ok := r.Header[k]; !ok {
+			r.Header[k] = vs
+		}
+	}
+	if c.Sign != nil {
+		if err := c.Sign(r); err != nil {
+			return err
+		}
+	}
+
+	var body io.Reader
+	switch {
+	case len(r.Form) > 0:
+		body = strings.NewReader(r.Form.Encode())
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	case r.Body != nil:
+		body = bytes.NewReader(r.Body)
+		if r.Header.Get("Content-Type") == "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, r.Method, c.BaseURL+r.RequestPath(), body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header = r.Header
+
+	client := c.HTTP
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	decode := c.Decode
+	if decode == nil {
+		decode = DecodeJSON
+	}
+	return decode(resp.StatusCode, respBody, out)
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
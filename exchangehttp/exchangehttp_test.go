@@ -0,0 +1,154 @@
+package exchangehttp
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+var errTransient = errors.New("temporary")
+
+func TestDoSignsEachAttemptAndRetriesIdempotent(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := calls.Add(1)
+		if got := r.URL.Query().Get("sig"); got != strconv.Itoa(int(n)) {
+			t.Errorf("第 %d 次请求的签名 = %q", n, got)
+		}
+		if n < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	signs := 0
+	c := &Client{
+		HTTP:    server.Client(),
+		BaseURL: server.URL,
+		Sign: func(r *Request) error {
+			signs++
+			r.Query.Set("sig", strconv.Itoa(signs))
+			return nil
+		},
+		Retry: Retry{MaxAttempts: 3, Retryable: func(err error) bool {
+			var se *StatusError
+			return errors.As(err, &se) && se.Status == http.StatusServiceUnavailable
+		}},
+	}
+	req := &Request{Method: http.MethodGet, Path: "/v1/x", Query: url.Values{"a": {"1"}}, Idempotent: true}
+	var out struct{ OK bool }
+	if err := c.Do(context.Background(), req, &out); err != nil || !out.OK {
+		t.Fatalf("Do 失败: %v out=%+v", err, out)
+	}
+	if calls.Load() != 3 || req.Query.Get("sig") != "" {
+		t.Errorf("calls=%d，签名不应修改调用方的参数: %v", calls.Load(), req.Query)
+	}
+
+	// 非幂等请求不重试
+	calls.Store(0)
+	signs = 0
+	req.Idempotent = false
+	err := c.Do(context.Background(), req, nil)
+	var se *StatusError
+	if !errors.As(err, &se) || calls.Load() != 1 {
+		t.Errorf("非幂等请求不应重试: calls=%d err=%v", calls.Load(), err)
+	}
+}
+
+func TestDoReturnsRetryErrorWhenExhausted(t *testing.T) {
+	var calls atomic.Int32
+	c := &Client{
+		HTTP: &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			calls.Add(1)
+			return nil, errTransient
+		})},
+		BaseURL: "http://example.invalid",
+		Retry:   Retry{MaxAttempts: 2, Retryable: func(err error) bool { return errors.Is(err, errTransient) }},
+	}
+	err := c.Do(context.Background(), &Request{Method: http.MethodGet, Path: "/", Idempotent: true}, nil)
+	var re *RetryError
+	if !errors.As(err, &re) || re.Attempts != 2 || !errors.Is(err, errTransient) || calls.Load() != 2 {
+		t.Errorf("err=%v calls=%d", err, calls.Load())
+	}
+}
+
+func TestDoResendOnce(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("Content-Type") != "application/json" || string(body) != `{"a":1}` {
+			t.Errorf("JSON 请求体错误: %s %s", r.Header.Get("Content-Type"), body)
+		}
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	resends := 0
+	c := &Client{HTTP: server.Client(), BaseURL: server.URL, Resend: func(ctx context.Context, err error) bool {
+		resends++
+		return true
+	}}
+	req := &Request{Method: http.MethodPost, Path: "/"}
+	if err := req.SetJSON(map[string]int{"a": 1}); err != nil {
+		t.Fatal(err)
+	}
+	err := c.Do(context.Background(), req, nil)
+	var re *RetryError
+	if err == nil || errors.As(err, &re) {
+		t.Errorf("重发一次后仍失败应返回原始错误: %v", err)
+	}
+	if calls.Load() != 2 || resends != 1 {
+		t.Errorf("应只重发一次: calls=%d resends=%d", calls.Load(), resends)
+	}
+}
+
+func TestDoFormBodyAndRawOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Header.Get("User-Agent") != "nofx" || r.PostForm.Get("symbol") != "BTCUSDT" {
+			t.Errorf("header=%v form=%v", r.Header, r.PostForm)
+		}
+		w.Write([]byte(`raw`))
+	}))
+	defer server.Close()
+
+	c := &Client{HTTP: server.Client(), BaseURL: server.URL, Header: http.Header{"User-Agent": {"nofx"}}}
+	var body []byte
+	err := c.Do(context.Background(), &Request{Method: http.MethodPost, Path: "/", Form: url.Values{"symbol": {"BTCUSDT"}}}, &body)
+	if err != nil || string(body) != "raw" {
+		t.Errorf("body=%q err=%v", body, err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+func TestTemporary(t *testing.T) {
+	cases := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{Status: http.StatusTooManyRequests}, true},
+		{&StatusError{Status: http.StatusBadGateway}, true},
+		{&StatusError{Status: http.StatusBadRequest}, false},
+		{&RetryError{Attempts: 2, Err: &StatusError{Status: http.StatusServiceUnavailable}}, true},
+		{io.ErrUnexpectedEOF, true},
+		{context.Canceled, false},
+		{errors.New("invalid symbol"), false},
+	}
+	for _, tc := range cases {
+		if got := Temporary(tc.err); got != tc.want {
+			t.Errorf("Temporary(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
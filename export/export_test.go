@@ -0,0 +1,233 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/market"
+)
+
+func sampleTable() *Table {
+	t0 := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	table := &Table{Columns: []Column{
+		{"symbol", ColumnString}, {"time", ColumnTime}, {"price", ColumnFloat}, {"count", ColumnInt}, {"stop", ColumnBool},
+	}}
+	for i := 0; i < 10; i++ {
+		table.Rows = append(table.Rows, []interface{}{
+			fmt.Sprintf("SYM%d", i), t0.Add(time.Duration(i) * time.Hour), 100.5 + float64(i), int64(i * 3), i%3 == 0,
+		})
+	}
+	return table
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	if err := Write(&buf, sampleTable(), FormatCSV); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 11 {
+		t.Fatalf("expected header + 10 rows, got %d lines", len(lines))
+	}
+	if lines[0] != "symbol,time,price,count,stop" || lines[1] != "SYM0,2024-03-01T12:00:00Z,100.5,0,true" {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+
+	bad := &Table{Columns: []Column{{"n", ColumnInt}}, Rows: [][]interface{}{{"x"}}}
+	if err := WriteCSV(&buf, bad); err == nil {
+		t.Error("expected type mismatch error")
+	}
+	if err := Write(&buf, sampleTable(), "xlsx"); err == nil {
+		t.Error("expected unsupported format error")
+	}
+}
+
+func TestWriteParquet(t *testing.T) {
+	table := sampleTable()
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, table); err != nil {
+		t.Fatalf("WriteParquet: %v", err)
+	}
+	data := buf.Bytes()
+	if string(data[:4]) != parquetMagic || string(data[len(data)-4:]) != parquetMagic {
+		t.Fatal("missing PAR1 magic")
+	}
+	footerLen := int(binary.LittleEndian.Uint32(data[len(data)-8:]))
+	meta := (&thriftReader{buf: data[len(data)-8-footerLen : len(data)-8]}).readStruct()
+
+	if meta[3].(int64) != 10 {
+		t.Errorf("num_rows = %v", meta[3])
+	}
+	schema := meta[2].([]interface{})
+	if len(schema) != 6 || string(schema[0].(map[int16]interface{})[4].([]byte)) != "schema" {
+		t.Fatalf("unexpected schema: %v", schema)
+	}
+	timeCol := schema[2].(map[int16]interface{})
+	if string(timeCol[4].([]byte)) != "time" || timeCol[1].(int32) != parquetInt64 || timeCol[6].(int32) != convertedTimestampMillis {
+		t.Errorf("unexpected time column schema: %v", timeCol)
+	}
+
+	rowGroup := meta[4].([]interface{})[0].(map[int16]interface{})
+	columns := rowGroup[1].([]interface{})
+	if len(columns) != 5 {
+		t.Fatalf("expected 5 column chunks, got %d", len(columns))
+	}
+	readPage := func(i int) []byte {
+		cm := columns[i].(map[int16]interface{})[3].(map[int16]interface{})
+		offset := cm[9].(int64)
+		r := &thriftReader{buf: data[offset:]}
+		header := r.readStruct()
+		size := int(header[3].(int32))
+		if dph := header[5].(map[int16]interface{}); dph[1].(int32) != 10 {
+			t.Fatalf("column %d num_values = %v", i, dph[1])
+		}
+		return r.buf[r.pos : r.pos+size]
+	}
+
+	// 字符串列：4 字节长度 + 内容
+	strs := readPage(0)
+	if n := binary.LittleEndian.Uint32(strs); n != 4 || string(strs[4:8]) != "SYM0" {
+		t.Errorf("unexpected string page prefix: %q", strs[:8])
+	}
+	times := readPage(1)
+	if ms := int64(binary.LittleEndian.Uint64(times[8:])); ms != table.Rows[1][1].(time.Time).UnixMilli() {
+		t.Errorf("timestamp[1] = %d", ms)
+	}
+	prices := readPage(2)
+	if p := math.Float64frombits(binary.LittleEndian.Uint64(prices[16:])); p != 102.5 {
+		t.Errorf("price[2] = %v", p)
+	}
+	// 布尔列：第 0、3、6、9 行为 true
+	bools := readPage(4)
+	if len(bools) != 2 || bools[0] != 0b01001001 || bools[1] != 0b10 {
+		t.Errorf("unexpected bit-packed booleans: %08b", bools)
+	}
+}
+
+func TestKlinesTable(t *testing.T) {
+	table := KlinesTable("BTCUSDT", "1h", []market.Kline{{OpenTime: 0, CloseTime: 3599999, Open: 1, High: 2, Low: 0.5, Close: 1.5, Trades: 7}})
+	var buf bytes.Buffer
+	if err := WriteParquet(&buf, table); err != nil {
+		t.Fatalf("WriteParquet: %v", err)
+	}
+	if err := WriteCSV(&buf, table); err != nil {
+		t.Fatalf("WriteCSV: %v", err)
+	}
+}
+
+// thriftReader 测试用的 Thrift Compact 解码器（字段值按 ID 存入 map）
+type thriftReader struct {
+	buf []byte
+	pos int
+}
+
+func (r *thriftReader) byte() byte {
+	b := r.buf[r.pos]
+	r.pos++
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	r.pos += n
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+func (r *thriftReader) value(typ byte) interface{} {
+	switch typ {
+	case 1:
+		return true
+	case 2:
+		return false
+	case thriftI32:
+		return int32(r.zigzag())
+	case thriftI64:
+		return r.zigzag()
+	case thriftBinary:
+		n := int(r.uvarint())
+		b := r.buf[r.pos : r.pos+n]
+		r.pos += n
+		return b
+	case thriftList:
+		header := r.byte()
+		size, elem := int(header>>4), header&0x0F
+		if size == 15 {
+			size = int(r.uvarint())
+		}
+		list := make([]interface{}, size)
+		for i := range list {
+			list[i] = r.value(elem)
+		}
+		return list
+	case thriftStruct:
+		return r.readStruct()
+	}
+	panic(fmt.Sprintf("unsupported thrift type %d", typ))
+}
+
+func (r *thriftReader) readStruct() map[int16]interface{} {
+	fields := make(map[int16]interface{})
+	var last int16
+	for {
+		header := r.byte()
+		if header == 0 {
+			return fields
+		}
+		typ := header & 0x0F
+		id := last + int16(header>>4)
+		if header>>4 == 0 {
+			id = int16(r.zigzag())
+		}
+		last = id
+		fields[id] = r.value(typ)
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	table := sampleTable()
+	for i := 0; i < 100; i++ { // 超过一页
+		table.Rows = append(table.Rows, table.Rows[0])
+	}
+	table.Rows[0][0] = "BTC(永续)"
+
+	var buf bytes.Buffer
+	if err := WritePDF(&buf, "Statement", PDFSection{Title: "Trades", Lines: []string{"note"}, Table: table}); err != nil {
+		t.Fatalf("WritePDF: %v", err)
+	}
+	pdf := buf.String()
+	if !strings.HasPrefix(pdf, "%PDF-1.4\n") || !strings.HasSuffix(pdf, "%%EOF\n") {
+		t.Fatalf("not a PDF:\n%.200s", pdf)
+	}
+	if !strings.Contains(pdf, "/Count 3") {
+		t.Errorf("expected 3 pages")
+	}
+	if !strings.Contains(pdf, `(BTC\(??\)  2024-03-01T12:00:00Z`) {
+		t.Errorf("cells should be escaped and non-ASCII replaced")
+	}
+
+	// 交叉引用表中的偏移量指向对应对象
+	xref := strings.LastIndex(pdf, "\nxref\n") + 1
+	var start int
+	fmt.Sscanf(pdf[strings.LastIndex(pdf, "startxref\n")+len("startxref\n"):], "%d", &start)
+	if start != xref {
+		t.Errorf("startxref = %d, want %d", start, xref)
+	}
+	entries := strings.Split(pdf[xref:], "\n")[3:]
+	for i, entry := range entries[:5] {
+		var off int
+		fmt.Sscanf(entry, "%d", &off)
+		if !strings.HasPrefix(pdf[off:], fmt.Sprintf("%d 0 obj", i+1)) {
+			t.Errorf("xref entry %d points to %q", i+1, pdf[off:off+10])
+		}
+	}
+}
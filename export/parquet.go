@@ -0,0 +1,324 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// 最小化的 Parquet 写入实现：单个行组、每列一个数据页、PLAIN 编码、不压缩、全部列为 REQUIRED
+// （pandas / pyarrow / DuckDB 均可直接读取；导出数据量不大，不需要字典编码和压缩）
+
+// parquetMagic 文件头尾的魔数
+const parquetMagic = "PAR1"
+
+// Parquet 物理类型
+const (
+	parquetBoolean   = 0
+	parquetInt64     = 2
+	parquetDouble    = 5
+	parquetByteArray = 6
+)
+
+// Parquet 转换类型（ConvertedType）
+const (
+	convertedUTF8            = 0
+	convertedTimestampMillis = 9
+)
+
+// Parquet 编码与压缩
+const (
+	encodingPlain      = 0
+	encodingRLE        = 3
+	codecUncompressed  = 0
+	pageTypeData       = 0
+	repetitionRequired = 0
+)
+
+// parquetCreatedBy 写入文件元数据的生成方
+const parquetCreatedBy = "nofx export"
+
+// WriteParquet 以 Parquet 格式写出表格
+func WriteParquet(w io.Writer, table *Table) error {
+	var file bytes.Buffer
+	file.WriteString(parquetMagic)
+
+	chunks := make([]columnChunk, len(table.Columns))
+	for i, col := range table.Columns {
+		values, err := encodePlain(col, i, table.Rows)
+		if err != nil {
+			return err
+		}
+
+		var header thriftWriter
+		header.i32(1, pageTypeData)
+		header.i32(2, int32(len(values)))
+		header.i32(3, int32(len(values)))
+		header.structBegin(5) // DataPageHeader
+		header.i32(1, int32(len(table.Rows)))
+		header.i32(2, encodingPlain)
+		header.i32(3, encodingRLE)
+		header.i32(4, encodingRLE)
+		header.structEnd()
+		header.stop()
+
+		offset := int64(file.Len())
+		file.Write(header.buf.Bytes())
+		file.Write(values)
+		chunks[i] = columnChunk{
+			column: col,
+			offset: offset,
+			size:   int64(header.buf.Len() + len(values)),
+		}
+	}
+
+	footer := fileMetaData(table, chunks)
+	file.Write(footer)
+	binary.Write(&file, binary.LittleEndian, uint32(len(footer)))
+	file.WriteString(parquetMagic)
+
+	_, err := w.Write(file.Bytes())
+	return err
+}
+
+// columnChunk 已写入的列数据位置
+type columnChunk struct {
+	column Column
+	offset int64
+	size   int64
+}
+
+// physicalType 列类型对应的 Parquet 物理类型和转换类型（-1 表示无）
+func physicalType(t ColumnType) (int32, int32) {
+	switch t {
+	case ColumnFloat:
+		return parquetDouble, -1
+	case ColumnInt:
+		return parquetInt64, -1
+	case ColumnTime:
+		return parquetInt64, convertedTimestampMillis
+	case ColumnBool:
+		return parquetBoolean, -1
+	default:
+		return parquetByteArray, convertedUTF8
+	}
+}
+
+// encodePlain 按 PLAIN 编码一列的全部值
+func encodePlain(col Column, index int, rows [][]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	var bits byte
+	for r, row := range rows {
+		if index >= len(row) {
+			return nil, fmt.Errorf("第 %d 行缺少列 %s", r+1, col.Name)
+		}
+		v := row[index]
+		mismatch := fmt.Errorf("第 %d 行: 列 %s 的值类型不匹配: %T", r+1, col.Name, v)
+		switch col.Type {
+		case ColumnString:
+			s, ok := v.(string)
+			if !ok {
+				return nil, mismatch
+			}
+			binary.Write(&buf, binary.LittleEndian, uint32(len(s)))
+			buf.WriteString(s)
+		case ColumnFloat:
+			f, ok := v.(float64)
+			if !ok {
+				return nil, mismatch
+			}
+			binary.Write(&buf, binary.LittleEndian, math.Float64bits(f))
+		case ColumnInt:
+			n, ok := v.(int64)
+			if !ok {
+				return nil, mismatch
+			}
+			binary.Write(&buf, binary.LittleEndian, n)
+		case ColumnTime:
+			t, ok := v.(time.Time)
+			if !ok {
+				return nil, mismatch
+			}
+			binary.Write(&buf, binary.LittleEndian, t.UnixMilli())
+		case ColumnBool:
+			b, ok := v.(bool)
+			if !ok {
+				return nil, mismatch
+			}
+			// 布尔值按位打包（低位在前）
+			if b {
+				bits |= 1 << (r % 8)
+			}
+			if r%8 == 7 {
+				buf.WriteByte(bits)
+				bits = 0
+			}
+		}
+	}
+	if col.Type == ColumnBool && len(rows)%8 != 0 {
+		buf.WriteByte(bits)
+	}
+	return buf.Bytes(), nil
+}
+
+// fileMetaData 编码文件元数据（schema、行组和列位置）
+func fileMetaData(table *Table, chunks []columnChunk) []byte {
+	var w thriftWriter
+	w.i32(1, 1) // version
+
+	w.listBegin(2, thriftStruct, len(table.Columns)+1) // schema
+	w.elemBegin()
+	w.binary(4, []byte("schema"))
+	w.i32(5, int32(len(table.Columns)))
+	w.elemEnd()
+	for _, c := range table.Columns {
+		typ, converted := physicalType(c.Type)
+		w.elemBegin()
+		w.i32(1, typ)
+		w.i32(3, repetitionRequired)
+		w.binary(4, []byte(c.Name))
+		if converted >= 0 {
+			w.i32(6, converted)
+		}
+		w.elemEnd()
+	}
+
+	numRows := int64(len(table.Rows))
+	var totalSize int64
+	for _, c := range chunks {
+		totalSize += c.size
+	}
+	w.i64(3, numRows)
+
+	w.listBegin(4, thriftStruct, 1) // row_groups
+	w.elemBegin()
+	w.listBegin(1, thriftStruct, len(chunks)) // columns
+	for _, c := range chunks {
+		typ, _ := physicalType(c.column.Type)
+		w.elemBegin()
+		w.i64(2, c.offset) // file_offset
+		w.structBegin(3)   // ColumnMetaData
+		w.i32(1, typ)
+		w.listBegin(2, thriftI32, 2)
+		w.varint(zigzag(encodingPlain))
+		w.varint(zigzag(encodingRLE))
+		w.listBegin(3, thriftBinary, 1)
+		w.rawBinary([]byte(c.column.Name))
+		w.i32(4, codecUncompressed)
+		w.i64(5, numRows)
+		w.i64(6, c.size)
+		w.i64(7, c.size)
+		w.i64(9, c.offset) // data_page_offset
+		w.structEnd()
+		w.elemEnd()
+	}
+	w.i64(2, totalSize)
+	w.i64(3, numRows)
+	w.elemEnd()
+
+	w.binary(6, []byte(parquetCreatedBy))
+	w.stop()
+	return w.buf.Bytes()
+}
+
+// Thrift Compact 协议类型
+const (
+	thriftI32    = 5
+	thriftI64    = 6
+	thriftBinary = 8
+	thriftList   = 9
+	thriftStruct = 12
+)
+
+// thriftWriter Thrift Compact 协议编码器（只实现 Parquet 元数据需要的部分）
+type thriftWriter struct {
+	buf    bytes.Buffer
+	last   int16   // 当前结构体上一个字段ID
+	parent []int16 // 外层结构体的 last
+}
+
+// fieldHeader 写入字段头（ID 差值 1~15 时使用短格式）
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	if delta := id - w.last; delta > 0 && delta <= 15 {
+		w.buf.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.buf.WriteByte(typ)
+		w.varint(zigzag(int64(id)))
+	}
+	w.last = id
+}
+
+func (w *thriftWriter) i32(id int16, v int32) {
+	w.fieldHeader(id, thriftI32)
+	w.varint(zigzag(int64(v)))
+}
+
+func (w *thriftWriter) i64(id int16, v int64) {
+	w.fieldHeader(id, thriftI64)
+	w.varint(zigzag(v))
+}
+
+func (w *thriftWriter) binary(id int16, b []byte) {
+	w.fieldHeader(id, thriftBinary)
+	w.rawBinary(b)
+}
+
+// rawBinary 写入不带字段头的二进制值（列表元素）
+func (w *thriftWriter) rawBinary(b []byte) {
+	w.varint(uint64(len(b)))
+	w.buf.Write(b)
+}
+
+// listBegin 写入列表字段头（元素紧随其后）
+func (w *thriftWriter) listBegin(id int16, elemType byte, size int) {
+	w.fieldHeader(id, thriftList)
+	if size < 15 {
+		w.buf.WriteByte(byte(size)<<4 | elemType)
+	} else {
+		w.buf.WriteByte(0xF0 | elemType)
+		w.varint(uint64(size))
+	}
+}
+
+// structBegin 开始一个结构体字段
+func (w *thriftWriter) structBegin(id int16) {
+	w.fieldHeader(id, thriftStruct)
+	w.elemBegin()
+}
+
+// structEnd 结束结构体字段
+func (w *thriftWriter) structEnd() {
+	w.elemEnd()
+}
+
+// elemBegin 开始一个结构体（列表元素或字段值）
+func (w *thriftWriter) elemBegin() {
+	w.parent = append(w.parent, w.last)
+	w.last = 0
+}
+
+// elemEnd 结束结构体并恢复外层字段ID
+func (w *thriftWriter) elemEnd() {
+	w.stop()
+	w.last = w.parent[len(w.parent)-1]
+	w.parent = w.parent[:len(w.parent)-1]
+}
+
+// stop 写入结构体结束标记
+func (w *thriftWriter) stop() {
+	w.buf.WriteByte(0)
+}
+
+func (w *thriftWriter) varint(v uint64) {
+	var tmp [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(tmp[:], v)
+	w.buf.Write(tmp[:n])
+}
+
+// zigzag ZigZag 编码有符号整数
+func zigzag(v int64) uint64 {
+	return uint64((v << 1) ^ (v >> 63))
+}
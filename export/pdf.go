@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// 最小化的 PDF 输出：A4 横向、等宽字体（Courier，PDF 内置字体无需嵌入）的纯文本表格，
+// 便于打印或提交给会计；Courier 只支持 ASCII，其他字符输出为 ?
+
+const (
+	pdfPageWidth    = 842 // A4 横向（pt）
+	pdfPageHeight   = 595
+	pdfMargin       = 36
+	pdfFontSize     = 7
+	pdfLineHeight   = 9
+	pdfLinesPerPage = (pdfPageHeight-2*pdfMargin)/pdfLineHeight - 1 // 预留页码行
+)
+
+// PDFSection PDF 中的一节（标题 + 表格）
+type PDFSection struct {
+	Title string
+	Lines []string // 表格前的说明文字
+	Table *Table
+}
+
+// WritePDF 将多个表格写成一个 PDF 文档
+func WritePDF(w io.Writer, title string, sections ...PDFSection) error {
+	var lines []string
+	if title != "" {
+		lines = append(lines, title, "")
+	}
+	for _, s := range sections {
+		if s.Title != "" {
+			lines = append(lines, s.Title, strings.Repeat("=", len(s.Title)))
+		}
+		lines = append(lines, s.Lines...)
+		if s.Table != nil {
+			table, err := tableLines(s.Table)
+			if err != nil {
+				return err
+			}
+			lines = append(lines, table...)
+		}
+		lines = append(lines, "")
+	}
+
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+	return writePDFPages(w, pages)
+}
+
+// tableLines 将表格格式化为等宽对齐的文本行
+func tableLines(table *Table) ([]string, error) {
+	cells := make([][]string, 0, len(table.Rows)+1)
+	header := make([]string, len(table.Columns))
+	for i, c := range table.Columns {
+		header[i] = c.Name
+	}
+	cells = append(cells, header)
+	for r, row := range table.Rows {
+		if len(row) != len(table.Columns) {
+			return nil, fmt.Errorf("第 %d 行有 %d 个值，应为 %d 个", r+1, len(row), len(table.Columns))
+		}
+		record := make([]string, len(row))
+		for i, v := range row {
+			if f, ok := v.(float64); ok && table.Columns[i].Type == ColumnFloat {
+				record[i] = strconv.FormatFloat(math.Round(f*1e8)/1e8, 'f', -1, 64)
+				continue
+			}
+			s, err := formatCSVValue(table.Columns[i], v)
+			if err != nil {
+				return nil, fmt.Errorf("第 %d 行: %w", r+1, err)
+			}
+			record[i] = s
+		}
+		cells = append(cells, record)
+	}
+
+	widths := make([]int, len(table.Columns))
+	for _, record := range cells {
+		for i, s := range record {
+			if n := utf8.RuneCountInString(s); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
+	lines := make([]string, 0, len(cells)+1)
+	for r, record := range cells {
+		var sb strings.Builder
+		for i, s := range record {
+			if i > 0 {
+				sb.WriteString("  ")
+			}
+			pad := strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s))
+			if table.Columns[i].Type == ColumnFloat || table.Columns[i].Type == ColumnInt {
+				sb.WriteString(pad + s) // 数值右对齐
+			} else {
+				sb.WriteString(s + pad)
+			}
+		}
+		lines = append(lines, strings.TrimRight(sb.String(), " "))
+		if r == 0 {
+			lines = append(lines, strings.Repeat("-", utf8.RuneCountInString(lines[0])))
+		}
+	}
+	return lines, nil
+}
+
+// writePDFPages 写出 PDF 文件结构（目录、页面树、字体、每页一个内容流和交叉引用表）
+func writePDFPages(w io.Writer, pages [][]string) error {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n")
+	// 对象编号：1 目录、2 页面树、3 字体，之后每页依次为页面对象和内容流
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>")
+	for i, lines := range pages {
+		var content strings.Builder
+		fmt.Fprintf(&content, "BT\n/F1 %d Tf\n%d TL\n%d %d Td\n", pdfFontSize, pdfLineHeight, pdfMargin, pdfPageHeight-pdfMargin-pdfFontSize)
+		for _, line := range lines {
+			fmt.Fprintf(&content, "(%s) '\n", pdfEscape(line))
+		}
+		fmt.Fprintf(&content, "(Page %d / %d) '\nET", i+1, len(pages))
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+			pdfPageWidth, pdfPageHeight, 5+2*i))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// pdfEscape 转义 PDF 字符串（非 ASCII 字符替换为 ?）
+func pdfEscape(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r < 0x20 || r > 0x7e:
+			sb.WriteByte('?')
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return sb.String()
+}
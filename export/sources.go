@@ -0,0 +1,113 @@
+package export
+
+import (
+	"nofx/analytics"
+	"nofx/logger"
+	"nofx/market"
+	"time"
+)
+
+// JournalTradesTable 交易事件日志重建的交易（analytics.Trade）
+func JournalTradesTable(traderID string, trades []analytics.Trade) *Table {
+	table := &Table{Columns: []Column{
+		{"trader_id", ColumnString}, {"symbol", ColumnString}, {"side", ColumnString},
+		{"opened_at", ColumnTime}, {"closed_at", ColumnTime}, {"quantity", ColumnFloat},
+		{"entry_price", ColumnFloat}, {"exit_price", ColumnFloat}, {"gross_pnl", ColumnFloat},
+		{"fees", ColumnFloat}, {"net_pnl", ColumnFloat}, {"initial_risk", ColumnFloat},
+		{"r_multiple", ColumnFloat}, {"holding_seconds", ColumnInt}, {"tag", ColumnString},
+	}}
+	for _, t := range trades {
+		table.Rows = append(table.Rows, []interface{}{
+			traderID, t.Symbol, t.Side, t.OpenedAt, t.ClosedAt, t.Quantity,
+			t.EntryPrice, t.ExitPrice, t.GrossPnL, t.Fees, t.NetPnL, t.InitialRisk,
+			t.RMultiple, int64(t.HoldingTime / time.Second), t.Tag,
+		})
+	}
+	return table
+}
+
+// DecisionTradesTable 决策日志匹配出的交易（logger.TradeOutcome）
+func DecisionTradesTable(traderID string, trades []logger.TradeOutcome) *Table {
+	table := &Table{Columns: []Column{
+		{"trader_id", ColumnString}, {"symbol", ColumnString}, {"side", ColumnString},
+		{"open_time", ColumnTime}, {"close_time", ColumnTime}, {"quantity", ColumnFloat},
+		{"leverage", ColumnInt}, {"open_price", ColumnFloat}, {"close_price", ColumnFloat},
+		{"position_value", ColumnFloat}, {"margin_used", ColumnFloat}, {"pnl", ColumnFloat},
+		{"pnl_pct", ColumnFloat}, {"was_stop_loss", ColumnBool},
+	}}
+	for _, t := range trades {
+		table.Rows = append(table.Rows, []interface{}{
+			traderID, t.Symbol, t.Side, t.OpenTime, t.CloseTime, t.Quantity,
+			int64(t.Leverage), t.OpenPrice, t.ClosePrice, t.PositionValue, t.MarginUsed, t.PnL,
+			t.PnLPct, t.WasStopLoss,
+		})
+	}
+	return table
+}
+
+// KlinesTable K线
+func KlinesTable(symbol, interval string, klines []market.Kline) *Table {
+	table := &Table{Columns: []Column{
+		{"symbol", ColumnString}, {"interval", ColumnString},
+		{"open_time", ColumnTime}, {"close_time", ColumnTime},
+		{"open", ColumnFloat}, {"high", ColumnFloat}, {"low", ColumnFloat}, {"close", ColumnFloat},
+		{"volume", ColumnFloat}, {"quote_volume", ColumnFloat}, {"trades", ColumnInt},
+		{"taker_buy_base_volume", ColumnFloat}, {"taker_buy_quote_volume", ColumnFloat},
+	}}
+	for _, k := range klines {
+		table.Rows = append(table.Rows, []interface{}{
+			symbol, interval, time.UnixMilli(k.OpenTime), time.UnixMilli(k.CloseTime),
+			k.Open, k.High, k.Low, k.Close,
+			k.Volume, k.QuoteVolume, int64(k.Trades),
+			k.TakerBuyBaseVolume, k.TakerBuyQuoteVolume,
+		})
+	}
+	return table
+}
+
+// DisposalsTable 对账单中每笔平仓的成本和已实现盈亏（analytics.Disposal）
+func DisposalsTable(disposals []analytics.Disposal) *Table {
+	table := &Table{Columns: []Column{
+		{"symbol", ColumnString}, {"side", ColumnString}, {"acquired_at", ColumnTime}, {"disposed_at", ColumnTime},
+		{"quantity", ColumnFloat}, {"proceeds", ColumnFloat}, {"cost_basis", ColumnFloat}, {"realized_pnl", ColumnFloat},
+	}}
+	for _, d := range disposals {
+		table.Rows = append(table.Rows, []interface{}{
+			d.Symbol, d.Side, d.AcquiredAt, d.DisposedAt,
+			d.Quantity, d.Proceeds, d.CostBasis, d.RealizedPnL,
+		})
+	}
+	return table
+}
+
+// StatementPeriodsTable 对账单按周期和币种汇总的盈亏（analytics.PeriodStatement）
+func StatementPeriodsTable(periods []analytics.PeriodStatement) *Table {
+	table := &Table{Columns: []Column{
+		{"period", ColumnString}, {"symbol", ColumnString}, {"disposals", ColumnInt},
+		{"realized_pnl", ColumnFloat}, {"fees", ColumnFloat}, {"funding", ColumnFloat}, {"net", ColumnFloat},
+	}}
+	for _, p := range periods {
+		table.Rows = append(table.Rows, []interface{}{
+			p.Period, p.Symbol, int64(p.Disposals),
+			p.RealizedPnL, p.Fees, p.Funding, p.Net,
+		})
+	}
+	return table
+}
+
+// StatementCoinsTable 对账单按币种汇总的盈亏和期末持仓（analytics.CoinStatement）
+func StatementCoinsTable(coins []analytics.CoinStatement) *Table {
+	table := &Table{Columns: []Column{
+		{"symbol", ColumnString}, {"disposals", ColumnInt},
+		{"realized_pnl", ColumnFloat}, {"fees", ColumnFloat}, {"funding", ColumnFloat}, {"net", ColumnFloat},
+		{"open_long", ColumnFloat}, {"open_short", ColumnFloat}, {"open_cost_basis", ColumnFloat},
+	}}
+	for _, c := range coins {
+		table.Rows = append(table.Rows, []interface{}{
+			c.Symbol, int64(c.Disposals),
+			c.RealizedPnL, c.Fees, c.Funding, c.Net,
+			c.OpenLong, c.OpenShort, c.OpenCostBasis,
+		})
+	}
+	return table
+}
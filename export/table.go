@@ -0,0 +1,114 @@
+// Package export 将交易记录和K线导出为 CSV / Parquet / PDF（便于 pandas 分析、打印或导入报税工具）
+package export
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// 导出格式
+const (
+	FormatCSV     = "csv"
+	FormatParquet = "parquet"
+	FormatPDF     = "pdf"
+)
+
+// ColumnType 列类型
+type ColumnType int
+
+const (
+	ColumnString ColumnType = iota // string
+	ColumnFloat                    // float64
+	ColumnInt                      // int64
+	ColumnTime                     // time.Time（Parquet 中为毫秒时间戳，CSV 中为 RFC3339 UTC）
+	ColumnBool                     // bool
+)
+
+// Column 列定义
+type Column struct {
+	Name string
+	Type ColumnType
+}
+
+// Table 待导出的表格（每行的值类型与列定义一致）
+type Table struct {
+	Columns []Column
+	Rows    [][]interface{}
+}
+
+// Write 按格式写出表格
+func Write(w io.Writer, table *Table, format string) error {
+	switch format {
+	case FormatCSV:
+		return WriteCSV(w, table)
+	case FormatParquet:
+		return WriteParquet(w, table)
+	case FormatPDF:
+		return WritePDF(w, "", PDFSection{Table: table})
+	default:
+		return fmt.Errorf("不支持的导出格式: %s（可选: csv, parquet, pdf）", format)
+	}
+}
+
+// WriteCSV 以 CSV 写出表格（首行为列名）
+func WriteCSV(w io.Writer, table *Table) error {
+	cw := csv.NewWriter(w)
+	header := make([]string, len(table.Columns))
+	for i, c := range table.Columns {
+		header[i] = c.Name
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	record := make([]string, len(table.Columns))
+	for r, row := range table.Rows {
+		if len(row) != len(table.Columns) {
+			return fmt.Errorf("第 %d 行有 %d 个值，应为 %d 个", r+1, len(row), len(table.Columns))
+		}
+		for i, v := range row {
+			s, err := formatCSVValue(table.Columns[i], v)
+			if err != nil {
+				return fmt.Errorf("第 %d 行: %w", r+1, err)
+			}
+			record[i] = s
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCSVValue 格式化单元格
+func formatCSVValue(col Column, v interface{}) (string, error) {
+	switch col.Type {
+	case ColumnString:
+		if s, ok := v.(string); ok {
+			return s, nil
+		}
+	case ColumnFloat:
+		if f, ok := v.(float64); ok {
+			return strconv.FormatFloat(f, 'f', -1, 64), nil
+		}
+	case ColumnInt:
+		if n, ok := v.(int64); ok {
+			return strconv.FormatInt(n, 10), nil
+		}
+	case ColumnTime:
+		if t, ok := v.(time.Time); ok {
+			if t.IsZero() {
+				return "", nil
+			}
+			return t.UTC().Format(time.RFC3339), nil
+		}
+	case ColumnBool:
+		if b, ok := v.(bool); ok {
+			return strconv.FormatBool(b), nil
+		}
+	}
+	return "", fmt.Errorf("列 %s 的值类型不匹配: %T", col.Name, v)
+}
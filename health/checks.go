@@ -0,0 +1,49 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"nofx/manager"
+	"nofx/market"
+)
+
+// DataSourceChecks 每个行情数据源一项检查（调用 HealthCheck；单个数据源故障由故障转移兜底，属非关键依赖）
+func DataSourceChecks(dsm *market.DataSourceManager) CheckSource {
+	return func() []Check {
+		sources := dsm.Sources()
+		checks := make([]Check, 0, len(sources))
+		for _, source := range sources {
+			checks = append(checks, Check{Name: source.GetName(), Kind: KindDataSource, Run: source.HealthCheck})
+		}
+		return checks
+	}
+}
+
+// DatabaseCheck 数据库连接检查（关键依赖）
+func DatabaseCheck(name string, ping func(ctx context.Context) error) Check {
+	return Check{Name: name, Kind: KindDatabase, Critical: true, Run: ping}
+}
+
+// WebSocketCheck WebSocket 连接检查（断线期间由自动重连恢复，属非关键依赖）
+func WebSocketCheck(name string, connected func() bool) Check {
+	return Check{Name: name, Kind: KindWebSocket, Run: func(context.Context) error {
+		if !connected() {
+			return errors.New("未连接")
+		}
+		return nil
+	}}
+}
+
+// ExchangeChecks 每个运行中交易员的交易所私有接口检查（查询余额；单个账户异常不影响其他交易员，属非关键依赖）
+func ExchangeChecks(tm *manager.TraderManager) CheckSource {
+	return func() []Check {
+		var checks []Check
+		for id, t := range tm.GetAllTraders() {
+			if running, _ := t.GetStatus()["is_running"].(bool); !running {
+				continue
+			}
+			checks = append(checks, Check{Name: id, Kind: KindExchange, Run: t.CheckExchange})
+		}
+		return checks
+	}
+}
@@ -0,0 +1,187 @@
+// Package health 存活 / 就绪探针：按依赖项（数据源、交易所私有接口、数据库、WebSocket）汇总健康状态，
+// 以 JSON 输出，供 Kubernetes livenessProbe / readinessProbe 和外部可用性监控使用
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 依赖项类型
+const (
+	KindDataSource = "datasource"
+	KindExchange   = "exchange"
+	KindDatabase   = "database"
+	KindWebSocket  = "websocket"
+)
+
+// 检查结果状态
+const (
+	StatusOK       = "ok"       // 全部依赖正常
+	StatusDegraded = "degraded" // 仅非关键依赖异常（仍可接收流量）
+	StatusDown     = "down"     // 关键依赖异常（/readyz 返回 503）
+)
+
+// DefaultCheckTimeout 单项检查的默认超时
+const DefaultCheckTimeout = 5 * time.Second
+
+// Check 单个依赖项的健康检查
+type Check struct {
+	Name     string                          // 依赖名称（同类型内唯一，如 binance、trader-1）
+	Kind     string                          // 依赖类型（KindDataSource 等）
+	Critical bool                            // 关键依赖：失败时服务视为未就绪
+	Run      func(ctx context.Context) error // 执行检查（nil 错误表示正常）
+}
+
+// CheckSource 动态生成检查项（如当前已加载的交易员），每次探测时调用
+type CheckSource func() []Check
+
+// Result 单项检查结果
+type Result struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Status    string `json:"status"` // ok / down
+	Critical  bool   `json:"critical"`
+	LatencyMs int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report 就绪检查报告
+type Report struct {
+	Status string   `json:"status"`
+	Time   string   `json:"time"`
+	Checks []Result `json:"checks"`
+}
+
+// Registry 健康检查注册表
+type Registry struct {
+	mu      sync.RWMutex
+	checks  []Check
+	sources []CheckSource
+	timeout time.Duration
+	started time.Time
+}
+
+// NewRegistry 创建注册表（timeout<=0 时使用 DefaultCheckTimeout）
+func NewRegistry(timeout time.Duration) *Registry {
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+	return &Registry{timeout: timeout, started: time.Now()}
+}
+
+// Register 注册固定的检查项
+func (r *Registry) Register(check Check) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, check)
+}
+
+// AddSource 注册动态检查项来源
+func (r *Registry) AddSource(source CheckSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.sources = append(r.sources, source)
+}
+
+// Check 并发执行全部检查（每项单独超时），结果按类型、名称排序
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := append([]Check(nil), r.checks...)
+	sources := append([]CheckSource(nil), r.sources...)
+	r.mu.RUnlock()
+	for _, source := range sources {
+		checks = append(checks, source()...)
+	}
+
+	results := make([]Result, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = r.run(ctx, check)
+		}()
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Kind != results[j].Kind {
+			return results[i].Kind < results[j].Kind
+		}
+		return results[i].Name < results[j].Name
+	})
+	status := StatusOK
+	for _, result := range results {
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			status = StatusDown
+			break
+		}
+		status = StatusDegraded
+	}
+	return Report{Status: status, Time: time.Now().Format(time.RFC3339), Checks: results}
+}
+
+// run 执行单项检查（超时后不再等待检查函数返回）
+func (r *Registry) run(ctx context.Context, check Check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	result := Result{Name: check.Name, Kind: check.Kind, Critical: check.Critical, Status: StatusOK}
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() { done <- check.Run(ctx) }()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	result.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		result.Status = StatusDown
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// LivenessHandler /healthz：进程能处理请求即为存活（不检查外部依赖，避免交易所故障时被反复重启）
+func (r *Registry) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"status":         StatusOK,
+			"time":           time.Now().Format(time.RFC3339),
+			"uptime_seconds": int64(time.Since(r.started).Seconds()),
+		})
+	})
+}
+
+// ReadinessHandler /readyz：执行全部依赖检查，关键依赖异常时返回 503（?verbose=0 时只返回总体状态）
+func (r *Registry) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		report := r.Check(req.Context())
+		status := http.StatusOK
+		if report.Status == StatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		if req.URL.Query().Get("verbose") == "0" {
+			writeJSON(w, status, map[string]string{"status": report.Status})
+			return
+		}
+		writeJSON(w, status, report)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
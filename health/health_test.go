@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func ok(context.Context) error { return nil }
+
+func TestCheckStatus(t *testing.T) {
+	tests := []struct {
+		name   string
+		checks []Check
+		want   string
+	}{
+		{"全部正常", []Check{{Name: "db", Kind: KindDatabase, Critical: true, Run: ok}}, StatusOK},
+		{"非关键依赖异常", []Check{
+			{Name: "db", Kind: KindDatabase, Critical: true, Run: ok},
+			{Name: "binance", Kind: KindDataSource, Run: func(context.Context) error { return errors.New("timeout") }},
+		}, StatusDegraded},
+		{"关键依赖异常", []Check{
+			{Name: "db", Kind: KindDatabase, Critical: true, Run: func(context.Context) error { return errors.New("closed") }},
+		}, StatusDown},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRegistry(time.Second)
+			for _, c := range tt.checks {
+				r.Register(c)
+			}
+			if got := r.Check(context.Background()).Status; got != tt.want {
+				t.Errorf("status = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	r := NewRegistry(20 * time.Millisecond)
+	block := make(chan struct{})
+	defer close(block)
+	r.Register(Check{Name: "exchange", Kind: KindExchange, Critical: true, Run: func(context.Context) error {
+		<-block // 忽略 ctx 的检查函数也不能拖住探针
+		return nil
+	}})
+
+	start := time.Now()
+	report := r.Check(context.Background())
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("检查耗时 %v，超时未生效", elapsed)
+	}
+	if report.Status != StatusDown || report.Checks[0].Error != context.DeadlineExceeded.Error() {
+		t.Errorf("report = %+v", report)
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	r := NewRegistry(time.Second)
+	r.Register(DatabaseCheck("sqlite", ok))
+	connected := false
+	r.Register(WebSocketCheck("streams", func() bool { return connected }))
+	r.AddSource(func() []Check {
+		return []Check{{Name: "okx", Kind: KindDataSource, Run: ok}, {Name: "binance", Kind: KindDataSource, Run: ok}}
+	})
+
+	rec := httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("code = %d, want 200（WebSocket 断开仅为 degraded）", rec.Code)
+	}
+	var report Report
+	if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Status != StatusDegraded || len(report.Checks) != 4 {
+		t.Fatalf("report = %+v", report)
+	}
+	var order []string
+	for _, c := range report.Checks {
+		order = append(order, c.Kind+"/"+c.Name)
+	}
+	want := []string{"database/sqlite", "datasource/binance", "datasource/okx", "websocket/streams"}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("order = %v, want %v", order, want)
+		}
+	}
+
+	r.Register(DatabaseCheck("replica", func(context.Context) error { return errors.New("locked") }))
+	rec = httptest.NewRecorder()
+	r.ReadinessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz?verbose=0", nil))
+	if rec.Code != http.StatusServiceUnavailable || rec.Body.String() != "{\"status\":\"down\"}\n" {
+		t.Errorf("code = %d, body = %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestLivenessHandler(t *testing.T) {
+	r := NewRegistry(0)
+	r.Register(DatabaseCheck("sqlite", func(context.Context) error { return errors.New("closed") }))
+
+	rec := httptest.NewRecorder()
+	r.LivenessHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("code = %d, 存活探针不应检查外部依赖", rec.Code)
+	}
+}
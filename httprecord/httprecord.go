@@ -0,0 +1,322 @@
+// Package httprecord 录制交易所 HTTP 请求/响应（密钥脱敏）为可回放的测试夹具，并提供回放 Transport
+package httprecord
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"nofx/logging"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var log = logging.Module("httprecord")
+
+// Redacted 脱敏后的占位值
+const Redacted = "REDACTED"
+
+// SecretHeaders 录制时脱敏的请求/响应头（不区分大小写）
+var SecretHeaders = []string{
+	"Authorization", "Cookie", "Set-Cookie",
+	"X-MBX-APIKEY",
+	"OK-ACCESS-KEY", "OK-ACCESS-SIGN", "OK-ACCESS-PASSPHRASE",
+	"X-BAPI-API-KEY", "X-BAPI-SIGN",
+	"CB-ACCESS-KEY", "CB-ACCESS-SIGN", "CB-ACCESS-PASSPHRASE",
+}
+
+// SecretParams 录制时脱敏的查询/表单参数
+var SecretParams = []string{"signature", "sign", "apiKey", "api_key", "passphrase", "secret", "privateKey"}
+
+// VolatileParams 回放匹配时忽略的参数（每次请求都不同）
+var VolatileParams = []string{"timestamp", "signature", "sign", "nonce", "recvWindow"}
+
+// Fixture 一次录制的请求/响应
+type Fixture struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest 录制的请求（已脱敏）
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse 录制的响应
+type RecordedResponse struct {
+	Status  int         `json:"status"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body"`
+}
+
+// recordDir 全局录制目录（空=不录制），由 Configure 设置
+var recordDir atomic.Value
+
+// Configure 设置全局录制目录（空字符串关闭录制）；经 Wrap 包装的 Transport 立即生效
+func Configure(dir string) error {
+	if dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("创建 HTTP 录制目录失败: %w", err)
+		}
+		log.Info("📼 HTTP 请求录制已开启", "dir", dir)
+	}
+	recordDir.Store(dir)
+	return nil
+}
+
+// ConfigureFromEnv 读取 NOFX_HTTP_RECORD_DIR 设置录制目录
+func ConfigureFromEnv() error {
+	return Configure(os.Getenv("NOFX_HTTP_RECORD_DIR"))
+}
+
+func currentDir() string {
+	dir, _ := recordDir.Load().(string)
+	return dir
+}
+
+// Wrap 为交易员和数据源的 Transport 挂载录制钩子（未 Configure 录制目录时直接透传）
+// base 为 nil 时使用 http.DefaultTransport
+func Wrap(base http.RoundTripper) http.RoundTripper {
+	return &Recorder{Base: base}
+}
+
+// Recorder 录制请求/响应到目录的 http.RoundTripper
+type Recorder struct {
+	Base http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Dir  string            // 录制目录（空=使用 Configure 设置的全局目录，全局也为空时不录制）
+}
+
+var fixtureSeq atomic.Int64
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	base := r.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	dir := r.Dir
+	if dir == "" {
+		dir = currentDir()
+	}
+	if dir == "" {
+		return base.RoundTrip(req)
+	}
+
+	var reqBody []byte
+	if req.Body != nil && req.Body != http.NoBody {
+		var err error
+		if reqBody, err = io.ReadAll(req.Body); err != nil {
+			return nil, err
+		}
+		req.Body.Close()
+		req = req.Clone(req.Context())
+		req.Body = io.NopCloser(bytes.NewReader(reqBody))
+	}
+
+	resp, err := base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	fixture := Fixture{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			URL:     redactURL(req.URL),
+			Headers: redactHeaders(req.Header),
+			Body:    redactBody(req.Header.Get("Content-Type"), reqBody),
+		},
+		Response: RecordedResponse{
+			Status:  resp.StatusCode,
+			Headers: redactHeaders(resp.Header),
+			Body:    string(respBody),
+		},
+	}
+	if err := writeFixture(dir, fixture); err != nil {
+		log.Warn("⚠️ 写入 HTTP 录制文件失败", "error", err)
+	}
+	return resp, nil
+}
+
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// writeFixture 按录制顺序命名写入（文件名排序即请求顺序）
+func writeFixture(dir string, f Fixture) error {
+	u, _ := url.Parse(f.Request.URL)
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(u.Path, "_"), "_")
+	file := fmt.Sprintf("%d-%04d-%s-%s.json", time.Now().UnixMilli(), fixtureSeq.Add(1)%10000, f.Request.Method, name)
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false) // 保持 URL 中的 & 可读
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(f); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, file), buf.Bytes(), 0o644)
+}
+
+func redactHeaders(h http.Header) http.Header {
+	if len(h) == 0 {
+		return nil
+	}
+	out := h.Clone()
+	for _, name := range SecretHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, Redacted)
+		}
+	}
+	return out
+}
+
+func redactValues(v url.Values) url.Values {
+	for _, name := range SecretParams {
+		if v.Has(name) {
+			v.Set(name, Redacted)
+		}
+	}
+	return v
+}
+
+func redactURL(u *url.URL) string {
+	c := *u
+	c.User = nil
+	c.RawQuery = redactValues(c.Query()).Encode()
+	return c.String()
+}
+
+func redactBody(contentType string, body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	if strings.HasPrefix(contentType, "application/x-www-form-urlencoded") {
+		if v, err := url.ParseQuery(string(body)); err == nil {
+			return redactValues(v).Encode()
+		}
+	}
+	return string(body)
+}
+
+// LoadFixtures 读取目录下全部录制文件（按文件名排序，即录制顺序）
+func LoadFixtures(dir string) ([]Fixture, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	fixtures := make([]Fixture, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var f Fixture
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("解析录制文件 %s 失败: %w", filepath.Base(file), err)
+		}
+		fixtures = append(fixtures, f)
+	}
+	return fixtures, nil
+}
+
+// Replayer 按录制文件回放响应的 http.RoundTripper（不发出任何网络请求）
+// 按 方法 + 路径 + 查询参数（忽略 VolatileParams 和 SecretParams）匹配；同一请求录制多次时按顺序返回，用完后重复最后一次
+type Replayer struct {
+	mu       sync.Mutex
+	fixtures map[string][]Fixture
+	served   map[string]int
+}
+
+// NewReplayer 从录制目录创建回放 Transport
+func NewReplayer(dir string) (*Replayer, error) {
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(fixtures) == 0 {
+		return nil, fmt.Errorf("录制目录 %s 中没有录制文件", dir)
+	}
+	return NewReplayerFromFixtures(fixtures)
+}
+
+// NewReplayerFromFixtures 从已加载的录制创建回放 Transport
+func NewReplayerFromFixtures(fixtures []Fixture) (*Replayer, error) {
+	r := &Replayer{fixtures: make(map[string][]Fixture), served: make(map[string]int)}
+	for _, f := range fixtures {
+		u, err := url.Parse(f.Request.URL)
+		if err != nil {
+			return nil, fmt.Errorf("录制的 URL 无效 %q: %w", f.Request.URL, err)
+		}
+		key := matchKey(f.Request.Method, u)
+		r.fixtures[key] = append(r.fixtures[key], f)
+	}
+	return r, nil
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body != nil {
+		req.Body.Close()
+	}
+	key := matchKey(req.Method, req.URL)
+
+	r.mu.Lock()
+	candidates := r.fixtures[key]
+	i := r.served[key]
+	if i < len(candidates) {
+		r.served[key]++
+	}
+	r.mu.Unlock()
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("httprecord: 没有匹配的录制响应: %s", key)
+	}
+	f := candidates[min(i, len(candidates)-1)].Response
+
+	header := f.Headers.Clone()
+	if header == nil {
+		header = http.Header{}
+	}
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", f.Status, http.StatusText(f.Status)),
+		StatusCode:    f.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}, nil
+}
+
+// matchKey 回放匹配键：方法 + 路径 + 排序后的查询参数（去掉每次都变化的参数和已脱敏的参数）
+func matchKey(method string, u *url.URL) string {
+	q := u.Query()
+	for _, name := range VolatileParams {
+		q.Del(name)
+	}
+	for _, name := range SecretParams {
+		q.Del(name)
+	}
+	key := method + " " + u.Path
+	if encoded := q.Encode(); encoded != "" { // Encode 按参数名排序
+		key += "?" + encoded
+	}
+	return key
+}
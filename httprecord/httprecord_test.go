@@ -0,0 +1,116 @@
+package httprecord
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRecordRedactsSecretsAndReplays(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=abc")
+		io.WriteString(w, `{"orderId":1,"symbol":"`+r.URL.Query().Get("symbol")+`"}`)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: &Recorder{Dir: dir}}
+	req, _ := http.NewRequest(http.MethodPost, server.URL+"/fapi/v1/order?symbol=BTCUSDT&timestamp=1&signature=deadbeef",
+		strings.NewReader(url.Values{"apiKey": {"my-key"}, "side": {"BUY"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("X-MBX-APIKEY", "my-key")
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != `{"orderId":1,"symbol":"BTCUSDT"}` {
+		t.Fatalf("录制不应改变响应, got %s", body)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("Expected 1 fixture, got %d", len(files))
+	}
+	raw, _ := os.ReadFile(dir + "/" + files[0].Name())
+	for _, secret := range []string{"my-key", "deadbeef", "session=abc"} {
+		if strings.Contains(string(raw), secret) {
+			t.Errorf("录制文件未脱敏 %q:\n%s", secret, raw)
+		}
+	}
+
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatalf("NewReplayer failed: %v", err)
+	}
+	replay := &http.Client{Transport: replayer}
+	// 时间戳、签名不同也能匹配
+	resp, err = replay.Post("https://fapi.binance.com/fapi/v1/order?timestamp=2&symbol=BTCUSDT&signature=other", "", nil)
+	if err != nil {
+		t.Fatalf("replay failed: %v", err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != `{"orderId":1,"symbol":"BTCUSDT"}` {
+		t.Errorf("Unexpected replay: %d %s", resp.StatusCode, body)
+	}
+
+	if _, err := replay.Get("https://fapi.binance.com/fapi/v1/order?symbol=ETHUSDT"); err == nil {
+		t.Error("未录制的请求应返回错误")
+	}
+}
+
+func TestReplayerServesInOrder(t *testing.T) {
+	fixture := func(body string) Fixture {
+		return Fixture{
+			Request:  RecordedRequest{Method: http.MethodGet, URL: "https://www.okx.com/api/v5/public/time"},
+			Response: RecordedResponse{Status: http.StatusOK, Body: body},
+		}
+	}
+	replayer, err := NewReplayerFromFixtures([]Fixture{fixture("first"), fixture("second")})
+	if err != nil {
+		t.Fatalf("NewReplayerFromFixtures failed: %v", err)
+	}
+	client := &http.Client{Transport: replayer}
+	for _, want := range []string{"first", "second", "second"} {
+		resp, err := client.Get("https://www.okx.com/api/v5/public/time")
+		if err != nil {
+			t.Fatalf("replay failed: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != want {
+			t.Errorf("body = %q, want %q", body, want)
+		}
+	}
+}
+
+func TestWrapPassesThroughWhenDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	client := &http.Client{Transport: Wrap(nil)}
+	if _, err := client.Get(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := Configure(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer Configure("")
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if files, _ := os.ReadDir(dir); len(files) != 1 {
+		t.Errorf("Configure 后应开始录制, got %d files", len(files))
+	}
+}
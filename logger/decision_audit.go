@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+)
+
+// InputSnapshot 决策输入快照：与 SystemPrompt / InputPrompt / AccountState / Positions 一起，足以复现并排查任意一笔交易
+type InputSnapshot struct {
+	Provider   string                  `json:"provider"`              // 决策来源（llm / webhook / strategy:xxx / ensemble(...)）
+	Market     map[string]MarketInputs `json:"market,omitempty"`      // 币种 -> 行情指标
+	RawOutput  string                  `json:"raw_output,omitempty"`  // 决策来源的原始输出
+	ParseError string                  `json:"parse_error,omitempty"` // 获取或解析决策失败的原因
+}
+
+// MarketInputs 单个币种的行情输入
+type MarketInputs struct {
+	Price         float64 `json:"price"`
+	PriceChange1h float64 `json:"price_change_1h"`
+	PriceChange4h float64 `json:"price_change_4h"`
+	EMA20         float64 `json:"ema20"`
+	MACD          float64 `json:"macd"`
+	RSI7          float64 `json:"rsi7"`
+	ATR14         float64 `json:"atr14,omitempty"` // 3分钟周期
+	EMA50On4h     float64 `json:"ema50_4h,omitempty"`
+	FundingRate   float64 `json:"funding_rate"`
+	OpenInterest  float64 `json:"open_interest,omitempty"`
+	DataHash      string  `json:"data_hash"` // 全部K线序列及指标的 SHA-256（比较两次决策的输入是否一致）
+}
+
+// FindTrade 按交易关联ID（trade_id）查找执行该动作的决策记录
+func (l *DecisionLogger) FindTrade(tradeID string) (*DecisionRecord, *DecisionAction, error) {
+	records, err := l.GetLatestRecords(math.MaxInt32)
+	if err != nil {
+		return nil, nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		for j := range records[i].Decisions {
+			if records[i].Decisions[j].TradeID == tradeID {
+				return records[i], &records[i].Decisions[j], nil
+			}
+		}
+	}
+	return nil, nil, fmt.Errorf("未找到交易 %s", tradeID)
+}
@@ -28,6 +28,8 @@ type DecisionRecord struct {
 	ErrorMessage   string             `json:"error_message"`   // 错误信息（如果有）
 	// AIRequestDurationMs 记录 AI API 调用耗时（毫秒），方便评估调用性能
 	AIRequestDurationMs int64 `json:"ai_request_duration_ms,omitempty"`
+	// Inputs 决策输入快照（行情指标、决策来源原始输出），用于复现和 nofx explain
+	Inputs *InputSnapshot `json:"inputs,omitempty"`
 }
 
 // AccountSnapshot 账户状态快照
@@ -54,15 +56,19 @@ type PositionSnapshot struct {
 
 // DecisionAction 决策动作
 type DecisionAction struct {
-	Action    string    `json:"action"`    // open_long, open_short, close_long, close_short, update_stop_loss, update_take_profit, partial_close
-	Symbol    string    `json:"symbol"`    // 币种
-	Quantity  float64   `json:"quantity"`  // 数量（部分平仓时使用）
-	Leverage  int       `json:"leverage"`  // 杠杆（开仓时）
-	Price     float64   `json:"price"`     // 执行价格
-	OrderID   int64     `json:"order_id"`  // 订单ID
-	Timestamp time.Time `json:"timestamp"` // 执行时间
-	Success   bool      `json:"success"`   // 是否成功
-	Error     string    `json:"error"`     // 错误信息
+	TradeID   string    `json:"trade_id,omitempty"` // 交易关联ID（与日志中的 trade_id 对应）
+	Action    string    `json:"action"`             // open_long, open_short, close_long, close_short, update_stop_loss, update_take_profit, partial_close
+	Symbol    string    `json:"symbol"`             // 币种
+	Quantity  float64   `json:"quantity"`           // 数量（部分平仓时使用）
+	Leverage  int       `json:"leverage"`           // 杠杆（开仓时）
+	Price     float64   `json:"price"`              // 执行价格
+	OrderID   int64     `json:"order_id"`           // 订单ID
+	ScaleIn   bool      `json:"scale_in,omitempty"` // 开仓动作是否为对已有同向持仓加仓
+	Side      string    `json:"side,omitempty"`     // 持仓方向 long/short（partial_close 时记录）
+	Fee       float64   `json:"fee"`                // 实际手续费（开平仓时，来自交易所成交记录）
+	Timestamp time.Time `json:"timestamp"`          // 执行时间
+	Success   bool      `json:"success"`            // 是否成功
+	Error     string    `json:"error"`              // 错误信息
 }
 
 // IDecisionLogger 决策日志记录器接口
@@ -79,12 +85,17 @@ type IDecisionLogger interface {
 	GetStatistics() (*Statistics, error)
 	// AnalyzePerformance 分析最近N个周期的交易表现
 	AnalyzePerformance(lookbackCycles int) (*PerformanceAnalysis, error)
+	// QueryTrades 按条件查询历史交易（筛选、排序、分页）
+	QueryTrades(q TradeQuery) (*TradePage, error)
+	// QueryDecisions 按条件查询历史决策（筛选、分页）
+	QueryDecisions(q DecisionQuery) (*DecisionPage, error)
 }
 
 // DecisionLogger 决策日志记录器
 type DecisionLogger struct {
 	logDir      string
 	cycleNumber int
+	lotMatching string // 部分平仓消耗加仓批次的顺序（fifo / lifo，空=fifo）
 }
 
 // NewDecisionLogger 创建决策日志记录器
@@ -109,6 +120,25 @@ func NewDecisionLogger(logDir string) IDecisionLogger {
 	}
 }
 
+// SetLotMatching 设置部分平仓消耗加仓批次的顺序（fifo / lifo）
+func (l *DecisionLogger) SetLotMatching(method string) error {
+	switch method {
+	case "", LotMatchFIFO, LotMatchLIFO:
+		l.lotMatching = method
+		return nil
+	default:
+		return fmt.Errorf("未知的批次匹配方式: %s", method)
+	}
+}
+
+// lotMatchingMethod 返回批次匹配方式（默认 FIFO）
+func (l *DecisionLogger) lotMatchingMethod() string {
+	if l.lotMatching == "" {
+		return LotMatchFIFO
+	}
+	return l.lotMatching
+}
+
 // LogDecision 记录决策
 func (l *DecisionLogger) LogDecision(record *DecisionRecord) error {
 	l.cycleNumber++
@@ -312,6 +342,9 @@ type TradeOutcome struct {
 	OpenTime      time.Time `json:"open_time"`      // 开仓时间
 	CloseTime     time.Time `json:"close_time"`     // 平仓时间
 	WasStopLoss   bool      `json:"was_stop_loss"`  // 是否止损
+
+	// 开仓批次（加仓时有多笔，开仓价为按数量加权的均价，各批次盈亏按 FIFO/LIFO 匹配平仓数量计算）
+	Lots []PositionLot `json:"lots,omitempty"`
 }
 
 // PerformanceAnalysis 交易表现分析
@@ -379,275 +412,44 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 		SymbolStats:  make(map[string]*SymbolPerformance),
 	}
 
-	// 追踪持仓状态：symbol_side -> {side, openPrice, openTime, quantity, leverage}
-	openPositions := make(map[string]map[string]interface{})
+	// 追踪持仓状态：symbol_side -> 按批次追踪的持仓
+	openPositions := make(map[string]*lotPosition)
 
-	// 为了避免开仓记录在窗口外导致匹配失败，需要先从所有历史记录中找出未平仓的持仓
+	// 为了避免开仓记录在窗口外导致匹配失败，需要先从窗口之前的历史记录中找出未平仓的持仓
 	// 获取更多历史记录来构建完整的持仓状态（使用更大的窗口）
 	allRecords, err := l.GetLatestRecords(lookbackCycles * 3) // 扩大3倍窗口
 	if err == nil && len(allRecords) > len(records) {
-		// 先从扩大的窗口中收集所有开仓记录
-		for _, record := range allRecords {
-			for _, action := range record.Decisions {
-				if !action.Success {
-					continue
-				}
-
-				symbol := action.Symbol
-				side := ""
-				if action.Action == "open_long" || action.Action == "close_long" || action.Action == "partial_close" || action.Action == "auto_close_long" {
-					side = "long"
-				} else if action.Action == "open_short" || action.Action == "close_short" || action.Action == "auto_close_short" {
-					side = "short"
-				}
-
-				// partial_close 需要根據持倉判斷方向
-				if action.Action == "partial_close" && side == "" {
-					for key, pos := range openPositions {
-						if posSymbol, _ := pos["side"].(string); key == symbol+"_"+posSymbol {
-							side = posSymbol
-							break
-						}
-					}
-				}
-
-				posKey := symbol + "_" + side
-
-				switch action.Action {
-				case "open_long", "open_short":
-					// 记录开仓
-					openPositions[posKey] = map[string]interface{}{
-						"side":      side,
-						"openPrice": action.Price,
-						"openTime":  action.Timestamp,
-						"quantity":  action.Quantity,
-						"leverage":  action.Leverage,
-					}
-				case "close_long", "close_short", "auto_close_long", "auto_close_short":
-					// Remove closed position records
-					delete(openPositions, posKey)
-					// partial_close 不處理，保留持倉記錄
-				}
-			}
-		}
+		matchTradeOutcomes(allRecords[:len(allRecords)-len(records)], openPositions, l.lotMatchingMethod())
 	}
 
 	// 遍历分析窗口内的记录，生成交易结果
-	for _, record := range records {
-		for _, action := range record.Decisions {
-			if !action.Success {
-				continue
-			}
+	for _, outcome := range matchTradeOutcomes(records, openPositions, l.lotMatchingMethod()) {
+		analysis.RecentTrades = append(analysis.RecentTrades, outcome)
+		analysis.TotalTrades++
+
+		// 分类交易
+		if outcome.PnL > 0 {
+			analysis.WinningTrades++
+			analysis.AvgWin += outcome.PnL
+		} else if outcome.PnL < 0 {
+			analysis.LosingTrades++
+			analysis.AvgLoss += outcome.PnL
+		}
 
-			symbol := action.Symbol
-			side := ""
-			if action.Action == "open_long" || action.Action == "close_long" || action.Action == "partial_close" || action.Action == "auto_close_long" {
-				side = "long"
-			} else if action.Action == "open_short" || action.Action == "close_short" || action.Action == "auto_close_short" {
-				side = "short"
-			}
-
-			// partial_close 需要根據持倉判斷方向
-			if action.Action == "partial_close" {
-				// 從 openPositions 中查找持倉方向
-				for key, pos := range openPositions {
-					if posSymbol, _ := pos["side"].(string); key == symbol+"_"+posSymbol {
-						side = posSymbol
-						break
-					}
-				}
-			}
-
-			posKey := symbol + "_" + side // 使用symbol_side作为key，区分多空持仓
-
-			switch action.Action {
-			case "open_long", "open_short":
-				// 更新开仓记录（可能已经在预填充时记录过了）
-				openPositions[posKey] = map[string]interface{}{
-					"side":               side,
-					"openPrice":          action.Price,
-					"openTime":           action.Timestamp,
-					"quantity":           action.Quantity,
-					"leverage":           action.Leverage,
-					"remainingQuantity":  action.Quantity, // 🔧 BUG FIX：追蹤剩餘數量
-					"accumulatedPnL":     0.0,             // 🔧 BUG FIX：累積部分平倉盈虧
-					"partialCloseCount":  0,               // 🔧 BUG FIX：部分平倉次數
-					"partialCloseVolume": 0.0,             // 🔧 BUG FIX：部分平倉總量
-				}
-
-			case "close_long", "close_short", "partial_close", "auto_close_long", "auto_close_short":
-				// 查找对应的开仓记录（可能来自预填充或当前窗口）
-				if openPos, exists := openPositions[posKey]; exists {
-					openPrice := openPos["openPrice"].(float64)
-					openTime := openPos["openTime"].(time.Time)
-					side := openPos["side"].(string)
-					quantity := openPos["quantity"].(float64)
-					leverage := openPos["leverage"].(int)
-
-					// 🔧 BUG FIX：取得追蹤字段（若不存在則初始化）
-					remainingQty, _ := openPos["remainingQuantity"].(float64)
-					if remainingQty == 0 {
-						remainingQty = quantity // 兼容舊數據（沒有 remainingQuantity 字段）
-					}
-					accumulatedPnL, _ := openPos["accumulatedPnL"].(float64)
-					partialCloseCount, _ := openPos["partialCloseCount"].(int)
-					partialCloseVolume, _ := openPos["partialCloseVolume"].(float64)
-
-					// 对于 partial_close，使用实际平仓数量；否则使用剩余仓位数量
-					actualQuantity := remainingQty
-					if action.Action == "partial_close" {
-						actualQuantity = action.Quantity
-					}
-
-					// 计算本次平仓的盈亏（USDT）- 包含手续费
-					var pnl float64
-					if side == "long" {
-						pnl = actualQuantity * (action.Price - openPrice)
-					} else {
-						pnl = actualQuantity * (openPrice - action.Price)
-					}
-
-					// ⚠️ 扣除交易手续费（开仓 + 平仓各一次）
-					// 获取交易所费率（从record中获取，如果没有则使用默认值）
-					feeRate := getTakerFeeRate(record.Exchange)
-					openFee := actualQuantity * openPrice * feeRate     // 开仓手续费
-					closeFee := actualQuantity * action.Price * feeRate // 平仓手续费
-					totalFees := openFee + closeFee
-					pnl -= totalFees // 从盈亏中扣除手续费
-
-					// 🔧 BUG FIX：處理 partial_close 聚合邏輯
-					if action.Action == "partial_close" {
-						// 累積盈虧和數量
-						accumulatedPnL += pnl
-						remainingQty -= actualQuantity
-						partialCloseCount++
-						partialCloseVolume += actualQuantity
-
-						// 更新 openPositions（保留持倉記錄，但更新追蹤數據）
-						openPos["remainingQuantity"] = remainingQty
-						openPos["accumulatedPnL"] = accumulatedPnL
-						openPos["partialCloseCount"] = partialCloseCount
-						openPos["partialCloseVolume"] = partialCloseVolume
-
-						// 判斷是否已完全平倉
-						if remainingQty <= 0.0001 { // 使用小閾值避免浮點誤差
-							// ✅ 完全平倉：記錄為一筆完整交易
-							positionValue := quantity * openPrice
-							marginUsed := positionValue / float64(leverage)
-							pnlPct := 0.0
-							if marginUsed > 0 {
-								pnlPct = (accumulatedPnL / marginUsed) * 100
-							}
-
-							outcome := TradeOutcome{
-								Symbol:        symbol,
-								Side:          side,
-								Quantity:      quantity, // 使用原始總量
-								Leverage:      leverage,
-								OpenPrice:     openPrice,
-								ClosePrice:    action.Price, // 最後一次平倉價格
-								PositionValue: positionValue,
-								MarginUsed:    marginUsed,
-								PnL:           accumulatedPnL, // 🔧 使用累積盈虧
-								PnLPct:        pnlPct,
-								Duration:      action.Timestamp.Sub(openTime).String(),
-								OpenTime:      openTime,
-								CloseTime:     action.Timestamp,
-							}
-
-							analysis.RecentTrades = append(analysis.RecentTrades, outcome)
-							analysis.TotalTrades++ // 🔧 Only count when fully closed
-
-							// 分类交易
-							if accumulatedPnL > 0 {
-								analysis.WinningTrades++
-								analysis.AvgWin += accumulatedPnL
-							} else if accumulatedPnL < 0 {
-								analysis.LosingTrades++
-								analysis.AvgLoss += accumulatedPnL
-							}
-
-							// 更新币种统计
-							if _, exists := analysis.SymbolStats[symbol]; !exists {
-								analysis.SymbolStats[symbol] = &SymbolPerformance{
-									Symbol: symbol,
-								}
-							}
-							stats := analysis.SymbolStats[symbol]
-							stats.TotalTrades++
-							stats.TotalPnL += accumulatedPnL
-							if accumulatedPnL > 0 {
-								stats.WinningTrades++
-							} else if accumulatedPnL < 0 {
-								stats.LosingTrades++
-							}
-
-							// 刪除持倉記錄
-							delete(openPositions, posKey)
-						}
-						// ⚠️ 否則不做任何操作（等待後續 partial_close 或 full close）
-
-					} else {
-						// 🔧 完全平倉（close_long/close_short/auto_close）
-						// 如果之前有部分平倉，需要加上累積的 PnL
-						totalPnL := accumulatedPnL + pnl
-
-						positionValue := quantity * openPrice
-						marginUsed := positionValue / float64(leverage)
-						pnlPct := 0.0
-						if marginUsed > 0 {
-							pnlPct = (totalPnL / marginUsed) * 100
-						}
-
-						outcome := TradeOutcome{
-							Symbol:        symbol,
-							Side:          side,
-							Quantity:      quantity, // 使用原始總量
-							Leverage:      leverage,
-							OpenPrice:     openPrice,
-							ClosePrice:    action.Price,
-							PositionValue: positionValue,
-							MarginUsed:    marginUsed,
-							PnL:           totalPnL, // 🔧 包含之前部分平倉的 PnL
-							PnLPct:        pnlPct,
-							Duration:      action.Timestamp.Sub(openTime).String(),
-							OpenTime:      openTime,
-							CloseTime:     action.Timestamp,
-						}
-
-						analysis.RecentTrades = append(analysis.RecentTrades, outcome)
-						analysis.TotalTrades++
-
-						// 分类交易
-						if totalPnL > 0 {
-							analysis.WinningTrades++
-							analysis.AvgWin += totalPnL
-						} else if totalPnL < 0 {
-							analysis.LosingTrades++
-							analysis.AvgLoss += totalPnL
-						}
-
-						// 更新币种统计
-						if _, exists := analysis.SymbolStats[symbol]; !exists {
-							analysis.SymbolStats[symbol] = &SymbolPerformance{
-								Symbol: symbol,
-							}
-						}
-						stats := analysis.SymbolStats[symbol]
-						stats.TotalTrades++
-						stats.TotalPnL += totalPnL
-						if totalPnL > 0 {
-							stats.WinningTrades++
-						} else if totalPnL < 0 {
-							stats.LosingTrades++
-						}
-
-						// 刪除持倉記錄
-						delete(openPositions, posKey)
-					}
-				}
+		// 更新币种统计
+		if _, exists := analysis.SymbolStats[outcome.Symbol]; !exists {
+			analysis.SymbolStats[outcome.Symbol] = &SymbolPerformance{
+				Symbol: outcome.Symbol,
 			}
 		}
+		stats := analysis.SymbolStats[outcome.Symbol]
+		stats.TotalTrades++
+		stats.TotalPnL += outcome.PnL
+		if outcome.PnL > 0 {
+			stats.WinningTrades++
+		} else if outcome.PnL < 0 {
+			stats.LosingTrades++
+		}
 	}
 
 	// 计算统计指标
@@ -714,6 +516,86 @@ func (l *DecisionLogger) AnalyzePerformance(lookbackCycles int) (*PerformanceAna
 	return analysis, nil
 }
 
+// matchTradeOutcomes 按时间顺序匹配开仓/平仓动作，生成已完全平仓的交易结果（按平仓时间正序）
+// openPositions 为窗口之前预先收集的未平仓持仓（可为空 map），method 为部分平仓消耗加仓批次的顺序
+func matchTradeOutcomes(records []*DecisionRecord, openPositions map[string]*lotPosition, method string) []TradeOutcome {
+	var trades []TradeOutcome
+	for _, record := range records {
+		for _, action := range record.Decisions {
+			if !action.Success {
+				continue
+			}
+
+			symbol := action.Symbol
+			side := ""
+			if action.Action == "open_long" || action.Action == "close_long" || action.Action == "auto_close_long" {
+				side = "long"
+			} else if action.Action == "open_short" || action.Action == "close_short" || action.Action == "auto_close_short" {
+				side = "short"
+			}
+
+			// partial_close 优先使用记录的持仓方向；旧记录没有方向时，只有单边持仓才能确定
+			if action.Action == "partial_close" {
+				side = partialCloseSide(action, openPositions)
+				if side == "" {
+					continue
+				}
+			}
+
+			posKey := symbol + "_" + side // 使用symbol_side作为key，区分多空持仓
+
+			switch action.Action {
+			case "open_long", "open_short":
+				// 加仓：追加开仓批次；否则视为新持仓（覆盖可能遗留的旧记录）
+				if openPos, exists := openPositions[posKey]; exists && action.ScaleIn {
+					openPos.add(action.Price, action.Quantity, action.Timestamp)
+				} else {
+					openPositions[posKey] = newLotPosition(side, action.Leverage, action.Price, action.Quantity, action.Timestamp)
+				}
+
+			case "close_long", "close_short", "partial_close", "auto_close_long", "auto_close_short":
+				// 查找对应的开仓记录（可能来自预填充或当前窗口）
+				openPos, exists := openPositions[posKey]
+				if !exists {
+					continue
+				}
+
+				// partial_close 按实际平仓数量消耗批次；其余平仓动作平掉剩余全部仓位
+				// ⚠️ 盈亏扣除交易手续费（开仓 + 平仓各一次），费率按交易所取默认值
+				quantity := 0.0
+				if action.Action == "partial_close" {
+					quantity = action.Quantity
+				}
+				openPos.close(quantity, action.Price, getTakerFeeRate(record.Exchange), method)
+
+				// 完全平仓才记录为一笔交易（部分平仓的盈亏累积到各批次）
+				if action.Action != "partial_close" || openPos.closed() {
+					trades = append(trades, openPos.outcome(symbol, action.Price, action.Timestamp))
+					delete(openPositions, posKey)
+				}
+			}
+		}
+	}
+
+	return trades
+}
+
+// partialCloseSide 判断 partial_close 平的是哪个方向的持仓（同时持有多空且未记录方向时返回空）
+func partialCloseSide(action DecisionAction, openPositions map[string]*lotPosition) string {
+	if action.Side == "long" || action.Side == "short" {
+		return action.Side
+	}
+	_, hasLong := openPositions[action.Symbol+"_long"]
+	_, hasShort := openPositions[action.Symbol+"_short"]
+	switch {
+	case hasLong && !hasShort:
+		return "long"
+	case hasShort && !hasLong:
+		return "short"
+	}
+	return ""
+}
+
 // calculateSharpeRatio 计算夏普比率
 // 基于账户净值的变化计算风险调整后收益
 func (l *DecisionLogger) calculateSharpeRatio(records []*DecisionRecord) float64 {
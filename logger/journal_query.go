@@ -0,0 +1,240 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 日志查询分页默认值
+const (
+	DefaultJournalPageSize = 50
+	MaxJournalPageSize     = 1000
+)
+
+// 交易结果筛选
+const (
+	TradeResultWin       = "win"
+	TradeResultLoss      = "loss"
+	TradeResultBreakeven = "breakeven"
+)
+
+// TradeQuery 历史交易查询条件（零值字段表示不筛选）
+type TradeQuery struct {
+	Symbol string    // 币种，如 BTCUSDT
+	Side   string    // long / short
+	Result string    // win / loss / breakeven
+	From   time.Time // 平仓时间 >= From
+	To     time.Time // 平仓时间 < To
+	SortBy string    // close_time（默认）/ open_time / pnl / pnl_pct / symbol
+	Desc   bool      // 是否倒序
+	Offset int
+	Limit  int // 0=默认50，最大1000
+}
+
+// DecisionQuery 历史决策查询条件（零值字段表示不筛选）
+type DecisionQuery struct {
+	Symbol  string    // 包含该币种动作的决策
+	Action  string    // 包含该动作的决策，如 open_long
+	Success *bool     // 周期是否成功
+	From    time.Time // 决策时间 >= From
+	To      time.Time // 决策时间 < To
+	Desc    bool      // 是否按时间倒序
+	Offset  int
+	Limit   int // 0=默认50，最大1000
+}
+
+// TradePage 交易查询结果
+type TradePage struct {
+	Total  int            `json:"total"`
+	Offset int            `json:"offset"`
+	Limit  int            `json:"limit"`
+	Trades []TradeOutcome `json:"trades"`
+}
+
+// DecisionPage 决策查询结果
+type DecisionPage struct {
+	Total   int               `json:"total"`
+	Offset  int               `json:"offset"`
+	Limit   int               `json:"limit"`
+	Records []*DecisionRecord `json:"records"`
+}
+
+// QueryTrades 查询全部历史中已完全平仓的交易（筛选、排序、分页）
+func (l *DecisionLogger) QueryTrades(q TradeQuery) (*TradePage, error) {
+	records, err := l.GetLatestRecords(math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	return FilterTrades(matchTradeOutcomes(records, make(map[string]*lotPosition), l.lotMatchingMethod()), q)
+}
+
+// QueryDecisions 查询全部历史决策记录（筛选、分页）
+func (l *DecisionLogger) QueryDecisions(q DecisionQuery) (*DecisionPage, error) {
+	records, err := l.GetLatestRecords(math.MaxInt32)
+	if err != nil {
+		return nil, fmt.Errorf("读取历史记录失败: %w", err)
+	}
+	return FilterDecisions(records, q)
+}
+
+// FilterTrades 对交易列表执行筛选、排序和分页
+func FilterTrades(trades []TradeOutcome, q TradeQuery) (*TradePage, error) {
+	less, err := tradeSortFunc(q.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(q.Result) {
+	case "", TradeResultWin, TradeResultLoss, TradeResultBreakeven:
+	default:
+		return nil, fmt.Errorf("不支持的交易结果: %s（可选: win, loss, breakeven）", q.Result)
+	}
+
+	matched := make([]TradeOutcome, 0, len(trades))
+	for _, t := range trades {
+		if q.Symbol != "" && !strings.EqualFold(t.Symbol, q.Symbol) {
+			continue
+		}
+		if q.Side != "" && !strings.EqualFold(t.Side, q.Side) {
+			continue
+		}
+		if !q.From.IsZero() && t.CloseTime.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !t.CloseTime.Before(q.To) {
+			continue
+		}
+		if !matchTradeResult(t.PnL, q.Result) {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if q.Desc {
+			return less(matched[j], matched[i])
+		}
+		return less(matched[i], matched[j])
+	})
+
+	offset, limit := normalizePage(q.Offset, q.Limit)
+	page := &TradePage{Total: len(matched), Offset: offset, Limit: limit, Trades: []TradeOutcome{}}
+	if offset < len(matched) {
+		page.Trades = matched[offset:min(offset+limit, len(matched))]
+	}
+	return page, nil
+}
+
+// FilterDecisions 对决策记录执行筛选和分页（按时间排序）
+func FilterDecisions(records []*DecisionRecord, q DecisionQuery) (*DecisionPage, error) {
+	matched := make([]*DecisionRecord, 0, len(records))
+	for _, r := range records {
+		if !q.From.IsZero() && r.Timestamp.Before(q.From) {
+			continue
+		}
+		if !q.To.IsZero() && !r.Timestamp.Before(q.To) {
+			continue
+		}
+		if q.Success != nil && r.Success != *q.Success {
+			continue
+		}
+		if (q.Symbol != "" || q.Action != "") && !hasMatchingAction(r, q.Symbol, q.Action) {
+			continue
+		}
+		matched = append(matched, r)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if q.Desc {
+			return matched[i].Timestamp.After(matched[j].Timestamp)
+		}
+		return matched[i].Timestamp.Before(matched[j].Timestamp)
+	})
+
+	offset, limit := normalizePage(q.Offset, q.Limit)
+	page := &DecisionPage{Total: len(matched), Offset: offset, Limit: limit, Records: []*DecisionRecord{}}
+	if offset < len(matched) {
+		page.Records = matched[offset:min(offset+limit, len(matched))]
+	}
+	return page, nil
+}
+
+// ParseJournalTime 解析查询时间（支持 2006-01-02 或 RFC3339）
+// endOfDay 为 true 时仅日期格式会被解析为次日零点（用于 "to" 的包含语义）
+func ParseJournalTime(value string, endOfDay bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("无效的时间格式 %q（应为 YYYY-MM-DD 或 RFC3339）", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
+
+// tradeSortFunc 获取交易排序比较函数
+func tradeSortFunc(sortBy string) (func(a, b TradeOutcome) bool, error) {
+	switch sortBy {
+	case "", "close_time":
+		return func(a, b TradeOutcome) bool { return a.CloseTime.Before(b.CloseTime) }, nil
+	case "open_time":
+		return func(a, b TradeOutcome) bool { return a.OpenTime.Before(b.OpenTime) }, nil
+	case "pnl":
+		return func(a, b TradeOutcome) bool { return a.PnL < b.PnL }, nil
+	case "pnl_pct":
+		return func(a, b TradeOutcome) bool { return a.PnLPct < b.PnLPct }, nil
+	case "symbol":
+		return func(a, b TradeOutcome) bool { return a.Symbol < b.Symbol }, nil
+	}
+	return nil, fmt.Errorf("不支持的排序字段: %s（可选: close_time, open_time, pnl, pnl_pct, symbol）", sortBy)
+}
+
+// matchTradeResult 判断交易盈亏是否符合筛选条件
+func matchTradeResult(pnl float64, result string) bool {
+	switch strings.ToLower(result) {
+	case TradeResultWin:
+		return pnl > 0
+	case TradeResultLoss:
+		return pnl < 0
+	case TradeResultBreakeven:
+		return pnl == 0
+	}
+	return true
+}
+
+// hasMatchingAction 判断决策记录中是否包含符合条件的动作
+func hasMatchingAction(r *DecisionRecord, symbol, action string) bool {
+	for _, a := range r.Decisions {
+		if symbol != "" && !strings.EqualFold(a.Symbol, symbol) {
+			continue
+		}
+		if action != "" && a.Action != action {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+// normalizePage 规范化分页参数
+func normalizePage(offset, limit int) (int, int) {
+	if offset < 0 {
+		offset = 0
+	}
+	if limit <= 0 {
+		limit = DefaultJournalPageSize
+	}
+	if limit > MaxJournalPageSize {
+		limit = MaxJournalPageSize
+	}
+	return offset, limit
+}
@@ -0,0 +1,125 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeTestRecords 按时间顺序写入决策记录文件（使用记录自带的时间戳命名）
+func writeTestRecords(t *testing.T, dir string, records []*DecisionRecord) {
+	t.Helper()
+	for i, r := range records {
+		r.CycleNumber = i + 1
+		data, err := json.Marshal(r)
+		if err != nil {
+			t.Fatalf("序列化失败: %v", err)
+		}
+		name := fmt.Sprintf("decision_%s_cycle%d.json", r.Timestamp.Format("20060102_150405"), r.CycleNumber)
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0600); err != nil {
+			t.Fatalf("写入失败: %v", err)
+		}
+	}
+}
+
+func journalFixture(t *testing.T) *DecisionLogger {
+	dir := t.TempDir()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local) // 日期格式按本地时区解析
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	action := func(name, symbol string, price float64, h int) DecisionAction {
+		return DecisionAction{Action: name, Symbol: symbol, Quantity: 1, Leverage: 10, Price: price, Timestamp: at(h), Success: true}
+	}
+
+	writeTestRecords(t, dir, []*DecisionRecord{
+		{Timestamp: at(0), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("open_long", "BTCUSDT", 100, 0)}},
+		{Timestamp: at(1), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("close_long", "BTCUSDT", 110, 1)}}, // 盈利
+		{Timestamp: at(24), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("open_short", "ETHUSDT", 100, 24)}},
+		{Timestamp: at(25), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("close_short", "ETHUSDT", 103, 25)}}, // 亏损
+		{Timestamp: at(48), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("open_long", "BTCUSDT", 100, 48)}},
+		{Timestamp: at(49), Success: true, Exchange: "binance", Decisions: []DecisionAction{action("close_long", "BTCUSDT", 95, 49)}}, // 亏损
+		{Timestamp: at(50), Success: false, Exchange: "binance", ErrorMessage: "AI调用失败"},
+	})
+	return NewDecisionLogger(dir).(*DecisionLogger)
+}
+
+func TestQueryTrades(t *testing.T) {
+	l := journalFixture(t)
+
+	page, err := l.QueryTrades(TradeQuery{})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if page.Total != 3 {
+		t.Fatalf("期望 3 笔交易，实际 %d", page.Total)
+	}
+
+	from, _ := ParseJournalTime("2024-01-02", false)
+	page, err = l.QueryTrades(TradeQuery{Symbol: "btcusdt", Result: TradeResultLoss, From: from})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if page.Total != 1 || page.Trades[0].ClosePrice != 95 {
+		t.Errorf("期望 1 笔 BTC 亏损交易，实际 %+v", page.Trades)
+	}
+
+	// 按盈亏倒序 + 分页
+	page, err = l.QueryTrades(TradeQuery{SortBy: "pnl", Desc: true, Offset: 1, Limit: 1})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if page.Total != 3 || len(page.Trades) != 1 || page.Trades[0].Symbol != "ETHUSDT" {
+		t.Errorf("分页结果错误: %+v", page)
+	}
+
+	// to 为日期时包含当天
+	to, _ := ParseJournalTime("2024-01-01", true)
+	page, _ = l.QueryTrades(TradeQuery{To: to})
+	if page.Total != 1 {
+		t.Errorf("期望 1 笔交易，实际 %d", page.Total)
+	}
+
+	if _, err := l.QueryTrades(TradeQuery{SortBy: "foo"}); err == nil {
+		t.Error("无效排序字段应返回错误")
+	}
+	if _, err := l.QueryTrades(TradeQuery{Result: "lose"}); err == nil {
+		t.Error("无效结果筛选应返回错误")
+	}
+}
+
+func TestQueryDecisions(t *testing.T) {
+	l := journalFixture(t)
+
+	failed := false
+	page, err := l.QueryDecisions(DecisionQuery{Success: &failed})
+	if err != nil {
+		t.Fatalf("查询失败: %v", err)
+	}
+	if page.Total != 1 || page.Records[0].ErrorMessage != "AI调用失败" {
+		t.Errorf("期望 1 条失败记录，实际 %+v", page.Records)
+	}
+
+	page, _ = l.QueryDecisions(DecisionQuery{Symbol: "BTCUSDT", Action: "open_long", Desc: true})
+	if page.Total != 2 || !page.Records[0].Timestamp.After(page.Records[1].Timestamp) {
+		t.Errorf("期望 2 条 BTC 开多记录且按时间倒序，实际 %+v", page.Records)
+	}
+
+	page, _ = l.QueryDecisions(DecisionQuery{Offset: 100})
+	if page.Total != 7 || len(page.Records) != 0 {
+		t.Errorf("超出范围的偏移应返回空页，实际 %+v", page)
+	}
+}
+
+func TestParseJournalTime(t *testing.T) {
+	if ts, err := ParseJournalTime("2024-01-01T08:00:00Z", true); err != nil || !ts.Equal(time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)) {
+		t.Errorf("RFC3339 解析错误: %v %v", ts, err)
+	}
+	if ts, _ := ParseJournalTime("", false); !ts.IsZero() {
+		t.Errorf("空字符串应返回零值，实际 %v", ts)
+	}
+	if _, err := ParseJournalTime("01/02/2024", false); err == nil {
+		t.Error("无效格式应返回错误")
+	}
+}
@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// 默认数字格式参数
+const (
+	defaultAmountDecimals   = 2
+	defaultCompactThreshold = 100000 // 超过 10 万才使用缩写
+	maxPriceDecimals        = 10     // 超低价币最多显示的小数位
+	priceSignificantDigits  = 4      // 价格 < 1 时至少保留的有效数字
+	highPriceThreshold      = 100.0  // 价格 >= 100 时只保留金额小数位
+	zhWan                   = 1e4    // 万
+	zhYi                    = 1e8    // 亿
+)
+
+// NumberFormat 通知、报告和 CSV 导出中的数字格式（零值为不分隔千位、小数点 "."、金额 2 位小数）
+type NumberFormat struct {
+	Locale             string  // 语言（影响缩写单位：zh 使用 万/亿，其余使用 K/M/B）
+	ThousandsSeparator string  // 千位分隔符，如 "," / "." / " "（空 = 不分隔）
+	DecimalSeparator   string  // 小数点（空 = "."）
+	Decimals           int     // 金额小数位（<=0 = 2）
+	Compact            bool    // 大额金额使用缩写（如 1.25M、3.2亿）
+	CompactThreshold   float64 // 绝对值达到该值才缩写（<=0 = 100000）
+}
+
+// NumberFormatForLocale 按语言返回常用数字格式（未知语言返回零值格式）
+func NumberFormatForLocale(locale string) NumberFormat {
+	lang := strings.ToLower(strings.SplitN(strings.ReplaceAll(locale, "_", "-"), "-", 2)[0])
+	switch lang {
+	case "en", "zh", "ja", "ko":
+		return NumberFormat{Locale: lang, ThousandsSeparator: ",", DecimalSeparator: "."}
+	case "de", "es", "it", "id", "pt", "tr", "vi":
+		return NumberFormat{Locale: lang, ThousandsSeparator: ".", DecimalSeparator: ","}
+	case "fr", "ru", "pl", "uk":
+		return NumberFormat{Locale: lang, ThousandsSeparator: " ", DecimalSeparator: ","}
+	default:
+		return NumberFormat{Locale: lang}
+	}
+}
+
+// Amount 格式化金额（USDT 名义价值、盈亏、余额等）
+func (f NumberFormat) Amount(v float64) string {
+	if f.Compact && math.Abs(v) >= f.compactThreshold() {
+		return f.compact(v)
+	}
+	return f.fixed(v, f.decimals())
+}
+
+// SignedAmount 格式化带符号的金额（正数加 "+"，用于盈亏）
+func (f NumberFormat) SignedAmount(v float64) string {
+	return signed(v, f.Amount(v))
+}
+
+// Price 格式化价格：按有效数字动态选择小数位，避免低价币被截断成 0.00
+func (f NumberFormat) Price(v float64) string {
+	return f.fixed(v, priceDecimals(v, f.decimals()))
+}
+
+// Quantity 格式化数量（最多 8 位小数，去掉末尾的 0）
+func (f NumberFormat) Quantity(v float64) string {
+	s := strconv.FormatFloat(v, 'f', 8, 64)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "" || s == "-0" {
+		s = "0"
+	}
+	return f.localize(s)
+}
+
+// Percent 格式化带符号的百分比（固定 2 位小数）
+func (f NumberFormat) Percent(v float64) string {
+	return signed(v, f.fixed(v, 2)) + "%"
+}
+
+// decimals 金额小数位
+func (f NumberFormat) decimals() int {
+	if f.Decimals > 0 {
+		return f.Decimals
+	}
+	return defaultAmountDecimals
+}
+
+// compactThreshold 开始缩写的阈值
+func (f NumberFormat) compactThreshold() float64 {
+	if f.CompactThreshold > 0 {
+		return f.CompactThreshold
+	}
+	return defaultCompactThreshold
+}
+
+// fixed 固定小数位格式化并应用分隔符
+func (f NumberFormat) fixed(v float64, decimals int) string {
+	return f.localize(strconv.FormatFloat(v, 'f', decimals, 64))
+}
+
+// localize 为 strconv 格式的数字插入千位分隔符并替换小数点
+func (f NumberFormat) localize(s string) string {
+	sign := ""
+	if strings.HasPrefix(s, "-") {
+		sign, s = "-", s[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(s, ".")
+
+	if f.ThousandsSeparator != "" && len(intPart) > 3 {
+		var b strings.Builder
+		head := len(intPart) % 3
+		if head > 0 {
+			b.WriteString(intPart[:head])
+		}
+		for i := head; i < len(intPart); i += 3 {
+			if b.Len() > 0 {
+				b.WriteString(f.ThousandsSeparator)
+			}
+			b.WriteString(intPart[i : i+3])
+		}
+		intPart = b.String()
+	}
+
+	if !hasFrac {
+		return sign + intPart
+	}
+	decimal := f.DecimalSeparator
+	if decimal == "" {
+		decimal = "."
+	}
+	return sign + intPart + decimal + fracPart
+}
+
+// compact 大额缩写（保留 2 位小数）
+func (f NumberFormat) compact(v float64) string {
+	abs := math.Abs(v)
+	var scale float64
+	var unit string
+	if f.Locale == "zh" {
+		switch {
+		case abs >= zhYi:
+			scale, unit = zhYi, "亿"
+		default:
+			scale, unit = zhWan, "万"
+		}
+	} else {
+		switch {
+		case abs >= 1e12:
+			scale, unit = 1e12, "T"
+		case abs >= 1e9:
+			scale, unit = 1e9, "B"
+		case abs >= 1e6:
+			scale, unit = 1e6, "M"
+		default:
+			scale, unit = 1e3, "K"
+		}
+	}
+	return f.fixed(v/scale, 2) + unit
+}
+
+// priceDecimals 价格小数位：>= 100 用金额小数位，1~100 用 4 位，< 1 至少保留 4 位有效数字
+func priceDecimals(price float64, amountDecimals int) int {
+	abs := math.Abs(price)
+	switch {
+	case abs == 0:
+		return amountDecimals
+	case abs >= highPriceThreshold:
+		return amountDecimals
+	case abs >= 1:
+		return 4
+	}
+	decimals := int(-math.Floor(math.Log10(abs))) + priceSignificantDigits - 1
+	if decimals > maxPriceDecimals {
+		return maxPriceDecimals
+	}
+	return decimals
+}
+
+// signed 为正数加 "+" 号
+func signed(v float64, formatted string) string {
+	if v > 0 {
+		return "+" + formatted
+	}
+	return formatted
+}
@@ -0,0 +1,47 @@
+package logger
+
+import "testing"
+
+func TestNumberFormat(t *testing.T) {
+	var zero NumberFormat
+	en := NumberFormatForLocale("en-US")
+	de := NumberFormatForLocale("de_DE")
+	fr := NumberFormatForLocale("fr")
+	zhCompact := NumberFormatForLocale("zh-CN")
+	zhCompact.Compact = true
+	enCompact := en
+	enCompact.Compact = true
+
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"零值格式保持原样", zero.Amount(1234567.891), "1234567.89"},
+		{"千位分隔", en.Amount(1234567.891), "1,234,567.89"},
+		{"负数千位分隔", en.SignedAmount(-1234.5), "-1,234.50"},
+		{"正数加号", en.SignedAmount(12.3), "+12.30"},
+		{"德语格式", de.Amount(1234567.891), "1.234.567,89"},
+		{"法语格式", fr.Amount(1234.5), "1 234,50"},
+		{"自定义小数位", NumberFormat{Decimals: 4}.Amount(1.5), "1.5000"},
+		{"高价币价格", en.Price(45678.9123), "45,678.91"},
+		{"中价币价格", zero.Price(23.45678), "23.4568"},
+		{"低价币保留有效数字", zero.Price(0.00002070), "0.00002070"},
+		{"低价币价格", de.Price(0.9954), "0,9954"},
+		{"数量去掉末尾0", en.Quantity(1500.25), "1,500.25"},
+		{"百分比", de.Percent(-3.456), "-3,46%"},
+		{"小于阈值不缩写", enCompact.Amount(99999), "99,999.00"},
+		{"英文缩写", enCompact.Amount(1250000), "1.25M"},
+		{"十亿缩写", enCompact.SignedAmount(3200000000), "+3.20B"},
+		{"中文万", zhCompact.Amount(123456), "12.35万"},
+		{"中文亿", zhCompact.Amount(-320000000), "-3.20亿"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %q, want %q", tt.got, tt.want)
+			}
+		})
+	}
+}
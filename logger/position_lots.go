@@ -0,0 +1,158 @@
+package logger
+
+import (
+	"math"
+	"time"
+)
+
+// 加仓后部分平仓时消耗开仓批次的顺序
+const (
+	LotMatchFIFO = "fifo" // 先开先平（默认）
+	LotMatchLIFO = "lifo" // 后开先平
+)
+
+// closedQtyEpsilon 剩余数量小于该值视为已完全平仓（避免浮点误差）
+const closedQtyEpsilon = 0.0001
+
+// PositionLot 持仓中的一笔开仓批次（首次开仓或加仓）
+type PositionLot struct {
+	EntryPrice  float64   `json:"entry_price"`  // 开仓价
+	Quantity    float64   `json:"quantity"`     // 开仓数量
+	Remaining   float64   `json:"remaining"`    // 未平数量
+	OpenTime    time.Time `json:"open_time"`    // 开仓时间
+	RealizedPnL float64   `json:"realized_pnl"` // 已平部分的盈亏（USDT，已扣手续费）
+}
+
+// lotPosition 按批次追踪的持仓
+type lotPosition struct {
+	side     string
+	leverage int
+	lots     []*PositionLot
+}
+
+// newLotPosition 以首笔开仓创建持仓
+func newLotPosition(side string, leverage int, price, quantity float64, openTime time.Time) *lotPosition {
+	p := &lotPosition{side: side, leverage: leverage}
+	p.add(price, quantity, openTime)
+	return p
+}
+
+// add 加仓（追加一个批次）
+func (p *lotPosition) add(price, quantity float64, openTime time.Time) {
+	p.lots = append(p.lots, &PositionLot{EntryPrice: price, Quantity: quantity, Remaining: quantity, OpenTime: openTime})
+}
+
+// quantity 累计开仓数量
+func (p *lotPosition) quantity() float64 {
+	total := 0.0
+	for _, lot := range p.lots {
+		total += lot.Quantity
+	}
+	return total
+}
+
+// remaining 未平数量
+func (p *lotPosition) remaining() float64 {
+	total := 0.0
+	for _, lot := range p.lots {
+		total += lot.Remaining
+	}
+	return total
+}
+
+// averageEntry 全部批次按开仓数量加权的开仓均价
+func (p *lotPosition) averageEntry() float64 {
+	qty, notional := 0.0, 0.0
+	for _, lot := range p.lots {
+		qty += lot.Quantity
+		notional += lot.Quantity * lot.EntryPrice
+	}
+	if qty <= 0 {
+		return 0
+	}
+	return notional / qty
+}
+
+// realizedPnL 各批次已实现盈亏合计
+func (p *lotPosition) realizedPnL() float64 {
+	total := 0.0
+	for _, lot := range p.lots {
+		total += lot.RealizedPnL
+	}
+	return total
+}
+
+// close 按 method 顺序消耗批次平仓 quantity（<=0 或超过剩余数量时全部平仓），返回本次盈亏（已扣开平仓手续费）
+func (p *lotPosition) close(quantity, price, feeRate float64, method string) float64 {
+	if remaining := p.remaining(); quantity <= 0 || quantity > remaining {
+		quantity = remaining
+	}
+
+	pnl := 0.0
+	for i := range p.lots {
+		lot := p.lots[i]
+		if method == LotMatchLIFO {
+			lot = p.lots[len(p.lots)-1-i]
+		}
+		if quantity <= 0 {
+			break
+		}
+		if lot.Remaining <= 0 {
+			continue
+		}
+
+		take := math.Min(lot.Remaining, quantity)
+		lotPnL := take * (price - lot.EntryPrice)
+		if p.side == "short" {
+			lotPnL = -lotPnL
+		}
+		lotPnL -= take*lot.EntryPrice*feeRate + take*price*feeRate
+
+		lot.Remaining -= take
+		lot.RealizedPnL += lotPnL
+		quantity -= take
+		pnl += lotPnL
+	}
+	return pnl
+}
+
+// closed 是否已完全平仓
+func (p *lotPosition) closed() bool {
+	return p.remaining() <= closedQtyEpsilon
+}
+
+// outcome 生成完全平仓后的交易结果
+func (p *lotPosition) outcome(symbol string, closePrice float64, closeTime time.Time) TradeOutcome {
+	quantity := p.quantity()
+	openPrice := p.averageEntry()
+	openTime := p.lots[0].OpenTime
+	positionValue := quantity * openPrice
+	marginUsed := positionValue / float64(p.leverage)
+	pnl := p.realizedPnL()
+	pnlPct := 0.0
+	if marginUsed > 0 {
+		pnlPct = (pnl / marginUsed) * 100
+	}
+
+	lots := make([]PositionLot, len(p.lots))
+	for i, lot := range p.lots {
+		lots[i] = *lot
+	}
+
+	return TradeOutcome{
+		Symbol:        symbol,
+		Side:          p.side,
+		Quantity:      quantity,
+		Leverage:      p.leverage,
+		OpenPrice:     openPrice,
+		ClosePrice:    closePrice,
+		PositionValue: positionValue,
+		MarginUsed:    marginUsed,
+		PnL:           pnl,
+		PnLPct:        pnlPct,
+		Duration:      closeTime.Sub(openTime).String(),
+		OpenTime:      openTime,
+		CloseTime:     closeTime,
+		Lots:          lots,
+	}
+}
@@ -0,0 +1,135 @@
+package logger
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// scaleInRecords 两笔开仓（100 @ 1.0，110 @ 1.0 加仓）后部分平仓 1.0 @ 120，再全部平仓 @ 130
+func scaleInRecords(base time.Time) []*DecisionRecord {
+	action := func(name string, qty, price float64, offset time.Duration, scaleIn bool) *DecisionRecord {
+		ts := base.Add(offset)
+		return &DecisionRecord{
+			Exchange:  "binance",
+			Timestamp: ts,
+			Success:   true,
+			Decisions: []DecisionAction{{
+				Action: name, Symbol: "SOLUSDT", Quantity: qty, Leverage: 5,
+				Price: price, Timestamp: ts, Success: true, ScaleIn: scaleIn,
+			}},
+		}
+	}
+	return []*DecisionRecord{
+		action("open_long", 1.0, 100, 0, false),
+		action("open_long", 1.0, 110, time.Hour, true),
+		action("partial_close", 1.0, 120, 2*time.Hour, false),
+		action("close_long", 0, 130, 3*time.Hour, false),
+	}
+}
+
+func TestMatchTradeOutcomes_ScaleInLots(t *testing.T) {
+	base := time.Now().Add(-4 * time.Hour)
+	feeRate := getTakerFeeRate("binance")
+	fees := func(entry, exit float64) float64 { return entry*feeRate + exit*feeRate }
+
+	tests := []struct {
+		method      string
+		firstLotPnL float64
+		lastLotPnL  float64
+	}{
+		// FIFO：部分平仓先消耗 100 的批次
+		{LotMatchFIFO, 20 - fees(100, 120), 20 - fees(110, 130)},
+		// LIFO：部分平仓先消耗 110 的批次
+		{LotMatchLIFO, 30 - fees(100, 130), 10 - fees(110, 120)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			trades := matchTradeOutcomes(scaleInRecords(base), make(map[string]*lotPosition), tt.method)
+			if len(trades) != 1 {
+				t.Fatalf("Expected 1 trade after full close, got %d", len(trades))
+			}
+			trade := trades[0]
+			if trade.Quantity != 2 || trade.OpenPrice != 105 {
+				t.Errorf("Expected blended entry 2 @ 105, got %.4f @ %.4f", trade.Quantity, trade.OpenPrice)
+			}
+			if !trade.OpenTime.Equal(base) {
+				t.Errorf("Open time should be the first lot's, got %v", trade.OpenTime)
+			}
+			if len(trade.Lots) != 2 {
+				t.Fatalf("Expected 2 lots, got %d", len(trade.Lots))
+			}
+			if math.Abs(trade.Lots[0].RealizedPnL-tt.firstLotPnL) > 1e-9 || math.Abs(trade.Lots[1].RealizedPnL-tt.lastLotPnL) > 1e-9 {
+				t.Errorf("Unexpected lot PnL: %.6f / %.6f, want %.6f / %.6f",
+					trade.Lots[0].RealizedPnL, trade.Lots[1].RealizedPnL, tt.firstLotPnL, tt.lastLotPnL)
+			}
+			if math.Abs(trade.PnL-(tt.firstLotPnL+tt.lastLotPnL)) > 1e-9 {
+				t.Errorf("Trade PnL should sum lot PnL, got %.6f", trade.PnL)
+			}
+		})
+	}
+}
+
+func TestMatchTradeOutcomes_OpenWithoutScaleInReplaces(t *testing.T) {
+	records := scaleInRecords(time.Now().Add(-4 * time.Hour))
+	records[1].Decisions[0].ScaleIn = false
+
+	trades := matchTradeOutcomes(records, make(map[string]*lotPosition), LotMatchFIFO)
+	if len(trades) != 1 || len(trades[0].Lots) != 1 || trades[0].OpenPrice != 110 {
+		t.Fatalf("Non scale-in open should start a new position, got %+v", trades)
+	}
+}
+
+func TestSetLotMatching(t *testing.T) {
+	l := NewDecisionLogger(t.TempDir()).(*DecisionLogger)
+	if l.lotMatchingMethod() != LotMatchFIFO {
+		t.Errorf("Default lot matching should be FIFO")
+	}
+	if err := l.SetLotMatching(LotMatchLIFO); err != nil || l.lotMatchingMethod() != LotMatchLIFO {
+		t.Errorf("Expected LIFO, err=%v", err)
+	}
+	if err := l.SetLotMatching("average"); err == nil {
+		t.Errorf("Unknown method should be rejected")
+	}
+}
+
+func TestMatchTradeOutcomes_PartialCloseWithBothSides(t *testing.T) {
+	base := time.Now().Add(-4 * time.Hour)
+	action := func(name, side string, qty, price float64, offset time.Duration) *DecisionRecord {
+		ts := base.Add(offset)
+		return &DecisionRecord{
+			Exchange:  "binance",
+			Timestamp: ts,
+			Success:   true,
+			Decisions: []DecisionAction{{
+				Action: name, Symbol: "SOLUSDT", Side: side, Quantity: qty, Leverage: 5,
+				Price: price, Timestamp: ts, Success: true,
+			}},
+		}
+	}
+	records := []*DecisionRecord{
+		action("open_long", "", 1.0, 100, 0),
+		action("open_short", "", 2.0, 110, time.Hour),
+		action("partial_close", "short", 2.0, 105, 2*time.Hour),
+	}
+
+	openPositions := make(map[string]*lotPosition)
+	trades := matchTradeOutcomes(records, openPositions, LotMatchFIFO)
+	if len(trades) != 1 || trades[0].Side != "short" || trades[0].Quantity != 2 {
+		t.Fatalf("partial_close should close the recorded short side, got %+v", trades)
+	}
+	if _, exists := openPositions["SOLUSDT_long"]; !exists {
+		t.Error("Long position should stay open")
+	}
+
+	// 旧记录没有方向且同时持有多空：无法判断，不应默认平多
+	records[2].Decisions[0].Side = ""
+	openPositions = make(map[string]*lotPosition)
+	if trades := matchTradeOutcomes(records, openPositions, LotMatchFIFO); len(trades) != 0 {
+		t.Fatalf("Ambiguous partial_close should be skipped, got %+v", trades)
+	}
+	if len(openPositions["SOLUSDT_long"].lots) != 1 || len(openPositions["SOLUSDT_short"].lots) != 1 {
+		t.Error("Ambiguous partial_close should not consume any lots")
+	}
+}
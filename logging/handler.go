@@ -0,0 +1,103 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+)
+
+// consoleHandler 人类可读格式：保持原有 "2006/01/02 15:04:05 消息" 的样式，属性以 key=value 追加在后
+type consoleHandler struct {
+	mu     *sync.Mutex
+	w      io.Writer
+	attrs  []slog.Attr
+	prefix string // 分组前缀
+}
+
+func newConsoleHandler(w io.Writer) *consoleHandler {
+	return &consoleHandler{mu: &sync.Mutex{}, w: w}
+}
+
+func (h *consoleHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h *consoleHandler) Handle(_ context.Context, r slog.Record) error {
+	var buf bytes.Buffer
+	buf.WriteString(r.Time.Format("2006/01/02 15:04:05 "))
+
+	// 没有图标的警告/错误补上图标，保持与原日志一致的辨识度
+	if !hasIcon(r.Message) {
+		switch {
+		case r.Level >= slog.LevelError:
+			buf.WriteString("❌ ")
+		case r.Level >= slog.LevelWarn:
+			buf.WriteString("⚠️ ")
+		}
+	}
+	buf.WriteString(r.Message)
+
+	writeAttr := func(key string, a slog.Attr) {
+		if key == "module" {
+			return
+		}
+		value := a.Value.Resolve().String()
+		if strings.ContainsAny(value, " \t\n\"=") {
+			value = fmt.Sprintf("%q", value)
+		}
+		buf.WriteString(" " + key + "=" + value)
+	}
+	for _, a := range h.attrs {
+		writeAttr(a.Key, a)
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		writeAttr(h.prefix+a.Key, a)
+		return true
+	})
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf.Bytes())
+	return err
+}
+
+func (h *consoleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		a.Key = h.prefix + a.Key
+		clone.attrs = append(clone.attrs, a)
+	}
+	return &clone
+}
+
+func (h *consoleHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// stripIconHandler json/text 输出时去掉消息行首图标
+type stripIconHandler struct {
+	slog.Handler
+}
+
+func (h *stripIconHandler) Handle(ctx context.Context, r slog.Record) error {
+	stripped := slog.NewRecord(r.Time, r.Level, stripIcon(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		stripped.AddAttrs(a)
+		return true
+	})
+	return h.Handler.Handle(ctx, stripped)
+}
+
+func (h *stripIconHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &stripIconHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h *stripIconHandler) WithGroup(name string) slog.Handler {
+	return &stripIconHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,64 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// now 当前时间（测试时替换）
+var now = time.Now
+
+// Logger 模块日志器（Debug/Info/Warn/Error + key/value 属性）
+type Logger struct {
+	l *slog.Logger
+}
+
+// Module 创建模块日志器（模块名会作为 module 属性输出，并可通过 NOFX_LOG_MODULES 单独设置级别）
+func Module(name string) *Logger {
+	return &Logger{l: slog.New(&moduleHandler{module: name})}
+}
+
+// With 返回携带额外属性的日志器（如 trade_id、symbol）
+func (l *Logger) With(args ...any) *Logger {
+	return &Logger{l: l.l.With(args...)}
+}
+
+// Enabled 该级别是否会输出
+func (l *Logger) Enabled(level slog.Level) bool {
+	return l.l.Enabled(context.Background(), level)
+}
+
+// Debug 调试日志
+func (l *Logger) Debug(msg string, args ...any) { l.l.Debug(msg, args...) }
+
+// Info 普通日志
+func (l *Logger) Info(msg string, args ...any) { l.l.Info(msg, args...) }
+
+// Warn 警告日志
+func (l *Logger) Warn(msg string, args ...any) { l.l.Warn(msg, args...) }
+
+// Error 错误日志
+func (l *Logger) Error(msg string, args ...any) { l.l.Error(msg, args...) }
+
+// hasIcon 消息是否以图标开头
+func hasIcon(msg string) bool {
+	for _, r := range strings.TrimSpace(msg) {
+		return isIconRune(r)
+	}
+	return false
+}
+
+// stripIcon 去掉行首图标和空白（json/text 输出不需要装饰）
+func stripIcon(msg string) string {
+	return strings.TrimLeftFunc(msg, func(r rune) bool {
+		return isIconRune(r) || unicode.IsSpace(r)
+	})
+}
+
+func isIconRune(r rune) bool {
+	// So: emoji 等符号；U+FE0F 变体选择符；U+200D 零宽连接符
+	return unicode.Is(unicode.So, r) || r == 0xFE0F || r == 0x200D
+}
@@ -0,0 +1,207 @@
+// Package logging 基于 log/slog 的结构化日志：
+//   - 支持日志级别、console / text / json 三种输出格式
+//   - 按模块（trader、market ...）设置不同级别
+//   - 通过 With("trade_id", ...) 携带关联ID，同一笔交易的日志可以串起来检索
+//
+// 标准库 log 的输出也会被接管，以 INFO 级别按同样的格式输出。
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 输出格式
+const (
+	FormatConsole = "console" // 人类可读（默认，与原有日志格式一致）
+	FormatText    = "text"    // logfmt（key=value）
+	FormatJSON    = "json"    // 每行一个 JSON 对象（适合 Loki / ELK 采集）
+)
+
+// Config 日志配置
+type Config struct {
+	Level        slog.Level            // 默认级别
+	Format       string                // console / text / json（空=console）
+	ModuleLevels map[string]slog.Level // 模块级别覆盖（如 market=warn）
+	Output       io.Writer             // 输出目标（nil=stderr）
+}
+
+// state 当前生效的配置和 handler
+type state struct {
+	config  Config
+	handler slog.Handler
+}
+
+var (
+	mu      sync.RWMutex
+	current = newState(Config{Level: slog.LevelInfo})
+)
+
+func newState(cfg Config) *state {
+	if cfg.Output == nil {
+		cfg.Output = os.Stderr
+	}
+	if cfg.Format == "" {
+		cfg.Format = FormatConsole
+	}
+	// handler 自身不过滤级别，由 enabled 按模块判断
+	opts := &slog.HandlerOptions{Level: slog.Level(-100)}
+	var h slog.Handler
+	switch cfg.Format {
+	case FormatJSON:
+		h = &stripIconHandler{slog.NewJSONHandler(cfg.Output, opts)}
+	case FormatText:
+		h = &stripIconHandler{slog.NewTextHandler(cfg.Output, opts)}
+	default:
+		h = newConsoleHandler(cfg.Output)
+	}
+	return &state{config: cfg, handler: h}
+}
+
+// Setup 应用日志配置，并接管标准库 log 的输出
+func Setup(cfg Config) error {
+	switch cfg.Format {
+	case "", FormatConsole, FormatText, FormatJSON:
+	default:
+		return fmt.Errorf("不支持的日志格式: %s（可选: console, text, json）", cfg.Format)
+	}
+	s := newState(cfg)
+	mu.Lock()
+	current = s
+	mu.Unlock()
+
+	slog.SetDefault(slog.New(&moduleHandler{}))
+	log.SetFlags(0)
+	log.SetOutput(stdlibBridge{})
+	return nil
+}
+
+// SetOutput 替换输出目标（保持其他配置不变）
+func SetOutput(w io.Writer) {
+	mu.RLock()
+	cfg := current.config
+	mu.RUnlock()
+	cfg.Output = w
+	Setup(cfg)
+}
+
+// ConfigFromEnv 从环境变量读取配置：
+//
+//	NOFX_LOG_LEVEL=debug|info|warn|error
+//	NOFX_LOG_FORMAT=console|text|json
+//	NOFX_LOG_MODULES=market=warn,trader=debug
+func ConfigFromEnv() (Config, error) {
+	cfg := Config{Level: slog.LevelInfo, Format: strings.TrimSpace(os.Getenv("NOFX_LOG_FORMAT"))}
+	if v := strings.TrimSpace(os.Getenv("NOFX_LOG_LEVEL")); v != "" {
+		level, err := ParseLevel(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Level = level
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_LOG_MODULES")); v != "" {
+		cfg.ModuleLevels = make(map[string]slog.Level)
+		for _, item := range strings.Split(v, ",") {
+			module, levelStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+			if !ok {
+				return cfg, fmt.Errorf("NOFX_LOG_MODULES 格式错误: %q（应为 module=level）", item)
+			}
+			level, err := ParseLevel(levelStr)
+			if err != nil {
+				return cfg, err
+			}
+			cfg.ModuleLevels[strings.TrimSpace(module)] = level
+		}
+	}
+	return cfg, nil
+}
+
+// ParseLevel 解析级别名称
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return level, fmt.Errorf("无效的日志级别: %q（可选: debug, info, warn, error）", s)
+	}
+	return level, nil
+}
+
+// enabled 模块在该级别是否输出
+func enabled(module string, level slog.Level) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+	min := current.config.Level
+	if l, ok := current.config.ModuleLevels[module]; ok {
+		min = l
+	}
+	return level >= min
+}
+
+func currentHandler() slog.Handler {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current.handler
+}
+
+// NewTradeID 生成交易关联ID
+func NewTradeID() string {
+	var b [6]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// moduleHandler slog.Default 使用的 handler：把记录转发给当前配置（Setup 后替换输出也能生效）
+type moduleHandler struct {
+	module string
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h *moduleHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return enabled(h.module, level)
+}
+
+func (h *moduleHandler) Handle(ctx context.Context, r slog.Record) error {
+	handler := currentHandler()
+	if h.module != "" {
+		handler = handler.WithAttrs([]slog.Attr{slog.String("module", h.module)})
+	}
+	for _, g := range h.groups {
+		handler = handler.WithGroup(g)
+	}
+	if len(h.attrs) > 0 {
+		handler = handler.WithAttrs(h.attrs)
+	}
+	return handler.Handle(ctx, r)
+}
+
+func (h *moduleHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append(append([]slog.Attr(nil), h.attrs...), attrs...)
+	return &clone
+}
+
+func (h *moduleHandler) WithGroup(name string) slog.Handler {
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
+
+// stdlibBridge 把标准库 log 的每一行转为 INFO 级别的 slog 记录
+type stdlibBridge struct{}
+
+func (stdlibBridge) Write(p []byte) (int, error) {
+	msg := strings.TrimRight(string(p), "\n")
+	if enabled("", slog.LevelInfo) {
+		r := slog.NewRecord(now(), slog.LevelInfo, msg, 0)
+		currentHandler().Handle(context.Background(), r)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,124 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+)
+
+func setupTest(t *testing.T, cfg Config) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	cfg.Output = &buf
+	if err := Setup(cfg); err != nil {
+		t.Fatalf("Setup: %v", err)
+	}
+	origNow := now
+	now = func() time.Time { return time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC) }
+	t.Cleanup(func() {
+		now = origNow
+		Setup(Config{Level: slog.LevelInfo})
+	})
+	return &buf
+}
+
+func TestJSONOutputWithTradeID(t *testing.T) {
+	buf := setupTest(t, Config{Level: slog.LevelInfo, Format: FormatJSON})
+
+	l := Module("trader").With("trade_id", "abc123", "symbol", "BTCUSDT")
+	l.Info("  ✓ 开仓成功", "order_id", int64(42))
+	l.Warn("⚠️ 设置止盈失败", "error", "timeout")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d: %s", len(lines), buf.String())
+	}
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("invalid json: %v", err)
+	}
+	if entry["msg"] != "开仓成功" || entry["module"] != "trader" || entry["trade_id"] != "abc123" ||
+		entry["symbol"] != "BTCUSDT" || entry["order_id"] != float64(42) || entry["level"] != "INFO" {
+		t.Errorf("unexpected entry: %v", entry)
+	}
+	json.Unmarshal([]byte(lines[1]), &entry)
+	if entry["level"] != "WARN" || entry["msg"] != "设置止盈失败" || entry["error"] != "timeout" {
+		t.Errorf("unexpected warn entry: %v", entry)
+	}
+}
+
+func TestModuleLevels(t *testing.T) {
+	buf := setupTest(t, Config{
+		Level:        slog.LevelInfo,
+		Format:       FormatText,
+		ModuleLevels: map[string]slog.Level{"market": slog.LevelWarn, "trader": slog.LevelDebug},
+	})
+
+	Module("market").Info("📡 已连接")
+	Module("market").Error("❌ 连接断开")
+	Module("trader").Debug("检查持仓")
+	Module("pool").Debug("不输出")
+
+	out := buf.String()
+	if strings.Contains(out, "已连接") || strings.Contains(out, "不输出") {
+		t.Errorf("messages below module level were written:\n%s", out)
+	}
+	if !strings.Contains(out, `level=ERROR msg=连接断开 module=market`) || !strings.Contains(out, `level=DEBUG msg=检查持仓 module=trader`) {
+		t.Errorf("missing expected lines:\n%s", out)
+	}
+}
+
+func TestConsoleFormatAndStdlibBridge(t *testing.T) {
+	buf := setupTest(t, Config{Level: slog.LevelInfo})
+
+	Module("trader").With("trade_id", "t1").Warn("保证金不足", "need", "10 USDT")
+	log.Printf("🚨 标准库日志")
+
+	want := "⚠️ 保证金不足 trade_id=t1 need=\"10 USDT\"\n"
+	lines := strings.Split(buf.String(), "\n")
+	if !strings.HasSuffix(lines[0]+"\n", want) {
+		t.Errorf("console line = %q, want suffix %q", lines[0], want)
+	}
+	if lines[1] != "2026/01/02 03:04:05 🚨 标准库日志" {
+		t.Errorf("stdlib bridge line = %q", lines[1])
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("NOFX_LOG_LEVEL", "warn")
+	t.Setenv("NOFX_LOG_FORMAT", "json")
+	t.Setenv("NOFX_LOG_MODULES", "market=error, trader=debug")
+
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv: %v", err)
+	}
+	if cfg.Level != slog.LevelWarn || cfg.Format != FormatJSON ||
+		cfg.ModuleLevels["market"] != slog.LevelError || cfg.ModuleLevels["trader"] != slog.LevelDebug {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("NOFX_LOG_MODULES", "market")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected error for malformed NOFX_LOG_MODULES")
+	}
+	t.Setenv("NOFX_LOG_MODULES", "")
+	t.Setenv("NOFX_LOG_LEVEL", "verbose")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if err := Setup(Config{Format: "xml"}); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestNewTradeID(t *testing.T) {
+	a, b := NewTradeID(), NewTradeID()
+	if len(a) != 12 || a == b {
+		t.Errorf("unexpected trade ids %q %q", a, b)
+	}
+}
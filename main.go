@@ -4,17 +4,31 @@ import (
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"nofx/api"
 	"nofx/auth"
 	"nofx/config"
+	"nofx/control"
 	"nofx/crypto"
+	"nofx/health"
+	"nofx/httprecord"
+	"nofx/logging"
 	"nofx/manager"
 	"nofx/market"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"nofx/notify"
 	"nofx/pool"
+	"nofx/ratelimit"
+	"nofx/secretstore"
+	"nofx/signalrpc"
+	"nofx/store"
 	"os"
 	"os/signal"
+	"slices"
 	"strconv"
 	"strings"
 	"syscall"
@@ -23,49 +37,51 @@ import (
 	"github.com/joho/godotenv"
 )
 
-// ConfigFile 配置文件结构，只包含需要同步到数据库的字段
-// TODO 现在与config.Config相同，未来会被替换， 现在为了兼容性不得不保留当前文件
-type ConfigFile struct {
-	BetaMode           bool                  `json:"beta_mode"`
-	APIServerPort      int                   `json:"api_server_port"`
-	UseDefaultCoins    bool                  `json:"use_default_coins"`
-	DefaultCoins       []string              `json:"default_coins"`
-	CoinPoolAPIURL     string                `json:"coin_pool_api_url"`
-	OITopAPIURL        string                `json:"oi_top_api_url"`
-	MaxDailyLoss       float64               `json:"max_daily_loss"`
-	MaxDrawdown        float64               `json:"max_drawdown"`
-	StopTradingMinutes int                   `json:"stop_trading_minutes"`
-	Leverage           config.LeverageConfig `json:"leverage"`
-	JWTSecret          string                `json:"jwt_secret"`
-	DataKLineTime      string                `json:"data_k_line_time"`
-	Log                *config.LogConfig     `json:"log"` // 日志配置
-}
+// loadConfigFile 读取并解析配置文件（JSON / YAML / TOML，按扩展名判断）
+// path 为 "-" 时从标准输入读取（以 { 开头按 JSON 解析，否则按 YAML 解析，适用于容器内通过管道注入配置）
+func loadConfigFile(path string) (*config.Config, error) {
+	var data []byte
+	var err error
+	var format string
+
+	if path == stdinConfigPath {
+		log.Printf("📄 从标准输入读取配置...")
+		data, err = io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("从标准输入读取配置失败: %w", err)
+		}
+		format = config.FormatYAML
+		if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+			format = config.FormatJSON
+		}
+	} else {
+		// 检查配置文件是否存在
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			log.Printf("📄 %s不存在，使用默认配置", path)
+			return nil, nil
+		}
 
-// loadConfigFile 读取并解析config.json文件
-func loadConfigFile() (*ConfigFile, error) {
-	// 检查config.json是否存在
-	if _, err := os.Stat("config.json"); os.IsNotExist(err) {
-		log.Printf("📄 config.json不存在，使用默认配置")
-		return nil, nil
-	}
+		if format, err = config.FormatFromPath(path); err != nil {
+			return nil, err
+		}
 
-	// 读取config.json
-	data, err := os.ReadFile("config.json")
-	if err != nil {
-		return nil, fmt.Errorf("读取config.json失败: %w", err)
+		// 读取配置文件
+		data, err = os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取%s失败: %w", path, err)
+		}
 	}
 
-	// 解析JSON
-	var configFile ConfigFile
-	if err := json.Unmarshal(data, &configFile); err != nil {
-		return nil, fmt.Errorf("解析config.json失败: %w", err)
+	configFile, err := config.ParseConfig(data, format)
+	if err != nil {
+		return nil, fmt.Errorf("解析%s失败: %w", path, err)
 	}
 
-	return &configFile, nil
+	return configFile, nil
 }
 
 // syncConfigToDatabase 将配置同步到数据库
-func syncConfigToDatabase(database *config.Database, configFile *ConfigFile) error {
+func syncConfigToDatabase(database *config.Database, configFile *config.Config) error {
 	if configFile == nil {
 		return nil
 	}
@@ -114,10 +130,58 @@ func syncConfigToDatabase(database *config.Database, configFile *ConfigFile) err
 		}
 	}
 
+	// 同步交易所账户（配置中为空的密钥字段保留数据库中已保存的值）
+	for _, ex := range configFile.Exchanges {
+		if ex.Owner() == "admin" {
+			if err := database.EnsureAdminUser(); err != nil {
+				log.Printf("⚠️  创建管理员用户失败: %v", err)
+				continue
+			}
+		}
+		if err := database.UpdateExchange(ex.Owner(), ex.ID, ex.IsEnabled(), ex.APIKey, ex.SecretKey, ex.Testnet,
+			ex.HyperliquidWalletAddr, ex.AsterUser, ex.AsterSigner, ex.AsterPrivateKey); err != nil {
+			log.Printf("⚠️  同步交易所 %s/%s 失败: %v", ex.Owner(), ex.ID, err)
+		} else {
+			log.Printf("✓ 同步交易所: %s/%s (启用: %v)", ex.Owner(), ex.ID, ex.IsEnabled())
+		}
+	}
+
 	log.Printf("✅ config.json同步完成")
 	return nil
 }
 
+// configFileExists 配置文件是否存在（标准输入读取的配置无法热更新）
+func configFileExists(path string) bool {
+	if path == stdinConfigPath {
+		return false
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// smtpConfigFromFile 配置文件 notifiers.smtp 转换为 SMTP 发送配置（未配置时返回 nil）
+func smtpConfigFromFile(configFile *config.Config) *notify.SMTPConfig {
+	if configFile == nil || configFile.Notifiers == nil || configFile.Notifiers.SMTP == nil {
+		return nil
+	}
+	smtp := configFile.Notifiers.SMTP
+	cfg := &notify.SMTPConfig{
+		Host:     smtp.Host,
+		Port:     smtp.Port,
+		Username: smtp.Username,
+		Password: smtp.Password,
+		From:     smtp.From,
+		To:       smtp.To,
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	return cfg
+}
+
 // loadBetaCodesToDatabase 加载内测码文件到数据库
 func loadBetaCodesToDatabase(database *config.Database) error {
 	betaCodeFile := "beta_codes.txt"
@@ -176,6 +240,16 @@ func validateSecurityConfig() error {
 }
 
 func main() {
+	// 子命令：nofx backtest / positions / balance / close / export / trades / decisions / keys / help（见 cli.go）
+	// nofx run 或不带子命令时启动交易服务
+	if len(os.Args) > 1 {
+		if os.Args[1] == "run" {
+			os.Args = append(os.Args[:1], os.Args[2:]...)
+		} else if _, ok := lookupCommand(os.Args[1]); ok || os.Args[1] == "help" {
+			os.Exit(runCommand(os.Args[1], os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
 	fmt.Println("╔════════════════════════════════════════════════════════════╗")
 	fmt.Println("║    🤖 AI多模型交易系统 - 支持 DeepSeek & Qwen            ║")
 	fmt.Println("╚════════════════════════════════════════════════════════════╝")
@@ -185,21 +259,84 @@ func main() {
 	// In Docker Compose, variables are injected by the runtime and this is harmless.
 	_ = godotenv.Load()
 
+	// 📝 日志级别 / 格式（NOFX_LOG_LEVEL、NOFX_LOG_FORMAT、NOFX_LOG_MODULES）
+	logConfig, err := logging.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("❌ 日志配置错误: %v", err)
+	}
+	if err := logging.Setup(logConfig); err != nil {
+		log.Fatalf("❌ 日志配置错误: %v", err)
+	}
+
+	// 🚦 交易所接口限流（NOFX_RATE_LIMITS 覆盖默认限额）
+	rateLimits, err := ratelimit.LimitsFromEnv()
+	if err != nil {
+		log.Fatalf("❌ 限流配置错误: %v", err)
+	}
+	ratelimit.Configure(rateLimits)
+	log.Printf("🚦 交易所接口限流: %s", ratelimit.Default.Describe())
+
+	// ⏱️ 决策到下单各阶段耗时预算（NOFX_LATENCY_BUDGETS，超出时告警并计数）
+	latencyBudgets, err := metrics.LatencyBudgetsFromEnv()
+	if err != nil {
+		log.Fatalf("❌ 耗时预算配置错误: %v", err)
+	}
+	metrics.SetLatencyBudgets(latencyBudgets)
+
+	// 📼 交易所 HTTP 请求录制（NOFX_HTTP_RECORD_DIR，用于调试和生成测试夹具）
+	if err := httprecord.ConfigureFromEnv(); err != nil {
+		log.Fatalf("❌ HTTP 录制配置错误: %v", err)
+	}
+
+	// 🌐 交易所代理和接口地址覆盖（NOFX_PROXY、NOFX_BASE_URLS，用于地区限制、区域镜像和公司代理）
+	if err := netconfig.ConfigureFromEnv(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
+	// 📑 OKX 按币种指定合约（NOFX_OKX_INSTRUMENTS，币本位永续/交割合约）
+	if err := market.ConfigureOKXInstrumentsFromEnv(); err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+
 	// 🔐 安全检查：验证必需的环境变量
 	if err := validateSecurityConfig(); err != nil {
 		log.Fatalf("❌ 安全配置检查失败: %v\n\n💡 请运行以下命令修复:\n   ./scripts/setup-env.sh\n", err)
 	}
 
-	// 初始化数据库配置
+	// 🔑 外部密钥提供方（Vault / Doppler / AWS Secrets Manager / 系统钥匙串 / age、GPG 加密文件 / nofx keys 本地加密文件，可选）
+	if err := secretstore.InitFromEnv(); err != nil {
+		log.Fatalf("❌ 初始化外部密钥提供方失败: %v", err)
+	}
+
+	// 命令行参数：nofx [run] [-config path|-] [dbPath]（其他子命令见 cli.go）
+	// 配置文件路径优先级：-config > NOFX_CONFIG_FILE > config.json
+	defaultConfigPath := "config.json"
+	if envPath := strings.TrimSpace(os.Getenv("NOFX_CONFIG_FILE")); envPath != "" {
+		defaultConfigPath = envPath
+	}
+	configPath := flag.String("config", defaultConfigPath, "配置文件路径，\"-\" 表示从标准输入读取")
+	flag.Parse()
+
+	// 初始化数据库配置（优先级：命令行参数 > NOFX_DB_PATH > config.db）
 	dbPath := "config.db"
-	if len(os.Args) > 1 {
-		dbPath = os.Args[1]
+	if envDBPath := strings.TrimSpace(os.Getenv("NOFX_DB_PATH")); envDBPath != "" {
+		dbPath = envDBPath
+	}
+	if flag.NArg() > 0 {
+		dbPath = flag.Arg(0)
 	}
 
-	// 读取配置文件
-	configFile, err := loadConfigFile()
+	// 读取配置文件，并叠加环境变量覆盖
+	configFile, err := loadConfigFile(*configPath)
 	if err != nil {
-		log.Fatalf("❌ 读取config.json失败: %v", err)
+		log.Fatalf("❌ 读取配置文件失败: %v", err)
+	}
+	configFile, err = applyEnvOverrides(configFile)
+	if err != nil {
+		log.Fatalf("❌ 解析配置环境变量失败: %v", err)
+	}
+	if err := configFile.Validate(); err != nil {
+		log.Fatalf("❌ %s: %v", *configPath, err)
 	}
 
 	log.Printf("📋 初始化配置数据库: %s", dbPath)
@@ -333,6 +470,31 @@ func main() {
 	if err != nil {
 		log.Fatalf("❌ 加载交易员失败: %v", err)
 	}
+	if configFile != nil && len(configFile.Strategy) > 0 {
+		if err := traderManager.ApplyRuntimeConfig(configFile); err != nil {
+			log.Fatalf("❌ %s: %v", *configPath, err)
+		}
+	}
+
+	// 配置热更新（NOFX_CONFIG_RELOAD_INTERVAL，如 10s；只接受风控限额、默认币种和策略参数的变更）
+	var configWatcher *config.Watcher
+	if interval := configReloadIntervalFromEnv(); interval > 0 && configFileExists(*configPath) {
+		configWatcher = config.NewWatcher(*configPath, interval, configFile,
+			func() (*config.Config, error) {
+				cfg, err := loadConfigFile(*configPath)
+				if err != nil {
+					return nil, err
+				}
+				return applyEnvOverrides(cfg)
+			},
+			func(cfg *config.Config) error {
+				if err := traderManager.ApplyRuntimeConfig(cfg); err != nil {
+					return err
+				}
+				return syncConfigToDatabase(database, cfg)
+			})
+		go configWatcher.Start()
+	}
 
 	// 获取数据库中的所有交易员配置（用于显示，使用default用户）
 	traders, err := database.GetTraders("default")
@@ -402,32 +564,142 @@ func main() {
 
 	// 创建并启动API服务器
 	apiServer := api.NewServer(traderManager, database, cryptoService, apiPort)
+
+	// 存活/就绪探针（/healthz、/readyz），数据源和 WebSocket 检查在对应组件初始化后注册
+	healthRegistry := health.NewRegistry(healthCheckTimeoutFromEnv())
+	healthRegistry.Register(health.DatabaseCheck("sqlite", database.Ping))
+	healthRegistry.AddSource(health.ExchangeChecks(traderManager))
+	apiServer.SetHealthRegistry(healthRegistry)
 	go func() {
 		if err := apiServer.Start(); err != nil {
 			log.Printf("❌ API服务器错误: %v", err)
 		}
 	}()
 
+	// 运维控制接口（设置 NOFX_CONTROL_ADDR 后启用，必须同时设置 NOFX_CONTROL_TOKEN）
+	var controlServer *control.Server
+	if controlAddr := strings.TrimSpace(os.Getenv("NOFX_CONTROL_ADDR")); controlAddr != "" {
+		controlServer, err = control.NewServer(controlAddr, os.Getenv("NOFX_CONTROL_TOKEN"), control.FromManager(traderManager))
+		if err != nil {
+			log.Fatalf("❌ 初始化控制接口失败: %v（请设置 NOFX_CONTROL_TOKEN）", err)
+		}
+		// 看板实时日志：复制一份 log 输出到内存缓冲
+		logBuffer := control.NewLogBuffer(0)
+		logging.SetOutput(io.MultiWriter(os.Stderr, logBuffer))
+		controlServer.SetLogBuffer(logBuffer)
+		// TradingView 告警（设置 NOFX_TRADINGVIEW_SECRET 后接收 POST /webhook/tradingview）
+		controlServer.SetTradingViewSecret(os.Getenv("NOFX_TRADINGVIEW_SECRET"))
+		go func() {
+			if err := controlServer.Start(); err != nil {
+				log.Printf("❌ 控制接口错误: %v", err)
+			}
+		}()
+	}
+
+	// 外部策略 gRPC 接入（设置 NOFX_SIGNAL_GRPC_ADDR 后启用，必须同时设置 NOFX_SIGNAL_GRPC_TOKEN）
+	var signalServer *signalrpc.Server
+	if signalAddr := strings.TrimSpace(os.Getenv("NOFX_SIGNAL_GRPC_ADDR")); signalAddr != "" {
+		signalServer, err = signalrpc.NewServer(signalAddr, os.Getenv("NOFX_SIGNAL_GRPC_TOKEN"), signalrpc.FromManager(traderManager))
+		if err != nil {
+			log.Fatalf("❌ 初始化外部策略接入服务失败: %v（请设置 NOFX_SIGNAL_GRPC_TOKEN）", err)
+		}
+		go func() {
+			if err := signalServer.Start(); err != nil {
+				log.Printf("❌ 外部策略接入服务错误: %v", err)
+			}
+		}()
+	}
+
+	// Telegram 命令机器人（设置 NOFX_TELEGRAM_BOT_TOKEN 后启用，只响应 NOFX_TELEGRAM_CHAT_IDS 中的 chat，交易员告警也推送到这些 chat）
+	// 未设置环境变量时使用配置文件 notifiers.telegram
+	var telegramBot *control.TelegramBot
+	botToken, chatIDs := strings.TrimSpace(os.Getenv("NOFX_TELEGRAM_BOT_TOKEN")), []int64(nil)
+	if botToken != "" {
+		chatIDs, err = control.ParseChatIDs(os.Getenv("NOFX_TELEGRAM_CHAT_IDS"))
+		if err != nil {
+			log.Fatalf("❌ NOFX_TELEGRAM_CHAT_IDS 配置错误: %v", err)
+		}
+	} else if configFile != nil && configFile.Notifiers != nil && configFile.Notifiers.Telegram != nil {
+		botToken, chatIDs = configFile.Notifiers.Telegram.BotToken, configFile.Notifiers.Telegram.ChatIDs
+	}
+	if botToken != "" {
+		telegramBot, err = control.NewTelegramBot(botToken, chatIDs, control.FromManager(traderManager))
+		if err != nil {
+			log.Fatalf("❌ 初始化Telegram机器人失败: %v", err)
+		}
+		go telegramBot.Start()
+		traderManager.AddAlertSink("telegram", func(subject, body string) error {
+			return telegramBot.Broadcast(subject + "\n" + body)
+		})
+	}
+
+	// 交易汇总邮件（设置 NOFX_SMTP_HOST 后启用，汇总数据来自 NOFX_JOURNAL_DB 事件日志）；交易员告警也通过邮件发送
+	var summaryScheduler *notify.SummaryScheduler
+	smtpConfig, err := notify.SMTPConfigFromEnv()
+	if err != nil {
+		log.Fatalf("❌ SMTP 配置错误: %v", err)
+	}
+	if smtpConfig == nil {
+		smtpConfig = smtpConfigFromFile(configFile)
+	}
+	if smtpConfig != nil {
+		traderManager.AddAlertSink("smtp", notify.NewSMTPNotifier(*smtpConfig).Send)
+		summaryConfig, err := notify.SummaryConfigFromEnv()
+		if err != nil {
+			log.Fatalf("❌ 汇总邮件配置错误: %v", err)
+		}
+		if journalPath := strings.TrimSpace(os.Getenv("NOFX_JOURNAL_DB")); journalPath == "" {
+			log.Printf("⚠️  已配置 SMTP，但未设置 NOFX_JOURNAL_DB，无法生成交易汇总邮件")
+		} else if journal, err := store.OpenJournal(journalPath); err != nil {
+			log.Printf("⚠️  打开交易事件日志失败，交易汇总邮件未启用: %v", err)
+		} else {
+			summaryScheduler = notify.NewSummaryScheduler(notify.NewSMTPNotifier(*smtpConfig), journal, summaryConfig)
+			go summaryScheduler.Start()
+		}
+	}
+
+	// 启用K线本地缓存（设置 NOFX_KLINE_CACHE_DIR 后每个周期只拉取缺失的最新K线）
+	if cacheDir := strings.TrimSpace(os.Getenv("NOFX_KLINE_CACHE_DIR")); cacheDir != "" {
+		if err := market.EnableKlineCache(cacheDir, 0); err != nil {
+			log.Printf("⚠️  启用K线缓存失败: %v", err)
+		}
+	}
+
 	// 初始化多数据源管理器（健康检查间隔: 60秒）
 	log.Println("🌐 初始化多数据源管理器...")
 	dataSourceManager := market.NewDataSourceManager(60 * time.Second)
 
-	// 添加 Binance 数据源
-	binanceSource := market.NewBinanceDataSource()
-	dataSourceManager.AddSource(binanceSource)
-
-	// 添加 Hyperliquid 数据源（主网）
-	hyperliquidSource := market.NewHyperliquidDataSource(false)
-	dataSourceManager.AddSource(hyperliquidSource)
+	// 按配置文件 data_sources 的优先级添加数据源（未配置时使用 Binance + Hyperliquid 主网）
+	dataSourceNames := []string{"binance", "hyperliquid"}
+	if configFile != nil && len(configFile.DataSources) > 0 {
+		dataSourceNames = configFile.DataSources
+	}
+	for _, name := range dataSourceNames {
+		source, err := market.NewDataSourceByName(name)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		dataSourceManager.AddSource(source)
+	}
 
 	// 启动健康检查
 	dataSourceManager.Start()
-	log.Printf("✅ 数据源管理器已启动，包含 %d 个数据源", 2)
+	log.Printf("✅ 数据源管理器已启动，包含 %d 个数据源: %s", len(dataSourceNames), strings.Join(dataSourceNames, ", "))
 
 	// 启动流行情数据 - 默认使用所有交易员设置的币种 如果没有设置币种 则优先使用系统默认
-	// 获取所有活跃 trader 的时间线配置（合并后的并集）
+	// 获取所有活跃 trader 的时间线配置（合并后的并集），再并入配置文件 intervals
 	timeframes := database.GetAllTimeframes()
-	go market.NewWSMonitor(150, timeframes, dataSourceManager).Start(database.GetCustomCoins())
+	if configFile != nil {
+		for _, interval := range configFile.Intervals {
+			if !slices.Contains(timeframes, interval) {
+				timeframes = append(timeframes, interval)
+			}
+		}
+	}
+	wsMonitor := market.NewWSMonitor(150, timeframes, dataSourceManager)
+	go wsMonitor.Start(database.GetCustomCoins())
+	healthRegistry.AddSource(health.DataSourceChecks(dataSourceManager))
+	healthRegistry.Register(health.WebSocketCheck("binance-combined-streams", wsMonitor.Connected))
 	//go market.NewWSMonitor(150, timeframes).Start([]string{}) //这里是一个使用方式 传入空的话 则使用market市场的所有币种
 	// 设置优雅退出
 	sigChan := make(chan os.Signal, 1)
@@ -446,9 +718,17 @@ func main() {
 	fmt.Println()
 	log.Println("📛 收到退出信号，正在优雅关闭...")
 
-	// 步骤 1: 停止所有交易员
-	log.Println("⏸️  停止所有交易员...")
-	traderManager.StopAll()
+	// 再次收到信号时立即退出（不再等待进行中的订单）
+	go func() {
+		<-sigChan
+		log.Println("⚠️  再次收到退出信号，立即退出")
+		os.Exit(1)
+	}()
+
+	// 步骤 1: 停止所有交易员（不再执行新决策，等待进行中的订单完成，按配置保留或撤销止盈止损单）
+	shutdownOpts := shutdownOptionsFromEnv()
+	log.Printf("⏸️  停止所有交易员（最多等待 %v）...", shutdownOpts.DrainTimeout)
+	traderManager.Shutdown(shutdownOpts)
 	log.Println("✅ 所有交易员已停止")
 
 	// 步骤 2: 关闭 API 服务器
@@ -459,11 +739,34 @@ func main() {
 		log.Println("✅ API 服务器已安全关闭")
 	}
 
+	if controlServer != nil {
+		if err := controlServer.Shutdown(); err != nil {
+			log.Printf("⚠️  关闭控制接口时出错: %v", err)
+		}
+	}
+	if signalServer != nil {
+		signalServer.Shutdown()
+	}
+	if telegramBot != nil {
+		telegramBot.Stop()
+	}
+	if summaryScheduler != nil {
+		summaryScheduler.Stop()
+	}
+	if configWatcher != nil {
+		configWatcher.Stop()
+	}
+
 	// 步骤 2.5: 停止数据源管理器
 	log.Println("🌐 停止数据源管理器...")
 	dataSourceManager.Stop()
 	log.Println("✅ 数据源管理器已停止")
 
+	// 步骤 2.6: 刷新并关闭交易事件日志
+	if err := store.CloseJournals(); err != nil {
+		log.Printf("⚠️  %v", err)
+	}
+
 	// 步骤 3: 关闭数据库连接 (确保所有写入完成)
 	log.Println("💾 关闭数据库连接...")
 	if err := database.Close(); err != nil {
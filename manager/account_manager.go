@@ -0,0 +1,305 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/trader"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AccountLimits 单个交易所账户（一组 API Key）的风控上限，零值表示沿用系统全局配置
+// 同一账户下的多个交易员共享账户净值，因此日亏损/回撤按账户计算，触发后该账户下的交易员都会暂停
+type AccountLimits struct {
+	MaxDailyLoss    float64       // 最大日亏损百分比
+	MaxDrawdown     float64       // 最大回撤百分比
+	StopTradingTime time.Duration // 触发风控后暂停时长
+}
+
+func (l AccountLimits) String() string {
+	parts := []string{}
+	if l.MaxDailyLoss > 0 {
+		parts = append(parts, fmt.Sprintf("max_daily_loss=%g", l.MaxDailyLoss))
+	}
+	if l.MaxDrawdown > 0 {
+		parts = append(parts, fmt.Sprintf("max_drawdown=%g", l.MaxDrawdown))
+	}
+	if l.StopTradingTime > 0 {
+		parts = append(parts, fmt.Sprintf("stop_trading=%s", l.StopTradingTime))
+	}
+	if len(parts) == 0 {
+		return "global"
+	}
+	return strings.Join(parts, ",")
+}
+
+// apply 用账户上限覆盖交易员配置中的全局上限
+func (l AccountLimits) apply(cfg *trader.AutoTraderConfig) {
+	if l.MaxDailyLoss > 0 {
+		cfg.MaxDailyLoss = l.MaxDailyLoss
+	}
+	if l.MaxDrawdown > 0 {
+		cfg.MaxDrawdown = l.MaxDrawdown
+	}
+	if l.StopTradingTime > 0 {
+		cfg.StopTradingTime = l.StopTradingTime
+	}
+}
+
+// ParseAccountLimits 解析 "main:max_daily_loss=3,max_drawdown=10;42:max_drawdown=5,stop_trading=2h" 形式的账户风控配置
+// 账户可用交易所配置的显示名称或数字 ID 指定
+func ParseAccountLimits(s string) (map[string]AccountLimits, error) {
+	limits := make(map[string]AccountLimits)
+	for _, entry := range strings.Split(s, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		account, spec, ok := strings.Cut(entry, ":")
+		account = strings.TrimSpace(account)
+		if !ok || account == "" {
+			return nil, fmt.Errorf("无效的账户风控配置: %q（应为 账户:参数=值,...）", entry)
+		}
+		var l AccountLimits
+		for _, field := range strings.Split(spec, ",") {
+			field = strings.TrimSpace(field)
+			if field == "" {
+				continue
+			}
+			key, value, ok := strings.Cut(field, "=")
+			if !ok {
+				return nil, fmt.Errorf("账户 %s 的风控参数无效: %q", account, field)
+			}
+			key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+			switch key {
+			case "max_daily_loss", "max_drawdown":
+				pct, err := strconv.ParseFloat(value, 64)
+				if err != nil || pct <= 0 || pct > 100 {
+					return nil, fmt.Errorf("账户 %s 的 %s 无效: %q（应为 0-100 的百分比）", account, key, value)
+				}
+				if key == "max_daily_loss" {
+					l.MaxDailyLoss = pct
+				} else {
+					l.MaxDrawdown = pct
+				}
+			case "stop_trading":
+				d, err := time.ParseDuration(value)
+				if err != nil || d <= 0 {
+					return nil, fmt.Errorf("账户 %s 的 stop_trading 无效: %q", account, value)
+				}
+				l.StopTradingTime = d
+			default:
+				return nil, fmt.Errorf("账户 %s 的风控参数未知: %q", account, key)
+			}
+		}
+		limits[account] = l
+	}
+	return limits, nil
+}
+
+// accountLimitsFromEnv 读取 NOFX_ACCOUNT_LIMITS（格式错误时忽略，全部账户沿用全局配置）
+func accountLimitsFromEnv() map[string]AccountLimits {
+	v := os.Getenv("NOFX_ACCOUNT_LIMITS")
+	if strings.TrimSpace(v) == "" {
+		return nil
+	}
+	limits, err := ParseAccountLimits(v)
+	if err != nil {
+		log.Printf("⚠️  NOFX_ACCOUNT_LIMITS 无效，全部账户使用全局风控配置: %v", err)
+		return nil
+	}
+	return limits
+}
+
+// Account 一个交易所账户及其下运行的交易员
+type Account struct {
+	ID        int      `json:"id"` // 交易所配置 ID
+	UserID    string   `json:"user_id"`
+	Name      string   `json:"name"`
+	Exchange  string   `json:"exchange"`
+	TraderIDs []string `json:"trader_ids"`
+	Limits    string   `json:"limits"`
+}
+
+// AccountManager 按交易所账户（exchanges 表中的一条配置）分组管理交易员
+// 同一部署可同时运行主账户和子账户（不同 API Key，甚至不同交易所），每个账户有独立的风控上限
+type AccountManager struct {
+	mu            sync.RWMutex
+	limits        map[string]AccountLimits // key: 账户显示名称或 ID
+	accounts      map[int]*Account
+	traderAccount map[string]int // trader ID -> 账户 ID
+}
+
+// NewAccountManager 创建账户管理器（limits 为 nil 时全部账户沿用全局配置）
+func NewAccountManager(limits map[string]AccountLimits) *AccountManager {
+	return &AccountManager{
+		limits:        limits,
+		accounts:      make(map[int]*Account),
+		traderAccount: make(map[string]int),
+	}
+}
+
+// accountName 账户显示名称（未设置时使用交易所名称与 ID）
+func accountName(cfg *config.ExchangeConfig) string {
+	if cfg.DisplayName != "" {
+		return cfg.DisplayName
+	}
+	return fmt.Sprintf("%s#%d", cfg.ExchangeID, cfg.ID)
+}
+
+// LimitsFor 账户的风控上限（按显示名称、ID 依次查找）
+func (m *AccountManager) LimitsFor(cfg *config.ExchangeConfig) (AccountLimits, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if l, ok := m.limits[accountName(cfg)]; ok {
+		return l, true
+	}
+	l, ok := m.limits[strconv.Itoa(cfg.ID)]
+	return l, ok
+}
+
+// Register 记录交易员所属账户，并用账户风控上限覆盖交易员配置
+func (m *AccountManager) Register(traderCfg *trader.AutoTraderConfig, exchangeCfg *config.ExchangeConfig, userID string) {
+	limits, custom := m.LimitsFor(exchangeCfg)
+	if custom {
+		limits.apply(traderCfg)
+		log.Printf("🏦 交易员 %s 使用账户 %s 的风控配置: %s", traderCfg.Name, accountName(exchangeCfg), limits)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeLocked(traderCfg.ID)
+	account, ok := m.accounts[exchangeCfg.ID]
+	if !ok {
+		account = &Account{ID: exchangeCfg.ID, UserID: userID, Exchange: exchangeCfg.ExchangeID}
+		m.accounts[exchangeCfg.ID] = account
+	}
+	account.Name = accountName(exchangeCfg)
+	account.Limits = limits.String()
+	account.TraderIDs = append(account.TraderIDs, traderCfg.ID)
+	sort.Strings(account.TraderIDs)
+	m.traderAccount[traderCfg.ID] = exchangeCfg.ID
+}
+
+// Unregister 移除交易员（账户下没有交易员时一并移除账户）
+func (m *AccountManager) Unregister(traderID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.removeLocked(traderID)
+}
+
+func (m *AccountManager) removeLocked(traderID string) {
+	id, ok := m.traderAccount[traderID]
+	if !ok {
+		return
+	}
+	delete(m.traderAccount, traderID)
+	account := m.accounts[id]
+	for i, tid := range account.TraderIDs {
+		if tid == traderID {
+			account.TraderIDs = append(account.TraderIDs[:i], account.TraderIDs[i+1:]...)
+			break
+		}
+	}
+	if len(account.TraderIDs) == 0 {
+		delete(m.accounts, id)
+	}
+}
+
+// Accounts 账户列表（userID 为空返回全部，按 ID 排序）
+func (m *AccountManager) Accounts(userID string) []Account {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	result := make([]Account, 0, len(m.accounts))
+	for _, a := range m.accounts {
+		if userID != "" && a.UserID != userID {
+			continue
+		}
+		c := *a
+		c.TraderIDs = append([]string(nil), a.TraderIDs...)
+		result = append(result, c)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
+	return result
+}
+
+// AccountReport 账户汇总
+// 同一账户下的交易员看到的是同一个交易所账户，净值与持仓取自其中任一交易员，不重复累加
+type AccountReport struct {
+	Account
+	TotalEquity    float64                  `json:"total_equity"`
+	AvailableUSD   float64                  `json:"available_balance"`
+	UnrealizedPnL  float64                  `json:"unrealized_pnl"`
+	PositionCount  int                      `json:"position_count"`
+	RunningTraders int                      `json:"running_traders"`
+	Traders        []map[string]interface{} `json:"traders"`
+	Error          string                   `json:"error,omitempty"`
+}
+
+// AccountsSummary 全部账户汇总
+type AccountsSummary struct {
+	Accounts      []AccountReport `json:"accounts"`
+	TotalEquity   float64         `json:"total_equity"`
+	UnrealizedPnL float64         `json:"unrealized_pnl"`
+	PositionCount int             `json:"position_count"`
+}
+
+// GetAccountsSummary 按账户汇总净值、持仓和交易员状态（userID 为空汇总全部账户）
+func (tm *TraderManager) GetAccountsSummary(userID string) AccountsSummary {
+	tm.mu.RLock()
+	traders := make(map[string]*trader.AutoTrader, len(tm.traders))
+	for id, t := range tm.traders {
+		traders[id] = t
+	}
+	tm.mu.RUnlock()
+
+	summary := AccountsSummary{Accounts: []AccountReport{}}
+	for _, account := range tm.accounts.Accounts(userID) {
+		report := AccountReport{Account: account, Traders: []map[string]interface{}{}}
+		var lastErr error
+		haveBalance := false
+		for _, id := range account.TraderIDs {
+			t, ok := traders[id]
+			if !ok || t == nil {
+				continue
+			}
+			status := t.GetStatus()
+			if running, _ := status["is_running"].(bool); running {
+				report.RunningTraders++
+			}
+			report.Traders = append(report.Traders, map[string]interface{}{
+				"trader_id":   t.GetID(),
+				"trader_name": t.GetName(),
+				"ai_model":    t.GetAIModel(),
+				"is_running":  status["is_running"],
+				"paused":      t.GetPauseState().Paused,
+			})
+			if haveBalance {
+				continue
+			}
+			info, err := t.GetAccountInfo()
+			if err != nil {
+				lastErr = err
+				continue
+			}
+			haveBalance = true
+			report.TotalEquity, _ = info["total_equity"].(float64)
+			report.AvailableUSD, _ = info["available_balance"].(float64)
+			report.UnrealizedPnL, _ = info["unrealized_profit"].(float64)
+			report.PositionCount, _ = info["position_count"].(int)
+		}
+		if !haveBalance && lastErr != nil {
+			report.Error = lastErr.Error()
+		}
+		summary.TotalEquity += report.TotalEquity
+		summary.UnrealizedPnL += report.UnrealizedPnL
+		summary.PositionCount += report.PositionCount
+		summary.Accounts = append(summary.Accounts, report)
+	}
+	return summary
+}
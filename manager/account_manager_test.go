@@ -0,0 +1,83 @@
+package manager
+
+import (
+	"testing"
+	"time"
+
+	"nofx/config"
+	"nofx/trader"
+)
+
+func TestParseAccountLimits(t *testing.T) {
+	limits, err := ParseAccountLimits("main:max_daily_loss=3,max_drawdown=10; 42:stop_trading=2h")
+	if err != nil {
+		t.Fatalf("ParseAccountLimits() error = %v", err)
+	}
+	if l := limits["main"]; l.MaxDailyLoss != 3 || l.MaxDrawdown != 10 || l.StopTradingTime != 0 {
+		t.Errorf("main = %+v", l)
+	}
+	if l := limits["42"]; l.StopTradingTime != 2*time.Hour || l.MaxDailyLoss != 0 {
+		t.Errorf("42 = %+v", l)
+	}
+	for _, bad := range []string{"main", ":max_drawdown=5", "main:max_drawdown=0", "main:max_drawdown=150", "main:foo=1", "main:stop_trading=abc", "main:max_drawdown"} {
+		if _, err := ParseAccountLimits(bad); err == nil {
+			t.Errorf("ParseAccountLimits(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestAccountManagerRegister(t *testing.T) {
+	m := NewAccountManager(map[string]AccountLimits{
+		"main": {MaxDailyLoss: 3},
+		"7":    {MaxDrawdown: 5, StopTradingTime: time.Hour},
+	})
+	main := &config.ExchangeConfig{ID: 1, ExchangeID: "binance", DisplayName: "main"}
+	sub := &config.ExchangeConfig{ID: 7, ExchangeID: "binance"}
+	other := &config.ExchangeConfig{ID: 9, ExchangeID: "hyperliquid"}
+
+	cfgA := trader.AutoTraderConfig{ID: "a", MaxDailyLoss: 10, MaxDrawdown: 20}
+	cfgB := trader.AutoTraderConfig{ID: "b", MaxDailyLoss: 10, MaxDrawdown: 20}
+	cfgC := trader.AutoTraderConfig{ID: "c", MaxDailyLoss: 10, MaxDrawdown: 20}
+	cfgD := trader.AutoTraderConfig{ID: "d", MaxDailyLoss: 10, MaxDrawdown: 20}
+	m.Register(&cfgA, main, "u1")
+	m.Register(&cfgB, main, "u1")
+	m.Register(&cfgC, sub, "u1")
+	m.Register(&cfgD, other, "u2")
+
+	if cfgA.MaxDailyLoss != 3 || cfgA.MaxDrawdown != 20 {
+		t.Errorf("按显示名称匹配的账户上限未生效: %+v", cfgA)
+	}
+	if cfgC.MaxDailyLoss != 10 || cfgC.MaxDrawdown != 5 || cfgC.StopTradingTime != time.Hour {
+		t.Errorf("按 ID 匹配的账户上限未生效: %+v", cfgC)
+	}
+	if cfgD.MaxDailyLoss != 10 || cfgD.MaxDrawdown != 20 {
+		t.Errorf("未配置的账户应沿用全局上限: %+v", cfgD)
+	}
+
+	accounts := m.Accounts("u1")
+	if len(accounts) != 2 || accounts[0].Name != "main" || len(accounts[0].TraderIDs) != 2 || accounts[1].Name != "binance#7" {
+		t.Fatalf("Accounts(u1) = %+v", accounts)
+	}
+	if len(m.Accounts("")) != 3 {
+		t.Errorf("Accounts(\"\") 应返回全部账户")
+	}
+
+	// 重新加载交易员不重复计入；移除最后一个交易员时移除账户
+	m.Register(&cfgA, main, "u1")
+	m.Unregister("c")
+	accounts = m.Accounts("u1")
+	if len(accounts) != 1 || len(accounts[0].TraderIDs) != 2 {
+		t.Errorf("Accounts(u1) after reload/unregister = %+v", accounts)
+	}
+}
+
+func TestGetAccountsSummaryWithoutLoadedTraders(t *testing.T) {
+	tm := NewTraderManager()
+	cfg := trader.AutoTraderConfig{ID: "ghost"}
+	tm.accounts.Register(&cfg, &config.ExchangeConfig{ID: 3, ExchangeID: "aster"}, "u1")
+
+	summary := tm.GetAccountsSummary("u1")
+	if len(summary.Accounts) != 1 || len(summary.Accounts[0].Traders) != 0 || summary.TotalEquity != 0 {
+		t.Errorf("GetAccountsSummary() = %+v", summary)
+	}
+}
@@ -0,0 +1,81 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/trader"
+	"os"
+	"strings"
+	"sync"
+)
+
+// alertSeverityRank 告警级别排序
+var alertSeverityRank = map[string]int{
+	trader.AlertSeverityInfo:     1,
+	trader.AlertSeverityWarning:  2,
+	trader.AlertSeverityCritical: 3,
+}
+
+// AlertSink 告警通知渠道（Telegram、SMTP 邮件等），subject 为一行摘要，body 为完整内容
+type AlertSink func(subject, body string) error
+
+// alertRouter 把所有交易员的告警转发到已注册的通知渠道
+type alertRouter struct {
+	minSeverity string // 低于此级别的告警只记录日志
+
+	mu    sync.RWMutex
+	names []string
+	sinks []AlertSink
+}
+
+// alertMinSeverityFromEnv 读取 NOFX_ALERT_MIN_SEVERITY（INFO / WARNING / CRITICAL，默认 WARNING）
+func alertMinSeverityFromEnv() string {
+	v := strings.ToUpper(strings.TrimSpace(os.Getenv("NOFX_ALERT_MIN_SEVERITY")))
+	if v == "" {
+		return trader.AlertSeverityWarning
+	}
+	if _, ok := alertSeverityRank[v]; !ok {
+		log.Printf("⚠️  NOFX_ALERT_MIN_SEVERITY=%q 无效（支持 INFO、WARNING、CRITICAL），使用 WARNING", v)
+		return trader.AlertSeverityWarning
+	}
+	return v
+}
+
+// AddAlertSink 注册告警通知渠道（对已加载和之后加载的交易员都生效）
+func (tm *TraderManager) AddAlertSink(name string, sink AlertSink) {
+	r := &tm.alerts
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.names = append(r.names, name)
+	r.sinks = append(r.sinks, sink)
+	log.Printf("🔔 交易员告警将推送到 %s（%s 及以上级别）", name, r.minSeverity)
+}
+
+// dispatchAlert 交易员的 AlertHandler：异步推送到各通知渠道，不阻塞交易流程
+func (tm *TraderManager) dispatchAlert(alert trader.Alert) {
+	r := &tm.alerts
+	if alertSeverityRank[alert.Severity] < alertSeverityRank[r.minSeverity] {
+		return
+	}
+	r.mu.RLock()
+	names := append([]string(nil), r.names...)
+	sinks := append([]AlertSink(nil), r.sinks...)
+	r.mu.RUnlock()
+
+	subject, body := formatAlert(alert)
+	for i, sink := range sinks {
+		go func(name string, sink AlertSink) {
+			if err := sink(subject, body); err != nil {
+				log.Printf("⚠️  推送告警到 %s 失败 [%s]: %v", name, alert.Title, err)
+			}
+		}(names[i], sink)
+	}
+}
+
+// formatAlert 告警的摘要和正文
+func formatAlert(alert trader.Alert) (string, string) {
+	subject := fmt.Sprintf("[%s] %s: %s", alert.Severity, alert.TraderName, alert.Title)
+	body := fmt.Sprintf("%s\n\n交易员: %s (%s)\n时间: %s", alert.Message, alert.TraderName, alert.TraderID,
+		alert.Timestamp.UTC().Format("2006-01-02 15:04:05 UTC"))
+	return subject, body
+}
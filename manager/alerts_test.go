@@ -0,0 +1,64 @@
+package manager
+
+import (
+	"errors"
+	"nofx/config"
+	"nofx/trader"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAlertMinSeverityFromEnv(t *testing.T) {
+	if got := alertMinSeverityFromEnv(); got != trader.AlertSeverityWarning {
+		t.Errorf("默认级别 = %s, want WARNING", got)
+	}
+	t.Setenv("NOFX_ALERT_MIN_SEVERITY", "critical")
+	if got := alertMinSeverityFromEnv(); got != trader.AlertSeverityCritical {
+		t.Errorf("级别 = %s, want CRITICAL", got)
+	}
+	t.Setenv("NOFX_ALERT_MIN_SEVERITY", "loud")
+	if got := alertMinSeverityFromEnv(); got != trader.AlertSeverityWarning {
+		t.Errorf("无效级别应回退到 WARNING, got %s", got)
+	}
+}
+
+// TestTraderAlertsReachSinks 通过管理器创建的交易员配置，告警推送到已注册的通知渠道
+func TestTraderAlertsReachSinks(t *testing.T) {
+	tm := NewTraderManager()
+	cfg := trader.AutoTraderConfig{ID: "alert-trader", Exchange: "binance"}
+	if err := tm.applyTraderOptions(&cfg, &config.ExchangeConfig{ID: 1, ExchangeID: "binance"}, "u1"); err != nil {
+		t.Fatalf("applyTraderOptions 失败: %v", err)
+	}
+	if cfg.AlertHandler == nil {
+		t.Fatal("交易员配置应设置 AlertHandler")
+	}
+
+	// 渠道可以在交易员创建之后注册（Telegram/SMTP 在加载交易员之后初始化）
+	sent := make(chan string, 4)
+	tm.AddAlertSink("test", func(subject, body string) error {
+		sent <- subject + "\n" + body
+		return nil
+	})
+	tm.AddAlertSink("broken", func(subject, body string) error { return errors.New("smtp down") })
+
+	cfg.AlertHandler(trader.Alert{TraderID: "alert-trader", TraderName: "alpha", Severity: trader.AlertSeverityInfo, Title: "提示"})
+	cfg.AlertHandler(trader.Alert{
+		TraderID: "alert-trader", TraderName: "alpha", Severity: trader.AlertSeverityCritical,
+		Title: "止损单设置失败", Message: "BTCUSDT long 止损单重试 3 次后仍失败", Timestamp: time.Date(2026, 10, 14, 8, 0, 0, 0, time.UTC),
+	})
+
+	select {
+	case msg := <-sent:
+		if !strings.HasPrefix(msg, "[CRITICAL] alpha: 止损单设置失败\n") || !strings.Contains(msg, "重试 3 次后仍失败") || !strings.Contains(msg, "2026-10-14 08:00:00 UTC") {
+			t.Errorf("告警内容错误: %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("告警未推送到通知渠道")
+	}
+	select {
+	case msg := <-sent:
+		t.Errorf("低于 WARNING 的告警不应推送: %q", msg)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,56 @@
+package manager
+
+import (
+	"fmt"
+	"log"
+	"nofx/config"
+	"nofx/trader"
+	"sort"
+)
+
+// runtimeStrategyParams 配置文件中作用于运行中交易员的策略参数（风控限额 + strategy 段）
+func runtimeStrategyParams(cfg *config.Config) map[string]float64 {
+	params := map[string]float64{
+		"max_daily_loss":    cfg.MaxDailyLoss,
+		"max_drawdown":      cfg.MaxDrawdown,
+		"stop_trading_time": float64(cfg.StopTradingMinutes * 60),
+	}
+	for name, value := range cfg.Strategy {
+		params[name] = value
+	}
+	return params
+}
+
+// ApplyRuntimeConfig 把热更新后的配置应用到所有已加载的交易员（风控限额、默认币种、策略参数）
+// 参数先整体校验，任一参数无效时不修改任何交易员
+func (tm *TraderManager) ApplyRuntimeConfig(cfg *config.Config) error {
+	if cfg == nil {
+		return nil
+	}
+	params := runtimeStrategyParams(cfg)
+	if err := trader.ValidateStrategyParams(params); err != nil {
+		return fmt.Errorf("策略参数无效: %w", err)
+	}
+
+	traders := tm.GetAllTraders()
+	ids := make([]string, 0, len(traders))
+	for id := range traders {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	for _, id := range ids {
+		at := traders[id]
+		if len(cfg.DefaultCoins) > 0 {
+			at.SetDefaultCoins(cfg.DefaultCoins)
+		}
+		changes, err := at.ApplyStrategyParams(params, "config", "配置热更新")
+		if err != nil {
+			return fmt.Errorf("交易员 %s 应用配置失败: %w", at.GetName(), err)
+		}
+		if len(changes) > 0 {
+			log.Printf("🔄 交易员 %s 已应用 %d 项配置变更", at.GetName(), len(changes))
+		}
+	}
+	return nil
+}
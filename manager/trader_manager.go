@@ -6,7 +6,11 @@ import (
 	"fmt"
 	"log"
 	"nofx/config"
+	"nofx/logger"
+	"nofx/market"
+	"nofx/secretstore"
 	"nofx/trader"
+	"os"
 	"sort"
 	"strconv"
 	"strings"
@@ -24,6 +28,8 @@ type CompetitionCache struct {
 // TraderManager 管理多个trader实例
 type TraderManager struct {
 	traders          map[string]*trader.AutoTrader // key: trader ID
+	accounts         *AccountManager               // 交易员按交易所账户分组（账户级风控与汇总）
+	alerts           alertRouter                   // 交易员告警的通知渠道
 	competitionCache *CompetitionCache
 	mu               sync.RWMutex
 }
@@ -31,7 +37,9 @@ type TraderManager struct {
 // NewTraderManager 创建trader管理器
 func NewTraderManager() *TraderManager {
 	return &TraderManager{
-		traders: make(map[string]*trader.AutoTrader),
+		traders:  make(map[string]*trader.AutoTrader),
+		accounts: NewAccountManager(accountLimitsFromEnv()),
+		alerts:   alertRouter{minSeverity: alertMinSeverityFromEnv()},
 		competitionCache: &CompetitionCache{
 			data: make(map[string]interface{}),
 		},
@@ -264,6 +272,11 @@ func (tm *TraderManager) addTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		traderConfig.DeepSeekKey = aiModelCfg.APIKey
 	}
 
+	// 环境变量中的全局交易选项、账户注册和密钥引用（secret://KEY）解析（风控配置错误时拒绝创建）
+	if err := tm.applyTraderOptions(&traderConfig, exchangeCfg, userID); err != nil {
+		return fmt.Errorf("配置交易员 %s 失败: %w", traderCfg.Name, err)
+	}
+
 	// 创建trader实例
 	at, err := trader.NewAutoTrader(traderConfig, database, userID)
 	if err != nil {
@@ -378,6 +391,11 @@ func (tm *TraderManager) AddTraderFromDB(traderCfg *config.TraderRecord, aiModel
 		traderConfig.DeepSeekKey = aiModelCfg.APIKey
 	}
 
+	// 环境变量中的全局交易选项、账户注册和密钥引用（secret://KEY）解析（风控配置错误时拒绝创建）
+	if err := tm.applyTraderOptions(&traderConfig, exchangeCfg, userID); err != nil {
+		return fmt.Errorf("配置交易员 %s 失败: %w", traderCfg.Name, err)
+	}
+
 	// 创建trader实例
 	at, err := trader.NewAutoTrader(traderConfig, database, userID)
 	if err != nil {
@@ -468,6 +486,7 @@ func (tm *TraderManager) RemoveTrader(traderID string) error {
 
 	// 从map中删除
 	delete(tm.traders, traderID)
+	tm.accounts.Unregister(traderID)
 	log.Printf("✅ 已从内存中移除交易员: %s", traderID)
 
 	// 清除竞赛缓存，强制下次重新计算
@@ -555,6 +574,23 @@ func (tm *TraderManager) StopAll() {
 	}
 }
 
+// Shutdown 并行优雅停止所有Trader（等待进行中的订单完成，见 trader.ShutdownOptions）
+func (tm *TraderManager) Shutdown(opts trader.ShutdownOptions) {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	log.Printf("⏹  优雅停止 %d 个Trader...", len(tm.traders))
+	var wg sync.WaitGroup
+	for _, t := range tm.traders {
+		wg.Add(1)
+		go func(t *trader.AutoTrader) {
+			defer wg.Done()
+			t.Shutdown(opts)
+		}(t)
+	}
+	wg.Wait()
+}
+
 // GetComparisonData 获取对比数据
 func (tm *TraderManager) GetComparisonData() (map[string]interface{}, error) {
 	tm.mu.RLock()
@@ -1195,6 +1231,11 @@ func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiMode
 		traderConfig.DeepSeekKey = aiModelCfg.APIKey
 	}
 
+	// 环境变量中的全局交易选项、账户注册和密钥引用（secret://KEY）解析（风控配置错误时拒绝创建）
+	if err := tm.applyTraderOptions(&traderConfig, exchangeCfg, userID); err != nil {
+		return nil, fmt.Errorf("配置交易员 %s 失败: %w", traderCfg.Name, err)
+	}
+
 	// 创建trader实例
 	at, err := trader.NewAutoTrader(traderConfig, database, userID)
 	if err != nil {
@@ -1214,3 +1255,229 @@ func (tm *TraderManager) loadSingleTrader(traderCfg *config.TraderRecord, aiMode
 
 	return at, nil
 }
+
+// applyTraderOptions 为新建的交易员配置填充环境变量中的全局交易选项，登记所属账户，
+// 并解析外部密钥提供方中的密钥引用（三处创建交易员的入口共用）。
+// 风控配置无效时返回错误，不能静默关闭保护后继续创建交易员
+func (tm *TraderManager) applyTraderOptions(traderConfig *trader.AutoTraderConfig, exchangeCfg *config.ExchangeConfig, userID string) error {
+	var err error
+	if traderConfig.CorrelationGroups, err = trader.CorrelationGroupsFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.ProtectionFailurePolicy, traderConfig.ProtectionRetryCount, err = trader.ProtectionPolicyFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.TradingSchedule, err = trader.TradingScheduleFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.MaxSlippageBps, err = maxSlippageBpsFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.PriceSanitySources, traderConfig.PriceSanityMaxDeviationPct, err = priceSanityFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.DailyFlattenTime, traderConfig.DailyFlattenTimezone, traderConfig.FlattenWarningMinutes, err = trader.DailyFlattenFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.StopRules, err = trader.StopRulesFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.MaxHolding, err = trader.MaxHoldingFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.MarginRatioDangerPct, traderConfig.DeleveragePct, err = trader.MarginWatchdogFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.ConfirmDelaySeconds, traderConfig.ConfirmMaxDeviationPct, err = trader.TradeConfirmationFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.FundingCostThresholdBps, traderConfig.FundingFilterMode, err = trader.FundingFilterFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.MaxPriceAgeMs, err = trader.MaxPriceAgeFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.BracketTemplates, traderConfig.SymbolClasses, err = trader.BracketTemplatesFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.ScaleOut, err = trader.ScaleOutFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.ListingWatch, err = trader.ListingWatchFromEnv(); err != nil {
+		return err
+	}
+	if traderConfig.RetryPolicy, err = trader.RetryPolicyFromEnv(); err != nil {
+		return fmt.Errorf("NOFX_RETRY_POLICY 配置错误: %w", err)
+	}
+
+	// 交易对精度快照目录（可选，冷启动加速）
+	traderConfig.PrecisionSnapshotDir = os.Getenv("NOFX_PRECISION_SNAPSHOT_DIR")
+	traderConfig.JournalPath = os.Getenv("NOFX_JOURNAL_DB")
+	traderConfig.ReconcilePolicy = os.Getenv("NOFX_RECONCILE_POLICY")
+	traderConfig.ExchangeTimeout = exchangeTimeoutFromEnv()
+	traderConfig.NumberFormat = numberFormatFromEnv()
+	traderConfig.ScheduleMode, traderConfig.CandleCloseGraceSeconds = scheduleModeFromEnv()
+	traderConfig.AllowScaleIn, traderConfig.LotMatching = scaleInFromEnv()
+	traderConfig.Pyramiding = pyramidingFromEnv()
+	traderConfig.DecisionProviders = decisionProvidersFromEnv()
+	traderConfig.SymbolWorkers = symbolWorkersFromEnv()
+	traderConfig.InstrumentRefreshInterval = instrumentRefreshIntervalFromEnv()
+	traderConfig.EquitySnapshotInterval = equitySnapshotIntervalFromEnv()
+	traderConfig.Universe = universeFromEnv()
+	traderConfig.AlertHandler = tm.dispatchAlert
+	tm.accounts.Register(traderConfig, exchangeCfg, userID)
+
+	return resolveSecretRefs(traderConfig)
+}
+
+// resolveSecretRefs 将交易员配置中的 secret:// 引用替换为外部密钥提供方中的实际值
+func resolveSecretRefs(cfg *trader.AutoTraderConfig) error {
+	return secretstore.ResolveFields(
+		&cfg.BinanceAPIKey,
+		&cfg.BinanceSecretKey,
+		&cfg.HyperliquidPrivateKey,
+		&cfg.AsterPrivateKey,
+		&cfg.DeepSeekKey,
+		&cfg.QwenKey,
+	)
+}
+
+// exchangeTimeoutFromEnv 读取 NOFX_EXCHANGE_TIMEOUT（单次交易所调用超时，如 "15s"；"0" 或 "off" 不限时，未设置使用默认值）
+func exchangeTimeoutFromEnv() time.Duration {
+	v := strings.TrimSpace(os.Getenv("NOFX_EXCHANGE_TIMEOUT"))
+	if v == "" {
+		return 0
+	}
+	if v == "0" || strings.EqualFold(v, "off") {
+		return -1
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil || d <= 0 {
+		log.Printf("⚠️  NOFX_EXCHANGE_TIMEOUT=%q 无效，使用默认值 %v", v, trader.DefaultCallTimeout)
+		return 0
+	}
+	return d
+}
+
+// maxSlippageBpsFromEnv 读取 NOFX_MAX_SLIPPAGE_BPS（开仓市价单最大滑点，基点；未设置时不限制）
+func maxSlippageBpsFromEnv() (float64, error) {
+	v := strings.TrimSpace(os.Getenv("NOFX_MAX_SLIPPAGE_BPS"))
+	if v == "" {
+		return 0, nil
+	}
+	bps, err := strconv.ParseFloat(v, 64)
+	if err != nil || bps < 0 {
+		return 0, fmt.Errorf("NOFX_MAX_SLIPPAGE_BPS 必须为非负数: %q", v)
+	}
+	return bps, nil
+}
+
+// priceSanityFromEnv 读取 NOFX_PRICE_SANITY_SOURCES（逗号分隔的数据源名称）和 NOFX_PRICE_SANITY_MAX_DEVIATION（百分比）
+func priceSanityFromEnv() ([]string, float64, error) {
+	var sources []string
+	for _, name := range strings.Split(os.Getenv("NOFX_PRICE_SANITY_SOURCES"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			sources = append(sources, name)
+		}
+	}
+
+	v := strings.TrimSpace(os.Getenv("NOFX_PRICE_SANITY_MAX_DEVIATION"))
+	if v == "" {
+		return sources, 0, nil
+	}
+	pct, err := strconv.ParseFloat(v, 64)
+	if err != nil || pct <= 0 {
+		return nil, 0, fmt.Errorf("NOFX_PRICE_SANITY_MAX_DEVIATION 必须为正数: %q", v)
+	}
+	return sources, pct, nil
+}
+
+// scheduleModeFromEnv 读取交易周期的调度模式（配置错误时按扫描间隔调度）
+func scheduleModeFromEnv() (string, int) {
+	mode, grace, err := trader.ScheduleModeFromEnv()
+	if err != nil {
+		log.Printf("⚠️  调度模式配置无效，按扫描间隔调度: %v", err)
+		return "", 0
+	}
+	return mode, grace
+}
+
+// scaleInFromEnv 读取加仓配置（配置错误时拒绝加仓，按 FIFO 统计批次盈亏）
+func scaleInFromEnv() (bool, string) {
+	allow, method, err := trader.ScaleInFromEnv()
+	if err != nil {
+		log.Printf("⚠️  加仓配置无效，拒绝同向加仓: %v", err)
+		return false, ""
+	}
+	return allow, method
+}
+
+// numberFormatFromEnv 读取告警/通知中的数字格式（配置错误时使用默认格式）
+func numberFormatFromEnv() logger.NumberFormat {
+	nf, err := trader.NumberFormatFromEnv()
+	if err != nil {
+		log.Printf("⚠️  数字格式配置无效，使用默认格式: %v", err)
+		return logger.NumberFormat{}
+	}
+	return nf
+}
+
+// pyramidingFromEnv 读取顺势加仓配置（NOFX_PYRAMID_*，配置错误时不开启）
+func pyramidingFromEnv() *trader.Pyramiding {
+	p, err := trader.PyramidingFromEnv()
+	if err != nil {
+		log.Printf("⚠️  顺势加仓配置无效，不开启: %v", err)
+		return nil
+	}
+	return p
+}
+
+// decisionProvidersFromEnv 读取决策来源配置（NOFX_DECISION_*，配置错误时只用 AI 决策）
+func decisionProvidersFromEnv() *trader.DecisionProviders {
+	p, err := trader.DecisionProvidersFromEnv()
+	if err != nil {
+		log.Printf("⚠️  决策来源配置无效，只使用 AI 决策: %v", err)
+		return nil
+	}
+	return p
+}
+
+// symbolWorkersFromEnv 读取 NOFX_SYMBOL_WORKERS（配置错误时按顺序执行决策）
+func symbolWorkersFromEnv() int {
+	n, err := trader.SymbolWorkersFromEnv()
+	if err != nil {
+		log.Printf("⚠️  NOFX_SYMBOL_WORKERS 无效，按顺序执行决策: %v", err)
+		return 0
+	}
+	return n
+}
+
+// instrumentRefreshIntervalFromEnv 读取 NOFX_INSTRUMENT_REFRESH_INTERVAL（格式错误时不定时刷新）
+func instrumentRefreshIntervalFromEnv() time.Duration {
+	d, err := trader.InstrumentRefreshIntervalFromEnv()
+	if err != nil {
+		log.Printf("⚠️  NOFX_INSTRUMENT_REFRESH_INTERVAL 无效，不定时刷新交易规则: %v", err)
+		return 0
+	}
+	return d
+}
+
+// equitySnapshotIntervalFromEnv 读取 NOFX_EQUITY_SNAPSHOT_INTERVAL（格式错误时不记录净值快照）
+func equitySnapshotIntervalFromEnv() time.Duration {
+	d, err := trader.EquitySnapshotIntervalFromEnv()
+	if err != nil {
+		log.Printf("⚠️  NOFX_EQUITY_SNAPSHOT_INTERVAL 无效，不记录净值快照: %v", err)
+		return 0
+	}
+	return d
+}
+
+// universeFromEnv 读取动态币种池配置（NOFX_UNIVERSE_*，配置错误时使用系统默认币种）
+func universeFromEnv() *market.UniverseConfig {
+	cfg, err := trader.UniverseFromEnv()
+	if err != nil {
+		log.Printf("⚠️  动态币种池配置无效，使用系统默认币种: %v", err)
+		return nil
+	}
+	return cfg
+}
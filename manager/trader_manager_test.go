@@ -1,7 +1,11 @@
 package manager
 
 import (
+	"nofx/config"
 	"nofx/trader"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -774,3 +778,121 @@ func TestGetTopTradersData(t *testing.T) {
 
 	t.Logf("✅ GetTopTradersData returned valid data structure")
 }
+
+// TestApplyTraderOptions 测试创建交易员时从环境变量填充的全局交易选项
+func TestApplyTraderOptions(t *testing.T) {
+	t.Setenv("NOFX_CORRELATION_GROUPS", "btc_beta_alts:30:SOLUSDT,AVAXUSDT,ARBUSDT")
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "flatten")
+	brackets := filepath.Join(t.TempDir(), "brackets.json")
+	if err := os.WriteFile(brackets, []byte(`{"major": {"stop_distance_pct": 1.5}}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NOFX_BRACKET_TEMPLATES", brackets)
+	t.Setenv("NOFX_SYMBOL_CLASSES", "SOLUSDT:major")
+	t.Setenv("NOFX_DAILY_FLATTEN_TIME", "15:45")
+	t.Setenv("NOFX_SCHEDULE_MODE", "candle_close")
+	t.Setenv("NOFX_ALLOW_SCALE_IN", "true")
+	t.Setenv("NOFX_LOT_MATCHING", "lifo")
+	t.Setenv("NOFX_NUMBER_LOCALE", "fr")
+	t.Setenv("NOFX_DAILY_FLATTEN_TIMEZONE", "America/New_York")
+	t.Setenv("NOFX_MARGIN_RATIO_DANGER_PCT", "85")
+	t.Setenv("NOFX_CONFIRM_DELAY_SECONDS", "10")
+	t.Setenv("NOFX_FUNDING_FILTER_MODE", "reverse")
+	t.Setenv("NOFX_MAX_PRICE_AGE_MS", "off")
+
+	tm := NewTraderManager()
+	exchangeCfg := &config.ExchangeConfig{ID: 1, ExchangeID: "binance"}
+	cfg := trader.AutoTraderConfig{ID: "opts-trader", Exchange: "binance"}
+	if err := tm.applyTraderOptions(&cfg, exchangeCfg, "u1"); err != nil {
+		t.Fatalf("applyTraderOptions 失败: %v", err)
+	}
+	if len(cfg.CorrelationGroups) != 1 || cfg.CorrelationGroups[0].MaxExposurePct != 30 || len(cfg.CorrelationGroups[0].Symbols) != 3 {
+		t.Errorf("相关性分组未生效: %+v", cfg.CorrelationGroups)
+	}
+	if cfg.BracketTemplates["major"].StopDistancePct != 1.5 || cfg.SymbolClasses["SOLUSDT"] != "major" {
+		t.Errorf("出场模板未生效: %+v %+v", cfg.BracketTemplates, cfg.SymbolClasses)
+	}
+	if cfg.DailyFlattenTime != "15:45" || cfg.DailyFlattenTimezone != "America/New_York" {
+		t.Errorf("收盘平仓未生效: %q %q", cfg.DailyFlattenTime, cfg.DailyFlattenTimezone)
+	}
+	if cfg.ScheduleMode != trader.ScheduleModeCandleClose {
+		t.Errorf("调度模式未生效: %q", cfg.ScheduleMode)
+	}
+	if !cfg.AllowScaleIn || cfg.LotMatching != "lifo" {
+		t.Errorf("加仓配置未生效: %v %q", cfg.AllowScaleIn, cfg.LotMatching)
+	}
+	if cfg.NumberFormat.ThousandsSeparator != " " || cfg.NumberFormat.DecimalSeparator != "," {
+		t.Errorf("数字格式未生效: %+v", cfg.NumberFormat)
+	}
+	if cfg.ProtectionFailurePolicy != trader.ProtectionPolicyFlatten {
+		t.Errorf("止损保护策略未生效: %q", cfg.ProtectionFailurePolicy)
+	}
+	if cfg.MarginRatioDangerPct != 85 {
+		t.Errorf("保证金率危险阈值未生效: %v", cfg.MarginRatioDangerPct)
+	}
+	if cfg.ConfirmDelaySeconds != 10 {
+		t.Errorf("开仓确认等待未生效: %v", cfg.ConfirmDelaySeconds)
+	}
+	if cfg.FundingFilterMode != trader.FundingFilterReverse {
+		t.Errorf("资金费率过滤模式未生效: %q", cfg.FundingFilterMode)
+	}
+	if cfg.MaxPriceAgeMs >= 0 {
+		t.Errorf("价格时效检查应关闭: %v", cfg.MaxPriceAgeMs)
+	}
+	if accounts := tm.accounts.Accounts("u1"); len(accounts) != 1 || accounts[0].TraderIDs[0] != "opts-trader" {
+		t.Errorf("交易员应登记到账户: %+v", accounts)
+	}
+
+	// 风控配置错误时拒绝创建交易员，不登记到账户
+	t.Setenv("NOFX_CORRELATION_GROUPS", "btc_beta_alts:30")
+	cfg = trader.AutoTraderConfig{ID: "opts-trader-2", Exchange: "binance"}
+	if err := tm.applyTraderOptions(&cfg, exchangeCfg, "u1"); err == nil || !strings.Contains(err.Error(), "NOFX_CORRELATION_GROUPS") {
+		t.Errorf("无效的相关性分组应返回错误: %v", err)
+	}
+	if accounts := tm.accounts.Accounts("u1"); len(accounts[0].TraderIDs) != 1 {
+		t.Errorf("配置错误的交易员不应登记到账户: %+v", accounts)
+	}
+
+	// 止损保护策略拼写错误时不能回退到 retry
+	t.Setenv("NOFX_CORRELATION_GROUPS", "")
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "flaten")
+	cfg = trader.AutoTraderConfig{ID: "opts-trader-3", Exchange: "binance"}
+	if err := tm.applyTraderOptions(&cfg, exchangeCfg, "u1"); err == nil || !strings.Contains(err.Error(), "NOFX_PROTECTION_FAILURE_POLICY") {
+		t.Errorf("无效的止损保护策略应返回错误: %v", err)
+	}
+
+	// 交易时段配置错误时不能全天开仓
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "")
+	t.Setenv("NOFX_TRADING_SESSIONS", "mon-fri 25:00-26:00")
+	cfg = trader.AutoTraderConfig{ID: "opts-trader-4", Exchange: "binance"}
+	if err := tm.applyTraderOptions(&cfg, exchangeCfg, "u1"); err == nil || !strings.Contains(err.Error(), "NOFX_TRADING_SESSIONS") {
+		t.Errorf("无效的交易时段应返回错误: %v", err)
+	}
+
+	// 其余风控配置错误时同样拒绝创建，不能静默关闭保护
+	t.Setenv("NOFX_TRADING_SESSIONS", "")
+	for _, env := range []struct{ key, value string }{
+		{"NOFX_MAX_SLIPPAGE_BPS", "-5"},
+		{"NOFX_PRICE_SANITY_MAX_DEVIATION", "abc"},
+		{"NOFX_DAILY_FLATTEN_TIME", "25:99"},
+		{"NOFX_STOP_RULES", "bogus"},
+		{"NOFX_MAX_HOLDING", "forever"},
+		{"NOFX_MARGIN_RATIO_DANGER_PCT", "150"},
+		{"NOFX_DELEVERAGE_PCT", "0"},
+		{"NOFX_CONFIRM_DELAY_SECONDS", "-1"},
+		{"NOFX_FUNDING_FILTER_MODE", "hedge"},
+		{"NOFX_MAX_PRICE_AGE_MS", "2s"},
+		{"NOFX_SYMBOL_CLASSES", "SOLUSDT:unknown"},
+		{"NOFX_SCALE_OUT", "1R:150"},
+		{"NOFX_LISTING_WATCH_INTERVAL", "hourly"},
+		{"NOFX_RETRY_POLICY", "max_attempts=0"},
+	} {
+		t.Run(env.key, func(t *testing.T) {
+			t.Setenv(env.key, env.value)
+			cfg := trader.AutoTraderConfig{ID: "opts-trader-5", Exchange: "binance"}
+			if err := tm.applyTraderOptions(&cfg, exchangeCfg, "u1"); err == nil {
+				t.Errorf("%s=%q 应返回错误", env.key, env.value)
+			}
+		})
+	}
+}
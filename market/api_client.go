@@ -1,12 +1,15 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"net/url"
+	"nofx/exchangehttp"
 	"nofx/hook"
+	"nofx/netconfig"
+	"nofx/ratelimit"
 	"strconv"
 	"time"
 )
@@ -17,18 +20,21 @@ const (
 
 var baseURL = defaultBaseURL
 
+// apiBaseURL Binance 合约 REST 地址（可由 NOFX_BASE_URLS 的 binance 覆盖）
+func apiBaseURL() string {
+	return netconfig.BaseURL("binance", baseURL)
+}
+
 type APIClient struct {
 	client *http.Client
 }
 
 func NewAPIClient() *APIClient {
-	client := &http.Client{
-		Timeout: 60 * time.Second, // Increased from 30s to 60s
-	}
+	client := exchangehttp.NewHTTPClient("binance", ratelimit.BinanceGroup("binance"), 60*time.Second) // Increased from 30s to 60s
 
 	hookRes := hook.HookExec[hook.SetHttpClientResult](hook.SET_HTTP_CLIENT, client)
 	if hookRes != nil && hookRes.Error() == nil {
-		log.Printf("Using HTTP client from Hook")
+		log.Info("Using HTTP client from Hook")
 		client = hookRes.GetResult()
 	}
 
@@ -37,125 +43,109 @@ func NewAPIClient() *APIClient {
 	}
 }
 
-func (c *APIClient) GetExchangeInfo() (*ExchangeInfo, error) {
-	url := fmt.Sprintf("%s/fapi/v1/exchangeInfo", baseURL)
-	resp, err := c.client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
+// get 请求币安合约公开接口并解析响应（临时错误和限流按 marketRetry 重试）
+func (c *APIClient) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	api := &exchangehttp.Client{HTTP: c.client, BaseURL: apiBaseURL(), Decode: decodeBinanceResponse, Retry: marketRetry}
+	return api.Do(ctx, &exchangehttp.Request{Method: http.MethodGet, Path: path, Query: params, Idempotent: true}, out)
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+// decodeBinanceResponse 解析币安响应：错误响应 {code, msg} 返回 *BinanceErrorResponse（限流时 HTTP 200 也可能返回），
+// 其余非 2xx 返回 *exchangehttp.StatusError
+func decodeBinanceResponse(status int, body []byte, out interface{}) error {
+	var binanceErr BinanceErrorResponse
+	if json.Unmarshal(body, &binanceErr) == nil && binanceErr.Code != 0 {
+		return &binanceErr
 	}
+	return exchangehttp.DecodeJSON(status, body, out)
+}
+
+func (c *APIClient) GetExchangeInfo(ctx context.Context) (*ExchangeInfo, error) {
 	var exchangeInfo ExchangeInfo
-	err = json.Unmarshal(body, &exchangeInfo)
-	if err != nil {
+	if err := c.get(ctx, "/fapi/v1/exchangeInfo", nil, &exchangeInfo); err != nil {
 		return nil, err
 	}
-
 	return &exchangeInfo, nil
 }
 
-func (c *APIClient) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
-	const maxRetries = 3
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		klines, err := c.getKlinesAttempt(symbol, interval, limit, attempt)
-		if err == nil {
-			return klines, nil
-		}
-
-		lastErr = err
-		if attempt < maxRetries {
-			backoff := time.Duration(attempt) * 2 * time.Second
-			log.Printf("⚠️ GetKlines attempt %d/%d failed for %s: %v, retrying in %v...",
-				attempt, maxRetries, symbol, err, backoff)
-			time.Sleep(backoff)
-		}
+func (c *APIClient) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	// 启用本地K线缓存时只拉取缺失的尾部
+	klines, err := fetchKlinesCached(ctx, "binance", c.getLatestKlines, symbol, interval, limit)
+	if err == nil {
+		return checkKlineIntegrityWith(ctx, "Binance", c, symbol, interval, klines), nil
 	}
 
 	// 如果所有重试都失败，尝试从多数据源管理器获取（故障转移）
 	if WSMonitorCli != nil && WSMonitorCli.dsManager != nil {
-		log.Printf("⚠️  Binance API 失败，尝试从多数据源池获取 %s %s 数据...", symbol, interval)
-		klines, err := WSMonitorCli.dsManager.GetKlinesWithFallback(symbol, interval, limit)
-		if err == nil {
-			log.Printf("✅ 故障转移成功：从备用数据源获取 %s %s 数据", symbol, interval)
+		log.Warn("⚠️ Binance API 失败，尝试从多数据源池获取数据...", "symbol", symbol, "interval", interval)
+		klines, fallbackErr := WSMonitorCli.dsManager.GetKlinesWithFallback(ctx, symbol, interval, limit)
+		if fallbackErr == nil {
+			log.Info("✅ 故障转移成功：从备用数据源获取数据", "symbol", symbol, "interval", interval)
 			return klines, nil
 		}
-		log.Printf("⚠️  多数据源池也失败: %v", err)
+		log.Warn("⚠️ 多数据源池也失败", "error", fallbackErr)
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
+	return nil, err
 }
 
-func (c *APIClient) getKlinesAttempt(symbol, interval string, limit int, attempt int) ([]Kline, error) {
-	url := fmt.Sprintf("%s/fapi/v1/klines", baseURL)
-	req, err := http.NewRequest("GET", url, nil)
+// getLatestKlines 从 Binance 获取最新 limit 根K线（临时错误按 marketRetry 重试）
+func (c *APIClient) getLatestKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("limit", strconv.Itoa(limit))
+
+	klines, err := c.requestKlines(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("create request failed: %w", err)
+		return nil, err
+	}
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("no valid K-line data returned")
 	}
 
-	q := req.URL.Query()
-	q.Add("symbol", symbol)
-	q.Add("interval", interval)
-	q.Add("limit", strconv.Itoa(limit))
-	req.URL.RawQuery = q.Encode()
+	return klines, nil
+}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
-	}
-	defer resp.Body.Close()
+// GetKlinesRange 获取 endTime（毫秒）之前、不早于 startTime 的最新至多 limit 根K线（按时间正序）
+func (c *APIClient) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	params.Set("endTime", strconv.FormatInt(endTime, 10))
+	params.Set("limit", strconv.Itoa(limit))
 
-	body, err := io.ReadAll(resp.Body)
+	klines, err := c.requestKlines(ctx, params)
 	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %w", err)
-	}
-
-	// Check HTTP status code first
-	if resp.StatusCode != http.StatusOK {
-		// Try to parse as Binance error response
-		var binanceErr BinanceErrorResponse
-		if json.Unmarshal(body, &binanceErr) == nil && binanceErr.Code != 0 {
-			log.Printf("❌ Binance API error for %s (attempt %d): %v", symbol, attempt, &binanceErr)
-			return nil, &binanceErr
-		}
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, err
 	}
 
-	// Try to parse as error response first (in case of rate limit)
-	var binanceErr BinanceErrorResponse
-	if json.Unmarshal(body, &binanceErr) == nil && binanceErr.Code != 0 {
-		log.Printf("❌ Binance API error for %s (attempt %d): %v", symbol, attempt, &binanceErr)
-		return nil, &binanceErr
+	// 只传 endTime 时返回截至 endTime 的最新K线，过滤掉早于 startTime 的部分
+	start := 0
+	for start < len(klines) && klines[start].OpenTime < startTime {
+		start++
 	}
+	return klines[start:], nil
+}
 
-	// Parse as normal kline response
+// requestKlines 请求 /fapi/v1/klines 并解析（无有效K线时返回空切片）
+func (c *APIClient) requestKlines(ctx context.Context, params url.Values) ([]Kline, error) {
+	symbol := params.Get("symbol")
 	var klineResponses []KlineResponse
-	err = json.Unmarshal(body, &klineResponses)
-	if err != nil {
-		log.Printf("❌ Failed to parse K-line data for %s (attempt %d), response: %s",
-			symbol, attempt, string(body[:min(200, len(body))]))
-		return nil, fmt.Errorf("parse K-line JSON failed: %w", err)
+	if err := c.get(ctx, "/fapi/v1/klines", params, &klineResponses); err != nil {
+		log.Error("❌ Binance GetKlines 失败", "symbol", symbol, "error", err)
+		return nil, err
 	}
 
 	var klines []Kline
 	for i, kr := range klineResponses {
 		kline, err := parseKline(kr)
 		if err != nil {
-			log.Printf("⚠️ Failed to parse K-line entry %d for %s: %v", i, symbol, err)
+			log.Warn("⚠️ Failed to parse K-line entry", "index", i, "symbol", symbol, "error", err)
 			continue
 		}
 		klines = append(klines, kline)
 	}
 
-	if len(klines) == 0 {
-		return nil, fmt.Errorf("no valid K-line data returned")
-	}
-
 	return klines, nil
 }
 
@@ -194,31 +184,9 @@ func parseKline(kr KlineResponse) (Kline, error) {
 	return kline, nil
 }
 
-func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
-	url := fmt.Sprintf("%s/fapi/v1/ticker/price", baseURL)
-	req, err := http.NewRequest("GET", url, nil)
-	if err != nil {
-		return 0, err
-	}
-
-	q := req.URL.Query()
-	q.Add("symbol", symbol)
-	req.URL.RawQuery = q.Encode()
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return 0, err
-	}
-	defer resp.Body.Close()
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return 0, err
-	}
-
+func (c *APIClient) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
 	var ticker PriceTicker
-	err = json.Unmarshal(body, &ticker)
-	if err != nil {
+	if err := c.get(ctx, "/fapi/v1/ticker/price", url.Values{"symbol": {symbol}}, &ticker); err != nil {
 		return 0, err
 	}
 
@@ -230,106 +198,232 @@ func (c *APIClient) GetCurrentPrice(symbol string) (float64, error) {
 	return price, nil
 }
 
-// GetOpenInterest 获取持仓量（P0修复：用于OI历史数据采集）
-func (c *APIClient) GetOpenInterest(symbol string) (*OIData, error) {
-	url := fmt.Sprintf("%s/fapi/v1/openInterest?symbol=%s", baseURL, symbol)
+// binanceDepthLimits Binance 深度接口支持的档数
+var binanceDepthLimits = []int{5, 10, 20, 50, 100, 500, 1000}
 
-	resp, err := c.client.Get(url)
-	if err != nil {
+// GetOrderBook 获取前 depth 档盘口（请求不小于 depth 的最小合法档数后截断）
+func (c *APIClient) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	limit := binanceDepthLimits[len(binanceDepthLimits)-1]
+	for _, l := range binanceDepthLimits {
+		if l >= depth {
+			limit = l
+			break
+		}
+	}
+
+	var result struct {
+		TransactionTime int64      `json:"T"`
+		Bids            [][]string `json:"bids"`
+		Asks            [][]string `json:"asks"`
+	}
+	params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, "/fapi/v1/depth", params, &result); err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	bids, err := parseOrderBookLevels(result.Bids)
 	if err != nil {
 		return nil, err
 	}
+	asks, err := parseOrderBookLevels(result.Asks)
+	if err != nil {
+		return nil, err
+	}
+
+	book := &OrderBook{Symbol: symbol, Bids: bids, Asks: asks, Timestamp: result.TransactionTime}
+	return book.truncate(depth), nil
+}
 
+// GetFundingRate 获取当前（预测）资金费率
+func (c *APIClient) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
 	var result struct {
-		OpenInterest string `json:"openInterest"`
-		Symbol       string `json:"symbol"`
-		Time         int64  `json:"time"`
+		Symbol          string `json:"symbol"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
 	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
+	if err := c.get(ctx, "/fapi/v1/premiumIndex", url.Values{"symbol": {symbol}}, &result); err != nil {
 		return nil, err
 	}
 
-	oi, _ := strconv.ParseFloat(result.OpenInterest, 64)
+	rate, err := strconv.ParseFloat(result.LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse funding rate failed: %w", err)
+	}
 
-	return &OIData{
-		Latest:       oi,
-		Average:      oi * 0.999, // 近似平均值
-		ActualPeriod: "snapshot", // 標記為快照數據，非計算值
+	return &FundingRate{
+		Symbol:      symbol,
+		Rate:        rate,
+		FundingTime: result.NextFundingTime,
 	}, nil
 }
 
-// GetOpenInterestHistory retrieves historical OI data (for backfilling on startup)
-// period: "5m", "15m", "30m", "1h", "2h", "4h", "6h", "12h", "1d"
-// limit: default 30, max 500 (we need 20 15-minute data points = 5 hours)
-func (c *APIClient) GetOpenInterestHistory(symbol string, period string, limit int) ([]OISnapshot, error) {
-	const maxRetries = 3
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		snapshots, err := c.getOpenInterestHistoryAttempt(symbol, period, limit, attempt)
-		if err == nil {
-			return snapshots, nil
-		}
+// GetFundingRateHistory 获取最近 limit 次已结算的资金费率（按时间正序）
+func (c *APIClient) GetFundingRateHistory(ctx context.Context, symbol string, limit int) ([]FundingRate, error) {
+	var result []struct {
+		Symbol      string `json:"symbol"`
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	params := url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, "/fapi/v1/fundingRate", params, &result); err != nil {
+		return nil, err
+	}
 
-		lastErr = err
-		if attempt < maxRetries {
-			backoff := time.Duration(attempt) * 2 * time.Second
-			log.Printf("⚠️ GetOpenInterestHistory attempt %d/%d failed for %s: %v, retrying in %v...",
-				attempt, maxRetries, symbol, err, backoff)
-			time.Sleep(backoff)
+	history := make([]FundingRate, 0, len(result))
+	for _, item := range result {
+		rate, err := strconv.ParseFloat(item.FundingRate, 64)
+		if err != nil {
+			continue
 		}
+		history = append(history, FundingRate{
+			Symbol:      item.Symbol,
+			Rate:        rate,
+			FundingTime: item.FundingTime,
+		})
 	}
 
-	return nil, fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
+	return history, nil
 }
 
-func (c *APIClient) getOpenInterestHistoryAttempt(symbol string, period string, limit int, attempt int) ([]OISnapshot, error) {
-	url := fmt.Sprintf("%s/futures/data/openInterestHist", baseURL)
-	req, err := http.NewRequest("GET", url, nil)
+// PremiumIndex 标记价格与指数价格（/fapi/v1/premiumIndex）
+type PremiumIndex struct {
+	Symbol     string
+	MarkPrice  float64
+	IndexPrice float64
+	Time       int64
+}
+
+// GetPremiumIndex 获取当前标记价格和指数价格
+func (c *APIClient) GetPremiumIndex(ctx context.Context, symbol string) (*PremiumIndex, error) {
+	var result struct {
+		Symbol     string `json:"symbol"`
+		MarkPrice  string `json:"markPrice"`
+		IndexPrice string `json:"indexPrice"`
+		Time       int64  `json:"time"`
+	}
+	if err := c.get(ctx, "/fapi/v1/premiumIndex", url.Values{"symbol": {symbol}}, &result); err != nil {
+		return nil, err
+	}
+
+	markPrice, err := strconv.ParseFloat(result.MarkPrice, 64)
 	if err != nil {
-		return nil, fmt.Errorf("create request failed: %w", err)
+		return nil, fmt.Errorf("parse mark price failed: %w", err)
+	}
+	indexPrice, err := strconv.ParseFloat(result.IndexPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse index price failed: %w", err)
 	}
 
-	q := req.URL.Query()
-	q.Add("symbol", symbol)
-	q.Add("period", period)
-	q.Add("limit", strconv.Itoa(limit))
-	req.URL.RawQuery = q.Encode()
+	return &PremiumIndex{Symbol: result.Symbol, MarkPrice: markPrice, IndexPrice: indexPrice, Time: result.Time}, nil
+}
 
-	resp, err := c.client.Do(req)
+// GetBasisHistory 获取标记价格K线和指数价格K线，按开盘时间对齐为基差序列（按时间正序）
+func (c *APIClient) GetBasisHistory(ctx context.Context, symbol, interval string, limit int) ([]BasisPoint, error) {
+	mark, err := c.getPriceKlines(ctx, "/fapi/v1/markPriceKlines", "symbol", symbol, interval, limit)
 	if err != nil {
-		return nil, fmt.Errorf("HTTP request failed: %w", err)
+		return nil, fmt.Errorf("get mark price klines failed: %w", err)
+	}
+	index, err := c.getPriceKlines(ctx, "/fapi/v1/indexPriceKlines", "pair", symbol, interval, limit)
+	if err != nil {
+		return nil, fmt.Errorf("get index price klines failed: %w", err)
+	}
+	return joinBasisSeries(mark, index), nil
+}
+
+// getPriceKlines 获取标记价格/指数价格K线的开盘时间和收盘价
+// 返回格式与普通K线相同：[openTime, open, high, low, close, ...]
+func (c *APIClient) getPriceKlines(ctx context.Context, path, symbolParam, symbol, interval string, limit int) ([]pricePoint, error) {
+	var rows [][]interface{}
+	params := url.Values{symbolParam: {symbol}, "interval": {interval}, "limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, path, params, &rows); err != nil {
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
+	points := make([]pricePoint, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 5 {
+			continue
+		}
+		openTime, ok := row[0].(float64)
+		if !ok {
+			continue
+		}
+		closeStr, _ := row[4].(string)
+		closePrice, err := strconv.ParseFloat(closeStr, 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, pricePoint{Timestamp: int64(openTime), Price: closePrice})
+	}
+	return points, nil
+}
+
+// GetOpenInterest 获取持仓量（P0修复：用于OI历史数据采集）
+func (c *APIClient) GetOpenInterest(ctx context.Context, symbol string) (*OIData, error) {
+	snapshot, err := c.GetOpenInterestSnapshot(ctx, symbol)
 	if err != nil {
-		return nil, fmt.Errorf("read response body failed: %w", err)
+		return nil, err
+	}
+
+	return &OIData{
+		Latest:       snapshot.Value,
+		Average:      snapshot.Value * 0.999, // 近似平均值
+		ActualPeriod: "snapshot",             // 標記為快照數據，非計算值
+	}, nil
+}
+
+// GetOpenInterestSnapshot 获取当前持仓量（含交易所数据时间）
+func (c *APIClient) GetOpenInterestSnapshot(ctx context.Context, symbol string) (*OpenInterest, error) {
+	var result struct {
+		OpenInterest string `json:"openInterest"`
+		Symbol       string `json:"symbol"`
+		Time         int64  `json:"time"`
+	}
+	if err := c.get(ctx, "/fapi/v1/openInterest", url.Values{"symbol": {symbol}}, &result); err != nil {
+		return nil, err
 	}
 
-	// Check HTTP status code
-	if resp.StatusCode != http.StatusOK {
-		var binanceErr BinanceErrorResponse
-		if json.Unmarshal(body, &binanceErr) == nil && binanceErr.Code != 0 {
-			log.Printf("❌ Binance API error for OI history %s (attempt %d): %v", symbol, attempt, &binanceErr)
-			return nil, &binanceErr
+	oi, _ := strconv.ParseFloat(result.OpenInterest, 64)
+
+	return &OpenInterest{Symbol: symbol, Value: oi, Timestamp: result.Time}, nil
+}
+
+// GetLongShortRatio 获取全市场多空账户人数比（按时间正序）
+// period: "5m", "15m", "30m", "1h", "2h", "4h", "6h", "12h", "1d"；limit 最大 500
+func (c *APIClient) GetLongShortRatio(ctx context.Context, symbol, period string, limit int) ([]LongShortRatio, error) {
+	var rows []struct {
+		LongShortRatio string `json:"longShortRatio"`
+		Timestamp      int64  `json:"timestamp"`
+	}
+	params := url.Values{"symbol": {symbol}, "period": {period}, "limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, "/futures/data/globalLongShortAccountRatio", params, &rows); err != nil {
+		return nil, err
+	}
+
+	ratios := make([]LongShortRatio, 0, len(rows))
+	for _, row := range rows {
+		ratio, err := strconv.ParseFloat(row.LongShortRatio, 64)
+		if err != nil {
+			continue
 		}
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		ratios = append(ratios, LongShortRatio{Symbol: symbol, Ratio: ratio, Timestamp: row.Timestamp})
 	}
+	return ratios, nil
+}
 
-	// Try to parse as error response first
-	var binanceErr BinanceErrorResponse
-	if json.Unmarshal(body, &binanceErr) == nil && binanceErr.Code != 0 {
-		log.Printf("❌ Binance API error for OI history %s (attempt %d): %v", symbol, attempt, &binanceErr)
-		return nil, &binanceErr
+// GetTickers24hr 获取全部合约的 24 小时行情统计
+func (c *APIClient) GetTickers24hr(ctx context.Context) ([]Ticker24hr, error) {
+	var tickers []Ticker24hr
+	if err := c.get(ctx, "/fapi/v1/ticker/24hr", nil, &tickers); err != nil {
+		return nil, err
 	}
+	return tickers, nil
+}
 
+// GetOpenInterestHistory retrieves historical OI data (for backfilling on startup; transient errors are retried by marketRetry)
+// period: "5m", "15m", "30m", "1h", "2h", "4h", "6h", "12h", "1d"
+// limit: default 30, max 500 (we need 20 15-minute data points = 5 hours)
+func (c *APIClient) GetOpenInterestHistory(ctx context.Context, symbol string, period string, limit int) ([]OISnapshot, error) {
 	var histData []struct {
 		Symbol               string `json:"symbol"`
 		SumOpenInterest      string `json:"sumOpenInterest"`
@@ -337,10 +431,10 @@ func (c *APIClient) getOpenInterestHistoryAttempt(symbol string, period string,
 		Timestamp            int64  `json:"timestamp"`
 	}
 
-	if err := json.Unmarshal(body, &histData); err != nil {
-		log.Printf("❌ Failed to parse OI history for %s (attempt %d), response: %s",
-			symbol, attempt, string(body[:min(200, len(body))]))
-		return nil, fmt.Errorf("parse OI history JSON failed: %w", err)
+	params := url.Values{"symbol": {symbol}, "period": {period}, "limit": {strconv.Itoa(limit)}}
+	if err := c.get(ctx, "/futures/data/openInterestHist", params, &histData); err != nil {
+		log.Error("❌ Binance GetOpenInterestHistory 失败", "symbol", symbol, "error", err)
+		return nil, err
 	}
 
 	// Convert to OISnapshot format
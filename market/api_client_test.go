@@ -1,6 +1,7 @@
 package market
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
@@ -47,7 +48,7 @@ func TestGetKlinesWithRetry(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			klines, err := client.GetKlines(tt.symbol, tt.interval, tt.limit)
+			klines, err := client.GetKlines(context.Background(), tt.symbol, tt.interval, tt.limit)
 
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("GetKlines() error = %v, wantErr %v", err, tt.wantErr)
@@ -96,7 +97,7 @@ func TestGetOpenInterestHistoryWithRetry(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			snapshots, err := client.GetOpenInterestHistory(tt.symbol, tt.period, tt.limit)
+			snapshots, err := client.GetOpenInterestHistory(context.Background(), tt.symbol, tt.period, tt.limit)
 
 			if (err != nil) != tt.wantErr {
 				t.Fatalf("GetOpenInterestHistory() error = %v, wantErr %v", err, tt.wantErr)
@@ -139,7 +140,7 @@ func BenchmarkGetKlines(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := client.GetKlines("BTCUSDT", "3m", 10)
+		_, err := client.GetKlines(context.Background(), "BTCUSDT", "3m", 10)
 		if err != nil {
 			b.Logf("Request failed: %v", err)
 		}
@@ -263,3 +264,21 @@ func handleMockOpenInterest(w http.ResponseWriter, r *http.Request, mu *sync.Mut
 
 	_ = json.NewEncoder(w).Encode(response)
 }
+
+// TestGetKlinesStopsRetryingOnCancel ctx 取消后不再退避重试
+func TestGetKlinesStopsRetryingOnCancel(t *testing.T) {
+	client := NewAPIClient()
+	cleanup := setupMockBinanceServer(t, client)
+	defer cleanup()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	start := time.Now()
+	if _, err := client.GetKlines(ctx, "INVALIDSYMBOL", "1m", 5); err == nil {
+		t.Fatal("expected error for invalid symbol")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("GetKlines kept retrying after cancel: %v", elapsed)
+	}
+}
@@ -0,0 +1,120 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// backfillPageSize 每页请求的K线数量（数据源会按自身上限截断，如 OKX 每页最多 100 根）
+const backfillPageSize = 1000
+
+// backfillMaxRetries 单页请求失败时的最大尝试次数
+const backfillMaxRetries = 3
+
+var (
+	// BackfillPageDelay 两次分页请求之间的间隔（避免触发交易所限频）
+	BackfillPageDelay = 300 * time.Millisecond
+	// backfillSleep 等待函数（测试中可替换，避免真实等待）
+	backfillSleep = time.Sleep
+)
+
+// KlineRangeProvider 支持按时间范围查询历史K线的数据源
+type KlineRangeProvider interface {
+	// GetKlinesRange 获取开盘时间位于 [startTime, endTime]（毫秒）内最新的至多 limit 根K线（按时间正序）
+	GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error)
+}
+
+// Backfill 从数据源分页下载 [from, to] 区间的历史K线并写入本地K线缓存（需先 EnableKlineCache）
+// 从 to 向前翻页，每页之间等待 BackfillPageDelay，单页失败时退避重试；返回写入的K线数量
+func Backfill(ctx context.Context, source, symbol, interval string, from, to time.Time) (int, error) {
+	cache := currentKlineCache()
+	if cache == nil {
+		return 0, fmt.Errorf("K线缓存未启用，无法回填历史K线")
+	}
+	if _, ok := TimeframeDuration(interval); !ok {
+		return 0, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if !from.Before(to) {
+		return 0, fmt.Errorf("回填区间无效: %s - %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	}
+
+	ds, err := NewDataSourceByName(source)
+	if err != nil {
+		return 0, err
+	}
+	provider, ok := ds.(KlineRangeProvider)
+	if !ok {
+		return 0, fmt.Errorf("数据源 %s 不支持按时间范围查询K线", ds.GetName())
+	}
+
+	log.Info("📥 开始回填K线", "source", source, "symbol", symbol, "interval", interval, "from", from.Format(time.RFC3339), "to", to.Format(time.RFC3339))
+
+	startTime, endTime := from.UnixMilli(), to.UnixMilli()
+	var all []Kline
+	for pages := 0; endTime >= startTime; pages++ {
+		if pages > 0 {
+			backfillSleep(BackfillPageDelay)
+		}
+		if err := ctx.Err(); err != nil {
+			// 已下载的部分仍然写入缓存，下次可从断点继续
+			if storeErr := cache.Store(source, symbol, interval, all); storeErr != nil {
+				log.Warn("⚠️ 回填K线写入缓存失败", "error", storeErr)
+			}
+			return len(all), fmt.Errorf("回填 %s %s 已取消（已下载 %d 根）: %w", symbol, interval, len(all), err)
+		}
+
+		page, err := fetchBackfillPage(ctx, provider, symbol, interval, startTime, endTime)
+		if err != nil {
+			// 已下载的部分仍然写入缓存，下次可从断点继续
+			if storeErr := cache.Store(source, symbol, interval, all); storeErr != nil {
+				log.Warn("⚠️ 回填K线写入缓存失败", "error", storeErr)
+			}
+			return len(all), fmt.Errorf("回填 %s %s 失败（已下载 %d 根）: %w", symbol, interval, len(all), err)
+		}
+		if len(page) == 0 || page[0].OpenTime > endTime {
+			break // 没有更早的数据（或数据源忽略了时间范围），结束翻页
+		}
+
+		all = append(page, all...)
+		endTime = page[0].OpenTime - 1
+	}
+
+	if err := cache.Store(source, symbol, interval, all); err != nil {
+		return 0, fmt.Errorf("回填K线写入缓存失败: %w", err)
+	}
+
+	log.Info("✅ 回填完成", "source", source, "symbol", symbol, "interval", interval, "kline_count", len(all))
+	return len(all), nil
+}
+
+// LoadCachedKlines 从本地K线缓存读取 [from, to] 区间的K线（用于回测和指标预热）
+func LoadCachedKlines(source, symbol, interval string, from, to time.Time) ([]Kline, error) {
+	cache := currentKlineCache()
+	if cache == nil {
+		return nil, fmt.Errorf("K线缓存未启用")
+	}
+	return cache.Range(source, symbol, interval, from, to), nil
+}
+
+// fetchBackfillPage 请求一页历史K线（失败时退避重试）
+func fetchBackfillPage(ctx context.Context, provider KlineRangeProvider, symbol, interval string, startTime, endTime int64) ([]Kline, error) {
+	var lastErr error
+	for attempt := 1; attempt <= backfillMaxRetries; attempt++ {
+		page, err := provider.GetKlinesRange(ctx, symbol, interval, startTime, endTime, backfillPageSize)
+		if err == nil {
+			return page, nil
+		}
+
+		lastErr = err
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		if attempt < backfillMaxRetries {
+			backoff := time.Duration(attempt) * 2 * time.Second
+			log.Warn("⚠️ 回填K线请求失败，稍后重试", "attempt", attempt, "max_retries", backfillMaxRetries, "error", err, "backoff", backoff)
+			backfillSleep(backoff)
+		}
+	}
+	return nil, lastErr
+}
@@ -0,0 +1,99 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestBackfillPagesBinanceIntoCache(t *testing.T) {
+	listing := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	hour := time.Hour.Milliseconds()
+	requests := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		endTime, _ := strconv.ParseInt(r.URL.Query().Get("endTime"), 10, 64)
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+
+		// 返回截至 endTime 的最新 limit 根1小时K线（不早于上线时间）
+		var rows [][]interface{}
+		if endTime < listing {
+			json.NewEncoder(w).Encode(rows)
+			return
+		}
+		last := endTime - (endTime-listing)%hour
+		for ts := last - int64(limit-1)*hour; ts <= last; ts += hour {
+			if ts < listing {
+				continue
+			}
+			rows = append(rows, []interface{}{ts, "1", "2", "0.5", "1.5", "10", ts + hour - 1, "15", 5, "4", "6", "0"})
+		}
+		json.NewEncoder(w).Encode(rows)
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	backfillSleep = func(time.Duration) {}
+	defer func() { backfillSleep = time.Sleep }()
+
+	if _, err := Backfill(context.Background(), "binance", "BTCUSDT", "1h", time.UnixMilli(listing), time.UnixMilli(listing+10*hour)); err == nil {
+		t.Fatal("缓存未启用时应返回错误")
+	}
+
+	if err := EnableKlineCache(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer DisableKlineCache()
+
+	// 区间起点早于上线时间：翻页直到没有更早的数据
+	from := time.UnixMilli(listing - 100*hour)
+	to := time.UnixMilli(listing + 2499*hour)
+	count, err := Backfill(context.Background(), "binance", "BTCUSDT", "1h", from, to)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if count != 2500 {
+		t.Errorf("期望回填 2500 根K线，实际 %d", count)
+	}
+	if requests > 5 {
+		t.Errorf("分页请求次数过多: %d", requests)
+	}
+
+	klines, err := LoadCachedKlines("binance", "BTCUSDT", "1h", time.UnixMilli(listing), time.UnixMilli(listing+9*hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(klines) != 10 || klines[0].OpenTime != listing || klines[9].OpenTime != listing+9*hour {
+		t.Errorf("缓存读取区间错误: len=%d", len(klines))
+	}
+}
+
+func TestOKXGetKlinesRange(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v5/market/history-candles" || q.Get("after") != "1700007200001" || q.Get("before") != "1699999999999" || q.Get("limit") != "100" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[
+			["1700007200000","3","3","3","3","1","1","1","1"],
+			["1700003600000","2","2","2","2","1","1","1","1"]]}`))
+	}))
+	defer server.Close()
+
+	source := NewOKXDataSource()
+	source.baseURL = server.URL
+
+	klines, err := source.GetKlinesRange(context.Background(), "BTCUSDT", "1h", 1700000000000, 1700007200000, 1000)
+	if err != nil {
+		t.Fatalf("GetKlinesRange failed: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1700003600000 || klines[1].Close != 3 {
+		t.Errorf("Expected ascending klines, got %+v", klines)
+	}
+}
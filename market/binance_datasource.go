@@ -1,14 +1,21 @@
 package market
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"strings"
 	"time"
 )
 
+const defaultBinanceStreamURL = "wss://fstream.binance.com/ws"
+
 // BinanceDataSource 封装 Binance 作为数据源
 type BinanceDataSource struct {
 	client *APIClient
+	wsURL  string
 	name   string
 }
 
@@ -16,6 +23,7 @@ type BinanceDataSource struct {
 func NewBinanceDataSource() *BinanceDataSource {
 	return &BinanceDataSource{
 		client: NewAPIClient(),
+		wsURL:  netconfig.BaseURL("binance-ws", defaultBinanceStreamURL),
 		name:   "Binance",
 	}
 }
@@ -26,22 +34,31 @@ func (b *BinanceDataSource) GetName() string {
 }
 
 // GetKlines 获取K线数据
-func (b *BinanceDataSource) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
-	klines, err := b.client.GetKlines(symbol, interval, limit)
+func (b *BinanceDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	klines, err := b.client.GetKlines(ctx, symbol, interval, limit)
 	if err != nil {
-		log.Printf("⚠️  Binance GetKlines 失败 [%s %s]: %v", symbol, interval, err)
+		log.Warn("⚠️ Binance GetKlines 失败", "symbol", symbol, "interval", interval, "error", err)
 		return nil, fmt.Errorf("binance GetKlines failed: %w", err)
 	}
 
-	log.Printf("✅ Binance GetKlines 成功 [%s %s]: %d 条数据", symbol, interval, len(klines))
+	log.Info("✅ Binance GetKlines 成功", "symbol", symbol, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// GetKlinesRange 按时间范围获取历史K线（单页最多 1500 根）
+func (b *BinanceDataSource) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	klines, err := b.client.GetKlinesRange(ctx, symbol, interval, startTime, endTime, min(limit, 1500))
+	if err != nil {
+		return nil, fmt.Errorf("binance GetKlinesRange failed: %w", err)
+	}
 	return klines, nil
 }
 
 // GetTicker 获取ticker数据
-func (b *BinanceDataSource) GetTicker(symbol string) (*Ticker, error) {
-	price, err := b.client.GetCurrentPrice(symbol)
+func (b *BinanceDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	price, err := b.client.GetCurrentPrice(ctx, symbol)
 	if err != nil {
-		log.Printf("⚠️  Binance GetTicker 失败 [%s]: %v", symbol, err)
+		log.Warn("⚠️ Binance GetTicker 失败", "symbol", symbol, "error", err)
 		return nil, fmt.Errorf("binance GetTicker failed: %w", err)
 	}
 
@@ -51,28 +68,350 @@ func (b *BinanceDataSource) GetTicker(symbol string) (*Ticker, error) {
 		Timestamp: time.Now().Unix(),
 	}
 
-	log.Printf("✅ Binance GetTicker 成功 [%s]: %.2f", symbol, price)
+	log.Info("✅ Binance GetTicker 成功", "symbol", symbol, "price", price)
 	return ticker, nil
 }
 
+// GetFundingRate 获取当前（预测）资金费率
+func (b *BinanceDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	rate, err := b.client.GetFundingRate(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Binance GetFundingRate 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetFundingRate failed: %w", err)
+	}
+	return rate, nil
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率
+func (b *BinanceDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	history, err := b.client.GetFundingRateHistory(ctx, symbol, n)
+	if err != nil {
+		log.Warn("⚠️ Binance GetFundingRateHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetFundingRateHistory failed: %w", err)
+	}
+	return history, nil
+}
+
+// GetOrderBook 获取盘口深度
+func (b *BinanceDataSource) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	book, err := b.client.GetOrderBook(ctx, symbol, depth)
+	if err != nil {
+		log.Warn("⚠️ Binance GetOrderBook 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetOrderBook failed: %w", err)
+	}
+	return book, nil
+}
+
+// GetMarkPrice 获取当前标记价格
+func (b *BinanceDataSource) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	premium, err := b.client.GetPremiumIndex(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Binance GetMarkPrice 失败", "symbol", symbol, "error", err)
+		return 0, fmt.Errorf("binance GetMarkPrice failed: %w", err)
+	}
+	return premium.MarkPrice, nil
+}
+
+// GetIndexPrice 获取当前指数价格
+func (b *BinanceDataSource) GetIndexPrice(ctx context.Context, symbol string) (float64, error) {
+	premium, err := b.client.GetPremiumIndex(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Binance GetIndexPrice 失败", "symbol", symbol, "error", err)
+		return 0, fmt.Errorf("binance GetIndexPrice failed: %w", err)
+	}
+	return premium.IndexPrice, nil
+}
+
+// GetBasisHistory 获取基差/溢价序列（按时间正序）
+func (b *BinanceDataSource) GetBasisHistory(ctx context.Context, symbol, interval string, limit int) ([]BasisPoint, error) {
+	series, err := b.client.GetBasisHistory(ctx, symbol, interval, limit)
+	if err != nil {
+		log.Warn("⚠️ Binance GetBasisHistory 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("binance GetBasisHistory failed: %w", err)
+	}
+	return series, nil
+}
+
+// GetOpenInterest 获取当前持仓量
+func (b *BinanceDataSource) GetOpenInterest(ctx context.Context, symbol string) (*OpenInterest, error) {
+	oi, err := b.client.GetOpenInterestSnapshot(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Binance GetOpenInterest 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetOpenInterest failed: %w", err)
+	}
+	return oi, nil
+}
+
+// GetOpenInterestHistory 获取历史持仓量（按时间正序）
+func (b *BinanceDataSource) GetOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]OISnapshot, error) {
+	history, err := b.client.GetOpenInterestHistory(ctx, symbol, period, limit)
+	if err != nil {
+		log.Warn("⚠️ Binance GetOpenInterestHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetOpenInterestHistory failed: %w", err)
+	}
+	return history, nil
+}
+
+// GetLongShortRatio 获取全市场多空账户人数比（按时间正序）
+func (b *BinanceDataSource) GetLongShortRatio(ctx context.Context, symbol, period string, limit int) ([]LongShortRatio, error) {
+	ratios, err := b.client.GetLongShortRatio(ctx, symbol, period, limit)
+	if err != nil {
+		log.Warn("⚠️ Binance GetLongShortRatio 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("binance GetLongShortRatio failed: %w", err)
+	}
+	return ratios, nil
+}
+
+// GetSymbolStats 获取全部 USDT 永续合约的 24 小时行情统计（跳过 24 小时内没有成交的已下架合约）
+func (b *BinanceDataSource) GetSymbolStats(ctx context.Context) ([]SymbolStat, error) {
+	tickers, err := b.client.GetTickers24hr(ctx)
+	if err != nil {
+		log.Warn("⚠️ Binance GetSymbolStats 失败", "error", err)
+		return nil, fmt.Errorf("binance GetSymbolStats failed: %w", err)
+	}
+	cutoff := time.Now().Add(-24 * time.Hour).UnixMilli()
+	stats := make([]SymbolStat, 0, len(tickers))
+	for _, t := range tickers {
+		if !strings.HasSuffix(t.Symbol, "USDT") || t.CloseTime < cutoff {
+			continue
+		}
+		stats = append(stats, t.SymbolStat())
+	}
+	return stats, nil
+}
+
 // HealthCheck 健康检查
-func (b *BinanceDataSource) HealthCheck() error {
-	_, err := b.client.GetExchangeInfo()
+func (b *BinanceDataSource) HealthCheck(ctx context.Context) error {
+	_, err := b.client.GetExchangeInfo(ctx)
 	if err != nil {
-		log.Printf("❌ Binance 健康检查失败: %v", err)
+		log.Error("❌ Binance 健康检查失败", "error", err)
 		return fmt.Errorf("binance health check failed: %w", err)
 	}
 
-	log.Printf("✅ Binance 健康检查成功")
+	log.Info("✅ Binance 健康检查成功")
 	return nil
 }
 
 // GetLatency 获取延迟
 func (b *BinanceDataSource) GetLatency() time.Duration {
+	ctx, cancel := requestContext()
+	defer cancel()
 	start := time.Now()
-	_ = b.HealthCheck()
+	err := b.HealthCheck(ctx)
 	latency := time.Since(start)
+	metrics.DataSourceCheck(b.GetName(), latency, err)
 
-	log.Printf("📊 Binance 延迟: %v", latency)
+	log.Info("📊 Binance 延迟", "latency", latency)
 	return latency
 }
+
+// StreamKlines 订阅 Binance 合约K线推送
+func (b *BinanceDataSource) StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	return startKlineStream(ctx, b.name, &binanceKlineProtocol{baseURL: b.wsURL}, b.GetKlines, symbol, interval)
+}
+
+// binanceKlineProtocol Binance 单流K线协议（连接地址即订阅，无需发送订阅消息）
+type binanceKlineProtocol struct {
+	baseURL string
+}
+
+func (p *binanceKlineProtocol) url(symbol, interval string) (string, error) {
+	return fmt.Sprintf("%s/%s@kline_%s", p.baseURL, strings.ToLower(symbol), interval), nil
+}
+
+func (p *binanceKlineProtocol) subscribeMessages(symbol, interval string) ([]interface{}, error) {
+	return nil, nil
+}
+
+func (p *binanceKlineProtocol) heartbeat() interface{} {
+	return nil // 服务端发送 ping 帧
+}
+
+func (p *binanceKlineProtocol) parse(message []byte, intervalMs int64) ([]Kline, error) {
+	var data KlineWSData
+	if err := json.Unmarshal(message, &data); err != nil {
+		return nil, err
+	}
+	if data.EventType != "kline" {
+		return nil, nil
+	}
+	return []Kline{klineFromWSData(data)}, nil
+}
+
+// StreamTickers 订阅 Binance 合约盘口推送（bookTicker，最优买卖价变化即推送）
+func (b *BinanceDataSource) StreamTickers(ctx context.Context, symbols []string) <-chan Ticker {
+	streamURL := strings.TrimSuffix(b.wsURL, "/ws") + "/stream"
+	return startTickerStream(ctx, b.name, symbols, func(symbols []string) tickerStreamProtocol {
+		return &binanceTickerProtocol{streamURL: streamURL, symbols: symbols}
+	})
+}
+
+// binanceTickerProtocol Binance 组合流 bookTicker 协议（连接地址即订阅）
+type binanceTickerProtocol struct {
+	streamURL string
+	symbols   []string
+}
+
+func (p *binanceTickerProtocol) url() string {
+	streams := make([]string, len(p.symbols))
+	for i, symbol := range p.symbols {
+		streams[i] = strings.ToLower(symbol) + "@bookTicker"
+	}
+	return p.streamURL + "?streams=" + strings.Join(streams, "/")
+}
+
+func (p *binanceTickerProtocol) subscribeMessages() []interface{} {
+	return nil
+}
+
+func (p *binanceTickerProtocol) heartbeat() interface{} {
+	return nil // 服务端发送 ping 帧
+}
+
+func (p *binanceTickerProtocol) parse(message []byte) ([]Ticker, error) {
+	var msg struct {
+		Data struct {
+			EventType string `json:"e"`
+			EventTime int64  `json:"E"`
+			Symbol    string `json:"s"`
+			BidPrice  string `json:"b"`
+			BidQty    string `json:"B"` // 需要显式声明，否则大小写不敏感匹配会覆盖 b
+			AskPrice  string `json:"a"`
+			AskQty    string `json:"A"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Data.EventType != "bookTicker" {
+		return nil, nil
+	}
+
+	bid, err := parseFloat(msg.Data.BidPrice)
+	if err != nil {
+		return nil, err
+	}
+	ask, err := parseFloat(msg.Data.AskPrice)
+	if err != nil {
+		return nil, err
+	}
+	ticker := Ticker{Symbol: msg.Data.Symbol, BidPrice: bid, AskPrice: ask, Timestamp: msg.Data.EventTime / 1000}
+	ticker.LastPrice = ticker.MidPrice()
+	return []Ticker{ticker}, nil
+}
+
+// StreamOrderBook 订阅 Binance 合约部分深度推送（5/10/20 档快照，100ms 一次）
+func (b *BinanceDataSource) StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error) {
+	levels := 20
+	for _, l := range []int{5, 10} {
+		if depth <= l {
+			levels = l
+			break
+		}
+	}
+	protocol := &binanceOrderBookProtocol{baseURL: b.wsURL, symbol: strings.ToUpper(symbol), levels: levels, depth: depth}
+	return startOrderBookStream(ctx, b.name, symbol, depth, maxStreamOrderBookDepth, protocol)
+}
+
+// binanceOrderBookProtocol Binance 部分深度流协议（连接地址即订阅）
+type binanceOrderBookProtocol struct {
+	baseURL string
+	symbol  string
+	levels  int // 订阅的档数（5/10/20）
+	depth   int // 返回的档数
+}
+
+func (p *binanceOrderBookProtocol) url() string {
+	return fmt.Sprintf("%s/%s@depth%d@100ms", p.baseURL, strings.ToLower(p.symbol), p.levels)
+}
+
+func (p *binanceOrderBookProtocol) subscribeMessages() []interface{} {
+	return nil
+}
+
+func (p *binanceOrderBookProtocol) heartbeat() interface{} {
+	return nil // 服务端发送 ping 帧
+}
+
+func (p *binanceOrderBookProtocol) parse(message []byte) ([]OrderBook, error) {
+	var msg struct {
+		EventType       string     `json:"e"`
+		EventTime       int64      `json:"E"` // 需要显式声明，否则大小写不敏感匹配到 e
+		TransactionTime int64      `json:"T"`
+		Bids            [][]string `json:"b"`
+		Asks            [][]string `json:"a"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.EventType != "depthUpdate" {
+		return nil, nil
+	}
+
+	bids, err := parseOrderBookLevels(msg.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseOrderBookLevels(msg.Asks)
+	if err != nil {
+		return nil, err
+	}
+	book := &OrderBook{Symbol: p.symbol, Bids: bids, Asks: asks, Timestamp: msg.TransactionTime}
+	return []OrderBook{*book.truncate(p.depth)}, nil
+}
+
+// StreamTrades 订阅 Binance 合约归集成交推送（aggTrade）
+func (b *BinanceDataSource) StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error) {
+	symbol = strings.ToUpper(symbol)
+	return startTradeStream(ctx, b.name, symbol, &binanceTradeProtocol{baseURL: b.wsURL, symbol: symbol})
+}
+
+// binanceTradeProtocol Binance 归集成交流协议（连接地址即订阅）
+type binanceTradeProtocol struct {
+	baseURL string
+	symbol  string
+}
+
+func (p *binanceTradeProtocol) url() string {
+	return fmt.Sprintf("%s/%s@aggTrade", p.baseURL, strings.ToLower(p.symbol))
+}
+
+func (p *binanceTradeProtocol) subscribeMessages() []interface{} {
+	return nil
+}
+
+func (p *binanceTradeProtocol) heartbeat() interface{} {
+	return nil // 服务端发送 ping 帧
+}
+
+func (p *binanceTradeProtocol) parse(message []byte) ([]Trade, error) {
+	var msg struct {
+		EventType    string `json:"e"`
+		EventTime    int64  `json:"E"` // 需要显式声明，否则大小写不敏感匹配到 e
+		AggTradeID   int64  `json:"a"`
+		Price        string `json:"p"`
+		Quantity     string `json:"q"`
+		TradeTime    int64  `json:"T"`
+		IsBuyerMaker bool   `json:"m"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.EventType != "aggTrade" {
+		return nil, nil
+	}
+
+	price, err := parseFloat(msg.Price)
+	if err != nil {
+		return nil, err
+	}
+	qty, err := parseFloat(msg.Quantity)
+	if err != nil {
+		return nil, err
+	}
+	side := TradeSideBuy
+	if msg.IsBuyerMaker {
+		side = TradeSideSell // 买方是挂单方，说明主动方是卖方
+	}
+	return []Trade{{Symbol: p.symbol, TradeID: msg.AggTradeID, Price: price, Quantity: qty, Side: side, Timestamp: msg.TradeTime}}, nil
+}
@@ -0,0 +1,307 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nofx/exchangehttp"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"nofx/ratelimit"
+	"strconv"
+	"time"
+)
+
+const defaultBybitBaseURL = "https://api.bybit.com"
+
+// BybitDataSource 封装 Bybit USDT 永续合约公开行情作为数据源（不需要认证）
+type BybitDataSource struct {
+	client  *http.Client
+	baseURL string
+	name    string
+}
+
+// bybitResponse Bybit v5 API 通用响应
+type bybitResponse struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// NewBybitDataSource 创建 Bybit 数据源实例
+func NewBybitDataSource() *BybitDataSource {
+	return &BybitDataSource{
+		client:  exchangehttp.NewHTTPClient("bybit", ratelimit.PublicGroup("bybit"), 30*time.Second),
+		baseURL: netconfig.BaseURL("bybit", defaultBybitBaseURL),
+		name:    "Bybit",
+	}
+}
+
+// GetName 获取数据源名称
+func (b *BybitDataSource) GetName() string {
+	return b.name
+}
+
+// GetKlines 获取K线数据（Bybit 返回按时间倒序，这里转换为正序）
+func (b *BybitDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	bybitInterval, err := convertIntervalToBybit(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("symbol", symbol)
+	params.Set("interval", bybitInterval)
+	params.Set("limit", strconv.Itoa(limit))
+
+	var result struct {
+		List [][]string `json:"list"`
+	}
+	if err := b.get(ctx, "/v5/market/kline", params, &result); err != nil {
+		log.Warn("⚠️ Bybit GetKlines 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("bybit GetKlines failed: %w", err)
+	}
+
+	intervalMs := intervalToMillis(interval)
+	klines := make([]Kline, 0, len(result.List))
+	for i := len(result.List) - 1; i >= 0; i-- {
+		kline, err := convertBybitCandle(result.List[i], intervalMs)
+		if err != nil {
+			return nil, fmt.Errorf("bybit GetKlines parse failed: %w", err)
+		}
+		klines = append(klines, kline)
+	}
+
+	log.Info("✅ Bybit GetKlines 成功", "symbol", symbol, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// bybitTicker Bybit 合约 ticker
+type bybitTicker struct {
+	LastPrice       string `json:"lastPrice"`
+	Volume24h       string `json:"volume24h"`
+	FundingRate     string `json:"fundingRate"`
+	NextFundingTime string `json:"nextFundingTime"`
+}
+
+// getTicker 获取合约 ticker 原始数据
+func (b *BybitDataSource) getTicker(ctx context.Context, symbol string) (*bybitTicker, error) {
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("symbol", symbol)
+
+	var result struct {
+		List []bybitTicker `json:"list"`
+	}
+	if err := b.get(ctx, "/v5/market/tickers", params, &result); err != nil {
+		return nil, err
+	}
+	if len(result.List) == 0 {
+		return nil, fmt.Errorf("no data for %s", symbol)
+	}
+	return &result.List[0], nil
+}
+
+// GetTicker 获取ticker数据
+func (b *BybitDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	raw, err := b.getTicker(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Bybit GetTicker 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("bybit GetTicker failed: %w", err)
+	}
+
+	price, err := strconv.ParseFloat(raw.LastPrice, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse price failed: %w", err)
+	}
+	volume, _ := strconv.ParseFloat(raw.Volume24h, 64)
+
+	ticker := &Ticker{
+		Symbol:    symbol,
+		LastPrice: price,
+		Volume:    volume,
+		Timestamp: time.Now().Unix(),
+	}
+
+	log.Info("✅ Bybit GetTicker 成功", "symbol", symbol, "price", price)
+	return ticker, nil
+}
+
+// GetFundingRate 获取当前（预测）资金费率
+func (b *BybitDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	raw, err := b.getTicker(ctx, symbol)
+	if err != nil {
+		log.Warn("⚠️ Bybit GetFundingRate 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("bybit GetFundingRate failed: %w", err)
+	}
+
+	rate, err := strconv.ParseFloat(raw.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse funding rate failed: %w", err)
+	}
+	fundingTime, _ := strconv.ParseInt(raw.NextFundingTime, 10, 64)
+
+	return &FundingRate{Symbol: symbol, Rate: rate, FundingTime: fundingTime}, nil
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (b *BybitDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("symbol", symbol)
+	params.Set("limit", strconv.Itoa(n))
+
+	var result struct {
+		List []struct {
+			FundingRate          string `json:"fundingRate"`
+			FundingRateTimestamp string `json:"fundingRateTimestamp"`
+		} `json:"list"`
+	}
+	if err := b.get(ctx, "/v5/market/funding/history", params, &result); err != nil {
+		log.Warn("⚠️ Bybit GetFundingRateHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("bybit GetFundingRateHistory failed: %w", err)
+	}
+
+	history := make([]FundingRate, 0, len(result.List))
+	for i := len(result.List) - 1; i >= 0; i-- {
+		rate, err := strconv.ParseFloat(result.List[i].FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		fundingTime, _ := strconv.ParseInt(result.List[i].FundingRateTimestamp, 10, 64)
+		history = append(history, FundingRate{Symbol: symbol, Rate: rate, FundingTime: fundingTime})
+	}
+	return history, nil
+}
+
+// GetOrderBook 获取盘口深度（Bybit 线性合约单次最多 500 档）
+func (b *BybitDataSource) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	params := url.Values{}
+	params.Set("category", "linear")
+	params.Set("symbol", symbol)
+	params.Set("limit", strconv.Itoa(depth))
+
+	var result struct {
+		Bids [][]string `json:"b"`
+		Asks [][]string `json:"a"`
+		Ts   int64      `json:"ts"`
+	}
+	if err := b.get(ctx, "/v5/market/orderbook", params, &result); err != nil {
+		log.Warn("⚠️ Bybit GetOrderBook 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("bybit GetOrderBook failed: %w", err)
+	}
+
+	bids, err := parseOrderBookLevels(result.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseOrderBookLevels(result.Asks)
+	if err != nil {
+		return nil, err
+	}
+	book := &OrderBook{Symbol: symbol, Bids: bids, Asks: asks, Timestamp: result.Ts}
+	return book.truncate(depth), nil
+}
+
+// HealthCheck 健康检查
+func (b *BybitDataSource) HealthCheck(ctx context.Context) error {
+	var serverTime struct {
+		TimeSecond string `json:"timeSecond"`
+	}
+	if err := b.get(ctx, "/v5/market/time", nil, &serverTime); err != nil {
+		log.Error("❌ Bybit 健康检查失败", "error", err)
+		return fmt.Errorf("bybit health check failed: %w", err)
+	}
+
+	log.Info("✅ Bybit 健康检查成功")
+	return nil
+}
+
+// GetLatency 获取延迟
+func (b *BybitDataSource) GetLatency() time.Duration {
+	ctx, cancel := requestContext()
+	defer cancel()
+	start := time.Now()
+	err := b.HealthCheck(ctx)
+	latency := time.Since(start)
+	metrics.DataSourceCheck(b.GetName(), latency, err)
+
+	log.Info("📊 Bybit 延迟", "latency", latency)
+	return latency
+}
+
+// get 请求 Bybit 公开接口并解析 result 字段
+func (b *BybitDataSource) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	api := &exchangehttp.Client{HTTP: b.client, BaseURL: b.baseURL, Decode: decodeBybitResponse, Retry: marketRetry}
+	return api.Do(ctx, &exchangehttp.Request{Method: http.MethodGet, Path: path, Query: params, Idempotent: true}, out)
+}
+
+// decodeBybitResponse 解析 Bybit v5 响应外层结构 {retCode, retMsg, result}
+func decodeBybitResponse(status int, body []byte, out interface{}) error {
+	if status != http.StatusOK {
+		return &exchangehttp.StatusError{Status: status, Body: body}
+	}
+	var result bybitResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+	if result.RetCode != 0 {
+		return fmt.Errorf("bybit error %d: %s", result.RetCode, result.RetMsg)
+	}
+	return exchangehttp.Unmarshal(result.Result, out)
+}
+
+// === Helper functions ===
+
+// bybitIntervals K线周期映射（1m -> 1, 1h -> 60, 1d -> D）
+var bybitIntervals = map[string]string{
+	"1m": "1", "3m": "3", "5m": "5", "15m": "15", "30m": "30",
+	"1h": "60", "2h": "120", "4h": "240", "6h": "360", "12h": "720",
+	"1d": "D", "1w": "W", "1M": "M",
+}
+
+// convertIntervalToBybit 转换K线周期
+func convertIntervalToBybit(interval string) (string, error) {
+	if v, ok := bybitIntervals[interval]; ok {
+		return v, nil
+	}
+	return "", fmt.Errorf("bybit 不支持的K线周期: %s", interval)
+}
+
+// convertBybitCandle 转换 Bybit K线 [startTime, open, high, low, close, volume, turnover]
+func convertBybitCandle(row []string, intervalMs int64) (Kline, error) {
+	if len(row) < 6 {
+		return Kline{}, fmt.Errorf("invalid candle: %v", row)
+	}
+
+	values := make([]float64, 5)
+	for i := 1; i <= 5; i++ {
+		v, err := strconv.ParseFloat(row[i], 64)
+		if err != nil {
+			return Kline{}, fmt.Errorf("parse candle field %d failed: %w", i, err)
+		}
+		values[i-1] = v
+	}
+
+	openTime, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil {
+		return Kline{}, fmt.Errorf("parse candle time failed: %w", err)
+	}
+
+	kline := Kline{
+		OpenTime:  openTime,
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+		CloseTime: openTime + intervalMs - 1,
+	}
+	if len(row) > 6 {
+		kline.QuoteVolume, _ = strconv.ParseFloat(row[6], 64)
+	}
+	return kline, nil
+}
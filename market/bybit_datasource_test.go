@@ -0,0 +1,43 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// TestBybitRetriesTemporaryErrors 临时错误（5xx）按 marketRetry 重试，业务错误码直接返回
+func TestBybitRetriesTemporaryErrors(t *testing.T) {
+	backoff := marketRetryBackoff
+	marketRetryBackoff = 0
+	defer func() { marketRetryBackoff = backoff }()
+
+	var calls atomic.Int32
+	source := NewBybitDataSource()
+	source.baseURL = "http://mock.bybit.local"
+	source.client = &http.Client{Transport: handlerRoundTripper{handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("symbol") == "BADUSDT" {
+			w.Write([]byte(`{"retCode":10001,"retMsg":"params error: symbol invalid","result":{}}`))
+			return
+		}
+		if calls.Add(1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`{"retCode":0,"retMsg":"OK","result":{"list":[["1700000060000","2","3","1","2.5","10","25"],["1700000000000","1","2","1","2","5","10"]]}}`))
+	})}}
+
+	klines, err := source.GetKlines(context.Background(), "ETHUSDT", "1m", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if calls.Load() != 2 || len(klines) != 2 || klines[0].OpenTime != 1700000000000 || klines[1].Close != 2.5 {
+		t.Errorf("应重试一次并按时间正序返回: calls=%d klines=%+v", calls.Load(), klines)
+	}
+
+	if _, err := source.GetKlines(context.Background(), "BADUSDT", "1m", 2); err == nil || !strings.Contains(err.Error(), "bybit error 10001") {
+		t.Errorf("业务错误码应直接返回: %v", err)
+	}
+}
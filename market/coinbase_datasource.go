@@ -0,0 +1,227 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nofx/exchangehttp"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const defaultCoinbaseBaseURL = "https://api.exchange.coinbase.com"
+
+// CoinbaseDataSource 封装 Coinbase Exchange 现货公开行情作为数据源（不需要认证）
+// 仅用于价格参考：USDT 交易对映射为 USD 现货（BTCUSDT -> BTC-USD），不支持资金费率
+type CoinbaseDataSource struct {
+	client  *http.Client
+	baseURL string
+	name    string
+}
+
+// NewCoinbaseDataSource 创建 Coinbase 数据源实例
+func NewCoinbaseDataSource() *CoinbaseDataSource {
+	return &CoinbaseDataSource{
+		client:  exchangehttp.NewHTTPClient("coinbase", nil, 30*time.Second),
+		baseURL: netconfig.BaseURL("coinbase", defaultCoinbaseBaseURL),
+		name:    "Coinbase",
+	}
+}
+
+// GetName 获取数据源名称
+func (c *CoinbaseDataSource) GetName() string {
+	return c.name
+}
+
+// GetKlines 获取K线数据（Coinbase 不支持的周期由更小的原生周期本地聚合）
+func (c *CoinbaseDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	if _, ok := coinbaseGranularities[interval]; ok {
+		return c.getNativeKlines(ctx, symbol, interval, limit)
+	}
+
+	base, ok := coinbaseBaseInterval(interval)
+	if !ok {
+		return nil, fmt.Errorf("coinbase 不支持的K线周期: %s", interval)
+	}
+	baseDur, _ := TimeframeDuration(base)
+	targetDur, _ := TimeframeDuration(interval)
+	ratio := int(targetDur / baseDur)
+
+	// 多取一个周期的子K线，用于丢弃开头不完整的周期（单次最多 300 根）
+	baseKlines, err := c.getNativeKlines(ctx, symbol, base, min((limit+1)*ratio, coinbaseMaxCandles))
+	if err != nil {
+		return nil, err
+	}
+	klines, err := Resample(baseKlines, base, interval)
+	if err != nil {
+		return nil, fmt.Errorf("coinbase GetKlines resample failed: %w", err)
+	}
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	log.Info("✅ Coinbase GetKlines 聚合", "symbol", symbol, "base", base, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// getNativeKlines 获取 Coinbase 原生周期K线（Coinbase 返回按时间倒序，这里转换为正序）
+func (c *CoinbaseDataSource) getNativeKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	granularity, err := convertIntervalToCoinbase(interval)
+	if err != nil {
+		return nil, err
+	}
+
+	params := url.Values{}
+	params.Set("granularity", strconv.Itoa(granularity))
+
+	var rows [][]float64
+	if err := c.get(ctx, "/products/"+convertSymbolToCoinbase(symbol)+"/candles", params, &rows); err != nil {
+		log.Warn("⚠️ Coinbase GetKlines 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("coinbase GetKlines failed: %w", err)
+	}
+
+	// 每次最多返回 300 根，只保留最近 limit 根
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	intervalMs := int64(granularity) * 1000
+	klines := make([]Kline, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		// [time(秒), low, high, open, close, volume]
+		row := rows[i]
+		if len(row) < 6 {
+			return nil, fmt.Errorf("coinbase GetKlines parse failed: invalid candle %v", row)
+		}
+		openTime := int64(row[0]) * 1000
+		klines = append(klines, Kline{
+			OpenTime:    openTime,
+			Low:         row[1],
+			High:        row[2],
+			Open:        row[3],
+			Close:       row[4],
+			Volume:      row[5],
+			CloseTime:   openTime + intervalMs - 1,
+			QuoteVolume: row[5] * row[4],
+		})
+	}
+
+	log.Info("✅ Coinbase GetKlines 成功", "symbol", symbol, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// GetTicker 获取ticker数据
+func (c *CoinbaseDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	var raw struct {
+		Price  string `json:"price"`
+		Volume string `json:"volume"`
+	}
+	if err := c.get(ctx, "/products/"+convertSymbolToCoinbase(symbol)+"/ticker", nil, &raw); err != nil {
+		log.Warn("⚠️ Coinbase GetTicker 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("coinbase GetTicker failed: %w", err)
+	}
+
+	price, err := strconv.ParseFloat(raw.Price, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse price failed: %w", err)
+	}
+	volume, _ := strconv.ParseFloat(raw.Volume, 64)
+
+	ticker := &Ticker{
+		Symbol:    symbol,
+		LastPrice: price,
+		Volume:    volume,
+		Timestamp: time.Now().Unix(),
+	}
+
+	log.Info("✅ Coinbase GetTicker 成功", "symbol", symbol, "price", price)
+	return ticker, nil
+}
+
+// GetFundingRate 现货市场没有资金费率
+func (c *CoinbaseDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	return nil, fmt.Errorf("coinbase GetFundingRate: 现货数据源不支持资金费率")
+}
+
+// GetFundingRateHistory 现货市场没有资金费率
+func (c *CoinbaseDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	return nil, fmt.Errorf("coinbase GetFundingRateHistory: 现货数据源不支持资金费率")
+}
+
+// HealthCheck 健康检查
+func (c *CoinbaseDataSource) HealthCheck(ctx context.Context) error {
+	var serverTime struct {
+		Epoch float64 `json:"epoch"`
+	}
+	if err := c.get(ctx, "/time", nil, &serverTime); err != nil {
+		log.Error("❌ Coinbase 健康检查失败", "error", err)
+		return fmt.Errorf("coinbase health check failed: %w", err)
+	}
+
+	log.Info("✅ Coinbase 健康检查成功")
+	return nil
+}
+
+// GetLatency 获取延迟
+func (c *CoinbaseDataSource) GetLatency() time.Duration {
+	ctx, cancel := requestContext()
+	defer cancel()
+	start := time.Now()
+	err := c.HealthCheck(ctx)
+	latency := time.Since(start)
+	metrics.DataSourceCheck(c.GetName(), latency, err)
+
+	log.Info("📊 Coinbase 延迟", "latency", latency)
+	return latency
+}
+
+// get 请求 Coinbase 公开接口
+func (c *CoinbaseDataSource) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	// Coinbase 要求请求携带 User-Agent
+	api := &exchangehttp.Client{HTTP: c.client, BaseURL: c.baseURL, Header: http.Header{"User-Agent": {"nofx"}}, Retry: marketRetry}
+	return api.Do(ctx, &exchangehttp.Request{Method: http.MethodGet, Path: path, Query: params, Idempotent: true}, out)
+}
+
+// === Helper functions ===
+
+// convertSymbolToCoinbase 转换币种符号为 Coinbase 现货交易对（BTCUSDT -> BTC-USD）
+func convertSymbolToCoinbase(symbol string) string {
+	if strings.HasSuffix(symbol, "USDT") {
+		return strings.TrimSuffix(symbol, "USDT") + "-USD"
+	}
+	return symbol
+}
+
+// coinbaseGranularities K线周期对应的秒数
+var coinbaseGranularities = map[string]int{
+	"1m": 60, "5m": 300, "15m": 900, "1h": 3600, "6h": 21600, "1d": 86400,
+}
+
+// coinbaseMaxCandles Coinbase 单次请求最多返回的K线数量
+const coinbaseMaxCandles = 300
+
+// coinbaseBaseInterval 可聚合为 interval 的最大 Coinbase 原生周期（如 4h → 1h，3m → 1m）
+func coinbaseBaseInterval(interval string) (string, bool) {
+	target, ok := TimeframeDuration(interval)
+	if !ok {
+		return "", false
+	}
+	for _, base := range []string{"1d", "6h", "1h", "15m", "5m", "1m"} {
+		d, _ := TimeframeDuration(base)
+		if d < target && target%d == 0 {
+			return base, true
+		}
+	}
+	return "", false
+}
+
+// convertIntervalToCoinbase 转换K线周期为秒（Coinbase 仅支持 1m/5m/15m/1h/6h/1d）
+func convertIntervalToCoinbase(interval string) (int, error) {
+	if v, ok := coinbaseGranularities[interval]; ok {
+		return v, nil
+	}
+	return 0, fmt.Errorf("coinbase 不支持的K线周期: %s", interval)
+}
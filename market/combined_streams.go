@@ -3,7 +3,7 @@ package market
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"nofx/netconfig"
 	"strings"
 	"sync"
 	"time"
@@ -36,10 +36,11 @@ func NewCombinedStreamsClient(batchSize int) *CombinedStreamsClient {
 func (c *CombinedStreamsClient) Connect() error {
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		Proxy:            netconfig.Proxy,
 	}
 
 	// 组合流使用不同的端点
-	conn, _, err := dialer.Dial("wss://fstream.binance.com/stream", nil)
+	conn, _, err := dialer.Dial(netconfig.BaseURL("binance-ws", "wss://fstream.binance.com/stream"), nil)
 	if err != nil {
 		return fmt.Errorf("组合流WebSocket连接失败: %v", err)
 	}
@@ -48,19 +49,26 @@ func (c *CombinedStreamsClient) Connect() error {
 	c.conn = conn
 	c.mu.Unlock()
 
-	log.Println("组合流WebSocket连接成功")
+	log.Info("组合流WebSocket连接成功")
 	go c.readMessages()
 
 	return nil
 }
 
+// Connected 组合流连接是否可用（断线重连期间为 false）
+func (c *CombinedStreamsClient) Connected() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.conn != nil
+}
+
 // BatchSubscribeKlines 批量订阅K线
 func (c *CombinedStreamsClient) BatchSubscribeKlines(symbols []string, interval string) error {
 	// 将symbols分批处理
 	batches := c.splitIntoBatches(symbols, c.batchSize)
 
 	for i, batch := range batches {
-		log.Printf("订阅第 %d 批, 数量: %d", i+1, len(batch))
+		log.Info("订阅批次", "batch", i+1, "stream_count", len(batch))
 
 		streams := make([]string, len(batch))
 		for j, symbol := range batch {
@@ -110,7 +118,7 @@ func (c *CombinedStreamsClient) subscribeStreams(streams []string) error {
 		return fmt.Errorf("WebSocket未连接")
 	}
 
-	log.Printf("订阅流: %v", streams)
+	log.Info("订阅流", "streams", streams)
 	return c.conn.WriteJSON(subscribeMsg)
 }
 
@@ -131,7 +139,12 @@ func (c *CombinedStreamsClient) readMessages() {
 
 			_, message, err := conn.ReadMessage()
 			if err != nil {
-				log.Printf("读取组合流消息失败: %v", err)
+				log.Warn("读取组合流消息失败", "error", err)
+				c.mu.Lock()
+				if c.conn == conn {
+					c.conn = nil // 断开期间 Connected 返回 false
+				}
+				c.mu.Unlock()
 				c.handleReconnect()
 				return
 			}
@@ -148,7 +161,7 @@ func (c *CombinedStreamsClient) handleCombinedMessage(message []byte) {
 	}
 
 	if err := json.Unmarshal(message, &combinedMsg); err != nil {
-		log.Printf("解析组合消息失败: %v", err)
+		log.Warn("解析组合消息失败", "error", err)
 		return
 	}
 
@@ -160,7 +173,7 @@ func (c *CombinedStreamsClient) handleCombinedMessage(message []byte) {
 		select {
 		case ch <- combinedMsg.Data:
 		default:
-			log.Printf("订阅者通道已满: %s", combinedMsg.Stream)
+			log.Info("订阅者通道已满", "stream", combinedMsg.Stream)
 		}
 	}
 }
@@ -178,11 +191,11 @@ func (c *CombinedStreamsClient) handleReconnect() {
 		return
 	}
 
-	log.Println("组合流尝试重新连接...")
+	log.Info("组合流尝试重新连接...")
 	time.Sleep(3 * time.Second)
 
 	if err := c.Connect(); err != nil {
-		log.Printf("组合流重新连接失败: %v", err)
+		log.Warn("组合流重新连接失败", "error", err)
 		go c.handleReconnect()
 		return
 	}
@@ -197,7 +210,7 @@ func (c *CombinedStreamsClient) handleReconnect() {
 	c.mu.RUnlock()
 
 	if len(streams) > 0 {
-		log.Printf("重新订阅 %d 个流", len(streams))
+		log.Info("重新订阅流", "stream_count", len(streams))
 
 		// 调用测试 hook（如果存在）
 		if c.onReconnectSubscribeFunc != nil {
@@ -205,9 +218,9 @@ func (c *CombinedStreamsClient) handleReconnect() {
 		}
 
 		if err := c.subscribeStreams(streams); err != nil {
-			log.Printf("⚠️  重新订阅失败: %v", err)
+			log.Warn("⚠️ 重新订阅失败", "error", err)
 		} else {
-			log.Printf("✅ 重新订阅成功")
+			log.Info("✅ 重新订阅成功")
 		}
 	}
 }
@@ -0,0 +1,26 @@
+package market
+
+import (
+	"context"
+	"time"
+)
+
+// RequestTimeout 不携带调用方 ctx 的请求（包级便捷函数、健康检查、WSMonitor 初始化等）使用的超时
+var RequestTimeout = 30 * time.Second
+
+// requestContext 为不携带调用方 ctx 的请求创建带 RequestTimeout 超时的上下文
+func requestContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), RequestTimeout)
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
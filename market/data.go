@@ -4,9 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
-	"log"
 	"math"
 	"net/http"
+	"nofx/metrics"
 	"strconv"
 	"strings"
 	"sync"
@@ -30,14 +30,13 @@ var (
 // 如果为空或nil，默认使用 ["15m", "1h", "4h"]
 func Get(symbol string, timeframes []string) (*Data, error) {
 	var klines1m, klines3m, klines5m, klines15m, klines1h, klines4h, klines1d []Kline
-	var err error
 	// 标准化symbol
 	symbol = Normalize(symbol)
 
 	// 设置默认时间线（如果未指定）
 	if len(timeframes) == 0 {
 		timeframes = []string{"15m", "1h", "4h"}
-		log.Printf("⚠️  %s 未指定时间线，使用默认值: %v", symbol, timeframes)
+		log.Warn("⚠️ 未指定时间线，使用默认值", "symbol", symbol, "timeframes", timeframes)
 	}
 
 	// 创建时间线查找映射（提高查找效率）
@@ -59,81 +58,82 @@ func Get(symbol string, timeframes []string) (*Data, error) {
 	// 如果没有找到任何短期时间线，使用3m作为默认（兼容旧行为）
 	if shortestTF == "" {
 		shortestTF = "3m"
-		log.Printf("⚠️  %s 未配置任何时间线，使用3m作为默认短期时间线", symbol)
+		log.Warn("⚠️ 未配置任何时间线，使用3m作为默认短期时间线", "symbol", symbol)
 	}
 
-	// 获取短期K线数据（用于当前价格和指标计算）
-	var shortKlines []Kline
-	switch shortestTF {
-	case "1m":
-		klines1m, err = WSMonitorCli.GetCurrentKlines(symbol, "1m")
-		if err != nil {
-			return nil, fmt.Errorf("获取1分钟K线失败: %v", err)
-		}
-		shortKlines = klines1m
-	case "3m":
-		klines3m, err = WSMonitorCli.GetCurrentKlines(symbol, "3m")
-		if err != nil {
-			return nil, fmt.Errorf("获取3分钟K线失败: %v", err)
+	// 短期K线（用于当前价格、指标计算和 stale 检测）：最短时间线是15m或更长时使用3m
+	shortFetchTF := shortestTF
+	if shortFetchTF != "1m" && shortFetchTF != "5m" {
+		shortFetchTF = "3m"
+	}
+
+	// 并发获取所有需要的时间线（避免逐个顺序请求拖慢决策周期）
+	intervals := []string{shortFetchTF}
+	for _, tf := range []string{"15m", "1h", "4h", "1d"} {
+		if tfMap[tf] {
+			intervals = append(intervals, tf)
 		}
-		shortKlines = klines3m
+	}
+	fetchStart := time.Now()
+	snapshot, _ := GetMultiTimeframe(symbol, intervals, 0)
+	fetchElapsed := time.Since(fetchStart)
+
+	if err := snapshot.Err(shortFetchTF); err != nil {
+		return nil, fmt.Errorf("获取%s K线失败: %v", shortFetchTF, err)
+	}
+	shortKlines := snapshot.Klines(shortFetchTF)
+	switch shortFetchTF {
+	case "1m":
+		klines1m = shortKlines
 	case "5m":
-		klines5m, err = WSMonitorCli.GetCurrentKlines(symbol, "5m")
-		if err != nil {
-			return nil, fmt.Errorf("获取5分钟K线失败: %v", err)
-		}
-		shortKlines = klines5m
+		klines5m = shortKlines
 	default:
-		// 如果最短时间线是15m或更长，也获取一个短期数据用于stale检测
-		klines3m, err = WSMonitorCli.GetCurrentKlines(symbol, "3m")
-		if err != nil {
-			return nil, fmt.Errorf("获取3分钟K线失败: %v", err)
-		}
-		shortKlines = klines3m
+		klines3m = shortKlines
 	}
 
 	// Data staleness detection: Prevent DOGEUSDT-style price freeze issues (PR #800)
 	if isStaleData(shortKlines, symbol) {
-		log.Printf("⚠️  WARNING: %s detected stale data (consecutive price freeze), skipping symbol", symbol)
+		log.Warn("⚠️ detected stale data (consecutive price freeze), skipping symbol", "symbol", symbol)
 		return nil, fmt.Errorf("%s data is stale, possible cache failure", symbol)
 	}
 
 	// 根据配置获取其他时间线数据
-	if tfMap["15m"] && len(klines15m) == 0 {
-		klines15m, err = WSMonitorCli.GetCurrentKlines(symbol, "15m")
-		if err != nil {
+	if tfMap["15m"] {
+		if err := snapshot.Err("15m"); err != nil {
 			return nil, fmt.Errorf("获取15分钟K线失败: %v", err)
 		}
+		klines15m = snapshot.Klines("15m")
 	}
 
-	if tfMap["1h"] && len(klines1h) == 0 {
-		klines1h, err = WSMonitorCli.GetCurrentKlines(symbol, "1h")
-		if err != nil {
+	if tfMap["1h"] {
+		if err := snapshot.Err("1h"); err != nil {
 			return nil, fmt.Errorf("获取1小时K线失败: %v", err)
 		}
+		klines1h = snapshot.Klines("1h")
 	}
 
 	if tfMap["4h"] {
-		klines4h, err = WSMonitorCli.GetCurrentKlines(symbol, "4h")
-		if err != nil {
+		if err := snapshot.Err("4h"); err != nil {
 			return nil, fmt.Errorf("获取4小时K线失败: %v", err)
 		}
+		klines4h = snapshot.Klines("4h")
 		// P0修复：检查 4h 数据完整性（如果用户选择了4h）
 		if len(klines4h) == 0 {
-			log.Printf("⚠️  WARNING: %s 缺少 4h K线数据，无法进行多周期趋势确认", symbol)
+			log.Warn("⚠️ 缺少 4h K线数据，无法进行多周期趋势确认", "symbol", symbol)
 			return nil, fmt.Errorf("%s 缺少 4h K线数据", symbol)
 		}
 	}
 
 	if tfMap["1d"] {
-		klines1d, err = WSMonitorCli.GetCurrentKlines(symbol, "1d")
-		if err != nil {
-			log.Printf("⚠️  WARNING: %s 获取日线K线失败: %v，将继续处理但缺少日线数据", symbol, err)
-			klines1d = nil // 日线数据失败不影响整体流程
+		if err := snapshot.Err("1d"); err != nil {
+			log.Warn("⚠️ 获取日线K线失败，将继续处理但缺少日线数据", "symbol", symbol, "error", err)
+		} else {
+			klines1d = snapshot.Klines("1d") // 日线数据失败不影响整体流程
 		}
 	}
 
 	// 计算当前指标 (基于最短时间线的最新数据)
+	indicatorStart := time.Now()
 	currentPrice := shortKlines[len(shortKlines)-1].Close
 	currentEMA20 := calculateEMA(shortKlines, 20)
 	currentMACD := calculateMACD(shortKlines)
@@ -165,7 +165,10 @@ func Get(symbol string, timeframes []string) (*Data, error) {
 		}
 	}
 
+	indicatorElapsed := time.Since(indicatorStart)
+
 	// 获取OI数据
+	fetchStart = time.Now()
 	oiData, err := getOpenInterestData(symbol)
 	if err != nil {
 		// OI失败不影响整体,使用默认值
@@ -176,13 +179,15 @@ func Get(symbol string, timeframes []string) (*Data, error) {
 	// 這不會影響性能，因為 Binance API 無限制且快速
 	if err := EnhanceOIData(symbol, oiData); err != nil {
 		// 多空比獲取失敗不影響整體流程，只記錄警告
-		log.Printf("⚠️  %s 獲取多空比數據失敗: %v", symbol, err)
+		log.Warn("⚠️ 獲取多空比數據失敗", "symbol", symbol, "error", err)
 	}
 
 	// 获取Funding Rate
 	fundingRate, _ := getFundingRate(symbol)
+	fetchElapsed += time.Since(fetchStart)
 
 	// ✅ 条件性计算时间线数据（只计算用户选择的时间线）
+	indicatorStart = time.Now()
 	var intradayData *IntradayData
 	var midTermData15m *MidTermData15m
 	var midTermData1h *MidTermData1h
@@ -217,6 +222,9 @@ func Get(symbol string, timeframes []string) (*Data, error) {
 	if len(klines1d) > 0 {
 		dailyData = calculateDailyData(klines1d)
 	}
+	indicatorElapsed += time.Since(indicatorStart)
+	metrics.ObserveStage(metrics.StageDataFetch, fetchElapsed)
+	metrics.ObserveStage(metrics.StageIndicators, indicatorElapsed)
 
 	return &Data{
 		Symbol:            symbol,
@@ -316,6 +324,70 @@ func calculateRSI(klines []Kline, period int) float64 {
 	return rsi
 }
 
+// ATR 计算K线的平均真实波幅（Wilder 平滑，K线数量不足 period+1 时返回 0）
+func ATR(klines []Kline, period int) float64 {
+	return calculateATR(klines, period)
+}
+
+// ADX 计算平均趋向指数（Wilder 平滑，K线数量不足 2*period+1 时返回 0）
+// 只衡量趋势强度、不区分方向，常用 25 以上视为趋势行情
+func ADX(klines []Kline, period int) float64 {
+	if period <= 0 || len(klines) <= 2*period {
+		return 0
+	}
+
+	p := float64(period)
+	var trSum, plusSum, minusSum float64
+	dxs := make([]float64, 0, len(klines)-period)
+	for i := 1; i < len(klines); i++ {
+		up := klines[i].High - klines[i-1].High
+		down := klines[i-1].Low - klines[i].Low
+		plusDM, minusDM := 0.0, 0.0
+		if up > down && up > 0 {
+			plusDM = up
+		}
+		if down > up && down > 0 {
+			minusDM = down
+		}
+		prevClose := klines[i-1].Close
+		tr := math.Max(klines[i].High-klines[i].Low, math.Max(math.Abs(klines[i].High-prevClose), math.Abs(klines[i].Low-prevClose)))
+
+		if i <= period {
+			trSum += tr
+			plusSum += plusDM
+			minusSum += minusDM
+			if i < period {
+				continue
+			}
+		} else {
+			trSum = trSum - trSum/p + tr
+			plusSum = plusSum - plusSum/p + plusDM
+			minusSum = minusSum - minusSum/p + minusDM
+		}
+
+		dx := 0.0
+		if trSum > 0 {
+			plusDI := 100 * plusSum / trSum
+			minusDI := 100 * minusSum / trSum
+			if plusDI+minusDI > 0 {
+				dx = 100 * math.Abs(plusDI-minusDI) / (plusDI + minusDI)
+			}
+		}
+		dxs = append(dxs, dx)
+	}
+
+	// 初始ADX为前 period 个DX的均值，之后Wilder平滑
+	adx := 0.0
+	for _, dx := range dxs[:period] {
+		adx += dx
+	}
+	adx /= p
+	for _, dx := range dxs[period:] {
+		adx = (adx*(p-1) + dx) / p
+	}
+	return adx
+}
+
 // calculateATR 计算ATR
 func calculateATR(klines []Kline, period int) float64 {
 	if len(klines) <= period {
@@ -596,7 +668,7 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 	// 好处：节省 50% API 调用，数据新鲜度 < 15 分钟
 	if WSMonitorCli != nil {
 		history := WSMonitorCli.GetOIHistory(symbol)
-		log.Printf("🔍 [OI缓存检查] Symbol: %s, WSMonitorCli存在: true, 历史数据点数: %d", symbol, len(history))
+		log.Info("🔍 [OI缓存检查]", "symbol", symbol, "history_count", len(history))
 		if len(history) > 0 {
 			// 使用最新的快照（最多 15 分钟前的数据）
 			latest := history[len(history)-1]
@@ -605,7 +677,7 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 			var actualPeriod string
 			change4h, actualPeriod = WSMonitorCli.CalculateOIChange4h(symbol, latest.Value)
 
-			log.Printf("✅ [OI缓存命中] Symbol: %s, 使用缓存数据, 数据点数: %d, ActualPeriod: %s", symbol, len(history), actualPeriod)
+			log.Info("✅ [OI缓存命中] 使用缓存数据", "symbol", symbol, "history_count", len(history), "actual_period", actualPeriod)
 			return &OIData{
 				Latest:       latest.Value,
 				Average:      latest.Value * 0.999, // 近似平均值
@@ -614,37 +686,18 @@ func getOpenInterestData(symbol string) (*OIData, error) {
 				Historical:   history,
 			}, nil
 		} else {
-			log.Printf("⚠️  [OI缓存未命中] Symbol: %s, 历史数据为空，降级到API调用", symbol)
+			log.Warn("⚠️ [OI缓存未命中] 历史数据为空，降级到API调用", "symbol", symbol)
 		}
 	} else {
-		log.Printf("⚠️  [OI缓存不可用] Symbol: %s, WSMonitorCli为nil", symbol)
+		log.Warn("⚠️ [OI缓存不可用] WSMonitorCli为nil", "symbol", symbol)
 	}
 
 	// ⚠️ 降级：缓存不存在时才调用 API（仅冷启动或缓存失效）
-	url := fmt.Sprintf("https://fapi.binance.com/fapi/v1/openInterest?symbol=%s", symbol)
-
-	resp, err := http.Get(url)
+	snapshot, err := GetOpenInterest(symbol)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
-
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var result struct {
-		OpenInterest string `json:"openInterest"`
-		Symbol       string `json:"symbol"`
-		Time         int64  `json:"time"`
-	}
-
-	if err := json.Unmarshal(body, &result); err != nil {
-		return nil, err
-	}
-
-	oi, _ := strconv.ParseFloat(result.OpenInterest, 64)
+	oi := snapshot.Value
 
 	// 计算4小时变化率
 	var change4h float64
@@ -1032,11 +1085,11 @@ func isStaleData(klines []Kline, symbol string) bool {
 	}
 
 	if allVolumeZero {
-		log.Printf("⚠️  %s stale data confirmed: price freeze + zero volume", symbol)
+		log.Warn("⚠️ stale data confirmed: price freeze + zero volume", "symbol", symbol)
 		return true
 	}
 
 	// Price frozen but has volume: might be extremely low volatility market, allow but log warning
-	log.Printf("⚠️  %s detected extreme price stability (no fluctuation for %d consecutive periods), but volume is normal", symbol, stalePriceThreshold)
+	log.Warn("⚠️ detected extreme price stability, but volume is normal", "symbol", symbol, "consecutive_periods", stalePriceThreshold)
 	return false
 }
@@ -1,19 +1,22 @@
 package market
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"nofx/metrics"
 	"sync"
 	"time"
 )
 
 // DataSource 数据源接口
 type DataSource interface {
-	GetName() string                                               // 获取数据源名称
-	GetKlines(symbol, interval string, limit int) ([]Kline, error) // 获取K线数据
-	GetTicker(symbol string) (*Ticker, error)                      // 获取ticker数据
-	HealthCheck() error                                            // 健康检查
-	GetLatency() time.Duration                                     // 获取延迟
+	GetName() string                                                                        // 获取数据源名称
+	GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error)     // 获取K线数据
+	GetTicker(ctx context.Context, symbol string) (*Ticker, error)                          // 获取ticker数据
+	GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error)                // 获取当前（预测）资金费率
+	GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) // 获取最近N次已结算资金费率（按时间正序）
+	HealthCheck(ctx context.Context) error                                                  // 健康检查
+	GetLatency() time.Duration                                                              // 获取延迟
 }
 
 // DataSourceStatus 数据源状态
@@ -64,12 +67,19 @@ func (dsm *DataSourceManager) AddSource(source DataSource) {
 		LastCheckTime: time.Now(),
 	}
 
-	log.Printf("✅ 添加数据源: %s", source.GetName())
+	log.Info("✅ 添加数据源", "source", source.GetName())
+}
+
+// Sources 已添加的数据源（按添加顺序）
+func (dsm *DataSourceManager) Sources() []DataSource {
+	dsm.mu.RLock()
+	defer dsm.mu.RUnlock()
+	return append([]DataSource(nil), dsm.sources...)
 }
 
 // Start 启动健康检查
 func (dsm *DataSourceManager) Start() {
-	log.Printf("🚀 启动数据源管理器，健康检查间隔: %v", dsm.checkInterval)
+	log.Info("🚀 启动数据源管理器", "check_interval", dsm.checkInterval)
 
 	go func() {
 		ticker := time.NewTicker(dsm.checkInterval)
@@ -80,7 +90,7 @@ func (dsm *DataSourceManager) Start() {
 			case <-ticker.C:
 				dsm.performHealthCheck()
 			case <-dsm.stopChan:
-				log.Println("⏹  数据源管理器已停止")
+				log.Info("⏹ 数据源管理器已停止")
 				return
 			}
 		}
@@ -97,34 +107,35 @@ func (dsm *DataSourceManager) performHealthCheck() {
 	dsm.mu.Lock()
 	defer dsm.mu.Unlock()
 
-	log.Println("🔍 执行数据源健康检查...")
+	log.Info("🔍 执行数据源健康检查...")
 
 	for _, source := range dsm.sources {
 		status := dsm.statuses[source.GetName()]
 		status.LastCheckTime = time.Now()
 
 		start := time.Now()
-		err := source.HealthCheck()
+		ctx, cancel := requestContext()
+		err := source.HealthCheck(ctx)
+		cancel()
 		latency := time.Since(start)
+		metrics.DataSourceCheck(source.GetName(), latency, err)
 
 		if err != nil {
 			status.Healthy = false
 			status.FailureCount++
-			log.Printf("❌ 数据源 %s 健康检查失败: %v (连续失败 %d 次)",
-				source.GetName(), err, status.FailureCount)
+			log.Error("❌ 数据源健康检查失败", "source", source.GetName(), "error", err, "failure_count", status.FailureCount)
 		} else {
 			status.Healthy = true
 			status.FailureCount = 0
 			status.Latency = latency
 			status.SuccessCount++
-			log.Printf("✅ 数据源 %s 健康检查成功 (延迟: %v)",
-				source.GetName(), latency)
+			log.Info("✅ 数据源健康检查成功", "source", source.GetName(), "latency", latency)
 		}
 	}
 
 	// 打印健康摘要
 	healthy, total := dsm.getHealthySummary()
-	log.Printf("📊 数据源健康状态: %d/%d 健康", healthy, total)
+	log.Info("📊 数据源健康状态", "healthy", healthy, "total", total)
 }
 
 // getHealthySummary 获取健康摘要（内部调用，不加锁）
@@ -161,13 +172,13 @@ func (dsm *DataSourceManager) GetHealthySource() (DataSource, error) {
 	}
 
 	// 所有数据源都不健康，返回第一个并警告
-	log.Printf("⚠️  所有数据源都不健康，强制使用 %s", dsm.sources[0].GetName())
+	log.Warn("⚠️ 所有数据源都不健康，强制使用首个数据源", "source", dsm.sources[0].GetName())
 	dsm.currentIndex = 1 % len(dsm.sources)
 	return dsm.sources[0], nil
 }
 
 // GetKlinesWithFallback 获取K线数据（带故障转移）
-func (dsm *DataSourceManager) GetKlinesWithFallback(symbol, interval string, limit int) ([]Kline, error) {
+func (dsm *DataSourceManager) GetKlinesWithFallback(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
 	dsm.mu.Lock()
 	sources := make([]DataSource, len(dsm.sources))
 	copy(sources, dsm.sources)
@@ -186,28 +197,26 @@ func (dsm *DataSourceManager) GetKlinesWithFallback(symbol, interval string, lim
 			continue // 跳过不健康的数据源
 		}
 
-		klines, err := source.GetKlines(symbol, interval, limit)
+		klines, err := source.GetKlines(ctx, symbol, interval, limit)
 
 		dsm.mu.Lock()
 		status.TotalRequests++
 		dsm.mu.Unlock()
 
 		if err == nil && len(klines) > 0 {
-			log.Printf("✅ 从 %s 获取 %s %s K线数据成功 (%d 条)",
-				source.GetName(), symbol, interval, len(klines))
-			return klines, nil
+			log.Info("✅ 获取K线数据成功", "source", source.GetName(), "symbol", symbol, "interval", interval, "kline_count", len(klines))
+			return checkKlineIntegrity(ctx, source, symbol, interval, klines), nil
 		}
 
 		lastErr = err
-		log.Printf("⚠️  从 %s 获取数据失败: %v，尝试下一个数据源...",
-			source.GetName(), err)
+		log.Warn("⚠️ 获取数据失败，尝试下一个数据源...", "source", source.GetName(), "error", err)
 	}
 
 	return nil, fmt.Errorf("所有数据源都失败: %w", lastErr)
 }
 
 // GetTickerWithFallback 获取ticker数据（带故障转移）
-func (dsm *DataSourceManager) GetTickerWithFallback(symbol string) (*Ticker, error) {
+func (dsm *DataSourceManager) GetTickerWithFallback(ctx context.Context, symbol string) (*Ticker, error) {
 	dsm.mu.Lock()
 	sources := make([]DataSource, len(dsm.sources))
 	copy(sources, dsm.sources)
@@ -226,7 +235,7 @@ func (dsm *DataSourceManager) GetTickerWithFallback(symbol string) (*Ticker, err
 			continue
 		}
 
-		ticker, err := source.GetTicker(symbol)
+		ticker, err := source.GetTicker(ctx, symbol)
 
 		dsm.mu.Lock()
 		status.TotalRequests++
@@ -242,6 +251,42 @@ func (dsm *DataSourceManager) GetTickerWithFallback(symbol string) (*Ticker, err
 	return nil, fmt.Errorf("所有数据源都失败: %w", lastErr)
 }
 
+// GetFundingRateWithFallback 获取资金费率（带故障转移）
+func (dsm *DataSourceManager) GetFundingRateWithFallback(ctx context.Context, symbol string) (*FundingRate, error) {
+	dsm.mu.Lock()
+	sources := make([]DataSource, len(dsm.sources))
+	copy(sources, dsm.sources)
+	dsm.mu.Unlock()
+
+	var lastErr error
+
+	// 尝试所有健康的数据源
+	for _, source := range sources {
+		dsm.mu.RLock()
+		status := dsm.statuses[source.GetName()]
+		healthy := status.Healthy
+		dsm.mu.RUnlock()
+
+		if !healthy {
+			continue
+		}
+
+		rate, err := source.GetFundingRate(ctx, symbol)
+
+		dsm.mu.Lock()
+		status.TotalRequests++
+		dsm.mu.Unlock()
+
+		if err == nil && rate != nil {
+			return rate, nil
+		}
+
+		lastErr = err
+	}
+
+	return nil, fmt.Errorf("所有数据源都失败: %w", lastErr)
+}
+
 // GetStatus 获取所有数据源的状态
 func (dsm *DataSourceManager) GetStatus() map[string]*DataSourceStatus {
 	dsm.mu.RLock()
@@ -265,7 +310,7 @@ func (dsm *DataSourceManager) GetStatus() map[string]*DataSourceStatus {
 }
 
 // VerifyPriceConsistency 验证价格一致性（对比多个数据源）
-func (dsm *DataSourceManager) VerifyPriceConsistency(symbol string, maxDeviation float64) (bool, map[string]float64, error) {
+func (dsm *DataSourceManager) VerifyPriceConsistency(ctx context.Context, symbol string, maxDeviation float64) (bool, map[string]float64, error) {
 	dsm.mu.Lock()
 	sources := make([]DataSource, len(dsm.sources))
 	copy(sources, dsm.sources)
@@ -283,7 +328,7 @@ func (dsm *DataSourceManager) VerifyPriceConsistency(symbol string, maxDeviation
 			continue
 		}
 
-		ticker, err := source.GetTicker(symbol)
+		ticker, err := source.GetTicker(ctx, symbol)
 		if err == nil && ticker != nil {
 			prices[source.GetName()] = ticker.LastPrice
 		}
@@ -306,13 +351,12 @@ func (dsm *DataSourceManager) VerifyPriceConsistency(symbol string, maxDeviation
 		deviation := abs((price - avgPrice) / avgPrice)
 		if deviation > maxDeviation {
 			consistent = false
-			log.Printf("⚠️  价格异常: %s 的 %s 价格 %.2f 偏离平均值 %.2f (偏差 %.2f%%)",
-				name, symbol, price, avgPrice, deviation*100)
+			log.Warn("⚠️ 价格异常：偏离平均值", "source", name, "symbol", symbol, "price", price, "avg_price", avgPrice, "deviation_pct", deviation*100)
 		}
 	}
 
 	if consistent {
-		log.Printf("✅ 价格一致性验证通过: %s 平均价格 %.2f", symbol, avgPrice)
+		log.Info("✅ 价格一致性验证通过", "symbol", symbol, "avg_price", avgPrice)
 	}
 
 	return consistent, prices, nil
@@ -1,6 +1,7 @@
 package market
 
 import (
+	"context"
 	"fmt"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ type MockDataSource struct {
 	failTicker    bool
 	klinesData    []Kline
 	tickerData    *Ticker
+	fundingRate   *FundingRate
 	healthCheckFn func() error
 }
 
@@ -22,21 +24,32 @@ func (m *MockDataSource) GetName() string {
 	return m.name
 }
 
-func (m *MockDataSource) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+func (m *MockDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
 	if m.failKlines {
 		return nil, fmt.Errorf("mock klines error")
 	}
 	return m.klinesData, nil
 }
 
-func (m *MockDataSource) GetTicker(symbol string) (*Ticker, error) {
+func (m *MockDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
 	if m.failTicker {
 		return nil, fmt.Errorf("mock ticker error")
 	}
 	return m.tickerData, nil
 }
 
-func (m *MockDataSource) HealthCheck() error {
+func (m *MockDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	if m.fundingRate == nil {
+		return nil, fmt.Errorf("mock funding rate error")
+	}
+	return m.fundingRate, nil
+}
+
+func (m *MockDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	return nil, nil
+}
+
+func (m *MockDataSource) HealthCheck(ctx context.Context) error {
 	if m.healthCheckFn != nil {
 		return m.healthCheckFn()
 	}
@@ -243,7 +256,7 @@ func TestGetKlinesWithFallback(t *testing.T) {
 	dsm.AddSource(mock1)
 	dsm.AddSource(mock2)
 
-	klines, err := dsm.GetKlinesWithFallback("BTCUSDT", "1m", 2)
+	klines, err := dsm.GetKlinesWithFallback(context.Background(), "BTCUSDT", "1m", 2)
 	if err != nil {
 		t.Fatalf("GetKlinesWithFallback failed: %v", err)
 	}
@@ -273,7 +286,7 @@ func TestGetKlinesWithFallback_AllFail(t *testing.T) {
 	dsm.AddSource(mock1)
 	dsm.AddSource(mock2)
 
-	_, err := dsm.GetKlinesWithFallback("BTCUSDT", "1m", 2)
+	_, err := dsm.GetKlinesWithFallback(context.Background(), "BTCUSDT", "1m", 2)
 	if err == nil {
 		t.Error("Expected error when all sources fail, got nil")
 	}
@@ -298,7 +311,7 @@ func TestGetTickerWithFallback(t *testing.T) {
 	dsm.AddSource(mock1)
 	dsm.AddSource(mock2)
 
-	ticker, err := dsm.GetTickerWithFallback("BTCUSDT")
+	ticker, err := dsm.GetTickerWithFallback(context.Background(), "BTCUSDT")
 	if err != nil {
 		t.Fatalf("GetTickerWithFallback failed: %v", err)
 	}
@@ -310,6 +323,29 @@ func TestGetTickerWithFallback(t *testing.T) {
 	t.Logf("✅ GetTickerWithFallback successfully fell back to second source")
 }
 
+// TestGetFundingRateWithFallback tests funding rate fallback
+func TestGetFundingRateWithFallback(t *testing.T) {
+	dsm := NewDataSourceManager(10 * time.Second)
+
+	// First source has no funding data, second succeeds
+	mock1 := &MockDataSource{name: "source1", healthy: true}
+	mock2 := &MockDataSource{name: "source2", healthy: true, fundingRate: &FundingRate{Symbol: "BTCUSDT", Rate: 0.0001}}
+
+	dsm.AddSource(mock1)
+	dsm.AddSource(mock2)
+
+	rate, err := dsm.GetFundingRateWithFallback(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetFundingRateWithFallback failed: %v", err)
+	}
+
+	if rate.Rate != 0.0001 {
+		t.Errorf("Expected rate 0.0001, got %f", rate.Rate)
+	}
+
+	t.Logf("✅ GetFundingRateWithFallback successfully fell back to second source")
+}
+
 // TestGetStatus tests getting all source statuses
 func TestGetStatus(t *testing.T) {
 	dsm := NewDataSourceManager(10 * time.Second)
@@ -386,7 +422,7 @@ func TestVerifyPriceConsistency(t *testing.T) {
 	dsm.AddSource(mock3)
 
 	// Test with 1% max deviation (should pass)
-	consistent, prices, err := dsm.VerifyPriceConsistency("BTCUSDT", 0.01)
+	consistent, prices, err := dsm.VerifyPriceConsistency(context.Background(), "BTCUSDT", 0.01)
 	if err != nil {
 		t.Errorf("VerifyPriceConsistency failed: %v", err)
 	}
@@ -428,7 +464,7 @@ func TestVerifyPriceConsistency_Inconsistent(t *testing.T) {
 	dsm.AddSource(mock2)
 
 	// Test with 1% max deviation (should fail)
-	consistent, prices, err := dsm.VerifyPriceConsistency("BTCUSDT", 0.01)
+	consistent, prices, err := dsm.VerifyPriceConsistency(context.Background(), "BTCUSDT", 0.01)
 	if err != nil {
 		t.Errorf("VerifyPriceConsistency failed: %v", err)
 	}
@@ -460,7 +496,7 @@ func TestVerifyPriceConsistency_InsufficientSources(t *testing.T) {
 
 	dsm.AddSource(mock1)
 
-	_, _, err := dsm.VerifyPriceConsistency("BTCUSDT", 0.01)
+	_, _, err := dsm.VerifyPriceConsistency(context.Background(), "BTCUSDT", 0.01)
 	if err == nil {
 		t.Error("Expected error with insufficient sources, got nil")
 	}
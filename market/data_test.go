@@ -278,6 +278,31 @@ func TestCalculateATR_TrueRange(t *testing.T) {
 	}
 }
 
+// TestADX 单边上涨时 ADX 接近 100，来回震荡时 ADX 很低，数据不足时返回 0
+func TestADX(t *testing.T) {
+	trend := make([]Kline, 40)
+	chop := make([]Kline, 40)
+	for i := range trend {
+		p := 100 + float64(i)
+		trend[i] = Kline{High: p + 1, Low: p - 1, Close: p + 0.5}
+		q := 100.0
+		if i%2 == 1 {
+			q = 101
+		}
+		chop[i] = Kline{High: q + 1, Low: q - 1, Close: q}
+	}
+
+	if adx := ADX(trend, 14); adx < 90 {
+		t.Errorf("单边上涨 ADX = %.2f, want > 90", adx)
+	}
+	if adx := ADX(chop, 14); adx > 20 {
+		t.Errorf("震荡 ADX = %.2f, want < 20", adx)
+	}
+	if adx := ADX(trend[:28], 14); adx != 0 {
+		t.Errorf("数据不足 ADX = %.2f, want 0", adx)
+	}
+}
+
 // TestCalculateIntradaySeries_ConsistencyWithOtherIndicators 测试 Volume 和其他指标的一致性
 func TestCalculateIntradaySeries_ConsistencyWithOtherIndicators(t *testing.T) {
 	klines := generateTestKlines(30)
@@ -500,3 +525,20 @@ func TestIsStaleData_EmptyKlines(t *testing.T) {
 		t.Error("Expected false for empty klines, got true")
 	}
 }
+
+// BenchmarkIndicators 单个币种一次完整的指标计算（对应 nofx_stage_latency_seconds{stage="indicators"}）
+func BenchmarkIndicators(b *testing.B) {
+	short := generateTestKlines(500)
+	klines4h := generateTestKlines(200)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		calculateEMA(short, 20)
+		calculateMACD(short)
+		calculateRSI(short, 7)
+		calculateIntradaySeries(short)
+		calculateMidTermSeries15m(short)
+		calculateMidTermSeries1h(short)
+		calculateLongerTermData(klines4h)
+	}
+}
@@ -0,0 +1,60 @@
+package market
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// DataSourceFactory 数据源构造函数
+type DataSourceFactory func() DataSource
+
+var (
+	dataSourceRegistry   = make(map[string]DataSourceFactory)
+	dataSourceRegistryMu sync.RWMutex
+)
+
+func init() {
+	RegisterDataSource("hyperliquid", func() DataSource { return NewHyperliquidDataSource(false) })
+	RegisterDataSource("binance", func() DataSource { return NewBinanceDataSource() })
+	RegisterDataSource("okx", func() DataSource { return NewOKXDataSource() })
+	RegisterDataSource("okx-demo", func() DataSource { return NewOKXDemoDataSource() })
+	RegisterDataSource("bybit", func() DataSource { return NewBybitDataSource() })
+	RegisterDataSource("coinbase", func() DataSource { return NewCoinbaseDataSource() })
+}
+
+// DefaultFailoverChain 默认故障转移顺序
+var DefaultFailoverChain = []string{"hyperliquid", "binance", "okx"}
+
+// RegisterDataSource 注册数据源（名称不区分大小写，重复注册会覆盖）
+func RegisterDataSource(name string, factory DataSourceFactory) {
+	dataSourceRegistryMu.Lock()
+	defer dataSourceRegistryMu.Unlock()
+	dataSourceRegistry[strings.ToLower(name)] = factory
+}
+
+// NewDataSourceByName 根据注册名称创建数据源
+func NewDataSourceByName(name string) (DataSource, error) {
+	dataSourceRegistryMu.RLock()
+	factory, ok := dataSourceRegistry[strings.ToLower(name)]
+	dataSourceRegistryMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("未注册的数据源: %s（可用: %s）", name, strings.Join(RegisteredDataSources(), ", "))
+	}
+	return factory(), nil
+}
+
+// RegisteredDataSources 获取已注册的数据源名称（按字母排序）
+func RegisteredDataSources() []string {
+	dataSourceRegistryMu.RLock()
+	defer dataSourceRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(dataSourceRegistry))
+	for name := range dataSourceRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,554 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"nofx/eventbus"
+	"nofx/metrics"
+	"sync"
+	"time"
+)
+
+// FailoverConfig 故障转移配置
+type FailoverConfig struct {
+	CheckInterval    time.Duration // 健康检查间隔（默认 30 秒）
+	MaxLatency       time.Duration // 最大可接受延迟，超过视为不健康（0 表示不限制）
+	FailThreshold    int           // 当前数据源连续失败多少次后切换（默认 2）
+	RecoverThreshold int           // 更高优先级数据源连续健康多少次后切回（默认 3）
+}
+
+// sourceHealth 单个数据源的健康状态（滞后计数）
+type sourceHealth struct {
+	consecutiveFails int
+	consecutiveOK    int
+	latency          time.Duration
+	lastErr          error
+}
+
+// FailoverDataSource 按优先级串联多个数据源的故障转移数据源
+// 当前数据源健康检查失败或延迟超限达到 FailThreshold 次后切换到下一个健康数据源；
+// 更高优先级的数据源需连续健康 RecoverThreshold 次后才切回，避免来回抖动。
+// 单次请求失败时会按优先级依次尝试其余数据源。
+type FailoverDataSource struct {
+	sources  []DataSource
+	health   []*sourceHealth
+	active   int
+	config   FailoverConfig
+	mu       sync.RWMutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewFailoverDataSource 创建故障转移数据源（sources 按优先级从高到低排列）
+func NewFailoverDataSource(config FailoverConfig, sources ...DataSource) (*FailoverDataSource, error) {
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("故障转移数据源至少需要一个数据源")
+	}
+	if config.CheckInterval <= 0 {
+		config.CheckInterval = 30 * time.Second
+	}
+	if config.FailThreshold <= 0 {
+		config.FailThreshold = 2
+	}
+	if config.RecoverThreshold <= 0 {
+		config.RecoverThreshold = 3
+	}
+
+	health := make([]*sourceHealth, len(sources))
+	for i := range health {
+		health[i] = &sourceHealth{}
+	}
+
+	return &FailoverDataSource{
+		sources: sources,
+		health:  health,
+		config:  config,
+		stopCh:  make(chan struct{}),
+	}, nil
+}
+
+// NewFailoverDataSourceFromNames 按注册名称创建故障转移数据源（如 "hyperliquid", "binance", "okx"）
+func NewFailoverDataSourceFromNames(config FailoverConfig, names ...string) (*FailoverDataSource, error) {
+	if len(names) == 0 {
+		names = DefaultFailoverChain
+	}
+
+	sources := make([]DataSource, 0, len(names))
+	for _, name := range names {
+		source, err := NewDataSourceByName(name)
+		if err != nil {
+			return nil, err
+		}
+		sources = append(sources, source)
+	}
+	return NewFailoverDataSource(config, sources...)
+}
+
+// GetName 获取数据源名称
+func (f *FailoverDataSource) GetName() string {
+	return "Failover(" + f.ActiveSource().GetName() + ")"
+}
+
+// ActiveSource 获取当前使用的数据源
+func (f *FailoverDataSource) ActiveSource() DataSource {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.sources[f.active]
+}
+
+// Start 启动健康检查
+func (f *FailoverDataSource) Start() {
+	log.Info("🚀 启动故障转移数据源", "check_interval", f.config.CheckInterval)
+
+	go func() {
+		ticker := time.NewTicker(f.config.CheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				f.CheckHealth()
+			case <-f.stopCh:
+				log.Info("⏹ 故障转移数据源已停止")
+				return
+			}
+		}
+	}()
+}
+
+// Stop 停止健康检查
+func (f *FailoverDataSource) Stop() {
+	f.stopOnce.Do(func() { close(f.stopCh) })
+}
+
+// CheckHealth 检查所有数据源并按需切换
+func (f *FailoverDataSource) CheckHealth() {
+	for i, source := range f.sources {
+		start := time.Now()
+		ctx, cancel := requestContext()
+		err := source.HealthCheck(ctx)
+		cancel()
+		latency := time.Since(start)
+
+		if err == nil && f.config.MaxLatency > 0 && latency > f.config.MaxLatency {
+			err = fmt.Errorf("延迟 %v 超过阈值 %v", latency, f.config.MaxLatency)
+		}
+
+		metrics.DataSourceCheck(source.GetName(), latency, err)
+
+		f.mu.Lock()
+		f.health[i].latency = latency
+		f.recordLocked(i, err)
+		f.mu.Unlock()
+	}
+
+	f.mu.Lock()
+	f.evaluateLocked()
+	f.mu.Unlock()
+}
+
+// HealthCheck 健康检查（当前数据源）
+func (f *FailoverDataSource) HealthCheck(ctx context.Context) error {
+	return f.ActiveSource().HealthCheck(ctx)
+}
+
+// GetLatency 获取当前数据源最近一次健康检查的延迟
+func (f *FailoverDataSource) GetLatency() time.Duration {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.health[f.active].latency
+}
+
+// GetKlines 获取K线数据
+func (f *FailoverDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	var klines []Kline
+	err := f.do(func(source DataSource) error {
+		result, err := source.GetKlines(ctx, symbol, interval, limit)
+		if err != nil {
+			return err
+		}
+		if len(result) == 0 {
+			return fmt.Errorf("%s 返回空K线数据", source.GetName())
+		}
+		klines = checkKlineIntegrity(ctx, source, symbol, interval, result)
+		return nil
+	})
+	return klines, err
+}
+
+// GetTicker 获取ticker数据
+func (f *FailoverDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	var ticker *Ticker
+	err := f.do(func(source DataSource) error {
+		result, err := source.GetTicker(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			return fmt.Errorf("%s 返回空ticker", source.GetName())
+		}
+		ticker = result
+		return nil
+	})
+	return ticker, err
+}
+
+// GetFundingRate 获取当前（预测）资金费率
+func (f *FailoverDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	var rate *FundingRate
+	err := f.do(func(source DataSource) error {
+		result, err := source.GetFundingRate(ctx, symbol)
+		if err != nil {
+			return err
+		}
+		if result == nil {
+			return fmt.Errorf("%s 返回空资金费率", source.GetName())
+		}
+		rate = result
+		return nil
+	})
+	return rate, err
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率
+func (f *FailoverDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	var history []FundingRate
+	err := f.do(func(source DataSource) error {
+		result, err := source.GetFundingRateHistory(ctx, symbol, n)
+		if err != nil {
+			return err
+		}
+		history = result
+		return nil
+	})
+	return history, err
+}
+
+// StreamKlines 使用当前数据源订阅K线推送（当前数据源不支持推送时按优先级选择其他数据源）
+// 推送建立后由该数据源自行断线重连，不随健康检查切换
+func (f *FailoverDataSource) StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		streamer, ok := f.sources[idx].(KlineStreamer)
+		if !ok {
+			continue
+		}
+		ch, err := streamer.StreamKlines(ctx, symbol, interval)
+		if err == nil {
+			return ch, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 订阅K线推送失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持K线推送的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源订阅K线推送失败: %w", lastErr)
+}
+
+// StreamTickers 使用当前数据源订阅实时行情（当前数据源不支持推送时按优先级选择其他数据源）
+func (f *FailoverDataSource) StreamTickers(ctx context.Context, symbols []string) <-chan Ticker {
+	_, order := f.priorityOrder()
+	for _, idx := range order {
+		if streamer, ok := f.sources[idx].(TickerStreamer); ok {
+			return streamer.StreamTickers(ctx, symbols)
+		}
+	}
+
+	log.Warn("⚠️ 没有支持行情推送的数据源")
+	return closedTickerChannel()
+}
+
+// GetOrderBook 获取盘口深度（跳过不支持盘口查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		provider, ok := f.sources[idx].(OrderBookProvider)
+		if !ok {
+			continue
+		}
+		book, err := provider.GetOrderBook(ctx, symbol, depth)
+		if err == nil {
+			return book, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 获取盘口失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持盘口查询的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源获取盘口失败: %w", lastErr)
+}
+
+// StreamOrderBook 使用当前数据源订阅盘口推送（不支持或档数超限时按优先级选择其他数据源）
+func (f *FailoverDataSource) StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		streamer, ok := f.sources[idx].(OrderBookStreamer)
+		if !ok {
+			continue
+		}
+		ch, err := streamer.StreamOrderBook(ctx, symbol, depth)
+		if err == nil {
+			return ch, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 订阅盘口推送失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持盘口推送的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源订阅盘口推送失败: %w", lastErr)
+}
+
+// StreamTrades 使用当前数据源订阅逐笔成交（当前数据源不支持推送时按优先级选择其他数据源）
+func (f *FailoverDataSource) StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		streamer, ok := f.sources[idx].(TradeStreamer)
+		if !ok {
+			continue
+		}
+		ch, err := streamer.StreamTrades(ctx, symbol)
+		if err == nil {
+			return ch, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 订阅成交推送失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持成交推送的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源订阅成交推送失败: %w", lastErr)
+}
+
+// GetOpenInterest 获取当前持仓量（跳过不支持持仓量查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetOpenInterest(ctx context.Context, symbol string) (*OpenInterest, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		provider, ok := f.sources[idx].(OpenInterestProvider)
+		if !ok {
+			continue
+		}
+		oi, err := provider.GetOpenInterest(ctx, symbol)
+		if err == nil {
+			return oi, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 获取持仓量失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持持仓量查询的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源获取持仓量失败: %w", lastErr)
+}
+
+// GetOpenInterestHistory 获取历史持仓量（跳过不支持持仓量查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]OISnapshot, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		provider, ok := f.sources[idx].(OpenInterestProvider)
+		if !ok {
+			continue
+		}
+		history, err := provider.GetOpenInterestHistory(ctx, symbol, period, limit)
+		if err == nil {
+			return history, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 获取历史持仓量失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持持仓量查询的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源获取历史持仓量失败: %w", lastErr)
+}
+
+// GetLongShortRatio 获取多空账户人数比（跳过不支持多空比查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetLongShortRatio(ctx context.Context, symbol, period string, limit int) ([]LongShortRatio, error) {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		provider, ok := f.sources[idx].(LongShortRatioProvider)
+		if !ok {
+			continue
+		}
+		ratios, err := provider.GetLongShortRatio(ctx, symbol, period, limit)
+		if err == nil {
+			return ratios, nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 获取多空比失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("没有支持多空比查询的数据源")
+	}
+	return nil, fmt.Errorf("所有数据源获取多空比失败: %w", lastErr)
+}
+
+// GetMarkPrice 获取标记价格（跳过不支持标记价格查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	var price float64
+	err := f.eachMarkPriceProvider("标记价格", func(provider MarkPriceProvider) (err error) {
+		price, err = provider.GetMarkPrice(ctx, symbol)
+		return err
+	})
+	return price, err
+}
+
+// GetIndexPrice 获取指数价格（跳过不支持标记价格查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetIndexPrice(ctx context.Context, symbol string) (float64, error) {
+	var price float64
+	err := f.eachMarkPriceProvider("指数价格", func(provider MarkPriceProvider) (err error) {
+		price, err = provider.GetIndexPrice(ctx, symbol)
+		return err
+	})
+	return price, err
+}
+
+// GetBasisHistory 获取基差/溢价序列（跳过不支持标记价格查询的数据源，不计入健康状态）
+func (f *FailoverDataSource) GetBasisHistory(ctx context.Context, symbol, interval string, limit int) ([]BasisPoint, error) {
+	var series []BasisPoint
+	err := f.eachMarkPriceProvider("基差序列", func(provider MarkPriceProvider) (err error) {
+		series, err = provider.GetBasisHistory(ctx, symbol, interval, limit)
+		return err
+	})
+	return series, err
+}
+
+// eachMarkPriceProvider 按优先级依次尝试支持标记价格查询的数据源，直到 call 成功
+func (f *FailoverDataSource) eachMarkPriceProvider(what string, call func(provider MarkPriceProvider) error) error {
+	_, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		provider, ok := f.sources[idx].(MarkPriceProvider)
+		if !ok {
+			continue
+		}
+		err := call(provider)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		log.Warn("⚠️ 数据获取失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "what", what, "error", err)
+	}
+
+	if lastErr == nil {
+		return fmt.Errorf("没有支持标记价格查询的数据源")
+	}
+	return fmt.Errorf("所有数据源获取%s失败: %w", what, lastErr)
+}
+
+// do 先使用当前数据源，失败后按优先级依次尝试其他数据源
+func (f *FailoverDataSource) do(call func(source DataSource) error) error {
+	active, order := f.priorityOrder()
+
+	var lastErr error
+	for _, idx := range order {
+		err := call(f.sources[idx])
+
+		f.mu.Lock()
+		f.recordLocked(idx, err)
+		if idx == active && err != nil {
+			f.evaluateLocked()
+		}
+		f.mu.Unlock()
+
+		if err == nil {
+			return nil
+		}
+
+		lastErr = err
+		log.Warn("⚠️ 获取数据失败，尝试下一个数据源...", "source", f.sources[idx].GetName(), "error", err)
+	}
+
+	return fmt.Errorf("所有数据源都失败: %w", lastErr)
+}
+
+// priorityOrder 返回当前数据源下标及尝试顺序（当前数据源优先，其余按优先级）
+func (f *FailoverDataSource) priorityOrder() (int, []int) {
+	f.mu.RLock()
+	active := f.active
+	f.mu.RUnlock()
+
+	order := make([]int, 0, len(f.sources))
+	order = append(order, active)
+	for i := range f.sources {
+		if i != active {
+			order = append(order, i)
+		}
+	}
+	return active, order
+}
+
+// recordLocked 记录一次成功或失败（调用方持有写锁）
+func (f *FailoverDataSource) recordLocked(idx int, err error) {
+	h := f.health[idx]
+	if err != nil {
+		h.consecutiveFails++
+		h.consecutiveOK = 0
+		h.lastErr = err
+		return
+	}
+	h.consecutiveOK++
+	h.consecutiveFails = 0
+	h.lastErr = nil
+}
+
+// evaluateLocked 根据滞后计数决定是否切换数据源（调用方持有写锁）
+func (f *FailoverDataSource) evaluateLocked() {
+	// 更高优先级的数据源已稳定恢复：切回
+	for i := 0; i < f.active; i++ {
+		if f.health[i].consecutiveOK >= f.config.RecoverThreshold {
+			f.switchLocked(i, "更高优先级数据源已恢复")
+			return
+		}
+	}
+
+	// 当前数据源连续失败：切到优先级最高的健康数据源
+	if f.health[f.active].consecutiveFails < f.config.FailThreshold {
+		return
+	}
+	for i := range f.sources {
+		if i != f.active && f.health[i].consecutiveFails == 0 {
+			f.switchLocked(i, fmt.Sprintf("连续失败 %d 次: %v", f.health[f.active].consecutiveFails, f.health[f.active].lastErr))
+			return
+		}
+	}
+}
+
+// switchLocked 切换当前数据源（调用方持有写锁）
+func (f *FailoverDataSource) switchLocked(idx int, reason string) {
+	if idx == f.active {
+		return
+	}
+	log.Info("🔀 数据源切换", "from", f.sources[f.active].GetName(), "to", f.sources[idx].GetName(), "reason", reason)
+	eventbus.Publish(eventbus.DataSourceSwitched{
+		From:   f.sources[f.active].GetName(),
+		To:     f.sources[idx].GetName(),
+		Reason: reason,
+		Time:   time.Now(),
+	})
+	f.active = idx
+}
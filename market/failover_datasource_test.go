@@ -0,0 +1,301 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func newTestFailover(t *testing.T, config FailoverConfig, sources ...DataSource) *FailoverDataSource {
+	t.Helper()
+	f, err := NewFailoverDataSource(config, sources...)
+	if err != nil {
+		t.Fatalf("NewFailoverDataSource failed: %v", err)
+	}
+	return f
+}
+
+// TestFailoverDataSource_RequestFallback 单次请求失败时尝试下一个数据源
+func TestFailoverDataSource_RequestFallback(t *testing.T) {
+	primary := &MockDataSource{name: "Primary", healthy: true, failTicker: true}
+	secondary := &MockDataSource{name: "Secondary", healthy: true, tickerData: &Ticker{Symbol: "BTCUSDT", LastPrice: 50000}}
+
+	f := newTestFailover(t, FailoverConfig{FailThreshold: 3}, primary, secondary)
+
+	ticker, err := f.GetTicker(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("Expected fallback to succeed, got %v", err)
+	}
+	if ticker.LastPrice != 50000 {
+		t.Errorf("Expected price 50000, got %.2f", ticker.LastPrice)
+	}
+
+	// 未达到失败阈值，不应切换
+	if f.ActiveSource().GetName() != "Primary" {
+		t.Errorf("Expected active source Primary, got %s", f.ActiveSource().GetName())
+	}
+}
+
+// TestFailoverDataSource_SwitchOnHealthFailure 连续健康检查失败后切换，恢复后需达到阈值才切回
+func TestFailoverDataSource_SwitchOnHealthFailure(t *testing.T) {
+	primaryHealthy := false
+	primary := &MockDataSource{name: "Hyperliquid", healthCheckFn: func() error {
+		if !primaryHealthy {
+			return fmt.Errorf("down")
+		}
+		return nil
+	}}
+	secondary := &MockDataSource{name: "Binance", healthy: true}
+	tertiary := &MockDataSource{name: "OKX", healthy: true}
+
+	f := newTestFailover(t, FailoverConfig{FailThreshold: 2, RecoverThreshold: 2}, primary, secondary, tertiary)
+
+	f.CheckHealth()
+	if f.ActiveSource().GetName() != "Hyperliquid" {
+		t.Fatalf("Expected no switch after 1 failure, got %s", f.ActiveSource().GetName())
+	}
+
+	f.CheckHealth()
+	if f.ActiveSource().GetName() != "Binance" {
+		t.Fatalf("Expected switch to Binance after 2 failures, got %s", f.ActiveSource().GetName())
+	}
+
+	// 恢复一次不足以切回（滞后）
+	primaryHealthy = true
+	f.CheckHealth()
+	if f.ActiveSource().GetName() != "Binance" {
+		t.Errorf("Expected to stay on Binance after 1 recovery check, got %s", f.ActiveSource().GetName())
+	}
+
+	f.CheckHealth()
+	if f.ActiveSource().GetName() != "Hyperliquid" {
+		t.Errorf("Expected switch back to Hyperliquid after 2 recovery checks, got %s", f.ActiveSource().GetName())
+	}
+}
+
+// TestFailoverDataSource_SwitchOnLatency 延迟超过阈值视为不健康
+func TestFailoverDataSource_SwitchOnLatency(t *testing.T) {
+	slow := &MockDataSource{name: "Slow", healthCheckFn: func() error {
+		time.Sleep(20 * time.Millisecond)
+		return nil
+	}}
+	fast := &MockDataSource{name: "Fast", healthy: true}
+
+	f := newTestFailover(t, FailoverConfig{MaxLatency: 5 * time.Millisecond, FailThreshold: 1}, slow, fast)
+
+	f.CheckHealth()
+	if f.ActiveSource().GetName() != "Fast" {
+		t.Errorf("Expected switch to Fast due to latency, got %s", f.ActiveSource().GetName())
+	}
+}
+
+// TestFailoverDataSource_AllFail 所有数据源失败时返回错误
+func TestFailoverDataSource_AllFail(t *testing.T) {
+	a := &MockDataSource{name: "A", failKlines: true}
+	b := &MockDataSource{name: "B", failKlines: true}
+
+	f := newTestFailover(t, FailoverConfig{}, a, b)
+
+	if _, err := f.GetKlines(context.Background(), "BTCUSDT", "3m", 10); err == nil {
+		t.Error("Expected error when all sources fail")
+	}
+}
+
+// TestNewFailoverDataSourceFromNames 通过注册表创建
+func TestNewFailoverDataSourceFromNames(t *testing.T) {
+	RegisterDataSource("mock-a", func() DataSource { return &MockDataSource{name: "MockA", healthy: true} })
+
+	f, err := NewFailoverDataSourceFromNames(FailoverConfig{}, "MOCK-A")
+	if err != nil {
+		t.Fatalf("Expected registered source to resolve, got %v", err)
+	}
+	if f.ActiveSource().GetName() != "MockA" {
+		t.Errorf("Expected MockA, got %s", f.ActiveSource().GetName())
+	}
+
+	if _, err := NewFailoverDataSourceFromNames(FailoverConfig{}, "unknown"); err == nil {
+		t.Error("Expected error for unknown source")
+	}
+}
+
+// TestOKXDataSource 使用模拟服务器验证 OKX 响应解析
+func TestOKXDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v5/market/candles":
+			if r.URL.Query().Get("instId") != "BTC-USDT-SWAP" || r.URL.Query().Get("bar") != "1H" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			// OKX 按时间倒序返回
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[
+				["1700003600000","101","103","100","102","20","0.2","2040","1"],
+				["1700000000000","100","102","99","101","10","0.1","1010","1"]]}`)
+		case "/api/v5/market/ticker":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[{"last":"50123.5","vol24h":"1234"}]}`)
+		case "/api/v5/public/funding-rate":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[{"fundingRate":"0.0001","fundingTime":"1700006400000"}]}`)
+		case "/api/v5/public/time":
+			fmt.Fprint(w, `{"code":"50001","msg":"service unavailable","data":[]}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	o := NewOKXDataSource()
+	o.baseURL = server.URL
+
+	klines, err := o.GetKlines(context.Background(), "BTCUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1700000000000 || klines[1].Close != 102 {
+		t.Errorf("Unexpected klines: %+v", klines)
+	}
+	if klines[0].CloseTime != 1700000000000+3600*1000-1 {
+		t.Errorf("Unexpected close time: %d", klines[0].CloseTime)
+	}
+
+	ticker, err := o.GetTicker(context.Background(), "BTCUSDT")
+	if err != nil || ticker.LastPrice != 50123.5 {
+		t.Errorf("Unexpected ticker: %+v, err=%v", ticker, err)
+	}
+
+	rate, err := o.GetFundingRate(context.Background(), "BTCUSDT")
+	if err != nil || rate.Rate != 0.0001 || rate.FundingTime != 1700006400000 {
+		t.Errorf("Unexpected funding rate: %+v, err=%v", rate, err)
+	}
+
+	if err := o.HealthCheck(context.Background()); err == nil {
+		t.Error("Expected health check to fail on non-zero code")
+	}
+}
+
+// TestBybitDataSource 使用模拟服务器验证 Bybit 响应解析
+func TestBybitDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v5/market/kline":
+			if r.URL.Query().Get("category") != "linear" || r.URL.Query().Get("interval") != "60" {
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+			// Bybit 按时间倒序返回
+			fmt.Fprint(w, `{"retCode":0,"retMsg":"OK","result":{"list":[
+				["1700003600000","101","103","100","102","20","2040"],
+				["1700000000000","100","102","99","101","10","1010"]]}}`)
+		case "/v5/market/tickers":
+			fmt.Fprint(w, `{"retCode":0,"retMsg":"OK","result":{"list":[
+				{"lastPrice":"50123.5","volume24h":"1234","fundingRate":"0.0001","nextFundingTime":"1700006400000"}]}}`)
+		case "/v5/market/funding/history":
+			fmt.Fprint(w, `{"retCode":0,"retMsg":"OK","result":{"list":[
+				{"fundingRate":"0.0002","fundingRateTimestamp":"1700028800000"},
+				{"fundingRate":"0.0001","fundingRateTimestamp":"1700000000000"}]}}`)
+		case "/v5/market/time":
+			fmt.Fprint(w, `{"retCode":10002,"retMsg":"server busy","result":{}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	b := NewBybitDataSource()
+	b.baseURL = server.URL
+
+	klines, err := b.GetKlines(context.Background(), "BTCUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1700000000000 || klines[1].Close != 102 || klines[1].QuoteVolume != 2040 {
+		t.Errorf("Unexpected klines: %+v", klines)
+	}
+
+	ticker, err := b.GetTicker(context.Background(), "BTCUSDT")
+	if err != nil || ticker.LastPrice != 50123.5 {
+		t.Errorf("Unexpected ticker: %+v, err=%v", ticker, err)
+	}
+
+	rate, err := b.GetFundingRate(context.Background(), "BTCUSDT")
+	if err != nil || rate.Rate != 0.0001 || rate.FundingTime != 1700006400000 {
+		t.Errorf("Unexpected funding rate: %+v, err=%v", rate, err)
+	}
+
+	history, err := b.GetFundingRateHistory(context.Background(), "BTCUSDT", 2)
+	if err != nil || len(history) != 2 || history[0].FundingTime != 1700000000000 {
+		t.Errorf("Unexpected funding history: %+v, err=%v", history, err)
+	}
+
+	if err := b.HealthCheck(context.Background()); err == nil {
+		t.Error("Expected health check to fail on non-zero retCode")
+	}
+
+	if _, err := b.GetKlines(context.Background(), "BTCUSDT", "2m", 2); err == nil {
+		t.Error("Expected error for unsupported interval")
+	}
+}
+
+// TestCoinbaseDataSource 使用模拟服务器验证 Coinbase 响应解析
+func TestCoinbaseDataSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/products/BTC-USD/candles":
+			// Coinbase 按时间倒序返回 [time, low, high, open, close, volume]
+			switch r.URL.Query().Get("granularity") {
+			case "3600":
+				fmt.Fprint(w, `[[1700007200,101,104,102,103,5],[1700003600,100,103,101,102,20],[1700000000,99,102,100,101,10]]`)
+			case "60":
+				// 1700000040 不是 3m 周期起点（所在周期不完整），1700000100 起为完整周期
+				fmt.Fprint(w, `[[1700000280,104,106,105,106,1],[1700000220,103,105,104,105,1],[1700000160,102,104,103,104,1],[1700000100,101,103,102,103,2],[1700000040,100,102,101,102,3]]`)
+			default:
+				t.Errorf("unexpected query: %s", r.URL.RawQuery)
+			}
+		case "/products/BTC-USD/ticker":
+			fmt.Fprint(w, `{"price":"50123.5","volume":"1234"}`)
+		case "/time":
+			fmt.Fprint(w, `{"iso":"2023-11-14T22:13:20Z","epoch":1700000000}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	c := NewCoinbaseDataSource()
+	c.baseURL = server.URL
+
+	klines, err := c.GetKlines(context.Background(), "BTCUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1700003600000 || klines[1].Close != 103 || klines[0].Low != 100 {
+		t.Errorf("Unexpected klines: %+v", klines)
+	}
+
+	ticker, err := c.GetTicker(context.Background(), "BTCUSDT")
+	if err != nil || ticker.LastPrice != 50123.5 {
+		t.Errorf("Unexpected ticker: %+v, err=%v", ticker, err)
+	}
+
+	if _, err := c.GetFundingRate(context.Background(), "BTCUSDT"); err == nil {
+		t.Error("Expected funding rate to be unsupported")
+	}
+
+	if err := c.HealthCheck(context.Background()); err != nil {
+		t.Errorf("Unexpected health check error: %v", err)
+	}
+
+	// 3m 不是 Coinbase 原生周期，由 1m K线本地聚合
+	klines, err = c.GetKlines(context.Background(), "BTCUSDT", "3m", 2)
+	if err != nil {
+		t.Fatalf("GetKlines 3m failed: %v", err)
+	}
+	if len(klines) != 2 || klines[0].OpenTime != 1700000100000 || klines[0].Volume != 4 ||
+		klines[0].Open != 102 || klines[0].Close != 105 || klines[1].OpenTime != 1700000280000 {
+		t.Errorf("Unexpected resampled klines: %+v", klines)
+	}
+
+	if _, err := c.GetKlines(context.Background(), "BTCUSDT", "7m", 2); err == nil {
+		t.Error("Expected error for unsupported interval")
+	}
+}
@@ -2,8 +2,10 @@ package market
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"log"
+	"nofx/metrics"
+	"nofx/netconfig"
 	"strconv"
 	"strings"
 	"time"
@@ -13,30 +15,30 @@ import (
 
 // HyperliquidDataSource 封装 Hyperliquid 作为数据源
 type HyperliquidDataSource struct {
-	info *hyperliquid.Info
-	ctx  context.Context
-	name string
+	info  *hyperliquid.Info
+	wsURL string
+	name  string
 }
 
 // NewHyperliquidDataSource 创建 Hyperliquid 数据源实例（不需要认证，只用于获取公开市场数据）
 func NewHyperliquidDataSource(testnet bool) *HyperliquidDataSource {
 	// 选择 API URL
 	baseURL := hyperliquid.MainnetAPIURL
+	wsURL := "wss://api.hyperliquid.xyz/ws"
 	if testnet {
 		baseURL = hyperliquid.TestnetAPIURL
+		wsURL = "wss://api.hyperliquid-testnet.xyz/ws"
 	}
 
-	ctx := context.Background()
-
 	// 创建 Info 客户端（公开API，不需要私钥）
 	// skipWS=true: 不需要 WebSocket
 	// meta=nil, spotMeta=nil: 会自动获取
-	info := hyperliquid.NewInfo(ctx, baseURL, true, nil, nil)
+	info := hyperliquid.NewInfo(context.Background(), netconfig.BaseURL("hyperliquid", baseURL), true, nil, nil)
 
 	return &HyperliquidDataSource{
-		info: info,
-		ctx:  ctx,
-		name: "Hyperliquid",
+		info:  info,
+		wsURL: wsURL,
+		name:  "Hyperliquid",
 	}
 }
 
@@ -46,7 +48,7 @@ func (h *HyperliquidDataSource) GetName() string {
 }
 
 // GetKlines 获取K线数据
-func (h *HyperliquidDataSource) GetKlines(symbol, interval string, limit int) ([]Kline, error) {
+func (h *HyperliquidDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
 	// 转换 symbol: BTCUSDT -> BTC
 	coin := convertSymbolToHyperliquid(symbol)
 
@@ -55,9 +57,9 @@ func (h *HyperliquidDataSource) GetKlines(symbol, interval string, limit int) ([
 	startTime := calculateStartTime(endTime, interval, limit)
 
 	// 获取 Candles 数据
-	candles, err := h.info.CandlesSnapshot(h.ctx, coin, interval, startTime, endTime)
+	candles, err := h.info.CandlesSnapshot(ctx, coin, interval, startTime, endTime)
 	if err != nil {
-		log.Printf("⚠️  Hyperliquid GetKlines 失败 [%s %s]: %v", symbol, interval, err)
+		log.Warn("⚠️ Hyperliquid GetKlines 失败", "symbol", symbol, "interval", interval, "error", err)
 		return nil, fmt.Errorf("hyperliquid GetKlines failed: %w", err)
 	}
 
@@ -66,7 +68,7 @@ func (h *HyperliquidDataSource) GetKlines(symbol, interval string, limit int) ([
 	for _, candle := range candles {
 		kline, err := convertCandleToKline(candle)
 		if err != nil {
-			log.Printf("⚠️  转换 Candle 失败: %v", err)
+			log.Warn("⚠️ 转换 Candle 失败", "error", err)
 			continue
 		}
 		klines = append(klines, kline)
@@ -81,19 +83,40 @@ func (h *HyperliquidDataSource) GetKlines(symbol, interval string, limit int) ([
 		klines = klines[len(klines)-limit:]
 	}
 
-	log.Printf("✅ Hyperliquid GetKlines 成功 [%s %s]: %d 条数据", symbol, interval, len(klines))
+	log.Info("✅ Hyperliquid GetKlines 成功", "symbol", symbol, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// GetKlinesRange 按时间范围获取历史K线（用于补齐缺失的K线）
+func (h *HyperliquidDataSource) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	candles, err := h.info.CandlesSnapshot(ctx, convertSymbolToHyperliquid(symbol), interval, startTime, endTime)
+	if err != nil {
+		return nil, fmt.Errorf("hyperliquid GetKlinesRange failed: %w", err)
+	}
+
+	klines := make([]Kline, 0, len(candles))
+	for _, candle := range candles {
+		kline, err := convertCandleToKline(candle)
+		if err != nil {
+			continue
+		}
+		klines = append(klines, kline)
+	}
+	if len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
 	return klines, nil
 }
 
 // GetTicker 获取ticker数据
-func (h *HyperliquidDataSource) GetTicker(symbol string) (*Ticker, error) {
+func (h *HyperliquidDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
 	// 转换 symbol: BTCUSDT -> BTC
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有 Mids 价格
-	mids, err := h.info.AllMids(h.ctx)
+	mids, err := h.info.AllMids(ctx)
 	if err != nil {
-		log.Printf("⚠️  Hyperliquid GetTicker 失败 [%s]: %v", symbol, err)
+		log.Warn("⚠️ Hyperliquid GetTicker 失败", "symbol", symbol, "error", err)
 		return nil, fmt.Errorf("hyperliquid GetTicker failed: %w", err)
 	}
 
@@ -114,33 +137,317 @@ func (h *HyperliquidDataSource) GetTicker(symbol string) (*Ticker, error) {
 		Timestamp: time.Now().Unix(),
 	}
 
-	log.Printf("✅ Hyperliquid GetTicker 成功 [%s]: %.2f", symbol, price)
+	log.Info("✅ Hyperliquid GetTicker 成功", "symbol", symbol, "price", price)
 	return ticker, nil
 }
 
+// GetFundingRate 获取当前（预测）资金费率（Hyperliquid 每小时结算一次）
+func (h *HyperliquidDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+
+	metaAndCtxs, err := h.info.MetaAndAssetCtxs(ctx)
+	if err != nil {
+		log.Warn("⚠️ Hyperliquid GetFundingRate 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("hyperliquid GetFundingRate failed: %w", err)
+	}
+
+	for i, asset := range metaAndCtxs.Universe {
+		if asset.Name != coin || i >= len(metaAndCtxs.Ctxs) {
+			continue
+		}
+		rate, err := strconv.ParseFloat(metaAndCtxs.Ctxs[i].Funding, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse funding rate failed: %w", err)
+		}
+		return &FundingRate{
+			Symbol:      symbol,
+			Rate:        rate,
+			FundingTime: time.Now().Truncate(time.Hour).Add(time.Hour).UnixMilli(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("funding rate not found for %s (%s)", symbol, coin)
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (h *HyperliquidDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+
+	// 每小时结算一次，多取一小时避免边界遗漏
+	startTime := time.Now().Add(-time.Duration(n+1) * time.Hour).UnixMilli()
+	items, err := h.info.FundingHistory(ctx, coin, startTime, nil)
+	if err != nil {
+		log.Warn("⚠️ Hyperliquid GetFundingRateHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("hyperliquid GetFundingRateHistory failed: %w", err)
+	}
+
+	history := make([]FundingRate, 0, len(items))
+	for _, item := range items {
+		rate, err := strconv.ParseFloat(item.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, FundingRate{Symbol: symbol, Rate: rate, FundingTime: item.Time})
+	}
+
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	return history, nil
+}
+
 // HealthCheck 健康检查
-func (h *HyperliquidDataSource) HealthCheck() error {
+func (h *HyperliquidDataSource) HealthCheck(ctx context.Context) error {
 	// 尝试获取 AllMids 作为健康检查
-	_, err := h.info.AllMids(h.ctx)
+	_, err := h.info.AllMids(ctx)
 	if err != nil {
-		log.Printf("❌ Hyperliquid 健康检查失败: %v", err)
+		log.Error("❌ Hyperliquid 健康检查失败", "error", err)
 		return fmt.Errorf("hyperliquid health check failed: %w", err)
 	}
 
-	log.Printf("✅ Hyperliquid 健康检查成功")
+	log.Info("✅ Hyperliquid 健康检查成功")
 	return nil
 }
 
 // GetLatency 获取延迟
 func (h *HyperliquidDataSource) GetLatency() time.Duration {
+	ctx, cancel := requestContext()
+	defer cancel()
 	start := time.Now()
-	_ = h.HealthCheck()
+	err := h.HealthCheck(ctx)
 	latency := time.Since(start)
+	metrics.DataSourceCheck(h.GetName(), latency, err)
 
-	log.Printf("📊 Hyperliquid 延迟: %v", latency)
+	log.Info("📊 Hyperliquid 延迟", "latency", latency)
 	return latency
 }
 
+// GetOrderBook 获取盘口深度（Hyperliquid 每侧最多 20 档）
+func (h *HyperliquidDataSource) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+	book, err := h.info.L2Snapshot(ctx, coin)
+	if err != nil {
+		log.Warn("⚠️ Hyperliquid GetOrderBook 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("hyperliquid GetOrderBook failed: %w", err)
+	}
+	return convertL2Book(symbol, *book).truncate(depth), nil
+}
+
+// StreamKlines 订阅 Hyperliquid K线推送（替代轮询 CandlesSnapshot）
+func (h *HyperliquidDataSource) StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	return startKlineStream(ctx, h.name, &hyperliquidKlineProtocol{wsURL: h.wsURL}, h.GetKlines, symbol, interval)
+}
+
+// hyperliquidKlineProtocol Hyperliquid candle 订阅协议
+type hyperliquidKlineProtocol struct {
+	wsURL string
+}
+
+func (p *hyperliquidKlineProtocol) url(symbol, interval string) (string, error) {
+	return p.wsURL, nil
+}
+
+func (p *hyperliquidKlineProtocol) subscribeMessages(symbol, interval string) ([]interface{}, error) {
+	return []interface{}{map[string]interface{}{
+		"method": "subscribe",
+		"subscription": map[string]string{
+			"type":     "candle",
+			"coin":     convertSymbolToHyperliquid(symbol),
+			"interval": interval,
+		},
+	}}, nil
+}
+
+func (p *hyperliquidKlineProtocol) heartbeat() interface{} {
+	return map[string]string{"method": "ping"} // 60 秒无消息会被服务端断开
+}
+
+func (p *hyperliquidKlineProtocol) parse(message []byte, intervalMs int64) ([]Kline, error) {
+	var msg struct {
+		Channel string             `json:"channel"`
+		Data    hyperliquid.Candle `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Channel != "candle" {
+		return nil, nil // subscriptionResponse / pong
+	}
+
+	kline, err := convertCandleToKline(msg.Data)
+	if err != nil {
+		return nil, err
+	}
+	return []Kline{kline}, nil
+}
+
+// StreamTickers 订阅 Hyperliquid 中间价推送（allMids，每个区块推送一次）
+func (h *HyperliquidDataSource) StreamTickers(ctx context.Context, symbols []string) <-chan Ticker {
+	return startTickerStream(ctx, h.name, symbols, func(symbols []string) tickerStreamProtocol {
+		p := &hyperliquidTickerProtocol{wsURL: h.wsURL, symbols: make(map[string]string, len(symbols))}
+		for _, symbol := range symbols {
+			p.symbols[convertSymbolToHyperliquid(symbol)] = symbol
+		}
+		return p
+	})
+}
+
+// hyperliquidTickerProtocol Hyperliquid allMids 订阅协议
+type hyperliquidTickerProtocol struct {
+	wsURL   string
+	symbols map[string]string // coin -> symbol
+}
+
+func (p *hyperliquidTickerProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *hyperliquidTickerProtocol) subscribeMessages() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": map[string]string{"type": "allMids"},
+	}}
+}
+
+func (p *hyperliquidTickerProtocol) heartbeat() interface{} {
+	return map[string]string{"method": "ping"}
+}
+
+func (p *hyperliquidTickerProtocol) parse(message []byte) ([]Ticker, error) {
+	var msg struct {
+		Channel string `json:"channel"`
+		Data    struct {
+			Mids map[string]string `json:"mids"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Channel != "allMids" {
+		return nil, nil // subscriptionResponse / pong
+	}
+
+	now := time.Now().Unix()
+	tickers := make([]Ticker, 0, len(p.symbols))
+	for coin, priceStr := range msg.Data.Mids {
+		symbol, ok := p.symbols[coin]
+		if !ok {
+			continue
+		}
+		price, err := strconv.ParseFloat(priceStr, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price failed: %w", err)
+		}
+		tickers = append(tickers, Ticker{Symbol: symbol, LastPrice: price, Timestamp: now})
+	}
+	return tickers, nil
+}
+
+// StreamOrderBook 订阅 Hyperliquid 盘口推送（l2Book，每个区块推送一次快照）
+func (h *HyperliquidDataSource) StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error) {
+	protocol := &hyperliquidOrderBookProtocol{wsURL: h.wsURL, symbol: symbol, depth: depth}
+	return startOrderBookStream(ctx, h.name, symbol, depth, maxStreamOrderBookDepth, protocol)
+}
+
+// hyperliquidOrderBookProtocol Hyperliquid l2Book 订阅协议
+type hyperliquidOrderBookProtocol struct {
+	wsURL  string
+	symbol string
+	depth  int
+}
+
+func (p *hyperliquidOrderBookProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *hyperliquidOrderBookProtocol) subscribeMessages() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": map[string]string{"type": "l2Book", "coin": convertSymbolToHyperliquid(p.symbol)},
+	}}
+}
+
+func (p *hyperliquidOrderBookProtocol) heartbeat() interface{} {
+	return map[string]string{"method": "ping"}
+}
+
+func (p *hyperliquidOrderBookProtocol) parse(message []byte) ([]OrderBook, error) {
+	var msg struct {
+		Channel string             `json:"channel"`
+		Data    hyperliquid.L2Book `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Channel != "l2Book" {
+		return nil, nil // subscriptionResponse / pong
+	}
+	return []OrderBook{*convertL2Book(p.symbol, msg.Data).truncate(p.depth)}, nil
+}
+
+// StreamTrades 订阅 Hyperliquid 逐笔成交推送
+func (h *HyperliquidDataSource) StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error) {
+	return startTradeStream(ctx, h.name, symbol, &hyperliquidTradeProtocol{wsURL: h.wsURL, symbol: symbol})
+}
+
+// hyperliquidTradeProtocol Hyperliquid trades 订阅协议
+type hyperliquidTradeProtocol struct {
+	wsURL  string
+	symbol string
+}
+
+func (p *hyperliquidTradeProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *hyperliquidTradeProtocol) subscribeMessages() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"method":       "subscribe",
+		"subscription": map[string]string{"type": "trades", "coin": convertSymbolToHyperliquid(p.symbol)},
+	}}
+}
+
+func (p *hyperliquidTradeProtocol) heartbeat() interface{} {
+	return map[string]string{"method": "ping"}
+}
+
+func (p *hyperliquidTradeProtocol) parse(message []byte) ([]Trade, error) {
+	var msg struct {
+		Channel string `json:"channel"`
+		Data    []struct {
+			Side string `json:"side"` // 主动成交方向：B=买入，A=卖出
+			Px   string `json:"px"`
+			Sz   string `json:"sz"`
+			Time int64  `json:"time"`
+			Tid  int64  `json:"tid"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Channel != "trades" {
+		return nil, nil // subscriptionResponse / pong
+	}
+
+	trades := make([]Trade, 0, len(msg.Data))
+	for _, d := range msg.Data {
+		price, err := strconv.ParseFloat(d.Px, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price failed: %w", err)
+		}
+		qty, err := strconv.ParseFloat(d.Sz, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse size failed: %w", err)
+		}
+		side := TradeSideBuy
+		if d.Side == "A" {
+			side = TradeSideSell
+		}
+		trades = append(trades, Trade{Symbol: p.symbol, TradeID: d.Tid, Price: price, Quantity: qty, Side: side, Timestamp: d.Time})
+	}
+	return trades, nil
+}
+
 // === Helper functions ===
 
 // convertSymbolToHyperliquid 将 Binance 格式的 symbol 转换为 Hyperliquid 格式
@@ -205,42 +512,7 @@ func convertCandleToKline(candle hyperliquid.Candle) (Kline, error) {
 
 // calculateStartTime 根据 interval 和 limit 计算开始时间
 func calculateStartTime(endTime int64, interval string, limit int) int64 {
-	// 将 interval 转换为毫秒
-	var intervalMs int64
-
-	switch interval {
-	case "1m":
-		intervalMs = 60 * 1000
-	case "3m":
-		intervalMs = 3 * 60 * 1000
-	case "5m":
-		intervalMs = 5 * 60 * 1000
-	case "15m":
-		intervalMs = 15 * 60 * 1000
-	case "30m":
-		intervalMs = 30 * 60 * 1000
-	case "1h":
-		intervalMs = 60 * 60 * 1000
-	case "2h":
-		intervalMs = 2 * 60 * 60 * 1000
-	case "4h":
-		intervalMs = 4 * 60 * 60 * 1000
-	case "8h":
-		intervalMs = 8 * 60 * 60 * 1000
-	case "12h":
-		intervalMs = 12 * 60 * 60 * 1000
-	case "1d":
-		intervalMs = 24 * 60 * 60 * 1000
-	case "3d":
-		intervalMs = 3 * 24 * 60 * 60 * 1000
-	case "1w":
-		intervalMs = 7 * 24 * 60 * 60 * 1000
-	case "1M":
-		intervalMs = 30 * 24 * 60 * 60 * 1000 // 近似值
-	default:
-		// 默认使用 15 分钟
-		intervalMs = 15 * 60 * 1000
-	}
+	intervalMs := intervalToMillis(interval)
 
 	// 开始时间 = 结束时间 - (limit * interval)
 	startTime := endTime - (int64(limit) * intervalMs)
@@ -250,3 +522,30 @@ func calculateStartTime(endTime int64, interval string, limit int) int64 {
 
 	return startTime
 }
+
+// intervalToMillis 将K线周期转换为毫秒（未知周期默认 15 分钟）
+func intervalToMillis(interval string) int64 {
+	if d, ok := TimeframeDuration(interval); ok {
+		return d.Milliseconds()
+	}
+	return 15 * 60 * 1000
+}
+
+// convertL2Book 转换 Hyperliquid 盘口（levels[0]=买盘，levels[1]=卖盘）
+func convertL2Book(symbol string, book hyperliquid.L2Book) *OrderBook {
+	result := &OrderBook{Symbol: symbol, Timestamp: book.Time}
+	convert := func(levels []hyperliquid.Level) []OrderBookLevel {
+		out := make([]OrderBookLevel, len(levels))
+		for i, l := range levels {
+			out[i] = OrderBookLevel{Price: l.Px, Quantity: l.Sz}
+		}
+		return out
+	}
+	if len(book.Levels) > 0 {
+		result.Bids = convert(book.Levels[0])
+	}
+	if len(book.Levels) > 1 {
+		result.Asks = convert(book.Levels[1])
+	}
+	return result
+}
@@ -0,0 +1,229 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// K线异常类型
+const (
+	KlineAnomalyGap       = "gap"       // 缺失K线
+	KlineAnomalyDuplicate = "duplicate" // 重复的开盘时间
+	KlineAnomalyZero      = "zero_ohlc" // 开高低收存在 0 或负值
+	KlineAnomalyUnordered = "unordered" // 未按时间正序排列
+)
+
+// K线缺口修复方式
+const (
+	KlineRepairNone        = "none"        // 只报告，不修复
+	KlineRepairInterpolate = "interpolate" // 线性插值补齐
+	KlineRepairRefetch     = "refetch"     // 按时间范围重新拉取，仍缺失的部分插值补齐
+)
+
+// maxKlineRefetchGaps 单次校验最多重新拉取的缺口数量（避免大量请求）
+const maxKlineRefetchGaps = 3
+
+var (
+	// KlineRepairMode K线缺口修复方式（KlineRepairNone / KlineRepairInterpolate / KlineRepairRefetch）
+	KlineRepairMode = KlineRepairRefetch
+	// OnKlineAnomaly 发现K线异常时的回调（nil=仅记录日志）
+	OnKlineAnomaly func(report KlineIntegrityReport)
+)
+
+// KlineAnomaly 一处K线异常
+type KlineAnomaly struct {
+	Type     string `json:"type"`
+	OpenTime int64  `json:"open_time"` // 异常K线（缺口为缺失的第一根）的开盘时间
+	Count    int    `json:"count"`     // 缺失/重复的K线数量
+}
+
+// KlineIntegrityReport K线校验结果
+type KlineIntegrityReport struct {
+	Source       string         `json:"source"`
+	Symbol       string         `json:"symbol"`
+	Interval     string         `json:"interval"`
+	Anomalies    []KlineAnomaly `json:"anomalies"`
+	Refetched    int            `json:"refetched"`    // 重新拉取补齐的K线数量
+	Interpolated int            `json:"interpolated"` // 插值补齐的K线数量
+}
+
+// Missing 缺失的K线总数
+func (r KlineIntegrityReport) Missing() int {
+	missing := 0
+	for _, a := range r.Anomalies {
+		if a.Type == KlineAnomalyGap {
+			missing += a.Count
+		}
+	}
+	return missing
+}
+
+// String 异常摘要
+func (r KlineIntegrityReport) String() string {
+	counts := make(map[string]int)
+	for _, a := range r.Anomalies {
+		counts[a.Type] += a.Count
+	}
+	parts := make([]string, 0, len(counts))
+	for _, t := range []string{KlineAnomalyGap, KlineAnomalyDuplicate, KlineAnomalyZero, KlineAnomalyUnordered} {
+		if counts[t] > 0 {
+			parts = append(parts, fmt.Sprintf("%s=%d", t, counts[t]))
+		}
+	}
+	return fmt.Sprintf("%s %s %s: %s（重新拉取 %d，插值 %d）",
+		r.Source, r.Symbol, r.Interval, strings.Join(parts, ", "), r.Refetched, r.Interpolated)
+}
+
+// ValidateKlines 检查K线序列的缺口、重复时间戳、非正序和 0 值（不修改输入）
+func ValidateKlines(klines []Kline, interval string) []KlineAnomaly {
+	intervalMs := intervalToMillis(interval)
+	var anomalies []KlineAnomaly
+	for i, k := range klines {
+		if k.Open <= 0 || k.High <= 0 || k.Low <= 0 || k.Close <= 0 {
+			anomalies = append(anomalies, KlineAnomaly{Type: KlineAnomalyZero, OpenTime: k.OpenTime, Count: 1})
+		}
+		if i == 0 {
+			continue
+		}
+		diff := k.OpenTime - klines[i-1].OpenTime
+		switch {
+		case diff == 0:
+			anomalies = append(anomalies, KlineAnomaly{Type: KlineAnomalyDuplicate, OpenTime: k.OpenTime, Count: 1})
+		case diff < 0:
+			anomalies = append(anomalies, KlineAnomaly{Type: KlineAnomalyUnordered, OpenTime: k.OpenTime, Count: 1})
+		case diff > intervalMs:
+			anomalies = append(anomalies, KlineAnomaly{
+				Type:     KlineAnomalyGap,
+				OpenTime: klines[i-1].OpenTime + intervalMs,
+				Count:    int(diff/intervalMs) - 1,
+			})
+		}
+	}
+	return anomalies
+}
+
+// RepairKlines 修复K线序列：排序、去重（保留后出现的）、修补或剔除 0 值K线，并按 mode 补齐缺口
+// provider 为 nil 或无法补齐时，refetch 模式退化为插值
+func RepairKlines(ctx context.Context, klines []Kline, symbol, interval, mode string, provider KlineRangeProvider) ([]Kline, KlineIntegrityReport) {
+	report := KlineIntegrityReport{Symbol: symbol, Interval: interval, Anomalies: ValidateKlines(klines, interval)}
+	if len(report.Anomalies) == 0 || mode == KlineRepairNone {
+		return klines, report
+	}
+
+	// 收盘价无效的K线剔除（之后作为缺口补齐），其余 0 值用收盘价修补，并按开盘时间去重排序
+	valid := make([]Kline, 0, len(klines))
+	for _, k := range klines {
+		if k.Close > 0 {
+			valid = append(valid, patchZeroPrices(k))
+		}
+	}
+	repaired := unionKlines(nil, valid)
+	if len(repaired) < 2 {
+		return repaired, report
+	}
+
+	intervalMs := intervalToMillis(interval)
+	if mode == KlineRepairRefetch && provider != nil {
+		repaired, report.Refetched = refetchKlineGaps(ctx, repaired, symbol, interval, intervalMs, provider)
+	}
+	repaired, report.Interpolated = interpolateKlineGaps(repaired, intervalMs)
+	return repaired, report
+}
+
+// patchZeroPrices 用收盘价修补为 0 的开/高/低价
+func patchZeroPrices(k Kline) Kline {
+	if k.Open <= 0 {
+		k.Open = k.Close
+	}
+	if k.High <= 0 {
+		k.High = math.Max(k.Open, k.Close)
+	}
+	if k.Low <= 0 {
+		k.Low = math.Min(k.Open, k.Close)
+	}
+	return k
+}
+
+// refetchKlineGaps 按时间范围重新拉取缺口内的K线（最多 maxKlineRefetchGaps 个缺口）
+func refetchKlineGaps(ctx context.Context, klines []Kline, symbol, interval string, intervalMs int64, provider KlineRangeProvider) ([]Kline, int) {
+	var fetched []Kline
+	gaps := 0
+	for i := 1; i < len(klines) && gaps < maxKlineRefetchGaps; i++ {
+		missing := int((klines[i].OpenTime-klines[i-1].OpenTime)/intervalMs) - 1
+		if missing <= 0 {
+			continue
+		}
+		gaps++
+		start, end := klines[i-1].OpenTime+intervalMs, klines[i].OpenTime-intervalMs
+		page, err := provider.GetKlinesRange(ctx, symbol, interval, start, end, missing)
+		if err != nil {
+			log.Warn("⚠️ 重新拉取缺失K线失败", "symbol", symbol, "interval", interval, "error", err)
+			continue
+		}
+		for _, k := range page {
+			if k.OpenTime >= start && k.OpenTime <= end && k.Open > 0 && k.High > 0 && k.Low > 0 && k.Close > 0 {
+				fetched = append(fetched, k)
+			}
+		}
+	}
+	if len(fetched) == 0 {
+		return klines, 0
+	}
+	merged := unionKlines(klines, fetched)
+	return merged, len(merged) - len(klines)
+}
+
+// interpolateKlineGaps 用前后两根K线的价格线性插值补齐缺口（插值K线成交量为 0）
+func interpolateKlineGaps(klines []Kline, intervalMs int64) ([]Kline, int) {
+	result := make([]Kline, 0, len(klines))
+	added := 0
+	for i, k := range klines {
+		if i > 0 {
+			prev := klines[i-1]
+			missing := int((k.OpenTime-prev.OpenTime)/intervalMs) - 1
+			for j := 1; j <= missing; j++ {
+				price := prev.Close + (k.Open-prev.Close)*float64(j)/float64(missing+1)
+				openTime := prev.OpenTime + int64(j)*intervalMs
+				result = append(result, Kline{
+					OpenTime:  openTime,
+					Open:      price,
+					High:      price,
+					Low:       price,
+					Close:     price,
+					CloseTime: openTime + intervalMs - 1,
+				})
+				added++
+			}
+		}
+		result = append(result, k)
+	}
+	return result, added
+}
+
+// checkKlineIntegrity 校验数据源返回的K线并按 KlineRepairMode 修复，发现异常时记录日志并回调 OnKlineAnomaly
+func checkKlineIntegrity(ctx context.Context, source DataSource, symbol, interval string, klines []Kline) []Kline {
+	provider, _ := source.(KlineRangeProvider)
+	return checkKlineIntegrityWith(ctx, source.GetName(), provider, symbol, interval, klines)
+}
+
+// checkKlineIntegrityWith 同 checkKlineIntegrity，直接指定数据源名称和范围查询接口
+func checkKlineIntegrityWith(ctx context.Context, sourceName string, provider KlineRangeProvider, symbol, interval string, klines []Kline) []Kline {
+	repaired, report := RepairKlines(ctx, klines, symbol, interval, KlineRepairMode, provider)
+	if len(report.Anomalies) == 0 {
+		return klines
+	}
+
+	report.Source = sourceName
+	log.Warn("⚠️ K线数据异常", "report", report)
+	if OnKlineAnomaly != nil {
+		OnKlineAnomaly(report)
+	}
+
+	// 补齐缺口后保持与原序列相同的长度（保留最新的K线）
+	if len(repaired) > len(klines) {
+		repaired = repaired[len(repaired)-len(klines):]
+	}
+	return repaired
+}
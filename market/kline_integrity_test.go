@@ -0,0 +1,109 @@
+package market
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// rangeProviderFunc 用函数实现 KlineRangeProvider
+type rangeProviderFunc func(symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error)
+
+func (f rangeProviderFunc) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	return f(symbol, interval, startTime, endTime, limit)
+}
+
+// testKline 生成第 i 根1分钟K线
+func testKline(i int, price float64) Kline {
+	openTime := int64(i) * time.Minute.Milliseconds()
+	return Kline{OpenTime: openTime, Open: price, High: price, Low: price, Close: price, CloseTime: openTime + 59999}
+}
+
+func TestValidateKlines(t *testing.T) {
+	klines := []Kline{
+		testKline(0, 100),
+		testKline(1, 101),
+		testKline(1, 101), // 重复
+		testKline(4, 104), // 缺失 2、3
+		{OpenTime: 5 * 60000, Close: 105},
+	}
+
+	anomalies := ValidateKlines(klines, "1m")
+	if len(anomalies) != 3 {
+		t.Fatalf("期望 3 处异常，实际 %+v", anomalies)
+	}
+	if anomalies[0].Type != KlineAnomalyDuplicate || anomalies[1].Type != KlineAnomalyGap || anomalies[2].Type != KlineAnomalyZero {
+		t.Errorf("异常类型错误: %+v", anomalies)
+	}
+	if anomalies[1].OpenTime != 2*60000 || anomalies[1].Count != 2 {
+		t.Errorf("缺口位置错误: %+v", anomalies[1])
+	}
+
+	if got := ValidateKlines([]Kline{testKline(0, 1), testKline(1, 1)}, "1m"); len(got) != 0 {
+		t.Errorf("连续K线不应有异常: %+v", got)
+	}
+}
+
+func TestRepairKlines(t *testing.T) {
+	klines := []Kline{
+		testKline(3, 103),
+		testKline(0, 100),
+		testKline(0, 100),
+		{OpenTime: 4 * 60000, Close: 0}, // 收盘价无效，剔除后补齐
+		testKline(5, 105),
+	}
+
+	t.Run("插值", func(t *testing.T) {
+		repaired, report := RepairKlines(context.Background(), klines, "BTCUSDT", "1m", KlineRepairInterpolate, nil)
+		if len(repaired) != 6 || report.Interpolated != 3 {
+			t.Fatalf("期望补齐为 6 根（插值 3 根），实际 %d（插值 %d）", len(repaired), report.Interpolated)
+		}
+		if repaired[1].Close != 101 || repaired[2].Close != 102 || repaired[4].Close != 104 {
+			t.Errorf("插值价格错误: %+v", repaired)
+		}
+		if len(ValidateKlines(repaired, "1m")) != 0 {
+			t.Errorf("修复后仍有异常")
+		}
+	})
+
+	t.Run("重新拉取后插值剩余缺口", func(t *testing.T) {
+		var requested [][2]int64
+		provider := rangeProviderFunc(func(symbol, interval string, start, end int64, limit int) ([]Kline, error) {
+			requested = append(requested, [2]int64{start, end})
+			if start == 60000 {
+				return []Kline{testKline(1, 90), testKline(2, 95)}, nil
+			}
+			return nil, nil
+		})
+
+		repaired, report := RepairKlines(context.Background(), klines, "BTCUSDT", "1m", KlineRepairRefetch, provider)
+		if len(requested) != 2 || report.Refetched != 2 || report.Interpolated != 1 {
+			t.Fatalf("requests=%v refetched=%d interpolated=%d", requested, report.Refetched, report.Interpolated)
+		}
+		if repaired[1].Close != 90 || repaired[2].Close != 95 {
+			t.Errorf("应使用重新拉取的K线: %+v", repaired[:3])
+		}
+	})
+
+	t.Run("只报告不修复", func(t *testing.T) {
+		repaired, report := RepairKlines(context.Background(), klines, "BTCUSDT", "1m", KlineRepairNone, nil)
+		if len(repaired) != len(klines) || len(report.Anomalies) == 0 {
+			t.Errorf("none 模式应原样返回并报告异常")
+		}
+	})
+}
+
+func TestCheckKlineIntegrityKeepsLength(t *testing.T) {
+	var reports []KlineIntegrityReport
+	OnKlineAnomaly = func(r KlineIntegrityReport) { reports = append(reports, r) }
+	defer func() { OnKlineAnomaly = nil }()
+
+	klines := []Kline{testKline(0, 100), testKline(1, 101), testKline(3, 103)}
+	repaired := checkKlineIntegrityWith(context.Background(), "Hyperliquid", nil, "ETHUSDT", "1m", klines)
+	if len(repaired) != 3 || repaired[0].OpenTime != 60000 || repaired[2].OpenTime != 3*60000 {
+		t.Errorf("应补齐缺口并保留最新的 3 根K线: %+v", repaired)
+	}
+	if len(reports) != 1 || reports[0].Source != "Hyperliquid" || reports[0].Missing() != 1 {
+		t.Errorf("异常报告错误: %+v", reports)
+	}
+}
@@ -0,0 +1,279 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"nofx/metrics"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultKlineCacheMaxBars 每个 (数据源, 币种, 周期) 最多保留的K线数量
+const defaultKlineCacheMaxBars = 1500
+
+// klineCacheNow 计算缺失K线数量时使用的时钟（测试中可替换）
+var klineCacheNow = time.Now
+
+// klineFetcher K线获取函数（与 DataSource.GetKlines 签名一致）
+type klineFetcher func(ctx context.Context, symbol, interval string, limit int) ([]Kline, error)
+
+// PersistentKlineCache K线本地持久化缓存
+// 按 (数据源, 币种, 周期) 存为 JSON 文件：先读缓存，只通过 API 补齐缺失的最新K线，
+// 避免每个周期重复拉取完整的 500 根K线（慢且消耗限频额度）
+type PersistentKlineCache struct {
+	dir     string
+	maxBars int
+
+	mu      sync.Mutex
+	entries map[string][]Kline // 已加载到内存的K线（按开盘时间正序）
+}
+
+// NewPersistentKlineCache 创建K线缓存（maxBars<=0 时使用默认值）
+func NewPersistentKlineCache(dir string, maxBars int) (*PersistentKlineCache, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建K线缓存目录失败: %w", err)
+	}
+	if maxBars <= 0 {
+		maxBars = defaultKlineCacheMaxBars
+	}
+	return &PersistentKlineCache{
+		dir:     dir,
+		maxBars: maxBars,
+		entries: make(map[string][]Kline),
+	}, nil
+}
+
+var (
+	klineCache   *PersistentKlineCache
+	klineCacheMu sync.RWMutex
+)
+
+// EnableKlineCache 启用全局K线缓存（APIClient.GetKlines 会优先读取缓存，Backfill 写入该缓存）
+func EnableKlineCache(dir string, maxBars int) error {
+	cache, err := NewPersistentKlineCache(dir, maxBars)
+	if err != nil {
+		return err
+	}
+	klineCacheMu.Lock()
+	klineCache = cache
+	klineCacheMu.Unlock()
+	log.Info("💾 K线本地缓存已启用", "dir", dir)
+	return nil
+}
+
+// DisableKlineCache 关闭全局K线缓存
+func DisableKlineCache() {
+	klineCacheMu.Lock()
+	klineCache = nil
+	klineCacheMu.Unlock()
+}
+
+// currentKlineCache 当前启用的全局K线缓存（未启用返回 nil）
+func currentKlineCache() *PersistentKlineCache {
+	klineCacheMu.RLock()
+	defer klineCacheMu.RUnlock()
+	return klineCache
+}
+
+// fetchKlinesCached 已启用K线缓存时经缓存获取，否则直接调用 fetch
+func fetchKlinesCached(ctx context.Context, source string, fetch klineFetcher, symbol, interval string, limit int) ([]Kline, error) {
+	cache := currentKlineCache()
+	if cache == nil {
+		return fetch(ctx, symbol, interval, limit)
+	}
+	return cache.GetKlines(ctx, source, fetch, symbol, interval, limit)
+}
+
+// GetKlines 获取最近 limit 根K线：缓存足够时只拉取缺失的尾部，否则完整拉取并写入缓存
+func (c *PersistentKlineCache) GetKlines(ctx context.Context, source string, fetch klineFetcher, symbol, interval string, limit int) ([]Kline, error) {
+	duration, ok := TimeframeDuration(interval)
+	if !ok || limit <= 0 {
+		return fetch(ctx, symbol, interval, limit)
+	}
+
+	key := c.key(source, symbol, interval)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cached := c.load(key)
+	missing := limit
+	metrics.CacheLookup("kline_disk", len(cached) >= limit)
+	if len(cached) >= limit {
+		// 重新拉取最后一根（可能尚未收盘）及之后的全部K线
+		elapsed := klineCacheNow().UnixMilli() - cached[len(cached)-1].OpenTime
+		missing = int(elapsed/duration.Milliseconds()) + 1
+		if missing < 1 {
+			missing = 1
+		}
+	}
+	if missing > limit {
+		missing = limit
+	}
+
+	fetched, err := fetch(ctx, symbol, interval, missing)
+	if err != nil {
+		return nil, err
+	}
+
+	// 保留数量取 maxBars 与原缓存数量的较大值（不因实时更新丢弃回填的历史K线）
+	merged := mergeKlines(cached, fetched, duration)
+	if keep := max(c.maxBars, len(cached)); len(merged) > keep {
+		merged = merged[len(merged)-keep:]
+	}
+	c.entries[key] = merged
+	if err := c.save(key, merged); err != nil {
+		log.Warn("⚠️ K线缓存写入失败", "key", key, "error", err)
+	}
+
+	if len(merged) > limit {
+		merged = merged[len(merged)-limit:]
+	}
+	result := make([]Kline, len(merged))
+	copy(result, merged)
+	return result, nil
+}
+
+// Store 将K线合并写入缓存（不限制数量，用于历史回填）
+func (c *PersistentKlineCache) Store(source, symbol, interval string, klines []Kline) error {
+	key := c.key(source, symbol, interval)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	merged := unionKlines(c.load(key), klines)
+	c.entries[key] = merged
+	return c.save(key, merged)
+}
+
+// Range 读取缓存中开盘时间位于 [from, to] 的K线（按时间正序）
+func (c *PersistentKlineCache) Range(source, symbol, interval string, from, to time.Time) []Kline {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var result []Kline
+	for _, k := range c.load(c.key(source, symbol, interval)) {
+		if k.OpenTime >= from.UnixMilli() && k.OpenTime <= to.UnixMilli() {
+			result = append(result, k)
+		}
+	}
+	return result
+}
+
+// KlineSeries 缓存中的一组K线
+type KlineSeries struct {
+	Source   string
+	Symbol   string
+	Interval string
+}
+
+// Series 列出磁盘上已缓存的全部K线序列（按数据源、币种、周期排序）
+func (c *PersistentKlineCache) Series() ([]KlineSeries, error) {
+	sources, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil, fmt.Errorf("读取K线缓存目录失败: %w", err)
+	}
+	var series []KlineSeries
+	for _, source := range sources {
+		if !source.IsDir() {
+			continue
+		}
+		files, err := os.ReadDir(filepath.Join(c.dir, source.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("读取K线缓存目录失败: %w", err)
+		}
+		for _, f := range files {
+			name, ok := strings.CutSuffix(f.Name(), ".json")
+			sep := strings.LastIndex(name, "_")
+			if !ok || f.IsDir() || sep <= 0 {
+				continue
+			}
+			series = append(series, KlineSeries{Source: source.Name(), Symbol: name[:sep], Interval: name[sep+1:]})
+		}
+	}
+	sort.Slice(series, func(i, j int) bool {
+		a, b := series[i], series[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Symbol != b.Symbol {
+			return a.Symbol < b.Symbol
+		}
+		return a.Interval < b.Interval
+	})
+	return series, nil
+}
+
+// key 缓存键（同时是相对文件路径）
+func (c *PersistentKlineCache) key(source, symbol, interval string) string {
+	return filepath.Join(strings.ToLower(source), fmt.Sprintf("%s_%s.json", strings.ToUpper(symbol), interval))
+}
+
+// load 读取缓存（内存中没有时从磁盘加载，文件损坏视为无缓存）
+func (c *PersistentKlineCache) load(key string) []Kline {
+	if klines, ok := c.entries[key]; ok {
+		return klines
+	}
+
+	data, err := os.ReadFile(filepath.Join(c.dir, key))
+	if err != nil {
+		return nil
+	}
+	var klines []Kline
+	if err := json.Unmarshal(data, &klines); err != nil {
+		log.Warn("⚠️ K线缓存文件损坏，忽略", "key", key, "error", err)
+		return nil
+	}
+	c.entries[key] = klines
+	return klines
+}
+
+// save 写入磁盘（先写临时文件再重命名，避免写入中断导致文件损坏）
+func (c *PersistentKlineCache) save(key string, klines []Kline) error {
+	path := filepath.Join(c.dir, key)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(klines)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// mergeKlines 用新拉取的K线更新缓存（同一开盘时间以新数据为准）
+// 新数据与缓存之间出现缺口时丢弃缓存，避免序列不连续导致指标计算错误
+func mergeKlines(cached, fetched []Kline, duration time.Duration) []Kline {
+	if len(fetched) == 0 {
+		return cached
+	}
+	if len(cached) == 0 || fetched[0].OpenTime > cached[len(cached)-1].OpenTime+duration.Milliseconds() {
+		return append([]Kline(nil), fetched...)
+	}
+	return unionKlines(cached, fetched)
+}
+
+// unionKlines 按开盘时间合并两组K线并排序（同一开盘时间以 fetched 为准）
+func unionKlines(cached, fetched []Kline) []Kline {
+	byOpenTime := make(map[int64]Kline, len(cached)+len(fetched))
+	for _, k := range cached {
+		byOpenTime[k.OpenTime] = k
+	}
+	for _, k := range fetched {
+		byOpenTime[k.OpenTime] = k
+	}
+
+	merged := make([]Kline, 0, len(byOpenTime))
+	for _, k := range byOpenTime {
+		merged = append(merged, k)
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].OpenTime < merged[j].OpenTime })
+	return merged
+}
@@ -0,0 +1,110 @@
+package market
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeKlineFetcher 按 limit 返回截至 now 的最近K线，并记录每次请求的数量
+type fakeKlineFetcher struct {
+	now      int64
+	interval time.Duration
+	limits   []int
+}
+
+func (f *fakeKlineFetcher) fetch(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	f.limits = append(f.limits, limit)
+	step := f.interval.Milliseconds()
+	last := f.now - f.now%step
+	klines := make([]Kline, limit)
+	for i := range klines {
+		openTime := last - int64(limit-1-i)*step
+		klines[i] = Kline{OpenTime: openTime, Close: float64(openTime / step)}
+	}
+	return klines, nil
+}
+
+func TestPersistentKlineCache_FetchesOnlyMissingTail(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2024, 1, 1, 0, 7, 0, 0, time.UTC)
+	klineCacheNow = func() time.Time { return start }
+	defer func() { klineCacheNow = time.Now }()
+
+	fetcher := &fakeKlineFetcher{now: start.UnixMilli(), interval: 3 * time.Minute}
+	cache, err := NewPersistentKlineCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 首次完整拉取
+	klines, err := cache.GetKlines(context.Background(), "binance", fetcher.fetch, "BTCUSDT", "3m", 100)
+	if err != nil || len(klines) != 100 {
+		t.Fatalf("首次获取失败: len=%d err=%v", len(klines), err)
+	}
+
+	// 经过 2 根K线：重新拉取原最后一根 + 2 根新K线
+	start = start.Add(6 * time.Minute)
+	fetcher.now = start.UnixMilli()
+	klines, err = cache.GetKlines(context.Background(), "binance", fetcher.fetch, "BTCUSDT", "3m", 100)
+	if err != nil || len(klines) != 100 {
+		t.Fatalf("增量获取失败: len=%d err=%v", len(klines), err)
+	}
+	if fetcher.limits[1] != 3 {
+		t.Errorf("期望只拉取 3 根K线，实际 %d", fetcher.limits[1])
+	}
+	if klines[99].OpenTime != fetcher.now-fetcher.now%fetcher.interval.Milliseconds() {
+		t.Errorf("最后一根K线应为最新K线，实际 %d", klines[99].OpenTime)
+	}
+	for i := 1; i < len(klines); i++ {
+		if klines[i].OpenTime-klines[i-1].OpenTime != fetcher.interval.Milliseconds() {
+			t.Fatalf("K线序列不连续: %d -> %d", klines[i-1].OpenTime, klines[i].OpenTime)
+		}
+	}
+
+	// 重启后从磁盘恢复，同样只拉取尾部
+	reloaded, err := NewPersistentKlineCache(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reloaded.GetKlines(context.Background(), "binance", fetcher.fetch, "BTCUSDT", "3m", 100); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.limits[2] != 1 {
+		t.Errorf("从磁盘恢复后期望只拉取 1 根K线，实际 %d", fetcher.limits[2])
+	}
+
+	// 不同数据源互不影响
+	if _, err := reloaded.GetKlines(context.Background(), "okx", fetcher.fetch, "BTCUSDT", "3m", 100); err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.limits[3] != 100 {
+		t.Errorf("新数据源应完整拉取，实际 %d", fetcher.limits[3])
+	}
+}
+
+func TestPersistentKlineCache_GapDiscardsCache(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	klineCacheNow = func() time.Time { return start }
+	defer func() { klineCacheNow = time.Now }()
+
+	fetcher := &fakeKlineFetcher{now: start.UnixMilli(), interval: time.Hour}
+	cache, err := NewPersistentKlineCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.GetKlines(context.Background(), "binance", fetcher.fetch, "ETHUSDT", "1h", 10); err != nil {
+		t.Fatal(err)
+	}
+
+	// 停机超过 limit 根K线：完整拉取并丢弃旧缓存
+	start = start.Add(48 * time.Hour)
+	fetcher.now = start.UnixMilli()
+	klines, err := cache.GetKlines(context.Background(), "binance", fetcher.fetch, "ETHUSDT", "1h", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fetcher.limits[1] != 10 || len(klines) != 10 || klines[0].OpenTime != start.Add(-9*time.Hour).UnixMilli() {
+		t.Errorf("期望完整拉取最近 10 根K线，实际 limit=%d len=%d first=%d", fetcher.limits[1], len(klines), klines[0].OpenTime)
+	}
+}
@@ -0,0 +1,183 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"nofx/eventbus"
+	"time"
+)
+
+// maxStreamBackfillLimit 断线补齐一次最多拉取的K线数量（OKX 单次上限）
+const maxStreamBackfillLimit = 300
+
+// DefaultKlineStreamSource StreamKlines 默认使用的数据源（注册名）
+var DefaultKlineStreamSource = "binance"
+
+// KlineStreamer 支持 WebSocket K线推送的数据源
+type KlineStreamer interface {
+	// StreamKlines 订阅K线推送，ctx 取消后关闭返回的通道
+	// 当前K线每次更新都会推送（OpenTime 相同），消费方按 OpenTime 去重/覆盖
+	StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error)
+}
+
+// StreamKlines 使用默认数据源订阅K线推送（断线自动重连，重连后通过 REST 补齐缺口）
+func StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	source, err := NewDataSourceByName(DefaultKlineStreamSource)
+	if err != nil {
+		return nil, err
+	}
+	streamer, ok := source.(KlineStreamer)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持K线推送", source.GetName())
+	}
+	return streamer.StreamKlines(ctx, symbol, interval)
+}
+
+// klineStreamProtocol 各交易所 WebSocket K线协议
+type klineStreamProtocol interface {
+	// url 连接地址
+	url(symbol, interval string) (string, error)
+	// subscribeMessages 连接后需要发送的订阅消息（可为空）
+	subscribeMessages(symbol, interval string) ([]interface{}, error)
+	// heartbeat 应用层心跳消息（nil=不需要）
+	heartbeat() interface{}
+	// parse 解析推送消息（订阅确认、心跳响应等返回空）
+	parse(message []byte, intervalMs int64) ([]Kline, error)
+}
+
+// klineStream 单个 symbol/interval 的K线推送（连接、重连、补齐）
+type klineStream struct {
+	streamConn
+	protocol   klineStreamProtocol
+	backfill   klineFetcher
+	symbol     string
+	interval   string
+	intervalMs int64
+	out        chan Kline
+
+	lastOpenTime int64 // 最后推送的K线开盘时间（用于重连后补齐）
+	last         Kline // 最后推送的K线（收到更新的K线时视为收盘，发布 CandleClosed）
+}
+
+// startKlineStream 校验参数并启动推送 goroutine
+func startKlineStream(ctx context.Context, name string, protocol klineStreamProtocol, backfill klineFetcher, symbol, interval string) (<-chan Kline, error) {
+	if _, err := protocol.url(symbol, interval); err != nil {
+		return nil, err
+	}
+	if _, err := protocol.subscribeMessages(symbol, interval); err != nil {
+		return nil, err
+	}
+
+	s := newKlineStream(name, protocol, backfill, symbol, interval)
+	go s.run(ctx)
+	return s.out, nil
+}
+
+// newKlineStream 创建K线推送（使用默认退避与超时参数）
+func newKlineStream(name string, protocol klineStreamProtocol, backfill klineFetcher, symbol, interval string) *klineStream {
+	return &klineStream{
+		streamConn: newStreamConn(name, "K线", symbol+" "+interval),
+		protocol:   protocol,
+		backfill:   backfill,
+		symbol:     symbol,
+		interval:   interval,
+		intervalMs: intervalToMillis(interval),
+		out:        make(chan Kline, streamChannelBuffer),
+	}
+}
+
+// run 连接循环：断线后按指数退避重连，直到 ctx 取消
+func (s *klineStream) run(ctx context.Context) {
+	defer close(s.out)
+
+	wsURL, _ := s.protocol.url(s.symbol, s.interval)
+	messages, _ := s.protocol.subscribeMessages(s.symbol, s.interval)
+
+	s.streamConn.run(ctx, func(ctx context.Context) (bool, error) {
+		return s.session(ctx, wsURL, messages, s.protocol.heartbeat(),
+			func() {
+				// 重连后补齐断线期间缺失的K线
+				if s.lastOpenTime > 0 {
+					s.backfillGap(ctx)
+				}
+			},
+			func(message []byte) bool {
+				klines, err := s.protocol.parse(message, s.intervalMs)
+				if err != nil {
+					log.Warn("⚠️ K线推送解析失败", "source", s.name, "stream", s.label, "error", err)
+					return true
+				}
+				for _, k := range klines {
+					if !s.emit(ctx, k) {
+						return false
+					}
+				}
+				return true
+			})
+	})
+}
+
+// backfillGap 通过 REST 拉取断线期间的K线（包含最后一根可能未收盘的K线）
+func (s *klineStream) backfillGap(ctx context.Context) {
+	if s.backfill == nil {
+		return
+	}
+
+	missing := (time.Now().UnixMilli()-s.lastOpenTime)/s.intervalMs + 2
+	if missing > maxStreamBackfillLimit {
+		missing = maxStreamBackfillLimit
+	}
+
+	klines, err := s.backfill(ctx, s.symbol, s.interval, int(missing))
+	if err != nil {
+		log.Warn("⚠️ K线缺口补齐失败", "source", s.name, "stream", s.label, "error", err)
+		return
+	}
+
+	count := 0
+	for _, k := range klines {
+		if k.OpenTime < s.lastOpenTime {
+			continue
+		}
+		if !s.emit(ctx, k) {
+			return
+		}
+		count++
+	}
+	log.Info("🔁 K线缺口已补齐", "source", s.name, "stream", s.label, "count", count)
+}
+
+// emit 推送一根K线（ctx 取消时返回 false）
+func (s *klineStream) emit(ctx context.Context, k Kline) bool {
+	select {
+	case s.out <- k:
+		if k.OpenTime > s.lastOpenTime {
+			if s.lastOpenTime > 0 {
+				s.publishClosed(s.last)
+			}
+			s.lastOpenTime = k.OpenTime
+		}
+		if k.OpenTime == s.lastOpenTime {
+			s.last = k
+		}
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// publishClosed 发布K线收盘事件
+func (s *klineStream) publishClosed(k Kline) {
+	eventbus.Publish(eventbus.CandleClosed{
+		Source:    s.name,
+		Symbol:    s.symbol,
+		Interval:  s.interval,
+		OpenTime:  k.OpenTime,
+		CloseTime: k.CloseTime,
+		Open:      k.Open,
+		High:      k.High,
+		Low:       k.Low,
+		Close:     k.Close,
+		Volume:    k.Volume,
+	})
+}
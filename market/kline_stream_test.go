@@ -0,0 +1,178 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"nofx/eventbus"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// binanceKlineMessage 构造 Binance K线推送消息
+func binanceKlineMessage(openTime int64, close string) string {
+	return fmt.Sprintf(`{"e":"kline","E":%d,"s":"BTCUSDT","k":{"t":%d,"T":%d,"s":"BTCUSDT","i":"1m","o":"100","c":"%s","h":"101","l":"99","v":"10","n":5,"x":false,"q":"1000","V":"5","Q":"500"}}`,
+		openTime, openTime, openTime+59999, close)
+}
+
+// TestKlineStream_ReconnectAndBackfill 断线后重连，并通过 REST 补齐缺口
+func TestKlineStream_ReconnectAndBackfill(t *testing.T) {
+	const base = int64(1700000000000)
+	var connections int32
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/btcusdt@kline_1m" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		switch atomic.AddInt32(&connections, 1) {
+		case 1:
+			// 第一次连接推送一根K线后断开
+			conn.WriteMessage(websocket.TextMessage, []byte(binanceKlineMessage(base, "100.5")))
+		default:
+			conn.WriteMessage(websocket.TextMessage, []byte(binanceKlineMessage(base+180000, "103")))
+			time.Sleep(time.Second)
+		}
+	}))
+	defer server.Close()
+
+	var backfillLimit int
+	backfill := func(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+		backfillLimit = limit
+		return []Kline{{OpenTime: base - 60000}, {OpenTime: base, Close: 101}, {OpenTime: base + 60000}, {OpenTime: base + 120000}}, nil
+	}
+
+	origBus := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = origBus }()
+	closed, unsubscribe := eventbus.Subscribe(16, eventbus.TopicCandleClosed)
+	defer unsubscribe()
+
+	s := newKlineStream("Binance", &binanceKlineProtocol{baseURL: "ws" + strings.TrimPrefix(server.URL, "http")}, backfill, "BTCUSDT", "1m")
+	s.backoffMin = 10 * time.Millisecond
+	s.backoffMax = 50 * time.Millisecond
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go s.run(ctx)
+
+	var got []int64
+	timeout := time.After(5 * time.Second)
+	for len(got) < 5 {
+		select {
+		case k := <-s.out:
+			got = append(got, k.OpenTime)
+		case <-timeout:
+			t.Fatalf("timeout, got %v", got)
+		}
+	}
+
+	// 第一次推送 base，重连后补齐 base(更新)及之后的K线（更早的丢弃），再收到实时推送
+	expected := []int64{base, base, base + 60000, base + 120000, base + 180000}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, got)
+		}
+	}
+	if backfillLimit <= 0 || backfillLimit > maxStreamBackfillLimit {
+		t.Errorf("Unexpected backfill limit: %d", backfillLimit)
+	}
+
+	// 收到更新的K线时发布上一根的收盘事件（base 使用补齐后的收盘价）
+	for i, openTime := range []int64{base, base + 60000, base + 120000} {
+		select {
+		case ev := <-closed:
+			c := ev.(eventbus.CandleClosed)
+			if c.OpenTime != openTime || c.Symbol != "BTCUSDT" || c.Interval != "1m" {
+				t.Errorf("CandleClosed[%d] = %+v, want openTime %d", i, c, openTime)
+			}
+			if openTime == base && c.Close != 101 {
+				t.Errorf("CandleClosed close = %v, want 101", c.Close)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for CandleClosed %d", openTime)
+		}
+	}
+
+	// ctx 取消后关闭通道
+	cancel()
+	for range s.out {
+	}
+}
+
+// TestKlineStream_StopWhileReconnecting 重连等待期间取消也能退出
+func TestKlineStream_StopWhileReconnecting(t *testing.T) {
+	s := newKlineStream("Binance", &binanceKlineProtocol{baseURL: "ws://127.0.0.1:1"}, nil, "BTCUSDT", "1m")
+	s.backoffMin = time.Hour
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go s.run(ctx)
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case _, ok := <-s.out:
+		if ok {
+			t.Error("Expected channel to be closed")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream did not stop after cancel")
+	}
+}
+
+// TestOKXKlineProtocol OKX 订阅消息与推送解析
+func TestOKXKlineProtocol(t *testing.T) {
+	p := &okxKlineProtocol{}
+
+	msgs, err := p.subscribeMessages("BTCUSDT", "1h")
+	if err != nil || len(msgs) != 1 {
+		t.Fatalf("Unexpected subscribe messages: %v, err=%v", msgs, err)
+	}
+	data, _ := json.Marshal(msgs[0])
+	if !strings.Contains(string(data), `"channel":"candle1H"`) || !strings.Contains(string(data), `"instId":"BTC-USDT-SWAP"`) {
+		t.Errorf("Unexpected subscribe message: %s", data)
+	}
+
+	if klines, err := p.parse([]byte("pong"), 3600000); err != nil || len(klines) != 0 {
+		t.Errorf("pong should be ignored: %v, err=%v", klines, err)
+	}
+	if _, err := p.parse([]byte(`{"event":"error","msg":"bad channel"}`), 3600000); err == nil {
+		t.Error("Expected error event to fail")
+	}
+
+	klines, err := p.parse([]byte(`{"arg":{"channel":"candle1H","instId":"BTC-USDT-SWAP"},"data":[["1700000000000","100","102","99","101","10","0.1","1010","0"]]}`), 3600000)
+	if err != nil || len(klines) != 1 || klines[0].Close != 101 || klines[0].CloseTime != 1700003599999 {
+		t.Errorf("Unexpected klines: %+v, err=%v", klines, err)
+	}
+}
+
+// TestHyperliquidKlineProtocol Hyperliquid 订阅消息与推送解析
+func TestHyperliquidKlineProtocol(t *testing.T) {
+	p := &hyperliquidKlineProtocol{}
+
+	msgs, _ := p.subscribeMessages("ETHUSDT", "15m")
+	data, _ := json.Marshal(msgs[0])
+	if !strings.Contains(string(data), `"coin":"ETH"`) || !strings.Contains(string(data), `"type":"candle"`) {
+		t.Errorf("Unexpected subscribe message: %s", data)
+	}
+
+	if klines, err := p.parse([]byte(`{"channel":"subscriptionResponse","data":{}}`), 900000); err != nil || len(klines) != 0 {
+		t.Errorf("subscription ack should be ignored: %v, err=%v", klines, err)
+	}
+
+	klines, err := p.parse([]byte(`{"channel":"candle","data":{"t":1700000000000,"T":1700000899999,"s":"ETH","i":"15m","o":"2000","c":"2010","h":"2020","l":"1990","v":"50","n":12}}`), 900000)
+	if err != nil || len(klines) != 1 || klines[0].Close != 2010 || klines[0].Trades != 12 {
+		t.Errorf("Unexpected klines: %+v, err=%v", klines, err)
+	}
+}
@@ -0,0 +1,126 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// binanceNoDelivery 币安永续合约未计划下架时的 deliveryDate（2100-12-25）
+const binanceNoDelivery = 4133404800000
+
+// InstrumentListing 一个永续合约的上架/下架状态
+type InstrumentListing struct {
+	Symbol     string    `json:"symbol"`
+	InstID     string    `json:"inst_id"`               // 交易所的合约 ID（币安与 Symbol 相同）
+	State      string    `json:"state"`                 // 交易所原始状态（TRADING / live / preopen 等）
+	ListTime   time.Time `json:"list_time"`             // 上架时间（未知为零值）
+	DelistTime time.Time `json:"delist_time,omitempty"` // 计划下架时间（没有下架计划为零值）
+}
+
+// Delisting 是否有下架计划
+func (l InstrumentListing) Delisting() bool {
+	return !l.DelistTime.IsZero()
+}
+
+// ListingProvider 支持查询全部永续合约上架/下架状态的数据源
+type ListingProvider interface {
+	// GetSwapListings 获取全部 USDT 永续合约（含预上架和计划下架的合约）
+	GetSwapListings(ctx context.Context) ([]InstrumentListing, error)
+}
+
+// ListingChanges 两次查询之间的合约变化
+type ListingChanges struct {
+	Listed    []InstrumentListing // 新上架（或预上架）的合约
+	Delisting []InstrumentListing // 新出现下架计划（或下架时间变化）的合约
+	Removed   []string            // 已从交易所合约列表中移除的币种
+}
+
+// Empty 是否没有变化
+func (c ListingChanges) Empty() bool {
+	return len(c.Listed) == 0 && len(c.Delisting) == 0 && len(c.Removed) == 0
+}
+
+// DiffListings 对比上次的合约列表（prev 为 nil 表示首次查询：只报告已有的下架计划，不把全部合约当作新上架）
+func DiffListings(prev map[string]InstrumentListing, current []InstrumentListing) ListingChanges {
+	var changes ListingChanges
+	seen := make(map[string]bool, len(current))
+	for _, l := range current {
+		seen[l.Symbol] = true
+		old, known := prev[l.Symbol]
+		if prev != nil && !known {
+			changes.Listed = append(changes.Listed, l)
+		}
+		if l.Delisting() && (!known || !old.DelistTime.Equal(l.DelistTime)) {
+			changes.Delisting = append(changes.Delisting, l)
+		}
+	}
+	for symbol := range prev {
+		if !seen[symbol] {
+			changes.Removed = append(changes.Removed, symbol)
+		}
+	}
+	sort.Strings(changes.Removed)
+	return changes
+}
+
+// GetSwapListings 获取全部 USDT 永续合约的上架/下架状态（exchangeInfo 的 onboardDate / deliveryDate）
+func (b *BinanceDataSource) GetSwapListings(ctx context.Context) ([]InstrumentListing, error) {
+	info, err := b.client.GetExchangeInfo(ctx)
+	if err != nil {
+		log.Warn("⚠️ Binance GetSwapListings 失败", "error", err)
+		return nil, fmt.Errorf("binance GetSwapListings failed: %w", err)
+	}
+	listings := make([]InstrumentListing, 0, len(info.Symbols))
+	for _, s := range info.Symbols {
+		if s.ContractType != "PERPETUAL" || s.QuoteAsset != "USDT" || s.Status == "CLOSE" {
+			continue
+		}
+		l := InstrumentListing{Symbol: s.Symbol, InstID: s.Symbol, State: s.Status}
+		if s.OnboardDate > 0 {
+			l.ListTime = time.UnixMilli(s.OnboardDate)
+		}
+		if s.DeliveryDate > 0 && s.DeliveryDate < binanceNoDelivery {
+			l.DelistTime = time.UnixMilli(s.DeliveryDate)
+		}
+		listings = append(listings, l)
+	}
+	return listings, nil
+}
+
+// GetSwapListings 获取全部 USDT 永续合约的上架/下架状态（/api/v5/public/instruments 的 listTime / expTime；
+// 永续合约的 expTime 为计划下架时间）
+func (o *OKXDataSource) GetSwapListings(ctx context.Context) ([]InstrumentListing, error) {
+	params := url.Values{}
+	params.Set("instType", OKXInstTypeSwap)
+
+	var rows []struct {
+		InstID    string `json:"instId"`
+		SettleCcy string `json:"settleCcy"`
+		State     string `json:"state"`
+		ListTime  string `json:"listTime"`
+		ExpTime   string `json:"expTime"`
+	}
+	if err := o.get(ctx, "/api/v5/public/instruments", params, &rows); err != nil {
+		return nil, fmt.Errorf("okx GetSwapListings failed: %w", err)
+	}
+	listings := make([]InstrumentListing, 0, len(rows))
+	for _, r := range rows {
+		if r.SettleCcy != "USDT" || !strings.HasSuffix(r.InstID, "-USDT-SWAP") {
+			continue
+		}
+		l := InstrumentListing{Symbol: OKXSymbolFromInstID(r.InstID), InstID: r.InstID, State: r.State}
+		if ms, err := strconv.ParseInt(r.ListTime, 10, 64); err == nil && ms > 0 {
+			l.ListTime = time.UnixMilli(ms)
+		}
+		if ms, err := strconv.ParseInt(r.ExpTime, 10, 64); err == nil && ms > 0 {
+			l.DelistTime = time.UnixMilli(ms)
+		}
+		listings = append(listings, l)
+	}
+	return listings, nil
+}
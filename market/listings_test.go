@@ -0,0 +1,112 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestDiffListings(t *testing.T) {
+	delist := time.Date(2024, 6, 1, 8, 0, 0, 0, time.UTC)
+	first := []InstrumentListing{
+		{Symbol: "BTCUSDT"},
+		{Symbol: "OLDUSDT", DelistTime: delist},
+	}
+	changes := DiffListings(nil, first)
+	if len(changes.Listed) != 0 || len(changes.Delisting) != 1 || changes.Delisting[0].Symbol != "OLDUSDT" {
+		t.Errorf("first poll: %+v", changes)
+	}
+
+	prev := map[string]InstrumentListing{}
+	for _, l := range first {
+		prev[l.Symbol] = l
+	}
+	if changes := DiffListings(prev, first); !changes.Empty() {
+		t.Errorf("unchanged: %+v", changes)
+	}
+
+	second := []InstrumentListing{
+		{Symbol: "BTCUSDT", DelistTime: delist},
+		{Symbol: "NEWUSDT", State: "preopen"},
+	}
+	changes = DiffListings(prev, second)
+	if len(changes.Listed) != 1 || changes.Listed[0].Symbol != "NEWUSDT" {
+		t.Errorf("listed: %+v", changes.Listed)
+	}
+	if len(changes.Delisting) != 1 || changes.Delisting[0].Symbol != "BTCUSDT" {
+		t.Errorf("delisting: %+v", changes.Delisting)
+	}
+	if !reflect.DeepEqual(changes.Removed, []string{"OLDUSDT"}) {
+		t.Errorf("removed: %v", changes.Removed)
+	}
+}
+
+func TestBinanceSwapListings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/exchangeInfo" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprint(w, `{"symbols":[
+			{"symbol":"BTCUSDT","status":"TRADING","quoteAsset":"USDT","contractType":"PERPETUAL","onboardDate":1569398400000,"deliveryDate":4133404800000},
+			{"symbol":"OLDUSDT","status":"TRADING","quoteAsset":"USDT","contractType":"PERPETUAL","onboardDate":1600000000000,"deliveryDate":1717228800000},
+			{"symbol":"BTCUSDT_240927","status":"TRADING","quoteAsset":"USDT","contractType":"CURRENT_QUARTER","deliveryDate":1727424000000},
+			{"symbol":"GONEUSDT","status":"CLOSE","quoteAsset":"USDT","contractType":"PERPETUAL"},
+			{"symbol":"ETHUSDC","status":"TRADING","quoteAsset":"USDC","contractType":"PERPETUAL"}
+		]}`)
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	listings, err := NewBinanceDataSource().GetSwapListings(context.Background())
+	if err != nil {
+		t.Fatalf("GetSwapListings failed: %v", err)
+	}
+	if len(listings) != 2 {
+		t.Fatalf("expected 2 USDT perpetuals, got %+v", listings)
+	}
+	if listings[0].Delisting() || listings[0].ListTime.UnixMilli() != 1569398400000 {
+		t.Errorf("BTCUSDT: %+v", listings[0])
+	}
+	if !listings[1].Delisting() || listings[1].DelistTime.UnixMilli() != 1717228800000 {
+		t.Errorf("OLDUSDT: %+v", listings[1])
+	}
+}
+
+func TestOKXSwapListings(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v5/public/instruments" || r.URL.Query().Get("instType") != "SWAP" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[
+			{"instId":"BTC-USDT-SWAP","settleCcy":"USDT","state":"live","listTime":"1573557408000","expTime":""},
+			{"instId":"OLD-USDT-SWAP","settleCcy":"USDT","state":"live","listTime":"1600000000000","expTime":"1717228800000"},
+			{"instId":"NEW-USDT-SWAP","settleCcy":"USDT","state":"preopen","listTime":"1717000000000","expTime":""},
+			{"instId":"BTC-USD-SWAP","settleCcy":"BTC","state":"live","listTime":"1573557408000","expTime":""}
+		]}`))
+	}))
+	defer server.Close()
+
+	source := NewOKXDataSource()
+	source.baseURL = server.URL
+	listings, err := source.GetSwapListings(context.Background())
+	if err != nil {
+		t.Fatalf("GetSwapListings failed: %v", err)
+	}
+	if len(listings) != 3 {
+		t.Fatalf("expected 3 USDT swaps, got %+v", listings)
+	}
+	if listings[0].Symbol != "BTCUSDT" || listings[0].Delisting() {
+		t.Errorf("BTC: %+v", listings[0])
+	}
+	if listings[1].Symbol != "OLDUSDT" || listings[1].DelistTime.UnixMilli() != 1717228800000 {
+		t.Errorf("OLD: %+v", listings[1])
+	}
+	if listings[2].State != "preopen" {
+		t.Errorf("NEW: %+v", listings[2])
+	}
+}
@@ -0,0 +1,6 @@
+package market
+
+import "nofx/logging"
+
+// log market 模块日志器（Info/Warn/Error + key/value 属性）
+var log = logging.Module("market")
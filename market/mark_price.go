@@ -0,0 +1,107 @@
+package market
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultMarkPriceSource GetMarkPrice / GetIndexPrice / GetBasisHistory 默认使用的数据源（注册名）
+var DefaultMarkPriceSource = "binance"
+
+// BasisPoint 某一时刻的标记价格与指数价格（用于计算基差/溢价）
+type BasisPoint struct {
+	Timestamp  int64   `json:"timestamp"` // K线开盘时间（毫秒）
+	MarkPrice  float64 `json:"mark_price"`
+	IndexPrice float64 `json:"index_price"`
+}
+
+// Basis 基差（标记价格 - 指数价格）
+func (p BasisPoint) Basis() float64 {
+	return p.MarkPrice - p.IndexPrice
+}
+
+// PremiumPct 溢价率百分比（基差 / 指数价格，正数表示合约升水）
+func (p BasisPoint) PremiumPct() float64 {
+	if p.IndexPrice <= 0 {
+		return 0
+	}
+	return p.Basis() / p.IndexPrice * 100
+}
+
+// MarkPriceProvider 支持标记价格/指数价格查询的数据源
+type MarkPriceProvider interface {
+	// GetMarkPrice 获取当前标记价格
+	GetMarkPrice(ctx context.Context, symbol string) (float64, error)
+	// GetIndexPrice 获取当前指数价格
+	GetIndexPrice(ctx context.Context, symbol string) (float64, error)
+	// GetBasisHistory 获取最近 limit 根 interval K线收盘时的标记价格与指数价格（按时间正序）
+	GetBasisHistory(ctx context.Context, symbol, interval string, limit int) ([]BasisPoint, error)
+}
+
+// GetMarkPrice 使用默认数据源获取标记价格
+func GetMarkPrice(symbol string) (float64, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	provider, err := defaultMarkPriceProvider()
+	if err != nil {
+		return 0, err
+	}
+	return provider.GetMarkPrice(ctx, symbol)
+}
+
+// GetIndexPrice 使用默认数据源获取指数价格
+func GetIndexPrice(symbol string) (float64, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	provider, err := defaultMarkPriceProvider()
+	if err != nil {
+		return 0, err
+	}
+	return provider.GetIndexPrice(ctx, symbol)
+}
+
+// GetBasisHistory 使用默认数据源获取基差/溢价序列
+func GetBasisHistory(symbol, interval string, limit int) ([]BasisPoint, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	provider, err := defaultMarkPriceProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetBasisHistory(ctx, symbol, interval, limit)
+}
+
+// defaultMarkPriceProvider 创建默认标记价格数据源
+func defaultMarkPriceProvider() (MarkPriceProvider, error) {
+	source, err := NewDataSourceByName(DefaultMarkPriceSource)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(MarkPriceProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持标记价格查询", source.GetName())
+	}
+	return provider, nil
+}
+
+// pricePoint K线开盘时间及收盘价
+type pricePoint struct {
+	Timestamp int64
+	Price     float64
+}
+
+// joinBasisSeries 按开盘时间对齐标记价格和指数价格K线（只保留两边都有的时间点）
+func joinBasisSeries(mark, index []pricePoint) []BasisPoint {
+	indexByTime := make(map[int64]float64, len(index))
+	for _, p := range index {
+		indexByTime[p.Timestamp] = p.Price
+	}
+
+	series := make([]BasisPoint, 0, len(mark))
+	for _, p := range mark {
+		if indexPrice, ok := indexByTime[p.Timestamp]; ok {
+			series = append(series, BasisPoint{Timestamp: p.Timestamp, MarkPrice: p.Price, IndexPrice: indexPrice})
+		}
+	}
+	return series
+}
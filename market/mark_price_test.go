@@ -0,0 +1,97 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBinanceMarkPriceAndBasis(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/premiumIndex":
+			fmt.Fprint(w, `{"symbol":"BTCUSDT","markPrice":"50050.5","indexPrice":"50000.0","lastFundingRate":"0.0001","time":1700000000000}`)
+		case "/fapi/v1/markPriceKlines":
+			fmt.Fprint(w, `[[1700000000000,"50000","50100","49900","50100","0",1700000899999,"0",0,"0","0","0"],
+				[1700000900000,"50100","50200","50000","50200","0",1700001799999,"0",0,"0","0","0"]]`)
+		case "/fapi/v1/indexPriceKlines":
+			if r.URL.Query().Get("pair") != "BTCUSDT" {
+				t.Errorf("index klines should use pair: %s", r.URL.String())
+			}
+			fmt.Fprint(w, `[[1700000900000,"50000","50100","49900","50000","0",1700001799999,"0",0,"0","0","0"]]`)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	source := NewBinanceDataSource()
+	mark, err := source.GetMarkPrice(context.Background(), "BTCUSDT")
+	if err != nil || mark != 50050.5 {
+		t.Errorf("Unexpected mark price: %v, err=%v", mark, err)
+	}
+	index, err := source.GetIndexPrice(context.Background(), "BTCUSDT")
+	if err != nil || index != 50000 {
+		t.Errorf("Unexpected index price: %v, err=%v", index, err)
+	}
+
+	// 只保留两边都有的时间点
+	series, err := source.GetBasisHistory(context.Background(), "BTCUSDT", "15m", 2)
+	if err != nil {
+		t.Fatalf("GetBasisHistory failed: %v", err)
+	}
+	if len(series) != 1 || series[0].Timestamp != 1700000900000 || series[0].Basis() != 200 {
+		t.Fatalf("Unexpected basis series: %+v", series)
+	}
+	if math.Abs(series[0].PremiumPct()-0.4) > 1e-9 {
+		t.Errorf("Expected premium 0.4%%, got %v", series[0].PremiumPct())
+	}
+}
+
+func TestOKXMarkPriceAndBasis(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		instID := r.URL.Query().Get("instId")
+		switch r.URL.Path {
+		case "/api/v5/public/mark-price":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[{"instType":"SWAP","instId":"ETH-USDT-SWAP","markPx":"1999","ts":"1700000000000"}]}`)
+		case "/api/v5/market/index-tickers":
+			if instID != "ETH-USDT" {
+				t.Errorf("unexpected index instId: %s", instID)
+			}
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[{"instId":"ETH-USDT","idxPx":"2000","ts":"1700000000000"}]}`)
+		case "/api/v5/market/mark-price-candles":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[["1700000300000","1","1","1","1990","0"],["1700000000000","1","1","1","2010","1"]]}`)
+		case "/api/v5/market/index-candles":
+			if instID != "ETH-USDT" {
+				t.Errorf("unexpected index instId: %s", instID)
+			}
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[["1700000300000","1","1","1","2000","0"],["1700000000000","1","1","1","2000","1"]]}`)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	source := NewOKXDataSource()
+	source.baseURL = server.URL
+
+	if mark, err := source.GetMarkPrice(context.Background(), "ETHUSDT"); err != nil || mark != 1999 {
+		t.Errorf("Unexpected mark price: %v, err=%v", mark, err)
+	}
+	if index, err := source.GetIndexPrice(context.Background(), "ETHUSDT"); err != nil || index != 2000 {
+		t.Errorf("Unexpected index price: %v, err=%v", index, err)
+	}
+
+	series, err := source.GetBasisHistory(context.Background(), "ETHUSDT", "5m", 2)
+	if err != nil {
+		t.Fatalf("GetBasisHistory failed: %v", err)
+	}
+	if len(series) != 2 || series[0].Basis() != 10 || series[1].Basis() != -10 {
+		t.Errorf("Expected ascending basis series, got %+v", series)
+	}
+}
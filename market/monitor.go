@@ -3,7 +3,7 @@ package market
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"nofx/metrics"
 	"strings"
 	"sync"
 	"time"
@@ -71,9 +71,9 @@ func NewWSMonitor(batchSize int, timeframes []string, dsManager *DataSourceManag
 		timeframes:     timeframes,
 		dsManager:      dsManager, // 设置数据源管理器
 	}
-	log.Printf("📊 WSMonitor 初始化，使用时间线: %v", timeframes)
+	log.Info("📊 WSMonitor 初始化", "timeframes", timeframes)
 	if dsManager != nil {
-		log.Printf("✅ WSMonitor 已连接多数据源管理器（故障转移已启用）")
+		log.Info("✅ WSMonitor 已连接多数据源管理器（故障转移已启用）")
 	}
 	return WSMonitorCli
 }
@@ -83,13 +83,20 @@ func (m *WSMonitor) GetDSManager() *DataSourceManager {
 	return m.dsManager
 }
 
+// Connected 行情组合流是否已连接
+func (m *WSMonitor) Connected() bool {
+	return m.combinedClient.Connected()
+}
+
 func (m *WSMonitor) Initialize(coins []string) error {
-	log.Println("初始化WebSocket监控器...")
+	ctx, cancel := requestContext()
+	defer cancel()
+	log.Info("初始化WebSocket监控器...")
 	// 获取交易对信息
 	apiClient := NewAPIClient()
 	// 如果不指定交易对，则使用market市场的所有交易对币种
 	if len(coins) == 0 {
-		exchangeInfo, err := apiClient.GetExchangeInfo()
+		exchangeInfo, err := apiClient.GetExchangeInfo(ctx)
 		if err != nil {
 			return err
 		}
@@ -105,38 +112,33 @@ func (m *WSMonitor) Initialize(coins []string) error {
 		m.symbols = coins
 	}
 
-	log.Printf("找到 %d 个交易对", len(m.symbols))
+	log.Info("找到交易对", "symbol_count", len(m.symbols))
 
 	// WebSocket 訂閱流數檢查與自動調整
 	totalStreams := len(m.symbols) * len(m.timeframes)
 
 	if len(m.symbols) > SafeMaxSymbols {
-		log.Printf("⚠️  幣種數量過多，自動調整:")
-		log.Printf("   - 原始數量: %d 個幣種 (%d 流)", len(m.symbols), totalStreams)
-		log.Printf("   - Binance 限制: %d 流/連接", MaxStreamsPerConnection)
-		log.Printf("   - 時間週期: %d (%v)", len(m.timeframes), m.timeframes)
+		log.Warn("⚠️ 幣種數量過多，自動調整", "symbol_count", len(m.symbols), "stream_count", totalStreams, "max_streams_per_connection", MaxStreamsPerConnection, "timeframes", m.timeframes)
 
 		// 調整到安全上限
 		m.symbols = m.symbols[:SafeMaxSymbols]
 		totalStreams = len(m.symbols) * len(m.timeframes)
 
-		log.Printf("   - 調整後: %d 個幣種 (%d 流)", len(m.symbols), totalStreams)
-		log.Printf("   - 已過濾: 前 %d 個幣種保留，其餘忽略", SafeMaxSymbols)
+		log.Info("   - 已調整：僅保留前列幣種，其餘忽略", "symbol_count", len(m.symbols), "stream_count", totalStreams)
 	}
 
 	// 顯示訂閱使用率
 	usagePercent := float64(totalStreams) / float64(MaxStreamsPerConnection) * 100
-	log.Printf("✓ WebSocket 訂閱: %d 個幣種 × %d 時間週期 = %d 流 (%.1f%% 用量)",
-		len(m.symbols), len(m.timeframes), totalStreams, usagePercent)
+	log.Info("✓ WebSocket 訂閱", "symbol_count", len(m.symbols), "timeframe_count", len(m.timeframes), "stream_count", totalStreams, "usage_pct", usagePercent)
 
 	// 接近上限警告（>90%）
 	if usagePercent > 90 {
-		log.Printf("⚠️  警告: 訂閱流使用率較高 (%.1f%%)，建議減少幣種數量以確保穩定性", usagePercent)
+		log.Warn("⚠️ 警告: 訂閱流使用率較高，建議減少幣種數量以確保穩定性", "usage_pct", usagePercent)
 	}
 
 	// 初始化历史数据
 	if err := m.initializeHistoricalData(); err != nil {
-		log.Printf("初始化历史数据失败: %v", err)
+		log.Warn("初始化历史数据失败", "error", err)
 	}
 
 	return nil
@@ -148,7 +150,7 @@ func (m *WSMonitor) initializeHistoricalData() error {
 	var wg sync.WaitGroup
 	semaphore := make(chan struct{}, 5) // 限制并发数
 
-	log.Printf("📥 开始加载历史数据，时间线: %v", m.timeframes)
+	log.Info("📥 开始加载历史数据", "timeframes", m.timeframes)
 
 	for _, symbol := range m.symbols {
 		wg.Add(1)
@@ -162,7 +164,7 @@ func (m *WSMonitor) initializeHistoricalData() error {
 			for _, tf := range m.timeframes {
 				klineDataMap := m.getKlineDataMap(tf)
 				if klineDataMap == nil {
-					log.Printf("⚠️  未知的时间线: %s", tf)
+					log.Warn("⚠️ 未知的时间线", "timeframe", tf)
 					continue
 				}
 
@@ -174,22 +176,24 @@ func (m *WSMonitor) initializeHistoricalData() error {
 					maxRetries = 3
 				}
 
+				ctx, cancel := requestContext()
 				for retry := 0; retry < maxRetries; retry++ {
-					klines, err = apiClient.GetKlines(s, tf, 100)
+					klines, err = apiClient.GetKlines(ctx, s, tf, 100)
 					if err == nil && len(klines) > 0 {
 						break
 					}
 					if retry < maxRetries-1 {
-						log.Printf("获取 %s %s历史数据失败 (尝试 %d/%d): %v，1秒后重试...", s, tf, retry+1, maxRetries, err)
+						log.Warn("获取历史数据失败，1秒后重试...", "symbol", s, "timeframe", tf, "attempt", retry+1, "max_retries", maxRetries, "error", err)
 						time.Sleep(1 * time.Second)
 					}
 				}
+				cancel()
 
 				if err != nil {
 					if maxRetries > 1 {
-						log.Printf("❌ 获取 %s %s历史数据失败（已重试%d次）: %v", s, tf, maxRetries, err)
+						log.Error("❌ 获取历史数据失败", "symbol", s, "timeframe", tf, "max_retries", maxRetries, "error", err)
 					} else {
-						log.Printf("获取 %s %s历史数据失败: %v", s, tf, err)
+						log.Warn("获取历史数据失败", "symbol", s, "timeframe", tf, "error", err)
 					}
 				} else if len(klines) > 0 {
 					// ✅ 修复类型不一致：使用 KlineCacheEntry 包装
@@ -198,33 +202,35 @@ func (m *WSMonitor) initializeHistoricalData() error {
 						ReceivedAt: time.Now(),
 					}
 					klineDataMap.Store(s, entry)
-					log.Printf("✅ 已加载 %s 的历史K线数据-%s: %d 条", s, tf, len(klines))
+					log.Info("✅ 已加载历史K线数据", "symbol", s, "timeframe", tf, "kline_count", len(klines))
 				} else {
-					log.Printf("⚠️  WARNING: %s %s数据为空（API返回成功但无数据）", s, tf)
+					log.Warn("⚠️ 数据为空（API返回成功但无数据）", "symbol", s, "timeframe", tf)
 				}
 			}
 
 			// 🚀 优化：回填历史OI数据（15分钟粒度，最近20个数据点 = 5小时）
-			oiHistory, err := apiClient.GetOpenInterestHistory(s, "15m", 20)
+			ctx, cancel := requestContext()
+			defer cancel()
+			oiHistory, err := apiClient.GetOpenInterestHistory(ctx, s, "15m", 20)
 			normalizedSymbol := strings.ToUpper(s)
 
 			if err != nil || len(oiHistory) == 0 {
 				// ✅ 修复：无论是API错误还是返回空数组，都尝试降级方案
 				if err != nil {
-					log.Printf("⚠️  获取 %s OI历史数据失败: %v，尝试降级方案...", s, err)
+					log.Warn("⚠️ 获取OI历史数据失败，尝试降级方案...", "symbol", s, "error", err)
 				} else {
-					log.Printf("⚠️  %s OI历史数据为空（API返回成功但无数据），尝试降级方案...", normalizedSymbol)
+					log.Warn("⚠️ OI历史数据为空（API返回成功但无数据），尝试降级方案...", "symbol", normalizedSymbol)
 				}
 
 				// ✅ 修复：降级方案 - 至少获取当前OI作为第一个数据点
-				currentOI, currentErr := apiClient.GetOpenInterest(s)
+				currentOI, currentErr := apiClient.GetOpenInterest(ctx, s)
 				if currentErr != nil {
-					log.Printf("❌ 获取 %s 当前OI也失败: %v，该币种将无OI数据", s, currentErr)
+					log.Error("❌ 获取当前OI也失败，该币种将无OI数据", "symbol", s, "error", currentErr)
 				} else {
 					// 创建单个数据点作为起始
 					oiHistory = []OISnapshot{{Value: currentOI.Latest, Timestamp: time.Now()}}
 					m.oiHistoryMap.Store(normalizedSymbol, oiHistory)
-					log.Printf("✅ %s 使用降级方案：仅1个OI数据点（%.0f），将在15分钟后开始累积历史数据", normalizedSymbol, currentOI.Latest)
+					log.Info("✅ 使用降级方案：仅1个OI数据点，将在15分钟后开始累积历史数据", "symbol", normalizedSymbol, "oi", currentOI.Latest)
 				}
 			} else {
 				// ✅ 成功获取历史数据
@@ -234,8 +240,7 @@ func (m *WSMonitor) initializeHistoricalData() error {
 				oldest := oiHistory[0].Timestamp
 				newest := oiHistory[len(oiHistory)-1].Timestamp
 				timeSpan := newest.Sub(oldest)
-				log.Printf("✅ 已回填 %s 的历史OI数据: %d 个快照（时间范围: %s ~ %s，跨度 %.1f 小时）",
-					normalizedSymbol, len(oiHistory), oldest.Format("15:04"), newest.Format("15:04"), timeSpan.Hours())
+				log.Info("✅ 已回填历史OI数据", "symbol", normalizedSymbol, "snapshot_count", len(oiHistory), "oldest", oldest.Format("15:04"), "newest", newest.Format("15:04"), "time_span_hours", timeSpan.Hours())
 			}
 		}(symbol)
 	}
@@ -245,23 +250,23 @@ func (m *WSMonitor) initializeHistoricalData() error {
 }
 
 func (m *WSMonitor) Start(coins []string) {
-	log.Printf("启动WebSocket实时监控...")
+	log.Info("启动WebSocket实时监控...")
 	// 初始化交易对
 	err := m.Initialize(coins)
 	if err != nil {
-		log.Printf("❌ 初始化币种失败: %v", err)
+		log.Error("❌ 初始化币种失败", "error", err)
 		return
 	}
 
 	err = m.combinedClient.Connect()
 	if err != nil {
-		log.Printf("❌ 批量订阅流失败: %v", err)
+		log.Error("❌ 批量订阅流失败", "error", err)
 		return
 	}
 	// 订阅所有交易对
 	err = m.subscribeAll()
 	if err != nil {
-		log.Printf("❌ 订阅币种交易对失败: %v", err)
+		log.Error("❌ 订阅币种交易对失败", "error", err)
 		return
 	}
 
@@ -280,7 +285,7 @@ func (m *WSMonitor) subscribeSymbol(symbol, st string) []string {
 	return streams
 }
 func (m *WSMonitor) subscribeAll() error {
-	log.Println("开始订阅所有交易对...")
+	log.Info("开始订阅所有交易对...")
 
 	for _, symbol := range m.symbols {
 		for _, st := range m.timeframes {
@@ -292,11 +297,11 @@ func (m *WSMonitor) subscribeAll() error {
 	for _, st := range m.timeframes {
 		err := m.combinedClient.BatchSubscribeKlines(m.symbols, st)
 		if err != nil {
-			log.Printf("❌ 订阅 %s K线失败: %v", st, err)
+			log.Error("❌ 订阅K线失败", "stream", st, "error", err)
 			return err
 		}
 	}
-	log.Println("所有交易对订阅完成")
+	log.Info("所有交易对订阅完成")
 	return nil
 }
 
@@ -304,7 +309,7 @@ func (m *WSMonitor) handleKlineData(symbol string, ch <-chan []byte, _time strin
 	for data := range ch {
 		var klineData KlineWSData
 		if err := json.Unmarshal(data, &klineData); err != nil {
-			log.Printf("解析Kline数据失败: %v", err)
+			log.Warn("解析Kline数据失败", "error", err)
 			continue
 		}
 		m.processKlineUpdate(symbol, klineData, _time)
@@ -335,20 +340,7 @@ func (m *WSMonitor) getKlineDataMap(_time string) *sync.Map {
 }
 func (m *WSMonitor) processKlineUpdate(symbol string, wsData KlineWSData, _time string) {
 	// 转换WebSocket数据为Kline结构
-	kline := Kline{
-		OpenTime:  wsData.Kline.StartTime,
-		CloseTime: wsData.Kline.CloseTime,
-		Trades:    wsData.Kline.NumberOfTrades,
-	}
-	kline.Open, _ = parseFloat(wsData.Kline.OpenPrice)
-	kline.High, _ = parseFloat(wsData.Kline.HighPrice)
-	kline.Low, _ = parseFloat(wsData.Kline.LowPrice)
-	kline.Close, _ = parseFloat(wsData.Kline.ClosePrice)
-	kline.Volume, _ = parseFloat(wsData.Kline.Volume)
-	kline.High, _ = parseFloat(wsData.Kline.HighPrice)
-	kline.QuoteVolume, _ = parseFloat(wsData.Kline.QuoteVolume)
-	kline.TakerBuyBaseVolume, _ = parseFloat(wsData.Kline.TakerBuyBaseVolume)
-	kline.TakerBuyQuoteVolume, _ = parseFloat(wsData.Kline.TakerBuyQuoteVolume)
+	kline := klineFromWSData(wsData)
 	// 更新K线数据
 	var klineDataMap = m.getKlineDataMap(_time)
 	value, exists := klineDataMap.Load(symbol)
@@ -362,10 +354,10 @@ func (m *WSMonitor) processKlineUpdate(symbol string, wsData KlineWSData, _time
 		case []Kline:
 			// 旧版本格式：直接是 []Kline（兼容旧数据）
 			klines = v
-			log.Printf("⚠️ 检测到旧格式缓存数据 %s %s，将自动升级", symbol, _time)
+			log.Warn("⚠️ 检测到旧格式缓存数据，将自动升级", "symbol", symbol, "timeframe", _time)
 		default:
 			// 未知类型，重新初始化
-			log.Printf("❌ 未知的缓存数据类型 %s %s: %T，重新初始化", symbol, _time, v)
+			log.Error("❌ 未知的缓存数据类型，重新初始化", "symbol", symbol, "timeframe", _time, "v", v)
 			klines = []Kline{}
 		}
 
@@ -395,12 +387,15 @@ func (m *WSMonitor) processKlineUpdate(symbol string, wsData KlineWSData, _time
 }
 
 func (m *WSMonitor) GetCurrentKlines(symbol string, duration string) ([]Kline, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
 	// 对每一个进来的symbol检测是否存在内类 是否的话就订阅它
 	value, exists := m.getKlineDataMap(duration).Load(symbol)
 	if !exists {
+		metrics.CacheLookup("kline_ws", false)
 		// 如果Ws数据未初始化完成时,单独使用api获取 - 兼容性代码 (防止在未初始化完成是,已经有交易员运行)
 		apiClient := NewAPIClient()
-		klines, err := apiClient.GetKlines(symbol, duration, 100)
+		klines, err := apiClient.GetKlines(ctx, symbol, duration, 100)
 		if err != nil {
 			return nil, fmt.Errorf("获取%v分钟K线失败: %v", duration, err)
 		}
@@ -415,9 +410,9 @@ func (m *WSMonitor) GetCurrentKlines(symbol string, duration string) ([]Kline, e
 		// 订阅 WebSocket 流
 		subStr := m.subscribeSymbol(symbol, duration)
 		subErr := m.combinedClient.subscribeStreams(subStr)
-		log.Printf("动态订阅流: %v", subStr)
+		log.Info("动态订阅流", "streams", subStr)
 		if subErr != nil {
-			log.Printf("警告: 动态订阅%v分钟K线失败: %v (使用API数据)", duration, subErr)
+			log.Warn("⚠️ 动态订阅K线失败（使用API数据）", "duration_min", duration, "error", subErr)
 		}
 
 		// ✅ FIX: 返回深拷贝而非引用
@@ -437,14 +432,14 @@ func (m *WSMonitor) GetCurrentKlines(symbol string, duration string) ([]Kline, e
 	dataAge := time.Since(entry.ReceivedAt)
 	maxAge := 5 * time.Minute
 
+	metrics.CacheLookup("kline_ws", dataAge <= maxAge)
 	if dataAge > maxAge {
 		// ⚠️ 数据过期，记录警告并尝试 API fallback
-		log.Printf("⚠️ %s 的 %s K线数据已过期 (%.1f 分钟)，WebSocket 可能停止工作，尝试 API fallback",
-			symbol, duration, dataAge.Minutes())
+		log.Warn("⚠️ K线数据已过期，WebSocket 可能停止工作，尝试 API fallback", "symbol", symbol, "interval", duration, "data_age_min", dataAge.Minutes())
 
 		// 🔧 P0修复：數據過期時，嘗試 API fallback（避免 AI 用過期數據決策）
 		apiClient := NewAPIClient()
-		freshKlines, err := apiClient.GetKlines(symbol, duration, 100)
+		freshKlines, err := apiClient.GetKlines(ctx, symbol, duration, 100)
 		if err != nil {
 			return nil, fmt.Errorf("%s 的 %s K线数据已过期且 API fallback 失败: %v", symbol, duration, err)
 		}
@@ -455,7 +450,7 @@ func (m *WSMonitor) GetCurrentKlines(symbol string, duration string) ([]Kline, e
 			ReceivedAt: time.Now(),
 		}
 		m.getKlineDataMap(duration).Store(strings.ToUpper(symbol), freshEntry)
-		log.Printf("✅ %s %s API fallback 成功，已更新緩存 (%d 條數據)", symbol, duration, len(freshKlines))
+		log.Info("✅ API fallback 成功，已更新緩存", "symbol", symbol, "interval", duration, "kline_count", len(freshKlines))
 
 		result := make([]Kline, len(freshKlines))
 		copy(result, freshKlines)
@@ -514,7 +509,7 @@ func (m *WSMonitor) StoreOISnapshot(symbol string, oiValue float64) {
 
 	// 診斷日誌（僅前3次採集時輸出）
 	if len(history) <= 3 {
-		log.Printf("📝 [OI存儲] Symbol: %s, OI: %.0f, 历史数据点数: %d", symbol, oiValue, len(history))
+		log.Info("📝 [OI存儲]", "symbol", symbol, "oi_value", oiValue, "history_count", len(history))
 	}
 }
 
@@ -533,36 +528,35 @@ func (m *WSMonitor) GetOIHistory(symbol string) []OISnapshot {
 // CalculateOIChange4h 计算4小时OI变化率（如果数据不足，降级到最长可用时间）
 // 返回：(变化率百分比, 实际时间段字符串)
 func (m *WSMonitor) CalculateOIChange4h(symbol string, latestOI float64) (float64, string) {
+	ctx, cancel := requestContext()
+	defer cancel()
 	// ✅ 修复：统一symbol格式（确保大小写一致）
 	symbol = strings.ToUpper(symbol)
 
 	history := m.GetOIHistory(symbol)
 	if len(history) == 0 {
 		// ✅ P0修复：歷史數據為空時，嘗試從 API 回填（降級方案）
-		log.Printf("⚠️  %s: OI历史数据为空，尝试从API回填历史数据...", symbol)
+		log.Warn("⚠️ OI历史数据为空，尝试从API回填历史数据...", "symbol", symbol)
 		apiClient := NewAPIClient()
-		historyFromAPI, err := apiClient.GetOpenInterestHistory(symbol, "15m", 20) // 获取20个15分钟数据点（5小时）
+		historyFromAPI, err := apiClient.GetOpenInterestHistory(ctx, symbol, "15m", 20) // 获取20个15分钟数据点（5小时）
 		if err != nil {
-			log.Printf("❌ %s: 从API回填OI历史数据失败: %v，无法计算变化率", symbol, err)
+			log.Error("❌ 从API回填OI历史数据失败，无法计算变化率", "symbol", symbol, "error", err)
 			return 0.0, "N/A" // API回填也失败，无法计算
 		}
 
 		if len(historyFromAPI) == 0 {
-			log.Printf("⚠️  %s: API返回的OI历史数据为空，无法计算变化率", symbol)
+			log.Warn("⚠️ API返回的OI历史数据为空，无法计算变化率", "symbol", symbol)
 			return 0.0, "N/A"
 		}
 
 		// 将回填的数据直接存储到缓存中（保留原始时间戳）
 		m.oiHistoryMap.Store(symbol, historyFromAPI)
-		log.Printf("✅ %s: 成功从API回填 %d 个OI历史数据点（时间跨度: %s 到 %s）",
-			symbol, len(historyFromAPI),
-			historyFromAPI[0].Timestamp.Format("15:04"),
-			historyFromAPI[len(historyFromAPI)-1].Timestamp.Format("15:04"))
+		log.Info("✅ 成功从API回填OI历史数据点", "symbol", symbol, "history_count", len(historyFromAPI), "from", historyFromAPI[0].Timestamp.Format("15:04"), "to", historyFromAPI[len(historyFromAPI)-1].Timestamp.Format("15:04"))
 
 		// 重新获取历史数据（现在应该有数据了）
 		history = m.GetOIHistory(symbol)
 		if len(history) == 0 {
-			log.Printf("❌ %s: 回填后历史数据仍为空，存储失败", symbol)
+			log.Error("❌ 回填后历史数据仍为空，存储失败", "symbol", symbol)
 			return 0.0, "N/A"
 		}
 	}
@@ -570,7 +564,7 @@ func (m *WSMonitor) CalculateOIChange4h(symbol string, latestOI float64) (float6
 	// ✅ 修复：只有 1 個數據點時，返回特殊標記而非 N/A
 	// 這樣至少能顯示 Latest 值，只是無法計算變化率
 	if len(history) == 1 {
-		log.Printf("⚠️  %s: OI历史数据仅1个点（系统刚启动），变化率为0", symbol)
+		log.Warn("⚠️ OI历史数据仅1个点（系统刚启动），变化率为0", "symbol", symbol)
 		return 0.0, "0m" // 特殊標記：剛啟動，無變化率數據
 	}
 
@@ -619,7 +613,7 @@ func (m *WSMonitor) CalculateOIChange4h(symbol string, latestOI float64) (float6
 
 	// 计算变化率
 	if oiOld == 0 {
-		log.Printf("⚠️  %s: 历史OI值为0，无法计算变化率", symbol)
+		log.Warn("⚠️ 历史OI值为0，无法计算变化率", "symbol", symbol)
 		return 0.0, "N/A"
 	}
 
@@ -627,11 +621,9 @@ func (m *WSMonitor) CalculateOIChange4h(symbol string, latestOI float64) (float6
 
 	// 根據實際使用的時間段記錄日誌
 	if actualPeriod == "4h" {
-		log.Printf("✅ %s: OI 4h变化 %.3f%% (当前: %.0f, 4h前: %.0f)",
-			symbol, change, latestOI, oiOld)
+		log.Info("✅ OI 4h变化", "symbol", symbol, "change_pct", change, "latest_oi", latestOI, "old_oi", oiOld)
 	} else {
-		log.Printf("⚠️  %s: OI %s变化 %.3f%% (当前: %.0f, %s前: %.0f) [系统运行时间不足4h，使用降级计算]",
-			symbol, actualPeriod, change, latestOI, actualPeriod, oiOld)
+		log.Warn("⚠️ OI变化（系统运行时间不足4h，使用降级计算）", "symbol", symbol, "period", actualPeriod, "change_pct", change, "latest_oi", latestOI, "old_oi", oiOld)
 	}
 
 	return change, actualPeriod
@@ -639,7 +631,7 @@ func (m *WSMonitor) CalculateOIChange4h(symbol string, latestOI float64) (float6
 
 // StartOIMonitoring 启动OI定期监控（每15分钟采样）
 func (m *WSMonitor) StartOIMonitoring() {
-	log.Println("✅ 启动 OI 定期监控（每15分钟采样）")
+	log.Info("✅ 启动 OI 定期监控（每15分钟采样）")
 
 	// 初始化停止通道
 	m.oiStopChan = make(chan struct{})
@@ -656,7 +648,7 @@ func (m *WSMonitor) StartOIMonitoring() {
 			case <-ticker.C:
 				m.collectOISnapshots()
 			case <-m.oiStopChan:
-				log.Println("🛑 停止 OI 定期监控")
+				log.Info("🛑 停止 OI 定期监控")
 				return
 			}
 		}
@@ -685,22 +677,25 @@ func (m *WSMonitor) collectOISnapshots() {
 			defer wg.Done()
 			defer func() { <-semaphore }()
 
+			ctx, cancel := requestContext()
+			defer cancel()
+
 			// ✅ 修复：添加重试机制（最多3次）
 			var oiData *OIData
 			var err error
 			for retry := 0; retry < 3; retry++ {
-				oiData, err = apiClient.GetOpenInterest(s)
+				oiData, err = apiClient.GetOpenInterest(ctx, s)
 				if err == nil {
 					break
 				}
 				if retry < 2 {
-					log.Printf("⚠️  获取 %s OI失败 (尝试 %d/3): %v，1秒后重试...", s, retry+1, err)
+					log.Warn("⚠️ 获取OI失败，1秒后重试...", "symbol", s, "attempt", retry+1, "error", err)
 					time.Sleep(1 * time.Second)
 				}
 			}
 
 			if err != nil {
-				log.Printf("❌ 获取 %s OI失败（已重试3次）: %v", s, err)
+				log.Error("❌ 获取OI失败（已重试3次）", "symbol", s, "error", err)
 				return
 			}
 
@@ -716,6 +711,5 @@ func (m *WSMonitor) collectOISnapshots() {
 	wg.Wait()
 
 	elapsed := time.Since(startTime)
-	log.Printf("✅ OI快照采集完成（成功: %d/%d，耗时: %.1f秒，时间: %s）",
-		successCount, len(m.symbols), elapsed.Seconds(), time.Now().Format("15:04:05"))
+	log.Info("✅ OI快照采集完成", "success_count", successCount, "symbol_count", len(m.symbols), "elapsed_sec", elapsed.Seconds(), "time", time.Now().Format("15:04:05"))
 }
@@ -0,0 +1,110 @@
+package market
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MultiTimeframeWorkers GetMultiTimeframe 同时获取的最大周期数
+var MultiTimeframeWorkers = 4
+
+// multiTimeframeFetch 获取单个周期的K线（WSMonitor 已初始化时读取其缓存，否则直接请求 API；测试中可替换）
+var multiTimeframeFetch = func(symbol, interval string, limit int) ([]Kline, error) {
+	if WSMonitorCli == nil {
+		ctx, cancel := requestContext()
+		defer cancel()
+		return NewAPIClient().GetKlines(ctx, symbol, interval, limit)
+	}
+	klines, err := WSMonitorCli.GetCurrentKlines(symbol, interval)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+// MultiTimeframeKlines 同一币种多个周期的K线快照
+type MultiTimeframeKlines struct {
+	Symbol string
+	Series map[string][]Kline // 周期 -> K线（按时间正序）
+	Errors map[string]error   // 获取失败的周期 -> 错误
+}
+
+// Klines 返回指定周期的K线（未获取或失败时为 nil）
+func (m *MultiTimeframeKlines) Klines(interval string) []Kline {
+	return m.Series[interval]
+}
+
+// Err 返回指定周期的获取错误
+func (m *MultiTimeframeKlines) Err(interval string) error {
+	return m.Errors[interval]
+}
+
+// GetMultiTimeframe 并发获取多个周期的K线（并发数受 MultiTimeframeWorkers 限制）
+// 单个周期失败记录在 Errors 中，全部周期都失败时返回错误
+func GetMultiTimeframe(symbol string, intervals []string, limit int) (*MultiTimeframeKlines, error) {
+	symbol = Normalize(symbol)
+	result := &MultiTimeframeKlines{
+		Symbol: symbol,
+		Series: make(map[string][]Kline, len(intervals)),
+		Errors: make(map[string]error),
+	}
+
+	// 去重，保持原顺序
+	seen := make(map[string]bool, len(intervals))
+	unique := make([]string, 0, len(intervals))
+	for _, interval := range intervals {
+		if !seen[interval] {
+			seen[interval] = true
+			unique = append(unique, interval)
+		}
+	}
+	if len(unique) == 0 {
+		return result, nil
+	}
+
+	workers := MultiTimeframeWorkers
+	if workers <= 0 {
+		workers = 1
+	}
+	if workers > len(unique) {
+		workers = len(unique)
+	}
+
+	jobs := make(chan string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for interval := range jobs {
+				klines, err := multiTimeframeFetch(symbol, interval, limit)
+				mu.Lock()
+				if err != nil {
+					result.Errors[interval] = err
+				} else {
+					result.Series[interval] = klines
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for _, interval := range unique {
+		jobs <- interval
+	}
+	close(jobs)
+	wg.Wait()
+
+	if len(result.Errors) == len(unique) {
+		msgs := make([]string, 0, len(unique))
+		for _, interval := range unique {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", interval, result.Errors[interval]))
+		}
+		return result, fmt.Errorf("获取 %s 多周期K线全部失败: %s", symbol, strings.Join(msgs, "; "))
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package market
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGetMultiTimeframeConcurrent(t *testing.T) {
+	origFetch, origWorkers := multiTimeframeFetch, MultiTimeframeWorkers
+	defer func() { multiTimeframeFetch, MultiTimeframeWorkers = origFetch, origWorkers }()
+
+	var running, peak int32
+	MultiTimeframeWorkers = 2
+	multiTimeframeFetch = func(symbol, interval string, limit int) ([]Kline, error) {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		if symbol != "BTCUSDT" || limit != 50 {
+			t.Errorf("unexpected fetch args: %s %d", symbol, limit)
+		}
+		if interval == "1d" {
+			return nil, errors.New("boom")
+		}
+		return []Kline{{OpenTime: 1, Close: 100}}, nil
+	}
+
+	start := time.Now()
+	snapshot, err := GetMultiTimeframe("btc", []string{"3m", "15m", "1h", "4h", "1d", "1h"}, 50)
+	if err != nil {
+		t.Fatalf("GetMultiTimeframe 失败: %v", err)
+	}
+	if peak > 2 {
+		t.Errorf("并发数超过上限: %d", peak)
+	}
+	if elapsed := time.Since(start); elapsed >= 100*time.Millisecond {
+		t.Errorf("未并发获取，耗时 %v", elapsed)
+	}
+	if len(snapshot.Series) != 4 || len(snapshot.Klines("4h")) != 1 {
+		t.Errorf("unexpected series: %+v", snapshot.Series)
+	}
+	if snapshot.Err("1d") == nil || snapshot.Klines("1d") != nil {
+		t.Error("期望 1d 记录错误")
+	}
+}
+
+func TestGetMultiTimeframeAllFailed(t *testing.T) {
+	origFetch := multiTimeframeFetch
+	defer func() { multiTimeframeFetch = origFetch }()
+	multiTimeframeFetch = func(symbol, interval string, limit int) ([]Kline, error) {
+		return nil, errors.New("unavailable")
+	}
+
+	if _, err := GetMultiTimeframe("ETHUSDT", []string{"15m", "1h"}, 10); err == nil {
+		t.Error("全部周期失败时期望返回错误")
+	}
+}
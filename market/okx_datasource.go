@@ -0,0 +1,836 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"nofx/exchangehttp"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"nofx/ratelimit"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultOKXBaseURL   = "https://www.okx.com"
+	defaultOKXStreamURL = "wss://ws.okx.com:8443/ws/v5/business"
+	defaultOKXPublicURL = "wss://ws.okx.com:8443/ws/v5/public"
+
+	// 模拟盘（Demo Trading）：REST 与实盘同域名，通过 x-simulated-trading: 1 请求头区分；WebSocket 使用 wspap 域名
+	demoOKXStreamURL = "wss://wspap.okx.com:8443/ws/v5/business"
+	demoOKXPublicURL = "wss://wspap.okx.com:8443/ws/v5/public"
+)
+
+// OKXDataSource 封装 OKX 合约公开行情作为数据源（不需要认证；默认 U 本位永续，可按币种配置币本位/交割合约）
+type OKXDataSource struct {
+	client  *http.Client
+	baseURL string
+	wsURL   string // business 频道（K线）
+	pubURL  string // public 频道（行情）
+	name    string
+	// simulated 模拟盘行情（请求带 x-simulated-trading: 1）
+	simulated bool
+
+	instMu      sync.RWMutex
+	instruments map[string]*OKXInstrument // instId -> 合约规格
+}
+
+// okxResponse OKX v5 API 通用响应
+type okxResponse struct {
+	Code string          `json:"code"`
+	Msg  string          `json:"msg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// NewOKXDataSource 创建 OKX 数据源实例
+func NewOKXDataSource() *OKXDataSource {
+	return &OKXDataSource{
+		client:  exchangehttp.NewHTTPClient("okx", ratelimit.OKXGroup, 30*time.Second),
+		baseURL: netconfig.BaseURL("okx", defaultOKXBaseURL),
+		wsURL:   netconfig.BaseURL("okx-ws", defaultOKXStreamURL),
+		pubURL:  netconfig.BaseURL("okx-ws", defaultOKXPublicURL),
+		name:    "OKX",
+
+		instruments: make(map[string]*OKXInstrument),
+	}
+}
+
+// NewOKXDemoDataSource 创建 OKX 模拟盘数据源（行情与模拟盘撮合一致，用于对接模拟盘的端到端测试）
+func NewOKXDemoDataSource() *OKXDataSource {
+	o := NewOKXDataSource()
+	o.wsURL = demoOKXStreamURL
+	o.pubURL = demoOKXPublicURL
+	o.name = "OKX Demo"
+	o.simulated = true
+	return o
+}
+
+// GetName 获取数据源名称
+func (o *OKXDataSource) GetName() string {
+	return o.name
+}
+
+// GetKlines 获取K线数据（OKX 返回按时间倒序，这里转换为正序）
+func (o *OKXDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("bar", convertIntervalToOKX(interval))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]string
+	if err := o.get(ctx, "/api/v5/market/candles", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetKlines 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("okx GetKlines failed: %w", err)
+	}
+
+	intervalMs := intervalToMillis(interval)
+	klines := make([]Kline, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		kline, err := convertOKXCandle(rows[i], intervalMs)
+		if err != nil {
+			return nil, fmt.Errorf("okx GetKlines parse failed: %w", err)
+		}
+		klines = append(klines, kline)
+	}
+
+	log.Info("✅ OKX GetKlines 成功", "symbol", symbol, "interval", interval, "kline_count", len(klines))
+	return klines, nil
+}
+
+// GetKlinesRange 按时间范围获取历史K线（history-candles，单页最多 100 根，返回按时间倒序，这里转换为正序）
+func (o *OKXDataSource) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("bar", convertIntervalToOKX(interval))
+	params.Set("after", strconv.FormatInt(endTime+1, 10))    // 返回早于该时间戳的K线
+	params.Set("before", strconv.FormatInt(startTime-1, 10)) // 返回晚于该时间戳的K线
+	params.Set("limit", strconv.Itoa(min(limit, 100)))
+
+	var rows [][]string
+	if err := o.get(ctx, "/api/v5/market/history-candles", params, &rows); err != nil {
+		return nil, fmt.Errorf("okx GetKlinesRange failed: %w", err)
+	}
+
+	intervalMs := intervalToMillis(interval)
+	klines := make([]Kline, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		kline, err := convertOKXCandle(rows[i], intervalMs)
+		if err != nil {
+			return nil, fmt.Errorf("okx GetKlinesRange parse failed: %w", err)
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// GetTicker 获取ticker数据
+func (o *OKXDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+
+	var tickers []struct {
+		Last   string `json:"last"`
+		Vol24h string `json:"vol24h"`
+	}
+	if err := o.get(ctx, "/api/v5/market/ticker", params, &tickers); err != nil {
+		log.Warn("⚠️ OKX GetTicker 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetTicker failed: %w", err)
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("okx GetTicker: no data for %s", symbol)
+	}
+
+	price, err := strconv.ParseFloat(tickers[0].Last, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse price failed: %w", err)
+	}
+	volume, _ := strconv.ParseFloat(tickers[0].Vol24h, 64)
+
+	ticker := &Ticker{
+		Symbol:    symbol,
+		LastPrice: price,
+		Volume:    volume,
+		Timestamp: time.Now().Unix(),
+	}
+
+	log.Info("✅ OKX GetTicker 成功", "symbol", symbol, "price", price)
+	return ticker, nil
+}
+
+// GetFundingRate 获取当前（预测）资金费率
+func (o *OKXDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+
+	var rates []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime string `json:"fundingTime"`
+	}
+	if err := o.get(ctx, "/api/v5/public/funding-rate", params, &rates); err != nil {
+		log.Warn("⚠️ OKX GetFundingRate 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetFundingRate failed: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("okx GetFundingRate: no data for %s", symbol)
+	}
+
+	rate, err := strconv.ParseFloat(rates[0].FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse funding rate failed: %w", err)
+	}
+	fundingTime, _ := strconv.ParseInt(rates[0].FundingTime, 10, 64)
+
+	return &FundingRate{Symbol: symbol, Rate: rate, FundingTime: fundingTime}, nil
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (o *OKXDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("limit", strconv.Itoa(n))
+
+	var rows []struct {
+		RealizedRate string `json:"realizedRate"`
+		FundingTime  string `json:"fundingTime"`
+	}
+	if err := o.get(ctx, "/api/v5/public/funding-rate-history", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetFundingRateHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetFundingRateHistory failed: %w", err)
+	}
+
+	history := make([]FundingRate, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		rate, err := strconv.ParseFloat(rows[i].RealizedRate, 64)
+		if err != nil {
+			continue
+		}
+		fundingTime, _ := strconv.ParseInt(rows[i].FundingTime, 10, 64)
+		history = append(history, FundingRate{Symbol: symbol, Rate: rate, FundingTime: fundingTime})
+	}
+	return history, nil
+}
+
+// HealthCheck 健康检查
+func (o *OKXDataSource) HealthCheck(ctx context.Context) error {
+	var serverTime []struct {
+		Ts string `json:"ts"`
+	}
+	if err := o.get(ctx, "/api/v5/public/time", nil, &serverTime); err != nil {
+		log.Error("❌ OKX 健康检查失败", "error", err)
+		return fmt.Errorf("okx health check failed: %w", err)
+	}
+
+	log.Info("✅ OKX 健康检查成功")
+	return nil
+}
+
+// GetLatency 获取延迟
+func (o *OKXDataSource) GetLatency() time.Duration {
+	ctx, cancel := requestContext()
+	defer cancel()
+	start := time.Now()
+	err := o.HealthCheck(ctx)
+	latency := time.Since(start)
+	metrics.DataSourceCheck(o.GetName(), latency, err)
+
+	log.Info("📊 OKX 延迟", "latency", latency)
+	return latency
+}
+
+// get 请求 OKX 公开接口并解析 data 字段
+func (o *OKXDataSource) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	api := &exchangehttp.Client{HTTP: o.client, BaseURL: o.baseURL, Decode: decodeOKXResponse, Retry: marketRetry}
+	if o.simulated {
+		api.Header = http.Header{"X-Simulated-Trading": {"1"}}
+	}
+	return api.Do(ctx, &exchangehttp.Request{Method: http.MethodGet, Path: path, Query: params, Idempotent: true}, out)
+}
+
+// decodeOKXResponse 解析 OKX v5 响应外层结构 {code, msg, data}
+func decodeOKXResponse(status int, body []byte, out interface{}) error {
+	if status != http.StatusOK {
+		return &exchangehttp.StatusError{Status: status, Body: body}
+	}
+	var result okxResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return err
+	}
+	if result.Code != "0" {
+		return fmt.Errorf("okx error %s: %s", result.Code, result.Msg)
+	}
+	return exchangehttp.Unmarshal(result.Data, out)
+}
+
+// GetOrderBook 获取盘口深度（OKX 单次最多 400 档）
+func (o *OKXDataSource) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("sz", strconv.Itoa(depth))
+
+	var books []okxOrderBook
+	if err := o.get(ctx, "/api/v5/market/books", params, &books); err != nil {
+		log.Warn("⚠️ OKX GetOrderBook 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetOrderBook failed: %w", err)
+	}
+	if len(books) == 0 {
+		return nil, fmt.Errorf("okx GetOrderBook: no data for %s", symbol)
+	}
+
+	book, err := books[0].toOrderBook(symbol)
+	if err != nil {
+		return nil, err
+	}
+	return book.truncate(depth), nil
+}
+
+// GetMarkPrice 获取当前标记价格
+func (o *OKXDataSource) GetMarkPrice(ctx context.Context, symbol string) (float64, error) {
+	instID := convertSymbolToOKX(symbol)
+	instType, err := okxInstType(instID)
+	if err != nil {
+		return 0, err
+	}
+	params := url.Values{}
+	params.Set("instType", instType)
+	params.Set("instId", instID)
+
+	var rows []struct {
+		MarkPx string `json:"markPx"`
+	}
+	if err := o.get(ctx, "/api/v5/public/mark-price", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetMarkPrice 失败", "symbol", symbol, "error", err)
+		return 0, fmt.Errorf("okx GetMarkPrice failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("okx GetMarkPrice: no data for %s", symbol)
+	}
+	price, err := strconv.ParseFloat(rows[0].MarkPx, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse mark price failed: %w", err)
+	}
+	return price, nil
+}
+
+// GetIndexPrice 获取当前指数价格
+func (o *OKXDataSource) GetIndexPrice(ctx context.Context, symbol string) (float64, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKXIndex(symbol))
+
+	var rows []struct {
+		IdxPx string `json:"idxPx"`
+	}
+	if err := o.get(ctx, "/api/v5/market/index-tickers", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetIndexPrice 失败", "symbol", symbol, "error", err)
+		return 0, fmt.Errorf("okx GetIndexPrice failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return 0, fmt.Errorf("okx GetIndexPrice: no data for %s", symbol)
+	}
+	price, err := strconv.ParseFloat(rows[0].IdxPx, 64)
+	if err != nil {
+		return 0, fmt.Errorf("parse index price failed: %w", err)
+	}
+	return price, nil
+}
+
+// GetBasisHistory 获取基差/溢价序列（标记价格K线与指数K线按时间对齐，按时间正序）
+func (o *OKXDataSource) GetBasisHistory(ctx context.Context, symbol, interval string, limit int) ([]BasisPoint, error) {
+	mark, err := o.getPriceCandles(ctx, "/api/v5/market/mark-price-candles", convertSymbolToOKX(symbol), interval, limit)
+	if err != nil {
+		log.Warn("⚠️ OKX GetBasisHistory 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("okx GetBasisHistory failed: %w", err)
+	}
+	index, err := o.getPriceCandles(ctx, "/api/v5/market/index-candles", convertSymbolToOKXIndex(symbol), interval, limit)
+	if err != nil {
+		log.Warn("⚠️ OKX GetBasisHistory 失败", "symbol", symbol, "interval", interval, "error", err)
+		return nil, fmt.Errorf("okx GetBasisHistory failed: %w", err)
+	}
+	return joinBasisSeries(mark, index), nil
+}
+
+// getPriceCandles 获取标记价格/指数K线的开盘时间和收盘价（按时间正序）
+// 返回格式 [ts, o, h, l, c, confirm]，按时间倒序
+func (o *OKXDataSource) getPriceCandles(ctx context.Context, path, instID, interval string, limit int) ([]pricePoint, error) {
+	params := url.Values{}
+	params.Set("instId", instID)
+	params.Set("bar", convertIntervalToOKX(interval))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]string
+	if err := o.get(ctx, path, params, &rows); err != nil {
+		return nil, err
+	}
+
+	points := make([]pricePoint, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		if len(rows[i]) < 5 {
+			continue
+		}
+		ts, err := strconv.ParseInt(rows[i][0], 10, 64)
+		if err != nil {
+			continue
+		}
+		closePrice, err := strconv.ParseFloat(rows[i][4], 64)
+		if err != nil {
+			continue
+		}
+		points = append(points, pricePoint{Timestamp: ts, Price: closePrice})
+	}
+	return points, nil
+}
+
+// GetOpenInterest 获取当前持仓量
+func (o *OKXDataSource) GetOpenInterest(ctx context.Context, symbol string) (*OpenInterest, error) {
+	instID := convertSymbolToOKX(symbol)
+	instType, err := okxInstType(instID)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("instType", instType)
+	params.Set("instId", instID)
+
+	var rows []struct {
+		OiCcy string `json:"oiCcy"`
+		OiUsd string `json:"oiUsd"`
+		Ts    string `json:"ts"`
+	}
+	if err := o.get(ctx, "/api/v5/public/open-interest", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetOpenInterest 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetOpenInterest failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("okx GetOpenInterest: no data for %s", symbol)
+	}
+
+	oi, err := strconv.ParseFloat(rows[0].OiCcy, 64)
+	if err != nil {
+		return nil, fmt.Errorf("parse open interest failed: %w", err)
+	}
+	oiUSD, _ := strconv.ParseFloat(rows[0].OiUsd, 64)
+	ts, _ := strconv.ParseInt(rows[0].Ts, 10, 64)
+
+	return &OpenInterest{Symbol: symbol, Value: oi, ValueUSD: oiUSD, Timestamp: ts}, nil
+}
+
+// GetOpenInterestHistory 获取历史持仓量（按时间正序，OKX 单次最多 100 条）
+// 返回格式 [ts, oi, oiCcy, oiUsd]，按时间倒序
+func (o *OKXDataSource) GetOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]OISnapshot, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("period", convertIntervalToOKX(period))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]string
+	if err := o.get(ctx, "/api/v5/rubik/stat/contracts/open-interest-history", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetOpenInterestHistory 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetOpenInterestHistory failed: %w", err)
+	}
+
+	history := make([]OISnapshot, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		if len(rows[i]) < 3 {
+			continue
+		}
+		ts, err := strconv.ParseInt(rows[i][0], 10, 64)
+		if err != nil {
+			continue
+		}
+		oi, err := strconv.ParseFloat(rows[i][2], 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, OISnapshot{Value: oi, Timestamp: time.UnixMilli(ts)})
+	}
+	return history, nil
+}
+
+// GetLongShortRatio 获取多空账户人数比（按时间正序，OKX 单次最多 100 条）
+// 返回格式 [ts, longShortAcctRatio]，按时间倒序
+func (o *OKXDataSource) GetLongShortRatio(ctx context.Context, symbol, period string, limit int) ([]LongShortRatio, error) {
+	params := url.Values{}
+	params.Set("instId", convertSymbolToOKX(symbol))
+	params.Set("period", convertIntervalToOKX(period))
+	params.Set("limit", strconv.Itoa(limit))
+
+	var rows [][]string
+	if err := o.get(ctx, "/api/v5/rubik/stat/contracts/long-short-account-ratio-contract", params, &rows); err != nil {
+		log.Warn("⚠️ OKX GetLongShortRatio 失败", "symbol", symbol, "error", err)
+		return nil, fmt.Errorf("okx GetLongShortRatio failed: %w", err)
+	}
+
+	ratios := make([]LongShortRatio, 0, len(rows))
+	for i := len(rows) - 1; i >= 0; i-- {
+		if len(rows[i]) < 2 {
+			continue
+		}
+		ts, err := strconv.ParseInt(rows[i][0], 10, 64)
+		if err != nil {
+			continue
+		}
+		ratio, err := strconv.ParseFloat(rows[i][1], 64)
+		if err != nil {
+			continue
+		}
+		ratios = append(ratios, LongShortRatio{Symbol: symbol, Ratio: ratio, Timestamp: ts})
+	}
+	return ratios, nil
+}
+
+// StreamKlines 订阅 OKX 永续合约K线推送
+func (o *OKXDataSource) StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	return startKlineStream(ctx, o.name, &okxKlineProtocol{wsURL: o.wsURL}, o.GetKlines, symbol, interval)
+}
+
+// okxKlineProtocol OKX v5 business 频道K线协议
+type okxKlineProtocol struct {
+	wsURL string
+}
+
+func (p *okxKlineProtocol) url(symbol, interval string) (string, error) {
+	return p.wsURL, nil
+}
+
+func (p *okxKlineProtocol) subscribeMessages(symbol, interval string) ([]interface{}, error) {
+	return []interface{}{map[string]interface{}{
+		"op": "subscribe",
+		"args": []map[string]string{{
+			"channel": "candle" + convertIntervalToOKX(interval),
+			"instId":  convertSymbolToOKX(symbol),
+		}},
+	}}, nil
+}
+
+func (p *okxKlineProtocol) heartbeat() interface{} {
+	return "ping" // 30 秒无消息会被服务端断开
+}
+
+func (p *okxKlineProtocol) parse(message []byte, intervalMs int64) ([]Kline, error) {
+	if string(message) == "pong" {
+		return nil, nil
+	}
+
+	var msg struct {
+		Event string     `json:"event"`
+		Msg   string     `json:"msg"`
+		Data  [][]string `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Event == "error" {
+		return nil, fmt.Errorf("okx subscribe error: %s", msg.Msg)
+	}
+
+	klines := make([]Kline, 0, len(msg.Data))
+	for _, row := range msg.Data {
+		kline, err := convertOKXCandle(row, intervalMs)
+		if err != nil {
+			return nil, err
+		}
+		klines = append(klines, kline)
+	}
+	return klines, nil
+}
+
+// StreamTickers 订阅 OKX 永续合约行情推送（tickers 频道，包含最新价与买一卖一）
+func (o *OKXDataSource) StreamTickers(ctx context.Context, symbols []string) <-chan Ticker {
+	return startTickerStream(ctx, o.name, symbols, func(symbols []string) tickerStreamProtocol {
+		p := &okxTickerProtocol{wsURL: o.pubURL, symbols: make(map[string]string, len(symbols))}
+		for _, symbol := range symbols {
+			p.symbols[convertSymbolToOKX(symbol)] = symbol
+		}
+		return p
+	})
+}
+
+// okxTickerProtocol OKX v5 public tickers 频道协议
+type okxTickerProtocol struct {
+	wsURL   string
+	symbols map[string]string // instId -> symbol
+}
+
+func (p *okxTickerProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *okxTickerProtocol) subscribeMessages() []interface{} {
+	instIDs := make([]string, 0, len(p.symbols))
+	for instID := range p.symbols {
+		instIDs = append(instIDs, instID)
+	}
+	sort.Strings(instIDs)
+
+	args := make([]map[string]string, len(instIDs))
+	for i, instID := range instIDs {
+		args[i] = map[string]string{"channel": "tickers", "instId": instID}
+	}
+	return []interface{}{map[string]interface{}{"op": "subscribe", "args": args}}
+}
+
+func (p *okxTickerProtocol) heartbeat() interface{} {
+	return "ping"
+}
+
+func (p *okxTickerProtocol) parse(message []byte) ([]Ticker, error) {
+	if string(message) == "pong" {
+		return nil, nil
+	}
+
+	var msg struct {
+		Event string `json:"event"`
+		Msg   string `json:"msg"`
+		Data  []struct {
+			InstID string `json:"instId"`
+			Last   string `json:"last"`
+			BidPx  string `json:"bidPx"`
+			AskPx  string `json:"askPx"`
+			Vol24h string `json:"vol24h"`
+			Ts     string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Event == "error" {
+		return nil, fmt.Errorf("okx subscribe error: %s", msg.Msg)
+	}
+
+	tickers := make([]Ticker, 0, len(msg.Data))
+	for _, d := range msg.Data {
+		symbol, ok := p.symbols[d.InstID]
+		if !ok {
+			continue
+		}
+		last, err := strconv.ParseFloat(d.Last, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price failed: %w", err)
+		}
+		bid, _ := strconv.ParseFloat(d.BidPx, 64)
+		ask, _ := strconv.ParseFloat(d.AskPx, 64)
+		volume, _ := strconv.ParseFloat(d.Vol24h, 64)
+		ts, _ := strconv.ParseInt(d.Ts, 10, 64)
+		tickers = append(tickers, Ticker{Symbol: symbol, LastPrice: last, BidPrice: bid, AskPrice: ask, Volume: volume, Timestamp: ts / 1000})
+	}
+	return tickers, nil
+}
+
+// StreamOrderBook 订阅 OKX 永续合约盘口推送（books5 频道，最多 5 档快照）
+func (o *OKXDataSource) StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error) {
+	protocol := &okxOrderBookProtocol{wsURL: o.pubURL, symbol: symbol, depth: depth}
+	return startOrderBookStream(ctx, o.name, symbol, depth, 5, protocol)
+}
+
+// okxOrderBookProtocol OKX v5 public books5 频道协议
+type okxOrderBookProtocol struct {
+	wsURL  string
+	symbol string
+	depth  int
+}
+
+func (p *okxOrderBookProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *okxOrderBookProtocol) subscribeMessages() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"op":   "subscribe",
+		"args": []map[string]string{{"channel": "books5", "instId": convertSymbolToOKX(p.symbol)}},
+	}}
+}
+
+func (p *okxOrderBookProtocol) heartbeat() interface{} {
+	return "ping"
+}
+
+func (p *okxOrderBookProtocol) parse(message []byte) ([]OrderBook, error) {
+	if string(message) == "pong" {
+		return nil, nil
+	}
+
+	var msg struct {
+		Event string         `json:"event"`
+		Msg   string         `json:"msg"`
+		Data  []okxOrderBook `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Event == "error" {
+		return nil, fmt.Errorf("okx subscribe error: %s", msg.Msg)
+	}
+
+	books := make([]OrderBook, 0, len(msg.Data))
+	for _, d := range msg.Data {
+		book, err := d.toOrderBook(p.symbol)
+		if err != nil {
+			return nil, err
+		}
+		books = append(books, *book.truncate(p.depth))
+	}
+	return books, nil
+}
+
+// StreamTrades 订阅 OKX 永续合约逐笔成交推送（trades 频道）
+func (o *OKXDataSource) StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error) {
+	return startTradeStream(ctx, o.name, symbol, &okxTradeProtocol{wsURL: o.pubURL, symbol: symbol})
+}
+
+// okxTradeProtocol OKX v5 public trades 频道协议
+type okxTradeProtocol struct {
+	wsURL  string
+	symbol string
+}
+
+func (p *okxTradeProtocol) url() string {
+	return p.wsURL
+}
+
+func (p *okxTradeProtocol) subscribeMessages() []interface{} {
+	return []interface{}{map[string]interface{}{
+		"op":   "subscribe",
+		"args": []map[string]string{{"channel": "trades", "instId": convertSymbolToOKX(p.symbol)}},
+	}}
+}
+
+func (p *okxTradeProtocol) heartbeat() interface{} {
+	return "ping"
+}
+
+func (p *okxTradeProtocol) parse(message []byte) ([]Trade, error) {
+	if string(message) == "pong" {
+		return nil, nil
+	}
+
+	var msg struct {
+		Event string `json:"event"`
+		Msg   string `json:"msg"`
+		Data  []struct {
+			TradeID string `json:"tradeId"`
+			Px      string `json:"px"`
+			Sz      string `json:"sz"`
+			Side    string `json:"side"` // 主动成交方向
+			Ts      string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Event == "error" {
+		return nil, fmt.Errorf("okx subscribe error: %s", msg.Msg)
+	}
+
+	trades := make([]Trade, 0, len(msg.Data))
+	for _, d := range msg.Data {
+		price, err := strconv.ParseFloat(d.Px, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price failed: %w", err)
+		}
+		qty, err := strconv.ParseFloat(d.Sz, 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse size failed: %w", err)
+		}
+		tradeID, _ := strconv.ParseInt(d.TradeID, 10, 64)
+		ts, _ := strconv.ParseInt(d.Ts, 10, 64)
+		side := TradeSideBuy
+		if d.Side == "sell" {
+			side = TradeSideSell
+		}
+		trades = append(trades, Trade{Symbol: p.symbol, TradeID: tradeID, Price: price, Quantity: qty, Side: side, Timestamp: ts})
+	}
+	return trades, nil
+}
+
+// okxOrderBook OKX 盘口数据（REST 与推送格式相同）
+type okxOrderBook struct {
+	Asks [][]string `json:"asks"` // [px, sz, 已弃用, 订单数]
+	Bids [][]string `json:"bids"`
+	Ts   string     `json:"ts"`
+}
+
+func (d okxOrderBook) toOrderBook(symbol string) (*OrderBook, error) {
+	bids, err := parseOrderBookLevels(d.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseOrderBookLevels(d.Asks)
+	if err != nil {
+		return nil, err
+	}
+	ts, _ := strconv.ParseInt(d.Ts, 10, 64)
+	return &OrderBook{Symbol: symbol, Bids: bids, Asks: asks, Timestamp: ts}, nil
+}
+
+// === Helper functions ===
+
+// convertSymbolToOKX 转换币种符号为 OKX 合约 instId（默认 BTCUSDT -> BTC-USDT-SWAP，SetOKXInstrument 配置的币种使用配置的合约）
+func convertSymbolToOKX(symbol string) string {
+	okxInstruments.RLock()
+	instID, ok := okxInstruments.bySymbol[symbol]
+	okxInstruments.RUnlock()
+	if ok {
+		return instID
+	}
+	if strings.HasSuffix(symbol, "USDT") {
+		return strings.TrimSuffix(symbol, "USDT") + "-USDT-SWAP"
+	}
+	return symbol
+}
+
+// convertSymbolToOKXIndex 转换币种符号为 OKX 指数 instId（BTC-USDT-SWAP -> BTC-USDT，BTC-USD-240927 -> BTC-USD）
+func convertSymbolToOKXIndex(symbol string) string {
+	instID := convertSymbolToOKX(symbol)
+	if okxFuturesInstID.MatchString(instID) {
+		return instID[:strings.LastIndexByte(instID, '-')]
+	}
+	return strings.TrimSuffix(instID, "-SWAP")
+}
+
+// convertIntervalToOKX 转换K线周期（小时及以上周期 OKX 使用大写单位：1h -> 1H）
+func convertIntervalToOKX(interval string) string {
+	if strings.HasSuffix(interval, "m") {
+		return interval
+	}
+	return strings.ToUpper(interval)
+}
+
+// convertOKXCandle 转换 OKX K线 [ts, o, h, l, c, vol, volCcy, volCcyQuote, confirm]
+func convertOKXCandle(row []string, intervalMs int64) (Kline, error) {
+	if len(row) < 6 {
+		return Kline{}, fmt.Errorf("invalid candle: %v", row)
+	}
+
+	values := make([]float64, 5)
+	for i := 1; i <= 5; i++ {
+		v, err := strconv.ParseFloat(row[i], 64)
+		if err != nil {
+			return Kline{}, fmt.Errorf("parse candle field %d failed: %w", i, err)
+		}
+		values[i-1] = v
+	}
+
+	openTime, err := strconv.ParseInt(row[0], 10, 64)
+	if err != nil {
+		return Kline{}, fmt.Errorf("parse candle time failed: %w", err)
+	}
+
+	kline := Kline{
+		OpenTime:  openTime,
+		Open:      values[0],
+		High:      values[1],
+		Low:       values[2],
+		Close:     values[3],
+		Volume:    values[4],
+		CloseTime: openTime + intervalMs - 1,
+	}
+	if len(row) > 7 {
+		kline.QuoteVolume, _ = strconv.ParseFloat(row[7], 64)
+	}
+	return kline, nil
+}
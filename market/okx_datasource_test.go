@@ -0,0 +1,91 @@
+package market
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"nofx/httprecord"
+	"strings"
+	"testing"
+)
+
+// newReplayOKXDataSource 使用 testdata 中录制的 OKX 响应创建数据源（不访问网络）
+func newReplayOKXDataSource(t *testing.T) *OKXDataSource {
+	t.Helper()
+	replayer, err := httprecord.NewReplayer("testdata/okx_replay")
+	if err != nil {
+		t.Fatalf("加载录制文件失败: %v", err)
+	}
+	source := NewOKXDataSource()
+	source.client = &http.Client{Transport: replayer}
+	return source
+}
+
+func TestOKXReplayKlines(t *testing.T) {
+	klines, err := newReplayOKXDataSource(t).GetKlines(context.Background(), "ETHUSDT", "1m", 3)
+	if err != nil {
+		t.Fatalf("GetKlines failed: %v", err)
+	}
+	if len(klines) != 3 {
+		t.Fatalf("Expected 3 klines, got %d", len(klines))
+	}
+	if klines[0].OpenTime != 1700000000000 || klines[2].Close != 2013.2 {
+		t.Errorf("Expected ascending klines, got %+v", klines)
+	}
+}
+
+func TestOKXReplayTickerAndFunding(t *testing.T) {
+	source := newReplayOKXDataSource(t)
+
+	ticker, err := source.GetTicker(context.Background(), "ETHUSDT")
+	if err != nil || ticker.LastPrice != 2013.2 {
+		t.Errorf("Unexpected ticker: %+v, err=%v", ticker, err)
+	}
+	rate, err := source.GetFundingRate(context.Background(), "ETHUSDT")
+	if err != nil || rate.Rate != 0.000085 || rate.FundingTime != 1700006400000 {
+		t.Errorf("Unexpected funding rate: %+v, err=%v", rate, err)
+	}
+
+	// 未录制的请求直接报错，不会访问网络
+	if _, err := source.GetTicker(context.Background(), "BTCUSDT"); err == nil {
+		t.Error("Expected error for unrecorded request")
+	}
+}
+
+// headerRoundTripper 记录请求头并返回固定响应
+type headerRoundTripper struct {
+	header http.Header
+	body   string
+}
+
+func (h *headerRoundTripper) RoundTrip(r *http.Request) (*http.Response, error) {
+	h.header = r.Header.Clone()
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(h.body)), Header: http.Header{}, Request: r}, nil
+}
+
+func TestOKXDemoDataSource(t *testing.T) {
+	rt := &headerRoundTripper{body: `{"code":"0","msg":"","data":[{"instId":"BTC-USDT-SWAP","last":"50000","vol24h":"1"}]}`}
+	source, err := NewDataSourceByName("okx-demo")
+	if err != nil {
+		t.Fatalf("NewDataSourceByName(okx-demo) error = %v", err)
+	}
+	demo := source.(*OKXDataSource)
+	demo.client = &http.Client{Transport: rt}
+
+	if _, err := demo.GetTicker(context.Background(), "BTCUSDT"); err != nil {
+		t.Fatalf("GetTicker() error = %v", err)
+	}
+	if rt.header.Get("x-simulated-trading") != "1" {
+		t.Errorf("模拟盘请求应带 x-simulated-trading: 1, header = %v", rt.header)
+	}
+	if demo.pubURL != demoOKXPublicURL || demo.wsURL != demoOKXStreamURL {
+		t.Errorf("模拟盘 WebSocket 地址 = %s / %s", demo.pubURL, demo.wsURL)
+	}
+
+	live := NewOKXDataSource()
+	live.client = &http.Client{Transport: rt}
+	live.GetTicker(context.Background(), "BTCUSDT")
+	if rt.header.Get("x-simulated-trading") != "" {
+		t.Error("实盘请求不应带 x-simulated-trading")
+	}
+}
@@ -0,0 +1,221 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OKX 合约类型
+const (
+	OKXInstTypeSwap    = "SWAP"    // 永续合约
+	OKXInstTypeFutures = "FUTURES" // 交割合约
+)
+
+// okxFuturesInstID 交割合约 instId（BTC-USD-240927）
+var okxFuturesInstID = regexp.MustCompile(`^[A-Z0-9]+-[A-Z]+-\d{6}$`)
+
+// okxInstruments 按币种配置的 OKX 合约（未配置的币种使用 USDT 本位永续：BTCUSDT -> BTC-USDT-SWAP）
+var okxInstruments = struct {
+	sync.RWMutex
+	bySymbol map[string]string // BTCUSDT -> BTC-USD-SWAP
+	byInstID map[string]string // BTC-USD-SWAP -> BTCUSDT
+}{bySymbol: map[string]string{}, byInstID: map[string]string{}}
+
+// okxInstType 根据 instId 判断合约类型
+func okxInstType(instID string) (string, error) {
+	switch {
+	case strings.HasSuffix(instID, "-SWAP"):
+		return OKXInstTypeSwap, nil
+	case okxFuturesInstID.MatchString(instID):
+		return OKXInstTypeFutures, nil
+	default:
+		return "", fmt.Errorf("不支持的 OKX 合约: %q（应为 BTC-USDT-SWAP、BTC-USD-SWAP 或 BTC-USD-240927 形式）", instID)
+	}
+}
+
+// SetOKXInstrument 指定币种使用的 OKX 合约（币本位永续 BTC-USD-SWAP、交割合约 BTC-USD-240927 等），instID 为空恢复默认
+func SetOKXInstrument(symbol, instID string) error {
+	symbol = Normalize(symbol)
+	instID = strings.ToUpper(strings.TrimSpace(instID))
+	if instID != "" {
+		if _, err := okxInstType(instID); err != nil {
+			return err
+		}
+	}
+
+	okxInstruments.Lock()
+	defer okxInstruments.Unlock()
+	if old, ok := okxInstruments.bySymbol[symbol]; ok {
+		delete(okxInstruments.byInstID, old)
+	}
+	if instID == "" {
+		delete(okxInstruments.bySymbol, symbol)
+		return nil
+	}
+	okxInstruments.bySymbol[symbol] = instID
+	okxInstruments.byInstID[instID] = symbol
+	return nil
+}
+
+// ConfigureOKXInstruments 解析 "BTCUSDT=BTC-USD-SWAP,ETHUSDT=ETH-USD-240927" 并设置各币种使用的合约
+func ConfigureOKXInstruments(spec string) error {
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		symbol, instID, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(symbol) == "" {
+			return fmt.Errorf("无效的 OKX 合约配置: %q（应为 币种=instId）", entry)
+		}
+		if err := SetOKXInstrument(strings.TrimSpace(symbol), instID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ConfigureOKXInstrumentsFromEnv 读取 NOFX_OKX_INSTRUMENTS
+func ConfigureOKXInstrumentsFromEnv() error {
+	spec := os.Getenv("NOFX_OKX_INSTRUMENTS")
+	if strings.TrimSpace(spec) == "" {
+		return nil
+	}
+	if err := ConfigureOKXInstruments(spec); err != nil {
+		return fmt.Errorf("NOFX_OKX_INSTRUMENTS 无效: %w", err)
+	}
+	log.Info("📑 OKX 合约配置", "spec", spec)
+	return nil
+}
+
+// OKXSymbolFromInstID 由 instId 还原系统内的币种符号（BTC-USDT-SWAP -> BTCUSDT，已配置的合约按配置还原）
+func OKXSymbolFromInstID(instID string) string {
+	okxInstruments.RLock()
+	symbol, ok := okxInstruments.byInstID[instID]
+	okxInstruments.RUnlock()
+	if ok {
+		return symbol
+	}
+	return strings.ReplaceAll(strings.TrimSuffix(instID, "-SWAP"), "-", "")
+}
+
+// OKXInstrument 合约规格（/api/v5/public/instruments）
+type OKXInstrument struct {
+	InstID   string
+	InstType string    // SWAP / FUTURES
+	CtType   string    // linear（U本位）/ inverse（币本位）
+	CtVal    float64   // 合约面值
+	CtValCcy string    // 面值币种：U本位为标的币（如 BTC），币本位为 USD
+	LotSz    float64   // 下单数量精度（张）
+	MinSz    float64   // 最小下单数量（张）
+	TickSz   float64   // 价格精度
+	Expiry   time.Time // 交割时间（永续为零值）
+}
+
+// Inverse 是否为币本位合约（面值以美元计价）
+func (i *OKXInstrument) Inverse() bool {
+	return i.CtType == "inverse"
+}
+
+// Contracts 把标的币数量换算为张数并按 LotSz 向下取整
+// U本位：张数 = 数量 / 面值；币本位：张数 = 数量 × 价格 / 面值（面值为美元）
+func (i *OKXInstrument) Contracts(quantity, price float64) (float64, error) {
+	if i.CtVal <= 0 {
+		return 0, fmt.Errorf("%s 合约面值无效: %v", i.InstID, i.CtVal)
+	}
+	contracts := quantity / i.CtVal
+	if i.Inverse() {
+		if price <= 0 {
+			return 0, fmt.Errorf("%s 为币本位合约，换算张数需要价格", i.InstID)
+		}
+		contracts = quantity * price / i.CtVal
+	}
+	if i.LotSz > 0 {
+		// 加一个极小值避免 0.3/0.1 = 2.9999999 向下取整为 2
+		contracts = math.Floor(contracts/i.LotSz+1e-9) * i.LotSz
+	}
+	if contracts <= 0 || contracts < i.MinSz {
+		return 0, fmt.Errorf("%s 下单数量 %v 不足最小 %v 张（面值 %v %s）", i.InstID, quantity, i.MinSz, i.CtVal, i.CtValCcy)
+	}
+	return contracts, nil
+}
+
+// FormatQuantity 把标的币数量格式化为下单张数（小数位与 LotSz 一致）
+func (i *OKXInstrument) FormatQuantity(quantity, price float64) (string, error) {
+	contracts, err := i.Contracts(quantity, price)
+	if err != nil {
+		return "", err
+	}
+	return strconv.FormatFloat(contracts, 'f', decimalPlaces(i.LotSz), 64), nil
+}
+
+// decimalPlaces 步长的小数位数（0.01 -> 2，1 -> 0）
+func decimalPlaces(step float64) int {
+	if step <= 0 {
+		return 8
+	}
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if dot := strings.IndexByte(s, '.'); dot >= 0 {
+		return len(s) - dot - 1
+	}
+	return 0
+}
+
+// GetInstrument 获取币种对应合约的规格（合约规格极少变化，缓存在数据源内）
+func (o *OKXDataSource) GetInstrument(ctx context.Context, symbol string) (*OKXInstrument, error) {
+	instID := convertSymbolToOKX(symbol)
+	o.instMu.RLock()
+	inst, ok := o.instruments[instID]
+	o.instMu.RUnlock()
+	if ok {
+		return inst, nil
+	}
+
+	instType, err := okxInstType(instID)
+	if err != nil {
+		return nil, err
+	}
+	params := url.Values{}
+	params.Set("instType", instType)
+	params.Set("instId", instID)
+
+	var rows []struct {
+		InstID   string `json:"instId"`
+		InstType string `json:"instType"`
+		CtType   string `json:"ctType"`
+		CtVal    string `json:"ctVal"`
+		CtValCcy string `json:"ctValCcy"`
+		LotSz    string `json:"lotSz"`
+		MinSz    string `json:"minSz"`
+		TickSz   string `json:"tickSz"`
+		ExpTime  string `json:"expTime"`
+	}
+	if err := o.get(ctx, "/api/v5/public/instruments", params, &rows); err != nil {
+		return nil, fmt.Errorf("okx GetInstrument failed: %w", err)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("okx GetInstrument: no data for %s", instID)
+	}
+	r := rows[0]
+	inst = &OKXInstrument{InstID: r.InstID, InstType: r.InstType, CtType: r.CtType, CtValCcy: r.CtValCcy}
+	inst.CtVal, _ = strconv.ParseFloat(r.CtVal, 64)
+	inst.LotSz, _ = strconv.ParseFloat(r.LotSz, 64)
+	inst.MinSz, _ = strconv.ParseFloat(r.MinSz, 64)
+	inst.TickSz, _ = strconv.ParseFloat(r.TickSz, 64)
+	if ms, err := strconv.ParseInt(r.ExpTime, 10, 64); err == nil && ms > 0 {
+		inst.Expiry = time.UnixMilli(ms)
+	}
+
+	o.instMu.Lock()
+	o.instruments[instID] = inst
+	o.instMu.Unlock()
+	return inst, nil
+}
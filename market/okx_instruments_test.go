@@ -0,0 +1,102 @@
+package market
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+)
+
+func TestConfigureOKXInstruments(t *testing.T) {
+	t.Cleanup(func() {
+		SetOKXInstrument("BTCUSDT", "")
+		SetOKXInstrument("ETHUSDT", "")
+	})
+	if err := ConfigureOKXInstruments("BTCUSDT=btc-usd-swap, ETHUSDT=ETH-USD-240927"); err != nil {
+		t.Fatalf("ConfigureOKXInstruments() error = %v", err)
+	}
+
+	tests := []struct {
+		symbol, instID, index, instType string
+	}{
+		{"BTCUSDT", "BTC-USD-SWAP", "BTC-USD", OKXInstTypeSwap},
+		{"ETHUSDT", "ETH-USD-240927", "ETH-USD", OKXInstTypeFutures},
+		{"SOLUSDT", "SOL-USDT-SWAP", "SOL-USDT", OKXInstTypeSwap},
+	}
+	for _, tt := range tests {
+		if got := convertSymbolToOKX(tt.symbol); got != tt.instID {
+			t.Errorf("convertSymbolToOKX(%s) = %s, want %s", tt.symbol, got, tt.instID)
+		}
+		if got := convertSymbolToOKXIndex(tt.symbol); got != tt.index {
+			t.Errorf("convertSymbolToOKXIndex(%s) = %s, want %s", tt.symbol, got, tt.index)
+		}
+		if got, _ := okxInstType(tt.instID); got != tt.instType {
+			t.Errorf("okxInstType(%s) = %s, want %s", tt.instID, got, tt.instType)
+		}
+		if got := OKXSymbolFromInstID(tt.instID); got != tt.symbol {
+			t.Errorf("OKXSymbolFromInstID(%s) = %s, want %s", tt.instID, got, tt.symbol)
+		}
+	}
+
+	for _, bad := range []string{"BTCUSDT", "BTCUSDT=BTC-USDT", "=BTC-USD-SWAP", "BTCUSDT=BTC-USD-2409"} {
+		if err := ConfigureOKXInstruments(bad); err == nil {
+			t.Errorf("ConfigureOKXInstruments(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestOKXInstrumentFormatQuantity(t *testing.T) {
+	linear := &OKXInstrument{InstID: "BTC-USDT-SWAP", CtType: "linear", CtVal: 0.01, CtValCcy: "BTC", LotSz: 0.1, MinSz: 0.1}
+	if got, err := linear.FormatQuantity(0.0567, 0); err != nil || got != "5.6" {
+		t.Errorf("U本位 FormatQuantity(0.0567) = %q, %v; want 5.6 张", got, err)
+	}
+	if got, err := linear.FormatQuantity(0.003, 0); err != nil || got != "0.3" {
+		t.Errorf("U本位 FormatQuantity(0.003) = %q, %v; want 0.3 张（浮点误差不应向下取整为 0.2）", got, err)
+	}
+	if _, err := linear.FormatQuantity(0.0005, 0); err == nil {
+		t.Error("不足最小张数应返回错误")
+	}
+
+	// 币本位：面值 100 USD，0.05 BTC × 60000 = 3000 USD = 30 张
+	inverse := &OKXInstrument{InstID: "BTC-USD-240927", CtType: "inverse", CtVal: 100, CtValCcy: "USD", LotSz: 1, MinSz: 1}
+	if got, err := inverse.FormatQuantity(0.05, 60000); err != nil || got != "30" {
+		t.Errorf("币本位 FormatQuantity() = %q, %v; want 30", got, err)
+	}
+	if _, err := inverse.FormatQuantity(0.05, 0); err == nil {
+		t.Error("币本位合约缺少价格时应返回错误")
+	}
+}
+
+func TestOKXGetInstrument(t *testing.T) {
+	t.Cleanup(func() { SetOKXInstrument("BTCUSDT", "") })
+	if err := SetOKXInstrument("BTCUSDT", "BTC-USD-240927"); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		q := r.URL.Query()
+		if r.URL.Path != "/api/v5/public/instruments" || q.Get("instType") != "FUTURES" || q.Get("instId") != "BTC-USD-240927" {
+			t.Errorf("unexpected request: %s", r.URL)
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"instId":"BTC-USD-240927","instType":"FUTURES","ctType":"inverse","ctVal":"100","ctValCcy":"USD","lotSz":"1","minSz":"1","tickSz":"0.1","expTime":"1727424000000"}]}`))
+	}))
+	defer server.Close()
+
+	source := NewOKXDataSource()
+	source.baseURL = server.URL
+	for i := 0; i < 2; i++ {
+		inst, err := source.GetInstrument(context.Background(), "BTCUSDT")
+		if err != nil {
+			t.Fatalf("GetInstrument() error = %v", err)
+		}
+		if !inst.Inverse() || inst.CtVal != 100 || inst.Expiry.UnixMilli() != 1727424000000 {
+			t.Errorf("GetInstrument() = %+v", inst)
+		}
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("合约规格应缓存, calls = %d", n)
+	}
+}
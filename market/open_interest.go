@@ -0,0 +1,96 @@
+package market
+
+import (
+	"context"
+	"fmt"
+)
+
+// DefaultOpenInterestSource GetOpenInterest / GetLongShortRatio 默认使用的数据源（注册名）
+var DefaultOpenInterestSource = "binance"
+
+// OpenInterest 当前持仓量
+type OpenInterest struct {
+	Symbol    string  `json:"symbol"`
+	Value     float64 `json:"value"`     // 持仓量（币数）
+	ValueUSD  float64 `json:"value_usd"` // 持仓价值（USD，数据源不提供时为 0）
+	Timestamp int64   `json:"timestamp"` // 数据时间（毫秒）
+}
+
+// LongShortRatio 多空账户人数比
+type LongShortRatio struct {
+	Symbol    string  `json:"symbol"`
+	Ratio     float64 `json:"ratio"`     // 多头账户数 / 空头账户数（>1 表示多头占优）
+	Timestamp int64   `json:"timestamp"` // 统计时间（毫秒）
+}
+
+// LongPct 多头账户占比（百分比）
+func (r LongShortRatio) LongPct() float64 {
+	if r.Ratio <= 0 {
+		return 0
+	}
+	return r.Ratio / (1 + r.Ratio) * 100
+}
+
+// OpenInterestProvider 支持持仓量查询的数据源
+type OpenInterestProvider interface {
+	// GetOpenInterest 获取当前持仓量
+	GetOpenInterest(ctx context.Context, symbol string) (*OpenInterest, error)
+	// GetOpenInterestHistory 获取最近 limit 个 period 周期的持仓量（按时间正序）
+	GetOpenInterestHistory(ctx context.Context, symbol, period string, limit int) ([]OISnapshot, error)
+}
+
+// LongShortRatioProvider 支持多空账户人数比查询的数据源
+type LongShortRatioProvider interface {
+	// GetLongShortRatio 获取最近 limit 个 period 周期的多空账户人数比（按时间正序）
+	GetLongShortRatio(ctx context.Context, symbol, period string, limit int) ([]LongShortRatio, error)
+}
+
+// GetOpenInterest 使用默认数据源获取当前持仓量
+func GetOpenInterest(symbol string) (*OpenInterest, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	provider, err := defaultOpenInterestProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetOpenInterest(ctx, symbol)
+}
+
+// GetOpenInterestHistory 使用默认数据源获取历史持仓量序列
+func GetOpenInterestHistory(symbol, period string, limit int) ([]OISnapshot, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	provider, err := defaultOpenInterestProvider()
+	if err != nil {
+		return nil, err
+	}
+	return provider.GetOpenInterestHistory(ctx, symbol, period, limit)
+}
+
+// GetLongShortRatio 使用默认数据源获取多空账户人数比序列
+func GetLongShortRatio(symbol, period string, limit int) ([]LongShortRatio, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	source, err := NewDataSourceByName(DefaultOpenInterestSource)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(LongShortRatioProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持多空比查询", source.GetName())
+	}
+	return provider.GetLongShortRatio(ctx, symbol, period, limit)
+}
+
+// defaultOpenInterestProvider 创建默认持仓量数据源
+func defaultOpenInterestProvider() (OpenInterestProvider, error) {
+	source, err := NewDataSourceByName(DefaultOpenInterestSource)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(OpenInterestProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持持仓量查询", source.GetName())
+	}
+	return provider, nil
+}
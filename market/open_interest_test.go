@@ -0,0 +1,99 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestBinanceOpenInterest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/openInterest":
+			fmt.Fprint(w, `{"openInterest":"10659.509","symbol":"BTCUSDT","time":1700000000000}`)
+		case "/futures/data/globalLongShortAccountRatio":
+			if r.URL.Query().Get("period") != "1h" || r.URL.Query().Get("limit") != "2" {
+				t.Errorf("unexpected request: %s", r.URL.String())
+			}
+			fmt.Fprint(w, `[{"symbol":"BTCUSDT","longShortRatio":"1.5","longAccount":"0.6","shortAccount":"0.4","timestamp":1700000000000},
+				{"symbol":"BTCUSDT","longShortRatio":"0.8","longAccount":"0.4444","shortAccount":"0.5556","timestamp":1700003600000}]`)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	source := NewBinanceDataSource()
+	oi, err := source.GetOpenInterest(context.Background(), "BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetOpenInterest failed: %v", err)
+	}
+	if oi.Value != 10659.509 || oi.Timestamp != 1700000000000 {
+		t.Errorf("Unexpected open interest: %+v", oi)
+	}
+
+	ratios, err := source.GetLongShortRatio(context.Background(), "BTCUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("GetLongShortRatio failed: %v", err)
+	}
+	if len(ratios) != 2 || ratios[0].Ratio != 1.5 || ratios[1].Timestamp != 1700003600000 {
+		t.Errorf("Unexpected ratios: %+v", ratios)
+	}
+	if math.Abs(ratios[0].LongPct()-60) > 1e-9 {
+		t.Errorf("Expected 60%% long, got %v", ratios[0].LongPct())
+	}
+}
+
+func TestOKXOpenInterest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("instId") != "ETH-USDT-SWAP" {
+			t.Errorf("unexpected instId: %s", r.URL.String())
+		}
+		switch r.URL.Path {
+		case "/api/v5/public/open-interest":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[{"instType":"SWAP","instId":"ETH-USDT-SWAP","oi":"2500000","oiCcy":"250000","oiUsd":"500000000","ts":"1700000000000"}]}`)
+		case "/api/v5/rubik/stat/contracts/open-interest-history":
+			if r.URL.Query().Get("period") != "1H" {
+				t.Errorf("unexpected period: %s", r.URL.String())
+			}
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[["1700003600000","2600000","260000","520000000"],["1700000000000","2500000","250000","500000000"]]}`)
+		case "/api/v5/rubik/stat/contracts/long-short-account-ratio-contract":
+			fmt.Fprint(w, `{"code":"0","msg":"","data":[["1700000300000","2.1"],["1700000000000","1.9"]]}`)
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	source := NewOKXDataSource()
+	source.baseURL = server.URL
+
+	oi, err := source.GetOpenInterest(context.Background(), "ETHUSDT")
+	if err != nil {
+		t.Fatalf("GetOpenInterest failed: %v", err)
+	}
+	if oi.Value != 250000 || oi.ValueUSD != 500000000 || oi.Timestamp != 1700000000000 {
+		t.Errorf("Unexpected open interest: %+v", oi)
+	}
+
+	history, err := source.GetOpenInterestHistory(context.Background(), "ETHUSDT", "1h", 2)
+	if err != nil {
+		t.Fatalf("GetOpenInterestHistory failed: %v", err)
+	}
+	if len(history) != 2 || history[0].Value != 250000 || history[1].Timestamp.UnixMilli() != 1700003600000 {
+		t.Errorf("Expected ascending history, got %+v", history)
+	}
+
+	ratios, err := source.GetLongShortRatio(context.Background(), "ETHUSDT", "5m", 2)
+	if err != nil {
+		t.Fatalf("GetLongShortRatio failed: %v", err)
+	}
+	if len(ratios) != 2 || ratios[0].Ratio != 1.9 || ratios[1].Ratio != 2.1 {
+		t.Errorf("Expected ascending ratios, got %+v", ratios)
+	}
+}
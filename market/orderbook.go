@@ -0,0 +1,169 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// maxStreamOrderBookDepth 盘口推送支持的最大档数（Binance 部分深度流上限）
+const maxStreamOrderBookDepth = 20
+
+// DefaultOrderBookSource GetOrderBook / StreamOrderBook 默认使用的数据源（注册名）
+var DefaultOrderBookSource = "binance"
+
+// OrderBookLevel 盘口单档
+type OrderBookLevel struct {
+	Price    float64 `json:"price"`
+	Quantity float64 `json:"quantity"`
+}
+
+// OrderBook 盘口快照（Bids 价格从高到低，Asks 价格从低到高）
+type OrderBook struct {
+	Symbol    string           `json:"symbol"`
+	Bids      []OrderBookLevel `json:"bids"`
+	Asks      []OrderBookLevel `json:"asks"`
+	Timestamp int64            `json:"timestamp,omitempty"` // 毫秒
+}
+
+// BestBid 买一价（无买盘时返回 0）
+func (ob *OrderBook) BestBid() float64 {
+	if len(ob.Bids) == 0 {
+		return 0
+	}
+	return ob.Bids[0].Price
+}
+
+// BestAsk 卖一价（无卖盘时返回 0）
+func (ob *OrderBook) BestAsk() float64 {
+	if len(ob.Asks) == 0 {
+		return 0
+	}
+	return ob.Asks[0].Price
+}
+
+// MidPrice 买一卖一中间价（任一侧为空时返回 0）
+func (ob *OrderBook) MidPrice() float64 {
+	bid, ask := ob.BestBid(), ob.BestAsk()
+	if bid <= 0 || ask <= 0 {
+		return 0
+	}
+	return (bid + ask) / 2
+}
+
+// SpreadPct 买卖价差占中间价的百分比（任一侧为空时返回 0）
+func (ob *OrderBook) SpreadPct() float64 {
+	mid := ob.MidPrice()
+	if mid <= 0 {
+		return 0
+	}
+	return (ob.BestAsk() - ob.BestBid()) / mid * 100
+}
+
+// LiquidityWithin 距离中间价 maxDeviationPct 百分比以内的可成交名义价值（USDT）
+// buy=true 统计卖盘（市价买入吃卖单），否则统计买盘
+func (ob *OrderBook) LiquidityWithin(buy bool, maxDeviationPct float64) float64 {
+	mid := ob.MidPrice()
+	if mid <= 0 {
+		return 0
+	}
+
+	levels, limit := ob.Bids, mid*(1-maxDeviationPct/100)
+	if buy {
+		levels, limit = ob.Asks, mid*(1+maxDeviationPct/100)
+	}
+
+	notional := 0.0
+	for _, level := range levels {
+		if (buy && level.Price > limit) || (!buy && level.Price < limit) {
+			break
+		}
+		notional += level.Price * level.Quantity
+	}
+	return notional
+}
+
+// truncate 只保留前 depth 档
+func (ob *OrderBook) truncate(depth int) *OrderBook {
+	if depth > 0 {
+		if len(ob.Bids) > depth {
+			ob.Bids = ob.Bids[:depth]
+		}
+		if len(ob.Asks) > depth {
+			ob.Asks = ob.Asks[:depth]
+		}
+	}
+	return ob
+}
+
+// OrderBookProvider 支持盘口深度查询的数据源
+type OrderBookProvider interface {
+	// GetOrderBook 获取前 depth 档盘口
+	GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error)
+}
+
+// OrderBookStreamer 支持 WebSocket 盘口推送的数据源
+type OrderBookStreamer interface {
+	// StreamOrderBook 订阅前 depth 档盘口快照推送，ctx 取消后关闭返回的通道
+	// 消费跟不上时丢弃最旧的快照，保证读到的总是最新盘口
+	StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error)
+}
+
+// GetOrderBook 使用默认数据源获取盘口深度（用于市价单前检查价差和可成交深度）
+func GetOrderBook(symbol string, depth int) (*OrderBook, error) {
+	ctx, cancel := requestContext()
+	defer cancel()
+	source, err := NewDataSourceByName(DefaultOrderBookSource)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(OrderBookProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持盘口查询", source.GetName())
+	}
+	return provider.GetOrderBook(ctx, symbol, depth)
+}
+
+// StreamOrderBook 使用默认数据源订阅盘口推送（断线自动重连）
+func StreamOrderBook(ctx context.Context, symbol string, depth int) (<-chan OrderBook, error) {
+	source, err := NewDataSourceByName(DefaultOrderBookSource)
+	if err != nil {
+		return nil, err
+	}
+	streamer, ok := source.(OrderBookStreamer)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持盘口推送", source.GetName())
+	}
+	return streamer.StreamOrderBook(ctx, symbol, depth)
+}
+
+// startOrderBookStream 校验档数并启动盘口推送 goroutine
+func startOrderBookStream(ctx context.Context, name, symbol string, depth, maxDepth int, protocol streamProtocol[OrderBook]) (<-chan OrderBook, error) {
+	if depth <= 0 || depth > maxDepth {
+		return nil, fmt.Errorf("%s 盘口推送档数必须在 1-%d 之间: %d", name, maxDepth, depth)
+	}
+
+	s := newLatestStream(name, "盘口", fmt.Sprintf("%s %d档", symbol, depth), protocol)
+	go s.run(ctx)
+	return s.out, nil
+}
+
+// parseOrderBookLevels 解析 [["price","qty",...], ...] 格式的盘口档位
+func parseOrderBookLevels(rows [][]string) ([]OrderBookLevel, error) {
+	levels := make([]OrderBookLevel, 0, len(rows))
+	for _, row := range rows {
+		if len(row) < 2 {
+			return nil, fmt.Errorf("invalid order book level: %v", row)
+		}
+		price, err := strconv.ParseFloat(row[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse price failed: %w", err)
+		}
+		qty, err := strconv.ParseFloat(row[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("parse quantity failed: %w", err)
+		}
+		levels = append(levels, OrderBookLevel{Price: price, Quantity: qty})
+	}
+	return levels, nil
+}
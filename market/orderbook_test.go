@@ -0,0 +1,143 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func testOrderBook() *OrderBook {
+	return &OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []OrderBookLevel{{Price: 99.9, Quantity: 2}, {Price: 99.5, Quantity: 5}, {Price: 98, Quantity: 100}},
+		Asks:   []OrderBookLevel{{Price: 100.1, Quantity: 1}, {Price: 100.4, Quantity: 3}, {Price: 102, Quantity: 100}},
+	}
+}
+
+func TestOrderBookMetrics(t *testing.T) {
+	book := testOrderBook()
+
+	if book.BestBid() != 99.9 || book.BestAsk() != 100.1 || book.MidPrice() != 100 {
+		t.Errorf("Unexpected best prices: bid=%v ask=%v mid=%v", book.BestBid(), book.BestAsk(), book.MidPrice())
+	}
+	if math.Abs(book.SpreadPct()-0.2) > 1e-9 {
+		t.Errorf("Expected spread 0.2%%, got %v", book.SpreadPct())
+	}
+
+	// 0.5% 以内：卖盘 100.1*1 + 100.4*3，买盘 99.9*2 + 99.5*5
+	if got := book.LiquidityWithin(true, 0.5); math.Abs(got-401.3) > 1e-9 {
+		t.Errorf("Expected ask liquidity 401.3, got %v", got)
+	}
+	if got := book.LiquidityWithin(false, 0.5); math.Abs(got-697.3) > 1e-9 {
+		t.Errorf("Expected bid liquidity 697.3, got %v", got)
+	}
+
+	empty := &OrderBook{Bids: book.Bids}
+	if empty.SpreadPct() != 0 || empty.LiquidityWithin(false, 1) != 0 {
+		t.Error("One-sided book should report no spread or liquidity")
+	}
+
+	if book.truncate(1); len(book.Bids) != 1 || len(book.Asks) != 1 {
+		t.Errorf("Expected truncation to 1 level, got %d/%d", len(book.Bids), len(book.Asks))
+	}
+}
+
+func TestBinanceGetOrderBook(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/depth" || r.URL.Query().Get("limit") != "10" {
+			t.Errorf("unexpected request: %s", r.URL.String())
+		}
+		fmt.Fprint(w, `{"lastUpdateId":1,"E":1700000000001,"T":1700000000000,
+			"bids":[["99.9","2"],["99.5","5"],["99","1"],["98","1"],["97","1"],["96","1"],["95","1"],["94","1"]],
+			"asks":[["100.1","1"],["100.4","3"]]}`)
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	book, err := NewBinanceDataSource().GetOrderBook(context.Background(), "BTCUSDT", 6)
+	if err != nil {
+		t.Fatalf("GetOrderBook failed: %v", err)
+	}
+	if len(book.Bids) != 6 || len(book.Asks) != 2 || book.BestBid() != 99.9 || book.Timestamp != 1700000000000 {
+		t.Errorf("Unexpected order book: %+v", book)
+	}
+}
+
+func TestBinanceStreamOrderBook(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/ethusdt@depth10@100ms" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"e":"depthUpdate","E":1700000000005,"T":1700000000003,"s":"ETHUSDT","U":1,"u":2,"pu":0,
+			"b":[["2000","1"],["1999","2"],["1998","3"],["1997","4"],["1996","5"],["1995","6"],["1994","7"]],"a":[["2001","1"]]}`))
+		time.Sleep(time.Second)
+	}))
+	defer server.Close()
+
+	source := NewBinanceDataSource()
+	source.wsURL = "ws" + strings.TrimPrefix(server.URL, "http")
+
+	if _, err := source.StreamOrderBook(context.Background(), "ETHUSDT", 50); err == nil {
+		t.Error("Expected error for depth above stream limit")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := source.StreamOrderBook(ctx, "ethusdt", 6)
+	if err != nil {
+		t.Fatalf("StreamOrderBook failed: %v", err)
+	}
+
+	select {
+	case book := <-ch:
+		if book.Symbol != "ETHUSDT" || len(book.Bids) != 6 || book.BestAsk() != 2001 || book.Timestamp != 1700000000003 {
+			t.Errorf("Unexpected order book: %+v", book)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for order book")
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestOKXOrderBookProtocol(t *testing.T) {
+	if _, err := NewOKXDataSource().StreamOrderBook(context.Background(), "BTCUSDT", 10); err == nil {
+		t.Error("Expected error for depth above books5 limit")
+	}
+
+	p := &okxOrderBookProtocol{symbol: "BTCUSDT", depth: 2}
+	books, err := p.parse([]byte(`{"arg":{"channel":"books5","instId":"BTC-USDT-SWAP"},"data":[{"asks":[["100.1","1","0","2"],["100.2","1","0","1"],["100.3","1","0","1"]],"bids":[["99.9","3","0","4"]],"ts":"1700000000000"}]}`))
+	if err != nil || len(books) != 1 {
+		t.Fatalf("Unexpected books: %+v, err=%v", books, err)
+	}
+	if len(books[0].Asks) != 2 || books[0].BestBid() != 99.9 || books[0].Timestamp != 1700000000000 {
+		t.Errorf("Unexpected order book: %+v", books[0])
+	}
+}
+
+func TestHyperliquidOrderBookProtocol(t *testing.T) {
+	p := &hyperliquidOrderBookProtocol{symbol: "ETHUSDT", depth: 5}
+	books, err := p.parse([]byte(`{"channel":"l2Book","data":{"coin":"ETH","time":1700000000000,"levels":[[{"px":"2000","sz":"1.5","n":3}],[{"px":"2000.5","sz":"2","n":1}]]}}`))
+	if err != nil || len(books) != 1 {
+		t.Fatalf("Unexpected books: %+v, err=%v", books, err)
+	}
+	if books[0].BestBid() != 2000 || books[0].Asks[0].Quantity != 2 || books[0].Symbol != "ETHUSDT" {
+		t.Errorf("Unexpected order book: %+v", books[0])
+	}
+}
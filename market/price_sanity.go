@@ -0,0 +1,65 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// PriceSanityResult 参考价与多个数据源价格的交叉校验结果
+type PriceSanityResult struct {
+	Symbol     string
+	Reference  float64            // 参考价（决策使用的价格）
+	Prices     map[string]float64 // 各数据源的最新价
+	Deviations map[string]float64 // 各数据源相对参考价的偏差（百分比，取绝对值）
+	Errors     map[string]error   // 取价失败的数据源
+}
+
+// MaxDeviation 偏差最大的数据源及其偏差百分比（没有可用价格时返回 "", 0）
+func (r *PriceSanityResult) MaxDeviation() (string, float64) {
+	name, max := "", 0.0
+	for source, dev := range r.Deviations {
+		if name == "" || dev > max || (dev == max && source < name) {
+			name, max = source, dev
+		}
+	}
+	return name, max
+}
+
+// CrossCheckPrice 并发从各数据源获取最新价并与参考价比较
+// 用于下单前识别单个数据源返回过期或脱锚的价格
+func CrossCheckPrice(ctx context.Context, symbol string, reference float64, sources []DataSource) *PriceSanityResult {
+	result := &PriceSanityResult{
+		Symbol:     symbol,
+		Reference:  reference,
+		Prices:     make(map[string]float64),
+		Deviations: make(map[string]float64),
+		Errors:     make(map[string]error),
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, source := range sources {
+		wg.Add(1)
+		go func(source DataSource) {
+			defer wg.Done()
+			ticker, err := source.GetTicker(ctx, symbol)
+			if err == nil && (ticker == nil || ticker.LastPrice <= 0) {
+				err = fmt.Errorf("%s 无有效价格", symbol)
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				result.Errors[source.GetName()] = err
+				return
+			}
+			result.Prices[source.GetName()] = ticker.LastPrice
+			if reference > 0 {
+				result.Deviations[source.GetName()] = abs(ticker.LastPrice-reference) / reference * 100
+			}
+		}(source)
+	}
+	wg.Wait()
+	return result
+}
@@ -0,0 +1,26 @@
+package market
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestCrossCheckPrice(t *testing.T) {
+	sources := []DataSource{
+		&MockDataSource{name: "hyperliquid", tickerData: &Ticker{Symbol: "BTCUSDT", LastPrice: 50050}},
+		&MockDataSource{name: "okx", tickerData: &Ticker{Symbol: "BTCUSDT", LastPrice: 48000}},
+		&MockDataSource{name: "bybit", failTicker: true},
+	}
+
+	result := CrossCheckPrice(context.Background(), "BTCUSDT", 50000, sources)
+	if len(result.Prices) != 2 || len(result.Errors) != 1 || result.Errors["bybit"] == nil {
+		t.Fatalf("CrossCheckPrice() = %+v", result)
+	}
+	if dev := result.Deviations["hyperliquid"]; math.Abs(dev-0.1) > 1e-9 {
+		t.Errorf("hyperliquid 偏差 = %v%%, want 0.1%%", dev)
+	}
+	if name, dev := result.MaxDeviation(); name != "okx" || math.Abs(dev-4) > 1e-9 {
+		t.Errorf("MaxDeviation() = %s, %v; want okx, 4", name, dev)
+	}
+}
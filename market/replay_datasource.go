@@ -0,0 +1,353 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ReplayInstant 即时回放：回放时钟只通过 Advance / SetTime 推进
+const ReplayInstant = 0
+
+// ReplayData 回放所用的历史数据
+type ReplayData struct {
+	Klines       map[string]map[string][]Kline // 币种 -> 周期 -> K线（按时间正序）
+	FundingRates map[string][]FundingRate      // 币种 -> 已结算资金费率（按时间正序）
+	Start        time.Time                     // 回放起点（零值=最早一根K线的开盘时间）
+}
+
+// ReplayDataSource 用录制的历史数据实现 DataSource，按回放时钟只返回"当前"之前的数据
+// 可注册为数据源（RegisterDataSource），让实盘流程（数据源 → 决策 → 模拟交易）在测试中确定性地运行：
+//   - speed=1 按真实时间回放，speed=10 为 10 倍速，speed=ReplayInstant 由调用方推进时钟
+//   - 未收盘的当前K线由更小周期已收盘的K线聚合而成，不会泄露未来的最高/最低/收盘价
+type ReplayDataSource struct {
+	name    string
+	klines  map[string]map[string][]Kline
+	funding map[string][]FundingRate
+	speed   float64
+	end     int64 // 数据中最后一根K线的收盘时间
+
+	mu        sync.Mutex
+	base      int64         // 回放时钟基准（毫秒）
+	wallStart time.Time     // 基准对应的真实时间
+	changed   chan struct{} // 时钟被 Advance / SetTime 调整时关闭并替换（唤醒 StreamKlines）
+	wallNow   func() time.Time
+}
+
+// NewReplayDataSource 创建回放数据源（speed<0 视为即时回放）
+func NewReplayDataSource(data ReplayData, speed float64) (*ReplayDataSource, error) {
+	r := &ReplayDataSource{
+		name:    "replay",
+		klines:  make(map[string]map[string][]Kline, len(data.Klines)),
+		funding: make(map[string][]FundingRate, len(data.FundingRates)),
+		speed:   speed,
+		changed: make(chan struct{}),
+		wallNow: time.Now,
+	}
+	if r.speed < 0 {
+		r.speed = ReplayInstant
+	}
+
+	start, found := int64(0), false
+	for symbol, byInterval := range data.Klines {
+		symbol = strings.ToUpper(symbol)
+		r.klines[symbol] = make(map[string][]Kline, len(byInterval))
+		for interval, klines := range byInterval {
+			if _, ok := TimeframeDuration(interval); !ok {
+				return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+			}
+			if len(klines) == 0 {
+				continue
+			}
+			r.klines[symbol][interval] = klines
+			if !found || klines[0].OpenTime < start {
+				start, found = klines[0].OpenTime, true
+			}
+			if last := klines[len(klines)-1].CloseTime; last > r.end {
+				r.end = last
+			}
+		}
+	}
+	for symbol, rates := range data.FundingRates {
+		r.funding[strings.ToUpper(symbol)] = rates
+	}
+	if !found {
+		return nil, fmt.Errorf("回放数据中没有K线")
+	}
+	if !data.Start.IsZero() {
+		start = data.Start.UnixMilli()
+	}
+	r.base = start
+	r.wallStart = r.wallNow()
+	return r, nil
+}
+
+// NewReplayDataSourceFromCache 从本地K线缓存读取 [from, to] 区间的数据创建回放数据源
+func NewReplayDataSourceFromCache(source string, symbols, intervals []string, from, to time.Time, speed float64) (*ReplayDataSource, error) {
+	data := ReplayData{Klines: make(map[string]map[string][]Kline, len(symbols)), Start: from}
+	for _, symbol := range symbols {
+		data.Klines[symbol] = make(map[string][]Kline, len(intervals))
+		for _, interval := range intervals {
+			klines, err := LoadCachedKlines(source, symbol, interval, from, to)
+			if err != nil {
+				return nil, err
+			}
+			data.Klines[symbol][interval] = klines
+		}
+	}
+	return NewReplayDataSource(data, speed)
+}
+
+// GetName 获取数据源名称
+func (r *ReplayDataSource) GetName() string {
+	return r.name
+}
+
+// Now 当前回放时间
+func (r *ReplayDataSource) Now() time.Time {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return time.UnixMilli(r.nowLocked())
+}
+
+// Advance 将回放时钟向前推进 d
+func (r *ReplayDataSource) Advance(d time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebaseLocked(r.nowLocked() + d.Milliseconds())
+}
+
+// SetTime 将回放时钟设置为 t（可以回拨，用于重复回放）
+func (r *ReplayDataSource) SetTime(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.rebaseLocked(t.UnixMilli())
+}
+
+// Finished 回放时钟是否已越过最后一根K线的收盘时间
+func (r *ReplayDataSource) Finished() bool {
+	return r.Now().UnixMilli() > r.end
+}
+
+// GetKlines 获取回放时钟之前的最近 limit 根K线（最后一根为由更小周期聚合的未收盘K线）
+func (r *ReplayDataSource) GetKlines(ctx context.Context, symbol, interval string, limit int) ([]Kline, error) {
+	now := r.Now().UnixMilli()
+	klines, err := r.visibleKlines(strings.ToUpper(symbol), interval, now)
+	if err != nil {
+		return nil, err
+	}
+	if limit > 0 && len(klines) > limit {
+		klines = klines[len(klines)-limit:]
+	}
+	return klines, nil
+}
+
+// GetKlinesRange 实现 KlineRangeProvider（只返回回放时钟之前已收盘的K线）
+func (r *ReplayDataSource) GetKlinesRange(ctx context.Context, symbol, interval string, startTime, endTime int64, limit int) ([]Kline, error) {
+	now := r.Now().UnixMilli()
+	all, ok := r.klines[strings.ToUpper(symbol)][interval]
+	if !ok {
+		return nil, fmt.Errorf("回放数据中没有 %s %s K线", symbol, interval)
+	}
+	var result []Kline
+	for _, k := range all {
+		if k.OpenTime >= startTime && k.OpenTime <= endTime && k.CloseTime < now {
+			result = append(result, k)
+		}
+	}
+	if limit > 0 && len(result) > limit {
+		result = result[len(result)-limit:]
+	}
+	return result, nil
+}
+
+// GetTicker 最新价格（最小周期上最近一根已收盘K线的收盘价）
+func (r *ReplayDataSource) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	symbol = strings.ToUpper(symbol)
+	now := r.Now().UnixMilli()
+	interval, ok := r.finestInterval(symbol)
+	if !ok {
+		return nil, fmt.Errorf("回放数据中没有 %s K线", symbol)
+	}
+	klines := r.closedKlines(symbol, interval, now)
+	if len(klines) == 0 {
+		return nil, fmt.Errorf("%s 在回放时间 %s 之前没有K线", symbol, time.UnixMilli(now).UTC().Format(time.RFC3339))
+	}
+	last := klines[len(klines)-1]
+	return &Ticker{Symbol: symbol, LastPrice: last.Close, Volume: last.Volume, Timestamp: last.CloseTime}, nil
+}
+
+// GetFundingRate 回放时钟之前最近一次结算的资金费率
+func (r *ReplayDataSource) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	history, err := r.GetFundingRateHistory(ctx, symbol, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(history) == 0 {
+		return nil, fmt.Errorf("%s 在回放时间之前没有资金费率数据", symbol)
+	}
+	return &history[0], nil
+}
+
+// GetFundingRateHistory 回放时钟之前最近 n 次已结算资金费率（按时间正序）
+func (r *ReplayDataSource) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]FundingRate, error) {
+	now := r.Now().UnixMilli()
+	rates := r.funding[strings.ToUpper(symbol)]
+	end := sort.Search(len(rates), func(i int) bool { return rates[i].FundingTime > now })
+	start := 0
+	if n > 0 && end > n {
+		start = end - n
+	}
+	return append([]FundingRate(nil), rates[start:end]...), nil
+}
+
+// HealthCheck 回放数据源始终健康
+func (r *ReplayDataSource) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// GetLatency 回放数据源没有网络延迟
+func (r *ReplayDataSource) GetLatency() time.Duration {
+	return 0
+}
+
+// StreamKlines 按回放时钟推送收盘的K线（即时回放时随 Advance / SetTime 推送新收盘的K线）
+func (r *ReplayDataSource) StreamKlines(ctx context.Context, symbol, interval string) (<-chan Kline, error) {
+	symbol = strings.ToUpper(symbol)
+	all, ok := r.klines[symbol][interval]
+	if !ok {
+		return nil, fmt.Errorf("回放数据中没有 %s %s K线", symbol, interval)
+	}
+
+	// 从订阅时尚未收盘的K线开始推送
+	now := r.Now().UnixMilli()
+	first := sort.Search(len(all), func(i int) bool { return all[i].CloseTime >= now })
+
+	out := make(chan Kline, 16)
+	go func() {
+		defer close(out)
+		for i := first; i < len(all); i++ {
+			k := all[i]
+			if !r.waitUntil(ctx, k.CloseTime+1) {
+				return
+			}
+			select {
+			case out <- k:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// waitUntil 等待回放时钟到达 t
+func (r *ReplayDataSource) waitUntil(ctx context.Context, t int64) bool {
+	for {
+		r.mu.Lock()
+		now := r.nowLocked()
+		changed := r.changed
+		r.mu.Unlock()
+		if now >= t {
+			return true
+		}
+
+		// 即时回放只等待时钟调整；按倍速回放时同时等待到达时间
+		var timer *time.Timer
+		var timeout <-chan time.Time
+		if r.speed != ReplayInstant {
+			timer = time.NewTimer(max(time.Duration(float64(t-now)/r.speed)*time.Millisecond, time.Millisecond))
+			timeout = timer.C
+		}
+		select {
+		case <-timeout:
+		case <-changed:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+	}
+}
+
+// nowLocked 当前回放时间（毫秒，调用方持有锁）
+func (r *ReplayDataSource) nowLocked() int64 {
+	if r.speed == ReplayInstant {
+		return r.base
+	}
+	elapsed := r.wallNow().Sub(r.wallStart)
+	return r.base + int64(float64(elapsed.Milliseconds())*r.speed)
+}
+
+// rebaseLocked 重设回放时钟基准（调用方持有锁）
+func (r *ReplayDataSource) rebaseLocked(t int64) {
+	r.base = t
+	r.wallStart = r.wallNow()
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+// closedKlines now 之前已收盘的K线
+func (r *ReplayDataSource) closedKlines(symbol, interval string, now int64) []Kline {
+	all := r.klines[symbol][interval]
+	end := sort.Search(len(all), func(i int) bool { return all[i].CloseTime >= now })
+	return all[:end]
+}
+
+// visibleKlines 已收盘的K线 + 当前未收盘K线（由更小周期已收盘的K线聚合）
+func (r *ReplayDataSource) visibleKlines(symbol, interval string, now int64) ([]Kline, error) {
+	if _, ok := r.klines[symbol][interval]; !ok {
+		return nil, fmt.Errorf("回放数据中没有 %s %s K线", symbol, interval)
+	}
+	closed := r.closedKlines(symbol, interval, now)
+	result := make([]Kline, len(closed), len(closed)+1)
+	copy(result, closed)
+
+	if partial, ok := r.partialKline(symbol, interval, now); ok {
+		result = append(result, partial)
+	}
+	return result, nil
+}
+
+// partialKline 用最小周期中已收盘的子K线聚合当前未收盘的K线
+func (r *ReplayDataSource) partialKline(symbol, interval string, now int64) (Kline, bool) {
+	fine, ok := r.finestInterval(symbol)
+	if !ok || fine == interval {
+		return Kline{}, false
+	}
+	duration, _ := TimeframeDuration(interval)
+	start, _ := resampleBucket(now, interval, duration)
+
+	var children []Kline
+	for _, k := range r.closedKlines(symbol, fine, now) {
+		if k.OpenTime >= start {
+			children = append(children, k)
+		}
+	}
+	if len(children) == 0 || children[0].OpenTime != start {
+		return Kline{}, false
+	}
+	aggregated, err := Resample(children, fine, interval)
+	if err != nil || len(aggregated) != 1 {
+		return Kline{}, false
+	}
+	return aggregated[0], true
+}
+
+// finestInterval 该币种数据中最小的K线周期
+func (r *ReplayDataSource) finestInterval(symbol string) (string, bool) {
+	best, bestDur := "", time.Duration(0)
+	for interval := range r.klines[symbol] {
+		d, _ := TimeframeDuration(interval)
+		if best == "" || d < bestDur {
+			best, bestDur = interval, d
+		}
+	}
+	return best, best != ""
+}
@@ -0,0 +1,126 @@
+package market
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// replayTestData 1m K线 0~19 分钟（价格 = 100 + 分钟数）及对应的 5m K线
+func replayTestData() ReplayData {
+	var oneMin, fiveMin []Kline
+	for i := 0; i < 20; i++ {
+		oneMin = append(oneMin, testKline(i, 100+float64(i)))
+	}
+	for i := 0; i < 4; i++ {
+		open := int64(i) * 5 * 60000
+		fiveMin = append(fiveMin, Kline{
+			OpenTime: open, Open: 100 + float64(i*5), High: 104 + float64(i*5), Low: 100 + float64(i*5),
+			Close: 104 + float64(i*5), Volume: 5, CloseTime: open + 5*60000 - 1,
+		})
+	}
+	return ReplayData{
+		Klines: map[string]map[string][]Kline{"btcusdt": {"1m": oneMin, "5m": fiveMin}},
+		FundingRates: map[string][]FundingRate{"BTCUSDT": {
+			{Symbol: "BTCUSDT", Rate: 0.0001, FundingTime: 0},
+			{Symbol: "BTCUSDT", Rate: 0.0003, FundingTime: 10 * 60000},
+		}},
+	}
+}
+
+func TestReplayDataSourceInstant(t *testing.T) {
+	r, err := NewReplayDataSource(replayTestData(), ReplayInstant)
+	if err != nil {
+		t.Fatalf("NewReplayDataSource 失败: %v", err)
+	}
+	var _ DataSource = r
+
+	// 07:00 时：5m 只有 [0,5) 已收盘，[5,10) 由 1m 的 5、6 两根聚合
+	r.Advance(7 * time.Minute)
+	klines, err := r.GetKlines(context.Background(), "BTCUSDT", "5m", 10)
+	if err != nil {
+		t.Fatalf("GetKlines 失败: %v", err)
+	}
+	if len(klines) != 2 {
+		t.Fatalf("期望 2 根5m K线，得到 %d: %+v", len(klines), klines)
+	}
+	partial := klines[1]
+	if partial.OpenTime != 5*60000 || partial.Open != 105 || partial.Close != 106 || partial.High != 106 {
+		t.Errorf("未收盘K线应只包含已收盘的子K线: %+v", partial)
+	}
+
+	ticker, err := r.GetTicker(context.Background(), "BTCUSDT")
+	if err != nil || ticker.LastPrice != 106 {
+		t.Errorf("unexpected ticker: %+v, err=%v", ticker, err)
+	}
+	if rate, err := r.GetFundingRate(context.Background(), "BTCUSDT"); err != nil || rate.Rate != 0.0001 {
+		t.Errorf("资金费率不应包含未来数据: %+v, err=%v", rate, err)
+	}
+
+	r.SetTime(time.UnixMilli(12 * 60000))
+	if rate, _ := r.GetFundingRate(context.Background(), "BTCUSDT"); rate == nil || rate.Rate != 0.0003 {
+		t.Errorf("unexpected funding rate after SetTime: %+v", rate)
+	}
+	if r.Finished() {
+		t.Error("回放尚未结束")
+	}
+	r.Advance(time.Hour)
+	if !r.Finished() {
+		t.Error("回放应已结束")
+	}
+}
+
+func TestReplayDataSourceStreamInstant(t *testing.T) {
+	r, err := NewReplayDataSource(replayTestData(), ReplayInstant)
+	if err != nil {
+		t.Fatalf("NewReplayDataSource 失败: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ch, err := r.StreamKlines(ctx, "BTCUSDT", "5m")
+	if err != nil {
+		t.Fatalf("StreamKlines 失败: %v", err)
+	}
+	// 即时回放：每次推进 5 分钟，推送刚收盘的那根K线，GetKlines 同步可见
+	for i := 0; i < 4; i++ {
+		r.Advance(5 * time.Minute)
+		select {
+		case k := <-ch:
+			klines, _ := r.GetKlines(context.Background(), "BTCUSDT", "5m", 1)
+			if k.OpenTime != int64(i)*5*60000 || len(klines) != 1 || klines[0].OpenTime != k.OpenTime {
+				t.Errorf("第 %d 次推进: stream=%+v klines=%+v", i, k, klines)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("第 %d 次推进后未收到K线", i)
+		}
+	}
+	select {
+	case k, ok := <-ch:
+		if ok {
+			t.Errorf("数据已推送完毕，不应再收到K线: %+v", k)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("数据推送完毕后通道应关闭")
+	}
+}
+
+func TestReplayDataSourceSpeed(t *testing.T) {
+	r, err := NewReplayDataSource(replayTestData(), 60)
+	if err != nil {
+		t.Fatalf("NewReplayDataSource 失败: %v", err)
+	}
+	wall := time.Unix(1700000000, 0)
+	r.wallNow = func() time.Time { return wall }
+	r.SetTime(time.UnixMilli(0))
+
+	// 60 倍速：真实 5 秒 = 回放 5 分钟
+	wall = wall.Add(5 * time.Second)
+	if now := r.Now().UnixMilli(); now != 5*60000 {
+		t.Errorf("回放时间 = %d，期望 %d", now, 5*60000)
+	}
+	klines, _ := r.GetKlinesRange(context.Background(), "BTCUSDT", "1m", 0, 20*60000, 0)
+	if len(klines) != 5 {
+		t.Errorf("期望 5 根已收盘的1m K线，得到 %d", len(klines))
+	}
+}
@@ -0,0 +1,97 @@
+package market
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// weekOffsetMs Unix 纪元（周四）到第一个周一的偏移，周线按周一 00:00 UTC 对齐（与 Binance 一致）
+const weekOffsetMs = 4 * 24 * 60 * 60 * 1000
+
+// Resample 将 fromInterval 周期的K线聚合为更大的 toInterval 周期（如 15m → 4h）
+// 按 UTC 周期边界分桶（周线从周一开始，月线按自然月）：
+//   - 开头不完整的周期（缺少前面的子K线）会被丢弃，避免开盘价失真
+//   - 最后一个不完整的周期保留为当前未收盘K线，CloseTime 为该周期的结束时间
+//
+// 输入需按开盘时间正序排列
+func Resample(klines []Kline, fromInterval, toInterval string) ([]Kline, error) {
+	fromDur, ok := TimeframeDuration(fromInterval)
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", fromInterval)
+	}
+	toDur, ok := TimeframeDuration(toInterval)
+	if !ok {
+		return nil, fmt.Errorf("不支持的K线周期: %s", toInterval)
+	}
+	if toInterval != "1M" && (toDur <= fromDur || toDur%fromDur != 0) {
+		return nil, fmt.Errorf("无法将 %s K线聚合为 %s（目标周期必须是源周期的整数倍）", fromInterval, toInterval)
+	}
+	if toInterval == "1M" && (24*time.Hour)%fromDur != 0 {
+		return nil, fmt.Errorf("无法将 %s K线聚合为月线", fromInterval)
+	}
+	if len(klines) == 0 {
+		return nil, nil
+	}
+
+	// 开头不完整的周期：第一根子K线不在周期起点
+	skipStart := int64(math.MinInt64)
+	if start, _ := resampleBucket(klines[0].OpenTime, toInterval, toDur); start != klines[0].OpenTime {
+		skipStart = start
+	}
+
+	var result []Kline
+	var current *Kline
+	for _, k := range klines {
+		start, end := resampleBucket(k.OpenTime, toInterval, toDur)
+		if start == skipStart {
+			continue
+		}
+		if current == nil || start != current.OpenTime {
+			if current != nil {
+				result = append(result, *current)
+			}
+			current = &Kline{OpenTime: start, Open: k.Open, High: k.High, Low: k.Low, CloseTime: end - 1}
+		}
+
+		current.High = math.Max(current.High, k.High)
+		current.Low = math.Min(current.Low, k.Low)
+		current.Close = k.Close
+		current.Volume += k.Volume
+		current.QuoteVolume += k.QuoteVolume
+		current.Trades += k.Trades
+		current.TakerBuyBaseVolume += k.TakerBuyBaseVolume
+		current.TakerBuyQuoteVolume += k.TakerBuyQuoteVolume
+	}
+	if current != nil {
+		result = append(result, *current)
+	}
+	return result, nil
+}
+
+// resampleBucket 计算开盘时间所在的目标周期 [start, end)（毫秒）
+func resampleBucket(openTime int64, interval string, duration time.Duration) (int64, int64) {
+	switch interval {
+	case "1M":
+		t := time.UnixMilli(openTime).UTC()
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+		return start.UnixMilli(), start.AddDate(0, 1, 0).UnixMilli()
+	case "1w":
+		ms := duration.Milliseconds()
+		start := floorDiv(openTime-weekOffsetMs, ms)*ms + weekOffsetMs
+		return start, start + ms
+	default:
+		ms := duration.Milliseconds()
+		start := floorDiv(openTime, ms) * ms
+		return start, start + ms
+	}
+}
+
+// floorDiv 向下取整的整数除法（支持负数）
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
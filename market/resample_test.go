@@ -0,0 +1,113 @@
+package market
+
+import (
+	"testing"
+	"time"
+)
+
+// minuteKlines 从 start 开始生成 n 根间隔为 interval 的K线，价格依次递增
+func minuteKlines(start time.Time, interval time.Duration, n int) []Kline {
+	klines := make([]Kline, n)
+	for i := range klines {
+		openTime := start.Add(time.Duration(i) * interval).UnixMilli()
+		price := 100 + float64(i)
+		klines[i] = Kline{
+			OpenTime:  openTime,
+			Open:      price,
+			High:      price + 0.5,
+			Low:       price - 0.5,
+			Close:     price + 0.25,
+			Volume:    1,
+			Trades:    2,
+			CloseTime: openTime + interval.Milliseconds() - 1,
+		}
+	}
+	return klines
+}
+
+func TestResample15mTo4h(t *testing.T) {
+	// 从 03:00 开始：00:00~04:00 周期不完整应被丢弃；04:00~08:00 完整；08:00 之后为未收盘周期
+	start := time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)
+	klines := minuteKlines(start, 15*time.Minute, 4+16+3)
+
+	result, err := Resample(klines, "15m", "4h")
+	if err != nil {
+		t.Fatalf("Resample 失败: %v", err)
+	}
+	if len(result) != 2 {
+		t.Fatalf("期望 2 根4h K线，得到 %d", len(result))
+	}
+
+	full := result[0]
+	fullStart := time.Date(2024, 1, 1, 4, 0, 0, 0, time.UTC).UnixMilli()
+	if full.OpenTime != fullStart || full.CloseTime != fullStart+4*time.Hour.Milliseconds()-1 {
+		t.Errorf("完整周期时间错误: open=%d close=%d", full.OpenTime, full.CloseTime)
+	}
+	if full.Open != 104 || full.Close != 119.25 || full.High != 119.5 || full.Low != 103.5 {
+		t.Errorf("完整周期 OHLC 错误: %+v", full)
+	}
+	if full.Volume != 16 || full.Trades != 32 {
+		t.Errorf("完整周期成交量错误: volume=%v trades=%d", full.Volume, full.Trades)
+	}
+
+	partial := result[1]
+	partialStart := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC).UnixMilli()
+	if partial.OpenTime != partialStart || partial.CloseTime != partialStart+4*time.Hour.Milliseconds()-1 {
+		t.Errorf("未收盘周期时间错误: open=%d close=%d", partial.OpenTime, partial.CloseTime)
+	}
+	if partial.Open != 120 || partial.Close != 122.25 || partial.Volume != 3 {
+		t.Errorf("未收盘周期错误: %+v", partial)
+	}
+}
+
+func TestResampleWeekAndMonth(t *testing.T) {
+	// 2024-01-01 是周一
+	klines := minuteKlines(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), 24*time.Hour, 40)
+
+	weeks, err := Resample(klines, "1d", "1w")
+	if err != nil {
+		t.Fatalf("Resample 1w 失败: %v", err)
+	}
+	if len(weeks) != 6 {
+		t.Fatalf("期望 6 根周线，得到 %d", len(weeks))
+	}
+	for _, w := range weeks {
+		if time.UnixMilli(w.OpenTime).UTC().Weekday() != time.Monday {
+			t.Errorf("周线未按周一对齐: %v", time.UnixMilli(w.OpenTime).UTC())
+		}
+	}
+
+	months, err := Resample(klines, "1d", "1M")
+	if err != nil {
+		t.Fatalf("Resample 1M 失败: %v", err)
+	}
+	if len(months) != 2 {
+		t.Fatalf("期望 2 根月线，得到 %d", len(months))
+	}
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	if months[0].Volume != 31 || months[0].CloseTime != feb-1 {
+		t.Errorf("1月K线错误: %+v", months[0])
+	}
+	if months[1].OpenTime != feb || months[1].Volume != 9 {
+		t.Errorf("2月K线错误: %+v", months[1])
+	}
+}
+
+func TestResampleInvalidInterval(t *testing.T) {
+	klines := minuteKlines(time.Unix(0, 0), time.Hour, 10)
+	if _, err := Resample(klines, "1h", "90m"); err == nil {
+		t.Error("90m 不是 1h 的整数倍，期望返回错误")
+	}
+	if _, err := Resample(klines, "4h", "1h"); err == nil {
+		t.Error("目标周期小于源周期，期望返回错误")
+	}
+}
+
+func TestCoinbaseBaseInterval(t *testing.T) {
+	cases := map[string]string{"4h": "1h", "3m": "1m", "30m": "15m", "12h": "6h", "1w": "1d", "1M": "1d"}
+	for interval, want := range cases {
+		if got, ok := coinbaseBaseInterval(interval); !ok || got != want {
+			t.Errorf("coinbaseBaseInterval(%s) = %s, %v，期望 %s", interval, got, ok, want)
+		}
+	}
+}
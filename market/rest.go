@@ -0,0 +1,26 @@
+package market
+
+import (
+	"errors"
+	"nofx/exchangehttp"
+	"time"
+)
+
+// marketRetryBackoff 行情查询第一次重试前的等待时间，之后按次数递增（测试中可调整）
+var marketRetryBackoff = 2 * time.Second
+
+// marketRetry 行情 REST 查询（只读、可安全重发）的重试策略：临时网络错误、429 和 5xx 最多尝试 3 次
+var marketRetry = exchangehttp.Retry{
+	MaxAttempts: 3,
+	Backoff:     func(retry int) time.Duration { return time.Duration(retry) * marketRetryBackoff },
+	Retryable:   isRetryableMarketError,
+}
+
+// isRetryableMarketError 临时错误，或币安返回的限流（-1003）/内部错误（-1001）
+func isRetryableMarketError(err error) bool {
+	var binanceErr *BinanceErrorResponse
+	if errors.As(err, &binanceErr) {
+		return binanceErr.Code == -1003 || binanceErr.Code == -1001
+	}
+	return exchangehttp.Temporary(err)
+}
@@ -269,10 +269,9 @@ func EnhanceOIData(symbol string, oi *OIData) error {
 		return fmt.Errorf("OI data is nil")
 	}
 
-	// 獲取多空比（完全免費）
-	longShortRatio, err := FetchLongShortRatio(symbol)
-	if err == nil {
-		oi.LongShortRatio = longShortRatio
+	// 獲取多空比（使用 DefaultOpenInterestSource 數據源）
+	if ratios, err := GetLongShortRatio(symbol, "5m", 1); err == nil && len(ratios) > 0 {
+		oi.LongShortRatio = ratios[len(ratios)-1].Ratio
 	}
 
 	// 獲取大戶多空比（完全免費）
@@ -2,7 +2,6 @@ package market
 
 import (
 	"fmt"
-	"log"
 )
 
 // ExampleUsage 展示如何使用免費市場情緒 API
@@ -17,7 +16,7 @@ func ExampleUsage() {
 	// 全市場多空持倉人數比
 	longShortRatio, err := FetchLongShortRatio(symbol)
 	if err != nil {
-		log.Printf("❌ 獲取多空比失敗: %v", err)
+		log.Error("❌ 獲取多空比失敗", "error", err)
 	} else {
 		fmt.Printf("  • 全市場多空比：%.2f\n", longShortRatio)
 		if longShortRatio > 1 {
@@ -34,7 +33,7 @@ func ExampleUsage() {
 	// 大戶多空持倉量比
 	topTraderRatio, err := FetchTopTraderLongShortRatio(symbol)
 	if err != nil {
-		log.Printf("❌ 獲取大戶多空比失敗: %v", err)
+		log.Error("❌ 獲取大戶多空比失敗", "error", err)
 	} else {
 		fmt.Printf("  • 大戶多空比：%.2f\n", topTraderRatio)
 		if topTraderRatio > 1 {
@@ -57,7 +56,7 @@ func ExampleUsage() {
 
 	vix, err := FetchVIX()
 	if err != nil {
-		log.Printf("❌ 獲取 VIX 失敗: %v", err)
+		log.Error("❌ 獲取 VIX 失敗", "error", err)
 	} else {
 		fearLevel, recommendation := AnalyzeVIX(vix)
 		fmt.Printf("  • VIX 值：%.2f\n", vix)
@@ -92,7 +91,7 @@ func ExampleUsage() {
 	} else {
 		usMarket, err := FetchSPXStatus(alphaVantageKey)
 		if err != nil {
-			log.Printf("❌ 獲取美股狀態失敗: %v", err)
+			log.Error("❌ 獲取美股狀態失敗", "error", err)
 		} else {
 			if usMarket.IsOpen {
 				fmt.Printf("  • 美股狀態：開盤中\n")
@@ -114,7 +113,7 @@ func ExampleUsage() {
 
 	sentiment, err := FetchMarketSentiment(alphaVantageKey)
 	if err != nil {
-		log.Printf("❌ 獲取市場情緒失敗: %v", err)
+		log.Error("❌ 獲取市場情緒失敗", "error", err)
 	} else {
 		fmt.Printf("  • VIX：%.2f (%s)\n", sentiment.VIX, sentiment.FearLevel)
 		fmt.Printf("  • 建議：%s\n", sentiment.Recommendation)
@@ -0,0 +1,253 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"nofx/eventbus"
+)
+
+// 价差监控默认参数
+const (
+	defaultSpreadInterval    = 5 * time.Second
+	defaultSpreadSlippageBps = 2.0
+	defaultSpreadCooldown    = time.Minute
+)
+
+// SpreadVenue 价差监控的一个交易场所
+type SpreadVenue struct {
+	Name    string     // 场所名称（执行时用于找到对应的交易员）
+	Source  DataSource // 该场所的行情
+	FeeRate float64    // 吃单手续费率（小数，如 0.0005 = 5bp）
+}
+
+// SpreadExecutor 价差套利执行（trader.SpreadLegs 实现了该接口）
+type SpreadExecutor interface {
+	// OpenSpread 在 BuyVenue 做多、SellVenue 做空
+	OpenSpread(opp eventbus.SpreadOpportunity) error
+	// CloseSpread 平掉 OpenSpread 开出的两条腿
+	CloseSpread(opp eventbus.SpreadOpportunity) error
+}
+
+// SpreadMonitorConfig 跨交易所价差监控配置
+type SpreadMonitorConfig struct {
+	Symbols     []string
+	Venues      []SpreadVenue
+	SlippageBps float64        // 每次成交的滑点估计（基点，默认 2）
+	MinNetBps   float64        // 扣除手续费和滑点后至少剩余多少基点才算机会（默认 0）
+	ExitBps     float64        // 已执行的价差回落到该值（基点）以下时两条腿一起平仓（默认 0）
+	Interval    time.Duration  // 检查间隔（默认 5s）
+	Cooldown    time.Duration  // 同一币种重复发布机会事件的最小间隔（默认 1m）
+	Executor    SpreadExecutor // 可选：nil=只发布事件，不下单
+}
+
+// SpreadMonitor 持续比较同一币种在多个交易场所的价格，价差超过手续费+滑点时发布 SpreadOpportunity 事件，
+// 配置了 Executor 时在两个场所同时开仓，价差收敛后平仓
+type SpreadMonitor struct {
+	cfg SpreadMonitorConfig
+	now func() time.Time
+
+	mu        sync.Mutex
+	open      map[string]eventbus.SpreadOpportunity // 币种 -> 已执行的价差
+	lastEvent map[string]time.Time                  // 币种 -> 上次发布事件时间
+}
+
+// NewSpreadMonitor 创建价差监控
+func NewSpreadMonitor(cfg SpreadMonitorConfig) (*SpreadMonitor, error) {
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("价差监控未配置币种")
+	}
+	if len(cfg.Venues) < 2 {
+		return nil, fmt.Errorf("价差监控至少需要两个交易场所（当前 %d）", len(cfg.Venues))
+	}
+	for _, v := range cfg.Venues {
+		if v.Name == "" || v.Source == nil {
+			return nil, fmt.Errorf("价差监控的交易场所缺少名称或行情数据源")
+		}
+	}
+	if cfg.SlippageBps == 0 {
+		cfg.SlippageBps = defaultSpreadSlippageBps
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = defaultSpreadInterval
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaultSpreadCooldown
+	}
+	return &SpreadMonitor{
+		cfg:       cfg,
+		now:       time.Now,
+		open:      make(map[string]eventbus.SpreadOpportunity),
+		lastEvent: make(map[string]time.Time),
+	}, nil
+}
+
+// Run 每隔 Interval 检查一轮，直到 ctx 取消
+func (m *SpreadMonitor) Run(ctx context.Context) error {
+	ticker := time.NewTicker(m.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		m.CheckOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// OpenSpreads 当前已执行、等待收敛的价差
+func (m *SpreadMonitor) OpenSpreads() []eventbus.SpreadOpportunity {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	spreads := make([]eventbus.SpreadOpportunity, 0, len(m.open))
+	for _, opp := range m.open {
+		spreads = append(spreads, opp)
+	}
+	return spreads
+}
+
+// CheckOnce 检查一轮，返回本轮发现的机会（不受 Cooldown 限制）
+func (m *SpreadMonitor) CheckOnce(ctx context.Context) []eventbus.SpreadOpportunity {
+	var found []eventbus.SpreadOpportunity
+	for _, symbol := range m.cfg.Symbols {
+		if ctx.Err() != nil {
+			return found
+		}
+		tickers := m.fetchTickers(ctx, symbol)
+		if len(tickers) < 2 {
+			continue
+		}
+		now := m.now()
+
+		m.mu.Lock()
+		opened, isOpen := m.open[symbol]
+		m.mu.Unlock()
+		if isOpen {
+			m.checkExit(opened, tickers, now)
+			continue
+		}
+
+		opp, ok := m.bestSpread(symbol, tickers, now)
+		if !ok || opp.NetBps < m.cfg.MinNetBps || opp.NetBps <= 0 {
+			continue
+		}
+		found = append(found, opp)
+
+		m.mu.Lock()
+		publish := now.Sub(m.lastEvent[symbol]) >= m.cfg.Cooldown
+		if publish {
+			m.lastEvent[symbol] = now
+		}
+		m.mu.Unlock()
+		if publish {
+			log.Info("💱 跨交易所价差", "symbol", symbol, "buy_venue", opp.BuyVenue, "buy_price", opp.BuyPrice, "sell_venue", opp.SellVenue, "sell_price", opp.SellPrice, "gross_bps", opp.GrossBps, "cost_bps", opp.CostBps, "net_bps", opp.NetBps)
+			eventbus.Publish(opp)
+		}
+
+		if m.cfg.Executor == nil {
+			continue
+		}
+		if err := m.cfg.Executor.OpenSpread(opp); err != nil {
+			log.Error("❌ 价差套利开仓失败", "symbol", symbol, "error", err)
+			continue
+		}
+		m.mu.Lock()
+		m.open[symbol] = opp
+		m.mu.Unlock()
+	}
+	return found
+}
+
+// checkExit 已执行的价差：按平仓方向（买入场所卖出、卖出场所买回）计算当前价差，回落到 ExitBps 以下时平仓
+func (m *SpreadMonitor) checkExit(opened eventbus.SpreadOpportunity, tickers map[string]*Ticker, now time.Time) {
+	buy, okBuy := tickers[opened.BuyVenue]
+	sell, okSell := tickers[opened.SellVenue]
+	if !okBuy || !okSell || m.cfg.Executor == nil {
+		return
+	}
+	exitBid, exitAsk := bidPrice(buy), askPrice(sell)
+	spread := (exitAsk - exitBid) / exitBid * 10000
+	if spread > m.cfg.ExitBps {
+		return
+	}
+	if err := m.cfg.Executor.CloseSpread(opened); err != nil {
+		log.Error("❌ 价差套利平仓失败", "symbol", opened.Symbol, "error", err)
+		return
+	}
+	log.Info("💱 价差已收敛，已平仓", "symbol", opened.Symbol, "spread_bps", spread, "entry_spread_bps", opened.GrossBps, "held", now.Sub(opened.Time).Round(time.Second))
+	m.mu.Lock()
+	delete(m.open, opened.Symbol)
+	m.mu.Unlock()
+}
+
+// bestSpread 价差最大的场所组合：在卖一价最低的场所买入、买一价最高的另一场所卖出
+func (m *SpreadMonitor) bestSpread(symbol string, tickers map[string]*Ticker, now time.Time) (eventbus.SpreadOpportunity, bool) {
+	var best eventbus.SpreadOpportunity
+	found := false
+	for _, buyVenue := range m.cfg.Venues {
+		buy, ok := tickers[buyVenue.Name]
+		if !ok {
+			continue
+		}
+		for _, sellVenue := range m.cfg.Venues {
+			sell, ok := tickers[sellVenue.Name]
+			if !ok || sellVenue.Name == buyVenue.Name {
+				continue
+			}
+			buyPrice, sellPrice := askPrice(buy), bidPrice(sell)
+			gross := (sellPrice - buyPrice) / buyPrice * 10000
+			// 两条腿各开平一次：4 次成交的手续费和滑点
+			cost := 2*(buyVenue.FeeRate+sellVenue.FeeRate)*10000 + 4*m.cfg.SlippageBps
+			if !found || gross-cost > best.NetBps {
+				best = eventbus.SpreadOpportunity{
+					Symbol: symbol, BuyVenue: buyVenue.Name, SellVenue: sellVenue.Name,
+					BuyPrice: buyPrice, SellPrice: sellPrice,
+					GrossBps: gross, CostBps: cost, NetBps: gross - cost, Time: now,
+				}
+				found = true
+			}
+		}
+	}
+	return best, found
+}
+
+// fetchTickers 并发获取各场所的行情（失败或无有效价格的场所不在结果中）
+func (m *SpreadMonitor) fetchTickers(ctx context.Context, symbol string) map[string]*Ticker {
+	tickers := make(map[string]*Ticker, len(m.cfg.Venues))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, venue := range m.cfg.Venues {
+		wg.Add(1)
+		go func(venue SpreadVenue) {
+			defer wg.Done()
+			ticker, err := venue.Source.GetTicker(ctx, symbol)
+			if err != nil || ticker == nil || askPrice(ticker) <= 0 || bidPrice(ticker) <= 0 {
+				return
+			}
+			mu.Lock()
+			tickers[venue.Name] = ticker
+			mu.Unlock()
+		}(venue)
+	}
+	wg.Wait()
+	return tickers
+}
+
+// askPrice 卖一价（无盘口数据时为最新价）
+func askPrice(t *Ticker) float64 {
+	if t.AskPrice > 0 {
+		return t.AskPrice
+	}
+	return t.LastPrice
+}
+
+// bidPrice 买一价（无盘口数据时为最新价）
+func bidPrice(t *Ticker) float64 {
+	if t.BidPrice > 0 {
+		return t.BidPrice
+	}
+	return t.LastPrice
+}
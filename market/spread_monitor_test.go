@@ -0,0 +1,125 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"nofx/eventbus"
+)
+
+// recordingSpreadExecutor 记录开平仓请求
+type recordingSpreadExecutor struct {
+	opened, closed []eventbus.SpreadOpportunity
+	failOpen       bool
+}
+
+func (e *recordingSpreadExecutor) OpenSpread(opp eventbus.SpreadOpportunity) error {
+	if e.failOpen {
+		return errors.New("exchange error")
+	}
+	e.opened = append(e.opened, opp)
+	return nil
+}
+
+func (e *recordingSpreadExecutor) CloseSpread(opp eventbus.SpreadOpportunity) error {
+	e.closed = append(e.closed, opp)
+	return nil
+}
+
+func TestSpreadMonitorDetectsOpportunity(t *testing.T) {
+	binance := &MockDataSource{name: "binance", tickerData: &Ticker{LastPrice: 100, BidPrice: 99.99, AskPrice: 100}}
+	okx := &MockDataSource{name: "okx", tickerData: &Ticker{LastPrice: 100.3, BidPrice: 100.3, AskPrice: 100.31}}
+	bybit := &MockDataSource{name: "bybit", failTicker: true}
+	m, err := NewSpreadMonitor(SpreadMonitorConfig{
+		Symbols:     []string{"BTCUSDT"},
+		Venues:      []SpreadVenue{{Name: "binance", Source: binance, FeeRate: 0.0004}, {Name: "okx", Source: okx, FeeRate: 0.0005}, {Name: "bybit", Source: bybit}},
+		SlippageBps: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, unsubscribe := eventbus.Subscribe(4, eventbus.TopicSpreadOpportunity)
+	defer unsubscribe()
+
+	// 价差 30 bps，成本 2×(4+5) + 4×1 = 22 bps
+	found := m.CheckOnce(context.Background())
+	if len(found) != 1 {
+		t.Fatalf("期望发现 1 个机会, got %+v", found)
+	}
+	opp := found[0]
+	if opp.BuyVenue != "binance" || opp.SellVenue != "okx" || math.Abs(opp.GrossBps-30) > 1e-6 || math.Abs(opp.CostBps-22) > 1e-9 {
+		t.Errorf("机会计算错误: %+v", opp)
+	}
+	select {
+	case ev := <-events:
+		if ev.(eventbus.SpreadOpportunity).Symbol != "BTCUSDT" {
+			t.Errorf("事件错误: %+v", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("未发布价差事件")
+	}
+
+	// 冷却期内不重复发布事件
+	m.CheckOnce(context.Background())
+	select {
+	case ev := <-events:
+		t.Errorf("冷却期内不应重复发布: %+v", ev)
+	default:
+	}
+
+	// 价差不足以覆盖成本
+	okx.tickerData = &Ticker{LastPrice: 100.1}
+	if found := m.CheckOnce(context.Background()); len(found) != 0 {
+		t.Errorf("价差低于成本时不应视为机会: %+v", found)
+	}
+}
+
+func TestSpreadMonitorExecutesAndUnwinds(t *testing.T) {
+	binance := &MockDataSource{name: "binance", tickerData: &Ticker{LastPrice: 100}}
+	okx := &MockDataSource{name: "okx", tickerData: &Ticker{LastPrice: 100.5}}
+	exec := &recordingSpreadExecutor{}
+	m, _ := NewSpreadMonitor(SpreadMonitorConfig{
+		Symbols:  []string{"BTCUSDT"},
+		Venues:   []SpreadVenue{{Name: "binance", Source: binance}, {Name: "okx", Source: okx}},
+		Executor: exec,
+		ExitBps:  5,
+	})
+
+	m.CheckOnce(context.Background())
+	if len(exec.opened) != 1 || len(m.OpenSpreads()) != 1 {
+		t.Fatalf("期望执行开仓: %+v", exec.opened)
+	}
+	// 已有持仓时不重复开仓，价差未收敛不平仓
+	m.CheckOnce(context.Background())
+	if len(exec.opened) != 1 || len(exec.closed) != 0 {
+		t.Fatalf("价差未收敛时不应重复开仓或平仓: opened=%d closed=%d", len(exec.opened), len(exec.closed))
+	}
+	// 价差收敛到 3 bps：平仓
+	okx.tickerData = &Ticker{LastPrice: 100.03}
+	m.CheckOnce(context.Background())
+	if len(exec.closed) != 1 || len(m.OpenSpreads()) != 0 {
+		t.Fatalf("价差收敛后应平仓: %+v", exec.closed)
+	}
+}
+
+func TestSpreadMonitorOpenFailureNotTracked(t *testing.T) {
+	m, _ := NewSpreadMonitor(SpreadMonitorConfig{
+		Symbols: []string{"BTCUSDT"},
+		Venues: []SpreadVenue{
+			{Name: "binance", Source: &MockDataSource{name: "binance", tickerData: &Ticker{LastPrice: 100}}},
+			{Name: "okx", Source: &MockDataSource{name: "okx", tickerData: &Ticker{LastPrice: 101}}},
+		},
+		Executor: &recordingSpreadExecutor{failOpen: true},
+	})
+	m.CheckOnce(context.Background())
+	if len(m.OpenSpreads()) != 0 {
+		t.Error("开仓失败时不应记录为已执行")
+	}
+	if _, err := NewSpreadMonitor(SpreadMonitorConfig{Symbols: []string{"BTCUSDT"}, Venues: []SpreadVenue{{Name: "binance", Source: &MockDataSource{}}}}); err == nil {
+		t.Error("只有一个场所时应返回错误")
+	}
+}
@@ -0,0 +1,235 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"nofx/netconfig"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket 推送默认参数
+const (
+	defaultStreamBackoffMin  = 1 * time.Second
+	defaultStreamBackoffMax  = 30 * time.Second
+	defaultStreamReadTimeout = 90 * time.Second
+	streamHeartbeatInterval  = 20 * time.Second
+	streamChannelBuffer      = 256
+)
+
+// streamConn WebSocket 推送的连接与重连策略（K线、行情推送共用）
+type streamConn struct {
+	name        string // 数据源名称
+	kind        string // 推送类型（日志用），如 "K线"、"行情"
+	label       string // 订阅标识（日志用），如 "BTCUSDT 1m"
+	backoffMin  time.Duration
+	backoffMax  time.Duration
+	readTimeout time.Duration
+}
+
+// newStreamConn 创建推送连接（使用默认退避与超时参数）
+func newStreamConn(name, kind, label string) streamConn {
+	return streamConn{
+		name:        name,
+		kind:        kind,
+		label:       label,
+		backoffMin:  defaultStreamBackoffMin,
+		backoffMax:  defaultStreamBackoffMax,
+		readTimeout: defaultStreamReadTimeout,
+	}
+}
+
+// run 连接循环：断线后按指数退避重连，直到 ctx 取消
+// connect 建立一次连接并阻塞读取，返回是否连接成功过以及断开原因
+func (c *streamConn) run(ctx context.Context, connect func(ctx context.Context) (bool, error)) {
+	backoff := c.backoffMin
+	for {
+		connected, err := connect(ctx)
+		if ctx.Err() != nil {
+			log.Info("⏹ 推送已停止", "source", c.name, "kind", c.kind, "stream", c.label)
+			return
+		}
+		if connected {
+			backoff = c.backoffMin // 成功连接过则重置退避
+		}
+
+		log.Warn("⚠️ 推送断开，稍后重连", "source", c.name, "kind", c.kind, "stream", c.label, "error", err, "backoff", backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			log.Info("⏹ 推送已停止", "source", c.name, "kind", c.kind, "stream", c.label)
+			return
+		}
+
+		backoff *= 2
+		if backoff > c.backoffMax {
+			backoff = c.backoffMax
+		}
+	}
+}
+
+// session 建立一次连接：发送订阅消息、回调 onConnected，然后持续读取
+// onMessage 返回 false 时结束读取；heartbeat 为 nil 表示不需要应用层心跳
+func (c *streamConn) session(ctx context.Context, wsURL string, subscribe []interface{}, heartbeat interface{}, onConnected func(), onMessage func([]byte) bool) (bool, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: netconfig.Proxy}
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
+	if err != nil {
+		return false, fmt.Errorf("连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	// ctx 取消时关闭连接，打断阻塞的读取
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	for _, msg := range subscribe {
+		if err := conn.WriteJSON(msg); err != nil {
+			return true, fmt.Errorf("订阅失败: %w", err)
+		}
+	}
+	log.Info("✅ 推送已连接", "source", c.name, "kind", c.kind, "stream", c.label)
+
+	if onConnected != nil {
+		onConnected()
+	}
+
+	if heartbeat != nil {
+		go sendStreamHeartbeat(conn, heartbeat, done)
+	}
+
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(5*time.Second))
+	})
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if !onMessage(message) {
+			return true, ctx.Err()
+		}
+	}
+}
+
+// sendStreamHeartbeat 定时发送应用层心跳（连接结束时退出）
+func sendStreamHeartbeat(conn *websocket.Conn, hb interface{}, done <-chan struct{}) {
+	ticker := time.NewTicker(streamHeartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			var err error
+			if text, ok := hb.(string); ok {
+				err = conn.WriteMessage(websocket.TextMessage, []byte(text))
+			} else {
+				err = conn.WriteJSON(hb)
+			}
+			if err != nil {
+				return
+			}
+		case <-done:
+			return
+		}
+	}
+}
+
+// streamProtocol 快照类推送协议（行情、盘口等只关心最新值的推送）
+type streamProtocol[T any] interface {
+	// url 连接地址
+	url() string
+	// subscribeMessages 连接后需要发送的订阅消息（可为空）
+	subscribeMessages() []interface{}
+	// heartbeat 应用层心跳消息（nil=不需要）
+	heartbeat() interface{}
+	// parse 解析推送消息（订阅确认、心跳响应、未订阅的币种等返回空）
+	parse(message []byte) ([]T, error)
+}
+
+// protocolStream 按 streamProtocol 解析的推送
+// keepAll=false（快照类）：消费跟不上时丢弃最旧的数据，保证读到的总是最新值
+// keepAll=true（逐笔类）：不丢弃数据，通道满时阻塞读取直到消费或 ctx 取消
+type protocolStream[T any] struct {
+	streamConn
+	protocol streamProtocol[T]
+	keepAll  bool
+	out      chan T
+}
+
+// newLatestStream 创建快照类推送（使用默认退避与超时参数）
+func newLatestStream[T any](name, kind, label string, protocol streamProtocol[T]) *protocolStream[T] {
+	return &protocolStream[T]{
+		streamConn: newStreamConn(name, kind, label),
+		protocol:   protocol,
+		out:        make(chan T, streamChannelBuffer),
+	}
+}
+
+// newOrderedStream 创建逐笔类推送（不丢弃数据）
+func newOrderedStream[T any](name, kind, label string, protocol streamProtocol[T]) *protocolStream[T] {
+	s := newLatestStream(name, kind, label, protocol)
+	s.keepAll = true
+	return s
+}
+
+// run 连接循环：断线后按指数退避重连，直到 ctx 取消
+func (s *protocolStream[T]) run(ctx context.Context) {
+	defer close(s.out)
+
+	s.streamConn.run(ctx, func(ctx context.Context) (bool, error) {
+		return s.session(ctx, s.protocol.url(), s.protocol.subscribeMessages(), s.protocol.heartbeat(), nil,
+			func(message []byte) bool {
+				values, err := s.protocol.parse(message)
+				if err != nil {
+					log.Warn("⚠️ 推送解析失败", "source", s.name, "kind", s.kind, "stream", s.label, "error", err)
+					return true
+				}
+				for _, v := range values {
+					if !s.emit(ctx, v) {
+						return false
+					}
+				}
+				return ctx.Err() == nil
+			})
+	})
+}
+
+// emit 推送一条数据（ctx 取消时返回 false）
+func (s *protocolStream[T]) emit(ctx context.Context, v T) bool {
+	if s.keepAll {
+		select {
+		case s.out <- v:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	select {
+	case s.out <- v:
+		return true
+	default:
+	}
+
+	// 通道已满：丢弃最旧的一条
+	select {
+	case <-s.out:
+	default:
+	}
+	select {
+	case s.out <- v:
+	default:
+	}
+	return true
+}
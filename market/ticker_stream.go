@@ -0,0 +1,70 @@
+package market
+
+import (
+	"context"
+	"strings"
+)
+
+// DefaultTickerStreamSource StreamTickers 默认使用的数据源（注册名）
+var DefaultTickerStreamSource = "binance"
+
+// TickerStreamer 支持 WebSocket 实时行情（最新价/盘口中间价）推送的数据源
+type TickerStreamer interface {
+	// StreamTickers 订阅多个币种的实时行情，ctx 取消后关闭返回的通道
+	// 消费跟不上时丢弃最旧的行情，保证读到的总是最新价格
+	StreamTickers(ctx context.Context, symbols []string) <-chan Ticker
+}
+
+// StreamTickers 使用默认数据源订阅实时行情（断线自动重连），用于止损等需要毫秒级响应的场景
+// 默认数据源不可用时记录日志并返回已关闭的通道
+func StreamTickers(ctx context.Context, symbols []string) <-chan Ticker {
+	source, err := NewDataSourceByName(DefaultTickerStreamSource)
+	if err != nil {
+		log.Warn("⚠️ 行情推送启动失败", "error", err)
+		return closedTickerChannel()
+	}
+	streamer, ok := source.(TickerStreamer)
+	if !ok {
+		log.Warn("⚠️ 数据源不支持行情推送", "source", source.GetName())
+		return closedTickerChannel()
+	}
+	return streamer.StreamTickers(ctx, symbols)
+}
+
+// tickerStreamProtocol 各交易所 WebSocket 行情协议（按订阅的币种创建）
+type tickerStreamProtocol = streamProtocol[Ticker]
+
+// startTickerStream 启动行情推送 goroutine（没有币种时返回已关闭的通道）
+func startTickerStream(ctx context.Context, name string, symbols []string, newProtocol func(symbols []string) tickerStreamProtocol) <-chan Ticker {
+	symbols = normalizeStreamSymbols(symbols)
+	if len(symbols) == 0 {
+		log.Warn("⚠️ 行情推送未指定币种", "source", name)
+		return closedTickerChannel()
+	}
+
+	s := newLatestStream(name, "行情", strings.Join(symbols, ","), newProtocol(symbols))
+	go s.run(ctx)
+	return s.out
+}
+
+// normalizeStreamSymbols 转大写并去重（保持原顺序）
+func normalizeStreamSymbols(symbols []string) []string {
+	seen := make(map[string]bool, len(symbols))
+	result := make([]string, 0, len(symbols))
+	for _, symbol := range symbols {
+		symbol = strings.ToUpper(strings.TrimSpace(symbol))
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		result = append(result, symbol)
+	}
+	return result
+}
+
+// closedTickerChannel 返回已关闭的行情通道
+func closedTickerChannel() <-chan Ticker {
+	ch := make(chan Ticker)
+	close(ch)
+	return ch
+}
@@ -0,0 +1,107 @@
+package market
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestBinanceStreamTickers 组合流 bookTicker 推送转换为中间价行情
+func TestBinanceStreamTickers(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/stream" || r.URL.Query().Get("streams") != "btcusdt@bookTicker/ethusdt@bookTicker" {
+			t.Errorf("unexpected url: %s", r.URL.String())
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"stream":"btcusdt@bookTicker","data":{"e":"bookTicker","E":1700000000123,"s":"BTCUSDT","b":"100","B":"1","a":"102","A":"2"}}`))
+		time.Sleep(time.Second)
+	}))
+	defer server.Close()
+
+	source := NewBinanceDataSource()
+	source.wsURL = "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch := source.StreamTickers(ctx, []string{"btcusdt", "ETHUSDT", "BTCUSDT"})
+
+	select {
+	case ticker := <-ch:
+		if ticker.Symbol != "BTCUSDT" || ticker.LastPrice != 101 || ticker.BidPrice != 100 || ticker.AskPrice != 102 || ticker.Timestamp != 1700000000 {
+			t.Errorf("Unexpected ticker: %+v", ticker)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for ticker")
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+// TestTickerStream_DropOldest 消费跟不上时丢弃最旧的行情
+func TestTickerStream_DropOldest(t *testing.T) {
+	s := newLatestStream[Ticker]("Binance", "行情", "BTCUSDT", nil)
+	for i := 0; i < streamChannelBuffer+10; i++ {
+		s.emit(context.Background(), Ticker{Symbol: "BTCUSDT", LastPrice: float64(i)})
+	}
+
+	if len(s.out) != streamChannelBuffer {
+		t.Fatalf("Expected %d buffered tickers, got %d", streamChannelBuffer, len(s.out))
+	}
+	first := <-s.out
+	if first.LastPrice != 10 {
+		t.Errorf("Expected oldest tickers to be dropped, first=%v", first.LastPrice)
+	}
+}
+
+// TestStreamTickers_NoSymbols 未指定币种时返回已关闭通道
+func TestStreamTickers_NoSymbols(t *testing.T) {
+	ch := NewOKXDataSource().StreamTickers(context.Background(), []string{" "})
+	if _, ok := <-ch; ok {
+		t.Error("Expected closed channel")
+	}
+}
+
+// TestOKXTickerProtocol 订阅消息与推送解析（忽略未订阅的币种）
+func TestOKXTickerProtocol(t *testing.T) {
+	p := &okxTickerProtocol{symbols: map[string]string{"BTC-USDT-SWAP": "BTCUSDT"}}
+
+	data, _ := json.Marshal(p.subscribeMessages()[0])
+	if !strings.Contains(string(data), `{"channel":"tickers","instId":"BTC-USDT-SWAP"}`) {
+		t.Errorf("Unexpected subscribe message: %s", data)
+	}
+
+	tickers, err := p.parse([]byte(`{"arg":{"channel":"tickers"},"data":[{"instId":"BTC-USDT-SWAP","last":"101.5","bidPx":"101.4","askPx":"101.6","vol24h":"1000","ts":"1700000000000"},{"instId":"ETH-USDT-SWAP","last":"2000"}]}`))
+	if err != nil || len(tickers) != 1 {
+		t.Fatalf("Unexpected tickers: %+v, err=%v", tickers, err)
+	}
+	if tickers[0].Symbol != "BTCUSDT" || tickers[0].LastPrice != 101.5 || tickers[0].MidPrice() != 101.5 || tickers[0].Volume != 1000 {
+		t.Errorf("Unexpected ticker: %+v", tickers[0])
+	}
+}
+
+// TestHyperliquidTickerProtocol allMids 推送只保留订阅的币种
+func TestHyperliquidTickerProtocol(t *testing.T) {
+	p := &hyperliquidTickerProtocol{symbols: map[string]string{"ETH": "ETHUSDT"}}
+
+	tickers, err := p.parse([]byte(`{"channel":"allMids","data":{"mids":{"BTC":"65000","ETH":"3000.5"}}}`))
+	if err != nil || len(tickers) != 1 || tickers[0].Symbol != "ETHUSDT" || tickers[0].LastPrice != 3000.5 {
+		t.Errorf("Unexpected tickers: %+v, err=%v", tickers, err)
+	}
+
+	if tickers, _ := p.parse([]byte(`{"channel":"pong"}`)); len(tickers) != 0 {
+		t.Errorf("pong should be ignored: %+v", tickers)
+	}
+}
@@ -0,0 +1,73 @@
+package market
+
+import (
+	"context"
+	"fmt"
+)
+
+// 成交方向（主动成交方）
+const (
+	TradeSideBuy  = "buy"  // 主动买入（吃卖单）
+	TradeSideSell = "sell" // 主动卖出（吃买单）
+)
+
+// DefaultTradeStreamSource StreamTrades 默认使用的数据源（注册名）
+var DefaultTradeStreamSource = "binance"
+
+// Trade 逐笔成交（Binance 为归集成交：同一主动单同价位的成交合并为一条）
+type Trade struct {
+	Symbol    string  `json:"symbol"`
+	TradeID   int64   `json:"trade_id"`
+	Price     float64 `json:"price"`
+	Quantity  float64 `json:"quantity"`
+	Side      string  `json:"side"`      // 主动成交方向：buy / sell
+	Timestamp int64   `json:"timestamp"` // 成交时间（毫秒）
+}
+
+// SignedQuantity 带方向的成交量（主动买入为正，主动卖出为负）
+func (t Trade) SignedQuantity() float64 {
+	if t.Side == TradeSideSell {
+		return -t.Quantity
+	}
+	return t.Quantity
+}
+
+// CumulativeVolumeDelta 累计成交量差（CVD：主动买入量 - 主动卖出量）
+func CumulativeVolumeDelta(trades []Trade) float64 {
+	delta := 0.0
+	for _, t := range trades {
+		delta += t.SignedQuantity()
+	}
+	return delta
+}
+
+// TradeStreamer 支持 WebSocket 逐笔成交推送的数据源
+type TradeStreamer interface {
+	// StreamTrades 订阅逐笔成交，ctx 取消后关闭返回的通道
+	// 成交不会被丢弃（计算 CVD 需要完整数据），消费方需及时读取
+	StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error)
+}
+
+// StreamTrades 使用默认数据源订阅逐笔成交（断线自动重连，断线期间的成交不补齐）
+func StreamTrades(ctx context.Context, symbol string) (<-chan Trade, error) {
+	source, err := NewDataSourceByName(DefaultTradeStreamSource)
+	if err != nil {
+		return nil, err
+	}
+	streamer, ok := source.(TradeStreamer)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持成交推送", source.GetName())
+	}
+	return streamer.StreamTrades(ctx, symbol)
+}
+
+// startTradeStream 启动成交推送 goroutine
+func startTradeStream(ctx context.Context, name, symbol string, protocol streamProtocol[Trade]) (<-chan Trade, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("%s 成交推送未指定币种", name)
+	}
+
+	s := newOrderedStream(name, "成交", symbol, protocol)
+	go s.run(ctx)
+	return s.out, nil
+}
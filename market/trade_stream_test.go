@@ -0,0 +1,107 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestBinanceStreamTrades 超过通道缓冲的成交也不丢弃
+func TestBinanceStreamTrades(t *testing.T) {
+	const total = streamChannelBuffer + 50
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/btcusdt@aggTrade" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		for i := 1; i <= total; i++ {
+			msg := fmt.Sprintf(`{"e":"aggTrade","E":1700000000100,"s":"BTCUSDT","a":%d,"p":"100.5","q":"0.2","f":1,"l":2,"T":1700000000000,"m":%t}`, i, i%2 == 0)
+			conn.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+		time.Sleep(2 * time.Second)
+	}))
+	defer server.Close()
+
+	source := NewBinanceDataSource()
+	source.wsURL = "ws" + strings.TrimPrefix(server.URL, "http")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ch, err := source.StreamTrades(ctx, "btcusdt")
+	if err != nil {
+		t.Fatalf("StreamTrades failed: %v", err)
+	}
+
+	// 等待服务端写满通道后再开始消费
+	time.Sleep(200 * time.Millisecond)
+
+	var trades []Trade
+	timeout := time.After(5 * time.Second)
+	for len(trades) < total {
+		select {
+		case trade := <-ch:
+			if trade.TradeID != int64(len(trades)+1) {
+				t.Fatalf("Expected trade %d, got %d", len(trades)+1, trade.TradeID)
+			}
+			trades = append(trades, trade)
+		case <-timeout:
+			t.Fatalf("timeout, got %d trades", len(trades))
+		}
+	}
+
+	first := trades[0]
+	if first.Symbol != "BTCUSDT" || first.Price != 100.5 || first.Quantity != 0.2 || first.Side != TradeSideBuy || first.Timestamp != 1700000000000 {
+		t.Errorf("Unexpected trade: %+v", first)
+	}
+	if trades[1].Side != TradeSideSell {
+		t.Errorf("Buyer-maker trade should be a taker sell: %+v", trades[1])
+	}
+
+	cancel()
+	for range ch {
+	}
+}
+
+func TestCumulativeVolumeDelta(t *testing.T) {
+	trades := []Trade{
+		{Quantity: 2, Side: TradeSideBuy},
+		{Quantity: 0.5, Side: TradeSideSell},
+		{Quantity: 1, Side: TradeSideSell},
+	}
+	if got := CumulativeVolumeDelta(trades); got != 0.5 {
+		t.Errorf("Expected CVD 0.5, got %v", got)
+	}
+}
+
+func TestOKXTradeProtocol(t *testing.T) {
+	p := &okxTradeProtocol{symbol: "ETHUSDT"}
+	trades, err := p.parse([]byte(`{"arg":{"channel":"trades","instId":"ETH-USDT-SWAP"},"data":[{"instId":"ETH-USDT-SWAP","tradeId":"42","px":"2000.5","sz":"3","side":"sell","ts":"1700000000000"}]}`))
+	if err != nil || len(trades) != 1 {
+		t.Fatalf("Unexpected trades: %+v, err=%v", trades, err)
+	}
+	if trades[0].TradeID != 42 || trades[0].Side != TradeSideSell || trades[0].SignedQuantity() != -3 {
+		t.Errorf("Unexpected trade: %+v", trades[0])
+	}
+}
+
+func TestHyperliquidTradeProtocol(t *testing.T) {
+	p := &hyperliquidTradeProtocol{symbol: "BTCUSDT"}
+	trades, err := p.parse([]byte(`{"channel":"trades","data":[{"coin":"BTC","side":"B","px":"65000","sz":"0.1","hash":"0x1","time":1700000000000,"tid":7},{"coin":"BTC","side":"A","px":"64999","sz":"0.2","hash":"0x2","time":1700000000001,"tid":8}]}`))
+	if err != nil || len(trades) != 2 {
+		t.Fatalf("Unexpected trades: %+v, err=%v", trades, err)
+	}
+	if trades[0].Side != TradeSideBuy || trades[1].Side != TradeSideSell || trades[1].TradeID != 8 {
+		t.Errorf("Unexpected trades: %+v", trades)
+	}
+}
@@ -111,6 +111,8 @@ type SymbolInfo struct {
 	ContractType      string `json:"contractType"`
 	PricePrecision    int    `json:"pricePrecision"`
 	QuantityPrecision int    `json:"quantityPrecision"`
+	OnboardDate       int64  `json:"onboardDate"`  // 上架时间（毫秒）
+	DeliveryDate      int64  `json:"deliveryDate"` // 交割/下架时间（毫秒；永续合约未计划下架时为 2100 年）
 }
 
 type Kline struct {
@@ -147,17 +149,63 @@ type PriceTicker struct {
 
 type Ticker struct {
 	Symbol    string  `json:"symbol"`
-	LastPrice float64 `json:"lastPrice"`
+	LastPrice float64 `json:"lastPrice"`          // 最新成交价（盘口推送时为买一卖一中间价）
+	BidPrice  float64 `json:"bidPrice,omitempty"` // 买一价（仅推送提供）
+	AskPrice  float64 `json:"askPrice,omitempty"` // 卖一价（仅推送提供）
 	Volume    float64 `json:"volume,omitempty"`
 	Timestamp int64   `json:"timestamp,omitempty"`
 }
 
+// MidPrice 买一卖一中间价（无盘口数据时返回最新价）
+func (t Ticker) MidPrice() float64 {
+	if t.BidPrice > 0 && t.AskPrice > 0 {
+		return (t.BidPrice + t.AskPrice) / 2
+	}
+	return t.LastPrice
+}
+
+// timeframeDurations K线周期时长（"1M" 为近似值）
+var timeframeDurations = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+	"1M":  30 * 24 * time.Hour,
+}
+
+// TimeframeDuration 获取K线周期时长（如 "15m" -> 15分钟），未知周期返回 false
+func TimeframeDuration(timeframe string) (time.Duration, bool) {
+	d, ok := timeframeDurations[timeframe]
+	return d, ok
+}
+
+// FundingRate 资金费率
+type FundingRate struct {
+	Symbol      string  `json:"symbol"`
+	Rate        float64 `json:"rate"`         // 资金费率（小数，0.0001 = 1bp；正数表示多头支付空头）
+	FundingTime int64   `json:"funding_time"` // 结算时间（毫秒）；当前/预测费率为下次结算时间
+}
+
 type Ticker24hr struct {
 	Symbol             string `json:"symbol"`
 	PriceChange        string `json:"priceChange"`
 	PriceChangePercent string `json:"priceChangePercent"`
+	LastPrice          string `json:"lastPrice"`
+	HighPrice          string `json:"highPrice"`
+	LowPrice           string `json:"lowPrice"`
 	Volume             string `json:"volume"`
 	QuoteVolume        string `json:"quoteVolume"`
+	CloseTime          int64  `json:"closeTime"`
 }
 
 // 特征数据结构
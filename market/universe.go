@@ -0,0 +1,198 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 动态币种池的排序方式
+const (
+	UniverseRankVolume     = "volume"     // 按 24 小时成交额
+	UniverseRankVolatility = "volatility" // 按 24 小时振幅（(最高-最低)/最低）
+)
+
+// 动态币种池默认参数
+const (
+	defaultUniverseTopN    = 20
+	defaultUniverseRefresh = 24 * time.Hour
+)
+
+// SymbolStat 一个合约的 24 小时行情统计
+type SymbolStat struct {
+	Symbol         string  `json:"symbol"`
+	LastPrice      float64 `json:"last_price"`
+	HighPrice      float64 `json:"high_price"`
+	LowPrice       float64 `json:"low_price"`
+	QuoteVolume    float64 `json:"quote_volume"`     // 24 小时成交额（USDT）
+	PriceChangePct float64 `json:"price_change_pct"` // 24 小时涨跌幅（百分比）
+}
+
+// VolatilityPct 24 小时振幅（百分比）
+func (s SymbolStat) VolatilityPct() float64 {
+	if s.LowPrice <= 0 {
+		return 0
+	}
+	return (s.HighPrice - s.LowPrice) / s.LowPrice * 100
+}
+
+// SymbolStat 转换为行情统计
+func (t Ticker24hr) SymbolStat() SymbolStat {
+	parse := func(v string) float64 {
+		f, _ := strconv.ParseFloat(v, 64)
+		return f
+	}
+	return SymbolStat{
+		Symbol:         t.Symbol,
+		LastPrice:      parse(t.LastPrice),
+		HighPrice:      parse(t.HighPrice),
+		LowPrice:       parse(t.LowPrice),
+		QuoteVolume:    parse(t.QuoteVolume),
+		PriceChangePct: parse(t.PriceChangePercent),
+	}
+}
+
+// SymbolStatsProvider 支持一次查询全部合约 24 小时行情统计的数据源
+type SymbolStatsProvider interface {
+	// GetSymbolStats 获取全部 USDT 合约的 24 小时行情统计
+	GetSymbolStats(ctx context.Context) ([]SymbolStat, error)
+}
+
+// UniverseConfig 动态币种池配置
+type UniverseConfig struct {
+	Source         string        // 数据源注册名（默认 binance，需要支持 SymbolStatsProvider）
+	Rank           string        // volume / volatility
+	TopN           int           // 选取的币种数（默认 20）
+	Allow          []string      // 只在这些币种中选取（为空不限制）
+	Deny           []string      // 永不选取的币种
+	MinQuoteVolume float64       // 24 小时成交额下限（USDT，按振幅排序时过滤流动性差的币种）
+	Refresh        time.Duration // 重新选取的间隔（默认 24h）
+}
+
+// RankUniverse 按配置从行情统计中选出前 TopN 个币种（同分按币种名排序，结果稳定）
+func RankUniverse(stats []SymbolStat, cfg UniverseConfig) []string {
+	topN := cfg.TopN
+	if topN <= 0 {
+		topN = defaultUniverseTopN
+	}
+	allow := symbolSet(cfg.Allow)
+	deny := symbolSet(cfg.Deny)
+	score := func(s SymbolStat) float64 {
+		if cfg.Rank == UniverseRankVolatility {
+			return s.VolatilityPct()
+		}
+		return s.QuoteVolume
+	}
+
+	candidates := make([]SymbolStat, 0, len(stats))
+	seen := make(map[string]bool, len(stats))
+	for _, s := range stats {
+		symbol := strings.ToUpper(s.Symbol)
+		if seen[symbol] || deny[symbol] || (len(allow) > 0 && !allow[symbol]) {
+			continue
+		}
+		if s.LastPrice <= 0 || s.QuoteVolume < cfg.MinQuoteVolume {
+			continue
+		}
+		seen[symbol] = true
+		s.Symbol = symbol
+		candidates = append(candidates, s)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		si, sj := score(candidates[i]), score(candidates[j])
+		if si != sj {
+			return si > sj
+		}
+		return candidates[i].Symbol < candidates[j].Symbol
+	})
+
+	symbols := make([]string, 0, min(topN, len(candidates)))
+	for i := 0; i < len(candidates) && i < topN; i++ {
+		symbols = append(symbols, candidates[i].Symbol)
+	}
+	return symbols
+}
+
+// symbolSet 币种集合（大写）
+func symbolSet(symbols []string) map[string]bool {
+	set := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		if s = strings.ToUpper(strings.TrimSpace(s)); s != "" {
+			set[s] = true
+		}
+	}
+	return set
+}
+
+// Universe 动态币种池：按 Refresh 间隔从数据源重新选取成交额或振幅前 N 的币种，其余时间返回缓存结果
+type Universe struct {
+	cfg   UniverseConfig
+	fetch func(ctx context.Context) ([]SymbolStat, error)
+	now   func() time.Time
+
+	mu          sync.Mutex
+	symbols     []string
+	refreshedAt time.Time
+}
+
+// NewUniverse 创建动态币种池（数据源不支持行情统计查询时返回错误）
+func NewUniverse(cfg UniverseConfig) (*Universe, error) {
+	if cfg.Rank != UniverseRankVolume && cfg.Rank != UniverseRankVolatility {
+		return nil, fmt.Errorf("不支持的币种池排序方式: %q（可选: %s, %s）", cfg.Rank, UniverseRankVolume, UniverseRankVolatility)
+	}
+	if cfg.Source == "" {
+		cfg.Source = "binance"
+	}
+	source, err := NewDataSourceByName(cfg.Source)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(SymbolStatsProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持全市场行情统计查询", source.GetName())
+	}
+	return newUniverse(cfg, provider.GetSymbolStats), nil
+}
+
+// newUniverse 使用指定的行情统计来源创建动态币种池
+func newUniverse(cfg UniverseConfig, fetch func(ctx context.Context) ([]SymbolStat, error)) *Universe {
+	if cfg.Refresh <= 0 {
+		cfg.Refresh = defaultUniverseRefresh
+	}
+	return &Universe{cfg: cfg, fetch: fetch, now: time.Now}
+}
+
+// Config 动态币种池配置
+func (u *Universe) Config() UniverseConfig {
+	return u.cfg
+}
+
+// Symbols 当前选取的币种（距上次选取超过 Refresh 时重新选取；
+// 数据源失败时沿用上次结果，从未选取成功时返回错误）
+func (u *Universe) Symbols() ([]string, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	now := u.now()
+	if u.symbols != nil && now.Sub(u.refreshedAt) < u.cfg.Refresh {
+		return u.symbols, nil
+	}
+	ctx, cancel := requestContext()
+	defer cancel()
+	stats, err := u.fetch(ctx)
+	if err != nil {
+		if u.symbols != nil {
+			log.Warn("⚠️ 刷新动态币种池失败，沿用上次结果", "refreshed_at", u.refreshedAt.Format("01-02 15:04"), "error", err)
+			return u.symbols, nil
+		}
+		return nil, fmt.Errorf("获取全市场行情统计失败: %w", err)
+	}
+	u.symbols = RankUniverse(stats, u.cfg)
+	u.refreshedAt = now
+	log.Info("🌐 动态币种池已更新", "rank", u.cfg.Rank, "symbol_count", len(u.symbols), "symbols", u.symbols)
+	return u.symbols, nil
+}
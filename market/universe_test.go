@@ -0,0 +1,139 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRankUniverse(t *testing.T) {
+	stats := []SymbolStat{
+		{Symbol: "BTCUSDT", LastPrice: 60000, HighPrice: 61000, LowPrice: 59000, QuoteVolume: 9e9},
+		{Symbol: "ETHUSDT", LastPrice: 3000, HighPrice: 3100, LowPrice: 2900, QuoteVolume: 5e9},
+		{Symbol: "PEPEUSDT", LastPrice: 0.00001, HighPrice: 0.000012, LowPrice: 0.00001, QuoteVolume: 2e8},
+		{Symbol: "SOLUSDT", LastPrice: 150, HighPrice: 165, LowPrice: 150, QuoteVolume: 1e9},
+		{Symbol: "DEADUSDT", LastPrice: 0, QuoteVolume: 0},
+	}
+
+	byVolume := RankUniverse(stats, UniverseConfig{Rank: UniverseRankVolume, TopN: 3})
+	if want := []string{"BTCUSDT", "ETHUSDT", "SOLUSDT"}; !reflect.DeepEqual(byVolume, want) {
+		t.Errorf("by volume = %v, want %v", byVolume, want)
+	}
+
+	byVolatility := RankUniverse(stats, UniverseConfig{Rank: UniverseRankVolatility, TopN: 2})
+	if want := []string{"PEPEUSDT", "SOLUSDT"}; !reflect.DeepEqual(byVolatility, want) {
+		t.Errorf("by volatility = %v, want %v", byVolatility, want)
+	}
+
+	liquid := RankUniverse(stats, UniverseConfig{Rank: UniverseRankVolatility, TopN: 2, MinQuoteVolume: 5e8, Deny: []string{"solusdt"}})
+	if want := []string{"ETHUSDT", "BTCUSDT"}; !reflect.DeepEqual(liquid, want) {
+		t.Errorf("min volume + deny = %v, want %v", liquid, want)
+	}
+
+	allowed := RankUniverse(stats, UniverseConfig{Rank: UniverseRankVolume, Allow: []string{"SOLUSDT", "ETHUSDT", "DEADUSDT"}})
+	if want := []string{"ETHUSDT", "SOLUSDT"}; !reflect.DeepEqual(allowed, want) {
+		t.Errorf("allow list = %v, want %v", allowed, want)
+	}
+}
+
+func TestUniverseRefresh(t *testing.T) {
+	now := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	calls := 0
+	var fail bool
+	stats := []SymbolStat{
+		{Symbol: "BTCUSDT", LastPrice: 1, QuoteVolume: 2},
+		{Symbol: "ETHUSDT", LastPrice: 1, QuoteVolume: 1},
+	}
+	u := newUniverse(UniverseConfig{Rank: UniverseRankVolume, TopN: 1}, func(ctx context.Context) ([]SymbolStat, error) {
+		calls++
+		if fail {
+			return nil, errors.New("unavailable")
+		}
+		return stats, nil
+	})
+	u.now = func() time.Time { return now }
+
+	symbols, err := u.Symbols()
+	if err != nil || !reflect.DeepEqual(symbols, []string{"BTCUSDT"}) {
+		t.Fatalf("Symbols() = %v, %v", symbols, err)
+	}
+
+	// 刷新间隔内使用缓存
+	stats[1].QuoteVolume = 3
+	now = now.Add(23 * time.Hour)
+	if symbols, _ = u.Symbols(); symbols[0] != "BTCUSDT" || calls != 1 {
+		t.Errorf("cached: %v after %d calls", symbols, calls)
+	}
+
+	// 一天后重新选取
+	now = now.Add(time.Hour)
+	if symbols, _ = u.Symbols(); symbols[0] != "ETHUSDT" || calls != 2 {
+		t.Errorf("refreshed: %v after %d calls", symbols, calls)
+	}
+
+	// 数据源失败时沿用上次结果
+	fail = true
+	now = now.Add(25 * time.Hour)
+	if symbols, err = u.Symbols(); err != nil || symbols[0] != "ETHUSDT" {
+		t.Errorf("fallback: %v, %v", symbols, err)
+	}
+}
+
+func TestUniverseFirstFetchError(t *testing.T) {
+	u := newUniverse(UniverseConfig{Rank: UniverseRankVolume}, func(ctx context.Context) ([]SymbolStat, error) {
+		return nil, errors.New("unavailable")
+	})
+	if _, err := u.Symbols(); err == nil {
+		t.Fatal("expected error before the first successful fetch")
+	}
+}
+
+func TestNewUniverseValidates(t *testing.T) {
+	if _, err := NewUniverse(UniverseConfig{Rank: "oi"}); err == nil {
+		t.Error("expected error for unknown rank")
+	}
+	if _, err := NewUniverse(UniverseConfig{Rank: UniverseRankVolume, Source: "coinbase"}); err == nil {
+		t.Error("expected error for a source without symbol stats")
+	}
+	if _, err := NewUniverse(UniverseConfig{Rank: UniverseRankVolume}); err != nil {
+		t.Errorf("binance universe: %v", err)
+	}
+}
+
+func TestBinanceSymbolStats(t *testing.T) {
+	recent := time.Now().UnixMilli()
+	stale := time.Now().Add(-72 * time.Hour).UnixMilli()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/ticker/24hr" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		fmt.Fprintf(w, `[
+			{"symbol":"BTCUSDT","priceChangePercent":"2.5","lastPrice":"60000","highPrice":"61000","lowPrice":"59000","quoteVolume":"9000000000","closeTime":%d},
+			{"symbol":"BTCUSD_PERP","priceChangePercent":"1","lastPrice":"60000","highPrice":"61000","lowPrice":"59000","quoteVolume":"1","closeTime":%d},
+			{"symbol":"OLDUSDT","priceChangePercent":"0","lastPrice":"1","highPrice":"1","lowPrice":"1","quoteVolume":"100","closeTime":%d}
+		]`, recent, recent, stale)
+	}))
+	defer server.Close()
+	setBaseURLForTesting(server.URL)
+	defer setBaseURLForTesting(defaultBaseURL)
+
+	stats, err := NewBinanceDataSource().GetSymbolStats(context.Background())
+	if err != nil {
+		t.Fatalf("GetSymbolStats failed: %v", err)
+	}
+	if len(stats) != 1 {
+		t.Fatalf("expected only the live USDT contract, got %+v", stats)
+	}
+	s := stats[0]
+	if s.Symbol != "BTCUSDT" || s.QuoteVolume != 9e9 || s.PriceChangePct != 2.5 {
+		t.Errorf("unexpected stat: %+v", s)
+	}
+	if v := s.VolatilityPct(); v < 3.38 || v > 3.39 {
+		t.Errorf("volatility = %v", v)
+	}
+}
@@ -3,7 +3,7 @@ package market
 import (
 	"encoding/json"
 	"fmt"
-	"log"
+	"nofx/netconfig"
 	"sync"
 	"time"
 
@@ -47,6 +47,24 @@ type KlineWSData struct {
 	} `json:"k"`
 }
 
+// klineFromWSData 将 Binance K线推送转换为 Kline
+func klineFromWSData(wsData KlineWSData) Kline {
+	kline := Kline{
+		OpenTime:  wsData.Kline.StartTime,
+		CloseTime: wsData.Kline.CloseTime,
+		Trades:    wsData.Kline.NumberOfTrades,
+	}
+	kline.Open, _ = parseFloat(wsData.Kline.OpenPrice)
+	kline.High, _ = parseFloat(wsData.Kline.HighPrice)
+	kline.Low, _ = parseFloat(wsData.Kline.LowPrice)
+	kline.Close, _ = parseFloat(wsData.Kline.ClosePrice)
+	kline.Volume, _ = parseFloat(wsData.Kline.Volume)
+	kline.QuoteVolume, _ = parseFloat(wsData.Kline.QuoteVolume)
+	kline.TakerBuyBaseVolume, _ = parseFloat(wsData.Kline.TakerBuyBaseVolume)
+	kline.TakerBuyQuoteVolume, _ = parseFloat(wsData.Kline.TakerBuyQuoteVolume)
+	return kline
+}
+
 type TickerWSData struct {
 	EventType          string `json:"e"`
 	EventTime          int64  `json:"E"`
@@ -79,6 +97,7 @@ func NewWSClient() *WSClient {
 func (w *WSClient) Connect() error {
 	dialer := websocket.Dialer{
 		HandshakeTimeout: 10 * time.Second,
+		Proxy:            netconfig.Proxy,
 	}
 
 	conn, _, err := dialer.Dial("wss://ws-fapi.binance.com/ws-fapi/v1", nil)
@@ -90,7 +109,7 @@ func (w *WSClient) Connect() error {
 	w.conn = conn
 	w.mu.Unlock()
 
-	log.Println("WebSocket连接成功")
+	log.Info("WebSocket连接成功")
 
 	// 启动消息读取循环
 	go w.readMessages()
@@ -132,7 +151,7 @@ func (w *WSClient) subscribe(stream string) error {
 		return err
 	}
 
-	log.Printf("订阅流: %s", stream)
+	log.Info("订阅流", "stream", stream)
 	return nil
 }
 
@@ -153,7 +172,7 @@ func (w *WSClient) readMessages() {
 
 			_, message, err := conn.ReadMessage()
 			if err != nil {
-				log.Printf("读取WebSocket消息失败: %v", err)
+				log.Warn("读取WebSocket消息失败", "error", err)
 				w.handleReconnect()
 				return
 			}
@@ -178,7 +197,7 @@ func (w *WSClient) handleMessage(message []byte) {
 		select {
 		case ch <- wsMsg.Data:
 		default:
-			log.Printf("订阅者通道已满: %s", wsMsg.Stream)
+			log.Info("订阅者通道已满", "stream", wsMsg.Stream)
 		}
 	}
 }
@@ -188,11 +207,11 @@ func (w *WSClient) handleReconnect() {
 		return
 	}
 
-	log.Println("尝试重新连接...")
+	log.Info("尝试重新连接...")
 	time.Sleep(3 * time.Second)
 
 	if err := w.Connect(); err != nil {
-		log.Printf("重新连接失败: %v", err)
+		log.Warn("重新连接失败", "error", err)
 		go w.handleReconnect()
 	}
 }
@@ -0,0 +1,85 @@
+package metrics
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 决策到下单全链路的阶段（stage 标签值）
+const (
+	StageDataFetch  = "data_fetch" // 拉取 K 线等行情数据
+	StageIndicators = "indicators" // 计算技术指标
+	StageContext    = "context"    // 构建交易上下文（账户、持仓、候选币种）
+	StageDecision   = "decision"   // AI 决策（含提示词构建和响应解析）
+	StageRisk       = "risk"       // 下单前风控检查
+	StageOrder      = "order"      // 下单请求往返
+	StageCycle      = "cycle"      // 完整交易周期
+)
+
+var (
+	// StageLatency 决策到下单各阶段耗时（最近 1024 个样本的 p50/p95/p99）
+	StageLatency = Default.NewSummaryVec("nofx_stage_latency_seconds",
+		"Latency of each stage on the decision-to-order path (sliding window quantiles).", nil, 1024, "stage")
+
+	// LatencyBudgetExceeded 阶段耗时超出预算的次数
+	LatencyBudgetExceeded = Default.NewCounterVec("nofx_latency_budget_exceeded_total",
+		"Stage executions slower than the configured latency budget.", "stage")
+)
+
+var (
+	budgetsMu sync.RWMutex
+	budgets   = map[string]time.Duration{}
+)
+
+// LatencyBudgetsFromEnv 读取 NOFX_LATENCY_BUDGETS（如 decision=30s,order=2s；未设置返回空=不检查预算）
+func LatencyBudgetsFromEnv() (map[string]time.Duration, error) {
+	out := make(map[string]time.Duration)
+	v := strings.TrimSpace(os.Getenv("NOFX_LATENCY_BUDGETS"))
+	if v == "" {
+		return out, nil
+	}
+	for _, item := range strings.Split(v, ",") {
+		stage, raw, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("NOFX_LATENCY_BUDGETS 格式错误: %q（应为 stage=时长）", item)
+		}
+		stage = strings.TrimSpace(stage)
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("NOFX_LATENCY_BUDGETS %s 必须为正的时长: %q", stage, raw)
+		}
+		out[stage] = d
+	}
+	return out, nil
+}
+
+// SetLatencyBudgets 设置各阶段的耗时预算（替换原有配置）
+func SetLatencyBudgets(b map[string]time.Duration) {
+	copied := make(map[string]time.Duration, len(b))
+	for stage, d := range b {
+		copied[stage] = d
+	}
+	budgetsMu.Lock()
+	budgets = copied
+	budgetsMu.Unlock()
+}
+
+// LatencyBudget 阶段的耗时预算（0=未设置）
+func LatencyBudget(stage string) time.Duration {
+	budgetsMu.RLock()
+	defer budgetsMu.RUnlock()
+	return budgets[stage]
+}
+
+// ObserveStage 记录一次阶段耗时，超出预算时计数并返回 true
+func ObserveStage(stage string, d time.Duration) bool {
+	StageLatency.Observe(d.Seconds(), stage)
+	if budget := LatencyBudget(stage); budget > 0 && d > budget {
+		LatencyBudgetExceeded.Inc(stage)
+		return true
+	}
+	return false
+}
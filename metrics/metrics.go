@@ -0,0 +1,354 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 最小化的 Prometheus 指标实现：Counter / Gauge / Histogram / Summary（均带标签），
+// 以 text exposition format 0.0.4 输出（Prometheus、VictoriaMetrics 均可直接抓取）
+
+// DefaultBuckets 默认的耗时直方图分桶（秒）
+var DefaultBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Registry 指标注册表
+type Registry struct {
+	mu      sync.Mutex
+	metrics []*vec
+	names   map[string]bool
+}
+
+// NewRegistry 创建空注册表
+func NewRegistry() *Registry {
+	return &Registry{names: make(map[string]bool)}
+}
+
+// Default 进程默认注册表（/metrics 输出的内容）
+var Default = NewRegistry()
+
+// 指标类型
+const (
+	typeCounter   = "counter"
+	typeGauge     = "gauge"
+	typeHistogram = "histogram"
+	typeSummary   = "summary"
+)
+
+// DefaultQuantiles 默认输出的分位数
+var DefaultQuantiles = []float64{0.5, 0.95, 0.99}
+
+// vec 一个指标名下按标签值区分的全部序列
+type vec struct {
+	name    string
+	help    string
+	typ     string
+	labels  []string
+	buckets []float64
+	// summary：输出的分位数和参与计算的样本窗口大小
+	quantiles []float64
+	window    int
+
+	mu     sync.Mutex
+	series map[string]*series
+}
+
+// series 一组标签值对应的数据
+type series struct {
+	labelValues []string
+	value       float64   // counter / gauge
+	counts      []uint64  // histogram 各分桶计数（非累计）
+	sum         float64   // histogram / summary 总和
+	count       uint64    // histogram / summary 样本数
+	samples     []float64 // summary 最近 window 个样本（环形缓冲）
+	next        int       // summary 下一个写入位置
+}
+
+func (r *Registry) register(v *vec) *vec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.names[v.name] {
+		panic(fmt.Sprintf("metrics: 重复注册指标 %s", v.name))
+	}
+	r.names[v.name] = true
+	r.metrics = append(r.metrics, v)
+	return v
+}
+
+// get 获取（或创建）标签值对应的序列，调用方需持有 v.mu
+func (v *vec) get(labelValues []string) *series {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: 指标 %s 需要 %d 个标签值，实际 %d 个", v.name, len(v.labels), len(labelValues)))
+	}
+	key := strings.Join(labelValues, "\xff")
+	s, ok := v.series[key]
+	if !ok {
+		s = &series{labelValues: append([]string(nil), labelValues...)}
+		if v.typ == typeHistogram {
+			s.counts = make([]uint64, len(v.buckets))
+		}
+		v.series[key] = s
+	}
+	return s
+}
+
+// CounterVec 只增不减的计数器
+type CounterVec struct{ v *vec }
+
+// NewCounterVec 注册计数器
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{r.register(&vec{name: name, help: help, typ: typeCounter, labels: labels, series: make(map[string]*series)})}
+}
+
+// Inc 计数加一
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add 计数增加 delta（负数会被忽略）
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+	c.v.mu.Lock()
+	c.v.get(labelValues).value += delta
+	c.v.mu.Unlock()
+}
+
+// Value 当前计数（测试用）
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.v.mu.Lock()
+	defer c.v.mu.Unlock()
+	return c.v.get(labelValues).value
+}
+
+// GaugeVec 可增可减的数值
+type GaugeVec struct{ v *vec }
+
+// NewGaugeVec 注册 gauge
+func (r *Registry) NewGaugeVec(name, help string, labels ...string) *GaugeVec {
+	return &GaugeVec{r.register(&vec{name: name, help: help, typ: typeGauge, labels: labels, series: make(map[string]*series)})}
+}
+
+// Set 设置当前值
+func (g *GaugeVec) Set(value float64, labelValues ...string) {
+	g.v.mu.Lock()
+	g.v.get(labelValues).value = value
+	g.v.mu.Unlock()
+}
+
+// Delete 删除一组标签值（如交易员被移除）
+func (g *GaugeVec) Delete(labelValues ...string) {
+	g.v.mu.Lock()
+	delete(g.v.series, strings.Join(labelValues, "\xff"))
+	g.v.mu.Unlock()
+}
+
+// Value 当前值（测试用）
+func (g *GaugeVec) Value(labelValues ...string) float64 {
+	g.v.mu.Lock()
+	defer g.v.mu.Unlock()
+	return g.v.get(labelValues).value
+}
+
+// HistogramVec 分桶统计（用于耗时）
+type HistogramVec struct{ v *vec }
+
+// NewHistogramVec 注册直方图（buckets 为空时使用 DefaultBuckets）
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	buckets = append([]float64(nil), buckets...)
+	sort.Float64s(buckets)
+	return &HistogramVec{r.register(&vec{name: name, help: help, typ: typeHistogram, labels: labels, buckets: buckets, series: make(map[string]*series)})}
+}
+
+// Observe 记录一个样本
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	s := h.v.get(labelValues)
+	if i := sort.SearchFloat64s(h.v.buckets, value); i < len(s.counts) {
+		s.counts[i]++
+	}
+	s.sum += value
+	s.count++
+}
+
+// Count 样本数（测试用）
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.v.mu.Lock()
+	defer h.v.mu.Unlock()
+	return h.v.get(labelValues).count
+}
+
+// SummaryVec 滑动窗口分位数统计（最近 window 个样本的 p50/p95/p99 等，用于发现延迟回退）
+type SummaryVec struct{ v *vec }
+
+// NewSummaryVec 注册 summary（quantiles 为空时使用 DefaultQuantiles，window<=0 时为 1024）
+func (r *Registry) NewSummaryVec(name, help string, quantiles []float64, window int, labels ...string) *SummaryVec {
+	if len(quantiles) == 0 {
+		quantiles = DefaultQuantiles
+	}
+	if window <= 0 {
+		window = 1024
+	}
+	quantiles = append([]float64(nil), quantiles...)
+	sort.Float64s(quantiles)
+	return &SummaryVec{r.register(&vec{name: name, help: help, typ: typeSummary, labels: labels, quantiles: quantiles, window: window, series: make(map[string]*series)})}
+}
+
+// Observe 记录一个样本（窗口满后覆盖最早的样本）
+func (s *SummaryVec) Observe(value float64, labelValues ...string) {
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	ser := s.v.get(labelValues)
+	if len(ser.samples) < s.v.window {
+		ser.samples = append(ser.samples, value)
+	} else {
+		ser.samples[ser.next] = value
+		ser.next = (ser.next + 1) % s.v.window
+	}
+	ser.sum += value
+	ser.count++
+}
+
+// Quantile 当前窗口的分位数（无样本时为 NaN，测试用）
+func (s *SummaryVec) Quantile(q float64, labelValues ...string) float64 {
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	return windowQuantiles(s.v.get(labelValues).samples, []float64{q})[0]
+}
+
+// Count 样本数（测试用）
+func (s *SummaryVec) Count(labelValues ...string) uint64 {
+	s.v.mu.Lock()
+	defer s.v.mu.Unlock()
+	return s.v.get(labelValues).count
+}
+
+// windowQuantiles 计算样本的分位数（nearest-rank）
+func windowQuantiles(samples []float64, qs []float64) []float64 {
+	out := make([]float64, len(qs))
+	if len(samples) == 0 {
+		for i := range out {
+			out[i] = math.NaN()
+		}
+		return out
+	}
+	sorted := append([]float64(nil), samples...)
+	sort.Float64s(sorted)
+	for i, q := range qs {
+		rank := int(math.Ceil(q*float64(len(sorted)))) - 1
+		if rank < 0 {
+			rank = 0
+		}
+		if rank >= len(sorted) {
+			rank = len(sorted) - 1
+		}
+		out[i] = sorted[rank]
+	}
+	return out
+}
+
+// WriteText 以 Prometheus 文本格式输出全部指标
+func (r *Registry) WriteText(w io.Writer) error {
+	r.mu.Lock()
+	metrics := append([]*vec(nil), r.metrics...)
+	r.mu.Unlock()
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].name < metrics[j].name })
+
+	bw := bufio.NewWriter(w)
+	for _, v := range metrics {
+		v.write(bw)
+	}
+	return bw.Flush()
+}
+
+func (v *vec) write(w *bufio.Writer) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	fmt.Fprintf(w, "# HELP %s %s\n", v.name, escapeHelp(v.help))
+	fmt.Fprintf(w, "# TYPE %s %s\n", v.name, v.typ)
+
+	keys := make([]string, 0, len(v.series))
+	for k := range v.series {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		s := v.series[k]
+		labels := formatLabels(v.labels, s.labelValues, "", "")
+		if v.typ == typeSummary {
+			for i, value := range windowQuantiles(s.samples, v.quantiles) {
+				fmt.Fprintf(w, "%s%s %s\n", v.name, formatLabels(v.labels, s.labelValues, "quantile", formatValue(v.quantiles[i])), formatValue(value))
+			}
+			fmt.Fprintf(w, "%s_sum%s %s\n", v.name, labels, formatValue(s.sum))
+			fmt.Fprintf(w, "%s_count%s %d\n", v.name, labels, s.count)
+			continue
+		}
+		if v.typ != typeHistogram {
+			fmt.Fprintf(w, "%s%s %s\n", v.name, labels, formatValue(s.value))
+			continue
+		}
+		var cumulative uint64
+		for i, upper := range v.buckets {
+			cumulative += s.counts[i]
+			fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, formatLabels(v.labels, s.labelValues, "le", formatValue(upper)), cumulative)
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", v.name, formatLabels(v.labels, s.labelValues, "le", "+Inf"), s.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", v.name, labels, formatValue(s.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", v.name, labels, s.count)
+	}
+}
+
+// formatLabels 格式化标签（extraName 非空时追加一个标签，如直方图的 le）
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+	parts := make([]string, 0, len(names)+1)
+	for i, name := range names {
+		parts = append(parts, name+`="`+escapeLabel(values[i])+`"`)
+	}
+	if extraName != "" {
+		parts = append(parts, extraName+`="`+extraValue+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+var (
+	labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
+func escapeHelp(s string) string  { return helpEscaper.Replace(s) }
+
+// Handler 输出注册表内容的 HTTP 处理器
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		r.WriteText(w)
+	})
+}
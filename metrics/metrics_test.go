@@ -0,0 +1,187 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteText(t *testing.T) {
+	r := NewRegistry()
+	orders := r.NewCounterVec("test_orders_total", "Orders.", "action", "result")
+	equity := r.NewGaugeVec("test_equity", "Equity\nper trader.", "trader_id")
+	latency := r.NewHistogramVec("test_latency_seconds", "Latency.", []float64{1, 0.1}, "endpoint")
+
+	orders.Inc("open_long", "success")
+	orders.Add(2, "open_long", "success")
+	orders.Add(-5, "open_long", "success") // counter 不允许减少
+	orders.Inc("close_short", "failure")
+	equity.Set(1234.5, `a"b`)
+	latency.Observe(0.05, "get_balance")
+	latency.Observe(0.5, "get_balance")
+	latency.Observe(3, "get_balance")
+
+	var buf bytes.Buffer
+	if err := r.WriteText(&buf); err != nil {
+		t.Fatalf("WriteText: %v", err)
+	}
+	want := `# HELP test_equity Equity\nper trader.
+# TYPE test_equity gauge
+test_equity{trader_id="a\"b"} 1234.5
+# HELP test_latency_seconds Latency.
+# TYPE test_latency_seconds histogram
+test_latency_seconds_bucket{endpoint="get_balance",le="0.1"} 1
+test_latency_seconds_bucket{endpoint="get_balance",le="1"} 2
+test_latency_seconds_bucket{endpoint="get_balance",le="+Inf"} 3
+test_latency_seconds_sum{endpoint="get_balance"} 3.55
+test_latency_seconds_count{endpoint="get_balance"} 3
+# HELP test_orders_total Orders.
+# TYPE test_orders_total counter
+test_orders_total{action="close_short",result="failure"} 1
+test_orders_total{action="open_long",result="success"} 3
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+
+	equity.Delete(`a"b`)
+	buf.Reset()
+	r.WriteText(&buf)
+	if strings.Contains(buf.String(), "test_equity{") {
+		t.Error("deleted gauge series still exported")
+	}
+}
+
+func TestRegistryRejectsDuplicatesAndBadLabels(t *testing.T) {
+	r := NewRegistry()
+	c := r.NewCounterVec("dup_total", "x", "a")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on duplicate registration")
+			}
+		}()
+		r.NewGaugeVec("dup_total", "x")
+	}()
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic on label count mismatch")
+			}
+		}()
+		c.Inc("a", "b")
+	}()
+}
+
+func TestHandlerAndHelpers(t *testing.T) {
+	DataSourceCheck("binance", 150*time.Millisecond, nil)
+	DataSourceCheck("binance", time.Second, errors.New("timeout"))
+	CacheLookup("kline_disk", true)
+
+	if DataSourceLatency.Value("binance") != 0.15 || DataSourceHealthy.Value("binance") != 0 {
+		t.Errorf("failed check should keep last latency and mark unhealthy")
+	}
+
+	rec := httptest.NewRecorder()
+	Default.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("unexpected content type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), `nofx_cache_requests_total{cache="kline_disk",result="hit"}`) {
+		t.Errorf("cache metric missing:\n%s", rec.Body.String())
+	}
+}
+
+func TestSummaryQuantiles(t *testing.T) {
+	r := NewRegistry()
+	latency := r.NewSummaryVec("test_stage_seconds", "Stage latency.", nil, 100, "stage")
+	for i := 1; i <= 200; i++ {
+		latency.Observe(float64(i), "order") // 窗口只保留最近 100 个样本（101..200）
+	}
+
+	if got := latency.Quantile(0.5, "order"); got != 150 {
+		t.Errorf("p50 = %v, want 150", got)
+	}
+	if got := latency.Quantile(0.99, "order"); got != 199 {
+		t.Errorf("p99 = %v, want 199", got)
+	}
+	if latency.Count("order") != 200 {
+		t.Errorf("count = %d, want 200", latency.Count("order"))
+	}
+
+	var buf bytes.Buffer
+	r.WriteText(&buf)
+	want := `# HELP test_stage_seconds Stage latency.
+# TYPE test_stage_seconds summary
+test_stage_seconds{stage="order",quantile="0.5"} 150
+test_stage_seconds{stage="order",quantile="0.95"} 195
+test_stage_seconds{stage="order",quantile="0.99"} 199
+test_stage_seconds_sum{stage="order"} 20100
+test_stage_seconds_count{stage="order"} 200
+`
+	if buf.String() != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
+
+func TestLatencyBudgets(t *testing.T) {
+	t.Setenv("NOFX_LATENCY_BUDGETS", "decision=30s, order=500ms")
+	budgets, err := LatencyBudgetsFromEnv()
+	if err != nil {
+		t.Fatalf("LatencyBudgetsFromEnv: %v", err)
+	}
+	if budgets[StageDecision] != 30*time.Second || budgets[StageOrder] != 500*time.Millisecond {
+		t.Errorf("unexpected budgets: %v", budgets)
+	}
+	for _, raw := range []string{"order", "order=fast", "order=-1s"} {
+		t.Setenv("NOFX_LATENCY_BUDGETS", raw)
+		if _, err := LatencyBudgetsFromEnv(); err == nil {
+			t.Errorf("expected error for %q", raw)
+		}
+	}
+
+	SetLatencyBudgets(budgets)
+	defer SetLatencyBudgets(nil)
+	before := LatencyBudgetExceeded.Value(StageOrder)
+	if ObserveStage(StageOrder, 100*time.Millisecond) {
+		t.Error("within budget reported as exceeded")
+	}
+	if !ObserveStage(StageOrder, time.Second) {
+		t.Error("slow order not reported as exceeded")
+	}
+	if ObserveStage(StageIndicators, time.Hour) {
+		t.Error("stage without budget reported as exceeded")
+	}
+	if got := LatencyBudgetExceeded.Value(StageOrder) - before; got != 1 {
+		t.Errorf("exceeded counter delta = %v, want 1", got)
+	}
+}
+
+func BenchmarkSummaryObserve(b *testing.B) {
+	r := NewRegistry()
+	latency := r.NewSummaryVec("bench_stage_seconds", "Stage latency.", nil, 1024, "stage")
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		latency.Observe(float64(i%1000)/1000, StageOrder)
+	}
+}
+
+func BenchmarkSummaryWriteText(b *testing.B) {
+	r := NewRegistry()
+	latency := r.NewSummaryVec("bench_stage_seconds", "Stage latency.", nil, 1024, "stage")
+	for _, stage := range []string{StageDataFetch, StageIndicators, StageDecision, StageRisk, StageOrder} {
+		for i := 0; i < 1024; i++ {
+			latency.Observe(float64(i)/1000, stage)
+		}
+	}
+	var buf bytes.Buffer
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf.Reset()
+		r.WriteText(&buf)
+	}
+}
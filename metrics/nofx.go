@@ -0,0 +1,72 @@
+package metrics
+
+import "time"
+
+// 结果标签值
+const (
+	ResultSuccess = "success"
+	ResultFailure = "failure"
+	ResultHit     = "hit"
+	ResultMiss    = "miss"
+)
+
+var (
+	// ExchangeRequestDuration 交易所接口调用耗时（endpoint 为 Trader 方法，如 get_balance、open_long）
+	ExchangeRequestDuration = Default.NewHistogramVec("nofx_exchange_request_duration_seconds",
+		"Latency of exchange API calls by exchange and endpoint.", nil, "exchange", "endpoint")
+
+	// ExchangeRequestErrors 交易所接口调用失败次数
+	ExchangeRequestErrors = Default.NewCounterVec("nofx_exchange_request_errors_total",
+		"Failed exchange API calls by exchange and endpoint.", "exchange", "endpoint")
+
+	// Orders 下单结果计数（action: open_long / open_short / close_long / close_short / stop_loss / take_profit）
+	Orders = Default.NewCounterVec("nofx_orders_total",
+		"Orders sent to the exchange by action and result.", "exchange", "action", "result")
+
+	// RateLimited 触发交易所限流（429/418 或限流错误码）的次数，group 为接口组（如 okx.public、binance.trade）
+	RateLimited = Default.NewCounterVec("nofx_rate_limited_total",
+		"Responses rejected by exchange rate limits, by endpoint group.", "group")
+
+	// CacheRequests 缓存命中/未命中计数
+	CacheRequests = Default.NewCounterVec("nofx_cache_requests_total",
+		"Cache lookups by cache name and result (hit/miss).", "cache", "result")
+
+	// OpenPositions 当前持仓数量
+	OpenPositions = Default.NewGaugeVec("nofx_open_positions",
+		"Number of open positions per trader.", "trader_id")
+
+	// Equity 账户净值
+	Equity = Default.NewGaugeVec("nofx_equity",
+		"Account equity (wallet balance + unrealized PnL) per trader.", "trader_id")
+
+	// UnrealizedPnL 未实现盈亏
+	UnrealizedPnL = Default.NewGaugeVec("nofx_unrealized_pnl",
+		"Unrealized PnL of open positions per trader.", "trader_id")
+
+	// DataSourceLatency 行情数据源最近一次健康检查延迟
+	DataSourceLatency = Default.NewGaugeVec("nofx_datasource_latency_seconds",
+		"Latency of the last market data source health check.", "source")
+
+	// DataSourceHealthy 行情数据源最近一次健康检查是否成功（1/0）
+	DataSourceHealthy = Default.NewGaugeVec("nofx_datasource_healthy",
+		"Whether the last market data source health check succeeded (1) or failed (0).", "source")
+)
+
+// CacheLookup 记录一次缓存查询
+func CacheLookup(cache string, hit bool) {
+	if hit {
+		CacheRequests.Inc(cache, ResultHit)
+	} else {
+		CacheRequests.Inc(cache, ResultMiss)
+	}
+}
+
+// DataSourceCheck 记录一次数据源健康检查（失败时保留上次成功的延迟）
+func DataSourceCheck(source string, latency time.Duration, err error) {
+	if err != nil {
+		DataSourceHealthy.Set(0, source)
+		return
+	}
+	DataSourceHealthy.Set(1, source)
+	DataSourceLatency.Set(latency.Seconds(), source)
+}
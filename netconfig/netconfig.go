@@ -0,0 +1,154 @@
+// Package netconfig 交易所网络配置：HTTP(S)/SOCKS5 代理和按交易所覆盖的接口地址（区域镜像、OKX AWS 域名、公司代理）
+package netconfig
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"nofx/logging"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var log = logging.Module("netconfig")
+
+// Endpoints 支持覆盖地址的接口名称（"-ws" 为对应的 WebSocket 地址）
+var Endpoints = []string{
+	"binance", "binance-ws",
+	"okx", "okx-ws",
+	"bybit", "coinbase", "hyperliquid", "aster",
+}
+
+var (
+	mu        sync.RWMutex
+	proxyURL  *url.URL
+	overrides = map[string]*url.URL{}
+)
+
+// ConfigureProxy 设置交易所请求使用的代理（http://、https://、socks5://、socks5h://，空字符串恢复系统 HTTP(S)_PROXY 环境变量）
+// 同时作用于共享的 http.DefaultTransport 和各 WebSocket 连接
+func ConfigureProxy(raw string) error {
+	raw = strings.TrimSpace(raw)
+	var parsed *url.URL
+	if raw != "" {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("代理地址无效: %q", raw)
+		}
+		switch u.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return fmt.Errorf("不支持的代理协议 %q（支持 http、https、socks5、socks5h）", u.Scheme)
+		}
+		parsed = u
+	}
+
+	mu.Lock()
+	proxyURL = parsed
+	mu.Unlock()
+
+	if t, ok := http.DefaultTransport.(*http.Transport); ok {
+		t.Proxy = Proxy
+		t.CloseIdleConnections()
+	}
+	if parsed != nil {
+		log.Info("🌐 交易所请求代理", "proxy", parsed.Redacted())
+	}
+	return nil
+}
+
+// Proxy 请求使用的代理（可直接用作 http.Transport.Proxy / websocket.Dialer.Proxy）
+// 未配置时沿用 HTTP_PROXY/HTTPS_PROXY/NO_PROXY 环境变量
+func Proxy(req *http.Request) (*url.URL, error) {
+	mu.RLock()
+	u := proxyURL
+	mu.RUnlock()
+	if u != nil {
+		return u, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
+// ConfigureBaseURLs 解析接口地址覆盖，格式 "okx=https://aws.okx.com,okx-ws=wss://wsaws.okx.com:8443"
+// 覆盖只替换协议和主机（含端口），路径沿用默认地址；空字符串清除全部覆盖
+func ConfigureBaseURLs(raw string) error {
+	parsed := make(map[string]*url.URL)
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(item, "=")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !ok || name == "" {
+			return fmt.Errorf("接口地址配置格式错误: %q（应为 名称=地址）", item)
+		}
+		if !knownEndpoint(name) {
+			return fmt.Errorf("未知的接口名称 %q（支持 %s）", name, strings.Join(Endpoints, ", "))
+		}
+		u, err := url.Parse(strings.TrimSpace(value))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("%s 接口地址无效: %q", name, value)
+		}
+		parsed[name] = u
+	}
+
+	mu.Lock()
+	overrides = parsed
+	mu.Unlock()
+
+	names := make([]string, 0, len(parsed))
+	for name := range parsed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		log.Info("🌐 接口地址", "name", name, "url", parsed[name])
+	}
+	return nil
+}
+
+// BaseURL 返回接口地址：配置了覆盖时用覆盖的协议和主机替换 def 的协议和主机，否则原样返回 def
+func BaseURL(name, def string) string {
+	mu.RLock()
+	override := overrides[name]
+	mu.RUnlock()
+	if override == nil {
+		return def
+	}
+	u, err := url.Parse(def)
+	if err != nil {
+		return def
+	}
+	u.Scheme = override.Scheme
+	u.Host = override.Host
+	u.Path = strings.TrimSuffix(override.Path, "/") + u.Path
+	return u.String()
+}
+
+// ConfigureFromEnv 读取 NOFX_PROXY、NOFX_BASE_URLS 和连接池参数（NOFX_HTTP_*）
+func ConfigureFromEnv() error {
+	if err := ConfigureProxy(os.Getenv("NOFX_PROXY")); err != nil {
+		return fmt.Errorf("NOFX_PROXY 配置错误: %w", err)
+	}
+	if err := ConfigureBaseURLs(os.Getenv("NOFX_BASE_URLS")); err != nil {
+		return fmt.Errorf("NOFX_BASE_URLS 配置错误: %w", err)
+	}
+	cfg, err := transportConfigFromEnv()
+	if err != nil {
+		return err
+	}
+	ConfigureTransport(cfg)
+	return nil
+}
+
+func knownEndpoint(name string) bool {
+	for _, e := range Endpoints {
+		if e == name {
+			return true
+		}
+	}
+	return false
+}
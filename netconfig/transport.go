@@ -0,0 +1,130 @@
+package netconfig
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TransportConfig 交易所 HTTP 连接池参数（零值字段使用 DefaultTransportConfig 中的默认值）
+type TransportConfig struct {
+	MaxIdleConnsPerHost int           // 每个主机保持的空闲连接数（Go 默认只有 2 个，并发下单时会反复建连）
+	MaxConnsPerHost     int           // 每个主机的最大连接数（0=不限制）
+	IdleConnTimeout     time.Duration // 空闲连接保持时间
+	TLSSessionCacheSize int           // TLS 会话缓存条数（重连时复用会话，省去完整握手）
+}
+
+// DefaultTransportConfig 默认连接池参数
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConnsPerHost: 16,
+	IdleConnTimeout:     90 * time.Second,
+	TLSSessionCacheSize: 64,
+}
+
+var (
+	transportConfig = DefaultTransportConfig
+	transports      = map[string]*http.Transport{}
+)
+
+// ConfigureTransport 设置连接池参数（需在创建交易器和数据源之前调用，之后创建的客户端使用新参数）
+func ConfigureTransport(cfg TransportConfig) {
+	if cfg.MaxIdleConnsPerHost <= 0 {
+		cfg.MaxIdleConnsPerHost = DefaultTransportConfig.MaxIdleConnsPerHost
+	}
+	if cfg.MaxConnsPerHost < 0 {
+		cfg.MaxConnsPerHost = 0
+	}
+	if cfg.IdleConnTimeout <= 0 {
+		cfg.IdleConnTimeout = DefaultTransportConfig.IdleConnTimeout
+	}
+	if cfg.TLSSessionCacheSize <= 0 {
+		cfg.TLSSessionCacheSize = DefaultTransportConfig.TLSSessionCacheSize
+	}
+
+	mu.Lock()
+	old := transports
+	transportConfig = cfg
+	transports = map[string]*http.Transport{}
+	mu.Unlock()
+
+	for _, t := range old {
+		t.CloseIdleConnections()
+	}
+}
+
+// Transport 返回交易所共享的 HTTP Transport（同一交易所的交易器和数据源复用连接池和 TLS 会话）
+func Transport(exchange string) *http.Transport {
+	mu.RLock()
+	t := transports[exchange]
+	mu.RUnlock()
+	if t != nil {
+		return t
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if t := transports[exchange]; t != nil {
+		return t
+	}
+	t = newTransport(transportConfig)
+	transports[exchange] = t
+	return t
+}
+
+func newTransport(cfg TransportConfig) *http.Transport {
+	return &http.Transport{
+		Proxy: Proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true, // 自定义 TLSClientConfig 后需显式开启 HTTP/2
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig: &tls.Config{
+			ClientSessionCache: tls.NewLRUClientSessionCache(cfg.TLSSessionCacheSize),
+		},
+	}
+}
+
+// transportConfigFromEnv 读取 NOFX_HTTP_MAX_IDLE_CONNS_PER_HOST、NOFX_HTTP_MAX_CONNS_PER_HOST、
+// NOFX_HTTP_IDLE_CONN_TIMEOUT、NOFX_HTTP_TLS_SESSION_CACHE（未设置时使用默认值）
+func transportConfigFromEnv() (TransportConfig, error) {
+	var cfg TransportConfig
+	ints := []struct {
+		key string
+		dst *int
+	}{
+		{"NOFX_HTTP_MAX_IDLE_CONNS_PER_HOST", &cfg.MaxIdleConnsPerHost},
+		{"NOFX_HTTP_MAX_CONNS_PER_HOST", &cfg.MaxConnsPerHost},
+		{"NOFX_HTTP_TLS_SESSION_CACHE", &cfg.TLSSessionCacheSize},
+	}
+	for _, item := range ints {
+		v := strings.TrimSpace(os.Getenv(item.key))
+		if v == "" {
+			continue
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("%s=%q 无效（应为非负整数）", item.key, v)
+		}
+		*item.dst = n
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_HTTP_IDLE_CONN_TIMEOUT")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, fmt.Errorf("NOFX_HTTP_IDLE_CONN_TIMEOUT=%q 无效（如 90s、5m）", v)
+		}
+		cfg.IdleConnTimeout = d
+	}
+	return cfg, nil
+}
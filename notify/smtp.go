@@ -0,0 +1,160 @@
+// Package notify 邮件通知：SMTP 发送器和每日/每周交易汇总邮件
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SMTPConfig SMTP 发送配置
+type SMTPConfig struct {
+	Host     string
+	Port     int // 默认 587（STARTTLS）；465 使用 SMTPS（隐式 TLS）
+	Username string
+	Password string
+	From     string   // 发件人（空=Username）
+	To       []string // 收件人
+}
+
+// SMTPConfigFromEnv 从环境变量读取 SMTP 配置（未设置 NOFX_SMTP_HOST 时返回 nil）
+func SMTPConfigFromEnv() (*SMTPConfig, error) {
+	host := strings.TrimSpace(os.Getenv("NOFX_SMTP_HOST"))
+	if host == "" {
+		return nil, nil
+	}
+	cfg := &SMTPConfig{
+		Host:     host,
+		Port:     587,
+		Username: strings.TrimSpace(os.Getenv("NOFX_SMTP_USERNAME")),
+		Password: os.Getenv("NOFX_SMTP_PASSWORD"),
+		From:     strings.TrimSpace(os.Getenv("NOFX_SMTP_FROM")),
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SMTP_PORT")); v != "" {
+		port, err := strconv.Atoi(v)
+		if err != nil || port <= 0 {
+			return nil, fmt.Errorf("无效的 NOFX_SMTP_PORT: %q", v)
+		}
+		cfg.Port = port
+	}
+	for _, to := range strings.Split(os.Getenv("NOFX_SMTP_TO"), ",") {
+		if to = strings.TrimSpace(to); to != "" {
+			cfg.To = append(cfg.To, to)
+		}
+	}
+	if cfg.From == "" {
+		cfg.From = cfg.Username
+	}
+	if cfg.From == "" {
+		return nil, fmt.Errorf("需要设置 NOFX_SMTP_FROM 或 NOFX_SMTP_USERNAME")
+	}
+	if len(cfg.To) == 0 {
+		return nil, fmt.Errorf("需要设置 NOFX_SMTP_TO（逗号分隔的收件人）")
+	}
+	return cfg, nil
+}
+
+// sendMail 投递邮件（测试时替换）
+var sendMail = deliver
+
+// SMTPNotifier 通过 SMTP 发送纯文本邮件
+type SMTPNotifier struct {
+	config SMTPConfig
+}
+
+// NewSMTPNotifier 创建 SMTP 发送器
+func NewSMTPNotifier(config SMTPConfig) *SMTPNotifier {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	if config.From == "" {
+		config.From = config.Username
+	}
+	return &SMTPNotifier{config: config}
+}
+
+// Send 发送邮件给所有收件人
+func (n *SMTPNotifier) Send(subject, body string) error {
+	if len(n.config.To) == 0 {
+		return fmt.Errorf("没有配置收件人")
+	}
+	if err := sendMail(n.config, buildMessage(n.config.From, n.config.To, subject, body, time.Now())); err != nil {
+		return fmt.Errorf("发送邮件失败: %w", err)
+	}
+	return nil
+}
+
+// buildMessage 生成 RFC 5322 邮件（UTF-8 正文 base64 编码）
+func buildMessage(from string, to []string, subject, body string, date time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "From: %s\r\n", from)
+	fmt.Fprintf(&buf, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&buf, "Subject: %s\r\n", mime.BEncoding.Encode("UTF-8", subject))
+	fmt.Fprintf(&buf, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString([]byte(body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+	return buf.Bytes()
+}
+
+// deliver 连接 SMTP 服务器投递邮件（465 端口使用隐式 TLS，其他端口由 smtp.SendMail 自动 STARTTLS）
+func deliver(cfg SMTPConfig, msg []byte) error {
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if cfg.Port != 465 {
+		return smtp.SendMail(addr, auth, cfg.From, cfg.To, msg)
+	}
+
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: 30 * time.Second}, "tcp", addr, &tls.Config{ServerName: cfg.Host})
+	if err != nil {
+		return err
+	}
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer c.Close()
+	if auth != nil {
+		if err := c.Auth(auth); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(cfg.From); err != nil {
+		return err
+	}
+	for _, to := range cfg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,83 @@
+package notify
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSMTPConfigFromEnv(t *testing.T) {
+	t.Setenv("NOFX_SMTP_HOST", "")
+	if cfg, err := SMTPConfigFromEnv(); cfg != nil || err != nil {
+		t.Errorf("expected disabled config, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv("NOFX_SMTP_HOST", "smtp.example.com")
+	t.Setenv("NOFX_SMTP_USERNAME", "bot@example.com")
+	t.Setenv("NOFX_SMTP_TO", "a@example.com, b@example.com")
+	cfg, err := SMTPConfigFromEnv()
+	if err != nil {
+		t.Fatalf("SMTPConfigFromEnv: %v", err)
+	}
+	if cfg.Port != 587 || cfg.From != "bot@example.com" || len(cfg.To) != 2 || cfg.To[1] != "b@example.com" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	t.Setenv("NOFX_SMTP_PORT", "abc")
+	if _, err := SMTPConfigFromEnv(); err == nil {
+		t.Error("expected error for invalid port")
+	}
+	t.Setenv("NOFX_SMTP_PORT", "465")
+	t.Setenv("NOFX_SMTP_TO", "")
+	if _, err := SMTPConfigFromEnv(); err == nil {
+		t.Error("expected error without recipients")
+	}
+}
+
+func TestSMTPNotifierSend(t *testing.T) {
+	orig := sendMail
+	defer func() { sendMail = orig }()
+
+	var gotCfg SMTPConfig
+	var gotMsg string
+	sendMail = func(cfg SMTPConfig, msg []byte) error {
+		gotCfg, gotMsg = cfg, string(msg)
+		return nil
+	}
+
+	n := NewSMTPNotifier(SMTPConfig{Host: "smtp.example.com", Username: "bot@example.com", To: []string{"a@example.com"}})
+	body := strings.Repeat("净盈亏 +12.34 USDT\n", 10)
+	if err := n.Send("每日交易汇总", body); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if gotCfg.Port != 587 || gotCfg.From != "bot@example.com" {
+		t.Errorf("defaults not applied: %+v", gotCfg)
+	}
+	header, encoded, ok := strings.Cut(gotMsg, "\r\n\r\n")
+	if !ok || !strings.Contains(header, "Subject: =?UTF-8?b?") || !strings.Contains(header, "To: a@example.com") {
+		t.Fatalf("unexpected headers:\n%s", header)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(encoded, "\r\n", ""))
+	if err != nil || string(decoded) != body {
+		t.Errorf("body round trip failed: %v", err)
+	}
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("body line longer than 76 chars: %d", len(line))
+		}
+	}
+
+	sendMail = func(SMTPConfig, []byte) error { return errors.New("connection refused") }
+	if err := n.Send("x", "y"); err == nil || !strings.Contains(err.Error(), "connection refused") {
+		t.Errorf("expected delivery error, got %v", err)
+	}
+}
+
+func TestBuildMessageDate(t *testing.T) {
+	msg := string(buildMessage("a@x", []string{"b@x"}, "s", "b", time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)))
+	if !strings.Contains(msg, "Date: Fri, 02 Jan 2026 03:04:05 +0000\r\n") {
+		t.Errorf("missing date header:\n%s", msg)
+	}
+}
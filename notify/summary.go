@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"fmt"
+	"log"
+	"nofx/analytics"
+	"nofx/store"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 汇总周期
+const (
+	PeriodDaily  = "daily"
+	PeriodWeekly = "weekly" // 每周一发送上一周的汇总
+)
+
+// Mailer 邮件发送接口（*SMTPNotifier 实现）
+type Mailer interface {
+	Send(subject, body string) error
+}
+
+// SummaryConfig 汇总邮件配置
+type SummaryConfig struct {
+	Period string // daily / weekly（空=daily）
+	Hour   int    // 发送时间（本地时间 0-23 点，默认 0 点，即汇总完整的前一天/前一周）
+}
+
+// SummaryConfigFromEnv 从 NOFX_SUMMARY_PERIOD / NOFX_SUMMARY_HOUR 读取配置
+func SummaryConfigFromEnv() (SummaryConfig, error) {
+	cfg := SummaryConfig{Period: strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_SUMMARY_PERIOD")))}
+	switch cfg.Period {
+	case "":
+		cfg.Period = PeriodDaily
+	case PeriodDaily, PeriodWeekly:
+	default:
+		return cfg, fmt.Errorf("无效的 NOFX_SUMMARY_PERIOD: %q（可选: daily, weekly）", cfg.Period)
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SUMMARY_HOUR")); v != "" {
+		hour, err := strconv.Atoi(v)
+		if err != nil || hour < 0 || hour > 23 {
+			return cfg, fmt.Errorf("无效的 NOFX_SUMMARY_HOUR: %q（0-23）", v)
+		}
+		cfg.Hour = hour
+	}
+	return cfg, nil
+}
+
+// nextRun now 之后的下一个发送时间
+func (c SummaryConfig) nextRun(now time.Time) time.Time {
+	next := time.Date(now.Year(), now.Month(), now.Day(), c.Hour, 0, 0, 0, now.Location())
+	if c.Period == PeriodWeekly {
+		// 本周一
+		next = next.AddDate(0, 0, -((int(next.Weekday()) + 6) % 7))
+		for !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	}
+	for !next.After(now) {
+		next = next.AddDate(0, 0, 1)
+	}
+	return next
+}
+
+// windowStart 在 runAt 发送的汇总覆盖区间 [windowStart, runAt) 的起点
+func (c SummaryConfig) windowStart(runAt time.Time) time.Time {
+	if c.Period == PeriodWeekly {
+		return runAt.AddDate(0, 0, -7)
+	}
+	return runAt.AddDate(0, 0, -1)
+}
+
+// SummaryScheduler 定时从事件日志生成交易汇总并发送邮件
+type SummaryScheduler struct {
+	mailer  Mailer
+	journal *store.Journal
+	config  SummaryConfig
+
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewSummaryScheduler 创建汇总邮件调度器
+func NewSummaryScheduler(mailer Mailer, journal *store.Journal, config SummaryConfig) *SummaryScheduler {
+	if config.Period == "" {
+		config.Period = PeriodDaily
+	}
+	return &SummaryScheduler{mailer: mailer, journal: journal, config: config, stop: make(chan struct{})}
+}
+
+// Start 按周期发送汇总（阻塞直到 Stop）
+func (s *SummaryScheduler) Start() {
+	for {
+		next := s.config.nextRun(time.Now())
+		log.Printf("📧 下一次交易汇总邮件: %s", next.Format("2006-01-02 15:04"))
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-s.stop:
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := s.Send(s.config.windowStart(next), next); err != nil {
+			log.Printf("❌ 发送交易汇总邮件失败: %v", err)
+		}
+	}
+}
+
+// Stop 停止调度
+func (s *SummaryScheduler) Stop() {
+	s.once.Do(func() { close(s.stop) })
+}
+
+// Send 生成 [from, until) 的汇总并发送
+func (s *SummaryScheduler) Send(from, until time.Time) error {
+	traderIDs, err := s.journal.TraderIDs()
+	if err != nil {
+		return err
+	}
+	summaries := make([]*analytics.Summary, 0, len(traderIDs))
+	for _, id := range traderIDs {
+		summary, err := analytics.SummaryFromJournal(s.journal, id, from, until)
+		if err != nil {
+			return fmt.Errorf("生成交易员 %s 的汇总失败: %w", id, err)
+		}
+		summaries = append(summaries, summary)
+	}
+	subject, body := RenderSummary(s.config.Period, from, until, summaries)
+	if err := s.mailer.Send(subject, body); err != nil {
+		return err
+	}
+	log.Printf("📧 已发送交易汇总邮件: %s", subject)
+	return nil
+}
+
+// RenderSummary 生成汇总邮件的标题和正文
+func RenderSummary(period string, from, until time.Time, summaries []*analytics.Summary) (string, string) {
+	title := "每日交易汇总"
+	dateRange := from.Format("2006-01-02")
+	if period == PeriodWeekly {
+		title = "每周交易汇总"
+		dateRange += " ~ " + until.Add(-time.Second).Format("2006-01-02")
+	}
+
+	var totalPnL, totalFees float64
+	var totalTrades, totalRisk int
+	for _, s := range summaries {
+		totalPnL += s.Stats.NetPnL
+		totalFees += s.Stats.TotalFees
+		totalTrades += s.Stats.Trades
+		totalRisk += len(s.RiskEvents)
+	}
+	subject := fmt.Sprintf("[NOFX] %s %s: 净盈亏 %+.2f USDT，%d 笔交易", title, dateRange, totalPnL, totalTrades)
+	if totalRisk > 0 {
+		subject += fmt.Sprintf("，%d 条风控事件", totalRisk)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "NOFX %s\n", title)
+	fmt.Fprintf(&b, "统计区间: %s ~ %s\n", from.Format("2006-01-02 15:04"), until.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "合计: 净盈亏 %+.2f USDT，手续费 %.2f USDT，%d 笔交易，%d 条风控事件\n", totalPnL, totalFees, totalTrades, totalRisk)
+	if len(summaries) == 0 {
+		b.WriteString("\n没有交易员的事件记录。\n")
+	}
+
+	for _, s := range summaries {
+		st := s.Stats
+		fmt.Fprintf(&b, "\n==== 交易员 %s ====\n", s.TraderID)
+		fmt.Fprintf(&b, "交易笔数: %d（盈利 %d / 亏损 %d，胜率 %.1f%%）\n", st.Trades, st.Wins, st.Losses, st.WinRate)
+		fmt.Fprintf(&b, "净盈亏: %+.2f USDT（毛盈亏 %+.2f，手续费 %.2f）\n", st.NetPnL, st.GrossPnL, st.TotalFees)
+		if st.Trades > 0 {
+			fmt.Fprintf(&b, "最大盈利: %+.2f，最大亏损: %+.2f，盈亏比: %.2f\n", st.LargestWin, st.LargestLoss, st.ProfitFactor)
+		}
+		if s.Incomplete > 0 {
+			fmt.Fprintf(&b, "⚠️ %d 笔交易平仓价格未知，未计入统计\n", s.Incomplete)
+		}
+
+		b.WriteString("\n交易明细:\n")
+		if len(s.Trades) == 0 {
+			b.WriteString("  （无）\n")
+		}
+		for _, t := range s.Trades {
+			fmt.Fprintf(&b, "  %s %-5s %s @ %.4f → %s @ %.4f  数量 %.4f  手续费 %.2f  净盈亏 %+.2f\n",
+				t.Symbol, strings.ToUpper(t.Side),
+				t.OpenedAt.Format("01-02 15:04"), t.EntryPrice,
+				t.ClosedAt.Format("01-02 15:04"), t.ExitPrice,
+				t.Quantity, t.Fees, t.NetPnL)
+		}
+
+		b.WriteString("\n风控事件:\n")
+		if len(s.RiskEvents) == 0 {
+			b.WriteString("  （无）\n")
+		}
+		for _, e := range s.RiskEvents {
+			fmt.Fprintf(&b, "  %s [%s] %s: %s\n", e.Time.Format("01-02 15:04"), e.Severity, e.Title, e.Message)
+		}
+	}
+	return subject, b.String()
+}
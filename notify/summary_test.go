@@ -0,0 +1,126 @@
+package notify
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/analytics"
+	"nofx/store"
+)
+
+func TestSummaryNextRun(t *testing.T) {
+	loc := time.UTC
+	// 2026-10-14 是周三
+	now := time.Date(2026, 10, 14, 10, 30, 0, 0, loc)
+
+	daily := SummaryConfig{Period: PeriodDaily, Hour: 8}
+	if got := daily.nextRun(now); !got.Equal(time.Date(2026, 10, 15, 8, 0, 0, 0, loc)) {
+		t.Errorf("daily nextRun = %v", got)
+	}
+	if got := (SummaryConfig{Period: PeriodDaily, Hour: 12}).nextRun(now); !got.Equal(time.Date(2026, 10, 14, 12, 0, 0, 0, loc)) {
+		t.Errorf("daily nextRun later today = %v", got)
+	}
+	if got := daily.windowStart(time.Date(2026, 10, 15, 8, 0, 0, 0, loc)); !got.Equal(time.Date(2026, 10, 14, 8, 0, 0, 0, loc)) {
+		t.Errorf("daily window = %v", got)
+	}
+
+	weekly := SummaryConfig{Period: PeriodWeekly}
+	next := weekly.nextRun(now)
+	if !next.Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, loc)) || next.Weekday() != time.Monday {
+		t.Errorf("weekly nextRun = %v", next)
+	}
+	if got := weekly.windowStart(next); !got.Equal(time.Date(2026, 10, 12, 0, 0, 0, 0, loc)) {
+		t.Errorf("weekly window = %v", got)
+	}
+}
+
+func TestSummaryConfigFromEnv(t *testing.T) {
+	t.Setenv("NOFX_SUMMARY_PERIOD", "")
+	t.Setenv("NOFX_SUMMARY_HOUR", "")
+	if cfg, err := SummaryConfigFromEnv(); err != nil || cfg.Period != PeriodDaily || cfg.Hour != 0 {
+		t.Errorf("defaults = %+v, %v", cfg, err)
+	}
+	t.Setenv("NOFX_SUMMARY_PERIOD", "Weekly")
+	t.Setenv("NOFX_SUMMARY_HOUR", "9")
+	if cfg, err := SummaryConfigFromEnv(); err != nil || cfg.Period != PeriodWeekly || cfg.Hour != 9 {
+		t.Errorf("weekly = %+v, %v", cfg, err)
+	}
+	t.Setenv("NOFX_SUMMARY_HOUR", "24")
+	if _, err := SummaryConfigFromEnv(); err == nil {
+		t.Error("expected error for hour 24")
+	}
+	t.Setenv("NOFX_SUMMARY_HOUR", "")
+	t.Setenv("NOFX_SUMMARY_PERIOD", "monthly")
+	if _, err := SummaryConfigFromEnv(); err == nil {
+		t.Error("expected error for unknown period")
+	}
+}
+
+func TestRenderSummary(t *testing.T) {
+	from := time.Date(2026, 10, 13, 0, 0, 0, 0, time.UTC)
+	until := from.AddDate(0, 0, 1)
+	summaries := []*analytics.Summary{{
+		TraderID: "t1",
+		Stats:    analytics.Stats{Trades: 1, Wins: 1, WinRate: 100, NetPnL: 9, GrossPnL: 10, TotalFees: 1, LargestWin: 9},
+		Trades: []analytics.Trade{{
+			Symbol: "BTCUSDT", Side: "long", Quantity: 1, EntryPrice: 100, ExitPrice: 110, Fees: 1, NetPnL: 9,
+			OpenedAt: from.Add(8 * time.Hour), ClosedAt: from.Add(12 * time.Hour),
+		}},
+		RiskEvents: []analytics.RiskEvent{{Time: from.Add(9 * time.Hour), Severity: "WARNING", Title: "触发风险暂停", Message: "触发当日最大亏损"}},
+	}}
+
+	subject, body := RenderSummary(PeriodDaily, from, until, summaries)
+	if subject != "[NOFX] 每日交易汇总 2026-10-13: 净盈亏 +9.00 USDT，1 笔交易，1 条风控事件" {
+		t.Errorf("subject = %q", subject)
+	}
+	for _, want := range []string{
+		"==== 交易员 t1 ====",
+		"净盈亏: +9.00 USDT（毛盈亏 +10.00，手续费 1.00）",
+		"BTCUSDT LONG  10-13 08:00 @ 100.0000 → 10-13 12:00 @ 110.0000",
+		"10-13 09:00 [WARNING] 触发风险暂停: 触发当日最大亏损",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %q:\n%s", want, body)
+		}
+	}
+
+	weeklySubject, _ := RenderSummary(PeriodWeekly, from, from.AddDate(0, 0, 7), nil)
+	if !strings.Contains(weeklySubject, "每周交易汇总 2026-10-13 ~ 2026-10-19") {
+		t.Errorf("weekly subject = %q", weeklySubject)
+	}
+}
+
+type fakeMailer struct {
+	subject, body string
+}
+
+func (m *fakeMailer) Send(subject, body string) error {
+	m.subject, m.body = subject, body
+	return nil
+}
+
+func TestSummarySchedulerSend(t *testing.T) {
+	j, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+	j.Record("t1", store.EventFill, "ETHUSDT", "short", store.Fill{Action: "open", Quantity: 2, Price: 50})
+	j.Record("t1", store.EventFill, "ETHUSDT", "short", store.Fill{Action: "close", Quantity: 2, Price: 55})
+	j.Record("t2", store.EventRisk, "", "", store.RiskEvent{Severity: "CRITICAL", Title: "紧急停止", Message: "控制接口紧急停止"})
+
+	mailer := &fakeMailer{}
+	s := NewSummaryScheduler(mailer, j, SummaryConfig{})
+	now := time.Now()
+	if err := s.Send(now.Add(-time.Hour), now.Add(time.Hour)); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	if !strings.Contains(mailer.subject, "净盈亏 -10.00 USDT，1 笔交易，1 条风控事件") {
+		t.Errorf("subject = %q", mailer.subject)
+	}
+	if !strings.Contains(mailer.body, "==== 交易员 t2 ====") || !strings.Contains(mailer.body, "[CRITICAL] 紧急停止") {
+		t.Errorf("body:\n%s", mailer.body)
+	}
+}
@@ -0,0 +1,252 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 测试中替换
+var (
+	now   = time.Now
+	sleep = sleepContext
+)
+
+// Limit 每 Per 时间内最多 Requests 次请求（Requests <= 0 表示不限流）
+type Limit struct {
+	Requests int
+	Per      time.Duration
+}
+
+func (l Limit) String() string {
+	if l.Requests <= 0 || l.Per <= 0 {
+		return "off"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Per)
+}
+
+// ParseLimit 解析 "20/2s"、"1200/1m" 形式的限额（"0" 或 "off" 表示不限流）
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || strings.EqualFold(s, "off") {
+		return Limit{}, nil
+	}
+	countStr, perStr, ok := strings.Cut(s, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("无效的限流配置: %q（应为 次数/时间，如 20/2s）", s)
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(countStr))
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("无效的限流次数: %q", countStr)
+	}
+	perStr = strings.TrimSpace(perStr)
+	if perStr != "" && (perStr[0] < '0' || perStr[0] > '9') {
+		perStr = "1" + perStr // 允许 "10/s" 这种写法
+	}
+	per, err := time.ParseDuration(perStr)
+	if err != nil || per <= 0 {
+		return Limit{}, fmt.Errorf("无效的限流时间窗口: %q", perStr)
+	}
+	return Limit{Requests: n, Per: per}, nil
+}
+
+// DefaultLimits 各交易所接口组的默认限额（低于交易所公布的上限，留出余量）
+//
+//	okx.public  行情接口 20 次/2s（按 IP）
+//	okx.private 账户接口 10 次/2s
+//	okx.trade   下单/撤单 60 次/2s
+//	binance.*   权重上限 2400/min，订单上限 300/10s，按请求次数粗略换算
+//	aster.*     与币安接口一致
+//	bybit.public 行情接口 600 次/5s（按 IP），取 20 次/s
+var DefaultLimits = map[string]Limit{
+	"okx.public":      {Requests: 20, Per: 2 * time.Second},
+	"okx.private":     {Requests: 10, Per: 2 * time.Second},
+	"okx.trade":       {Requests: 60, Per: 2 * time.Second},
+	"binance.public":  {Requests: 20, Per: time.Second},
+	"binance.private": {Requests: 10, Per: time.Second},
+	"binance.trade":   {Requests: 20, Per: time.Second},
+	"aster.public":    {Requests: 20, Per: time.Second},
+	"aster.private":   {Requests: 10, Per: time.Second},
+	"aster.trade":     {Requests: 20, Per: time.Second},
+	"bybit.public":    {Requests: 20, Per: time.Second},
+}
+
+// LimitsFromEnv 读取 NOFX_RATE_LIMITS 覆盖默认限额：
+//
+//	NOFX_RATE_LIMITS=okx.public=10/2s,binance.trade=off
+//
+// 未列出的组使用 DefaultLimits；NOFX_RATE_LIMITS=off 关闭全部限流
+func LimitsFromEnv() (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(DefaultLimits))
+	for group, l := range DefaultLimits {
+		limits[group] = l
+	}
+	v := strings.TrimSpace(os.Getenv("NOFX_RATE_LIMITS"))
+	if v == "" {
+		return limits, nil
+	}
+	if strings.EqualFold(v, "off") {
+		return map[string]Limit{}, nil
+	}
+	for _, item := range strings.Split(v, ",") {
+		group, limitStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return nil, fmt.Errorf("NOFX_RATE_LIMITS 格式错误: %q（应为 group=次数/时间）", item)
+		}
+		l, err := ParseLimit(limitStr)
+		if err != nil {
+			return nil, fmt.Errorf("NOFX_RATE_LIMITS %s: %w", group, err)
+		}
+		limits[strings.TrimSpace(group)] = l
+	}
+	return limits, nil
+}
+
+// Bucket 令牌桶：容量为 Requests，每 Per/Requests 补充一个令牌；
+// 收到 429 / Retry-After 时整个桶暂停到指定时间
+type Bucket struct {
+	mu           sync.Mutex
+	limit        Limit
+	tokens       float64
+	last         time.Time
+	blockedUntil time.Time
+}
+
+// NewBucket 创建令牌桶（初始为满）
+func NewBucket(l Limit) *Bucket {
+	return &Bucket{limit: l, tokens: float64(l.Requests), last: now()}
+}
+
+// Wait 取一个令牌，没有可用令牌时阻塞（ctx 取消时返回错误）
+func (b *Bucket) Wait(ctx context.Context) error {
+	if d := b.reserve(); d > 0 {
+		return sleep(ctx, d)
+	}
+	return nil
+}
+
+// reserve 预留一个令牌，返回需要等待的时间
+func (b *Bucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t := now()
+	var wait time.Duration
+	if t.Before(b.blockedUntil) {
+		wait = b.blockedUntil.Sub(t)
+	}
+	if b.limit.Requests <= 0 || b.limit.Per <= 0 {
+		return wait
+	}
+
+	rate := float64(b.limit.Requests) / b.limit.Per.Seconds() // 每秒补充的令牌数
+	b.tokens = min(float64(b.limit.Requests), b.tokens+t.Sub(b.last).Seconds()*rate)
+	b.last = t
+	b.tokens--
+	if b.tokens < 0 {
+		// 令牌可以透支，等待时间随排队请求数增加
+		wait = max(wait, time.Duration(-b.tokens/rate*float64(time.Second)))
+	}
+	return wait
+}
+
+// Penalize 在 until 之前暂停该组的全部请求（处理 429 / Retry-After）
+func (b *Bucket) Penalize(until time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if until.After(b.blockedUntil) {
+		b.blockedUntil = until
+	}
+	// 清空令牌，暂停结束后按速率重新放行，避免解封瞬间再次突发
+	b.tokens = 0
+	if until.After(b.last) {
+		b.last = until
+	}
+}
+
+// Limiter 按接口组管理令牌桶（组未配置限额时不限流，但仍会响应 Retry-After）
+type Limiter struct {
+	mu      sync.Mutex
+	limits  map[string]Limit
+	buckets map[string]*Bucket
+}
+
+// New 创建限流器
+func New(limits map[string]Limit) *Limiter {
+	copied := make(map[string]Limit, len(limits))
+	for group, l := range limits {
+		copied[group] = l
+	}
+	return &Limiter{limits: copied, buckets: make(map[string]*Bucket)}
+}
+
+// Default 进程共享的限流器（同一交易所的所有交易员共享同一组令牌桶，与交易所按 IP/账户限流一致）
+var Default = New(DefaultLimits)
+
+// Configure 替换 Default 的限额（已创建的令牌桶按新限额重建）
+func Configure(limits map[string]Limit) {
+	Default.SetLimits(limits)
+}
+
+// SetLimits 替换限额
+func (l *Limiter) SetLimits(limits map[string]Limit) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limits = make(map[string]Limit, len(limits))
+	for group, limit := range limits {
+		l.limits[group] = limit
+	}
+	l.buckets = make(map[string]*Bucket)
+}
+
+// Bucket 获取接口组的令牌桶
+func (l *Limiter) Bucket(group string) *Bucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[group]
+	if !ok {
+		b = NewBucket(l.limits[group])
+		l.buckets[group] = b
+	}
+	return b
+}
+
+// Wait 在接口组上取一个令牌
+func (l *Limiter) Wait(ctx context.Context, group string) error {
+	return l.Bucket(group).Wait(ctx)
+}
+
+// Describe 当前生效的限额（用于启动日志）
+func (l *Limiter) Describe() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	groups := make([]string, 0, len(l.limits))
+	for group := range l.limits {
+		groups = append(groups, group)
+	}
+	if len(groups) == 0 {
+		return "off"
+	}
+	sort.Strings(groups)
+	parts := make([]string, 0, len(groups))
+	for _, group := range groups {
+		parts = append(parts, group+"="+l.limits[group].String())
+	}
+	return strings.Join(parts, ",")
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
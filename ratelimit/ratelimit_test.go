@@ -0,0 +1,117 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeClock 替换 now/sleep：sleep 直接推进时钟并记录等待时间
+func fakeClock(t *testing.T) *[]time.Duration {
+	t.Helper()
+	current := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	var waits []time.Duration
+	origNow, origSleep := now, sleep
+	now = func() time.Time { return current }
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		current = current.Add(d)
+		return nil
+	}
+	t.Cleanup(func() { now, sleep = origNow, origSleep })
+	return &waits
+}
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Limit
+	}{
+		{"20/2s", Limit{Requests: 20, Per: 2 * time.Second}},
+		{"1200/1m", Limit{Requests: 1200, Per: time.Minute}},
+		{"10/s", Limit{Requests: 10, Per: time.Second}},
+		{"off", Limit{}},
+		{"0", Limit{}},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLimit(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"20", "x/2s", "20/abc", "20/-1s"} {
+		if _, err := ParseLimit(bad); err == nil {
+			t.Errorf("ParseLimit(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestLimitsFromEnv(t *testing.T) {
+	t.Setenv("NOFX_RATE_LIMITS", "okx.public=5/1s, binance.trade=off")
+	limits, err := LimitsFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limits["okx.public"] != (Limit{Requests: 5, Per: time.Second}) {
+		t.Errorf("okx.public = %v", limits["okx.public"])
+	}
+	if limits["binance.trade"].Requests != 0 {
+		t.Errorf("binance.trade 应关闭限流, got %v", limits["binance.trade"])
+	}
+	if limits["okx.trade"] != DefaultLimits["okx.trade"] {
+		t.Errorf("未配置的组应使用默认值, got %v", limits["okx.trade"])
+	}
+
+	t.Setenv("NOFX_RATE_LIMITS", "off")
+	if limits, _ := LimitsFromEnv(); len(limits) != 0 {
+		t.Errorf("off 应关闭全部限流, got %v", limits)
+	}
+
+	t.Setenv("NOFX_RATE_LIMITS", "okx.public")
+	if _, err := LimitsFromEnv(); err == nil {
+		t.Error("格式错误应返回错误")
+	}
+}
+
+func TestBucketWait(t *testing.T) {
+	waits := fakeClock(t)
+	b := NewBucket(Limit{Requests: 2, Per: time.Second})
+	ctx := context.Background()
+
+	// 前两次使用初始令牌，之后每 500ms 放行一次
+	for i := 0; i < 4; i++ {
+		if err := b.Wait(ctx); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(*waits) != 2 || (*waits)[0] != 500*time.Millisecond || (*waits)[1] != 500*time.Millisecond {
+		t.Errorf("waits = %v, want [500ms 500ms]", *waits)
+	}
+}
+
+func TestBucketPenalize(t *testing.T) {
+	waits := fakeClock(t)
+	b := NewBucket(Limit{})
+	b.Penalize(now().Add(3 * time.Second))
+	b.Penalize(now().Add(time.Second)) // 不会缩短已有的暂停
+
+	if err := b.Wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(*waits) != 1 || (*waits)[0] != 3*time.Second {
+		t.Errorf("waits = %v, want [3s]", *waits)
+	}
+	if err := b.Wait(context.Background()); err != nil || len(*waits) != 1 {
+		t.Errorf("暂停结束后不应再等待, waits = %v", *waits)
+	}
+}
+
+func TestBucketWaitCanceled(t *testing.T) {
+	b := NewBucket(Limit{Requests: 1, Per: time.Hour})
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	b.Wait(ctx)
+	if err := b.Wait(ctx); err != context.Canceled {
+		t.Errorf("Wait() = %v, want context.Canceled", err)
+	}
+}
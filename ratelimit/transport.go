@@ -0,0 +1,206 @@
+package ratelimit
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"nofx/logging"
+	"nofx/metrics"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var log = logging.Module("ratelimit")
+
+const (
+	defaultMaxRetries = 3
+	defaultBackoff    = time.Second
+	maxBackoff        = 30 * time.Second
+	peekSize          = 64 // 只检查响应体开头的错误码
+)
+
+// GroupFunc 将请求映射到接口组（返回空字符串表示不限流）
+type GroupFunc func(req *http.Request) string
+
+// Transport 按接口组限流的 http.RoundTripper：发送前取令牌，
+// 遇到 429/418 或 OKX 限流错误码（50011/50061）时按 Retry-After 暂停整个组并重试
+type Transport struct {
+	Base       http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Group      GroupFunc
+	Limiter    *Limiter // 为 nil 时使用 Default
+	MaxRetries int      // 被限流后的最大重试次数（0 使用默认值 3，<0 不重试）
+}
+
+// NewTransport 创建限流 Transport
+func NewTransport(base http.RoundTripper, group GroupFunc) *Transport {
+	return &Transport{Base: base, Group: group}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	group := ""
+	if t.Group != nil {
+		group = t.Group(req)
+	}
+	if group == "" {
+		return t.base().RoundTrip(req)
+	}
+	bucket := t.limiter().Bucket(group)
+
+	for attempt := 0; ; attempt++ {
+		if err := bucket.Wait(req.Context()); err != nil {
+			return nil, err
+		}
+		r := req
+		if attempt > 0 {
+			var err error
+			if r, err = rewind(req); err != nil {
+				return nil, err
+			}
+		}
+		resp, err := t.base().RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		limited, err := isRateLimited(resp)
+		if err != nil {
+			resp.Body.Close()
+			return nil, err
+		}
+		if !limited {
+			return resp, nil
+		}
+
+		wait := retryAfter(resp, attempt)
+		bucket.Penalize(now().Add(wait))
+		metrics.RateLimited.Inc(group)
+		// 418 表示 IP 已被封禁，继续重试只会延长封禁时间
+		if resp.StatusCode == http.StatusTeapot || attempt >= t.maxRetries() || !canRewind(req) {
+			log.Warn("⚠️ 触发交易所限流", "group", group, "status", resp.StatusCode, "retry_after", wait.String())
+			return resp, nil
+		}
+		log.Warn("⚠️ 触发交易所限流，暂停后重试", "group", group, "status", resp.StatusCode,
+			"retry_after", wait.String(), "attempt", attempt+1)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+func (t *Transport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+	return http.DefaultTransport
+}
+
+func (t *Transport) limiter() *Limiter {
+	if t.Limiter != nil {
+		return t.Limiter
+	}
+	return Default
+}
+
+func (t *Transport) maxRetries() int {
+	switch {
+	case t.MaxRetries < 0:
+		return 0
+	case t.MaxRetries == 0:
+		return defaultMaxRetries
+	default:
+		return t.MaxRetries
+	}
+}
+
+// isRateLimited 判断响应是否为限流（会读取响应体开头，读取的部分会放回 Body）
+func isRateLimited(resp *http.Response) (bool, error) {
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusTeapot {
+		return true, nil
+	}
+	if resp.Body == nil || resp.Body == http.NoBody {
+		return false, nil
+	}
+	head := make([]byte, peekSize)
+	n, err := io.ReadFull(resp.Body, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return false, err
+	}
+	head = head[:n]
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(head), resp.Body), resp.Body}
+
+	compact := strings.ReplaceAll(string(head), " ", "")
+	// 币安的 -1003 总是伴随 HTTP 429，这里只需识别 OKX 的错误码
+	for _, code := range []string{`"code":"50011"`, `"code":"50061"`} {
+		if strings.Contains(compact, code) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// retryAfter 解析 Retry-After（秒数或 HTTP 日期），没有时按指数退避
+func retryAfter(resp *http.Response, attempt int) time.Duration {
+	if v := strings.TrimSpace(resp.Header.Get("Retry-After")); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs >= 0 {
+			return min(time.Duration(secs)*time.Second, maxBackoff)
+		}
+		if at, err := http.ParseTime(v); err == nil {
+			return min(max(at.Sub(now()), 0), maxBackoff)
+		}
+	}
+	return min(defaultBackoff<<attempt, maxBackoff)
+}
+
+// canRewind 请求体是否可以重放（被限流的请求未被交易所受理，重放下单请求是安全的）
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	r := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		r.Body = body
+	}
+	return r, nil
+}
+
+// OKXGroup OKX v5 接口分组：trade（下单/撤单）、private（账户/资产）、public（行情）
+func OKXGroup(req *http.Request) string {
+	path := req.URL.Path
+	switch {
+	case strings.HasPrefix(path, "/api/v5/trade"):
+		return "okx.trade"
+	case strings.HasPrefix(path, "/api/v5/account"), strings.HasPrefix(path, "/api/v5/asset"),
+		strings.HasPrefix(path, "/api/v5/users"):
+		return "okx.private"
+	default:
+		return "okx.public"
+	}
+}
+
+// BinanceGroup 币安风格接口分组（Aster 与币安一致）：非 GET 请求为 trade，
+// 带签名的查询为 private，其余为 public
+func BinanceGroup(exchange string) GroupFunc {
+	return func(req *http.Request) string {
+		if req.Method != http.MethodGet {
+			return exchange + ".trade"
+		}
+		q := req.URL.Query()
+		if q.Has("signature") || q.Has("timestamp") {
+			return exchange + ".private"
+		}
+		return exchange + ".public"
+	}
+}
+
+// PublicGroup 只使用公开行情接口的交易所（如 Bybit 行情数据源）：所有请求归入 public 组
+func PublicGroup(exchange string) GroupFunc {
+	return func(*http.Request) string { return exchange + ".public" }
+}
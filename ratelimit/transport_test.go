@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTransportRetriesAfter429(t *testing.T) {
+	waits := fakeClock(t)
+	var calls int
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if calls == 1 {
+			w.Header().Set("Retry-After", "2")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"ok":true}`))
+	}))
+	defer server.Close()
+
+	limiter := New(nil)
+	client := &http.Client{Transport: &Transport{Group: BinanceGroup("binance"), Limiter: limiter}}
+	resp, err := client.Post(server.URL+"/fapi/v1/order", "application/x-www-form-urlencoded", strings.NewReader("symbol=BTCUSDT"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || calls != 2 {
+		t.Fatalf("status = %d, calls = %d, want 200 after retry", resp.StatusCode, calls)
+	}
+	if bodies[1] != "symbol=BTCUSDT" {
+		t.Errorf("重试时请求体应重放, got %q", bodies[1])
+	}
+	if len(*waits) != 1 || (*waits)[0] != 2*time.Second {
+		t.Errorf("waits = %v, want [2s]（按 Retry-After 暂停）", *waits)
+	}
+}
+
+func TestTransportDetectsOKXRateLimitCode(t *testing.T) {
+	waits := fakeClock(t)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			w.Write([]byte(`{"code": "50011", "msg": "Rate limit reached. Please refer to API documentation and throttle requests accordingly.", "data": []}`))
+			return
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[]}`))
+	}))
+	defer server.Close()
+
+	client := &http.Client{Transport: &Transport{Group: OKXGroup, Limiter: New(nil)}}
+	resp, err := client.Get(server.URL + "/api/v5/market/ticker?instId=BTC-USDT-SWAP")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if calls != 2 || string(body) != `{"code":"0","msg":"","data":[]}` {
+		t.Errorf("calls = %d, body = %s, want retry with full body", calls, body)
+	}
+	if len(*waits) != 1 || (*waits)[0] != defaultBackoff {
+		t.Errorf("waits = %v, want [%v]（无 Retry-After 时指数退避）", *waits, defaultBackoff)
+	}
+}
+
+func TestTransportGivesUpOn418(t *testing.T) {
+	fakeClock(t)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "120")
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer server.Close()
+
+	limiter := New(nil)
+	client := &http.Client{Transport: &Transport{Group: BinanceGroup("binance"), Limiter: limiter}}
+	resp, err := client.Get(server.URL + "/fapi/v1/ticker/price")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot || calls != 1 {
+		t.Errorf("status = %d, calls = %d, want 418 without retry", resp.StatusCode, calls)
+	}
+	// 封禁期间同组请求会被暂停（Retry-After 上限 30s）
+	if until := limiter.Bucket("binance.public").blockedUntil; until.Sub(now()) != maxBackoff {
+		t.Errorf("blockedUntil - now = %v, want %v", until.Sub(now()), maxBackoff)
+	}
+}
+
+func TestGroups(t *testing.T) {
+	tests := []struct {
+		group  GroupFunc
+		method string
+		url    string
+		want   string
+	}{
+		{OKXGroup, "GET", "https://www.okx.com/api/v5/market/candles", "okx.public"},
+		{OKXGroup, "GET", "https://www.okx.com/api/v5/account/balance", "okx.private"},
+		{OKXGroup, "POST", "https://www.okx.com/api/v5/trade/order", "okx.trade"},
+		{BinanceGroup("binance"), "GET", "https://fapi.binance.com/fapi/v1/klines?symbol=BTCUSDT", "binance.public"},
+		{BinanceGroup("binance"), "GET", "https://fapi.binance.com/fapi/v2/account?timestamp=1&signature=x", "binance.private"},
+		{BinanceGroup("aster"), "DELETE", "https://fapi.asterdex.com/fapi/v3/allOpenOrders", "aster.trade"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest(tt.method, tt.url, nil)
+		if got := tt.group(req); got != tt.want {
+			t.Errorf("%s %s -> %q, want %q", tt.method, tt.url, got, tt.want)
+		}
+	}
+}
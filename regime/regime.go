@@ -0,0 +1,170 @@
+// Package regime 市场状态分类：用 ATR、ADX 和已实现波动率把币种在某个K线周期上的行情划分为
+// 趋势（trending）、震荡（ranging）和高波动（high_vol），状态变化发布到事件总线，策略和风控据此调整仓位和止损宽度
+package regime
+
+import (
+	"fmt"
+	"math"
+	"nofx/market"
+	"time"
+)
+
+// 市场状态
+const (
+	Trending = "trending" // ADX 达到趋势阈值
+	Ranging  = "ranging"  // 趋势不明显且波动正常
+	HighVol  = "high_vol" // 近期波动率明显高于基准（优先于趋势判定）
+)
+
+// Config 分类参数（字段为零值时使用默认值）
+type Config struct {
+	ATRPeriod    int     // ATR 周期（默认 14）
+	ADXPeriod    int     // ADX 周期（默认 14）
+	VolWindow    int     // 近期已实现波动率的K线数（默认 20）
+	VolBaseline  int     // 基准已实现波动率的K线数（默认 100，K线不足时使用全部）
+	TrendADX     float64 // ADX 达到该值视为趋势（默认 25）
+	HighVolRatio float64 // 近期/基准波动率达到该倍数视为高波动（默认 1.5）
+}
+
+// withDefaults 填充默认值
+func (c Config) withDefaults() Config {
+	if c.ATRPeriod <= 0 {
+		c.ATRPeriod = 14
+	}
+	if c.ADXPeriod <= 0 {
+		c.ADXPeriod = 14
+	}
+	if c.VolWindow <= 1 {
+		c.VolWindow = 20
+	}
+	if c.VolBaseline < c.VolWindow {
+		c.VolBaseline = max(100, c.VolWindow)
+	}
+	if c.TrendADX <= 0 {
+		c.TrendADX = 25
+	}
+	if c.HighVolRatio <= 0 {
+		c.HighVolRatio = 1.5
+	}
+	return c
+}
+
+// MinBars 分类所需的最少K线数
+func (c Config) MinBars() int {
+	c = c.withDefaults()
+	return max(2*c.ADXPeriod+1, c.ATRPeriod+1, c.VolWindow+1)
+}
+
+// Reading 一次分类结果
+type Reading struct {
+	Symbol         string    `json:"symbol"`
+	Interval       string    `json:"interval"`
+	Regime         string    `json:"regime"`
+	ADX            float64   `json:"adx"`
+	ATR            float64   `json:"atr"`
+	ATRPct         float64   `json:"atr_pct"`          // ATR 占最新收盘价的百分比
+	RealizedVolPct float64   `json:"realized_vol_pct"` // 近期年化已实现波动率（百分比）
+	VolRatio       float64   `json:"vol_ratio"`        // 近期波动率 / 基准波动率
+	Time           time.Time `json:"time"`             // 最后一根K线的收盘时间
+}
+
+// Classify 用K线（按时间正序）判定市场状态：
+// 近期波动率达到基准的 HighVolRatio 倍为 high_vol，否则 ADX 达到 TrendADX 为 trending，其余为 ranging
+func Classify(klines []market.Kline, interval string, cfg Config) (Reading, error) {
+	cfg = cfg.withDefaults()
+	dur, ok := market.TimeframeDuration(interval)
+	if !ok {
+		return Reading{}, fmt.Errorf("不支持的K线周期: %s", interval)
+	}
+	if need := cfg.MinBars(); len(klines) < need {
+		return Reading{}, fmt.Errorf("K线不足: %d 根（至少需要 %d 根）", len(klines), need)
+	}
+
+	last := klines[len(klines)-1]
+	r := Reading{
+		Interval: interval,
+		ADX:      market.ADX(klines, cfg.ADXPeriod),
+		ATR:      market.ATR(klines, cfg.ATRPeriod),
+		Time:     time.UnixMilli(last.CloseTime),
+	}
+	if last.Close > 0 {
+		r.ATRPct = r.ATR / last.Close * 100
+	}
+
+	returns := logReturns(klines)
+	recent := stdDev(returns[max(0, len(returns)-cfg.VolWindow):])
+	baseline := stdDev(returns[max(0, len(returns)-cfg.VolBaseline):])
+	r.RealizedVolPct = recent * math.Sqrt(float64(365*24*time.Hour)/float64(dur)) * 100
+	if baseline > 0 {
+		r.VolRatio = recent / baseline
+	}
+
+	switch {
+	case r.VolRatio >= cfg.HighVolRatio:
+		r.Regime = HighVol
+	case r.ADX >= cfg.TrendADX:
+		r.Regime = Trending
+	default:
+		r.Regime = Ranging
+	}
+	return r, nil
+}
+
+// logReturns 相邻收盘价的对数收益率（跳过无效价格）
+func logReturns(klines []market.Kline) []float64 {
+	out := make([]float64, 0, len(klines)-1)
+	for i := 1; i < len(klines); i++ {
+		if klines[i-1].Close > 0 && klines[i].Close > 0 {
+			out = append(out, math.Log(klines[i].Close/klines[i-1].Close))
+		}
+	}
+	return out
+}
+
+// stdDev 样本标准差
+func stdDev(xs []float64) float64 {
+	if len(xs) < 2 {
+		return 0
+	}
+	mean := 0.0
+	for _, x := range xs {
+		mean += x
+	}
+	mean /= float64(len(xs))
+	sum := 0.0
+	for _, x := range xs {
+		sum += (x - mean) * (x - mean)
+	}
+	return math.Sqrt(sum / float64(len(xs)-1))
+}
+
+// Adjustment 某个市场状态下的仓位和止损调整
+type Adjustment struct {
+	SizeMult float64 // 开仓金额乘数
+	StopMult float64 // 止损距离（入场价到止损价）乘数
+}
+
+// Policy 市场状态 -> 调整（缺少的状态不调整）
+type Policy map[string]Adjustment
+
+// DefaultPolicy 默认调整：震荡减仓，高波动减半仓位并放宽止损避免被噪音扫掉
+var DefaultPolicy = Policy{
+	Trending: {SizeMult: 1, StopMult: 1},
+	Ranging:  {SizeMult: 0.75, StopMult: 1},
+	HighVol:  {SizeMult: 0.5, StopMult: 1.5},
+}
+
+// For 返回市场状态对应的调整（未配置或乘数无效时为 1）
+func (p Policy) For(regime string) Adjustment {
+	a, ok := p[regime]
+	if !ok {
+		return Adjustment{SizeMult: 1, StopMult: 1}
+	}
+	if a.SizeMult <= 0 {
+		a.SizeMult = 1
+	}
+	if a.StopMult <= 0 {
+		a.StopMult = 1
+	}
+	return a
+}
@@ -0,0 +1,202 @@
+package regime
+
+import (
+	"context"
+	"math"
+	"nofx/eventbus"
+	"nofx/market"
+	"testing"
+	"time"
+)
+
+const hourMs = int64(time.Hour / time.Millisecond)
+
+// klinesFromCloses 按收盘价生成 1h K线（开盘价为上一根收盘价，最高/最低在开收盘价外 0.5%）
+func klinesFromCloses(closes []float64) []market.Kline {
+	klines := make([]market.Kline, len(closes))
+	for i, c := range closes {
+		open := c
+		if i > 0 {
+			open = closes[i-1]
+		}
+		klines[i] = market.Kline{
+			OpenTime:  int64(i) * hourMs,
+			Open:      open,
+			High:      math.Max(open, c) * 1.005,
+			Low:       math.Min(open, c) * 0.995,
+			Close:     c,
+			CloseTime: int64(i+1)*hourMs - 1,
+		}
+	}
+	return klines
+}
+
+// trendCloses 单边上涨
+func trendCloses(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = 100 * math.Pow(1.01, float64(i))
+	}
+	return out
+}
+
+// chopCloses 在 100 附近来回震荡，最后 spikes 根的振幅放大到 swing 倍
+func chopCloses(n, spikes int, swing float64) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		move := 1.0
+		if i >= n-spikes {
+			move *= swing
+		}
+		out[i] = 100 + move*[]float64{0, 1, 0, -1}[i%4]
+	}
+	return out
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		name   string
+		closes []float64
+		want   string
+	}{
+		{"trending", trendCloses(150), Trending},
+		{"ranging", chopCloses(150, 0, 1), Ranging},
+		{"high vol", chopCloses(150, 20, 5), HighVol},
+	}
+	for _, tc := range cases {
+		r, err := Classify(klinesFromCloses(tc.closes), "1h", Config{})
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if r.Regime != tc.want {
+			t.Errorf("%s: regime=%s (ADX %.1f, vol ratio %.2f), want %s", tc.name, r.Regime, r.ADX, r.VolRatio, tc.want)
+		}
+		if r.ATR <= 0 || r.ATRPct <= 0 || r.RealizedVolPct <= 0 {
+			t.Errorf("%s: indicators not populated: %+v", tc.name, r)
+		}
+	}
+
+	if _, err := Classify(klinesFromCloses(trendCloses(20)), "1h", Config{}); err == nil {
+		t.Error("expected error for too few klines")
+	}
+	if _, err := Classify(klinesFromCloses(trendCloses(150)), "7x", Config{}); err == nil {
+		t.Error("expected error for unknown interval")
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	if a := DefaultPolicy.For(HighVol); a.SizeMult != 0.5 || a.StopMult != 1.5 {
+		t.Errorf("high vol adjustment = %+v", a)
+	}
+	if a := (Policy{Ranging: {SizeMult: 0.5}}).For(Ranging); a.SizeMult != 0.5 || a.StopMult != 1 {
+		t.Errorf("missing stop multiplier should default to 1: %+v", a)
+	}
+	if a := (Policy{}).For(Trending); a.SizeMult != 1 || a.StopMult != 1 {
+		t.Errorf("unconfigured regime should not adjust: %+v", a)
+	}
+}
+
+func TestTrackerPublishesOnChange(t *testing.T) {
+	bus := eventbus.New()
+	events, cancel := bus.Subscribe(10, eventbus.TopicRegimeChanged)
+	defer cancel()
+	tracker := NewTracker(Config{}, bus)
+
+	ranging := klinesFromCloses(chopCloses(150, 0, 1))
+	for i := 0; i < 2; i++ {
+		if _, err := tracker.Update("BTCUSDT", "1h", ranging); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tracker.Update("BTCUSDT", "1h", klinesFromCloses(chopCloses(150, 20, 5))); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []eventbus.RegimeChanged
+	for len(events) > 0 {
+		got = append(got, (<-events).(eventbus.RegimeChanged))
+	}
+	if len(got) != 2 {
+		t.Fatalf("events = %+v, want initial reading and one change", got)
+	}
+	if got[0].From != "" || got[0].To != Ranging {
+		t.Errorf("initial event = %+v", got[0])
+	}
+	if got[1].From != Ranging || got[1].To != HighVol || got[1].Symbol != "BTCUSDT" || got[1].Interval != "1h" {
+		t.Errorf("change event = %+v", got[1])
+	}
+	if r, ok := tracker.Current("BTCUSDT", "1h"); !ok || r.Regime != HighVol {
+		t.Errorf("current = %+v, %v", r, ok)
+	}
+	if _, ok := tracker.Current("BTCUSDT", "4h"); ok {
+		t.Error("unexpected reading for untracked interval")
+	}
+}
+
+func TestTrackerWatchAppendsClosedCandle(t *testing.T) {
+	bus := eventbus.New()
+	tracker := NewTracker(Config{}, bus)
+	events, cancelEvents := bus.Subscribe(10, eventbus.TopicRegimeChanged)
+	defer cancelEvents()
+
+	// 缓存中缺少最后一根刚收盘的K线
+	all := klinesFromCloses(trendCloses(150))
+	cached := all[:len(all)-1]
+	load := func(symbol, interval string, until time.Time, bars int) ([]market.Kline, error) {
+		if symbol != "ETHUSDT" || interval != "1h" {
+			t.Errorf("unexpected load %s %s", symbol, interval)
+		}
+		return append([]market.Kline(nil), cached...), nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		tracker.Watch(ctx, load, "1h")
+		close(done)
+	}()
+	defer func() {
+		cancel()
+		<-done
+	}()
+
+	// 等待订阅生效后发布
+	last := all[len(all)-1]
+	deadline := time.After(2 * time.Second)
+	for {
+		bus.Publish(eventbus.CandleClosed{Symbol: "BTCUSDT", Interval: "4h", OpenTime: last.OpenTime, CloseTime: last.CloseTime, Close: last.Close})
+		bus.Publish(eventbus.CandleClosed{
+			Symbol: "ETHUSDT", Interval: "1h", OpenTime: last.OpenTime, CloseTime: last.CloseTime,
+			Open: last.Open, High: last.High, Low: last.Low, Close: last.Close,
+		})
+		select {
+		case ev := <-events:
+			if r := ev.(eventbus.RegimeChanged); r.To != Trending || r.Symbol != "ETHUSDT" {
+				t.Errorf("event = %+v", r)
+			}
+			r, ok := tracker.Current("ETHUSDT", "1h")
+			if !ok || r.Time != time.UnixMilli(last.CloseTime) {
+				t.Errorf("reading should include the closed candle: %+v", r)
+			}
+			return
+		case <-time.After(10 * time.Millisecond):
+		case <-deadline:
+			t.Fatal("no regime event")
+		}
+	}
+}
+
+func TestCachedKlines(t *testing.T) {
+	if err := market.EnableKlineCache(t.TempDir(), 0); err != nil {
+		t.Fatal(err)
+	}
+	defer market.DisableKlineCache()
+
+	if _, err := CachedKlines("binance")("BTCUSDT", "7x", time.Now(), 10); err == nil {
+		t.Error("expected error for unknown interval")
+	}
+	klines, err := CachedKlines("binance")("BTCUSDT", "1h", time.UnixMilli(100*hourMs), 10)
+	if err != nil || len(klines) != 0 {
+		t.Errorf("empty cache: %d klines, err=%v", len(klines), err)
+	}
+}
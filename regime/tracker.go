@@ -0,0 +1,139 @@
+package regime
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"nofx/eventbus"
+	"nofx/market"
+	"sync"
+	"time"
+)
+
+// Loader 读取币种在某个周期截至 until 的最近 bars 根K线（按时间正序）
+type Loader func(symbol, interval string, until time.Time, bars int) ([]market.Kline, error)
+
+// CachedKlines 从本地K线缓存（NOFX_KLINE_CACHE_DIR）读取K线
+func CachedKlines(source string) Loader {
+	return func(symbol, interval string, until time.Time, bars int) ([]market.Kline, error) {
+		dur, ok := market.TimeframeDuration(interval)
+		if !ok {
+			return nil, fmt.Errorf("不支持的K线周期: %s", interval)
+		}
+		klines, err := market.LoadCachedKlines(source, symbol, interval, until.Add(-time.Duration(bars)*dur), until)
+		if err != nil {
+			return nil, err
+		}
+		if len(klines) > bars {
+			klines = klines[len(klines)-bars:]
+		}
+		return klines, nil
+	}
+}
+
+// Tracker 按币种和周期保存最新的市场状态，状态变化时发布 eventbus.RegimeChanged
+type Tracker struct {
+	cfg Config
+	bus *eventbus.Bus
+
+	mu       sync.RWMutex
+	readings map[string]Reading // symbol|interval -> 最新结果
+}
+
+// NewTracker 创建市场状态跟踪器（bus 为 nil 时发布到 eventbus.Default）
+func NewTracker(cfg Config, bus *eventbus.Bus) *Tracker {
+	if bus == nil {
+		bus = eventbus.Default
+	}
+	return &Tracker{cfg: cfg.withDefaults(), bus: bus, readings: make(map[string]Reading)}
+}
+
+// Config 分类参数（已填充默认值）
+func (t *Tracker) Config() Config {
+	return t.cfg
+}
+
+// Update 用最新K线重新分类，状态与上次不同（或首次判定）时发布事件
+func (t *Tracker) Update(symbol, interval string, klines []market.Kline) (Reading, error) {
+	r, err := Classify(klines, interval, t.cfg)
+	if err != nil {
+		return Reading{}, err
+	}
+	r.Symbol = symbol
+
+	key := symbol + "|" + interval
+	t.mu.Lock()
+	prev, seen := t.readings[key]
+	t.readings[key] = r
+	t.mu.Unlock()
+
+	if !seen || prev.Regime != r.Regime {
+		if seen {
+			log.Printf("🌡️  %s %s 市场状态 %s → %s (ADX %.1f, ATR %.2f%%, 波动率比 %.2f)", symbol, interval, prev.Regime, r.Regime, r.ADX, r.ATRPct, r.VolRatio)
+		}
+		t.bus.Publish(eventbus.RegimeChanged{
+			Symbol:         symbol,
+			Interval:       interval,
+			From:           prev.Regime,
+			To:             r.Regime,
+			ADX:            r.ADX,
+			ATRPct:         r.ATRPct,
+			RealizedVolPct: r.RealizedVolPct,
+			VolRatio:       r.VolRatio,
+			Time:           r.Time,
+		})
+	}
+	return r, nil
+}
+
+// Current 最近一次分类结果
+func (t *Tracker) Current(symbol, interval string) (Reading, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	r, ok := t.readings[symbol+"|"+interval]
+	return r, ok
+}
+
+// Watch 订阅 K线收盘事件，对 intervals 中的周期（为空时全部周期）用 load 读取最近K线重新分类，直到 ctx 取消
+func (t *Tracker) Watch(ctx context.Context, load Loader, intervals ...string) {
+	watched := make(map[string]bool, len(intervals))
+	for _, interval := range intervals {
+		watched[interval] = true
+	}
+	events, cancel := t.bus.Subscribe(64, eventbus.TopicCandleClosed)
+	defer cancel()
+
+	bars := t.cfg.VolBaseline + 1
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			candle := ev.(eventbus.CandleClosed)
+			if len(watched) > 0 && !watched[candle.Interval] {
+				continue
+			}
+			klines, err := load(candle.Symbol, candle.Interval, time.UnixMilli(candle.CloseTime), bars)
+			if err != nil {
+				log.Printf("⚠️  读取 %s %s K线失败，跳过市场状态更新: %v", candle.Symbol, candle.Interval, err)
+				continue
+			}
+			// 缓存只由 REST 请求写入，可能还没有这根刚收盘的K线（或保存的是它未收盘时的数据）
+			closed := market.Kline{
+				OpenTime: candle.OpenTime, Open: candle.Open, High: candle.High, Low: candle.Low,
+				Close: candle.Close, Volume: candle.Volume, CloseTime: candle.CloseTime,
+			}
+			if n := len(klines); n > 0 && klines[n-1].OpenTime == candle.OpenTime {
+				klines[n-1] = closed
+			} else if n == 0 || klines[n-1].OpenTime < candle.OpenTime {
+				klines = append(klines, closed)
+			}
+			if _, err := t.Update(candle.Symbol, candle.Interval, klines); err != nil && len(klines) >= t.cfg.MinBars() {
+				log.Printf("⚠️  %s %s 市场状态分类失败: %v", candle.Symbol, candle.Interval, err)
+			}
+		}
+	}
+}
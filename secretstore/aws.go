@@ -0,0 +1,190 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// AWSCredentials AWS 访问凭证
+type AWSCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string // 临时凭证（可选）
+}
+
+// AWSSecretsManagerProvider AWS Secrets Manager 密钥提供方
+// SecretString 需为 JSON 对象（如 {"BINANCE_API_KEY": "..."}）
+type AWSSecretsManagerProvider struct {
+	creds    AWSCredentials
+	region   string
+	secretID string
+	endpoint string
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewAWSSecretsManagerProvider 创建 AWS Secrets Manager 密钥提供方
+func NewAWSSecretsManagerProvider(creds AWSCredentials, region, secretID string) (*AWSSecretsManagerProvider, error) {
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" || region == "" || secretID == "" {
+		return nil, fmt.Errorf("aws 配置不完整：需要 AWS_ACCESS_KEY_ID、AWS_SECRET_ACCESS_KEY、AWS_REGION、AWS_SECRET_ID")
+	}
+
+	return &AWSSecretsManagerProvider{
+		creds:    creds,
+		region:   region,
+		secretID: secretID,
+		endpoint: fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", region),
+		client:   &http.Client{Timeout: 15 * time.Second},
+		now:      time.Now,
+	}, nil
+}
+
+// Name 提供方名称
+func (p *AWSSecretsManagerProvider) Name() string {
+	return "aws-secrets-manager"
+}
+
+// Fetch 调用 GetSecretValue 并解析 SecretString
+func (p *AWSSecretsManagerProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": p.secretID})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+"/", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	p.sign(req, payload)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("aws secrets manager 返回 HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析 aws 响应失败: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(result.SecretString), &raw); err != nil {
+		return nil, fmt.Errorf("SecretString 不是 JSON 对象: %w", err)
+	}
+
+	return stringifyValues(raw), nil
+}
+
+// sign 使用 AWS Signature Version 4 签名请求
+func (p *AWSSecretsManagerProvider) sign(req *http.Request, payload []byte) {
+	const service = "secretsmanager"
+
+	now := p.now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if p.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.creds.SessionToken)
+	}
+
+	// 规范化请求头（host + 所有已设置的头，按小写名称排序）
+	headers := map[string]string{"host": req.URL.Host}
+	for name := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(req.Header.Get(name))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalURI := req.URL.EscapedPath()
+	if canonicalURI == "" {
+		canonicalURI = "/"
+	}
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		canonicalURI,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		sha256Hex(payload),
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/%s/aws4_request", date, p.region, service)
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+p.creds.SecretAccessKey), date)
+	signingKey = hmacSHA256(signingKey, p.region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 规范化查询字符串
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range values[key] {
+			parts = append(parts, url.QueryEscape(key)+"="+url.QueryEscape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
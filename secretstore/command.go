@@ -0,0 +1,24 @@
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// runCommand 执行外部命令并返回标准输出（钥匙串、age、gpg 均通过命令行工具访问；测试中替换）
+var runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, name, args...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s 执行失败: %w: %s", name, err, msg)
+		}
+		return nil, fmt.Errorf("%s 执行失败: %w", name, err)
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,83 @@
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const defaultDopplerBaseURL = "https://api.doppler.com"
+
+// DopplerProvider Doppler 密钥提供方
+type DopplerProvider struct {
+	baseURL string
+	token   string
+	project string // 使用 Service Token 时可为空
+	config  string // 使用 Service Token 时可为空
+	client  *http.Client
+}
+
+// NewDopplerProvider 创建 Doppler 密钥提供方
+func NewDopplerProvider(token, project, config string) (*DopplerProvider, error) {
+	if token == "" {
+		return nil, fmt.Errorf("doppler 配置不完整：需要 DOPPLER_TOKEN")
+	}
+
+	return &DopplerProvider{
+		baseURL: defaultDopplerBaseURL,
+		token:   token,
+		project: project,
+		config:  config,
+		client:  &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// Name 提供方名称
+func (p *DopplerProvider) Name() string {
+	return "doppler"
+}
+
+// Fetch 下载配置中的全部密钥
+func (p *DopplerProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	query := url.Values{}
+	query.Set("format", "json")
+	if p.project != "" {
+		query.Set("project", p.project)
+	}
+	if p.config != "" {
+		query.Set("config", p.config)
+	}
+
+	endpoint := fmt.Sprintf("%s/v3/configs/config/secrets/download?%s", p.baseURL, query.Encode())
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("doppler 返回 HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, fmt.Errorf("解析 doppler 响应失败: %w", err)
+	}
+
+	return stringifyValues(raw), nil
+}
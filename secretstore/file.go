@@ -0,0 +1,116 @@
+package secretstore
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// EncryptedFileProvider 加密文件密钥提供方（age 或 GPG）
+// 解密后的内容为 JSON 对象（{"BINANCE_API_KEY": "..."}）或每行 KEY=VALUE 的 dotenv 格式
+type EncryptedFileProvider struct {
+	path           string
+	cipher         string // age / gpg
+	identity       string // age 私钥文件（age -i）
+	passphraseFile string // gpg 口令文件（可选，未设置时由 gpg-agent 提供）
+}
+
+// NewEncryptedFileProvider 创建加密文件密钥提供方
+// cipher 为空时按扩展名判断：.age → age，.gpg / .asc → gpg
+func NewEncryptedFileProvider(path, cipher, identity, passphraseFile string) (*EncryptedFileProvider, error) {
+	if path == "" {
+		return nil, fmt.Errorf("file 配置不完整：需要 SECRETS_FILE")
+	}
+	cipher = strings.ToLower(strings.TrimSpace(cipher))
+	if cipher == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".age":
+			cipher = "age"
+		case ".gpg", ".asc":
+			cipher = "gpg"
+		}
+	}
+
+	switch cipher {
+	case "age":
+		if identity == "" {
+			return nil, fmt.Errorf("file 配置不完整：age 加密文件需要 SECRETS_AGE_IDENTITY（私钥文件路径）")
+		}
+	case "gpg":
+	case "":
+		return nil, fmt.Errorf("无法判断 %s 的加密方式，请设置 SECRETS_FILE_CIPHER（age / gpg）", path)
+	default:
+		return nil, fmt.Errorf("不支持的 SECRETS_FILE_CIPHER: %s（可选 age / gpg）", cipher)
+	}
+
+	return &EncryptedFileProvider{path: path, cipher: cipher, identity: identity, passphraseFile: passphraseFile}, nil
+}
+
+// Name 提供方名称
+func (p *EncryptedFileProvider) Name() string {
+	return "file(" + p.cipher + ")"
+}
+
+// Fetch 解密文件并解析密钥（明文只保存在内存中）
+func (p *EncryptedFileProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	if _, err := os.Stat(p.path); err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+
+	var out []byte
+	var err error
+	switch p.cipher {
+	case "age":
+		out, err = runCommand(ctx, "age", "--decrypt", "-i", p.identity, p.path)
+	case "gpg":
+		args := []string{"--batch", "--quiet", "--decrypt"}
+		if p.passphraseFile != "" {
+			args = append(args, "--pinentry-mode", "loopback", "--passphrase-file", p.passphraseFile)
+		}
+		out, err = runCommand(ctx, "gpg", append(args, p.path)...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解密 %s 失败: %w", p.path, err)
+	}
+	return parseSecretsFile(out)
+}
+
+// parseSecretsFile 解析解密后的内容：以 { 开头按 JSON 对象解析，否则按 dotenv（KEY=VALUE，# 开头为注释）解析
+func parseSecretsFile(data []byte) (map[string]string, error) {
+	trimmed := bytes.TrimSpace(data)
+	if bytes.HasPrefix(trimmed, []byte("{")) {
+		var raw map[string]interface{}
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("解析密钥文件失败: %w", err)
+		}
+		return stringifyValues(raw), nil
+	}
+
+	values := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(trimmed))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimPrefix(text, "export "), "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("解析密钥文件失败：第 %d 行应为 KEY=VALUE", line)
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		values[key] = value
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("解析密钥文件失败: %w", err)
+	}
+	return values, nil
+}
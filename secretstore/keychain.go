@@ -0,0 +1,64 @@
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+const defaultKeychainService = "nofx"
+
+// KeychainProvider 操作系统钥匙串密钥提供方
+// macOS 使用 security（登录钥匙串），Linux 使用 secret-tool（GNOME Keyring / KWallet 等 Secret Service）
+// 每个密钥存为一条通用密码：service=KEYCHAIN_SERVICE，account=密钥名
+type KeychainProvider struct {
+	service string
+	keys    []string // 钥匙串无法枚举条目，需显式列出要读取的密钥名
+	goos    string
+}
+
+// NewKeychainProvider 创建钥匙串密钥提供方（service 为空时使用 "nofx"）
+func NewKeychainProvider(service string, keys []string) (*KeychainProvider, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("keychain 配置不完整：需要 KEYCHAIN_KEYS（逗号分隔的密钥名）")
+	}
+	if service == "" {
+		service = defaultKeychainService
+	}
+
+	return &KeychainProvider{service: service, keys: keys, goos: runtime.GOOS}, nil
+}
+
+// Name 提供方名称
+func (p *KeychainProvider) Name() string {
+	return "keychain"
+}
+
+// Fetch 逐个读取钥匙串条目（任一条目缺失即失败，避免交易员带着空密钥启动）
+func (p *KeychainProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	values := make(map[string]string, len(p.keys))
+	for _, key := range p.keys {
+		var name string
+		var args []string
+		switch p.goos {
+		case "darwin":
+			name, args = "security", []string{"find-generic-password", "-s", p.service, "-a", key, "-w"}
+		case "linux", "freebsd", "openbsd":
+			name, args = "secret-tool", []string{"lookup", "service", p.service, "account", key}
+		default:
+			return nil, fmt.Errorf("keychain 暂不支持 %s（支持 macOS security 和 Linux secret-tool）", p.goos)
+		}
+
+		out, err := runCommand(ctx, name, args...)
+		if err != nil {
+			return nil, fmt.Errorf("读取钥匙串 %s/%s 失败: %w", p.service, key, err)
+		}
+		value := strings.TrimRight(string(out), "\r\n")
+		if value == "" {
+			return nil, fmt.Errorf("钥匙串 %s/%s 为空", p.service, key)
+		}
+		values[key] = value
+	}
+	return values, nil
+}
@@ -0,0 +1,193 @@
+package secretstore
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// DefaultKeyFilePath 本地加密密钥文件的默认路径（NOFX_KEYS_FILE 覆盖）
+const DefaultKeyFilePath = "keys.enc"
+
+// 密钥派生参数（scrypt 推荐的交互式参数，派生一次约 100ms）
+const (
+	keyFileVersion = 1
+	scryptN        = 1 << 15
+	scryptR        = 8
+	scryptP        = 1
+	saltSize       = 16
+)
+
+// ErrWrongPassphrase 口令错误（或文件被篡改）
+var ErrWrongPassphrase = errors.New("口令错误或密钥文件已损坏")
+
+// keyFileEnvelope 磁盘格式：scrypt 由口令派生 AES-256 密钥，AES-GCM 加密 JSON 编码的密钥表
+type keyFileEnvelope struct {
+	Version    int    `json:"version"`
+	KDF        string `json:"kdf"`
+	N          int    `json:"n"`
+	R          int    `json:"r"`
+	P          int    `json:"p"`
+	Salt       []byte `json:"salt"`
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// ReadKeyFile 用口令解密本地密钥文件
+func ReadKeyFile(path, passphrase string) (map[string]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取密钥文件失败: %w", err)
+	}
+
+	var env keyFileEnvelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return nil, fmt.Errorf("解析密钥文件 %s 失败: %w", path, err)
+	}
+	if env.Version != keyFileVersion || env.KDF != "scrypt" {
+		return nil, fmt.Errorf("不支持的密钥文件格式（version=%d, kdf=%s）", env.Version, env.KDF)
+	}
+
+	gcm, err := keyFileCipher(passphrase, env.Salt, env.N, env.R, env.P)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := gcm.Open(nil, env.Nonce, env.Ciphertext, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	values := make(map[string]string)
+	if err := json.Unmarshal(plaintext, &values); err != nil {
+		return nil, fmt.Errorf("解析密钥文件内容失败: %w", err)
+	}
+	return values, nil
+}
+
+// WriteKeyFile 用口令加密并原子写入本地密钥文件（权限 0600，每次写入使用新的盐和 nonce）
+func WriteKeyFile(path, passphrase string, values map[string]string) error {
+	if passphrase == "" {
+		return fmt.Errorf("口令不能为空")
+	}
+	if values == nil {
+		values = map[string]string{}
+	}
+	plaintext, err := json.Marshal(values)
+	if err != nil {
+		return err
+	}
+
+	env := keyFileEnvelope{Version: keyFileVersion, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: make([]byte, saltSize)}
+	if _, err := rand.Read(env.Salt); err != nil {
+		return fmt.Errorf("生成盐失败: %w", err)
+	}
+	gcm, err := keyFileCipher(passphrase, env.Salt, env.N, env.R, env.P)
+	if err != nil {
+		return err
+	}
+	env.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return fmt.Errorf("生成 nonce 失败: %w", err)
+	}
+	env.Ciphertext = gcm.Seal(nil, env.Nonce, plaintext, nil)
+
+	data, err := json.MarshalIndent(env, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return fmt.Errorf("创建目录失败: %w", err)
+		}
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入密钥文件失败: %w", err)
+	}
+	return nil
+}
+
+func keyFileCipher(passphrase string, salt []byte, n, r, p int) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, n, r, p, 32)
+	if err != nil {
+		return nil, fmt.Errorf("派生密钥失败: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SortedKeys 密钥名（按字母排序，用于列出密钥而不输出值）
+func SortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// LocalKeyFileProvider 本地加密密钥文件提供方（由 nofx keys 管理）
+type LocalKeyFileProvider struct {
+	path       string
+	passphrase string
+}
+
+// NewLocalKeyFileProvider 创建本地加密密钥文件提供方（path 为空时使用 DefaultKeyFilePath）
+func NewLocalKeyFileProvider(path, passphrase string) (*LocalKeyFileProvider, error) {
+	if path == "" {
+		path = DefaultKeyFilePath
+	}
+	if passphrase == "" {
+		return nil, fmt.Errorf("local 配置不完整：需要 NOFX_KEYS_PASSPHRASE（或在终端中输入口令）")
+	}
+	return &LocalKeyFileProvider{path: path, passphrase: passphrase}, nil
+}
+
+// Name 提供方名称
+func (p *LocalKeyFileProvider) Name() string {
+	return "local"
+}
+
+// Fetch 解密本地密钥文件
+func (p *LocalKeyFileProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	return ReadKeyFile(p.path, p.passphrase)
+}
+
+// KeyFilePathFromEnv 本地密钥文件路径（NOFX_KEYS_FILE，默认 keys.enc）
+func KeyFilePathFromEnv() string {
+	if v := os.Getenv("NOFX_KEYS_FILE"); v != "" {
+		return v
+	}
+	return DefaultKeyFilePath
+}
+
+// KeyFilePassphraseFromEnv 读取主口令：NOFX_KEYS_PASSPHRASE > NOFX_KEYS_PASSPHRASE_FILE > 终端输入
+func KeyFilePassphraseFromEnv() (string, error) {
+	if v := os.Getenv("NOFX_KEYS_PASSPHRASE"); v != "" {
+		return v, nil
+	}
+	if path := os.Getenv("NOFX_KEYS_PASSPHRASE_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("读取 NOFX_KEYS_PASSPHRASE_FILE 失败: %w", err)
+		}
+		return trimLineEnding(string(data)), nil
+	}
+	return PromptPassphrase("🔐 请输入密钥文件口令: ")
+}
@@ -0,0 +1,76 @@
+package secretstore
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyFileRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "keys.enc")
+	values := map[string]string{"BINANCE_API_KEY": "abc", "BINANCE_SECRET_KEY": "xyz"}
+	require.NoError(t, WriteKeyFile(path, "correct horse", values))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	// 磁盘上不出现明文
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "abc")
+	assert.NotContains(t, string(data), "BINANCE_API_KEY")
+
+	got, err := ReadKeyFile(path, "correct horse")
+	require.NoError(t, err)
+	assert.Equal(t, values, got)
+
+	_, err = ReadKeyFile(path, "wrong")
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	// 篡改密文后无法解密
+	tampered := strings.Replace(string(data), `"ciphertext": "`, `"ciphertext": "AA`, 1)
+	require.NoError(t, os.WriteFile(path, []byte(tampered), 0600))
+	_, err = ReadKeyFile(path, "correct horse")
+	assert.Error(t, err)
+
+	assert.Error(t, WriteKeyFile(path, "", values))
+}
+
+func TestLocalKeyFileProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.enc")
+	require.NoError(t, WriteKeyFile(path, "passphrase", map[string]string{"DEEPSEEK_API_KEY": "sk-1"}))
+
+	t.Setenv("SECRETS_PROVIDER", "local")
+	t.Setenv("NOFX_KEYS_FILE", path)
+	t.Setenv("NOFX_KEYS_PASSPHRASE", "")
+
+	// 未设置口令环境变量时在终端中输入
+	original := PromptPassphrase
+	defer func() { PromptPassphrase = original }()
+	PromptPassphrase = func(prompt string) (string, error) { return "passphrase", nil }
+
+	provider, err := NewProviderFromEnv()
+	require.NoError(t, err)
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", values["DEEPSEEK_API_KEY"])
+
+	// 口令文件
+	passFile := filepath.Join(t.TempDir(), "pass")
+	require.NoError(t, os.WriteFile(passFile, []byte("wrong\n"), 0600))
+	t.Setenv("NOFX_KEYS_PASSPHRASE_FILE", passFile)
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	_, err = provider.Fetch(context.Background())
+	assert.ErrorIs(t, err, ErrWrongPassphrase)
+
+	t.Setenv("NOFX_KEYS_FILE", filepath.Join(t.TempDir(), "missing.enc"))
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,43 @@
+package secretstore
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// PromptPassphrase 在终端中读取口令（关闭回显）；没有可用终端时返回错误（测试中替换）
+var PromptPassphrase = func(prompt string) (string, error) {
+	tty, err := os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	if err != nil {
+		return "", fmt.Errorf("没有可用的终端输入口令，请设置 NOFX_KEYS_PASSPHRASE 或 NOFX_KEYS_PASSPHRASE_FILE")
+	}
+	defer tty.Close()
+
+	if err := stty(tty, "-echo"); err != nil {
+		return "", fmt.Errorf("无法关闭终端回显，请设置 NOFX_KEYS_PASSPHRASE: %w", err)
+	}
+	defer func() {
+		stty(tty, "echo")
+		fmt.Fprintln(tty)
+	}()
+
+	fmt.Fprint(tty, prompt)
+	line, err := bufio.NewReader(tty).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("读取口令失败: %w", err)
+	}
+	return trimLineEnding(line), nil
+}
+
+func stty(tty *os.File, mode string) error {
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = tty
+	return cmd.Run()
+}
+
+func trimLineEnding(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
@@ -0,0 +1,277 @@
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RefPrefix 密钥引用前缀
+// 数据库中的 API Key 等字段填写 "secret://BINANCE_API_KEY" 时，启动交易员前会从外部密钥提供方解析实际值
+const RefPrefix = "secret://"
+
+// Provider 外部密钥提供方
+type Provider interface {
+	// Name 提供方名称（用于日志）
+	Name() string
+	// Fetch 拉取全部密钥（key -> value）
+	Fetch(ctx context.Context) (map[string]string, error)
+}
+
+// Store 密钥缓存，支持定时刷新
+type Store struct {
+	provider Provider
+	values   map[string]string
+	mu       sync.RWMutex
+	stopCh   chan struct{}
+	stopOnce sync.Once
+}
+
+// NewStore 创建密钥缓存，并立即从提供方拉取一次
+func NewStore(ctx context.Context, provider Provider) (*Store, error) {
+	s := &Store{
+		provider: provider,
+		values:   make(map[string]string),
+		stopCh:   make(chan struct{}),
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Refresh 从提供方重新拉取密钥（失败时保留旧值）
+func (s *Store) Refresh(ctx context.Context) error {
+	values, err := s.provider.Fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("从 %s 拉取密钥失败: %w", s.provider.Name(), err)
+	}
+
+	s.mu.Lock()
+	s.values = values
+	s.mu.Unlock()
+
+	log.Printf("🔑 已从 %s 加载 %d 个密钥", s.provider.Name(), len(values))
+	return nil
+}
+
+// Get 获取密钥
+func (s *Store) Get(key string) (string, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// StartRefresh 启动定时刷新（interval<=0 时不刷新）
+// 刷新后新启动的交易员使用新密钥，已运行的交易员需重启后生效
+func (s *Store) StartRefresh(interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				if err := s.Refresh(ctx); err != nil {
+					log.Printf("⚠️  密钥刷新失败（继续使用缓存值）: %v", err)
+				}
+				cancel()
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+
+	log.Printf("🔄 已启动密钥定时刷新（间隔 %v）", interval)
+}
+
+// Stop 停止定时刷新
+func (s *Store) Stop() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+}
+
+var (
+	defaultStore   *Store
+	defaultStoreMu sync.RWMutex
+)
+
+// SetDefaultStore 设置全局密钥缓存
+func SetDefaultStore(s *Store) {
+	defaultStoreMu.Lock()
+	defer defaultStoreMu.Unlock()
+	defaultStore = s
+}
+
+// IsRef 判断值是否为密钥引用
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, RefPrefix)
+}
+
+// Resolve 解析密钥引用；非引用值原样返回
+func Resolve(value string) (string, error) {
+	if !IsRef(value) {
+		return value, nil
+	}
+
+	key := strings.TrimPrefix(value, RefPrefix)
+
+	defaultStoreMu.RLock()
+	store := defaultStore
+	defaultStoreMu.RUnlock()
+
+	if store == nil {
+		return "", fmt.Errorf("密钥引用 %s 无法解析：未配置外部密钥提供方 (SECRETS_PROVIDER)", value)
+	}
+
+	secret, ok := store.Get(key)
+	if !ok {
+		return "", fmt.Errorf("密钥 %s 在 %s 中不存在", key, store.provider.Name())
+	}
+	return secret, nil
+}
+
+// ResolveFields 就地解析多个字段中的密钥引用
+func ResolveFields(fields ...*string) error {
+	for _, field := range fields {
+		if field == nil {
+			continue
+		}
+		resolved, err := Resolve(*field)
+		if err != nil {
+			return err
+		}
+		*field = resolved
+	}
+	return nil
+}
+
+// ProviderFactory 根据环境变量创建密钥提供方
+type ProviderFactory func() (Provider, error)
+
+var (
+	providerRegistry   = map[string]ProviderFactory{}
+	providerRegistryMu sync.RWMutex
+)
+
+func init() {
+	RegisterProvider("vault", func() (Provider, error) {
+		return NewVaultProvider(os.Getenv("VAULT_ADDR"), os.Getenv("VAULT_TOKEN"), os.Getenv("VAULT_SECRET_PATH"), os.Getenv("VAULT_NAMESPACE"))
+	})
+	RegisterProvider("doppler", func() (Provider, error) {
+		return NewDopplerProvider(os.Getenv("DOPPLER_TOKEN"), os.Getenv("DOPPLER_PROJECT"), os.Getenv("DOPPLER_CONFIG"))
+	})
+	RegisterProvider("aws", func() (Provider, error) {
+		return NewAWSSecretsManagerProvider(AWSCredentials{
+			AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		}, os.Getenv("AWS_REGION"), os.Getenv("AWS_SECRET_ID"))
+	})
+	RegisterProvider("keychain", func() (Provider, error) {
+		return NewKeychainProvider(os.Getenv("KEYCHAIN_SERVICE"), splitList(os.Getenv("KEYCHAIN_KEYS")))
+	})
+	RegisterProvider("file", func() (Provider, error) {
+		return NewEncryptedFileProvider(os.Getenv("SECRETS_FILE"), os.Getenv("SECRETS_FILE_CIPHER"), os.Getenv("SECRETS_AGE_IDENTITY"), os.Getenv("SECRETS_GPG_PASSPHRASE_FILE"))
+	})
+	RegisterProvider("local", func() (Provider, error) {
+		path := KeyFilePathFromEnv()
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("本地密钥文件 %s 不存在，请先运行 nofx keys init", path)
+		}
+		passphrase, err := KeyFilePassphraseFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		return NewLocalKeyFileProvider(path, passphrase)
+	})
+}
+
+// RegisterProvider 注册密钥提供方（SECRETS_PROVIDER=name 时使用），同名注册会覆盖
+func RegisterProvider(name string, factory ProviderFactory) {
+	providerRegistryMu.Lock()
+	defer providerRegistryMu.Unlock()
+	providerRegistry[strings.ToLower(name)] = factory
+}
+
+// RegisteredProviders 已注册的密钥提供方名称（按字母排序）
+func RegisteredProviders() []string {
+	providerRegistryMu.RLock()
+	defer providerRegistryMu.RUnlock()
+
+	names := make([]string, 0, len(providerRegistry))
+	for name := range providerRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewProviderFromEnv 根据环境变量创建密钥提供方
+// SECRETS_PROVIDER: 已注册的提供方名称，内置 vault / doppler / aws / keychain / file / local（为空表示不启用，返回 nil）
+func NewProviderFromEnv() (Provider, error) {
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("SECRETS_PROVIDER")))
+	if name == "" {
+		return nil, nil
+	}
+
+	providerRegistryMu.RLock()
+	factory, ok := providerRegistry[name]
+	providerRegistryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的 SECRETS_PROVIDER: %s（可选 %s）", os.Getenv("SECRETS_PROVIDER"), strings.Join(RegisteredProviders(), " / "))
+	}
+	return factory()
+}
+
+// splitList 解析逗号分隔的列表（忽略空项）
+func splitList(raw string) []string {
+	var items []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// InitFromEnv 根据环境变量初始化全局密钥缓存并启动定时刷新
+// SECRETS_REFRESH_INTERVAL: 刷新间隔（如 "10m"，为空表示只在启动时拉取一次）
+func InitFromEnv() error {
+	provider, err := NewProviderFromEnv()
+	if err != nil {
+		return err
+	}
+	if provider == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	store, err := NewStore(ctx, provider)
+	if err != nil {
+		return err
+	}
+
+	if raw := strings.TrimSpace(os.Getenv("SECRETS_REFRESH_INTERVAL")); raw != "" {
+		interval, err := time.ParseDuration(raw)
+		if err != nil {
+			return fmt.Errorf("SECRETS_REFRESH_INTERVAL 格式错误: %w", err)
+		}
+		store.StartRefresh(interval)
+	}
+
+	SetDefaultStore(store)
+	return nil
+}
@@ -0,0 +1,281 @@
+package secretstore
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticProvider struct {
+	values map[string]string
+	err    error
+	calls  int
+}
+
+func (p *staticProvider) Name() string { return "static" }
+
+func (p *staticProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, p.err
+	}
+	return p.values, nil
+}
+
+func TestResolve(t *testing.T) {
+	SetDefaultStore(nil)
+	defer SetDefaultStore(nil)
+
+	// 非引用值原样返回
+	v, err := Resolve("plain-key")
+	require.NoError(t, err)
+	assert.Equal(t, "plain-key", v)
+
+	// 未配置提供方时引用无法解析
+	_, err = Resolve("secret://BINANCE_API_KEY")
+	assert.Error(t, err)
+
+	store, err := NewStore(context.Background(), &staticProvider{values: map[string]string{"BINANCE_API_KEY": "abc"}})
+	require.NoError(t, err)
+	SetDefaultStore(store)
+
+	v, err = Resolve("secret://BINANCE_API_KEY")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", v)
+
+	_, err = Resolve("secret://MISSING")
+	assert.Error(t, err)
+
+	apiKey, secretKey, empty := "secret://BINANCE_API_KEY", "raw", ""
+	require.NoError(t, ResolveFields(&apiKey, &secretKey, &empty, nil))
+	assert.Equal(t, "abc", apiKey)
+	assert.Equal(t, "raw", secretKey)
+	assert.Equal(t, "", empty)
+}
+
+func TestStoreRefreshKeepsOldValuesOnError(t *testing.T) {
+	provider := &staticProvider{values: map[string]string{"K": "v1"}}
+	store, err := NewStore(context.Background(), provider)
+	require.NoError(t, err)
+
+	provider.err = fmt.Errorf("boom")
+	assert.Error(t, store.Refresh(context.Background()))
+
+	v, ok := store.Get("K")
+	assert.True(t, ok)
+	assert.Equal(t, "v1", v)
+}
+
+func TestVaultProviderFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/secret/data/nofx", r.URL.Path)
+		assert.Equal(t, "tok", r.Header.Get("X-Vault-Token"))
+		assert.Equal(t, "team-a", r.Header.Get("X-Vault-Namespace"))
+		fmt.Fprint(w, `{"data":{"data":{"BINANCE_API_KEY":"abc","PORT":8080},"metadata":{"version":3}}}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(server.URL+"/", "tok", "/secret/data/nofx", "team-a")
+	require.NoError(t, err)
+
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "abc", values["BINANCE_API_KEY"])
+	assert.Equal(t, "8080", values["PORT"])
+
+	_, err = NewVaultProvider("", "tok", "secret/data/nofx", "")
+	assert.Error(t, err)
+}
+
+func TestVaultProviderHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"errors":["permission denied"]}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewVaultProvider(server.URL, "bad", "secret/data/nofx", "")
+	require.NoError(t, err)
+
+	_, err = provider.Fetch(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "403")
+}
+
+func TestDopplerProviderFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/configs/config/secrets/download", r.URL.Path)
+		assert.Equal(t, "json", r.URL.Query().Get("format"))
+		assert.Equal(t, "nofx", r.URL.Query().Get("project"))
+		assert.Equal(t, "prd", r.URL.Query().Get("config"))
+		assert.Equal(t, "Bearer dp.st.xxx", r.Header.Get("Authorization"))
+		fmt.Fprint(w, `{"DEEPSEEK_API_KEY":"sk-1"}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewDopplerProvider("dp.st.xxx", "nofx", "prd")
+	require.NoError(t, err)
+	provider.baseURL = server.URL
+
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", values["DEEPSEEK_API_KEY"])
+}
+
+func TestAWSSecretsManagerProviderFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "secretsmanager.GetSecretValue", r.Header.Get("X-Amz-Target"))
+		assert.Equal(t, "session", r.Header.Get("X-Amz-Security-Token"))
+		assert.Equal(t, "20240101T000000Z", r.Header.Get("X-Amz-Date"))
+
+		auth := r.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKID/20240101/us-east-1/secretsmanager/aws4_request"))
+		assert.Contains(t, auth, "SignedHeaders=content-type;host;x-amz-date;x-amz-security-token;x-amz-target")
+
+		fmt.Fprint(w, `{"Name":"nofx","SecretString":"{\"HYPERLIQUID_PRIVATE_KEY\":\"0xabc\"}"}`)
+	}))
+	defer server.Close()
+
+	provider, err := NewAWSSecretsManagerProvider(AWSCredentials{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	}, "us-east-1", "nofx")
+	require.NoError(t, err)
+	provider.endpoint = server.URL
+	provider.now = func() time.Time { return time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC) }
+
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "0xabc", values["HYPERLIQUID_PRIVATE_KEY"])
+}
+
+func TestNewProviderFromEnv(t *testing.T) {
+	t.Setenv("SECRETS_PROVIDER", "")
+	provider, err := NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, provider)
+
+	t.Setenv("SECRETS_PROVIDER", "doppler")
+	t.Setenv("DOPPLER_TOKEN", "dp.st.xxx")
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "doppler", provider.Name())
+
+	t.Setenv("SECRETS_PROVIDER", "keychain")
+	t.Setenv("KEYCHAIN_KEYS", "BINANCE_API_KEY, BINANCE_SECRET_KEY")
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"BINANCE_API_KEY", "BINANCE_SECRET_KEY"}, provider.(*KeychainProvider).keys)
+
+	t.Setenv("SECRETS_PROVIDER", "onepassword")
+	_, err = NewProviderFromEnv()
+	assert.Error(t, err)
+
+	// 注册自定义提供方后即可通过 SECRETS_PROVIDER 选择
+	RegisterProvider("onepassword", func() (Provider, error) { return &staticProvider{}, nil })
+	defer func() {
+		providerRegistryMu.Lock()
+		delete(providerRegistry, "onepassword")
+		providerRegistryMu.Unlock()
+	}()
+	provider, err = NewProviderFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "static", provider.Name())
+	assert.Contains(t, RegisteredProviders(), "onepassword")
+}
+
+// stubCommand 替换 runCommand，记录调用参数并返回 outputs[命令名]
+func stubCommand(t *testing.T, outputs map[string]string) *[][]string {
+	t.Helper()
+	var calls [][]string
+	original := runCommand
+	runCommand = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		out, ok := outputs[name+" "+args[len(args)-1]]
+		if !ok {
+			out, ok = outputs[name]
+		}
+		if !ok {
+			return nil, fmt.Errorf("%s: not found", name)
+		}
+		return []byte(out), nil
+	}
+	t.Cleanup(func() { runCommand = original })
+	return &calls
+}
+
+func TestKeychainProviderFetch(t *testing.T) {
+	calls := stubCommand(t, map[string]string{"security": "abc\n"})
+
+	provider, err := NewKeychainProvider("", []string{"BINANCE_API_KEY"})
+	require.NoError(t, err)
+	provider.goos = "darwin"
+
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "abc", values["BINANCE_API_KEY"])
+	assert.Equal(t, []string{"security", "find-generic-password", "-s", "nofx", "-a", "BINANCE_API_KEY", "-w"}, (*calls)[0])
+
+	// Linux secret-tool：缺失的条目导致整体失败
+	calls = stubCommand(t, map[string]string{"secret-tool BINANCE_API_KEY": "abc"})
+	provider, err = NewKeychainProvider("nofx-prod", []string{"BINANCE_API_KEY", "BINANCE_SECRET_KEY"})
+	require.NoError(t, err)
+	provider.goos = "linux"
+
+	_, err = provider.Fetch(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "nofx-prod/BINANCE_SECRET_KEY")
+	assert.Equal(t, []string{"secret-tool", "lookup", "service", "nofx-prod", "account", "BINANCE_API_KEY"}, (*calls)[0])
+
+	_, err = NewKeychainProvider("nofx", nil)
+	assert.Error(t, err)
+}
+
+func TestEncryptedFileProviderFetch(t *testing.T) {
+	dir := t.TempDir()
+	agePath := dir + "/secrets.env.age"
+	gpgPath := dir + "/secrets.json.gpg"
+	require.NoError(t, os.WriteFile(agePath, []byte("ciphertext"), 0600))
+	require.NoError(t, os.WriteFile(gpgPath, []byte("ciphertext"), 0600))
+
+	calls := stubCommand(t, map[string]string{
+		"age " + agePath: "# exchange keys\nexport BINANCE_API_KEY=abc\nBINANCE_SECRET_KEY=\"s=1\"\n",
+		"gpg " + gpgPath: `{"DEEPSEEK_API_KEY":"sk-1"}`,
+	})
+
+	provider, err := NewEncryptedFileProvider(agePath, "", dir+"/key.txt", "")
+	require.NoError(t, err)
+	assert.Equal(t, "file(age)", provider.Name())
+	values, err := provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"BINANCE_API_KEY": "abc", "BINANCE_SECRET_KEY": "s=1"}, values)
+	assert.Equal(t, []string{"age", "--decrypt", "-i", dir + "/key.txt", agePath}, (*calls)[0])
+
+	provider, err = NewEncryptedFileProvider(gpgPath, "", "", dir+"/pass")
+	require.NoError(t, err)
+	values, err = provider.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", values["DEEPSEEK_API_KEY"])
+	assert.Equal(t, []string{"gpg", "--batch", "--quiet", "--decrypt", "--pinentry-mode", "loopback", "--passphrase-file", dir + "/pass", gpgPath}, (*calls)[1])
+
+	// 配置错误
+	_, err = NewEncryptedFileProvider(agePath, "", "", "")
+	assert.Error(t, err, "age 需要私钥文件")
+	_, err = NewEncryptedFileProvider(dir+"/secrets.txt", "", "", "")
+	assert.Error(t, err, "无法判断加密方式")
+	_, err = NewEncryptedFileProvider(dir+"/secrets.txt", "sops", "", "")
+	assert.Error(t, err)
+
+	_, err = parseSecretsFile([]byte("BINANCE_API_KEY"))
+	assert.Error(t, err)
+}
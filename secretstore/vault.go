@@ -0,0 +1,93 @@
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// VaultProvider HashiCorp Vault KV v2 密钥提供方
+type VaultProvider struct {
+	addr      string
+	token     string
+	path      string // KV v2 API 路径，如 "secret/data/nofx"
+	namespace string // Vault Enterprise 命名空间（可选）
+	client    *http.Client
+}
+
+// NewVaultProvider 创建 Vault 密钥提供方
+func NewVaultProvider(addr, token, path, namespace string) (*VaultProvider, error) {
+	if addr == "" || token == "" || path == "" {
+		return nil, fmt.Errorf("vault 配置不完整：需要 VAULT_ADDR、VAULT_TOKEN、VAULT_SECRET_PATH")
+	}
+
+	return &VaultProvider{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		path:      strings.Trim(path, "/"),
+		namespace: namespace,
+		client:    &http.Client{Timeout: 15 * time.Second},
+	}, nil
+}
+
+// Name 提供方名称
+func (p *VaultProvider) Name() string {
+	return "vault"
+}
+
+// Fetch 读取 KV v2 密钥
+func (p *VaultProvider) Fetch(ctx context.Context) (map[string]string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/%s", p.addr, p.path), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	if p.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", p.namespace)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault 返回 HTTP %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result struct {
+		Data struct {
+			Data map[string]interface{} `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("解析 vault 响应失败: %w", err)
+	}
+
+	return stringifyValues(result.Data.Data), nil
+}
+
+// stringifyValues 将 JSON 值统一转换为字符串
+func stringifyValues(raw map[string]interface{}) map[string]string {
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		switch v := value.(type) {
+		case string:
+			values[key] = v
+		case nil:
+			continue
+		default:
+			values[key] = fmt.Sprintf("%v", v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,241 @@
+// Package signalrpc 外部策略接入的 gRPC 服务：外部进程推送交易决策（经过与 TradingView 告警相同的风控和校验），
+// 并订阅成交、开仓和风控事件，便于用其他语言开发策略
+package signalrpc
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"sort"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"nofx/decision"
+	"nofx/eventbus"
+	"nofx/manager"
+	"nofx/signalrpc/signalpb"
+	"nofx/trader"
+)
+
+// eventBuffer 每个事件订阅的缓冲大小（订阅者处理过慢时事件总线丢弃事件）
+const eventBuffer = 256
+
+// Trader 可接收外部决策的交易员（*trader.AutoTrader 实现）
+type Trader interface {
+	GetID() string
+	ExecuteSignal(sig trader.Signal) (decision.Decision, error)
+}
+
+// TraderSource 返回当前已加载的交易员
+type TraderSource func() []Trader
+
+// FromManager 以 TraderManager 中已加载的交易员作为数据源
+func FromManager(tm *manager.TraderManager) TraderSource {
+	return func() []Trader {
+		all := tm.GetAllTraders()
+		traders := make([]Trader, 0, len(all))
+		for _, t := range all {
+			traders = append(traders, t)
+		}
+		return traders
+	}
+}
+
+// Server 外部策略接入服务（使用固定 token 认证）
+type Server struct {
+	signalpb.UnimplementedSignalServiceServer
+
+	addr    string
+	token   string
+	traders TraderSource
+	bus     *eventbus.Bus
+	grpc    *grpc.Server
+}
+
+// NewServer 创建外部策略接入服务（token 不能为空）
+func NewServer(addr, token string, traders TraderSource) (*Server, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("外部策略接入服务需要设置访问 token")
+	}
+	s := &Server{addr: addr, token: token, traders: traders, bus: eventbus.Default}
+	s.grpc = grpc.NewServer(grpc.StreamInterceptor(s.authStream))
+	signalpb.RegisterSignalServiceServer(s.grpc, s)
+	return s, nil
+}
+
+// Start 启动服务（阻塞直到关闭）
+func (s *Server) Start() error {
+	lis, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听 %s 失败: %w", s.addr, err)
+	}
+	return s.Serve(lis)
+}
+
+// Serve 在已有的监听器上提供服务（测试中使用内存监听器）
+func (s *Server) Serve(lis net.Listener) error {
+	log.Printf("🔌 外部策略 gRPC 服务启动在 %s (nofx.signal.v1.SignalService)", lis.Addr())
+	if err := s.grpc.Serve(lis); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// Shutdown 优雅关闭：事件订阅流立即结束，等待进行中的决策执行完成
+func (s *Server) Shutdown() {
+	s.grpc.GracefulStop()
+}
+
+// authStream 校验 metadata 中的 authorization: Bearer <token>
+func (s *Server) authStream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	md, _ := metadata.FromIncomingContext(ss.Context())
+	var token string
+	if values := md.Get("authorization"); len(values) > 0 {
+		token, _ = strings.CutPrefix(values[0], "Bearer ")
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+		return status.Error(codes.Unauthenticated, "无效的访问 token")
+	}
+	return handler(srv, ss)
+}
+
+// StreamDecisions 实现 SignalServiceServer：按接收顺序逐条执行决策并返回结果，单条决策失败不影响后续决策
+func (s *Server) StreamDecisions(stream signalpb.SignalService_StreamDecisionsServer) error {
+	for {
+		req, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := stream.Send(s.execute(req)); err != nil {
+			return err
+		}
+	}
+}
+
+// execute 执行一条外部决策
+func (s *Server) execute(req *signalpb.DecisionRequest) *signalpb.DecisionResult {
+	result := &signalpb.DecisionResult{RequestId: req.GetRequestId(), Symbol: strings.ToUpper(req.GetSymbol()), Action: req.GetAction()}
+	t, err := s.findTrader(req.GetTraderId())
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.TraderId = t.GetID()
+
+	reason := req.GetReasoning()
+	if reason == "" {
+		reason = "外部策略决策"
+	}
+	d, err := t.ExecuteSignal(trader.Signal{
+		Symbol:          req.GetSymbol(),
+		Action:          req.GetAction(),
+		SizeUSD:         req.GetSizeUsd(),
+		SizePct:         req.GetSizePct(),
+		Quantity:        req.GetQuantity(),
+		Leverage:        int(req.GetLeverage()),
+		StopLoss:        req.GetStopLoss(),
+		TakeProfit:      req.GetTakeProfit(),
+		StopLossPct:     req.GetStopLossPct(),
+		TakeProfitPct:   req.GetTakeProfitPct(),
+		ClosePercentage: req.GetClosePercentage(),
+		Price:           req.GetPrice(),
+		Reason:          reason,
+	})
+	result.Symbol, result.Action = d.Symbol, d.Action
+	result.PositionSizeUsd, result.Leverage = d.PositionSizeUSD, int32(d.Leverage)
+	result.StopLoss, result.TakeProfit = d.StopLoss, d.TakeProfit
+	if err != nil {
+		log.Printf("⚠️  外部策略决策 %s %s 未执行: %v", d.Symbol, d.Action, err)
+		result.Error = err.Error()
+		return result
+	}
+	result.Accepted = true
+	return result
+}
+
+// findTrader 按 ID 查找交易员（只有一个交易员时 ID 可为空）
+func (s *Server) findTrader(id string) (Trader, error) {
+	traders := s.traders()
+	if id == "" {
+		if len(traders) == 1 {
+			return traders[0], nil
+		}
+		ids := make([]string, 0, len(traders))
+		for _, t := range traders {
+			ids = append(ids, t.GetID())
+		}
+		sort.Strings(ids)
+		return nil, fmt.Errorf("存在 %d 个交易员，请通过 trader_id 指定: %s", len(traders), strings.Join(ids, ", "))
+	}
+	for _, t := range traders {
+		if t.GetID() == id {
+			return t, nil
+		}
+	}
+	return nil, fmt.Errorf("交易员不存在: %s", id)
+}
+
+// SubscribeEvents 实现 SignalServiceServer：转发事件总线上的成交、开仓和风控事件
+func (s *Server) SubscribeEvents(req *signalpb.SubscribeRequest, stream signalpb.SignalService_SubscribeEventsServer) error {
+	topics := req.GetTopics()
+	if len(topics) == 0 {
+		topics = []string{eventbus.TopicOrderFilled, eventbus.TopicPositionOpened, eventbus.TopicRiskTripped}
+	}
+	for _, topic := range topics {
+		if topic != eventbus.TopicOrderFilled && topic != eventbus.TopicPositionOpened && topic != eventbus.TopicRiskTripped {
+			return status.Errorf(codes.InvalidArgument, "不支持订阅 %q（支持 order_filled、position_opened、risk_tripped）", topic)
+		}
+	}
+	events, cancel := s.bus.Subscribe(eventBuffer, topics...)
+	defer cancel()
+
+	ctx := stream.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case ev, ok := <-events:
+			if !ok {
+				return nil
+			}
+			msg, traderID := toProto(ev)
+			if msg == nil || (req.GetTraderId() != "" && traderID != req.GetTraderId()) {
+				continue
+			}
+			if err := stream.Send(msg); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// toProto 事件总线事件转换为 gRPC 消息，同时返回事件所属的交易员
+func toProto(ev eventbus.Event) (*signalpb.Event, string) {
+	switch e := ev.(type) {
+	case eventbus.OrderFilled:
+		return &signalpb.Event{Topic: e.Topic(), TimeUnixMs: e.Time.UnixMilli(), Payload: &signalpb.Event_OrderFilled{OrderFilled: &signalpb.OrderFilled{
+			TraderId: e.TraderID, Exchange: e.Exchange, Symbol: e.Symbol, Side: e.Side, Action: e.Action,
+			OrderId: e.OrderID, Quantity: e.Quantity, Price: e.Price, Fee: e.Fee,
+		}}}, e.TraderID
+	case eventbus.PositionOpened:
+		return &signalpb.Event{Topic: e.Topic(), TimeUnixMs: e.Time.UnixMilli(), Payload: &signalpb.Event_PositionOpened{PositionOpened: &signalpb.PositionOpened{
+			TraderId: e.TraderID, Exchange: e.Exchange, Symbol: e.Symbol, Side: e.Side,
+			Quantity: e.Quantity, EntryPrice: e.EntryPrice, Leverage: int32(e.Leverage),
+		}}}, e.TraderID
+	case eventbus.RiskTripped:
+		return &signalpb.Event{Topic: e.Topic(), TimeUnixMs: e.Time.UnixMilli(), Payload: &signalpb.Event_RiskTripped{RiskTripped: &signalpb.RiskTripped{
+			Source: e.Source, Symbol: e.Symbol, Reason: e.Reason, Blocking: e.Blocking,
+		}}}, e.Source
+	}
+	return nil, ""
+}
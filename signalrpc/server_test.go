@@ -0,0 +1,162 @@
+package signalrpc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"nofx/decision"
+	"nofx/eventbus"
+	"nofx/signalrpc/signalpb"
+	"nofx/trader"
+)
+
+// fakeTrader 记录外部决策的测试交易员
+type fakeTrader struct {
+	id      string
+	signals []trader.Signal
+}
+
+func (f *fakeTrader) GetID() string { return f.id }
+
+func (f *fakeTrader) ExecuteSignal(sig trader.Signal) (decision.Decision, error) {
+	f.signals = append(f.signals, sig)
+	if sig.Action == "open_short" {
+		return decision.Decision{Symbol: sig.Symbol, Action: sig.Action}, errors.New("风险控制暂停中")
+	}
+	return decision.Decision{Symbol: sig.Symbol, Action: sig.Action, PositionSizeUSD: sig.SizeUSD, Leverage: 3}, nil
+}
+
+// startTestServer 在内存监听器上启动服务，返回客户端
+func startTestServer(t *testing.T, traders ...Trader) (*Server, signalpb.SignalServiceClient) {
+	t.Helper()
+	s, err := NewServer("", "secret", func() []Trader { return traders })
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	s.bus = eventbus.New()
+	lis := bufconn.Listen(1 << 20)
+	go s.Serve(lis)
+	t.Cleanup(s.Shutdown)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return s, signalpb.NewSignalServiceClient(conn)
+}
+
+func authorized(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer secret")
+}
+
+func TestNewServerRequiresToken(t *testing.T) {
+	if _, err := NewServer(":0", "", func() []Trader { return nil }); err == nil {
+		t.Fatal("expected error for empty token")
+	}
+}
+
+func TestStreamDecisions(t *testing.T) {
+	tr := &fakeTrader{id: "a"}
+	_, client := startTestServer(t, tr)
+
+	stream, err := client.StreamDecisions(authorized(t))
+	if err != nil {
+		t.Fatalf("StreamDecisions: %v", err)
+	}
+	requests := []*signalpb.DecisionRequest{
+		{RequestId: "1", Symbol: "btcusdt", Action: "open_long", SizeUsd: 200, StopLossPct: 2, TakeProfitPct: 6, Reasoning: "model score 0.8"},
+		{RequestId: "2", Symbol: "ETHUSDT", Action: "open_short", SizeUsd: 100},
+		{RequestId: "3", TraderId: "missing", Symbol: "ETHUSDT", Action: "close_long"},
+	}
+	for _, req := range requests {
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send: %v", err)
+		}
+	}
+	stream.CloseSend()
+
+	var results []*signalpb.DecisionResult
+	for range requests {
+		res, err := stream.Recv()
+		if err != nil {
+			t.Fatalf("Recv: %v", err)
+		}
+		results = append(results, res)
+	}
+	if r := results[0]; r.RequestId != "1" || !r.Accepted || r.TraderId != "a" || r.PositionSizeUsd != 200 || r.Leverage != 3 {
+		t.Errorf("unexpected result for accepted decision: %+v", r)
+	}
+	if r := results[1]; r.Accepted || r.Error == "" {
+		t.Errorf("rejected decision should carry the error: %+v", r)
+	}
+	if r := results[2]; r.Accepted || r.Error == "" {
+		t.Errorf("unknown trader should be reported: %+v", r)
+	}
+	if len(tr.signals) != 2 || tr.signals[0].StopLossPct != 2 || tr.signals[0].Reason != "model score 0.8" {
+		t.Errorf("unexpected signals: %+v", tr.signals)
+	}
+}
+
+func TestAuthRequired(t *testing.T) {
+	_, client := startTestServer(t, &fakeTrader{id: "a"})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	stream, err := client.SubscribeEvents(metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer wrong"), &signalpb.SubscribeRequest{})
+	if err == nil {
+		_, err = stream.Recv()
+	}
+	if status.Code(err) != codes.Unauthenticated {
+		t.Errorf("expected Unauthenticated, got %v", err)
+	}
+}
+
+func TestSubscribeEvents(t *testing.T) {
+	s, client := startTestServer(t, &fakeTrader{id: "a"})
+	stream, err := client.SubscribeEvents(authorized(t), &signalpb.SubscribeRequest{TraderId: "a"})
+	if err != nil {
+		t.Fatalf("SubscribeEvents: %v", err)
+	}
+
+	// 等待订阅建立后再发布事件
+	deadline := time.Now().Add(2 * time.Second)
+	go func() {
+		for time.Now().Before(deadline) {
+			s.bus.Publish(eventbus.OrderFilled{TraderID: "b", Symbol: "ETHUSDT", Time: time.Now()})
+			s.bus.Publish(eventbus.OrderFilled{TraderID: "a", Symbol: "BTCUSDT", Side: "long", Action: "open", Quantity: 0.1, Price: 50000, Time: time.Now()})
+			time.Sleep(20 * time.Millisecond)
+		}
+	}()
+
+	ev, err := stream.Recv()
+	if err != nil {
+		t.Fatalf("Recv: %v", err)
+	}
+	fill := ev.GetOrderFilled()
+	if ev.Topic != eventbus.TopicOrderFilled || fill == nil || fill.TraderId != "a" || fill.Symbol != "BTCUSDT" || fill.Price != 50000 {
+		t.Errorf("unexpected event: %+v", ev)
+	}
+
+	bad, err := client.SubscribeEvents(authorized(t), &signalpb.SubscribeRequest{Topics: []string{"candle_closed"}})
+	if err == nil {
+		_, err = bad.Recv()
+	}
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("expected InvalidArgument for unsupported topic, got %v", err)
+	}
+}
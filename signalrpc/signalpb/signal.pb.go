@@ -0,0 +1,878 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: signalrpc/signal.proto
+
+package signalpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// DecisionRequest 一条交易决策，仓位和止损止盈可以只给出提示（与 TradingView 告警相同的换算规则）
+type DecisionRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RequestId       string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"` // 客户端自定义 ID，原样返回
+	TraderId        string                 `protobuf:"bytes,2,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`    // 只有一个交易员时可省略
+	Symbol          string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Action          string                 `protobuf:"bytes,4,opt,name=action,proto3" json:"action,omitempty"` // open_long / open_short / close_long / close_short / partial_close
+	SizeUsd         float64                `protobuf:"fixed64,5,opt,name=size_usd,json=sizeUsd,proto3" json:"size_usd,omitempty"`
+	SizePct         float64                `protobuf:"fixed64,6,opt,name=size_pct,json=sizePct,proto3" json:"size_pct,omitempty"` // 或：按账户净值百分比
+	Quantity        float64                `protobuf:"fixed64,7,opt,name=quantity,proto3" json:"quantity,omitempty"`              // 或：按数量
+	Leverage        int32                  `protobuf:"varint,8,opt,name=leverage,proto3" json:"leverage,omitempty"`               // 0=使用交易员配置的杠杆
+	StopLoss        float64                `protobuf:"fixed64,9,opt,name=stop_loss,json=stopLoss,proto3" json:"stop_loss,omitempty"`
+	TakeProfit      float64                `protobuf:"fixed64,10,opt,name=take_profit,json=takeProfit,proto3" json:"take_profit,omitempty"`
+	StopLossPct     float64                `protobuf:"fixed64,11,opt,name=stop_loss_pct,json=stopLossPct,proto3" json:"stop_loss_pct,omitempty"`
+	TakeProfitPct   float64                `protobuf:"fixed64,12,opt,name=take_profit_pct,json=takeProfitPct,proto3" json:"take_profit_pct,omitempty"`
+	ClosePercentage float64                `protobuf:"fixed64,13,opt,name=close_percentage,json=closePercentage,proto3" json:"close_percentage,omitempty"` // partial_close 的平仓比例（%）
+	Price           float64                `protobuf:"fixed64,14,opt,name=price,proto3" json:"price,omitempty"`                                            // 参考价（0=当前市价）
+	Reasoning       string                 `protobuf:"bytes,15,opt,name=reasoning,proto3" json:"reasoning,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DecisionRequest) Reset() {
+	*x = DecisionRequest{}
+	mi := &file_signalrpc_signal_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionRequest) ProtoMessage() {}
+
+func (x *DecisionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionRequest.ProtoReflect.Descriptor instead.
+func (*DecisionRequest) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *DecisionRequest) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *DecisionRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *DecisionRequest) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *DecisionRequest) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *DecisionRequest) GetSizeUsd() float64 {
+	if x != nil {
+		return x.SizeUsd
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetSizePct() float64 {
+	if x != nil {
+		return x.SizePct
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetStopLoss() float64 {
+	if x != nil {
+		return x.StopLoss
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetTakeProfit() float64 {
+	if x != nil {
+		return x.TakeProfit
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetStopLossPct() float64 {
+	if x != nil {
+		return x.StopLossPct
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetTakeProfitPct() float64 {
+	if x != nil {
+		return x.TakeProfitPct
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetClosePercentage() float64 {
+	if x != nil {
+		return x.ClosePercentage
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *DecisionRequest) GetReasoning() string {
+	if x != nil {
+		return x.Reasoning
+	}
+	return ""
+}
+
+// DecisionResult 决策执行结果
+type DecisionResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RequestId       string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	TraderId        string                 `protobuf:"bytes,2,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Accepted        bool                   `protobuf:"varint,3,opt,name=accepted,proto3" json:"accepted,omitempty"` // 通过风控并执行成功
+	Error           string                 `protobuf:"bytes,4,opt,name=error,proto3" json:"error,omitempty"`        // 被拒绝或执行失败的原因
+	Symbol          string                 `protobuf:"bytes,5,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Action          string                 `protobuf:"bytes,6,opt,name=action,proto3" json:"action,omitempty"`
+	PositionSizeUsd float64                `protobuf:"fixed64,7,opt,name=position_size_usd,json=positionSizeUsd,proto3" json:"position_size_usd,omitempty"` // 换算后的开仓金额
+	Leverage        int32                  `protobuf:"varint,8,opt,name=leverage,proto3" json:"leverage,omitempty"`
+	StopLoss        float64                `protobuf:"fixed64,9,opt,name=stop_loss,json=stopLoss,proto3" json:"stop_loss,omitempty"`
+	TakeProfit      float64                `protobuf:"fixed64,10,opt,name=take_profit,json=takeProfit,proto3" json:"take_profit,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *DecisionResult) Reset() {
+	*x = DecisionResult{}
+	mi := &file_signalrpc_signal_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DecisionResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DecisionResult) ProtoMessage() {}
+
+func (x *DecisionResult) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DecisionResult.ProtoReflect.Descriptor instead.
+func (*DecisionResult) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *DecisionResult) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *DecisionResult) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *DecisionResult) GetAccepted() bool {
+	if x != nil {
+		return x.Accepted
+	}
+	return false
+}
+
+func (x *DecisionResult) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *DecisionResult) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *DecisionResult) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *DecisionResult) GetPositionSizeUsd() float64 {
+	if x != nil {
+		return x.PositionSizeUsd
+	}
+	return 0
+}
+
+func (x *DecisionResult) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+func (x *DecisionResult) GetStopLoss() float64 {
+	if x != nil {
+		return x.StopLoss
+	}
+	return 0
+}
+
+func (x *DecisionResult) GetTakeProfit() float64 {
+	if x != nil {
+		return x.TakeProfit
+	}
+	return 0
+}
+
+// SubscribeRequest 订阅条件
+type SubscribeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Topics        []string               `protobuf:"bytes,1,rep,name=topics,proto3" json:"topics,omitempty"`                     // order_filled / position_opened / risk_tripped，空=全部
+	TraderId      string                 `protobuf:"bytes,2,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"` // 空=所有交易员
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeRequest) Reset() {
+	*x = SubscribeRequest{}
+	mi := &file_signalrpc_signal_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SubscribeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SubscribeRequest) ProtoMessage() {}
+
+func (x *SubscribeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SubscribeRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeRequest) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SubscribeRequest) GetTopics() []string {
+	if x != nil {
+		return x.Topics
+	}
+	return nil
+}
+
+func (x *SubscribeRequest) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+// Event 事件
+type Event struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Topic      string                 `protobuf:"bytes,1,opt,name=topic,proto3" json:"topic,omitempty"`
+	TimeUnixMs int64                  `protobuf:"varint,2,opt,name=time_unix_ms,json=timeUnixMs,proto3" json:"time_unix_ms,omitempty"`
+	// Types that are valid to be assigned to Payload:
+	//
+	//	*Event_OrderFilled
+	//	*Event_PositionOpened
+	//	*Event_RiskTripped
+	Payload       isEvent_Payload `protobuf_oneof:"payload"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_signalrpc_signal_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Event) GetTopic() string {
+	if x != nil {
+		return x.Topic
+	}
+	return ""
+}
+
+func (x *Event) GetTimeUnixMs() int64 {
+	if x != nil {
+		return x.TimeUnixMs
+	}
+	return 0
+}
+
+func (x *Event) GetPayload() isEvent_Payload {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+func (x *Event) GetOrderFilled() *OrderFilled {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_OrderFilled); ok {
+			return x.OrderFilled
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetPositionOpened() *PositionOpened {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_PositionOpened); ok {
+			return x.PositionOpened
+		}
+	}
+	return nil
+}
+
+func (x *Event) GetRiskTripped() *RiskTripped {
+	if x != nil {
+		if x, ok := x.Payload.(*Event_RiskTripped); ok {
+			return x.RiskTripped
+		}
+	}
+	return nil
+}
+
+type isEvent_Payload interface {
+	isEvent_Payload()
+}
+
+type Event_OrderFilled struct {
+	OrderFilled *OrderFilled `protobuf:"bytes,3,opt,name=order_filled,json=orderFilled,proto3,oneof"`
+}
+
+type Event_PositionOpened struct {
+	PositionOpened *PositionOpened `protobuf:"bytes,4,opt,name=position_opened,json=positionOpened,proto3,oneof"`
+}
+
+type Event_RiskTripped struct {
+	RiskTripped *RiskTripped `protobuf:"bytes,5,opt,name=risk_tripped,json=riskTripped,proto3,oneof"`
+}
+
+func (*Event_OrderFilled) isEvent_Payload() {}
+
+func (*Event_PositionOpened) isEvent_Payload() {}
+
+func (*Event_RiskTripped) isEvent_Payload() {}
+
+// OrderFilled 订单成交（开仓或平仓）
+type OrderFilled struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Exchange      string                 `protobuf:"bytes,2,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          string                 `protobuf:"bytes,4,opt,name=side,proto3" json:"side,omitempty"`     // long / short
+	Action        string                 `protobuf:"bytes,5,opt,name=action,proto3" json:"action,omitempty"` // open / close
+	OrderId       int64                  `protobuf:"varint,6,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,7,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	Price         float64                `protobuf:"fixed64,8,opt,name=price,proto3" json:"price,omitempty"`
+	Fee           float64                `protobuf:"fixed64,9,opt,name=fee,proto3" json:"fee,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *OrderFilled) Reset() {
+	*x = OrderFilled{}
+	mi := &file_signalrpc_signal_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OrderFilled) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OrderFilled) ProtoMessage() {}
+
+func (x *OrderFilled) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OrderFilled.ProtoReflect.Descriptor instead.
+func (*OrderFilled) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *OrderFilled) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *OrderFilled) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *OrderFilled) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *OrderFilled) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *OrderFilled) GetAction() string {
+	if x != nil {
+		return x.Action
+	}
+	return ""
+}
+
+func (x *OrderFilled) GetOrderId() int64 {
+	if x != nil {
+		return x.OrderId
+	}
+	return 0
+}
+
+func (x *OrderFilled) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *OrderFilled) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *OrderFilled) GetFee() float64 {
+	if x != nil {
+		return x.Fee
+	}
+	return 0
+}
+
+// PositionOpened 开仓成交
+type PositionOpened struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TraderId      string                 `protobuf:"bytes,1,opt,name=trader_id,json=traderId,proto3" json:"trader_id,omitempty"`
+	Exchange      string                 `protobuf:"bytes,2,opt,name=exchange,proto3" json:"exchange,omitempty"`
+	Symbol        string                 `protobuf:"bytes,3,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Side          string                 `protobuf:"bytes,4,opt,name=side,proto3" json:"side,omitempty"`
+	Quantity      float64                `protobuf:"fixed64,5,opt,name=quantity,proto3" json:"quantity,omitempty"`
+	EntryPrice    float64                `protobuf:"fixed64,6,opt,name=entry_price,json=entryPrice,proto3" json:"entry_price,omitempty"`
+	Leverage      int32                  `protobuf:"varint,7,opt,name=leverage,proto3" json:"leverage,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PositionOpened) Reset() {
+	*x = PositionOpened{}
+	mi := &file_signalrpc_signal_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PositionOpened) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PositionOpened) ProtoMessage() {}
+
+func (x *PositionOpened) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PositionOpened.ProtoReflect.Descriptor instead.
+func (*PositionOpened) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *PositionOpened) GetTraderId() string {
+	if x != nil {
+		return x.TraderId
+	}
+	return ""
+}
+
+func (x *PositionOpened) GetExchange() string {
+	if x != nil {
+		return x.Exchange
+	}
+	return ""
+}
+
+func (x *PositionOpened) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *PositionOpened) GetSide() string {
+	if x != nil {
+		return x.Side
+	}
+	return ""
+}
+
+func (x *PositionOpened) GetQuantity() float64 {
+	if x != nil {
+		return x.Quantity
+	}
+	return 0
+}
+
+func (x *PositionOpened) GetEntryPrice() float64 {
+	if x != nil {
+		return x.EntryPrice
+	}
+	return 0
+}
+
+func (x *PositionOpened) GetLeverage() int32 {
+	if x != nil {
+		return x.Leverage
+	}
+	return 0
+}
+
+// RiskTripped 风控触发
+type RiskTripped struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"` // 交易员 ID 或策略名
+	Symbol        string                 `protobuf:"bytes,2,opt,name=symbol,proto3" json:"symbol,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	Blocking      bool                   `protobuf:"varint,4,opt,name=blocking,proto3" json:"blocking,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RiskTripped) Reset() {
+	*x = RiskTripped{}
+	mi := &file_signalrpc_signal_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RiskTripped) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RiskTripped) ProtoMessage() {}
+
+func (x *RiskTripped) ProtoReflect() protoreflect.Message {
+	mi := &file_signalrpc_signal_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RiskTripped.ProtoReflect.Descriptor instead.
+func (*RiskTripped) Descriptor() ([]byte, []int) {
+	return file_signalrpc_signal_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RiskTripped) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *RiskTripped) GetSymbol() string {
+	if x != nil {
+		return x.Symbol
+	}
+	return ""
+}
+
+func (x *RiskTripped) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *RiskTripped) GetBlocking() bool {
+	if x != nil {
+		return x.Blocking
+	}
+	return false
+}
+
+var File_signalrpc_signal_proto protoreflect.FileDescriptor
+
+const file_signalrpc_signal_proto_rawDesc = "" +
+	"\n" +
+	"\x16signalrpc/signal.proto\x12\x0enofx.signal.v1\"\xd4\x03\n" +
+	"\x0fDecisionRequest\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
+	"\ttrader_id\x18\x02 \x01(\tR\btraderId\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06action\x18\x04 \x01(\tR\x06action\x12\x19\n" +
+	"\bsize_usd\x18\x05 \x01(\x01R\asizeUsd\x12\x19\n" +
+	"\bsize_pct\x18\x06 \x01(\x01R\asizePct\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\x01R\bquantity\x12\x1a\n" +
+	"\bleverage\x18\b \x01(\x05R\bleverage\x12\x1b\n" +
+	"\tstop_loss\x18\t \x01(\x01R\bstopLoss\x12\x1f\n" +
+	"\vtake_profit\x18\n" +
+	" \x01(\x01R\n" +
+	"takeProfit\x12\"\n" +
+	"\rstop_loss_pct\x18\v \x01(\x01R\vstopLossPct\x12&\n" +
+	"\x0ftake_profit_pct\x18\f \x01(\x01R\rtakeProfitPct\x12)\n" +
+	"\x10close_percentage\x18\r \x01(\x01R\x0fclosePercentage\x12\x14\n" +
+	"\x05price\x18\x0e \x01(\x01R\x05price\x12\x1c\n" +
+	"\treasoning\x18\x0f \x01(\tR\treasoning\"\xb4\x02\n" +
+	"\x0eDecisionResult\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x12\x1b\n" +
+	"\ttrader_id\x18\x02 \x01(\tR\btraderId\x12\x1a\n" +
+	"\baccepted\x18\x03 \x01(\bR\baccepted\x12\x14\n" +
+	"\x05error\x18\x04 \x01(\tR\x05error\x12\x16\n" +
+	"\x06symbol\x18\x05 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06action\x18\x06 \x01(\tR\x06action\x12*\n" +
+	"\x11position_size_usd\x18\a \x01(\x01R\x0fpositionSizeUsd\x12\x1a\n" +
+	"\bleverage\x18\b \x01(\x05R\bleverage\x12\x1b\n" +
+	"\tstop_loss\x18\t \x01(\x01R\bstopLoss\x12\x1f\n" +
+	"\vtake_profit\x18\n" +
+	" \x01(\x01R\n" +
+	"takeProfit\"G\n" +
+	"\x10SubscribeRequest\x12\x16\n" +
+	"\x06topics\x18\x01 \x03(\tR\x06topics\x12\x1b\n" +
+	"\ttrader_id\x18\x02 \x01(\tR\btraderId\"\x99\x02\n" +
+	"\x05Event\x12\x14\n" +
+	"\x05topic\x18\x01 \x01(\tR\x05topic\x12 \n" +
+	"\ftime_unix_ms\x18\x02 \x01(\x03R\n" +
+	"timeUnixMs\x12@\n" +
+	"\forder_filled\x18\x03 \x01(\v2\x1b.nofx.signal.v1.OrderFilledH\x00R\vorderFilled\x12I\n" +
+	"\x0fposition_opened\x18\x04 \x01(\v2\x1e.nofx.signal.v1.PositionOpenedH\x00R\x0epositionOpened\x12@\n" +
+	"\frisk_tripped\x18\x05 \x01(\v2\x1b.nofx.signal.v1.RiskTrippedH\x00R\vriskTrippedB\t\n" +
+	"\apayload\"\xe9\x01\n" +
+	"\vOrderFilled\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x1a\n" +
+	"\bexchange\x18\x02 \x01(\tR\bexchange\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x04 \x01(\tR\x04side\x12\x16\n" +
+	"\x06action\x18\x05 \x01(\tR\x06action\x12\x19\n" +
+	"\border_id\x18\x06 \x01(\x03R\aorderId\x12\x1a\n" +
+	"\bquantity\x18\a \x01(\x01R\bquantity\x12\x14\n" +
+	"\x05price\x18\b \x01(\x01R\x05price\x12\x10\n" +
+	"\x03fee\x18\t \x01(\x01R\x03fee\"\xce\x01\n" +
+	"\x0ePositionOpened\x12\x1b\n" +
+	"\ttrader_id\x18\x01 \x01(\tR\btraderId\x12\x1a\n" +
+	"\bexchange\x18\x02 \x01(\tR\bexchange\x12\x16\n" +
+	"\x06symbol\x18\x03 \x01(\tR\x06symbol\x12\x12\n" +
+	"\x04side\x18\x04 \x01(\tR\x04side\x12\x1a\n" +
+	"\bquantity\x18\x05 \x01(\x01R\bquantity\x12\x1f\n" +
+	"\ventry_price\x18\x06 \x01(\x01R\n" +
+	"entryPrice\x12\x1a\n" +
+	"\bleverage\x18\a \x01(\x05R\bleverage\"q\n" +
+	"\vRiskTripped\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06symbol\x18\x02 \x01(\tR\x06symbol\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\x12\x1a\n" +
+	"\bblocking\x18\x04 \x01(\bR\bblocking2\xb5\x01\n" +
+	"\rSignalService\x12V\n" +
+	"\x0fStreamDecisions\x12\x1f.nofx.signal.v1.DecisionRequest\x1a\x1e.nofx.signal.v1.DecisionResult(\x010\x01\x12L\n" +
+	"\x0fSubscribeEvents\x12 .nofx.signal.v1.SubscribeRequest\x1a\x15.nofx.signal.v1.Event0\x01B\x19Z\x17nofx/signalrpc/signalpbb\x06proto3"
+
+var (
+	file_signalrpc_signal_proto_rawDescOnce sync.Once
+	file_signalrpc_signal_proto_rawDescData []byte
+)
+
+func file_signalrpc_signal_proto_rawDescGZIP() []byte {
+	file_signalrpc_signal_proto_rawDescOnce.Do(func() {
+		file_signalrpc_signal_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_signalrpc_signal_proto_rawDesc), len(file_signalrpc_signal_proto_rawDesc)))
+	})
+	return file_signalrpc_signal_proto_rawDescData
+}
+
+var file_signalrpc_signal_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_signalrpc_signal_proto_goTypes = []any{
+	(*DecisionRequest)(nil),  // 0: nofx.signal.v1.DecisionRequest
+	(*DecisionResult)(nil),   // 1: nofx.signal.v1.DecisionResult
+	(*SubscribeRequest)(nil), // 2: nofx.signal.v1.SubscribeRequest
+	(*Event)(nil),            // 3: nofx.signal.v1.Event
+	(*OrderFilled)(nil),      // 4: nofx.signal.v1.OrderFilled
+	(*PositionOpened)(nil),   // 5: nofx.signal.v1.PositionOpened
+	(*RiskTripped)(nil),      // 6: nofx.signal.v1.RiskTripped
+}
+var file_signalrpc_signal_proto_depIdxs = []int32{
+	4, // 0: nofx.signal.v1.Event.order_filled:type_name -> nofx.signal.v1.OrderFilled
+	5, // 1: nofx.signal.v1.Event.position_opened:type_name -> nofx.signal.v1.PositionOpened
+	6, // 2: nofx.signal.v1.Event.risk_tripped:type_name -> nofx.signal.v1.RiskTripped
+	0, // 3: nofx.signal.v1.SignalService.StreamDecisions:input_type -> nofx.signal.v1.DecisionRequest
+	2, // 4: nofx.signal.v1.SignalService.SubscribeEvents:input_type -> nofx.signal.v1.SubscribeRequest
+	1, // 5: nofx.signal.v1.SignalService.StreamDecisions:output_type -> nofx.signal.v1.DecisionResult
+	3, // 6: nofx.signal.v1.SignalService.SubscribeEvents:output_type -> nofx.signal.v1.Event
+	5, // [5:7] is the sub-list for method output_type
+	3, // [3:5] is the sub-list for method input_type
+	3, // [3:3] is the sub-list for extension type_name
+	3, // [3:3] is the sub-list for extension extendee
+	0, // [0:3] is the sub-list for field type_name
+}
+
+func init() { file_signalrpc_signal_proto_init() }
+func file_signalrpc_signal_proto_init() {
+	if File_signalrpc_signal_proto != nil {
+		return
+	}
+	file_signalrpc_signal_proto_msgTypes[3].OneofWrappers = []any{
+		(*Event_OrderFilled)(nil),
+		(*Event_PositionOpened)(nil),
+		(*Event_RiskTripped)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_signalrpc_signal_proto_rawDesc), len(file_signalrpc_signal_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_signalrpc_signal_proto_goTypes,
+		DependencyIndexes: file_signalrpc_signal_proto_depIdxs,
+		MessageInfos:      file_signalrpc_signal_proto_msgTypes,
+	}.Build()
+	File_signalrpc_signal_proto = out.File
+	file_signalrpc_signal_proto_goTypes = nil
+	file_signalrpc_signal_proto_depIdxs = nil
+}
@@ -0,0 +1,164 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: signalrpc/signal.proto
+
+package signalpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	SignalService_StreamDecisions_FullMethodName = "/nofx.signal.v1.SignalService/StreamDecisions"
+	SignalService_SubscribeEvents_FullMethodName = "/nofx.signal.v1.SignalService/SubscribeEvents"
+)
+
+// SignalServiceClient is the client API for SignalService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// SignalService 需要在 metadata 中携带 authorization: Bearer <token>
+type SignalServiceClient interface {
+	// StreamDecisions 持续推送决策，每条决策返回一条执行结果（按接收顺序依次执行）
+	StreamDecisions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DecisionRequest, DecisionResult], error)
+	// SubscribeEvents 订阅成交、开仓和风控事件，直到客户端取消
+	SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error)
+}
+
+type signalServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSignalServiceClient(cc grpc.ClientConnInterface) SignalServiceClient {
+	return &signalServiceClient{cc}
+}
+
+func (c *signalServiceClient) StreamDecisions(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[DecisionRequest, DecisionResult], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SignalService_ServiceDesc.Streams[0], SignalService_StreamDecisions_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DecisionRequest, DecisionResult]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalService_StreamDecisionsClient = grpc.BidiStreamingClient[DecisionRequest, DecisionResult]
+
+func (c *signalServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Event], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &SignalService_ServiceDesc.Streams[1], SignalService_SubscribeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeRequest, Event]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalService_SubscribeEventsClient = grpc.ServerStreamingClient[Event]
+
+// SignalServiceServer is the server API for SignalService service.
+// All implementations must embed UnimplementedSignalServiceServer
+// for forward compatibility.
+//
+// SignalService 需要在 metadata 中携带 authorization: Bearer <token>
+type SignalServiceServer interface {
+	// StreamDecisions 持续推送决策，每条决策返回一条执行结果（按接收顺序依次执行）
+	StreamDecisions(grpc.BidiStreamingServer[DecisionRequest, DecisionResult]) error
+	// SubscribeEvents 订阅成交、开仓和风控事件，直到客户端取消
+	SubscribeEvents(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error
+	mustEmbedUnimplementedSignalServiceServer()
+}
+
+// UnimplementedSignalServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSignalServiceServer struct{}
+
+func (UnimplementedSignalServiceServer) StreamDecisions(grpc.BidiStreamingServer[DecisionRequest, DecisionResult]) error {
+	return status.Error(codes.Unimplemented, "method StreamDecisions not implemented")
+}
+func (UnimplementedSignalServiceServer) SubscribeEvents(*SubscribeRequest, grpc.ServerStreamingServer[Event]) error {
+	return status.Error(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedSignalServiceServer) mustEmbedUnimplementedSignalServiceServer() {}
+func (UnimplementedSignalServiceServer) testEmbeddedByValue()                       {}
+
+// UnsafeSignalServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SignalServiceServer will
+// result in compilation errors.
+type UnsafeSignalServiceServer interface {
+	mustEmbedUnimplementedSignalServiceServer()
+}
+
+func RegisterSignalServiceServer(s grpc.ServiceRegistrar, srv SignalServiceServer) {
+	// If the following call panics, it indicates UnimplementedSignalServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&SignalService_ServiceDesc, srv)
+}
+
+func _SignalService_StreamDecisions_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SignalServiceServer).StreamDecisions(&grpc.GenericServerStream[DecisionRequest, DecisionResult]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalService_StreamDecisionsServer = grpc.BidiStreamingServer[DecisionRequest, DecisionResult]
+
+func _SignalService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(SignalServiceServer).SubscribeEvents(m, &grpc.GenericServerStream[SubscribeRequest, Event]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type SignalService_SubscribeEventsServer = grpc.ServerStreamingServer[Event]
+
+// SignalService_ServiceDesc is the grpc.ServiceDesc for SignalService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var SignalService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "nofx.signal.v1.SignalService",
+	HandlerType: (*SignalServiceServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamDecisions",
+			Handler:       _SignalService_StreamDecisions_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _SignalService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "signalrpc/signal.proto",
+}
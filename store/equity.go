@@ -0,0 +1,106 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// EquitySnapshot 一次账户净值快照（定时写入，用于净值曲线和回撤统计）
+type EquitySnapshot struct {
+	TraderID      string    `json:"trader_id"`
+	Time          time.Time `json:"time"`
+	WalletBalance float64   `json:"wallet_balance"`
+	UnrealizedPnL float64   `json:"unrealized_pnl"`
+	PositionCount int       `json:"position_count"`
+}
+
+// TotalEquity 账户净值（钱包余额 + 未实现盈亏）
+func (s EquitySnapshot) TotalEquity() float64 {
+	return s.WalletBalance + s.UnrealizedPnL
+}
+
+// createEquityTable 创建净值快照表
+func createEquityTable(db *sql.DB) error {
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS equity_snapshots (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trader_id TEXT NOT NULL,
+		created_at INTEGER NOT NULL,
+		wallet_balance REAL NOT NULL,
+		unrealized_pnl REAL NOT NULL,
+		position_count INTEGER NOT NULL DEFAULT 0
+	)`); err != nil {
+		return fmt.Errorf("创建净值快照表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_equity_snapshots_trader_time ON equity_snapshots(trader_id, created_at)`); err != nil {
+		return fmt.Errorf("创建净值快照索引失败: %w", err)
+	}
+	return nil
+}
+
+// RecordEquity 写入一次净值快照（Time 为零值时使用当前时间）
+func (j *Journal) RecordEquity(snapshot EquitySnapshot) error {
+	if snapshot.Time.IsZero() {
+		snapshot.Time = j.now()
+	}
+	if _, err := j.db.Exec(`INSERT INTO equity_snapshots (trader_id, created_at, wallet_balance, unrealized_pnl, position_count) VALUES (?, ?, ?, ?, ?)`,
+		snapshot.TraderID, snapshot.Time.UnixMilli(), snapshot.WalletBalance, snapshot.UnrealizedPnL, snapshot.PositionCount); err != nil {
+		return fmt.Errorf("写入净值快照失败: %w", err)
+	}
+	return nil
+}
+
+// EquityFilter 净值快照查询条件（零值字段不过滤）
+type EquityFilter struct {
+	TraderID string
+	Since    time.Time
+	Until    time.Time // 不含该时间
+	Limit    int       // 只返回最近的 Limit 条
+}
+
+// EquitySnapshots 按时间正序查询净值快照
+func (j *Journal) EquitySnapshots(filter EquityFilter) ([]EquitySnapshot, error) {
+	query := `SELECT trader_id, created_at, wallet_balance, unrealized_pnl, position_count FROM equity_snapshots WHERE 1 = 1`
+	var args []interface{}
+	if filter.TraderID != "" {
+		query += ` AND trader_id = ?`
+		args = append(args, filter.TraderID)
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, filter.Since.UnixMilli())
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, filter.Until.UnixMilli())
+	}
+	// 先倒序取最近的 Limit 条，读取后再翻转为正序
+	query += ` ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	}
+
+	rows, err := j.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询净值快照失败: %w", err)
+	}
+	defer rows.Close()
+
+	var snapshots []EquitySnapshot
+	for rows.Next() {
+		var s EquitySnapshot
+		var createdAt int64
+		if err := rows.Scan(&s.TraderID, &createdAt, &s.WalletBalance, &s.UnrealizedPnL, &s.PositionCount); err != nil {
+			return nil, fmt.Errorf("读取净值快照失败: %w", err)
+		}
+		s.Time = time.UnixMilli(createdAt)
+		snapshots = append(snapshots, s)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	for i, k := 0, len(snapshots)-1; i < k; i, k = i+1, k-1 {
+		snapshots[i], snapshots[k] = snapshots[k], snapshots[i]
+	}
+	return snapshots, nil
+}
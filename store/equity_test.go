@@ -0,0 +1,64 @@
+package store
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEquitySnapshots(t *testing.T) {
+	j := openTestJournal(t)
+	base := time.UnixMilli(1700000000000)
+	for i := 0; i < 5; i++ {
+		if err := j.RecordEquity(EquitySnapshot{TraderID: "t1", Time: base.Add(time.Duration(i) * time.Minute), WalletBalance: 1000 + float64(i), UnrealizedPnL: -2, PositionCount: i}); err != nil {
+			t.Fatalf("RecordEquity: %v", err)
+		}
+	}
+	j.now = func() time.Time { return base.Add(time.Hour) }
+	if err := j.RecordEquity(EquitySnapshot{TraderID: "t2", WalletBalance: 50}); err != nil {
+		t.Fatalf("RecordEquity: %v", err)
+	}
+
+	all, err := j.EquitySnapshots(EquityFilter{TraderID: "t1"})
+	if err != nil {
+		t.Fatalf("EquitySnapshots: %v", err)
+	}
+	if len(all) != 5 || !all[0].Time.Equal(base) || all[4].PositionCount != 4 {
+		t.Fatalf("unexpected snapshots: %+v", all)
+	}
+	if all[4].TotalEquity() != 1002 {
+		t.Errorf("expected total equity 1002, got %v", all[4].TotalEquity())
+	}
+
+	latest, err := j.EquitySnapshots(EquityFilter{TraderID: "t1", Limit: 2})
+	if err != nil {
+		t.Fatalf("EquitySnapshots: %v", err)
+	}
+	if len(latest) != 2 || latest[0].PositionCount != 3 || latest[1].PositionCount != 4 {
+		t.Errorf("expected the latest 2 snapshots in time order, got %+v", latest)
+	}
+
+	ranged, err := j.EquitySnapshots(EquityFilter{TraderID: "t1", Since: base.Add(time.Minute), Until: base.Add(3 * time.Minute)})
+	if err != nil {
+		t.Fatalf("EquitySnapshots: %v", err)
+	}
+	if len(ranged) != 2 || ranged[0].PositionCount != 1 {
+		t.Errorf("unexpected ranged snapshots: %+v", ranged)
+	}
+
+	other, err := j.EquitySnapshots(EquityFilter{TraderID: "t2"})
+	if err != nil {
+		t.Fatalf("EquitySnapshots: %v", err)
+	}
+	if len(other) != 1 || !other[0].Time.Equal(base.Add(time.Hour)) {
+		t.Errorf("expected zero time to default to now, got %+v", other)
+	}
+
+	// 只有净值快照的交易员也会列出
+	ids, err := j.TraderIDs()
+	if err != nil {
+		t.Fatalf("TraderIDs: %v", err)
+	}
+	if len(ids) != 2 || ids[0] != "t1" || ids[1] != "t2" {
+		t.Errorf("unexpected trader IDs: %v", ids)
+	}
+}
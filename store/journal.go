@@ -0,0 +1,310 @@
+// Package store 交易事件日志：将决策、下单请求/响应、成交、止盈止损设置和错误按时间顺序写入 SQLite，
+// 崩溃重启后可据此重建持仓状态，用户也可以审计某个持仓是如何产生的
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// 事件类型
+const (
+	EventDecision      = "decision"       // 决策（AI 或策略给出）
+	EventOrderRequest  = "order_request"  // 下单请求
+	EventOrderResponse = "order_response" // 交易所返回的订单执行报告
+	EventFill          = "fill"           // 成交
+	EventProtection    = "protection"     // 止损/止盈单设置
+	EventError         = "error"          // 错误
+	EventRisk          = "risk"           // 风控事件（风险暂停、止损设置失败、紧急停止等需要人工关注的告警）
+	EventScaleOut      = "scale_out"      // 分批止盈（挂单、目标触发、止损移至保本、尾仓移动止损）
+	EventLeverage      = "leverage"       // 杠杆调整
+)
+
+// Event 一条事件
+type Event struct {
+	ID       int64           `json:"id"`
+	TraderID string          `json:"trader_id"`
+	Type     string          `json:"type"`
+	Symbol   string          `json:"symbol"`
+	Side     string          `json:"side"` // long / short（与方向无关的事件为空）
+	Time     time.Time       `json:"time"`
+	Payload  json.RawMessage `json:"payload"`
+}
+
+// Decode 将事件内容解析到 v
+func (e Event) Decode(v interface{}) error {
+	return json.Unmarshal(e.Payload, v)
+}
+
+// OrderRequest 下单请求
+type OrderRequest struct {
+	Action   string  `json:"action"` // open / close
+	Quantity float64 `json:"quantity"`
+	Leverage int     `json:"leverage,omitempty"`
+	Tag      string  `json:"tag,omitempty"` // 策略标签
+}
+
+// Fill 成交
+type Fill struct {
+	Action   string  `json:"action"` // open / close
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+	Fee      float64 `json:"fee,omitempty"`
+	OrderID  int64   `json:"order_id,omitempty"`
+	Full     bool    `json:"full,omitempty"`   // 全部平仓（数量以交易所持仓为准）
+	Reason   string  `json:"reason,omitempty"` // 被动平仓原因（stop_loss / take_profit / liquidation / manual）
+	Tag      string  `json:"tag,omitempty"`    // 策略标签（来自下单时的决策）
+}
+
+// Protection 止损/止盈单
+type Protection struct {
+	Kind     string  `json:"kind"` // stop_loss / take_profit
+	Quantity float64 `json:"quantity"`
+	Price    float64 `json:"price"`
+}
+
+// LeverageChange 杠杆调整
+type LeverageChange struct {
+	From int `json:"from,omitempty"` // 调整前杠杆（未知时为 0）
+	To   int `json:"to"`
+}
+
+// ErrorInfo 错误
+type ErrorInfo struct {
+	Operation string `json:"operation"`
+	Message   string `json:"message"`
+}
+
+// RiskEvent 风控事件
+type RiskEvent struct {
+	Severity string `json:"severity"` // WARNING / CRITICAL
+	Title    string `json:"title"`
+	Message  string `json:"message"`
+}
+
+// 分批止盈事件阶段
+const (
+	ScaleOutStagePlaced    = "placed"    // 开仓后设置各档止盈
+	ScaleOutStageTarget    = "target"    // 一档止盈成交
+	ScaleOutStageBreakeven = "breakeven" // 止损移至开仓价
+	ScaleOutStageTrail     = "trail"     // 尾仓移动止损上移
+)
+
+// ScaleOutTarget 分批止盈的一档
+type ScaleOutTarget struct {
+	R          float64 `json:"r"` // 目标距离（风险倍数）
+	Price      float64 `json:"price"`
+	Quantity   float64 `json:"quantity"`
+	OnExchange bool    `json:"on_exchange"` // 已挂交易所止盈单（否则由本地监控触发平仓）
+}
+
+// ScaleOutEvent 分批止盈事件
+type ScaleOutEvent struct {
+	Stage    string           `json:"stage"`
+	Step     int              `json:"step,omitempty"` // target：触发的档位（从 1 开始）
+	Entry    float64          `json:"entry,omitempty"`
+	Risk     float64          `json:"risk,omitempty"` // 1R 对应的价格距离
+	Quantity float64          `json:"quantity"`
+	Price    float64          `json:"price"` // placed：初始止损价；target：目标价；breakeven/trail：新止损价
+	Targets  []ScaleOutTarget `json:"targets,omitempty"`
+}
+
+// Journal SQLite 事件日志
+type Journal struct {
+	db   *sql.DB
+	path string
+	now  func() time.Time
+}
+
+var (
+	journals   = make(map[string]*Journal)
+	journalsMu sync.Mutex
+)
+
+// OpenJournal 打开（或创建）事件日志数据库；同一路径返回同一个实例（多个交易员共享）
+func OpenJournal(path string) (*Journal, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("解析事件日志路径失败: %w", err)
+	}
+	journalsMu.Lock()
+	defer journalsMu.Unlock()
+	if j, ok := journals[abs]; ok {
+		return j, nil
+	}
+
+	db, err := sql.Open("sqlite", abs)
+	if err != nil {
+		return nil, fmt.Errorf("打开事件日志数据库失败: %w", err)
+	}
+	// WAL + FULL 同步：崩溃或断电时已写入的事件不会丢失
+	for _, pragma := range []string{"PRAGMA journal_mode=WAL", "PRAGMA synchronous=FULL", "PRAGMA busy_timeout=5000"} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("设置事件日志数据库失败 (%s): %w", pragma, err)
+		}
+	}
+	if _, err := db.Exec(`CREATE TABLE IF NOT EXISTS trade_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		trader_id TEXT NOT NULL,
+		type TEXT NOT NULL,
+		symbol TEXT NOT NULL DEFAULT '',
+		side TEXT NOT NULL DEFAULT '',
+		created_at INTEGER NOT NULL,
+		payload TEXT NOT NULL DEFAULT '{}'
+	)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("创建事件表失败: %w", err)
+	}
+	if _, err := db.Exec(`CREATE INDEX IF NOT EXISTS idx_trade_events_trader_symbol ON trade_events(trader_id, symbol, side, id)`); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("创建事件索引失败: %w", err)
+	}
+
+	if err := createEquityTable(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	j := &Journal{db: db, path: abs, now: time.Now}
+	journals[abs] = j
+	return j, nil
+}
+
+// Close 关闭数据库
+func (j *Journal) Close() error {
+	journalsMu.Lock()
+	delete(journals, j.path)
+	journalsMu.Unlock()
+	return j.db.Close()
+}
+
+// CloseJournals 关闭所有已打开的事件日志（进程退出前调用：合并 WAL 并释放文件）
+func CloseJournals() error {
+	journalsMu.Lock()
+	open := make([]*Journal, 0, len(journals))
+	for _, j := range journals {
+		open = append(open, j)
+	}
+	journals = make(map[string]*Journal)
+	journalsMu.Unlock()
+
+	var firstErr error
+	for _, j := range open {
+		if _, err := j.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("合并事件日志 %s 失败: %w", j.path, err)
+		}
+		if err := j.db.Close(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("关闭事件日志 %s 失败: %w", j.path, err)
+		}
+	}
+	return firstErr
+}
+
+// Record 追加一条事件（payload 序列化为 JSON），返回事件ID
+func (j *Journal) Record(traderID, eventType, symbol, side string, payload interface{}) (int64, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("序列化事件失败: %w", err)
+	}
+	res, err := j.db.Exec(`INSERT INTO trade_events (trader_id, type, symbol, side, created_at, payload) VALUES (?, ?, ?, ?, ?, ?)`,
+		traderID, eventType, normalizeSymbol(symbol), side, j.now().UnixMilli(), string(data))
+	if err != nil {
+		return 0, fmt.Errorf("写入事件失败: %w", err)
+	}
+	return res.LastInsertId()
+}
+
+// EventFilter 事件查询条件（零值字段不过滤）
+type EventFilter struct {
+	TraderID string
+	Symbol   string
+	Side     string
+	Types    []string
+	AfterID  int64 // 只返回 ID 大于该值的事件
+	Since    time.Time
+	Limit    int
+}
+
+// Events 按写入顺序查询事件
+func (j *Journal) Events(filter EventFilter) ([]Event, error) {
+	query := `SELECT id, trader_id, type, symbol, side, created_at, payload FROM trade_events WHERE id > ?`
+	args := []interface{}{filter.AfterID}
+	if filter.TraderID != "" {
+		query += ` AND trader_id = ?`
+		args = append(args, filter.TraderID)
+	}
+	if filter.Symbol != "" {
+		query += ` AND symbol = ?`
+		args = append(args, normalizeSymbol(filter.Symbol))
+	}
+	if filter.Side != "" {
+		query += ` AND side = ?`
+		args = append(args, filter.Side)
+	}
+	if len(filter.Types) > 0 {
+		query += ` AND type IN (?` + strings.Repeat(`, ?`, len(filter.Types)-1) + `)`
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+	if !filter.Since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, filter.Since.UnixMilli())
+	}
+	query += ` ORDER BY id`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(` LIMIT %d`, filter.Limit)
+	}
+
+	rows, err := j.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var e Event
+		var createdAt int64
+		var payload string
+		if err := rows.Scan(&e.ID, &e.TraderID, &e.Type, &e.Symbol, &e.Side, &createdAt, &payload); err != nil {
+			return nil, fmt.Errorf("读取事件失败: %w", err)
+		}
+		e.Time = time.UnixMilli(createdAt)
+		e.Payload = json.RawMessage(payload)
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// TraderIDs 事件日志（含净值快照）中出现过的全部交易员ID
+func (j *Journal) TraderIDs() ([]string, error) {
+	rows, err := j.db.Query(`SELECT trader_id FROM trade_events UNION SELECT trader_id FROM equity_snapshots ORDER BY trader_id`)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易员失败: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("读取交易员失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// normalizeSymbol 统一币种大小写
+func normalizeSymbol(symbol string) string {
+	return strings.ToUpper(strings.TrimSpace(symbol))
+}
@@ -0,0 +1,188 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestJournal(t *testing.T) *Journal {
+	t.Helper()
+	j, err := OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	t.Cleanup(func() { j.Close() })
+	return j
+}
+
+func mustRecord(t *testing.T, j *Journal, eventType, symbol, side string, payload interface{}) int64 {
+	t.Helper()
+	id, err := j.Record("t1", eventType, symbol, side, payload)
+	if err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	return id
+}
+
+func TestJournalRecordAndQuery(t *testing.T) {
+	j := openTestJournal(t)
+	now := time.UnixMilli(1700000000000)
+	j.now = func() time.Time { return now }
+
+	mustRecord(t, j, EventDecision, "btcusdt", "long", map[string]string{"reasoning": "breakout"})
+	mustRecord(t, j, EventOrderRequest, "BTCUSDT", "long", OrderRequest{Action: "open", Quantity: 0.1, Leverage: 5})
+	if _, err := j.Record("t2", EventDecision, "ETHUSDT", "short", nil); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+
+	events, err := j.Events(EventFilter{TraderID: "t1", Symbol: "btcusdt"})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Symbol != "BTCUSDT" || !events[0].Time.Equal(now) {
+		t.Errorf("unexpected first event: %+v", events[0])
+	}
+	var req OrderRequest
+	if err := events[1].Decode(&req); err != nil || req.Quantity != 0.1 || req.Leverage != 5 {
+		t.Errorf("decode order request = %+v, %v", req, err)
+	}
+
+	byType, _ := j.Events(EventFilter{Types: []string{EventDecision}})
+	if len(byType) != 2 {
+		t.Errorf("expected 2 decision events across traders, got %d", len(byType))
+	}
+	after, _ := j.Events(EventFilter{AfterID: events[1].ID})
+	if len(after) != 1 || after[0].TraderID != "t2" {
+		t.Errorf("AfterID filter returned %+v", after)
+	}
+}
+
+func TestOpenJournalSharesInstanceAndPersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "journal.db")
+	j1, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	j2, _ := OpenJournal(path)
+	if j1 != j2 {
+		t.Fatal("expected the same journal instance for the same path")
+	}
+	if _, err := j1.Record("t1", EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 100}); err != nil {
+		t.Fatalf("Record: %v", err)
+	}
+	j1.Close()
+
+	// 模拟重启：重新打开后事件仍在
+	j3, err := OpenJournal(path)
+	if err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	defer j3.Close()
+	positions, err := j3.Positions("t1")
+	if err != nil || len(positions) != 1 {
+		t.Fatalf("expected 1 position after reopen, got %v (%v)", positions, err)
+	}
+}
+
+func TestJournalPositionsRebuild(t *testing.T) {
+	j := openTestJournal(t)
+
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 100})
+	mustRecord(t, j, EventProtection, "BTCUSDT", "long", Protection{Kind: "stop_loss", Quantity: 1, Price: 95})
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 110}) // 加仓
+	mustRecord(t, j, EventProtection, "BTCUSDT", "long", Protection{Kind: "take_profit", Quantity: 2, Price: 130})
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "close", Quantity: 0.5, Price: 120}) // 部分平仓
+
+	mustRecord(t, j, EventFill, "ETHUSDT", "short", Fill{Action: "open", Quantity: 2, Price: 2000})
+	mustRecord(t, j, EventFill, "ETHUSDT", "short", Fill{Action: "close", Full: true})
+
+	// 没有对应持仓的止损事件被忽略
+	mustRecord(t, j, EventProtection, "SOLUSDT", "long", Protection{Kind: "stop_loss", Price: 10})
+
+	positions, err := j.Positions("t1")
+	if err != nil {
+		t.Fatalf("Positions: %v", err)
+	}
+	if len(positions) != 1 {
+		t.Fatalf("expected only BTCUSDT_long, got %v", positions)
+	}
+	pos := positions["BTCUSDT_long"]
+	if pos == nil {
+		t.Fatal("missing BTCUSDT_long")
+	}
+	if pos.Quantity != 1.5 || pos.EntryPrice != 105 || pos.StopLoss != 95 || pos.TakeProfit != 130 {
+		t.Errorf("unexpected position: %+v", pos)
+	}
+}
+
+func TestJournalPositionsScaleOut(t *testing.T) {
+	j := openTestJournal(t)
+
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 100})
+	mustRecord(t, j, EventScaleOut, "BTCUSDT", "long", ScaleOutEvent{Stage: ScaleOutStagePlaced, Entry: 100, Risk: 5, Quantity: 1, Price: 95,
+		Targets: []ScaleOutTarget{{R: 1, Price: 105, Quantity: 0.5, OnExchange: true}, {R: 2, Price: 110, Quantity: 0.25, OnExchange: true}}})
+	mustRecord(t, j, EventScaleOut, "BTCUSDT", "long", ScaleOutEvent{Stage: ScaleOutStageTarget, Step: 1, Quantity: 0.5, Price: 105})
+	mustRecord(t, j, EventScaleOut, "BTCUSDT", "long", ScaleOutEvent{Stage: ScaleOutStageBreakeven, Quantity: 0.5, Price: 100})
+
+	positions, err := j.Positions("t1")
+	if err != nil {
+		t.Fatalf("Positions: %v", err)
+	}
+	so := positions["BTCUSDT_long"].ScaleOut
+	if so == nil || so.Hits != 1 || !so.Breakeven || so.Stop != 100 || so.InitialQty != 1 || len(so.Targets) != 2 {
+		t.Fatalf("unexpected scale-out state: %+v", so)
+	}
+
+	// 平仓后重新开仓：旧的分批止盈进度不再适用
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "close", Full: true})
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 120})
+	positions, _ = j.Positions("t1")
+	if positions["BTCUSDT_long"].ScaleOut != nil {
+		t.Errorf("new position should not inherit scale-out state")
+	}
+}
+
+func TestJournalHistory(t *testing.T) {
+	j := openTestJournal(t)
+
+	// 上一轮已平仓的持仓不属于本次历史
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 90})
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "close", Full: true})
+
+	decisionID := mustRecord(t, j, EventDecision, "BTCUSDT", "long", map[string]string{"reasoning": "trend"})
+	mustRecord(t, j, EventOrderRequest, "BTCUSDT", "long", OrderRequest{Action: "open", Quantity: 1})
+	mustRecord(t, j, EventFill, "BTCUSDT", "short", Fill{Action: "open", Quantity: 1, Price: 100}) // 另一方向
+	mustRecord(t, j, EventFill, "BTCUSDT", "long", Fill{Action: "open", Quantity: 1, Price: 100})
+	mustRecord(t, j, EventError, "BTCUSDT", "", ErrorInfo{Operation: "update_stop_loss", Message: "timeout"})
+	mustRecord(t, j, EventProtection, "BTCUSDT", "long", Protection{Kind: "stop_loss", Price: 95})
+
+	history, err := j.History("t1", "BTCUSDT", "long")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+	var types []string
+	for _, e := range history {
+		types = append(types, e.Type)
+	}
+	want := []string{EventDecision, EventOrderRequest, EventFill, EventError, EventProtection}
+	if len(types) != len(want) {
+		t.Fatalf("history types = %v, want %v", types, want)
+	}
+	for i := range want {
+		if types[i] != want[i] {
+			t.Fatalf("history types = %v, want %v", types, want)
+		}
+	}
+	if history[0].ID != decisionID {
+		t.Errorf("history should start at the opening decision, got #%d", history[0].ID)
+	}
+
+	none, err := j.History("t1", "ETHUSDT", "long")
+	if err != nil || none != nil {
+		t.Errorf("expected nil history for missing position, got %v (%v)", none, err)
+	}
+}
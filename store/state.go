@@ -0,0 +1,157 @@
+package store
+
+import (
+	"fmt"
+	"time"
+)
+
+// qtyEpsilon 数量比较容差（浮点误差）
+const qtyEpsilon = 1e-9
+
+// PositionState 由事件重建的持仓状态
+type PositionState struct {
+	Symbol     string    `json:"symbol"`
+	Side       string    `json:"side"`
+	Quantity   float64   `json:"quantity"`
+	EntryPrice float64   `json:"entry_price"` // 按成交加权的开仓均价
+	StopLoss   float64   `json:"stop_loss"`
+	TakeProfit float64   `json:"take_profit"`
+	OpenedAt   time.Time `json:"opened_at"`
+	FirstEvent int64     `json:"first_event"` // 本次持仓第一条开仓成交事件ID（History 从这里开始）
+
+	ScaleOut *ScaleOutState `json:"scale_out,omitempty"` // 分批止盈进度（未启用时为 nil）
+}
+
+// ScaleOutState 由事件重建的分批止盈进度
+type ScaleOutState struct {
+	Entry      float64          `json:"entry"`
+	Risk       float64          `json:"risk"`
+	InitialQty float64          `json:"initial_qty"`
+	Targets    []ScaleOutTarget `json:"targets"`
+	Hits       int              `json:"hits"`      // 已成交的档数
+	Stop       float64          `json:"stop"`      // 当前止损价
+	Breakeven  bool             `json:"breakeven"` // 止损是否已移至保本
+}
+
+// Positions 按成交和止盈止损事件重建交易员的当前持仓（key 为 symbol_side）
+func (j *Journal) Positions(traderID string) (map[string]*PositionState, error) {
+	events, err := j.Events(EventFilter{TraderID: traderID, Types: []string{EventFill, EventProtection, EventScaleOut}})
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string]*PositionState)
+	for _, e := range events {
+		key := e.Symbol + "_" + e.Side
+		switch e.Type {
+		case EventFill:
+			var fill Fill
+			if err := e.Decode(&fill); err != nil {
+				return nil, fmt.Errorf("解析成交事件 #%d 失败: %w", e.ID, err)
+			}
+			applyFill(positions, key, e, fill)
+		case EventProtection:
+			var p Protection
+			if err := e.Decode(&p); err != nil {
+				return nil, fmt.Errorf("解析止盈止损事件 #%d 失败: %w", e.ID, err)
+			}
+			if pos, ok := positions[key]; ok {
+				if p.Kind == "take_profit" {
+					pos.TakeProfit = p.Price
+				} else {
+					pos.StopLoss = p.Price
+				}
+			}
+		case EventScaleOut:
+			var so ScaleOutEvent
+			if err := e.Decode(&so); err != nil {
+				return nil, fmt.Errorf("解析分批止盈事件 #%d 失败: %w", e.ID, err)
+			}
+			if pos, ok := positions[key]; ok {
+				applyScaleOut(pos, so)
+			}
+		}
+	}
+	return positions, nil
+}
+
+// applyScaleOut 将一条分批止盈事件应用到持仓
+func applyScaleOut(pos *PositionState, so ScaleOutEvent) {
+	if so.Stage == ScaleOutStagePlaced {
+		pos.ScaleOut = &ScaleOutState{Entry: so.Entry, Risk: so.Risk, InitialQty: so.Quantity, Targets: so.Targets, Stop: so.Price}
+		return
+	}
+	if pos.ScaleOut == nil {
+		return
+	}
+	switch so.Stage {
+	case ScaleOutStageTarget:
+		if so.Step > pos.ScaleOut.Hits {
+			pos.ScaleOut.Hits = so.Step
+		}
+	case ScaleOutStageBreakeven:
+		pos.ScaleOut.Stop, pos.ScaleOut.Breakeven = so.Price, true
+	case ScaleOutStageTrail:
+		pos.ScaleOut.Stop = so.Price
+	}
+}
+
+// applyFill 将一笔成交应用到持仓
+func applyFill(positions map[string]*PositionState, key string, e Event, fill Fill) {
+	pos, exists := positions[key]
+	if fill.Action == "open" {
+		if !exists {
+			pos = &PositionState{Symbol: e.Symbol, Side: e.Side, OpenedAt: e.Time, FirstEvent: e.ID}
+			positions[key] = pos
+		}
+		if total := pos.Quantity + fill.Quantity; total > 0 {
+			pos.EntryPrice = (pos.EntryPrice*pos.Quantity + fill.Price*fill.Quantity) / total
+		}
+		pos.Quantity += fill.Quantity
+		return
+	}
+	if !exists {
+		return
+	}
+	pos.Quantity -= fill.Quantity
+	if fill.Full || pos.Quantity <= qtyEpsilon {
+		delete(positions, key)
+	}
+}
+
+// History 该持仓从开仓至今的全部事件（包括开仓前最近一次决策），用于审计持仓为何存在；无持仓时返回 nil
+func (j *Journal) History(traderID, symbol, side string) ([]Event, error) {
+	positions, err := j.Positions(traderID)
+	if err != nil {
+		return nil, err
+	}
+	pos, ok := positions[fmt.Sprintf("%s_%s", normalizeSymbol(symbol), side)]
+	if !ok {
+		return nil, nil
+	}
+
+	// 开仓成交之前最近一条该方向的决策
+	decisions, err := j.Events(EventFilter{TraderID: traderID, Symbol: symbol, Side: side, Types: []string{EventDecision}})
+	if err != nil {
+		return nil, err
+	}
+	afterID := pos.FirstEvent - 1
+	for _, d := range decisions {
+		if d.ID < pos.FirstEvent {
+			afterID = d.ID - 1
+		}
+	}
+	events, err := j.Events(EventFilter{TraderID: traderID, Symbol: symbol, AfterID: afterID})
+	if err != nil {
+		return nil, err
+	}
+
+	// 只保留该方向或与方向无关的事件
+	history := events[:0]
+	for _, e := range events {
+		if e.Side == "" || e.Side == side {
+			history = append(history, e)
+		}
+	}
+	return history, nil
+}
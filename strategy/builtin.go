@@ -0,0 +1,200 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"nofx/market"
+)
+
+// Factory 根据参数创建策略（参数名见各策略说明，未提供的参数使用默认值）
+type Factory func(params map[string]float64) (Strategy, error)
+
+var (
+	registry   = map[string]Factory{}
+	registryMu sync.RWMutex
+)
+
+func init() {
+	Register("sma_cross", newSMACross)
+	Register("breakout", newBreakout)
+	Register("grid", newGridStrategy)
+}
+
+// Register 注册策略（nofx backtest --strategy name 使用），同名注册会覆盖
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[strings.ToLower(name)] = factory
+}
+
+// New 按注册名称创建策略
+func New(name string, params map[string]float64) (Strategy, error) {
+	registryMu.RLock()
+	factory, ok := registry[strings.ToLower(name)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未注册的策略: %s（可用: %s）", name, strings.Join(Registered(), ", "))
+	}
+	return factory(params)
+}
+
+// Registered 已注册的策略名称（按字母排序）
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// orderParams 内置策略共用的下单参数
+type orderParams struct {
+	sizeUSD  float64
+	leverage int
+}
+
+func parseOrderParams(name string, params map[string]float64) (orderParams, error) {
+	p := orderParams{sizeUSD: 1000, leverage: 1}
+	if v, ok := params["size_usd"]; ok {
+		p.sizeUSD = v
+	}
+	if v, ok := params["leverage"]; ok {
+		p.leverage = int(v)
+	}
+	if p.sizeUSD <= 0 || p.leverage <= 0 {
+		return p, fmt.Errorf("%s: size_usd 和 leverage 必须大于 0", name)
+	}
+	return p, nil
+}
+
+// smaCross 均线交叉：快线上穿慢线开多，下穿平多
+// 参数: fast（默认 10）、slow（默认 30）、size_usd（默认 1000）、leverage（默认 1）
+type smaCross struct {
+	orderParams
+	fast, slow int
+}
+
+func newSMACross(params map[string]float64) (Strategy, error) {
+	s := &smaCross{fast: 10, slow: 30}
+	if v, ok := params["fast"]; ok {
+		s.fast = int(v)
+	}
+	if v, ok := params["slow"]; ok {
+		s.slow = int(v)
+	}
+	if s.fast <= 0 || s.slow <= s.fast {
+		return nil, fmt.Errorf("sma_cross: 需要 0 < fast < slow（fast=%d, slow=%d）", s.fast, s.slow)
+	}
+	var err error
+	s.orderParams, err = parseOrderParams("sma_cross", params)
+	return s, err
+}
+
+func (s *smaCross) Name() string { return "sma_cross" }
+
+func (s *smaCross) OnCandle(ctx context.Context, snapshot MarketSnapshot) []Decision {
+	closes := shortestCloses(snapshot)
+	if len(closes) < s.slow+1 {
+		return nil
+	}
+	fastNow, slowNow := sma(closes, s.fast), sma(closes, s.slow)
+	fastPrev, slowPrev := sma(closes[:len(closes)-1], s.fast), sma(closes[:len(closes)-1], s.slow)
+
+	if _, ok := snapshot.Position("long"); ok {
+		if fastPrev >= slowPrev && fastNow < slowNow {
+			return []Decision{{Symbol: snapshot.Symbol, Action: "close_long", Reasoning: "快线下穿慢线"}}
+		}
+		return nil
+	}
+	if fastPrev <= slowPrev && fastNow > slowNow {
+		return []Decision{{Symbol: snapshot.Symbol, Action: "open_long", Leverage: s.leverage, PositionSizeUSD: s.sizeUSD, Reasoning: "快线上穿慢线"}}
+	}
+	return nil
+}
+
+// breakout 通道突破：收盘价突破前 lookback 根最高价开多，止损设在前 lookback 根最低价；跌破通道下沿平多
+// 参数: lookback（默认 20）、size_usd（默认 1000）、leverage（默认 1）
+type breakout struct {
+	orderParams
+	lookback int
+}
+
+func newBreakout(params map[string]float64) (Strategy, error) {
+	s := &breakout{lookback: 20}
+	if v, ok := params["lookback"]; ok {
+		s.lookback = int(v)
+	}
+	if s.lookback < 2 {
+		return nil, fmt.Errorf("breakout: lookback 至少为 2（当前 %d）", s.lookback)
+	}
+	var err error
+	s.orderParams, err = parseOrderParams("breakout", params)
+	return s, err
+}
+
+func (s *breakout) Name() string { return "breakout" }
+
+func (s *breakout) OnCandle(ctx context.Context, snapshot MarketSnapshot) []Decision {
+	klines := snapshot.Klines[shortestInterval(snapshot)]
+	if len(klines) < s.lookback+1 {
+		return nil
+	}
+	last := klines[len(klines)-1]
+	high, low := klines[len(klines)-2].High, klines[len(klines)-2].Low
+	for _, k := range klines[len(klines)-1-s.lookback : len(klines)-1] {
+		high, low = max(high, k.High), min(low, k.Low)
+	}
+
+	if _, ok := snapshot.Position("long"); ok {
+		if last.Close < low {
+			return []Decision{{Symbol: snapshot.Symbol, Action: "close_long", Reasoning: "跌破通道下沿"}}
+		}
+		return nil
+	}
+	if last.Close > high {
+		return []Decision{{Symbol: snapshot.Symbol, Action: "open_long", Leverage: s.leverage, PositionSizeUSD: s.sizeUSD, StopLoss: low, Reasoning: "突破通道上沿"}}
+	}
+	return nil
+}
+
+// shortestInterval 快照中最短的K线周期（内置策略在该周期上计算指标）
+func shortestInterval(snapshot MarketSnapshot) string {
+	var best string
+	var shortest time.Duration
+	for interval := range snapshot.Klines {
+		d, ok := market.TimeframeDuration(interval)
+		if !ok {
+			continue
+		}
+		if best == "" || d < shortest {
+			best, shortest = interval, d
+		}
+	}
+	return best
+}
+
+func shortestCloses(snapshot MarketSnapshot) []float64 {
+	klines := snapshot.Klines[shortestInterval(snapshot)]
+	closes := make([]float64, len(klines))
+	for i, k := range klines {
+		closes[i] = k.Close
+	}
+	return closes
+}
+
+func sma(values []float64, n int) float64 {
+	var sum float64
+	for _, v := range values[len(values)-n:] {
+		sum += v
+	}
+	return sum / float64(n)
+}
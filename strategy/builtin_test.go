@@ -0,0 +1,82 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+
+	"nofx/decision"
+	"nofx/market"
+)
+
+// snapshotFrom 用收盘价序列构造 15m 快照（最高/最低价为收盘价 ±1）
+func snapshotFrom(closes []float64, positions ...string) MarketSnapshot {
+	klines := make([]market.Kline, len(closes))
+	for i, c := range closes {
+		klines[i] = market.Kline{Close: c, High: c + 1, Low: c - 1}
+	}
+	s := MarketSnapshot{Symbol: "BTCUSDT", Klines: map[string][]market.Kline{"15m": klines, "1h": klines[:1]}}
+	for _, side := range positions {
+		s.Positions = append(s.Positions, decision.PositionInfo{Side: side})
+	}
+	return s
+}
+
+func TestSMACross(t *testing.T) {
+	s, err := New("SMA_CROSS", map[string]float64{"fast": 2, "slow": 4, "size_usd": 500, "leverage": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 快线上穿慢线：开多
+	decisions := s.OnCandle(context.Background(), snapshotFrom([]float64{10, 9, 8, 7, 12}))
+	if len(decisions) != 1 || decisions[0].Action != "open_long" || decisions[0].PositionSizeUSD != 500 || decisions[0].Leverage != 3 {
+		t.Fatalf("期望开多, got %+v", decisions)
+	}
+	// 已有多仓时不重复开仓
+	if decisions := s.OnCandle(context.Background(), snapshotFrom([]float64{10, 9, 8, 7, 12}, "long")); len(decisions) != 0 {
+		t.Errorf("已有多仓时不应开仓, got %+v", decisions)
+	}
+	// 快线下穿慢线：平多
+	decisions = s.OnCandle(context.Background(), snapshotFrom([]float64{7, 8, 9, 10, 5}, "long"))
+	if len(decisions) != 1 || decisions[0].Action != "close_long" {
+		t.Fatalf("期望平多, got %+v", decisions)
+	}
+	// K线不足
+	if decisions := s.OnCandle(context.Background(), snapshotFrom([]float64{1, 2})); len(decisions) != 0 {
+		t.Errorf("K线不足时不应产生决策, got %+v", decisions)
+	}
+
+	if _, err := New("sma_cross", map[string]float64{"fast": 30, "slow": 10}); err == nil {
+		t.Error("fast >= slow 应返回错误")
+	}
+}
+
+func TestBreakout(t *testing.T) {
+	s, err := New("breakout", map[string]float64{"lookback": 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decisions := s.OnCandle(context.Background(), snapshotFrom([]float64{10, 11, 10, 13}))
+	if len(decisions) != 1 || decisions[0].Action != "open_long" || decisions[0].StopLoss != 9 {
+		t.Fatalf("期望突破开多且止损为通道下沿 9, got %+v", decisions)
+	}
+	decisions = s.OnCandle(context.Background(), snapshotFrom([]float64{10, 11, 10, 8}, "long"))
+	if len(decisions) != 1 || decisions[0].Action != "close_long" {
+		t.Fatalf("期望跌破下沿平多, got %+v", decisions)
+	}
+
+	if _, err := New("breakout", map[string]float64{"size_usd": -1}); err == nil {
+		t.Error("size_usd <= 0 应返回错误")
+	}
+}
+
+func TestNewUnknownStrategy(t *testing.T) {
+	if _, err := New("martingale", nil); err == nil {
+		t.Error("未注册的策略应返回错误")
+	}
+	names := Registered()
+	if len(names) < 3 || names[0] != "breakout" || names[1] != "grid" || names[2] != "sma_cross" {
+		t.Errorf("Registered() = %v", names)
+	}
+}
@@ -0,0 +1,128 @@
+package strategy
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"nofx/decision"
+)
+
+// ChurnLimits 单个币种的交易频率限制（字段为零值表示不限制），防止震荡行情中反复开平仓
+type ChurnLimits struct {
+	MinEntryInterval time.Duration // 同一币种两次开仓的最小间隔
+	MaxTradesPerDay  int           // 同一币种每天（UTC）最多开仓次数
+	StopOutCooldown  time.Duration // 持仓被止损（非策略主动平仓）后的冷却时间
+}
+
+// enabled 是否配置了任一限制
+func (l ChurnLimits) enabled() bool {
+	return l.MinEntryInterval > 0 || l.MaxTradesPerDay > 0 || l.StopOutCooldown > 0
+}
+
+// symbolActivity 单个币种的开仓与止损记录
+type symbolActivity struct {
+	lastEntry    time.Time
+	day          string // entriesToday 对应的日期（UTC）
+	entriesToday int
+	stoppedAt    time.Time       // 最近一次被止损的时间
+	open         map[string]bool // 上一轮快照中的持仓方向
+	closing      map[string]bool // 本轮策略主动平仓的方向（消失时不算止损）
+}
+
+// churnGuard 按币种执行 ChurnLimits（时间使用快照时间，回测中按回放时间计算）
+type churnGuard struct {
+	defaults  ChurnLimits
+	overrides map[string]ChurnLimits
+
+	mu       sync.Mutex
+	activity map[string]*symbolActivity
+}
+
+func newChurnGuard(defaults ChurnLimits, overrides map[string]ChurnLimits) *churnGuard {
+	if !defaults.enabled() && len(overrides) == 0 {
+		return nil
+	}
+	return &churnGuard{defaults: defaults, overrides: overrides, activity: make(map[string]*symbolActivity)}
+}
+
+func (g *churnGuard) limits(symbol string) ChurnLimits {
+	if l, ok := g.overrides[symbol]; ok {
+		return l
+	}
+	return g.defaults
+}
+
+func (g *churnGuard) get(symbol string) *symbolActivity {
+	a := g.activity[symbol]
+	if a == nil {
+		a = &symbolActivity{open: map[string]bool{}, closing: map[string]bool{}}
+		g.activity[symbol] = a
+	}
+	return a
+}
+
+// observe 对比上一轮持仓：持仓消失且不是策略主动平仓时记为止损
+func (g *churnGuard) observe(symbol string, positions []decision.PositionInfo, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a := g.get(symbol)
+	current := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		current[pos.Side] = true
+	}
+	for side := range a.open {
+		if !current[side] && !a.closing[side] {
+			a.stoppedAt = now
+			log.Printf("🧊 %s %s 持仓已被交易所平仓（止损/强平），进入冷却", symbol, side)
+		}
+	}
+	a.open, a.closing = current, map[string]bool{}
+}
+
+// check 开仓前检查频率限制
+func (g *churnGuard) check(symbol string, now time.Time) error {
+	limits := g.limits(symbol)
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a := g.get(symbol)
+
+	if limits.StopOutCooldown > 0 && !a.stoppedAt.IsZero() {
+		if until := a.stoppedAt.Add(limits.StopOutCooldown); now.Before(until) {
+			return fmt.Errorf("止损后冷却中，%s 之后才能开仓", until.UTC().Format("2006-01-02 15:04 MST"))
+		}
+	}
+	if limits.MinEntryInterval > 0 && !a.lastEntry.IsZero() {
+		if next := a.lastEntry.Add(limits.MinEntryInterval); now.Before(next) {
+			return fmt.Errorf("距上次开仓不足 %v，%s 之后才能开仓", limits.MinEntryInterval, next.UTC().Format("2006-01-02 15:04 MST"))
+		}
+	}
+	if limits.MaxTradesPerDay > 0 && a.day == dayKey(now) && a.entriesToday >= limits.MaxTradesPerDay {
+		return fmt.Errorf("今日已开仓 %d 次，达到上限 %d", a.entriesToday, limits.MaxTradesPerDay)
+	}
+	return nil
+}
+
+// record 记录已执行的决策（开仓计数，主动平仓的方向不计为止损）
+func (g *churnGuard) record(d Decision, now time.Time) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	a := g.get(d.Symbol)
+	switch d.Action {
+	case "open_long", "open_short":
+		if day := dayKey(now); a.day != day {
+			a.day, a.entriesToday = day, 0
+		}
+		a.entriesToday++
+		a.lastEntry = now
+	case "close_long":
+		a.closing["long"] = true
+	case "close_short":
+		a.closing["short"] = true
+	}
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
@@ -0,0 +1,138 @@
+package strategy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nofx/decision"
+	"nofx/market"
+)
+
+// mutableAccount 测试中逐轮修改持仓的账户
+type mutableAccount struct {
+	positions []decision.PositionInfo
+}
+
+func (a *mutableAccount) StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error) {
+	return decision.AccountInfo{TotalEquity: 1000, PositionCount: len(a.positions)}, a.positions, nil
+}
+
+// alwaysLong 每轮都尝试开多
+var alwaysLong = Func(func(ctx context.Context, s MarketSnapshot) []Decision {
+	return []Decision{{Action: "open_long", Leverage: 1, PositionSizeUSD: 100}}
+})
+
+func newChurnRunner(t *testing.T, limits ChurnLimits, account AccountSource, now *time.Time, strategies ...Strategy) (*Runner, *recordingExecutor) {
+	withSnapshots(t, map[string][]market.Kline{"BTCUSDT": {{Close: 100}}})
+	exec := &recordingExecutor{}
+	runner, err := NewRunner(RunnerConfig{
+		Symbols:    []string{"BTCUSDT"},
+		Strategies: strategies,
+		Executor:   exec,
+		Account:    account,
+		Clock:      func() time.Time { return *now },
+		Churn:      limits,
+	})
+	if err != nil {
+		t.Fatalf("NewRunner 失败: %v", err)
+	}
+	return runner, exec
+}
+
+func TestChurnMinEntryIntervalAndDailyCap(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	runner, exec := newChurnRunner(t, ChurnLimits{MinEntryInterval: time.Hour, MaxTradesPerDay: 2}, nil, &now, alwaysLong)
+
+	// 每 30 分钟一轮：间隔 1h 只允许隔轮开仓，每天最多 2 次
+	var opened []string
+	for i := 0; i < 10; i++ {
+		before := len(exec.executed)
+		if _, err := runner.RunOnce(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if len(exec.executed) > before {
+			opened = append(opened, now.Format("01-02 15:04"))
+		}
+		now = now.Add(30 * time.Minute)
+	}
+	if len(opened) != 2 || opened[0] != "07-01 00:00" || opened[1] != "07-01 01:00" {
+		t.Fatalf("开仓时间 = %v", opened)
+	}
+
+	// 第二天（UTC）计数重置
+	now = time.Date(2024, 7, 2, 0, 0, 0, 0, time.UTC)
+	results, _ := runner.RunOnce(context.Background())
+	if results[0].Rejected {
+		t.Errorf("新的一天应允许开仓: %v", results[0].Err)
+	}
+}
+
+func TestChurnStopOutCooldown(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	account := &mutableAccount{positions: []decision.PositionInfo{{Symbol: "BTCUSDT", Side: "long"}}}
+	runner, _ := newChurnRunner(t, ChurnLimits{StopOutCooldown: 4 * time.Hour}, account, &now,
+		Func(func(ctx context.Context, s MarketSnapshot) []Decision {
+			if len(s.Positions) > 0 {
+				return nil
+			}
+			return []Decision{{Action: "open_long", Leverage: 1, PositionSizeUSD: 100}}
+		}))
+
+	if _, err := runner.RunOnce(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// 持仓在两轮之间消失（止损触发），冷却期内拒绝开仓
+	account.positions = nil
+	now = now.Add(15 * time.Minute)
+	results, _ := runner.RunOnce(context.Background())
+	if len(results) != 1 || !results[0].Rejected {
+		t.Fatalf("止损后冷却期内应拒绝开仓: %+v", results)
+	}
+
+	now = now.Add(4 * time.Hour)
+	results, _ = runner.RunOnce(context.Background())
+	if results[0].Rejected {
+		t.Errorf("冷却结束后应允许开仓: %v", results[0].Err)
+	}
+}
+
+func TestChurnStrategyCloseIsNotStopOut(t *testing.T) {
+	now := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	account := &mutableAccount{positions: []decision.PositionInfo{{Symbol: "BTCUSDT", Side: "long"}}}
+	round := 0
+	runner, exec := newChurnRunner(t, ChurnLimits{StopOutCooldown: 4 * time.Hour}, account, &now,
+		Func(func(ctx context.Context, s MarketSnapshot) []Decision {
+			round++
+			if round == 1 {
+				return []Decision{{Action: "close_long"}}
+			}
+			return []Decision{{Action: "open_long", Leverage: 1, PositionSizeUSD: 100}}
+		}))
+
+	runner.RunOnce(context.Background())
+	account.positions = nil
+	now = now.Add(15 * time.Minute)
+	results, _ := runner.RunOnce(context.Background())
+	if results[0].Rejected || len(exec.executed) != 2 {
+		t.Errorf("策略主动平仓后不应进入止损冷却: %+v", results)
+	}
+}
+
+func TestChurnSymbolOverride(t *testing.T) {
+	guard := newChurnGuard(ChurnLimits{}, map[string]ChurnLimits{"DOGEUSDT": {MaxTradesPerDay: 1}})
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	for _, symbol := range []string{"DOGEUSDT", "BTCUSDT"} {
+		guard.record(Decision{Symbol: symbol, Action: "open_short"}, now)
+	}
+	if err := guard.check("DOGEUSDT", now); err == nil {
+		t.Error("DOGEUSDT 应按覆盖配置限制每日开仓次数")
+	}
+	if err := guard.check("BTCUSDT", now); err != nil {
+		t.Errorf("BTCUSDT 未配置限制: %v", err)
+	}
+	if newChurnGuard(ChurnLimits{}, nil) != nil {
+		t.Error("未配置限制时不应创建 churnGuard")
+	}
+}
@@ -0,0 +1,294 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"nofx/decision"
+)
+
+// FundingSource 预测资金费率来源（*trader.AutoTrader 实现了该接口）
+type FundingSource interface {
+	// StrategyFundingRate 下次结算的预测资金费率（小数，正数表示多头支付空头）
+	StrategyFundingRate(symbol string) (float64, error)
+}
+
+// Venue 资金费率套利的一个交易场所（通常是一个交易员对应的交易所账户）
+type Venue struct {
+	Name     string
+	Executor Executor
+	Account  AccountSource
+	Funding  FundingSource // nil=现货（不收付资金费，只能做多）
+}
+
+// FundingArbConfig 资金费率套利配置
+type FundingArbConfig struct {
+	Symbols        []string
+	Venues         []Venue // 至少两个场所，其中至少一个为永续合约
+	EntrySpreadBps float64 // 空头场所与多头场所单次结算的资金费率差达到该值（基点）时开仓（默认 5）
+	ExitSpreadBps  float64 // 持仓期间费率差收窄到该值以下时平仓（默认 1）
+	SizeUSD        float64 // 每条腿的名义价值（默认 1000）
+	Leverage       int     // 每条腿的杠杆（默认 1）
+	ProtectPct     float64 // 每条腿的保护性止损/止盈距离（%，默认 20；对冲持仓只防极端行情和强平）
+	Feed           Feed    // 可选：价格来源（nil=实时行情）
+}
+
+// ArbAction 一轮检查中对一个币种的处理结果
+type ArbAction struct {
+	Symbol     string
+	Action     string // "open" / "unwind" / "hold"
+	LongVenue  string
+	ShortVenue string
+	SpreadBps  float64 // 空头场所费率 - 多头场所费率（基点）
+	Reason     string
+	Err        error
+}
+
+// FundingArb 跨场所资金费率套利：资金费率差足够大时在费率最高的永续合约做空、在费率最低的场所（或现货）做多，
+// 两条腿名义价值相同（Delta 中性），费率差收窄或任一腿被平掉时两条腿一起平仓。
+// 持仓从各场所的账户读取（重启后继续管理已有的对冲仓位），套利币种不应再由其他策略交易
+type FundingArb struct {
+	cfg FundingArbConfig
+}
+
+// NewFundingArb 创建资金费率套利
+func NewFundingArb(cfg FundingArbConfig) (*FundingArb, error) {
+	if len(cfg.Symbols) == 0 {
+		return nil, fmt.Errorf("funding_arb: 未配置交易币种")
+	}
+	if len(cfg.Venues) < 2 {
+		return nil, fmt.Errorf("funding_arb: 至少需要两个交易场所（当前 %d）", len(cfg.Venues))
+	}
+	perps := 0
+	for _, v := range cfg.Venues {
+		if v.Executor == nil || v.Account == nil {
+			return nil, fmt.Errorf("funding_arb: 场所 %s 未配置交易执行器或账户", v.Name)
+		}
+		if v.Funding != nil {
+			perps++
+		}
+	}
+	if perps == 0 {
+		return nil, fmt.Errorf("funding_arb: 至少需要一个永续合约场所")
+	}
+	if cfg.EntrySpreadBps == 0 {
+		cfg.EntrySpreadBps = 5
+	}
+	if cfg.ExitSpreadBps == 0 {
+		cfg.ExitSpreadBps = 1
+	}
+	if cfg.SizeUSD == 0 {
+		cfg.SizeUSD = 1000
+	}
+	if cfg.Leverage == 0 {
+		cfg.Leverage = 1
+	}
+	if cfg.ProtectPct == 0 {
+		cfg.ProtectPct = 20
+	}
+	if cfg.ExitSpreadBps >= cfg.EntrySpreadBps {
+		return nil, fmt.Errorf("funding_arb: 平仓费率差 %.2f bps 必须小于开仓费率差 %.2f bps", cfg.ExitSpreadBps, cfg.EntrySpreadBps)
+	}
+	if cfg.SizeUSD < 0 || cfg.Leverage < 0 || cfg.ProtectPct < 0 || cfg.ProtectPct >= 100 {
+		return nil, fmt.Errorf("funding_arb: size_usd、leverage 必须大于 0，protect_pct 必须在 0-100 之间")
+	}
+	return &FundingArb{cfg: cfg}, nil
+}
+
+// Run 每隔 every 检查一轮，直到 ctx 取消
+func (a *FundingArb) Run(ctx context.Context, every time.Duration) error {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if _, err := a.RunOnce(ctx); err != nil {
+			log.Printf("⚠️  资金费率套利检查失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce 执行一轮：读取各场所持仓和资金费率 → 已有对冲仓位检查是否需要平仓 → 无仓位时寻找费率差最大的场所组合开仓
+func (a *FundingArb) RunOnce(ctx context.Context) ([]ArbAction, error) {
+	positions := make([][]decision.PositionInfo, len(a.cfg.Venues))
+	for i, v := range a.cfg.Venues {
+		_, pos, err := v.Account.StrategyAccount()
+		if err != nil {
+			return nil, fmt.Errorf("获取 %s 持仓失败: %w", v.Name, err)
+		}
+		positions[i] = pos
+	}
+
+	var actions []ArbAction
+	for _, symbol := range a.cfg.Symbols {
+		if err := ctx.Err(); err != nil {
+			return actions, err
+		}
+		rates := a.fundingRates(symbol)
+		longIdx, shortIdx := -1, -1
+		for i := range a.cfg.Venues {
+			for _, pos := range positionsFor(positions[i], symbol) {
+				if pos.Side == "long" && longIdx < 0 {
+					longIdx = i
+				} else if pos.Side == "short" && shortIdx < 0 {
+					shortIdx = i
+				}
+			}
+		}
+
+		var action ArbAction
+		switch {
+		case longIdx >= 0 && shortIdx >= 0:
+			action = a.manage(symbol, longIdx, shortIdx, rates)
+		case longIdx >= 0 || shortIdx >= 0:
+			action = a.unwind(symbol, longIdx, shortIdx, 0, "对冲腿缺失（被止损、强平或人工平仓）")
+		default:
+			action = a.enter(symbol, rates)
+		}
+		actions = append(actions, action)
+	}
+	return actions, nil
+}
+
+// fundingRates 各场所的预测资金费率（现货为 0；获取失败的场所不在结果中）
+func (a *FundingArb) fundingRates(symbol string) map[int]float64 {
+	rates := make(map[int]float64, len(a.cfg.Venues))
+	for i, v := range a.cfg.Venues {
+		if v.Funding == nil {
+			rates[i] = 0
+			continue
+		}
+		rate, err := v.Funding.StrategyFundingRate(symbol)
+		if err != nil {
+			log.Printf("⚠️  %s %s 获取资金费率失败: %v", v.Name, symbol, err)
+			continue
+		}
+		rates[i] = rate
+	}
+	return rates
+}
+
+// manage 已有对冲仓位：费率差收窄到 ExitSpreadBps 以下时两条腿一起平仓
+func (a *FundingArb) manage(symbol string, longIdx, shortIdx int, rates map[int]float64) ArbAction {
+	longRate, okLong := rates[longIdx]
+	shortRate, okShort := rates[shortIdx]
+	action := ArbAction{Symbol: symbol, Action: "hold", LongVenue: a.cfg.Venues[longIdx].Name, ShortVenue: a.cfg.Venues[shortIdx].Name}
+	if !okLong || !okShort {
+		action.Reason = "资金费率未知，保持仓位"
+		return action
+	}
+	spread := (shortRate - longRate) * 10000
+	action.SpreadBps = spread
+	if spread >= a.cfg.ExitSpreadBps {
+		return action
+	}
+	return a.unwind(symbol, longIdx, shortIdx, spread, fmt.Sprintf("费率差收窄至 %.2f bps（< %.2f bps）", spread, a.cfg.ExitSpreadBps))
+}
+
+// enter 无仓位：在费率最高的永续合约做空、费率最低的其他场所做多，费率差达到 EntrySpreadBps 时开仓
+// 先开多头腿，空头腿失败时立即平掉多头腿，不留下单边敞口
+func (a *FundingArb) enter(symbol string, rates map[int]float64) ArbAction {
+	shortIdx := -1
+	for i, v := range a.cfg.Venues {
+		if rate, ok := rates[i]; ok && v.Funding != nil && (shortIdx < 0 || rate > rates[shortIdx]) {
+			shortIdx = i
+		}
+	}
+	longIdx := -1
+	for i := range a.cfg.Venues {
+		if rate, ok := rates[i]; ok && i != shortIdx && (longIdx < 0 || rate < rates[longIdx]) {
+			longIdx = i
+		}
+	}
+	action := ArbAction{Symbol: symbol, Action: "hold"}
+	if shortIdx < 0 || longIdx < 0 {
+		action.Reason = "可用的资金费率不足两个场所"
+		return action
+	}
+	long, short := a.cfg.Venues[longIdx], a.cfg.Venues[shortIdx]
+	action.LongVenue, action.ShortVenue = long.Name, short.Name
+	action.SpreadBps = (rates[shortIdx] - rates[longIdx]) * 10000
+	if action.SpreadBps < a.cfg.EntrySpreadBps {
+		action.Reason = fmt.Sprintf("费率差 %.2f bps 未达到开仓阈值 %.2f bps", action.SpreadBps, a.cfg.EntrySpreadBps)
+		return action
+	}
+
+	price, err := a.price(symbol)
+	if err != nil {
+		action.Err = err
+		return action
+	}
+	protect := a.cfg.ProtectPct / 100
+	reason := fmt.Sprintf("资金费率套利：%s 费率 %.4f%% / %s 费率 %.4f%%，费率差 %.2f bps",
+		short.Name, rates[shortIdx]*100, long.Name, rates[longIdx]*100, action.SpreadBps)
+	longLeg := Decision{Symbol: symbol, Action: "open_long", Leverage: a.cfg.Leverage, PositionSizeUSD: a.cfg.SizeUSD,
+		StopLoss: price * (1 - protect), TakeProfit: price * (1 + protect), Reasoning: reason, Tag: "funding_arb"}
+	shortLeg := Decision{Symbol: symbol, Action: "open_short", Leverage: a.cfg.Leverage, PositionSizeUSD: a.cfg.SizeUSD,
+		StopLoss: price * (1 + protect), TakeProfit: price * (1 - protect), Reasoning: reason, Tag: "funding_arb"}
+
+	if err := long.Executor.ExecuteDecision(longLeg); err != nil {
+		action.Err = fmt.Errorf("%s 开多失败: %w", long.Name, err)
+		return action
+	}
+	if err := short.Executor.ExecuteDecision(shortLeg); err != nil {
+		action.Err = fmt.Errorf("%s 开空失败: %w", short.Name, err)
+		if uerr := long.Executor.ExecuteDecision(Decision{Symbol: symbol, Action: "close_long", Reasoning: "对冲腿开仓失败，平掉多头腿"}); uerr != nil {
+			action.Err = fmt.Errorf("%w；平掉 %s 多头腿也失败，存在单边敞口: %v", action.Err, long.Name, uerr)
+			log.Printf("🚨 %s 资金费率套利开空失败且多头腿平仓失败，%s 存在单边敞口: %v", symbol, long.Name, uerr)
+		}
+		return action
+	}
+	action.Action = "open"
+	action.Reason = reason
+	log.Printf("⚖️  %s", reason)
+	return action
+}
+
+// unwind 平掉现有的对冲腿（longIdx/shortIdx 为 -1 表示该腿不存在）
+func (a *FundingArb) unwind(symbol string, longIdx, shortIdx int, spread float64, reason string) ArbAction {
+	action := ArbAction{Symbol: symbol, Action: "unwind", SpreadBps: spread, Reason: reason}
+	var errs []error
+	if shortIdx >= 0 {
+		v := a.cfg.Venues[shortIdx]
+		action.ShortVenue = v.Name
+		if err := v.Executor.ExecuteDecision(Decision{Symbol: symbol, Action: "close_short", Reasoning: "资金费率套利平仓：" + reason}); err != nil {
+			errs = append(errs, fmt.Errorf("%s 平空失败: %w", v.Name, err))
+		}
+	}
+	if longIdx >= 0 {
+		v := a.cfg.Venues[longIdx]
+		action.LongVenue = v.Name
+		if err := v.Executor.ExecuteDecision(Decision{Symbol: symbol, Action: "close_long", Reasoning: "资金费率套利平仓：" + reason}); err != nil {
+			errs = append(errs, fmt.Errorf("%s 平多失败: %w", v.Name, err))
+		}
+	}
+	if len(errs) > 0 {
+		action.Err = fmt.Errorf("%s 平仓失败: %v", symbol, errs)
+		log.Printf("❌ %s 资金费率套利平仓失败（%s）: %v", symbol, reason, errs)
+		return action
+	}
+	log.Printf("⚖️  %s 资金费率套利平仓：%s", symbol, reason)
+	return action
+}
+
+// price 最新价格（用于计算保护性止损/止盈）
+func (a *FundingArb) price(symbol string) (float64, error) {
+	feed := fetchSnapshot
+	if a.cfg.Feed != nil {
+		feed = a.cfg.Feed
+	}
+	series, err := feed(symbol, []string{"1m"}, 1)
+	if err != nil {
+		return 0, fmt.Errorf("获取 %s 价格失败: %w", symbol, err)
+	}
+	price := MarketSnapshot{Klines: series.Series}.Price()
+	if price <= 0 {
+		return 0, fmt.Errorf("%s 没有价格数据", symbol)
+	}
+	return price, nil
+}
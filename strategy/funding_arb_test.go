@@ -0,0 +1,138 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"nofx/decision"
+	"nofx/market"
+)
+
+// fakeVenue 模拟交易场所：执行决策后更新持仓，failOn 中的动作返回错误
+type fakeVenue struct {
+	rate      float64
+	positions []decision.PositionInfo
+	executed  []Decision
+	failOn    string
+}
+
+func (v *fakeVenue) ExecuteDecision(d Decision) error {
+	v.executed = append(v.executed, d)
+	if d.Action == v.failOn {
+		return errors.New("exchange error")
+	}
+	switch d.Action {
+	case "open_long", "open_short":
+		v.positions = append(v.positions, decision.PositionInfo{Symbol: d.Symbol, Side: d.Action[len("open_"):]})
+	case "close_long", "close_short":
+		v.positions = nil
+	}
+	return nil
+}
+
+func (v *fakeVenue) StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error) {
+	return decision.AccountInfo{}, v.positions, nil
+}
+
+func (v *fakeVenue) StrategyFundingRate(symbol string) (float64, error) {
+	return v.rate, nil
+}
+
+func newArb(t *testing.T, venues map[string]*fakeVenue, spot *fakeVenue) *FundingArb {
+	withSnapshots(t, map[string][]market.Kline{"BTCUSDT": {{Close: 100}}})
+	var list []Venue
+	for _, name := range []string{"binance", "hyperliquid"} {
+		if v, ok := venues[name]; ok {
+			list = append(list, Venue{Name: name, Executor: v, Account: v, Funding: v})
+		}
+	}
+	if spot != nil {
+		list = append(list, Venue{Name: "spot", Executor: spot, Account: spot})
+	}
+	arb, err := NewFundingArb(FundingArbConfig{Symbols: []string{"BTCUSDT"}, Venues: list, EntrySpreadBps: 5, ExitSpreadBps: 1, SizeUSD: 500})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return arb
+}
+
+func TestFundingArbOpensAndUnwinds(t *testing.T) {
+	binance, hl := &fakeVenue{rate: 0.0008}, &fakeVenue{rate: 0.0001}
+	arb := newArb(t, map[string]*fakeVenue{"binance": binance, "hyperliquid": hl}, nil)
+
+	// 费率差 7 bps：在 binance 做空、hyperliquid 做多
+	actions, err := arb.RunOnce(context.Background())
+	if err != nil || len(actions) != 1 || actions[0].Action != "open" {
+		t.Fatalf("期望开仓: %+v, %v", actions, err)
+	}
+	if len(hl.executed) != 1 || hl.executed[0].Action != "open_long" || hl.executed[0].PositionSizeUSD != 500 || hl.executed[0].StopLoss != 80 {
+		t.Errorf("多头腿错误: %+v", hl.executed)
+	}
+	if len(binance.executed) != 1 || binance.executed[0].Action != "open_short" || binance.executed[0].StopLoss != 120 {
+		t.Errorf("空头腿错误: %+v", binance.executed)
+	}
+
+	// 费率差仍在 1 bps 之上：保持
+	binance.rate = 0.0003
+	if actions, _ := arb.RunOnce(context.Background()); actions[0].Action != "hold" {
+		t.Fatalf("费率差 2 bps 应保持仓位: %+v", actions)
+	}
+
+	// 费率差收窄到 0.5 bps：两条腿一起平仓
+	binance.rate = 0.00015
+	actions, _ = arb.RunOnce(context.Background())
+	if actions[0].Action != "unwind" || len(binance.positions) != 0 || len(hl.positions) != 0 {
+		t.Fatalf("费率差收窄应平仓: %+v", actions)
+	}
+}
+
+func TestFundingArbSpotLegAndBelowThreshold(t *testing.T) {
+	binance, spot := &fakeVenue{rate: 0.0002}, &fakeVenue{}
+	arb := newArb(t, map[string]*fakeVenue{"binance": binance}, spot)
+
+	if actions, _ := arb.RunOnce(context.Background()); actions[0].Action != "hold" || len(spot.executed) != 0 {
+		t.Fatalf("费率差 2 bps 不应开仓: %+v", actions)
+	}
+	binance.rate = 0.001
+	actions, _ := arb.RunOnce(context.Background())
+	if actions[0].Action != "open" || actions[0].LongVenue != "spot" || actions[0].ShortVenue != "binance" {
+		t.Fatalf("期望现货做多、永续做空: %+v", actions)
+	}
+}
+
+func TestFundingArbUnwindsBrokenLegs(t *testing.T) {
+	binance, hl := &fakeVenue{rate: 0.001, failOn: "open_short"}, &fakeVenue{}
+	arb := newArb(t, map[string]*fakeVenue{"binance": binance, "hyperliquid": hl}, nil)
+
+	// 空头腿开仓失败：立即平掉多头腿
+	actions, _ := arb.RunOnce(context.Background())
+	if actions[0].Err == nil || len(hl.positions) != 0 || hl.executed[len(hl.executed)-1].Action != "close_long" {
+		t.Fatalf("空头腿失败应平掉多头腿: %+v, %+v", actions, hl.executed)
+	}
+
+	// 只剩一条腿（另一条被止损）：平掉剩下的腿
+	binance.failOn = ""
+	hl.positions = []decision.PositionInfo{{Symbol: "BTCUSDT", Side: "long"}}
+	actions, _ = arb.RunOnce(context.Background())
+	if actions[0].Action != "unwind" || len(hl.positions) != 0 {
+		t.Fatalf("对冲腿缺失应平仓: %+v", actions)
+	}
+}
+
+func TestNewFundingArbValidation(t *testing.T) {
+	v := &fakeVenue{}
+	perp := Venue{Name: "perp", Executor: v, Account: v, Funding: v}
+	spot := Venue{Name: "spot", Executor: v, Account: v}
+	invalid := []FundingArbConfig{
+		{Venues: []Venue{perp, spot}},
+		{Symbols: []string{"BTCUSDT"}, Venues: []Venue{perp}},
+		{Symbols: []string{"BTCUSDT"}, Venues: []Venue{spot, spot}},
+		{Symbols: []string{"BTCUSDT"}, Venues: []Venue{perp, spot}, EntrySpreadBps: 2, ExitSpreadBps: 3},
+	}
+	for i, cfg := range invalid {
+		if _, err := NewFundingArb(cfg); err == nil {
+			t.Errorf("配置 #%d 应返回错误", i)
+		}
+	}
+}
@@ -0,0 +1,283 @@
+package strategy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// GridConfig 网格 / DCA 策略配置
+type GridConfig struct {
+	Lower    float64 // 网格下沿
+	Upper    float64 // 网格上沿
+	Levels   int     // 挂单档数（区间等分，每档止盈为相邻一档）
+	Side     string  // "long"（默认，逐档低买、上一档止盈）/ "short"（逐档高卖、下一档止盈）
+	SizeUSD  float64 // 每档开仓金额（默认 100）
+	Leverage int     // 杠杆（默认 1）
+	StopLoss float64 // 整个网格的止损价（0=多单取下沿下方一档、空单取上沿上方一档）
+
+	StatePath string // 网格状态文件（JSON，空=只保存在内存；重启后从文件恢复已成交的档位）
+}
+
+// GridLevel 网格中的一档
+type GridLevel struct {
+	Price      float64 `json:"price"`       // 挂单价
+	TakeProfit float64 `json:"take_profit"` // 该档止盈价
+	Armed      bool    `json:"armed"`       // 已挂单（价格曾在挂单价的有利一侧，防止把已越过的档位当作成交）
+	Filled     bool    `json:"filled"`      // 已成交、等待止盈
+	Quantity   float64 `json:"quantity"`    // 成交数量（按挂单价估算）
+}
+
+// gridState 网格状态文件内容
+type gridState struct {
+	Lower  float64                `json:"lower"`
+	Upper  float64                `json:"upper"`
+	Side   string                 `json:"side"`
+	Levels map[string][]GridLevel `json:"levels"` // 币种 -> 档位
+}
+
+// Grid 网格 / DCA 策略：在区间内逐档挂限价单（按K线最高/最低价判断成交），每档成交后在相邻一档止盈，
+// 止盈后重新挂单；持仓被止损或人工平掉后全部档位重置。
+// 需要运行器配置 AccountSource（根据持仓判断是否被止损），实盘交易器需允许加仓（AllowScaleIn）
+type Grid struct {
+	cfg  GridConfig
+	step float64
+
+	mu     sync.Mutex
+	levels map[string][]GridLevel
+}
+
+// NewGrid 创建网格策略，配置了 StatePath 时加载已保存的档位（区间或方向变化时丢弃旧状态）
+func NewGrid(cfg GridConfig) (*Grid, error) {
+	if cfg.Side == "" {
+		cfg.Side = "long"
+	}
+	if cfg.SizeUSD == 0 {
+		cfg.SizeUSD = 100
+	}
+	if cfg.Leverage == 0 {
+		cfg.Leverage = 1
+	}
+	switch {
+	case cfg.Side != "long" && cfg.Side != "short":
+		return nil, fmt.Errorf("grid: side 必须为 long 或 short（当前 %q）", cfg.Side)
+	case cfg.Lower <= 0 || cfg.Upper <= cfg.Lower:
+		return nil, fmt.Errorf("grid: 需要 0 < lower < upper（lower=%g, upper=%g）", cfg.Lower, cfg.Upper)
+	case cfg.Levels < 1:
+		return nil, fmt.Errorf("grid: levels 至少为 1（当前 %d）", cfg.Levels)
+	case cfg.SizeUSD < 0 || cfg.Leverage < 0:
+		return nil, fmt.Errorf("grid: size_usd 和 leverage 必须大于 0")
+	}
+
+	g := &Grid{cfg: cfg, step: (cfg.Upper - cfg.Lower) / float64(cfg.Levels), levels: make(map[string][]GridLevel)}
+	if g.cfg.StopLoss == 0 {
+		if cfg.Side == "long" {
+			g.cfg.StopLoss = cfg.Lower - g.step
+		} else {
+			g.cfg.StopLoss = cfg.Upper + g.step
+		}
+	}
+	if (cfg.Side == "long" && (g.cfg.StopLoss <= 0 || g.cfg.StopLoss >= cfg.Lower)) || (cfg.Side == "short" && g.cfg.StopLoss <= cfg.Upper) {
+		return nil, fmt.Errorf("grid: 止损价 %g 必须在网格区间之外", g.cfg.StopLoss)
+	}
+	g.load()
+	return g, nil
+}
+
+// newGridStrategy 注册表使用的构造函数（不持久化状态）
+// 参数: lower、upper（必填）、levels（默认 10）、side（1=做多，-1=做空，默认 1）、stop_loss、size_usd（每档，默认 1000）、leverage（默认 1）
+func newGridStrategy(params map[string]float64) (Strategy, error) {
+	op, err := parseOrderParams("grid", params)
+	if err != nil {
+		return nil, err
+	}
+	cfg := GridConfig{Lower: params["lower"], Upper: params["upper"], Levels: 10, SizeUSD: op.sizeUSD, Leverage: op.leverage, StopLoss: params["stop_loss"]}
+	if v, ok := params["levels"]; ok {
+		cfg.Levels = int(v)
+	}
+	if params["side"] < 0 {
+		cfg.Side = "short"
+	}
+	return NewGrid(cfg)
+}
+
+func (g *Grid) Name() string { return "grid" }
+
+// Levels 指定币种当前的档位（副本）
+func (g *Grid) Levels(symbol string) []GridLevel {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return append([]GridLevel(nil), g.levels[symbol]...)
+}
+
+// newLevels 按区间等分生成档位
+func (g *Grid) newLevels() []GridLevel {
+	levels := make([]GridLevel, g.cfg.Levels)
+	for i := range levels {
+		if g.cfg.Side == "long" {
+			price := g.cfg.Lower + float64(i)*g.step
+			levels[i] = GridLevel{Price: price, TakeProfit: price + g.step}
+		} else {
+			price := g.cfg.Upper - float64(i)*g.step
+			levels[i] = GridLevel{Price: price, TakeProfit: price - g.step}
+		}
+	}
+	return levels
+}
+
+func (g *Grid) OnCandle(ctx context.Context, snapshot MarketSnapshot) []Decision {
+	bar, ok := snapshot.Latest(shortestInterval(snapshot))
+	if !ok || bar.Close <= 0 {
+		return nil
+	}
+	long := g.cfg.Side == "long"
+	closeAction, openAction := "close_long", "open_long"
+	if !long {
+		closeAction, openAction = "close_short", "open_short"
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	levels, ok := g.levels[snapshot.Symbol]
+	if !ok {
+		levels = g.newLevels()
+		g.levels[snapshot.Symbol] = levels
+	}
+	changed := !ok
+
+	// 持仓已不存在（止损、强平或人工平仓）：已成交的档位全部重置
+	if _, open := snapshot.Position(g.cfg.Side); !open {
+		for i := range levels {
+			if levels[i].Filled {
+				levels[i].Filled, levels[i].Quantity = false, 0
+				changed = true
+			}
+		}
+	}
+
+	var decisions []Decision
+
+	// 1. 已成交档位触及止盈：按数量占比部分平仓，该档重新挂单
+	var total, closed float64
+	justClosed := make(map[int]bool)
+	for i := range levels {
+		lv := &levels[i]
+		if !lv.Filled {
+			continue
+		}
+		total += lv.Quantity
+		if (long && bar.High >= lv.TakeProfit) || (!long && bar.Low <= lv.TakeProfit) {
+			closed += lv.Quantity
+			justClosed[i] = true
+			lv.Filled, lv.Quantity, lv.Armed = false, 0, true
+		}
+	}
+	if len(justClosed) > 0 {
+		changed = true
+		reason := fmt.Sprintf("网格 %d 档止盈", len(justClosed))
+		if closed >= total*0.9999 {
+			decisions = append(decisions, Decision{Symbol: snapshot.Symbol, Action: closeAction, Reasoning: reason})
+		} else {
+			decisions = append(decisions, Decision{Symbol: snapshot.Symbol, Action: "partial_close", ClosePercentage: math.Min(closed/total*100, 100), Reasoning: reason})
+		}
+	}
+
+	// 2. 已挂单档位被K线穿过：成交（同一根K线刚止盈的档位不再成交）；价格回到挂单价有利一侧的档位重新挂单
+	var fills int
+	for i := range levels {
+		lv := &levels[i]
+		if lv.Filled || justClosed[i] {
+			continue
+		}
+		if lv.Armed && ((long && bar.Low <= lv.Price) || (!long && bar.High >= lv.Price)) {
+			lv.Filled, lv.Armed = true, false
+			lv.Quantity = g.cfg.SizeUSD / lv.Price
+			fills++
+			continue
+		}
+		if !lv.Armed && ((long && bar.Close > lv.Price) || (!long && bar.Close < lv.Price)) {
+			lv.Armed = true
+			changed = true
+		}
+	}
+	if fills > 0 {
+		changed = true
+		target := g.cfg.Upper
+		if !long {
+			target = g.cfg.Lower
+		}
+		decisions = append(decisions, Decision{
+			Symbol:          snapshot.Symbol,
+			Action:          openAction,
+			Leverage:        g.cfg.Leverage,
+			PositionSizeUSD: g.cfg.SizeUSD * float64(fills),
+			StopLoss:        g.cfg.StopLoss,
+			TakeProfit:      target,
+			Reasoning:       fmt.Sprintf("网格 %d 档成交", fills),
+		})
+	}
+
+	if changed {
+		g.save()
+	}
+	return decisions
+}
+
+// load 从状态文件恢复档位（调用方持有锁或尚未并发使用）
+func (g *Grid) load() {
+	if g.cfg.StatePath == "" {
+		return
+	}
+	data, err := os.ReadFile(g.cfg.StatePath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Printf("⚠️  读取网格状态失败: %v", err)
+		}
+		return
+	}
+	var state gridState
+	if err := json.Unmarshal(data, &state); err != nil {
+		log.Printf("⚠️  网格状态文件格式错误，重新建立网格: %v", err)
+		return
+	}
+	if state.Lower != g.cfg.Lower || state.Upper != g.cfg.Upper || state.Side != g.cfg.Side {
+		log.Printf("⚠️  网格区间或方向已变更（%g-%g %s → %g-%g %s），丢弃旧状态",
+			state.Lower, state.Upper, state.Side, g.cfg.Lower, g.cfg.Upper, g.cfg.Side)
+		return
+	}
+	for symbol, levels := range state.Levels {
+		if len(levels) == g.cfg.Levels {
+			g.levels[symbol] = levels
+		}
+	}
+	log.Printf("🕸️  已恢复 %d 个币种的网格状态", len(g.levels))
+}
+
+// save 写入状态文件（先写临时文件再重命名，防止写入中途退出损坏状态；调用方持有锁）
+func (g *Grid) save() {
+	if g.cfg.StatePath == "" {
+		return
+	}
+	data, err := json.MarshalIndent(gridState{Lower: g.cfg.Lower, Upper: g.cfg.Upper, Side: g.cfg.Side, Levels: g.levels}, "", "  ")
+	if err != nil {
+		log.Printf("⚠️  序列化网格状态失败: %v", err)
+		return
+	}
+	if err := os.MkdirAll(filepath.Dir(g.cfg.StatePath), 0700); err != nil {
+		log.Printf("⚠️  创建网格状态目录失败: %v", err)
+		return
+	}
+	tmp := g.cfg.StatePath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		log.Printf("⚠️  写入网格状态失败: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, g.cfg.StatePath); err != nil {
+		log.Printf("⚠️  保存网格状态失败: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package strategy
+
+import (
+	"context"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+func TestGridFillsAndTakesProfit(t *testing.T) {
+	// 90-110 分 4 档：挂单 90/95/100/105，止盈为上一档
+	g, err := NewGrid(GridConfig{Lower: 90, Upper: 110, Levels: 4, SizeUSD: 100, Leverage: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	// 价格 103：低于现价的 90/95/100 挂单，尚未成交
+	if d := g.OnCandle(ctx, snapshotFrom([]float64{103})); len(d) != 0 {
+		t.Fatalf("首根K线不应成交: %+v", d)
+	}
+	// 最低 98：100 档成交
+	d := g.OnCandle(ctx, snapshotFrom([]float64{99}))
+	if len(d) != 1 || d[0].Action != "open_long" || d[0].PositionSizeUSD != 100 || d[0].StopLoss != 85 || d[0].TakeProfit != 110 {
+		t.Fatalf("期望 100 档成交开多, got %+v", d)
+	}
+	// 最低 93：95 档成交
+	d = g.OnCandle(ctx, snapshotFrom([]float64{94}, "long"))
+	if len(d) != 1 || d[0].Action != "open_long" {
+		t.Fatalf("期望 95 档成交, got %+v", d)
+	}
+	// 最高 101：95 档止盈（部分平仓），100 档未到止盈
+	d = g.OnCandle(ctx, snapshotFrom([]float64{100}, "long"))
+	want := (100.0 / 95) / (100.0/95 + 100.0/100) * 100
+	if len(d) != 1 || d[0].Action != "partial_close" || math.Abs(d[0].ClosePercentage-want) > 1e-9 {
+		t.Fatalf("期望 95 档止盈部分平仓 %.2f%%, got %+v", want, d)
+	}
+	// 最高 106：100 档止盈，已是最后一档，全部平仓
+	d = g.OnCandle(ctx, snapshotFrom([]float64{105}, "long"))
+	if len(d) != 1 || d[0].Action != "close_long" {
+		t.Fatalf("期望全部止盈平仓, got %+v", d)
+	}
+	// 止盈后的档位重新挂单，再次下跌时重新成交
+	d = g.OnCandle(ctx, snapshotFrom([]float64{99}))
+	if len(d) != 1 || d[0].Action != "open_long" {
+		t.Fatalf("止盈后应重新挂单成交, got %+v", d)
+	}
+}
+
+func TestGridResetsAfterStopOut(t *testing.T) {
+	g, _ := NewGrid(GridConfig{Lower: 90, Upper: 110, Levels: 4, Side: "short"})
+	ctx := context.Background()
+	g.OnCandle(ctx, snapshotFrom([]float64{97}))
+	if d := g.OnCandle(ctx, snapshotFrom([]float64{101})); len(d) != 1 || d[0].Action != "open_short" || d[0].StopLoss != 115 {
+		t.Fatalf("期望 100 档开空, got %+v", d)
+	}
+	// 持仓消失（被止损）：已成交档位重置，不再产生止盈
+	if d := g.OnCandle(ctx, snapshotFrom([]float64{94})); len(d) != 0 {
+		t.Errorf("持仓已不存在时不应止盈, got %+v", d)
+	}
+	for _, lv := range g.Levels("BTCUSDT") {
+		if lv.Filled {
+			t.Errorf("持仓消失后档位应重置: %+v", lv)
+		}
+	}
+}
+
+func TestGridPersistsState(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "grid.json")
+	cfg := GridConfig{Lower: 90, Upper: 110, Levels: 4, StatePath: path}
+	g, _ := NewGrid(cfg)
+	ctx := context.Background()
+	g.OnCandle(ctx, snapshotFrom([]float64{103}))
+	g.OnCandle(ctx, snapshotFrom([]float64{99}))
+
+	restored, err := NewGrid(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	levels := restored.Levels("BTCUSDT")
+	if len(levels) != 4 || !levels[2].Filled || levels[2].Price != 100 {
+		t.Fatalf("重启后应恢复 100 档已成交: %+v", levels)
+	}
+	// 已恢复的档位继续止盈
+	if d := restored.OnCandle(ctx, snapshotFrom([]float64{105}, "long")); len(d) != 1 || d[0].Action != "close_long" {
+		t.Errorf("恢复后应按原档位止盈, got %+v", d)
+	}
+
+	cfg.Upper = 120
+	changed, _ := NewGrid(cfg)
+	if len(changed.Levels("BTCUSDT")) != 0 {
+		t.Error("区间变化后应丢弃旧状态")
+	}
+}
+
+func TestGridConfigValidation(t *testing.T) {
+	invalid := []GridConfig{
+		{Lower: 110, Upper: 90, Levels: 4},
+		{Lower: 90, Upper: 110},
+		{Lower: 90, Upper: 110, Levels: 4, Side: "both"},
+		{Lower: 90, Upper: 110, Levels: 4, StopLoss: 95},
+	}
+	for _, cfg := range invalid {
+		if _, err := NewGrid(cfg); err == nil {
+			t.Errorf("配置 %+v 应返回错误", cfg)
+		}
+	}
+	if _, err := New("grid", map[string]float64{"lower": 90, "upper": 110, "levels": 4, "side": -1}); err != nil {
+		t.Errorf("注册表创建网格失败: %v", err)
+	}
+}
@@ -0,0 +1,92 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"nofx/decision"
+)
+
+// Provider 将规则策略适配为 decision.DecisionProvider：对交易上下文中的候选币种和持仓币种逐一构建行情快照并调用策略，
+// 可替代 AI 决策或与 AI 一起投票（decision.Ensemble）
+type Provider struct {
+	Strategies []Strategy
+	Intervals  []string // 提供给策略的K线周期（默认 ["15m", "1h", "4h"]）
+	Limit      int      // 每个周期的K线数量（默认 200）
+	Feed       Feed     // 可选：K线数据源（nil=实时行情）
+}
+
+// Name 实现 decision.DecisionProvider
+func (p *Provider) Name() string {
+	names := make([]string, len(p.Strategies))
+	for i, s := range p.Strategies {
+		names[i] = strategyName(s)
+	}
+	return "strategy:" + strings.Join(names, "+")
+}
+
+// Decide 实现 decision.DecisionProvider
+func (p *Provider) Decide(ctx *decision.Context) (*decision.FullDecision, error) {
+	if len(p.Strategies) == 0 {
+		return nil, fmt.Errorf("未配置策略")
+	}
+	intervals, limit, feed := p.Intervals, p.Limit, p.Feed
+	if len(intervals) == 0 {
+		intervals = []string{"15m", "1h", "4h"}
+	}
+	if limit <= 0 {
+		limit = 200
+	}
+	if feed == nil {
+		feed = fetchSnapshot
+	}
+
+	// 持仓币种优先（需要管理出场），再加上候选币种
+	var symbols []string
+	seen := make(map[string]bool)
+	for _, pos := range ctx.Positions {
+		if !seen[pos.Symbol] {
+			seen[pos.Symbol] = true
+			symbols = append(symbols, pos.Symbol)
+		}
+	}
+	for _, coin := range ctx.CandidateCoins {
+		if !seen[coin.Symbol] {
+			seen[coin.Symbol] = true
+			symbols = append(symbols, coin.Symbol)
+		}
+	}
+
+	full := &decision.FullDecision{Timestamp: time.Now()}
+	var trace strings.Builder
+	for _, symbol := range symbols {
+		series, err := feed(symbol, intervals, limit)
+		if err != nil {
+			fmt.Fprintf(&trace, "%s 获取K线失败，跳过: %v\n", symbol, err)
+			continue
+		}
+		snapshot := MarketSnapshot{
+			Symbol:    series.Symbol,
+			Time:      full.Timestamp,
+			Klines:    series.Series,
+			Account:   ctx.Account,
+			Positions: positionsFor(ctx.Positions, series.Symbol),
+		}
+		for _, s := range p.Strategies {
+			for _, d := range s.OnCandle(context.Background(), snapshot) {
+				if d.Symbol == "" {
+					d.Symbol = snapshot.Symbol
+				}
+				if d.Tag == "" {
+					d.Tag = strategyName(s)
+				}
+				fmt.Fprintf(&trace, "[%s] %s %s: %s\n", strategyName(s), d.Symbol, d.Action, d.Reasoning)
+				full.Decisions = append(full.Decisions, d)
+			}
+		}
+	}
+	full.CoTTrace = trace.String()
+	return full, nil
+}
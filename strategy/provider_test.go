@@ -0,0 +1,36 @@
+package strategy
+
+import (
+	"strings"
+	"testing"
+
+	"nofx/decision"
+	"nofx/market"
+)
+
+func TestProviderDecide(t *testing.T) {
+	withSnapshots(t, map[string][]market.Kline{
+		"BTCUSDT": {{High: 100, Low: 90, Close: 95}, {High: 105, Low: 96, Close: 104}},
+		"ETHUSDT": {{High: 100, Low: 90, Close: 95}, {High: 99, Low: 85, Close: 86}},
+	})
+	p := &Provider{Strategies: []Strategy{breakoutStrategy{}}}
+	if p.Name() != "strategy:breakout" {
+		t.Errorf("名称错误: %s", p.Name())
+	}
+
+	full, err := p.Decide(&decision.Context{
+		Positions:      []decision.PositionInfo{{Symbol: "ETHUSDT", Side: "long"}},
+		CandidateCoins: []decision.CandidateCoin{{Symbol: "BTCUSDT"}, {Symbol: "ETHUSDT"}, {Symbol: "DOGEUSDT"}},
+	})
+	if err != nil {
+		t.Fatalf("策略决策失败: %v", err)
+	}
+	// 持仓币种优先：ETH 跌破前低平仓，BTC 突破开多，DOGE 无数据跳过
+	if len(full.Decisions) != 2 || full.Decisions[0].Symbol != "ETHUSDT" || full.Decisions[0].Action != "close_long" ||
+		full.Decisions[1].Symbol != "BTCUSDT" || full.Decisions[1].Action != "open_long" {
+		t.Errorf("决策错误: %+v", full.Decisions)
+	}
+	if !strings.Contains(full.CoTTrace, "DOGEUSDT 获取K线失败") {
+		t.Errorf("思维链应记录跳过的币种: %s", full.CoTTrace)
+	}
+}
@@ -0,0 +1,53 @@
+package strategy
+
+import (
+	"fmt"
+
+	"nofx/regime"
+)
+
+// RegimeRisk 按市场状态调整开仓：用快照中 Interval 周期的K线判定趋势/震荡/高波动，
+// 按 Policy 缩放开仓金额和止损距离（不拒绝决策；K线不足时不调整）
+type RegimeRisk struct {
+	Interval string          // 判定使用的K线周期（默认 "1h"，需在 RunnerConfig.Intervals 中）
+	Tracker  *regime.Tracker // 可选：记录状态并在变化时发布事件（nil=仅按默认参数分类）
+	Policy   regime.Policy   // 可选：nil=regime.DefaultPolicy
+}
+
+// Check 实现 RiskManager
+func (r RegimeRisk) Check(d *Decision, snapshot MarketSnapshot) error {
+	if d.Action != "open_long" && d.Action != "open_short" {
+		return nil
+	}
+	interval := r.Interval
+	if interval == "" {
+		interval = "1h"
+	}
+	klines := snapshot.Klines[interval]
+	var reading regime.Reading
+	var err error
+	if r.Tracker != nil {
+		reading, err = r.Tracker.Update(snapshot.Symbol, interval, klines)
+	} else {
+		reading, err = regime.Classify(klines, interval, regime.Config{})
+	}
+	if err != nil {
+		return nil
+	}
+
+	policy := r.Policy
+	if policy == nil {
+		policy = regime.DefaultPolicy
+	}
+	adj := policy.For(reading.Regime)
+	d.PositionSizeUSD *= adj.SizeMult
+	if price := klines[len(klines)-1].Close; d.StopLoss > 0 && price > 0 {
+		if stop := price + (d.StopLoss-price)*adj.StopMult; stop > 0 {
+			d.StopLoss = stop
+		}
+	}
+	if adj.SizeMult != 1 || adj.StopMult != 1 {
+		d.Reasoning += fmt.Sprintf(" [市场状态 %s: 仓位 ×%.2f, 止损距离 ×%.2f]", reading.Regime, adj.SizeMult, adj.StopMult)
+	}
+	return nil
+}
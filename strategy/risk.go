@@ -0,0 +1,52 @@
+package strategy
+
+import "fmt"
+
+// RiskManager 风控：在策略决策交给交易器执行前检查，返回错误则拒绝该决策
+type RiskManager interface {
+	Check(d *Decision, snapshot MarketSnapshot) error
+}
+
+// RiskLimits 基础风控规则（字段为零值表示不限制）
+type RiskLimits struct {
+	MaxPositionSizeUSD float64 // 单笔开仓最大名义价值
+	MaxLeverage        int     // 最大杠杆
+	MaxOpenPositions   int     // 最大同时持仓数（仅限制开新仓）
+	RequireStopLoss    bool    // 开仓必须设置止损
+}
+
+// Check 实现 RiskManager
+func (r RiskLimits) Check(d *Decision, snapshot MarketSnapshot) error {
+	if d.Action != "open_long" && d.Action != "open_short" {
+		return nil
+	}
+	if d.PositionSizeUSD <= 0 {
+		return fmt.Errorf("开仓金额必须大于0: %.2f", d.PositionSizeUSD)
+	}
+	if r.MaxPositionSizeUSD > 0 && d.PositionSizeUSD > r.MaxPositionSizeUSD {
+		return fmt.Errorf("开仓金额 %.2f USDT 超过上限 %.2f USDT", d.PositionSizeUSD, r.MaxPositionSizeUSD)
+	}
+	if r.MaxLeverage > 0 && d.Leverage > r.MaxLeverage {
+		return fmt.Errorf("杠杆 %dx 超过上限 %dx", d.Leverage, r.MaxLeverage)
+	}
+	if r.RequireStopLoss && d.StopLoss <= 0 {
+		return fmt.Errorf("开仓必须设置止损")
+	}
+	if r.MaxOpenPositions > 0 && len(snapshot.Positions) == 0 && snapshot.Account.PositionCount >= r.MaxOpenPositions {
+		return fmt.Errorf("持仓数 %d 已达上限 %d", snapshot.Account.PositionCount, r.MaxOpenPositions)
+	}
+	return nil
+}
+
+// RiskChain 依次执行多个风控规则，任一拒绝即拒绝
+type RiskChain []RiskManager
+
+// Check 实现 RiskManager
+func (c RiskChain) Check(d *Decision, snapshot MarketSnapshot) error {
+	for _, r := range c {
+		if err := r.Check(d, snapshot); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+package strategy
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"nofx/decision"
+	"nofx/eventbus"
+	"nofx/market"
+)
+
+// Executor 交易执行（*trader.AutoTrader 实现了该接口）
+type Executor interface {
+	ExecuteDecision(d Decision) error
+}
+
+// AccountSource 账户与持仓来源（*trader.AutoTrader 实现了该接口）
+type AccountSource interface {
+	StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error)
+}
+
+// Feed 多周期K线数据源（签名与 market.GetMultiTimeframe 一致）
+type Feed func(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error)
+
+// fetchSnapshot 默认的K线数据源（测试中可替换）
+var fetchSnapshot Feed = market.GetMultiTimeframe
+
+// RunnerConfig 策略运行器配置
+type RunnerConfig struct {
+	Symbols    []string   // 交易币种
+	Intervals  []string   // 提供给策略的K线周期（默认 ["15m", "1h", "4h"]）
+	Limit      int        // 每个周期的K线数量（默认 200）
+	Strategies []Strategy // 策略（按顺序调用，决策合并后执行）
+	Risk       RiskManager
+	Executor   Executor
+	Account    AccountSource    // 可选：nil 时快照不含账户与持仓
+	Feed       Feed             // 可选：K线数据源（nil=实时行情，回测时替换为历史回放）
+	Clock      func() time.Time // 可选：快照时间（nil=time.Now）
+
+	Churn       ChurnLimits            // 可选：每个币种的交易频率限制（开仓间隔、每日次数、止损后冷却）
+	SymbolChurn map[string]ChurnLimits // 可选：按币种覆盖 Churn
+}
+
+// Result 一条决策的处理结果
+type Result struct {
+	Strategy string
+	Decision Decision
+	Rejected bool  // 被风控拒绝
+	Err      error // 风控拒绝原因或执行错误
+}
+
+// Runner 策略运行器
+type Runner struct {
+	config RunnerConfig
+	churn  *churnGuard // nil=不限制交易频率
+}
+
+// NewRunner 创建策略运行器
+func NewRunner(config RunnerConfig) (*Runner, error) {
+	if len(config.Strategies) == 0 {
+		return nil, fmt.Errorf("至少需要一个策略")
+	}
+	if config.Executor == nil {
+		return nil, fmt.Errorf("未配置交易执行器")
+	}
+	if len(config.Intervals) == 0 {
+		config.Intervals = []string{"15m", "1h", "4h"}
+	}
+	if config.Limit <= 0 {
+		config.Limit = 200
+	}
+	return &Runner{config: config, churn: newChurnGuard(config.Churn, config.SymbolChurn)}, nil
+}
+
+// Run 每隔 every 执行一轮，直到 ctx 取消
+func (r *Runner) Run(ctx context.Context, every time.Duration) error {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	for {
+		if _, err := r.RunOnce(ctx); err != nil {
+			log.Printf("⚠️  策略周期执行失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnCandleClose 订阅事件总线，interval 周期的K线收盘时执行一轮（同一收盘时间只执行一次），直到 ctx 取消
+// 需要有组件在推送该周期的K线（market.StreamKlines），否则不会触发
+func (r *Runner) RunOnCandleClose(ctx context.Context, bus *eventbus.Bus, interval string) error {
+	events, unsubscribe := bus.Subscribe(64, eventbus.TopicCandleClosed)
+	defer unsubscribe()
+
+	var lastOpenTime int64
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-events:
+			candle := ev.(eventbus.CandleClosed)
+			if candle.Interval != interval || candle.OpenTime <= lastOpenTime {
+				continue // 多个币种同时收盘只执行一轮
+			}
+			lastOpenTime = candle.OpenTime
+			if _, err := r.RunOnce(ctx); err != nil {
+				log.Printf("⚠️  策略周期执行失败: %v", err)
+			}
+		}
+	}
+}
+
+// RunOnce 执行一轮：获取行情 → 调用全部策略 → 频率限制与风控检查 → 执行（先平仓后开仓）
+func (r *Runner) RunOnce(ctx context.Context) ([]Result, error) {
+	var account decision.AccountInfo
+	var positions []decision.PositionInfo
+	if r.config.Account != nil {
+		var err error
+		account, positions, err = r.config.Account.StrategyAccount()
+		if err != nil {
+			return nil, fmt.Errorf("获取账户信息失败: %w", err)
+		}
+	}
+
+	type pending struct {
+		strategy string
+		decision Decision
+		snapshot MarketSnapshot
+	}
+	var queue []pending
+	now := time.Now()
+	if r.config.Clock != nil {
+		now = r.config.Clock()
+	}
+	feed := fetchSnapshot
+	if r.config.Feed != nil {
+		feed = r.config.Feed
+	}
+	for _, symbol := range r.config.Symbols {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		series, err := feed(symbol, r.config.Intervals, r.config.Limit)
+		if err != nil {
+			log.Printf("⚠️  %s 获取K线失败，跳过: %v", symbol, err)
+			continue
+		}
+		snapshot := MarketSnapshot{
+			Symbol:    series.Symbol,
+			Time:      now,
+			Klines:    series.Series,
+			Account:   account,
+			Positions: positionsFor(positions, series.Symbol),
+		}
+		if r.churn != nil && r.config.Account != nil {
+			r.churn.observe(snapshot.Symbol, snapshot.Positions, now)
+		}
+		for _, s := range r.config.Strategies {
+			name := strategyName(s)
+			for _, d := range s.OnCandle(ctx, snapshot) {
+				if d.Symbol == "" {
+					d.Symbol = snapshot.Symbol
+				}
+				if d.Tag == "" {
+					d.Tag = name
+				}
+				queue = append(queue, pending{strategy: name, decision: d, snapshot: snapshot})
+			}
+		}
+	}
+
+	sort.SliceStable(queue, func(i, j int) bool {
+		return actionPriority(queue[i].decision.Action) < actionPriority(queue[j].decision.Action)
+	})
+
+	results := make([]Result, 0, len(queue))
+	for _, p := range queue {
+		result := Result{Strategy: p.strategy, Decision: p.decision}
+		d := p.decision
+		if d.Action == "hold" || d.Action == "wait" {
+			results = append(results, result)
+			continue
+		}
+		if r.churn != nil && (d.Action == "open_long" || d.Action == "open_short") {
+			if err := r.churn.check(d.Symbol, now); err != nil {
+				log.Printf("🧊 [%s] 频率限制拒绝 %s %s: %v", p.strategy, d.Symbol, d.Action, err)
+				eventbus.Publish(eventbus.RiskTripped{Source: p.strategy, Symbol: d.Symbol, Reason: err.Error(), Time: now})
+				result.Rejected, result.Err = true, err
+				results = append(results, result)
+				continue
+			}
+		}
+		if r.config.Risk != nil {
+			if err := r.config.Risk.Check(&d, p.snapshot); err != nil {
+				log.Printf("🛡️ [%s] 风控拒绝 %s %s: %v", p.strategy, d.Symbol, d.Action, err)
+				eventbus.Publish(eventbus.RiskTripped{Source: p.strategy, Symbol: d.Symbol, Reason: err.Error(), Time: now})
+				result.Rejected, result.Err = true, err
+				results = append(results, result)
+				continue
+			}
+		}
+		result.Decision = d
+		if err := r.config.Executor.ExecuteDecision(d); err != nil {
+			log.Printf("❌ [%s] 执行 %s %s 失败: %v", p.strategy, d.Symbol, d.Action, err)
+			result.Err = err
+		} else {
+			log.Printf("✓ [%s] %s %s 已执行", p.strategy, d.Symbol, d.Action)
+			if r.churn != nil {
+				r.churn.record(d, now)
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// positionsFor 筛选指定币种的持仓
+func positionsFor(positions []decision.PositionInfo, symbol string) []decision.PositionInfo {
+	var result []decision.PositionInfo
+	for _, pos := range positions {
+		if pos.Symbol == symbol {
+			result = append(result, pos)
+		}
+	}
+	return result
+}
+
+// strategyName 策略名称（未实现 Named 时使用类型名）
+func strategyName(s Strategy) string {
+	if n, ok := s.(Named); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+// actionPriority 执行顺序：先平仓，再调整止盈止损，最后开仓（防止换仓时仓位叠加超限）
+func actionPriority(action string) int {
+	switch action {
+	case "close_long", "close_short", "partial_close":
+		return 1
+	case "update_stop_loss", "update_take_profit":
+		return 2
+	case "open_long", "open_short":
+		return 3
+	default:
+		return 4
+	}
+}
@@ -0,0 +1,268 @@
+package strategy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"nofx/decision"
+	"nofx/eventbus"
+	"nofx/market"
+	"nofx/regime"
+)
+
+type recordingExecutor struct {
+	executed []Decision
+}
+
+func (e *recordingExecutor) ExecuteDecision(d Decision) error {
+	e.executed = append(e.executed, d)
+	if d.Symbol == "FAILUSDT" {
+		return errors.New("exchange error")
+	}
+	return nil
+}
+
+type staticAccount struct {
+	positions []decision.PositionInfo
+}
+
+func (a staticAccount) StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error) {
+	return decision.AccountInfo{TotalEquity: 1000, PositionCount: len(a.positions)}, a.positions, nil
+}
+
+// breakoutStrategy 收盘价高于前一根最高价时开多，已有多仓且跌破前低时平仓
+type breakoutStrategy struct{}
+
+func (breakoutStrategy) Name() string { return "breakout" }
+
+func (breakoutStrategy) OnCandle(ctx context.Context, s MarketSnapshot) []Decision {
+	klines := s.Klines["15m"]
+	if len(klines) < 2 {
+		return nil
+	}
+	last, prev := klines[len(klines)-1], klines[len(klines)-2]
+	if _, ok := s.Position("long"); ok {
+		if last.Close < prev.Low {
+			return []Decision{{Action: "close_long"}}
+		}
+		return nil
+	}
+	if last.Close > prev.High {
+		return []Decision{{Action: "open_long", Leverage: 5, PositionSizeUSD: 200, StopLoss: prev.Low}}
+	}
+	return nil
+}
+
+func withSnapshots(t *testing.T, series map[string][]market.Kline) {
+	orig := fetchSnapshot
+	t.Cleanup(func() { fetchSnapshot = orig })
+	fetchSnapshot = func(symbol string, intervals []string, limit int) (*market.MultiTimeframeKlines, error) {
+		klines, ok := series[symbol]
+		if !ok {
+			return nil, errors.New("no data")
+		}
+		return &market.MultiTimeframeKlines{Symbol: symbol, Series: map[string][]market.Kline{"15m": klines}}, nil
+	}
+}
+
+func TestRunnerRunOnce(t *testing.T) {
+	up := []market.Kline{{High: 101, Low: 99, Close: 100}, {High: 103, Low: 100, Close: 102.5}}
+	down := []market.Kline{{High: 101, Low: 99, Close: 100}, {High: 100, Low: 97, Close: 98}}
+	withSnapshots(t, map[string][]market.Kline{"BTCUSDT": up, "ETHUSDT": down, "SOLUSDT": up})
+
+	exec := &recordingExecutor{}
+	runner, err := NewRunner(RunnerConfig{
+		Symbols:    []string{"BTCUSDT", "ETHUSDT", "SOLUSDT", "MISSINGUSDT"},
+		Strategies: []Strategy{breakoutStrategy{}},
+		Risk:       RiskLimits{MaxLeverage: 3},
+		Executor:   exec,
+		Account:    staticAccount{positions: []decision.PositionInfo{{Symbol: "ETHUSDT", Side: "long"}}},
+	})
+	if err != nil {
+		t.Fatalf("NewRunner 失败: %v", err)
+	}
+
+	results, err := runner.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce 失败: %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("期望 3 条结果，得到 %d: %+v", len(results), results)
+	}
+	// 平仓先执行；杠杆 5x 超过风控上限，开仓全部被拒绝
+	if results[0].Decision.Action != "close_long" || results[0].Decision.Symbol != "ETHUSDT" || results[0].Err != nil {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+	for _, r := range results[1:] {
+		if !r.Rejected || r.Strategy != "breakout" {
+			t.Errorf("期望开仓被风控拒绝: %+v", r)
+		}
+	}
+	if len(exec.executed) != 1 {
+		t.Errorf("期望只执行 1 条决策，实际 %d", len(exec.executed))
+	}
+}
+
+func TestRunnerExecutionOrderAndErrors(t *testing.T) {
+	withSnapshots(t, map[string][]market.Kline{"FAILUSDT": {{Close: 1}}})
+
+	exec := &recordingExecutor{}
+	strategy := Func(func(ctx context.Context, s MarketSnapshot) []Decision {
+		return []Decision{
+			{Action: "open_short", PositionSizeUSD: 50},
+			{Action: "hold"},
+			{Action: "partial_close", ClosePercentage: 50},
+		}
+	})
+	runner, err := NewRunner(RunnerConfig{Symbols: []string{"FAILUSDT"}, Strategies: []Strategy{strategy}, Executor: exec})
+	if err != nil {
+		t.Fatalf("NewRunner 失败: %v", err)
+	}
+
+	results, err := runner.RunOnce(context.Background())
+	if err != nil {
+		t.Fatalf("RunOnce 失败: %v", err)
+	}
+	if len(exec.executed) != 2 || exec.executed[0].Action != "partial_close" || exec.executed[1].Action != "open_short" {
+		t.Errorf("unexpected execution order: %+v", exec.executed)
+	}
+	if len(results) != 3 || results[0].Err == nil || results[2].Decision.Action != "hold" {
+		t.Errorf("unexpected results: %+v", results)
+	}
+}
+
+func TestRiskLimits(t *testing.T) {
+	limits := RiskLimits{MaxPositionSizeUSD: 100, MaxLeverage: 10, MaxOpenPositions: 2, RequireStopLoss: true}
+	full := MarketSnapshot{Account: decision.AccountInfo{PositionCount: 2}}
+
+	cases := []struct {
+		name     string
+		d        Decision
+		snapshot MarketSnapshot
+		wantErr  bool
+	}{
+		{"ok", Decision{Action: "open_long", PositionSizeUSD: 50, Leverage: 5, StopLoss: 90}, MarketSnapshot{}, false},
+		{"size", Decision{Action: "open_long", PositionSizeUSD: 150, Leverage: 5, StopLoss: 90}, MarketSnapshot{}, true},
+		{"leverage", Decision{Action: "open_short", PositionSizeUSD: 50, Leverage: 20, StopLoss: 110}, MarketSnapshot{}, true},
+		{"stop loss", Decision{Action: "open_long", PositionSizeUSD: 50, Leverage: 5}, MarketSnapshot{}, true},
+		{"max positions", Decision{Action: "open_long", PositionSizeUSD: 50, Leverage: 5, StopLoss: 90}, full, true},
+		{"close ignored", Decision{Action: "close_long"}, full, false},
+	}
+	for _, tc := range cases {
+		if err := limits.Check(&tc.d, tc.snapshot); (err != nil) != tc.wantErr {
+			t.Errorf("%s: err=%v, wantErr=%v", tc.name, err, tc.wantErr)
+		}
+	}
+}
+
+func TestRegimeRisk(t *testing.T) {
+	// 来回震荡，最后 20 根振幅放大 5 倍：高波动
+	klines := make([]market.Kline, 150)
+	for i := range klines {
+		move := 1.0
+		if i >= 130 {
+			move = 5
+		}
+		c := 100 + move*[]float64{0, 1, 0, -1}[i%4]
+		klines[i] = market.Kline{OpenTime: int64(i) * 3600000, High: c + 0.5, Low: c - 0.5, Close: c, CloseTime: int64(i+1)*3600000 - 1}
+	}
+	snapshot := MarketSnapshot{Symbol: "BTCUSDT", Klines: map[string][]market.Kline{"1h": klines}}
+	price := klines[len(klines)-1].Close
+
+	bus := eventbus.New()
+	events, cancel := bus.Subscribe(10, eventbus.TopicRegimeChanged)
+	defer cancel()
+	risk := RegimeRisk{Tracker: regime.NewTracker(regime.Config{}, bus)}
+
+	d := Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 100, StopLoss: price - 2}
+	if err := risk.Check(&d, snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if d.PositionSizeUSD != 50 || d.StopLoss != price-3 {
+		t.Errorf("high vol adjustment: size=%.2f stop=%.2f (price %.2f)", d.PositionSizeUSD, d.StopLoss, price)
+	}
+	if len(events) != 1 {
+		t.Errorf("expected one regime event, got %d", len(events))
+	}
+
+	short := Decision{Action: "open_short", PositionSizeUSD: 100, StopLoss: price + 2}
+	if err := (RegimeRisk{}).Check(&short, snapshot); err != nil || short.StopLoss != price+3 {
+		t.Errorf("short stop = %.2f, err=%v", short.StopLoss, err)
+	}
+
+	// K线不足或非开仓决策不调整
+	few := Decision{Action: "open_long", PositionSizeUSD: 100}
+	if err := (RegimeRisk{Interval: "4h"}).Check(&few, snapshot); err != nil || few.PositionSizeUSD != 100 {
+		t.Errorf("missing interval: size=%.2f err=%v", few.PositionSizeUSD, err)
+	}
+	closeD := Decision{Action: "close_long", PositionSizeUSD: 100}
+	if err := risk.Check(&closeD, snapshot); err != nil || closeD.PositionSizeUSD != 100 {
+		t.Errorf("close decision adjusted: %+v", closeD)
+	}
+}
+
+func TestRunnerRunOnCandleClose(t *testing.T) {
+	withSnapshots(t, map[string][]market.Kline{"BTCUSDT": {{Close: 1}}})
+
+	executed := make(chan Decision, 4)
+	strategy := Func(func(ctx context.Context, s MarketSnapshot) []Decision {
+		return []Decision{{Action: "close_long"}}
+	})
+	runner, err := NewRunner(RunnerConfig{
+		Symbols:    []string{"BTCUSDT"},
+		Strategies: []Strategy{strategy},
+		Executor:   channelExecutor(executed),
+	})
+	if err != nil {
+		t.Fatalf("NewRunner 失败: %v", err)
+	}
+
+	bus := eventbus.New()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- runner.RunOnCandleClose(ctx, bus, "15m") }()
+
+	// 等待订阅生效后再发布（Publish 不会为未订阅者缓存事件）
+	deadline := time.Now().Add(time.Second)
+	for {
+		bus.Publish(eventbus.CandleClosed{Symbol: "BTCUSDT", Interval: "15m", OpenTime: 1000})
+		select {
+		case <-executed:
+		case <-time.After(20 * time.Millisecond):
+			if time.Now().After(deadline) {
+				t.Fatal("收盘事件未触发策略执行")
+			}
+			continue
+		}
+		break
+	}
+
+	// 同一收盘时间（其他币种）和其他周期不重复执行
+	bus.Publish(eventbus.CandleClosed{Symbol: "ETHUSDT", Interval: "15m", OpenTime: 1000})
+	bus.Publish(eventbus.CandleClosed{Symbol: "BTCUSDT", Interval: "1h", OpenTime: 2000})
+	bus.Publish(eventbus.CandleClosed{Symbol: "BTCUSDT", Interval: "15m", OpenTime: 2000})
+	select {
+	case <-executed:
+	case <-time.After(time.Second):
+		t.Fatal("下一根K线收盘未触发策略执行")
+	}
+	select {
+	case d := <-executed:
+		t.Errorf("不应重复执行: %+v", d)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("RunOnCandleClose() = %v, want context.Canceled", err)
+	}
+}
+
+type channelExecutor chan Decision
+
+func (e channelExecutor) ExecuteDecision(d Decision) error {
+	e <- d
+	return nil
+}
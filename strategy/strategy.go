@@ -0,0 +1,77 @@
+// Package strategy 可插拔的规则策略引擎：数据源 → 策略 → 风控 → 交易执行
+// 用户实现 Strategy 接口即可编写自己的规则策略，替代单一的 AI 决策流程
+package strategy
+
+import (
+	"context"
+	"time"
+
+	"nofx/decision"
+	"nofx/market"
+)
+
+// Decision 策略输出的交易决策（与 AI 决策格式一致，可直接交给交易器执行）
+type Decision = decision.Decision
+
+// Strategy 交易策略
+// 每个周期对每个币种调用一次 OnCandle，返回需要执行的决策（无操作时返回 nil）
+type Strategy interface {
+	OnCandle(ctx context.Context, snapshot MarketSnapshot) []Decision
+}
+
+// Func 将普通函数适配为 Strategy
+type Func func(ctx context.Context, snapshot MarketSnapshot) []Decision
+
+// OnCandle 实现 Strategy
+func (f Func) OnCandle(ctx context.Context, snapshot MarketSnapshot) []Decision {
+	return f(ctx, snapshot)
+}
+
+// Named 可选接口：策略名称（用于日志，未实现时使用类型名）
+type Named interface {
+	Name() string
+}
+
+// MarketSnapshot 单个币种在某一时刻的行情与账户快照
+type MarketSnapshot struct {
+	Symbol    string
+	Time      time.Time
+	Klines    map[string][]market.Kline // 周期 -> K线（按时间正序，最后一根可能未收盘）
+	Account   decision.AccountInfo
+	Positions []decision.PositionInfo // 该币种的持仓
+}
+
+// Latest 指定周期最新的一根K线
+func (s MarketSnapshot) Latest(interval string) (market.Kline, bool) {
+	klines := s.Klines[interval]
+	if len(klines) == 0 {
+		return market.Kline{}, false
+	}
+	return klines[len(klines)-1], true
+}
+
+// Price 最新价格（取最短周期最新K线的收盘价，无数据时为 0）
+func (s MarketSnapshot) Price() float64 {
+	var price float64
+	var shortest time.Duration
+	for interval, klines := range s.Klines {
+		d, ok := market.TimeframeDuration(interval)
+		if !ok || len(klines) == 0 {
+			continue
+		}
+		if shortest == 0 || d < shortest {
+			shortest, price = d, klines[len(klines)-1].Close
+		}
+	}
+	return price
+}
+
+// Position 该币种指定方向（"long" / "short"）的持仓
+func (s MarketSnapshot) Position(side string) (decision.PositionInfo, bool) {
+	for _, pos := range s.Positions {
+		if pos.Side == side {
+			return pos, true
+		}
+	}
+	return decision.PositionInfo{}, false
+}
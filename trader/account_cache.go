@@ -0,0 +1,143 @@
+package trader
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// accountCacheTTL 并发执行时余额/持仓查询的缓存时间
+const accountCacheTTL = 2 * time.Second
+
+// accountCacheTrader 短时间缓存余额和持仓查询的 Trader 装饰器
+// 并发执行多个币种的决策时，各协程共享同一次查询结果；下单、调整杠杆和仓位模式后立即失效
+type accountCacheTrader struct {
+	Trader
+	ttl time.Duration
+
+	fetchMu sync.Mutex // 同一时间只有一个协程查询交易所，其他协程等待并复用结果
+
+	mu          sync.Mutex
+	generation  uint64 // 每次失效加一，查询期间发生失效时不写入缓存
+	balance     map[string]interface{}
+	balanceAt   time.Time
+	positions   []map[string]interface{}
+	positionsAt time.Time
+}
+
+// newAccountCacheTrader 为 trader 包装余额/持仓缓存
+func newAccountCacheTrader(t Trader, ttl time.Duration) *accountCacheTrader {
+	return &accountCacheTrader{Trader: t, ttl: ttl}
+}
+
+// invalidate 丢弃缓存的余额和持仓
+func (t *accountCacheTrader) invalidate() {
+	t.mu.Lock()
+	t.generation++
+	t.balance, t.positions = nil, nil
+	t.mu.Unlock()
+}
+
+func (t *accountCacheTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	t.fetchMu.Lock()
+	defer t.fetchMu.Unlock()
+
+	t.mu.Lock()
+	if t.balance != nil && time.Since(t.balanceAt) < t.ttl {
+		balance := copyAccountMap(t.balance)
+		t.mu.Unlock()
+		return balance, nil
+	}
+	gen := t.generation
+	t.mu.Unlock()
+
+	balance, err := t.Trader.GetBalance(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	if gen == t.generation {
+		t.balance, t.balanceAt = copyAccountMap(balance), time.Now()
+	}
+	t.mu.Unlock()
+	return balance, nil
+}
+
+func (t *accountCacheTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	t.fetchMu.Lock()
+	defer t.fetchMu.Unlock()
+
+	t.mu.Lock()
+	if t.positions != nil && time.Since(t.positionsAt) < t.ttl {
+		positions := copyPositions(t.positions)
+		t.mu.Unlock()
+		return positions, nil
+	}
+	gen := t.generation
+	t.mu.Unlock()
+
+	positions, err := t.Trader.GetPositions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	if gen == t.generation {
+		cached := copyPositions(positions)
+		if cached == nil {
+			cached = []map[string]interface{}{}
+		}
+		t.positions, t.positionsAt = cached, time.Now()
+	}
+	t.mu.Unlock()
+	return positions, nil
+}
+
+func (t *accountCacheTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	defer t.invalidate()
+	return t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+}
+
+func (t *accountCacheTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	defer t.invalidate()
+	return t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+}
+
+func (t *accountCacheTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	defer t.invalidate()
+	return t.Trader.CloseLong(ctx, symbol, quantity)
+}
+
+func (t *accountCacheTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	defer t.invalidate()
+	return t.Trader.CloseShort(ctx, symbol, quantity)
+}
+
+func (t *accountCacheTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	defer t.invalidate()
+	return t.Trader.SetLeverage(ctx, symbol, leverage)
+}
+
+func (t *accountCacheTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
+	defer t.invalidate()
+	return t.Trader.SetMarginMode(ctx, symbol, isCrossMargin)
+}
+
+// copyAccountMap 浅拷贝（调用方可能修改返回的 map）
+func copyAccountMap(m map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
+
+func copyPositions(positions []map[string]interface{}) []map[string]interface{} {
+	if positions == nil {
+		return nil
+	}
+	out := make([]map[string]interface{}, len(positions))
+	for i, p := range positions {
+		out[i] = copyAccountMap(p)
+	}
+	return out
+}
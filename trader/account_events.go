@@ -0,0 +1,149 @@
+package trader
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AccountEventType 账户事件类型
+type AccountEventType string
+
+const (
+	AccountEventOrder       AccountEventType = "order"       // 订单状态变化（新建、撤销、部分成交）
+	AccountEventFill        AccountEventType = "fill"        // 成交（含部分成交）
+	AccountEventTrigger     AccountEventType = "trigger"     // 止盈止损单被触发
+	AccountEventPosition    AccountEventType = "position"    // 持仓变化
+	AccountEventBalance     AccountEventType = "balance"     // 账户余额变化
+	AccountEventLiquidation AccountEventType = "liquidation" // 强平 / 自动减仓
+)
+
+// AccountEvent 交易所私有推送产生的账户事件
+type AccountEvent struct {
+	Exchange string
+	Type     AccountEventType
+	Symbol   string // 统一格式，如 BTCUSDT（余额事件为空）
+	Time     time.Time
+
+	Order    *OrderUpdate    // order / fill / trigger / liquidation
+	Position *PositionUpdate // position
+	Balance  *BalanceUpdate  // balance
+}
+
+// OrderUpdate 订单推送
+type OrderUpdate struct {
+	OrderID       string
+	ClientOrderID string
+	Side          string // buy / sell
+	PositionSide  string // long / short / net
+	OrderType     string
+	State         string // 交易所原始状态，如 live / partially_filled / filled / canceled
+	Category      string // OKX: normal / full_liquidation / partial_liquidation / adl 等
+	Price         float64
+	Quantity      float64
+	FilledQty     float64 // 累计成交数量
+	AvgPrice      float64 // 累计成交均价
+	FillPrice     float64 // 本次成交价格
+	FillQty       float64 // 本次成交数量
+	Fee           float64
+	RealizedPnL   float64
+	ReduceOnly    bool
+	TriggerPrice  float64 // 止盈止损触发价（trigger 事件）
+}
+
+// PositionUpdate 持仓推送（Quantity 为 0 表示已平仓）
+type PositionUpdate struct {
+	Side             string // long / short（单向持仓模式平仓时为空：方向未知，该币种的持仓全部平掉）
+	Quantity         float64
+	EntryPrice       float64
+	MarkPrice        float64
+	UnrealizedPnL    float64
+	Leverage         float64
+	LiquidationPrice float64
+	MarginMode       string // cross / isolated
+}
+
+// BalanceUpdate 余额推送
+type BalanceUpdate struct {
+	TotalEquity      float64
+	Currency         string
+	Equity           float64
+	Available        float64
+	UnrealizedProfit float64
+}
+
+// AccountEventBus 账户事件总线：私有推送发布，风控、日志、持仓缓存等订阅
+// 订阅者处理不过来时丢弃该订阅者的事件（不阻塞推送读取）
+type AccountEventBus struct {
+	mu      sync.RWMutex
+	subs    map[int]chan AccountEvent
+	nextID  int
+	dropped atomic.Int64
+}
+
+// NewAccountEventBus 创建事件总线
+func NewAccountEventBus() *AccountEventBus {
+	return &AccountEventBus{subs: make(map[int]chan AccountEvent)}
+}
+
+// Subscribe 订阅事件（buffer 为通道缓冲大小），返回取消订阅函数
+func (b *AccountEventBus) Subscribe(buffer int) (<-chan AccountEvent, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	id := b.nextID
+	b.nextID++
+	ch := make(chan AccountEvent, buffer)
+	b.subs[id] = ch
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			delete(b.subs, id)
+			close(ch)
+		})
+	}
+}
+
+// Publish 发布事件（不阻塞）
+func (b *AccountEventBus) Publish(ev AccountEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, ch := range b.subs {
+		select {
+		case ch <- ev:
+		default:
+			if n := b.dropped.Add(1); n%100 == 1 {
+				log.Warn("⚠️ 账户事件订阅者处理过慢，已丢弃事件", "dropped", n)
+			}
+		}
+	}
+}
+
+// AccountStreamer 支持私有推送的交易器：推送事件发布到 bus，ctx 取消时停止
+type AccountStreamer interface {
+	StartAccountStream(ctx context.Context, bus *AccountEventBus)
+}
+
+// PositionEventSubscriber 持仓缓存可按推送增量更新的交易器，返回取消订阅函数
+type PositionEventSubscriber interface {
+	SubscribePositionEvents(bus *AccountEventBus) func()
+}
+
+// startAccountStream 启动交易器的私有推送，并让持仓缓存订阅推送的持仓事件（Run 时调用，Stop 时随运行期 ctx 一起停止）
+func (at *AutoTrader) startAccountStream() {
+	if at.accountStream == nil {
+		return
+	}
+	ctx := at.runCtx
+	if at.positionEvents != nil {
+		cancel := at.positionEvents.SubscribePositionEvents(at.accountEvents)
+		go func() {
+			<-ctx.Done()
+			cancel()
+		}()
+	}
+	at.accountStream.StartAccountStream(ctx, at.accountEvents)
+}
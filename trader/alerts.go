@@ -0,0 +1,86 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/logger"
+	"nofx/store"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 告警级别
+const (
+	AlertSeverityInfo     = "INFO"
+	AlertSeverityWarning  = "WARNING"
+	AlertSeverityCritical = "CRITICAL"
+)
+
+// Alert 交易员告警（需要人工关注的事件）
+type Alert struct {
+	TraderID   string    `json:"trader_id"`
+	TraderName string    `json:"trader_name"`
+	Severity   string    `json:"severity"`
+	Title      string    `json:"title"`
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+// AlertHandler 告警回调（在触发告警的 goroutine 中同步调用，耗时操作需自行异步处理）
+type AlertHandler func(alert Alert)
+
+// NumberFormatFromEnv 读取告警/通知中的数字格式：NOFX_NUMBER_LOCALE（如 en / zh / de / fr，决定千位分隔符和小数点）、
+// NOFX_NUMBER_DECIMALS（金额小数位，默认 2）、NOFX_NUMBER_COMPACT（true=大额金额使用缩写）
+func NumberFormatFromEnv() (logger.NumberFormat, error) {
+	nf := logger.NumberFormatForLocale(strings.TrimSpace(os.Getenv("NOFX_NUMBER_LOCALE")))
+	if v := strings.TrimSpace(os.Getenv("NOFX_NUMBER_DECIMALS")); v != "" {
+		decimals, err := strconv.Atoi(v)
+		if err != nil || decimals < 0 || decimals > 8 {
+			return logger.NumberFormat{}, fmt.Errorf("NOFX_NUMBER_DECIMALS=%q 应为 0-8 之间的整数", v)
+		}
+		nf.Decimals = decimals
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_NUMBER_COMPACT")); v != "" {
+		compact, err := strconv.ParseBool(v)
+		if err != nil {
+			return logger.NumberFormat{}, fmt.Errorf("NOFX_NUMBER_COMPACT=%q 应为 true 或 false", v)
+		}
+		nf.Compact = compact
+	}
+	return nf, nil
+}
+
+// notify 记录告警日志并调用配置的告警回调
+func (at *AutoTrader) notify(severity, title, format string, args ...interface{}) {
+	alert := Alert{
+		TraderID:   at.id,
+		TraderName: at.name,
+		Severity:   severity,
+		Title:      title,
+		Message:    fmt.Sprintf(format, args...),
+		Timestamp:  time.Now(),
+	}
+
+	attrs := []any{"trader", at.name, "severity", severity, "message", alert.Message}
+	switch severity {
+	case AlertSeverityWarning:
+		log.Warn("⚠️ "+title, attrs...)
+	case AlertSeverityCritical:
+		log.Error("🚨 "+title, attrs...)
+	default:
+		log.Info("ℹ️ "+title, attrs...)
+	}
+
+	// 警告及以上级别写入事件日志，用于每日/每周汇总中的风控事件
+	if at.journal != nil && severity != AlertSeverityInfo {
+		risk := store.RiskEvent{Severity: severity, Title: title, Message: alert.Message}
+		if _, err := at.journal.Record(at.id, store.EventRisk, "", "", risk); err != nil {
+			log.Warn("⚠️ 写入风控事件失败", "title", title, "error", err)
+		}
+	}
+
+	if at.config.AlertHandler != nil {
+		at.config.AlertHandler(alert)
+	}
+}
@@ -0,0 +1,36 @@
+package trader
+
+import (
+	"nofx/logger"
+	"testing"
+)
+
+func TestNumberFormatFromEnv(t *testing.T) {
+	if nf, err := NumberFormatFromEnv(); err != nil || nf != (logger.NumberFormat{}) {
+		t.Fatalf("未配置时应使用默认格式: %+v %v", nf, err)
+	}
+
+	t.Setenv("NOFX_NUMBER_LOCALE", "de-DE")
+	t.Setenv("NOFX_NUMBER_DECIMALS", "3")
+	t.Setenv("NOFX_NUMBER_COMPACT", "true")
+	nf, err := NumberFormatFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if nf.ThousandsSeparator != "." || nf.DecimalSeparator != "," || nf.Decimals != 3 || !nf.Compact {
+		t.Errorf("配置解析错误: %+v", nf)
+	}
+	if got := nf.Amount(1234.5); got != "1.234,500" {
+		t.Errorf("Amount(1234.5) = %q, want 1.234,500", got)
+	}
+
+	t.Setenv("NOFX_NUMBER_DECIMALS", "-1")
+	if _, err := NumberFormatFromEnv(); err == nil {
+		t.Error("无效的小数位应返回错误")
+	}
+	t.Setenv("NOFX_NUMBER_DECIMALS", "")
+	t.Setenv("NOFX_NUMBER_COMPACT", "sometimes")
+	if _, err := NumberFormatFromEnv(); err == nil {
+		t.Error("无效的 NOFX_NUMBER_COMPACT 应返回错误")
+	}
+}
@@ -3,18 +3,21 @@ package trader
 import (
 	"context"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"math"
 	"math/big"
 	"net/http"
 	"net/url"
 	"nofx/decision"
+	"nofx/exchangehttp"
 	"nofx/hook"
+	"nofx/market"
+	"nofx/netconfig"
+	"nofx/ratelimit"
 	"sort"
 	"strconv"
 	"strings"
@@ -28,12 +31,12 @@ import (
 
 // AsterTrader Aster交易平台实现
 type AsterTrader struct {
-	ctx        context.Context
 	user       string            // 主钱包地址 (ERC20)
 	signer     string            // API钱包地址
 	privateKey *ecdsa.PrivateKey // API钱包私钥
 	client     *http.Client
 	baseURL    string
+	retry      RetryPolicy // 请求重试策略（零值使用 DefaultRetryPolicy）
 
 	// 缓存交易对精度信息
 	symbolPrecision map[string]SymbolPrecision
@@ -42,10 +45,10 @@ type AsterTrader struct {
 
 // SymbolPrecision 交易对精度信息
 type SymbolPrecision struct {
-	PricePrecision    int
-	QuantityPrecision int
-	TickSize          float64 // 价格步进值
-	StepSize          float64 // 数量步进值
+	PricePrecision    int     `json:"price_precision"`
+	QuantityPrecision int     `json:"quantity_precision"`
+	TickSize          float64 `json:"tick_size"` // 价格步进值
+	StepSize          float64 `json:"step_size"` // 数量步进值
 }
 
 // NewAsterTrader 创建Aster交易器
@@ -58,27 +61,19 @@ func NewAsterTrader(user, signer, privateKeyHex string) (*AsterTrader, error) {
 	if err != nil {
 		return nil, fmt.Errorf("解析私钥失败: %w", err)
 	}
-	client := &http.Client{
-		Timeout: 30 * time.Second, // 增加到30秒
-		Transport: &http.Transport{
-			TLSHandshakeTimeout:   10 * time.Second,
-			ResponseHeaderTimeout: 10 * time.Second,
-			IdleConnTimeout:       90 * time.Second,
-		},
-	}
+	client := exchangehttp.NewHTTPClient("aster", ratelimit.BinanceGroup("aster"), 30*time.Second)
 	res := hook.HookExec[hook.NewAsterTraderResult](hook.NEW_ASTER_TRADER, user, client)
 	if res != nil && res.Error() == nil {
 		client = res.GetResult()
 	}
 
 	return &AsterTrader{
-		ctx:             context.Background(),
 		user:            user,
 		signer:          signer,
 		privateKey:      privKey,
 		symbolPrecision: make(map[string]SymbolPrecision),
 		client:          client,
-		baseURL:         "https://fapi.asterdex.com",
+		baseURL:         netconfig.BaseURL("aster", "https://fapi.asterdex.com"),
 	}, nil
 }
 
@@ -96,14 +91,28 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	}
 	t.mu.RUnlock()
 
-	// 获取交易所信息
-	resp, err := t.client.Get(t.baseURL + "/fapi/v3/exchangeInfo")
-	if err != nil {
+	ctx, cancel := backgroundCallContext()
+	defer cancel()
+	if err := t.refreshPrecisions(ctx); err != nil {
 		return SymbolPrecision{}, err
 	}
-	defer resp.Body.Close()
 
-	body, _ := io.ReadAll(resp.Body)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	if prec, ok := t.symbolPrecision[symbol]; ok {
+		return prec, nil
+	}
+
+	return SymbolPrecision{}, fmt.Errorf("未找到交易对 %s 的精度信息", symbol)
+}
+
+// refreshPrecisions 从 exchangeInfo 拉取并替换所有交易对的精度缓存（交易规则可能调整，已下架的交易对一并移除）
+func (t *AsterTrader) refreshPrecisions(ctx context.Context) error {
+	// 获取交易所信息
+	body, err := t.publicRequest(ctx, "/fapi/v3/exchangeInfo", nil)
+	if err != nil {
+		return err
+	}
 	var info struct {
 		Symbols []struct {
 			Symbol            string                   `json:"symbol"`
@@ -114,11 +123,13 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 	}
 
 	if err := json.Unmarshal(body, &info); err != nil {
-		return SymbolPrecision{}, err
+		return err
+	}
+	if len(info.Symbols) == 0 {
+		return fmt.Errorf("交易规则为空")
 	}
 
-	// 缓存所有交易对的精度
-	t.mu.Lock()
+	precisions := make(map[string]SymbolPrecision, len(info.Symbols))
 	for _, s := range info.Symbols {
 		prec := SymbolPrecision{
 			PricePrecision:    s.PricePrecision,
@@ -140,15 +151,64 @@ func (t *AsterTrader) getPrecision(symbol string) (SymbolPrecision, error) {
 			}
 		}
 
-		t.symbolPrecision[s.Symbol] = prec
+		precisions[s.Symbol] = prec
 	}
+
+	t.mu.Lock()
+	t.symbolPrecision = precisions
 	t.mu.Unlock()
+	return nil
+}
 
-	if prec, ok := t.symbolPrecision[symbol]; ok {
-		return prec, nil
+// PreloadInstruments 一次请求拉取全部合约的交易规则并替换精度缓存（实现 InstrumentPreloader）
+func (t *AsterTrader) PreloadInstruments(ctx context.Context, symbols []string) error {
+	if err := t.refreshPrecisions(ctx); err != nil {
+		return fmt.Errorf("获取交易规则失败: %w", err)
 	}
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return missingInstruments(t.symbolPrecision, symbols)
+}
 
-	return SymbolPrecision{}, fmt.Errorf("未找到交易对 %s 的精度信息", symbol)
+// OrderLimits 获取最小下单要求（实现 OrderLimitsProvider，Aster 只校验数量步进值）
+func (t *AsterTrader) OrderLimits(ctx context.Context, symbol string) (OrderLimits, error) {
+	prec, err := t.getPrecision(symbol)
+	if err != nil {
+		return OrderLimits{}, err
+	}
+	return OrderLimits{MinQty: prec.StepSize, StepSize: prec.StepSize}, nil
+}
+
+// ExportPrecisionSnapshot 导出所有交易对精度（实现 PrecisionSnapshotter）
+func (t *AsterTrader) ExportPrecisionSnapshot() (*PrecisionSnapshot, error) {
+	t.mu.RLock()
+	empty := len(t.symbolPrecision) == 0
+	t.mu.RUnlock()
+
+	if empty {
+		ctx, cancel := backgroundCallContext()
+		defer cancel()
+		if err := t.refreshPrecisions(ctx); err != nil {
+			return nil, fmt.Errorf("获取交易规则失败: %w", err)
+		}
+	}
+
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	symbols := make(map[string]SymbolPrecision, len(t.symbolPrecision))
+	for symbol, prec := range t.symbolPrecision {
+		symbols[symbol] = prec
+	}
+	return &PrecisionSnapshot{Exchange: VenueAster, CreatedAt: time.Now(), Symbols: symbols}, nil
+}
+
+// LoadPrecisionSnapshot 载入精度快照（实现 PrecisionSnapshotter）
+func (t *AsterTrader) LoadPrecisionSnapshot(snapshot *PrecisionSnapshot) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for symbol, prec := range snapshot.Symbols {
+		t.symbolPrecision[symbol] = prec
+	}
 }
 
 // roundToTickSize 将价格/数量四舍五入到tick size/step size的整数倍
@@ -328,114 +388,153 @@ func (t *AsterTrader) sign(params map[string]interface{}, nonce uint64) error {
 	return nil
 }
 
-// request 发送HTTP请求（带重试机制）
-func (t *AsterTrader) request(method, endpoint string, params map[string]interface{}) ([]byte, error) {
-	const maxRetries = 3
-	var lastErr error
-
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// 每次重试都生成新的nonce和签名
-		nonce := t.genNonce()
-		paramsCopy := make(map[string]interface{})
-		for k, v := range params {
-			paramsCopy[k] = v
+// request 发送HTTP请求（带重试机制，每次重试都生成新的nonce和签名）
+func (t *AsterTrader) request(ctx context.Context, method, endpoint string, params map[string]interface{}) ([]byte, error) {
+	method = strings.ToUpper(method)
+	policy := t.retry.orDefault()
+	api := &exchangehttp.Client{
+		HTTP:    t.client,
+		BaseURL: t.baseURL,
+		Sign:    t.requestSigner(params),
+		Decode:  asterDecode,
+		Retry:   policy.httpRetry(),
+	}
+
+	req := &exchangehttp.Request{Method: method, Path: endpoint, Idempotent: isIdempotentRequest(method)}
+	var body []byte
+	err := api.Do(ctx, req, &body)
+
+	clientOrderID, _ := params["newClientOrderId"].(string)
+	if err == nil || method != http.MethodPost || clientOrderID == "" {
+		return body, err
+	}
+
+	// 下单请求遇到临时错误时执行状态未知：clientOrderId 只在挂单中唯一，不能直接重发，
+	// 先按 clientOrderId 查询，交易所确认订单不存在时才重发
+	for attempt := 1; attempt < policy.MaxAttempts && exchangehttp.Temporary(err) && ctx.Err() == nil; attempt++ {
+		order, queryErr := t.queryOrderByClientID(ctx, params["symbol"], clientOrderID)
+		if queryErr == nil {
+			log.Info("  ℹ️ 下单请求失败但订单已被受理，按 clientOrderId 查回订单", "new_client_order_id", clientOrderID, "error", err)
+			return order, nil
 		}
-
-		// 签名
-		if err := t.sign(paramsCopy, nonce); err != nil {
+		if !errors.Is(queryErr, ErrOrderNotFound) {
+			log.Warn("⚠️ 下单请求失败且无法确认订单状态，不重发", "new_client_order_id", clientOrderID, "error", err, "query_error", queryErr)
 			return nil, err
 		}
 
-		body, err := t.doRequest(method, endpoint, paramsCopy)
-		if err == nil {
+		wait := policy.Backoff(attempt)
+		log.Warn("⚠️ 下单请求失败且订单不存在，稍后重发", "new_client_order_id", clientOrderID, "attempt", attempt, "error", err, "wait", wait.String())
+		if sleepContext(ctx, wait) != nil {
+			return nil, err
+		}
+		body = nil
+		if err = api.Do(ctx, req, &body); err == nil {
 			return body, nil
 		}
-
-		lastErr = err
-
-		// 如果是网络超时或临时错误，重试
-		if strings.Contains(err.Error(), "timeout") ||
-			strings.Contains(err.Error(), "connection reset") ||
-			strings.Contains(err.Error(), "EOF") {
-			if attempt < maxRetries {
-				waitTime := time.Duration(attempt) * time.Second
-				time.Sleep(waitTime)
-				continue
-			}
+		// 重发时提示 clientOrderId 重复：上一次请求在查询之后才被受理，按 clientOrderId 查回订单
+		if isDuplicateClientOrderID(err) {
+			log.Info("  ℹ️ 重发下单时订单已存在，按 clientOrderId 查询", "new_client_order_id", clientOrderID)
+			return t.queryOrderByClientID(ctx, params["symbol"], clientOrderID)
 		}
-
-		// 其他错误（如400/401等）不重试
-		return nil, err
+		err = &exchangehttp.RetryError{Attempts: attempt + 1, Err: err}
 	}
-
-	return nil, fmt.Errorf("请求失败（已重试%d次）: %w", maxRetries, lastErr)
+	return nil, err
 }
 
-// doRequest 执行实际的HTTP请求
-func (t *AsterTrader) doRequest(method, endpoint string, params map[string]interface{}) ([]byte, error) {
-	fullURL := t.baseURL + endpoint
-	method = strings.ToUpper(method)
+// queryOrderByClientID 按 clientOrderId 查询订单（订单不存在时返回 ErrOrderNotFound）
+func (t *AsterTrader) queryOrderByClientID(ctx context.Context, symbol interface{}, clientOrderID string) ([]byte, error) {
+	return t.request(ctx, http.MethodGet, "/fapi/v3/order", map[string]interface{}{
+		"symbol":            symbol,
+		"origClientOrderId": clientOrderID,
+	})
+}
 
-	switch method {
-	case "POST":
-		// POST请求：参数放在表单body中
-		form := url.Values{}
+// requestSigner 签名 params：POST 参数放在表单body中，GET/DELETE 参数放在querystring中
+func (t *AsterTrader) requestSigner(params map[string]interface{}) exchangehttp.Signer {
+	return func(r *exchangehttp.Request) error {
+		paramsCopy := make(map[string]interface{}, len(params))
 		for k, v := range params {
-			form.Set(k, fmt.Sprintf("%v", v))
+			paramsCopy[k] = v
 		}
-		req, err := http.NewRequest("POST", fullURL, strings.NewReader(form.Encode()))
-		if err != nil {
-			return nil, err
+		if err := t.sign(paramsCopy, t.genNonce()); err != nil {
+			return err
 		}
-		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-		resp, err := t.client.Do(req)
-		if err != nil {
-			return nil, err
+		values := url.Values{}
+		for k, v := range paramsCopy {
+			values.Set(k, fmt.Sprintf("%v", v))
 		}
-		defer resp.Body.Close()
-
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		switch r.Method {
+		case http.MethodPost:
+			r.Form = values
+		case http.MethodGet, http.MethodDelete:
+			r.Query = values
+		default:
+			return fmt.Errorf("不支持的HTTP方法: %s", r.Method)
 		}
-		return body, nil
+		return nil
+	}
+}
 
-	case "GET", "DELETE":
-		// GET/DELETE请求：参数放在querystring中
-		q := url.Values{}
-		for k, v := range params {
-			q.Set(k, fmt.Sprintf("%v", v))
-		}
-		u, _ := url.Parse(fullURL)
-		u.RawQuery = q.Encode()
+// publicRequest 发送无需签名的公开行情请求（与签名请求共用 HTTP 客户端、限流、重试策略和错误分类）
+func (t *AsterTrader) publicRequest(ctx context.Context, endpoint string, query url.Values) ([]byte, error) {
+	api := &exchangehttp.Client{
+		HTTP:    t.client,
+		BaseURL: t.baseURL,
+		Decode:  asterDecode,
+		Retry:   t.retry.orDefault().httpRetry(),
+	}
+	var body []byte
+	err := api.Do(ctx, &exchangehttp.Request{Method: http.MethodGet, Path: endpoint, Query: query, Idempotent: true}, &body)
+	return body, err
+}
 
-		req, err := http.NewRequest(method, u.String(), nil)
-		if err != nil {
-			return nil, err
-		}
+// asterDecode 非 200 响应转为分类错误，否则返回原始响应体
+func asterDecode(status int, body []byte, out interface{}) error {
+	if status != http.StatusOK {
+		return asterHTTPError(status, body)
+	}
+	return exchangehttp.Unmarshal(body, out)
+}
 
-		resp, err := t.client.Do(req)
-		if err != nil {
-			return nil, err
-		}
-		defer resp.Body.Close()
+// isDuplicateClientOrderID 是否为 clientOrderId 重复错误（-4116）
+func isDuplicateClientOrderID(err error) bool {
+	var exErr *ExchangeError
+	return errors.As(err, &exErr) && exErr.Code == "-4116"
+}
 
-		body, _ := io.ReadAll(resp.Body)
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-		}
-		return body, nil
+// newAsterClientOrderID 生成下单用的 clientOrderId（使超时后的重试可以安全重发）
+func newAsterClientOrderID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return fmt.Sprintf("nofx-%d-%s", time.Now().UnixMilli(), hex.EncodeToString(b))
+}
 
-	default:
-		return nil, fmt.Errorf("不支持的HTTP方法: %s", method)
+// SetRetryPolicy 设置请求重试策略（MaxAttempts 为 0 时使用 DefaultRetryPolicy）
+func (t *AsterTrader) SetRetryPolicy(p RetryPolicy) {
+	t.retry = p
+}
+
+// asterHTTPError 将非 200 响应转为分类错误（响应体为 {"code":-2019,"msg":"..."}，错误码与币安一致）
+func asterHTTPError(status int, body []byte) error {
+	err := fmt.Errorf("HTTP %d: %s", status, string(body))
+	var apiErr struct {
+		Code int64  `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if json.Unmarshal(body, &apiErr) == nil && apiErr.Code != 0 {
+		return NewExchangeError("aster", strconv.FormatInt(apiErr.Code, 10), apiErr.Msg, err)
 	}
+	if status == http.StatusTooManyRequests {
+		return &ExchangeError{Exchange: "aster", Message: string(body), Kind: ErrRateLimited, Err: err}
+	}
+	return ClassifyError("aster", err)
 }
 
 // GetBalance 获取账户余额
-func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
+func (t *AsterTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
 	params := make(map[string]interface{})
-	body, err := t.request("GET", "/fapi/v3/balance", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/balance", params)
 	if err != nil {
 		return nil, err
 	}
@@ -470,13 +569,13 @@ func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
 	}
 
 	if !foundUSDT {
-		log.Printf("⚠️  未找到USDT资产记录！")
+		log.Warn("⚠️ 未找到USDT资产记录！")
 	}
 
 	// 获取持仓计算保证金占用和真实未实现盈亏
-	positions, err := t.GetPositions()
+	positions, err := t.GetPositions(ctx)
 	if err != nil {
-		log.Printf("⚠️  获取持仓信息失败: %v", err)
+		log.Warn("⚠️ 获取持仓信息失败", "error", err)
 		// fallback: 无法获取持仓时使用简单计算
 		return map[string]interface{}{
 			"totalWalletBalance":    crossWalletBalance,
@@ -513,17 +612,51 @@ func (t *AsterTrader) GetBalance() (map[string]interface{}, error) {
 	totalEquity := availableBalance + totalMarginUsed
 	totalWalletBalance := totalEquity - realUnrealizedPnl
 
-	return map[string]interface{}{
+	result := map[string]interface{}{
 		"totalWalletBalance":    totalWalletBalance, // 钱包余额（不含未实现盈亏）
 		"availableBalance":      availableBalance,   // 可用余额
 		"totalUnrealizedProfit": realUnrealizedPnl,  // 未实现盈亏（从持仓累加）
-	}, nil
+	}
+
+	// 维持保证金和保证金余额（与币安相同口径，用于计算保证金率）
+	maintMargin, marginBalance, err := t.getMaintMargin(ctx)
+	if err != nil {
+		log.Warn("⚠️ 获取维持保证金失败", "error", err)
+		return result, nil
+	}
+	result["totalMaintMargin"] = maintMargin
+	result["totalMarginBalance"] = marginBalance
+	return result, nil
+}
+
+// getMaintMargin 从账户信息中获取维持保证金和保证金余额
+func (t *AsterTrader) getMaintMargin(ctx context.Context) (float64, float64, error) {
+	body, err := t.request(ctx, "GET", "/fapi/v3/account", make(map[string]interface{}))
+	if err != nil {
+		return 0, 0, err
+	}
+	var account struct {
+		TotalMaintMargin   string `json:"totalMaintMargin"`
+		TotalMarginBalance string `json:"totalMarginBalance"`
+	}
+	if err := json.Unmarshal(body, &account); err != nil {
+		return 0, 0, fmt.Errorf("解析账户信息失败: %w", err)
+	}
+	maintMargin, err := strconv.ParseFloat(account.TotalMaintMargin, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的 totalMaintMargin %q", account.TotalMaintMargin)
+	}
+	marginBalance, err := strconv.ParseFloat(account.TotalMarginBalance, 64)
+	if err != nil {
+		return 0, 0, fmt.Errorf("无效的 totalMarginBalance %q", account.TotalMarginBalance)
+	}
+	return maintMargin, marginBalance, nil
 }
 
 // GetPositions 获取持仓信息
-func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *AsterTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
 	params := make(map[string]interface{})
-	body, err := t.request("GET", "/fapi/v3/positionRisk", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/positionRisk", params)
 	if err != nil {
 		return nil, err
 	}
@@ -575,19 +708,16 @@ func (t *AsterTrader) GetPositions() ([]map[string]interface{}, error) {
 }
 
 // OpenLong 开多单
-func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *AsterTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败(继续开仓)", "error", err)
 	}
 
-	// 先设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, fmt.Errorf("设置杠杆失败: %w", err)
-	}
+	// 杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过）
 
 	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -615,46 +745,43 @@ func (t *AsterTrader) OpenLong(symbol string, quantity float64, leverage int) (m
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+	log.Info("  📏 精度处理", "price", limitPrice, "price_str", priceStr, "price_precision", prec.PricePrecision, "quantity", quantity, "quantity_str", qtyStr, "quantity_precision", prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "BUY",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "LIMIT",
+		"side":             "BUY",
+		"timeInForce":      "GTC",
+		"quantity":         qtyStr,
+		"price":            priceStr,
 	}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
+	body, err := t.request(ctx, "POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	report, err := t.newExecutionReport(ctx, body)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return report, nil
 }
 
 // OpenShort 开空单
-func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *AsterTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 开仓前先取消所有挂单,防止残留挂单导致仓位叠加
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败(继续开仓): %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败(继续开仓)", "error", err)
 	}
 
-	// 先设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, fmt.Errorf("设置杠杆失败: %w", err)
-	}
+	// 杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过）
 
 	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -682,37 +809,37 @@ func (t *AsterTrader) OpenShort(symbol string, quantity float64, leverage int) (
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+	log.Info("  📏 精度处理", "price", limitPrice, "price_str", priceStr, "price_precision", prec.PricePrecision, "quantity", quantity, "quantity_str", qtyStr, "quantity_precision", prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "SELL",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "LIMIT",
+		"side":             "SELL",
+		"timeInForce":      "GTC",
+		"quantity":         qtyStr,
+		"price":            priceStr,
 	}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
+	body, err := t.request(ctx, "POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	report, err := t.newExecutionReport(ctx, body)
+	if err != nil {
 		return nil, err
 	}
 
-	return result, nil
+	return report, nil
 }
 
 // CloseLong 平多单
-func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *AsterTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPositions(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -727,10 +854,10 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 		if quantity == 0 {
 			return nil, fmt.Errorf("没有找到 %s 的多仓", symbol)
 		}
-		log.Printf("  📊 获取到多仓数量: %.8f", quantity)
+		log.Info("  📊 获取到多仓数量", "quantity", quantity)
 	}
 
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -757,49 +884,45 @@ func (t *AsterTrader) CloseLong(symbol string, quantity float64) (map[string]int
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+	log.Info("  📏 精度处理", "price", limitPrice, "price_str", priceStr, "price_precision", prec.PricePrecision, "quantity", quantity, "quantity_str", qtyStr, "quantity_precision", prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "SELL",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "LIMIT",
+		"side":             "SELL",
+		"timeInForce":      "GTC",
+		"quantity":         qtyStr,
+		"price":            priceStr,
 	}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
+	body, err := t.request(ctx, "POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	report, err := t.newExecutionReport(ctx, body)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %s", symbol, qtyStr)
+	log.Info("✓ 平多仓成功", "symbol", symbol, "quantity", qtyStr)
 
 	// 平仓后取消该币种的所有挂单(止损止盈单) - 使用重試機制
-	if err := t.CancelAllOrdersWithRetry(symbol, 3); err != nil {
+	if err := t.CancelAllOrdersWithRetry(ctx, symbol, 3); err != nil {
 		// 重試失敗後記錄強警告（已在 WithRetry 中記錄詳細信息）
-		log.Printf("  ❌❌❌ 警告：平倉成功但掛單取消失敗 ❌❌❌")
-		log.Printf("  ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		log.Printf("  幣種: %s", symbol)
-		log.Printf("  建議：立即手動檢查 %s 的掛單！", symbol)
-		log.Printf("  ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		log.Error("❌ 平倉成功但掛單取消失敗，請立即手動檢查掛單", "symbol", symbol)
 	}
 
-	return result, nil
+	return report, nil
 }
 
 // CloseShort 平空单
-func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *AsterTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPositions(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -815,10 +938,10 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 		if quantity == 0 {
 			return nil, fmt.Errorf("没有找到 %s 的空仓", symbol)
 		}
-		log.Printf("  📊 获取到空仓数量: %.8f", quantity)
+		log.Info("  📊 获取到空仓数量", "quantity", quantity)
 	}
 
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
@@ -845,46 +968,126 @@ func (t *AsterTrader) CloseShort(symbol string, quantity float64) (map[string]in
 	priceStr := t.formatFloatWithPrecision(formattedPrice, prec.PricePrecision)
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
-	log.Printf("  📏 精度处理: 价格 %.8f -> %s (精度=%d), 数量 %.8f -> %s (精度=%d)",
-		limitPrice, priceStr, prec.PricePrecision, quantity, qtyStr, prec.QuantityPrecision)
+	log.Info("  📏 精度处理", "price", limitPrice, "price_str", priceStr, "price_precision", prec.PricePrecision, "quantity", quantity, "quantity_str", qtyStr, "quantity_precision", prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "LIMIT",
-		"side":         "BUY",
-		"timeInForce":  "GTC",
-		"quantity":     qtyStr,
-		"price":        priceStr,
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "LIMIT",
+		"side":             "BUY",
+		"timeInForce":      "GTC",
+		"quantity":         qtyStr,
+		"price":            priceStr,
 	}
 
-	body, err := t.request("POST", "/fapi/v3/order", params)
+	body, err := t.request(ctx, "POST", "/fapi/v3/order", params)
 	if err != nil {
 		return nil, err
 	}
 
-	var result map[string]interface{}
-	if err := json.Unmarshal(body, &result); err != nil {
+	report, err := t.newExecutionReport(ctx, body)
+	if err != nil {
 		return nil, err
 	}
 
-	log.Printf("✓ 平空仓成功: %s 数量: %s", symbol, qtyStr)
+	log.Info("✓ 平空仓成功", "symbol", symbol, "quantity", qtyStr)
 
 	// 平仓后取消该币种的所有挂单(止损止盈单) - 使用重試機制
-	if err := t.CancelAllOrdersWithRetry(symbol, 3); err != nil {
+	if err := t.CancelAllOrdersWithRetry(ctx, symbol, 3); err != nil {
 		// 重試失敗後記錄強警告（已在 WithRetry 中記錄詳細信息）
-		log.Printf("  ❌❌❌ 警告：平倉成功但掛單取消失敗 ❌❌❌")
-		log.Printf("  ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-		log.Printf("  幣種: %s", symbol)
-		log.Printf("  建議：立即手動檢查 %s 的掛單！", symbol)
-		log.Printf("  ━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		log.Error("❌ 平倉成功但掛單取消失敗，請立即手動檢查掛單", "symbol", symbol)
 	}
 
-	return result, nil
+	return report, nil
+}
+
+// asterOrderResponse Aster 下单响应（与币安格式一致）
+type asterOrderResponse struct {
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Status        string `json:"status"`
+	Side          string `json:"side"`
+	PositionSide  string `json:"positionSide"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	AvgPrice      string `json:"avgPrice"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// asterUserTrade Aster 成交明细
+type asterUserTrade struct {
+	OrderID         int64  `json:"orderId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+}
+
+// newExecutionReport 解析 Aster 下单响应为执行报告，并补充成交手续费
+func (t *AsterTrader) newExecutionReport(ctx context.Context, body []byte) (*ExecutionReport, error) {
+	var order asterOrderResponse
+	if err := json.Unmarshal(body, &order); err != nil {
+		return nil, fmt.Errorf("解析下单响应失败: %w", err)
+	}
+
+	report := &ExecutionReport{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          order.Side,
+		PositionSide:  order.PositionSide,
+		Status:        order.Status,
+		RequestedQty:  parseFloatOrZero(order.OrigQty),
+		FilledQty:     parseFloatOrZero(order.ExecutedQty),
+		AvgPrice:      parseFloatOrZero(order.AvgPrice),
+		SubmittedAt:   time.Now(),
+		UpdatedAt:     msToTime(order.UpdateTime),
+		Venue:         VenueAster,
+	}
+
+	if report.IsFilled() {
+		t.enrichExecutionReport(ctx, report)
+	}
+	return report, nil
+}
+
+// enrichExecutionReport 查询成交明细补充成交均价和手续费（查询失败仅记录日志）
+func (t *AsterTrader) enrichExecutionReport(ctx context.Context, report *ExecutionReport) {
+	body, err := t.request(ctx, "GET", "/fapi/v3/userTrades", map[string]interface{}{
+		"symbol":  report.Symbol,
+		"orderId": report.OrderID,
+	})
+	if err != nil {
+		log.Warn("  ⚠ 查询成交手续费失败", "order_id", report.OrderID, "error", err)
+		return
+	}
+
+	var trades []asterUserTrade
+	if err := json.Unmarshal(body, &trades); err != nil {
+		log.Warn("  ⚠ 解析成交明细失败", "order_id", report.OrderID, "error", err)
+		return
+	}
+
+	var fills []tradeFill
+	for _, trade := range trades {
+		if trade.OrderID != report.OrderID {
+			continue
+		}
+		fills = append(fills, tradeFill{
+			Price:    parseFloatOrZero(trade.Price),
+			Quantity: parseFloatOrZero(trade.Qty),
+			Fee:      parseFloatOrZero(trade.Commission),
+			FeeAsset: trade.CommissionAsset,
+		})
+	}
+	report.applyFills(fills)
 }
 
 // SetMarginMode 设置仓位模式
-func (t *AsterTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+func (t *AsterTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
 	// Aster支持仓位模式设置
 	// API格式与币安相似：CROSSED(全仓) / ISOLATED(逐仓)
 	marginType := "CROSSED"
@@ -898,62 +1101,47 @@ func (t *AsterTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	}
 
 	// 使用request方法调用API
-	_, err := t.request("POST", "/fapi/v3/marginType", params)
+	_, err := t.request(ctx, "POST", "/fapi/v3/marginType", params)
 	if err != nil {
-		// 如果错误表示无需更改，忽略错误
-		if strings.Contains(err.Error(), "No need to change") ||
-			strings.Contains(err.Error(), "Margin type cannot be changed") {
-			log.Printf("  ✓ %s 仓位模式已是 %s 或有持仓无法更改", symbol, marginType)
+		switch {
+		case errors.Is(err, ErrNoChange), errors.Is(err, ErrMarginModeLocked):
+			log.Info("  ✓ 仓位模式已是目标模式或有持仓无法更改", "symbol", symbol, "margin_type", marginType)
 			return nil
-		}
-		// 检测多资产模式（错误码 -4168）
-		if strings.Contains(err.Error(), "Multi-Assets mode") ||
-			strings.Contains(err.Error(), "-4168") ||
-			strings.Contains(err.Error(), "4168") {
-			log.Printf("  ⚠️ %s 检测到多资产模式，强制使用全仓模式", symbol)
-			log.Printf("  💡 提示：如需使用逐仓模式，请在交易所关闭多资产模式")
+		case errors.Is(err, ErrMultiAssetsMode):
+			log.Warn("  ⚠️ 检测到多资产模式，强制使用全仓模式", "symbol", symbol)
+			log.Info("  💡 提示：如需使用逐仓模式，请在交易所关闭多资产模式")
 			return nil
+		case errors.Is(err, ErrUnifiedAccount):
+			log.Error("  ❌ 检测到统一账户 API，无法进行合约交易", "symbol", symbol)
+			return fmt.Errorf("请使用「现货与合约交易」API 权限，不要使用「统一账户 API」: %w", err)
 		}
-		// 检测统一账户 API
-		if strings.Contains(err.Error(), "unified") ||
-			strings.Contains(err.Error(), "portfolio") ||
-			strings.Contains(err.Error(), "Portfolio") {
-			log.Printf("  ❌ %s 检测到统一账户 API，无法进行合约交易", symbol)
-			return fmt.Errorf("请使用「现货与合约交易」API 权限，不要使用「统一账户 API」")
-		}
-		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+		log.Warn("  ⚠️ 设置仓位模式失败", "error", err)
 		// 不返回错误，让交易继续
 		return nil
 	}
 
-	log.Printf("  ✓ %s 仓位模式已设置为 %s", symbol, marginType)
+	log.Info("  ✓ 仓位模式已设置", "symbol", symbol, "margin_type", marginType)
 	return nil
 }
 
 // SetLeverage 设置杠杆倍数
-func (t *AsterTrader) SetLeverage(symbol string, leverage int) error {
+func (t *AsterTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
 	params := map[string]interface{}{
 		"symbol":   symbol,
 		"leverage": leverage,
 	}
 
-	_, err := t.request("POST", "/fapi/v3/leverage", params)
+	_, err := t.request(ctx, "POST", "/fapi/v3/leverage", params)
 	return err
 }
 
 // GetMarketPrice 获取市场价格
-func (t *AsterTrader) GetMarketPrice(symbol string) (float64, error) {
+func (t *AsterTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
 	// 使用ticker接口获取当前价格
-	resp, err := t.client.Get(fmt.Sprintf("%s/fapi/v3/ticker/price?symbol=%s", t.baseURL, symbol))
+	body, err := t.publicRequest(ctx, "/fapi/v3/ticker/price", url.Values{"symbol": {symbol}})
 	if err != nil {
 		return 0, err
 	}
-	defer resp.Body.Close()
-
-	body, _ := io.ReadAll(resp.Body)
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-	}
 
 	var result map[string]interface{}
 	if err := json.Unmarshal(body, &result); err != nil {
@@ -968,8 +1156,62 @@ func (t *AsterTrader) GetMarketPrice(symbol string) (float64, error) {
 	return strconv.ParseFloat(priceStr, 64)
 }
 
+// GetFundingRate 获取当前（预测）资金费率
+func (t *AsterTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	body, err := t.publicRequest(ctx, "/fapi/v3/premiumIndex", url.Values{"symbol": {symbol}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	rate, err := strconv.ParseFloat(result.LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("资金费率格式错误: %w", err)
+	}
+
+	return &market.FundingRate{
+		Symbol:      symbol,
+		Rate:        rate,
+		FundingTime: result.NextFundingTime,
+	}, nil
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (t *AsterTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	body, err := t.publicRequest(ctx, "/fapi/v3/fundingRate", url.Values{"symbol": {symbol}, "limit": {strconv.Itoa(n)}})
+	if err != nil {
+		return nil, err
+	}
+
+	var result []struct {
+		FundingRate string `json:"fundingRate"`
+		FundingTime int64  `json:"fundingTime"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, err
+	}
+
+	history := make([]market.FundingRate, 0, len(result))
+	for _, item := range result {
+		rate, err := strconv.ParseFloat(item.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, market.FundingRate{Symbol: symbol, Rate: rate, FundingTime: item.FundingTime})
+	}
+
+	return history, nil
+}
+
 // SetStopLoss 设置止损
-func (t *AsterTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+func (t *AsterTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
 	side := "SELL"
 	if positionSide == "SHORT" {
 		side = "BUY"
@@ -996,21 +1238,22 @@ func (t *AsterTrader) SetStopLoss(symbol string, positionSide string, quantity,
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "STOP_MARKET",
-		"side":         side,
-		"stopPrice":    priceStr,
-		"quantity":     qtyStr,
-		"timeInForce":  "GTC",
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "STOP_MARKET",
+		"side":             side,
+		"stopPrice":        priceStr,
+		"quantity":         qtyStr,
+		"timeInForce":      "GTC",
 	}
 
-	_, err = t.request("POST", "/fapi/v3/order", params)
+	_, err = t.request(ctx, "POST", "/fapi/v3/order", params)
 	return err
 }
 
 // SetTakeProfit 设置止盈
-func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+func (t *AsterTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
 	side := "SELL"
 	if positionSide == "SHORT" {
 		side = "BUY"
@@ -1037,27 +1280,33 @@ func (t *AsterTrader) SetTakeProfit(symbol string, positionSide string, quantity
 	qtyStr := t.formatFloatWithPrecision(formattedQty, prec.QuantityPrecision)
 
 	params := map[string]interface{}{
-		"symbol":       symbol,
-		"positionSide": "BOTH",
-		"type":         "TAKE_PROFIT_MARKET",
-		"side":         side,
-		"stopPrice":    priceStr,
-		"quantity":     qtyStr,
-		"timeInForce":  "GTC",
+		"newClientOrderId": newAsterClientOrderID(),
+		"symbol":           symbol,
+		"positionSide":     "BOTH",
+		"type":             "TAKE_PROFIT_MARKET",
+		"side":             side,
+		"stopPrice":        priceStr,
+		"quantity":         qtyStr,
+		"timeInForce":      "GTC",
 	}
 
-	_, err = t.request("POST", "/fapi/v3/order", params)
+	_, err = t.request(ctx, "POST", "/fapi/v3/order", params)
 	return err
 }
 
+// SetPartialTakeProfit 按数量设置止盈单（实现 PartialTakeProfitSetter，Aster 止盈单本身按数量下单）
+func (t *AsterTrader) SetPartialTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice)
+}
+
 // CancelStopLossOrders 仅取消止损单（不影响止盈单）
-func (t *AsterTrader) CancelStopLossOrders(symbol string) error {
+func (t *AsterTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	body, err := t.request("GET", "/fapi/v3/openOrders", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/openOrders", params)
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
 	}
@@ -1082,23 +1331,23 @@ func (t *AsterTrader) CancelStopLossOrders(symbol string) error {
 				"orderId": int64(orderID),
 			}
 
-			_, err := t.request("DELETE", "/fapi/v1/order", cancelParams)
+			_, err := t.request(ctx, "DELETE", "/fapi/v1/order", cancelParams)
 			if err != nil {
 				errMsg := fmt.Sprintf("订单ID %d: %v", int64(orderID), err)
 				cancelErrors = append(cancelErrors, fmt.Errorf("%s", errMsg))
-				log.Printf("  ⚠ 取消止损单失败: %s", errMsg)
+				log.Warn("  ⚠ 取消止损单失败", "error", errMsg)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消止损单 (订单ID: %d, 类型: %s, 方向: %s)", int64(orderID), orderType, positionSide)
+			log.Info("  ✓ 已取消止损单", "order_id", int64(orderID), "order_type", orderType, "position_side", positionSide)
 		}
 	}
 
 	if canceledCount == 0 && len(cancelErrors) == 0 {
-		log.Printf("  ℹ %s 没有止损单需要取消", symbol)
+		log.Info("  ℹ 没有止损单需要取消", "symbol", symbol)
 	} else if canceledCount > 0 {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止损单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止损单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	// 如果所有取消都失败了，返回错误
@@ -1110,13 +1359,13 @@ func (t *AsterTrader) CancelStopLossOrders(symbol string) error {
 }
 
 // CancelTakeProfitOrders 仅取消止盈单（不影响止损单）
-func (t *AsterTrader) CancelTakeProfitOrders(symbol string) error {
+func (t *AsterTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	body, err := t.request("GET", "/fapi/v3/openOrders", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/openOrders", params)
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
 	}
@@ -1141,23 +1390,23 @@ func (t *AsterTrader) CancelTakeProfitOrders(symbol string) error {
 				"orderId": int64(orderID),
 			}
 
-			_, err := t.request("DELETE", "/fapi/v1/order", cancelParams)
+			_, err := t.request(ctx, "DELETE", "/fapi/v1/order", cancelParams)
 			if err != nil {
 				errMsg := fmt.Sprintf("订单ID %d: %v", int64(orderID), err)
 				cancelErrors = append(cancelErrors, fmt.Errorf("%s", errMsg))
-				log.Printf("  ⚠ 取消止盈单失败: %s", errMsg)
+				log.Warn("  ⚠ 取消止盈单失败", "error", errMsg)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消止盈单 (订单ID: %d, 类型: %s, 方向: %s)", int64(orderID), orderType, positionSide)
+			log.Info("  ✓ 已取消止盈单", "order_id", int64(orderID), "order_type", orderType, "position_side", positionSide)
 		}
 	}
 
 	if canceledCount == 0 && len(cancelErrors) == 0 {
-		log.Printf("  ℹ %s 没有止盈单需要取消", symbol)
+		log.Info("  ℹ 没有止盈单需要取消", "symbol", symbol)
 	} else if canceledCount > 0 {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止盈单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止盈单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	// 如果所有取消都失败了，返回错误
@@ -1169,24 +1418,24 @@ func (t *AsterTrader) CancelTakeProfitOrders(symbol string) error {
 }
 
 // CancelAllOrders 取消所有订单
-func (t *AsterTrader) CancelAllOrders(symbol string) error {
+func (t *AsterTrader) CancelAllOrders(ctx context.Context, symbol string) error {
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	_, err := t.request("DELETE", "/fapi/v3/allOpenOrders", params)
+	_, err := t.request(ctx, "DELETE", "/fapi/v3/allOpenOrders", params)
 	return err
 }
 
 // CancelAllOrdersWithRetry 取消所有掛單（帶重試機制）
-func (t *AsterTrader) CancelAllOrdersWithRetry(symbol string, maxRetries int) error {
+func (t *AsterTrader) CancelAllOrdersWithRetry(ctx context.Context, symbol string, maxRetries int) error {
 	var lastErr error
 
 	for attempt := 1; attempt <= maxRetries; attempt++ {
-		err := t.CancelAllOrders(symbol)
+		err := t.CancelAllOrders(ctx, symbol)
 		if err == nil {
 			if attempt > 1 {
-				log.Printf("  ✓ 第 %d 次重試成功取消 %s 的掛單", attempt, symbol)
+				log.Info("  ✓ 重試成功取消掛單", "attempt", attempt, "symbol", symbol)
 			}
 			return nil
 		}
@@ -1195,24 +1444,26 @@ func (t *AsterTrader) CancelAllOrdersWithRetry(symbol string, maxRetries int) er
 		if attempt < maxRetries {
 			// 遞增延遲：1秒, 2秒, 3秒...
 			waitTime := time.Duration(attempt) * time.Second
-			log.Printf("  🔄 取消掛單失敗，%v 後重試 (%d/%d): %v", waitTime, attempt, maxRetries, err)
-			time.Sleep(waitTime)
+			log.Info("  🔄 取消掛單失敗，稍後重試", "wait_time", waitTime, "attempt", attempt, "max_retries", maxRetries, "error", err)
+			if err := sleepContext(ctx, waitTime); err != nil {
+				return fmt.Errorf("取消掛單被中止: %w", err)
+			}
 		}
 	}
 
 	// 所有重試都失敗
-	log.Printf("  ❌ 緊急：%s 掛單取消失敗（已重試 %d 次），請手動檢查！", symbol, maxRetries)
+	log.Error("  ❌ 緊急：掛單取消失敗，請手動檢查！", "symbol", symbol, "max_retries", maxRetries)
 	return fmt.Errorf("重試 %d 次後仍失敗: %w", maxRetries, lastErr)
 }
 
 // CancelStopOrders 取消该币种的止盈/止损单（用于调整止盈止损位置）
-func (t *AsterTrader) CancelStopOrders(symbol string) error {
+func (t *AsterTrader) CancelStopOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	params := map[string]interface{}{
 		"symbol": symbol,
 	}
 
-	body, err := t.request("GET", "/fapi/v3/openOrders", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/openOrders", params)
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
 	}
@@ -1239,22 +1490,21 @@ func (t *AsterTrader) CancelStopOrders(symbol string) error {
 				"orderId": int64(orderID),
 			}
 
-			_, err := t.request("DELETE", "/fapi/v3/order", cancelParams)
+			_, err := t.request(ctx, "DELETE", "/fapi/v3/order", cancelParams)
 			if err != nil {
-				log.Printf("  ⚠ 取消订单 %d 失败: %v", int64(orderID), err)
+				log.Warn("  ⚠ 取消订单失败", "order_id", int64(orderID), "error", err)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消 %s 的止盈/止损单 (订单ID: %d, 类型: %s)",
-				symbol, int64(orderID), orderType)
+			log.Info("  ✓ 已取消止盈/止损单", "symbol", symbol, "order_id", int64(orderID), "order_type", orderType)
 		}
 	}
 
 	if canceledCount == 0 {
-		log.Printf("  ℹ %s 没有止盈/止损单需要取消", symbol)
+		log.Info("  ℹ 没有止盈/止损单需要取消", "symbol", symbol)
 	} else {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止盈/止损单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止盈/止损单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	return nil
@@ -1271,13 +1521,13 @@ func (t *AsterTrader) FormatQuantity(symbol string, quantity float64) (string, e
 
 // GetOpenOrders retrieves open orders for AI decision context
 // Returns all orders if symbol is empty, otherwise returns orders for the specified symbol
-func (t *AsterTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo, error) {
+func (t *AsterTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
 	params := map[string]interface{}{}
 	if symbol != "" {
 		params["symbol"] = symbol
 	}
 
-	body, err := t.request("GET", "/fapi/v3/openOrders", params)
+	body, err := t.request(ctx, "GET", "/fapi/v3/openOrders", params)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch open orders: %w", err)
 	}
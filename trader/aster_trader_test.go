@@ -198,7 +198,6 @@ func NewAsterTraderTestSuite(t *testing.T) *AsterTraderTestSuite {
 
 	// 创建 mock trader，使用 mock server 的 URL
 	trader := &AsterTrader{
-		ctx:             context.Background(),
 		user:            "0x1234567890123456789012345678901234567890",
 		signer:          "0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
 		privateKey:      privateKey,
@@ -247,6 +246,49 @@ func TestAsterTrader_CommonInterface(t *testing.T) {
 // 三、Aster 特定功能的单元测试
 // ============================================================
 
+// TestAsterPublicRequestHonoursContext 测试公开行情请求携带调用方 ctx（取消或超时后立即返回）
+func TestAsterPublicRequestHonoursContext(t *testing.T) {
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("symbol") != "BTCUSDT" {
+			t.Errorf("unexpected query: %s", r.URL.RawQuery)
+		}
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+
+	trader := &AsterTrader{client: server.Client(), baseURL: server.URL}
+	calls := map[string]func(ctx context.Context) error{
+		"GetMarketPrice": func(ctx context.Context) error {
+			_, err := trader.GetMarketPrice(ctx, "BTCUSDT")
+			return err
+		},
+		"GetFundingRate": func(ctx context.Context) error {
+			_, err := trader.GetFundingRate(ctx, "BTCUSDT")
+			return err
+		},
+		"GetFundingRateHistory": func(ctx context.Context) error {
+			_, err := trader.GetFundingRateHistory(ctx, "BTCUSDT", 3)
+			return err
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			start := time.Now()
+			if err := call(ctx); err == nil {
+				t.Fatal("超时后应返回错误")
+			}
+			if elapsed := time.Since(start); elapsed > 2*time.Second {
+				t.Errorf("请求未随 ctx 超时中止，耗时 %v", elapsed)
+			}
+		})
+	}
+}
+
 // TestNewAsterTrader 测试创建 Aster 交易器
 func TestNewAsterTrader(t *testing.T) {
 	tests := []struct {
@@ -337,7 +379,7 @@ func TestCancelAllOrdersWithRetry_Success_FirstAttempt(t *testing.T) {
 	trader.baseURL = mockServer.URL
 
 	// 执行测试
-	err := trader.CancelAllOrdersWithRetry("BTCUSDT", 3)
+	err := trader.CancelAllOrdersWithRetry(context.Background(), "BTCUSDT", 3)
 
 	// 验证：成功且只调用1次
 	assert.NoError(t, err)
@@ -383,7 +425,7 @@ func TestCancelAllOrdersWithRetry_Success_SecondAttempt(t *testing.T) {
 
 	// 执行测试（记录开始时间）
 	startTime := time.Now()
-	err := trader.CancelAllOrdersWithRetry("BTCUSDT", 3)
+	err := trader.CancelAllOrdersWithRetry(context.Background(), "BTCUSDT", 3)
 	duration := time.Since(startTime)
 
 	// 验证：成功、调用2次、延迟约1秒
@@ -430,7 +472,7 @@ func TestCancelAllOrdersWithRetry_Success_ThirdAttempt(t *testing.T) {
 	trader.baseURL = mockServer.URL
 
 	startTime := time.Now()
-	err := trader.CancelAllOrdersWithRetry("BTCUSDT", 3)
+	err := trader.CancelAllOrdersWithRetry(context.Background(), "BTCUSDT", 3)
 	duration := time.Since(startTime)
 
 	assert.NoError(t, err)
@@ -467,7 +509,7 @@ func TestCancelAllOrdersWithRetry_AllFailed(t *testing.T) {
 	trader.baseURL = mockServer.URL
 
 	startTime := time.Now()
-	err := trader.CancelAllOrdersWithRetry("BTCUSDT", 3)
+	err := trader.CancelAllOrdersWithRetry(context.Background(), "BTCUSDT", 3)
 	duration := time.Since(startTime)
 
 	assert.Error(t, err)
@@ -504,7 +546,7 @@ func TestCancelAllOrdersWithRetry_RetryIntervals(t *testing.T) {
 	)
 	trader.baseURL = mockServer.URL
 
-	_ = trader.CancelAllOrdersWithRetry("BTCUSDT", 3)
+	_ = trader.CancelAllOrdersWithRetry(context.Background(), "BTCUSDT", 3)
 
 	assert.Len(t, timestamps, 3, "应该调用3次")
 
@@ -578,7 +620,7 @@ func TestCancelAllOrdersWithRetry_MaxRetries(t *testing.T) {
 			trader.baseURL = mockServer.URL
 
 			startTime := time.Now()
-			err := trader.CancelAllOrdersWithRetry("ETHUSDT", tt.maxRetries)
+			err := trader.CancelAllOrdersWithRetry(context.Background(), "ETHUSDT", tt.maxRetries)
 			duration := time.Since(startTime)
 
 			assert.Error(t, err)
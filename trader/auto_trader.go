@@ -1,18 +1,23 @@
 package trader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"math"
 	"nofx/config"
 	"nofx/decision"
+	"nofx/eventbus"
 	"nofx/logger"
+	"nofx/logging"
 	"nofx/market"
 	"nofx/mcp"
+	"nofx/metrics"
 	"nofx/pool"
+	"nofx/store"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -56,6 +61,10 @@ type AutoTraderConfig struct {
 	// 扫描配置
 	ScanInterval time.Duration // 扫描间隔（建议3分钟）
 
+	// 调度模式（"interval"=按扫描间隔，"candle_close"=在订阅时间线的K线收盘时触发）
+	ScheduleMode            string // 空=interval
+	CandleCloseGraceSeconds int    // 收盘后等待数据可用的秒数（默认3）
+
 	// 账户配置
 	InitialBalance float64 // 初始金额（用于计算盈亏，需手动设置）
 
@@ -72,6 +81,82 @@ type AutoTraderConfig struct {
 	MaxDrawdown     float64       // 最大回撤百分比（提示）
 	StopTradingTime time.Duration // 触发风控后暂停时长
 
+	// 组合级相关性敞口限制（同组同方向合计名义价值上限）
+	CorrelationGroups []CorrelationGroup
+
+	// 资金费率过滤（开仓前检查预测资金费率成本）
+	FundingCostThresholdBps float64 // 单次结算资金费率成本阈值（基点，0=关闭）
+	FundingFilterMode       string  // 超过阈值时的处理："veto"（默认，拒绝开仓）/ "reverse"（反向开仓收取资金费）
+
+	// 保证金率监控（在交易所强平/自动减仓前主动降低风险）
+	MarginRatioDangerPct float64 // 保证金率危险阈值百分比（0=关闭）
+	DeleveragePct        float64 // 触发后对亏损最大持仓的减仓比例百分比（默认50）
+
+	// 出场模板（按币种流动性分类选择止损距离、止盈阶梯、回撤止盈规则）
+	BracketTemplates map[string]BracketTemplate // 分类 -> 模板（"major" / "midcap" / "meme"；空=关闭）
+	SymbolClasses    map[string]string          // 币种分类覆盖（如 "SOLUSDT": "major"）
+
+	// 收盘平仓（日内策略：每天固定时间平掉全部持仓并撤销挂单，之后当天不再开仓）
+	DailyFlattenTime      string // 每天平仓时间 "HH:MM"（空=关闭）
+	DailyFlattenTimezone  string // 平仓时间所在时区，IANA 名称如 "America/New_York"（空=UTC）
+	FlattenWarningMinutes int    // 提前多少分钟发出平仓预警（默认10）
+
+	// 交易时段和事件禁开仓窗口（如避开周末、CPI/FOMC 前后 30 分钟；nil=不限制）
+	TradingSchedule *TradingSchedule
+
+	// 分批止盈（按风险倍数分档止盈、第一档后止损移至保本、尾仓移动止损；nil=使用单一止盈）
+	ScaleOut *ScaleOut
+
+	// 最长持仓时间（超时仍未触发止盈/止损时告警或平仓；nil=不限制）
+	MaxHolding *MaxHolding
+
+	// 止损收紧规则（浮盈达到 X R 或价格移动 Y% 时把止损移至保本/ATR/摆动点；nil=不自动调整）
+	StopRules *StopRules
+
+	// 加仓（已有同向持仓时继续开仓，按批次记录并合并止损/止盈）
+	AllowScaleIn bool   // 是否允许加仓（默认拒绝同向重复开仓）
+	LotMatching  string // 部分平仓消耗加仓批次的顺序："fifo"（默认）/ "lifo"，用于日志分析的逐批盈亏
+
+	// 顺势加仓（浮盈达到阈值后才允许加仓，批次有上限、规模递减，加仓后合并止损；nil=按 AllowScaleIn 不限条件加仓）
+	Pyramiding *Pyramiding
+
+	// 决策来源（AI、外部 webhook、规则策略，多个来源时投票；nil=只用 AI 决策）
+	DecisionProviders *DecisionProviders
+
+	// 每个周期并发执行决策的币种数（<=1=按顺序执行；>1 时同一批内不同币种并发执行，并共享短时缓存的余额和持仓）
+	SymbolWorkers int
+
+	// 开仓后止损单设置失败的处理策略
+	ProtectionFailurePolicy string // "retry"（默认，重试）/ "synthetic"（本地模拟止损）/ "flatten"（立即平仓）
+	ProtectionRetryCount    int    // retry 策略的重试次数（默认3）
+
+	// 告警回调（止损保护失败等 CRITICAL 事件，nil=仅记录日志）
+	AlertHandler AlertHandler
+
+	// 告警/通知中的数字格式（千位分隔符、小数位、大额缩写；零值为默认格式）
+	NumberFormat logger.NumberFormat
+
+	// 精度快照（冷启动时从本地文件加载交易对精度，免去逐个交易对的规则请求）
+	PrecisionSnapshotDir string // 快照目录（空=关闭，启动时一次拉取全部交易规则）
+
+	// 交易规则定时刷新间隔（交易所会调整价格/数量步进，0=不定时刷新）
+	InstrumentRefreshInterval time.Duration
+
+	// 净值快照间隔（钱包余额、未实现盈亏、持仓数写入事件日志，需要 JournalPath；0=不记录）
+	EquitySnapshotInterval time.Duration
+
+	// 交易事件日志（决策、下单、成交、止盈止损、错误写入 SQLite，用于崩溃恢复和审计）
+	JournalPath string // SQLite 文件路径（空=关闭）
+
+	// 单次交易所调用超时（0=DefaultCallTimeout，<0=不限制；下单/平仓只随停止取消）
+	ExchangeTimeout time.Duration
+
+	// 交易所请求重试策略（零值=DefaultRetryPolicy；只重试幂等请求和带 clientOrderId 的下单）
+	RetryPolicy RetryPolicy
+
+	// 启动对账（核对事件日志、交易所持仓和止盈止损挂单）
+	ReconcilePolicy string // "repair"（默认，自动修复，无法修复时暂停交易）/ "halt"（有不一致即暂停交易，等待确认）/ "off"
+
 	// 仓位模式
 	IsCrossMargin bool // true=全仓模式, false=逐仓模式
 
@@ -79,6 +164,12 @@ type AutoTraderConfig struct {
 	DefaultCoins []string // 默认币种列表（从数据库获取）
 	TradingCoins []string // 实际交易币种列表
 
+	// 动态币种池（每天按 24 小时成交额或振幅选取前 N 个币种，替代系统默认币种；nil=使用默认币种）
+	Universe *market.UniverseConfig
+
+	// 合约上架/下架监控（计划下架的币种禁止开仓，下架前平仓；nil=不监控）
+	ListingWatch *ListingWatch
+
 	// 币种池信号源配置
 	UseCoinPool bool // 是否使用 AI500 Coin Pool 信号源
 	UseOITop    bool // 是否使用 OI Top 增长信号源
@@ -87,9 +178,21 @@ type AutoTraderConfig struct {
 	SystemPromptTemplate string // 系统提示词模板名称（如 "default", "aggressive"）
 
 	// 订单策略配置
-	OrderStrategy       string  // Order strategy: "market_only", "conservative_hybrid", "limit_only"
+	OrderStrategy       string  // Order strategy: "market_only", "conservative_hybrid", "limit_only", "maker_then_taker"
 	LimitPriceOffset    float64 // Limit order price offset percentage (e.g., -0.03 for -0.03%)
 	LimitTimeoutSeconds int     // Timeout in seconds before converting to market order
+	MaxSlippageBps      float64 // 开仓市价单最大滑点（基点，0=不限制）：按最优买卖价转为 IOC 限价单，价差超过上限时拒绝开仓
+
+	// 开仓确认配置（过滤单根K线尖刺触发的信号）
+	ConfirmDelaySeconds    int     // 开仓前等待N秒后用最新行情重新验证（0=关闭）
+	ConfirmMaxDeviationPct float64 // 确认期间允许的最大价格偏离百分比（默认0.5）
+
+	// 下单前价格时效检查（防止按上一轮循环的旧价格计算数量/止损）
+	MaxPriceAgeMs int // 价格最大允许年龄（毫秒，0=默认2000，<0=关闭），超过则刷新价格并重算数量
+
+	// 开仓前多数据源价格交叉校验（防止单个数据源价格过期或脱锚）
+	PriceSanitySources         []string // 校验数据源名称（如 ["hyperliquid", "okx"]，空=使用数据源管理器的一致性检查）
+	PriceSanityMaxDeviationPct float64  // 决策价格与任一数据源的最大偏差百分比（0=默认2）
 
 	// K线时间线配置
 	Timeframes []string // K线时间线选择，例如: ["1m", "15m", "1h", "4h"]
@@ -102,7 +205,16 @@ type AutoTrader struct {
 	aiModel               string // AI模型名称
 	exchange              string // 交易平台名称
 	config                AutoTraderConfig
-	trader                Trader // 使用Trader接口（支持多平台）
+	trader                Trader                  // 使用Trader接口（支持多平台）
+	orderLimits           OrderLimitsProvider     // 最小下单要求（交易器不支持时为 nil）
+	partialTakeProfit     PartialTakeProfitSetter // 按数量挂止盈单（交易器不支持时为 nil，分批止盈改由监控触发）
+	stopAmender           StopLossAmender         // 直接修改止损单（交易器不支持时为 nil，撤单后重新设置）
+	positionGetter        PositionGetter          // 按币种查询持仓（交易器不支持时为 nil，查询全部持仓后筛选）
+	instrumentPreloader   InstrumentPreloader     // 一次拉取全部交易规则（交易器不支持时为 nil，首次下单时拉取）
+	accountStream         AccountStreamer         // 私有推送（交易器不支持时为 nil，持仓/余额按 REST 缓存查询）
+	positionEvents        PositionEventSubscriber // 持仓缓存订阅推送的持仓事件（交易器不支持时为 nil）
+	accountEvents         *AccountEventBus        // 账户事件总线（私有推送发布）
+	priceSanitySources    []market.DataSource     // 开仓前价格交叉校验数据源（空=使用数据源管理器）
 	mcpClient             mcp.AIClient
 	decisionLogger        logger.IDecisionLogger // 决策日志记录器
 	initialBalance        float64
@@ -119,6 +231,9 @@ type AutoTrader struct {
 	useOITop              bool     // 是否使用 OI Top 增长信号源
 	coinPoolAPIURL        string
 	oiTopAPIURL           string
+	universe              *market.Universe // 动态币种池（nil=未启用）
+	listingProvider       market.ListingProvider
+	listingState          listingState
 	lastResetTime         time.Time
 	stopUntil             time.Time
 	isRunning             bool
@@ -126,16 +241,39 @@ type AutoTrader struct {
 	callCount             int                              // AI调用次数
 	positionFirstSeenTime map[string]int64                 // 持仓首次出现时间 (symbol_side -> timestamp毫秒)
 	lastPositions         map[string]decision.PositionInfo // 上一次周期的持仓快照 (用于检测被动平仓)
+	agingAlerted          map[string]int64                 // 已发送超时告警的持仓 (symbol_side -> 开仓时间，重新开仓后再次告警)
 	positionStopLoss      map[string]float64               // 持仓止损价格 (symbol_side -> stop_loss_price)
 	positionTakeProfit    map[string]float64               // 持仓止盈价格 (symbol_side -> take_profit_price)
 	stopMonitorCh         chan struct{}                    // 用于停止监控goroutine
+	stopMonitorOnce       sync.Once                        // 保证 stopMonitorCh 只关闭一次（Stop 和 Shutdown 共用）
 	monitorWg             sync.WaitGroup                   // 用于等待监控goroutine结束
 	peakPnLCache          map[string]float64               // 最高收益缓存 (symbol -> 峰值盈亏百分比)
 	peakPnLCacheMutex     sync.RWMutex                     // 缓存读写锁
+	ladderProgress        map[string]int                   // 止盈阶梯已触发档数 (symbol_side -> 档数)
+	scaleOuts             map[string]*scaleOutState        // 分批止盈进度 (symbol_side -> 进度)
+	pyramids              map[string]*pyramidState         // 顺势加仓进度 (symbol_side -> 进度)
+	decisionProvider      decision.DecisionProvider        // 决策来源（默认 AI）
+	ladderMutex           sync.Mutex                       // 止盈阶梯/分批止盈进度锁
 	peakEquity            float64                          // 账户峰值净值，用于回撤计算
 	lastBalanceSyncTime   time.Time                        // 上次余额同步时间
 	database              interface{}                      // 数据库引用（用于自动更新余额）
 	userID                string                           // 用户ID
+	paramMutex            sync.Mutex                       // 策略参数调整锁
+	paramJournal          *paramJournal                    // 策略参数调整日志
+	syntheticStops        map[string]syntheticStop         // 本地模拟止损 (symbol_side -> 止损)
+	syntheticStopMutex    sync.Mutex                       // 模拟止损锁
+	journal               *store.Journal                   // 交易事件日志（nil=关闭）
+	reconcileReport       *ReconcileReport                 // 启动对账结果
+	reconcileMutex        sync.Mutex                       // 对账结果锁
+	pause                 PauseState                       // 人工暂停状态
+	pauseMutex            sync.Mutex                       // 人工暂停状态锁
+	stopUntilMutex        sync.Mutex                       // 风险暂停恢复时间锁（周期循环与外部信号并发读写 stopUntil）
+	positionMutex         sync.Mutex                       // 持仓状态锁（首次出现时间、止损止盈价格、加仓进度）
+	symbolLocks           sync.Map                         // 币种执行锁 (symbol -> *sync.Mutex)
+	marginReserve         marginReservation                // 并发开仓时本批已占用的保证金
+	runCtx                context.Context                  // 运行期 ctx（Stop 时取消，中止进行中的交易所请求）
+	cancelRun             context.CancelFunc
+	draining              atomic.Bool // 优雅退出中：不再开始新的决策周期和执行新决策
 }
 
 // NewAutoTrader 创建自动交易器
@@ -161,24 +299,24 @@ func NewAutoTrader(config AutoTraderConfig, database interface{}, userID string)
 	if config.AIModel == "custom" {
 		// 使用自定义API
 		mcpClient.SetAPIKey(config.CustomAPIKey, config.CustomAPIURL, config.CustomModelName)
-		log.Printf("🤖 [%s] 使用自定义AI API: %s (模型: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
+		log.Info("🤖 使用自定义AI API", "trader", config.Name, "url", config.CustomAPIURL, "model", config.CustomModelName)
 	} else if config.UseQwen || config.AIModel == "qwen" {
 		// 使用Qwen (支持自定义URL和Model)
 		mcpClient = mcp.NewQwenClient()
 		mcpClient.SetAPIKey(config.QwenKey, config.CustomAPIURL, config.CustomModelName)
 		if config.CustomAPIURL != "" || config.CustomModelName != "" {
-			log.Printf("🤖 [%s] 使用阿里云Qwen AI (自定义URL: %s, 模型: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
+			log.Info("🤖 使用阿里云Qwen AI", "trader", config.Name, "url", config.CustomAPIURL, "model", config.CustomModelName)
 		} else {
-			log.Printf("🤖 [%s] 使用阿里云Qwen AI", config.Name)
+			log.Info("🤖 使用阿里云Qwen AI", "trader", config.Name)
 		}
 	} else {
 		// 默认使用DeepSeek (支持自定义URL和Model)
 		mcpClient = mcp.NewDeepSeekClient()
 		mcpClient.SetAPIKey(config.DeepSeekKey, config.CustomAPIURL, config.CustomModelName)
 		if config.CustomAPIURL != "" || config.CustomModelName != "" {
-			log.Printf("🤖 [%s] 使用DeepSeek AI (自定义URL: %s, 模型: %s)", config.Name, config.CustomAPIURL, config.CustomModelName)
+			log.Info("🤖 使用DeepSeek AI", "trader", config.Name, "url", config.CustomAPIURL, "model", config.CustomModelName)
 		} else {
-			log.Printf("🤖 [%s] 使用DeepSeek AI", config.Name)
+			log.Info("🤖 使用DeepSeek AI", "trader", config.Name)
 		}
 	}
 
@@ -187,52 +325,82 @@ func NewAutoTrader(config AutoTraderConfig, database interface{}, userID string)
 		config.Exchange = "binance"
 	}
 
-	// 根据配置创建对应的交易器
-	var trader Trader
-	var err error
-
 	// 记录仓位模式（通用）
 	marginModeStr := "全仓"
 	if !config.IsCrossMargin {
 		marginModeStr = "逐仓"
 	}
-	log.Printf("📊 [%s] 仓位模式: %s", config.Name, marginModeStr)
+	log.Info("📊 仓位模式", "trader", config.Name, "margin_mode", marginModeStr)
 
-	switch config.Exchange {
-	case "binance":
-		log.Printf("🏦 [%s] 使用币安合约交易", config.Name)
-		trader = NewFuturesTrader(
-			config.BinanceAPIKey,
-			config.BinanceSecretKey,
-			userID,
-			config.OrderStrategy,
-			config.LimitPriceOffset,
-			config.LimitTimeoutSeconds,
-		)
-	case "hyperliquid":
-		log.Printf("🏦 [%s] 使用Hyperliquid交易", config.Name)
-		trader, err = NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
-		if err != nil {
-			return nil, fmt.Errorf("初始化Hyperliquid交易器失败: %w", err)
-		}
-	case "aster":
-		log.Printf("🏦 [%s] 使用Aster交易", config.Name)
-		trader, err = NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
+	// 根据配置创建对应的交易器
+	trader, err := NewExchangeTrader(config, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.PrecisionSnapshotDir != "" {
+		preloadPrecisionSnapshot(trader, config.PrecisionSnapshotDir, config.Exchange)
+	}
+	raw := trader
+	orderLimits, _ := trader.(OrderLimitsProvider)
+	partialTakeProfit, _ := trader.(PartialTakeProfitSetter)
+	stopAmender, _ := trader.(StopLossAmender)
+	positionGetter, _ := trader.(PositionGetter)
+	instrumentPreloader, _ := trader.(InstrumentPreloader)
+	accountStream, _ := trader.(AccountStreamer)
+	positionEvents, _ := trader.(PositionEventSubscriber)
+	priceSanitySources, err := newPriceSanitySources(config.PriceSanitySources)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := config.ExchangeTimeout
+	if timeout == 0 {
+		timeout = DefaultCallTimeout
+	}
+	trader = newTimeoutTrader(trader, timeout)
+	classifying := newClassifyingTrader(trader, config.Exchange)
+	if syncer, ok := raw.(ClockSyncer); ok {
+		classifying.clock = syncer.ServerClock()
+	}
+	trader = classifying
+	trader = newMetricsTrader(trader, config.Exchange)
+	trader = newEventTrader(trader, config.Exchange, config.ID)
+
+	var journal *store.Journal
+	if config.JournalPath != "" {
+		journal, err = store.OpenJournal(config.JournalPath)
 		if err != nil {
-			return nil, fmt.Errorf("初始化Aster交易器失败: %w", err)
+			return nil, fmt.Errorf("打开交易事件日志失败: %w", err)
 		}
-	default:
-		return nil, fmt.Errorf("不支持的交易平台: %s", config.Exchange)
+		trader = newJournalTrader(trader, journal, config.ID)
 	}
+	if config.SymbolWorkers > 1 {
+		trader = newAccountCacheTrader(trader, accountCacheTTL)
+		log.Info("⚡ 并发执行决策", "trader", config.Name, "symbol_workers", config.SymbolWorkers)
+	}
+	trader = newLeverageTrader(trader, config.Exchange, config.ID, journal)
 
 	// 验证初始金额配置
 	if config.InitialBalance <= 0 {
 		return nil, fmt.Errorf("初始金额必须大于0，请在配置中设置InitialBalance")
 	}
 
+	var universe *market.Universe
+	if config.Universe != nil {
+		if universe, err = market.NewUniverse(*config.Universe); err != nil {
+			return nil, fmt.Errorf("初始化动态币种池失败: %w", err)
+		}
+	}
+
 	// 初始化决策日志记录器（使用trader ID创建独立目录）
 	logDir := fmt.Sprintf("decision_logs/%s", config.ID)
 	decisionLogger := logger.NewDecisionLogger(logDir)
+	if dl, ok := decisionLogger.(*logger.DecisionLogger); ok {
+		if err := dl.SetLotMatching(config.LotMatching); err != nil {
+			return nil, err
+		}
+	}
 
 	// 设置默认系统提示词模板
 	systemPromptTemplate := config.SystemPromptTemplate
@@ -241,19 +409,29 @@ func NewAutoTrader(config AutoTraderConfig, database interface{}, userID string)
 		systemPromptTemplate = "adaptive"
 	}
 
-	return &AutoTrader{
+	at := &AutoTrader{
 		id:                    config.ID,
 		name:                  config.Name,
 		aiModel:               config.AIModel,
 		exchange:              config.Exchange,
 		config:                config,
 		trader:                trader,
+		orderLimits:           orderLimits,
+		partialTakeProfit:     partialTakeProfit,
+		stopAmender:           stopAmender,
+		positionGetter:        positionGetter,
+		instrumentPreloader:   instrumentPreloader,
+		accountStream:         accountStream,
+		positionEvents:        positionEvents,
+		accountEvents:         NewAccountEventBus(),
+		priceSanitySources:    priceSanitySources,
 		mcpClient:             mcpClient,
 		decisionLogger:        decisionLogger,
 		initialBalance:        config.InitialBalance,
 		systemPromptTemplate:  systemPromptTemplate,
 		timeframes:            config.Timeframes, // K线时间线配置
 		defaultCoins:          config.DefaultCoins,
+		universe:              universe,
 		tradingCoins:          config.TradingCoins,
 		useCoinPool:           config.UseCoinPool,
 		useOITop:              config.UseOITop,
@@ -272,46 +450,145 @@ func NewAutoTrader(config AutoTraderConfig, database interface{}, userID string)
 		monitorWg:             sync.WaitGroup{},
 		peakPnLCache:          make(map[string]float64),
 		peakPnLCacheMutex:     sync.RWMutex{},
+		ladderProgress:        make(map[string]int),
+		scaleOuts:             make(map[string]*scaleOutState),
 		lastBalanceSyncTime:   time.Now(), // 初始化为当前时间
 		database:              database,
 		userID:                userID,
 		coinPoolAPIURL:        strings.TrimSpace(config.CoinPoolAPIURL),
 		oiTopAPIURL:           strings.TrimSpace(config.OITopAPIURL),
-	}, nil
+		paramJournal:          newParamJournal(logDir + "/params/changes.jsonl"),
+		journal:               journal,
+	}
+	if at.decisionProvider, err = at.buildDecisionProvider(); err != nil {
+		return nil, err
+	}
+	if journal != nil {
+		at.restoreFromJournal()
+	}
+	return at, nil
+}
+
+// NewExchangeTrader 根据 config.Exchange 和对应的密钥创建交易所交易器（不含 AI 和风控装饰，命令行一次性操作也使用）
+func NewExchangeTrader(config AutoTraderConfig, userID string) (Trader, error) {
+	switch config.Exchange {
+	case "binance":
+		log.Info("🏦 使用币安合约交易", "trader", config.Name)
+		futuresTrader := NewFuturesTrader(
+			config.BinanceAPIKey,
+			config.BinanceSecretKey,
+			userID,
+			config.OrderStrategy,
+			config.LimitPriceOffset,
+			config.LimitTimeoutSeconds,
+		)
+		if config.MaxSlippageBps > 0 {
+			futuresTrader.SetMaxSlippageBps(config.MaxSlippageBps)
+			log.Info("🛡️ 开仓市价单最大滑点", "trader", config.Name, "max_slippage_bps", config.MaxSlippageBps)
+		}
+		return futuresTrader, nil
+	case "hyperliquid":
+		log.Info("🏦 使用Hyperliquid交易", "trader", config.Name)
+		trader, err := NewHyperliquidTrader(config.HyperliquidPrivateKey, config.HyperliquidWalletAddr, config.HyperliquidTestnet)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Hyperliquid交易器失败: %w", err)
+		}
+		return trader, nil
+	case "aster":
+		log.Info("🏦 使用Aster交易", "trader", config.Name)
+		asterTrader, err := NewAsterTrader(config.AsterUser, config.AsterSigner, config.AsterPrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("初始化Aster交易器失败: %w", err)
+		}
+		asterTrader.SetRetryPolicy(config.RetryPolicy)
+		return asterTrader, nil
+	default:
+		return nil, fmt.Errorf("不支持的交易平台: %s", config.Exchange)
+	}
 }
 
 // Run 运行自动交易主循环
 func (at *AutoTrader) Run() error {
+	at.runCtx, at.cancelRun = context.WithCancel(context.Background())
+	at.draining.Store(false)
 	at.isRunning = true
 	at.stopMonitorCh = make(chan struct{})
+	at.stopMonitorOnce = sync.Once{}
 	at.startTime = time.Now()
 
-	log.Println("🚀 AI驱动自动交易系统启动")
-	log.Printf("💰 初始余额: %.2f USDT", at.initialBalance)
-	log.Printf("⚙️  扫描间隔: %v", at.config.ScanInterval)
-	log.Println("🤖 AI将全权决定杠杆、仓位大小、止损止盈等参数")
+	log.Info("🚀 AI驱动自动交易系统启动")
+	log.Info("💰 初始余额", "initial_balance", at.initialBalance)
+	if at.config.ScheduleMode == ScheduleModeCandleClose {
+		log.Info("⚙️ 调度模式: K线收盘触发", "timeframes", at.timeframes)
+	} else {
+		log.Info("⚙️ 扫描间隔", "scan_interval", at.getScanInterval())
+	}
+	log.Info("🤖 AI将全权决定杠杆、仓位大小、止损止盈等参数")
 	at.monitorWg.Add(1)
 	defer at.monitorWg.Done()
 
+	// 私有推送（持仓缓存按推送增量更新）
+	at.startAccountStream()
+
+	// 预加载交易规则并定时刷新
+	at.preloadInstruments()
+	at.startInstrumentRefreshMonitor()
+
+	// 定时记录净值快照
+	at.startEquitySnapshotMonitor()
+
+	// 合约上架/下架监控
+	at.startListingMonitor()
+
+	// 启动对账（不一致且无法修复时暂停交易，等待确认）
+	at.reconcileOnStart()
+
 	// 启动回撤监控
 	at.startDrawdownMonitor()
 
-	ticker := time.NewTicker(at.config.ScanInterval)
+	// 启动保证金率监控
+	at.startMarginRatioMonitor()
+
+	// 启动收盘平仓监控
+	at.startDailyFlattenMonitor()
+
+	// 启动事件窗口监控（进入禁开仓窗口时平仓或收紧止损）
+	at.startTradingScheduleMonitor()
+
+	// 启动止损收紧规则监控（浮盈达到阈值后移至保本/ATR/摆动点）
+	at.startStopRuleMonitor()
+
+	// 启动模拟止损监控
+	at.startSyntheticStopMonitor()
+
+	// K线收盘对齐调度（不在启动时立即执行，避免在K线中途采样）
+	if schedule := at.candleCloseSchedule(); schedule != nil {
+		return at.runCandleCloseLoop(schedule)
+	}
+
+	scanInterval := at.getScanInterval()
+	ticker := time.NewTicker(scanInterval)
 	defer ticker.Stop()
 
 	// 首次立即执行
 	if err := at.runCycle(); err != nil {
-		log.Printf("❌ 执行失败: %v", err)
+		log.Error("❌ 执行失败", "error", err)
 	}
 
 	for at.isRunning {
 		select {
 		case <-ticker.C:
 			if err := at.runCycle(); err != nil {
-				log.Printf("❌ 执行失败: %v", err)
+				log.Error("❌ 执行失败", "error", err)
+			}
+			// 扫描间隔可能在运行时被调整
+			if current := at.getScanInterval(); current != scanInterval {
+				scanInterval = current
+				ticker.Reset(scanInterval)
+				log.Info("⚙️ 扫描间隔已更新", "trader", at.name, "scan_interval", scanInterval)
 			}
 		case <-at.stopMonitorCh:
-			log.Printf("[%s] ⏹ 收到停止信号，退出自动交易主循环", at.name)
+			log.Info("⏹ 收到停止信号，退出自动交易主循环", "trader", at.name)
 			return nil
 		}
 	}
@@ -321,22 +598,42 @@ func (at *AutoTrader) Run() error {
 
 // Stop 停止自动交易
 func (at *AutoTrader) Stop() {
-	if !at.isRunning {
+	// 优雅退出中由 Shutdown 负责停止；与 Shutdown 并发时只有先关闭停止信号的一方继续
+	if !at.isRunning || at.draining.Load() || !at.closeStopMonitor() {
 		return
 	}
 	at.isRunning = false
-	close(at.stopMonitorCh) // 通知监控goroutine停止
-	at.monitorWg.Wait()     // 等待监控goroutine结束
-	log.Println("⏹ 自动交易系统停止")
+	at.cancelRun()      // 中止进行中的交易所请求
+	at.monitorWg.Wait() // 等待监控goroutine结束
+	log.Info("⏹ 自动交易系统停止")
+}
+
+// closeStopMonitor 关闭 stopMonitorCh 通知主循环和监控goroutine停止，返回是否由本次调用关闭
+func (at *AutoTrader) closeStopMonitor() bool {
+	closed := false
+	at.stopMonitorOnce.Do(func() {
+		close(at.stopMonitorCh)
+		closed = true
+	})
+	return closed
+}
+
+// ctx 交易所调用使用的 ctx（未运行时为 Background，停止后的手动平仓等操作不受影响）
+func (at *AutoTrader) ctx() context.Context {
+	if at.runCtx == nil || !at.isRunning {
+		return context.Background()
+	}
+	return at.runCtx
 }
 
 // runCycle 运行一个交易周期（使用AI全权决策）
 func (at *AutoTrader) runCycle() error {
+	if at.draining.Load() {
+		return nil
+	}
 	at.callCount++
 
-	log.Print("\n" + strings.Repeat("=", 70) + "\n")
-	log.Printf("⏰ %s - AI决策周期 #%d", time.Now().Format("2006-01-02 15:04:05"), at.callCount)
-	log.Println(strings.Repeat("=", 70))
+	log.Info("⏰ AI决策周期开始", "trader", at.name, "cycle", at.callCount)
 
 	// 创建决策记录
 	record := &logger.DecisionRecord{
@@ -346,20 +643,40 @@ func (at *AutoTrader) runCycle() error {
 	}
 
 	// 1. 检查是否需要停止交易
-	if time.Now().Before(at.stopUntil) {
-		remaining := at.stopUntil.Sub(time.Now())
-		log.Printf("⏸ 风险控制：暂停交易中，剩余 %.0f 分钟", remaining.Minutes())
+	if stopUntil := at.riskStopUntil(); time.Now().Before(stopUntil) {
+		remaining := time.Until(stopUntil)
+		log.Info("⏸ 风险控制：暂停交易中", "remaining_min", remaining.Minutes())
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("风险控制暂停中，剩余 %.0f 分钟", remaining.Minutes())
 		at.decisionLogger.LogDecision(record)
 		return nil
 	}
 
+	// 人工暂停（控制接口 /pause、/killswitch）
+	if pause := at.GetPauseState(); pause.Paused {
+		log.Info("⏸ 交易已人工暂停", "trader", at.name, "reason", pause.Reason)
+		record.Success = false
+		record.ErrorMessage = "交易已人工暂停: " + pause.Reason
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
+
+	// 启动对账发现无法修复的不一致：确认前不交易
+	if at.reconciliationHalted() {
+		log.Info("⏸ 启动对账发现异常，等待确认后恢复交易", "trader", at.name)
+		record.Success = false
+		record.ErrorMessage = "启动对账发现异常，等待确认后恢复交易"
+		at.decisionLogger.LogDecision(record)
+		return nil
+	}
+
 	// 2. 重置日盈亏基线（每天一次）
 	at.maybeResetDailyMetrics()
 
 	// 4. 收集交易上下文
+	cycleStart := time.Now()
 	ctx, err := at.buildTradingContext()
+	observeStage(at.name, metrics.StageContext, time.Since(cycleStart))
 	if err != nil {
 		record.Success = false
 		record.ErrorMessage = fmt.Sprintf("构建交易上下文失败: %v", err)
@@ -396,7 +713,7 @@ func (at *AutoTrader) runCycle() error {
 		record.Success = false
 		record.ErrorMessage = reason
 		at.decisionLogger.LogDecision(record)
-		log.Printf("⛔ 风险控制触发，暂停交易：%s | 恢复时间: %s", reason, at.stopUntil.Format(time.RFC3339))
+		log.Warn("⛔ 风险控制触发", "reason", reason, "resume_at", at.riskStopUntil().Format(time.RFC3339))
 		return nil
 	}
 
@@ -405,9 +722,10 @@ func (at *AutoTrader) runCycle() error {
 	if len(closedPositions) > 0 {
 		autoCloseActions := at.generateAutoCloseActions(closedPositions)
 		record.Decisions = append(record.Decisions, autoCloseActions...)
-		log.Printf("🔔 检测到 %d 个被动平仓", len(closedPositions))
+		log.Info("🔔 检测到被动平仓", "count", len(closedPositions))
 		for i, closed := range closedPositions {
 			action := autoCloseActions[i]
+			at.recordPassiveClose(closed, action)
 			pnl := closed.Quantity * (closed.MarkPrice - closed.EntryPrice)
 			if closed.Side == "short" {
 				pnl = -pnl
@@ -426,36 +744,43 @@ func (at *AutoTrader) runCycle() error {
 				reasonCN = action.Error
 			}
 
-			log.Printf("   └─ %s %s | 开仓: %.4f → 平仓: %.4f | 盈亏: %+.2f%% | 原因: %s",
-				closed.Symbol,
-				closed.Side,
-				closed.EntryPrice,
-				action.Price, // 使用推断的平仓价格
-				pnlPct,
-				reasonCN)
+			log.Info("   └─ 被动平仓", "symbol", closed.Symbol, "side", closed.Side, "entry_price", closed.EntryPrice, "close_price", action.Price, "pnl_pct", pnlPct, "reason", reasonCN)
 		}
 	}
 
-	log.Print(strings.Repeat("=", 70))
+	// 超过最长持仓时间的持仓（告警或平仓，已平仓的不再交给AI决策）
+	if agingActions := at.enforceMaxHolding(ctx, time.Now()); len(agingActions) > 0 {
+		record.Decisions = append(record.Decisions, agingActions...)
+	}
+
 	for _, coin := range ctx.CandidateCoins {
 		record.CandidateCoins = append(record.CandidateCoins, coin.Symbol)
 	}
 
-	log.Printf("📊 账户净值: %.2f USDT | 可用: %.2f USDT | 持仓: %d",
-		ctx.Account.TotalEquity, ctx.Account.AvailableBalance, ctx.Account.PositionCount)
+	log.Info("📊 账户净值", "total_equity", ctx.Account.TotalEquity, "available_balance", ctx.Account.AvailableBalance, "position_count", ctx.Account.PositionCount)
 
 	// 5. 调用AI获取完整决策
-	log.Printf("🤖 正在请求AI分析并决策... [模板: %s]", at.systemPromptTemplate)
-	decision, err := decision.GetFullDecisionWithCustomPrompt(ctx, at.mcpClient, at.customPrompt, at.overrideBasePrompt, at.systemPromptTemplate)
+	provider := at.decisionProvider
+	if provider == nil {
+		provider = llmProvider{at: at}
+	}
+	log.Info("🤖 正在请求AI分析并决策...", "template", at.systemPromptTemplate, "source", provider.Name())
+	decideStart := time.Now()
+	decision, err := provider.Decide(ctx)
+	decideElapsed := time.Since(decideStart) // 含行情获取，有 AI 调用耗时时改用 AI 调用耗时
 
 	if decision != nil && decision.AIRequestDurationMs > 0 {
 		record.AIRequestDurationMs = decision.AIRequestDurationMs
-		log.Printf("⏱️ AI调用耗时: %.2f 秒", float64(record.AIRequestDurationMs)/1000)
+		decideElapsed = time.Duration(decision.AIRequestDurationMs) * time.Millisecond
+		log.Info("⏱️ AI调用耗时", "ai_duration_sec", float64(record.AIRequestDurationMs)/1000)
 		record.ExecutionLog = append(record.ExecutionLog,
 			fmt.Sprintf("AI调用耗时: %d ms", record.AIRequestDurationMs))
 	}
 
+	observeStage(at.name, metrics.StageDecision, decideElapsed)
+
 	// 即使有错误，也保存思维链、决策和输入prompt（用于debug）
+	record.Inputs = buildInputSnapshot(ctx, provider.Name(), decision, err)
 	if decision != nil {
 		record.SystemPrompt = decision.SystemPrompt // 保存系统提示词
 		record.InputPrompt = decision.UserPrompt
@@ -472,18 +797,9 @@ func (at *AutoTrader) runCycle() error {
 
 		// 打印系统提示词和AI思维链（即使有错误，也要输出以便调试）
 		if decision != nil {
-			log.Print("\n" + strings.Repeat("=", 70) + "\n")
-			log.Printf("📋 系统提示词 [模板: %s] (错误情况)", at.systemPromptTemplate)
-			log.Println(strings.Repeat("=", 70))
-			log.Println(decision.SystemPrompt)
-			log.Println(strings.Repeat("=", 70))
-
+			log.Info("📋 系统提示词（错误情况）", "template", at.systemPromptTemplate, "prompt", decision.SystemPrompt)
 			if decision.CoTTrace != "" {
-				log.Print("\n" + strings.Repeat("-", 70) + "\n")
-				log.Println("💭 AI思维链分析（错误情况）:")
-				log.Println(strings.Repeat("-", 70))
-				log.Println(decision.CoTTrace)
-				log.Println(strings.Repeat("-", 70))
+				log.Info("💭 AI思维链分析（错误情况）", "cot", decision.CoTTrace)
 			}
 		}
 
@@ -491,75 +807,25 @@ func (at *AutoTrader) runCycle() error {
 		return fmt.Errorf("获取AI决策失败: %w", err)
 	}
 
-	// // 5. 打印系统提示词
-	// log.Printf("\n" + strings.Repeat("=", 70))
-	// log.Printf("📋 系统提示词 [模板: %s]", at.systemPromptTemplate)
-	// log.Println(strings.Repeat("=", 70))
-	// log.Println(decision.SystemPrompt)
-	// log.Printf(strings.Repeat("=", 70) + "\n")
-
-	// 6. 打印AI思维链
-	// log.Printf("\n" + strings.Repeat("-", 70))
-	// log.Println("💭 AI思维链分析:")
-	// log.Println(strings.Repeat("-", 70))
-	// log.Println(decision.CoTTrace)
-	// log.Printf(strings.Repeat("-", 70) + "\n")
-
-	// 7. 打印AI决策
-	// log.Printf("📋 AI决策列表 (%d 个):\n", len(decision.Decisions))
-	// for i, d := range decision.Decisions {
-	//     log.Printf("  [%d] %s: %s - %s", i+1, d.Symbol, d.Action, d.Reasoning)
-	//     if d.Action == "open_long" || d.Action == "open_short" {
-	//        log.Printf("      杠杆: %dx | 仓位: %.2f USDT | 止损: %.4f | 止盈: %.4f",
-	//           d.Leverage, d.PositionSizeUSD, d.StopLoss, d.TakeProfit)
-	//     }
-	// }
-	log.Println()
-	log.Print(strings.Repeat("-", 70))
-	// 8. 对决策排序：确保先平仓后开仓（防止仓位叠加超限）
-	log.Print(strings.Repeat("-", 70))
-
 	// 8. 对决策排序：确保先平仓后开仓（防止仓位叠加超限）
+	tagDecisions(decision.Decisions, provider.Name())
 	sortedDecisions := sortDecisionsByPriority(decision.Decisions)
 
-	log.Println("🔄 执行顺序（已优化）: 先平仓→后开仓")
+	log.Info("🔄 执行顺序（已优化）: 先平仓→后开仓")
 	for i, d := range sortedDecisions {
-		log.Printf("  [%d] %s %s", i+1, d.Symbol, d.Action)
+		log.Info("  决策", "index", i+1, "symbol", d.Symbol, "action", d.Action)
 	}
-	log.Println()
 
 	// 执行决策并记录结果
-	for _, d := range sortedDecisions {
-		actionRecord := logger.DecisionAction{
-			Action:    d.Action,
-			Symbol:    d.Symbol,
-			Quantity:  0,
-			Leverage:  d.Leverage,
-			Price:     0,
-			Timestamp: time.Now(),
-			Success:   false,
-		}
-
-		if err := at.executeDecisionWithRecord(&d, &actionRecord); err != nil {
-			log.Printf("❌ 执行决策失败 (%s %s): %v", d.Symbol, d.Action, err)
-			actionRecord.Error = err.Error()
-			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, err))
-		} else {
-			actionRecord.Success = true
-			record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action))
-			// 成功执行后短暂延迟
-			time.Sleep(1 * time.Second)
-		}
-
-		record.Decisions = append(record.Decisions, actionRecord)
-	}
+	at.executeDecisions(sortedDecisions, record)
+	observeStage(at.name, metrics.StageCycle, time.Since(cycleStart))
 
 	// 9. 更新持仓快照（用于下一周期检测被动平仓）
 	at.updatePositionSnapshot(ctx.Positions)
 
 	// 10. 保存决策记录
 	if err := at.decisionLogger.LogDecision(record); err != nil {
-		log.Printf("⚠ 保存决策记录失败: %v", err)
+		log.Warn("⚠ 保存决策记录失败", "error", err)
 	}
 
 	return nil
@@ -573,27 +839,28 @@ func (at *AutoTrader) maybeResetDailyMetrics() {
 		at.dailyPnLBase = 0
 		at.needsDailyBaseline = true
 		at.lastResetTime = now
-		log.Println("📅 日盈亏已重置，等待新的基准净值")
+		log.Info("📅 日盈亏已重置，等待新的基准净值")
 	}
 }
 
 func (at *AutoTrader) enforceRiskLimits(currentEquity float64) (string, bool) {
 	at.updatePnLMetrics(currentEquity)
 
-	if limit := at.config.MaxDailyLoss; limit > 0 && at.dailyPnLBase > 0 {
+	maxDailyLoss, maxDrawdown, _ := at.getRiskLimits()
+	if limit := maxDailyLoss; limit > 0 && at.dailyPnLBase > 0 {
 		maxLoss := -at.dailyPnLBase * limit / 100
 		if at.dailyPnL <= maxLoss {
 			reason := fmt.Sprintf("触发当日最大亏损 %.2f%% (盈亏 %.2f / 基准 %.2f USDT)", limit, at.dailyPnL, at.dailyPnLBase)
-			at.activateRiskStop()
+			at.activateRiskStop(reason)
 			return reason, true
 		}
 	}
 
-	if dd := at.config.MaxDrawdown; dd > 0 && at.peakEquity > 0 {
+	if dd := maxDrawdown; dd > 0 && at.peakEquity > 0 {
 		drawdownPct := (at.peakEquity - currentEquity) / at.peakEquity * 100
 		if drawdownPct >= dd {
 			reason := fmt.Sprintf("触发账户回撤 %.2f%% (峰值 %.2f → 当前 %.2f)", drawdownPct, at.peakEquity, currentEquity)
-			at.activateRiskStop()
+			at.activateRiskStop(reason)
 			return reason, true
 		}
 	}
@@ -606,7 +873,7 @@ func (at *AutoTrader) updatePnLMetrics(currentEquity float64) {
 		at.dailyPnLBase = currentEquity
 		at.dailyPnL = 0
 		at.needsDailyBaseline = false
-		log.Printf("📊 日盈亏基准同步：%.2f USDT", currentEquity)
+		log.Info("📊 日盈亏基准同步", "current_equity", currentEquity)
 	} else {
 		at.dailyPnL = currentEquity - at.dailyPnLBase
 	}
@@ -616,19 +883,30 @@ func (at *AutoTrader) updatePnLMetrics(currentEquity float64) {
 	}
 }
 
-func (at *AutoTrader) activateRiskStop() {
-	pause := at.config.StopTradingTime
+func (at *AutoTrader) activateRiskStop(reason string) {
+	_, _, pause := at.getRiskLimits()
 	if pause <= 0 {
 		pause = 60 * time.Minute
 	}
-	at.stopUntil = time.Now().Add(pause)
-	log.Printf("⚠️ 触发风险暂停，暂停时长: %v，恢复时间: %s", pause, at.stopUntil.Format(time.RFC3339))
+	stopUntil := time.Now().Add(pause)
+	at.stopUntilMutex.Lock()
+	at.stopUntil = stopUntil
+	at.stopUntilMutex.Unlock()
+	eventbus.Publish(eventbus.RiskTripped{Source: at.id, Reason: reason, Blocking: true, Time: time.Now()})
+	at.notify(AlertSeverityWarning, "触发风险暂停", "%s，暂停时长: %v，恢复时间: %s", reason, pause, stopUntil.Format(time.RFC3339))
+}
+
+// riskStopUntil 风险暂停的恢复时间（零值=未暂停）
+func (at *AutoTrader) riskStopUntil() time.Time {
+	at.stopUntilMutex.Lock()
+	defer at.stopUntilMutex.Unlock()
+	return at.stopUntil
 }
 
 // buildTradingContext 构建交易上下文
 func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	// 1. 获取账户信息
-	balance, err := at.trader.GetBalance()
+	balance, err := at.trader.GetBalance(at.ctx())
 	if err != nil {
 		return nil, fmt.Errorf("获取账户余额失败: %w", err)
 	}
@@ -652,7 +930,7 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	totalEquity := totalWalletBalance + totalUnrealizedProfit
 
 	// 2. 获取持仓信息
-	positions, err := at.trader.GetPositions()
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -695,21 +973,22 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		// 跟踪持仓首次出现时间
 		posKey := symbol + "_" + side
 		currentPositionKeys[posKey] = true
+		at.positionMutex.Lock()
 		if _, exists := at.positionFirstSeenTime[posKey]; !exists {
 			// 新持仓，记录当前时间
 			at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
 		}
 		updateTime := at.positionFirstSeenTime[posKey]
+		// 获取止损止盈价格（用于后续推断平仓原因）
+		stopLoss := at.positionStopLoss[posKey]
+		takeProfit := at.positionTakeProfit[posKey]
+		at.positionMutex.Unlock()
 
 		// 获取该持仓的历史最高收益率
 		at.peakPnLCacheMutex.RLock()
 		peakPnlPct := at.peakPnLCache[posKey]
 		at.peakPnLCacheMutex.RUnlock()
 
-		// 获取止损止盈价格（用于后续推断平仓原因）
-		stopLoss := at.positionStopLoss[posKey]
-		takeProfit := at.positionTakeProfit[posKey]
-
 		positionInfos = append(positionInfos, decision.PositionInfo{
 			Symbol:           symbol,
 			Side:             side,
@@ -729,19 +1008,29 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	}
 
 	// 清理已平仓的持仓记录（包括止损止盈记录）
+	var closedKeys []string
+	at.positionMutex.Lock()
 	for key := range at.positionFirstSeenTime {
 		if !currentPositionKeys[key] {
+			closedKeys = append(closedKeys, key)
 			delete(at.positionFirstSeenTime, key)
 			delete(at.positionStopLoss, key)
 			delete(at.positionTakeProfit, key)
 		}
 	}
+	at.positionMutex.Unlock()
+	for _, key := range closedKeys {
+		at.clearSyntheticStop(key)
+		at.clearScaleOut(key)
+		at.clearPyramid(key)
+	}
 
 	// 3. 获取交易员的候选币种池
 	candidateCoins, err := at.getCandidateCoins()
 	if err != nil {
 		return nil, fmt.Errorf("获取候选币种失败: %w", err)
 	}
+	candidateCoins = at.excludeDelisting(candidateCoins)
 
 	// 4. 计算总盈亏
 	totalPnL := totalEquity - at.initialBalance
@@ -759,31 +1048,32 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 	// 假设每3分钟一个周期，100个周期 = 5小时，足够覆盖大部分交易
 	performance, err := at.decisionLogger.AnalyzePerformance(100)
 	if err != nil {
-		log.Printf("⚠️  分析历史表现失败: %v", err)
+		log.Warn("⚠️ 分析历史表现失败", "error", err)
 		// 不影响主流程，继续执行（但设置performance为nil以避免传递错误数据）
 		performance = nil
 	}
 
 	// 6. Fetch open orders for AI decision context to prevent duplicate orders
-	openOrders, err := at.trader.GetOpenOrders("")
+	openOrders, err := at.trader.GetOpenOrders(at.ctx(), "")
 	if err != nil {
-		log.Printf("⚠️  Failed to fetch open orders: %v (continuing execution, but AI won't see order status)", err)
+		log.Warn("⚠️ Failed to fetch open orders (continuing execution, but AI won't see order status)", "error", err)
 		// Don't block main flow, use empty list
 		openOrders = []decision.OpenOrderInfo{}
 	} else {
-		log.Printf("  ✓ Fetched %d open orders", len(openOrders))
+		log.Info("  ✓ Fetched open orders", "count", len(openOrders))
 	}
 
 	// 7. Build context
+	btcEthLeverage, altcoinLeverage := at.getLeverages()
 	ctx := &decision.Context{
 		CurrentTime:     time.Now().Format("2006-01-02 15:04:05"),
 		RuntimeMinutes:  int(time.Since(at.startTime).Minutes()),
 		CallCount:       at.callCount,
-		BTCETHLeverage:  at.config.BTCETHLeverage,  // 使用配置的杠杆倍数
-		AltcoinLeverage: at.config.AltcoinLeverage, // 使用配置的杠杆倍数
-		TakerFeeRate:    at.config.TakerFeeRate,    // Use configured taker fee rate
-		MakerFeeRate:    at.config.MakerFeeRate,    // Use configured maker fee rate
-		Timeframes:      at.timeframes,             // K线时间线配置
+		BTCETHLeverage:  btcEthLeverage,         // 使用配置的杠杆倍数
+		AltcoinLeverage: altcoinLeverage,        // 使用配置的杠杆倍数
+		TakerFeeRate:    at.config.TakerFeeRate, // Use configured taker fee rate
+		MakerFeeRate:    at.config.MakerFeeRate, // Use configured maker fee rate
+		Timeframes:      at.timeframes,          // K线时间线配置
 		Account: decision.AccountInfo{
 			TotalEquity:      totalEquity,
 			AvailableBalance: availableBalance,
@@ -799,12 +1089,32 @@ func (at *AutoTrader) buildTradingContext() (*decision.Context, error) {
 		CandidateCoins: candidateCoins,
 		Performance:    performance, // 添加历史表现分析（包含 RecentTrades 用于 AI 学习）
 	}
+	at.recordAccountMetrics(ctx.Account)
 
 	return ctx, nil
 }
 
 // executeDecisionWithRecord 执行AI决策并记录详细信息
 func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
+	if actionRecord.TradeID == "" {
+		actionRecord.TradeID = logging.NewTradeID()
+	}
+
+	// 资金费率过滤（可能否决开仓或将其反向）
+	if decision.Action == "open_long" || decision.Action == "open_short" {
+		if err := at.checkDelistingEntry(decision.Symbol); err != nil {
+			return err
+		}
+		if err := at.applyFundingFilter(decision); err != nil {
+			return err
+		}
+		actionRecord.Action = decision.Action
+	}
+
+	if decision.Action != "hold" && decision.Action != "wait" {
+		at.tradeLog(actionRecord).Info("▶️ 执行决策")
+	}
+
 	switch decision.Action {
 	case "open_long":
 		return at.executeOpenLongWithRecord(decision, actionRecord)
@@ -828,16 +1138,40 @@ func (at *AutoTrader) executeDecisionWithRecord(decision *decision.Decision, act
 	}
 }
 
+// tradeLog 单笔交易的日志器（携带 trade_id，同一笔交易的全部日志可按该ID检索）
+func (at *AutoTrader) tradeLog(actionRecord *logger.DecisionAction) *logging.Logger {
+	return log.With("trade_id", actionRecord.TradeID, "trader_id", at.id,
+		"symbol", actionRecord.Symbol, "action", actionRecord.Action)
+}
+
 // executeOpenLongWithRecord 执行开多仓并记录详细信息
 func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📈 开多仓: %s", decision.Symbol)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  📈 开多仓")
+	riskStart := time.Now()
 
-	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	positions, err := at.trader.GetPositions()
+	// 收盘平仓后当天不再开仓
+	if err := at.checkDailyFlattenWindow(time.Now()); err != nil {
+		return err
+	}
+
+	// 交易时段之外、事件禁开仓窗口内不开新仓（或缩小开仓金额）
+	if err := at.checkTradingSchedule(decision, time.Now()); err != nil {
+		return err
+	}
+
+	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限），开启加仓时除外
+	var existingQty float64
+	var pyramid *pyramidPlan
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err == nil {
-		for _, pos := range positions {
-			if pos["symbol"] == decision.Symbol && pos["side"] == "long" {
-				return fmt.Errorf("❌ %s 已有多仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_long 决策", decision.Symbol)
+		if existingQty, err = at.checkScaleIn(positions, decision.Symbol, "long", actionRecord); err != nil {
+			return err
+		}
+		// 🔺 顺势加仓：浮盈达到阈值、未超过批次上限才允许加仓，本批金额按比例递减
+		if actionRecord.ScaleIn {
+			if pyramid, err = at.planPyramid(positions, decision, "long"); err != nil {
+				return err
 			}
 		}
 	}
@@ -847,22 +1181,11 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 	if err != nil {
 		return err
 	}
+	pricedAt := priceClock()
 
-	// 🔍 价格一致性验证（防止单交易所价格异常导致误判）
-	if market.WSMonitorCli != nil && market.WSMonitorCli.GetDSManager() != nil {
-		consistent, prices, err := market.WSMonitorCli.GetDSManager().VerifyPriceConsistency(decision.Symbol, 0.02) // 2% 偏差阈值
-		if err != nil {
-			log.Printf("⚠️  %s 价格验证失败（数据源不足），继续交易: %v", decision.Symbol, err)
-		} else if !consistent {
-			priceDetails := ""
-			for source, price := range prices {
-				priceDetails += fmt.Sprintf("%s: %.2f, ", source, price)
-			}
-			return fmt.Errorf("❌ 价格异常：%s 在多个数据源间偏差过大（>2%%），拒绝开仓以防止误判。价格: %s",
-				decision.Symbol, priceDetails)
-		} else {
-			log.Printf("✅ %s 价格验证通过（多数据源一致性检查）", decision.Symbol)
-		}
+	// 🔍 多数据源交叉校验决策价格（防止单个数据源价格过期或脱锚导致误判）
+	if err := at.checkPriceSanity(decision.Symbol, marketData.CurrentPrice, tl); err != nil {
+		return err
 	}
 
 	// 计算数量
@@ -873,7 +1196,7 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 	// ⚠️ 保证金验证：防止保证金不足错误（code=-2019）
 	requiredMargin := decision.PositionSizeUSD / float64(decision.Leverage)
 
-	balance, err := at.trader.GetBalance()
+	balance, err := at.trader.GetBalance(at.ctx())
 	if err != nil {
 		return fmt.Errorf("获取账户余额失败: %w", err)
 	}
@@ -891,6 +1214,15 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 			totalRequired, requiredMargin, estimatedFee, availableBalance)
 	}
 
+	// 🔗 组合级相关性敞口检查（防止多个高度相关的同向仓位叠加成一笔巨大押注）
+	equity, _ := ParseTotalEquity(balance, "")
+	if err := at.checkCorrelationExposure(decision.Symbol, "long", decision.PositionSizeUSD, positions, equity); err != nil {
+		return err
+	}
+
+	// 📐 按币种分类的出场模板覆盖止损/止盈
+	at.applyBracketTemplate(decision, marketData.CurrentPrice)
+
 	// ⚡ 严格验证止损/止盈价格（防止开仓后无法设置保护，导致仓位风险）
 	// 修复 Issue: 开仓成功但止损/止盈设置失败，仓位失去保护
 	if decision.StopLoss <= 0 || decision.TakeProfit <= 0 {
@@ -914,39 +1246,114 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 			decision.TakeProfit, marketData.CurrentPrice, priceGapPct, marketData.CurrentPrice*1.02)
 	}
 
+	// ⏳ 延迟确认：等待后用最新行情重新验证信号，并按最新价格重算数量
+	confirmStart := time.Now()
+	confirmedPrice, err := at.confirmEntrySignal(decision, marketData.CurrentPrice)
+	confirmWait := time.Since(confirmStart) // 延迟确认的等待不计入风控耗时
+	if err != nil {
+		return err
+	}
+	if confirmedPrice != marketData.CurrentPrice {
+		quantity = decision.PositionSizeUSD / confirmedPrice
+		actionRecord.Quantity = quantity
+		actionRecord.Price = confirmedPrice
+		pricedAt = priceClock()
+	}
+
 	// 设置仓位模式
-	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
-		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+	if err := at.trader.SetMarginMode(at.ctx(), decision.Symbol, at.config.IsCrossMargin); err != nil {
+		tl.Warn("  ⚠️ 设置仓位模式失败", "error", err)
 		// 继续执行，不影响交易
 	}
 
+	// ⏱️ 下单前价格时效检查：价格过期则刷新并按最新价格重算数量
+	freshPrice, err := at.refreshStalePrice(decision, confirmedPrice, pricedAt)
+	if err != nil {
+		return err
+	}
+	if freshPrice != confirmedPrice {
+		confirmedPrice = freshPrice
+		quantity = decision.PositionSizeUSD / confirmedPrice
+		actionRecord.Quantity = quantity
+		actionRecord.Price = confirmedPrice
+	}
+
+	// 📏 下单前校验最小下单量（不足时直接给出最小可下单量，不浪费交易周期）
+	if err := at.preflightOrder(decision.Symbol, quantity, confirmedPrice, decision.Leverage, availableBalance); err != nil {
+		return err
+	}
+
+	// 并发开仓：扣除本批其他开仓已占用的保证金和相关性分组敞口
+	if err := at.reserveMargin(decision.Symbol, totalRequired, availableBalance); err != nil {
+		return err
+	}
+	if err := at.reserveCorrelation(decision.Symbol, "long", decision.PositionSizeUSD, positions, equity); err != nil {
+		at.releaseMargin(totalRequired)
+		return err
+	}
+
+	observeStage(at.name, metrics.StageRisk, time.Since(riskStart)-confirmWait)
+
 	// 开仓
-	order, err := at.trader.OpenLong(decision.Symbol, quantity, decision.Leverage)
+	order, err := at.trader.OpenLong(at.orderCtx(decision), decision.Symbol, quantity, decision.Leverage)
 	if err != nil {
+		at.releaseMargin(totalRequired)
+		at.releaseCorrelation(decision.Symbol, "long", decision.PositionSizeUSD)
 		return err
 	}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	// 记录订单ID及实际成交数据
+	order.applyToAction(actionRecord)
+
+	tl.Info("  ✓ 开仓成功", "order_id", order.OrderID, "quantity", quantity)
+	if order.IsFilled() {
+		nf := at.config.NumberFormat
+		tl.Info("  ✓ 已成交", "avg_price", nf.Price(order.AvgPrice), "filled_qty", nf.Quantity(order.FilledQty), "fee", nf.Quantity(order.Fee), "fee_asset", order.FeeAsset)
 	}
 
-	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
+	// 🎯 止损/止盈按实际成交均价重新锚定（而非下单前的行情价）
+	anchorBracketToFill(decision, confirmedPrice, order)
+
+	entryPrice := confirmedPrice
+	if order.IsFilled() && order.AvgPrice > 0 {
+		entryPrice = order.AvgPrice
+	}
 
 	// 记录开仓时间
 	posKey := decision.Symbol + "_long"
-	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
-
-	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "LONG", quantity, decision.StopLoss); err != nil {
-		log.Printf("  ⚠ 设置止损失败: %v", err)
+	protectQty := quantity
+	if actionRecord.ScaleIn {
+		// 加仓：保留首次开仓时间，止损/止盈按合并后的总数量重新设置
+		protectQty = at.replaceProtectionForScaleIn(decision.Symbol, existingQty, quantity)
+		at.clearScaleOut(posKey)
+		at.applyPyramidStop(pyramid, decision, quantity, entryPrice)
 	} else {
-		at.positionStopLoss[posKey] = decision.StopLoss // 记录止损价格
+		at.markPositionOpened(posKey)
+		at.resetTakeProfitLadder(posKey)
+		at.startPyramid(posKey, entryPrice, decision.StopLoss, decision.PositionSizeUSD)
+	}
+
+	// 设置止损（失败时按保护单失败策略处理）
+	switch at.protectNewPosition(decision.Symbol, "long", protectQty, decision.StopLoss) {
+	case protectionFlattened:
+		return fmt.Errorf("❌ %s 止损单设置失败，已按策略平仓", decision.Symbol)
+	case protectionPlaced, protectionSynthetic:
+		at.setPositionStopLoss(posKey, decision.StopLoss) // 记录止损价格
 	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "LONG", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ 设置止盈失败: %v", err)
+
+	// 分批止盈（加仓后改用单一止盈）
+	if !actionRecord.ScaleIn {
+		if lastTarget, ok := at.placeScaleOut(decision.Symbol, "long", entryPrice, decision.StopLoss, protectQty); ok {
+			at.setPositionTakeProfit(posKey, lastTarget)
+			return nil
+		}
+	}
+
+	// 设置止盈
+	if err := at.trader.SetTakeProfit(at.ctx(), decision.Symbol, "LONG", protectQty, decision.TakeProfit); err != nil {
+		tl.Warn("  ⚠ 设置止盈失败", "error", err)
 	} else {
-		at.positionTakeProfit[posKey] = decision.TakeProfit // 记录止盈价格
+		at.setPositionTakeProfit(posKey, decision.TakeProfit) // 记录止盈价格
 	}
 
 	return nil
@@ -954,14 +1361,32 @@ func (at *AutoTrader) executeOpenLongWithRecord(decision *decision.Decision, act
 
 // executeOpenShortWithRecord 执行开空仓并记录详细信息
 func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📉 开空仓: %s", decision.Symbol)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  📉 开空仓")
+	riskStart := time.Now()
+
+	// 收盘平仓后当天不再开仓
+	if err := at.checkDailyFlattenWindow(time.Now()); err != nil {
+		return err
+	}
 
-	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限）
-	positions, err := at.trader.GetPositions()
+	// 交易时段之外、事件禁开仓窗口内不开新仓（或缩小开仓金额）
+	if err := at.checkTradingSchedule(decision, time.Now()); err != nil {
+		return err
+	}
+
+	// ⚠️ 关键：检查是否已有同币种同方向持仓，如果有则拒绝开仓（防止仓位叠加超限），开启加仓时除外
+	var existingQty float64
+	var pyramid *pyramidPlan
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err == nil {
-		for _, pos := range positions {
-			if pos["symbol"] == decision.Symbol && pos["side"] == "short" {
-				return fmt.Errorf("❌ %s 已有空仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_short 决策", decision.Symbol)
+		if existingQty, err = at.checkScaleIn(positions, decision.Symbol, "short", actionRecord); err != nil {
+			return err
+		}
+		// 🔺 顺势加仓：浮盈达到阈值、未超过批次上限才允许加仓，本批金额按比例递减
+		if actionRecord.ScaleIn {
+			if pyramid, err = at.planPyramid(positions, decision, "short"); err != nil {
+				return err
 			}
 		}
 	}
@@ -971,22 +1396,11 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 	if err != nil {
 		return err
 	}
+	pricedAt := priceClock()
 
-	// 🔍 价格一致性验证（防止单交易所价格异常导致误判）
-	if market.WSMonitorCli != nil && market.WSMonitorCli.GetDSManager() != nil {
-		consistent, prices, err := market.WSMonitorCli.GetDSManager().VerifyPriceConsistency(decision.Symbol, 0.02) // 2% 偏差阈值
-		if err != nil {
-			log.Printf("⚠️  %s 价格验证失败（数据源不足），继续交易: %v", decision.Symbol, err)
-		} else if !consistent {
-			priceDetails := ""
-			for source, price := range prices {
-				priceDetails += fmt.Sprintf("%s: %.2f, ", source, price)
-			}
-			return fmt.Errorf("❌ 价格异常：%s 在多个数据源间偏差过大（>2%%），拒绝开仓以防止误判。价格: %s",
-				decision.Symbol, priceDetails)
-		} else {
-			log.Printf("✅ %s 价格验证通过（多数据源一致性检查）", decision.Symbol)
-		}
+	// 🔍 多数据源交叉校验决策价格（防止单个数据源价格过期或脱锚导致误判）
+	if err := at.checkPriceSanity(decision.Symbol, marketData.CurrentPrice, tl); err != nil {
+		return err
 	}
 
 	// 计算数量
@@ -997,7 +1411,7 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 	// ⚠️ 保证金验证：防止保证金不足错误（code=-2019）
 	requiredMargin := decision.PositionSizeUSD / float64(decision.Leverage)
 
-	balance, err := at.trader.GetBalance()
+	balance, err := at.trader.GetBalance(at.ctx())
 	if err != nil {
 		return fmt.Errorf("获取账户余额失败: %w", err)
 	}
@@ -1015,6 +1429,15 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 			totalRequired, requiredMargin, estimatedFee, availableBalance)
 	}
 
+	// 🔗 组合级相关性敞口检查（防止多个高度相关的同向仓位叠加成一笔巨大押注）
+	equity, _ := ParseTotalEquity(balance, "")
+	if err := at.checkCorrelationExposure(decision.Symbol, "short", decision.PositionSizeUSD, positions, equity); err != nil {
+		return err
+	}
+
+	// 📐 按币种分类的出场模板覆盖止损/止盈
+	at.applyBracketTemplate(decision, marketData.CurrentPrice)
+
 	// ⚡ 严格验证止损/止盈价格（防止开仓后无法设置保护，导致仓位风险）
 	// 修复 Issue: 开仓成功但止损/止盈设置失败，仓位失去保护
 	if decision.StopLoss <= 0 || decision.TakeProfit <= 0 {
@@ -1038,39 +1461,114 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 			decision.TakeProfit, marketData.CurrentPrice, priceGapPct, marketData.CurrentPrice*0.98)
 	}
 
+	// ⏳ 延迟确认：等待后用最新行情重新验证信号，并按最新价格重算数量
+	confirmStart := time.Now()
+	confirmedPrice, err := at.confirmEntrySignal(decision, marketData.CurrentPrice)
+	confirmWait := time.Since(confirmStart) // 延迟确认的等待不计入风控耗时
+	if err != nil {
+		return err
+	}
+	if confirmedPrice != marketData.CurrentPrice {
+		quantity = decision.PositionSizeUSD / confirmedPrice
+		actionRecord.Quantity = quantity
+		actionRecord.Price = confirmedPrice
+		pricedAt = priceClock()
+	}
+
 	// 设置仓位模式
-	if err := at.trader.SetMarginMode(decision.Symbol, at.config.IsCrossMargin); err != nil {
-		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+	if err := at.trader.SetMarginMode(at.ctx(), decision.Symbol, at.config.IsCrossMargin); err != nil {
+		tl.Warn("  ⚠️ 设置仓位模式失败", "error", err)
 		// 继续执行，不影响交易
 	}
 
+	// ⏱️ 下单前价格时效检查：价格过期则刷新并按最新价格重算数量
+	freshPrice, err := at.refreshStalePrice(decision, confirmedPrice, pricedAt)
+	if err != nil {
+		return err
+	}
+	if freshPrice != confirmedPrice {
+		confirmedPrice = freshPrice
+		quantity = decision.PositionSizeUSD / confirmedPrice
+		actionRecord.Quantity = quantity
+		actionRecord.Price = confirmedPrice
+	}
+
+	// 📏 下单前校验最小下单量（不足时直接给出最小可下单量，不浪费交易周期）
+	if err := at.preflightOrder(decision.Symbol, quantity, confirmedPrice, decision.Leverage, availableBalance); err != nil {
+		return err
+	}
+
+	// 并发开仓：扣除本批其他开仓已占用的保证金和相关性分组敞口
+	if err := at.reserveMargin(decision.Symbol, totalRequired, availableBalance); err != nil {
+		return err
+	}
+	if err := at.reserveCorrelation(decision.Symbol, "short", decision.PositionSizeUSD, positions, equity); err != nil {
+		at.releaseMargin(totalRequired)
+		return err
+	}
+
+	observeStage(at.name, metrics.StageRisk, time.Since(riskStart)-confirmWait)
+
 	// 开仓
-	order, err := at.trader.OpenShort(decision.Symbol, quantity, decision.Leverage)
+	order, err := at.trader.OpenShort(at.orderCtx(decision), decision.Symbol, quantity, decision.Leverage)
 	if err != nil {
+		at.releaseMargin(totalRequired)
+		at.releaseCorrelation(decision.Symbol, "short", decision.PositionSizeUSD)
 		return err
 	}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
+	// 记录订单ID及实际成交数据
+	order.applyToAction(actionRecord)
+
+	tl.Info("  ✓ 开仓成功", "order_id", order.OrderID, "quantity", quantity)
+	if order.IsFilled() {
+		nf := at.config.NumberFormat
+		tl.Info("  ✓ 已成交", "avg_price", nf.Price(order.AvgPrice), "filled_qty", nf.Quantity(order.FilledQty), "fee", nf.Quantity(order.Fee), "fee_asset", order.FeeAsset)
 	}
 
-	log.Printf("  ✓ 开仓成功，订单ID: %v, 数量: %.4f", order["orderId"], quantity)
+	// 🎯 止损/止盈按实际成交均价重新锚定（而非下单前的行情价）
+	anchorBracketToFill(decision, confirmedPrice, order)
+
+	entryPrice := confirmedPrice
+	if order.IsFilled() && order.AvgPrice > 0 {
+		entryPrice = order.AvgPrice
+	}
 
 	// 记录开仓时间
 	posKey := decision.Symbol + "_short"
-	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
-
-	// 设置止损止盈
-	if err := at.trader.SetStopLoss(decision.Symbol, "SHORT", quantity, decision.StopLoss); err != nil {
-		log.Printf("  ⚠ 设置止损失败: %v", err)
+	protectQty := quantity
+	if actionRecord.ScaleIn {
+		// 加仓：保留首次开仓时间，止损/止盈按合并后的总数量重新设置
+		protectQty = at.replaceProtectionForScaleIn(decision.Symbol, existingQty, quantity)
+		at.clearScaleOut(posKey)
+		at.applyPyramidStop(pyramid, decision, quantity, entryPrice)
 	} else {
-		at.positionStopLoss[posKey] = decision.StopLoss // 记录止损价格
+		at.markPositionOpened(posKey)
+		at.resetTakeProfitLadder(posKey)
+		at.startPyramid(posKey, entryPrice, decision.StopLoss, decision.PositionSizeUSD)
+	}
+
+	// 设置止损（失败时按保护单失败策略处理）
+	switch at.protectNewPosition(decision.Symbol, "short", protectQty, decision.StopLoss) {
+	case protectionFlattened:
+		return fmt.Errorf("❌ %s 止损单设置失败，已按策略平仓", decision.Symbol)
+	case protectionPlaced, protectionSynthetic:
+		at.setPositionStopLoss(posKey, decision.StopLoss) // 记录止损价格
+	}
+
+	// 分批止盈（加仓后改用单一止盈）
+	if !actionRecord.ScaleIn {
+		if lastTarget, ok := at.placeScaleOut(decision.Symbol, "short", entryPrice, decision.StopLoss, protectQty); ok {
+			at.setPositionTakeProfit(posKey, lastTarget)
+			return nil
+		}
 	}
-	if err := at.trader.SetTakeProfit(decision.Symbol, "SHORT", quantity, decision.TakeProfit); err != nil {
-		log.Printf("  ⚠ 设置止盈失败: %v", err)
+
+	// 设置止盈
+	if err := at.trader.SetTakeProfit(at.ctx(), decision.Symbol, "SHORT", protectQty, decision.TakeProfit); err != nil {
+		tl.Warn("  ⚠ 设置止盈失败", "error", err)
 	} else {
-		at.positionTakeProfit[posKey] = decision.TakeProfit // 记录止盈价格
+		at.setPositionTakeProfit(posKey, decision.TakeProfit) // 记录止盈价格
 	}
 
 	return nil
@@ -1078,7 +1576,8 @@ func (at *AutoTrader) executeOpenShortWithRecord(decision *decision.Decision, ac
 
 // executeCloseLongWithRecord 执行平多仓并记录详细信息
 func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 平多仓: %s", decision.Symbol)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  🔄 平多仓")
 
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol, at.timeframes)
@@ -1088,23 +1587,22 @@ func (at *AutoTrader) executeCloseLongWithRecord(decision *decision.Decision, ac
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 平仓
-	order, err := at.trader.CloseLong(decision.Symbol, 0) // 0 = 全部平仓
+	order, err := at.trader.CloseLong(at.orderCtx(decision), decision.Symbol, 0) // 0 = 全部平仓
 	if err != nil {
 		return err
 	}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	// 记录订单ID及实际成交数据
+	order.applyToAction(actionRecord)
 
-	log.Printf("  ✓ 平仓成功")
+	tl.Info("  ✓ 平仓成功", "order_id", order.OrderID)
 	return nil
 }
 
 // executeCloseShortWithRecord 执行平空仓并记录详细信息
 func (at *AutoTrader) executeCloseShortWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🔄 平空仓: %s", decision.Symbol)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  🔄 平空仓")
 
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol, at.timeframes)
@@ -1114,23 +1612,22 @@ func (at *AutoTrader) executeCloseShortWithRecord(decision *decision.Decision, a
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 平仓
-	order, err := at.trader.CloseShort(decision.Symbol, 0) // 0 = 全部平仓
+	order, err := at.trader.CloseShort(at.orderCtx(decision), decision.Symbol, 0) // 0 = 全部平仓
 	if err != nil {
 		return err
 	}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	// 记录订单ID及实际成交数据
+	order.applyToAction(actionRecord)
 
-	log.Printf("  ✓ 平仓成功")
+	tl.Info("  ✓ 平仓成功", "order_id", order.OrderID)
 	return nil
 }
 
 // executeUpdateStopLossWithRecord 执行调整止损并记录详细信息
 func (at *AutoTrader) executeUpdateStopLossWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🎯 调整止损: %s → %.2f", decision.Symbol, decision.NewStopLoss)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  🎯 调整止损", "new_stop_loss", decision.NewStopLoss)
 
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol, at.timeframes)
@@ -1140,7 +1637,7 @@ func (at *AutoTrader) executeUpdateStopLossWithRecord(decision *decision.Decisio
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 获取当前持仓
-	positions, err := at.trader.GetPositions()
+	positions, err := at.symbolPositions(decision.Symbol)
 	if err != nil {
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -1169,9 +1666,8 @@ func (at *AutoTrader) executeUpdateStopLossWithRecord(decision *decision.Decisio
 
 		if wasRecentlyOpen {
 			// 持仓刚刚消失，很可能是止损单已触发
-			log.Printf("  ℹ️  %s 持仓已平仓（止损单可能已触发），跳过止损调整", decision.Symbol)
-			log.Printf("  💡 提示：市价 %.2f，目标止损 %.2f - 交易所可能已在两次AI周期间执行止损",
-				marketData.CurrentPrice, decision.NewStopLoss)
+			tl.Info("  ℹ️ 持仓已平仓（止损单可能已触发），跳过止损调整")
+			tl.Info("  💡 提示：交易所可能已在两次AI周期间执行止损", "current_price", marketData.CurrentPrice, "new_stop_loss", decision.NewStopLoss)
 			return nil // 优雅返回，不抛错误
 		}
 
@@ -1183,6 +1679,7 @@ func (at *AutoTrader) executeUpdateStopLossWithRecord(decision *decision.Decisio
 	side, _ := targetPosition["side"].(string)
 	positionSide := strings.ToUpper(side)
 	positionAmt, _ := targetPosition["positionAmt"].(float64)
+	actionRecord.Side = side
 
 	// ⚡ 严格验证新止损价格合理性（防止 "Order would immediately trigger" 错误）
 	priceGap := 0.0
@@ -1217,35 +1714,36 @@ func (at *AutoTrader) executeUpdateStopLossWithRecord(decision *decision.Decisio
 	}
 
 	if hasOppositePosition {
-		log.Printf("  🚨 警告：检测到 %s 存在双向持仓（%s + %s），这违反了策略规则",
-			decision.Symbol, positionSide, oppositeSide)
-		log.Printf("  🚨 取消止损单将影响两个方向的订单，请检查是否为用户手动操作导致")
-		log.Printf("  🚨 建议：手动平掉其中一个方向的持仓，或检查系统是否有BUG")
+		tl.Error("  🚨 检测到双向持仓，取消止损单将影响两个方向的订单", "position_side", positionSide, "opposite_side", oppositeSide,
+			"hint", "手动平掉其中一个方向的持仓，或检查系统是否有BUG")
 	}
 
 	// 取消旧的止损单（只删除止损单，不影响止盈单）
 	// 注意：如果存在双向持仓，这会删除两个方向的止损单
 	// ✅ 修复 Issue #998: 必须成功取消旧单才能继续，防止重复挂单
-	if err := at.trader.CancelStopLossOrders(decision.Symbol); err != nil {
+	if err := at.trader.CancelStopLossOrders(at.ctx(), decision.Symbol); err != nil {
 		return fmt.Errorf("取消舊止損單失敗，中止操作以防止重複掛單 (Issue #998): %w", err)
 	}
 
-	log.Printf("  ✓ 已取消舊止損單，準備設置新止損")
+	tl.Info("  ✓ 已取消舊止損單，準備設置新止損")
 
 	// 调用交易所 API 修改止损
 	quantity := math.Abs(positionAmt)
-	err = at.trader.SetStopLoss(decision.Symbol, positionSide, quantity, decision.NewStopLoss)
+	err = at.trader.SetStopLoss(at.ctx(), decision.Symbol, positionSide, quantity, decision.NewStopLoss)
 	if err != nil {
 		return fmt.Errorf("修改止损失败: %w", err)
 	}
 
-	log.Printf("  ✓ 止损已调整: %.2f (当前价格: %.2f)", decision.NewStopLoss, marketData.CurrentPrice)
+	at.clearSyntheticStop(decision.Symbol + "_" + side)
+
+	tl.Info("  ✓ 止损已调整", "stop_loss", decision.NewStopLoss, "price", marketData.CurrentPrice)
 	return nil
 }
 
 // executeUpdateTakeProfitWithRecord 执行调整止盈并记录详细信息
 func (at *AutoTrader) executeUpdateTakeProfitWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  🎯 调整止盈: %s → %.2f", decision.Symbol, decision.NewTakeProfit)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  🎯 调整止盈", "new_take_profit", decision.NewTakeProfit)
 
 	// 获取当前价格
 	marketData, err := market.Get(decision.Symbol, at.timeframes)
@@ -1255,7 +1753,7 @@ func (at *AutoTrader) executeUpdateTakeProfitWithRecord(decision *decision.Decis
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 获取当前持仓
-	positions, err := at.trader.GetPositions()
+	positions, err := at.symbolPositions(decision.Symbol)
 	if err != nil {
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -1284,9 +1782,8 @@ func (at *AutoTrader) executeUpdateTakeProfitWithRecord(decision *decision.Decis
 
 		if wasRecentlyOpen {
 			// 持仓刚刚消失，很可能是止盈单已触发
-			log.Printf("  ℹ️  %s 持仓已平仓（止盈单可能已触发），跳过止盈调整", decision.Symbol)
-			log.Printf("  💡 提示：市价 %.2f，目标止盈 %.2f - 交易所可能已在两次AI周期间执行止盈",
-				marketData.CurrentPrice, decision.NewTakeProfit)
+			tl.Info("  ℹ️ 持仓已平仓（止盈单可能已触发），跳过止盈调整")
+			tl.Info("  💡 提示：交易所可能已在两次AI周期间执行止盈", "current_price", marketData.CurrentPrice, "new_take_profit", decision.NewTakeProfit)
 			return nil // 优雅返回，不抛错误
 		}
 
@@ -1298,6 +1795,7 @@ func (at *AutoTrader) executeUpdateTakeProfitWithRecord(decision *decision.Decis
 	side, _ := targetPosition["side"].(string)
 	positionSide := strings.ToUpper(side)
 	positionAmt, _ := targetPosition["positionAmt"].(float64)
+	actionRecord.Side = side
 
 	// ⚡ 严格验证新止盈价格合理性（防止 "Order would immediately trigger" 错误）
 	priceGap := 0.0
@@ -1332,35 +1830,34 @@ func (at *AutoTrader) executeUpdateTakeProfitWithRecord(decision *decision.Decis
 	}
 
 	if hasOppositePosition {
-		log.Printf("  🚨 警告：检测到 %s 存在双向持仓（%s + %s），这违反了策略规则",
-			decision.Symbol, positionSide, oppositeSide)
-		log.Printf("  🚨 取消止盈单将影响两个方向的订单，请检查是否为用户手动操作导致")
-		log.Printf("  🚨 建议：手动平掉其中一个方向的持仓，或检查系统是否有BUG")
+		tl.Error("  🚨 检测到双向持仓，取消止盈单将影响两个方向的订单", "position_side", positionSide, "opposite_side", oppositeSide,
+			"hint", "手动平掉其中一个方向的持仓，或检查系统是否有BUG")
 	}
 
 	// 取消旧的止盈单（只删除止盈单，不影响止损单）
 	// 注意：如果存在双向持仓，这会删除两个方向的止盈单
 	// ✅ 修复 Issue #998: 必须成功取消旧单才能继续，防止重复挂单
-	if err := at.trader.CancelTakeProfitOrders(decision.Symbol); err != nil {
+	if err := at.trader.CancelTakeProfitOrders(at.ctx(), decision.Symbol); err != nil {
 		return fmt.Errorf("取消舊止盈單失敗，中止操作以防止重複掛單 (Issue #998): %w", err)
 	}
 
-	log.Printf("  ✓ 已取消舊止盈單，準備設置新止盈")
+	tl.Info("  ✓ 已取消舊止盈單，準備設置新止盈")
 
 	// 调用交易所 API 修改止盈
 	quantity := math.Abs(positionAmt)
-	err = at.trader.SetTakeProfit(decision.Symbol, positionSide, quantity, decision.NewTakeProfit)
+	err = at.trader.SetTakeProfit(at.ctx(), decision.Symbol, positionSide, quantity, decision.NewTakeProfit)
 	if err != nil {
 		return fmt.Errorf("修改止盈失败: %w", err)
 	}
 
-	log.Printf("  ✓ 止盈已调整: %.2f (当前价格: %.2f)", decision.NewTakeProfit, marketData.CurrentPrice)
+	tl.Info("  ✓ 止盈已调整", "take_profit", decision.NewTakeProfit, "price", marketData.CurrentPrice)
 	return nil
 }
 
 // executePartialCloseWithRecord 执行部分平仓并记录详细信息
 func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision, actionRecord *logger.DecisionAction) error {
-	log.Printf("  📊 部分平仓: %s %.1f%%", decision.Symbol, decision.ClosePercentage)
+	tl := at.tradeLog(actionRecord)
+	tl.Info("  📊 部分平仓", "close_pct", decision.ClosePercentage)
 
 	// 验证百分比范围
 	if decision.ClosePercentage <= 0 || decision.ClosePercentage > 100 {
@@ -1375,7 +1872,7 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 	actionRecord.Price = marketData.CurrentPrice
 
 	// 获取当前持仓
-	positions, err := at.trader.GetPositions()
+	positions, err := at.symbolPositions(decision.Symbol)
 	if err != nil {
 		return fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -1404,9 +1901,8 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 
 		if wasRecentlyOpen {
 			// 持仓刚刚消失，很可能是止损/止盈单已触发全部平仓
-			log.Printf("  ℹ️  %s 持仓已完全平仓（止损/止盈可能已触发），跳过部分平仓", decision.Symbol)
-			log.Printf("  💡 提示：市价 %.2f - 交易所可能已在两次AI周期间自动平仓",
-				marketData.CurrentPrice)
+			tl.Info("  ℹ️ 持仓已完全平仓（止损/止盈可能已触发），跳过部分平仓")
+			tl.Info("  💡 提示：交易所可能已在两次AI周期间自动平仓", "current_price", marketData.CurrentPrice)
 			return nil // 优雅返回，不抛错误
 		}
 
@@ -1418,6 +1914,7 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 	side, _ := targetPosition["side"].(string)
 	positionSide := strings.ToUpper(side)
 	positionAmt, _ := targetPosition["positionAmt"].(float64)
+	actionRecord.Side = side
 
 	// 计算平仓数量
 	totalQuantity := math.Abs(positionAmt)
@@ -1437,43 +1934,39 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 	const MIN_POSITION_VALUE = 10.0 // 最小持仓价值 10 USDT（對齊交易所底线，小仓位建议直接全平）
 
 	if remainingValue > 0 && remainingValue <= MIN_POSITION_VALUE {
-		log.Printf("⚠️ 检测到 partial_close 后剩余仓位 %.2f USDT < %.0f USDT",
-			remainingValue, MIN_POSITION_VALUE)
-		log.Printf("  → 当前仓位价值: %.2f USDT, 平仓 %.1f%%, 剩余: %.2f USDT",
-			currentPositionValue, decision.ClosePercentage, remainingValue)
-		log.Printf("  → 自动修正为全部平仓，避免产生无法平仓的小额剩余")
+		tl.Warn("⚠️ 检测到 partial_close 后剩余仓位低于最小仓位价值", "remaining_value", remainingValue, "min_position_value", MIN_POSITION_VALUE)
+		tl.Info("  → 当前仓位价值", "position_value", currentPositionValue, "close_pct", decision.ClosePercentage, "remaining_value", remainingValue)
+		tl.Info("  → 自动修正为全部平仓，避免产生无法平仓的小额剩余")
 
 		// 🔄 自动修正为全部平仓
 		if positionSide == "LONG" {
 			decision.Action = "close_long"
-			log.Printf("  ✓ 已修正为: close_long")
+			tl.Info("  ✓ 已修正为: close_long")
 			return at.executeCloseLongWithRecord(decision, actionRecord)
 		} else {
 			decision.Action = "close_short"
-			log.Printf("  ✓ 已修正为: close_short")
+			tl.Info("  ✓ 已修正为: close_short")
 			return at.executeCloseShortWithRecord(decision, actionRecord)
 		}
 	}
 
 	// 执行平仓
-	var order map[string]interface{}
+	var order *ExecutionReport
 	if positionSide == "LONG" {
-		order, err = at.trader.CloseLong(decision.Symbol, closeQuantity)
+		order, err = at.trader.CloseLong(at.orderCtx(decision), decision.Symbol, closeQuantity)
 	} else {
-		order, err = at.trader.CloseShort(decision.Symbol, closeQuantity)
+		order, err = at.trader.CloseShort(at.orderCtx(decision), decision.Symbol, closeQuantity)
 	}
 
 	if err != nil {
 		return fmt.Errorf("部分平仓失败: %w", err)
 	}
 
-	// 记录订单ID
-	if orderID, ok := order["orderId"].(int64); ok {
-		actionRecord.OrderID = orderID
-	}
+	// 记录订单ID及实际成交数据
+	order.applyToAction(actionRecord)
 
-	log.Printf("  ✓ 部分平仓成功: 平仓 %.4f (%.1f%%), 剩余 %.4f",
-		closeQuantity, decision.ClosePercentage, remainingQuantity)
+	tl.Info("  ✓ 部分平仓成功", "order_id", order.OrderID, "quantity", closeQuantity,
+		"close_pct", decision.ClosePercentage, "remaining", remainingQuantity)
 
 	// ✅ Step 4: Restore TP/SL protection (prevent remaining position from being unprotected)
 	// IMPORTANT: Exchanges like Binance automatically cancel existing TP/SL orders after partial close (due to quantity mismatch)
@@ -1488,17 +1981,15 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 		}
 
 		if isValidStopLoss {
-			log.Printf("  → Restoring stop-loss for remaining position %.4f: %.2f", remainingQuantity, decision.NewStopLoss)
-			err = at.trader.SetStopLoss(decision.Symbol, positionSide, remainingQuantity, decision.NewStopLoss)
+			tl.Info("  → Restoring stop-loss for remaining position", "remaining_quantity", remainingQuantity, "new_stop_loss", decision.NewStopLoss)
+			err = at.trader.SetStopLoss(at.ctx(), decision.Symbol, positionSide, remainingQuantity, decision.NewStopLoss)
 			if err != nil {
-				log.Printf("  ⚠️ Failed to restore stop-loss: %v (doesn't affect close result)", err)
+				tl.Warn("  ⚠️ Failed to restore stop-loss (doesn't affect close result)", "error", err)
 			}
 		} else {
 			priceGapPct := math.Abs((decision.NewStopLoss-marketData.CurrentPrice)/marketData.CurrentPrice) * 100
-			log.Printf("  ⚠️⚠️ 跳过设置止损：价格不合理 (止损 %.2f, 当前 %.2f, 差距 %.2f%%)",
-				decision.NewStopLoss, marketData.CurrentPrice, priceGapPct)
-			log.Printf("  → %s仓位的止损必须%s当前价，剩余仓位目前没有止损保护",
-				positionSide, map[string]string{"LONG": "低于", "SHORT": "高于"}[positionSide])
+			tl.Warn("  ⚠️⚠️ 跳过设置止损：价格不合理", "new_stop_loss", decision.NewStopLoss, "current_price", marketData.CurrentPrice, "price_gap_pct", priceGapPct)
+			tl.Info("  → 止损价必须位于当前价的亏损方向，剩余仓位目前没有止损保护", "position_side", positionSide)
 		}
 	}
 
@@ -1512,25 +2003,21 @@ func (at *AutoTrader) executePartialCloseWithRecord(decision *decision.Decision,
 		}
 
 		if isValidTakeProfit {
-			log.Printf("  → Restoring take-profit for remaining position %.4f: %.2f", remainingQuantity, decision.NewTakeProfit)
-			err = at.trader.SetTakeProfit(decision.Symbol, positionSide, remainingQuantity, decision.NewTakeProfit)
+			tl.Info("  → Restoring take-profit for remaining position", "remaining_quantity", remainingQuantity, "new_take_profit", decision.NewTakeProfit)
+			err = at.trader.SetTakeProfit(at.ctx(), decision.Symbol, positionSide, remainingQuantity, decision.NewTakeProfit)
 			if err != nil {
-				log.Printf("  ⚠️ Failed to restore take-profit: %v (doesn't affect close result)", err)
+				tl.Warn("  ⚠️ Failed to restore take-profit (doesn't affect close result)", "error", err)
 			}
 		} else {
 			priceGapPct := math.Abs((decision.NewTakeProfit-marketData.CurrentPrice)/marketData.CurrentPrice) * 100
-			log.Printf("  ⚠️⚠️ 跳过设置止盈：价格不合理 (止盈 %.2f, 当前 %.2f, 差距 %.2f%%)",
-				decision.NewTakeProfit, marketData.CurrentPrice, priceGapPct)
-			log.Printf("  → %s仓位的止盈必须%s当前价，剩余仓位目前没有止盈保护",
-				positionSide, map[string]string{"LONG": "高于", "SHORT": "低于"}[positionSide])
+			tl.Warn("  ⚠️⚠️ 跳过设置止盈：价格不合理", "new_take_profit", decision.NewTakeProfit, "current_price", marketData.CurrentPrice, "price_gap_pct", priceGapPct)
+			tl.Info("  → 止盈价必须位于当前价的盈利方向，剩余仓位目前没有止盈保护", "position_side", positionSide)
 		}
 	}
 
 	// 如果 AI 没有提供新的止盈止损，记录警告
 	if decision.NewStopLoss <= 0 && decision.NewTakeProfit <= 0 {
-		log.Printf("  ⚠️⚠️⚠️ 警告: 部分平仓后AI未提供新的止盈止损价格")
-		log.Printf("  → 剩余仓位 %.4f (价值 %.2f USDT) 目前没有止盈止损保护", remainingQuantity, remainingValue)
-		log.Printf("  → 建议: 在 partial_close 决策中包含 new_stop_loss 和 new_take_profit 字段")
+		tl.Warn("  ⚠️ 部分平仓后AI未提供新的止盈止损价格，剩余仓位没有止盈止损保护（建议在 partial_close 决策中包含 new_stop_loss 和 new_take_profit）", "remaining_quantity", remainingQuantity, "remaining_value", remainingValue)
 	}
 
 	return nil
@@ -1589,25 +2076,35 @@ func (at *AutoTrader) GetStatus() map[string]interface{} {
 	}
 
 	return map[string]interface{}{
-		"trader_id":       at.id,
-		"trader_name":     at.name,
-		"ai_model":        at.aiModel,
-		"exchange":        at.exchange,
-		"is_running":      at.isRunning,
-		"start_time":      at.startTime.Format(time.RFC3339),
-		"runtime_minutes": int(time.Since(at.startTime).Minutes()),
-		"call_count":      at.callCount,
-		"initial_balance": at.initialBalance,
-		"scan_interval":   at.config.ScanInterval.String(),
-		"stop_until":      at.stopUntil.Format(time.RFC3339),
-		"last_reset_time": at.lastResetTime.Format(time.RFC3339),
-		"ai_provider":     aiProvider,
+		"trader_id":        at.id,
+		"trader_name":      at.name,
+		"ai_model":         at.aiModel,
+		"exchange":         at.exchange,
+		"is_running":       at.isRunning,
+		"start_time":       at.startTime.Format(time.RFC3339),
+		"runtime_minutes":  int(time.Since(at.startTime).Minutes()),
+		"call_count":       at.callCount,
+		"initial_balance":  at.initialBalance,
+		"scan_interval":    at.getScanInterval().String(),
+		"stop_until":       at.riskStopUntil().Format(time.RFC3339),
+		"last_reset_time":  at.lastResetTime.Format(time.RFC3339),
+		"ai_provider":      aiProvider,
+		"reconcile_halted": at.reconciliationHalted(),
+		"paused":           at.GetPauseState().Paused,
+	}
+}
+
+// CheckExchange 检查交易所私有接口是否可用（查询一次余额，用于就绪探针）
+func (at *AutoTrader) CheckExchange(ctx context.Context) error {
+	if _, err := at.trader.GetBalance(ctx); err != nil {
+		return fmt.Errorf("%s 私有接口不可用: %w", at.exchange, err)
 	}
+	return nil
 }
 
 // GetAccountInfo 获取账户信息（用于API）
 func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
-	balance, err := at.trader.GetBalance()
+	balance, err := at.trader.GetBalance(at.ctx())
 	if err != nil {
 		return nil, fmt.Errorf("获取余额失败: %w", err)
 	}
@@ -1626,12 +2123,13 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	if avail, ok := balance["availableBalance"].(float64); ok {
 		availableBalance = avail
 	}
+	marginRatio, _ := ParseMarginRatio(balance)
 
 	// Total Equity = 钱包余额 + 未实现盈亏
 	totalEquity := totalWalletBalance + totalUnrealizedProfit
 
 	// 获取持仓计算总保证金
-	positions, err := at.trader.GetPositions()
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -1658,8 +2156,7 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	// 验证未实现盈亏的一致性（API值 vs 从持仓计算）
 	diff := math.Abs(totalUnrealizedProfit - totalUnrealizedPnLCalculated)
 	if diff > 0.1 { // 允许0.01 USDT的误差
-		log.Printf("⚠️ 未实现盈亏不一致: API=%.4f, 计算=%.4f, 差异=%.4f",
-			totalUnrealizedProfit, totalUnrealizedPnLCalculated, diff)
+		log.Warn("⚠️ 未实现盈亏不一致", "api_unrealized_pnl", totalUnrealizedProfit, "calculated_unrealized_pnl", totalUnrealizedPnLCalculated, "diff", diff)
 	}
 
 	totalPnL := totalEquity - at.initialBalance
@@ -1667,7 +2164,7 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 	if at.initialBalance > 0 {
 		totalPnLPct = (totalPnL / at.initialBalance) * 100
 	} else {
-		log.Printf("⚠️ Initial Balance异常: %.2f，无法计算PNL百分比", at.initialBalance)
+		log.Warn("⚠️ Initial Balance异常，无法计算PNL百分比", "initial_balance", at.initialBalance)
 	}
 
 	marginUsedPct := 0.0
@@ -1689,9 +2186,10 @@ func (at *AutoTrader) GetAccountInfo() (map[string]interface{}, error) {
 		"daily_pnl":       at.dailyPnL,       // 日盈亏
 
 		// 持仓信息
-		"position_count":  len(positions),  // 持仓数量
-		"margin_used":     totalMarginUsed, // 保证金占用
-		"margin_used_pct": marginUsedPct,   // 保证金使用率
+		"position_count":  len(positions),    // 持仓数量
+		"margin_used":     totalMarginUsed,   // 保证金占用
+		"margin_used_pct": marginUsedPct,     // 保证金使用率
+		"margin_ratio":    marginRatio.Pct(), // 保证金率（维持保证金 / 保证金余额）
 	}, nil
 }
 
@@ -1703,7 +2201,7 @@ func sameDay(a, b time.Time) bool {
 
 // GetPositions 获取持仓列表（用于API）
 func (at *AutoTrader) GetPositions() ([]map[string]interface{}, error) {
-	positions, err := at.trader.GetPositions()
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -1765,22 +2263,6 @@ func sortDecisionsByPriority(decisions []decision.Decision) []decision.Decision
 		return decisions
 	}
 
-	// 定义优先级
-	getActionPriority := func(action string) int {
-		switch action {
-		case "close_long", "close_short", "partial_close":
-			return 1 // 最高优先级：先平仓（包括部分平仓）
-		case "update_stop_loss", "update_take_profit":
-			return 2 // 调整持仓止盈止损
-		case "open_long", "open_short":
-			return 3 // 次优先级：后开仓
-		case "hold", "wait":
-			return 4 // 最低优先级：观望
-		default:
-			return 999 // 未知动作放最后
-		}
-	}
-
 	// 复制决策列表
 	sorted := make([]decision.Decision, len(decisions))
 	copy(sorted, decisions)
@@ -1788,7 +2270,7 @@ func sortDecisionsByPriority(decisions []decision.Decision) []decision.Decision
 	// 按优先级排序
 	for i := 0; i < len(sorted)-1; i++ {
 		for j := i + 1; j < len(sorted); j++ {
-			if getActionPriority(sorted[i].Action) > getActionPriority(sorted[j].Action) {
+			if decisionPriority(sorted[i].Action) > decisionPriority(sorted[j].Action) {
 				sorted[i], sorted[j] = sorted[j], sorted[i]
 			}
 		}
@@ -1797,6 +2279,31 @@ func sortDecisionsByPriority(decisions []decision.Decision) []decision.Decision
 	return sorted
 }
 
+// 决策执行优先级
+const (
+	priorityClose  = 1   // 最高优先级：先平仓（包括部分平仓）
+	priorityAdjust = 2   // 调整持仓止盈止损
+	priorityOpen   = 3   // 次优先级：后开仓
+	priorityHold   = 4   // 最低优先级：观望
+	priorityOther  = 999 // 未知动作放最后
+)
+
+// decisionPriority 决策动作的执行优先级
+func decisionPriority(action string) int {
+	switch action {
+	case "close_long", "close_short", "partial_close":
+		return priorityClose
+	case "update_stop_loss", "update_take_profit":
+		return priorityAdjust
+	case "open_long", "open_short":
+		return priorityOpen
+	case "hold", "wait":
+		return priorityHold
+	default:
+		return priorityOther
+	}
+}
+
 // getCandidateCoins 获取交易员的候选币种列表
 func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 	// 优先级 1: 自定义币种列表（最高优先级）
@@ -1809,11 +2316,12 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 				Sources: []string{"custom"},
 			})
 		}
-		log.Printf("📋 [%s] 使用自定义币种: %d个币种 %v",
-			at.name, len(candidateCoins), at.tradingCoins)
+		log.Info("📋 使用自定义币种", "trader", at.name, "coin_count", len(candidateCoins), "coins", at.tradingCoins)
 		return candidateCoins, nil
 	}
 
+	defaultCoins, defaultSource := at.baseCoins()
+
 	// 优先级 2: 信号源扩展模式（合并系统默认 + 信号源）
 	if at.useCoinPool || at.useOITop {
 		symbolMap := make(map[string][]string) // symbol -> sources
@@ -1822,9 +2330,9 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 
 		// 2.1 先添加系统默认币种作为基础
 		defaultCount := 0
-		for _, coin := range at.defaultCoins {
+		for _, coin := range defaultCoins {
 			symbol := normalizeSymbol(coin)
-			symbolMap[symbol] = []string{"default"}
+			symbolMap[symbol] = []string{defaultSource}
 			defaultCount++
 		}
 
@@ -1848,7 +2356,7 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 					}
 				}
 			} else if err != nil {
-				log.Printf("⚠️  [%s] 获取合并信号源失败: %v", at.name, err)
+				log.Warn("⚠️ 获取合并信号源失败", "trader", at.name, "error", err)
 			}
 		} else if at.useCoinPool {
 			// 只使用 AI500
@@ -1869,7 +2377,7 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 					}
 				}
 			} else if err != nil {
-				log.Printf("⚠️  [%s] 获取 AI500 信号失败: %v", at.name, err)
+				log.Warn("⚠️ 获取 AI500 信号失败", "trader", at.name, "error", err)
 			}
 		} else if at.useOITop {
 			// 只使用 OI Top
@@ -1895,7 +2403,7 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 					}
 				}
 			} else if err != nil {
-				log.Printf("⚠️  [%s] 获取 OI Top 信号失败: %v", at.name, err)
+				log.Warn("⚠️ 获取 OI Top 信号失败", "trader", at.name, "error", err)
 			}
 		}
 
@@ -1908,28 +2416,30 @@ func (at *AutoTrader) getCandidateCoins() ([]decision.CandidateCoin, error) {
 			})
 		}
 
-		log.Printf("📋 [%s] 信号源扩展模式: 系统默认%d + 信号源新增%d = 总计%d个候选币种",
-			at.name, defaultCount, signalSourceCount, len(candidateCoins))
+		log.Info("📋 信号源扩展模式", "trader", at.name, "default_count", defaultCount, "signal_source_count", signalSourceCount, "candidate_count", len(candidateCoins))
 		return candidateCoins, nil
 	}
 
 	// 优先级 3: 只使用系统默认币种（未启用信号源）
-	if len(at.defaultCoins) > 0 {
+	if len(defaultCoins) > 0 {
 		var candidateCoins []decision.CandidateCoin
-		for _, coin := range at.defaultCoins {
+		for _, coin := range defaultCoins {
 			symbol := normalizeSymbol(coin)
 			candidateCoins = append(candidateCoins, decision.CandidateCoin{
 				Symbol:  symbol,
-				Sources: []string{"default"},
+				Sources: []string{defaultSource},
 			})
 		}
-		log.Printf("📋 [%s] 使用系统默认币种: %d个币种 %v",
-			at.name, len(candidateCoins), at.defaultCoins)
+		label := "系统默认币种"
+		if defaultSource == "universe" {
+			label = "动态币种池"
+		}
+		log.Info("📋 使用币种来源", "trader", at.name, "source", label, "coin_count", len(candidateCoins), "coins", defaultCoins)
 		return candidateCoins, nil
 	}
 
 	// 优先级 4: 都没有配置 - 返回空列表（AI 只管理现有持仓）
-	log.Printf("⚠️  [%s] 无任何币种来源，AI 将只管理现有持仓（不开新仓）", at.name)
+	log.Warn("⚠️ 无任何币种来源，AI 将只管理现有持仓（不开新仓）", "trader", at.name)
 	return []decision.CandidateCoin{}, nil
 }
 
@@ -1955,14 +2465,14 @@ func (at *AutoTrader) startDrawdownMonitor() {
 		ticker := time.NewTicker(1 * time.Minute) // 每分钟检查一次
 		defer ticker.Stop()
 
-		log.Println("📊 启动持仓回撤监控（每分钟检查一次）")
+		log.Info("📊 启动持仓回撤监控（每分钟检查一次）")
 
 		for {
 			select {
 			case <-ticker.C:
 				at.checkPositionDrawdown()
 			case <-at.stopMonitorCh:
-				log.Println("⏹ 停止持仓回撤监控")
+				log.Info("⏹ 停止持仓回撤监控")
 				return
 			}
 		}
@@ -1972,9 +2482,9 @@ func (at *AutoTrader) startDrawdownMonitor() {
 // 检查持仓回撤情况
 func (at *AutoTrader) checkPositionDrawdown() {
 	// 获取当前持仓
-	positions, err := at.trader.GetPositions()
+	positions, err := at.trader.GetPositions(at.ctx())
 	if err != nil {
-		log.Printf("❌ 回撤监控：获取持仓失败: %v", err)
+		log.Error("❌ 回撤监控：获取持仓失败", "error", err)
 		return
 	}
 
@@ -2024,23 +2534,27 @@ func (at *AutoTrader) checkPositionDrawdown() {
 			drawdownPct = ((peakPnLPct - currentPnLPct) / peakPnLPct) * 100
 		}
 
-		// 检查平仓条件：收益大于5%且回撤超过40%
-		if currentPnLPct > 5.0 && drawdownPct >= 40.0 {
-			log.Printf("🚨 触发回撤平仓条件: %s %s | 当前收益: %.2f%% | 最高收益: %.2f%% | 回撤: %.2f%%",
-				symbol, side, currentPnLPct, peakPnLPct, drawdownPct)
+		// 分批止盈优先，未启用时使用出场模板的阶梯止盈
+		if !at.checkScaleOut(symbol, side, markPrice, quantity) {
+			at.checkTakeProfitLadder(symbol, side, entryPrice, markPrice, quantity)
+		}
+
+		// 检查平仓条件：收益大于激活阈值（默认5%）且回撤超过回撤阈值（默认40%），阈值可由出场模板按币种分类调整
+		activatePct, drawdownLimit := at.trailingThresholds(symbol)
+		if currentPnLPct > activatePct && drawdownPct >= drawdownLimit {
+			log.Error("🚨 触发回撤平仓条件", "symbol", symbol, "side", side, "current_pnl_pct", currentPnLPct, "peak_pnl_pct", peakPnLPct, "drawdown_pct", drawdownPct)
 
 			// 执行平仓
 			if err := at.emergencyClosePosition(symbol, side); err != nil {
-				log.Printf("❌ 回撤平仓失败 (%s %s): %v", symbol, side, err)
+				log.Error("❌ 回撤平仓失败", "symbol", symbol, "side", side, "error", err)
 			} else {
-				log.Printf("✅ 回撤平仓成功: %s %s", symbol, side)
+				log.Info("✅ 回撤平仓成功", "symbol", symbol, "side", side)
 				// 平仓后清理该持仓的缓存
 				at.ClearPeakPnLCache(symbol, side)
 			}
-		} else if currentPnLPct > 5.0 {
+		} else if currentPnLPct > activatePct {
 			// 记录接近平仓条件的情况（用于调试）
-			log.Printf("📊 回撤监控: %s %s | 收益: %.2f%% | 最高: %.2f%% | 回撤: %.2f%%",
-				symbol, side, currentPnLPct, peakPnLPct, drawdownPct)
+			log.Info("📊 回撤监控", "symbol", symbol, "side", side, "current_pnl_pct", currentPnLPct, "peak_pnl_pct", peakPnLPct, "drawdown_pct", drawdownPct)
 		}
 	}
 }
@@ -2049,17 +2563,17 @@ func (at *AutoTrader) checkPositionDrawdown() {
 func (at *AutoTrader) emergencyClosePosition(symbol, side string) error {
 	switch side {
 	case "long":
-		order, err := at.trader.CloseLong(symbol, 0) // 0 = 全部平仓
+		order, err := at.trader.CloseLong(at.ctx(), symbol, 0) // 0 = 全部平仓
 		if err != nil {
 			return err
 		}
-		log.Printf("✅ 紧急平多仓成功，订单ID: %v", order["orderId"])
+		log.Info("✅ 紧急平多仓成功", "order_id", order.OrderID)
 	case "short":
-		order, err := at.trader.CloseShort(symbol, 0) // 0 = 全部平仓
+		order, err := at.trader.CloseShort(at.ctx(), symbol, 0) // 0 = 全部平仓
 		if err != nil {
 			return err
 		}
-		log.Printf("✅ 紧急平空仓成功，订单ID: %v", order["orderId"])
+		log.Info("✅ 紧急平空仓成功", "order_id", order.OrderID)
 	default:
 		return fmt.Errorf("未知的持仓方向: %s", side)
 	}
@@ -2241,7 +2755,7 @@ func (at *AutoTrader) ReloadAIModelConfig(modelConfig *config.AIModelConfig) err
 		return fmt.Errorf("模型配置为空")
 	}
 
-	log.Printf("🔄 [%s] 重新加载AI模型配置...", at.name)
+	log.Info("🔄 重新加载AI模型配置...", "trader", at.name)
 
 	// 更新AI模型相关配置
 	at.config.CustomModelName = modelConfig.CustomModelName
@@ -2252,16 +2766,13 @@ func (at *AutoTrader) ReloadAIModelConfig(modelConfig *config.AIModelConfig) err
 	case "deepseek":
 		at.config.DeepSeekKey = modelConfig.APIKey
 		at.config.CustomAPIKey = modelConfig.APIKey
-		log.Printf("✓ [%s] DeepSeek配置已更新: Model=%s, BaseURL=%s",
-			at.name, at.config.CustomModelName, at.config.CustomAPIURL)
+		log.Info("✓ DeepSeek配置已更新", "trader", at.name, "model", at.config.CustomModelName, "base_url", at.config.CustomAPIURL)
 	case "qwen":
 		at.config.QwenKey = modelConfig.APIKey
-		log.Printf("✓ [%s] Qwen配置已更新: Model=%s",
-			at.name, at.config.CustomModelName)
+		log.Info("✓ Qwen配置已更新", "trader", at.name, "model", at.config.CustomModelName)
 	case "custom":
 		at.config.CustomAPIKey = modelConfig.APIKey
-		log.Printf("✓ [%s] 自定义AI配置已更新: URL=%s, Model=%s",
-			at.name, at.config.CustomAPIURL, at.config.CustomModelName)
+		log.Info("✓ 自定义AI配置已更新", "trader", at.name, "url", at.config.CustomAPIURL, "model", at.config.CustomModelName)
 	default:
 		return fmt.Errorf("不支持的AI provider: %s", modelConfig.Provider)
 	}
@@ -2271,7 +2782,7 @@ func (at *AutoTrader) ReloadAIModelConfig(modelConfig *config.AIModelConfig) err
 		return fmt.Errorf("重新初始化MCP客户端失败: %w", err)
 	}
 
-	log.Printf("✅ [%s] AI模型配置热更新完成", at.name)
+	log.Info("✅ AI模型配置热更新完成", "trader", at.name)
 	return nil
 }
 
@@ -2300,8 +2811,7 @@ func (at *AutoTrader) reinitializeMCPClient() error {
 	// 使用统一的 SetAPIKey 方法重新初始化
 	at.mcpClient.SetAPIKey(apiKey, at.config.CustomAPIURL, at.config.CustomModelName)
 
-	log.Printf("🔧 [MCP] AI模型配置已重新初始化: Model=%s, Provider=%s, CustomURL=%s",
-		at.config.CustomModelName, at.config.AIModel, at.config.CustomAPIURL)
+	log.Info("🔧 [MCP] AI模型配置已重新初始化", "model", at.config.CustomModelName, "provider", at.config.AIModel, "custom_url", at.config.CustomAPIURL)
 
 	return nil
 }
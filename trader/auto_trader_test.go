@@ -1,6 +1,7 @@
 package trader
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -817,6 +818,7 @@ func (s *AutoTraderTestSuite) TestExecuteDecisionWithRecord() {
 
 		err := s.autoTrader.executeDecisionWithRecord(decision, actionRecord)
 		s.NoError(err)
+		s.NotEmpty(actionRecord.TradeID, "执行时应分配交易关联ID")
 	})
 
 	s.Run("路由到close_long", func() {
@@ -1004,9 +1006,10 @@ type MockTrader struct {
 	shouldFailOpenLong   bool
 	shouldFailCloseLong  bool
 	shouldFailCloseShort bool
+	fundingRate          float64
 }
 
-func (m *MockTrader) GetBalance() (map[string]interface{}, error) {
+func (m *MockTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
 	if m.shouldFailBalance {
 		return nil, errors.New("failed to get balance")
 	}
@@ -1020,7 +1023,7 @@ func (m *MockTrader) GetBalance() (map[string]interface{}, error) {
 	return m.balance, nil
 }
 
-func (m *MockTrader) GetPositions() ([]map[string]interface{}, error) {
+func (m *MockTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
 	if m.shouldFailPositions {
 		return nil, errors.New("failed to get positions")
 	}
@@ -1030,76 +1033,84 @@ func (m *MockTrader) GetPositions() ([]map[string]interface{}, error) {
 	return m.positions, nil
 }
 
-func (m *MockTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (m *MockTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	if m.shouldFailOpenLong {
 		return nil, errors.New("failed to open long")
 	}
-	return map[string]interface{}{
-		"orderId": int64(123456),
-		"symbol":  symbol,
+	return &ExecutionReport{
+		OrderID: 123456,
+		Symbol:  symbol,
 	}, nil
 }
 
-func (m *MockTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
-	return map[string]interface{}{
-		"orderId": int64(123457),
-		"symbol":  symbol,
+func (m *MockTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	return &ExecutionReport{
+		OrderID: 123457,
+		Symbol:  symbol,
 	}, nil
 }
 
-func (m *MockTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (m *MockTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	if m.shouldFailCloseLong {
 		return nil, errors.New("failed to close long")
 	}
-	return map[string]interface{}{
-		"orderId": int64(123458),
-		"symbol":  symbol,
+	return &ExecutionReport{
+		OrderID: 123458,
+		Symbol:  symbol,
 	}, nil
 }
 
-func (m *MockTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (m *MockTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	if m.shouldFailCloseShort {
 		return nil, errors.New("failed to close short")
 	}
-	return map[string]interface{}{
-		"orderId": int64(123459),
-		"symbol":  symbol,
+	return &ExecutionReport{
+		OrderID: 123459,
+		Symbol:  symbol,
 	}, nil
 }
 
-func (m *MockTrader) SetLeverage(symbol string, leverage int) error {
+func (m *MockTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
 	return nil
 }
 
-func (m *MockTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+func (m *MockTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
 	return nil
 }
 
-func (m *MockTrader) GetMarketPrice(symbol string) (float64, error) {
+func (m *MockTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
 	return 50000.0, nil
 }
 
-func (m *MockTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+func (m *MockTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	return &market.FundingRate{Symbol: symbol, Rate: m.fundingRate}, nil
+}
+
+func (m *MockTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	return []market.FundingRate{}, nil
+}
+
+func (m *MockTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
 	return nil
 }
 
-func (m *MockTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+func (m *MockTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
 	return nil
 }
 
-func (m *MockTrader) CancelStopLossOrders(symbol string) error {
+func (m *MockTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
 	return nil
 }
 
-func (m *MockTrader) CancelTakeProfitOrders(symbol string) error {
+func (m *MockTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
 	return nil
 }
 
-func (m *MockTrader) CancelAllOrders(symbol string) error {
+func (m *MockTrader) CancelAllOrders(ctx context.Context, symbol string) error {
 	return nil
 }
 
-func (m *MockTrader) CancelStopOrders(symbol string) error {
+func (m *MockTrader) CancelStopOrders(ctx context.Context, symbol string) error {
 	return nil
 }
 
@@ -1107,7 +1118,7 @@ func (m *MockTrader) FormatQuantity(symbol string, quantity float64) (string, er
 	return fmt.Sprintf("%.4f", quantity), nil
 }
 
-func (m *MockTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo, error) {
+func (m *MockTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
 	return []decision.OpenOrderInfo{}, nil
 }
 
@@ -1,7 +1,5 @@
 package trader
 
-import "log"
-
 // ParseTotalEquity 從交易所余額信息中提取總資產（total equity）
 // 總資產 = 錢包餘額 + 未實現盈虧
 // 這是計算交易員盈虧的正確基準，而不是可用餘額
@@ -24,8 +22,7 @@ func ParseTotalEquity(balanceInfo map[string]interface{}, logPrefix string) (tot
 
 	if totalEquity > 0 {
 		if logPrefix != "" {
-			log.Printf("%s 查詢到交易所總資產: %.2f USDT (錢包: %.2f + 未實現: %.2f)",
-				logPrefix, totalEquity, totalWalletBalance, totalUnrealizedProfit)
+			log.Info(logPrefix+" 查詢到交易所總資產", "total_equity", totalEquity, "wallet_balance", totalWalletBalance, "unrealized_pnl", totalUnrealizedProfit)
 		}
 		return totalEquity, true
 	}
@@ -33,21 +30,54 @@ func ParseTotalEquity(balanceInfo map[string]interface{}, logPrefix string) (tot
 	// 嘗試 fallback 字段
 	if availableBalance, ok := balanceInfo["availableBalance"].(float64); ok && availableBalance > 0 {
 		if logPrefix != "" {
-			log.Printf("⚠️ %s 無法提取 totalEquity，使用 availableBalance: %.2f", logPrefix, availableBalance)
+			log.Warn("⚠️ 無法提取 totalEquity，使用 availableBalance", "available_balance", availableBalance)
 		}
 		return availableBalance, true
 	}
 
 	if balance, ok := balanceInfo["balance"].(float64); ok && balance > 0 {
 		if logPrefix != "" {
-			log.Printf("⚠️ %s 無法提取 totalEquity，使用 balance: %.2f", logPrefix, balance)
+			log.Warn("⚠️ 無法提取 totalEquity，使用 balance", "balance", balance)
 		}
 		return balance, true
 	}
 
 	// 所有字段都失敗
 	if logPrefix != "" {
-		log.Printf("⚠️ %s 無法提取任何余額字段", logPrefix)
+		log.Warn("⚠️ 無法提取任何余額字段")
 	}
 	return 0, false
 }
+
+// MarginRatio 賬戶保證金率（各交易所統一口徑：維持保證金 / 保證金餘額）
+type MarginRatio struct {
+	MaintMargin   float64 // 維持保證金（USDT）
+	MarginBalance float64 // 保證金餘額（錢包餘額 + 未實現盈虧）
+}
+
+// Pct 保證金率（百分比），越接近 100%，越接近交易所強制平倉/自動減倉
+func (m MarginRatio) Pct() float64 {
+	return calculateMarginRatio(m.MaintMargin, m.MarginBalance)
+}
+
+// ParseMarginRatio 從交易所余額信息中提取維持保證金（totalMaintMargin）和保證金餘額（totalMarginBalance）
+// 交易所未返回這兩個字段時 success 為 false
+func ParseMarginRatio(balanceInfo map[string]interface{}) (ratio MarginRatio, success bool) {
+	maintMargin, ok := balanceInfo["totalMaintMargin"].(float64)
+	if !ok {
+		return MarginRatio{}, false
+	}
+	marginBalance, ok := balanceInfo["totalMarginBalance"].(float64)
+	if !ok || marginBalance <= 0 {
+		return MarginRatio{}, false
+	}
+	return MarginRatio{MaintMargin: maintMargin, MarginBalance: marginBalance}, true
+}
+
+// calculateMarginRatio 計算保證金率 = 維持保證金 / 保證金餘額 × 100
+func calculateMarginRatio(margin, equity float64) float64 {
+	if equity <= 0 {
+		return 0
+	}
+	return margin / equity * 100
+}
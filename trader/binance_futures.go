@@ -4,10 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
 	"nofx/decision"
 	"nofx/hook"
+	"nofx/httprecord"
+	"nofx/market"
+	"nofx/metrics"
+	"nofx/netconfig"
+	"nofx/ratelimit"
 	"strconv"
 	"strings"
 	"sync"
@@ -53,23 +59,39 @@ type FuturesTrader struct {
 	balanceCacheTime  time.Time
 	balanceCacheMutex sync.RWMutex
 
-	// 持仓缓存
-	cachedPositions     []map[string]interface{}
-	positionsCacheTime  time.Time
-	positionsCacheMutex sync.RWMutex
+	// 持仓缓存（按币种索引，下单后只刷新相关币种）
+	positions positionCache
 
 	// 缓存有效期（15秒）
 	cacheDuration time.Duration
 
 	// 订单策略配置
-	orderStrategy       string  // Order strategy: "market_only", "conservative_hybrid", "limit_only"
+	orderStrategy       string  // Order strategy: "market_only", "conservative_hybrid", "limit_only", "maker_then_taker"
 	limitPriceOffset    float64 // Limit order price offset percentage (e.g., -0.03 for -0.03%)
-	limitTimeoutSeconds int     // Timeout in seconds before converting to market order
+	limitTimeoutSeconds int     // Timeout in seconds before converting to market order (maker_then_taker: post-only wait)
+	maxSlippageBps      float64 // 开仓市价单最大滑点（基点，0=不限制，直接发市价单）
+
+	// 服务器时钟（签名时间戳偏移）
+	clock *ServerClock
+
+	// 用户数据推送地址（StartAccountStream 使用）
+	userStreamURL string
+
+	// 交易对精度缓存（来自 exchangeInfo 或精度快照）
+	symbolPrecision      map[string]SymbolPrecision
+	precisionRefreshedAt time.Time // 最近一次从 exchangeInfo 刷新的时间（其中没有的交易对在 missingPrecisionTTL 内视为不存在）
+	precisionMutex       sync.RWMutex
 }
 
+// missingPrecisionTTL 缓存未命中时多久内直接以最近一次刷新的结果为准（新上线的交易对在下次定时刷新或超过该时间后被发现）
+const missingPrecisionTTL = 10 * time.Minute
+
 // NewFuturesTrader 创建合约交易器
 func NewFuturesTrader(apiKey, secretKey string, userId string, orderStrategy string, limitPriceOffset float64, limitTimeoutSeconds int) *FuturesTrader {
 	client := futures.NewClient(apiKey, secretKey)
+	// 按接口组限流，429/418 时按 Retry-After 暂停并重试
+	client.HTTPClient = &http.Client{Transport: ratelimit.NewTransport(httprecord.Wrap(netconfig.Transport("binance")), ratelimit.BinanceGroup("binance"))}
+	client.BaseURL = netconfig.BaseURL("binance", client.BaseURL)
 
 	hookRes := hook.HookExec[hook.NewBinanceTraderResult](hook.NEW_BINANCE_TRADER, userId, client)
 	if hookRes != nil && hookRes.GetResult() != nil {
@@ -81,20 +103,26 @@ func NewFuturesTrader(apiKey, secretKey string, userId string, orderStrategy str
 
 // newFuturesTraderWithClient creates a trader with a pre-configured client (for testing)
 func newFuturesTraderWithClient(client *futures.Client, orderStrategy string, limitPriceOffset float64, limitTimeoutSeconds int) *FuturesTrader {
-	// 同步时间，避免 Timestamp ahead 错误
-	syncBinanceServerTime(client)
 	trader := &FuturesTrader{
 		client:              client,
+		clock:               newBinanceServerClock(client),
 		cacheDuration:       15 * time.Second, // 15秒缓存
+		symbolPrecision:     make(map[string]SymbolPrecision),
 		orderStrategy:       orderStrategy,
 		limitPriceOffset:    limitPriceOffset,
 		limitTimeoutSeconds: limitTimeoutSeconds,
+		userStreamURL:       netconfig.BaseURL("binance-ws", futures.BaseWsMainUrl),
 	}
 
+	// 同步时间，避免 Timestamp ahead 错误（之后返回 -1021 时自动重新同步）
+	ctx, cancel := backgroundCallContext()
+	trader.clock.Sync(ctx)
+	cancel()
+
 	// 设置双向持仓模式（Hedge Mode）
 	// 这是必需的，因为代码中使用了 PositionSide (LONG/SHORT)
 	if err := trader.setDualSidePosition(); err != nil {
-		log.Printf("⚠️ 设置双向持仓模式失败: %v (如果已是双向模式则忽略此警告)", err)
+		log.Warn("⚠️ 设置双向持仓模式失败 (如果已是双向模式则忽略此警告)", "error", err)
 	}
 
 	return trader
@@ -102,23 +130,26 @@ func newFuturesTraderWithClient(client *futures.Client, orderStrategy string, li
 
 // setDualSidePosition 设置双向持仓模式（初始化时调用）
 func (t *FuturesTrader) setDualSidePosition() error {
+	ctx, cancel := backgroundCallContext()
+	defer cancel()
+
 	// 尝试设置双向持仓模式
 	err := t.client.NewChangePositionModeService().
 		DualSide(true). // true = 双向持仓（Hedge Mode）
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
-		// 如果错误信息包含"No need to change"，说明已经是双向持仓模式
-		if strings.Contains(err.Error(), "No need to change position side") {
-			log.Printf("  ✓ 账户已是双向持仓模式（Hedge Mode）")
+		// -4059：已经是双向持仓模式
+		if errors.Is(ClassifyError("binance", err), ErrNoChange) {
+			log.Info("  ✓ 账户已是双向持仓模式（Hedge Mode）")
 			return nil
 		}
 		// 其他错误则返回（但在调用方不会中断初始化）
 		return err
 	}
 
-	log.Printf("  ✓ 账户已切换为双向持仓模式（Hedge Mode）")
-	log.Printf("  ℹ️  双向持仓模式允许同时持有多单和空单")
+	log.Info("  ✓ 账户已切换为双向持仓模式（Hedge Mode）")
+	log.Info("  ℹ️ 双向持仓模式允许同时持有多单和空单")
 	return nil
 }
 
@@ -128,55 +159,79 @@ func (t *FuturesTrader) InvalidateBalanceCache() {
 	t.cachedBalance = nil
 	t.balanceCacheTime = time.Time{} // 重置时间为零值
 	t.balanceCacheMutex.Unlock()
-	log.Printf("🔄 已清除余额缓存（交易后自动刷新）")
+	log.Info("🔄 已清除余额缓存（交易后自动刷新）")
 }
 
-// InvalidatePositionsCache 清除持仓缓存（交易后调用以确保数据实时性）
+// InvalidatePositionsCache 清除全部持仓缓存（下次 GetPositions 重新查询全部持仓）
 func (t *FuturesTrader) InvalidatePositionsCache() {
-	t.positionsCacheMutex.Lock()
-	t.cachedPositions = nil
-	t.positionsCacheTime = time.Time{} // 重置时间为零值
-	t.positionsCacheMutex.Unlock()
-	log.Printf("🔄 已清除持仓缓存（交易后自动刷新）")
+	t.positions.invalidateAll()
+	log.Info("🔄 已清除持仓缓存")
 }
 
-// InvalidateAllCaches 清除所有缓存（重大交易操作后调用）
+// InvalidateAllCaches 清除所有缓存
 func (t *FuturesTrader) InvalidateAllCaches() {
 	t.InvalidateBalanceCache()
 	t.InvalidatePositionsCache()
 }
 
-// syncBinanceServerTime 同步币安服务器时间，确保请求时间戳合法
-func syncBinanceServerTime(client *futures.Client) {
-	serverTime, err := client.NewServerTimeService().Do(context.Background())
-	if err != nil {
-		log.Printf("⚠️ 同步币安服务器时间失败: %v", err)
+// invalidateSymbol 交易后清除余额缓存，持仓缓存只让该币种失效
+func (t *FuturesTrader) invalidateSymbol(symbol string) {
+	t.InvalidateBalanceCache()
+	t.positions.invalidate(symbol)
+}
+
+// SubscribePositionEvents 用账户事件总线的持仓推送增量更新持仓缓存，返回取消订阅函数
+func (t *FuturesTrader) SubscribePositionEvents(bus *AccountEventBus) func() {
+	return t.positions.subscribe(bus)
+}
+
+// StartAccountStream 启动用户数据推送，事件发布到 bus（ctx 取消时停止；未配置 API Key 时不启动）
+func (t *FuturesTrader) StartAccountStream(ctx context.Context, bus *AccountEventBus) {
+	if t.client.APIKey == "" {
 		return
 	}
+	NewBinanceUserStream(BinanceUserStreamConfig{Client: t.client, URL: t.userStreamURL}, bus).Start(ctx)
+}
 
-	now := time.Now().UnixMilli()
-	offset := now - serverTime
-	client.TimeOffset = offset
-	log.Printf("⏱ 已同步币安服务器时间，偏移 %dms", offset)
+// newBinanceServerClock 币安服务器时钟：同步后把偏移写入 client.TimeOffset（SDK 用本机时间 - TimeOffset 作为签名时间戳）
+func newBinanceServerClock(client *futures.Client) *ServerClock {
+	clock := NewServerClock("币安", func(ctx context.Context) (time.Time, error) {
+		serverTime, err := client.NewServerTimeService().Do(ctx)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.UnixMilli(serverTime), nil
+	})
+	clock.onSync = func(offset time.Duration) {
+		client.TimeOffset = -offset.Milliseconds()
+	}
+	return clock
+}
+
+// ServerClock 服务器时钟（实现 ClockSyncer）
+func (t *FuturesTrader) ServerClock() *ServerClock {
+	return t.clock
 }
 
 // GetBalance 获取账户余额（带缓存）
-func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
+func (t *FuturesTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
 	// 先检查缓存是否有效
 	t.balanceCacheMutex.RLock()
-	if t.cachedBalance != nil && time.Since(t.balanceCacheTime) < t.cacheDuration {
+	hit := t.cachedBalance != nil && time.Since(t.balanceCacheTime) < t.cacheDuration
+	metrics.CacheLookup("binance_balance", hit)
+	if hit {
 		cacheAge := time.Since(t.balanceCacheTime)
 		t.balanceCacheMutex.RUnlock()
-		log.Printf("✓ 使用缓存的账户余额（缓存时间: %.1f秒前）", cacheAge.Seconds())
+		log.Info("✓ 使用缓存的账户余额", "cache_age_sec", cacheAge.Seconds())
 		return t.cachedBalance, nil
 	}
 	t.balanceCacheMutex.RUnlock()
 
 	// 缓存过期或不存在，调用API
-	log.Printf("🔄 缓存过期，正在调用币安API获取账户余额...")
-	account, err := t.client.NewGetAccountService().Do(context.Background())
+	log.Info("🔄 缓存过期，正在调用币安API获取账户余额...")
+	account, err := t.client.NewGetAccountService().Do(ctx)
 	if err != nil {
-		log.Printf("❌ 币安API调用失败: %v", err)
+		log.Error("❌ 币安API调用失败", "error", err)
 		return nil, fmt.Errorf("获取账户信息失败: %w", err)
 	}
 
@@ -185,10 +240,11 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 	result["availableBalance"], _ = strconv.ParseFloat(account.AvailableBalance, 64)
 	result["totalUnrealizedProfit"], _ = strconv.ParseFloat(account.TotalUnrealizedProfit, 64)
 
-	log.Printf("✓ 币安API返回: 总余额=%s, 可用=%s, 未实现盈亏=%s",
-		account.TotalWalletBalance,
-		account.AvailableBalance,
-		account.TotalUnrealizedProfit)
+	// 维持保证金和保证金余额，用于计算保证金率（见 ParseMarginRatio）
+	result["totalMaintMargin"], _ = strconv.ParseFloat(account.TotalMaintMargin, 64)
+	result["totalMarginBalance"], _ = strconv.ParseFloat(account.TotalMarginBalance, 64)
+
+	log.Info("✓ 币安API返回账户余额", "total_wallet_balance", account.TotalWalletBalance, "available_balance", account.AvailableBalance, "total_unrealized_profit", account.TotalUnrealizedProfit)
 
 	// 更新缓存
 	t.balanceCacheMutex.Lock()
@@ -199,35 +255,80 @@ func (t *FuturesTrader) GetBalance() (map[string]interface{}, error) {
 	return result, nil
 }
 
-// GetPositions 获取所有持仓（带缓存）
-func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
-	// 先检查缓存是否有效
-	t.positionsCacheMutex.RLock()
-	if t.cachedPositions != nil && time.Since(t.positionsCacheTime) < t.cacheDuration {
-		cacheAge := time.Since(t.positionsCacheTime)
-		t.positionsCacheMutex.RUnlock()
-		log.Printf("✓ 使用缓存的持仓信息（缓存时间: %.1f秒前）", cacheAge.Seconds())
-		return t.cachedPositions, nil
+// maxPositionRefreshSymbols 失效币种不超过此数量时逐个刷新，否则重新查询全部持仓
+const maxPositionRefreshSymbols = 3
+
+// GetPositions 获取所有持仓（带缓存：有效期内只单独刷新下单后失效的币种）
+func (t *FuturesTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	positions, dirty, ok := t.positions.all(t.cacheDuration)
+	hit := ok && len(dirty) == 0
+	metrics.CacheLookup("binance_positions", hit)
+	if hit {
+		log.Info("✓ 使用缓存的持仓信息")
+		return positions, nil
+	}
+	if ok && len(dirty) <= maxPositionRefreshSymbols {
+		for _, symbol := range dirty {
+			if _, err := t.refreshPosition(ctx, symbol); err != nil {
+				return nil, err
+			}
+		}
+		if positions, dirty, ok = t.positions.all(t.cacheDuration); ok && len(dirty) == 0 {
+			return positions, nil
+		}
 	}
-	t.positionsCacheMutex.RUnlock()
 
 	// 缓存过期或不存在，调用API
-	log.Printf("🔄 缓存过期，正在调用币安API获取持仓信息...")
-	positions, err := t.client.NewGetPositionRiskService().Do(context.Background())
+	log.Info("🔄 缓存过期，正在调用币安API获取持仓信息...")
+	result, err := t.fetchPositionRisk(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	t.positions.replace(result)
+	return result, nil
+}
+
+// GetPosition 获取单个币种的持仓（实现 PositionGetter：缓存失效时只查询该币种）
+func (t *FuturesTrader) GetPosition(ctx context.Context, symbol string) ([]map[string]interface{}, error) {
+	positions, hit := t.positions.get(symbol, t.cacheDuration)
+	metrics.CacheLookup("binance_positions", hit)
+	if hit {
+		return positions, nil
+	}
+	return t.refreshPosition(ctx, symbol)
+}
+
+// refreshPosition 查询单个币种的持仓并写入缓存
+func (t *FuturesTrader) refreshPosition(ctx context.Context, symbol string) ([]map[string]interface{}, error) {
+	positions, err := t.fetchPositionRisk(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	t.positions.replaceSymbol(symbol, positions)
+	return positions, nil
+}
+
+// fetchPositionRisk 查询持仓（symbol 为空时查询全部），跳过无持仓的条目
+func (t *FuturesTrader) fetchPositionRisk(ctx context.Context, symbol string) ([]map[string]interface{}, error) {
+	service := t.client.NewGetPositionRiskService()
+	if symbol != "" {
+		service = service.Symbol(symbol)
+	}
+	positions, err := service.Do(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
 
-	var result []map[string]interface{}
+	result := []map[string]interface{}{}
 	for _, pos := range positions {
 		posAmt, _ := strconv.ParseFloat(pos.PositionAmt, 64)
-		if posAmt == 0 {
+		if posAmt == 0 || (symbol != "" && pos.Symbol != symbol) {
 			continue // 跳过无持仓的
 		}
 
 		posMap := make(map[string]interface{})
 		posMap["symbol"] = pos.Symbol
-		posMap["positionAmt"], _ = strconv.ParseFloat(pos.PositionAmt, 64)
+		posMap["positionAmt"] = posAmt
 		posMap["entryPrice"], _ = strconv.ParseFloat(pos.EntryPrice, 64)
 		posMap["markPrice"], _ = strconv.ParseFloat(pos.MarkPrice, 64)
 		posMap["unRealizedProfit"], _ = strconv.ParseFloat(pos.UnRealizedProfit, 64)
@@ -243,18 +344,11 @@ func (t *FuturesTrader) GetPositions() ([]map[string]interface{}, error) {
 
 		result = append(result, posMap)
 	}
-
-	// 更新缓存
-	t.positionsCacheMutex.Lock()
-	t.cachedPositions = result
-	t.positionsCacheTime = time.Now()
-	t.positionsCacheMutex.Unlock()
-
 	return result, nil
 }
 
 // SetMarginMode 设置仓位模式
-func (t *FuturesTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+func (t *FuturesTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
 	var marginType futures.MarginType
 	if isCrossMargin {
 		marginType = futures.MarginTypeCrossed
@@ -266,7 +360,7 @@ func (t *FuturesTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	err := t.client.NewChangeMarginTypeService().
 		Symbol(symbol).
 		MarginType(marginType).
-		Do(context.Background())
+		Do(ctx)
 
 	marginModeStr := "全仓"
 	if !isCrossMargin {
@@ -274,85 +368,57 @@ func (t *FuturesTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
 	}
 
 	if err != nil {
-		// 如果错误信息包含"No need to change"，说明仓位模式已经是目标值
-		if contains(err.Error(), "No need to change margin type") {
-			log.Printf("  ✓ %s 仓位模式已是 %s", symbol, marginModeStr)
+		err = ClassifyError("binance", err)
+		switch {
+		case errors.Is(err, ErrNoChange):
+			// 仓位模式已经是目标值
+			log.Info("  ✓ 仓位模式无需更改", "symbol", symbol, "margin_mode", marginModeStr)
 			return nil
-		}
-		// 如果有持仓，无法更改仓位模式，但不影响交易
-		if contains(err.Error(), "Margin type cannot be changed if there exists position") {
-			log.Printf("  ⚠️ %s 有持仓，无法更改仓位模式，继续使用当前模式", symbol)
+		case errors.Is(err, ErrMarginModeLocked):
+			// 有持仓，无法更改仓位模式，但不影响交易
+			log.Warn("  ⚠️ 有持仓，无法更改仓位模式，继续使用当前模式", "symbol", symbol)
 			return nil
-		}
-		// 检测多资产模式（错误码 -4168）
-		if contains(err.Error(), "Multi-Assets mode") || contains(err.Error(), "-4168") || contains(err.Error(), "4168") {
-			log.Printf("  ⚠️ %s 检测到多资产模式，强制使用全仓模式", symbol)
-			log.Printf("  💡 提示：如需使用逐仓模式，请在币安关闭多资产模式")
+		case errors.Is(err, ErrMultiAssetsMode):
+			log.Warn("  ⚠️ 检测到多资产模式，强制使用全仓模式", "symbol", symbol)
+			log.Info("  💡 提示：如需使用逐仓模式，请在币安关闭多资产模式")
 			return nil
+		case errors.Is(err, ErrUnifiedAccount):
+			log.Error("  ❌ 检测到统一账户 API，无法进行合约交易", "symbol", symbol)
+			return fmt.Errorf("请使用「现货与合约交易」API 权限，不要使用「统一账户 API」: %w", err)
 		}
-		// 检测统一账户 API（Portfolio Margin）
-		if contains(err.Error(), "unified") || contains(err.Error(), "portfolio") || contains(err.Error(), "Portfolio") {
-			log.Printf("  ❌ %s 检测到统一账户 API，无法进行合约交易", symbol)
-			return fmt.Errorf("请使用「现货与合约交易」API 权限，不要使用「统一账户 API」")
-		}
-		log.Printf("  ⚠️ 设置仓位模式失败: %v", err)
+		log.Warn("  ⚠️ 设置仓位模式失败", "error", err)
 		// 不返回错误，让交易继续
 		return nil
 	}
 
-	log.Printf("  ✓ %s 仓位模式已设置为 %s", symbol, marginModeStr)
+	log.Info("  ✓ 仓位模式已设置", "symbol", symbol, "margin_mode", marginModeStr)
 	return nil
 }
 
-// SetLeverage 设置杠杆（智能判断+冷却期）
-func (t *FuturesTrader) SetLeverage(symbol string, leverage int) error {
-	// 先尝试获取当前杠杆（从持仓信息）
-	currentLeverage := 0
-	positions, err := t.GetPositions()
-	if err == nil {
-		for _, pos := range positions {
-			if pos["symbol"] == symbol {
-				if lev, ok := pos["leverage"].(float64); ok {
-					currentLeverage = int(lev)
-					break
-				}
-			}
-		}
-	}
-
-	// 如果当前杠杆已经是目标杠杆，跳过
-	if currentLeverage == leverage && currentLeverage > 0 {
-		log.Printf("  ✓ %s 杠杆已是 %dx，无需切换", symbol, leverage)
-		return nil
-	}
-
-	// 切换杠杆
-	_, err = t.client.NewChangeLeverageService().
+// SetLeverage 设置杠杆（是否已是目标杠杆和冷却期重试由 leverageTrader 处理）
+func (t *FuturesTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	_, err := t.client.NewChangeLeverageService().
 		Symbol(symbol).
 		Leverage(leverage).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
-		// 如果错误信息包含"No need to change"，说明杠杆已经是目标值
-		if contains(err.Error(), "No need to change") {
-			log.Printf("  ✓ %s 杠杆已是 %dx", symbol, leverage)
+		// 杠杆已经是目标值
+		if errors.Is(ClassifyError("binance", err), ErrNoChange) {
+			log.Info("  ✓ 杠杆无需更改", "symbol", symbol, "leverage", leverage)
 			return nil
 		}
-		return fmt.Errorf("设置杠杆失败: %w", err)
+		return fmt.Errorf("设置杠杆失败: %w", ClassifyError("binance", err))
 	}
 
-	log.Printf("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
-
-	// 切换杠杆后等待5秒（避免冷却期错误）
-	log.Printf("  ⏱ 等待5秒冷却期...")
-	time.Sleep(5 * time.Second)
-
+	log.Info("  ✓ 杠杆已切换", "symbol", symbol, "leverage", leverage)
+	t.positions.invalidate(symbol)
 	return nil
 }
 
 // GetCurrentPrice 获取当前市场价格
-func (t *FuturesTrader) GetCurrentPrice(symbol string) (float64, error) {
-	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+func (t *FuturesTrader) GetCurrentPrice(ctx context.Context, symbol string) (float64, error) {
+	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取价格失败: %w", err)
 	}
@@ -368,49 +434,100 @@ func (t *FuturesTrader) GetCurrentPrice(symbol string) (float64, error) {
 
 // FormatPrice 格式化价格到交易所要求的精度
 func (t *FuturesTrader) FormatPrice(symbol string, price float64) (string, error) {
-	// 获取交易对信息
-	info, err := t.client.NewExchangeInfoService().Do(context.Background())
+	prec, found, err := t.getSymbolPrecision(symbol)
 	if err != nil {
 		return "", fmt.Errorf("获取交易对信息失败: %w", err)
 	}
+	if !found || prec.TickSize <= 0 {
+		return "", fmt.Errorf("未找到 %s 的价格精度信息", symbol)
+	}
 
-	// 查找对应的symbol信息
-	for _, s := range info.Symbols {
-		if s.Symbol == symbol {
-			// 找到价格精度过滤器
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "PRICE_FILTER" {
-					tickSizeStr := filter["tickSize"].(string)
-					tickSize, err := strconv.ParseFloat(tickSizeStr, 64)
-					if err != nil {
-						return "", fmt.Errorf("解析tickSize失败: %w", err)
-					}
-
-					// 计算精度
-					precision := 0
-					temp := tickSize
-					for temp < 1 {
-						temp *= 10
-						precision++
-					}
-
-					// 格式化价格
-					format := fmt.Sprintf("%%.%df", precision)
-					return fmt.Sprintf(format, price), nil
+	format := fmt.Sprintf("%%.%df", prec.PricePrecision)
+	return fmt.Sprintf(format, price), nil
+}
+
+// getSymbolPrecision 获取交易对精度（首次调用时一次性缓存所有交易对）
+// 最近一次刷新中没有的交易对直接返回未找到，不再为每次查询拉取 exchangeInfo
+func (t *FuturesTrader) getSymbolPrecision(symbol string) (SymbolPrecision, bool, error) {
+	t.precisionMutex.RLock()
+	prec, ok := t.symbolPrecision[symbol]
+	cached := len(t.symbolPrecision) > 0
+	refreshedAt := t.precisionRefreshedAt
+	t.precisionMutex.RUnlock()
+	if ok {
+		return prec, true, nil
+	}
+	if !refreshedAt.IsZero() && time.Since(refreshedAt) < missingPrecisionTTL {
+		return SymbolPrecision{}, false, nil
+	}
+
+	// 缓存未命中（首次调用或新上线的交易对）：拉取 exchangeInfo 刷新缓存
+	ctx, cancel := backgroundCallContext()
+	defer cancel()
+	precisions, err := t.refreshSymbolPrecisions(ctx)
+	if err != nil {
+		if cached {
+			log.Warn("  ⚠ 刷新交易规则失败，不在精度缓存中", "symbol", symbol, "error", err)
+		}
+		return SymbolPrecision{}, false, err
+	}
+
+	prec, ok = precisions[symbol]
+	return prec, ok, nil
+}
+
+// refreshSymbolPrecisions 拉取 exchangeInfo 并替换所有交易对的精度缓存（交易规则可能调整，已下架的交易对一并移除）
+func (t *FuturesTrader) refreshSymbolPrecisions(ctx context.Context) (map[string]SymbolPrecision, error) {
+	exchangeInfo, err := t.client.NewExchangeInfoService().Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(exchangeInfo.Symbols) == 0 {
+		return nil, fmt.Errorf("交易规则为空")
+	}
+
+	precisions := make(map[string]SymbolPrecision, len(exchangeInfo.Symbols))
+	for _, s := range exchangeInfo.Symbols {
+		var p SymbolPrecision
+		for _, filter := range s.Filters {
+			switch filter["filterType"] {
+			case "PRICE_FILTER":
+				if tickSizeStr, ok := filter["tickSize"].(string); ok {
+					p.TickSize, _ = strconv.ParseFloat(tickSizeStr, 64)
+					p.PricePrecision = calculatePrecision(tickSizeStr)
+				}
+			case "LOT_SIZE":
+				if stepSizeStr, ok := filter["stepSize"].(string); ok {
+					p.StepSize, _ = strconv.ParseFloat(stepSizeStr, 64)
+					p.QuantityPrecision = calculatePrecision(stepSizeStr)
 				}
 			}
 		}
+		precisions[s.Symbol] = p
 	}
 
-	return "", fmt.Errorf("未找到 %s 的价格精度信息", symbol)
+	t.precisionMutex.Lock()
+	t.symbolPrecision = precisions
+	t.precisionRefreshedAt = time.Now()
+	t.precisionMutex.Unlock()
+	return precisions, nil
+}
+
+// PreloadInstruments 一次请求拉取全部合约的交易规则并替换精度缓存（实现 InstrumentPreloader）
+func (t *FuturesTrader) PreloadInstruments(ctx context.Context, symbols []string) error {
+	precisions, err := t.refreshSymbolPrecisions(ctx)
+	if err != nil {
+		return fmt.Errorf("获取交易规则失败: %w", err)
+	}
+	return missingInstruments(precisions, symbols)
 }
 
 // QueryOrderStatus 查询订单状态
-func (t *FuturesTrader) QueryOrderStatus(symbol string, orderID int64) (string, error) {
+func (t *FuturesTrader) QueryOrderStatus(ctx context.Context, symbol string, orderID int64) (string, error) {
 	order, err := t.client.NewGetOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
-		Do(context.Background())
+		Do(ctx)
 	if err != nil {
 		return "", fmt.Errorf("查询订单状态失败: %w", err)
 	}
@@ -418,27 +535,77 @@ func (t *FuturesTrader) QueryOrderStatus(symbol string, orderID int64) (string,
 }
 
 // CancelOrder 取消订单
-func (t *FuturesTrader) CancelOrder(symbol string, orderID int64) error {
+func (t *FuturesTrader) CancelOrder(ctx context.Context, symbol string, orderID int64) error {
 	_, err := t.client.NewCancelOrderService().
 		Symbol(symbol).
 		OrderID(orderID).
-		Do(context.Background())
+		Do(ctx)
 	if err != nil {
 		return fmt.Errorf("取消订单失败: %w", err)
 	}
 	return nil
 }
 
+// SetMaxSlippageBps 设置开仓市价单最大滑点（基点，0=不限制）
+func (t *FuturesTrader) SetMaxSlippageBps(bps float64) {
+	t.maxSlippageBps = bps
+}
+
+// placeMarketOrder 下开仓市价单；设置了最大滑点时按最优买卖价转换为 IOC 限价单，价差本身超过上限时拒绝下单
+// 平仓单不做转换：止损/风控平仓不能因为盘口价差过大而平不掉
+func (t *FuturesTrader) placeMarketOrder(ctx context.Context, symbol string, side futures.SideType, positionSide futures.PositionSideType, quantityStr string) (*futures.CreateOrderResponse, error) {
+	service := t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(positionSide).
+		Quantity(quantityStr).
+		NewClientOrderID(getBrOrderID())
+	if t.maxSlippageBps <= 0 {
+		return service.Type(futures.OrderTypeMarket).Do(ctx)
+	}
+
+	tickers, err := t.client.NewListBookTickersService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最优买卖价失败: %w", err)
+	}
+	if len(tickers) == 0 {
+		return nil, fmt.Errorf("获取最优买卖价失败: %s 无盘口数据", symbol)
+	}
+	bid, ask := parseFloatOrZero(tickers[0].BidPrice), parseFloatOrZero(tickers[0].AskPrice)
+	limitPrice, err := slippageLimitPrice(bid, ask, side == futures.SideTypeBuy, t.maxSlippageBps)
+	if err != nil {
+		return nil, err
+	}
+	limitPriceStr, err := t.FormatPrice(symbol, limitPrice)
+	if err != nil {
+		return nil, fmt.Errorf("格式化限价失败: %w", err)
+	}
+	log.Info("🛡️ 滑点保护：市价单转为 IOC 限价", "symbol", symbol, "bid", bid, "ask", ask, "limit_price", limitPriceStr, "max_slippage_bps", t.maxSlippageBps)
+
+	order, err := service.Type(futures.OrderTypeLimit).
+		TimeInForce(futures.TimeInForceTypeIOC).
+		Price(limitPriceStr).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status == futures.OrderStatusTypeExpired && parseFloatOrZero(order.ExecutedQuantity) == 0 {
+		return nil, fmt.Errorf("%w: IOC 限价 %s 未能成交", ErrSlippageExceeded, limitPriceStr)
+	}
+	return order, nil
+}
+
 // monitorAndConvertLimitOrder 监控限价单并在超时时转换为市价单
 // 返回值：最终订单结果, 是否发生了降级, error
 func (t *FuturesTrader) monitorAndConvertLimitOrder(
+	ctx context.Context,
 	symbol string,
 	orderID int64,
 	side futures.SideType,
 	positionSide futures.PositionSideType,
 	quantityStr string,
-) (map[string]interface{}, bool, error) {
-	log.Printf("⏱️  [%s] 开始监控限价单 OrderID=%d，超时时间 %d 秒", symbol, orderID, t.limitTimeoutSeconds)
+) (*ExecutionReport, bool, error) {
+	log.Info("⏱️ 开始监控限价单", "symbol", symbol, "order_id", orderID, "timeout_sec", t.limitTimeoutSeconds)
 
 	ticker := time.NewTicker(1 * time.Second)
 	defer ticker.Stop()
@@ -448,84 +615,84 @@ func (t *FuturesTrader) monitorAndConvertLimitOrder(
 
 	for {
 		select {
+		case <-ctx.Done():
+			// 调用方取消：撤掉未成交的限价单，避免残留挂单
+			cancelCtx, cancel := backgroundCallContext()
+			if err := t.CancelOrder(cancelCtx, symbol, orderID); err != nil {
+				log.Warn("⚠️ 监控被取消，撤销限价单失败", "symbol", symbol, "order_id", orderID, "error", err)
+			}
+			cancel()
+			return nil, false, fmt.Errorf("监控限价单被取消: %w", ctx.Err())
 		case <-ticker.C:
 			// 查询订单状态
-			status, err := t.QueryOrderStatus(symbol, orderID)
+			status, err := t.QueryOrderStatus(ctx, symbol, orderID)
 			if err != nil {
-				log.Printf("⚠️  [%s] 查询订单状态失败: %v", symbol, err)
+				log.Warn("⚠️ 查询订单状态失败", "symbol", symbol, "error", err)
 				continue
 			}
 
 			// 检查是否成交
 			if status == string(futures.OrderStatusTypeFilled) {
-				log.Printf("✅ [%s] 限价单已成交 OrderID=%d", symbol, orderID)
-				result := make(map[string]interface{})
-				result["orderId"] = orderID
-				result["symbol"] = symbol
-				result["status"] = status
-				result["converted"] = false
-				return result, false, nil
+				log.Info("✅ 限价单已成交", "symbol", symbol, "order_id", orderID)
+				report := &ExecutionReport{
+					OrderID:      orderID,
+					Symbol:       symbol,
+					Side:         string(side),
+					PositionSide: string(positionSide),
+					Status:       status,
+					RequestedQty: parseFloatOrZero(quantityStr),
+					SubmittedAt:  startTime,
+					Venue:        VenueBinance,
+				}
+				t.enrichExecutionReport(ctx, report)
+				return report, false, nil
 			}
 
 			// 检查是否超时
 			elapsed := time.Since(startTime)
 			if elapsed >= timeout {
-				log.Printf("⏰ [%s] 限价单超时未成交 (%.1f秒)，转换为市价单", symbol, elapsed.Seconds())
+				log.Info("⏰ 限价单超时未成交，转换为市价单", "symbol", symbol, "elapsed_sec", elapsed.Seconds())
 
 				// 取消限价单
-				if err := t.CancelOrder(symbol, orderID); err != nil {
-					log.Printf("⚠️  [%s] 取消限价单失败: %v，但继续尝试创建市价单", symbol, err)
+				if err := t.CancelOrder(ctx, symbol, orderID); err != nil {
+					log.Warn("⚠️ 取消限价单失败，但继续尝试创建市价单", "symbol", symbol, "error", err)
 				} else {
-					log.Printf("✓ [%s] 已取消限价单 OrderID=%d", symbol, orderID)
+					log.Info("✓ 已取消限价单", "symbol", symbol, "order_id", orderID)
 				}
 
 				// 创建市价单
-				log.Printf("📋 [%s] 创建市价单替代限价单", symbol)
-				marketOrder, err := t.client.NewCreateOrderService().
-					Symbol(symbol).
-					Side(side).
-					PositionSide(positionSide).
-					Type(futures.OrderTypeMarket).
-					Quantity(quantityStr).
-					NewClientOrderID(getBrOrderID()).
-					Do(context.Background())
+				log.Info("📋 创建市价单替代限价单", "symbol", symbol)
+				marketOrder, err := t.placeMarketOrder(ctx, symbol, side, positionSide, quantityStr)
 
 				if err != nil {
 					return nil, true, fmt.Errorf("超时转换为市价单失败: %w", err)
 				}
 
-				log.Printf("✅ [%s] 市价单创建成功 OrderID=%d (从限价单降级)", symbol, marketOrder.OrderID)
-				result := make(map[string]interface{})
-				result["orderId"] = marketOrder.OrderID
-				result["symbol"] = marketOrder.Symbol
-				result["status"] = marketOrder.Status
-				result["converted"] = true
-				result["originalOrderId"] = orderID
-				return result, true, nil
+				log.Info("✅ 市价单创建成功（从限价单降级）", "symbol", symbol, "order_id", marketOrder.OrderID)
+				report := t.newExecutionReport(ctx, marketOrder)
+				report.Converted = true
+				report.OriginalOrderID = orderID
+				return report, true, nil
 			}
 
 			// 显示进度
 			remaining := timeout - elapsed
 			if int(remaining.Seconds())%10 == 0 && remaining.Seconds() > 0 {
-				log.Printf("⏱️  [%s] 等待限价单成交... 剩余 %.0f 秒", symbol, remaining.Seconds())
+				log.Info("⏱️ 等待限价单成交...", "symbol", symbol, "remaining_sec", remaining.Seconds())
 			}
 		}
 	}
 }
 
 // OpenLong 开多仓
-func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *FuturesTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 先取消该币种的所有委托单（清理旧的止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消旧委托单失败（可能没有委托单）", "error", err)
 	}
 
-	// 设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, err
-	}
-
-	// 注意：仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
+	// 注意：杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过），
+	// 仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
 
 	// 格式化数量到正确精度
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -540,26 +707,30 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 	}
 
 	// ✅ 检查最小名义价值（Binance 要求至少 10 USDT）
-	if err := t.CheckMinNotional(symbol, quantityFloat); err != nil {
+	if err := t.CheckMinNotional(ctx, symbol, quantityFloat); err != nil {
 		return nil, err
 	}
 
+	// 挂单优先策略：post-only 挂单超时后剩余数量转市价单
+	if t.orderStrategy == "maker_then_taker" {
+		report, err := t.placeMakerThenTaker(ctx, symbol, futures.SideTypeBuy, futures.PositionSideTypeLong, quantityStr)
+		if err != nil {
+			return nil, fmt.Errorf("开多仓失败: %w", err)
+		}
+		log.Info("✓ 开多仓成功（挂单优先）", "symbol", symbol, "filled_qty", report.FilledQty)
+		t.invalidateSymbol(symbol)
+		return report, nil
+	}
+
 	// 根据订单策略创建订单
 	var order *futures.CreateOrderResponse
 	if t.orderStrategy == "market_only" {
 		// 纯市价单策略
-		log.Printf("📋 [%s] 使用市价单策略", symbol)
-		order, err = t.client.NewCreateOrderService().
-			Symbol(symbol).
-			Side(futures.SideTypeBuy).
-			PositionSide(futures.PositionSideTypeLong).
-			Type(futures.OrderTypeMarket).
-			Quantity(quantityStr).
-			NewClientOrderID(getBrOrderID()).
-			Do(context.Background())
+		log.Info("📋 使用市价单策略", "symbol", symbol)
+		order, err = t.placeMarketOrder(ctx, symbol, futures.SideTypeBuy, futures.PositionSideTypeLong, quantityStr)
 	} else {
 		// 限价单策略（conservative_hybrid 或 limit_only）
-		currentPrice, priceErr := t.GetCurrentPrice(symbol)
+		currentPrice, priceErr := t.GetCurrentPrice(ctx, symbol)
 		if priceErr != nil {
 			return nil, fmt.Errorf("获取当前价格失败: %w", priceErr)
 		}
@@ -572,8 +743,7 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 			return nil, fmt.Errorf("格式化限价失败: %w", formatErr)
 		}
 
-		log.Printf("📋 [%s] 使用限价单策略: 当前价 %.6f, 限价 %s (偏移 %.2f%%)",
-			symbol, currentPrice, limitPriceStr, t.limitPriceOffset)
+		log.Info("📋 使用限价单策略", "symbol", symbol, "current_price", currentPrice, "limit_price", limitPriceStr, "offset_pct", t.limitPriceOffset)
 
 		order, err = t.client.NewCreateOrderService().
 			Symbol(symbol).
@@ -584,29 +754,23 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 			Price(limitPriceStr).
 			TimeInForce(futures.TimeInForceTypeGTC). // Good Till Cancel
 			NewClientOrderID(getBrOrderID()).
-			Do(context.Background())
+			Do(ctx)
 
 		if err != nil {
-			log.Printf("⚠️ 限价单创建失败: %v", err)
+			log.Warn("⚠️ 限价单创建失败", "error", err)
 			// 如果是 conservative_hybrid 策略，失败后可以降级到市价单
 			if t.orderStrategy == "conservative_hybrid" {
-				log.Printf("📋 [%s] 限价单失败，降级为市价单", symbol)
-				order, err = t.client.NewCreateOrderService().
-					Symbol(symbol).
-					Side(futures.SideTypeBuy).
-					PositionSide(futures.PositionSideTypeLong).
-					Type(futures.OrderTypeMarket).
-					Quantity(quantityStr).
-					NewClientOrderID(getBrOrderID()).
-					Do(context.Background())
+				log.Info("📋 限价单失败，降级为市价单", "symbol", symbol)
+				order, err = t.placeMarketOrder(ctx, symbol, futures.SideTypeBuy, futures.PositionSideTypeLong, quantityStr)
 			}
 		} else {
 			// 限价单创建成功
-			log.Printf("✓ 限价单创建成功: %s OrderID=%d", symbol, order.OrderID)
+			log.Info("✓ 限价单创建成功", "symbol", symbol, "order_id", order.OrderID)
 
 			// 如果是 conservative_hybrid 策略，启动监控并在超时时转换为市价单
 			if t.orderStrategy == "conservative_hybrid" {
 				result, converted, monitorErr := t.monitorAndConvertLimitOrder(
+					ctx,
 					symbol,
 					order.OrderID,
 					futures.SideTypeBuy,
@@ -618,17 +782,17 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 				}
 
 				if converted {
-					log.Printf("✓ 开多仓成功（限价单超时转市价单）: %s 数量: %s", symbol, quantityStr)
+					log.Info("✓ 开多仓成功（限价单超时转市价单）", "symbol", symbol, "quantity", quantityStr)
 				} else {
-					log.Printf("✓ 开多仓成功（限价单成交）: %s 数量: %s", symbol, quantityStr)
+					log.Info("✓ 开多仓成功（限价单成交）", "symbol", symbol, "quantity", quantityStr)
 				}
 				// 交易成功后清除缓存
-				t.InvalidateAllCaches()
+				t.invalidateSymbol(symbol)
 				return result, nil
 			}
 
 			// limit_only 策略：直接返回限价单结果，不监控
-			log.Printf("✓ 限价单已提交: %s 数量: %s (limit_only 模式，不自动转换)", symbol, quantityStr)
+			log.Info("✓ 限价单已提交 (limit_only 模式，不自动转换)", "symbol", symbol, "quantity", quantityStr)
 		}
 	}
 
@@ -636,32 +800,24 @@ func (t *FuturesTrader) OpenLong(symbol string, quantity float64, leverage int)
 		return nil, fmt.Errorf("开多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开多仓成功: %s 数量: %s 类型: %s", symbol, quantityStr, order.Type)
-	log.Printf("  订单ID: %d 状态: %s", order.OrderID, order.Status)
+	log.Info("✓ 开多仓成功", "symbol", symbol, "quantity", quantityStr, "type", order.Type)
+	log.Info("  订单已提交", "order_id", order.OrderID, "status", order.Status)
 
 	// 交易成功后清除缓存
-	t.InvalidateAllCaches()
+	t.invalidateSymbol(symbol)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return t.newExecutionReport(ctx, order), nil
 }
 
 // OpenShort 开空仓
-func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *FuturesTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 先取消该币种的所有委托单（清理旧的止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败（可能没有委托单）: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消旧委托单失败（可能没有委托单）", "error", err)
 	}
 
-	// 设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, err
-	}
-
-	// 注意：仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
+	// 注意：杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过），
+	// 仓位模式应该由调用方（AutoTrader）在开仓前通过 SetMarginMode 设置
 
 	// 格式化数量到正确精度
 	quantityStr, err := t.FormatQuantity(symbol, quantity)
@@ -676,26 +832,30 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 	}
 
 	// ✅ 检查最小名义价值（Binance 要求至少 10 USDT）
-	if err := t.CheckMinNotional(symbol, quantityFloat); err != nil {
+	if err := t.CheckMinNotional(ctx, symbol, quantityFloat); err != nil {
 		return nil, err
 	}
 
+	// 挂单优先策略：post-only 挂单超时后剩余数量转市价单
+	if t.orderStrategy == "maker_then_taker" {
+		report, err := t.placeMakerThenTaker(ctx, symbol, futures.SideTypeSell, futures.PositionSideTypeShort, quantityStr)
+		if err != nil {
+			return nil, fmt.Errorf("开空仓失败: %w", err)
+		}
+		log.Info("✓ 开空仓成功（挂单优先）", "symbol", symbol, "filled_qty", report.FilledQty)
+		t.invalidateSymbol(symbol)
+		return report, nil
+	}
+
 	// 根据订单策略创建订单
 	var order *futures.CreateOrderResponse
 	if t.orderStrategy == "market_only" {
 		// 纯市价单策略
-		log.Printf("📋 [%s] 使用市价单策略", symbol)
-		order, err = t.client.NewCreateOrderService().
-			Symbol(symbol).
-			Side(futures.SideTypeSell).
-			PositionSide(futures.PositionSideTypeShort).
-			Type(futures.OrderTypeMarket).
-			Quantity(quantityStr).
-			NewClientOrderID(getBrOrderID()).
-			Do(context.Background())
+		log.Info("📋 使用市价单策略", "symbol", symbol)
+		order, err = t.placeMarketOrder(ctx, symbol, futures.SideTypeSell, futures.PositionSideTypeShort, quantityStr)
 	} else {
 		// 限价单策略（conservative_hybrid 或 limit_only）
-		currentPrice, priceErr := t.GetCurrentPrice(symbol)
+		currentPrice, priceErr := t.GetCurrentPrice(ctx, symbol)
 		if priceErr != nil {
 			return nil, fmt.Errorf("获取当前价格失败: %w", priceErr)
 		}
@@ -708,8 +868,7 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 			return nil, fmt.Errorf("格式化限价失败: %w", formatErr)
 		}
 
-		log.Printf("📋 [%s] 使用限价单策略: 当前价 %.6f, 限价 %s (偏移 %.2f%%)",
-			symbol, currentPrice, limitPriceStr, t.limitPriceOffset)
+		log.Info("📋 使用限价单策略", "symbol", symbol, "current_price", currentPrice, "limit_price", limitPriceStr, "offset_pct", t.limitPriceOffset)
 
 		order, err = t.client.NewCreateOrderService().
 			Symbol(symbol).
@@ -720,29 +879,23 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 			Price(limitPriceStr).
 			TimeInForce(futures.TimeInForceTypeGTC). // Good Till Cancel
 			NewClientOrderID(getBrOrderID()).
-			Do(context.Background())
+			Do(ctx)
 
 		if err != nil {
-			log.Printf("⚠️ 限价单创建失败: %v", err)
+			log.Warn("⚠️ 限价单创建失败", "error", err)
 			// 如果是 conservative_hybrid 策略，失败后可以降级到市价单
 			if t.orderStrategy == "conservative_hybrid" {
-				log.Printf("📋 [%s] 限价单失败，降级为市价单", symbol)
-				order, err = t.client.NewCreateOrderService().
-					Symbol(symbol).
-					Side(futures.SideTypeSell).
-					PositionSide(futures.PositionSideTypeShort).
-					Type(futures.OrderTypeMarket).
-					Quantity(quantityStr).
-					NewClientOrderID(getBrOrderID()).
-					Do(context.Background())
+				log.Info("📋 限价单失败，降级为市价单", "symbol", symbol)
+				order, err = t.placeMarketOrder(ctx, symbol, futures.SideTypeSell, futures.PositionSideTypeShort, quantityStr)
 			}
 		} else {
 			// 限价单创建成功
-			log.Printf("✓ 限价单创建成功: %s OrderID=%d", symbol, order.OrderID)
+			log.Info("✓ 限价单创建成功", "symbol", symbol, "order_id", order.OrderID)
 
 			// 如果是 conservative_hybrid 策略，启动监控并在超时时转换为市价单
 			if t.orderStrategy == "conservative_hybrid" {
 				result, converted, monitorErr := t.monitorAndConvertLimitOrder(
+					ctx,
 					symbol,
 					order.OrderID,
 					futures.SideTypeSell,
@@ -754,17 +907,17 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 				}
 
 				if converted {
-					log.Printf("✓ 开空仓成功（限价单超时转市价单）: %s 数量: %s", symbol, quantityStr)
+					log.Info("✓ 开空仓成功（限价单超时转市价单）", "symbol", symbol, "quantity", quantityStr)
 				} else {
-					log.Printf("✓ 开空仓成功（限价单成交）: %s 数量: %s", symbol, quantityStr)
+					log.Info("✓ 开空仓成功（限价单成交）", "symbol", symbol, "quantity", quantityStr)
 				}
 				// 交易成功后清除缓存
-				t.InvalidateAllCaches()
+				t.invalidateSymbol(symbol)
 				return result, nil
 			}
 
 			// limit_only 策略：直接返回限价单结果，不监控
-			log.Printf("✓ 限价单已提交: %s 数量: %s (limit_only 模式，不自动转换)", symbol, quantityStr)
+			log.Info("✓ 限价单已提交 (limit_only 模式，不自动转换)", "symbol", symbol, "quantity", quantityStr)
 		}
 	}
 
@@ -772,24 +925,20 @@ func (t *FuturesTrader) OpenShort(symbol string, quantity float64, leverage int)
 		return nil, fmt.Errorf("开空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开空仓成功: %s 数量: %s 类型: %s", symbol, quantityStr, order.Type)
-	log.Printf("  订单ID: %d 状态: %s", order.OrderID, order.Status)
+	log.Info("✓ 开空仓成功", "symbol", symbol, "quantity", quantityStr, "type", order.Type)
+	log.Info("  订单已提交", "order_id", order.OrderID, "status", order.Status)
 
 	// 交易成功后清除缓存
-	t.InvalidateAllCaches()
+	t.invalidateSymbol(symbol)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return t.newExecutionReport(ctx, order), nil
 }
 
 // CloseLong 平多仓
-func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPosition(ctx, symbol)
 		if err != nil {
 			return nil, err
 		}
@@ -820,34 +969,30 @@ func (t *FuturesTrader) CloseLong(symbol string, quantity float64) (map[string]i
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
 		NewClientOrderID(getBrOrderID()).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("平多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %s", symbol, quantityStr)
+	log.Info("✓ 平多仓成功", "symbol", symbol, "quantity", quantityStr)
 
 	// 平仓后取消该币种的所有挂单（止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败", "error", err)
 	}
 
 	// 交易成功后清除缓存
-	t.InvalidateAllCaches()
+	t.invalidateSymbol(symbol)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return t.newExecutionReport(ctx, order), nil
 }
 
 // CloseShort 平空仓
-func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *FuturesTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPosition(ctx, symbol)
 		if err != nil {
 			return nil, err
 		}
@@ -878,35 +1023,94 @@ func (t *FuturesTrader) CloseShort(symbol string, quantity float64) (map[string]
 		Type(futures.OrderTypeMarket).
 		Quantity(quantityStr).
 		NewClientOrderID(getBrOrderID()).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return nil, fmt.Errorf("平空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平空仓成功: %s 数量: %s", symbol, quantityStr)
+	log.Info("✓ 平空仓成功", "symbol", symbol, "quantity", quantityStr)
 
 	// 平仓后取消该币种的所有挂单（止损止盈单）
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败", "error", err)
 	}
 
 	// 交易成功后清除缓存
-	t.InvalidateAllCaches()
+	t.invalidateSymbol(symbol)
 
-	result := make(map[string]interface{})
-	result["orderId"] = order.OrderID
-	result["symbol"] = order.Symbol
-	result["status"] = order.Status
-	return result, nil
+	return t.newExecutionReport(ctx, order), nil
+}
+
+// newExecutionReport 将币安下单响应转换为执行报告，并补充实际成交数据
+func (t *FuturesTrader) newExecutionReport(ctx context.Context, order *futures.CreateOrderResponse) *ExecutionReport {
+	report := &ExecutionReport{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        order.Symbol,
+		Side:          string(order.Side),
+		PositionSide:  string(order.PositionSide),
+		Status:        string(order.Status),
+		RequestedQty:  parseFloatOrZero(order.OrigQuantity),
+		FilledQty:     parseFloatOrZero(order.ExecutedQuantity),
+		AvgPrice:      parseFloatOrZero(order.AvgPrice),
+		SubmittedAt:   time.Now(),
+		UpdatedAt:     msToTime(order.UpdateTime),
+		Venue:         VenueBinance,
+	}
+	t.enrichExecutionReport(ctx, report)
+	return report
+}
+
+// enrichExecutionReport 查询订单和成交明细，补充成交数量、均价和手续费
+// 成交明细可用时以逐笔成交计算的加权均价为准
+// 市价单下单响应通常尚未包含成交信息（status=NEW, executedQty=0），需要再查询一次
+// 查询失败不影响交易结果，仅记录日志
+func (t *FuturesTrader) enrichExecutionReport(ctx context.Context, report *ExecutionReport) {
+	order, err := t.client.NewGetOrderService().
+		Symbol(report.Symbol).
+		OrderID(report.OrderID).
+		Do(ctx)
+	if err != nil {
+		log.Warn("  ⚠ 查询订单成交信息失败", "order_id", report.OrderID, "error", err)
+	} else if executedQty := parseFloatOrZero(order.ExecutedQuantity); executedQty > 0 {
+		report.Status = string(order.Status)
+		report.FilledQty = executedQty
+		report.AvgPrice = parseFloatOrZero(order.AvgPrice)
+		report.UpdatedAt = msToTime(order.UpdateTime)
+	}
+
+	if !report.IsFilled() {
+		return
+	}
+
+	trades, err := t.client.NewListAccountTradeService().
+		Symbol(report.Symbol).
+		OrderID(report.OrderID).
+		Do(ctx)
+	if err != nil {
+		log.Warn("  ⚠ 查询成交手续费失败", "order_id", report.OrderID, "error", err)
+		return
+	}
+
+	fills := make([]tradeFill, 0, len(trades))
+	for _, trade := range trades {
+		fills = append(fills, tradeFill{
+			Price:    parseFloatOrZero(trade.Price),
+			Quantity: parseFloatOrZero(trade.Quantity),
+			Fee:      parseFloatOrZero(trade.Commission),
+			FeeAsset: trade.CommissionAsset,
+		})
+	}
+	report.applyFills(fills)
 }
 
 // CancelStopLossOrders 仅取消止损单（不影响止盈单）
-func (t *FuturesTrader) CancelStopLossOrders(symbol string) error {
+func (t *FuturesTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	orders, err := t.client.NewListOpenOrdersService().
 		Symbol(symbol).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
@@ -923,24 +1127,24 @@ func (t *FuturesTrader) CancelStopLossOrders(symbol string) error {
 			_, err := t.client.NewCancelOrderService().
 				Symbol(symbol).
 				OrderID(order.OrderID).
-				Do(context.Background())
+				Do(ctx)
 
 			if err != nil {
 				errMsg := fmt.Sprintf("订单ID %d: %v", order.OrderID, err)
 				cancelErrors = append(cancelErrors, fmt.Errorf("%s", errMsg))
-				log.Printf("  ⚠ 取消止损单失败: %s", errMsg)
+				log.Warn("  ⚠ 取消止损单失败", "error", errMsg)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消止损单 (订单ID: %d, 类型: %s, 方向: %s)", order.OrderID, orderType, order.PositionSide)
+			log.Info("  ✓ 已取消止损单", "order_id", order.OrderID, "order_type", orderType, "position_side", order.PositionSide)
 		}
 	}
 
 	if canceledCount == 0 && len(cancelErrors) == 0 {
-		log.Printf("  ℹ %s 没有止损单需要取消", symbol)
+		log.Info("  ℹ 没有止损单需要取消", "symbol", symbol)
 	} else if canceledCount > 0 {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止损单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止损单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	// 如果所有取消都失败了，返回错误
@@ -952,11 +1156,11 @@ func (t *FuturesTrader) CancelStopLossOrders(symbol string) error {
 }
 
 // CancelTakeProfitOrders 仅取消止盈单（不影响止损单）
-func (t *FuturesTrader) CancelTakeProfitOrders(symbol string) error {
+func (t *FuturesTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	orders, err := t.client.NewListOpenOrdersService().
 		Symbol(symbol).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
@@ -973,24 +1177,24 @@ func (t *FuturesTrader) CancelTakeProfitOrders(symbol string) error {
 			_, err := t.client.NewCancelOrderService().
 				Symbol(symbol).
 				OrderID(order.OrderID).
-				Do(context.Background())
+				Do(ctx)
 
 			if err != nil {
 				errMsg := fmt.Sprintf("订单ID %d: %v", order.OrderID, err)
 				cancelErrors = append(cancelErrors, fmt.Errorf("%s", errMsg))
-				log.Printf("  ⚠ 取消止盈单失败: %s", errMsg)
+				log.Warn("  ⚠ 取消止盈单失败", "error", errMsg)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消止盈单 (订单ID: %d, 类型: %s, 方向: %s)", order.OrderID, orderType, order.PositionSide)
+			log.Info("  ✓ 已取消止盈单", "order_id", order.OrderID, "order_type", orderType, "position_side", order.PositionSide)
 		}
 	}
 
 	if canceledCount == 0 && len(cancelErrors) == 0 {
-		log.Printf("  ℹ %s 没有止盈单需要取消", symbol)
+		log.Info("  ℹ 没有止盈单需要取消", "symbol", symbol)
 	} else if canceledCount > 0 {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止盈单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止盈单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	// 如果所有取消都失败了，返回错误
@@ -1002,25 +1206,25 @@ func (t *FuturesTrader) CancelTakeProfitOrders(symbol string) error {
 }
 
 // CancelAllOrders 取消该币种的所有挂单
-func (t *FuturesTrader) CancelAllOrders(symbol string) error {
+func (t *FuturesTrader) CancelAllOrders(ctx context.Context, symbol string) error {
 	err := t.client.NewCancelAllOpenOrdersService().
 		Symbol(symbol).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("取消挂单失败: %w", err)
 	}
 
-	log.Printf("  ✓ 已取消 %s 的所有挂单", symbol)
+	log.Info("  ✓ 已取消所有挂单", "symbol", symbol)
 	return nil
 }
 
 // CancelStopOrders 取消该币种的止盈/止损单（用于调整止盈止损位置）
-func (t *FuturesTrader) CancelStopOrders(symbol string) error {
+func (t *FuturesTrader) CancelStopOrders(ctx context.Context, symbol string) error {
 	// 获取该币种的所有未完成订单
 	orders, err := t.client.NewListOpenOrdersService().
 		Symbol(symbol).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("获取未完成订单失败: %w", err)
@@ -1040,31 +1244,30 @@ func (t *FuturesTrader) CancelStopOrders(symbol string) error {
 			_, err := t.client.NewCancelOrderService().
 				Symbol(symbol).
 				OrderID(order.OrderID).
-				Do(context.Background())
+				Do(ctx)
 
 			if err != nil {
-				log.Printf("  ⚠ 取消订单 %d 失败: %v", order.OrderID, err)
+				log.Warn("  ⚠ 取消订单失败", "order_id", order.OrderID, "error", err)
 				continue
 			}
 
 			canceledCount++
-			log.Printf("  ✓ 已取消 %s 的止盈/止损单 (订单ID: %d, 类型: %s)",
-				symbol, order.OrderID, orderType)
+			log.Info("  ✓ 已取消止盈/止损单", "symbol", symbol, "order_id", order.OrderID, "order_type", orderType)
 		}
 	}
 
 	if canceledCount == 0 {
-		log.Printf("  ℹ %s 没有止盈/止损单需要取消", symbol)
+		log.Info("  ℹ 没有止盈/止损单需要取消", "symbol", symbol)
 	} else {
-		log.Printf("  ✓ 已取消 %s 的 %d 个止盈/止损单", symbol, canceledCount)
+		log.Info("  ✓ 已取消止盈/止损单", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	return nil
 }
 
 // GetMarketPrice 获取市场价格
-func (t *FuturesTrader) GetMarketPrice(symbol string) (float64, error) {
-	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(context.Background())
+func (t *FuturesTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
+	prices, err := t.client.NewListPricesService().Symbol(symbol).Do(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取价格失败: %w", err)
 	}
@@ -1081,6 +1284,52 @@ func (t *FuturesTrader) GetMarketPrice(symbol string) (float64, error) {
 	return price, nil
 }
 
+// GetFundingRate 获取当前（预测）资金费率
+func (t *FuturesTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	indexes, err := t.client.NewPremiumIndexService().Symbol(symbol).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+
+	if len(indexes) == 0 {
+		return nil, fmt.Errorf("未找到 %s 的资金费率", symbol)
+	}
+
+	rate, err := strconv.ParseFloat(indexes[0].LastFundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("资金费率格式错误: %w", err)
+	}
+
+	return &market.FundingRate{
+		Symbol:      symbol,
+		Rate:        rate,
+		FundingTime: indexes[0].NextFundingTime,
+	}, nil
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (t *FuturesTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	rates, err := t.client.NewFundingRateService().Symbol(symbol).Limit(n).Do(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率历史失败: %w", err)
+	}
+
+	history := make([]market.FundingRate, 0, len(rates))
+	for _, r := range rates {
+		rate, err := strconv.ParseFloat(r.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, market.FundingRate{
+			Symbol:      r.Symbol,
+			Rate:        rate,
+			FundingTime: r.FundingTime,
+		})
+	}
+
+	return history, nil
+}
+
 // CalculatePositionSize 计算仓位大小
 func (t *FuturesTrader) CalculatePositionSize(balance, riskPercent, price float64, leverage int) float64 {
 	riskAmount := balance * (riskPercent / 100.0)
@@ -1090,7 +1339,7 @@ func (t *FuturesTrader) CalculatePositionSize(balance, riskPercent, price float6
 }
 
 // SetStopLoss 设置止损单
-func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+func (t *FuturesTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
 	var side futures.SideType
 	var posSide futures.PositionSideType
 
@@ -1117,21 +1366,63 @@ func (t *FuturesTrader) SetStopLoss(symbol string, positionSide string, quantity
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("设置止损失败: %w", err)
 	}
 
 	// 设置止损后清除持仓缓存（掛單會影響持倉信息）
-	t.InvalidatePositionsCache()
+	t.positions.invalidate(symbol)
 
-	log.Printf("  止损价设置: %.4f", stopPrice)
+	log.Info("  止损价设置", "stop_price", stopPrice)
+	return nil
+}
+
+// AmendStopLoss 修改持仓止损价（实现 StopLossAmender）：币安不支持修改条件单，
+// 先按数量挂新止损单、成功后再撤销旧止损单，保证两次请求之间持仓始终有止损保护
+func (t *FuturesTrader) AmendStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	orders, err := t.GetOpenOrders(ctx, symbol)
+	if err != nil {
+		return err
+	}
+
+	side, posSide := futures.SideTypeSell, futures.PositionSideTypeLong
+	if positionSide == "SHORT" {
+		side, posSide = futures.SideTypeBuy, futures.PositionSideTypeShort
+	}
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return err
+	}
+	_, err = t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(posSide).
+		Type(futures.OrderTypeStopMarket).
+		StopPrice(fmt.Sprintf("%.8f", stopPrice)).
+		Quantity(quantityStr).
+		WorkingType(futures.WorkingTypeContractPrice).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置新止损失败: %w", err)
+	}
+	t.positions.invalidate(symbol)
+
+	for _, order := range orders {
+		if order.Type == string(futures.OrderTypeStopMarket) && order.PositionSide == string(posSide) {
+			if err := t.CancelOrder(ctx, symbol, order.OrderID); err != nil {
+				return fmt.Errorf("撤销旧止损单 %d 失败: %w", order.OrderID, err)
+			}
+		}
+	}
+
+	log.Info("  止损价修改", "stop_price", stopPrice)
 	return nil
 }
 
 // SetTakeProfit 设置止盈单
-func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+func (t *FuturesTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
 	var side futures.SideType
 	var posSide futures.PositionSideType
 
@@ -1158,16 +1449,46 @@ func (t *FuturesTrader) SetTakeProfit(symbol string, positionSide string, quanti
 		Quantity(quantityStr).
 		WorkingType(futures.WorkingTypeContractPrice).
 		ClosePosition(true).
-		Do(context.Background())
+		Do(ctx)
 
 	if err != nil {
 		return fmt.Errorf("设置止盈失败: %w", err)
 	}
 
 	// 设置止盈后清除持仓缓存（掛單會影響持倉信息）
-	t.InvalidatePositionsCache()
+	t.positions.invalidate(symbol)
 
-	log.Printf("  止盈价设置: %.4f", takeProfitPrice)
+	log.Info("  止盈价设置", "take_profit_price", takeProfitPrice)
+	return nil
+}
+
+// SetPartialTakeProfit 按数量设置止盈单（实现 PartialTakeProfitSetter，不使用 closePosition，用于分批止盈）
+func (t *FuturesTrader) SetPartialTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	side, posSide := futures.SideTypeSell, futures.PositionSideTypeLong
+	if positionSide == "SHORT" {
+		side, posSide = futures.SideTypeBuy, futures.PositionSideTypeShort
+	}
+
+	quantityStr, err := t.FormatQuantity(symbol, quantity)
+	if err != nil {
+		return err
+	}
+
+	_, err = t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(posSide).
+		Type(futures.OrderTypeTakeProfitMarket).
+		StopPrice(fmt.Sprintf("%.8f", takeProfitPrice)).
+		Quantity(quantityStr).
+		WorkingType(futures.WorkingTypeContractPrice).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("设置分批止盈失败: %w", err)
+	}
+
+	t.positions.invalidate(symbol)
+	log.Info("  分批止盈设置", "quantity", quantityStr, "take_profit_price", takeProfitPrice)
 	return nil
 }
 
@@ -1178,8 +1499,8 @@ func (t *FuturesTrader) GetMinNotional(symbol string) float64 {
 }
 
 // CheckMinNotional 检查订单是否满足最小名义价值要求
-func (t *FuturesTrader) CheckMinNotional(symbol string, quantity float64) error {
-	price, err := t.GetMarketPrice(symbol)
+func (t *FuturesTrader) CheckMinNotional(ctx context.Context, symbol string, quantity float64) error {
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return fmt.Errorf("获取市价失败: %w", err)
 	}
@@ -1197,28 +1518,62 @@ func (t *FuturesTrader) CheckMinNotional(symbol string, quantity float64) error
 	return nil
 }
 
+// OrderLimits 获取最小下单要求（实现 OrderLimitsProvider）
+func (t *FuturesTrader) OrderLimits(ctx context.Context, symbol string) (OrderLimits, error) {
+	prec, _, err := t.getSymbolPrecision(symbol)
+	if err != nil {
+		return OrderLimits{}, fmt.Errorf("获取交易规则失败: %w", err)
+	}
+	return OrderLimits{MinNotional: t.GetMinNotional(symbol), StepSize: prec.StepSize}, nil
+}
+
+// ExportPrecisionSnapshot 导出所有交易对精度（实现 PrecisionSnapshotter）
+func (t *FuturesTrader) ExportPrecisionSnapshot() (*PrecisionSnapshot, error) {
+	t.precisionMutex.RLock()
+	empty := len(t.symbolPrecision) == 0
+	t.precisionMutex.RUnlock()
+
+	if empty {
+		ctx, cancel := backgroundCallContext()
+		defer cancel()
+		if _, err := t.refreshSymbolPrecisions(ctx); err != nil {
+			return nil, fmt.Errorf("获取交易规则失败: %w", err)
+		}
+	}
+
+	t.precisionMutex.RLock()
+	defer t.precisionMutex.RUnlock()
+	symbols := make(map[string]SymbolPrecision, len(t.symbolPrecision))
+	for symbol, prec := range t.symbolPrecision {
+		symbols[symbol] = prec
+	}
+	return &PrecisionSnapshot{Exchange: VenueBinance, CreatedAt: time.Now(), Symbols: symbols}, nil
+}
+
+// LoadPrecisionSnapshot 载入精度快照（实现 PrecisionSnapshotter）
+func (t *FuturesTrader) LoadPrecisionSnapshot(snapshot *PrecisionSnapshot) {
+	t.precisionMutex.Lock()
+	defer t.precisionMutex.Unlock()
+	if t.symbolPrecision == nil {
+		t.symbolPrecision = make(map[string]SymbolPrecision)
+	}
+	for symbol, prec := range snapshot.Symbols {
+		t.symbolPrecision[symbol] = prec
+	}
+}
+
 // GetSymbolPrecision 获取交易对的数量精度
 func (t *FuturesTrader) GetSymbolPrecision(symbol string) (int, error) {
-	exchangeInfo, err := t.client.NewExchangeInfoService().Do(context.Background())
+	prec, found, err := t.getSymbolPrecision(symbol)
 	if err != nil {
 		return 0, fmt.Errorf("获取交易规则失败: %w", err)
 	}
 
-	for _, s := range exchangeInfo.Symbols {
-		if s.Symbol == symbol {
-			// 从LOT_SIZE filter获取精度
-			for _, filter := range s.Filters {
-				if filter["filterType"] == "LOT_SIZE" {
-					stepSize := filter["stepSize"].(string)
-					precision := calculatePrecision(stepSize)
-					log.Printf("  %s 数量精度: %d (stepSize: %s)", symbol, precision, stepSize)
-					return precision, nil
-				}
-			}
-		}
+	if found && prec.StepSize > 0 {
+		return prec.QuantityPrecision, nil
 	}
 
-	log.Printf("  ⚠ %s 未找到精度信息，使用默认精度3", symbol)
+	log.Warn("  ⚠ 未找到精度信息，使用默认精度3", "symbol", symbol)
 	return 3, nil // 默认精度为3
 }
 
@@ -1248,7 +1603,7 @@ func calculatePrecision(stepSize string) int {
 // trimTrailingZeros 去除尾部的0
 func trimTrailingZeros(s string) string {
 	// 如果没有小数点，直接返回
-	if !stringContains(s, ".") {
+	if !strings.Contains(s, ".") {
 		return s
 	}
 
@@ -1278,14 +1633,14 @@ func (t *FuturesTrader) FormatQuantity(symbol string, quantity float64) (string,
 }
 
 // GetOpenOrders retrieves open orders for AI decision context
-func (t *FuturesTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo, error) {
+func (t *FuturesTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
 	// 使用 Binance SDK 查詢未成交訂單
 	service := t.client.NewListOpenOrdersService()
 	if symbol != "" {
 		service = service.Symbol(symbol)
 	}
 
-	orders, err := service.Do(context.Background())
+	orders, err := service.Do(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("獲取未成交訂單失敗: %w", err)
 	}
@@ -1296,19 +1651,19 @@ func (t *FuturesTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo,
 		// 解析價格和數量（跳過無效數據）
 		price, err := strconv.ParseFloat(order.Price, 64)
 		if err != nil {
-			log.Printf("⚠️ 解析訂單價格失敗 (OrderID: %d): %v", order.OrderID, err)
+			log.Warn("⚠️ 解析訂單價格失敗", "order_id", order.OrderID, "error", err)
 			continue
 		}
 
 		stopPrice, err := strconv.ParseFloat(order.StopPrice, 64)
 		if err != nil {
-			log.Printf("⚠️ 解析止損價失敗 (OrderID: %d): %v", order.OrderID, err)
+			log.Warn("⚠️ 解析止損價失敗", "order_id", order.OrderID, "error", err)
 			stopPrice = 0 // 止損價可選，設置為0
 		}
 
 		quantity, err := strconv.ParseFloat(order.OrigQuantity, 64)
 		if err != nil {
-			log.Printf("⚠️ 解析訂單數量失敗 (OrderID: %d): %v", order.OrderID, err)
+			log.Warn("⚠️ 解析訂單數量失敗", "order_id", order.OrderID, "error", err)
 			continue
 		}
 
@@ -1325,20 +1680,6 @@ func (t *FuturesTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo,
 		result = append(result, orderInfo)
 	}
 
-	log.Printf("✓ 查詢到 %d 個未成交訂單", len(result))
+	log.Info("✓ 查詢到未成交訂單", "order_count", len(result))
 	return result, nil
 }
-
-// 辅助函数
-func contains(s, substr string) bool {
-	return len(s) >= len(substr) && stringContains(s, substr)
-}
-
-func stringContains(s, substr string) bool {
-	for i := 0; i <= len(s)-len(substr); i++ {
-		if s[i:i+len(substr)] == substr {
-			return true
-		}
-	}
-	return false
-}
@@ -1,6 +1,7 @@
 package trader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -488,7 +489,7 @@ func TestInvalidateBalanceCache(t *testing.T) {
 	trader.cacheDuration = 1 * time.Hour // 启用长时间缓存以便测试
 
 	// 1. 第一次调用 GetBalance 填充缓存
-	balance1, err := trader.GetBalance()
+	balance1, err := trader.GetBalance(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, balance1)
 
@@ -504,12 +505,24 @@ func TestInvalidateBalanceCache(t *testing.T) {
 	assert.True(t, trader.balanceCacheTime.IsZero(), "缓存时间应该被重置为零值")
 
 	// 4. 再次调用 GetBalance 应该重新从 API 获取（而非缓存）
-	balance2, err := trader.GetBalance()
+	balance2, err := trader.GetBalance(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, balance2)
 	assert.NotNil(t, trader.cachedBalance, "缓存应该重新填充")
 }
 
+// positionsCached 全部持仓是否可直接从缓存读取
+func positionsCached(trader *FuturesTrader) bool {
+	_, dirty, ok := trader.positions.all(trader.cacheDuration)
+	return ok && len(dirty) == 0
+}
+
+// symbolCached 该币种的持仓是否可直接从缓存读取
+func symbolCached(trader *FuturesTrader, symbol string) bool {
+	_, ok := trader.positions.get(symbol, trader.cacheDuration)
+	return ok
+}
+
 // TestInvalidatePositionsCache 测试清除持仓缓存
 func TestInvalidatePositionsCache(t *testing.T) {
 	suite := NewBinanceFuturesTestSuite(t)
@@ -519,26 +532,24 @@ func TestInvalidatePositionsCache(t *testing.T) {
 	trader.cacheDuration = 1 * time.Hour // 启用长时间缓存以便测试
 
 	// 1. 第一次调用 GetPositions 填充缓存
-	positions1, err := trader.GetPositions()
+	positions1, err := trader.GetPositions(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, positions1)
 
 	// 验证缓存已被填充
-	assert.NotNil(t, trader.cachedPositions, "缓存应该被填充")
-	assert.False(t, trader.positionsCacheTime.IsZero(), "缓存时间应该被设置")
+	assert.True(t, positionsCached(trader), "缓存应该被填充")
 
 	// 2. 清除缓存
 	trader.InvalidatePositionsCache()
 
 	// 3. 验证缓存已被清除
-	assert.Nil(t, trader.cachedPositions, "缓存应该被清除")
-	assert.True(t, trader.positionsCacheTime.IsZero(), "缓存时间应该被重置为零值")
+	assert.False(t, positionsCached(trader), "缓存应该被清除")
 
 	// 4. 再次调用 GetPositions 应该重新从 API 获取（而非缓存）
-	positions2, err := trader.GetPositions()
+	positions2, err := trader.GetPositions(context.Background())
 	assert.NoError(t, err)
 	assert.NotNil(t, positions2)
-	assert.NotNil(t, trader.cachedPositions, "缓存应该重新填充")
+	assert.True(t, positionsCached(trader), "缓存应该重新填充")
 }
 
 // TestInvalidateAllCaches 测试清除所有缓存
@@ -550,14 +561,14 @@ func TestInvalidateAllCaches(t *testing.T) {
 	trader.cacheDuration = 1 * time.Hour // 启用长时间缓存以便测试
 
 	// 1. 填充所有缓存
-	_, err := trader.GetBalance()
+	_, err := trader.GetBalance(context.Background())
 	assert.NoError(t, err)
-	_, err = trader.GetPositions()
+	_, err = trader.GetPositions(context.Background())
 	assert.NoError(t, err)
 
 	// 验证两个缓存都被填充
 	assert.NotNil(t, trader.cachedBalance, "余额缓存应该被填充")
-	assert.NotNil(t, trader.cachedPositions, "持仓缓存应该被填充")
+	assert.True(t, positionsCached(trader), "持仓缓存应该被填充")
 
 	// 2. 清除所有缓存
 	trader.InvalidateAllCaches()
@@ -565,8 +576,7 @@ func TestInvalidateAllCaches(t *testing.T) {
 	// 3. 验证所有缓存都被清除
 	assert.Nil(t, trader.cachedBalance, "余额缓存应该被清除")
 	assert.True(t, trader.balanceCacheTime.IsZero(), "余额缓存时间应该被重置")
-	assert.Nil(t, trader.cachedPositions, "持仓缓存应该被清除")
-	assert.True(t, trader.positionsCacheTime.IsZero(), "持仓缓存时间应该被重置")
+	assert.False(t, positionsCached(trader), "持仓缓存应该被清除")
 }
 
 // TestTradeOperationsInvalidateCache 测试交易操作自动清除缓存
@@ -580,97 +590,126 @@ func TestTradeOperationsInvalidateCache(t *testing.T) {
 	// 子测试1：OpenLong 后缓存被清除
 	t.Run("OpenLong_invalidates_cache", func(t *testing.T) {
 		// 填充缓存
-		_, _ = trader.GetBalance()
-		_, _ = trader.GetPositions()
+		_, _ = trader.GetBalance(context.Background())
+		_, _ = trader.GetPositions(context.Background())
 		assert.NotNil(t, trader.cachedBalance, "开仓前余额缓存应该存在")
-		assert.NotNil(t, trader.cachedPositions, "开仓前持仓缓存应该存在")
+		assert.True(t, positionsCached(trader), "开仓前持仓缓存应该存在")
 
 		// 执行开多仓
-		_, err := trader.OpenLong("BTCUSDT", 0.01, 10)
+		_, err := trader.OpenLong(context.Background(), "BTCUSDT", 0.01, 10)
 		assert.NoError(t, err)
 
 		// 验证缓存被清除
 		assert.Nil(t, trader.cachedBalance, "开多仓后余额缓存应该被清除")
-		assert.Nil(t, trader.cachedPositions, "开多仓后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "BTCUSDT"), "开多仓后该币种持仓缓存应该失效")
 	})
 
 	// 子测试2：OpenShort 后缓存被清除
 	t.Run("OpenShort_invalidates_cache", func(t *testing.T) {
 		// 重新填充缓存
-		_, _ = trader.GetBalance()
-		_, _ = trader.GetPositions()
+		_, _ = trader.GetBalance(context.Background())
+		_, _ = trader.GetPositions(context.Background())
 		assert.NotNil(t, trader.cachedBalance)
-		assert.NotNil(t, trader.cachedPositions)
+		assert.True(t, positionsCached(trader))
 
 		// 执行开空仓
-		_, err := trader.OpenShort("ETHUSDT", 0.004, 5)
+		_, err := trader.OpenShort(context.Background(), "ETHUSDT", 0.004, 5)
 		assert.NoError(t, err)
 
 		// 验证缓存被清除
 		assert.Nil(t, trader.cachedBalance, "开空仓后余额缓存应该被清除")
-		assert.Nil(t, trader.cachedPositions, "开空仓后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "ETHUSDT"), "开空仓后该币种持仓缓存应该失效")
+		assert.True(t, symbolCached(trader, "BTCUSDT"), "其他币种的持仓缓存不受影响")
 	})
 
 	// 子测试3：CloseLong 后缓存被清除
 	t.Run("CloseLong_invalidates_cache", func(t *testing.T) {
 		// 重新填充缓存
-		_, _ = trader.GetBalance()
-		_, _ = trader.GetPositions()
+		_, _ = trader.GetBalance(context.Background())
+		_, _ = trader.GetPositions(context.Background())
 		assert.NotNil(t, trader.cachedBalance)
 
 		// 执行平多仓
-		_, err := trader.CloseLong("BTCUSDT", 0.01)
+		_, err := trader.CloseLong(context.Background(), "BTCUSDT", 0.01)
 		assert.NoError(t, err)
 
 		// 验证缓存被清除
 		assert.Nil(t, trader.cachedBalance, "平多仓后余额缓存应该被清除")
-		assert.Nil(t, trader.cachedPositions, "平多仓后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "BTCUSDT"), "平多仓后该币种持仓缓存应该失效")
 	})
 
 	// 子测试4：CloseShort 后缓存被清除
 	t.Run("CloseShort_invalidates_cache", func(t *testing.T) {
 		// 重新填充缓存
-		_, _ = trader.GetBalance()
-		_, _ = trader.GetPositions()
+		_, _ = trader.GetBalance(context.Background())
+		_, _ = trader.GetPositions(context.Background())
 
 		// 执行平空仓
-		_, err := trader.CloseShort("ETHUSDT", 0.004)
+		_, err := trader.CloseShort(context.Background(), "ETHUSDT", 0.004)
 		assert.NoError(t, err)
 
 		// 验证缓存被清除
 		assert.Nil(t, trader.cachedBalance, "平空仓后余额缓存应该被清除")
-		assert.Nil(t, trader.cachedPositions, "平空仓后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "ETHUSDT"), "平空仓后该币种持仓缓存应该失效")
 	})
 
 	// 子测试5：SetStopLoss 后持仓缓存被清除
 	t.Run("SetStopLoss_invalidates_positions_cache", func(t *testing.T) {
 		// 重新填充缓存
-		_, _ = trader.GetPositions()
-		assert.NotNil(t, trader.cachedPositions)
+		_, _ = trader.GetPositions(context.Background())
+		assert.True(t, positionsCached(trader))
 
 		// 设置止损
-		err := trader.SetStopLoss("BTCUSDT", "LONG", 0.01, 45000.0)
+		err := trader.SetStopLoss(context.Background(), "BTCUSDT", "LONG", 0.01, 45000.0)
 		assert.NoError(t, err)
 
 		// 验证持仓缓存被清除（止损单会影响持仓信息）
-		assert.Nil(t, trader.cachedPositions, "设置止损后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "BTCUSDT"), "设置止损后该币种持仓缓存应该失效")
 	})
 
 	// 子测试6：SetTakeProfit 后持仓缓存被清除
 	t.Run("SetTakeProfit_invalidates_positions_cache", func(t *testing.T) {
 		// 重新填充缓存
-		_, _ = trader.GetPositions()
-		assert.NotNil(t, trader.cachedPositions)
+		_, _ = trader.GetPositions(context.Background())
+		assert.True(t, positionsCached(trader))
 
 		// 设置止盈
-		err := trader.SetTakeProfit("BTCUSDT", "LONG", 0.01, 55000.0)
+		err := trader.SetTakeProfit(context.Background(), "BTCUSDT", "LONG", 0.01, 55000.0)
 		assert.NoError(t, err)
 
 		// 验证持仓缓存被清除
-		assert.Nil(t, trader.cachedPositions, "设置止盈后持仓缓存应该被清除")
+		assert.False(t, symbolCached(trader, "BTCUSDT"), "设置止盈后该币种持仓缓存应该失效")
 	})
 }
 
+// TestGetPositionRefreshesSingleSymbol 测试按币种读取持仓缓存
+func TestGetPositionRefreshesSingleSymbol(t *testing.T) {
+	suite := NewBinanceFuturesTestSuite(t)
+	defer suite.Cleanup()
+
+	trader := suite.Trader.(*FuturesTrader)
+	trader.cacheDuration = 1 * time.Hour
+
+	// 没有缓存时只查询该币种
+	positions, err := trader.GetPosition(context.Background(), "BTCUSDT")
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.False(t, positionsCached(trader), "单个币种的查询不应视为全量快照")
+
+	_, err = trader.GetPositions(context.Background())
+	assert.NoError(t, err)
+	positions, err = trader.GetPosition(context.Background(), "ETHUSDT")
+	assert.NoError(t, err)
+	assert.Empty(t, positions, "全量快照中没有的币种应视为无持仓")
+
+	// 失效的币种单独刷新后，全部持仓重新可用
+	trader.positions.invalidate("BTCUSDT")
+	positions, err = trader.GetPositions(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, positions, 1)
+	assert.True(t, positionsCached(trader))
+}
+
 // ============================================================
 // 五、GetOpenOrders 测试
 // ============================================================
@@ -683,7 +722,7 @@ func TestGetOpenOrders_SpecificSymbol(t *testing.T) {
 	trader := suite.Trader.(*FuturesTrader)
 
 	// 查询 BTCUSDT 的未成交订单
-	orders, err := trader.GetOpenOrders("BTCUSDT")
+	orders, err := trader.GetOpenOrders(context.Background(), "BTCUSDT")
 
 	// 验证
 	assert.NoError(t, err)
@@ -715,7 +754,7 @@ func TestGetOpenOrders_AllSymbols(t *testing.T) {
 	trader := suite.Trader.(*FuturesTrader)
 
 	// 查询所有未成交订单（symbol 为空字符串）
-	orders, err := trader.GetOpenOrders("")
+	orders, err := trader.GetOpenOrders(context.Background(), "")
 
 	// 验证
 	assert.NoError(t, err)
@@ -738,7 +777,7 @@ func TestGetOpenOrders_EmptyResult(t *testing.T) {
 	trader := suite.Trader.(*FuturesTrader)
 
 	// 查询没有订单的币种
-	orders, err := trader.GetOpenOrders("XRPUSDT")
+	orders, err := trader.GetOpenOrders(context.Background(), "XRPUSDT")
 
 	// 验证
 	assert.NoError(t, err)
@@ -752,7 +791,7 @@ func TestGetOpenOrders_DifferentOrderTypes(t *testing.T) {
 
 	trader := suite.Trader.(*FuturesTrader)
 
-	orders, err := trader.GetOpenOrders("BTCUSDT")
+	orders, err := trader.GetOpenOrders(context.Background(), "BTCUSDT")
 	assert.NoError(t, err)
 
 	// 验证包含不同类型的订单
@@ -772,7 +811,7 @@ func TestGetOpenOrders_ParseFloatValues(t *testing.T) {
 
 	trader := suite.Trader.(*FuturesTrader)
 
-	orders, err := trader.GetOpenOrders("BTCUSDT")
+	orders, err := trader.GetOpenOrders(context.Background(), "BTCUSDT")
 	assert.NoError(t, err)
 	assert.NotEmpty(t, orders)
 
@@ -0,0 +1,259 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"nofx/netconfig"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	binanceUserStreamKeepalive   = 30 * time.Minute // listenKey 60 分钟未续期即失效
+	binanceUserStreamReadTimeout = 10 * time.Minute // 服务器每 3 分钟发送 ping
+	binanceUserStreamBackoffMin  = time.Second
+	binanceUserStreamBackoffMax  = 30 * time.Second
+)
+
+// BinanceUserStreamConfig 币安合约用户数据推送配置
+type BinanceUserStreamConfig struct {
+	Client *futures.Client // 创建和续期 listenKey
+	URL    string          // 覆盖 WebSocket 地址（测试用，默认 wss://fstream.binance.com/ws）
+}
+
+// BinanceUserStream 币安合约用户数据推送（listenKey）：持仓、余额和订单变化解析为 AccountEvent 发布到事件总线
+type BinanceUserStream struct {
+	cfg BinanceUserStreamConfig
+	bus *AccountEventBus
+
+	mu        sync.RWMutex
+	connected bool
+}
+
+// NewBinanceUserStream 创建用户数据推送
+func NewBinanceUserStream(cfg BinanceUserStreamConfig, bus *AccountEventBus) *BinanceUserStream {
+	if cfg.URL == "" {
+		cfg.URL = netconfig.BaseURL("binance-ws", futures.BaseWsMainUrl)
+	}
+	return &BinanceUserStream{cfg: cfg, bus: bus}
+}
+
+// Start 后台运行推送（断线自动重连，ctx 取消时退出）
+func (s *BinanceUserStream) Start(ctx context.Context) {
+	go s.Run(ctx)
+}
+
+// Run 连接循环：断线或 listenKey 过期后按指数退避重连，直到 ctx 取消
+func (s *BinanceUserStream) Run(ctx context.Context) {
+	backoff := binanceUserStreamBackoffMin
+	for {
+		connected, err := s.session(ctx)
+		s.setConnected(false)
+		if ctx.Err() != nil {
+			log.Info("⏹ 币安用户数据推送已停止")
+			return
+		}
+		if connected {
+			backoff = binanceUserStreamBackoffMin
+		}
+		log.Warn("⚠️ 币安用户数据推送断开，稍后重连", "error", err, "backoff", backoff)
+		if sleepContext(ctx, backoff) != nil {
+			log.Info("⏹ 币安用户数据推送已停止")
+			return
+		}
+		backoff = min(backoff*2, binanceUserStreamBackoffMax)
+	}
+}
+
+// Connected 推送是否已连接
+func (s *BinanceUserStream) Connected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected
+}
+
+func (s *BinanceUserStream) setConnected(connected bool) {
+	s.mu.Lock()
+	s.connected = connected
+	s.mu.Unlock()
+}
+
+// session 建立一次连接：创建 listenKey、连接，然后持续读取推送（后台定时续期 listenKey）
+func (s *BinanceUserStream) session(ctx context.Context) (bool, error) {
+	listenKey, err := s.cfg.Client.NewStartUserStreamService().Do(ctx)
+	if err != nil {
+		return false, fmt.Errorf("创建 listenKey 失败: %w", err)
+	}
+
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: netconfig.Proxy}
+	conn, _, err := dialer.DialContext(ctx, strings.TrimSuffix(s.cfg.URL, "/")+"/"+listenKey, http.Header{})
+	if err != nil {
+		return false, fmt.Errorf("连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		ticker := time.NewTicker(binanceUserStreamKeepalive)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := s.cfg.Client.NewKeepaliveUserStreamService().ListenKey(listenKey).Do(ctx); err != nil {
+					log.Warn("⚠️ 币安 listenKey 续期失败", "error", err)
+				}
+			case <-ctx.Done():
+				conn.Close()
+				return
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	conn.SetPingHandler(func(data string) error {
+		conn.SetReadDeadline(time.Now().Add(binanceUserStreamReadTimeout))
+		return conn.WriteControl(websocket.PongMessage, []byte(data), time.Now().Add(time.Second))
+	})
+
+	s.setConnected(true)
+	log.Info("✅ 币安用户数据推送已连接（持仓/余额/订单）")
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(binanceUserStreamReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if err := s.handleMessage(message); err != nil {
+			return true, err
+		}
+	}
+}
+
+// handleMessage 处理一条推送（返回错误时断开重连）
+func (s *BinanceUserStream) handleMessage(message []byte) error {
+	var ev futures.WsUserDataEvent
+	if err := json.Unmarshal(message, &ev); err != nil {
+		// 未识别的事件类型同样会解析失败，忽略即可
+		log.Warn("⚠️ 币安用户数据推送解析失败", "error", err)
+		return nil
+	}
+	switch ev.Event {
+	case futures.UserDataEventTypeListenKeyExpired:
+		return errors.New("listenKey 已过期")
+	case futures.UserDataEventTypeAccountUpdate:
+		s.handleAccountUpdate(ev)
+	case futures.UserDataEventTypeOrderTradeUpdate:
+		s.handleOrderTradeUpdate(ev)
+	}
+	return nil
+}
+
+// handleAccountUpdate 余额和持仓变化（只包含变化的币种和方向；数量为 0 表示已平仓）
+func (s *BinanceUserStream) handleAccountUpdate(ev futures.WsUserDataEvent) {
+	at := time.UnixMilli(ev.Time)
+	for _, b := range ev.AccountUpdate.Balances {
+		if b.Asset != "USDT" {
+			continue
+		}
+		// 推送只有钱包余额（不含未实现盈亏）
+		s.publish(AccountEvent{Type: AccountEventBalance, Time: at, Balance: &BalanceUpdate{Currency: b.Asset, Equity: parseFloatOrZero(b.Balance)}})
+	}
+	for _, p := range ev.AccountUpdate.Positions {
+		qty := parseFloatOrZero(p.Amount)
+		side := strings.ToLower(string(p.Side))
+		if side == "both" || side == "" {
+			// 单向持仓模式：数量的正负表示方向（平仓推送数量为 0，方向未知）
+			switch {
+			case qty > 0:
+				side = "long"
+			case qty < 0:
+				side = "short"
+			default:
+				side = ""
+			}
+		}
+		if qty < 0 {
+			qty = -qty
+		}
+		marginMode := "cross"
+		if strings.EqualFold(string(p.MarginType), string(futures.MarginTypeIsolated)) {
+			marginMode = "isolated"
+		}
+		// 推送不含杠杆和强平价：数量未变时持仓缓存沿用上次查询的值，否则重新查询
+		s.publish(AccountEvent{Type: AccountEventPosition, Symbol: p.Symbol, Time: at, Position: &PositionUpdate{
+			Side:          side,
+			Quantity:      qty,
+			EntryPrice:    parseFloatOrZero(p.EntryPrice),
+			MarkPrice:     parseFloatOrZero(p.MarkPrice),
+			UnrealizedPnL: parseFloatOrZero(p.UnrealizedPnL),
+			MarginMode:    marginMode,
+		}})
+	}
+}
+
+// handleOrderTradeUpdate 订单变化：成交发布 fill，强平/自动减仓发布 liquidation，止盈止损触发发布 trigger
+func (s *BinanceUserStream) handleOrderTradeUpdate(ev futures.WsUserDataEvent) {
+	o := ev.OrderTradeUpdate
+	update := &OrderUpdate{
+		OrderID:       strconv.FormatInt(o.ID, 10),
+		ClientOrderID: o.ClientOrderID,
+		Side:          strings.ToLower(string(o.Side)),
+		PositionSide:  strings.ToLower(string(o.PositionSide)),
+		OrderType:     string(o.OriginalType),
+		State:         string(o.Status),
+		Category:      string(o.ExecutionType),
+		Price:         parseFloatOrZero(o.OriginalPrice),
+		Quantity:      parseFloatOrZero(o.OriginalQty),
+		FilledQty:     parseFloatOrZero(o.AccumulatedFilledQty),
+		AvgPrice:      parseFloatOrZero(o.AveragePrice),
+		FillPrice:     parseFloatOrZero(o.LastFilledPrice),
+		FillQty:       parseFloatOrZero(o.LastFilledQty),
+		Fee:           parseFloatOrZero(o.Commission),
+		RealizedPnL:   parseFloatOrZero(o.RealizedPnL),
+		ReduceOnly:    o.IsReduceOnly,
+		TriggerPrice:  parseFloatOrZero(o.StopPrice),
+	}
+	if update.PositionSide == "both" {
+		update.PositionSide = "net"
+	}
+
+	evType := AccountEventOrder
+	switch {
+	case o.ExecutionType == futures.OrderExecutionTypeCalculated || strings.HasPrefix(o.ClientOrderID, "autoclose-") || o.ClientOrderID == "adl_autoclose":
+		evType = AccountEventLiquidation
+	case update.FillQty > 0:
+		evType = AccountEventFill
+	case isBinanceTriggerOrder(o.OriginalType) && o.Type != o.OriginalType:
+		// 止盈止损单触发后订单类型变为市价/限价单
+		evType = AccountEventTrigger
+	}
+	s.publish(AccountEvent{Type: evType, Symbol: o.Symbol, Time: time.UnixMilli(ev.Time), Order: update})
+}
+
+// isBinanceTriggerOrder 是否为止盈止损条件单
+func isBinanceTriggerOrder(t futures.OrderType) bool {
+	switch t {
+	case futures.OrderTypeStopMarket, futures.OrderTypeTakeProfitMarket, futures.OrderTypeStop, futures.OrderTypeTakeProfit, futures.OrderTypeTrailingStopMarket:
+		return true
+	}
+	return false
+}
+
+func (s *BinanceUserStream) publish(ev AccountEvent) {
+	if s.bus == nil {
+		return
+	}
+	ev.Exchange = "binance"
+	s.bus.Publish(ev)
+}
@@ -0,0 +1,59 @@
+package trader
+
+import (
+	"testing"
+)
+
+func TestBinanceUserStreamHandleMessage(t *testing.T) {
+	bus := NewAccountEventBus()
+	events, cancel := bus.Subscribe(16)
+	defer cancel()
+	s := NewBinanceUserStream(BinanceUserStreamConfig{URL: "ws://unused"}, bus)
+
+	// 单向持仓模式：空仓数量为负
+	if err := s.handleMessage([]byte(`{"e":"ACCOUNT_UPDATE","E":1700000000000,"a":{"m":"ORDER",` +
+		`"B":[{"a":"USDT","wb":"1000.5","cw":"1000.5","bc":"0"},{"a":"BNB","wb":"1","cw":"1","bc":"0"}],` +
+		`"P":[{"s":"BTCUSDT","pa":"-0.2","ep":"50000","cr":"0","up":"-10","mt":"isolated","iw":"100","ps":"BOTH"}]}}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	ev := waitEvent(t, events)
+	if ev.Type != AccountEventBalance || ev.Exchange != "binance" || ev.Balance.Equity != 1000.5 {
+		t.Errorf("unexpected balance event: %+v", ev)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventPosition || ev.Symbol != "BTCUSDT" || ev.Position.Side != "short" || ev.Position.Quantity != 0.2 ||
+		ev.Position.EntryPrice != 50000 || ev.Position.MarginMode != "isolated" {
+		t.Errorf("unexpected position event: %+v %+v", ev, ev.Position)
+	}
+
+	// 单向持仓模式平仓：数量为 0，方向未知
+	if err := s.handleMessage([]byte(`{"e":"ACCOUNT_UPDATE","E":1700000000000,"a":{"m":"ORDER",` +
+		`"P":[{"s":"BTCUSDT","pa":"0","ep":"0","up":"0","mt":"cross","ps":"BOTH"}]}}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if ev = waitEvent(t, events); ev.Type != AccountEventPosition || ev.Position.Side != "" || ev.Position.Quantity != 0 {
+		t.Errorf("单向持仓平仓不应推断方向: %+v", ev.Position)
+	}
+
+	if err := s.handleMessage([]byte(`{"e":"ORDER_TRADE_UPDATE","E":1700000000000,"o":{"s":"BTCUSDT","c":"abc","S":"BUY",` +
+		`"o":"MARKET","ot":"MARKET","x":"TRADE","X":"FILLED","i":42,"q":"0.2","ap":"50010","L":"50010","l":"0.2","z":"0.2",` +
+		`"n":"0.4","R":true,"ps":"BOTH","rp":"-2"}}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventFill || ev.Order.OrderID != "42" || ev.Order.FillQty != 0.2 || ev.Order.PositionSide != "net" || !ev.Order.ReduceOnly {
+		t.Errorf("unexpected fill event: %+v %+v", ev, ev.Order)
+	}
+
+	if err := s.handleMessage([]byte(`{"e":"ORDER_TRADE_UPDATE","E":1700000000000,"o":{"s":"BTCUSDT","c":"autoclose-1","S":"SELL",` +
+		`"o":"LIMIT","ot":"LIMIT","x":"CALCULATED","X":"FILLED","i":43,"l":"0","ps":"LONG"}}`)); err != nil {
+		t.Fatalf("handleMessage() error = %v", err)
+	}
+	if ev = waitEvent(t, events); ev.Type != AccountEventLiquidation {
+		t.Errorf("强平单应发布 liquidation: %+v", ev)
+	}
+
+	if err := s.handleMessage([]byte(`{"e":"listenKeyExpired","E":1700000000000}`)); err == nil {
+		t.Error("listenKey 过期应返回错误以重连")
+	}
+}
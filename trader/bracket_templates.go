@@ -0,0 +1,251 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"nofx/decision"
+	"os"
+	"strings"
+)
+
+// 币种流动性分类（用于选择出场模板）
+const (
+	SymbolClassMajor  = "major"  // 主流币（BTC/ETH）
+	SymbolClassMidCap = "midcap" // 中等市值币种（默认分类）
+	SymbolClassMeme   = "meme"   // Meme 币
+)
+
+// 默认回撤止盈参数（未配置模板时沿用原有规则：收益>5%且回撤≥40%平仓）
+const (
+	defaultTrailingActivatePct = 5.0
+	defaultTrailingDrawdownPct = 40.0
+)
+
+// defaultMajorSymbols 默认主流币
+var defaultMajorSymbols = map[string]bool{
+	"BTCUSDT": true,
+	"ETHUSDT": true,
+}
+
+// defaultMemeBases 默认 Meme 币（基础币种，忽略 1000 前缀）
+var defaultMemeBases = map[string]bool{
+	"DOGE": true, "SHIB": true, "PEPE": true, "WIF": true, "BONK": true, "FLOKI": true,
+	"MEME": true, "TURBO": true, "BOME": true, "NEIRO": true, "PNUT": true, "POPCAT": true,
+	"MOG": true, "BRETT": true, "TRUMP": true, "FARTCOIN": true,
+}
+
+// TakeProfitStep 止盈阶梯中的一档
+type TakeProfitStep struct {
+	DistancePct float64 `json:"distance_pct"` // 距开仓价的价格距离百分比
+	ClosePct    float64 `json:"close_pct"`    // 触发时平掉当前剩余持仓的百分比（最后一档挂交易所止盈单平掉剩余，忽略此值）
+}
+
+// BracketTemplate 出场模板（止损距离、止盈阶梯、移动止盈规则）
+type BracketTemplate struct {
+	StopDistancePct     float64          `json:"stop_distance_pct"`     // 止损距开仓价的价格百分比（0=使用AI给出的止损）
+	TakeProfitLadder    []TakeProfitStep `json:"take_profit_ladder"`    // 止盈阶梯，按距离从近到远排列（空=使用AI给出的止盈）
+	TrailingActivatePct float64          `json:"trailing_activate_pct"` // 回撤止盈激活的收益百分比（含杠杆，0=默认5）
+	TrailingDrawdownPct float64          `json:"trailing_drawdown_pct"` // 从最高收益回撤多少百分比后平仓（0=默认40）
+}
+
+// validate 检查模板参数（止盈阶梯按距离从近到远）
+func (t BracketTemplate) validate() error {
+	if t.StopDistancePct < 0 || t.StopDistancePct >= 100 {
+		return fmt.Errorf("stop_distance_pct %.2f 必须在 0~100 之间", t.StopDistancePct)
+	}
+	prev := 0.0
+	for i, step := range t.TakeProfitLadder {
+		if step.DistancePct <= prev {
+			return fmt.Errorf("止盈阶梯第 %d 档 distance_pct %.2f 必须为正数且大于上一档", i+1, step.DistancePct)
+		}
+		if step.ClosePct < 0 || step.ClosePct > 100 {
+			return fmt.Errorf("止盈阶梯第 %d 档 close_pct %.2f 必须在 0~100 之间", i+1, step.ClosePct)
+		}
+		prev = step.DistancePct
+	}
+	if t.TrailingActivatePct < 0 || t.TrailingDrawdownPct < 0 || t.TrailingDrawdownPct > 100 {
+		return fmt.Errorf("回撤止盈参数无效（trailing_activate_pct ≥ 0，trailing_drawdown_pct 在 0~100 之间）")
+	}
+	return nil
+}
+
+// LoadBracketTemplates 读取出场模板文件（JSON 对象：分类 -> 模板，如 {"major": {"stop_distance_pct": 1.5, ...}}）
+func LoadBracketTemplates(path string) (map[string]BracketTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取出场模板失败: %w", err)
+	}
+	var raw map[string]BracketTemplate
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("解析出场模板 %s 失败: %w", path, err)
+	}
+	templates := make(map[string]BracketTemplate, len(raw))
+	for class, tmpl := range raw {
+		if err := tmpl.validate(); err != nil {
+			return nil, fmt.Errorf("出场模板 %s: %w", class, err)
+		}
+		templates[strings.ToLower(strings.TrimSpace(class))] = tmpl
+	}
+	return templates, nil
+}
+
+// ParseSymbolClasses 解析币种分类覆盖，格式 "SOLUSDT:major,WIFUSDT:meme"
+func ParseSymbolClasses(raw string) (map[string]string, error) {
+	classes := make(map[string]string)
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		symbol, class, ok := strings.Cut(part, ":")
+		symbol, class = normalizeSymbol(symbol), strings.ToLower(strings.TrimSpace(class))
+		if !ok || symbol == "USDT" || class == "" {
+			return nil, fmt.Errorf("币种分类 %q 格式错误（应为 SOLUSDT:major）", part)
+		}
+		classes[symbol] = class
+	}
+	return classes, nil
+}
+
+// BracketTemplatesFromEnv 读取 NOFX_BRACKET_TEMPLATES（模板文件路径）和 NOFX_SYMBOL_CLASSES（币种分类覆盖），
+// 未设置模板文件时返回 nil（沿用 AI 给出的止损止盈）
+func BracketTemplatesFromEnv() (map[string]BracketTemplate, map[string]string, error) {
+	path := strings.TrimSpace(os.Getenv("NOFX_BRACKET_TEMPLATES"))
+	if path == "" {
+		return nil, nil, nil
+	}
+	templates, err := LoadBracketTemplates(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("NOFX_BRACKET_TEMPLATES 配置错误: %w", err)
+	}
+	var classes map[string]string
+	if raw := strings.TrimSpace(os.Getenv("NOFX_SYMBOL_CLASSES")); raw != "" {
+		if classes, err = ParseSymbolClasses(raw); err != nil {
+			return nil, nil, fmt.Errorf("NOFX_SYMBOL_CLASSES 配置错误: %w", err)
+		}
+		for symbol, class := range classes {
+			if _, ok := templates[class]; !ok {
+				return nil, nil, fmt.Errorf("NOFX_SYMBOL_CLASSES 中 %s 的分类 %s 没有对应的出场模板", symbol, class)
+			}
+		}
+	}
+	return templates, classes, nil
+}
+
+// classifySymbol 判断币种的流动性分类（显式配置优先）
+func classifySymbol(symbol string, overrides map[string]string) string {
+	symbol = strings.ToUpper(symbol)
+	if class, ok := overrides[symbol]; ok && class != "" {
+		return class
+	}
+	if defaultMajorSymbols[symbol] {
+		return SymbolClassMajor
+	}
+
+	base := strings.TrimSuffix(symbol, "USDT")
+	base = strings.TrimPrefix(base, "1000000")
+	base = strings.TrimPrefix(base, "1000")
+	if defaultMemeBases[base] {
+		return SymbolClassMeme
+	}
+	return SymbolClassMidCap
+}
+
+// bracketTemplateFor 获取币种对应的出场模板
+func (at *AutoTrader) bracketTemplateFor(symbol string) (BracketTemplate, bool) {
+	if len(at.config.BracketTemplates) == 0 {
+		return BracketTemplate{}, false
+	}
+	tmpl, ok := at.config.BracketTemplates[classifySymbol(symbol, at.config.SymbolClasses)]
+	return tmpl, ok
+}
+
+// applyBracketTemplate 开仓前按模板覆盖止损/止盈价格（止盈取阶梯最后一档）
+func (at *AutoTrader) applyBracketTemplate(d *decision.Decision, price float64) {
+	tmpl, ok := at.bracketTemplateFor(d.Symbol)
+	if !ok {
+		return
+	}
+
+	direction := 1.0
+	if d.Action == "open_short" {
+		direction = -1.0
+	}
+
+	if tmpl.StopDistancePct > 0 {
+		d.StopLoss = price * (1 - direction*tmpl.StopDistancePct/100)
+	}
+	if n := len(tmpl.TakeProfitLadder); n > 0 {
+		d.TakeProfit = price * (1 + direction*tmpl.TakeProfitLadder[n-1].DistancePct/100)
+	}
+
+	log.Info("  📐 使用出场模板", "symbol", d.Symbol, "template", classifySymbol(d.Symbol, at.config.SymbolClasses), "stop_loss", d.StopLoss, "take_profit", d.TakeProfit, "ladder_steps", len(tmpl.TakeProfitLadder))
+}
+
+// trailingThresholds 获取币种的回撤止盈参数（激活收益%、回撤%）
+func (at *AutoTrader) trailingThresholds(symbol string) (activatePct, drawdownPct float64) {
+	activatePct, drawdownPct = defaultTrailingActivatePct, defaultTrailingDrawdownPct
+	if tmpl, ok := at.bracketTemplateFor(symbol); ok {
+		if tmpl.TrailingActivatePct > 0 {
+			activatePct = tmpl.TrailingActivatePct
+		}
+		if tmpl.TrailingDrawdownPct > 0 {
+			drawdownPct = tmpl.TrailingDrawdownPct
+		}
+	}
+	return activatePct, drawdownPct
+}
+
+// resetTakeProfitLadder 新开仓时重置止盈阶梯进度
+func (at *AutoTrader) resetTakeProfitLadder(posKey string) {
+	at.ladderMutex.Lock()
+	defer at.ladderMutex.Unlock()
+	delete(at.ladderProgress, posKey)
+}
+
+// checkTakeProfitLadder 价格到达阶梯档位时部分平仓（最后一档由交易所止盈单负责）
+func (at *AutoTrader) checkTakeProfitLadder(symbol, side string, entryPrice, markPrice, quantity float64) {
+	tmpl, ok := at.bracketTemplateFor(symbol)
+	if !ok || len(tmpl.TakeProfitLadder) < 2 || entryPrice <= 0 {
+		return
+	}
+
+	movePct := (markPrice - entryPrice) / entryPrice * 100
+	if side == "short" {
+		movePct = -movePct
+	}
+
+	posKey := symbol + "_" + side
+	at.ladderMutex.Lock()
+	defer at.ladderMutex.Unlock()
+	if at.ladderProgress == nil {
+		at.ladderProgress = make(map[string]int)
+	}
+
+	steps := tmpl.TakeProfitLadder[:len(tmpl.TakeProfitLadder)-1]
+	progress := at.ladderProgress[posKey]
+	for progress < len(steps) && movePct >= steps[progress].DistancePct {
+		step := steps[progress]
+		closeQty := quantity * step.ClosePct / 100
+		if closeQty <= 0 {
+			progress++
+			continue
+		}
+
+		var err error
+		if side == "long" {
+			_, err = at.trader.CloseLong(at.ctx(), symbol, closeQty)
+		} else {
+			_, err = at.trader.CloseShort(at.ctx(), symbol, closeQty)
+		}
+		if err != nil {
+			log.Error("❌ 阶梯止盈平仓失败", "step", progress+1, "symbol", symbol, "side", side, "error", err)
+			break
+		}
+
+		log.Info("🪜 阶梯止盈触发", "step", progress+1, "symbol", symbol, "side", side, "move_pct", movePct, "distance_pct", step.DistancePct, "close_qty", closeQty)
+		quantity -= closeQty
+		progress++
+	}
+	at.ladderProgress[posKey] = progress
+}
@@ -0,0 +1,159 @@
+package trader
+
+import (
+	"math"
+	"nofx/decision"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestClassifySymbol(t *testing.T) {
+	overrides := map[string]string{"SOLUSDT": SymbolClassMajor}
+
+	tests := []struct {
+		symbol string
+		expect string
+	}{
+		{"BTCUSDT", SymbolClassMajor},
+		{"ethusdt", SymbolClassMajor},
+		{"SOLUSDT", SymbolClassMajor}, // 显式覆盖
+		{"DOGEUSDT", SymbolClassMeme},
+		{"1000PEPEUSDT", SymbolClassMeme},
+		{"LINKUSDT", SymbolClassMidCap},
+	}
+
+	for _, tt := range tests {
+		if got := classifySymbol(tt.symbol, overrides); got != tt.expect {
+			t.Errorf("classifySymbol(%s) = %s，期望 %s", tt.symbol, got, tt.expect)
+		}
+	}
+}
+
+func TestApplyBracketTemplate(t *testing.T) {
+	at := &AutoTrader{config: AutoTraderConfig{
+		BracketTemplates: map[string]BracketTemplate{
+			SymbolClassMeme: {
+				StopDistancePct:  8,
+				TakeProfitLadder: []TakeProfitStep{{DistancePct: 10, ClosePct: 50}, {DistancePct: 25}},
+			},
+		},
+	}}
+
+	d := &decision.Decision{Symbol: "DOGEUSDT", Action: "open_short", StopLoss: 0.11, TakeProfit: 0.09}
+	at.applyBracketTemplate(d, 0.10)
+	if math.Abs(d.StopLoss-0.108) > 1e-9 || math.Abs(d.TakeProfit-0.075) > 1e-9 {
+		t.Errorf("空单模板止损/止盈错误: SL=%.6f TP=%.6f", d.StopLoss, d.TakeProfit)
+	}
+
+	// 未配置模板的分类保持 AI 给出的价格
+	d = &decision.Decision{Symbol: "BTCUSDT", Action: "open_long", StopLoss: 49000, TakeProfit: 52000}
+	at.applyBracketTemplate(d, 50000)
+	if d.StopLoss != 49000 || d.TakeProfit != 52000 {
+		t.Errorf("未配置模板时不应修改: SL=%.2f TP=%.2f", d.StopLoss, d.TakeProfit)
+	}
+}
+
+func TestTrailingThresholds(t *testing.T) {
+	at := &AutoTrader{config: AutoTraderConfig{
+		BracketTemplates: map[string]BracketTemplate{
+			SymbolClassMajor: {TrailingActivatePct: 3, TrailingDrawdownPct: 30},
+		},
+	}}
+
+	if a, d := at.trailingThresholds("BTCUSDT"); a != 3 || d != 30 {
+		t.Errorf("major 回撤参数错误: %.1f / %.1f", a, d)
+	}
+	if a, d := at.trailingThresholds("LINKUSDT"); a != defaultTrailingActivatePct || d != defaultTrailingDrawdownPct {
+		t.Errorf("默认回撤参数错误: %.1f / %.1f", a, d)
+	}
+}
+
+func TestCheckTakeProfitLadder(t *testing.T) {
+	mock := &closeRecordingTrader{}
+	at := &AutoTrader{
+		trader: mock,
+		config: AutoTraderConfig{
+			BracketTemplates: map[string]BracketTemplate{
+				SymbolClassMidCap: {TakeProfitLadder: []TakeProfitStep{
+					{DistancePct: 2, ClosePct: 50},
+					{DistancePct: 4, ClosePct: 50},
+					{DistancePct: 8},
+				}},
+			},
+		},
+	}
+
+	// 未到第一档
+	at.checkTakeProfitLadder("LINKUSDT", "long", 10, 10.1, 100)
+	if mock.closedSymbol != "" {
+		t.Fatalf("未到档位不应平仓，实际平仓 %s", mock.closedSymbol)
+	}
+
+	// 到达第一档：平掉 50%
+	at.checkTakeProfitLadder("LINKUSDT", "long", 10, 10.25, 100)
+	if mock.closedSide != "long" || mock.closedQuantity != 50 {
+		t.Fatalf("第一档期望平多 50，实际 %s %.4f", mock.closedSide, mock.closedQuantity)
+	}
+
+	// 同一档不重复触发
+	mock.closedQuantity = 0
+	at.checkTakeProfitLadder("LINKUSDT", "long", 10, 10.25, 50)
+	if mock.closedQuantity != 0 {
+		t.Errorf("同一档不应重复触发，实际平仓 %.4f", mock.closedQuantity)
+	}
+
+	// 到达第二档：平掉剩余的 50%；最后一档由交易所止盈单负责
+	at.checkTakeProfitLadder("LINKUSDT", "long", 10, 11, 50)
+	if mock.closedQuantity != 25 {
+		t.Errorf("第二档期望平仓 25，实际 %.4f", mock.closedQuantity)
+	}
+	if at.ladderProgress["LINKUSDT_long"] != 2 {
+		t.Errorf("期望阶梯进度 2，实际 %d", at.ladderProgress["LINKUSDT_long"])
+	}
+
+	// 新开仓重置进度
+	at.resetTakeProfitLadder("LINKUSDT_long")
+	if _, ok := at.ladderProgress["LINKUSDT_long"]; ok {
+		t.Error("重置后不应保留阶梯进度")
+	}
+}
+
+func TestBracketTemplatesFromEnv(t *testing.T) {
+	if templates, classes, err := BracketTemplatesFromEnv(); err != nil || templates != nil || classes != nil {
+		t.Fatalf("未配置时应返回 nil: %v %v %v", templates, classes, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "brackets.json")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(`{
+		"major": {"stop_distance_pct": 1.5, "take_profit_ladder": [{"distance_pct": 2, "close_pct": 50}, {"distance_pct": 4}]},
+		"Meme": {"stop_distance_pct": 6, "trailing_drawdown_pct": 25}
+	}`)
+	t.Setenv("NOFX_BRACKET_TEMPLATES", path)
+	t.Setenv("NOFX_SYMBOL_CLASSES", "sol:major, WIFUSDT:meme")
+	templates, classes, err := BracketTemplatesFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(templates) != 2 || len(templates[SymbolClassMajor].TakeProfitLadder) != 2 || templates[SymbolClassMeme].TrailingDrawdownPct != 25 {
+		t.Errorf("模板解析错误: %+v", templates)
+	}
+	if classes["SOLUSDT"] != SymbolClassMajor || classes["WIFUSDT"] != SymbolClassMeme {
+		t.Errorf("分类覆盖解析错误: %+v", classes)
+	}
+
+	t.Setenv("NOFX_SYMBOL_CLASSES", "SOLUSDT:midcap")
+	if _, _, err := BracketTemplatesFromEnv(); err == nil {
+		t.Error("覆盖到没有模板的分类应返回错误")
+	}
+	t.Setenv("NOFX_SYMBOL_CLASSES", "")
+	write(`{"major": {"take_profit_ladder": [{"distance_pct": 4}, {"distance_pct": 2}]}}`)
+	if _, _, err := BracketTemplatesFromEnv(); err == nil {
+		t.Error("止盈阶梯未按距离递增应返回错误")
+	}
+}
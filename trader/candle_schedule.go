@@ -0,0 +1,132 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/market"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 调度模式
+const (
+	ScheduleModeInterval    = "interval"     // 按 ScanInterval 固定间隔（默认）
+	ScheduleModeCandleClose = "candle_close" // 在订阅时间线的K线收盘时触发
+)
+
+// defaultCandleCloseGraceSeconds K线收盘后等待数据可用的默认秒数
+const defaultCandleCloseGraceSeconds = 3
+
+// ScheduleModeFromEnv 读取 NOFX_SCHEDULE_MODE（interval / candle_close，未设置为 interval）
+// 和 NOFX_CANDLE_CLOSE_GRACE_SECONDS（收盘后等待秒数，未设置为默认值）
+func ScheduleModeFromEnv() (string, int, error) {
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_SCHEDULE_MODE")))
+	switch mode {
+	case "", ScheduleModeInterval, ScheduleModeCandleClose:
+	default:
+		return "", 0, fmt.Errorf("NOFX_SCHEDULE_MODE=%q 无效（支持 interval、candle_close）", mode)
+	}
+	grace := 0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_CANDLE_CLOSE_GRACE_SECONDS")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("NOFX_CANDLE_CLOSE_GRACE_SECONDS 必须为正整数: %q", raw)
+		}
+		grace = n
+	}
+	return mode, grace, nil
+}
+
+// candleSchedule K线收盘对齐的调度计划（按 UTC 对齐，只支持不超过 1 天且能整除 1 天的周期）
+type candleSchedule struct {
+	timeframes []string
+	periods    []time.Duration
+	grace      time.Duration
+}
+
+// newCandleSchedule 根据时间线创建调度计划（时间线为空时与 market.Get 一致使用 15m/1h/4h）
+func newCandleSchedule(timeframes []string, graceSeconds int) (*candleSchedule, error) {
+	if len(timeframes) == 0 {
+		timeframes = []string{"15m", "1h", "4h"}
+	}
+	if graceSeconds < 0 {
+		return nil, fmt.Errorf("收盘等待秒数不能为负数: %d", graceSeconds)
+	}
+	if graceSeconds == 0 {
+		graceSeconds = defaultCandleCloseGraceSeconds
+	}
+
+	s := &candleSchedule{grace: time.Duration(graceSeconds) * time.Second}
+	for _, tf := range timeframes {
+		period, ok := market.TimeframeDuration(tf)
+		if !ok {
+			return nil, fmt.Errorf("未知的时间线: %s", tf)
+		}
+		if period > 24*time.Hour || (24*time.Hour)%period != 0 {
+			return nil, fmt.Errorf("时间线 %s 不支持收盘对齐调度（需不超过1天且能整除1天）", tf)
+		}
+		s.timeframes = append(s.timeframes, tf)
+		s.periods = append(s.periods, period)
+	}
+	return s, nil
+}
+
+// next 计算下一次触发时间（最近的收盘时间 + 等待时间）及在该时刻收盘的时间线
+// 刚收盘但还在等待时间内时，返回本次收盘的触发时间
+func (s *candleSchedule) next(now time.Time) (time.Time, []string) {
+	ref := now.Add(-s.grace).UnixMilli()
+
+	var boundary int64
+	var closed []string
+	for i, period := range s.periods {
+		p := period.Milliseconds()
+		b := (ref/p + 1) * p
+		switch {
+		case closed == nil || b < boundary:
+			boundary = b
+			closed = []string{s.timeframes[i]}
+		case b == boundary:
+			closed = append(closed, s.timeframes[i])
+		}
+	}
+	return time.UnixMilli(boundary).Add(s.grace), closed
+}
+
+// candleCloseSchedule 返回收盘对齐调度计划（未启用或配置无效时返回 nil，使用固定间隔）
+func (at *AutoTrader) candleCloseSchedule() *candleSchedule {
+	if at.config.ScheduleMode != ScheduleModeCandleClose {
+		return nil
+	}
+	schedule, err := newCandleSchedule(at.timeframes, at.config.CandleCloseGraceSeconds)
+	if err != nil {
+		log.Warn("⚠️ K线收盘调度配置无效", "trader", at.name, "error", err)
+		return nil
+	}
+	return schedule
+}
+
+// runCandleCloseLoop 在每个订阅时间线的K线收盘时执行交易周期，直到收到停止信号
+func (at *AutoTrader) runCandleCloseLoop(schedule *candleSchedule) error {
+	log.Info("🕯️ K线收盘调度", "trader", at.name, "timeframes", schedule.timeframes, "grace", schedule.grace)
+
+	for at.isRunning {
+		fireAt, closed := schedule.next(time.Now())
+		log.Info("⏳ 下次决策", "trader", at.name, "fire_at", fireAt.Format("2006-01-02 15:04:05"), "timeframes", strings.Join(closed, ","))
+
+		timer := time.NewTimer(time.Until(fireAt))
+		select {
+		case <-timer.C:
+			log.Info("🕯️ K线收盘触发", "trader", at.name, "timeframes", strings.Join(closed, ","))
+			if err := at.runCycle(); err != nil {
+				log.Error("❌ 执行失败", "error", err)
+			}
+		case <-at.stopMonitorCh:
+			timer.Stop()
+			log.Info("⏹ 收到停止信号，退出自动交易主循环", "trader", at.name)
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package trader
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCandleScheduleNext(t *testing.T) {
+	schedule, err := newCandleSchedule([]string{"15m", "1h", "4h"}, 5)
+	if err != nil {
+		t.Fatalf("创建调度计划失败: %v", err)
+	}
+
+	at := func(h, m, s int) time.Time { return time.Date(2024, 1, 1, h, m, s, 0, time.UTC) }
+	tests := []struct {
+		name   string
+		now    time.Time
+		want   time.Time
+		closed []string
+	}{
+		{"K线中途等待下一个15m收盘", at(10, 7, 0), at(10, 15, 5), []string{"15m"}},
+		{"整点同时收盘的时间线一起触发", at(11, 50, 0), at(12, 0, 5), []string{"15m", "1h", "4h"}},
+		{"刚收盘仍在等待时间内", at(10, 0, 2), at(10, 0, 5), []string{"15m", "1h"}},
+		{"触发时刻之后排到下一根", at(10, 0, 5), at(10, 15, 5), []string{"15m"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, closed := schedule.next(tt.now)
+			if !got.Equal(tt.want) || !reflect.DeepEqual(closed, tt.closed) {
+				t.Errorf("期望 %v %v，实际 %v %v", tt.want, tt.closed, got, closed)
+			}
+		})
+	}
+
+	// 非 UTC 时区的当前时间同样按 UTC K线边界对齐
+	shanghai := time.FixedZone("UTC+8", 8*3600)
+	got, _ := schedule.next(time.Date(2024, 1, 1, 18, 7, 0, 0, shanghai))
+	if !got.Equal(at(10, 15, 5)) {
+		t.Errorf("期望 %v，实际 %v", at(10, 15, 5), got)
+	}
+}
+
+func TestNewCandleSchedule(t *testing.T) {
+	schedule, err := newCandleSchedule(nil, 0)
+	if err != nil {
+		t.Fatalf("创建调度计划失败: %v", err)
+	}
+	if !reflect.DeepEqual(schedule.timeframes, []string{"15m", "1h", "4h"}) || schedule.grace != defaultCandleCloseGraceSeconds*time.Second {
+		t.Errorf("默认值错误: %+v", schedule)
+	}
+
+	for _, tfs := range [][]string{{"1w"}, {"7m"}, {"15m", "3d"}} {
+		if _, err := newCandleSchedule(tfs, 0); err == nil {
+			t.Errorf("时间线 %v 应返回错误", tfs)
+		}
+	}
+	if _, err := newCandleSchedule([]string{"1h"}, -1); err == nil {
+		t.Error("负数等待时间应返回错误")
+	}
+
+	// 配置无效时回退到固定间隔
+	trader := &AutoTrader{name: "test", timeframes: []string{"1w"}, config: AutoTraderConfig{ScheduleMode: ScheduleModeCandleClose}}
+	if trader.candleCloseSchedule() != nil {
+		t.Error("无效配置应回退到固定间隔")
+	}
+	trader.timeframes = []string{"5m"}
+	if trader.candleCloseSchedule() == nil {
+		t.Error("期望启用收盘调度")
+	}
+}
+
+func TestScheduleModeFromEnv(t *testing.T) {
+	if mode, grace, err := ScheduleModeFromEnv(); err != nil || mode != "" || grace != 0 {
+		t.Fatalf("未配置时应按扫描间隔调度: %q %d %v", mode, grace, err)
+	}
+
+	t.Setenv("NOFX_SCHEDULE_MODE", "Candle_Close")
+	t.Setenv("NOFX_CANDLE_CLOSE_GRACE_SECONDS", "5")
+	if mode, grace, err := ScheduleModeFromEnv(); err != nil || mode != ScheduleModeCandleClose || grace != 5 {
+		t.Errorf("配置解析错误: %q %d %v", mode, grace, err)
+	}
+
+	t.Setenv("NOFX_SCHEDULE_MODE", "cron")
+	if _, _, err := ScheduleModeFromEnv(); err == nil {
+		t.Error("未知的调度模式应返回错误")
+	}
+	t.Setenv("NOFX_SCHEDULE_MODE", "")
+	t.Setenv("NOFX_CANDLE_CLOSE_GRACE_SECONDS", "-1")
+	if _, _, err := ScheduleModeFromEnv(); err == nil {
+		t.Error("无效的等待秒数应返回错误")
+	}
+}
@@ -0,0 +1,106 @@
+package trader
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// clockResyncInterval 定期重新同步服务器时间的间隔（本机时钟漂移通常每天数百毫秒）
+	clockResyncInterval = 30 * time.Minute
+	// clockMinResyncInterval 两次同步的最小间隔（时间戳错误集中出现时避免反复请求）
+	clockMinResyncInterval = 10 * time.Second
+	// clockSkewWarnThreshold 偏移超过该值时提示检查本机时间同步
+	clockSkewWarnThreshold = time.Second
+)
+
+// ServerClock 交易所服务器时钟：记录本机与服务器的时间偏移，签名时间戳按偏移校正
+// 首次取时间时同步，之后每 30 分钟或交易所返回时间戳错误时重新同步
+type ServerClock struct {
+	name  string
+	fetch func(ctx context.Context) (time.Time, error) // 获取服务器时间
+	// onSync 同步成功后回调（offset = 服务器时间 - 本机时间），用于把偏移写入 SDK 客户端
+	onSync func(offset time.Duration)
+
+	offset   atomic.Int64 // 服务器时间 - 本机时间（纳秒）
+	lastSync atomic.Int64 // 上次同步尝试时间（unix 纳秒，0=从未同步）
+	synced   atomic.Bool  // 是否成功同步过
+	syncing  atomic.Bool
+}
+
+// NewServerClock 创建服务器时钟（fetch 获取交易所服务器时间）
+func NewServerClock(name string, fetch func(ctx context.Context) (time.Time, error)) *ServerClock {
+	return &ServerClock{name: name, fetch: fetch}
+}
+
+// Offset 当前时间偏移（服务器时间 - 本机时间）
+func (c *ServerClock) Offset() time.Duration {
+	return time.Duration(c.offset.Load())
+}
+
+// Now 按偏移校正后的服务器时间（从未同步时先同步一次；偏移过期时后台重新同步）
+func (c *ServerClock) Now() time.Time {
+	if c == nil {
+		return time.Now()
+	}
+	last := c.lastSync.Load()
+	switch {
+	case last == 0:
+		ctx, cancel := backgroundCallContext()
+		c.Sync(ctx)
+		cancel()
+	case time.Since(time.Unix(0, last)) > clockResyncInterval:
+		c.Resync()
+	}
+	return time.Now().Add(c.Offset())
+}
+
+// Sync 同步服务器时间：以请求往返的中点作为服务器时间对应的本机时间，尽量抵消网络延迟
+func (c *ServerClock) Sync(ctx context.Context) error {
+	c.lastSync.Store(time.Now().UnixNano())
+	start := time.Now()
+	serverTime, err := c.fetch(ctx)
+	if err != nil {
+		log.Warn("⚠️ 同步服务器时间失败", "exchange", c.name, "error", err)
+		return err
+	}
+	rtt := time.Since(start)
+	offset := serverTime.Sub(start.Add(rtt / 2))
+
+	previous, wasSynced := c.Offset(), c.synced.Load()
+	c.offset.Store(int64(offset))
+	c.synced.Store(true)
+	if c.onSync != nil {
+		c.onSync(offset)
+	}
+
+	if !wasSynced || (offset-previous).Abs() >= 100*time.Millisecond {
+		log.Info("⏱ 已同步服务器时间", "exchange", c.name, "offset_ms", offset.Milliseconds(), "rtt_ms", rtt.Milliseconds())
+	}
+	if offset.Abs() > clockSkewWarnThreshold {
+		log.Warn("⚠️ 本机时间与服务器相差较大，签名时间戳已按偏移校正，建议检查本机 NTP 时间同步", "exchange", c.name, "offset_ms", offset.Milliseconds())
+	}
+	return nil
+}
+
+// Resync 后台重新同步（交易所返回时间戳错误时调用；距上次同步不足 10 秒或正在同步时忽略）
+func (c *ServerClock) Resync() {
+	if c == nil || time.Since(time.Unix(0, c.lastSync.Load())) < clockMinResyncInterval {
+		return
+	}
+	if !c.syncing.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer c.syncing.Store(false)
+		ctx, cancel := backgroundCallContext()
+		defer cancel()
+		c.Sync(ctx)
+	}()
+}
+
+// ClockSyncer 维护服务器时钟的交易器（时间戳错误时由错误分类装饰器触发重新同步）
+type ClockSyncer interface {
+	ServerClock() *ServerClock
+}
@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// newFakeServerClock 服务器时间比本机快 skew 的时钟，返回同步次数计数
+func newFakeServerClock(skew time.Duration) (*ServerClock, *int32) {
+	var syncs int32
+	clock := NewServerClock("test", func(ctx context.Context) (time.Time, error) {
+		atomic.AddInt32(&syncs, 1)
+		return time.Now().Add(skew), nil
+	})
+	return clock, &syncs
+}
+
+func TestServerClockSync(t *testing.T) {
+	clock, syncs := newFakeServerClock(-3 * time.Second)
+	var applied time.Duration
+	clock.onSync = func(offset time.Duration) { applied = offset }
+
+	// 首次取时间时同步
+	now := clock.Now()
+	if atomic.LoadInt32(syncs) != 1 {
+		t.Fatalf("首次 Now() 应同步一次, syncs = %d", *syncs)
+	}
+	if d := time.Until(now); d > -2900*time.Millisecond || d < -3100*time.Millisecond {
+		t.Errorf("Now() 偏离本机 %v, want ≈-3s", d)
+	}
+	if applied != clock.Offset() {
+		t.Errorf("onSync 偏移 = %v, want %v", applied, clock.Offset())
+	}
+
+	// 刚同步过：再次取时间和按需重同步都不请求服务器
+	clock.Now()
+	clock.Resync()
+	if n := atomic.LoadInt32(syncs); n != 1 {
+		t.Errorf("同步间隔内不应重复同步, syncs = %d", n)
+	}
+
+	// 超过最小间隔后按需重同步（后台执行）
+	clock.lastSync.Store(time.Now().Add(-time.Minute).UnixNano())
+	clock.Resync()
+	waitFor(t, func() bool { return atomic.LoadInt32(syncs) == 2 })
+}
+
+func TestServerClockSyncFailure(t *testing.T) {
+	clock := NewServerClock("test", func(ctx context.Context) (time.Time, error) {
+		return time.Time{}, errors.New("unreachable")
+	})
+	if err := clock.Sync(context.Background()); err == nil {
+		t.Error("Sync() 应返回错误")
+	}
+	if clock.Offset() != 0 {
+		t.Errorf("同步失败时不应修改偏移, Offset() = %v", clock.Offset())
+	}
+
+	var nilClock *ServerClock
+	if d := time.Since(nilClock.Now()).Abs(); d > time.Second {
+		t.Errorf("nil 时钟应返回本机时间, 偏差 %v", d)
+	}
+	nilClock.Resync()
+}
+
+func TestClassifyingTraderResyncsOnTimestampError(t *testing.T) {
+	clock, syncs := newFakeServerClock(time.Second)
+	ct := newClassifyingTrader(nil, "binance")
+	ct.clock = clock
+
+	ct.classify(&common.APIError{Code: -2019, Message: "Margin is insufficient."})
+	if n := atomic.LoadInt32(syncs); n != 0 {
+		t.Errorf("非时间戳错误不应重新同步, syncs = %d", n)
+	}
+
+	err := ct.classify(&common.APIError{Code: -1021, Message: "Timestamp for this request is outside of the recvWindow."})
+	if !errors.Is(err, ErrTimestamp) {
+		t.Fatalf("classify() = %v, want ErrTimestamp", err)
+	}
+	waitFor(t, func() bool { return atomic.LoadInt32(syncs) == 1 })
+}
+
+// waitFor 等待条件成立（最多 2 秒）
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("等待超时")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
@@ -0,0 +1,132 @@
+package trader
+
+import (
+	"context"
+	"nofx/decision"
+	"nofx/market"
+	"time"
+)
+
+// DefaultCallTimeout 单次交易所调用的默认超时（NOFX_EXCHANGE_TIMEOUT 覆盖）
+// 下单/平仓不受此限制（限价单等待成交可能超过该时间），只随 ctx 取消
+var DefaultCallTimeout = 30 * time.Second
+
+// backgroundCallContext 没有调用方 ctx 时（初始化、精度缓存刷新等）使用的带默认超时 ctx
+func backgroundCallContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), DefaultCallTimeout)
+}
+
+// sleepContext 等待 d，ctx 取消时提前返回 ctx.Err()
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// timeoutTrader 为每次调用附加超时的 Trader 装饰器（挂起的交易所接口不会拖住整个交易周期）
+type timeoutTrader struct {
+	Trader
+	timeout time.Duration
+}
+
+// newTimeoutTrader 为 trader 包装单次调用超时（timeout <= 0 时不限制）
+func newTimeoutTrader(t Trader, timeout time.Duration) *timeoutTrader {
+	return &timeoutTrader{Trader: t, timeout: timeout}
+}
+
+// withTimeout 在 ctx 上附加超时（调用方 ctx 自带更早的截止时间时以调用方为准）
+func (t *timeoutTrader) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if t.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, t.timeout)
+}
+
+func (t *timeoutTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetBalance(ctx)
+}
+
+func (t *timeoutTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetPositions(ctx)
+}
+
+func (t *timeoutTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.SetLeverage(ctx, symbol, leverage)
+}
+
+func (t *timeoutTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.SetMarginMode(ctx, symbol, isCrossMargin)
+}
+
+func (t *timeoutTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetMarketPrice(ctx, symbol)
+}
+
+func (t *timeoutTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetFundingRate(ctx, symbol)
+}
+
+func (t *timeoutTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetFundingRateHistory(ctx, symbol, n)
+}
+
+func (t *timeoutTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.SetStopLoss(ctx, symbol, positionSide, quantity, stopPrice)
+}
+
+func (t *timeoutTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice)
+}
+
+func (t *timeoutTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.CancelStopLossOrders(ctx, symbol)
+}
+
+func (t *timeoutTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.CancelTakeProfitOrders(ctx, symbol)
+}
+
+func (t *timeoutTrader) CancelAllOrders(ctx context.Context, symbol string) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.CancelAllOrders(ctx, symbol)
+}
+
+func (t *timeoutTrader) CancelStopOrders(ctx context.Context, symbol string) error {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.CancelStopOrders(ctx, symbol)
+}
+
+func (t *timeoutTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
+	ctx, cancel := t.withTimeout(ctx)
+	defer cancel()
+	return t.Trader.GetOpenOrders(ctx, symbol)
+}
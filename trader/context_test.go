@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// deadlineTrader 记录调用时 ctx 是否带截止时间的 MockTrader
+type deadlineTrader struct {
+	*MockTrader
+	balanceDeadline  bool
+	openLongDeadline bool
+}
+
+func (t *deadlineTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	_, t.balanceDeadline = ctx.Deadline()
+	return t.MockTrader.GetBalance(ctx)
+}
+
+func (t *deadlineTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	_, t.openLongDeadline = ctx.Deadline()
+	return t.MockTrader.OpenLong(ctx, symbol, quantity, leverage)
+}
+
+func TestTimeoutTrader(t *testing.T) {
+	inner := &deadlineTrader{MockTrader: &MockTrader{}}
+	tt := newTimeoutTrader(inner, time.Minute)
+
+	if _, err := tt.GetBalance(context.Background()); err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if !inner.balanceDeadline {
+		t.Error("查询调用应附加超时")
+	}
+	if _, err := tt.OpenLong(context.Background(), "BTCUSDT", 1, 5); err != nil {
+		t.Fatalf("OpenLong() error = %v", err)
+	}
+	if inner.openLongDeadline {
+		t.Error("下单调用不应附加超时")
+	}
+
+	inner = &deadlineTrader{MockTrader: &MockTrader{}}
+	if _, err := newTimeoutTrader(inner, 0).GetBalance(context.Background()); err != nil {
+		t.Fatalf("GetBalance() error = %v", err)
+	}
+	if inner.balanceDeadline {
+		t.Error("timeout <= 0 时不应附加超时")
+	}
+}
+
+func TestAsterRequestHonorsContext(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	mockServer := startIPv4Server(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-release:
+		}
+	}))
+	defer mockServer.Close()
+
+	trader, _ := NewAsterTrader(
+		"0x1234567890123456789012345678901234567890",
+		"0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	)
+	trader.baseURL = mockServer.URL
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := trader.GetBalance(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("GetBalance() error = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("挂起的接口应在 ctx 超时后立即返回, 耗时 %v", elapsed)
+	}
+}
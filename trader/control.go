@@ -0,0 +1,88 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PauseState 人工暂停状态
+type PauseState struct {
+	Paused bool      `json:"paused"`
+	Reason string    `json:"reason,omitempty"`
+	Since  time.Time `json:"since,omitempty"`
+}
+
+// Pause 人工暂停交易（决策周期跳过，持仓和止盈止损单保持不变）
+func (at *AutoTrader) Pause(reason string) {
+	at.pauseMutex.Lock()
+	at.pause = PauseState{Paused: true, Reason: reason, Since: time.Now()}
+	at.pauseMutex.Unlock()
+	at.notify(AlertSeverityWarning, "交易已暂停", "原因: %s", reason)
+}
+
+// Resume 恢复人工暂停的交易
+func (at *AutoTrader) Resume() error {
+	at.pauseMutex.Lock()
+	defer at.pauseMutex.Unlock()
+	if !at.pause.Paused {
+		return fmt.Errorf("交易未处于暂停状态")
+	}
+	at.pause = PauseState{}
+	log.Info("▶️ 交易已恢复", "trader", at.name)
+	return nil
+}
+
+// GetPauseState 当前人工暂停状态
+func (at *AutoTrader) GetPauseState() PauseState {
+	at.pauseMutex.Lock()
+	defer at.pauseMutex.Unlock()
+	return at.pause
+}
+
+// ClosePosition 人工平掉指定币种的全部持仓（多空双向）并撤销该币种挂单
+func (at *AutoTrader) ClosePosition(symbol string) error {
+	symbol = strings.ToUpper(symbol)
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	var closed int
+	for _, pos := range positions {
+		if pos["symbol"] != symbol {
+			continue
+		}
+		if amt, _ := pos["positionAmt"].(float64); math.Abs(amt) == 0 {
+			continue
+		}
+		side, _ := pos["side"].(string)
+		if err := at.emergencyClosePosition(symbol, side); err != nil {
+			return fmt.Errorf("平仓 %s %s 失败: %w", symbol, side, err)
+		}
+		closed++
+	}
+	if closed == 0 {
+		return fmt.Errorf("没有 %s 的持仓", symbol)
+	}
+	if err := at.trader.CancelAllOrders(at.ctx(), symbol); err != nil {
+		log.Warn("⚠️ 人工平仓后撤销挂单失败", "trader", at.name, "symbol", symbol, "error", err)
+	}
+	at.notify(AlertSeverityWarning, "人工平仓", "已平掉 %s 的 %d 个持仓", symbol, closed)
+	return nil
+}
+
+// KillSwitch 紧急停止：暂停交易，撤销所有挂单并平掉全部持仓
+func (at *AutoTrader) KillSwitch(reason string) error {
+	at.pauseMutex.Lock()
+	at.pause = PauseState{Paused: true, Reason: "killswitch: " + reason, Since: time.Now()}
+	at.pauseMutex.Unlock()
+
+	if err := at.flattenAll(); err != nil {
+		at.notify(AlertSeverityCritical, "紧急停止失败", "交易已暂停，但平仓未完成: %v", err)
+		return err
+	}
+	at.notify(AlertSeverityCritical, "紧急停止", "已暂停交易并平掉全部持仓（原因: %s）", reason)
+	return nil
+}
@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"testing"
+)
+
+func newControlTestTrader(mock *MockTrader) *AutoTrader {
+	return &AutoTrader{id: "t1", name: "test", trader: mock}
+}
+
+func TestPauseResume(t *testing.T) {
+	at := newControlTestTrader(&MockTrader{})
+	if err := at.Resume(); err == nil {
+		t.Error("expected error when resuming a running trader")
+	}
+
+	at.Pause("maintenance")
+	state := at.GetPauseState()
+	if !state.Paused || state.Reason != "maintenance" || state.Since.IsZero() {
+		t.Fatalf("unexpected pause state: %+v", state)
+	}
+	if paused, _ := at.GetStatus()["paused"].(bool); !paused {
+		t.Error("GetStatus should report paused")
+	}
+
+	if err := at.Resume(); err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if at.GetPauseState().Paused {
+		t.Error("trader still paused after Resume")
+	}
+}
+
+func TestClosePosition(t *testing.T) {
+	mock := &MockTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+		{"symbol": "BTCUSDT", "side": "short", "positionAmt": -0.2},
+		{"symbol": "ETHUSDT", "side": "long", "positionAmt": 1.0},
+	}}
+	at := newControlTestTrader(mock)
+
+	if err := at.ClosePosition("btcusdt"); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	if err := at.ClosePosition("SOLUSDT"); err == nil {
+		t.Error("expected error for symbol without position")
+	}
+
+	mock.shouldFailCloseShort = true
+	if err := at.ClosePosition("BTCUSDT"); err == nil {
+		t.Error("expected close failure to be returned")
+	}
+}
+
+func TestKillSwitch(t *testing.T) {
+	mock := &MockTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+	}}
+	at := newControlTestTrader(mock)
+
+	if err := at.KillSwitch("outage"); err != nil {
+		t.Fatalf("KillSwitch: %v", err)
+	}
+	if state := at.GetPauseState(); !state.Paused || state.Reason != "killswitch: outage" {
+		t.Errorf("unexpected pause state: %+v", state)
+	}
+
+	// 平仓失败时仍保持暂停
+	mock.shouldFailCloseLong = true
+	at.Resume()
+	if err := at.KillSwitch("outage"); err == nil {
+		t.Error("expected flatten failure to be returned")
+	}
+	if !at.GetPauseState().Paused {
+		t.Error("trader should stay paused when flatten fails")
+	}
+}
@@ -0,0 +1,136 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// CorrelationGroup 相关性分组配置
+// 将高度相关的币种（如 BTC-beta 山寨币）归为一组，限制组内同方向合计敞口，
+// 防止同时开多个高度相关的多单，实际上等于押注一笔巨大的单边仓位
+type CorrelationGroup struct {
+	Name           string   `json:"name"`             // 分组名称（如 "btc_beta_alts"）
+	Symbols        []string `json:"symbols"`          // 组内币种（如 ["SOLUSDT", "AVAXUSDT"]）
+	MaxExposurePct float64  `json:"max_exposure_pct"` // 组内同方向合计名义价值占账户净值的上限（百分比）
+}
+
+// ParseCorrelationGroups 解析相关性分组，格式 "btc_beta_alts:30:SOLUSDT,AVAXUSDT;memes:15:DOGEUSDT,1000PEPEUSDT"
+// （分组之间用分号分隔，每组为 名称:同方向敞口上限百分比:币种列表）
+func ParseCorrelationGroups(raw string) ([]CorrelationGroup, error) {
+	var groups []CorrelationGroup
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(raw, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.SplitN(part, ":", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("分组 %q 格式错误（应为 名称:上限百分比:币种1,币种2）", part)
+		}
+		name := strings.TrimSpace(fields[0])
+		if name == "" || seen[name] {
+			return nil, fmt.Errorf("分组 %q 名称为空或重复", part)
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil || pct <= 0 {
+			return nil, fmt.Errorf("分组 %s 的敞口上限 %q 必须为正数", name, fields[1])
+		}
+		group := CorrelationGroup{Name: name, MaxExposurePct: pct}
+		for _, symbol := range strings.Split(fields[2], ",") {
+			if symbol = strings.TrimSpace(symbol); symbol != "" {
+				group.Symbols = append(group.Symbols, normalizeSymbol(symbol))
+			}
+		}
+		if len(group.Symbols) < 2 {
+			return nil, fmt.Errorf("分组 %s 至少需要 2 个币种", name)
+		}
+		seen[name] = true
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// CorrelationGroupsFromEnv 读取 NOFX_CORRELATION_GROUPS（未设置返回 nil=不限制）
+func CorrelationGroupsFromEnv() ([]CorrelationGroup, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_CORRELATION_GROUPS"))
+	if raw == "" {
+		return nil, nil
+	}
+	groups, err := ParseCorrelationGroups(raw)
+	if err != nil {
+		return nil, fmt.Errorf("NOFX_CORRELATION_GROUPS 配置错误: %w", err)
+	}
+	return groups, nil
+}
+
+// contains 判断币种是否属于该分组（自动标准化符号）
+func (g CorrelationGroup) contains(symbol string) bool {
+	symbol = normalizeSymbol(symbol)
+	for _, s := range g.Symbols {
+		if normalizeSymbol(s) == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// checkCorrelationExposure 检查开仓后相关性分组的合计敞口是否超限
+// positions 为交易所返回的当前持仓，newNotional 为本次开仓的名义价值（USDT）
+func (at *AutoTrader) checkCorrelationExposure(symbol, side string, newNotional float64, positions []map[string]interface{}, equity float64) error {
+	return at.correlationExposure(symbol, side, newNotional, positions, equity, nil, true)
+}
+
+// correlationExposure 按现有持仓加上 reserved 中本批并发开仓已占用的名义价值（key 为 correlationKey）检查分组敞口
+func (at *AutoTrader) correlationExposure(symbol, side string, newNotional float64, positions []map[string]interface{}, equity float64, reserved map[string]float64, verbose bool) error {
+	if len(at.config.CorrelationGroups) == 0 || equity <= 0 {
+		return nil
+	}
+
+	for _, group := range at.config.CorrelationGroups {
+		if group.MaxExposurePct <= 0 || !group.contains(symbol) {
+			continue
+		}
+
+		exposure := groupExposure(group, side, positions) + reserved[correlationKey(group, side)]
+		limit := equity * group.MaxExposurePct / 100
+		if exposure+newNotional > limit {
+			return fmt.Errorf("❌ 相关性分组 [%s] %s 方向敞口超限：现有 %.2f + 新开 %.2f > 上限 %.2f USDT (净值 %.2f × %.1f%%)",
+				group.Name, side, exposure, newNotional, limit, equity, group.MaxExposurePct)
+		}
+
+		if verbose {
+			log.Info("  ✓ 相关性分组敞口检查通过", "group", group.Name, "side", side, "exposure", exposure+newNotional, "limit", limit)
+		}
+	}
+
+	return nil
+}
+
+// correlationKey 分组同方向敞口的占用键
+func correlationKey(group CorrelationGroup, side string) string {
+	return group.Name + "|" + strings.ToLower(side)
+}
+
+// groupExposure 计算分组内指定方向现有持仓的名义价值合计
+func groupExposure(group CorrelationGroup, side string, positions []map[string]interface{}) float64 {
+	total := 0.0
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		posSide, _ := pos["side"].(string)
+		if !strings.EqualFold(posSide, side) || !group.contains(symbol) {
+			continue
+		}
+
+		quantity, _ := pos["positionAmt"].(float64)
+		price, _ := pos["markPrice"].(float64)
+		if price <= 0 {
+			price, _ = pos["entryPrice"].(float64)
+		}
+		total += math.Abs(quantity) * price
+	}
+	return total
+}
@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCheckCorrelationExposure 测试相关性分组合计敞口限制
+func TestCheckCorrelationExposure(t *testing.T) {
+	at := &AutoTrader{
+		config: AutoTraderConfig{
+			CorrelationGroups: []CorrelationGroup{
+				{Name: "btc_beta_alts", Symbols: []string{"SOL", "AVAXUSDT", "DOGEUSDT"}, MaxExposurePct: 50},
+			},
+		},
+	}
+
+	positions := []map[string]interface{}{
+		{"symbol": "SOLUSDT", "side": "long", "positionAmt": 20.0, "markPrice": 150.0},    // 3000
+		{"symbol": "AVAXUSDT", "side": "long", "positionAmt": 50.0, "markPrice": 30.0},    // 1500
+		{"symbol": "DOGEUSDT", "side": "short", "positionAmt": 10000.0, "markPrice": 0.2}, // 空单，不计入多头敞口
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1, "markPrice": 50000.0},   // 不在分组内
+	}
+
+	tests := []struct {
+		name        string
+		symbol      string
+		side        string
+		notional    float64
+		expectedErr string
+	}{
+		{name: "组内多单_未超限", symbol: "DOGEUSDT", side: "long", notional: 400},
+		{name: "组内多单_超限", symbol: "DOGEUSDT", side: "long", notional: 800, expectedErr: "敞口超限"},
+		{name: "组内空单_独立计算", symbol: "SOLUSDT", side: "short", notional: 2000},
+		{name: "组外币种_不受限制", symbol: "BTCUSDT", side: "long", notional: 100000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// 净值 10000，上限 50% = 5000，现有多头敞口 4500
+			err := at.checkCorrelationExposure(tt.symbol, tt.side, tt.notional, positions, 10000)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("期望通过，实际错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("期望错误包含 %q，实际: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+// TestCheckCorrelationExposure_NoGroups 测试未配置分组时不做限制
+func TestCheckCorrelationExposure_NoGroups(t *testing.T) {
+	at := &AutoTrader{}
+	if err := at.checkCorrelationExposure("SOLUSDT", "long", 1e9, nil, 100); err != nil {
+		t.Fatalf("未配置分组时不应限制: %v", err)
+	}
+}
+
+func TestCorrelationGroupsFromEnv(t *testing.T) {
+	if groups, err := CorrelationGroupsFromEnv(); err != nil || groups != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", groups, err)
+	}
+
+	t.Setenv("NOFX_CORRELATION_GROUPS", "btc_beta_alts:30:sol, AVAXUSDT ,ARBUSDT; memes:15:DOGEUSDT,1000PEPEUSDT")
+	groups, err := CorrelationGroupsFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(groups) != 2 || groups[0].Name != "btc_beta_alts" || groups[0].MaxExposurePct != 30 ||
+		strings.Join(groups[0].Symbols, ",") != "SOLUSDT,AVAXUSDT,ARBUSDT" || groups[1].Name != "memes" {
+		t.Errorf("分组解析错误: %+v", groups)
+	}
+
+	for _, raw := range []string{"alts:30", "alts:0:SOLUSDT,AVAXUSDT", "alts:30:SOLUSDT", "a:30:SOLUSDT,AVAXUSDT;a:20:DOGEUSDT,PEPEUSDT"} {
+		t.Setenv("NOFX_CORRELATION_GROUPS", raw)
+		if _, err := CorrelationGroupsFromEnv(); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultFlattenWarningMinutes 默认提前多少分钟发出收盘平仓预警
+const defaultFlattenWarningMinutes = 10
+
+// dailyFlattenSchedule 收盘平仓时间（在指定时区下每天的时:分）
+type dailyFlattenSchedule struct {
+	hour     int
+	minute   int
+	location *time.Location
+}
+
+// parseDailyFlattenSchedule 解析收盘平仓配置（时间格式 "HH:MM"，时区为 IANA 名称，空=UTC）
+func parseDailyFlattenSchedule(flattenTime, timezone string) (*dailyFlattenSchedule, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(flattenTime))
+	if err != nil {
+		return nil, fmt.Errorf("收盘平仓时间格式错误（应为 HH:MM）: %s", flattenTime)
+	}
+
+	location := time.UTC
+	if tz := strings.TrimSpace(timezone); tz != "" {
+		location, err = time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("无效的时区 %s: %w", tz, err)
+		}
+	}
+
+	return &dailyFlattenSchedule{hour: t.Hour(), minute: t.Minute(), location: location}, nil
+}
+
+// DailyFlattenFromEnv 读取 NOFX_DAILY_FLATTEN_TIME（"HH:MM"，未设置返回空=关闭）、NOFX_DAILY_FLATTEN_TIMEZONE
+// 和 NOFX_FLATTEN_WARNING_MINUTES，返回平仓时间、时区和预警提前分钟数（0=默认）
+func DailyFlattenFromEnv() (string, string, int, error) {
+	flattenTime := strings.TrimSpace(os.Getenv("NOFX_DAILY_FLATTEN_TIME"))
+	if flattenTime == "" {
+		return "", "", 0, nil
+	}
+	timezone := strings.TrimSpace(os.Getenv("NOFX_DAILY_FLATTEN_TIMEZONE"))
+	if _, err := parseDailyFlattenSchedule(flattenTime, timezone); err != nil {
+		return "", "", 0, fmt.Errorf("NOFX_DAILY_FLATTEN_TIME 配置错误: %w", err)
+	}
+	warning := 0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_FLATTEN_WARNING_MINUTES")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return "", "", 0, fmt.Errorf("NOFX_FLATTEN_WARNING_MINUTES 必须为正整数: %q", raw)
+		}
+		warning = n
+	}
+	return flattenTime, timezone, warning, nil
+}
+
+// flattenAt 返回 now 所在交易日（按配置时区）的收盘平仓时刻
+func (s *dailyFlattenSchedule) flattenAt(now time.Time) time.Time {
+	local := now.In(s.location)
+	return time.Date(local.Year(), local.Month(), local.Day(), s.hour, s.minute, 0, 0, s.location)
+}
+
+// dayKey 返回 now 在配置时区下的日期（用于保证每天只预警/平仓一次）
+func (s *dailyFlattenSchedule) dayKey(now time.Time) string {
+	return now.In(s.location).Format("2006-01-02")
+}
+
+// 启动收盘平仓监控
+// 每天到达 DailyFlattenTime 时平掉全部持仓并撤销所有挂单，提前 FlattenWarningMinutes 分钟发出预警
+func (at *AutoTrader) startDailyFlattenMonitor() {
+	if at.config.DailyFlattenTime == "" {
+		return
+	}
+
+	schedule, err := parseDailyFlattenSchedule(at.config.DailyFlattenTime, at.config.DailyFlattenTimezone)
+	if err != nil {
+		log.Error("❌ 收盘平仓监控未启动", "error", err)
+		return
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(30 * time.Second) // 每30秒检查一次
+		defer ticker.Stop()
+
+		log.Info("🌙 启动收盘平仓监控", "hour", schedule.hour, "minute", schedule.minute, "location", schedule.location)
+
+		var warnedDay, flattenedDay string
+		for {
+			select {
+			case <-ticker.C:
+				warnedDay, flattenedDay = at.checkDailyFlatten(schedule, time.Now(), warnedDay, flattenedDay)
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止收盘平仓监控")
+				return
+			}
+		}
+	}()
+}
+
+// checkDailyFlatten 检查是否到达预警/平仓时间，返回更新后的已预警日期和已平仓日期
+func (at *AutoTrader) checkDailyFlatten(schedule *dailyFlattenSchedule, now time.Time, warnedDay, flattenedDay string) (string, string) {
+	day := schedule.dayKey(now)
+	flattenAt := schedule.flattenAt(now)
+
+	if !now.Before(flattenAt) {
+		if flattenedDay != day {
+			if err := at.flattenAll(); err != nil {
+				log.Error("❌ 收盘平仓失败", "error", err)
+				return warnedDay, flattenedDay // 下次检查重试
+			}
+			flattenedDay = day
+		}
+		return warnedDay, flattenedDay
+	}
+
+	warningMinutes := at.getFlattenWarningMinutes()
+	if warningMinutes <= 0 {
+		warningMinutes = defaultFlattenWarningMinutes
+	}
+	if warnedDay != day && !now.Before(flattenAt.Add(-time.Duration(warningMinutes)*time.Minute)) {
+		log.Info("📢 收盘平仓预警：将平掉全部持仓并撤销挂单", "trader", at.name, "flatten_at", flattenAt.Format("15:04 MST"), "remaining_min", flattenAt.Sub(now).Minutes())
+		warnedDay = day
+	}
+	return warnedDay, flattenedDay
+}
+
+// flattenAll 撤销所有挂单并平掉全部持仓
+func (at *AutoTrader) flattenAll() error {
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	// 先撤单（包括没有持仓的限价单），避免平仓后止盈止损单残留
+	symbols := make(map[string]bool)
+	for _, pos := range positions {
+		symbols[pos["symbol"].(string)] = true
+	}
+	if orders, err := at.trader.GetOpenOrders(at.ctx(), ""); err == nil {
+		for _, order := range orders {
+			symbols[order.Symbol] = true
+		}
+	} else {
+		log.Warn("⚠️ 收盘平仓: 获取挂单失败，仅撤销持仓币种的挂单", "error", err)
+	}
+	for symbol := range symbols {
+		if err := at.trader.CancelAllOrders(at.ctx(), symbol); err != nil {
+			log.Warn("⚠️ 收盘平仓: 撤销挂单失败", "symbol", symbol, "error", err)
+		}
+	}
+
+	var failed []string
+	for _, pos := range positions {
+		symbol := pos["symbol"].(string)
+		side := pos["side"].(string)
+		quantity := math.Abs(pos["positionAmt"].(float64))
+		if quantity == 0 {
+			continue
+		}
+
+		if side == "long" {
+			_, err = at.trader.CloseLong(at.ctx(), symbol, 0) // 0 = 全部平仓
+		} else {
+			_, err = at.trader.CloseShort(at.ctx(), symbol, 0)
+		}
+		if err != nil {
+			log.Error("❌ 收盘平仓失败", "symbol", symbol, "side", side, "error", err)
+			failed = append(failed, symbol+"_"+side)
+			continue
+		}
+		log.Info("✅ 收盘平仓", "symbol", symbol, "side", side, "quantity", quantity)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("部分持仓平仓失败: %s", strings.Join(failed, ", "))
+	}
+	log.Info("🌙 收盘平仓完成", "trader", at.name, "canceled_symbols", len(symbols), "closed_positions", len(positions))
+	return nil
+}
+
+// checkDailyFlattenWindow 收盘平仓之后到当天结束不再开新仓（避免AI在收盘后重新开仓）
+func (at *AutoTrader) checkDailyFlattenWindow(now time.Time) error {
+	if at.config.DailyFlattenTime == "" {
+		return nil
+	}
+	schedule, err := parseDailyFlattenSchedule(at.config.DailyFlattenTime, at.config.DailyFlattenTimezone)
+	if err != nil {
+		return nil // 配置错误时监控未启动，不限制开仓
+	}
+	if flattenAt := schedule.flattenAt(now); !now.Before(flattenAt) {
+		return fmt.Errorf("❌ 已过收盘平仓时间 %s，当天不再开新仓", flattenAt.Format("15:04 MST"))
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package trader
+
+import (
+	"context"
+	"nofx/decision"
+	"sort"
+	"testing"
+	"time"
+)
+
+// flattenRecordingTrader 记录撤单与平仓调用的 MockTrader
+type flattenRecordingTrader struct {
+	MockTrader
+	openOrders []decision.OpenOrderInfo
+	cancelled  []string
+	closed     []string
+}
+
+func (m *flattenRecordingTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
+	return m.openOrders, nil
+}
+
+func (m *flattenRecordingTrader) CancelAllOrders(ctx context.Context, symbol string) error {
+	m.cancelled = append(m.cancelled, symbol)
+	return nil
+}
+
+func (m *flattenRecordingTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	m.closed = append(m.closed, symbol+"_long")
+	return m.MockTrader.CloseLong(context.Background(), symbol, quantity)
+}
+
+func (m *flattenRecordingTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	m.closed = append(m.closed, symbol+"_short")
+	return m.MockTrader.CloseShort(context.Background(), symbol, quantity)
+}
+
+func TestParseDailyFlattenSchedule(t *testing.T) {
+	schedule, err := parseDailyFlattenSchedule("15:45", "America/New_York")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+
+	// 2024-07-01 为夏令时（UTC-4），15:45 纽约时间 = 19:45 UTC
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	if got := schedule.flattenAt(now).UTC(); !got.Equal(time.Date(2024, 7, 1, 19, 45, 0, 0, time.UTC)) {
+		t.Errorf("平仓时刻错误: %v", got)
+	}
+
+	// UTC 已是次日 02:00，但纽约仍是 7月1日
+	if day := schedule.dayKey(time.Date(2024, 7, 2, 2, 0, 0, 0, time.UTC)); day != "2024-07-01" {
+		t.Errorf("交易日应按配置时区计算，实际 %s", day)
+	}
+
+	if _, err := parseDailyFlattenSchedule("25:00", ""); err == nil {
+		t.Error("无效时间应返回错误")
+	}
+	if _, err := parseDailyFlattenSchedule("16:00", "Mars/Base"); err == nil {
+		t.Error("无效时区应返回错误")
+	}
+}
+
+func TestDailyFlattenFromEnv(t *testing.T) {
+	if flattenTime, _, _, err := DailyFlattenFromEnv(); err != nil || flattenTime != "" {
+		t.Fatalf("未配置时应关闭: %q %v", flattenTime, err)
+	}
+
+	t.Setenv("NOFX_DAILY_FLATTEN_TIME", "15:45")
+	t.Setenv("NOFX_DAILY_FLATTEN_TIMEZONE", "America/New_York")
+	t.Setenv("NOFX_FLATTEN_WARNING_MINUTES", "5")
+	flattenTime, timezone, warning, err := DailyFlattenFromEnv()
+	if err != nil || flattenTime != "15:45" || timezone != "America/New_York" || warning != 5 {
+		t.Errorf("配置解析错误: %q %q %d %v", flattenTime, timezone, warning, err)
+	}
+
+	t.Setenv("NOFX_DAILY_FLATTEN_TIMEZONE", "Mars/Base")
+	if _, _, _, err := DailyFlattenFromEnv(); err == nil {
+		t.Error("无效时区应返回错误")
+	}
+	t.Setenv("NOFX_DAILY_FLATTEN_TIMEZONE", "")
+	t.Setenv("NOFX_FLATTEN_WARNING_MINUTES", "soon")
+	if _, _, _, err := DailyFlattenFromEnv(); err == nil {
+		t.Error("无效的预警分钟数应返回错误")
+	}
+}
+
+func TestCheckDailyFlatten(t *testing.T) {
+	mock := &flattenRecordingTrader{
+		MockTrader: MockTrader{positions: []map[string]interface{}{
+			{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+			{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0},
+		}},
+		openOrders: []decision.OpenOrderInfo{{Symbol: "SOLUSDT", Type: "LIMIT"}},
+	}
+	at := &AutoTrader{
+		trader: mock,
+		config: AutoTraderConfig{DailyFlattenTime: "16:00", FlattenWarningMinutes: 15},
+	}
+	schedule, err := parseDailyFlattenSchedule("16:00", "")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+
+	day := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// 预警前：什么都不做
+	warned, flattened := at.checkDailyFlatten(schedule, day.Add(15*time.Hour), "", "")
+	if warned != "" || flattened != "" {
+		t.Fatalf("预警前不应有动作: warned=%q flattened=%q", warned, flattened)
+	}
+
+	// 预警窗口内：只预警不平仓
+	warned, flattened = at.checkDailyFlatten(schedule, day.Add(15*time.Hour+50*time.Minute), warned, flattened)
+	if warned != "2024-07-01" || flattened != "" || len(mock.closed) != 0 {
+		t.Fatalf("预警窗口内应只预警: warned=%q flattened=%q closed=%v", warned, flattened, mock.closed)
+	}
+
+	// 到达平仓时间：撤销全部挂单并平仓
+	warned, flattened = at.checkDailyFlatten(schedule, day.Add(16*time.Hour), warned, flattened)
+	if flattened != "2024-07-01" {
+		t.Fatalf("到达平仓时间应平仓，flattened=%q", flattened)
+	}
+	sort.Strings(mock.cancelled)
+	if len(mock.cancelled) != 3 || mock.cancelled[0] != "BTCUSDT" || mock.cancelled[2] != "SOLUSDT" {
+		t.Errorf("应撤销持仓和挂单币种的全部挂单，实际 %v", mock.cancelled)
+	}
+	if len(mock.closed) != 2 {
+		t.Errorf("应平掉全部持仓，实际 %v", mock.closed)
+	}
+
+	// 同一天不重复平仓
+	at.checkDailyFlatten(schedule, day.Add(17*time.Hour), warned, flattened)
+	if len(mock.closed) != 2 {
+		t.Errorf("同一天不应重复平仓，实际 %v", mock.closed)
+	}
+}
+
+func TestCheckDailyFlattenWindow(t *testing.T) {
+	at := &AutoTrader{config: AutoTraderConfig{DailyFlattenTime: "16:00", DailyFlattenTimezone: "Asia/Shanghai"}}
+
+	// 上海 15:00 = UTC 07:00
+	if err := at.checkDailyFlattenWindow(time.Date(2024, 7, 1, 7, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("收盘前应允许开仓: %v", err)
+	}
+	// 上海 17:00 = UTC 09:00
+	if err := at.checkDailyFlattenWindow(time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("收盘后应拒绝开仓")
+	}
+
+	at.config.DailyFlattenTime = ""
+	if err := at.checkDailyFlattenWindow(time.Date(2024, 7, 1, 9, 0, 0, 0, time.UTC)); err != nil {
+		t.Errorf("未配置时不应限制开仓: %v", err)
+	}
+}
@@ -0,0 +1,57 @@
+package trader
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+)
+
+// buildInputSnapshot 记录本周期决策的输入（各币种行情指标和数据哈希）及决策来源的原始输出
+func buildInputSnapshot(ctx *decision.Context, provider string, full *decision.FullDecision, err error) *logger.InputSnapshot {
+	snapshot := &logger.InputSnapshot{Provider: provider}
+	if full != nil {
+		snapshot.RawOutput = full.RawResponse
+	}
+	if err != nil {
+		snapshot.ParseError = err.Error()
+	}
+	if len(ctx.MarketDataMap) > 0 {
+		snapshot.Market = make(map[string]logger.MarketInputs, len(ctx.MarketDataMap))
+		for symbol, data := range ctx.MarketDataMap {
+			if data != nil {
+				snapshot.Market[symbol] = marketInputs(data)
+			}
+		}
+	}
+	return snapshot
+}
+
+// marketInputs 提取单个币种的关键指标，并对完整的K线序列和指标计算哈希
+func marketInputs(data *market.Data) logger.MarketInputs {
+	inputs := logger.MarketInputs{
+		Price:         data.CurrentPrice,
+		PriceChange1h: data.PriceChange1h,
+		PriceChange4h: data.PriceChange4h,
+		EMA20:         data.CurrentEMA20,
+		MACD:          data.CurrentMACD,
+		RSI7:          data.CurrentRSI7,
+		FundingRate:   data.FundingRate,
+	}
+	if data.IntradaySeries != nil {
+		inputs.ATR14 = data.IntradaySeries.ATR14
+	}
+	if data.LongerTermContext != nil {
+		inputs.EMA50On4h = data.LongerTermContext.EMA50
+	}
+	if data.OpenInterest != nil {
+		inputs.OpenInterest = data.OpenInterest.Latest
+	}
+	series, _ := json.Marshal([]interface{}{data.IntradaySeries, data.MidTermSeries15m, data.MidTermSeries1h, data.LongerTermContext, data.DailyContext})
+	sum := sha256.Sum256(series)
+	inputs.DataHash = hex.EncodeToString(sum[:])
+	return inputs
+}
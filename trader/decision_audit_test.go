@@ -0,0 +1,39 @@
+package trader
+
+import (
+	"errors"
+	"nofx/decision"
+	"nofx/market"
+	"testing"
+)
+
+func TestBuildInputSnapshot(t *testing.T) {
+	data := &market.Data{
+		Symbol:            "BTCUSDT",
+		CurrentPrice:      50000,
+		CurrentRSI7:       61.5,
+		IntradaySeries:    &market.IntradayData{MidPrices: []float64{49900, 50000}, ATR14: 120},
+		LongerTermContext: &market.LongerTermData{EMA50: 48000},
+		OpenInterest:      &market.OIData{Latest: 1e6},
+	}
+	ctx := &decision.Context{MarketDataMap: map[string]*market.Data{"BTCUSDT": data}}
+	full := &decision.FullDecision{RawResponse: "raw model output"}
+
+	snapshot := buildInputSnapshot(ctx, "llm", full, errors.New("parse failed"))
+	if snapshot.Provider != "llm" || snapshot.RawOutput != "raw model output" || snapshot.ParseError != "parse failed" {
+		t.Errorf("快照字段错误: %+v", snapshot)
+	}
+	m := snapshot.Market["BTCUSDT"]
+	if m.Price != 50000 || m.RSI7 != 61.5 || m.ATR14 != 120 || m.EMA50On4h != 48000 || m.OpenInterest != 1e6 || len(m.DataHash) != 64 {
+		t.Errorf("行情指标错误: %+v", m)
+	}
+
+	// 相同数据哈希一致，K线变化后哈希改变
+	if again := marketInputs(data); again.DataHash != m.DataHash {
+		t.Error("相同输入的哈希应一致")
+	}
+	data.IntradaySeries.MidPrices[1] = 50001
+	if changed := marketInputs(data); changed.DataHash == m.DataHash {
+		t.Error("K线变化后哈希应改变")
+	}
+}
@@ -0,0 +1,106 @@
+package trader
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"nofx/decision"
+	"nofx/strategy"
+)
+
+// DecisionProviders 决策来源配置：单个来源时替代 AI 决策，多个来源时投票，只执行达到票数的一致决策
+type DecisionProviders struct {
+	Names        []string // "llm" / "webhook" / "strategy:<名称>"（如 strategy:sma_cross）
+	Quorum       int      // 投票所需票数（默认过半数）
+	WebhookURL   string   // webhook 来源的地址
+	WebhookToken string   // 可选：webhook 请求的 Bearer token
+}
+
+// DecisionProvidersFromEnv 读取 NOFX_DECISION_PROVIDERS / NOFX_DECISION_QUORUM / NOFX_DECISION_WEBHOOK_URL / NOFX_DECISION_WEBHOOK_TOKEN
+// 未配置或只有 llm 时返回 nil（由 AI 决策）
+func DecisionProvidersFromEnv() (*DecisionProviders, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_DECISION_PROVIDERS"))
+	if raw == "" {
+		return nil, nil
+	}
+	cfg := &DecisionProviders{
+		WebhookURL:   strings.TrimSpace(os.Getenv("NOFX_DECISION_WEBHOOK_URL")),
+		WebhookToken: strings.TrimSpace(os.Getenv("NOFX_DECISION_WEBHOOK_TOKEN")),
+	}
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		switch {
+		case name == "llm":
+		case name == "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("NOFX_DECISION_PROVIDERS 包含 webhook，但未配置 NOFX_DECISION_WEBHOOK_URL")
+			}
+		case strings.HasPrefix(name, "strategy:"):
+			if _, err := strategy.New(strings.TrimPrefix(name, "strategy:"), nil); err != nil {
+				return nil, fmt.Errorf("NOFX_DECISION_PROVIDERS 策略 %q 无效: %w", name, err)
+			}
+		default:
+			return nil, fmt.Errorf("NOFX_DECISION_PROVIDERS 未知的决策来源 %q（支持 llm、webhook、strategy:<名称>）", name)
+		}
+		cfg.Names = append(cfg.Names, name)
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_DECISION_QUORUM")); v != "" {
+		quorum, err := strconv.Atoi(v)
+		if err != nil || quorum <= 0 || quorum > len(cfg.Names) {
+			return nil, fmt.Errorf("NOFX_DECISION_QUORUM=%q 应为 1 到 %d 之间的整数", v, len(cfg.Names))
+		}
+		cfg.Quorum = quorum
+	}
+	if len(cfg.Names) == 0 || (len(cfg.Names) == 1 && cfg.Names[0] == "llm") {
+		return nil, nil
+	}
+	return cfg, nil
+}
+
+// llmProvider AI 决策来源（每次调用时读取当前的客户端和提示词，运行中修改提示词或 API Key 立即生效）
+type llmProvider struct {
+	at *AutoTrader
+}
+
+func (p llmProvider) Name() string { return "llm" }
+
+func (p llmProvider) Decide(ctx *decision.Context) (*decision.FullDecision, error) {
+	at := p.at
+	return decision.GetFullDecisionWithCustomPrompt(ctx, at.mcpClient, at.customPrompt, at.overrideBasePrompt, at.systemPromptTemplate)
+}
+
+// buildDecisionProvider 按配置创建决策来源（未配置时为 AI 决策）
+func (at *AutoTrader) buildDecisionProvider() (decision.DecisionProvider, error) {
+	cfg := at.config.DecisionProviders
+	if cfg == nil || len(cfg.Names) == 0 {
+		return llmProvider{at: at}, nil
+	}
+	providers := make([]decision.DecisionProvider, 0, len(cfg.Names))
+	for _, name := range cfg.Names {
+		switch {
+		case name == "llm":
+			providers = append(providers, llmProvider{at: at})
+		case name == "webhook":
+			providers = append(providers, &decision.WebhookProvider{URL: cfg.WebhookURL, Token: cfg.WebhookToken})
+		case strings.HasPrefix(name, "strategy:"):
+			s, err := strategy.New(strings.TrimPrefix(name, "strategy:"), nil)
+			if err != nil {
+				return nil, fmt.Errorf("创建决策来源 %s 失败: %w", name, err)
+			}
+			providers = append(providers, &strategy.Provider{Strategies: []strategy.Strategy{s}})
+		default:
+			return nil, fmt.Errorf("未知的决策来源 %q", name)
+		}
+	}
+	if len(providers) == 1 {
+		return providers[0], nil
+	}
+	return &decision.Ensemble{Providers: providers, Quorum: cfg.Quorum}, nil
+}
@@ -0,0 +1,56 @@
+package trader
+
+import (
+	"nofx/decision"
+	"testing"
+)
+
+func TestDecisionProvidersFromEnv(t *testing.T) {
+	if p, err := DecisionProvidersFromEnv(); err != nil || p != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", p, err)
+	}
+	t.Setenv("NOFX_DECISION_PROVIDERS", "llm")
+	if p, err := DecisionProvidersFromEnv(); err != nil || p != nil {
+		t.Fatalf("只有 llm 时应返回 nil: %v, %v", p, err)
+	}
+	t.Setenv("NOFX_DECISION_PROVIDERS", "llm, webhook")
+	if _, err := DecisionProvidersFromEnv(); err == nil {
+		t.Error("webhook 缺少地址应返回错误")
+	}
+	t.Setenv("NOFX_DECISION_WEBHOOK_URL", "http://localhost:9000/decide")
+	t.Setenv("NOFX_DECISION_PROVIDERS", "llm,webhook,strategy:sma_cross,llm")
+	t.Setenv("NOFX_DECISION_QUORUM", "3")
+	p, err := DecisionProvidersFromEnv()
+	if err != nil || len(p.Names) != 3 || p.Quorum != 3 || p.WebhookURL == "" {
+		t.Fatalf("解析错误: %+v, %v", p, err)
+	}
+	t.Setenv("NOFX_DECISION_QUORUM", "4")
+	if _, err := DecisionProvidersFromEnv(); err == nil {
+		t.Error("票数超过来源数量应返回错误")
+	}
+	t.Setenv("NOFX_DECISION_QUORUM", "")
+	t.Setenv("NOFX_DECISION_PROVIDERS", "strategy:unknown")
+	if _, err := DecisionProvidersFromEnv(); err == nil {
+		t.Error("未注册的策略应返回错误")
+	}
+}
+
+func TestBuildDecisionProvider(t *testing.T) {
+	at := &AutoTrader{}
+	if p, err := at.buildDecisionProvider(); err != nil || p.Name() != "llm" {
+		t.Fatalf("默认应为 AI 决策: %v, %v", p, err)
+	}
+	at.config.DecisionProviders = &DecisionProviders{Names: []string{"strategy:sma_cross"}}
+	if p, err := at.buildDecisionProvider(); err != nil || p.Name() != "strategy:sma_cross" {
+		t.Fatalf("单个来源应直接使用: %v, %v", p, err)
+	}
+	at.config.DecisionProviders = &DecisionProviders{Names: []string{"llm", "webhook"}, Quorum: 2, WebhookURL: "http://localhost"}
+	p, err := at.buildDecisionProvider()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ensemble, ok := p.(*decision.Ensemble)
+	if !ok || len(ensemble.Providers) != 2 || ensemble.Quorum != 2 {
+		t.Errorf("多个来源应组成投票: %#v", p)
+	}
+}
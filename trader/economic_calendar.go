@@ -0,0 +1,312 @@
+package trader
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 经济事件影响等级
+const (
+	ImpactLow    = "low"
+	ImpactMedium = "medium"
+	ImpactHigh   = "high"
+)
+
+// 经济日历数据源默认参数
+const (
+	defaultCalendarRefresh = 6 * time.Hour
+	calendarRetryInterval  = 10 * time.Minute // 拉取失败后的重试间隔
+	calendarFetchTimeout   = 15 * time.Second
+)
+
+// impactRank 影响等级排序（未标注等级的事件视为高影响）
+var impactRank = map[string]int{ImpactLow: 1, ImpactMedium: 2, ImpactHigh: 3, "": 3}
+
+// nonMarketImpacts 不影响行情的事件类型（节假日、非经济数据），解析时跳过
+var nonMarketImpacts = map[string]bool{"holiday": true, "non-economic": true}
+
+// ParseImpact 归一化影响等级（High / MEDIUM / low），无法识别时返回错误
+func ParseImpact(raw string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(raw)); v {
+	case ImpactLow, ImpactMedium, ImpactHigh:
+		return v, nil
+	case "med", "moderate":
+		return ImpactMedium, nil
+	}
+	return "", fmt.Errorf("未知的影响等级 %q（支持 low、medium、high）", raw)
+}
+
+// CalendarFilter 事件过滤条件
+type CalendarFilter struct {
+	MinImpact  string   // 最低影响等级（空=high）
+	Currencies []string // 只保留这些货币/国家的事件（空=全部；未标注货币的事件总是保留）
+}
+
+// Apply 过滤事件（保持原顺序）
+func (f CalendarFilter) Apply(events []BlackoutEvent) []BlackoutEvent {
+	minImpact := f.MinImpact
+	if minImpact == "" {
+		minImpact = ImpactHigh
+	}
+	currencies := make(map[string]bool, len(f.Currencies))
+	for _, c := range f.Currencies {
+		currencies[strings.ToUpper(c)] = true
+	}
+	out := make([]BlackoutEvent, 0, len(events))
+	for _, e := range events {
+		if impactRank[e.Impact] < impactRank[minImpact] {
+			continue
+		}
+		if len(currencies) > 0 && e.Currency != "" && !currencies[strings.ToUpper(e.Currency)] {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// ParseCalendar 解析事件日历：ICS（BEGIN:VCALENDAR）或 JSON 数组。
+// JSON 支持本项目格式 {"name","time","before","after","impact","currency"}
+// 和 ForexFactory 格式 {"title","country","date","impact"}；source 用于错误信息
+func ParseCalendar(data []byte, source string) ([]BlackoutEvent, error) {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")))
+	var events []BlackoutEvent
+	var err error
+	if bytes.HasPrefix(trimmed, []byte("BEGIN:VCALENDAR")) {
+		events, err = parseICSCalendar(string(trimmed))
+	} else {
+		events, err = parseJSONCalendar(trimmed)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析事件日历 %s 失败: %w", source, err)
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].Time.Before(events[j].Time) })
+	return events, nil
+}
+
+// parseJSONCalendar 解析 JSON 事件日历
+func parseJSONCalendar(data []byte) ([]BlackoutEvent, error) {
+	var items []blackoutEventFile
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, err
+	}
+	events := make([]BlackoutEvent, 0, len(items))
+	for i, item := range items {
+		name, at, currency := item.Name, item.Time, item.Currency
+		if name == "" {
+			name = item.Title
+		}
+		if at.IsZero() {
+			at = item.Date
+		}
+		if currency == "" {
+			currency = item.Country
+		}
+		if name == "" || at.IsZero() {
+			return nil, fmt.Errorf("事件日历第 %d 项缺少 name 或 time", i+1)
+		}
+		event := BlackoutEvent{Name: name, Time: at, Currency: strings.ToUpper(currency)}
+		if item.Impact != "" {
+			impact, err := ParseImpact(item.Impact)
+			if nonMarketImpacts[strings.ToLower(item.Impact)] {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("事件日历第 %d 项（%s）: %w", i+1, name, err)
+			}
+			event.Impact = impact
+		}
+		for _, d := range []struct {
+			raw string
+			dst *time.Duration
+		}{{item.Before, &event.Before}, {item.After, &event.After}} {
+			if d.raw == "" {
+				continue
+			}
+			var err error
+			if *d.dst, err = time.ParseDuration(d.raw); err != nil || *d.dst < 0 {
+				return nil, fmt.Errorf("事件日历第 %d 项（%s）时长 %q 无效", i+1, name, d.raw)
+			}
+		}
+		events = append(events, event)
+	}
+	return events, nil
+}
+
+// parseICSCalendar 解析 ICS 日历中的 VEVENT（SUMMARY、DTSTART，影响等级取 X-IMPACT 或 CATEGORIES，货币取 X-CURRENCY）
+func parseICSCalendar(data string) ([]BlackoutEvent, error) {
+	// 折行：以空格或制表符开头的行是上一行的延续
+	data = strings.ReplaceAll(data, "\r\n", "\n")
+	data = strings.NewReplacer("\n ", "", "\n\t", "").Replace(data)
+
+	var events []BlackoutEvent
+	var current *BlackoutEvent
+	skip := false
+	for _, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		switch line {
+		case "BEGIN:VEVENT":
+			current, skip = &BlackoutEvent{}, false
+			continue
+		case "END:VEVENT":
+			if current == nil {
+				continue
+			}
+			if current.Name == "" || current.Time.IsZero() {
+				return nil, fmt.Errorf("第 %d 个事件缺少 SUMMARY 或 DTSTART", len(events)+1)
+			}
+			if !skip {
+				events = append(events, *current)
+			}
+			current = nil
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		name, params, _ := strings.Cut(key, ";")
+		switch strings.ToUpper(name) {
+		case "SUMMARY":
+			current.Name = icsUnescape(value)
+		case "DTSTART":
+			t, err := parseICSTime(value, params)
+			if err != nil {
+				return nil, fmt.Errorf("事件 %q: %w", current.Name, err)
+			}
+			current.Time = t
+		case "X-IMPACT", "CATEGORIES":
+			for _, v := range strings.Split(value, ",") {
+				if impact, err := ParseImpact(v); err == nil {
+					current.Impact = impact
+				} else if nonMarketImpacts[strings.ToLower(strings.TrimSpace(v))] {
+					skip = true
+				}
+			}
+		case "X-CURRENCY":
+			current.Currency = strings.ToUpper(strings.TrimSpace(value))
+		}
+	}
+	return events, nil
+}
+
+// parseICSTime 解析 DTSTART（UTC 20261112T133000Z、带 TZID 的本地时间或 VALUE=DATE 的全天事件）
+func parseICSTime(value, params string) (time.Time, error) {
+	location := time.UTC
+	for _, p := range strings.Split(params, ";") {
+		if k, v, ok := strings.Cut(p, "="); ok && strings.EqualFold(k, "TZID") {
+			loc, err := time.LoadLocation(strings.Trim(v, `"`))
+			if err != nil {
+				return time.Time{}, fmt.Errorf("无效的时区 %s", v)
+			}
+			location = loc
+		}
+	}
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102T1504", "20060102"} {
+		if t, err := time.ParseInLocation(layout, value, location); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("无效的时间 %q", value)
+}
+
+// icsUnescape 还原 ICS 文本中的转义字符
+func icsUnescape(s string) string {
+	return strings.NewReplacer(`\n`, " ", `\N`, " ", `\,`, ",", `\;`, ";", `\\`, `\`).Replace(strings.TrimSpace(s))
+}
+
+// CalendarFeed 定时拉取的经济日历（如 ForexFactory 的 ff_calendar_thisweek.json，或导出的 ICS 链接）
+type CalendarFeed struct {
+	URL     string
+	Refresh time.Duration // 刷新间隔（默认 6h）
+	Filter  CalendarFilter
+
+	client  *http.Client
+	mu      sync.RWMutex
+	events  []BlackoutEvent
+	nextTry time.Time // 下次拉取时间（零值=立即）
+}
+
+// NewCalendarFeed 创建经济日历数据源
+func NewCalendarFeed(url string, refresh time.Duration, filter CalendarFilter) *CalendarFeed {
+	if refresh <= 0 {
+		refresh = defaultCalendarRefresh
+	}
+	return &CalendarFeed{URL: url, Refresh: refresh, Filter: filter, client: &http.Client{Timeout: calendarFetchTimeout}}
+}
+
+// Events 最近一次拉取成功的事件（按时间排序）
+func (f *CalendarFeed) Events() []BlackoutEvent {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.events
+}
+
+// Fetch 拉取并解析日历（失败时保留上次的事件）
+func (f *CalendarFeed) Fetch(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, f.URL, nil)
+	if err != nil {
+		return fmt.Errorf("创建经济日历请求失败: %w", err)
+	}
+	client := f.client
+	if client == nil {
+		client = &http.Client{Timeout: calendarFetchTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求经济日历失败: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取经济日历失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("经济日历返回错误 (status %d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	events, err := ParseCalendar(body, f.URL)
+	if err != nil {
+		return err
+	}
+	events = f.Filter.Apply(events)
+
+	f.mu.Lock()
+	f.events = events
+	f.mu.Unlock()
+	return nil
+}
+
+// refreshIfDue 到达刷新时间时拉取日历（失败后 calendarRetryInterval 重试），返回是否执行了拉取
+func (f *CalendarFeed) refreshIfDue(ctx context.Context, now time.Time) (bool, error) {
+	f.mu.Lock()
+	if now.Before(f.nextTry) {
+		f.mu.Unlock()
+		return false, nil
+	}
+	f.nextTry = now.Add(calendarRetryInterval)
+	f.mu.Unlock()
+
+	if err := f.Fetch(ctx); err != nil {
+		return true, err
+	}
+	refresh := f.Refresh
+	if refresh <= 0 {
+		refresh = defaultCalendarRefresh
+	}
+	f.mu.Lock()
+	f.nextTry = now.Add(refresh)
+	f.mu.Unlock()
+	return true, nil
+}
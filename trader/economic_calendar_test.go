@@ -0,0 +1,205 @@
+package trader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"nofx/decision"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// ffCalendar ForexFactory 格式的经济日历
+const ffCalendar = `[
+	{"title":"CPI m/m","country":"USD","date":"2026-11-12T08:30:00-05:00","impact":"High","forecast":"0.2%","previous":"0.3%"},
+	{"title":"Retail Sales m/m","country":"USD","date":"2026-11-14T08:30:00-05:00","impact":"Medium"},
+	{"title":"Bank Holiday","country":"JPY","date":"2026-11-03T00:00:00-05:00","impact":"Holiday"},
+	{"title":"ECB Press Conference","country":"EUR","date":"2026-11-05T08:45:00-05:00","impact":"High"}
+]`
+
+func TestParseCalendarForexFactory(t *testing.T) {
+	events, err := ParseCalendar([]byte(ffCalendar), "ff.json")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("节假日应被跳过: %+v", events)
+	}
+	if events[0].Name != "ECB Press Conference" || events[0].Currency != "EUR" || events[0].Impact != ImpactHigh {
+		t.Errorf("事件应按时间排序并解析货币和等级: %+v", events[0])
+	}
+	if want := time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC); !events[1].Time.Equal(want) {
+		t.Errorf("CPI 时间 = %v, want %v", events[1].Time, want)
+	}
+
+	high := CalendarFilter{}.Apply(events)
+	if len(high) != 2 {
+		t.Errorf("默认只保留高影响事件: %+v", high)
+	}
+	usd := CalendarFilter{MinImpact: ImpactMedium, Currencies: []string{"usd"}}.Apply(events)
+	if len(usd) != 2 || usd[0].Name != "CPI m/m" || usd[1].Name != "Retail Sales m/m" {
+		t.Errorf("按货币和最低等级过滤错误: %+v", usd)
+	}
+
+	if _, err := ParseCalendar([]byte(`[{"name":"CPI","time":"2026-11-12T13:30:00Z","impact":"huge"}]`), "bad.json"); err == nil {
+		t.Error("未知的影响等级应返回错误")
+	}
+}
+
+func TestParseCalendarICS(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"BEGIN:VEVENT",
+		"SUMMARY:FOMC Rate Decision\\, Statement",
+		"DTSTART:20261209T190000Z",
+		"X-IMPACT:High",
+		"X-CURRENCY:usd",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Non-Farm",
+		"  Payrolls",
+		"DTSTART;TZID=America/New_York:20261204T083000",
+		"CATEGORIES:Economic,Medium",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"SUMMARY:Thanksgiving",
+		"DTSTART;VALUE=DATE:20261126",
+		"CATEGORIES:Holiday",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	events, err := ParseCalendar([]byte(ics), "calendar.ics")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("应解析 2 个事件（跳过节假日）: %+v", events)
+	}
+	nfp, fomc := events[0], events[1]
+	if nfp.Name != "Non-Farm Payrolls" || nfp.Impact != ImpactMedium || !nfp.Time.Equal(time.Date(2026, 12, 4, 13, 30, 0, 0, time.UTC)) {
+		t.Errorf("NFP 解析错误（折行、TZID、CATEGORIES）: %+v", nfp)
+	}
+	if fomc.Name != "FOMC Rate Decision, Statement" || fomc.Impact != ImpactHigh || fomc.Currency != "USD" {
+		t.Errorf("FOMC 解析错误: %+v", fomc)
+	}
+
+	if _, err := ParseCalendar([]byte("BEGIN:VCALENDAR\nBEGIN:VEVENT\nSUMMARY:CPI\nEND:VEVENT\nEND:VCALENDAR"), "bad.ics"); err == nil {
+		t.Error("缺少 DTSTART 的事件应返回错误")
+	}
+}
+
+func TestCalendarFeedRefresh(t *testing.T) {
+	var requests atomic.Int32
+	fail := atomic.Bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if fail.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(ffCalendar))
+	}))
+	defer server.Close()
+
+	feed := NewCalendarFeed(server.URL, time.Hour, CalendarFilter{Currencies: []string{"USD"}})
+	now := time.Date(2026, 11, 10, 0, 0, 0, 0, time.UTC)
+	if fetched, err := feed.refreshIfDue(context.Background(), now); !fetched || err != nil {
+		t.Fatalf("首次应立即拉取: fetched=%v err=%v", fetched, err)
+	}
+	if events := feed.Events(); len(events) != 1 || events[0].Name != "CPI m/m" {
+		t.Fatalf("应只保留美元高影响事件: %+v", events)
+	}
+	if fetched, _ := feed.refreshIfDue(context.Background(), now.Add(30*time.Minute)); fetched {
+		t.Error("未到刷新间隔不应拉取")
+	}
+
+	fail.Store(true)
+	if _, err := feed.refreshIfDue(context.Background(), now.Add(time.Hour)); err == nil {
+		t.Error("拉取失败应返回错误")
+	}
+	if len(feed.Events()) != 1 {
+		t.Error("拉取失败时应保留上次的事件")
+	}
+	if fetched, _ := feed.refreshIfDue(context.Background(), now.Add(time.Hour+5*time.Minute)); fetched {
+		t.Error("失败后应等待重试间隔")
+	}
+	if got := requests.Load(); got != 2 {
+		t.Errorf("请求次数 = %d, want 2", got)
+	}
+}
+
+func TestTradingScheduleReduceEntry(t *testing.T) {
+	cpi := time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC)
+	feed := &CalendarFeed{events: []BlackoutEvent{{Name: "CPI", Time: cpi, Impact: ImpactHigh, Currency: "USD"}}}
+	schedule := &TradingSchedule{Feed: feed, Window: 30 * time.Minute, Entry: BlackoutEntryReduce, SizePct: 25}
+	at := &AutoTrader{config: AutoTraderConfig{TradingSchedule: schedule}}
+
+	d := &decision.Decision{Symbol: "BTCUSDT", Action: "open_long", PositionSizeUSD: 400}
+	if err := at.checkTradingSchedule(d, cpi.Add(-10*time.Minute)); err != nil {
+		t.Fatalf("reduce 模式不应拒绝开仓: %v", err)
+	}
+	if d.PositionSizeUSD != 100 {
+		t.Errorf("开仓金额 = %.2f, want 100", d.PositionSizeUSD)
+	}
+	d.PositionSizeUSD = 400
+	if err := at.checkTradingSchedule(d, cpi.Add(time.Hour)); err != nil || d.PositionSizeUSD != 400 {
+		t.Errorf("窗口外不应调整: size=%.2f err=%v", d.PositionSizeUSD, err)
+	}
+
+	schedule.Entry = BlackoutEntryBlock
+	if err := at.checkTradingSchedule(d, cpi); err == nil {
+		t.Error("block 模式应拒绝窗口内开仓（数据源事件也生效）")
+	}
+}
+
+func TestCheckUpcomingEventsNotifiesOnce(t *testing.T) {
+	var alerts []Alert
+	cpi := time.Date(2026, 11, 12, 13, 30, 0, 0, time.UTC)
+	schedule := &TradingSchedule{
+		Events: []BlackoutEvent{{Name: "CPI", Time: cpi, Currency: "USD"}},
+		Window: 30 * time.Minute,
+		Notice: time.Hour,
+	}
+	at := &AutoTrader{name: "test", config: AutoTraderConfig{AlertHandler: func(a Alert) { alerts = append(alerts, a) }}}
+	noticed := make(map[string]bool)
+
+	at.checkUpcomingEvents(schedule, cpi.Add(-2*time.Hour), noticed) // 窗口 13:00 开始，提前 1 小时提醒
+	if len(alerts) != 0 {
+		t.Fatalf("提醒时间之前不应发送: %+v", alerts)
+	}
+	at.checkUpcomingEvents(schedule, cpi.Add(-80*time.Minute), noticed)
+	at.checkUpcomingEvents(schedule, cpi.Add(-70*time.Minute), noticed)
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Message, "USD CPI") {
+		t.Fatalf("应只提醒一次: %+v", alerts)
+	}
+	if upcoming := schedule.Upcoming(cpi.Add(-20*time.Minute), time.Hour); len(upcoming) != 0 {
+		t.Errorf("已进入窗口的事件不算即将到来: %+v", upcoming)
+	}
+}
+
+func TestTradingScheduleFromEnvCalendarFeed(t *testing.T) {
+	t.Setenv("NOFX_BLACKOUT_FEED_URL", "https://example.com/ff_calendar_thisweek.json")
+	t.Setenv("NOFX_BLACKOUT_MIN_IMPACT", "Medium")
+	t.Setenv("NOFX_BLACKOUT_CURRENCIES", "usd, eur")
+	t.Setenv("NOFX_BLACKOUT_ENTRY", "reduce")
+	t.Setenv("NOFX_BLACKOUT_SIZE_PCT", "30")
+	schedule, err := TradingScheduleFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if schedule.Feed == nil || schedule.Feed.Refresh != defaultCalendarRefresh || schedule.Feed.Filter.MinImpact != ImpactMedium ||
+		strings.Join(schedule.Feed.Filter.Currencies, ",") != "USD,EUR" {
+		t.Errorf("经济日历数据源配置错误: %+v", schedule.Feed)
+	}
+	if schedule.Entry != BlackoutEntryReduce || schedule.SizePct != 30 || schedule.Notice != defaultBlackoutNotice {
+		t.Errorf("配置解析错误: %+v", schedule)
+	}
+
+	t.Setenv("NOFX_BLACKOUT_ENTRY", "skip")
+	if _, err := TradingScheduleFromEnv(); err == nil {
+		t.Error("未知的 NOFX_BLACKOUT_ENTRY 应返回错误")
+	}
+}
@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/store"
+	"os"
+	"strings"
+	"time"
+)
+
+// EquitySnapshotIntervalFromEnv 读取 NOFX_EQUITY_SNAPSHOT_INTERVAL（净值快照间隔，未设置返回 0=不记录快照）
+func EquitySnapshotIntervalFromEnv() (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_EQUITY_SNAPSHOT_INTERVAL"))
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("NOFX_EQUITY_SNAPSHOT_INTERVAL 必须为正的时间间隔（如 15m）: %q", raw)
+	}
+	return d, nil
+}
+
+// startEquitySnapshotMonitor 按 EquitySnapshotInterval 定时将账户净值写入事件日志（启动时先记录一次）
+func (at *AutoTrader) startEquitySnapshotMonitor() {
+	interval := at.config.EquitySnapshotInterval
+	if interval <= 0 || at.journal == nil {
+		return
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Info("📈 启动净值快照", "trader", at.name, "interval", interval)
+		at.recordEquitySnapshot()
+
+		for {
+			select {
+			case <-ticker.C:
+				at.recordEquitySnapshot()
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止净值快照", "trader", at.name)
+				return
+			}
+		}
+	}()
+}
+
+// recordEquitySnapshot 查询余额和持仓并写入一次净值快照
+func (at *AutoTrader) recordEquitySnapshot() {
+	balance, err := at.trader.GetBalance(at.ctx())
+	if err != nil {
+		log.Warn("⚠️ 净值快照：获取余额失败", "trader", at.name, "error", err)
+		return
+	}
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		log.Warn("⚠️ 净值快照：获取持仓失败", "trader", at.name, "error", err)
+		return
+	}
+
+	snapshot := store.EquitySnapshot{TraderID: at.id}
+	snapshot.WalletBalance, _ = balance["totalWalletBalance"].(float64)
+	snapshot.UnrealizedPnL, _ = balance["totalUnrealizedProfit"].(float64)
+	for _, pos := range positions {
+		if amt, ok := pos["positionAmt"].(float64); ok && amt != 0 {
+			snapshot.PositionCount++
+		}
+	}
+	if err := at.journal.RecordEquity(snapshot); err != nil {
+		log.Warn("⚠️ 净值快照失败", "trader", at.name, "error", err)
+	}
+}
+
+// GetEquitySnapshots 查询事件日志中的净值快照（按时间正序；limit>0 时只返回最近的 limit 条；未启用事件日志时返回错误）
+func (at *AutoTrader) GetEquitySnapshots(since, until time.Time, limit int) ([]store.EquitySnapshot, error) {
+	if at.journal == nil {
+		return nil, fmt.Errorf("交易事件日志未启用（设置 NOFX_JOURNAL_DB）")
+	}
+	return at.journal.EquitySnapshots(store.EquityFilter{TraderID: at.id, Since: since, Until: until, Limit: limit})
+}
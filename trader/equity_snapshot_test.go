@@ -0,0 +1,58 @@
+package trader
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/store"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRecordEquitySnapshot 快照记录钱包余额、未实现盈亏和非空持仓数
+func TestRecordEquitySnapshot(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	require.NoError(t, err)
+	defer journal.Close()
+
+	at := &AutoTrader{id: "t1", name: "test", journal: journal, trader: &MockTrader{
+		balance: map[string]interface{}{"totalWalletBalance": 1200.0, "totalUnrealizedProfit": -35.5},
+		positions: []map[string]interface{}{
+			{"symbol": "BTCUSDT", "positionAmt": 0.1},
+			{"symbol": "ETHUSDT", "positionAmt": -2.0},
+			{"symbol": "SOLUSDT", "positionAmt": 0.0},
+		},
+	}}
+	at.recordEquitySnapshot()
+	at.trader = &MockTrader{shouldFailPositions: true}
+	at.recordEquitySnapshot() // 查询失败时不写入
+
+	snapshots, err := at.GetEquitySnapshots(time.Time{}, time.Time{}, 0)
+	require.NoError(t, err)
+	require.Len(t, snapshots, 1)
+	assert.Equal(t, "t1", snapshots[0].TraderID)
+	assert.Equal(t, 1200.0, snapshots[0].WalletBalance)
+	assert.Equal(t, -35.5, snapshots[0].UnrealizedPnL)
+	assert.Equal(t, 2, snapshots[0].PositionCount)
+
+	_, err = (&AutoTrader{}).GetEquitySnapshots(time.Time{}, time.Time{}, 0)
+	assert.Error(t, err)
+}
+
+func TestEquitySnapshotIntervalFromEnv(t *testing.T) {
+	t.Setenv("NOFX_EQUITY_SNAPSHOT_INTERVAL", "")
+	d, err := EquitySnapshotIntervalFromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	t.Setenv("NOFX_EQUITY_SNAPSHOT_INTERVAL", "15m")
+	d, err = EquitySnapshotIntervalFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 15*time.Minute, d)
+
+	t.Setenv("NOFX_EQUITY_SNAPSHOT_INTERVAL", "-1m")
+	_, err = EquitySnapshotIntervalFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,260 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"nofx/decision"
+	"nofx/market"
+	"strconv"
+	"strings"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+// 交易所错误分类：各交易所的错误码统一映射为以下错误，调用方用 errors.Is 判断语义，
+// 不再依赖错误信息字符串匹配
+var (
+	ErrInsufficientMargin = errors.New("保证金不足")
+	ErrRateLimited        = errors.New("请求频率超限")
+	ErrMinNotional        = errors.New("订单金额低于最小名义价值")
+	ErrInvalidPrecision   = errors.New("数量或价格精度不符合要求")
+	ErrOrderNotFound      = errors.New("订单不存在或已成交/已取消")
+	ErrReduceOnlyRejected = errors.New("只减仓订单被拒绝（没有可平的持仓）")
+	ErrLeverageCooldown   = errors.New("杠杆调整过于频繁，请稍后重试")
+	ErrNoChange           = errors.New("无需修改（已是目标设置）")
+	ErrMarginModeLocked   = errors.New("存在持仓或挂单，无法修改仓位模式")
+	ErrMultiAssetsMode    = errors.New("多资产模式下只能使用全仓")
+	ErrUnifiedAccount     = errors.New("统一账户 API 不支持合约交易")
+	ErrInvalidAPIKey      = errors.New("API Key 无效或权限不足")
+	ErrTimestamp          = errors.New("请求时间戳超出服务器接收窗口")
+)
+
+// ExchangeError 交易所返回的错误（保留原始错误信息，并通过 errors.Is 暴露分类）
+type ExchangeError struct {
+	Exchange string
+	Code     string // 交易所错误码（没有错误码时为空）
+	Message  string // 交易所错误信息
+	Kind     error  // 分类（上面的 Err* 之一，无法识别时为 nil）
+	Err      error  // 原始错误
+}
+
+func (e *ExchangeError) Error() string { return e.Err.Error() }
+
+// Unwrap 同时暴露分类和原始错误（errors.Is/As 均可命中）
+func (e *ExchangeError) Unwrap() []error {
+	if e.Kind == nil {
+		return []error{e.Err}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+// binanceCodes 币安合约错误码（Aster 使用相同的错误码）
+var binanceCodes = map[int64]error{
+	-1003: ErrRateLimited,        // TOO_MANY_REQUESTS
+	-1015: ErrRateLimited,        // TOO_MANY_ORDERS
+	-1021: ErrTimestamp,          // INVALID_TIMESTAMP
+	-1111: ErrInvalidPrecision,   // BAD_PRECISION
+	-2011: ErrOrderNotFound,      // CANCEL_REJECTED（Unknown order sent）
+	-2013: ErrOrderNotFound,      // NO_SUCH_ORDER
+	-2014: ErrInvalidAPIKey,      // BAD_API_KEY_FMT
+	-2015: ErrInvalidAPIKey,      // REJECTED_MBX_KEY
+	-2018: ErrInsufficientMargin, // BALANCE_NOT_SUFFICIENT
+	-2019: ErrInsufficientMargin, // MARGIN_NOT_SUFFICIEN
+	-2022: ErrReduceOnlyRejected, // REDUCE_ONLY_REJECT
+	-4046: ErrNoChange,           // NO_NEED_TO_CHANGE_MARGIN_TYPE
+	-4048: ErrMarginModeLocked,   // MARGIN_TYPE_CANNOT_BE_CHANGED（存在持仓或挂单）
+	-4059: ErrNoChange,           // NO_NEED_TO_CHANGE_POSITION_SIDE
+	-4164: ErrMinNotional,        // MIN_NOTIONAL
+	-4168: ErrMultiAssetsMode,    // 多资产模式不支持逐仓
+}
+
+// okxCodes OKX 错误码（sCode）
+var okxCodes = map[string]error{
+	"50011": ErrRateLimited,        // Rate limit reached
+	"50061": ErrRateLimited,        // Sub-account rate limit exceeded
+	"50102": ErrTimestamp,          // Timestamp request expired
+	"60004": ErrTimestamp,          // WebSocket login: Invalid timestamp
+	"60006": ErrTimestamp,          // WebSocket login: Timestamp request expired
+	"50111": ErrInvalidAPIKey,      // Invalid OK-ACCESS-KEY
+	"50113": ErrInvalidAPIKey,      // Invalid Sign
+	"51008": ErrInsufficientMargin, // Insufficient balance / margin
+	"51020": ErrMinNotional,        // Order amount below minimum
+	"51121": ErrInvalidPrecision,   // Quantity must be a multiple of lot size
+	"51400": ErrOrderNotFound,      // Cancellation failed: order does not exist / filled
+	"51603": ErrOrderNotFound,      // Order does not exist
+	"59000": ErrMarginModeLocked,   // Setting failed: close positions / cancel orders first
+}
+
+// messagePatterns 没有错误码（或错误码未收录）时按错误信息识别（小写匹配）
+var messagePatterns = []struct {
+	substr string
+	kind   error
+}{
+	{"no need to change", ErrNoChange},
+	{"margin type cannot be changed", ErrMarginModeLocked},
+	{"multi-assets mode", ErrMultiAssetsMode},
+	{"unified", ErrUnifiedAccount},
+	{"portfolio", ErrUnifiedAccount},
+	{"insufficient margin", ErrInsufficientMargin},
+	{"margin is insufficient", ErrInsufficientMargin},
+	{"insufficient balance", ErrInsufficientMargin},
+	{"minimum value", ErrMinNotional}, // Hyperliquid: Order must have minimum value of $10
+	{"notional must be no smaller", ErrMinNotional},
+	{"never placed, already canceled, or filled", ErrOrderNotFound}, // Hyperliquid 撤单
+	{"unknown order", ErrOrderNotFound},
+	{"order does not exist", ErrOrderNotFound},
+	{"reduceonly order is rejected", ErrReduceOnlyRejected},
+	{"too many requests", ErrRateLimited},
+	{"rate limit", ErrRateLimited},
+	{"cooldown", ErrLeverageCooldown},
+	{"too frequent", ErrLeverageCooldown},
+}
+
+// NewExchangeError 按交易所错误码创建分类错误（错误码未收录时按错误信息识别）
+func NewExchangeError(exchange, code, message string, err error) *ExchangeError {
+	e := &ExchangeError{Exchange: exchange, Code: code, Message: message, Err: err}
+	switch exchange {
+	case "okx":
+		e.Kind = okxCodes[code]
+	default:
+		if n, perr := strconv.ParseInt(code, 10, 64); perr == nil {
+			e.Kind = binanceCodes[n]
+		}
+	}
+	if e.Kind == nil {
+		e.Kind = kindFromMessage(message)
+	}
+	return e
+}
+
+// ClassifyError 将交易所返回的错误转换为 *ExchangeError（已分类或为 nil 时原样返回）
+func ClassifyError(exchange string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exErr *ExchangeError
+	if errors.As(err, &exErr) {
+		return err
+	}
+	var apiErr *common.APIError
+	if errors.As(err, &apiErr) {
+		return NewExchangeError(exchange, strconv.FormatInt(apiErr.Code, 10), apiErr.Message, err)
+	}
+	if kind := kindFromMessage(err.Error()); kind != nil {
+		return &ExchangeError{Exchange: exchange, Message: err.Error(), Kind: kind, Err: err}
+	}
+	return err
+}
+
+func kindFromMessage(message string) error {
+	lower := strings.ToLower(message)
+	for _, p := range messagePatterns {
+		if strings.Contains(lower, p.substr) {
+			return p.kind
+		}
+	}
+	return nil
+}
+
+// classifyingTrader 将底层交易器返回的错误统一分类的 Trader 装饰器
+type classifyingTrader struct {
+	Trader
+	exchange string
+	clock    *ServerClock // 返回时间戳错误时重新同步（nil=不同步）
+}
+
+// newClassifyingTrader 为 trader 包装错误分类
+func newClassifyingTrader(t Trader, exchange string) *classifyingTrader {
+	return &classifyingTrader{Trader: t, exchange: exchange}
+}
+
+func (t *classifyingTrader) classify(err error) error {
+	err = ClassifyError(t.exchange, err)
+	if t.clock != nil && errors.Is(err, ErrTimestamp) {
+		log.Info("⏱ 返回时间戳错误，重新同步服务器时间", "exchange", t.exchange, "error", err)
+		t.clock.Resync()
+	}
+	return err
+}
+
+func (t *classifyingTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	balance, err := t.Trader.GetBalance(ctx)
+	return balance, t.classify(err)
+}
+
+func (t *classifyingTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	positions, err := t.Trader.GetPositions(ctx)
+	return positions, t.classify(err)
+}
+
+func (t *classifyingTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	report, err := t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+	return report, t.classify(err)
+}
+
+func (t *classifyingTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	report, err := t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+	return report, t.classify(err)
+}
+
+func (t *classifyingTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	report, err := t.Trader.CloseLong(ctx, symbol, quantity)
+	return report, t.classify(err)
+}
+
+func (t *classifyingTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	report, err := t.Trader.CloseShort(ctx, symbol, quantity)
+	return report, t.classify(err)
+}
+
+func (t *classifyingTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return t.classify(t.Trader.SetLeverage(ctx, symbol, leverage))
+}
+
+func (t *classifyingTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
+	return t.classify(t.Trader.SetMarginMode(ctx, symbol, isCrossMargin))
+}
+
+func (t *classifyingTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
+	price, err := t.Trader.GetMarketPrice(ctx, symbol)
+	return price, t.classify(err)
+}
+
+func (t *classifyingTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	rate, err := t.Trader.GetFundingRate(ctx, symbol)
+	return rate, t.classify(err)
+}
+
+func (t *classifyingTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	rates, err := t.Trader.GetFundingRateHistory(ctx, symbol, n)
+	return rates, t.classify(err)
+}
+
+func (t *classifyingTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	return t.classify(t.Trader.SetStopLoss(ctx, symbol, positionSide, quantity, stopPrice))
+}
+
+func (t *classifyingTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.classify(t.Trader.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice))
+}
+
+func (t *classifyingTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
+	return t.classify(t.Trader.CancelStopLossOrders(ctx, symbol))
+}
+
+func (t *classifyingTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
+	return t.classify(t.Trader.CancelTakeProfitOrders(ctx, symbol))
+}
+
+func (t *classifyingTrader) CancelAllOrders(ctx context.Context, symbol string) error {
+	return t.classify(t.Trader.CancelAllOrders(ctx, symbol))
+}
+
+func (t *classifyingTrader) CancelStopOrders(ctx context.Context, symbol string) error {
+	return t.classify(t.Trader.CancelStopOrders(ctx, symbol))
+}
+
+func (t *classifyingTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
+	orders, err := t.Trader.GetOpenOrders(ctx, symbol)
+	return orders, t.classify(err)
+}
@@ -0,0 +1,110 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/common"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		exchange string
+		err      error
+		want     error
+	}{
+		{"binance 保证金不足", "binance", &common.APIError{Code: -2019, Message: "Margin is insufficient."}, ErrInsufficientMargin},
+		{"binance 限频", "binance", &common.APIError{Code: -1003, Message: "Too many requests"}, ErrRateLimited},
+		{"binance 最小名义价值", "binance", &common.APIError{Code: -4164, Message: "Order's notional must be no smaller than 5"}, ErrMinNotional},
+		{"binance 无需修改", "binance", &common.APIError{Code: -4046, Message: "No need to change margin type."}, ErrNoChange},
+		{"包装后的 APIError", "binance", fmt.Errorf("开多仓失败: %w", &common.APIError{Code: -2013, Message: "Order does not exist."}), ErrOrderNotFound},
+		{"未收录错误码按信息识别", "binance", &common.APIError{Code: -9999, Message: "leverage adjust too frequent"}, ErrLeverageCooldown},
+		{"Hyperliquid 按信息识别", "hyperliquid", errors.New("Order must have minimum value of $10"), ErrMinNotional},
+		{"Hyperliquid 撤单", "hyperliquid", errors.New("Order was never placed, already canceled, or filled."), ErrOrderNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ClassifyError(tt.exchange, tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("ClassifyError() = %v, want errors.Is %v", got, tt.want)
+			}
+			if got.Error() != tt.err.Error() {
+				t.Errorf("Error() = %q, want original %q", got.Error(), tt.err.Error())
+			}
+			var apiErr *common.APIError
+			if errors.As(tt.err, &apiErr) && !errors.As(got, &apiErr) {
+				t.Error("原始 APIError 应仍可通过 errors.As 获取")
+			}
+		})
+	}
+}
+
+func TestClassifyErrorPassThrough(t *testing.T) {
+	if ClassifyError("binance", nil) != nil {
+		t.Error("nil 应原样返回")
+	}
+	plain := errors.New("connection reset by peer")
+	if got := ClassifyError("binance", plain); got != plain {
+		t.Errorf("无法识别的错误应原样返回, got %v", got)
+	}
+	classified := ClassifyError("binance", &common.APIError{Code: -2019})
+	if got := ClassifyError("binance", fmt.Errorf("wrap: %w", classified)); !errors.Is(got, ErrInsufficientMargin) {
+		t.Errorf("已分类的错误不应被重新分类, got %v", got)
+	}
+}
+
+func TestNewExchangeErrorOKX(t *testing.T) {
+	err := NewExchangeError("okx", "51008", "Order failed. Insufficient USDT margin in account", errors.New("下单失败"))
+	if !errors.Is(err, ErrInsufficientMargin) {
+		t.Errorf("OKX 51008 应映射为 ErrInsufficientMargin")
+	}
+	// OKX 错误码不应按币安错误码表解析
+	if errors.Is(NewExchangeError("okx", "-2019", "", errors.New("x")), ErrInsufficientMargin) {
+		t.Error("OKX 不应使用币安错误码表")
+	}
+}
+
+func TestAsterHTTPError(t *testing.T) {
+	err := asterHTTPError(http.StatusBadRequest, []byte(`{"code":-2019,"msg":"Margin is insufficient."}`))
+	if !errors.Is(err, ErrInsufficientMargin) {
+		t.Errorf("Aster -2019 应映射为 ErrInsufficientMargin, got %v", err)
+	}
+	if want := `HTTP 400: {"code":-2019,"msg":"Margin is insufficient."}`; err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+	if err := asterHTTPError(http.StatusTooManyRequests, []byte("rate exceeded")); !errors.Is(err, ErrRateLimited) {
+		t.Errorf("HTTP 429 应映射为 ErrRateLimited, got %v", err)
+	}
+	if err := asterHTTPError(http.StatusBadGateway, []byte("bad gateway")); errors.Is(err, ErrRateLimited) {
+		t.Errorf("未知错误不应被分类, got %v", err)
+	}
+}
+
+// leverageErrTrader SetLeverage 返回指定错误的 MockTrader
+type leverageErrTrader struct {
+	*MockTrader
+	err error
+}
+
+func (t *leverageErrTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return t.err
+}
+
+func TestClassifyingTrader(t *testing.T) {
+	ct := newClassifyingTrader(&leverageErrTrader{
+		MockTrader: &MockTrader{},
+		err:        &common.APIError{Code: -2019, Message: "Margin is insufficient."},
+	}, "binance")
+	if err := ct.SetLeverage(context.Background(), "BTCUSDT", 5); !errors.Is(err, ErrInsufficientMargin) {
+		t.Errorf("SetLeverage() = %v, want ErrInsufficientMargin", err)
+	}
+
+	ct = newClassifyingTrader(&MockTrader{shouldFailOpenLong: true}, "binance")
+	if _, err := ct.OpenLong(context.Background(), "BTCUSDT", 1, 5); err == nil || err.Error() != "failed to open long" {
+		t.Errorf("无法识别的错误应原样返回, got %v", err)
+	}
+}
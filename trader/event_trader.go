@@ -0,0 +1,83 @@
+package trader
+
+import (
+	"context"
+	"nofx/eventbus"
+	"time"
+)
+
+// eventTrader 成交后向事件总线发布 OrderFilled / PositionOpened 的 Trader 装饰器
+type eventTrader struct {
+	Trader
+	exchange string
+	traderID string
+}
+
+// newEventTrader 为 trader 包装事件发布
+func newEventTrader(t Trader, exchange, traderID string) *eventTrader {
+	return &eventTrader{Trader: t, exchange: exchange, traderID: traderID}
+}
+
+// OpenLong 开多仓并发布成交事件
+func (t *eventTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	report, err := t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+	t.publish("open", symbol, "long", quantity, leverage, report, err)
+	return report, err
+}
+
+// OpenShort 开空仓并发布成交事件
+func (t *eventTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	report, err := t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+	t.publish("open", symbol, "short", quantity, leverage, report, err)
+	return report, err
+}
+
+// CloseLong 平多仓并发布成交事件
+func (t *eventTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	report, err := t.Trader.CloseLong(ctx, symbol, quantity)
+	t.publish("close", symbol, "long", quantity, 0, report, err)
+	return report, err
+}
+
+// CloseShort 平空仓并发布成交事件
+func (t *eventTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	report, err := t.Trader.CloseShort(ctx, symbol, quantity)
+	t.publish("close", symbol, "short", quantity, 0, report, err)
+	return report, err
+}
+
+// publish 下单成功后发布事件（与事件日志一致：执行报告没有成交数量时按请求数量记）
+func (t *eventTrader) publish(action, symbol, side string, quantity float64, leverage int, report *ExecutionReport, err error) {
+	if err != nil {
+		return
+	}
+	fill := eventbus.OrderFilled{
+		TraderID: t.traderID,
+		Exchange: t.exchange,
+		Symbol:   symbol,
+		Side:     side,
+		Action:   action,
+		Quantity: quantity,
+		Time:     time.Now(),
+	}
+	if report != nil {
+		fill.OrderID, fill.Price, fill.Fee = report.OrderID, report.AvgPrice, report.Fee
+		if report.IsFilled() {
+			fill.Quantity = report.FilledQty
+		}
+	}
+	eventbus.Publish(fill)
+
+	if action == "open" {
+		eventbus.Publish(eventbus.PositionOpened{
+			TraderID:   t.traderID,
+			Exchange:   t.exchange,
+			Symbol:     symbol,
+			Side:       side,
+			Quantity:   fill.Quantity,
+			EntryPrice: fill.Price,
+			Leverage:   leverage,
+			Time:       fill.Time,
+		})
+	}
+}
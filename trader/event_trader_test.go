@@ -0,0 +1,36 @@
+package trader
+
+import (
+	"context"
+	"testing"
+
+	"nofx/eventbus"
+)
+
+func TestEventTraderPublishesFills(t *testing.T) {
+	orig := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = orig }()
+	events, unsubscribe := eventbus.Subscribe(8)
+	defer unsubscribe()
+
+	et := newEventTrader(&MockTrader{shouldFailCloseShort: true}, "binance", "t1")
+	if _, err := et.OpenLong(context.Background(), "BTCUSDT", 0.2, 5); err != nil {
+		t.Fatalf("OpenLong: %v", err)
+	}
+	if _, err := et.CloseShort(context.Background(), "ETHUSDT", 0); err == nil {
+		t.Fatal("expected CloseShort to fail")
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2 (失败的下单不发布)", len(events))
+	}
+	fill, ok := (<-events).(eventbus.OrderFilled)
+	if !ok || fill.Action != "open" || fill.Side != "long" || fill.Quantity != 0.2 || fill.OrderID != 123456 || fill.TraderID != "t1" {
+		t.Errorf("unexpected OrderFilled: %+v", fill)
+	}
+	opened, ok := (<-events).(eventbus.PositionOpened)
+	if !ok || opened.Symbol != "BTCUSDT" || opened.Leverage != 5 || opened.Exchange != "binance" {
+		t.Errorf("unexpected PositionOpened: %+v", opened)
+	}
+}
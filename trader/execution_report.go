@@ -0,0 +1,116 @@
+package trader
+
+import (
+	"nofx/logger"
+	"strconv"
+	"time"
+)
+
+// 交易所标识（ExecutionReport.Venue）
+const (
+	VenueBinance     = "binance"
+	VenueHyperliquid = "hyperliquid"
+	VenueAster       = "aster"
+)
+
+// ExecutionReport 订单执行报告
+// 所有交易方法（开仓/平仓）统一返回该结构，字段尽量使用交易所返回的真实成交数据填充
+type ExecutionReport struct {
+	OrderID       int64  `json:"order_id"`        // 交易所订单ID（Hyperliquid 为 oid）
+	ClientOrderID string `json:"client_order_id"` // 客户端订单ID
+	Symbol        string `json:"symbol"`          // 交易对
+	Side          string `json:"side"`            // BUY / SELL
+	PositionSide  string `json:"position_side"`   // LONG / SHORT
+	Status        string `json:"status"`          // 订单状态（FILLED / NEW / PARTIALLY_FILLED ...）
+
+	RequestedQty float64 `json:"requested_qty"` // 请求数量（精度处理后）
+	FilledQty    float64 `json:"filled_qty"`    // 实际成交数量
+	AvgPrice     float64 `json:"avg_price"`     // 成交均价
+	Fee          float64 `json:"fee"`           // 手续费合计（负数表示返佣）
+	FeeAsset     string  `json:"fee_asset"`     // 手续费币种
+
+	SubmittedAt time.Time `json:"submitted_at"` // 提交时间（本地）
+	UpdatedAt   time.Time `json:"updated_at"`   // 最后更新时间（交易所）
+	Venue       string    `json:"venue"`        // 交易所标识
+
+	// 限价单超时转市价单时记录原限价单ID
+	Converted       bool  `json:"converted,omitempty"`
+	OriginalOrderID int64 `json:"original_order_id,omitempty"`
+}
+
+// IsFilled 是否已有成交
+func (r *ExecutionReport) IsFilled() bool {
+	return r != nil && r.FilledQty > 0
+}
+
+// applyToAction 将执行报告写入决策日志动作（有成交数据时用实际成交价/成交量覆盖预估值）
+func (r *ExecutionReport) applyToAction(action *logger.DecisionAction) {
+	if r == nil || action == nil {
+		return
+	}
+	action.OrderID = r.OrderID
+	action.Fee = r.Fee
+	if r.IsFilled() {
+		action.Quantity = r.FilledQty
+		if r.AvgPrice > 0 {
+			action.Price = r.AvgPrice
+		}
+	}
+}
+
+// fillQtyTolerance 成交明细合计与订单成交量比较时允许的相对误差（浮点字符串累加误差）
+const fillQtyTolerance = 1e-6
+
+// tradeFill 单笔成交明细（来自交易所成交历史）
+type tradeFill struct {
+	Price    float64
+	Quantity float64
+	Fee      float64
+	FeeAsset string
+}
+
+// applyFills 按成交明细计算真实成交量、成交均价（按成交量加权）和手续费合计
+// 没有有效成交明细，或成交历史尚未同步完整（明细数量少于订单成交量）时，保留订单自身的成交量和均价
+func (r *ExecutionReport) applyFills(fills []tradeFill) {
+	var qty, notional, fee float64
+	var feeAsset string
+	for _, fill := range fills {
+		if fill.Price <= 0 || fill.Quantity <= 0 {
+			continue
+		}
+		qty += fill.Quantity
+		notional += fill.Price * fill.Quantity
+		fee += fill.Fee
+		if fill.FeeAsset != "" {
+			feeAsset = fill.FeeAsset
+		}
+	}
+	if qty <= 0 {
+		return
+	}
+
+	r.Fee = fee
+	r.FeeAsset = feeAsset
+	if qty < r.FilledQty*(1-fillQtyTolerance) {
+		return
+	}
+	r.FilledQty = qty
+	r.AvgPrice = notional / qty
+}
+
+// parseFloatOrZero 解析交易所返回的字符串数值，失败时返回0
+func parseFloatOrZero(s string) float64 {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0
+	}
+	return v
+}
+
+// msToTime 毫秒时间戳转换为 time.Time（0 返回零值）
+func msToTime(ms int64) time.Time {
+	if ms <= 0 {
+		return time.Time{}
+	}
+	return time.UnixMilli(ms)
+}
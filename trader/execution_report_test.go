@@ -0,0 +1,82 @@
+package trader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nofx/logger"
+
+	"github.com/sonirico/go-hyperliquid"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestHyperliquidNewExecutionReport 测试 Hyperliquid 下单状态转换为执行报告
+func TestHyperliquidNewExecutionReport(t *testing.T) {
+	trader := &HyperliquidTrader{}
+
+	t.Run("已成交", func(t *testing.T) {
+		report, err := trader.newExecutionReport(context.Background(), "BTCUSDT", "BUY", "LONG", 0.01, hyperliquid.OrderStatus{
+			Filled: &hyperliquid.OrderStatusFilled{TotalSz: "0.01", AvgPx: "50000.5"},
+		}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, "FILLED", report.Status)
+		assert.Equal(t, 0.01, report.FilledQty)
+		assert.Equal(t, 50000.5, report.AvgPrice)
+		assert.Equal(t, VenueHyperliquid, report.Venue)
+	})
+
+	t.Run("挂单中", func(t *testing.T) {
+		report, err := trader.newExecutionReport(context.Background(), "BTCUSDT", "SELL", "SHORT", 0.01, hyperliquid.OrderStatus{
+			Resting: &hyperliquid.OrderStatusResting{Oid: 42},
+		}, time.Now())
+		assert.NoError(t, err)
+		assert.Equal(t, int64(42), report.OrderID)
+		assert.Equal(t, "NEW", report.Status)
+		assert.False(t, report.IsFilled())
+	})
+
+	t.Run("订单被拒绝", func(t *testing.T) {
+		msg := "Order could not immediately match against any resting orders"
+		_, err := trader.newExecutionReport(context.Background(), "BTCUSDT", "BUY", "LONG", 0.01, hyperliquid.OrderStatus{Error: &msg}, time.Now())
+		assert.Error(t, err)
+	})
+}
+
+// TestExecutionReportApplyToAction 测试执行报告写入决策日志动作
+func TestExecutionReportApplyToAction(t *testing.T) {
+	action := &logger.DecisionAction{Quantity: 1.0, Price: 100}
+
+	(&ExecutionReport{OrderID: 7}).applyToAction(action)
+	assert.Equal(t, int64(7), action.OrderID)
+	assert.Equal(t, 100.0, action.Price, "未成交时保留预估价格")
+
+	(&ExecutionReport{OrderID: 8, FilledQty: 0.9, AvgPrice: 101}).applyToAction(action)
+	assert.Equal(t, 0.9, action.Quantity)
+	assert.Equal(t, 101.0, action.Price)
+}
+
+// TestExecutionReportApplyFills 测试按成交明细计算加权均价和手续费
+func TestExecutionReportApplyFills(t *testing.T) {
+	report := &ExecutionReport{FilledQty: 3, AvgPrice: 100}
+	report.applyFills([]tradeFill{
+		{Price: 100, Quantity: 1, Fee: 0.04, FeeAsset: "USDT"},
+		{Price: 103, Quantity: 2, Fee: 0.08, FeeAsset: "USDT"},
+	})
+	assert.Equal(t, 3.0, report.FilledQty)
+	assert.InDelta(t, 102.0, report.AvgPrice, 1e-9)
+	assert.InDelta(t, 0.12, report.Fee, 1e-9)
+	assert.Equal(t, "USDT", report.FeeAsset)
+
+	// 成交历史尚未同步完整：只更新手续费，保留订单成交量和均价
+	report = &ExecutionReport{FilledQty: 3, AvgPrice: 101}
+	report.applyFills([]tradeFill{{Price: 100, Quantity: 1, Fee: 0.04, FeeAsset: "USDT"}})
+	assert.Equal(t, 3.0, report.FilledQty)
+	assert.Equal(t, 101.0, report.AvgPrice)
+	assert.Equal(t, 0.04, report.Fee)
+
+	// 没有有效明细时保持不变
+	report = &ExecutionReport{FilledQty: 1, AvgPrice: 99}
+	report.applyFills(nil)
+	assert.Equal(t, 99.0, report.AvgPrice)
+}
@@ -0,0 +1,28 @@
+package trader
+
+import (
+	"math"
+	"nofx/decision"
+)
+
+// minFillSlippagePct 成交均价与参考价偏差小于该百分比时不调整止损/止盈
+const minFillSlippagePct = 0.01
+
+// anchorBracketToFill 按实际成交均价重新锚定止损/止盈
+// 止损/止盈是按下单前行情价校验和计算的，行情剧烈波动时成交价可能明显偏离，
+// 按成交均价等比例平移止损/止盈，保持它们相对开仓价的百分比距离不变
+func anchorBracketToFill(d *decision.Decision, referencePrice float64, order *ExecutionReport) {
+	if !order.IsFilled() || order.AvgPrice <= 0 || referencePrice <= 0 {
+		return
+	}
+
+	slippagePct := (order.AvgPrice - referencePrice) / referencePrice * 100
+	if math.Abs(slippagePct) < minFillSlippagePct {
+		return
+	}
+
+	ratio := order.AvgPrice / referencePrice
+	stopLoss, takeProfit := d.StopLoss*ratio, d.TakeProfit*ratio
+	log.Info("  🎯 按成交均价调整止盈止损", "symbol", d.Symbol, "avg_price", order.AvgPrice, "reference_price", referencePrice, "slippage_pct", slippagePct, "stop_loss_from", d.StopLoss, "stop_loss", stopLoss, "take_profit_from", d.TakeProfit, "take_profit", takeProfit)
+	d.StopLoss, d.TakeProfit = stopLoss, takeProfit
+}
@@ -0,0 +1,33 @@
+package trader
+
+import (
+	"testing"
+
+	"nofx/decision"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnchorBracketToFill(t *testing.T) {
+	t.Run("多单成交价高于参考价", func(t *testing.T) {
+		d := &decision.Decision{Symbol: "BTCUSDT", Action: "open_long", StopLoss: 98, TakeProfit: 104}
+		anchorBracketToFill(d, 100, &ExecutionReport{FilledQty: 1, AvgPrice: 101})
+		assert.InDelta(t, 98.98, d.StopLoss, 1e-9)
+		assert.InDelta(t, 105.04, d.TakeProfit, 1e-9)
+	})
+
+	t.Run("空单成交价低于参考价", func(t *testing.T) {
+		d := &decision.Decision{Symbol: "ETHUSDT", Action: "open_short", StopLoss: 2040, TakeProfit: 1900}
+		anchorBracketToFill(d, 2000, &ExecutionReport{FilledQty: 1, AvgPrice: 1990})
+		assert.InDelta(t, 2029.8, d.StopLoss, 1e-9)
+		assert.InDelta(t, 1890.5, d.TakeProfit, 1e-9)
+	})
+
+	t.Run("无成交数据或偏差极小时不调整", func(t *testing.T) {
+		d := &decision.Decision{StopLoss: 98, TakeProfit: 104}
+		anchorBracketToFill(d, 100, &ExecutionReport{OrderID: 1})
+		anchorBracketToFill(d, 100, &ExecutionReport{FilledQty: 1, AvgPrice: 100.005})
+		assert.Equal(t, 98.0, d.StopLoss)
+		assert.Equal(t, 104.0, d.TakeProfit)
+	})
+}
@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 资金费率过滤模式
+const (
+	FundingFilterVeto    = "veto"    // 拒绝开仓
+	FundingFilterReverse = "reverse" // 反向开仓（收取资金费）
+)
+
+// FundingFilterFromEnv 读取 NOFX_FUNDING_COST_THRESHOLD_BPS（单次结算资金费率成本阈值，基点，未设置=关闭）
+// 和 NOFX_FUNDING_FILTER_MODE（veto / reverse，未设置=veto）
+func FundingFilterFromEnv() (float64, string, error) {
+	threshold := 0.0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_FUNDING_COST_THRESHOLD_BPS")); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 || v > 100 {
+			return 0, "", fmt.Errorf("NOFX_FUNDING_COST_THRESHOLD_BPS 必须在 [0, 100] 之间: %q", raw)
+		}
+		threshold = v
+	}
+	mode := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_FUNDING_FILTER_MODE")))
+	switch mode {
+	case "":
+		mode = FundingFilterVeto
+	case FundingFilterVeto, FundingFilterReverse:
+	default:
+		return 0, "", fmt.Errorf("NOFX_FUNDING_FILTER_MODE 必须是 %s 或 %s: %q", FundingFilterVeto, FundingFilterReverse, mode)
+	}
+	return threshold, mode, nil
+}
+
+// fundingCostBps 计算开仓方向在单次结算中需要支付的资金费率成本（基点）
+// 资金费率为正时多头支付空头，为负时空头支付多头；返回负数表示该方向可收取资金费
+func fundingCostBps(action string, rate float64) float64 {
+	if action == "open_short" {
+		return -rate * 10000
+	}
+	return rate * 10000
+}
+
+// applyFundingFilter 开仓前检查预测资金费率成本
+// 成本超过 FundingCostThresholdBps 时：veto 模式直接否决；reverse 模式改为反方向开仓并镜像止损止盈
+// 获取资金费率失败时不阻止开仓，仅记录日志
+func (at *AutoTrader) applyFundingFilter(d *decision.Decision) error {
+	threshold := at.getFundingCostThresholdBps()
+	if threshold <= 0 {
+		return nil
+	}
+
+	funding, err := at.trader.GetFundingRate(at.ctx(), d.Symbol)
+	if err != nil {
+		log.Warn("  ⚠ 获取资金费率失败，跳过资金费率过滤", "symbol", d.Symbol, "error", err)
+		return nil
+	}
+
+	cost := fundingCostBps(d.Action, funding.Rate)
+	if cost <= threshold {
+		return nil
+	}
+
+	if at.config.FundingFilterMode != FundingFilterReverse {
+		return fmt.Errorf("❌ %s 预测资金费率 %.4f%% 使 %s 每次结算成本 %.2f bps 超过阈值 %.2f bps，否决开仓",
+			d.Symbol, funding.Rate*100, d.Action, cost, threshold)
+	}
+
+	price, err := at.trader.GetMarketPrice(at.ctx(), d.Symbol)
+	if err != nil {
+		return fmt.Errorf("❌ %s 资金费率成本超限且无法获取价格用于反向开仓: %w", d.Symbol, err)
+	}
+
+	original := d.Action
+	reverseEntry(d, price)
+	log.Info("  🔄 预测资金费率成本超过阈值，反向开仓", "symbol", d.Symbol, "funding_rate_pct", funding.Rate*100, "original", original, "cost_bps", cost, "threshold_bps", threshold, "action", d.Action, "stop_loss", d.StopLoss, "take_profit", d.TakeProfit)
+	return nil
+}
+
+// reverseEntry 将开仓决策反向，并以当前价格为中心镜像止损止盈（保持相同的风险收益距离）
+func reverseEntry(d *decision.Decision, price float64) {
+	if d.Action == "open_long" {
+		d.Action = "open_short"
+	} else {
+		d.Action = "open_long"
+	}
+
+	if d.StopLoss > 0 {
+		d.StopLoss = 2*price - d.StopLoss
+	}
+	if d.TakeProfit > 0 {
+		d.TakeProfit = 2*price - d.TakeProfit
+	}
+}
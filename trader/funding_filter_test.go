@@ -0,0 +1,77 @@
+package trader
+
+import (
+	"strings"
+	"testing"
+
+	"nofx/decision"
+)
+
+// TestApplyFundingFilter 测试资金费率过滤（否决/反向/放行）
+func TestApplyFundingFilter(t *testing.T) {
+	tests := []struct {
+		name         string
+		mode         string
+		action       string
+		rate         float64
+		expectErr    string
+		expectAction string
+		expectSL     float64
+		expectTP     float64
+	}{
+		{name: "多单_成本未超限_放行", action: "open_long", rate: 0.0001, expectAction: "open_long", expectSL: 48000, expectTP: 53000},
+		{name: "多单_成本超限_否决", action: "open_long", rate: 0.001, expectErr: "否决开仓"},
+		{name: "空单_收取资金费_放行", action: "open_short", rate: 0.001, expectAction: "open_short", expectSL: 48000, expectTP: 53000},
+		{name: "多单_成本超限_反向开空", mode: FundingFilterReverse, action: "open_long", rate: 0.001, expectAction: "open_short", expectSL: 52000, expectTP: 47000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := &AutoTrader{
+				trader: &MockTrader{fundingRate: tt.rate}, // MockTrader 价格固定为 50000
+				config: AutoTraderConfig{FundingCostThresholdBps: 5, FundingFilterMode: tt.mode},
+			}
+			d := &decision.Decision{Symbol: "BTCUSDT", Action: tt.action, StopLoss: 48000, TakeProfit: 53000}
+
+			err := at.applyFundingFilter(d)
+			if tt.expectErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.expectErr) {
+					t.Fatalf("期望错误包含 %q，实际: %v", tt.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if d.Action != tt.expectAction || d.StopLoss != tt.expectSL || d.TakeProfit != tt.expectTP {
+				t.Errorf("期望 %s SL=%.0f TP=%.0f，实际 %s SL=%.0f TP=%.0f",
+					tt.expectAction, tt.expectSL, tt.expectTP, d.Action, d.StopLoss, d.TakeProfit)
+			}
+		})
+	}
+}
+
+func TestFundingFilterFromEnv(t *testing.T) {
+	if threshold, mode, err := FundingFilterFromEnv(); err != nil || threshold != 0 || mode != FundingFilterVeto {
+		t.Fatalf("未配置时应关闭: %v %q %v", threshold, mode, err)
+	}
+
+	t.Setenv("NOFX_FUNDING_COST_THRESHOLD_BPS", "3.5")
+	t.Setenv("NOFX_FUNDING_FILTER_MODE", "Reverse")
+	if threshold, mode, err := FundingFilterFromEnv(); err != nil || threshold != 3.5 || mode != FundingFilterReverse {
+		t.Errorf("配置解析错误: %v %q %v", threshold, mode, err)
+	}
+
+	for _, bad := range []struct{ key, value string }{
+		{"NOFX_FUNDING_COST_THRESHOLD_BPS", "-1"},
+		{"NOFX_FUNDING_COST_THRESHOLD_BPS", "abc"},
+		{"NOFX_FUNDING_FILTER_MODE", "hedge"},
+	} {
+		t.Run(bad.key+"="+bad.value, func(t *testing.T) {
+			t.Setenv(bad.key, bad.value)
+			if _, _, err := FundingFilterFromEnv(); err == nil {
+				t.Errorf("%s=%q 应返回错误", bad.key, bad.value)
+			}
+		})
+	}
+}
@@ -5,11 +5,14 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
 	"nofx/decision"
+	"nofx/market"
+	"nofx/netconfig"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/sonirico/go-hyperliquid"
@@ -18,7 +21,6 @@ import (
 // HyperliquidTrader Hyperliquid交易器
 type HyperliquidTrader struct {
 	exchange      *hyperliquid.Exchange
-	ctx           context.Context
 	walletAddr    string
 	meta          *hyperliquid.Meta // 缓存meta信息（包含精度等）
 	metaMutex     sync.RWMutex      // 保护meta字段的并发访问
@@ -41,6 +43,7 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 	if testnet {
 		apiURL = hyperliquid.TestnetAPIURL
 	}
+	apiURL = netconfig.BaseURL("hyperliquid", apiURL)
 
 	// Security enhancement: Implement Agent Wallet best practices
 	// Reference: https://hyperliquid.gitbook.io/hyperliquid-docs/for-developers/api/nonces-and-api-wallets
@@ -57,14 +60,9 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 
 	// Check if user accidentally uses main wallet private key (security risk)
 	if strings.EqualFold(walletAddr, agentAddr) {
-		log.Printf("⚠️⚠️⚠️ WARNING: Main wallet address (%s) matches Agent wallet address!", walletAddr)
-		log.Printf("   This indicates you may be using your main wallet private key, which poses extremely high security risks!")
-		log.Printf("   Recommendation: Immediately create a separate Agent Wallet on Hyperliquid official website")
-		log.Printf("   Reference: https://hyperliquid.gitbook.io/hyperliquid-docs/for-developers/api/nonces-and-api-wallets")
+		log.Warn("⚠️ Main wallet address matches Agent wallet address! You may be using your main wallet private key, which poses extremely high security risks; create a separate Agent Wallet on Hyperliquid", "wallet", walletAddr, "reference", "https://hyperliquid.gitbook.io/hyperliquid-docs/for-developers/api/nonces-and-api-wallets")
 	} else {
-		log.Printf("✓ Using Agent Wallet mode (secure)")
-		log.Printf("  └─ Agent wallet address: %s (for signing)", agentAddr)
-		log.Printf("  └─ Main wallet address: %s (holds funds)", walletAddr)
+		log.Info("✓ Using Agent Wallet mode (secure)", "agent_wallet", agentAddr, "main_wallet", walletAddr)
 	}
 
 	ctx := context.Background()
@@ -80,7 +78,7 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 		nil,        // SpotMeta will be fetched automatically
 	)
 
-	log.Printf("✓ Hyperliquid交易器初始化成功 (testnet=%v, wallet=%s)", testnet, walletAddr)
+	log.Info("✓ Hyperliquid交易器初始化成功", "testnet", testnet, "wallet_addr", walletAddr)
 
 	// 获取meta信息（包含精度等配置）
 	meta, err := exchange.Info().Meta(ctx)
@@ -98,32 +96,23 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 
 			if agentBalance > 100 {
 				// Critical: Agent wallet holds too much funds
-				log.Printf("🚨🚨🚨 CRITICAL SECURITY WARNING 🚨🚨🚨")
-				log.Printf("   Agent wallet balance: %.2f USDC (exceeds safe threshold of 100 USDC)", agentBalance)
-				log.Printf("   Agent wallet address: %s", agentAddr)
-				log.Printf("   ⚠️  Agent wallets should only be used for signing and hold minimal/zero balance")
-				log.Printf("   ⚠️  High balance in Agent wallet poses security risks")
-				log.Printf("   📖 Reference: https://hyperliquid.gitbook.io/hyperliquid-docs/for-developers/api/nonces-and-api-wallets")
-				log.Printf("   💡 Recommendation: Transfer funds to main wallet and keep Agent wallet balance near 0")
+				log.Error("🚨 CRITICAL SECURITY WARNING: Agent wallet balance exceeds safe threshold of 100 USDC; agent wallets should only be used for signing, transfer funds to the main wallet", "agent_wallet", agentAddr, "balance", agentBalance, "reference", "https://hyperliquid.gitbook.io/hyperliquid-docs/for-developers/api/nonces-and-api-wallets")
 				return nil, fmt.Errorf("security check failed: Agent wallet balance too high (%.2f USDC), exceeds 100 USDC threshold", agentBalance)
 			} else if agentBalance > 10 {
 				// Warning: Agent wallet has some balance (acceptable but not ideal)
-				log.Printf("⚠️  Notice: Agent wallet address (%s) has some balance: %.2f USDC", agentAddr, agentBalance)
-				log.Printf("   While not critical, it's recommended to keep Agent wallet balance near 0 for security")
+				log.Warn("⚠️ Agent wallet has some balance; while not critical, it's recommended to keep it near 0 for security", "agent_wallet", agentAddr, "balance", agentBalance)
 			} else {
 				// OK: Agent wallet balance is safe
-				log.Printf("✓ Agent wallet balance is safe: %.2f USDC (near zero as recommended)", agentBalance)
+				log.Info("✓ Agent wallet balance is safe (near zero as recommended)", "balance", agentBalance)
 			}
 		} else if err != nil {
 			// Failed to query agent balance - log warning but don't block initialization
-			log.Printf("⚠️  Could not verify Agent wallet balance (query failed): %v", err)
-			log.Printf("   Proceeding with initialization, but please manually verify Agent wallet balance is near 0")
+			log.Warn("⚠️ Could not verify Agent wallet balance, proceeding with initialization; please manually verify it is near 0", "error", err)
 		}
 	}
 
 	return &HyperliquidTrader{
 		exchange:      exchange,
-		ctx:           ctx,
 		walletAddr:    walletAddr,
 		meta:          meta,
 		isCrossMargin: true, // 默认使用全仓模式
@@ -131,28 +120,28 @@ func NewHyperliquidTrader(privateKeyHex string, walletAddr string, testnet bool)
 }
 
 // GetBalance 获取账户余额
-func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
-	log.Printf("🔄 正在调用Hyperliquid API获取账户余额...")
+func (t *HyperliquidTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	log.Info("🔄 正在调用Hyperliquid API获取账户余额...")
 
 	// ✅ Step 1: 查询 Spot 现货账户余额
-	spotState, err := t.exchange.Info().SpotUserState(t.ctx, t.walletAddr)
+	spotState, err := t.exchange.Info().SpotUserState(ctx, t.walletAddr)
 	var spotUSDCBalance float64 = 0.0
 	if err != nil {
-		log.Printf("⚠️ 查询 Spot 余额失败（可能无现货资产）: %v", err)
+		log.Warn("⚠️ 查询 Spot 余额失败（可能无现货资产）", "error", err)
 	} else if spotState != nil && len(spotState.Balances) > 0 {
 		for _, balance := range spotState.Balances {
 			if balance.Coin == "USDC" {
 				spotUSDCBalance, _ = strconv.ParseFloat(balance.Total, 64)
-				log.Printf("✓ 发现 Spot 现货余额: %.2f USDC", spotUSDCBalance)
+				log.Info("✓ 发现 Spot 现货余额", "spot_balance", spotUSDCBalance)
 				break
 			}
 		}
 	}
 
 	// ✅ Step 2: 查询 Perpetuals 合约账户状态
-	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
+	accountState, err := t.exchange.Info().UserState(ctx, t.walletAddr)
 	if err != nil {
-		log.Printf("❌ Hyperliquid Perpetuals API调用失败: %v", err)
+		log.Error("❌ Hyperliquid Perpetuals API调用失败", "error", err)
 		return nil, fmt.Errorf("获取账户信息失败: %w", err)
 	}
 
@@ -180,8 +169,7 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 
 	// 🔍 调试：打印API返回的完整摘要结构
 	summaryJSON, _ := json.MarshalIndent(summary, "  ", "  ")
-	log.Printf("🔍 [DEBUG] Hyperliquid API %s 完整数据:", summaryType)
-	log.Printf("%s", string(summaryJSON))
+	log.Debug("🔍 Hyperliquid API 完整数据", "type", summaryType, "data", string(summaryJSON))
 
 	// ⚠️ 关键修复：从所有持仓中累加真正的未实现盈亏
 	totalUnrealizedPnl := 0.0
@@ -205,7 +193,7 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 		withdrawable, err := strconv.ParseFloat(accountState.Withdrawable, 64)
 		if err == nil && withdrawable > 0 {
 			availableBalance = withdrawable
-			log.Printf("✓ 使用 Withdrawable 作为可用余额: %.2f", availableBalance)
+			log.Info("✓ 使用 Withdrawable 作为可用余额", "available_balance", availableBalance)
 		}
 	}
 
@@ -213,7 +201,7 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 	if availableBalance == 0 && accountState.Withdrawable == "" {
 		availableBalance = accountValue - totalMarginUsed
 		if availableBalance < 0 {
-			log.Printf("⚠️ 计算出的可用余额为负数 (%.2f)，重置为 0", availableBalance)
+			log.Warn("⚠️ 计算出的可用余额为负数，重置为 0", "available_balance", availableBalance)
 			availableBalance = 0
 		}
 	}
@@ -227,26 +215,50 @@ func (t *HyperliquidTrader) GetBalance() (map[string]interface{}, error) {
 	result["availableBalance"] = availableBalance        // Available balance (Perpetuals only, excludes Spot)
 	result["totalUnrealizedProfit"] = totalUnrealizedPnl // Unrealized P&L (from Perpetuals only)
 	result["spotBalance"] = spotUSDCBalance              // Spot balance (returned separately)
+	// 维持保证金和保证金余额（与币安相同口径，用于计算保证金率）
+	result["totalMaintMargin"] = t.maintMargin(accountState.AssetPositions)
+	result["totalMarginBalance"] = accountValue
 
-	log.Printf("✓ Hyperliquid 完整账户:")
-	log.Printf("  • Spot 现货余额: %.2f USDC （需手动转账到 Perpetuals 才能开仓）", spotUSDCBalance)
-	log.Printf("  • Perpetuals 合约净值: %.2f USDC (钱包%.2f + 未实现%.2f)",
-		accountValue,
-		walletBalanceWithoutUnrealized,
-		totalUnrealizedPnl)
-	log.Printf("  • Perpetuals 可用余额: %.2f USDC （可直接用于开仓）", availableBalance)
-	log.Printf("  • 保证金占用: %.2f USDC", totalMarginUsed)
-	log.Printf("  • 总资产 (Perp+Spot): %.2f USDC", totalWalletBalance)
-	log.Printf("  ⭐ 总资产: %.2f USDC | Perp 可用: %.2f USDC | Spot 余额: %.2f USDC",
-		totalWalletBalance, availableBalance, spotUSDCBalance)
+	log.Info("✓ Hyperliquid 完整账户", "total_balance", totalWalletBalance, "account_value", accountValue, "wallet_balance", walletBalanceWithoutUnrealized, "unrealized_pnl", totalUnrealizedPnl, "available_balance", availableBalance, "margin_used", totalMarginUsed, "spot_balance", spotUSDCBalance)
 
 	return result, nil
 }
 
+// maintMargin 估算持仓的维持保证金：Hyperliquid 的维持保证金为最大杠杆下初始保证金的一半，
+// 即 持仓价值 / (2 × 最大杠杆)（UserState 未返回维持保证金）
+func (t *HyperliquidTrader) maintMargin(positions []hyperliquid.AssetPosition) float64 {
+	t.metaMutex.RLock()
+	defer t.metaMutex.RUnlock()
+
+	maxLeverage := make(map[string]int)
+	if t.meta != nil {
+		for _, asset := range t.meta.Universe {
+			maxLeverage[asset.Name] = asset.MaxLeverage
+		}
+	}
+
+	total := 0.0
+	for _, assetPos := range positions {
+		value, err := strconv.ParseFloat(assetPos.Position.PositionValue, 64)
+		if err != nil {
+			continue
+		}
+		lev := maxLeverage[assetPos.Position.Coin]
+		if lev <= 0 {
+			// 未知币种按已占用保证金计（保守估计）
+			marginUsed, _ := strconv.ParseFloat(assetPos.Position.MarginUsed, 64)
+			total += marginUsed
+			continue
+		}
+		total += math.Abs(value) / float64(2*lev)
+	}
+	return total
+}
+
 // GetPositions 获取所有持仓
-func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
+func (t *HyperliquidTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
 	// 获取账户状态
-	accountState, err := t.exchange.Info().UserState(t.ctx, t.walletAddr)
+	accountState, err := t.exchange.Info().UserState(ctx, t.walletAddr)
 	if err != nil {
 		return nil, fmt.Errorf("获取持仓失败: %w", err)
 	}
@@ -310,44 +322,44 @@ func (t *HyperliquidTrader) GetPositions() ([]map[string]interface{}, error) {
 }
 
 // SetMarginMode 设置仓位模式 (在SetLeverage时一并设置)
-func (t *HyperliquidTrader) SetMarginMode(symbol string, isCrossMargin bool) error {
+func (t *HyperliquidTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
 	// Hyperliquid的仓位模式在SetLeverage时设置，这里只记录
 	t.isCrossMargin = isCrossMargin
 	marginModeStr := "全仓"
 	if !isCrossMargin {
 		marginModeStr = "逐仓"
 	}
-	log.Printf("  ✓ %s 将使用 %s 模式", symbol, marginModeStr)
+	log.Info("  ✓ 使用仓位模式", "symbol", symbol, "margin_mode", marginModeStr)
 	return nil
 }
 
 // SetLeverage 设置杠杆
-func (t *HyperliquidTrader) SetLeverage(symbol string, leverage int) error {
+func (t *HyperliquidTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
 	// Hyperliquid symbol格式（去掉USDT后缀）
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 调用UpdateLeverage (leverage int, name string, isCross bool)
 	// 第三个参数: true=全仓模式, false=逐仓模式
-	_, err := t.exchange.UpdateLeverage(t.ctx, leverage, coin, t.isCrossMargin)
+	_, err := t.exchange.UpdateLeverage(ctx, leverage, coin, t.isCrossMargin)
 	if err != nil {
 		return fmt.Errorf("设置杠杆失败: %w", err)
 	}
 
-	log.Printf("  ✓ %s 杠杆已切换为 %dx", symbol, leverage)
+	log.Info("  ✓ 杠杆已切换", "symbol", symbol, "leverage", leverage)
 	return nil
 }
 
 // refreshMetaIfNeeded 当 Meta 信息失效时刷新（Asset ID 为 0 时触发）
-func (t *HyperliquidTrader) refreshMetaIfNeeded(coin string) error {
+func (t *HyperliquidTrader) refreshMetaIfNeeded(ctx context.Context, coin string) error {
 	assetID := t.exchange.Info().NameToAsset(coin)
 	if assetID != 0 {
 		return nil // Meta 正常，无需刷新
 	}
 
-	log.Printf("⚠️  %s 的 Asset ID 为 0，尝试刷新 Meta 信息...", coin)
+	log.Warn("⚠️ Asset ID 为 0，尝试刷新 Meta 信息...", "coin", coin)
 
 	// 刷新 Meta 信息
-	meta, err := t.exchange.Info().Meta(t.ctx)
+	meta, err := t.exchange.Info().Meta(ctx)
 	if err != nil {
 		return fmt.Errorf("刷新 Meta 信息失败: %w", err)
 	}
@@ -357,7 +369,7 @@ func (t *HyperliquidTrader) refreshMetaIfNeeded(coin string) error {
 	t.meta = meta
 	t.metaMutex.Unlock()
 
-	log.Printf("✅ Meta 信息已刷新，包含 %d 个资产", len(meta.Universe))
+	log.Info("✅ Meta 信息已刷新", "asset_count", len(meta.Universe))
 
 	// 验证刷新后的 Asset ID
 	assetID = t.exchange.Info().NameToAsset(coin)
@@ -368,38 +380,35 @@ func (t *HyperliquidTrader) refreshMetaIfNeeded(coin string) error {
 			"  3. API 连接问题", coin)
 	}
 
-	log.Printf("✅ 刷新后 Asset ID 检查通过: %s -> %d", coin, assetID)
+	log.Info("✅ 刷新后 Asset ID 检查通过", "coin", coin, "asset_id", assetID)
 	return nil
 }
 
 // OpenLong 开多仓
-func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 先取消该币种的所有委托单
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消旧委托单失败", "error", err)
 	}
 
-	// 设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, err
-	}
+	// 杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过）
 
 	// Hyperliquid symbol格式
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取当前价格（用于市价单）
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	log.Info("  📏 数量精度处理", "quantity", quantity, "rounded", roundedQuantity, "sz_decimals", t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 1.01)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
+	log.Info("  💰 价格精度处理（5位有效数字）", "price", price*1.01, "rounded", aggressivePrice)
 
 	// 创建市价买入订单（使用IOC limit order with aggressive price）
 	order := hyperliquid.CreateOrderRequest{
@@ -415,49 +424,47 @@ func (t *HyperliquidTrader) OpenLong(symbol string, quantity float64, leverage i
 		ReduceOnly: false,
 	}
 
-	_, err = t.exchange.Order(t.ctx, order, nil)
+	submittedAt := time.Now()
+	status, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("开多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	report, err := t.newExecutionReport(ctx, symbol, "BUY", "LONG", roundedQuantity, status, submittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("开多仓失败: %w", err)
+	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0 // Hyperliquid没有返回order ID
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	log.Info("✓ 开多仓成功", "symbol", symbol, "quantity", roundedQuantity)
 
-	return result, nil
+	return report, nil
 }
 
 // OpenShort 开空仓
-func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
 	// 先取消该币种的所有委托单
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消旧委托单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消旧委托单失败", "error", err)
 	}
 
-	// 设置杠杆
-	if err := t.SetLeverage(symbol, leverage); err != nil {
-		return nil, err
-	}
+	// 杠杆由 leverageTrader 在开仓前设置（已是目标杠杆时跳过）
 
 	// Hyperliquid symbol格式
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	log.Info("  📏 数量精度处理", "quantity", quantity, "rounded", roundedQuantity, "sz_decimals", t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 0.99)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
+	log.Info("  💰 价格精度处理（5位有效数字）", "price", price*0.99, "rounded", aggressivePrice)
 
 	// 创建市价卖出订单
 	order := hyperliquid.CreateOrderRequest{
@@ -473,26 +480,27 @@ func (t *HyperliquidTrader) OpenShort(symbol string, quantity float64, leverage
 		ReduceOnly: false,
 	}
 
-	_, err = t.exchange.Order(t.ctx, order, nil)
+	submittedAt := time.Now()
+	status, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("开空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 开空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	report, err := t.newExecutionReport(ctx, symbol, "SELL", "SHORT", roundedQuantity, status, submittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("开空仓失败: %w", err)
+	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	log.Info("✓ 开空仓成功", "symbol", symbol, "quantity", roundedQuantity)
 
-	return result, nil
+	return report, nil
 }
 
 // CloseLong 平多仓
-func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPositions(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -513,18 +521,18 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	log.Info("  📏 数量精度处理", "quantity", quantity, "rounded", roundedQuantity, "sz_decimals", t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 0.99)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*0.99, aggressivePrice)
+	log.Info("  💰 价格精度处理（5位有效数字）", "price", price*0.99, "rounded", aggressivePrice)
 
 	// 创建平仓订单（卖出 + ReduceOnly）
 	order := hyperliquid.CreateOrderRequest{
@@ -540,31 +548,32 @@ func (t *HyperliquidTrader) CloseLong(symbol string, quantity float64) (map[stri
 		ReduceOnly: true, // 只平仓，不开新仓
 	}
 
-	_, err = t.exchange.Order(t.ctx, order, nil)
+	submittedAt := time.Now()
+	status, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("平多仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平多仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	report, err := t.newExecutionReport(ctx, symbol, "SELL", "LONG", roundedQuantity, status, submittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("平多仓失败: %w", err)
+	}
+
+	log.Info("✓ 平多仓成功", "symbol", symbol, "quantity", roundedQuantity)
 
 	// 平仓后取消该币种的所有挂单
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败", "error", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
-
-	return result, nil
+	return report, nil
 }
 
 // CloseShort 平空仓
-func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (t *HyperliquidTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	// 如果数量为0，获取当前持仓数量
 	if quantity == 0 {
-		positions, err := t.GetPositions()
+		positions, err := t.GetPositions(ctx)
 		if err != nil {
 			return nil, err
 		}
@@ -585,18 +594,18 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取当前价格
-	price, err := t.GetMarketPrice(symbol)
+	price, err := t.GetMarketPrice(ctx, symbol)
 	if err != nil {
 		return nil, err
 	}
 
 	// ⚠️ 关键：根据币种精度要求，四舍五入数量
 	roundedQuantity := t.roundToSzDecimals(coin, quantity)
-	log.Printf("  📏 数量精度处理: %.8f -> %.8f (szDecimals=%d)", quantity, roundedQuantity, t.getSzDecimals(coin))
+	log.Info("  📏 数量精度处理", "quantity", quantity, "rounded", roundedQuantity, "sz_decimals", t.getSzDecimals(coin))
 
 	// ⚠️ 关键：价格也需要处理为5位有效数字
 	aggressivePrice := t.roundPriceToSigfigs(price * 1.01)
-	log.Printf("  💰 价格精度处理: %.8f -> %.8f (5位有效数字)", price*1.01, aggressivePrice)
+	log.Info("  💰 价格精度处理（5位有效数字）", "price", price*1.01, "rounded", aggressivePrice)
 
 	// 创建平仓订单（买入 + ReduceOnly）
 	order := hyperliquid.CreateOrderRequest{
@@ -612,50 +621,114 @@ func (t *HyperliquidTrader) CloseShort(symbol string, quantity float64) (map[str
 		ReduceOnly: true,
 	}
 
-	_, err = t.exchange.Order(t.ctx, order, nil)
+	submittedAt := time.Now()
+	status, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return nil, fmt.Errorf("平空仓失败: %w", err)
 	}
 
-	log.Printf("✓ 平空仓成功: %s 数量: %.4f", symbol, roundedQuantity)
+	report, err := t.newExecutionReport(ctx, symbol, "BUY", "SHORT", roundedQuantity, status, submittedAt)
+	if err != nil {
+		return nil, fmt.Errorf("平空仓失败: %w", err)
+	}
+
+	log.Info("✓ 平空仓成功", "symbol", symbol, "quantity", roundedQuantity)
 
 	// 平仓后取消该币种的所有挂单
-	if err := t.CancelAllOrders(symbol); err != nil {
-		log.Printf("  ⚠ 取消挂单失败: %v", err)
+	if err := t.CancelAllOrders(ctx, symbol); err != nil {
+		log.Warn("  ⚠ 取消挂单失败", "error", err)
 	}
 
-	result := make(map[string]interface{})
-	result["orderId"] = 0
-	result["symbol"] = symbol
-	result["status"] = "FILLED"
+	return report, nil
+}
 
-	return result, nil
+// newExecutionReport 将 Hyperliquid 下单状态转换为执行报告
+// IOC 订单未能立即成交时 Hyperliquid 返回 error 状态，此时视为下单失败
+func (t *HyperliquidTrader) newExecutionReport(ctx context.Context, symbol, side, positionSide string, requestedQty float64, status hyperliquid.OrderStatus, submittedAt time.Time) (*ExecutionReport, error) {
+	if status.Error != nil {
+		return nil, fmt.Errorf("订单被拒绝: %s", *status.Error)
+	}
+
+	report := &ExecutionReport{
+		Symbol:       symbol,
+		Side:         side,
+		PositionSide: positionSide,
+		RequestedQty: requestedQty,
+		SubmittedAt:  submittedAt,
+		UpdatedAt:    time.Now(),
+		Venue:        VenueHyperliquid,
+	}
+
+	switch {
+	case status.Filled != nil:
+		report.OrderID = int64(status.Filled.Oid)
+		report.Status = "FILLED"
+		report.FilledQty = parseFloatOrZero(status.Filled.TotalSz)
+		report.AvgPrice = parseFloatOrZero(status.Filled.AvgPx)
+	case status.Resting != nil:
+		report.OrderID = status.Resting.Oid
+		report.Status = "NEW"
+		if status.Resting.ClientID != nil {
+			report.ClientOrderID = *status.Resting.ClientID
+		}
+	}
+
+	t.enrichExecutionReport(ctx, report)
+	return report, nil
+}
+
+// enrichExecutionReport 从成交记录中补充成交均价和手续费（查询失败仅记录日志）
+func (t *HyperliquidTrader) enrichExecutionReport(ctx context.Context, report *ExecutionReport) {
+	if report.OrderID == 0 || !report.IsFilled() {
+		return
+	}
+
+	fills, err := t.exchange.Info().UserFillsByTime(ctx, t.walletAddr, report.SubmittedAt.Add(-time.Minute).UnixMilli(), nil)
+	if err != nil {
+		log.Warn("  ⚠ 查询成交手续费失败", "order_id", report.OrderID, "error", err)
+		return
+	}
+
+	var orderFills []tradeFill
+	for _, fill := range fills {
+		if fill.Oid != report.OrderID {
+			continue
+		}
+		orderFills = append(orderFills, tradeFill{
+			Price:    parseFloatOrZero(fill.Price),
+			Quantity: parseFloatOrZero(fill.Size),
+			Fee:      parseFloatOrZero(fill.Fee),
+			FeeAsset: fill.FeeToken,
+		})
+		report.UpdatedAt = msToTime(fill.Time)
+	}
+	report.applyFills(orderFills)
 }
 
 // CancelStopOrders 取消该币种的止盈/止
 
 // CancelStopLossOrders 仅取消止损单（Hyperliquid 暂无法区分止损和止盈，取消所有）
-func (t *HyperliquidTrader) CancelStopLossOrders(symbol string) error {
+func (t *HyperliquidTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
 	// Hyperliquid SDK 的 OpenOrder 结构不暴露 trigger 字段
 	// 无法区分止损和止盈单，因此取消该币种的所有挂单
-	log.Printf("  ⚠️ Hyperliquid 无法区分止损/止盈单，将取消所有挂单")
-	return t.CancelStopOrders(symbol)
+	log.Warn("  ⚠️ Hyperliquid 无法区分止损/止盈单，将取消所有挂单")
+	return t.CancelStopOrders(ctx, symbol)
 }
 
 // CancelTakeProfitOrders 仅取消止盈单（Hyperliquid 暂无法区分止损和止盈，取消所有）
-func (t *HyperliquidTrader) CancelTakeProfitOrders(symbol string) error {
+func (t *HyperliquidTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
 	// Hyperliquid SDK 的 OpenOrder 结构不暴露 trigger 字段
 	// 无法区分止损和止盈单，因此取消该币种的所有挂单
-	log.Printf("  ⚠️ Hyperliquid 无法区分止损/止盈单，将取消所有挂单")
-	return t.CancelStopOrders(symbol)
+	log.Warn("  ⚠️ Hyperliquid 无法区分止损/止盈单，将取消所有挂单")
+	return t.CancelStopOrders(ctx, symbol)
 }
 
 // CancelAllOrders 取消该币种的所有挂单
-func (t *HyperliquidTrader) CancelAllOrders(symbol string) error {
+func (t *HyperliquidTrader) CancelAllOrders(ctx context.Context, symbol string) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有挂单
-	openOrders, err := t.exchange.Info().OpenOrders(t.ctx, t.walletAddr)
+	openOrders, err := t.exchange.Info().OpenOrders(ctx, t.walletAddr)
 	if err != nil {
 		return fmt.Errorf("获取挂单失败: %w", err)
 	}
@@ -663,23 +736,23 @@ func (t *HyperliquidTrader) CancelAllOrders(symbol string) error {
 	// 取消该币种的所有挂单
 	for _, order := range openOrders {
 		if order.Coin == coin {
-			_, err := t.exchange.Cancel(t.ctx, coin, order.Oid)
+			_, err := t.exchange.Cancel(ctx, coin, order.Oid)
 			if err != nil {
-				log.Printf("  ⚠ 取消订单失败 (oid=%d): %v", order.Oid, err)
+				log.Warn("  ⚠ 取消订单失败", "order_id", order.Oid, "error", err)
 			}
 		}
 	}
 
-	log.Printf("  ✓ 已取消 %s 的所有挂单", symbol)
+	log.Info("  ✓ 已取消所有挂单", "symbol", symbol)
 	return nil
 }
 
 // CancelStopOrders 取消该币种的止盈/止损单（用于调整止盈止损位置）
-func (t *HyperliquidTrader) CancelStopOrders(symbol string) error {
+func (t *HyperliquidTrader) CancelStopOrders(ctx context.Context, symbol string) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有挂单
-	openOrders, err := t.exchange.Info().OpenOrders(t.ctx, t.walletAddr)
+	openOrders, err := t.exchange.Info().OpenOrders(ctx, t.walletAddr)
 	if err != nil {
 		return fmt.Errorf("获取挂单失败: %w", err)
 	}
@@ -690,9 +763,9 @@ func (t *HyperliquidTrader) CancelStopOrders(symbol string) error {
 	canceledCount := 0
 	for _, order := range openOrders {
 		if order.Coin == coin {
-			_, err := t.exchange.Cancel(t.ctx, coin, order.Oid)
+			_, err := t.exchange.Cancel(ctx, coin, order.Oid)
 			if err != nil {
-				log.Printf("  ⚠ 取消订单失败 (oid=%d): %v", order.Oid, err)
+				log.Warn("  ⚠ 取消订单失败", "order_id", order.Oid, "error", err)
 				continue
 			}
 			canceledCount++
@@ -700,20 +773,20 @@ func (t *HyperliquidTrader) CancelStopOrders(symbol string) error {
 	}
 
 	if canceledCount == 0 {
-		log.Printf("  ℹ %s 没有挂单需要取消", symbol)
+		log.Info("  ℹ 没有挂单需要取消", "symbol", symbol)
 	} else {
-		log.Printf("  ✓ 已取消 %s 的 %d 个挂单（包括止盈/止损单）", symbol, canceledCount)
+		log.Info("  ✓ 已取消挂单（包括止盈/止损单）", "symbol", symbol, "canceled_count", canceledCount)
 	}
 
 	return nil
 }
 
 // GetMarketPrice 获取市场价格
-func (t *HyperliquidTrader) GetMarketPrice(symbol string) (float64, error) {
+func (t *HyperliquidTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	// 获取所有市场价格
-	allMids, err := t.exchange.Info().AllMids(t.ctx)
+	allMids, err := t.exchange.Info().AllMids(ctx)
 	if err != nil {
 		return 0, fmt.Errorf("获取价格失败: %w", err)
 	}
@@ -730,8 +803,61 @@ func (t *HyperliquidTrader) GetMarketPrice(symbol string) (float64, error) {
 	return 0, fmt.Errorf("未找到 %s 的价格", symbol)
 }
 
+// GetFundingRate 获取当前（预测）资金费率（Hyperliquid 每小时结算一次）
+func (t *HyperliquidTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+
+	metaAndCtxs, err := t.exchange.Info().MetaAndAssetCtxs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率失败: %w", err)
+	}
+
+	for i, asset := range metaAndCtxs.Universe {
+		if asset.Name != coin || i >= len(metaAndCtxs.Ctxs) {
+			continue
+		}
+		rate, err := strconv.ParseFloat(metaAndCtxs.Ctxs[i].Funding, 64)
+		if err != nil {
+			return nil, fmt.Errorf("资金费率格式错误: %w", err)
+		}
+		return &market.FundingRate{
+			Symbol:      symbol,
+			Rate:        rate,
+			FundingTime: time.Now().Truncate(time.Hour).Add(time.Hour).UnixMilli(),
+		}, nil
+	}
+
+	return nil, fmt.Errorf("未找到 %s 的资金费率", symbol)
+}
+
+// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+func (t *HyperliquidTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	coin := convertSymbolToHyperliquid(symbol)
+
+	// 每小时结算一次，多取一小时避免边界遗漏
+	startTime := time.Now().Add(-time.Duration(n+1) * time.Hour).UnixMilli()
+	items, err := t.exchange.Info().FundingHistory(ctx, coin, startTime, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取资金费率历史失败: %w", err)
+	}
+
+	history := make([]market.FundingRate, 0, len(items))
+	for _, item := range items {
+		rate, err := strconv.ParseFloat(item.FundingRate, 64)
+		if err != nil {
+			continue
+		}
+		history = append(history, market.FundingRate{Symbol: symbol, Rate: rate, FundingTime: item.Time})
+	}
+
+	if len(history) > n {
+		history = history[len(history)-n:]
+	}
+	return history, nil
+}
+
 // SetStopLoss 设置止损单
-func (t *HyperliquidTrader) SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error {
+func (t *HyperliquidTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	isBuy := positionSide == "SHORT" // 空仓止损=买入，多仓止损=卖出
@@ -758,17 +884,65 @@ func (t *HyperliquidTrader) SetStopLoss(symbol string, positionSide string, quan
 		ReduceOnly: true,
 	}
 
-	_, err := t.exchange.Order(t.ctx, order, nil)
+	_, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return fmt.Errorf("设置止损失败: %w", err)
 	}
 
-	log.Printf("  止损价设置: %.4f", roundedStopPrice)
+	log.Info("  止损价设置", "stop_price", roundedStopPrice)
+	return nil
+}
+
+// AmendStopLoss 修改持仓止损价（实现 StopLossAmender）：直接修改交易所的止损触发单，
+// 不撤单重挂（Hyperliquid 撤销止损时无法区分止盈单，会连同止盈单一起撤销）
+func (t *HyperliquidTrader) AmendStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	coin := convertSymbolToHyperliquid(symbol)
+	isBuy := positionSide == "SHORT" // 空仓止损=买入，多仓止损=卖出
+
+	orders, err := t.exchange.Info().FrontendOpenOrders(ctx, t.walletAddr)
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", err)
+	}
+	var oid int64
+	for _, order := range orders {
+		if order.Coin == coin && order.IsTrigger && strings.HasPrefix(order.OrderType, "Stop") &&
+			(order.Side == hyperliquid.OrderSideBid) == isBuy {
+			oid = order.Oid
+			break
+		}
+	}
+	if oid == 0 {
+		return fmt.Errorf("未找到 %s 的止损单", symbol)
+	}
+
+	roundedStopPrice := t.roundPriceToSigfigs(stopPrice)
+	_, err = t.exchange.ModifyOrder(ctx, hyperliquid.ModifyOrderRequest{
+		Oid: oid,
+		Order: hyperliquid.CreateOrderRequest{
+			Coin:  coin,
+			IsBuy: isBuy,
+			Size:  t.roundToSzDecimals(coin, quantity),
+			Price: roundedStopPrice,
+			OrderType: hyperliquid.OrderType{
+				Trigger: &hyperliquid.TriggerOrderType{
+					TriggerPx: roundedStopPrice,
+					IsMarket:  true,
+					Tpsl:      "sl",
+				},
+			},
+			ReduceOnly: true,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("修改止损失败: %w", err)
+	}
+
+	log.Info("  止损价修改", "stop_price", roundedStopPrice)
 	return nil
 }
 
 // SetTakeProfit 设置止盈单
-func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+func (t *HyperliquidTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
 	coin := convertSymbolToHyperliquid(symbol)
 
 	isBuy := positionSide == "SHORT" // 空仓止盈=买入，多仓止盈=卖出
@@ -795,12 +969,12 @@ func (t *HyperliquidTrader) SetTakeProfit(symbol string, positionSide string, qu
 		ReduceOnly: true,
 	}
 
-	_, err := t.exchange.Order(t.ctx, order, nil)
+	_, err := t.exchange.Order(ctx, order, nil)
 	if err != nil {
 		return fmt.Errorf("设置止盈失败: %w", err)
 	}
 
-	log.Printf("  止盈价设置: %.4f", roundedTakeProfitPrice)
+	log.Info("  止盈价设置", "take_profit_price", roundedTakeProfitPrice)
 	return nil
 }
 
@@ -814,6 +988,20 @@ func (t *HyperliquidTrader) FormatQuantity(symbol string, quantity float64) (str
 	return fmt.Sprintf(formatStr, quantity), nil
 }
 
+// hyperliquidMinOrderValue Hyperliquid 单笔订单最小价值（Order must have minimum value of $10）
+const hyperliquidMinOrderValue = 10.0
+
+// SetPartialTakeProfit 按数量设置止盈单（实现 PartialTakeProfitSetter，Hyperliquid 止盈单本身按数量只减仓）
+func (t *HyperliquidTrader) SetPartialTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice)
+}
+
+// OrderLimits 获取最小下单要求（实现 OrderLimitsProvider）
+func (t *HyperliquidTrader) OrderLimits(ctx context.Context, symbol string) (OrderLimits, error) {
+	step := math.Pow10(-t.getSzDecimals(convertSymbolToHyperliquid(symbol)))
+	return OrderLimits{MinNotional: hyperliquidMinOrderValue, StepSize: step}, nil
+}
+
 // getSzDecimals 获取币种的数量精度
 func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 	// ✅ 并发安全：使用读锁保护 meta 字段访问
@@ -821,7 +1009,7 @@ func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 	defer t.metaMutex.RUnlock()
 
 	if t.meta == nil {
-		log.Printf("⚠️  meta信息为空，使用默认精度4")
+		log.Warn("⚠️ meta信息为空，使用默认精度4")
 		return 4 // 默认精度
 	}
 
@@ -832,7 +1020,7 @@ func (t *HyperliquidTrader) getSzDecimals(coin string) int {
 		}
 	}
 
-	log.Printf("⚠️  未找到 %s 的精度信息，使用默认精度4", coin)
+	log.Warn("⚠️ 未找到精度信息，使用默认精度4", "coin", coin)
 	return 4 // 默认精度
 }
 
@@ -913,9 +1101,9 @@ func absFloat(x float64) float64 {
 }
 
 // GetOpenOrders retrieves open orders for AI decision context
-func (t *HyperliquidTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderInfo, error) {
+func (t *HyperliquidTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
 	// 獲取所有未成交訂單
-	openOrders, err := t.exchange.Info().OpenOrders(t.ctx, t.walletAddr)
+	openOrders, err := t.exchange.Info().OpenOrders(ctx, t.walletAddr)
 	if err != nil {
 		return nil, fmt.Errorf("獲取未成交訂單失敗: %w", err)
 	}
@@ -950,6 +1138,6 @@ func (t *HyperliquidTrader) GetOpenOrders(symbol string) ([]decision.OpenOrderIn
 		result = append(result, orderInfo)
 	}
 
-	log.Printf("✓ 查詢到 %d 個未成交訂單", len(result))
+	log.Info("✓ 查詢到未成交訂單", "order_count", len(result))
 	return result, nil
 }
@@ -1,7 +1,6 @@
 package trader
 
 import (
-	"context"
 	"sync"
 	"testing"
 
@@ -12,7 +11,6 @@ import (
 func TestMetaConcurrentAccess(t *testing.T) {
 	// Create a HyperliquidTrader instance with meta initialized
 	trader := &HyperliquidTrader{
-		ctx: context.Background(),
 		meta: &hyperliquid.Meta{
 			Universe: []hyperliquid.AssetInfo{
 				{Name: "BTC", SzDecimals: 5},
@@ -45,7 +43,6 @@ func TestMetaConcurrentAccess(t *testing.T) {
 // TestMetaConcurrentReadWrite tests concurrent reads and writes to meta field
 func TestMetaConcurrentReadWrite(t *testing.T) {
 	trader := &HyperliquidTrader{
-		ctx: context.Background(),
 		meta: &hyperliquid.Meta{
 			Universe: []hyperliquid.AssetInfo{
 				{Name: "BTC", SzDecimals: 5},
@@ -145,7 +142,6 @@ func TestGetSzDecimals_ValidMeta(t *testing.T) {
 // Run with: go test -race -run TestMetaMutex_NoRaceCondition
 func TestMetaMutex_NoRaceCondition(t *testing.T) {
 	trader := &HyperliquidTrader{
-		ctx: context.Background(),
 		meta: &hyperliquid.Meta{
 			Universe: []hyperliquid.AssetInfo{
 				{Name: "BTC", SzDecimals: 5},
@@ -222,7 +222,6 @@ func NewHyperliquidTestSuite(t *testing.T) *HyperliquidTestSuite {
 
 	trader := &HyperliquidTrader{
 		exchange:      exchange,
-		ctx:           ctx,
 		walletAddr:    walletAddr,
 		meta:          meta,
 		isCrossMargin: true,
@@ -576,7 +575,6 @@ func TestHyperliquidTrader_GetSzDecimals(t *testing.T) {
 // TestHyperliquidTrader_SetMarginMode 测试设置保证金模式
 func TestHyperliquidTrader_SetMarginMode(t *testing.T) {
 	trader := &HyperliquidTrader{
-		ctx:           context.Background(),
 		isCrossMargin: true,
 	}
 
@@ -602,7 +600,7 @@ func TestHyperliquidTrader_SetMarginMode(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			err := trader.SetMarginMode(tt.symbol, tt.isCrossMargin)
+			err := trader.SetMarginMode(context.Background(), tt.symbol, tt.isCrossMargin)
 
 			if tt.wantError {
 				assert.Error(t, err)
@@ -701,7 +699,7 @@ func TestGetOpenOrders_Integration(t *testing.T) {
 	require.NoError(t, err)
 
 	// 查询所有未成交订单
-	orders, err := trader.GetOpenOrders("")
+	orders, err := trader.GetOpenOrders(context.Background(), "")
 	require.NoError(t, err)
 
 	// 验证结果格式
@@ -0,0 +1,87 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// 资金流水类型
+const (
+	IncomeFunding    = "funding"    // 资金费
+	IncomeCommission = "commission" // 交易手续费
+)
+
+// IncomeRecord 一条交易所资金流水
+type IncomeRecord struct {
+	Time   time.Time `json:"time"`
+	Symbol string    `json:"symbol"`
+	Type   string    `json:"type"`   // funding / commission
+	Amount float64   `json:"amount"` // 正数为收入，负数为支出
+	Asset  string    `json:"asset"`
+}
+
+// IncomeHistoryProvider 支持查询资金费和手续费流水的交易器（用于对账单）
+type IncomeHistoryProvider interface {
+	// GetIncomeHistory 查询 [from, to) 期间的资金费和手续费流水（按时间正序）
+	GetIncomeHistory(ctx context.Context, from, to time.Time) ([]IncomeRecord, error)
+}
+
+// binanceIncomePageSize 币安资金流水接口单次最多返回条数
+const binanceIncomePageSize = 1000
+
+// binanceIncomeTypes 币安资金流水类型
+var binanceIncomeTypes = map[string]string{
+	"FUNDING_FEE": IncomeFunding,
+	"COMMISSION":  IncomeCommission,
+}
+
+// GetIncomeHistory 查询资金费和手续费流水（按类型分页拉取；币安只能查询最近 3 个月的流水）
+func (t *FuturesTrader) GetIncomeHistory(ctx context.Context, from, to time.Time) ([]IncomeRecord, error) {
+	var records []IncomeRecord
+	for binanceType, incomeType := range binanceIncomeTypes {
+		var start int64
+		if !from.IsZero() {
+			start = from.UnixMilli()
+		}
+		seen := make(map[int64]bool)
+		for {
+			svc := t.client.NewGetIncomeHistoryService().
+				IncomeType(binanceType).
+				Limit(binanceIncomePageSize)
+			if start > 0 {
+				svc = svc.StartTime(start)
+			}
+			if !to.IsZero() {
+				svc = svc.EndTime(to.UnixMilli() - 1)
+			}
+			page, err := svc.Do(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("查询%s流水失败: %w", binanceType, err)
+			}
+			added := 0
+			for _, item := range page {
+				if seen[item.TranID] {
+					continue
+				}
+				seen[item.TranID] = true
+				added++
+				records = append(records, IncomeRecord{
+					Time:   time.UnixMilli(item.Time),
+					Symbol: item.Symbol,
+					Type:   incomeType,
+					Amount: parseFloatOrZero(item.Income),
+					Asset:  item.Asset,
+				})
+			}
+			if len(page) < binanceIncomePageSize || added == 0 {
+				break
+			}
+			// 下一页从本页最后一条的时间开始（同一毫秒可能有多条，按 tranId 去重）
+			start = page[len(page)-1].Time
+		}
+	}
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Time.Before(records[j].Time) })
+	return records, nil
+}
@@ -0,0 +1,71 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func TestFuturesTraderGetIncomeHistory(t *testing.T) {
+	var fundingCalls int
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/income" {
+			http.NotFound(w, r)
+			return
+		}
+		q := r.URL.Query()
+		var items []map[string]interface{}
+		switch q.Get("incomeType") {
+		case "FUNDING_FEE":
+			fundingCalls++
+			start, _ := strconv.ParseInt(q.Get("startTime"), 10, 64)
+			// 第一页 1000 条满页；第二页从最后一条的时间开始，首条与上一页重复
+			for id := start; id < start+1000 && id <= 1001; id++ {
+				items = append(items, map[string]interface{}{
+					"symbol": "BTCUSDT", "incomeType": "FUNDING_FEE", "income": "-0.01", "asset": "USDT", "time": id, "tranId": id,
+				})
+			}
+		case "COMMISSION":
+			items = append(items, map[string]interface{}{
+				"symbol": "ETHUSDT", "incomeType": "COMMISSION", "income": "-0.5", "asset": "USDT", "time": 500, "tranId": 9999,
+			})
+		}
+		json.NewEncoder(w).Encode(items)
+	}))
+	defer server.Close()
+
+	client := futures.NewClient("test_key", "test_secret")
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+	trader := &FuturesTrader{client: client}
+
+	records, err := trader.GetIncomeHistory(context.Background(), time.UnixMilli(1), time.Time{})
+	if err != nil {
+		t.Fatalf("GetIncomeHistory() error = %v", err)
+	}
+	if fundingCalls != 2 {
+		t.Errorf("满页时应继续翻页, got %d 次请求", fundingCalls)
+	}
+	if len(records) != 1002 {
+		t.Fatalf("翻页重复的流水应去重, got %d 条", len(records))
+	}
+	for i := 1; i < len(records); i++ {
+		if records[i].Time.Before(records[i-1].Time) {
+			t.Fatalf("流水应按时间排序: %v 在 %v 之后", records[i].Time, records[i-1].Time)
+		}
+	}
+	var commission IncomeRecord
+	for _, r := range records {
+		if r.Type == IncomeCommission {
+			commission = r
+		}
+	}
+	if commission.Symbol != "ETHUSDT" || commission.Amount != -0.5 || commission.Asset != "USDT" {
+		t.Errorf("unexpected commission record: %+v", commission)
+	}
+}
@@ -0,0 +1,104 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// InstrumentPreloader 支持一次请求拉取全部合约交易规则的交易器
+type InstrumentPreloader interface {
+	// PreloadInstruments 拉取全部合约的交易规则并替换精度缓存；symbols 中有交易所未上线的币种时返回错误（缓存仍会更新）
+	PreloadInstruments(ctx context.Context, symbols []string) error
+}
+
+// InstrumentRefreshIntervalFromEnv 读取 NOFX_INSTRUMENT_REFRESH_INTERVAL（交易规则定时刷新间隔，未设置返回 0=不定时刷新）
+func InstrumentRefreshIntervalFromEnv() (time.Duration, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_INSTRUMENT_REFRESH_INTERVAL"))
+	if raw == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("NOFX_INSTRUMENT_REFRESH_INTERVAL 必须为正的时间间隔（如 1h）: %q", raw)
+	}
+	return d, nil
+}
+
+// missingInstruments 检查 symbols 是否都在精度缓存中
+func missingInstruments(precisions map[string]SymbolPrecision, symbols []string) error {
+	var missing []string
+	for _, symbol := range symbols {
+		if _, ok := precisions[symbol]; !ok {
+			missing = append(missing, symbol)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+	sort.Strings(missing)
+	return fmt.Errorf("未找到交易对 %s 的交易规则", strings.Join(missing, ", "))
+}
+
+// instrumentSymbols 需要预加载交易规则的币种（自定义币种，否则为动态币种池或系统默认币种）
+func (at *AutoTrader) instrumentSymbols() []string {
+	coins := at.tradingCoins
+	if len(coins) == 0 {
+		coins, _ = at.baseCoins()
+	}
+	symbols := make([]string, 0, len(coins))
+	for _, coin := range coins {
+		symbols = append(symbols, normalizeSymbol(coin))
+	}
+	return symbols
+}
+
+// preloadInstruments 启动时一次拉取全部交易规则，首次下单不再额外请求（已从精度快照加载时跳过）
+func (at *AutoTrader) preloadInstruments() {
+	if at.instrumentPreloader == nil || at.config.PrecisionSnapshotDir != "" {
+		return
+	}
+	symbols := at.instrumentSymbols()
+	ctx, cancel := context.WithTimeout(at.ctx(), DefaultCallTimeout)
+	defer cancel()
+	if err := at.instrumentPreloader.PreloadInstruments(ctx, symbols); err != nil {
+		log.Warn("⚠️ 预加载交易规则失败", "trader", at.name, "error", err)
+		return
+	}
+	log.Info("⚡ 已预加载交易规则", "trader", at.name, "symbol_count", len(symbols))
+}
+
+// startInstrumentRefreshMonitor 按 InstrumentRefreshInterval 定时刷新交易规则（交易所会调整最小步进等规格）
+func (at *AutoTrader) startInstrumentRefreshMonitor() {
+	interval := at.config.InstrumentRefreshInterval
+	if interval <= 0 || at.instrumentPreloader == nil {
+		return
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Info("🔄 启动交易规则定时刷新", "trader", at.name, "interval", interval)
+
+		for {
+			select {
+			case <-ticker.C:
+				ctx, cancel := context.WithTimeout(at.ctx(), DefaultCallTimeout)
+				if err := at.instrumentPreloader.PreloadInstruments(ctx, at.instrumentSymbols()); err != nil {
+					log.Warn("⚠️ 刷新交易规则失败", "trader", at.name, "error", err)
+				}
+				cancel()
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止交易规则定时刷新", "trader", at.name)
+				return
+			}
+		}
+	}()
+}
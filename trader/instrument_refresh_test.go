@@ -0,0 +1,112 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPreloadInstruments_Binance 预加载一次拉取全部交易规则，之后首次下单的格式化不再请求交易所
+func TestPreloadInstruments_Binance(t *testing.T) {
+	var calls int32
+	tickSize := atomic.Value{}
+	tickSize.Store("0.10")
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/exchangeInfo" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&calls, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbols": []map[string]interface{}{
+				{
+					"symbol": "BTCUSDT",
+					"filters": []map[string]interface{}{
+						{"filterType": "PRICE_FILTER", "tickSize": tickSize.Load()},
+						{"filterType": "LOT_SIZE", "stepSize": "0.001"},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	client := futures.NewClient("test_api_key", "test_secret_key")
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+	trader := &FuturesTrader{client: client}
+
+	err := trader.PreloadInstruments(context.Background(), []string{"BTCUSDT", "FOOUSDT"})
+	assert.EqualError(t, err, "未找到交易对 FOOUSDT 的交易规则")
+
+	price, err := trader.FormatPrice("BTCUSDT", 50123.456)
+	require.NoError(t, err)
+	assert.Equal(t, "50123.5", price)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	// 交易所调整规格后刷新替换缓存
+	tickSize.Store("1")
+	require.NoError(t, trader.PreloadInstruments(context.Background(), []string{"BTCUSDT"}))
+	price, err = trader.FormatPrice("BTCUSDT", 50123.456)
+	require.NoError(t, err)
+	assert.Equal(t, "50123", price)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 不在交易规则中的交易对：直接以最近一次刷新的结果为准，不再逐次拉取
+	for i := 0; i < 3; i++ {
+		_, err = trader.FormatPrice("FOOUSDT", 1.2345)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	// 超过有效期后未命中才重新拉取
+	trader.precisionRefreshedAt = time.Now().Add(-missingPrecisionTTL)
+	_, err = trader.FormatPrice("FOOUSDT", 1.2345)
+	assert.Error(t, err)
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+// TestPreloadInstruments_AsterKeepsCacheOnError 请求失败时保留原有缓存
+func TestPreloadInstruments_AsterKeepsCacheOnError(t *testing.T) {
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte(`{"code":-1001,"msg":"Internal error"}`))
+	}))
+	defer server.Close()
+
+	trader := &AsterTrader{
+		client:          server.Client(),
+		baseURL:         server.URL,
+		symbolPrecision: map[string]SymbolPrecision{"BTCUSDT": {TickSize: 0.1, StepSize: 0.001}},
+	}
+	assert.Error(t, trader.PreloadInstruments(context.Background(), []string{"BTCUSDT"}))
+
+	price, err := trader.formatPrice("BTCUSDT", 50123.456)
+	require.NoError(t, err)
+	assert.InDelta(t, 50123.5, price, 1e-9)
+}
+
+func TestInstrumentRefreshIntervalFromEnv(t *testing.T) {
+	t.Setenv("NOFX_INSTRUMENT_REFRESH_INTERVAL", "")
+	d, err := InstrumentRefreshIntervalFromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, d)
+
+	t.Setenv("NOFX_INSTRUMENT_REFRESH_INTERVAL", "6h")
+	d, err = InstrumentRefreshIntervalFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Hour, d)
+
+	for _, raw := range []string{"6", "-1h", "0s"} {
+		t.Setenv("NOFX_INSTRUMENT_REFRESH_INTERVAL", raw)
+		_, err = InstrumentRefreshIntervalFromEnv()
+		assert.Error(t, err, raw)
+	}
+}
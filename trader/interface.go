@@ -1,59 +1,71 @@
 package trader
 
-import "nofx/decision"
+import (
+	"context"
+	"nofx/decision"
+	"nofx/market"
+)
 
 // Trader 交易器统一接口
 // 支持多个交易平台（币安、Hyperliquid等）
+// 访问交易所的方法都接收 ctx：ctx 取消或超时时请求立即中止
+// （FormatQuantity 只在精度缓存未命中时请求交易所，使用默认超时）
 type Trader interface {
 	// GetBalance 获取账户余额
-	GetBalance() (map[string]interface{}, error)
+	GetBalance(ctx context.Context) (map[string]interface{}, error)
 
 	// GetPositions 获取所有持仓
-	GetPositions() ([]map[string]interface{}, error)
+	GetPositions(ctx context.Context) ([]map[string]interface{}, error)
 
-	// OpenLong 开多仓
-	OpenLong(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	// OpenLong 开多仓，返回订单执行报告
+	OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error)
 
-	// OpenShort 开空仓
-	OpenShort(symbol string, quantity float64, leverage int) (map[string]interface{}, error)
+	// OpenShort 开空仓，返回订单执行报告
+	OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error)
 
-	// CloseLong 平多仓（quantity=0表示全部平仓）
-	CloseLong(symbol string, quantity float64) (map[string]interface{}, error)
+	// CloseLong 平多仓（quantity=0表示全部平仓），返回订单执行报告
+	CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error)
 
-	// CloseShort 平空仓（quantity=0表示全部平仓）
-	CloseShort(symbol string, quantity float64) (map[string]interface{}, error)
+	// CloseShort 平空仓（quantity=0表示全部平仓），返回订单执行报告
+	CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error)
 
 	// SetLeverage 设置杠杆
-	SetLeverage(symbol string, leverage int) error
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
 
 	// SetMarginMode 设置仓位模式 (true=全仓, false=逐仓)
-	SetMarginMode(symbol string, isCrossMargin bool) error
+	SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error
 
 	// GetMarketPrice 获取市场价格
-	GetMarketPrice(symbol string) (float64, error)
+	GetMarketPrice(ctx context.Context, symbol string) (float64, error)
+
+	// GetFundingRate 获取当前（预测）资金费率
+	GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error)
+
+	// GetFundingRateHistory 获取最近N次已结算资金费率（按时间正序）
+	GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error)
 
 	// SetStopLoss 设置止损单
-	SetStopLoss(symbol string, positionSide string, quantity, stopPrice float64) error
+	SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error
 
 	// SetTakeProfit 设置止盈单
-	SetTakeProfit(symbol string, positionSide string, quantity, takeProfitPrice float64) error
+	SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error
 
 	// CancelStopLossOrders 仅取消止损单（修复 BUG：调整止损时不删除止盈）
-	CancelStopLossOrders(symbol string) error
+	CancelStopLossOrders(ctx context.Context, symbol string) error
 
 	// CancelTakeProfitOrders 仅取消止盈单（修复 BUG：调整止盈时不删除止损）
-	CancelTakeProfitOrders(symbol string) error
+	CancelTakeProfitOrders(ctx context.Context, symbol string) error
 
 	// CancelAllOrders 取消该币种的所有挂单
-	CancelAllOrders(symbol string) error
+	CancelAllOrders(ctx context.Context, symbol string) error
 
 	// CancelStopOrders 取消该币种的止盈/止损单（用于调整止盈止损位置）
-	CancelStopOrders(symbol string) error
+	CancelStopOrders(ctx context.Context, symbol string) error
 
 	// FormatQuantity 格式化数量到正确的精度
 	FormatQuantity(symbol string, quantity float64) (string, error)
 
 	// GetOpenOrders retrieves open orders for AI decision context
 	// Returns all orders if symbol is empty, otherwise returns orders for the specified symbol
-	GetOpenOrders(symbol string) ([]decision.OpenOrderInfo, error)
+	GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error)
 }
@@ -0,0 +1,183 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"nofx/analytics"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// journalTrader 记录交易事件的 Trader 装饰器：下单请求/响应、成交、止盈止损设置和错误写入事件日志
+type journalTrader struct {
+	Trader
+	journal  *store.Journal
+	traderID string
+}
+
+// newJournalTrader 为 trader 包装事件日志
+func newJournalTrader(t Trader, journal *store.Journal, traderID string) *journalTrader {
+	return &journalTrader{Trader: t, journal: journal, traderID: traderID}
+}
+
+// OpenLong 开多仓并记录事件
+func (t *journalTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	return t.order(ctx, "open", symbol, "long", quantity, leverage, func() (*ExecutionReport, error) {
+		return t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+	})
+}
+
+// OpenShort 开空仓并记录事件
+func (t *journalTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	return t.order(ctx, "open", symbol, "short", quantity, leverage, func() (*ExecutionReport, error) {
+		return t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+	})
+}
+
+// CloseLong 平多仓并记录事件
+func (t *journalTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	return t.order(ctx, "close", symbol, "long", quantity, 0, func() (*ExecutionReport, error) {
+		return t.Trader.CloseLong(ctx, symbol, quantity)
+	})
+}
+
+// CloseShort 平空仓并记录事件
+func (t *journalTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	return t.order(ctx, "close", symbol, "short", quantity, 0, func() (*ExecutionReport, error) {
+		return t.Trader.CloseShort(ctx, symbol, quantity)
+	})
+}
+
+// SetStopLoss 设置止损单并记录事件
+func (t *journalTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	return t.protection("stop_loss", symbol, positionSide, quantity, stopPrice, func() error {
+		return t.Trader.SetStopLoss(ctx, symbol, positionSide, quantity, stopPrice)
+	})
+}
+
+// SetTakeProfit 设置止盈单并记录事件
+func (t *journalTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	return t.protection("take_profit", symbol, positionSide, quantity, takeProfitPrice, func() error {
+		return t.Trader.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice)
+	})
+}
+
+// order 记录下单请求、执行报告、成交或错误（下单 context 中的策略标签一并记录）
+func (t *journalTrader) order(ctx context.Context, action, symbol, side string, quantity float64, leverage int, submit func() (*ExecutionReport, error)) (*ExecutionReport, error) {
+	tag := OrderTag(ctx)
+	t.record(store.EventOrderRequest, symbol, side, store.OrderRequest{Action: action, Quantity: quantity, Leverage: leverage, Tag: tag})
+
+	report, err := submit()
+	if err != nil {
+		t.record(store.EventError, symbol, side, store.ErrorInfo{Operation: action + "_" + side, Message: err.Error()})
+		return report, err
+	}
+	t.record(store.EventOrderResponse, symbol, side, report)
+
+	fill := store.Fill{Action: action, Quantity: quantity, Full: action == "close" && quantity == 0, Tag: tag}
+	if report != nil {
+		fill.OrderID, fill.Fee, fill.Price = report.OrderID, report.Fee, report.AvgPrice
+		if report.IsFilled() {
+			fill.Quantity = report.FilledQty
+		}
+	}
+	t.record(store.EventFill, symbol, side, fill)
+	return report, nil
+}
+
+// protection 记录止盈止损设置结果
+func (t *journalTrader) protection(kind, symbol, positionSide string, quantity, price float64, place func() error) error {
+	side := strings.ToLower(positionSide)
+	if err := place(); err != nil {
+		t.record(store.EventError, symbol, side, store.ErrorInfo{Operation: "set_" + kind, Message: err.Error()})
+		return err
+	}
+	t.record(store.EventProtection, symbol, side, store.Protection{Kind: kind, Quantity: quantity, Price: price})
+	return nil
+}
+
+// record 写入事件（事件日志故障不影响交易）
+func (t *journalTrader) record(eventType, symbol, side string, payload interface{}) {
+	if _, err := t.journal.Record(t.traderID, eventType, symbol, side, payload); err != nil {
+		log.Warn("⚠️ 写入交易事件日志失败", "event_type", eventType, "symbol", symbol, "error", err)
+	}
+}
+
+// recordDecision 记录一条决策（AI 或外部策略）
+func (at *AutoTrader) recordDecision(d *decision.Decision) {
+	if at.journal == nil {
+		return
+	}
+	side := ""
+	switch {
+	case strings.HasSuffix(d.Action, "_long"):
+		side = "long"
+	case strings.HasSuffix(d.Action, "_short"):
+		side = "short"
+	}
+	if _, err := at.journal.Record(at.id, store.EventDecision, d.Symbol, side, d); err != nil {
+		log.Warn("⚠️ 写入决策事件失败", "symbol", d.Symbol, "action", d.Action, "error", err)
+	}
+}
+
+// recordDecisionError 记录决策执行失败
+func (at *AutoTrader) recordDecisionError(d *decision.Decision, err error) {
+	if at.journal == nil {
+		return
+	}
+	if _, jerr := at.journal.Record(at.id, store.EventError, d.Symbol, "", store.ErrorInfo{Operation: d.Action, Message: err.Error()}); jerr != nil {
+		log.Warn("⚠️ 写入错误事件失败", "symbol", d.Symbol, "action", d.Action, "error", jerr)
+	}
+}
+
+// recordPassiveClose 记录交易所侧触发的平仓（止损/止盈/强平/手动），使事件日志中的持仓状态与交易所一致
+func (at *AutoTrader) recordPassiveClose(pos decision.PositionInfo, action logger.DecisionAction) {
+	if at.journal == nil {
+		return
+	}
+	fill := store.Fill{Action: "close", Quantity: pos.Quantity, Price: action.Price, Full: true, Reason: action.Error}
+	if _, err := at.journal.Record(at.id, store.EventFill, pos.Symbol, pos.Side, fill); err != nil {
+		log.Warn("⚠️ 写入被动平仓事件失败", "symbol", pos.Symbol, "side", pos.Side, "error", err)
+	}
+}
+
+// restoreFromJournal 启动时由事件日志重建持仓的止损/止盈价格和开仓时间（崩溃恢复）
+func (at *AutoTrader) restoreFromJournal() {
+	positions, err := at.journal.Positions(at.id)
+	if err != nil {
+		log.Warn("⚠️ 从事件日志重建持仓状态失败", "trader", at.name, "error", err)
+		return
+	}
+	for key, pos := range positions {
+		at.positionFirstSeenTime[key] = pos.OpenedAt.UnixMilli()
+		if pos.StopLoss > 0 {
+			at.positionStopLoss[key] = pos.StopLoss
+		}
+		if pos.TakeProfit > 0 {
+			at.positionTakeProfit[key] = pos.TakeProfit
+		}
+		at.restoreScaleOut(key, pos)
+	}
+	if len(positions) > 0 {
+		log.Info("📒 已从事件日志恢复持仓的状态", "trader", at.name, "position_count", len(positions))
+	}
+}
+
+// GetTradeAnalytics 基于交易事件日志的交易表现统计（未启用事件日志时返回错误）
+func (at *AutoTrader) GetTradeAnalytics(filter analytics.Filter) (*analytics.Report, error) {
+	if at.journal == nil {
+		return nil, fmt.Errorf("交易事件日志未启用（设置 NOFX_JOURNAL_DB）")
+	}
+	return analytics.FromJournal(at.journal, at.id, filter)
+}
+
+// GetBuyAndHoldBenchmark 对比 [since, until) 区间的策略收益与同期买入持有 symbols 的收益（价格取自本地K线缓存；未启用事件日志时返回错误）
+func (at *AutoTrader) GetBuyAndHoldBenchmark(symbols []string, since, until time.Time) (*analytics.Benchmark, error) {
+	if at.journal == nil {
+		return nil, fmt.Errorf("交易事件日志未启用（设置 NOFX_JOURNAL_DB）")
+	}
+	return analytics.BenchmarkFromJournal(at.journal, at.id, at.initialBalance, symbols, since, until, analytics.CachedPrices("binance"))
+}
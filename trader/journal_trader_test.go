@@ -0,0 +1,145 @@
+package trader
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"nofx/store"
+)
+
+func TestJournalTraderRecordsOrderLifecycle(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+
+	mock := &MockTrader{shouldFailCloseShort: true}
+	jt := newJournalTrader(mock, journal, "t1")
+
+	if _, err := jt.OpenLong(context.Background(), "BTCUSDT", 0.2, 5); err != nil {
+		t.Fatalf("OpenLong: %v", err)
+	}
+	if err := jt.SetStopLoss(context.Background(), "BTCUSDT", "LONG", 0.2, 48000); err != nil {
+		t.Fatalf("SetStopLoss: %v", err)
+	}
+	if _, err := jt.CloseShort(context.Background(), "ETHUSDT", 0); err == nil {
+		t.Fatal("expected CloseShort to fail")
+	}
+
+	events, err := journal.Events(store.EventFilter{TraderID: "t1"})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	want := []string{store.EventOrderRequest, store.EventOrderResponse, store.EventFill, store.EventProtection, store.EventOrderRequest, store.EventError}
+	if len(events) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(events), len(want), events)
+	}
+	for i, e := range events {
+		if e.Type != want[i] {
+			t.Errorf("event %d type = %s, want %s", i, e.Type, want[i])
+		}
+	}
+	if events[3].Side != "long" {
+		t.Errorf("protection side = %q, want long", events[3].Side)
+	}
+
+	positions, err := journal.Positions("t1")
+	if err != nil {
+		t.Fatalf("Positions: %v", err)
+	}
+	pos := positions["BTCUSDT_long"]
+	if pos == nil || pos.Quantity != 0.2 || pos.StopLoss != 48000 {
+		t.Fatalf("unexpected rebuilt position: %+v", pos)
+	}
+}
+
+func TestJournalTraderRecordsOrderTag(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+
+	jt := newJournalTrader(&MockTrader{}, journal, "t1")
+	if _, err := jt.OpenLong(WithOrderTag(context.Background(), "ema_cross"), "BTCUSDT", 0.2, 5); err != nil {
+		t.Fatalf("OpenLong: %v", err)
+	}
+	if _, err := jt.CloseLong(context.Background(), "BTCUSDT", 0); err != nil {
+		t.Fatalf("CloseLong: %v", err)
+	}
+
+	events, err := journal.Events(store.EventFilter{TraderID: "t1", Types: []string{store.EventOrderRequest, store.EventFill}})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(events) != 4 {
+		t.Fatalf("got %d events, want 4: %+v", len(events), events)
+	}
+	var req store.OrderRequest
+	if err := events[0].Decode(&req); err != nil || req.Tag != "ema_cross" {
+		t.Errorf("order request tag = %q (%v), want ema_cross", req.Tag, err)
+	}
+	var open, closeFill store.Fill
+	if err := events[1].Decode(&open); err != nil || open.Tag != "ema_cross" {
+		t.Errorf("open fill tag = %q (%v), want ema_cross", open.Tag, err)
+	}
+	if err := events[3].Decode(&closeFill); err != nil || closeFill.Tag != "" {
+		t.Errorf("untagged close fill tag = %q (%v)", closeFill.Tag, err)
+	}
+}
+
+func TestRestoreFromJournal(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 50000})
+	journal.Record("t1", store.EventProtection, "BTCUSDT", "long", store.Protection{Kind: "stop_loss", Price: 48000})
+	journal.Record("t1", store.EventProtection, "BTCUSDT", "long", store.Protection{Kind: "take_profit", Price: 55000})
+
+	at := &AutoTrader{
+		id:                    "t1",
+		name:                  "test",
+		journal:               journal,
+		positionFirstSeenTime: make(map[string]int64),
+		positionStopLoss:      make(map[string]float64),
+		positionTakeProfit:    make(map[string]float64),
+	}
+	at.restoreFromJournal()
+
+	if at.positionStopLoss["BTCUSDT_long"] != 48000 || at.positionTakeProfit["BTCUSDT_long"] != 55000 {
+		t.Errorf("stop loss/take profit not restored: %v %v", at.positionStopLoss, at.positionTakeProfit)
+	}
+	if at.positionFirstSeenTime["BTCUSDT_long"] == 0 {
+		t.Error("position open time not restored")
+	}
+}
+
+func TestNotifyRecordsRiskEvents(t *testing.T) {
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer journal.Close()
+
+	at := &AutoTrader{id: "t1", name: "test", journal: journal}
+	at.notify(AlertSeverityInfo, "人工恢复", "不写入事件日志")
+	at.activateRiskStop("触发当日最大亏损 5.00%")
+
+	events, err := journal.Events(store.EventFilter{TraderID: "t1", Types: []string{store.EventRisk}})
+	if err != nil {
+		t.Fatalf("Events: %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d risk events, want 1", len(events))
+	}
+	var risk store.RiskEvent
+	events[0].Decode(&risk)
+	if risk.Severity != AlertSeverityWarning || risk.Title != "触发风险暂停" || !strings.HasPrefix(risk.Message, "触发当日最大亏损 5.00%") {
+		t.Errorf("unexpected risk event: %+v", risk)
+	}
+}
@@ -0,0 +1,143 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"nofx/eventbus"
+	"nofx/store"
+	"sync"
+	"time"
+)
+
+const (
+	// leverageCacheTTL 杠杆缓存有效期（用户可能在交易所页面手动修改杠杆，过期后重新设置一次）
+	leverageCacheTTL = 30 * time.Minute
+	// leverageCooldownWait 交易所返回杠杆调整冷却错误后的等待时间
+	leverageCooldownWait = 5 * time.Second
+)
+
+// leverageTrader 缓存每个币种当前杠杆的 Trader 装饰器，开仓前按开仓杠杆设置杠杆
+// 杠杆已是目标值时不再请求交易所；只有交易所返回冷却错误时才等待后重试一次；
+// 杠杆调整后发布 LeverageChanged 事件并写入交易事件日志
+type leverageTrader struct {
+	Trader
+	exchange string
+	traderID string
+	journal  *store.Journal // nil=不写事件日志
+	cooldown time.Duration  // 冷却错误后的等待时间
+
+	mu      sync.Mutex
+	symbols map[string]leverageEntry
+}
+
+// leverageEntry 单个币种已知的杠杆
+type leverageEntry struct {
+	leverage  int
+	updatedAt time.Time
+}
+
+// newLeverageTrader 为 trader 包装杠杆缓存
+func newLeverageTrader(t Trader, exchange, traderID string, journal *store.Journal) *leverageTrader {
+	return &leverageTrader{
+		Trader:   t,
+		exchange: exchange,
+		traderID: traderID,
+		journal:  journal,
+		cooldown: leverageCooldownWait,
+		symbols:  make(map[string]leverageEntry),
+	}
+}
+
+// cached 币种已知的杠杆（未知或已过期时返回 0）
+func (t *leverageTrader) cached(symbol string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	entry, ok := t.symbols[symbol]
+	if !ok || time.Since(entry.updatedAt) >= leverageCacheTTL {
+		return 0
+	}
+	return entry.leverage
+}
+
+func (t *leverageTrader) remember(symbol string, leverage int) {
+	t.mu.Lock()
+	t.symbols[symbol] = leverageEntry{leverage: leverage, updatedAt: time.Now()}
+	t.mu.Unlock()
+}
+
+// observe 从持仓信息更新已知杠杆
+func (t *leverageTrader) observe(positions []map[string]interface{}) {
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		if lev, ok := pos["leverage"].(float64); ok && symbol != "" && lev > 0 {
+			t.remember(symbol, int(lev))
+		}
+	}
+}
+
+// SetLeverage 设置杠杆（已是目标杠杆时跳过）
+func (t *leverageTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	from := t.cached(symbol)
+	if from > 0 && from == leverage {
+		log.Info("  ✓ 杠杆无需切换", "symbol", symbol, "leverage", leverage)
+		return nil
+	}
+
+	err := t.Trader.SetLeverage(ctx, symbol, leverage)
+	if errors.Is(err, ErrLeverageCooldown) {
+		log.Info("  ⏱ 杠杆调整冷却中，等待后重试...", "symbol", symbol, "cooldown", t.cooldown)
+		if err := sleepContext(ctx, t.cooldown); err != nil {
+			return err
+		}
+		err = t.Trader.SetLeverage(ctx, symbol, leverage)
+	}
+	if err != nil {
+		return err
+	}
+
+	t.remember(symbol, leverage)
+	t.publish(symbol, from, leverage)
+	return nil
+}
+
+// OpenLong 设置杠杆后开多仓
+func (t *leverageTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	if err := t.SetLeverage(ctx, symbol, leverage); err != nil {
+		return nil, err
+	}
+	return t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+}
+
+// OpenShort 设置杠杆后开空仓
+func (t *leverageTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	if err := t.SetLeverage(ctx, symbol, leverage); err != nil {
+		return nil, err
+	}
+	return t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+}
+
+// publish 发布杠杆调整事件并写入事件日志
+func (t *leverageTrader) publish(symbol string, from, to int) {
+	eventbus.Publish(eventbus.LeverageChanged{
+		TraderID: t.traderID,
+		Exchange: t.exchange,
+		Symbol:   symbol,
+		From:     from,
+		To:       to,
+		Time:     time.Now(),
+	})
+	if t.journal == nil {
+		return
+	}
+	if _, err := t.journal.Record(t.traderID, store.EventLeverage, symbol, "", store.LeverageChange{From: from, To: to}); err != nil {
+		log.Warn("⚠️ 写入交易事件日志失败", "event_type", store.EventLeverage, "symbol", symbol, "error", err)
+	}
+}
+
+func (t *leverageTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	positions, err := t.Trader.GetPositions(ctx)
+	if err == nil {
+		t.observe(positions)
+	}
+	return positions, err
+}
@@ -0,0 +1,124 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"nofx/eventbus"
+	"nofx/store"
+)
+
+// countingLeverageTrader 记录 SetLeverage 调用次数，前 cooldowns 次返回冷却错误
+type countingLeverageTrader struct {
+	*MockTrader
+	calls     int
+	cooldowns int
+}
+
+func (t *countingLeverageTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	t.calls++
+	if t.calls <= t.cooldowns {
+		return ErrLeverageCooldown
+	}
+	return nil
+}
+
+func TestLeverageTraderCachesAndPublishes(t *testing.T) {
+	orig := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = orig }()
+	events, unsubscribe := eventbus.Subscribe(8, eventbus.TopicLeverageChanged)
+	defer unsubscribe()
+
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	inner := &countingLeverageTrader{MockTrader: &MockTrader{
+		positions: []map[string]interface{}{{"symbol": "ETHUSDT", "side": "long", "leverage": 3.0}},
+	}, cooldowns: 1}
+	lt := newLeverageTrader(inner, "binance", "t1", journal)
+	lt.cooldown = time.Millisecond
+	ctx := context.Background()
+
+	// 冷却错误：等待后重试一次
+	if err := lt.SetLeverage(ctx, "BTCUSDT", 5); err != nil {
+		t.Fatalf("SetLeverage: %v", err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("calls=%d，冷却错误应重试一次", inner.calls)
+	}
+	// 已是目标杠杆：不请求交易所，不发布事件
+	if err := lt.SetLeverage(ctx, "BTCUSDT", 5); err != nil || inner.calls != 2 {
+		t.Fatalf("缓存命中不应请求交易所: calls=%d err=%v", inner.calls, err)
+	}
+
+	// 持仓中的杠杆也会更新缓存
+	if _, err := lt.GetPositions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := lt.SetLeverage(ctx, "ETHUSDT", 3); err != nil || inner.calls != 2 {
+		t.Fatalf("持仓杠杆已是目标值: calls=%d err=%v", inner.calls, err)
+	}
+	if err := lt.SetLeverage(ctx, "ETHUSDT", 10); err != nil || inner.calls != 3 {
+		t.Fatalf("calls=%d err=%v", inner.calls, err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+	first := (<-events).(eventbus.LeverageChanged)
+	second := (<-events).(eventbus.LeverageChanged)
+	if first.Symbol != "BTCUSDT" || first.From != 0 || first.To != 5 || first.TraderID != "t1" {
+		t.Errorf("unexpected event: %+v", first)
+	}
+	if second.Symbol != "ETHUSDT" || second.From != 3 || second.To != 10 {
+		t.Errorf("unexpected event: %+v", second)
+	}
+
+	recorded, err := journal.Events(store.EventFilter{TraderID: "t1", Types: []string{store.EventLeverage}})
+	if err != nil || len(recorded) != 2 {
+		t.Fatalf("journal events=%v err=%v", recorded, err)
+	}
+	var change store.LeverageChange
+	if err := recorded[1].Decode(&change); err != nil || change.From != 3 || change.To != 10 {
+		t.Errorf("change=%+v err=%v", change, err)
+	}
+}
+
+func TestLeverageTraderSetsLeverageBeforeOpen(t *testing.T) {
+	orig := eventbus.Default
+	eventbus.Default = eventbus.New()
+	defer func() { eventbus.Default = orig }()
+
+	inner := &countingLeverageTrader{MockTrader: &MockTrader{}, cooldowns: 1}
+	lt := newLeverageTrader(inner, "binance", "t1", nil)
+	lt.cooldown = time.Millisecond
+	var tr Trader = lt
+	ctx := context.Background()
+
+	// 首次开仓：设置杠杆遇到冷却错误，等待后重试一次再下单
+	if report, err := tr.OpenLong(ctx, "BTCUSDT", 0.1, 5); err != nil || report == nil {
+		t.Fatalf("OpenLong: report=%v err=%v", report, err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("calls=%d，冷却错误应重试一次", inner.calls)
+	}
+	// 相同杠杆再次开仓：不再请求交易所
+	if _, err := tr.OpenShort(ctx, "BTCUSDT", 0.1, 5); err != nil || inner.calls != 2 {
+		t.Fatalf("杠杆未变不应请求交易所: calls=%d err=%v", inner.calls, err)
+	}
+	// 杠杆变化：重新设置
+	if _, err := tr.OpenLong(ctx, "BTCUSDT", 0.1, 10); err != nil || inner.calls != 3 {
+		t.Fatalf("calls=%d err=%v", inner.calls, err)
+	}
+
+	// 冷却重试仍失败时不下单
+	inner.calls, inner.cooldowns = 0, 2
+	if _, err := tr.OpenLong(ctx, "ETHUSDT", 1, 3); !errors.Is(err, ErrLeverageCooldown) || inner.calls != 2 {
+		t.Errorf("calls=%d err=%v，杠杆设置失败应放弃开仓", inner.calls, err)
+	}
+}
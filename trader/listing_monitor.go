@@ -0,0 +1,232 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/market"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDelistFlattenBefore 默认在下架前多久平掉持仓
+const defaultDelistFlattenBefore = 24 * time.Hour
+
+// ListingWatch 合约上架/下架监控：定时查询交易所合约列表，新上架时告警，
+// 计划下架的币种禁止开新仓，并在下架前告警或平掉已有持仓
+type ListingWatch struct {
+	Interval      time.Duration // 查询间隔
+	FlattenBefore time.Duration // 下架前多久平掉持仓（0=只告警不平仓）
+	Source        string        // 数据源注册名（为空时与交易所同名，交易所没有对应数据源时为 binance）
+}
+
+// ListingWatchFromEnv 读取合约上架/下架监控配置（NOFX_LISTING_WATCH_INTERVAL 未设置时返回 nil）
+func ListingWatchFromEnv() (*ListingWatch, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_LISTING_WATCH_INTERVAL"))
+	if raw == "" {
+		return nil, nil
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval <= 0 {
+		return nil, fmt.Errorf("NOFX_LISTING_WATCH_INTERVAL 必须为正的时间间隔（如 1h）: %q", raw)
+	}
+	w := &ListingWatch{
+		Interval:      interval,
+		FlattenBefore: defaultDelistFlattenBefore,
+		Source:        strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_LISTING_SOURCE"))),
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_DELIST_FLATTEN_BEFORE")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			return nil, fmt.Errorf("NOFX_DELIST_FLATTEN_BEFORE 必须为非负的时间间隔（如 24h，0=只告警）: %q", v)
+		}
+		w.FlattenBefore = d
+	}
+	return w, nil
+}
+
+// listingState 最近一次查询到的合约列表和已发送的下架告警
+type listingState struct {
+	mu       sync.Mutex
+	listings map[string]market.InstrumentListing // nil=尚未查询成功
+	alerted  map[string]time.Time                // 已告警持仓的币种 -> 下架时间
+}
+
+// listingProviderFor 监控使用的数据源
+func (at *AutoTrader) listingProviderFor(cfg *ListingWatch) (market.ListingProvider, error) {
+	name := cfg.Source
+	if name == "" {
+		name = "binance"
+		for _, registered := range market.RegisteredDataSources() {
+			if registered == strings.ToLower(at.exchange) {
+				name = registered
+			}
+		}
+	}
+	source, err := market.NewDataSourceByName(name)
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := source.(market.ListingProvider)
+	if !ok {
+		return nil, fmt.Errorf("数据源 %s 不支持查询合约上架/下架状态", source.GetName())
+	}
+	return provider, nil
+}
+
+// startListingMonitor 按 ListingWatch.Interval 定时检查合约上架/下架（启动时先检查一次）
+func (at *AutoTrader) startListingMonitor() {
+	cfg := at.config.ListingWatch
+	if cfg == nil || cfg.Interval <= 0 {
+		return
+	}
+	if at.listingProvider == nil {
+		provider, err := at.listingProviderFor(cfg)
+		if err != nil {
+			log.Warn("⚠️ 无法启动合约上架/下架监控", "trader", at.name, "error", err)
+			return
+		}
+		at.listingProvider = provider
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(cfg.Interval)
+		defer ticker.Stop()
+
+		log.Info("📑 启动合约上架/下架监控", "trader", at.name, "interval", cfg.Interval)
+		at.checkListings(time.Now())
+
+		for {
+			select {
+			case <-ticker.C:
+				at.checkListings(time.Now())
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止合约上架/下架监控", "trader", at.name)
+				return
+			}
+		}
+	}()
+}
+
+// checkListings 查询合约列表，对新上架和新出现下架计划的合约告警，再处理下架币种的持仓
+func (at *AutoTrader) checkListings(now time.Time) {
+	listings, err := at.listingProvider.GetSwapListings(at.ctx())
+	if err != nil {
+		log.Warn("⚠️ 查询合约上架/下架状态失败", "trader", at.name, "error", err)
+		return
+	}
+
+	at.listingState.mu.Lock()
+	changes := market.DiffListings(at.listingState.listings, listings)
+	at.listingState.listings = make(map[string]market.InstrumentListing, len(listings))
+	for _, l := range listings {
+		at.listingState.listings[l.Symbol] = l
+	}
+	at.listingState.mu.Unlock()
+
+	for _, l := range changes.Listed {
+		at.notify(AlertSeverityInfo, "新合约上架", "%s 已上架（状态: %s）", l.Symbol, l.State)
+	}
+	for _, l := range changes.Delisting {
+		at.notify(AlertSeverityWarning, "合约计划下架", "%s 将于 %s 下架，已禁止开新仓",
+			l.Symbol, l.DelistTime.UTC().Format("2006-01-02 15:04 UTC"))
+	}
+	if len(changes.Removed) > 0 {
+		log.Info("📑 合约已从交易所移除", "trader", at.name, "symbols", strings.Join(changes.Removed, ", "))
+	}
+	at.handleDelistingPositions(now)
+}
+
+// delistTime 币种的计划下架时间（没有下架计划为零值）
+func (at *AutoTrader) delistTime(symbol string) time.Time {
+	at.listingState.mu.Lock()
+	defer at.listingState.mu.Unlock()
+	return at.listingState.listings[symbol].DelistTime
+}
+
+// excludeDelisting 从候选币种中去掉计划下架的币种
+func (at *AutoTrader) excludeDelisting(coins []decision.CandidateCoin) []decision.CandidateCoin {
+	if at.config.ListingWatch == nil {
+		return coins
+	}
+	kept := coins[:0]
+	var excluded []string
+	for _, coin := range coins {
+		if at.delistTime(coin.Symbol).IsZero() {
+			kept = append(kept, coin)
+		} else {
+			excluded = append(excluded, coin.Symbol)
+		}
+	}
+	if len(excluded) > 0 {
+		log.Info("📑 计划下架的币种不参与开仓", "trader", at.name, "symbols", strings.Join(excluded, ", "))
+	}
+	return kept
+}
+
+// checkDelistingEntry 计划下架的币种拒绝开新仓
+func (at *AutoTrader) checkDelistingEntry(symbol string) error {
+	if at.config.ListingWatch == nil {
+		return nil
+	}
+	if t := at.delistTime(symbol); !t.IsZero() {
+		return fmt.Errorf("%s 计划于 %s 下架，拒绝开仓", symbol, t.UTC().Format("2006-01-02 15:04 UTC"))
+	}
+	return nil
+}
+
+// handleDelistingPositions 计划下架币种的持仓：进入 FlattenBefore 窗口时平仓，否则告警一次
+func (at *AutoTrader) handleDelistingPositions(now time.Time) {
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		log.Warn("⚠️ 下架检查：获取持仓失败", "trader", at.name, "error", err)
+		return
+	}
+	flattenBefore := at.config.ListingWatch.FlattenBefore
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		if amt, _ := pos["positionAmt"].(float64); amt == 0 || symbol == "" {
+			continue
+		}
+		delist := at.delistTime(symbol)
+		if delist.IsZero() {
+			continue
+		}
+		when := delist.UTC().Format("2006-01-02 15:04 UTC")
+
+		if flattenBefore <= 0 || now.Before(delist.Add(-flattenBefore)) {
+			at.listingState.mu.Lock()
+			alerted := at.listingState.alerted[symbol].Equal(delist)
+			if !alerted {
+				if at.listingState.alerted == nil {
+					at.listingState.alerted = make(map[string]time.Time)
+				}
+				at.listingState.alerted[symbol] = delist
+			}
+			at.listingState.mu.Unlock()
+			if !alerted {
+				at.notify(AlertSeverityWarning, "持仓合约计划下架", "%s %s 持仓所在合约将于 %s 下架，请在下架前处理", symbol, side, when)
+			}
+			continue
+		}
+
+		unlock := at.lockSymbol(symbol)
+		ctx := WithOrderTag(at.ctx(), "delist")
+		if side == "short" {
+			_, err = at.trader.CloseShort(ctx, symbol, 0) // 0 = 全部平仓
+		} else {
+			_, err = at.trader.CloseLong(ctx, symbol, 0)
+		}
+		unlock()
+		if err != nil {
+			at.notify(AlertSeverityCritical, "下架前平仓失败", "%s %s 合约将于 %s 下架，平仓失败: %v", symbol, side, when, err)
+			continue
+		}
+		at.notify(AlertSeverityWarning, "下架前平仓", "%s %s 合约将于 %s 下架，已平仓", symbol, side, when)
+	}
+}
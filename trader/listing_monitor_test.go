@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"nofx/decision"
+	"nofx/market"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// staticListings 返回固定合约列表的上架/下架数据源
+type staticListings []market.InstrumentListing
+
+func (s *staticListings) GetSwapListings(ctx context.Context) ([]market.InstrumentListing, error) {
+	return *s, nil
+}
+
+func TestListingWatchFromEnv(t *testing.T) {
+	w, err := ListingWatchFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, w, "未设置时不监控")
+
+	t.Setenv("NOFX_LISTING_WATCH_INTERVAL", "30m")
+	w, err = ListingWatchFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, &ListingWatch{Interval: 30 * time.Minute, FlattenBefore: 24 * time.Hour}, w)
+
+	t.Setenv("NOFX_DELIST_FLATTEN_BEFORE", "0")
+	w, err = ListingWatchFromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, w.FlattenBefore)
+
+	t.Setenv("NOFX_DELIST_FLATTEN_BEFORE", "soon")
+	_, err = ListingWatchFromEnv()
+	assert.Error(t, err)
+}
+
+func TestCheckListingsBlocksEntriesAndFlattens(t *testing.T) {
+	now := time.Date(2024, 5, 30, 12, 0, 0, 0, time.UTC)
+	soon := now.Add(12 * time.Hour)
+	later := now.Add(7 * 24 * time.Hour)
+
+	mock := &flattenRecordingTrader{MockTrader: MockTrader{positions: []map[string]interface{}{
+		{"symbol": "OLDUSDT", "side": "long", "positionAmt": 1.0},
+		{"symbol": "LATEUSDT", "side": "short", "positionAmt": -2.0},
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.1},
+	}}}
+	var alerts []Alert
+	listings := staticListings{
+		{Symbol: "BTCUSDT"},
+		{Symbol: "OLDUSDT", DelistTime: soon},
+		{Symbol: "LATEUSDT", DelistTime: later},
+	}
+	at := &AutoTrader{trader: mock, listingProvider: &listings, config: AutoTraderConfig{
+		ListingWatch: &ListingWatch{Interval: time.Hour, FlattenBefore: 24 * time.Hour},
+		AlertHandler: func(a Alert) { alerts = append(alerts, a) },
+	}}
+
+	at.checkListings(now)
+	assert.Equal(t, []string{"OLDUSDT_long"}, mock.closed, "进入下架前窗口的持仓平仓")
+	titles := func() []string {
+		var out []string
+		for _, a := range alerts {
+			out = append(out, a.Title)
+		}
+		return out
+	}
+	assert.Equal(t, []string{"合约计划下架", "合约计划下架", "下架前平仓", "持仓合约计划下架"}, titles())
+
+	assert.EqualError(t, at.checkDelistingEntry("LATEUSDT"), "LATEUSDT 计划于 2024-06-06 12:00 UTC 下架，拒绝开仓")
+	assert.NoError(t, at.checkDelistingEntry("BTCUSDT"))
+	coins := at.excludeDelisting([]decision.CandidateCoin{{Symbol: "BTCUSDT"}, {Symbol: "LATEUSDT"}})
+	assert.Equal(t, []decision.CandidateCoin{{Symbol: "BTCUSDT"}}, coins)
+
+	// 再次检查：持仓告警只发一次，新上架合约告警
+	alerts = nil
+	listings = append(listings, market.InstrumentListing{Symbol: "NEWUSDT", State: "preopen"})
+	mock.positions = mock.positions[1:]
+	at.checkListings(now.Add(time.Hour))
+	assert.Equal(t, []string{"新合约上架"}, titles())
+}
@@ -0,0 +1,6 @@
+package trader
+
+import "nofx/logging"
+
+// log trader 模块日志器（Info/Warn/Error + key/value 属性）
+var log = logging.Module("trader")
@@ -0,0 +1,252 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// defaultMakerWaitSeconds 挂单优先策略未配置等待时间时的默认值
+const defaultMakerWaitSeconds = 10
+
+// 撤单未确认的挂单的后台跟踪参数（测试中可调整）
+var (
+	makerTrackInterval = 5 * time.Second
+	makerTrackAttempts = 60
+)
+
+// makerFill 挂单最后一次查询到的状态（status 为空表示未能查询到）
+type makerFill struct {
+	filled float64
+	status futures.OrderStatusType
+}
+
+// resting 挂单是否可能仍在挂单中（未查询到状态时按仍在挂单处理）
+func (f makerFill) resting() bool {
+	switch f.status {
+	case futures.OrderStatusTypeFilled, futures.OrderStatusTypeCanceled, futures.OrderStatusTypeExpired, futures.OrderStatusTypeRejected:
+		return false
+	}
+	return true
+}
+
+// placeMakerThenTaker 挂单优先（maker_then_taker）：在最优报价挂只做 Maker 的限价单（GTX），
+// 等待 limitTimeoutSeconds 秒，未完全成交时撤单并用市价单成交剩余数量（交易频繁时能明显降低 Taker 手续费）
+func (t *FuturesTrader) placeMakerThenTaker(ctx context.Context, symbol string, side futures.SideType, positionSide futures.PositionSideType, quantityStr string) (*ExecutionReport, error) {
+	tickers, err := t.client.NewListBookTickersService().Symbol(symbol).Do(ctx)
+	if err != nil || len(tickers) == 0 {
+		log.Warn("⚠️ 获取最优买卖价失败，直接使用市价单", "symbol", symbol, "error", err)
+		return t.placeTakerOrder(ctx, symbol, side, positionSide, quantityStr)
+	}
+	// 买单挂买一、卖单挂卖一，保证不会立即与对手盘成交
+	quote := parseFloatOrZero(tickers[0].BidPrice)
+	if side == futures.SideTypeSell {
+		quote = parseFloatOrZero(tickers[0].AskPrice)
+	}
+	if quote <= 0 {
+		return t.placeTakerOrder(ctx, symbol, side, positionSide, quantityStr)
+	}
+	priceStr, err := t.FormatPrice(symbol, quote)
+	if err != nil {
+		return nil, fmt.Errorf("格式化限价失败: %w", err)
+	}
+
+	submittedAt := time.Now()
+	order, err := t.client.NewCreateOrderService().
+		Symbol(symbol).
+		Side(side).
+		PositionSide(positionSide).
+		Type(futures.OrderTypeLimit).
+		Quantity(quantityStr).
+		Price(priceStr).
+		TimeInForce(futures.TimeInForceTypeGTX). // Post Only：会立即成交时交易所直接拒绝
+		NewClientOrderID(getBrOrderID()).
+		Do(ctx)
+	if err != nil {
+		log.Warn("⚠️ 挂单失败，改用市价单", "symbol", symbol, "error", err)
+		return t.placeTakerOrder(ctx, symbol, side, positionSide, quantityStr)
+	}
+	if order.Status == futures.OrderStatusTypeExpired {
+		log.Info("📋 报价已变动，Post Only 挂单被拒绝，改用市价单", "symbol", symbol)
+		return t.placeTakerOrder(ctx, symbol, side, positionSide, quantityStr)
+	}
+	log.Info("📋 挂单优先", "symbol", symbol, "side", side, "quantity", quantityStr, "price", priceStr, "order_id", order.OrderID)
+
+	wait := t.limitTimeoutSeconds
+	if wait <= 0 {
+		wait = defaultMakerWaitSeconds
+	}
+	fill, waitErr := t.waitMakerFill(ctx, symbol, order.OrderID, time.Duration(wait)*time.Second)
+	if fill.resting() {
+		// 撤单未确认（撤单失败或查询失败）：用独立的 context 再撤单并重新查询
+		confirmCtx, cancel := backgroundCallContext()
+		if retry, err := t.cancelMakerOrder(confirmCtx, symbol, order.OrderID); err == nil || retry.status != "" {
+			fill = retry
+		}
+		cancel()
+	}
+	resting := fill.resting()
+	if resting {
+		// 仍无法确认：挂单可能稍后成交，后台继续撤单；返回挂单报告，调用方照常设置全仓止损，保护之后的成交
+		log.Error("🚨 挂单撤销未确认，后台继续跟踪", "symbol", symbol, "order_id", order.OrderID, "filled_qty", fill.filled, "error", waitErr)
+		go t.trackRestingMaker(symbol, order.OrderID)
+	} else if waitErr != nil && fill.filled <= 0 {
+		return nil, waitErr
+	}
+
+	maker := &ExecutionReport{
+		OrderID:       order.OrderID,
+		ClientOrderID: order.ClientOrderID,
+		Symbol:        symbol,
+		Side:          string(side),
+		PositionSide:  string(positionSide),
+		Status:        string(order.Status),
+		RequestedQty:  parseFloatOrZero(quantityStr),
+		FilledQty:     fill.filled, // 查询成交明细失败时也不会重复下单已成交的数量
+		AvgPrice:      parseFloatOrZero(priceStr),
+		SubmittedAt:   submittedAt,
+		Venue:         VenueBinance,
+	}
+	if fill.status != "" {
+		maker.Status = string(fill.status)
+	}
+	if fill.filled > 0 {
+		enrichCtx, cancel := backgroundCallContext() // ctx 可能已取消，仍需取得成交明细
+		t.enrichExecutionReport(enrichCtx, maker)
+		cancel()
+		if maker.AvgPrice <= 0 {
+			maker.AvgPrice = parseFloatOrZero(priceStr) // Post Only 挂单只会按限价成交
+		}
+	}
+	if resting {
+		return maker, nil
+	}
+	if waitErr != nil {
+		// 已部分成交但无法继续（被取消或撤单后才确认）：不再市价补单，返回已成交部分，调用方据此设置止损
+		log.Warn("⚠️ 挂单已部分成交，剩余数量不再转市价单", "symbol", symbol, "filled_qty", maker.FilledQty, "error", waitErr)
+		return maker, nil
+	}
+	remaining := maker.RequestedQty - maker.FilledQty
+	if prec, ok, _ := t.getSymbolPrecision(symbol); ok && prec.StepSize > 0 && remaining < prec.StepSize/2 {
+		remaining = 0
+	}
+	if remaining <= 0 {
+		log.Info("✅ 挂单全部成交", "symbol", symbol, "order_id", order.OrderID)
+		return maker, nil
+	}
+
+	remainingStr, err := t.FormatQuantity(symbol, remaining)
+	if err != nil || parseFloatOrZero(remainingStr) <= 0 {
+		return maker, nil // 剩余数量不足一个步进值
+	}
+	log.Info("⏰ 挂单未全部成交，剩余改用市价单", "symbol", symbol, "wait_sec", wait, "filled_qty", maker.FilledQty, "remaining", remainingStr)
+	taker, err := t.placeTakerOrder(ctx, symbol, side, positionSide, remainingStr)
+	if err != nil {
+		if maker.IsFilled() {
+			// 已部分成交：返回已成交部分，调用方据此设置止损
+			log.Warn("⚠️ 剩余数量市价单失败，仅部分成交", "symbol", symbol, "error", err)
+			return maker, nil
+		}
+		return nil, fmt.Errorf("挂单未成交，转换为市价单失败: %w", err)
+	}
+	return mergeExecutionReports(maker, taker), nil
+}
+
+// placeTakerOrder 市价单成交（仍受最大滑点保护）
+func (t *FuturesTrader) placeTakerOrder(ctx context.Context, symbol string, side futures.SideType, positionSide futures.PositionSideType, quantityStr string) (*ExecutionReport, error) {
+	order, err := t.placeMarketOrder(ctx, symbol, side, positionSide, quantityStr)
+	if err != nil {
+		return nil, err
+	}
+	return t.newExecutionReport(ctx, order), nil
+}
+
+// waitMakerFill 轮询挂单状态直到订单结束或超时；超时后撤单，返回撤单后的订单状态。
+// 被取消或撤单失败时同时返回最后查询到的状态和错误，调用方仍需为已成交部分设置止损
+func (t *FuturesTrader) waitMakerFill(ctx context.Context, symbol string, orderID int64, timeout time.Duration) (makerFill, error) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+
+	for {
+		select {
+		case <-ctx.Done():
+			// 调用方取消：撤掉挂单，避免残留
+			cancelCtx, cancel := backgroundCallContext()
+			fill, err := t.cancelMakerOrder(cancelCtx, symbol, orderID)
+			cancel()
+			if err != nil {
+				log.Warn("⚠️ 挂单等待被取消", "symbol", symbol, "error", err)
+			}
+			return fill, fmt.Errorf("等待挂单成交被取消: %w", ctx.Err())
+		case <-ticker.C:
+			order, err := t.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+			if err != nil {
+				log.Warn("⚠️ 查询挂单状态失败", "symbol", symbol, "error", err)
+				continue
+			}
+			if fill := (makerFill{filled: parseFloatOrZero(order.ExecutedQuantity), status: order.Status}); !fill.resting() {
+				return fill, nil
+			}
+		case <-deadline:
+			return t.cancelMakerOrder(ctx, symbol, orderID)
+		}
+	}
+}
+
+// cancelMakerOrder 撤销挂单并以撤单后的订单状态确认已成交数量（撤单前可能刚好成交）；
+// 撤单失败、订单仍在挂单中或无法查询时同时返回最后查询到的状态和错误
+func (t *FuturesTrader) cancelMakerOrder(ctx context.Context, symbol string, orderID int64) (makerFill, error) {
+	if err := t.CancelOrder(ctx, symbol, orderID); err != nil {
+		log.Warn("⚠️ 撤销挂单失败", "symbol", symbol, "error", err)
+	}
+	order, err := t.client.NewGetOrderService().Symbol(symbol).OrderID(orderID).Do(ctx)
+	if err != nil {
+		return makerFill{}, fmt.Errorf("撤单后查询挂单失败，无法确认成交数量: %w", err)
+	}
+	fill := makerFill{filled: parseFloatOrZero(order.ExecutedQuantity), status: order.Status}
+	if fill.resting() {
+		return fill, fmt.Errorf("挂单 OrderID=%d 撤销失败，仍在挂单中（已成交 %.8g）", orderID, fill.filled)
+	}
+	return fill, nil
+}
+
+// trackRestingMaker 后台重复撤销撤单未确认的挂单，直到交易所确认订单已结束
+// （在此之前的成交由调用方设置的全仓止损保护）
+func (t *FuturesTrader) trackRestingMaker(symbol string, orderID int64) {
+	for i := 0; i < makerTrackAttempts; i++ {
+		time.Sleep(makerTrackInterval)
+		ctx, cancel := backgroundCallContext()
+		fill, err := t.cancelMakerOrder(ctx, symbol, orderID)
+		cancel()
+		if err == nil {
+			log.Info("✓ 挂单已确认结束", "symbol", symbol, "order_id", orderID, "status", fill.status, "filled_qty", fill.filled)
+			t.invalidateSymbol(symbol)
+			return
+		}
+	}
+	log.Error("🚨 挂单仍未确认撤销，请手动检查", "symbol", symbol, "order_id", orderID)
+}
+
+// mergeExecutionReports 合并挂单成交部分和市价单成交部分（成交均价按数量加权）
+func mergeExecutionReports(maker, taker *ExecutionReport) *ExecutionReport {
+	merged := *taker
+	merged.RequestedQty = maker.RequestedQty
+	merged.Converted = true
+	merged.OriginalOrderID = maker.OrderID
+	merged.SubmittedAt = maker.SubmittedAt
+	if maker.IsFilled() {
+		qty := maker.FilledQty + taker.FilledQty
+		if qty > 0 {
+			merged.AvgPrice = (maker.FilledQty*maker.AvgPrice + taker.FilledQty*taker.AvgPrice) / qty
+		}
+		merged.FilledQty = qty
+		merged.Fee = maker.Fee + taker.Fee
+		if merged.FeeAsset == "" {
+			merged.FeeAsset = maker.FeeAsset
+		}
+	}
+	return &merged
+}
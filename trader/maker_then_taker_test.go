@@ -0,0 +1,186 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+// makerTestExchange 模拟挂单部分成交（0.004 @ makerAvgPrice）、撤单后剩余数量市价成交的交易所
+type makerTestExchange struct {
+	mu       sync.Mutex
+	orders   []url.Values
+	canceled bool
+}
+
+func newMakerTestTrader(t *testing.T, makerAvgPrice string, waitSeconds int) (*FuturesTrader, *makerTestExchange) {
+	ex := &makerTestExchange{}
+	trader := newFakeBinanceFuturesTrader(t, "50001.00", "maker_then_taker", waitSeconds, func(w http.ResponseWriter, r *http.Request) {
+		ex.mu.Lock()
+		defer ex.mu.Unlock()
+		r.ParseForm()
+		switch {
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodPost:
+			ex.orders = append(ex.orders, r.Form)
+			id := int64(len(ex.orders))
+			json.NewEncoder(w).Encode(&futures.CreateOrderResponse{OrderID: id, Symbol: "BTCUSDT", Status: futures.OrderStatusTypeNew, ExecutedQuantity: "0"})
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodDelete:
+			ex.canceled = true
+			json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 1, "status": "CANCELED"})
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodGet:
+			order := futures.Order{OrderID: 1, Symbol: "BTCUSDT", Status: futures.OrderStatusTypePartiallyFilled, ExecutedQuantity: "0.004", AvgPrice: makerAvgPrice}
+			if ex.canceled {
+				order.Status = futures.OrderStatusTypeCanceled
+			}
+			if r.Form.Get("orderId") == "2" {
+				order = futures.Order{OrderID: 2, Symbol: "BTCUSDT", Status: futures.OrderStatusTypeFilled, ExecutedQuantity: "0.006", AvgPrice: "50010"}
+			}
+			json.NewEncoder(w).Encode(order)
+		default:
+			json.NewEncoder(w).Encode([]interface{}{})
+		}
+	})
+	return trader, ex
+}
+
+// TestMakerThenTakerFallsBackForRemainder 挂单部分成交，超时撤单后剩余数量转市价单
+func TestMakerThenTakerFallsBackForRemainder(t *testing.T) {
+	trader, ex := newMakerTestTrader(t, "0", 1) // 交易所未返回挂单均价时按限价计算
+
+	report, err := trader.placeMakerThenTaker(context.Background(), "BTCUSDT", futures.SideTypeBuy, futures.PositionSideTypeLong, "0.010")
+	if err != nil {
+		t.Fatalf("placeMakerThenTaker() error = %v", err)
+	}
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	orders := ex.orders
+	if len(orders) != 2 {
+		t.Fatalf("应先挂单再市价成交剩余数量, got %d 个订单", len(orders))
+	}
+	if orders[0].Get("timeInForce") != "GTX" || orders[0].Get("price") != "50000.00" {
+		t.Errorf("挂单应为买一价 Post Only: %v", orders[0])
+	}
+	if orders[1].Get("type") != "MARKET" || orders[1].Get("quantity") != "0.006" {
+		t.Errorf("市价单只成交剩余数量: %v", orders[1])
+	}
+	if !report.Converted || report.OriginalOrderID != 1 || report.OrderID != 2 {
+		t.Errorf("unexpected report ids: %+v", report)
+	}
+	if report.FilledQty < 0.0099 || report.FilledQty > 0.0101 || report.AvgPrice < 50005.9 || report.AvgPrice > 50006.1 {
+		t.Errorf("成交量/均价应合并两笔订单: qty=%v avg=%v", report.FilledQty, report.AvgPrice)
+	}
+}
+
+// TestMakerThenTakerCanceledAfterPartialFill 等待期间被取消：撤单并返回已成交部分，不再市价补单
+func TestMakerThenTakerCanceledAfterPartialFill(t *testing.T) {
+	trader, ex := newMakerTestTrader(t, "50000", 60)
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+
+	report, err := trader.placeMakerThenTaker(ctx, "BTCUSDT", futures.SideTypeBuy, futures.PositionSideTypeLong, "0.010")
+	if err != nil {
+		t.Fatalf("已部分成交时应返回成交部分而不是错误: %v", err)
+	}
+	if report.FilledQty != 0.004 || report.AvgPrice != 50000 || report.Converted || report.Status != string(futures.OrderStatusTypeCanceled) {
+		t.Errorf("应只包含挂单成交部分，状态取撤单后查询结果: %+v", report)
+	}
+
+	ex.mu.Lock()
+	defer ex.mu.Unlock()
+	if !ex.canceled {
+		t.Error("取消时应撤销挂单")
+	}
+	if len(ex.orders) != 1 {
+		t.Errorf("取消后不应再下市价单, got %d 个订单", len(ex.orders))
+	}
+}
+
+// TestMakerThenTakerUnconfirmedCancelKeepsTracking 撤单失败、挂单仍在挂单中：不返回错误（调用方照常设置止损），
+// 不下市价单，后台继续撤单直到确认
+func TestMakerThenTakerUnconfirmedCancelKeepsTracking(t *testing.T) {
+	interval, attempts := makerTrackInterval, makerTrackAttempts
+	makerTrackInterval, makerTrackAttempts = 10*time.Millisecond, 50
+	defer func() { makerTrackInterval, makerTrackAttempts = interval, attempts }()
+
+	var mu sync.Mutex
+	placed, cancels := 0, 0
+	trader := newFakeBinanceFuturesTrader(t, "50001.00", "maker_then_taker", 1, func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodPost:
+			placed++
+			json.NewEncoder(w).Encode(&futures.CreateOrderResponse{OrderID: 1, Symbol: "BTCUSDT", Status: futures.OrderStatusTypeNew, ExecutedQuantity: "0"})
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodDelete:
+			cancels++
+			if cancels < 4 {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(map[string]interface{}{"code": -1001, "msg": "Internal error"})
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]interface{}{"orderId": 1, "status": "CANCELED"})
+		case r.URL.Path == "/fapi/v1/order" && r.Method == http.MethodGet:
+			order := futures.Order{OrderID: 1, Symbol: "BTCUSDT", Status: futures.OrderStatusTypeNew, ExecutedQuantity: "0"}
+			if cancels >= 4 {
+				order.Status = futures.OrderStatusTypeCanceled
+			}
+			json.NewEncoder(w).Encode(order)
+		default:
+			json.NewEncoder(w).Encode([]interface{}{})
+		}
+	})
+
+	report, err := trader.placeMakerThenTaker(context.Background(), "BTCUSDT", futures.SideTypeBuy, futures.PositionSideTypeLong, "0.010")
+	if err != nil {
+		t.Fatalf("撤单未确认时不应返回错误（挂单可能稍后成交，需要设置止损）: %v", err)
+	}
+	if report.OrderID != 1 || report.Status != string(futures.OrderStatusTypeNew) || report.FilledQty != 0 {
+		t.Errorf("报告应为最后查询到的挂单状态: %+v", report)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		mu.Lock()
+		done := cancels >= 4
+		mu.Unlock()
+		if done || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if cancels < 4 {
+		t.Errorf("后台应继续撤单直到确认, 撤单次数 = %d", cancels)
+	}
+	if placed != 1 {
+		t.Errorf("撤单未确认时不应再下市价单, got %d 个订单", placed)
+	}
+}
+
+func TestMakerThenTakerPostOnlyRejected(t *testing.T) {
+	var orders []url.Values
+	trader := newSlippageTestTrader(t, "50001.00", futures.OrderStatusTypeExpired, &orders)
+	trader.orderStrategy = "maker_then_taker"
+	trader.SetMaxSlippageBps(0)
+
+	if _, err := trader.placeMakerThenTaker(context.Background(), "BTCUSDT", futures.SideTypeSell, futures.PositionSideTypeShort, "0.010"); err != nil {
+		t.Fatalf("placeMakerThenTaker() error = %v", err)
+	}
+	var placed []url.Values // 同一路径上也记录了查询订单的请求
+	for _, o := range orders {
+		if o.Get("type") != "" {
+			placed = append(placed, o)
+		}
+	}
+	if len(placed) != 2 || placed[0].Get("price") != "50001.00" || placed[1].Get("type") != "MARKET" {
+		t.Errorf("Post Only 被拒绝后应立即市价成交: %v", placed)
+	}
+}
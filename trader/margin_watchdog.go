@@ -0,0 +1,152 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultDeleveragePct 默认每次触发时对亏损最大持仓的减仓比例（百分比）
+const defaultDeleveragePct = 50.0
+
+// MarginWatchdogFromEnv 读取 NOFX_MARGIN_RATIO_DANGER_PCT（保证金率危险阈值百分比，未设置=关闭）
+// 和 NOFX_DELEVERAGE_PCT（触发时的减仓比例百分比，未设置=默认 50）
+func MarginWatchdogFromEnv() (float64, float64, error) {
+	dangerPct, deleveragePct := 0.0, 0.0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_MARGIN_RATIO_DANGER_PCT")); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v <= 0 || v > 100 {
+			return 0, 0, fmt.Errorf("NOFX_MARGIN_RATIO_DANGER_PCT 必须在 (0, 100] 之间: %q", raw)
+		}
+		dangerPct = v
+	}
+	if raw := strings.TrimSpace(os.Getenv("NOFX_DELEVERAGE_PCT")); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 1 || v > 100 {
+			return 0, 0, fmt.Errorf("NOFX_DELEVERAGE_PCT 必须在 [1, 100] 之间: %q", raw)
+		}
+		deleveragePct = v
+	}
+	return dangerPct, deleveragePct, nil
+}
+
+// 启动保证金率监控
+// 保证金率超过 MarginRatioDangerPct 时，主动减仓亏损最大的持仓，避免被交易所强平/自动减仓
+// 阈值每次检查时读取（可在运行时调整，0=不检查）
+func (at *AutoTrader) startMarginRatioMonitor() {
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(30 * time.Second) // 每30秒检查一次
+		defer ticker.Stop()
+
+		dangerPct, _ := at.getMarginRatioParams()
+		log.Info("🛡️ 启动保证金率监控（每30秒检查一次）", "danger_pct", dangerPct)
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := at.checkMarginRatio(); err != nil {
+					log.Error("❌ 保证金率监控失败", "error", err)
+				}
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止保证金率监控")
+				return
+			}
+		}
+	}()
+}
+
+// checkMarginRatio 检查保证金率，超过危险阈值时减仓亏损最大的持仓（阈值为 0 时不检查）
+func (at *AutoTrader) checkMarginRatio() error {
+	dangerPct, deleveragePct := at.getMarginRatioParams()
+	if dangerPct <= 0 {
+		return nil
+	}
+
+	balance, err := at.trader.GetBalance(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取余额失败: %w", err)
+	}
+
+	ratio, ok := ParseMarginRatio(balance)
+	if !ok {
+		return nil
+	}
+	marginRatio := ratio.Pct()
+	if marginRatio < dangerPct {
+		return nil
+	}
+
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+
+	target := findLargestLosingPosition(positions)
+	if target == nil {
+		log.Warn("⚠️ 保证金率超过危险阈值，但没有亏损持仓可减仓", "margin_ratio_pct", marginRatio, "danger_pct", dangerPct)
+		return nil
+	}
+
+	symbol, ok := target["symbol"].(string)
+	if !ok || symbol == "" {
+		return fmt.Errorf("持仓缺少 symbol 字段: %v", target)
+	}
+	side, ok := target["side"].(string)
+	if !ok {
+		return fmt.Errorf("%s 持仓缺少 side 字段", symbol)
+	}
+	positionAmt, ok := target["positionAmt"].(float64)
+	if !ok {
+		return fmt.Errorf("%s 持仓缺少 positionAmt 字段", symbol)
+	}
+	quantity := math.Abs(positionAmt)
+	markPrice, _ := target["markPrice"].(float64)
+	unrealizedPnL, _ := target["unRealizedProfit"].(float64)
+
+	if deleveragePct <= 0 || deleveragePct > 100 {
+		deleveragePct = defaultDeleveragePct
+	}
+	closeQuantity := quantity * deleveragePct / 100
+
+	log.Error("🚨 保证金率超过危险阈值，自动减仓", "margin_ratio_pct", marginRatio, "danger_pct", dangerPct, "symbol", symbol, "side", side, "deleverage_pct", deleveragePct, "unrealized_pnl", unrealizedPnL)
+
+	// 剩余仓位过小时直接全部平仓，避免产生无法平仓的小额剩余
+	const MIN_POSITION_VALUE = 10.0
+	if (quantity-closeQuantity)*markPrice <= MIN_POSITION_VALUE {
+		closeQuantity = 0 // 0 = 全部平仓
+	}
+
+	var order *ExecutionReport
+	if side == "long" {
+		order, err = at.trader.CloseLong(at.ctx(), symbol, closeQuantity)
+	} else {
+		order, err = at.trader.CloseShort(at.ctx(), symbol, closeQuantity)
+	}
+	if err != nil {
+		return fmt.Errorf("自动减仓失败 (%s %s): %w", symbol, side, err)
+	}
+
+	log.Info("✅ 自动减仓成功", "symbol", symbol, "side", side, "order_id", order.OrderID)
+	return nil
+}
+
+// findLargestLosingPosition 找出未实现亏损最大的持仓（没有亏损持仓时返回 nil）
+func findLargestLosingPosition(positions []map[string]interface{}) map[string]interface{} {
+	var target map[string]interface{}
+	worstPnL := 0.0
+	for _, pos := range positions {
+		pnl, ok := pos["unRealizedProfit"].(float64)
+		if !ok || pnl >= worstPnL {
+			continue
+		}
+		worstPnL = pnl
+		target = pos
+	}
+	return target
+}
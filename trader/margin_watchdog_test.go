@@ -0,0 +1,142 @@
+package trader
+
+import (
+	"context"
+	"testing"
+)
+
+// closeRecordingTrader 记录平仓调用的 MockTrader
+type closeRecordingTrader struct {
+	MockTrader
+	closedSymbol   string
+	closedSide     string
+	closedQuantity float64
+}
+
+func (m *closeRecordingTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	m.closedSymbol, m.closedSide, m.closedQuantity = symbol, "long", quantity
+	return m.MockTrader.CloseLong(context.Background(), symbol, quantity)
+}
+
+func (m *closeRecordingTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	m.closedSymbol, m.closedSide, m.closedQuantity = symbol, "short", quantity
+	return m.MockTrader.CloseShort(context.Background(), symbol, quantity)
+}
+
+// TestCheckMarginRatio 测试保证金率超过阈值时减仓亏损最大的持仓
+func TestCheckMarginRatio(t *testing.T) {
+	positions := []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5, "markPrice": 50000.0, "unRealizedProfit": -200.0},
+		{"symbol": "ETHUSDT", "side": "short", "positionAmt": -10.0, "markPrice": 3000.0, "unRealizedProfit": -500.0},
+		{"symbol": "SOLUSDT", "side": "long", "positionAmt": 100.0, "markPrice": 150.0, "unRealizedProfit": 300.0},
+	}
+
+	tests := []struct {
+		name         string
+		maintMargin  float64
+		expectSymbol string
+		expectQty    float64
+	}{
+		{name: "未超过阈值_不减仓", maintMargin: 600},
+		{name: "超过阈值_减仓亏损最大持仓", maintMargin: 850, expectSymbol: "ETHUSDT", expectQty: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &closeRecordingTrader{MockTrader: MockTrader{
+				balance:   map[string]interface{}{"totalMaintMargin": tt.maintMargin, "totalMarginBalance": 1000.0},
+				positions: positions,
+			}}
+			at := &AutoTrader{
+				trader: mock,
+				config: AutoTraderConfig{MarginRatioDangerPct: 80},
+			}
+
+			if err := at.checkMarginRatio(); err != nil {
+				t.Fatalf("不应返回错误: %v", err)
+			}
+			if mock.closedSymbol != tt.expectSymbol {
+				t.Fatalf("期望减仓 %q，实际 %q", tt.expectSymbol, mock.closedSymbol)
+			}
+			if mock.closedQuantity != tt.expectQty {
+				t.Errorf("期望减仓数量 %.4f，实际 %.4f", tt.expectQty, mock.closedQuantity)
+			}
+		})
+	}
+}
+
+// TestCheckMarginRatio_Disabled 测试阈值为 0 时不查询余额（监控仍在运行，调整阈值后下次检查生效）
+func TestCheckMarginRatio_Disabled(t *testing.T) {
+	mock := &closeRecordingTrader{MockTrader: MockTrader{shouldFailBalance: true}}
+	at := &AutoTrader{trader: mock}
+	if err := at.checkMarginRatio(); err != nil {
+		t.Fatalf("阈值为 0 时不应查询余额: %v", err)
+	}
+
+	at.config.MarginRatioDangerPct = 80
+	if err := at.checkMarginRatio(); err == nil {
+		t.Error("设置阈值后应开始检查")
+	}
+}
+
+func TestMarginWatchdogFromEnv(t *testing.T) {
+	if danger, deleverage, err := MarginWatchdogFromEnv(); err != nil || danger != 0 || deleverage != 0 {
+		t.Fatalf("未配置时应关闭: %v %v %v", danger, deleverage, err)
+	}
+
+	t.Setenv("NOFX_MARGIN_RATIO_DANGER_PCT", "80")
+	t.Setenv("NOFX_DELEVERAGE_PCT", "25")
+	if danger, deleverage, err := MarginWatchdogFromEnv(); err != nil || danger != 80 || deleverage != 25 {
+		t.Errorf("配置解析错误: %v %v %v", danger, deleverage, err)
+	}
+
+	for _, bad := range []struct{ key, value string }{
+		{"NOFX_MARGIN_RATIO_DANGER_PCT", "abc"},
+		{"NOFX_MARGIN_RATIO_DANGER_PCT", "120"},
+		{"NOFX_DELEVERAGE_PCT", "0.5"},
+	} {
+		t.Run(bad.key+"="+bad.value, func(t *testing.T) {
+			t.Setenv(bad.key, bad.value)
+			if _, _, err := MarginWatchdogFromEnv(); err == nil {
+				t.Errorf("%s=%q 应返回错误", bad.key, bad.value)
+			}
+		})
+	}
+}
+
+// TestFindLargestLosingPosition_NoLosses 测试没有亏损持仓时不选择任何持仓
+func TestFindLargestLosingPosition_NoLosses(t *testing.T) {
+	positions := []map[string]interface{}{
+		{"symbol": "BTCUSDT", "unRealizedProfit": 10.0},
+	}
+	if pos := findLargestLosingPosition(positions); pos != nil {
+		t.Errorf("期望返回 nil，实际 %v", pos)
+	}
+}
+
+// TestCheckMarginRatio_MalformedPosition 测试持仓字段缺失时返回错误而不是 panic
+func TestCheckMarginRatio_MalformedPosition(t *testing.T) {
+	mock := &closeRecordingTrader{MockTrader: MockTrader{
+		balance:   map[string]interface{}{"totalMaintMargin": 900.0, "totalMarginBalance": 1000.0},
+		positions: []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long", "positionAmt": "0.5", "unRealizedProfit": -200.0}},
+	}}
+	at := &AutoTrader{trader: mock, config: AutoTraderConfig{MarginRatioDangerPct: 80}}
+
+	if err := at.checkMarginRatio(); err == nil {
+		t.Fatal("positionAmt 类型错误时应返回错误")
+	}
+	if mock.closedSymbol != "" {
+		t.Errorf("字段缺失时不应减仓，实际减仓 %q", mock.closedSymbol)
+	}
+}
+
+// TestParseMarginRatio 测试各交易所统一口径的保证金率
+func TestParseMarginRatio(t *testing.T) {
+	ratio, ok := ParseMarginRatio(map[string]interface{}{"totalMaintMargin": 25.0, "totalMarginBalance": 500.0})
+	if !ok || ratio.Pct() != 5 {
+		t.Errorf("保证金率 = %+v (%.2f%%), ok=%v, want 5%%", ratio, ratio.Pct(), ok)
+	}
+	if _, ok := ParseMarginRatio(map[string]interface{}{"totalWalletBalance": 500.0}); ok {
+		t.Error("缺少维持保证金时不应返回保证金率")
+	}
+}
@@ -0,0 +1,175 @@
+package trader
+
+import (
+	"context"
+	"nofx/decision"
+	"nofx/market"
+	"nofx/metrics"
+	"time"
+)
+
+// metricsTrader 记录交易所调用耗时、失败次数和下单结果的 Trader 装饰器
+type metricsTrader struct {
+	Trader
+	exchange string
+}
+
+// newMetricsTrader 为 trader 包装 Prometheus 指标
+func newMetricsTrader(t Trader, exchange string) *metricsTrader {
+	return &metricsTrader{Trader: t, exchange: exchange}
+}
+
+// observe 记录一次调用的耗时和结果
+func (t *metricsTrader) observe(endpoint string, start time.Time, err error) {
+	metrics.ExchangeRequestDuration.Observe(time.Since(start).Seconds(), t.exchange, endpoint)
+	if err != nil {
+		metrics.ExchangeRequestErrors.Inc(t.exchange, endpoint)
+	}
+}
+
+// order 记录下单类调用（额外计入 nofx_orders_total）
+func (t *metricsTrader) order(action string, start time.Time, err error) {
+	t.observe(action, start, err)
+	result := metrics.ResultSuccess
+	if err != nil {
+		result = metrics.ResultFailure
+	}
+	metrics.Orders.Inc(t.exchange, action, result)
+}
+
+func (t *metricsTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	start := time.Now()
+	balance, err := t.Trader.GetBalance(ctx)
+	t.observe("get_balance", start, err)
+	return balance, err
+}
+
+func (t *metricsTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	start := time.Now()
+	positions, err := t.Trader.GetPositions(ctx)
+	t.observe("get_positions", start, err)
+	return positions, err
+}
+
+func (t *metricsTrader) OpenLong(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	start := time.Now()
+	report, err := t.Trader.OpenLong(ctx, symbol, quantity, leverage)
+	t.order("open_long", start, err)
+	observeStage(t.exchange, metrics.StageOrder, time.Since(start))
+	return report, err
+}
+
+func (t *metricsTrader) OpenShort(ctx context.Context, symbol string, quantity float64, leverage int) (*ExecutionReport, error) {
+	start := time.Now()
+	report, err := t.Trader.OpenShort(ctx, symbol, quantity, leverage)
+	t.order("open_short", start, err)
+	observeStage(t.exchange, metrics.StageOrder, time.Since(start))
+	return report, err
+}
+
+func (t *metricsTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	start := time.Now()
+	report, err := t.Trader.CloseLong(ctx, symbol, quantity)
+	t.order("close_long", start, err)
+	observeStage(t.exchange, metrics.StageOrder, time.Since(start))
+	return report, err
+}
+
+func (t *metricsTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
+	start := time.Now()
+	report, err := t.Trader.CloseShort(ctx, symbol, quantity)
+	t.order("close_short", start, err)
+	observeStage(t.exchange, metrics.StageOrder, time.Since(start))
+	return report, err
+}
+
+func (t *metricsTrader) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	start := time.Now()
+	err := t.Trader.SetLeverage(ctx, symbol, leverage)
+	t.observe("set_leverage", start, err)
+	return err
+}
+
+func (t *metricsTrader) SetMarginMode(ctx context.Context, symbol string, isCrossMargin bool) error {
+	start := time.Now()
+	err := t.Trader.SetMarginMode(ctx, symbol, isCrossMargin)
+	t.observe("set_margin_mode", start, err)
+	return err
+}
+
+func (t *metricsTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
+	start := time.Now()
+	price, err := t.Trader.GetMarketPrice(ctx, symbol)
+	t.observe("get_market_price", start, err)
+	return price, err
+}
+
+func (t *metricsTrader) GetFundingRate(ctx context.Context, symbol string) (*market.FundingRate, error) {
+	start := time.Now()
+	rate, err := t.Trader.GetFundingRate(ctx, symbol)
+	t.observe("get_funding_rate", start, err)
+	return rate, err
+}
+
+func (t *metricsTrader) GetFundingRateHistory(ctx context.Context, symbol string, n int) ([]market.FundingRate, error) {
+	start := time.Now()
+	rates, err := t.Trader.GetFundingRateHistory(ctx, symbol, n)
+	t.observe("get_funding_rate_history", start, err)
+	return rates, err
+}
+
+func (t *metricsTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	start := time.Now()
+	err := t.Trader.SetStopLoss(ctx, symbol, positionSide, quantity, stopPrice)
+	t.order("stop_loss", start, err)
+	return err
+}
+
+func (t *metricsTrader) SetTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	start := time.Now()
+	err := t.Trader.SetTakeProfit(ctx, symbol, positionSide, quantity, takeProfitPrice)
+	t.order("take_profit", start, err)
+	return err
+}
+
+func (t *metricsTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
+	start := time.Now()
+	err := t.Trader.CancelStopLossOrders(ctx, symbol)
+	t.observe("cancel_stop_loss_orders", start, err)
+	return err
+}
+
+func (t *metricsTrader) CancelTakeProfitOrders(ctx context.Context, symbol string) error {
+	start := time.Now()
+	err := t.Trader.CancelTakeProfitOrders(ctx, symbol)
+	t.observe("cancel_take_profit_orders", start, err)
+	return err
+}
+
+func (t *metricsTrader) CancelAllOrders(ctx context.Context, symbol string) error {
+	start := time.Now()
+	err := t.Trader.CancelAllOrders(ctx, symbol)
+	t.observe("cancel_all_orders", start, err)
+	return err
+}
+
+func (t *metricsTrader) CancelStopOrders(ctx context.Context, symbol string) error {
+	start := time.Now()
+	err := t.Trader.CancelStopOrders(ctx, symbol)
+	t.observe("cancel_stop_orders", start, err)
+	return err
+}
+
+func (t *metricsTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
+	start := time.Now()
+	orders, err := t.Trader.GetOpenOrders(ctx, symbol)
+	t.observe("get_open_orders", start, err)
+	return orders, err
+}
+
+// recordAccountMetrics 更新净值、未实现盈亏和持仓数量指标
+func (at *AutoTrader) recordAccountMetrics(account decision.AccountInfo) {
+	metrics.Equity.Set(account.TotalEquity, at.id)
+	metrics.UnrealizedPnL.Set(account.UnrealizedPnL, at.id)
+	metrics.OpenPositions.Set(float64(account.PositionCount), at.id)
+}
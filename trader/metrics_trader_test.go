@@ -0,0 +1,46 @@
+package trader
+
+import (
+	"context"
+	"testing"
+
+	"nofx/decision"
+	"nofx/metrics"
+)
+
+func TestMetricsTraderCountsOrders(t *testing.T) {
+	mock := &MockTrader{shouldFailCloseLong: true}
+	mt := newMetricsTrader(mock, "metrics_test")
+
+	beforeCalls := metrics.ExchangeRequestDuration.Count("metrics_test", "open_long")
+	mt.OpenLong(context.Background(), "BTCUSDT", 1, 5)
+	mt.CloseLong(context.Background(), "BTCUSDT", 0)
+	mt.GetBalance(context.Background())
+
+	if got := metrics.Orders.Value("metrics_test", "open_long", metrics.ResultSuccess); got != 1 {
+		t.Errorf("open_long success = %v, want 1", got)
+	}
+	if got := metrics.Orders.Value("metrics_test", "close_long", metrics.ResultFailure); got != 1 {
+		t.Errorf("close_long failure = %v, want 1", got)
+	}
+	if got := metrics.ExchangeRequestErrors.Value("metrics_test", "close_long"); got != 1 {
+		t.Errorf("close_long errors = %v, want 1", got)
+	}
+	if got := metrics.ExchangeRequestDuration.Count("metrics_test", "open_long") - beforeCalls; got != 1 {
+		t.Errorf("open_long latency samples = %d, want 1", got)
+	}
+	if got := metrics.ExchangeRequestDuration.Count("metrics_test", "get_balance"); got != 1 {
+		t.Errorf("get_balance latency samples = %d, want 1", got)
+	}
+}
+
+func TestRecordAccountMetrics(t *testing.T) {
+	at := &AutoTrader{id: "metrics_trader"}
+	at.recordAccountMetrics(decision.AccountInfo{TotalEquity: 1050, UnrealizedPnL: -12.5, PositionCount: 2})
+
+	if metrics.Equity.Value("metrics_trader") != 1050 ||
+		metrics.UnrealizedPnL.Value("metrics_trader") != -12.5 ||
+		metrics.OpenPositions.Value("metrics_trader") != 2 {
+		t.Error("account gauges not updated")
+	}
+}
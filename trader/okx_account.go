@@ -0,0 +1,444 @@
+package trader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"nofx/exchangehttp"
+	"nofx/netconfig"
+	"nofx/ratelimit"
+	"strconv"
+	"time"
+)
+
+const okxRESTBaseURL = "https://www.okx.com"
+
+// OKXAccountType OKX 资金账户类型（划转 from/to）
+type OKXAccountType string
+
+const (
+	OKXFundingAccount OKXAccountType = "6"  // 资金账户
+	OKXTradingAccount OKXAccountType = "18" // 交易账户（统一账户）
+)
+
+// OKX 划转类型
+const (
+	OKXTransferInternal    = "0" // 本账户内划转
+	OKXTransferToSub       = "1" // 母账户转子账户（母账户 API Key）
+	OKXTransferFromSub     = "2" // 子账户转母账户（母账户 API Key）
+	OKXTransferSubToMaster = "3" // 子账户转母账户（子账户 API Key）
+)
+
+// OKXSubAccount 子账户
+type OKXSubAccount struct {
+	Name    string    `json:"sub_acct"`
+	Label   string    `json:"label"`
+	Type    string    `json:"type"` // 1: 普通子账户 2: 托管子账户 ...
+	Enabled bool      `json:"enabled"`
+	Created time.Time `json:"created"`
+}
+
+// OKXAssetBalance 单个币种余额
+type OKXAssetBalance struct {
+	Currency  string  `json:"currency"`
+	Equity    float64 `json:"equity"`    // 币种权益（交易账户）或总余额（资金账户）
+	Available float64 `json:"available"` // 可用（可划转）余额
+	Frozen    float64 `json:"frozen"`
+}
+
+// OKXAccountBalance 账户余额
+type OKXAccountBalance struct {
+	TotalEquity float64           `json:"total_equity"` // 美元计价总权益（仅交易账户）
+	Assets      []OKXAssetBalance `json:"assets"`
+}
+
+// Asset 指定币种余额（没有时返回零值）
+func (b *OKXAccountBalance) Asset(currency string) OKXAssetBalance {
+	for _, a := range b.Assets {
+		if a.Currency == currency {
+			return a
+		}
+	}
+	return OKXAssetBalance{Currency: currency}
+}
+
+// OKXTransferRequest 资金划转请求（/api/v5/asset/transfer）
+type OKXTransferRequest struct {
+	Currency   string
+	Amount     float64
+	From       OKXAccountType
+	To         OKXAccountType
+	Type       string // OKXTransfer*，为空时为 OKXTransferInternal
+	SubAccount string // 涉及子账户的划转必填
+	ClientID   string // 客户端划转ID（幂等，为空时自动生成）
+}
+
+// OKXTransferResult 划转结果
+type OKXTransferResult struct {
+	TransferID string  `json:"trans_id"`
+	ClientID   string  `json:"client_id"`
+	Currency   string  `json:"currency"`
+	Amount     float64 `json:"amount"`
+}
+
+// OKXAccountClient OKX 账户与资产接口：子账户列表、子账户余额、资金划转
+type OKXAccountClient struct {
+	apiKey     string
+	secretKey  string
+	passphrase string
+	simulated  bool
+	baseURL    string
+	client     *http.Client
+	clock      *ServerClock // 服务器时钟（签名时间戳按偏移校正）
+}
+
+// NewOKXAccountClient 创建 OKX 账户接口客户端（查询子账户需要母账户 API Key）
+func NewOKXAccountClient(apiKey, secretKey, passphrase string, simulated bool) *OKXAccountClient {
+	c := &OKXAccountClient{
+		apiKey:     apiKey,
+		secretKey:  secretKey,
+		passphrase: passphrase,
+		simulated:  simulated,
+		baseURL:    netconfig.BaseURL("okx", okxRESTBaseURL),
+		client:     exchangehttp.NewHTTPClient("okx", ratelimit.OKXGroup, 30*time.Second),
+	}
+	c.clock = NewServerClock("OKX", c.serverTime)
+	return c
+}
+
+// ServerClock 服务器时钟（可与同一进程内的 OKX 私有推送共用）
+func (c *OKXAccountClient) ServerClock() *ServerClock {
+	return c.clock
+}
+
+// serverTime 获取 OKX 服务器时间（/api/v5/public/time，无需签名）
+func (c *OKXAccountClient) serverTime(ctx context.Context) (time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v5/public/time", nil)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if c.simulated {
+		req.Header.Set("x-simulated-trading", "1")
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Code string `json:"code"`
+		Msg  string `json:"msg"`
+		Data []struct {
+			Ts string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败 (HTTP %d): %w", resp.StatusCode, err)
+	}
+	if result.Code != "0" || len(result.Data) == 0 {
+		return time.Time{}, fmt.Errorf("获取服务器时间失败: code=%s msg=%s", result.Code, result.Msg)
+	}
+	ms, err := strconv.ParseInt(result.Data[0].Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("解析服务器时间失败: %w", err)
+	}
+	return time.UnixMilli(ms), nil
+}
+
+// okxRESTSign REST 签名：Base64(HMAC-SHA256(secret, timestamp + method + requestPath + body))
+func okxRESTSign(secret, timestamp, method, requestPath, body string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + method + requestPath + body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// request 发送签名请求并把 data 解析到 out（时间戳错误时同步服务器时间后重发一次）
+func (c *OKXAccountClient) request(ctx context.Context, method, path string, query url.Values, payload interface{}, out interface{}) error {
+	req := &exchangehttp.Request{Method: method, Path: path, Query: query}
+	if payload != nil {
+		if err := req.SetJSON(payload); err != nil {
+			return err
+		}
+	}
+	api := &exchangehttp.Client{
+		HTTP:    c.client,
+		BaseURL: c.baseURL,
+		Sign:    c.sign,
+		Decode:  okxDecoder(method, path),
+		// 时间戳超出接收窗口的请求不会被执行，同步服务器时间后可以安全重发
+		Resend: func(ctx context.Context, err error) bool {
+			return errors.Is(err, ErrTimestamp) && c.clock.Sync(ctx) == nil
+		},
+	}
+	return api.Do(ctx, req, out)
+}
+
+// sign 按服务器时钟签名
+func (c *OKXAccountClient) sign(r *exchangehttp.Request) error {
+	ts := c.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("OK-ACCESS-KEY", c.apiKey)
+	r.Header.Set("OK-ACCESS-SIGN", okxRESTSign(c.secretKey, ts, r.Method, r.RequestPath(), string(r.Body)))
+	r.Header.Set("OK-ACCESS-TIMESTAMP", ts)
+	r.Header.Set("OK-ACCESS-PASSPHRASE", c.passphrase)
+	if c.simulated {
+		r.Header.Set("x-simulated-trading", "1")
+	}
+	return nil
+}
+
+// okxDecoder 解析 OKX v5 响应外层结构 {code, msg, data}，业务错误转为 ExchangeError
+func okxDecoder(method, path string) exchangehttp.Decoder {
+	return func(status int, body []byte, out interface{}) error {
+		var result struct {
+			Code string          `json:"code"`
+			Msg  string          `json:"msg"`
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal(body, &result); err != nil {
+			return fmt.Errorf("解析响应失败 (HTTP %d): %w, body: %s", status, err, body)
+		}
+		if result.Code != "0" {
+			// 批量类接口的具体错误在 data[].sCode 中
+			var details []struct {
+				SCode string `json:"sCode"`
+				SMsg  string `json:"sMsg"`
+			}
+			code, msg := result.Code, result.Msg
+			if json.Unmarshal(result.Data, &details) == nil && len(details) > 0 && details[0].SCode != "" && details[0].SCode != "0" {
+				code, msg = details[0].SCode, details[0].SMsg
+			}
+			return NewExchangeError("okx", code, msg, fmt.Errorf("OKX %s %s 失败: code=%s msg=%s", method, path, code, msg))
+		}
+		if err := exchangehttp.Unmarshal(result.Data, out); err != nil {
+			return fmt.Errorf("解析 %s 数据失败: %w", path, err)
+		}
+		return nil
+	}
+}
+
+// ListSubAccounts 子账户列表
+func (c *OKXAccountClient) ListSubAccounts(ctx context.Context) ([]OKXSubAccount, error) {
+	var data []struct {
+		SubAcct string `json:"subAcct"`
+		Label   string `json:"label"`
+		Type    string `json:"type"`
+		Enable  bool   `json:"enable"`
+		Ts      string `json:"ts"`
+	}
+	if err := c.request(ctx, http.MethodGet, "/api/v5/users/subaccount/list", nil, nil, &data); err != nil {
+		return nil, fmt.Errorf("获取子账户列表失败: %w", err)
+	}
+	subs := make([]OKXSubAccount, 0, len(data))
+	for _, d := range data {
+		subs = append(subs, OKXSubAccount{Name: d.SubAcct, Label: d.Label, Type: d.Type, Enabled: d.Enable, Created: okxTime(d.Ts)})
+	}
+	return subs, nil
+}
+
+// okxTradingBalanceData 交易账户余额（/account/balance 与 /account/subaccount/balances 格式相同）
+type okxTradingBalanceData struct {
+	TotalEq string `json:"totalEq"`
+	Details []struct {
+		Ccy       string `json:"ccy"`
+		Eq        string `json:"eq"`
+		AvailBal  string `json:"availBal"`
+		FrozenBal string `json:"frozenBal"`
+	} `json:"details"`
+}
+
+func (d okxTradingBalanceData) balance() *OKXAccountBalance {
+	b := &OKXAccountBalance{TotalEquity: parseOKXFloat(d.TotalEq)}
+	for _, a := range d.Details {
+		b.Assets = append(b.Assets, OKXAssetBalance{
+			Currency:  a.Ccy,
+			Equity:    parseOKXFloat(a.Eq),
+			Available: parseOKXFloat(a.AvailBal),
+			Frozen:    parseOKXFloat(a.FrozenBal),
+		})
+	}
+	return b
+}
+
+// okxFundingBalanceData 资金账户余额
+type okxFundingBalanceData struct {
+	Ccy       string `json:"ccy"`
+	Bal       string `json:"bal"`
+	AvailBal  string `json:"availBal"`
+	FrozenBal string `json:"frozenBal"`
+}
+
+func fundingBalance(data []okxFundingBalanceData) *OKXAccountBalance {
+	b := &OKXAccountBalance{}
+	for _, a := range data {
+		b.Assets = append(b.Assets, OKXAssetBalance{
+			Currency:  a.Ccy,
+			Equity:    parseOKXFloat(a.Bal),
+			Available: parseOKXFloat(a.AvailBal),
+			Frozen:    parseOKXFloat(a.FrozenBal),
+		})
+	}
+	return b
+}
+
+// TradingBalance 交易账户余额（subAccount 为空时查询本账户）
+func (c *OKXAccountClient) TradingBalance(ctx context.Context, subAccount string) (*OKXAccountBalance, error) {
+	path, query := "/api/v5/account/balance", url.Values{}
+	if subAccount != "" {
+		path = "/api/v5/account/subaccount/balances"
+		query.Set("subAcct", subAccount)
+	}
+	var data []okxTradingBalanceData
+	if err := c.request(ctx, http.MethodGet, path, query, nil, &data); err != nil {
+		return nil, fmt.Errorf("获取交易账户余额失败: %w", err)
+	}
+	if len(data) == 0 {
+		return &OKXAccountBalance{}, nil
+	}
+	return data[0].balance(), nil
+}
+
+// FundingBalance 资金账户余额（subAccount 为空时查询本账户；currency 为空返回全部币种）
+func (c *OKXAccountClient) FundingBalance(ctx context.Context, subAccount, currency string) (*OKXAccountBalance, error) {
+	path, query := "/api/v5/asset/balances", url.Values{}
+	if subAccount != "" {
+		path = "/api/v5/asset/subaccount/balances"
+		query.Set("subAcct", subAccount)
+	}
+	if currency != "" {
+		query.Set("ccy", currency)
+	}
+	var data []okxFundingBalanceData
+	if err := c.request(ctx, http.MethodGet, path, query, nil, &data); err != nil {
+		return nil, fmt.Errorf("获取资金账户余额失败: %w", err)
+	}
+	return fundingBalance(data), nil
+}
+
+// newOKXTransferClientID 划转客户端ID（OKX 限制为 1-32 位字母数字）
+func newOKXTransferClientID() string {
+	return "nofx" + strconv.FormatInt(time.Now().UnixNano(), 36)
+}
+
+// Transfer 资金划转（资金账户 ↔ 交易账户 ↔ 子账户）
+func (c *OKXAccountClient) Transfer(ctx context.Context, r OKXTransferRequest) (*OKXTransferResult, error) {
+	if r.Currency == "" || r.Amount <= 0 || math.IsNaN(r.Amount) || math.IsInf(r.Amount, 0) {
+		return nil, fmt.Errorf("划转币种和金额无效: %s %v", r.Currency, r.Amount)
+	}
+	if r.Type == "" {
+		r.Type = OKXTransferInternal
+	}
+	if r.Type != OKXTransferInternal && r.Type != OKXTransferSubToMaster && r.SubAccount == "" {
+		return nil, fmt.Errorf("子账户划转必须指定子账户")
+	}
+	if r.ClientID == "" {
+		r.ClientID = newOKXTransferClientID()
+	}
+
+	payload := map[string]string{
+		"ccy":      r.Currency,
+		"amt":      strconv.FormatFloat(r.Amount, 'f', -1, 64),
+		"from":     string(r.From),
+		"to":       string(r.To),
+		"type":     r.Type,
+		"clientId": r.ClientID,
+	}
+	if r.SubAccount != "" {
+		payload["subAcct"] = r.SubAccount
+	}
+	var data []struct {
+		TransID  string `json:"transId"`
+		ClientID string `json:"clientId"`
+		Ccy      string `json:"ccy"`
+		Amt      string `json:"amt"`
+	}
+	if err := c.request(ctx, http.MethodPost, "/api/v5/asset/transfer", nil, payload, &data); err != nil {
+		return nil, fmt.Errorf("划转 %v %s 失败: %w", r.Amount, r.Currency, err)
+	}
+	if len(data) == 0 {
+		return nil, fmt.Errorf("划转响应为空")
+	}
+	log.Info("💸 OKX 划转成功", "amount", data[0].Amt, "currency", data[0].Ccy, "from", r.From, "to", r.To, "type", r.Type, "sub_account", r.SubAccount, "trans_id", data[0].TransID)
+	return &OKXTransferResult{TransferID: data[0].TransID, ClientID: data[0].ClientID, Currency: data[0].Ccy, Amount: parseOKXFloat(data[0].Amt)}, nil
+}
+
+// OKXProfitSweepConfig 定时把交易账户中超出基准的利润划出
+type OKXProfitSweepConfig struct {
+	Currency   string         // 默认 USDT
+	Baseline   float64        // 交易账户保留的权益，超出部分视为利润
+	MinAmount  float64        // 低于该金额不划转（默认 1）
+	To         OKXAccountType // 划入的账户类型（默认资金账户）
+	SubAccount string         // 可选：划入该子账户（母账户 API Key）
+	Interval   time.Duration  // 划转间隔（默认 24 小时）
+}
+
+// OKXProfitSweeper 利润归集：交易账户权益超过 Baseline 的部分（不超过可用余额）划出交易账户
+type OKXProfitSweeper struct {
+	client *OKXAccountClient
+	cfg    OKXProfitSweepConfig
+}
+
+// NewOKXProfitSweeper 创建利润归集任务
+func NewOKXProfitSweeper(client *OKXAccountClient, cfg OKXProfitSweepConfig) (*OKXProfitSweeper, error) {
+	if cfg.Baseline <= 0 {
+		return nil, fmt.Errorf("利润归集需要设置交易账户保留权益（Baseline > 0）")
+	}
+	if cfg.Currency == "" {
+		cfg.Currency = "USDT"
+	}
+	if cfg.MinAmount <= 0 {
+		cfg.MinAmount = 1
+	}
+	if cfg.To == "" {
+		cfg.To = OKXFundingAccount
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 24 * time.Hour
+	}
+	return &OKXProfitSweeper{client: client, cfg: cfg}, nil
+}
+
+// SweepOnce 执行一次归集，返回划转结果（无可划转利润时返回 nil, nil）
+func (s *OKXProfitSweeper) SweepOnce(ctx context.Context) (*OKXTransferResult, error) {
+	balance, err := s.client.TradingBalance(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+	asset := balance.Asset(s.cfg.Currency)
+	amount := math.Min(asset.Equity-s.cfg.Baseline, asset.Available)
+	amount = math.Floor(amount*100) / 100 // 保留两位小数，避免可用余额精度误差导致划转失败
+	if amount < s.cfg.MinAmount {
+		log.Info("💰 OKX 利润归集：未超过基准，无需划转", "currency", s.cfg.Currency, "equity", asset.Equity, "baseline", s.cfg.Baseline)
+		return nil, nil
+	}
+
+	req := OKXTransferRequest{Currency: s.cfg.Currency, Amount: amount, From: OKXTradingAccount, To: s.cfg.To}
+	if s.cfg.SubAccount != "" {
+		req.Type, req.SubAccount = OKXTransferToSub, s.cfg.SubAccount
+	}
+	return s.client.Transfer(ctx, req)
+}
+
+// Run 按 Interval 定时归集，直到 ctx 取消
+func (s *OKXProfitSweeper) Run(ctx context.Context) error {
+	ticker := time.NewTicker(s.cfg.Interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SweepOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Warn("⚠️ OKX 利润归集失败", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
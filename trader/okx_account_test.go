@@ -0,0 +1,169 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// okxTestServerTime 测试服务器时间（本机时间 + 1.5 秒）
+func okxTestServerTime() time.Time {
+	return time.Now().Add(1500 * time.Millisecond)
+}
+
+func newTestOKXAccountClient(t *testing.T, handler http.HandlerFunc) *OKXAccountClient {
+	t.Helper()
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/api/v5/public/time" {
+			fmt.Fprintf(w, `{"code":"0","msg":"","data":[{"ts":"%d"}]}`, okxTestServerTime().UnixMilli())
+			return
+		}
+		handler(w, r)
+	}))
+	t.Cleanup(server.Close)
+	client := NewOKXAccountClient("key", "secret", "pass", false)
+	client.baseURL = server.URL
+	return client
+}
+
+// verifyOKXSign 校验请求签名头
+func verifyOKXSign(t *testing.T, r *http.Request, body string) {
+	t.Helper()
+	ts := r.Header.Get("OK-ACCESS-TIMESTAMP")
+	if r.Header.Get("OK-ACCESS-KEY") != "key" || r.Header.Get("OK-ACCESS-PASSPHRASE") != "pass" ||
+		r.Header.Get("OK-ACCESS-SIGN") != okxRESTSign("secret", ts, r.Method, r.URL.RequestURI(), body) {
+		t.Errorf("签名无效: %s %s headers=%v", r.Method, r.URL.RequestURI(), r.Header)
+	}
+}
+
+func TestOKXAccountClientSubAccounts(t *testing.T) {
+	client := newTestOKXAccountClient(t, func(w http.ResponseWriter, r *http.Request) {
+		verifyOKXSign(t, r, "")
+		switch r.URL.Path {
+		case "/api/v5/users/subaccount/list":
+			w.Write([]byte(`{"code":"0","msg":"","data":[{"subAcct":"bot1","label":"grid","type":"1","enable":true,"ts":"1700000000000"}]}`))
+		case "/api/v5/account/subaccount/balances":
+			if r.URL.Query().Get("subAcct") != "bot1" {
+				t.Errorf("subAcct = %q", r.URL.Query().Get("subAcct"))
+			}
+			w.Write([]byte(`{"code":"0","msg":"","data":[{"totalEq":"1500.5","details":[{"ccy":"USDT","eq":"1500","availBal":"1200","frozenBal":"300"}]}]}`))
+		default:
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+	})
+
+	subs, err := client.ListSubAccounts(context.Background())
+	if err != nil || len(subs) != 1 || subs[0].Name != "bot1" || !subs[0].Enabled || subs[0].Created.UnixMilli() != 1700000000000 {
+		t.Fatalf("ListSubAccounts() = %+v, %v", subs, err)
+	}
+	balance, err := client.TradingBalance(context.Background(), "bot1")
+	if err != nil {
+		t.Fatalf("TradingBalance() error = %v", err)
+	}
+	if usdt := balance.Asset("USDT"); balance.TotalEquity != 1500.5 || usdt.Available != 1200 || usdt.Frozen != 300 {
+		t.Errorf("TradingBalance() = %+v", balance)
+	}
+}
+
+func TestOKXProfitSweeper(t *testing.T) {
+	var transfer map[string]string
+	client := newTestOKXAccountClient(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		verifyOKXSign(t, r, string(body))
+		switch r.URL.Path {
+		case "/api/v5/account/balance":
+			w.Write([]byte(`{"code":"0","msg":"","data":[{"totalEq":"1260","details":[{"ccy":"USDT","eq":"1250.789","availBal":"1100"}]}]}`))
+		case "/api/v5/asset/transfer":
+			json.Unmarshal(body, &transfer)
+			w.Write([]byte(`{"code":"0","msg":"","data":[{"transId":"754147","clientId":"` + transfer["clientId"] + `","ccy":"USDT","amt":"` + transfer["amt"] + `","from":"18","to":"6"}]}`))
+		}
+	})
+
+	sweeper, err := NewOKXProfitSweeper(client, OKXProfitSweepConfig{Baseline: 1000, SubAccount: "vault"})
+	if err != nil {
+		t.Fatalf("NewOKXProfitSweeper() error = %v", err)
+	}
+	result, err := sweeper.SweepOnce(context.Background())
+	if err != nil {
+		t.Fatalf("SweepOnce() error = %v", err)
+	}
+	if transfer["amt"] != "250.78" || transfer["from"] != "18" || transfer["to"] != "6" || transfer["type"] != OKXTransferToSub || transfer["subAcct"] != "vault" || transfer["clientId"] == "" {
+		t.Errorf("transfer request = %v", transfer)
+	}
+	if result == nil || result.TransferID != "754147" || result.Amount != 250.78 {
+		t.Errorf("SweepOnce() = %+v", result)
+	}
+
+	// 超出基准的利润低于最小划转金额时不划转
+	transfer = nil
+	sweeper.cfg.Baseline = 1250.5
+	if result, err := sweeper.SweepOnce(context.Background()); err != nil || result != nil || transfer != nil {
+		t.Errorf("SweepOnce() = %+v, %v, transfer=%v; want no transfer", result, err, transfer)
+	}
+}
+
+func TestOKXAccountClientErrors(t *testing.T) {
+	client := newTestOKXAccountClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"code":"50113","msg":"Invalid Sign","data":[]}`))
+	})
+	_, err := client.FundingBalance(context.Background(), "", "USDT")
+	if !errors.Is(err, ErrInvalidAPIKey) {
+		t.Errorf("FundingBalance() error = %v, want ErrInvalidAPIKey", err)
+	}
+
+	if _, err := client.Transfer(context.Background(), OKXTransferRequest{Currency: "USDT", Amount: 10, Type: OKXTransferFromSub}); err == nil {
+		t.Error("子账户划转未指定子账户时应返回错误")
+	}
+}
+
+func TestOKXAccountClientTimestampResync(t *testing.T) {
+	var calls int32
+	client := newTestOKXAccountClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// 签名时间戳应按服务器时间校正
+		ts, err := time.Parse("2006-01-02T15:04:05.000Z", r.Header.Get("OK-ACCESS-TIMESTAMP"))
+		if err != nil || ts.Sub(okxTestServerTime()).Abs() > 500*time.Millisecond {
+			t.Errorf("签名时间戳 %s 未按服务器时间校正", r.Header.Get("OK-ACCESS-TIMESTAMP"))
+		}
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.Write([]byte(`{"code":"50102","msg":"Timestamp request expired","data":[]}`))
+			return
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"ccy":"USDT","availBal":"10","bal":"10"}]}`))
+	})
+
+	if _, err := client.FundingBalance(context.Background(), "", "USDT"); err != nil {
+		t.Fatalf("时间戳错误后应同步服务器时间并重发: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Errorf("请求次数 = %d, want 2", n)
+	}
+	if offset := client.ServerClock().Offset(); offset < time.Second || offset > 2*time.Second {
+		t.Errorf("Offset() = %v, want ≈1.5s", offset)
+	}
+}
+
+func TestOKXAccountClientSimulated(t *testing.T) {
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-simulated-trading") != "1" {
+			t.Errorf("模拟盘请求 %s 缺少 x-simulated-trading: 1", r.URL.Path)
+		}
+		if r.URL.Path == "/api/v5/public/time" {
+			fmt.Fprintf(w, `{"code":"0","msg":"","data":[{"ts":"%d"}]}`, time.Now().UnixMilli())
+			return
+		}
+		w.Write([]byte(`{"code":"0","msg":"","data":[{"totalEq":"100","details":[]}]}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewOKXAccountClient("key", "secret", "pass", true)
+	client.baseURL = server.URL
+	if _, err := client.TradingBalance(context.Background(), ""); err != nil {
+		t.Fatalf("TradingBalance() error = %v", err)
+	}
+}
@@ -0,0 +1,555 @@
+package trader
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"nofx/market"
+	"nofx/netconfig"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	okxPrivateWSURL          = "wss://ws.okx.com:8443/ws/v5/private"
+	okxPrivateWSURLSimulated = "wss://wspap.okx.com:8443/ws/v5/private"
+	okxPingInterval          = 20 * time.Second // OKX 30 秒无消息即断开
+	okxReadTimeout           = 60 * time.Second
+	okxLoginTimeout          = 10 * time.Second
+	okxBackoffMin            = time.Second
+	okxBackoffMax            = 30 * time.Second
+)
+
+// okxPrivateChannels 登录后订阅的私有频道（ANY 同时覆盖永续和交割合约）
+var okxPrivateChannels = []map[string]string{
+	{"channel": "orders", "instType": "ANY"},
+	{"channel": "orders-algo", "instType": "ANY"},
+	{"channel": "positions", "instType": "ANY"},
+	{"channel": "account"},
+}
+
+// OKXPrivateStreamConfig OKX 私有推送配置
+type OKXPrivateStreamConfig struct {
+	APIKey     string
+	SecretKey  string
+	Passphrase string
+	Simulated  bool         // 模拟盘
+	URL        string       // 覆盖连接地址（测试用）
+	Clock      *ServerClock // 服务器时钟（登录时间戳按偏移校正，nil=使用本机时间；可用 OKXAccountClient.ServerClock()）
+}
+
+// OKXPrivateStream OKX 私有 WebSocket（登录后订阅订单、止盈止损、持仓、账户频道）
+// 推送解析为 AccountEvent 发布到事件总线，并维护最新持仓/余额快照：
+// 连接正常且收到过快照时 Positions/Balance 可直接替代 REST 轮询缓存
+type OKXPrivateStream struct {
+	cfg OKXPrivateStreamConfig
+	bus *AccountEventBus
+
+	mu        sync.RWMutex
+	connected bool
+	positions map[string]okxPositionEntry // instId/posSide -> 持仓
+	posSynced bool                        // 本次连接是否收到过持仓快照
+	balance   *BalanceUpdate
+}
+
+type okxPositionEntry struct {
+	symbol string
+	PositionUpdate
+}
+
+// NewOKXPrivateStream 创建私有推送（bus 为 nil 时只维护快照）
+func NewOKXPrivateStream(cfg OKXPrivateStreamConfig, bus *AccountEventBus) *OKXPrivateStream {
+	if cfg.URL == "" {
+		cfg.URL = netconfig.BaseURL("okx-ws", okxPrivateWSURL)
+		if cfg.Simulated {
+			cfg.URL = okxPrivateWSURLSimulated
+		}
+	}
+	return &OKXPrivateStream{cfg: cfg, bus: bus, positions: make(map[string]okxPositionEntry)}
+}
+
+// Start 后台运行推送（断线自动重连，ctx 取消时退出）
+func (s *OKXPrivateStream) Start(ctx context.Context) {
+	go s.Run(ctx)
+}
+
+// Run 连接循环：断线后按指数退避重连，直到 ctx 取消
+func (s *OKXPrivateStream) Run(ctx context.Context) {
+	backoff := okxBackoffMin
+	for {
+		connected, err := s.session(ctx)
+		s.setDisconnected()
+		if ctx.Err() != nil {
+			log.Info("⏹ OKX 私有推送已停止")
+			return
+		}
+		if connected {
+			backoff = okxBackoffMin
+		}
+		log.Warn("⚠️ OKX 私有推送断开，稍后重连", "error", err, "backoff", backoff)
+		if sleepContext(ctx, backoff) != nil {
+			log.Info("⏹ OKX 私有推送已停止")
+			return
+		}
+		backoff = min(backoff*2, okxBackoffMax)
+	}
+}
+
+// Connected 推送是否已登录并订阅成功
+func (s *OKXPrivateStream) Connected() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.connected
+}
+
+// Positions 推送维护的持仓快照（与 Trader.GetPositions 返回格式一致）
+// ok=false 表示推送未连接或尚未收到持仓快照，调用方应回退到 REST 查询
+func (s *OKXPrivateStream) Positions() (positions []map[string]interface{}, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.connected || !s.posSynced {
+		return nil, false
+	}
+	positions = make([]map[string]interface{}, 0, len(s.positions))
+	for _, p := range s.positions {
+		positions = append(positions, map[string]interface{}{
+			"symbol":           p.symbol,
+			"side":             p.Side,
+			"positionAmt":      p.Quantity,
+			"entryPrice":       p.EntryPrice,
+			"markPrice":        p.MarkPrice,
+			"unRealizedProfit": p.UnrealizedPnL,
+			"leverage":         p.Leverage,
+			"liquidationPrice": p.LiquidationPrice,
+		})
+	}
+	return positions, true
+}
+
+// Balance 推送维护的最新余额（ok=false 时应回退到 REST 查询）
+func (s *OKXPrivateStream) Balance() (balance BalanceUpdate, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if !s.connected || s.balance == nil {
+		return BalanceUpdate{}, false
+	}
+	return *s.balance, true
+}
+
+func (s *OKXPrivateStream) setDisconnected() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connected = false
+	s.posSynced = false
+}
+
+// session 建立一次连接：登录、订阅，然后持续读取推送
+func (s *OKXPrivateStream) session(ctx context.Context) (bool, error) {
+	dialer := websocket.Dialer{HandshakeTimeout: 10 * time.Second, Proxy: netconfig.Proxy}
+	conn, _, err := dialer.DialContext(ctx, s.cfg.URL, http.Header{})
+	if err != nil {
+		return false, fmt.Errorf("连接失败: %w", err)
+	}
+	defer conn.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if err := s.login(conn); err != nil {
+		return false, err
+	}
+	if err := conn.WriteJSON(map[string]interface{}{"op": "subscribe", "args": okxPrivateChannels}); err != nil {
+		return false, fmt.Errorf("订阅失败: %w", err)
+	}
+
+	s.mu.Lock()
+	s.connected = true
+	s.mu.Unlock()
+	log.Info("✅ OKX 私有推送已连接（订单/止盈止损/持仓/账户）")
+
+	go func() {
+		ticker := time.NewTicker(okxPingInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := conn.WriteMessage(websocket.TextMessage, []byte("ping")); err != nil {
+					return
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(okxReadTimeout))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return true, err
+		}
+		if err := s.handleMessage(message); err != nil {
+			return true, err
+		}
+	}
+}
+
+// login 发送登录请求并等待结果
+func (s *OKXPrivateStream) login(conn *websocket.Conn) error {
+	ts := strconv.FormatInt(s.cfg.Clock.Now().Unix(), 10)
+	login := map[string]interface{}{
+		"op": "login",
+		"args": []map[string]string{{
+			"apiKey":     s.cfg.APIKey,
+			"passphrase": s.cfg.Passphrase,
+			"timestamp":  ts,
+			"sign":       okxLoginSign(s.cfg.SecretKey, ts),
+		}},
+	}
+	if err := conn.WriteJSON(login); err != nil {
+		return fmt.Errorf("发送登录请求失败: %w", err)
+	}
+
+	conn.SetReadDeadline(time.Now().Add(okxLoginTimeout))
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return fmt.Errorf("等待登录结果失败: %w", err)
+		}
+		var resp okxWSEvent
+		if json.Unmarshal(message, &resp) != nil {
+			continue
+		}
+		switch resp.Event {
+		case "login":
+			if resp.Code != "" && resp.Code != "0" {
+				return s.loginError(resp)
+			}
+			return nil
+		case "error":
+			return s.loginError(resp)
+		}
+	}
+}
+
+// loginError 登录失败错误；时间戳错误时重新同步服务器时间，重连时使用校正后的时间戳
+func (s *OKXPrivateStream) loginError(resp okxWSEvent) error {
+	err := NewExchangeError("okx", resp.Code, resp.Msg, fmt.Errorf("OKX 私有推送登录失败: %s %s", resp.Code, resp.Msg))
+	if errors.Is(err, ErrTimestamp) {
+		s.cfg.Clock.Resync()
+	}
+	return err
+}
+
+// okxLoginSign 登录签名：Base64(HMAC-SHA256(secret, timestamp + "GET" + "/users/self/verify"))
+func okxLoginSign(secret, timestamp string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "GET" + "/users/self/verify"))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// okxWSEvent 事件/推送消息
+type okxWSEvent struct {
+	Event string `json:"event"`
+	Code  string `json:"code"`
+	Msg   string `json:"msg"`
+	Arg   struct {
+		Channel string `json:"channel"`
+	} `json:"arg"`
+	Data json.RawMessage `json:"data"`
+}
+
+// handleMessage 处理一条推送（返回错误时断开重连）
+func (s *OKXPrivateStream) handleMessage(message []byte) error {
+	if string(message) == "pong" {
+		return nil
+	}
+	var msg okxWSEvent
+	if err := json.Unmarshal(message, &msg); err != nil {
+		log.Warn("⚠️ OKX 私有推送解析失败", "error", err)
+		return nil
+	}
+	switch msg.Event {
+	case "subscribe":
+		return nil
+	case "error":
+		return NewExchangeError("okx", msg.Code, msg.Msg, fmt.Errorf("OKX 私有推送错误: %s %s", msg.Code, msg.Msg))
+	}
+	if len(msg.Data) == 0 {
+		return nil
+	}
+
+	var err error
+	switch msg.Arg.Channel {
+	case "orders":
+		err = s.handleOrders(msg.Data)
+	case "orders-algo":
+		err = s.handleAlgoOrders(msg.Data)
+	case "positions":
+		err = s.handlePositions(msg.Data)
+	case "account":
+		err = s.handleAccount(msg.Data)
+	}
+	if err != nil {
+		log.Warn("⚠️ OKX 推送解析失败", "channel", msg.Arg.Channel, "error", err)
+	}
+	return nil
+}
+
+type okxOrderPush struct {
+	InstID     string `json:"instId"`
+	OrdID      string `json:"ordId"`
+	ClOrdID    string `json:"clOrdId"`
+	Side       string `json:"side"`
+	PosSide    string `json:"posSide"`
+	OrdType    string `json:"ordType"`
+	State      string `json:"state"`
+	Category   string `json:"category"`
+	Px         string `json:"px"`
+	Sz         string `json:"sz"`
+	AccFillSz  string `json:"accFillSz"`
+	AvgPx      string `json:"avgPx"`
+	FillPx     string `json:"fillPx"`
+	FillSz     string `json:"fillSz"`
+	Fee        string `json:"fee"`
+	Pnl        string `json:"pnl"`
+	ReduceOnly string `json:"reduceOnly"`
+	UTime      string `json:"uTime"`
+}
+
+func (s *OKXPrivateStream) handleOrders(data json.RawMessage) error {
+	var orders []okxOrderPush
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return err
+	}
+	for _, o := range orders {
+		update := &OrderUpdate{
+			OrderID:       o.OrdID,
+			ClientOrderID: o.ClOrdID,
+			Side:          o.Side,
+			PositionSide:  o.PosSide,
+			OrderType:     o.OrdType,
+			State:         o.State,
+			Category:      o.Category,
+			Price:         parseOKXFloat(o.Px),
+			Quantity:      parseOKXFloat(o.Sz),
+			FilledQty:     parseOKXFloat(o.AccFillSz),
+			AvgPrice:      parseOKXFloat(o.AvgPx),
+			FillPrice:     parseOKXFloat(o.FillPx),
+			FillQty:       parseOKXFloat(o.FillSz),
+			Fee:           parseOKXFloat(o.Fee),
+			RealizedPnL:   parseOKXFloat(o.Pnl),
+			ReduceOnly:    o.ReduceOnly == "true",
+		}
+
+		eventType := AccountEventOrder
+		switch {
+		case strings.Contains(o.Category, "liquidation") || o.Category == "adl":
+			eventType = AccountEventLiquidation
+			log.Error("🚨 OKX 强平/自动减仓成交", "symbol", okxInstToSymbol(o.InstID), "category", o.Category, "fill_qty", update.FillQty, "fill_price", update.FillPrice)
+		case update.FillQty > 0:
+			eventType = AccountEventFill
+		}
+		s.publish(AccountEvent{Type: eventType, Symbol: okxInstToSymbol(o.InstID), Time: okxTime(o.UTime), Order: update})
+	}
+	return nil
+}
+
+type okxAlgoOrderPush struct {
+	InstID      string `json:"instId"`
+	AlgoID      string `json:"algoId"`
+	AlgoClOrdID string `json:"algoClOrdId"`
+	OrdID       string `json:"ordId"`
+	Side        string `json:"side"`
+	PosSide     string `json:"posSide"`
+	OrdType     string `json:"ordType"`
+	State       string `json:"state"`
+	Sz          string `json:"sz"`
+	TpTriggerPx string `json:"tpTriggerPx"`
+	SlTriggerPx string `json:"slTriggerPx"`
+	TriggerPx   string `json:"triggerPx"`
+	ActualPx    string `json:"actualPx"`
+	TriggerTime string `json:"triggerTime"`
+	UTime       string `json:"uTime"`
+}
+
+// handleAlgoOrders 止盈止损单推送：state=effective 表示已触发
+func (s *OKXPrivateStream) handleAlgoOrders(data json.RawMessage) error {
+	var orders []okxAlgoOrderPush
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return err
+	}
+	for _, o := range orders {
+		triggerPx := parseOKXFloat(o.TriggerPx)
+		if triggerPx == 0 {
+			triggerPx = max(parseOKXFloat(o.SlTriggerPx), parseOKXFloat(o.TpTriggerPx))
+		}
+		update := &OrderUpdate{
+			OrderID:       o.AlgoID,
+			ClientOrderID: o.AlgoClOrdID,
+			Side:          o.Side,
+			PositionSide:  o.PosSide,
+			OrderType:     o.OrdType,
+			State:         o.State,
+			Quantity:      parseOKXFloat(o.Sz),
+			Price:         parseOKXFloat(o.ActualPx),
+			TriggerPrice:  triggerPx,
+		}
+		eventType := AccountEventOrder
+		ts := o.UTime
+		if o.State == "effective" {
+			eventType = AccountEventTrigger
+			if o.TriggerTime != "" {
+				ts = o.TriggerTime
+			}
+		}
+		s.publish(AccountEvent{Type: eventType, Symbol: okxInstToSymbol(o.InstID), Time: okxTime(ts), Order: update})
+	}
+	return nil
+}
+
+type okxPositionPush struct {
+	InstID  string `json:"instId"`
+	PosSide string `json:"posSide"`
+	Pos     string `json:"pos"`
+	AvgPx   string `json:"avgPx"`
+	MarkPx  string `json:"markPx"`
+	Upl     string `json:"upl"`
+	Lever   string `json:"lever"`
+	LiqPx   string `json:"liqPx"`
+	MgnMode string `json:"mgnMode"`
+	UTime   string `json:"uTime"`
+}
+
+// handlePositions 持仓推送（订阅后首条为全量快照，之后为变化的持仓；数量为 0 表示已平仓）
+func (s *OKXPrivateStream) handlePositions(data json.RawMessage) error {
+	var positions []okxPositionPush
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return err
+	}
+
+	events := make([]AccountEvent, 0, len(positions))
+	s.mu.Lock()
+	if !s.posSynced {
+		s.positions = make(map[string]okxPositionEntry)
+		s.posSynced = true
+	}
+	for _, p := range positions {
+		qty := parseOKXFloat(p.Pos)
+		side := p.PosSide
+		if side == "net" || side == "" {
+			// 单向持仓模式：数量的正负表示方向（平仓推送数量为 0，方向未知）
+			switch {
+			case qty > 0:
+				side = "long"
+			case qty < 0:
+				side = "short"
+			default:
+				side = ""
+			}
+		}
+		if qty < 0 {
+			qty = -qty
+		}
+		update := PositionUpdate{
+			Side:             side,
+			Quantity:         qty,
+			EntryPrice:       parseOKXFloat(p.AvgPx),
+			MarkPrice:        parseOKXFloat(p.MarkPx),
+			UnrealizedPnL:    parseOKXFloat(p.Upl),
+			Leverage:         parseOKXFloat(p.Lever),
+			LiquidationPrice: parseOKXFloat(p.LiqPx),
+			MarginMode:       p.MgnMode,
+		}
+		symbol := okxInstToSymbol(p.InstID)
+		key := p.InstID + "/" + p.PosSide
+		if qty == 0 {
+			delete(s.positions, key)
+		} else {
+			s.positions[key] = okxPositionEntry{symbol: symbol, PositionUpdate: update}
+		}
+		events = append(events, AccountEvent{Type: AccountEventPosition, Symbol: symbol, Time: okxTime(p.UTime), Position: &update})
+	}
+	s.mu.Unlock()
+
+	for _, ev := range events {
+		s.publish(ev)
+	}
+	return nil
+}
+
+type okxAccountPush struct {
+	TotalEq string `json:"totalEq"`
+	UTime   string `json:"uTime"`
+	Details []struct {
+		Ccy     string `json:"ccy"`
+		Eq      string `json:"eq"`
+		AvailEq string `json:"availEq"`
+		Upl     string `json:"upl"`
+	} `json:"details"`
+}
+
+// handleAccount 账户推送（只关注 USDT 保证金）
+func (s *OKXPrivateStream) handleAccount(data json.RawMessage) error {
+	var accounts []okxAccountPush
+	if err := json.Unmarshal(data, &accounts); err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		balance := &BalanceUpdate{TotalEquity: parseOKXFloat(a.TotalEq), Currency: "USDT"}
+		for _, d := range a.Details {
+			if d.Ccy == "USDT" {
+				balance.Equity = parseOKXFloat(d.Eq)
+				balance.Available = parseOKXFloat(d.AvailEq)
+				balance.UnrealizedProfit = parseOKXFloat(d.Upl)
+			}
+		}
+		s.mu.Lock()
+		s.balance = balance
+		s.mu.Unlock()
+		s.publish(AccountEvent{Type: AccountEventBalance, Time: okxTime(a.UTime), Balance: balance})
+	}
+	return nil
+}
+
+func (s *OKXPrivateStream) publish(ev AccountEvent) {
+	if s.bus == nil {
+		return
+	}
+	ev.Exchange = "okx"
+	s.bus.Publish(ev)
+}
+
+// okxInstToSymbol BTC-USDT-SWAP -> BTCUSDT（按币种配置了币本位/交割合约时还原为配置的币种）
+func okxInstToSymbol(instID string) string {
+	return market.OKXSymbolFromInstID(instID)
+}
+
+func parseOKXFloat(s string) float64 {
+	v, _ := strconv.ParseFloat(s, 64)
+	return v
+}
+
+// okxTime 解析毫秒时间戳（为空时取当前时间）
+func okxTime(ms string) time.Time {
+	if v, err := strconv.ParseInt(ms, 10, 64); err == nil && v > 0 {
+		return time.UnixMilli(v)
+	}
+	return time.Now()
+}
@@ -0,0 +1,150 @@
+package trader
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+func TestOKXLoginSign(t *testing.T) {
+	// Base64(HMAC-SHA256(secret, "1538054050GET/users/self/verify"))
+	if got := okxLoginSign("22582BD0CFF14C41EDBF1AB98506286D", "1538054050"); got != "+LdIr8lkkvhr5hoA3g9TMC0+uQJ849ftAcocA/ouu4M=" {
+		t.Errorf("okxLoginSign() = %s", got)
+	}
+}
+
+// startOKXPrivateServer 模拟 OKX 私有推送：校验登录后依次推送 pushes
+func startOKXPrivateServer(t *testing.T, loginCode string, pushes []string) *httptest.Server {
+	t.Helper()
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var login struct {
+			Op   string              `json:"op"`
+			Args []map[string]string `json:"args"`
+		}
+		if err := conn.ReadJSON(&login); err != nil || login.Op != "login" || login.Args[0]["apiKey"] != "key" ||
+			login.Args[0]["sign"] != okxLoginSign("secret", login.Args[0]["timestamp"]) {
+			t.Errorf("unexpected login request: %+v, err=%v", login, err)
+			return
+		}
+		conn.WriteMessage(websocket.TextMessage, []byte(`{"event":"login","code":"`+loginCode+`","msg":""}`))
+		if loginCode != "0" {
+			return
+		}
+
+		var sub struct {
+			Op   string              `json:"op"`
+			Args []map[string]string `json:"args"`
+		}
+		if err := conn.ReadJSON(&sub); err != nil || sub.Op != "subscribe" || len(sub.Args) != len(okxPrivateChannels) {
+			t.Errorf("unexpected subscribe request: %+v, err=%v", sub, err)
+			return
+		}
+		for _, push := range pushes {
+			conn.WriteMessage(websocket.TextMessage, []byte(push))
+		}
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func waitEvent(t *testing.T, events <-chan AccountEvent) AccountEvent {
+	t.Helper()
+	select {
+	case ev := <-events:
+		return ev
+	case <-time.After(2 * time.Second):
+		t.Fatal("timeout waiting for account event")
+		return AccountEvent{}
+	}
+}
+
+func TestOKXPrivateStreamEvents(t *testing.T) {
+	server := startOKXPrivateServer(t, "0", []string{
+		`{"event":"subscribe","arg":{"channel":"orders","instType":"SWAP"}}`,
+		`{"arg":{"channel":"positions","instType":"SWAP"},"data":[{"instId":"BTC-USDT-SWAP","posSide":"net","pos":"-0.5","avgPx":"60000","markPx":"59900","upl":"50","lever":"5","liqPx":"70000","mgnMode":"isolated","uTime":"1700000000000"}]}`,
+		`{"arg":{"channel":"orders","instType":"SWAP"},"data":[{"instId":"BTC-USDT-SWAP","ordId":"1","clOrdId":"c1","side":"sell","posSide":"net","ordType":"market","state":"filled","category":"normal","sz":"0.5","accFillSz":"0.5","avgPx":"60000","fillPx":"60000","fillSz":"0.5","fee":"-1.2","pnl":"0","reduceOnly":"false","uTime":"1700000000001"}]}`,
+		`{"arg":{"channel":"orders-algo","instType":"SWAP"},"data":[{"instId":"BTC-USDT-SWAP","algoId":"a1","side":"buy","ordType":"conditional","state":"effective","sz":"0.5","slTriggerPx":"61000","triggerTime":"1700000000002"}]}`,
+		`{"arg":{"channel":"orders","instType":"SWAP"},"data":[{"instId":"ETH-USDT-SWAP","ordId":"2","side":"sell","state":"filled","category":"full_liquidation","fillPx":"1900","fillSz":"3","uTime":"1700000000003"}]}`,
+		`{"arg":{"channel":"account"},"data":[{"totalEq":"1234.5","uTime":"1700000000004","details":[{"ccy":"USDT","eq":"1200","availEq":"800","upl":"50"}]}]}`,
+	})
+
+	bus := NewAccountEventBus()
+	events, unsubscribe := bus.Subscribe(16)
+	defer unsubscribe()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := NewOKXPrivateStream(OKXPrivateStreamConfig{
+		APIKey: "key", SecretKey: "secret", Passphrase: "pass",
+		URL: "ws" + strings.TrimPrefix(server.URL, "http"),
+	}, bus)
+	stream.Start(ctx)
+
+	ev := waitEvent(t, events)
+	if ev.Type != AccountEventPosition || ev.Symbol != "BTCUSDT" || ev.Position.Side != "short" || ev.Position.Quantity != 0.5 {
+		t.Errorf("unexpected position event: %+v %+v", ev, ev.Position)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventFill || ev.Order.FillQty != 0.5 || ev.Order.ClientOrderID != "c1" || ev.Exchange != "okx" {
+		t.Errorf("unexpected fill event: %+v %+v", ev, ev.Order)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventTrigger || ev.Order.TriggerPrice != 61000 || ev.Time.UnixMilli() != 1700000000002 {
+		t.Errorf("unexpected trigger event: %+v %+v", ev, ev.Order)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventLiquidation || ev.Symbol != "ETHUSDT" {
+		t.Errorf("unexpected liquidation event: %+v", ev)
+	}
+	ev = waitEvent(t, events)
+	if ev.Type != AccountEventBalance || ev.Balance.Available != 800 || ev.Balance.TotalEquity != 1234.5 {
+		t.Errorf("unexpected balance event: %+v %+v", ev, ev.Balance)
+	}
+
+	positions, ok := stream.Positions()
+	if !ok || len(positions) != 1 || positions[0]["symbol"] != "BTCUSDT" || positions[0]["side"] != "short" {
+		t.Errorf("Positions() = %v, %v", positions, ok)
+	}
+	if balance, ok := stream.Balance(); !ok || balance.Equity != 1200 {
+		t.Errorf("Balance() = %+v, %v", balance, ok)
+	}
+
+	cancel()
+	deadline := time.Now().Add(2 * time.Second)
+	for stream.Connected() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, ok := stream.Positions(); ok {
+		t.Error("断开后持仓快照应失效，回退到 REST 查询")
+	}
+}
+
+func TestOKXPrivateStreamLoginFailure(t *testing.T) {
+	server := startOKXPrivateServer(t, "60009", nil)
+	stream := NewOKXPrivateStream(OKXPrivateStreamConfig{
+		APIKey: "key", SecretKey: "secret",
+		URL: "ws" + strings.TrimPrefix(server.URL, "http"),
+	}, nil)
+
+	connected, err := stream.session(context.Background())
+	if connected || err == nil || !strings.Contains(err.Error(), "60009") {
+		t.Errorf("session() = %v, %v; want login error", connected, err)
+	}
+}
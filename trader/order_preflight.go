@@ -0,0 +1,79 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"nofx/market"
+)
+
+// OrderLimits 交易所对单笔订单的最小下单要求（字段为 0 表示无此限制）
+type OrderLimits struct {
+	MinQty      float64 // 最小下单数量（标的币）
+	MinNotional float64 // 最小名义价值（USDT）
+	StepSize    float64 // 数量步进值（标的币）
+}
+
+// OrderLimitsProvider 能提供最小下单要求的交易器
+type OrderLimitsProvider interface {
+	// OrderLimits 获取交易对的最小下单要求
+	OrderLimits(ctx context.Context, symbol string) (OrderLimits, error)
+}
+
+// OKXOrderLimits 由 OKX 合约规格换算最小下单要求
+// U本位：最小数量 = minSz × ctVal（名义价值 = minSz × ctVal × 价格）；币本位：最小名义价值 = minSz × ctVal（美元）
+func OKXOrderLimits(inst *market.OKXInstrument) OrderLimits {
+	if inst.Inverse() {
+		return OrderLimits{MinNotional: inst.MinSz * inst.CtVal}
+	}
+	return OrderLimits{MinQty: inst.MinSz * inst.CtVal, StepSize: inst.LotSz * inst.CtVal}
+}
+
+// MinViableQuantity 按价格计算满足最小下单要求的最小数量（按步进值向上取整）
+func (l OrderLimits) MinViableQuantity(price float64) float64 {
+	minQty := l.MinQty
+	if l.MinNotional > 0 && price > 0 {
+		minQty = math.Max(minQty, l.MinNotional/price)
+	}
+	if l.StepSize > 0 && minQty > 0 {
+		// 减一个极小值避免 0.3/0.1 = 3.0000000004 向上取整为 4
+		minQty = math.Ceil(minQty/l.StepSize-1e-9) * l.StepSize
+	}
+	return minQty
+}
+
+// ValidateOrderSize 下单前校验订单是否满足最小下单要求，以及最小可下单量所需保证金是否足够
+// 不满足时返回带最小可下单量的错误（errors.Is 可命中 ErrMinNotional / ErrInsufficientMargin），
+// 避免提交后被交易所拒绝白白浪费一个交易周期
+func ValidateOrderSize(symbol string, limits OrderLimits, quantity, price float64, leverage int, availableBalance float64) error {
+	minQty := limits.MinViableQuantity(price)
+	if minQty <= 0 || quantity >= minQty-1e-12 {
+		return nil
+	}
+	if leverage <= 0 {
+		leverage = 1
+	}
+
+	minNotional := minQty * price
+	requiredMargin := minNotional / float64(leverage)
+	if requiredMargin > availableBalance {
+		return fmt.Errorf("%w: %s 订单金额 %.2f USDT 低于最小下单量 %.6f（%.2f USDT），按 %dx 杠杆最小下单量需保证金 %.2f USDT，可用 %.2f USDT",
+			ErrInsufficientMargin, symbol, quantity*price, minQty, minNotional, leverage, requiredMargin, availableBalance)
+	}
+	return fmt.Errorf("%w: %s 订单金额 %.2f USDT（数量 %.6f）低于最小下单量 %.6f（%.2f USDT，需保证金 %.2f USDT）。建议：开仓金额至少 %.2f USDT",
+		ErrMinNotional, symbol, quantity*price, quantity, minQty, minNotional, requiredMargin, minNotional)
+}
+
+// preflightOrder 开仓前校验最小下单要求（交易器不提供下单要求或获取失败时跳过）
+func (at *AutoTrader) preflightOrder(symbol string, quantity, price float64, leverage int, availableBalance float64) error {
+	if at.orderLimits == nil {
+		return nil
+	}
+	limits, err := at.orderLimits.OrderLimits(at.ctx(), symbol)
+	if err != nil {
+		log.Warn("  ⚠ 获取最小下单要求失败，跳过下单前校验", "symbol", symbol, "error", err)
+		return nil
+	}
+	return ValidateOrderSize(symbol, limits, quantity, price, leverage, availableBalance)
+}
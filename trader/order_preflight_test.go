@@ -0,0 +1,75 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"nofx/market"
+)
+
+func TestValidateOrderSize(t *testing.T) {
+	limits := OrderLimits{MinNotional: 10, StepSize: 0.001}
+
+	if err := ValidateOrderSize("BTCUSDT", limits, 0.001, 20000, 5, 100); err != nil {
+		t.Errorf("满足最小名义价值的订单不应报错: %v", err)
+	}
+
+	// 0.0004 × 20000 = 8 USDT < 10 USDT，最小可下单量 0.0005 向上取整为 0.001
+	err := ValidateOrderSize("BTCUSDT", limits, 0.0004, 20000, 5, 100)
+	if !errors.Is(err, ErrMinNotional) || !strings.Contains(err.Error(), "0.001000") || !strings.Contains(err.Error(), "20.00 USDT") {
+		t.Errorf("ValidateOrderSize() error = %v, want ErrMinNotional 并给出最小可下单量", err)
+	}
+
+	// 最小可下单量需保证金 20/5=4 USDT > 可用 3 USDT
+	err = ValidateOrderSize("BTCUSDT", limits, 0.0004, 20000, 5, 3)
+	if !errors.Is(err, ErrInsufficientMargin) {
+		t.Errorf("ValidateOrderSize() error = %v, want ErrInsufficientMargin", err)
+	}
+
+	if err := ValidateOrderSize("BTCUSDT", OrderLimits{}, 0.00001, 20000, 5, 0); err != nil {
+		t.Errorf("无下单限制时不应报错: %v", err)
+	}
+}
+
+func TestOKXOrderLimits(t *testing.T) {
+	// U本位：minSz 0.1 张 × 面值 0.01 BTC = 0.001 BTC
+	linear := OKXOrderLimits(&market.OKXInstrument{CtType: "linear", CtVal: 0.01, LotSz: 0.1, MinSz: 0.1})
+	if got := linear.MinViableQuantity(60000); math.Abs(got-0.001) > 1e-12 {
+		t.Errorf("U本位 MinViableQuantity() = %v, want 0.001", got)
+	}
+
+	// 币本位：minSz 1 张 × 面值 100 USD，价格 50000 时最小 0.002 BTC
+	inverse := OKXOrderLimits(&market.OKXInstrument{CtType: "inverse", CtVal: 100, LotSz: 1, MinSz: 1})
+	if got := inverse.MinViableQuantity(50000); math.Abs(got-0.002) > 1e-12 {
+		t.Errorf("币本位 MinViableQuantity() = %v, want 0.002", got)
+	}
+}
+
+type stubOrderLimits struct {
+	limits OrderLimits
+	err    error
+}
+
+func (s stubOrderLimits) OrderLimits(ctx context.Context, symbol string) (OrderLimits, error) {
+	return s.limits, s.err
+}
+
+func TestPreflightOrder(t *testing.T) {
+	at := &AutoTrader{}
+	if err := at.preflightOrder("BTCUSDT", 0.0001, 20000, 5, 100); err != nil {
+		t.Errorf("交易器不支持下单要求时应跳过校验: %v", err)
+	}
+
+	at.orderLimits = stubOrderLimits{err: errors.New("exchangeInfo unavailable")}
+	if err := at.preflightOrder("BTCUSDT", 0.0001, 20000, 5, 100); err != nil {
+		t.Errorf("获取下单要求失败时应跳过校验: %v", err)
+	}
+
+	at.orderLimits = stubOrderLimits{limits: OrderLimits{MinNotional: 10}}
+	if err := at.preflightOrder("BTCUSDT", 0.0001, 20000, 5, 100); !errors.Is(err, ErrMinNotional) {
+		t.Errorf("preflightOrder() error = %v, want ErrMinNotional", err)
+	}
+}
@@ -1,6 +1,7 @@
 package trader
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -124,20 +125,21 @@ func TestOrderStrategy_MarketOnly(t *testing.T) {
 	trader := newFuturesTraderWithClient(client, "market_only", -0.03, 60)
 
 	// 測試開多倉
-	result, err := trader.OpenLong("BTCUSDT", 100.0, 10)
+	result, err := trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("market_only 策略開多倉失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12345 {
-		t.Fatalf("預期 OrderID=12345, 實際 %v (type: %T)", result["orderId"], result["orderId"])
+	if result.OrderID != 12345 {
+		t.Fatalf("預期 OrderID=12345, 實際 %v", result.OrderID)
 	}
 
-	// Status might be OrderStatusType enum or string, so check the value not the type
-	statusStr := fmt.Sprintf("%v", result["status"])
-	if statusStr != "FILLED" {
-		t.Fatalf("預期 Status=FILLED, 實際 %v (type: %T)", result["status"], result["status"])
+	if result.Status != "FILLED" {
+		t.Fatalf("預期 Status=FILLED, 實際 %v", result.Status)
+	}
+
+	if result.Venue != VenueBinance {
+		t.Fatalf("預期 Venue=%s, 實際 %v", VenueBinance, result.Venue)
 	}
 
 	t.Logf("✅ market_only 策略測試通過")
@@ -250,20 +252,17 @@ func TestOrderStrategy_LimitOnly(t *testing.T) {
 	trader := newFuturesTraderWithClient(client, "limit_only", -0.03, 60)
 
 	// 測試開多倉
-	result, err := trader.OpenLong("BTCUSDT", 100.0, 10)
+	result, err := trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("limit_only 策略開多倉失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12346 {
-		t.Fatalf("預期 OrderID=12346, 實際 %v (type: %T)", result["orderId"], result["orderId"])
+	if result.OrderID != 12346 {
+		t.Fatalf("預期 OrderID=12346, 實際 %v", result.OrderID)
 	}
 
-	// Status might be OrderStatusType enum or string, so check the value not the type
-	statusStr := fmt.Sprintf("%v", result["status"])
-	if statusStr != "NEW" {
-		t.Fatalf("預期 Status=NEW (限價單未成交), 實際 %v (type: %T)", result["status"], result["status"])
+	if result.Status != "NEW" {
+		t.Fatalf("預期 Status=NEW (限價單未成交), 實際 %v", result.Status)
 	}
 
 	t.Logf("✅ limit_only 策略測試通過 - 限價單創建成功，不會自動轉換為市價單")
@@ -387,20 +386,17 @@ func TestOrderStrategy_ConservativeHybrid_Success(t *testing.T) {
 	trader := newFuturesTraderWithClient(client, "conservative_hybrid", -0.03, 5)
 
 	// 測試開多倉
-	result, err := trader.OpenLong("BTCUSDT", 100.0, 10)
+	result, err := trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("conservative_hybrid 策略開多倉失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12347 {
-		t.Fatalf("預期 OrderID=12347, 實際 %v (type: %T)", result["orderId"], result["orderId"])
+	if result.OrderID != 12347 {
+		t.Fatalf("預期 OrderID=12347, 實際 %v", result.OrderID)
 	}
 
-	// Status might be OrderStatusType enum or string, so check the value not the type
-	statusStr := fmt.Sprintf("%v", result["status"])
-	if statusStr != "FILLED" {
-		t.Fatalf("預期 Status=FILLED (限價單成交), 實際 %v (type: %T)", result["status"], result["status"])
+	if result.Status != "FILLED" {
+		t.Fatalf("預期 Status=FILLED (限價單成交), 實際 %v", result.Status)
 	}
 
 	t.Logf("✅ conservative_hybrid 策略測試通過 - 限價單成功成交")
@@ -483,19 +479,21 @@ func TestOrderStrategy_ConservativeHybrid_Timeout(t *testing.T) {
 	trader.client.HTTPClient = mockServer.Client()
 
 	// 測試開多倉 - 應該超時並轉換為市價單
-	result, err := trader.OpenLong("BTCUSDT", 100.0, 10)
+	result, err := trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("conservative_hybrid 策略超時轉換失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12349 {
-		t.Errorf("預期轉換後的市價單 OrderID=12349, 實際 %v", result["orderId"])
+	if result.OrderID != 12349 {
+		t.Errorf("預期轉換後的市價單 OrderID=12349, 實際 %v", result.OrderID)
+	}
+
+	if result.Status != "FILLED" {
+		t.Errorf("預期市價單 Status=FILLED, 實際 %v", result.Status)
 	}
 
-	status, ok := result["status"].(string)
-	if !ok || status != "FILLED" {
-		t.Errorf("預期市價單 Status=FILLED, 實際 %v", result["status"])
+	if !result.Converted || result.OriginalOrderID == 0 {
+		t.Errorf("預期標記為限價單超時轉換, 實際 Converted=%v OriginalOrderID=%d", result.Converted, result.OriginalOrderID)
 	}
 
 	t.Logf("✅ conservative_hybrid 策略超時轉換測試通過")
@@ -616,20 +614,17 @@ func TestOrderStrategy_ConservativeHybrid_LimitFail(t *testing.T) {
 	trader := newFuturesTraderWithClient(client, "conservative_hybrid", -0.03, 60)
 
 	// 測試開多倉 - 限價單失敗應該立即降級為市價單
-	result, err := trader.OpenLong("BTCUSDT", 100.0, 10)
+	result, err := trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("conservative_hybrid 策略降級失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12350 {
-		t.Fatalf("預期降級後的市價單 OrderID=12350, 實際 %v (type: %T)", result["orderId"], result["orderId"])
+	if result.OrderID != 12350 {
+		t.Fatalf("預期降級後的市價單 OrderID=12350, 實際 %v", result.OrderID)
 	}
 
-	// Status might be OrderStatusType enum or string, so check the value not the type
-	statusStr := fmt.Sprintf("%v", result["status"])
-	if statusStr != "FILLED" {
-		t.Fatalf("預期市價單 Status=FILLED, 實際 %v (type: %T)", result["status"], result["status"])
+	if result.Status != "FILLED" {
+		t.Fatalf("預期市價單 Status=FILLED, 實際 %v", result.Status)
 	}
 
 	t.Logf("✅ conservative_hybrid 策略降級測試通過 - 限價單失敗立即降級為市價單")
@@ -909,14 +904,13 @@ func TestOrderStrategy_ShortPosition(t *testing.T) {
 	trader := newFuturesTraderWithClient(client, "limit_only", 0.03, 60)
 
 	// 測試開空倉
-	result, err := trader.OpenShort("ETHUSDT", 100.0, 10)
+	result, err := trader.OpenShort(context.Background(), "ETHUSDT", 100.0, 10)
 	if err != nil {
 		t.Fatalf("做空倉位創建失敗: %v", err)
 	}
 
-	orderID, ok := result["orderId"].(int64)
-	if !ok || orderID != 12351 {
-		t.Fatalf("預期 OrderID=12351, 實際 %v (type: %T)", result["orderId"], result["orderId"])
+	if result.OrderID != 12351 {
+		t.Fatalf("預期 OrderID=12351, 實際 %v", result.OrderID)
 	}
 
 	t.Logf("✅ 做空倉位訂單策略測試通過")
@@ -978,6 +972,6 @@ func BenchmarkOrderStrategy_MarketOrder(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		trader.OpenLong("BTCUSDT", 100.0, 10)
+		trader.OpenLong(context.Background(), "BTCUSDT", 100.0, 10)
 	}
 }
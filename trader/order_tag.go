@@ -0,0 +1,37 @@
+package trader
+
+import (
+	"context"
+	"nofx/decision"
+)
+
+// orderTagKey 下单 context 中策略标签的键
+type orderTagKey struct{}
+
+// WithOrderTag 为下单请求附加策略标签（事件日志记录到下单请求和成交中，用于按策略归因盈亏）
+func WithOrderTag(ctx context.Context, tag string) context.Context {
+	if tag == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, orderTagKey{}, tag)
+}
+
+// OrderTag 下单 context 中的策略标签（未设置为空）
+func OrderTag(ctx context.Context) string {
+	tag, _ := ctx.Value(orderTagKey{}).(string)
+	return tag
+}
+
+// orderCtx 执行决策时的下单 context（携带决策的策略标签）
+func (at *AutoTrader) orderCtx(d *decision.Decision) context.Context {
+	return WithOrderTag(at.ctx(), d.Tag)
+}
+
+// tagDecisions 为没有标签的决策填入决策来源名称
+func tagDecisions(decisions []decision.Decision, source string) {
+	for i := range decisions {
+		if decisions[i].Tag == "" {
+			decisions[i].Tag = source
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package trader
 
 import (
+	"context"
 	"fmt"
 	"nofx/decision"
 	"nofx/logger"
@@ -19,7 +20,7 @@ type MockPartialCloseTrader struct {
 	lastTakeProfit     float64
 }
 
-func (m *MockPartialCloseTrader) GetPositions() ([]map[string]interface{}, error) {
+func (m *MockPartialCloseTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
 	return m.positions, nil
 }
 
@@ -33,23 +34,23 @@ func (m *MockPartialCloseTrader) ClosePartialShort(symbol string, quantity float
 	return map[string]interface{}{"orderId": "12345"}, nil
 }
 
-func (m *MockPartialCloseTrader) CloseLong(symbol string, quantity float64) (map[string]interface{}, error) {
+func (m *MockPartialCloseTrader) CloseLong(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	m.closeLongCalled = true
-	return map[string]interface{}{"orderId": "12346"}, nil
+	return &ExecutionReport{OrderID: 12346}, nil
 }
 
-func (m *MockPartialCloseTrader) CloseShort(symbol string, quantity float64) (map[string]interface{}, error) {
+func (m *MockPartialCloseTrader) CloseShort(ctx context.Context, symbol string, quantity float64) (*ExecutionReport, error) {
 	m.closeShortCalled = true
-	return map[string]interface{}{"orderId": "12346"}, nil
+	return &ExecutionReport{OrderID: 12346}, nil
 }
 
-func (m *MockPartialCloseTrader) SetStopLoss(symbol, side string, quantity, price float64) error {
+func (m *MockPartialCloseTrader) SetStopLoss(ctx context.Context, symbol, side string, quantity, price float64) error {
 	m.stopLossCalled = true
 	m.lastStopLoss = price
 	return nil
 }
 
-func (m *MockPartialCloseTrader) SetTakeProfit(symbol, side string, quantity, price float64) error {
+func (m *MockPartialCloseTrader) SetTakeProfit(ctx context.Context, symbol, side string, quantity, price float64) error {
 	m.takeProfitCalled = true
 	m.lastTakeProfit = price
 	return nil
@@ -342,9 +343,9 @@ func TestPartialCloseIntegration(t *testing.T) {
 			if shouldFullClose {
 				// 應該轉為全平
 				if tt.side == "LONG" {
-					mockTrader.CloseLong(tt.symbol, tt.totalQuantity)
+					mockTrader.CloseLong(context.Background(), tt.symbol, tt.totalQuantity)
 				} else {
-					mockTrader.CloseShort(tt.symbol, tt.totalQuantity)
+					mockTrader.CloseShort(context.Background(), tt.symbol, tt.totalQuantity)
 				}
 			} else {
 				// 正常部分平倉
@@ -356,10 +357,10 @@ func TestPartialCloseIntegration(t *testing.T) {
 
 				// 恢復止盈止損
 				if dec.NewStopLoss > 0 {
-					mockTrader.SetStopLoss(tt.symbol, tt.side, remainingQuantity, dec.NewStopLoss)
+					mockTrader.SetStopLoss(context.Background(), tt.symbol, tt.side, remainingQuantity, dec.NewStopLoss)
 				}
 				if dec.NewTakeProfit > 0 {
-					mockTrader.SetTakeProfit(tt.symbol, tt.side, remainingQuantity, dec.NewTakeProfit)
+					mockTrader.SetTakeProfit(context.Background(), tt.symbol, tt.side, remainingQuantity, dec.NewTakeProfit)
 				}
 			}
 
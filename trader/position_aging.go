@@ -0,0 +1,148 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/market"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 持仓超时的处理方式
+const (
+	AgingActionAlert = "alert" // 只发送告警（默认，每个持仓一次）
+	AgingActionClose = "close" // 市价平仓
+)
+
+// MaxHolding 最长持仓时间：持仓超过时长仍未触发止盈/止损时告警或平仓
+type MaxHolding struct {
+	Limit  time.Duration // 最长持仓时间
+	Label  string        // 配置原文（如 "48h"、"20x4h"），用于日志和告警
+	Action string        // alert / close
+}
+
+// ParseMaxHolding 解析最长持仓时间："48h"、"2d"（时长）或 "20x4h"（20 根 4h K线）
+func ParseMaxHolding(raw string) (time.Duration, error) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if count, timeframe, ok := strings.Cut(raw, "x"); ok {
+		n, err := strconv.Atoi(strings.TrimSpace(count))
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%q 的K线数量无效", raw)
+		}
+		tf, ok := market.TimeframeDuration(strings.TrimSpace(timeframe))
+		if !ok {
+			return 0, fmt.Errorf("%q 的K线周期无效（如 15m、1h、4h）", raw)
+		}
+		return time.Duration(n) * tf, nil
+	}
+	if days, ok := strings.CutSuffix(raw, "d"); ok {
+		n, err := strconv.ParseFloat(days, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%q 的天数无效", raw)
+		}
+		return time.Duration(n * float64(24*time.Hour)), nil
+	}
+	d, err := time.ParseDuration(raw)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("%q 无效（如 48h、2d、20x4h）", raw)
+	}
+	return d, nil
+}
+
+// MaxHoldingFromEnv 读取最长持仓时间（NOFX_MAX_HOLDING 未设置时返回 nil）
+func MaxHoldingFromEnv() (*MaxHolding, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_MAX_HOLDING"))
+	if raw == "" {
+		return nil, nil
+	}
+	limit, err := ParseMaxHolding(raw)
+	if err != nil {
+		return nil, fmt.Errorf("NOFX_MAX_HOLDING 配置错误: %w", err)
+	}
+	m := &MaxHolding{Limit: limit, Label: raw, Action: AgingActionAlert}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_MAX_HOLDING_ACTION"))); v != "" {
+		switch v {
+		case AgingActionAlert, AgingActionClose:
+			m.Action = v
+		default:
+			return nil, fmt.Errorf("NOFX_MAX_HOLDING_ACTION=%q 无效（支持 alert、close）", v)
+		}
+	}
+	return m, nil
+}
+
+// enforceMaxHolding 检查持仓时长（以开仓时间为准，重启后由事件日志恢复）：超时的持仓告警或平仓，
+// 已平掉的持仓从交易上下文中移除，返回平仓记录
+func (at *AutoTrader) enforceMaxHolding(ctx *decision.Context, now time.Time) []logger.DecisionAction {
+	cfg := at.config.MaxHolding
+	if cfg == nil || cfg.Limit <= 0 {
+		return nil
+	}
+	if at.agingAlerted == nil {
+		at.agingAlerted = make(map[string]int64)
+	}
+
+	var actions []logger.DecisionAction
+	remaining := ctx.Positions[:0]
+	for _, pos := range ctx.Positions {
+		if pos.UpdateTime == 0 {
+			remaining = append(remaining, pos)
+			continue
+		}
+		age := now.Sub(time.UnixMilli(pos.UpdateTime))
+		posKey := pos.Symbol + "_" + pos.Side
+		if age < cfg.Limit {
+			delete(at.agingAlerted, posKey)
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		if cfg.Action != AgingActionClose {
+			if at.agingAlerted[posKey] != pos.UpdateTime {
+				at.agingAlerted[posKey] = pos.UpdateTime
+				at.notify(AlertSeverityWarning, "持仓超时",
+					"%s %s 已持仓 %s，超过最长持仓时间 %s，仍未触发止盈/止损（浮动盈亏 %+.2f%%）",
+					pos.Symbol, pos.Side, formatAge(age), cfg.Label, pos.UnrealizedPnLPct)
+			}
+			remaining = append(remaining, pos)
+			continue
+		}
+
+		action := logger.DecisionAction{Action: "close_" + pos.Side, Symbol: pos.Symbol, Quantity: pos.Quantity, Price: pos.MarkPrice, Timestamp: now}
+		var order *ExecutionReport
+		var err error
+		if pos.Side == "long" {
+			order, err = at.trader.CloseLong(at.ctx(), pos.Symbol, 0) // 0 = 全部平仓
+		} else {
+			order, err = at.trader.CloseShort(at.ctx(), pos.Symbol, 0)
+		}
+		if err != nil {
+			action.Error = fmt.Sprintf("超时平仓失败: %v", err)
+			actions = append(actions, action)
+			log.Error("❌ 持仓超时平仓失败", "symbol", pos.Symbol, "side", pos.Side, "error", err)
+			remaining = append(remaining, pos)
+			continue
+		}
+		order.applyToAction(&action)
+		action.Success = true
+		actions = append(actions, action)
+		at.notify(AlertSeverityInfo, "持仓超时平仓",
+			"%s %s 已持仓 %s，超过最长持仓时间 %s，已平仓（浮动盈亏 %+.2f%%）",
+			pos.Symbol, pos.Side, formatAge(age), cfg.Label, pos.UnrealizedPnLPct)
+	}
+	ctx.Positions = remaining
+	ctx.Account.PositionCount = len(remaining)
+	return actions
+}
+
+// formatAge 持仓时长的中文描述
+func formatAge(d time.Duration) string {
+	hours := int(d.Hours())
+	if hours >= 24 {
+		return fmt.Sprintf("%d天%d小时", hours/24, hours%24)
+	}
+	return fmt.Sprintf("%d小时%d分钟", hours, int(d.Minutes())%60)
+}
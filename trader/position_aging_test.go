@@ -0,0 +1,89 @@
+package trader
+
+import (
+	"nofx/decision"
+	"testing"
+	"time"
+)
+
+func TestParseMaxHolding(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want time.Duration
+	}{
+		{"48h", 48 * time.Hour},
+		{"1.5d", 36 * time.Hour},
+		{"20x4h", 80 * time.Hour},
+		{"12X15m", 3 * time.Hour},
+	}
+	for _, tt := range tests {
+		got, err := ParseMaxHolding(tt.raw)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseMaxHolding(%q) = %v, %v, want %v", tt.raw, got, err, tt.want)
+		}
+	}
+	for _, raw := range []string{"", "forever", "0h", "0x4h", "10x7q", "-1d"} {
+		if _, err := ParseMaxHolding(raw); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
+
+func TestMaxHoldingFromEnv(t *testing.T) {
+	if m, err := MaxHoldingFromEnv(); err != nil || m != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", m, err)
+	}
+	t.Setenv("NOFX_MAX_HOLDING", "20x4h")
+	m, err := MaxHoldingFromEnv()
+	if err != nil || m.Limit != 80*time.Hour || m.Action != AgingActionAlert {
+		t.Fatalf("配置解析错误: %+v, %v", m, err)
+	}
+	t.Setenv("NOFX_MAX_HOLDING_ACTION", "reduce")
+	if _, err := MaxHoldingFromEnv(); err == nil {
+		t.Error("未知的 NOFX_MAX_HOLDING_ACTION 应返回错误")
+	}
+}
+
+func agingContext(now time.Time) *decision.Context {
+	return &decision.Context{Positions: []decision.PositionInfo{
+		{Symbol: "BTCUSDT", Side: "long", Quantity: 0.1, MarkPrice: 60000, UpdateTime: now.Add(-50 * time.Hour).UnixMilli()},
+		{Symbol: "ETHUSDT", Side: "short", Quantity: 1, MarkPrice: 3000, UpdateTime: now.Add(-2 * time.Hour).UnixMilli()},
+	}}
+}
+
+func TestEnforceMaxHoldingAlertsOnce(t *testing.T) {
+	var alerts []Alert
+	mock := &flattenRecordingTrader{}
+	at := &AutoTrader{trader: mock, config: AutoTraderConfig{
+		MaxHolding:   &MaxHolding{Limit: 48 * time.Hour, Label: "48h", Action: AgingActionAlert},
+		AlertHandler: func(a Alert) { alerts = append(alerts, a) },
+	}}
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		ctx := agingContext(now)
+		if actions := at.enforceMaxHolding(ctx, now.Add(time.Duration(i)*time.Minute)); len(actions) != 0 || len(ctx.Positions) != 2 {
+			t.Fatalf("alert 模式不应平仓: %+v", actions)
+		}
+	}
+	if len(alerts) != 1 || alerts[0].Severity != AlertSeverityWarning || len(mock.closed) != 0 {
+		t.Errorf("每个持仓只应告警一次: %+v", alerts)
+	}
+}
+
+func TestEnforceMaxHoldingCloses(t *testing.T) {
+	mock := &flattenRecordingTrader{}
+	at := &AutoTrader{trader: mock, config: AutoTraderConfig{
+		MaxHolding: &MaxHolding{Limit: 48 * time.Hour, Label: "48h", Action: AgingActionClose},
+	}}
+	now := time.Date(2024, 7, 1, 12, 0, 0, 0, time.UTC)
+	ctx := agingContext(now)
+
+	actions := at.enforceMaxHolding(ctx, now)
+	if len(actions) != 1 || actions[0].Action != "close_long" || !actions[0].Success || len(mock.closed) != 1 {
+		t.Fatalf("应平掉超时的 BTC 多单: actions=%+v closed=%v", actions, mock.closed)
+	}
+	if len(ctx.Positions) != 1 || ctx.Positions[0].Symbol != "ETHUSDT" || ctx.Account.PositionCount != 1 {
+		t.Errorf("已平仓的持仓应从交易上下文移除: %+v", ctx.Positions)
+	}
+}
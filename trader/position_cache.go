@@ -0,0 +1,239 @@
+package trader
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PositionGetter 能按币种查询持仓的交易器（只刷新该币种，不拉取全部持仓）
+type PositionGetter interface {
+	// GetPosition 获取该币种的持仓（双向持仓模式下最多多空两条，没有持仓时为空），格式与 GetPositions 一致
+	GetPosition(ctx context.Context, symbol string) ([]map[string]interface{}, error)
+}
+
+// symbolPositions 获取单个币种的持仓（交易器支持 PositionGetter 时只查询该币种）
+func (at *AutoTrader) symbolPositions(symbol string) ([]map[string]interface{}, error) {
+	if at.positionGetter != nil {
+		return at.positionGetter.GetPosition(at.ctx(), symbol)
+	}
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return nil, err
+	}
+	var matched []map[string]interface{}
+	for _, pos := range positions {
+		if pos["symbol"] == symbol {
+			matched = append(matched, pos)
+		}
+	}
+	return matched, nil
+}
+
+// positionCache 按币种索引的持仓缓存
+// 全量快照在有效期内可直接读取；下单等操作只让相关币种失效，读取时单独刷新该币种；
+// 私有推送的持仓事件直接更新对应币种，不需要重新查询（零值可直接使用）
+type positionCache struct {
+	mu       sync.RWMutex
+	symbols  map[string]*positionEntry
+	syncedAt time.Time       // 最近一次全量快照时间（零值=没有快照），快照中不存在的币种视为无持仓
+	dirty    map[string]bool // 已失效、需要单独刷新的币种
+}
+
+// positionEntry 单个币种的持仓（side -> 持仓）
+type positionEntry struct {
+	sides     map[string]map[string]interface{}
+	updatedAt time.Time
+}
+
+// initLocked 初始化零值缓存（调用方持有写锁）
+func (c *positionCache) initLocked() {
+	if c.symbols == nil {
+		c.symbols = make(map[string]*positionEntry)
+	}
+	if c.dirty == nil {
+		c.dirty = make(map[string]bool)
+	}
+}
+
+// replace 写入全量快照
+func (c *positionCache) replace(positions []map[string]interface{}) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.symbols = make(map[string]*positionEntry)
+	for _, p := range positions {
+		symbol, _ := p["symbol"].(string)
+		side, _ := p["side"].(string)
+		entry, ok := c.symbols[symbol]
+		if !ok {
+			entry = &positionEntry{sides: make(map[string]map[string]interface{}), updatedAt: now}
+			c.symbols[symbol] = entry
+		}
+		entry.sides[side] = copyAccountMap(p)
+	}
+	c.syncedAt = now
+	c.dirty = make(map[string]bool)
+}
+
+// replaceSymbol 写入单个币种的查询结果
+func (c *positionCache) replaceSymbol(symbol string, positions []map[string]interface{}) {
+	entry := &positionEntry{sides: make(map[string]map[string]interface{}), updatedAt: time.Now()}
+	for _, p := range positions {
+		side, _ := p["side"].(string)
+		entry.sides[side] = copyAccountMap(p)
+	}
+	c.mu.Lock()
+	c.initLocked()
+	c.symbols[symbol] = entry
+	delete(c.dirty, symbol)
+	c.mu.Unlock()
+}
+
+// invalidate 让单个币种失效（下单、设置杠杆后调用）
+func (c *positionCache) invalidate(symbol string) {
+	c.mu.Lock()
+	c.initLocked()
+	c.dirty[symbol] = true
+	c.mu.Unlock()
+}
+
+// invalidateAll 丢弃全部缓存
+func (c *positionCache) invalidateAll() {
+	c.mu.Lock()
+	c.symbols = make(map[string]*positionEntry)
+	c.syncedAt = time.Time{}
+	c.dirty = make(map[string]bool)
+	c.mu.Unlock()
+}
+
+// syncedWithin 全量快照是否在 ttl 内
+func (c *positionCache) syncedWithin(ttl time.Duration) bool {
+	return !c.syncedAt.IsZero() && time.Since(c.syncedAt) < ttl
+}
+
+// all 全部持仓（按币种、方向排序）和需要单独刷新的币种；ok=false 表示没有 ttl 内的全量快照
+func (c *positionCache) all(ttl time.Duration) (positions []map[string]interface{}, dirty []string, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if !c.syncedWithin(ttl) {
+		return nil, nil, false
+	}
+	for symbol := range c.dirty {
+		dirty = append(dirty, symbol)
+	}
+	sort.Strings(dirty)
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	positions = []map[string]interface{}{}
+	for _, symbol := range symbols {
+		positions = append(positions, c.symbols[symbol].list()...)
+	}
+	return positions, dirty, true
+}
+
+// get 单个币种的持仓；ok=false 表示该币种已失效或没有 ttl 内的缓存
+func (c *positionCache) get(symbol string, ttl time.Duration) (positions []map[string]interface{}, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.dirty[symbol] {
+		return nil, false
+	}
+	entry, exists := c.symbols[symbol]
+	if !exists {
+		// 全量快照中没有该币种 = 无持仓
+		return []map[string]interface{}{}, c.syncedWithin(ttl)
+	}
+	if time.Since(entry.updatedAt) >= ttl {
+		return nil, false
+	}
+	return entry.list(), true
+}
+
+// apply 按私有推送的持仓事件更新缓存（positionAmt 按 GetPositions 的约定：空仓为负数）
+// 没有全量快照时忽略：推送只包含变化的持仓，不能据此判断其他币种无持仓
+func (c *positionCache) apply(ev AccountEvent) {
+	if ev.Type != AccountEventPosition || ev.Position == nil || ev.Symbol == "" {
+		return
+	}
+	p := ev.Position
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// 已失效的币种仍需查询：推送只包含变化的方向
+	if c.syncedAt.IsZero() || c.dirty[ev.Symbol] {
+		return
+	}
+	c.initLocked()
+	entry, exists := c.symbols[ev.Symbol]
+	if !exists {
+		entry = &positionEntry{sides: make(map[string]map[string]interface{})}
+		c.symbols[ev.Symbol] = entry
+	}
+	entry.updatedAt = time.Now()
+	if p.Quantity == 0 {
+		if p.Side == "" {
+			clear(entry.sides)
+		} else {
+			delete(entry.sides, p.Side)
+		}
+		return
+	}
+	amt := p.Quantity
+	if p.Side == "short" {
+		amt = -amt
+	}
+	// 推送未包含杠杆/强平价时（币安）：数量未变只沿用上次查询的值；
+	// 新开仓或数量变化时强平价随之变化，让该币种失效，下次读取时重新查询
+	leverage, liquidationPrice := p.Leverage, p.LiquidationPrice
+	if leverage == 0 || liquidationPrice == 0 {
+		prev := entry.sides[p.Side]
+		if prevAmt, _ := prev["positionAmt"].(float64); prev == nil || prevAmt != amt {
+			c.dirty[ev.Symbol] = true
+			return
+		}
+		if leverage == 0 {
+			leverage, _ = prev["leverage"].(float64)
+		}
+		if liquidationPrice == 0 {
+			liquidationPrice, _ = prev["liquidationPrice"].(float64)
+		}
+	}
+	entry.sides[p.Side] = map[string]interface{}{
+		"symbol":           ev.Symbol,
+		"side":             p.Side,
+		"positionAmt":      amt,
+		"entryPrice":       p.EntryPrice,
+		"markPrice":        p.MarkPrice,
+		"unRealizedProfit": p.UnrealizedPnL,
+		"leverage":         leverage,
+		"liquidationPrice": liquidationPrice,
+	}
+}
+
+// subscribe 订阅账户事件总线的持仓事件，返回取消订阅函数
+func (c *positionCache) subscribe(bus *AccountEventBus) func() {
+	events, cancel := bus.Subscribe(256)
+	go func() {
+		for ev := range events {
+			c.apply(ev)
+		}
+	}()
+	return cancel
+}
+
+func (e *positionEntry) list() []map[string]interface{} {
+	sides := make([]string, 0, len(e.sides))
+	for side := range e.sides {
+		sides = append(sides, side)
+	}
+	sort.Strings(sides)
+	out := make([]map[string]interface{}, 0, len(sides))
+	for _, side := range sides {
+		out = append(out, copyAccountMap(e.sides[side]))
+	}
+	return out
+}
@@ -0,0 +1,112 @@
+package trader
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPositionCacheInvalidatesSingleSymbol(t *testing.T) {
+	c := &positionCache{}
+	if _, _, ok := c.all(time.Minute); ok {
+		t.Fatal("没有快照时不应命中")
+	}
+	c.replace([]map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+		{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0},
+	})
+
+	positions, dirty, ok := c.all(time.Minute)
+	if !ok || len(dirty) != 0 || len(positions) != 2 {
+		t.Fatalf("positions=%v dirty=%v ok=%v", positions, dirty, ok)
+	}
+	if got, ok := c.get("SOLUSDT", time.Minute); !ok || len(got) != 0 {
+		t.Errorf("快照中没有的币种应视为无持仓: %v ok=%v", got, ok)
+	}
+
+	c.invalidate("BTCUSDT")
+	if _, ok := c.get("BTCUSDT", time.Minute); ok {
+		t.Error("失效的币种不应命中")
+	}
+	if got, ok := c.get("ETHUSDT", time.Minute); !ok || len(got) != 1 {
+		t.Errorf("其他币种不受影响: %v ok=%v", got, ok)
+	}
+	if _, dirty, _ := c.all(time.Minute); len(dirty) != 1 || dirty[0] != "BTCUSDT" {
+		t.Errorf("dirty=%v，期望只有 BTCUSDT", dirty)
+	}
+
+	c.replaceSymbol("BTCUSDT", nil)
+	positions, dirty, _ = c.all(time.Minute)
+	if len(dirty) != 0 || len(positions) != 1 || positions[0]["symbol"] != "ETHUSDT" {
+		t.Errorf("刷新后 BTCUSDT 已平仓: positions=%v dirty=%v", positions, dirty)
+	}
+
+	// 修改返回值不影响缓存
+	positions[0]["positionAmt"] = 0.0
+	if got, _ := c.get("ETHUSDT", time.Minute); got[0]["positionAmt"] != -2.0 {
+		t.Errorf("缓存被调用方修改: %v", got)
+	}
+
+	if _, _, ok := c.all(0); ok {
+		t.Error("超过有效期不应命中")
+	}
+	c.invalidateAll()
+	if _, ok := c.get("ETHUSDT", time.Minute); ok {
+		t.Error("invalidateAll 后不应命中")
+	}
+}
+
+func TestPositionCacheAppliesEvents(t *testing.T) {
+	c := &positionCache{}
+	ev := AccountEvent{Type: AccountEventPosition, Symbol: "BTCUSDT", Position: &PositionUpdate{Side: "short", Quantity: 1, EntryPrice: 50000, Leverage: 5, LiquidationPrice: 60000}}
+	c.apply(ev)
+	if _, _, ok := c.all(time.Minute); ok {
+		t.Fatal("没有全量快照时应忽略推送")
+	}
+
+	c.replace(nil)
+	bus := NewAccountEventBus()
+	cancel := c.subscribe(bus)
+	defer cancel()
+	bus.Publish(ev)
+	bus.Publish(AccountEvent{Type: AccountEventBalance, Balance: &BalanceUpdate{TotalEquity: 1}})
+
+	deadline := time.Now().Add(time.Second)
+	var got []map[string]interface{}
+	for time.Now().Before(deadline) {
+		if got, _ = c.get("BTCUSDT", time.Minute); len(got) == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if len(got) != 1 || got[0]["positionAmt"] != -1.0 || got[0]["side"] != "short" || got[0]["entryPrice"] != 50000.0 {
+		t.Fatalf("推送应更新持仓（空仓数量为负）: %v", got)
+	}
+
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "BTCUSDT", Position: &PositionUpdate{Side: "short"}})
+	if got, ok := c.get("BTCUSDT", time.Minute); !ok || len(got) != 0 {
+		t.Errorf("数量为 0 表示已平仓: %v", got)
+	}
+
+	// 单向持仓模式平仓推送不带方向：平掉该币种全部持仓
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "BTCUSDT", Position: &PositionUpdate{Side: "short", Quantity: 1, EntryPrice: 50000, Leverage: 5, LiquidationPrice: 60000}})
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "BTCUSDT", Position: &PositionUpdate{}})
+	if got, ok := c.get("BTCUSDT", time.Minute); !ok || len(got) != 0 {
+		t.Errorf("单向持仓平仓后不应残留空仓: %v", got)
+	}
+
+	c.replace([]map[string]interface{}{{"symbol": "SOLUSDT", "side": "long", "positionAmt": 2.0, "leverage": 5.0, "liquidationPrice": 80.0}})
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "SOLUSDT", Position: &PositionUpdate{Side: "long", Quantity: 2, EntryPrice: 100, MarkPrice: 105}})
+	if got, _ := c.get("SOLUSDT", time.Minute); len(got) != 1 || got[0]["markPrice"] != 105.0 || got[0]["leverage"] != 5.0 || got[0]["liquidationPrice"] != 80.0 {
+		t.Errorf("数量未变且推送未包含杠杆/强平价时应沿用上次的值: %v", got)
+	}
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "SOLUSDT", Position: &PositionUpdate{Side: "long", Quantity: 3, EntryPrice: 100}})
+	if _, ok := c.get("SOLUSDT", time.Minute); ok {
+		t.Error("数量变化后强平价已变，应重新查询")
+	}
+
+	c.invalidate("ETHUSDT")
+	c.apply(AccountEvent{Type: AccountEventPosition, Symbol: "ETHUSDT", Position: &PositionUpdate{Side: "long", Quantity: 3}})
+	if _, ok := c.get("ETHUSDT", time.Minute); ok {
+		t.Error("已失效的币种仍需查询（推送只包含变化的方向）")
+	}
+}
@@ -0,0 +1,24 @@
+package trader
+
+import "time"
+
+// markPositionOpened 记录新开仓的首次出现时间 (posKey = symbol_side)
+func (at *AutoTrader) markPositionOpened(posKey string) {
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	at.positionFirstSeenTime[posKey] = time.Now().UnixMilli()
+}
+
+// setPositionStopLoss 记录持仓止损价格
+func (at *AutoTrader) setPositionStopLoss(posKey string, price float64) {
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	at.positionStopLoss[posKey] = price
+}
+
+// setPositionTakeProfit 记录持仓止盈价格
+func (at *AutoTrader) setPositionTakeProfit(posKey string, price float64) {
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	at.positionTakeProfit[posKey] = price
+}
@@ -0,0 +1,109 @@
+package trader
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// defaultPrecisionSnapshotMaxAge 精度快照默认有效期（交易规则很少变化，过期后重新拉取）
+const defaultPrecisionSnapshotMaxAge = 24 * time.Hour
+
+// PrecisionSnapshot 交易对精度快照（用于冷启动时免去逐个交易对的规则请求）
+type PrecisionSnapshot struct {
+	Exchange  string                     `json:"exchange"`
+	CreatedAt time.Time                  `json:"created_at"`
+	Symbols   map[string]SymbolPrecision `json:"symbols"`
+}
+
+// PrecisionSnapshotter 支持导出/加载精度快照的交易器
+type PrecisionSnapshotter interface {
+	// ExportPrecisionSnapshot 导出当前交易所全部交易对精度（缓存为空时会请求交易所）
+	ExportPrecisionSnapshot() (*PrecisionSnapshot, error)
+	// LoadPrecisionSnapshot 将快照载入精度缓存
+	LoadPrecisionSnapshot(snapshot *PrecisionSnapshot)
+}
+
+// precisionSnapshotPath 快照文件路径：{dir}/{exchange}_precision.json
+func precisionSnapshotPath(dir, exchange string) string {
+	return filepath.Join(dir, exchange+"_precision.json")
+}
+
+// SavePrecisionSnapshot 将快照写入文件（先写临时文件再重命名，避免写入中断产生损坏文件）
+func SavePrecisionSnapshot(path string, snapshot *PrecisionSnapshot) error {
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化精度快照失败: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建快照目录失败: %w", err)
+	}
+
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入精度快照失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入精度快照失败: %w", err)
+	}
+	return nil
+}
+
+// LoadPrecisionSnapshotFile 读取快照文件，校验交易所与有效期（maxAge<=0 表示不校验有效期）
+func LoadPrecisionSnapshotFile(path, exchange string, maxAge time.Duration) (*PrecisionSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot PrecisionSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("解析精度快照失败: %w", err)
+	}
+
+	if snapshot.Exchange != exchange {
+		return nil, fmt.Errorf("精度快照属于 %s，而非 %s", snapshot.Exchange, exchange)
+	}
+	if len(snapshot.Symbols) == 0 {
+		return nil, fmt.Errorf("精度快照为空")
+	}
+	if maxAge > 0 && time.Since(snapshot.CreatedAt) > maxAge {
+		return nil, fmt.Errorf("精度快照已过期（创建于 %s）", snapshot.CreatedAt.Format(time.RFC3339))
+	}
+
+	return &snapshot, nil
+}
+
+// preloadPrecisionSnapshot 冷启动时加载精度快照；快照缺失或过期时从交易所导出并保存新快照
+func preloadPrecisionSnapshot(t Trader, dir, exchange string) {
+	snapshotter, ok := t.(PrecisionSnapshotter)
+	if !ok {
+		return
+	}
+
+	path := precisionSnapshotPath(dir, exchange)
+	snapshot, err := LoadPrecisionSnapshotFile(path, exchange, defaultPrecisionSnapshotMaxAge)
+	if err == nil {
+		snapshotter.LoadPrecisionSnapshot(snapshot)
+		log.Info("⚡ 已从快照加载交易对精度", "symbol_count", len(snapshot.Symbols), "path", path)
+		return
+	}
+	if !os.IsNotExist(err) {
+		log.Warn("⚠️ 精度快照不可用，将重新导出", "error", err)
+	}
+
+	snapshot, err = snapshotter.ExportPrecisionSnapshot()
+	if err != nil {
+		log.Warn("⚠️ 导出精度快照失败", "error", err)
+		return
+	}
+	if err := SavePrecisionSnapshot(path, snapshot); err != nil {
+		log.Warn("⚠️ 精度快照", "error", err)
+		return
+	}
+	log.Info("💾 已导出交易对精度到快照", "symbol_count", len(snapshot.Symbols), "path", path)
+}
@@ -0,0 +1,98 @@
+package trader
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrecisionSnapshotSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "snapshots", "binance_precision.json")
+	snapshot := &PrecisionSnapshot{
+		Exchange:  VenueBinance,
+		CreatedAt: time.Now(),
+		Symbols: map[string]SymbolPrecision{
+			"BTCUSDT": {PricePrecision: 1, QuantityPrecision: 3, TickSize: 0.1, StepSize: 0.001},
+		},
+	}
+	require.NoError(t, SavePrecisionSnapshot(path, snapshot))
+
+	loaded, err := LoadPrecisionSnapshotFile(path, VenueBinance, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, snapshot.Symbols, loaded.Symbols)
+
+	// 交易所不匹配
+	_, err = LoadPrecisionSnapshotFile(path, VenueAster, time.Hour)
+	assert.Error(t, err)
+
+	// 已过期
+	snapshot.CreatedAt = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, SavePrecisionSnapshot(path, snapshot))
+	_, err = LoadPrecisionSnapshotFile(path, VenueBinance, time.Hour)
+	assert.Error(t, err)
+
+	// 文件不存在
+	_, err = LoadPrecisionSnapshotFile(filepath.Join(t.TempDir(), "missing.json"), VenueBinance, time.Hour)
+	assert.True(t, os.IsNotExist(err))
+}
+
+// TestPreloadPrecisionSnapshot_Binance 首次启动导出快照，再次启动直接从快照加载而不请求 exchangeInfo
+func TestPreloadPrecisionSnapshot_Binance(t *testing.T) {
+	var exchangeInfoCalls int32
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/exchangeInfo" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&exchangeInfoCalls, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"symbols": []map[string]interface{}{
+				{
+					"symbol": "BTCUSDT",
+					"filters": []map[string]interface{}{
+						{"filterType": "PRICE_FILTER", "tickSize": "0.10"},
+						{"filterType": "LOT_SIZE", "stepSize": "0.001"},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	newTrader := func() *FuturesTrader {
+		client := futures.NewClient("test_api_key", "test_secret_key")
+		client.BaseURL = server.URL
+		client.HTTPClient = server.Client()
+		return &FuturesTrader{client: client}
+	}
+	dir := t.TempDir()
+
+	// 冷启动：无快照，导出并保存
+	first := newTrader()
+	preloadPrecisionSnapshot(first, dir, VenueBinance)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exchangeInfoCalls))
+	_, err := os.Stat(precisionSnapshotPath(dir, VenueBinance))
+	require.NoError(t, err)
+
+	// 重启：从快照加载，格式化不再请求交易所
+	second := newTrader()
+	preloadPrecisionSnapshot(second, dir, VenueBinance)
+
+	price, err := second.FormatPrice("BTCUSDT", 50123.456)
+	require.NoError(t, err)
+	assert.Equal(t, "50123.5", price)
+
+	qty, err := second.FormatQuantity("BTCUSDT", 0.12345)
+	require.NoError(t, err)
+	assert.Equal(t, "0.123", qty)
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&exchangeInfoCalls))
+}
@@ -0,0 +1,90 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+
+	"nofx/logging"
+	"nofx/market"
+)
+
+// defaultPriceSanityMaxDeviationPct 决策价格与校验数据源的默认最大偏差（百分比）
+const defaultPriceSanityMaxDeviationPct = 2.0
+
+// minPriceSanitySources 交叉校验至少需要的数据源报价数量
+const minPriceSanitySources = 2
+
+// newPriceSanitySources 按名称创建价格校验数据源（名称见 market.RegisteredDataSources）
+func newPriceSanitySources(names []string) ([]market.DataSource, error) {
+	sources := make([]market.DataSource, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		source, err := market.NewDataSourceByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("价格校验数据源无效: %w", err)
+		}
+		sources = append(sources, source)
+	}
+	if len(sources) > 0 && len(sources) < minPriceSanitySources {
+		return nil, fmt.Errorf("价格校验至少需要 %d 个数据源，当前 %d 个", minPriceSanitySources, len(sources))
+	}
+	return sources, nil
+}
+
+// checkPriceSanity 开仓前用多个数据源交叉校验决策价格，防止单个数据源返回过期或脱锚的价格
+// 未配置校验数据源时沿用数据源管理器的多源一致性检查（数据源不足时放行）；
+// 配置后至少需要两个数据源报价，任一数据源偏差超过阈值即拒绝开仓并告警
+func (at *AutoTrader) checkPriceSanity(symbol string, price float64, tl *logging.Logger) error {
+	if len(at.priceSanitySources) == 0 {
+		return at.checkDataSourceConsistency(symbol, tl)
+	}
+
+	maxDeviation := at.config.PriceSanityMaxDeviationPct
+	if maxDeviation <= 0 {
+		maxDeviation = defaultPriceSanityMaxDeviationPct
+	}
+
+	result := market.CrossCheckPrice(at.ctx(), symbol, price, at.priceSanitySources)
+	if len(result.Prices) < minPriceSanitySources {
+		at.notify(AlertSeverityWarning, "价格校验失败",
+			"%s 仅 %d 个数据源返回价格（至少需要 %d 个），拒绝开仓: %v", symbol, len(result.Prices), minPriceSanitySources, result.Errors)
+		return fmt.Errorf("❌ 价格校验失败：%s 可用数据源不足（%d/%d），拒绝开仓", symbol, len(result.Prices), minPriceSanitySources)
+	}
+
+	source, deviation := result.MaxDeviation()
+	if deviation > maxDeviation {
+		at.notify(AlertSeverityCritical, "价格异常",
+			"%s 决策价格 %.6f 与 %s 价格 %.6f 偏差 %.2f%%（阈值 %.2f%%），拒绝开仓。各数据源: %v",
+			symbol, price, source, result.Prices[source], deviation, maxDeviation, result.Prices)
+		return fmt.Errorf("❌ 价格异常：%s 决策价格 %.6f 与 %s 价格 %.6f 偏差 %.2f%%（>%.2f%%），拒绝开仓以防止误判",
+			symbol, price, source, result.Prices[source], deviation, maxDeviation)
+	}
+
+	tl.Info("✅ 价格校验通过", "source_count", len(result.Prices), "deviation_pct", deviation)
+	return nil
+}
+
+// checkDataSourceConsistency 数据源管理器中多个数据源的价格一致性检查（数据源不足时放行）
+func (at *AutoTrader) checkDataSourceConsistency(symbol string, tl *logging.Logger) error {
+	if market.WSMonitorCli == nil || market.WSMonitorCli.GetDSManager() == nil {
+		return nil
+	}
+	consistent, prices, err := market.WSMonitorCli.GetDSManager().VerifyPriceConsistency(at.ctx(), symbol, 0.02) // 2% 偏差阈值
+	if err != nil {
+		tl.Warn("⚠️ 价格验证失败（数据源不足），继续交易", "error", err)
+		return nil
+	}
+	if !consistent {
+		priceDetails := ""
+		for source, price := range prices {
+			priceDetails += fmt.Sprintf("%s: %.2f, ", source, price)
+		}
+		return fmt.Errorf("❌ 价格异常：%s 在多个数据源间偏差过大（>2%%），拒绝开仓以防止误判。价格: %s",
+			symbol, priceDetails)
+	}
+	tl.Info("✅ 价格验证通过（多数据源一致性检查）")
+	return nil
+}
@@ -0,0 +1,78 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"nofx/market"
+)
+
+// priceSourceStub 只实现 GetName/GetTicker 的数据源
+type priceSourceStub struct {
+	market.DataSource
+	name  string
+	price float64
+	err   error
+}
+
+func (s *priceSourceStub) GetName() string { return s.name }
+
+func (s *priceSourceStub) GetTicker(ctx context.Context, symbol string) (*market.Ticker, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &market.Ticker{Symbol: symbol, LastPrice: s.price}, nil
+}
+
+func TestCheckPriceSanity(t *testing.T) {
+	var alerts []Alert
+	at := &AutoTrader{config: AutoTraderConfig{
+		PriceSanityMaxDeviationPct: 1,
+		AlertHandler:               func(a Alert) { alerts = append(alerts, a) },
+	}}
+	tl := log.With("trader_id", "test")
+
+	at.priceSanitySources = []market.DataSource{
+		&priceSourceStub{name: "hyperliquid", price: 50100},
+		&priceSourceStub{name: "okx", price: 49950},
+	}
+	if err := at.checkPriceSanity("BTCUSDT", 50000, tl); err != nil {
+		t.Errorf("偏差在阈值内时不应拒绝: %v", err)
+	}
+
+	// OKX 价格脱锚 3%
+	at.priceSanitySources[1] = &priceSourceStub{name: "okx", price: 48500}
+	err := at.checkPriceSanity("BTCUSDT", 50000, tl)
+	if err == nil || !strings.Contains(err.Error(), "okx") {
+		t.Errorf("checkPriceSanity() error = %v, want okx 偏差过大", err)
+	}
+	if len(alerts) != 1 || alerts[0].Severity != AlertSeverityCritical {
+		t.Errorf("价格偏差应触发 CRITICAL 告警, alerts = %+v", alerts)
+	}
+
+	// 只有一个数据源返回价格：无法交叉校验，拒绝开仓
+	at.priceSanitySources[1] = &priceSourceStub{name: "okx", err: errors.New("timeout")}
+	if err := at.checkPriceSanity("BTCUSDT", 50000, tl); err == nil {
+		t.Error("可用数据源不足时应拒绝开仓")
+	}
+	if len(alerts) != 2 || alerts[1].Severity != AlertSeverityWarning {
+		t.Errorf("数据源不足应触发 WARNING 告警, alerts = %+v", alerts)
+	}
+}
+
+func TestNewPriceSanitySources(t *testing.T) {
+	if sources, err := newPriceSanitySources(nil); err != nil || len(sources) != 0 {
+		t.Errorf("未配置时 = %v, %v", sources, err)
+	}
+	if sources, err := newPriceSanitySources([]string{"bybit", " okx "}); err != nil || len(sources) != 2 {
+		t.Errorf("newPriceSanitySources() = %v, %v", sources, err)
+	}
+	if _, err := newPriceSanitySources([]string{"okx"}); err == nil {
+		t.Error("只配置一个数据源时应返回错误")
+	}
+	if _, err := newPriceSanitySources([]string{"okx", "nope"}); err == nil {
+		t.Error("未注册的数据源应返回错误")
+	}
+}
@@ -0,0 +1,70 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultMaxPriceAge 默认下单前价格最大允许年龄
+const defaultMaxPriceAge = 2 * time.Second
+
+// priceClock 价格时效检查使用的时钟（测试中可替换）
+var priceClock = time.Now
+
+// MaxPriceAgeFromEnv 读取 NOFX_MAX_PRICE_AGE_MS（下单前价格最大允许年龄，毫秒；未设置=默认 2000，"off"=关闭检查）
+func MaxPriceAgeFromEnv() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_MAX_PRICE_AGE_MS"))
+	if raw == "" {
+		return 0, nil
+	}
+	if strings.EqualFold(raw, "off") {
+		return -1, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil || v < 1 || v > 60000 {
+		return 0, fmt.Errorf("NOFX_MAX_PRICE_AGE_MS 必须是 [1, 60000] 之间的整数或 off: %q", raw)
+	}
+	return v, nil
+}
+
+// maxPriceAge 下单前价格最大允许年龄（<=0 表示关闭检查）
+func (at *AutoTrader) maxPriceAge() time.Duration {
+	switch {
+	case at.config.MaxPriceAgeMs < 0:
+		return 0
+	case at.config.MaxPriceAgeMs == 0:
+		return defaultMaxPriceAge
+	default:
+		return time.Duration(at.config.MaxPriceAgeMs) * time.Millisecond
+	}
+}
+
+// refreshStalePrice 市价下单前检查计算数量/止损所用价格的时效
+// 价格获取时间 pricedAt 距今超过阈值时，从交易所重新获取最新价格，并校验止损/止盈仍位于正确一侧
+// 返回下单应使用的价格（未过期时原样返回）
+func (at *AutoTrader) refreshStalePrice(d *decision.Decision, price float64, pricedAt time.Time) (float64, error) {
+	maxAge := at.maxPriceAge()
+	if maxAge <= 0 {
+		return price, nil
+	}
+	age := priceClock().Sub(pricedAt)
+	if age <= maxAge {
+		return price, nil
+	}
+
+	freshPrice, err := at.trader.GetMarketPrice(at.ctx(), d.Symbol)
+	if err != nil {
+		return 0, fmt.Errorf("价格已过期 %.1f 秒且刷新失败: %w", age.Seconds(), err)
+	}
+	_, maxDeviationPct := at.getConfirmParams()
+	if err := validateEntryConfirmation(d.Action, price, freshPrice, d.StopLoss, d.TakeProfit, confirmMaxDeviationPct(maxDeviationPct)); err != nil {
+		return 0, fmt.Errorf("价格已过期 %.1f 秒，刷新后%w", age.Seconds(), err)
+	}
+
+	log.Info("  ⏱️ 价格已过期", "symbol", d.Symbol, "age_sec", age.Seconds(), "price", price, "fresh_price", freshPrice)
+	return freshPrice, nil
+}
@@ -0,0 +1,70 @@
+package trader
+
+import (
+	"nofx/decision"
+	"testing"
+	"time"
+)
+
+func TestRefreshStalePrice(t *testing.T) {
+	now := time.Now()
+	priceClock = func() time.Time { return now }
+	defer func() { priceClock = time.Now }()
+
+	tests := []struct {
+		name        string
+		maxAgeMs    int
+		age         time.Duration
+		marketPrice float64
+		expectPrice float64
+		expectErr   bool
+	}{
+		{name: "价格未过期_不刷新", age: time.Second, marketPrice: 101, expectPrice: 100},
+		{name: "价格过期_刷新", age: 3 * time.Second, marketPrice: 100.2, expectPrice: 100.2},
+		{name: "自定义阈值", maxAgeMs: 500, age: time.Second, marketPrice: 100.1, expectPrice: 100.1},
+		{name: "关闭检查", maxAgeMs: -1, age: time.Minute, marketPrice: 101, expectPrice: 100},
+		{name: "刷新后跌破止损", age: 3 * time.Second, marketPrice: 99.9, expectErr: true},
+		{name: "刷新后偏离过大", age: 3 * time.Second, marketPrice: 102, expectErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := &AutoTrader{
+				trader: &stopLossFailingTrader{marketPrice: tt.marketPrice},
+				config: AutoTraderConfig{MaxPriceAgeMs: tt.maxAgeMs},
+			}
+			d := &decision.Decision{Action: "open_long", Symbol: "BTCUSDT", StopLoss: 99.95, TakeProfit: 105}
+
+			price, err := at.refreshStalePrice(d, 100, now.Add(-tt.age))
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectErr, err)
+			}
+			if !tt.expectErr && price != tt.expectPrice {
+				t.Errorf("期望价格 %.4f，实际 %.4f", tt.expectPrice, price)
+			}
+		})
+	}
+}
+
+func TestMaxPriceAgeFromEnv(t *testing.T) {
+	if ms, err := MaxPriceAgeFromEnv(); err != nil || ms != 0 {
+		t.Fatalf("未配置时应使用默认值: %v %v", ms, err)
+	}
+	t.Setenv("NOFX_MAX_PRICE_AGE_MS", "500")
+	if ms, err := MaxPriceAgeFromEnv(); err != nil || ms != 500 {
+		t.Errorf("配置解析错误: %v %v", ms, err)
+	}
+	t.Setenv("NOFX_MAX_PRICE_AGE_MS", "off")
+	if ms, err := MaxPriceAgeFromEnv(); err != nil || ms >= 0 {
+		t.Errorf("off 应关闭检查: %v %v", ms, err)
+	}
+
+	for _, bad := range []string{"0", "-1", "1.5s", "120000"} {
+		t.Run(bad, func(t *testing.T) {
+			t.Setenv("NOFX_MAX_PRICE_AGE_MS", bad)
+			if _, err := MaxPriceAgeFromEnv(); err == nil {
+				t.Errorf("NOFX_MAX_PRICE_AGE_MS=%q 应返回错误", bad)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package trader
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 开仓后止损单设置失败时的处理策略
+const (
+	ProtectionPolicyRetry     = "retry"     // 重试 N 次（默认）
+	ProtectionPolicySynthetic = "synthetic" // 改用本地模拟止损（监控价格，触发后市价平仓）
+	ProtectionPolicyFlatten   = "flatten"   // 立即平掉刚开的仓位
+)
+
+// defaultProtectionRetryCount 重试策略的默认重试次数
+const defaultProtectionRetryCount = 3
+
+// 止损单重试间隔和模拟止损检查间隔（测试中可调整）
+var (
+	protectionRetryDelay       = 2 * time.Second
+	syntheticStopCheckInterval = 5 * time.Second
+)
+
+// ProtectionPolicyFromEnv 读取 NOFX_PROTECTION_FAILURE_POLICY（retry / synthetic / flatten，未设置为 retry）
+// 和 NOFX_PROTECTION_RETRY_COUNT（retry 策略的重试次数，未设置为默认值）
+func ProtectionPolicyFromEnv() (string, int, error) {
+	policy := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_PROTECTION_FAILURE_POLICY")))
+	switch policy {
+	case "", ProtectionPolicyRetry, ProtectionPolicySynthetic, ProtectionPolicyFlatten:
+	default:
+		return "", 0, fmt.Errorf("NOFX_PROTECTION_FAILURE_POLICY=%q 无效（支持 retry、synthetic、flatten）", policy)
+	}
+	retries := 0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_PROTECTION_RETRY_COUNT")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			return "", 0, fmt.Errorf("NOFX_PROTECTION_RETRY_COUNT 必须为正整数: %q", raw)
+		}
+		retries = n
+	}
+	return policy, retries, nil
+}
+
+// protectionResult 开仓后止损保护的结果
+type protectionResult int
+
+const (
+	protectionPlaced    protectionResult = iota // 交易所止损单已设置
+	protectionSynthetic                         // 使用本地模拟止损
+	protectionFlattened                         // 已按策略平仓
+	protectionMissing                           // 仓位没有止损保护
+)
+
+// syntheticStop 本地模拟止损
+type syntheticStop struct {
+	Symbol    string
+	Side      string // long / short
+	StopPrice float64
+}
+
+// triggered 当前价格是否触发止损
+func (s syntheticStop) triggered(price float64) bool {
+	if s.Side == "short" {
+		return price >= s.StopPrice
+	}
+	return price <= s.StopPrice
+}
+
+// protectNewPosition 为新开仓位设置止损，失败时按 ProtectionFailurePolicy 处理
+// 无论走哪条处理路径，都会发出 CRITICAL 级别告警
+func (at *AutoTrader) protectNewPosition(symbol, side string, quantity, stopLoss float64) protectionResult {
+	positionSide := strings.ToUpper(side)
+	err := at.trader.SetStopLoss(at.ctx(), symbol, positionSide, quantity, stopLoss)
+	if err == nil {
+		return protectionPlaced
+	}
+	log.Warn("  ⚠ 设置止损失败", "error", err)
+
+	switch at.config.ProtectionFailurePolicy {
+	case ProtectionPolicySynthetic:
+		at.setSyntheticStop(symbol, side, stopLoss)
+		at.notify(AlertSeverityCritical, "止损单设置失败",
+			"%s %s 止损单设置失败（%v），已改用本地模拟止损 %s", symbol, side, err, at.config.NumberFormat.Price(stopLoss))
+		return protectionSynthetic
+
+	case ProtectionPolicyFlatten:
+		if closeErr := at.emergencyClosePosition(symbol, side); closeErr != nil {
+			at.notify(AlertSeverityCritical, "止损单设置失败",
+				"%s %s 止损单设置失败（%v），按策略平仓也失败（%v），仓位没有止损保护，请立即人工处理", symbol, side, err, closeErr)
+			return protectionMissing
+		}
+		at.notify(AlertSeverityCritical, "止损单设置失败",
+			"%s %s 止损单设置失败（%v），已按策略立即平仓", symbol, side, err)
+		return protectionFlattened
+
+	default:
+		retries := at.config.ProtectionRetryCount
+		if retries <= 0 {
+			retries = defaultProtectionRetryCount
+		}
+		for attempt := 1; attempt <= retries; attempt++ {
+			time.Sleep(protectionRetryDelay)
+			if err = at.trader.SetStopLoss(at.ctx(), symbol, positionSide, quantity, stopLoss); err == nil {
+				at.notify(AlertSeverityCritical, "止损单设置失败",
+					"%s %s 止损单首次设置失败，第 %d 次重试成功", symbol, side, attempt)
+				return protectionPlaced
+			}
+			log.Warn("  ⚠ 重试设置止损失败", "attempt", attempt, "retries", retries, "error", err)
+		}
+		at.notify(AlertSeverityCritical, "止损单设置失败",
+			"%s %s 止损单重试 %d 次后仍失败（%v），仓位没有止损保护，请立即人工处理", symbol, side, retries, err)
+		return protectionMissing
+	}
+}
+
+// setSyntheticStop 设置（或更新）持仓的本地模拟止损
+func (at *AutoTrader) setSyntheticStop(symbol, side string, stopPrice float64) {
+	at.syntheticStopMutex.Lock()
+	defer at.syntheticStopMutex.Unlock()
+	if at.syntheticStops == nil {
+		at.syntheticStops = make(map[string]syntheticStop)
+	}
+	at.syntheticStops[symbol+"_"+side] = syntheticStop{Symbol: symbol, Side: side, StopPrice: stopPrice}
+}
+
+// clearSyntheticStop 移除持仓的本地模拟止损（交易所止损单已设置或持仓已平仓）
+func (at *AutoTrader) clearSyntheticStop(posKey string) {
+	at.syntheticStopMutex.Lock()
+	defer at.syntheticStopMutex.Unlock()
+	delete(at.syntheticStops, posKey)
+}
+
+// 启动模拟止损监控（仅 synthetic 策略）
+func (at *AutoTrader) startSyntheticStopMonitor() {
+	if at.config.ProtectionFailurePolicy != ProtectionPolicySynthetic {
+		return
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(syntheticStopCheckInterval)
+		defer ticker.Stop()
+
+		log.Info("🛡️ 启动模拟止损监控", "interval", syntheticStopCheckInterval)
+
+		for {
+			select {
+			case <-ticker.C:
+				at.checkSyntheticStops()
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止模拟止损监控")
+				return
+			}
+		}
+	}()
+}
+
+// checkSyntheticStops 检查本地模拟止损，价格触及止损价时市价平仓
+func (at *AutoTrader) checkSyntheticStops() {
+	at.syntheticStopMutex.Lock()
+	stops := make([]syntheticStop, 0, len(at.syntheticStops))
+	for _, stop := range at.syntheticStops {
+		stops = append(stops, stop)
+	}
+	at.syntheticStopMutex.Unlock()
+
+	nf := at.config.NumberFormat
+	for _, stop := range stops {
+		price, err := at.trader.GetMarketPrice(at.ctx(), stop.Symbol)
+		if err != nil {
+			log.Error("❌ 模拟止损：获取价格失败", "symbol", stop.Symbol, "error", err)
+			continue
+		}
+		if !stop.triggered(price) {
+			continue
+		}
+
+		if err := at.emergencyClosePosition(stop.Symbol, stop.Side); err != nil {
+			at.notify(AlertSeverityCritical, "模拟止损平仓失败",
+				"%s %s 价格 %s 触及模拟止损 %s，平仓失败: %v", stop.Symbol, stop.Side, nf.Price(price), nf.Price(stop.StopPrice), err)
+			continue
+		}
+		at.clearSyntheticStop(stop.Symbol + "_" + stop.Side)
+		at.notify(AlertSeverityCritical, "模拟止损触发",
+			"%s %s 价格 %s 触及模拟止损 %s，已市价平仓", stop.Symbol, stop.Side, nf.Price(price), nf.Price(stop.StopPrice))
+	}
+}
@@ -0,0 +1,140 @@
+package trader
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stopLossFailingTrader 前 failures 次设置止损失败的 MockTrader
+type stopLossFailingTrader struct {
+	closeRecordingTrader
+	failures    int
+	attempts    int
+	marketPrice float64
+}
+
+func (m *stopLossFailingTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	m.attempts++
+	if m.attempts <= m.failures {
+		return errors.New("order would immediately trigger")
+	}
+	return nil
+}
+
+func (m *stopLossFailingTrader) GetMarketPrice(ctx context.Context, symbol string) (float64, error) {
+	return m.marketPrice, nil
+}
+
+func TestProtectNewPosition(t *testing.T) {
+	protectionRetryDelay = 0
+
+	tests := []struct {
+		name         string
+		policy       string
+		retries      int
+		failures     int
+		expect       protectionResult
+		expectTries  int
+		expectClosed bool
+	}{
+		{name: "首次成功_不告警", policy: ProtectionPolicyRetry, failures: 0, expect: protectionPlaced, expectTries: 1},
+		{name: "重试后成功", policy: "", retries: 2, failures: 2, expect: protectionPlaced, expectTries: 3},
+		{name: "重试耗尽", policy: ProtectionPolicyRetry, retries: 2, failures: 10, expect: protectionMissing, expectTries: 3},
+		{name: "模拟止损", policy: ProtectionPolicySynthetic, failures: 10, expect: protectionSynthetic, expectTries: 1},
+		{name: "立即平仓", policy: ProtectionPolicyFlatten, failures: 10, expect: protectionFlattened, expectTries: 1, expectClosed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock := &stopLossFailingTrader{failures: tt.failures}
+			var alerts []Alert
+			at := &AutoTrader{
+				name:   "test",
+				trader: mock,
+				config: AutoTraderConfig{
+					ProtectionFailurePolicy: tt.policy,
+					ProtectionRetryCount:    tt.retries,
+					AlertHandler:            func(a Alert) { alerts = append(alerts, a) },
+				},
+			}
+
+			if got := at.protectNewPosition("BTCUSDT", "long", 0.1, 49000); got != tt.expect {
+				t.Fatalf("期望结果 %v，实际 %v", tt.expect, got)
+			}
+			if mock.attempts != tt.expectTries {
+				t.Errorf("期望设置止损 %d 次，实际 %d 次", tt.expectTries, mock.attempts)
+			}
+			if closed := mock.closedSymbol == "BTCUSDT"; closed != tt.expectClosed {
+				t.Errorf("期望平仓=%v，实际=%v", tt.expectClosed, closed)
+			}
+
+			// 只要首次设置失败，任何处理路径都要发出 CRITICAL 告警
+			if tt.failures == 0 {
+				if len(alerts) != 0 {
+					t.Errorf("首次成功不应告警: %+v", alerts)
+				}
+			} else if len(alerts) != 1 || alerts[0].Severity != AlertSeverityCritical {
+				t.Errorf("期望 1 条 CRITICAL 告警，实际 %+v", alerts)
+			}
+
+			_, hasSynthetic := at.syntheticStops["BTCUSDT_long"]
+			if hasSynthetic != (tt.expect == protectionSynthetic) {
+				t.Errorf("模拟止损状态错误: %+v", at.syntheticStops)
+			}
+		})
+	}
+}
+
+func TestCheckSyntheticStops(t *testing.T) {
+	mock := &stopLossFailingTrader{marketPrice: 3050}
+	var alerts []Alert
+	at := &AutoTrader{
+		name:   "test",
+		trader: mock,
+		config: AutoTraderConfig{AlertHandler: func(a Alert) { alerts = append(alerts, a) }},
+	}
+	at.setSyntheticStop("ETHUSDT", "short", 3100)
+
+	// 未触及止损价
+	at.checkSyntheticStops()
+	if mock.closedSymbol != "" || len(alerts) != 0 {
+		t.Fatalf("未触发时不应平仓: closed=%q alerts=%+v", mock.closedSymbol, alerts)
+	}
+
+	// 空单价格涨破止损价后平仓并移除模拟止损
+	mock.marketPrice = 3100
+	at.checkSyntheticStops()
+	if mock.closedSymbol != "ETHUSDT" || mock.closedSide != "short" {
+		t.Fatalf("期望平空 ETHUSDT，实际 %s %s", mock.closedSide, mock.closedSymbol)
+	}
+	if len(at.syntheticStops) != 0 {
+		t.Errorf("触发后应移除模拟止损: %+v", at.syntheticStops)
+	}
+	if len(alerts) != 1 || alerts[0].Severity != AlertSeverityCritical {
+		t.Errorf("期望 1 条 CRITICAL 告警，实际 %+v", alerts)
+	}
+}
+
+func TestProtectionPolicyFromEnv(t *testing.T) {
+	if policy, retries, err := ProtectionPolicyFromEnv(); err != nil || policy != "" || retries != 0 {
+		t.Fatalf("未配置时应使用默认策略: %q %d %v", policy, retries, err)
+	}
+
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "Synthetic")
+	t.Setenv("NOFX_PROTECTION_RETRY_COUNT", "5")
+	policy, retries, err := ProtectionPolicyFromEnv()
+	if err != nil || policy != ProtectionPolicySynthetic || retries != 5 {
+		t.Errorf("配置解析错误: %q %d %v", policy, retries, err)
+	}
+
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "ignore")
+	if _, _, err := ProtectionPolicyFromEnv(); err == nil {
+		t.Error("未知的策略应返回错误")
+	}
+	t.Setenv("NOFX_PROTECTION_FAILURE_POLICY", "retry")
+	t.Setenv("NOFX_PROTECTION_RETRY_COUNT", "0")
+	if _, _, err := ProtectionPolicyFromEnv(); err == nil {
+		t.Error("重试次数必须为正整数")
+	}
+}
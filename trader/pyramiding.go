@@ -0,0 +1,211 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 顺势加仓默认参数
+const defaultPyramidSizeDecay = 0.5
+
+// Pyramiding 顺势加仓：持仓浮盈达到阈值后才允许追加有限批次，每批规模递减，
+// 加仓后重新计算合并止损，使整个持仓的风险不超过加仓前（仍受保证金、相关性敞口、最小下单量等检查约束）
+type Pyramiding struct {
+	MinProfitR   float64 // 加仓所需浮盈（风险倍数，1R = 开仓价到初始止损的距离；0=不按 R 判断）
+	MinProfitPct float64 // 加仓所需价格有利变动百分比（0=不按百分比判断）
+	MaxAdds      int     // 每个持仓最多加仓批数
+	SizeDecay    float64 // 每批规模相对上一批的比例（默认 0.5：100% → 50% → 25%）
+}
+
+// pyramidState 单个持仓的加仓进度（仅保存在内存，重启后按当前止损估算风险、批次重新计数）
+type pyramidState struct {
+	risk     float64 // 首批开仓价到初始止损的距离
+	baseSize float64 // 首批开仓金额（USDT）
+	adds     int     // 已加仓批数
+}
+
+// pyramidPlan 一次加仓的计划（开仓前确定，成交后据此计算合并止损）
+type pyramidPlan struct {
+	posKey      string
+	side        string
+	existingQty float64
+	entry       float64 // 已有持仓均价
+	stop        float64 // 已有持仓止损
+	tranche     int     // 本次为第几批加仓
+}
+
+// ParsePyramidTrigger 解析加仓阈值："1R"（风险倍数）或 "2%"（价格变动百分比）
+func ParsePyramidTrigger(raw string) (r, pct float64, err error) {
+	trigger := strings.ToUpper(strings.TrimSpace(raw))
+	switch {
+	case strings.HasSuffix(trigger, "R"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(trigger, "R"), 64)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("%q 的风险倍数无效", raw)
+		}
+		return v, 0, nil
+	case strings.HasSuffix(trigger, "%"):
+		v, err := strconv.ParseFloat(strings.TrimSuffix(trigger, "%"), 64)
+		if err != nil || v <= 0 {
+			return 0, 0, fmt.Errorf("%q 的百分比无效", raw)
+		}
+		return 0, v, nil
+	}
+	return 0, 0, fmt.Errorf("%q 应以 R 或 %% 结尾（如 1R、2%%）", raw)
+}
+
+// PyramidingFromEnv 读取顺势加仓配置（NOFX_PYRAMID_MAX_ADDS 未设置时返回 nil）
+func PyramidingFromEnv() (*Pyramiding, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_PYRAMID_MAX_ADDS"))
+	if raw == "" {
+		return nil, nil
+	}
+	adds, err := strconv.Atoi(raw)
+	if err != nil || adds <= 0 {
+		return nil, fmt.Errorf("NOFX_PYRAMID_MAX_ADDS=%q 应为正整数", raw)
+	}
+	cfg := &Pyramiding{MaxAdds: adds, MinProfitR: 1, SizeDecay: defaultPyramidSizeDecay}
+	if v := strings.TrimSpace(os.Getenv("NOFX_PYRAMID_MIN_PROFIT")); v != "" {
+		if cfg.MinProfitR, cfg.MinProfitPct, err = ParsePyramidTrigger(v); err != nil {
+			return nil, fmt.Errorf("NOFX_PYRAMID_MIN_PROFIT 配置错误: %w", err)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_PYRAMID_SIZE_DECAY")); v != "" {
+		decay, err := strconv.ParseFloat(v, 64)
+		if err != nil || decay <= 0 || decay > 1 {
+			return nil, fmt.Errorf("NOFX_PYRAMID_SIZE_DECAY=%q 应在 (0, 1] 之间", v)
+		}
+		cfg.SizeDecay = decay
+	}
+	return cfg, nil
+}
+
+// startPyramid 首批开仓后记录初始风险和开仓金额
+func (at *AutoTrader) startPyramid(posKey string, entry, stop, size float64) {
+	if at.config.Pyramiding == nil {
+		return
+	}
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	if at.pyramids == nil {
+		at.pyramids = make(map[string]*pyramidState)
+	}
+	at.pyramids[posKey] = &pyramidState{risk: math.Abs(entry - stop), baseSize: size}
+}
+
+// clearPyramid 持仓平仓后清除加仓进度
+func (at *AutoTrader) clearPyramid(posKey string) {
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	delete(at.pyramids, posKey)
+}
+
+// planPyramid 加仓前检查浮盈阈值和批次上限，并按递减比例缩小本批开仓金额；未配置顺势加仓时返回 nil
+func (at *AutoTrader) planPyramid(positions []map[string]interface{}, d *decision.Decision, side string) (*pyramidPlan, error) {
+	cfg := at.config.Pyramiding
+	if cfg == nil {
+		return nil, nil
+	}
+	var pos map[string]interface{}
+	for _, p := range positions {
+		if p["symbol"] == d.Symbol && p["side"] == side {
+			pos = p
+			break
+		}
+	}
+	if pos == nil {
+		return nil, nil
+	}
+
+	posKey := d.Symbol + "_" + side
+	entry := floatValue(pos["entryPrice"])
+	mark := floatValue(pos["markPrice"])
+	qty := math.Abs(floatValue(pos["positionAmt"]))
+	if entry <= 0 || mark <= 0 || qty <= 0 {
+		return nil, fmt.Errorf("❌ %s 持仓数据不完整，无法判断是否满足加仓条件", d.Symbol)
+	}
+
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	state := at.pyramids[posKey]
+	stop := at.positionStopLoss[posKey]
+	if state == nil {
+		// 重启后没有首批记录：按当前止损估算风险，以当前持仓金额作为首批规模
+		risk := 0.0
+		if stop > 0 {
+			risk = math.Abs(entry - stop)
+		}
+		state = &pyramidState{risk: risk, baseSize: qty * entry}
+		if at.pyramids == nil {
+			at.pyramids = make(map[string]*pyramidState)
+		}
+		at.pyramids[posKey] = state
+	}
+	if state.adds >= cfg.MaxAdds {
+		return nil, fmt.Errorf("❌ %s 已加仓 %d 批，达到上限 %d", d.Symbol, state.adds, cfg.MaxAdds)
+	}
+
+	move := mark - entry
+	if side == "short" {
+		move = -move
+	}
+	if cfg.MinProfitPct > 0 {
+		if pct := move / entry * 100; pct < cfg.MinProfitPct {
+			return nil, fmt.Errorf("❌ %s 浮盈 %.2f%% 未达到加仓阈值 %.2f%%", d.Symbol, pct, cfg.MinProfitPct)
+		}
+	}
+	if cfg.MinProfitR > 0 {
+		if state.risk <= 0 {
+			return nil, fmt.Errorf("❌ %s 没有初始止损，无法按风险倍数判断加仓条件", d.Symbol)
+		}
+		if r := move / state.risk; r < cfg.MinProfitR {
+			return nil, fmt.Errorf("❌ %s 浮盈 %.2fR 未达到加仓阈值 %.2fR", d.Symbol, r, cfg.MinProfitR)
+		}
+	}
+
+	tranche := state.adds + 1
+	if maxSize := state.baseSize * math.Pow(cfg.SizeDecay, float64(tranche)); d.PositionSizeUSD > maxSize {
+		log.Info("  🔺 加仓金额", "symbol", d.Symbol, "tranche", tranche, "position_size_usd", d.PositionSizeUSD, "max_size", maxSize, "base_size", state.baseSize, "size_decay", cfg.SizeDecay)
+		d.PositionSizeUSD = maxSize
+	}
+	return &pyramidPlan{posKey: posKey, side: side, existingQty: qty, entry: entry, stop: stop, tranche: tranche}, nil
+}
+
+// combinedStop 加仓后的合并止损：整个持仓在止损处的盈亏与加仓前已有持仓在原止损处相同
+// （风险不增加，已锁定的利润不回吐；结果为原止损与加仓价的按数量加权，总在现价之外），同时不宽于本次决策给出的止损
+func (p *pyramidPlan) combinedStop(addedQty, addedPrice, decisionStop float64) float64 {
+	totalQty := p.existingQty + addedQty
+	avgEntry := (p.existingQty*p.entry + addedQty*addedPrice) / totalQty
+	stop := p.stop
+	if stop <= 0 {
+		stop = decisionStop
+	}
+	if p.side == "long" {
+		// 已有持仓在止损处的盈亏（为负时表示风险），合并后在新止损处的盈亏保持不变
+		pnlAtStop := p.existingQty * (stop - p.entry)
+		return math.Max(avgEntry+pnlAtStop/totalQty, decisionStop)
+	}
+	pnlAtStop := p.existingQty * (p.entry - stop)
+	return math.Min(avgEntry-pnlAtStop/totalQty, decisionStop)
+}
+
+// applyPyramidStop 加仓成交后按实际成交价计算合并止损，写入决策并计入加仓批数
+func (at *AutoTrader) applyPyramidStop(plan *pyramidPlan, d *decision.Decision, addedQty, fillPrice float64) {
+	if plan == nil {
+		return
+	}
+	stop := plan.combinedStop(addedQty, fillPrice, d.StopLoss)
+	if stop != d.StopLoss {
+		log.Info("  🔺 加仓，合并止损", "symbol", d.Symbol, "tranche", plan.tranche, "stop_loss", at.config.NumberFormat.Price(d.StopLoss), "merged_stop_loss", at.config.NumberFormat.Price(stop))
+		d.StopLoss = stop
+	}
+	at.positionMutex.Lock()
+	defer at.positionMutex.Unlock()
+	if state := at.pyramids[plan.posKey]; state != nil {
+		state.adds = plan.tranche
+	}
+}
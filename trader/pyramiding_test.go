@@ -0,0 +1,107 @@
+package trader
+
+import (
+	"math"
+	"nofx/decision"
+	"nofx/logger"
+	"testing"
+)
+
+func TestPyramidingFromEnv(t *testing.T) {
+	if p, err := PyramidingFromEnv(); err != nil || p != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", p, err)
+	}
+	t.Setenv("NOFX_PYRAMID_MAX_ADDS", "2")
+	p, err := PyramidingFromEnv()
+	if err != nil || p.MaxAdds != 2 || p.MinProfitR != 1 || p.SizeDecay != defaultPyramidSizeDecay {
+		t.Fatalf("默认配置错误: %+v, %v", p, err)
+	}
+	t.Setenv("NOFX_PYRAMID_MIN_PROFIT", "2%")
+	if p, err = PyramidingFromEnv(); err != nil || p.MinProfitPct != 2 || p.MinProfitR != 0 {
+		t.Fatalf("百分比阈值解析错误: %+v, %v", p, err)
+	}
+	t.Setenv("NOFX_PYRAMID_SIZE_DECAY", "1.5")
+	if _, err := PyramidingFromEnv(); err == nil {
+		t.Error("SIZE_DECAY 大于 1 应返回错误")
+	}
+	t.Setenv("NOFX_PYRAMID_SIZE_DECAY", "")
+	t.Setenv("NOFX_PYRAMID_MIN_PROFIT", "2")
+	if _, err := PyramidingFromEnv(); err == nil {
+		t.Error("阈值缺少 R/% 后缀应返回错误")
+	}
+}
+
+func TestPyramidCombinedStop(t *testing.T) {
+	tests := []struct {
+		name         string
+		plan         pyramidPlan
+		addedQty     float64
+		addedPrice   float64
+		decisionStop float64
+		want         float64
+	}{
+		// 1 @100 止损 95（风险 5），加 0.5 @110：合并后在 100 止损，亏损仍为 5
+		{name: "多单_风险不变", plan: pyramidPlan{side: "long", existingQty: 1, entry: 100, stop: 95}, addedQty: 0.5, addedPrice: 110, decisionStop: 98, want: 100},
+		{name: "多单_决策止损更紧", plan: pyramidPlan{side: "long", existingQty: 1, entry: 100, stop: 95}, addedQty: 0.5, addedPrice: 110, decisionStop: 101, want: 101},
+		{name: "多单_已保本时合并保本", plan: pyramidPlan{side: "long", existingQty: 1, entry: 100, stop: 100}, addedQty: 0.5, addedPrice: 110, decisionStop: 98, want: 310.0 / 3},
+		{name: "空单_风险不变", plan: pyramidPlan{side: "short", existingQty: 1, entry: 100, stop: 105}, addedQty: 0.5, addedPrice: 90, decisionStop: 102, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.plan.combinedStop(tt.addedQty, tt.addedPrice, tt.decisionStop); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("合并止损 = %.6f，期望 %.6f", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanPyramid(t *testing.T) {
+	at := &AutoTrader{
+		config:           AutoTraderConfig{Pyramiding: &Pyramiding{MinProfitR: 1, MaxAdds: 1, SizeDecay: 0.5}},
+		positionStopLoss: map[string]float64{"BTCUSDT_long": 95},
+	}
+	at.startPyramid("BTCUSDT_long", 100, 95, 1000)
+	positions := []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 10.0, "entryPrice": 100.0, "markPrice": 104.0},
+	}
+
+	// 浮盈 0.8R 未达到 1R
+	d := &decision.Decision{Symbol: "BTCUSDT", PositionSizeUSD: 1000, StopLoss: 98}
+	if _, err := at.planPyramid(positions, d, "long"); err == nil {
+		t.Fatal("浮盈未达到阈值时应拒绝加仓")
+	}
+
+	// 浮盈 1.2R：第一批加仓金额减半
+	positions[0]["markPrice"] = 106.0
+	plan, err := at.planPyramid(positions, d, "long")
+	if err != nil {
+		t.Fatalf("达到阈值应允许加仓: %v", err)
+	}
+	if d.PositionSizeUSD != 500 || plan.tranche != 1 || plan.stop != 95 {
+		t.Errorf("加仓计划错误: size=%.2f plan=%+v", d.PositionSizeUSD, plan)
+	}
+	at.applyPyramidStop(plan, d, 500.0/106, 106)
+	if d.StopLoss <= 98 || d.StopLoss >= 106 {
+		t.Errorf("合并止损应在决策止损与现价之间，实际 %.4f", d.StopLoss)
+	}
+
+	// 达到批次上限
+	if _, err := at.planPyramid(positions, &decision.Decision{Symbol: "BTCUSDT", PositionSizeUSD: 100}, "long"); err == nil {
+		t.Error("超过批次上限应拒绝加仓")
+	}
+
+	// 平仓后重新开始计数
+	at.clearPyramid("BTCUSDT_long")
+	if _, err := at.planPyramid(positions, &decision.Decision{Symbol: "BTCUSDT", PositionSizeUSD: 100}, "long"); err != nil {
+		t.Errorf("清除进度后应允许加仓: %v", err)
+	}
+}
+
+func TestCheckScaleInAllowedByPyramiding(t *testing.T) {
+	at := &AutoTrader{config: AutoTraderConfig{Pyramiding: &Pyramiding{MaxAdds: 1}}}
+	record := &logger.DecisionAction{}
+	positions := []map[string]interface{}{{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5}}
+	if _, err := at.checkScaleIn(positions, "BTCUSDT", "long", record); err != nil || !record.ScaleIn {
+		t.Errorf("开启顺势加仓时应标记为加仓: %v", err)
+	}
+}
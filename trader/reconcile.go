@@ -0,0 +1,305 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"nofx/store"
+	"strings"
+	"time"
+)
+
+// 启动对账策略
+const (
+	ReconcilePolicyRepair = "repair" // 自动修复可修复的不一致，无法修复时暂停交易直到确认（默认）
+	ReconcilePolicyHalt   = "halt"   // 发现任何不一致即暂停交易直到确认，不自动修复
+	ReconcilePolicyOff    = "off"    // 不对账
+)
+
+// 对账发现的不一致类型
+const (
+	DivergenceMissingStopLoss   = "missing_stop_loss"   // 持仓没有止损单
+	DivergenceMissingTakeProfit = "missing_take_profit" // 已记录止盈价但交易所没有止盈单
+	DivergenceUnknownPosition   = "unknown_position"    // 交易所有持仓，事件日志中没有
+	DivergenceStalePosition     = "stale_position"      // 事件日志中有持仓，交易所没有（离线期间被平仓）
+	DivergenceQuantityMismatch  = "quantity_mismatch"   // 持仓数量与事件日志不一致
+	DivergenceOrphanOrder       = "orphan_order"        // 没有对应持仓的止盈止损单
+	DivergenceCheckFailed       = "check_failed"        // 无法获取交易所状态
+)
+
+// reconcileQtyTolerance 持仓数量允许的相对误差
+const reconcileQtyTolerance = 0.001
+
+// Divergence 一处本地状态与交易所的不一致
+type Divergence struct {
+	Type        string `json:"type"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side,omitempty"`
+	Detail      string `json:"detail"`
+	Repaired    bool   `json:"repaired"`
+	RepairError string `json:"repair_error,omitempty"`
+}
+
+// ReconcileReport 启动对账结果
+type ReconcileReport struct {
+	Time           time.Time    `json:"time"`
+	Policy         string       `json:"policy"`
+	Divergences    []Divergence `json:"divergences"`
+	Halted         bool         `json:"halted"` // 是否暂停交易（等待确认）
+	AcknowledgedBy string       `json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time   `json:"acknowledged_at,omitempty"`
+}
+
+// Unresolved 未修复的不一致
+func (r *ReconcileReport) Unresolved() []Divergence {
+	var unresolved []Divergence
+	for _, d := range r.Divergences {
+		if !d.Repaired {
+			unresolved = append(unresolved, d)
+		}
+	}
+	return unresolved
+}
+
+// reconcilePolicy 当前对账策略
+func (at *AutoTrader) reconcilePolicy() string {
+	if at.config.ReconcilePolicy == "" {
+		return ReconcilePolicyRepair
+	}
+	return at.config.ReconcilePolicy
+}
+
+// reconcileOnStart 启动对账：比对事件日志、交易所持仓和止盈止损挂单，按策略修复或暂停交易
+func (at *AutoTrader) reconcileOnStart() {
+	policy := at.reconcilePolicy()
+	if policy == ReconcilePolicyOff {
+		return
+	}
+
+	log.Info("🔍 启动对账：核对持仓和止盈止损挂单...", "trader", at.name)
+	report := at.reconcile(policy)
+	unresolved := report.Unresolved()
+	report.Halted = len(unresolved) > 0
+
+	at.reconcileMutex.Lock()
+	at.reconcileReport = report
+	at.reconcileMutex.Unlock()
+
+	for _, d := range report.Divergences {
+		if d.Repaired {
+			log.Info("  🔧 对账差异（已修复）", "type", d.Type, "symbol", d.Symbol, "side", d.Side, "detail", d.Detail)
+		} else {
+			log.Error("  ❌ 对账差异", "type", d.Type, "symbol", d.Symbol, "side", d.Side, "detail", d.Detail, "repair_error", d.RepairError)
+		}
+	}
+
+	switch {
+	case report.Halted:
+		lines := make([]string, 0, len(unresolved))
+		for _, d := range unresolved {
+			lines = append(lines, fmt.Sprintf("%s %s %s: %s", d.Type, d.Symbol, d.Side, d.Detail))
+		}
+		at.notify(AlertSeverityCritical, "启动对账发现异常",
+			"发现 %d 处无法自动修复的不一致，已暂停交易，确认后恢复：%s", len(unresolved), strings.Join(lines, "; "))
+	case len(report.Divergences) > 0:
+		at.notify(AlertSeverityWarning, "启动对账已修复",
+			"发现并修复了 %d 处不一致", len(report.Divergences))
+	default:
+		log.Info("✓ 对账完成，本地状态与交易所一致", "trader", at.name)
+	}
+}
+
+// reconcile 执行对账（policy 为 repair 时尝试修复）
+func (at *AutoTrader) reconcile(policy string) *ReconcileReport {
+	report := &ReconcileReport{Time: time.Now(), Policy: policy}
+	repair := policy == ReconcilePolicyRepair
+	add := func(d Divergence, fix func() error) {
+		if repair && fix != nil {
+			if err := fix(); err != nil {
+				d.RepairError = err.Error()
+			} else {
+				d.Repaired = true
+			}
+		}
+		report.Divergences = append(report.Divergences, d)
+	}
+
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		add(Divergence{Type: DivergenceCheckFailed, Detail: fmt.Sprintf("获取持仓失败: %v", err)}, nil)
+		return report
+	}
+	orders, err := at.trader.GetOpenOrders(at.ctx(), "")
+	if err != nil {
+		add(Divergence{Type: DivergenceCheckFailed, Detail: fmt.Sprintf("获取挂单失败: %v", err)}, nil)
+		return report
+	}
+	var journaled map[string]*store.PositionState
+	if at.journal != nil {
+		if journaled, err = at.journal.Positions(at.id); err != nil {
+			add(Divergence{Type: DivergenceCheckFailed, Detail: fmt.Sprintf("读取事件日志失败: %v", err)}, nil)
+			return report
+		}
+	}
+	// Hyperliquid 的挂单查询不返回触发单，无法核对止盈止损
+	checkProtection := at.exchange != "hyperliquid"
+
+	live := make(map[string]bool)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		amt, _ := pos["positionAmt"].(float64)
+		entry, _ := pos["entryPrice"].(float64)
+		qty := math.Abs(amt)
+		if qty == 0 {
+			continue
+		}
+		key := symbol + "_" + side
+		live[key] = true
+
+		if journaled != nil {
+			jp, ok := journaled[key]
+			switch {
+			case !ok:
+				add(Divergence{Type: DivergenceUnknownPosition, Symbol: symbol, Side: side,
+					Detail: fmt.Sprintf("交易所持仓 %.6f @ %.6f 不在事件日志中", qty, entry)},
+					func() error { return at.journalAdjust(symbol, side, "open", qty, entry, "reconcile_adopt") })
+			case math.Abs(jp.Quantity-qty) > qty*reconcileQtyTolerance:
+				diff, action := qty-jp.Quantity, "open"
+				if diff < 0 {
+					diff, action = -diff, "close"
+				}
+				add(Divergence{Type: DivergenceQuantityMismatch, Symbol: symbol, Side: side,
+					Detail: fmt.Sprintf("事件日志数量 %.6f，交易所数量 %.6f", jp.Quantity, qty)},
+					func() error { return at.journalAdjust(symbol, side, action, diff, entry, "reconcile_sync") })
+			}
+		}
+
+		if !checkProtection {
+			continue
+		}
+		if !hasProtectiveOrder(orders, symbol, side, "STOP") {
+			stopLoss := at.positionStopLoss[key]
+			add(Divergence{Type: DivergenceMissingStopLoss, Symbol: symbol, Side: side,
+				Detail: fmt.Sprintf("持仓 %.6f 没有止损单", qty)},
+				func() error {
+					if stopLoss <= 0 {
+						return fmt.Errorf("未记录止损价格，无法自动补设")
+					}
+					return at.trader.SetStopLoss(at.ctx(), symbol, strings.ToUpper(side), qty, stopLoss)
+				})
+		}
+		if takeProfit := at.positionTakeProfit[key]; takeProfit > 0 && !hasProtectiveOrder(orders, symbol, side, "TAKE_PROFIT") {
+			add(Divergence{Type: DivergenceMissingTakeProfit, Symbol: symbol, Side: side,
+				Detail: fmt.Sprintf("已记录止盈价 %.6f 但交易所没有止盈单", takeProfit)},
+				func() error { return at.trader.SetTakeProfit(at.ctx(), symbol, strings.ToUpper(side), qty, takeProfit) })
+		}
+	}
+
+	for key, jp := range journaled {
+		if live[key] {
+			continue
+		}
+		symbol, side := jp.Symbol, jp.Side
+		add(Divergence{Type: DivergenceStalePosition, Symbol: symbol, Side: side,
+			Detail: fmt.Sprintf("事件日志持仓 %.6f 在交易所已不存在", jp.Quantity)},
+			func() error {
+				at.clearPositionState(key)
+				return at.journalAdjust(symbol, side, "close", jp.Quantity, 0, "reconcile_stale")
+			})
+	}
+
+	if checkProtection {
+		orphaned := make(map[string]bool)
+		for _, order := range orders {
+			if !isProtectiveOrder(order.Type) || orphaned[order.Symbol] || live[order.Symbol+"_long"] || live[order.Symbol+"_short"] {
+				continue
+			}
+			orphaned[order.Symbol] = true
+			symbol := order.Symbol
+			add(Divergence{Type: DivergenceOrphanOrder, Symbol: symbol,
+				Detail: fmt.Sprintf("没有持仓但存在止盈止损单（#%d %s）", order.OrderID, order.Type)},
+				func() error { return at.trader.CancelStopOrders(at.ctx(), symbol) })
+		}
+	}
+	return report
+}
+
+// journalAdjust 写入一条对账调整成交，使事件日志与交易所持仓一致
+func (at *AutoTrader) journalAdjust(symbol, side, action string, quantity, price float64, reason string) error {
+	if at.journal == nil {
+		return nil
+	}
+	fill := store.Fill{Action: action, Quantity: quantity, Price: price, Full: reason == "reconcile_stale", Reason: reason}
+	_, err := at.journal.Record(at.id, store.EventFill, symbol, side, fill)
+	return err
+}
+
+// clearPositionState 清除已不存在持仓的本地状态
+func (at *AutoTrader) clearPositionState(key string) {
+	delete(at.positionFirstSeenTime, key)
+	delete(at.positionStopLoss, key)
+	delete(at.positionTakeProfit, key)
+	at.clearPyramid(key)
+}
+
+// isProtectiveOrder 是否为止盈止损触发单
+func isProtectiveOrder(orderType string) bool {
+	return strings.Contains(orderType, "STOP") || strings.Contains(orderType, "TAKE_PROFIT")
+}
+
+// hasProtectiveOrder 是否存在保护该持仓的止损（kind="STOP"）或止盈（kind="TAKE_PROFIT"）单
+func hasProtectiveOrder(orders []decision.OpenOrderInfo, symbol, side, kind string) bool {
+	closingSide := "SELL"
+	if side == "short" {
+		closingSide = "BUY"
+	}
+	for _, order := range orders {
+		if order.Symbol != symbol || !strings.Contains(order.Type, kind) {
+			continue
+		}
+		switch order.PositionSide {
+		case strings.ToUpper(side):
+			return true
+		case "", "BOTH":
+			if order.Side == closingSide {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reconciliationHalted 启动对账是否要求暂停交易
+func (at *AutoTrader) reconciliationHalted() bool {
+	at.reconcileMutex.Lock()
+	defer at.reconcileMutex.Unlock()
+	return at.reconcileReport != nil && at.reconcileReport.Halted
+}
+
+// GetReconcileReport 最近一次启动对账的结果（未对账返回 nil）
+func (at *AutoTrader) GetReconcileReport() *ReconcileReport {
+	at.reconcileMutex.Lock()
+	defer at.reconcileMutex.Unlock()
+	if at.reconcileReport == nil {
+		return nil
+	}
+	report := *at.reconcileReport
+	report.Divergences = append([]Divergence(nil), at.reconcileReport.Divergences...)
+	return &report
+}
+
+// AcknowledgeReconciliation 确认对账异常并恢复交易
+func (at *AutoTrader) AcknowledgeReconciliation(by string) error {
+	at.reconcileMutex.Lock()
+	defer at.reconcileMutex.Unlock()
+	if at.reconcileReport == nil || !at.reconcileReport.Halted {
+		return fmt.Errorf("没有待确认的对账异常")
+	}
+	now := time.Now()
+	at.reconcileReport.Halted = false
+	at.reconcileReport.AcknowledgedBy = by
+	at.reconcileReport.AcknowledgedAt = &now
+	log.Info("✅ 对账异常已确认，恢复交易", "trader", at.name, "by", by)
+	return nil
+}
@@ -0,0 +1,170 @@
+package trader
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"nofx/decision"
+	"nofx/store"
+)
+
+// reconcileMockTrader 带挂单和止损/撤单记录的 MockTrader
+type reconcileMockTrader struct {
+	MockTrader
+	orders    []decision.OpenOrderInfo
+	stopLoss  map[string]float64
+	cancelled []string
+}
+
+func (m *reconcileMockTrader) GetOpenOrders(ctx context.Context, symbol string) ([]decision.OpenOrderInfo, error) {
+	return m.orders, nil
+}
+
+func (m *reconcileMockTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	if m.stopLoss == nil {
+		m.stopLoss = make(map[string]float64)
+	}
+	m.stopLoss[symbol+"_"+positionSide] = stopPrice
+	return nil
+}
+
+func (m *reconcileMockTrader) CancelStopOrders(ctx context.Context, symbol string) error {
+	m.cancelled = append(m.cancelled, symbol)
+	return nil
+}
+
+func newReconcileTestTrader(t *testing.T, mock Trader, policy string) (*AutoTrader, *store.Journal) {
+	t.Helper()
+	journal, err := store.OpenJournal(filepath.Join(t.TempDir(), "journal.db"))
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	t.Cleanup(func() { journal.Close() })
+	return &AutoTrader{
+		id:                    "t1",
+		name:                  "test",
+		exchange:              "binance",
+		trader:                mock,
+		journal:               journal,
+		config:                AutoTraderConfig{ReconcilePolicy: policy},
+		positionFirstSeenTime: make(map[string]int64),
+		positionStopLoss:      make(map[string]float64),
+		positionTakeProfit:    make(map[string]float64),
+	}, journal
+}
+
+func divergenceTypes(report *ReconcileReport) map[string]Divergence {
+	byType := make(map[string]Divergence)
+	for _, d := range report.Divergences {
+		byType[d.Type] = d
+	}
+	return byType
+}
+
+func TestReconcileRepairsDivergences(t *testing.T) {
+	mock := &reconcileMockTrader{
+		MockTrader: MockTrader{positions: []map[string]interface{}{
+			{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0, "entryPrice": 50000.0},
+			{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0, "entryPrice": 3000.0},
+		}},
+		orders: []decision.OpenOrderInfo{
+			{Symbol: "ETHUSDT", Type: "STOP_MARKET", Side: "BUY", PositionSide: "SHORT"},
+			{Symbol: "SOLUSDT", Type: "TAKE_PROFIT_MARKET", Side: "SELL", PositionSide: "LONG"},
+		},
+	}
+	at, journal := newReconcileTestTrader(t, mock, "")
+
+	// BTC 在日志中有止损记录；ETH 不在日志中；XRP 在离线期间已被平仓
+	journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 50000})
+	journal.Record("t1", store.EventProtection, "BTCUSDT", "long", store.Protection{Kind: "stop_loss", Price: 48000})
+	journal.Record("t1", store.EventFill, "XRPUSDT", "long", store.Fill{Action: "open", Quantity: 100, Price: 0.5})
+	at.restoreFromJournal()
+
+	at.reconcileOnStart()
+	report := at.GetReconcileReport()
+	if report == nil || report.Halted {
+		t.Fatalf("expected all divergences repaired, got %+v", report)
+	}
+
+	byType := divergenceTypes(report)
+	for _, typ := range []string{DivergenceMissingStopLoss, DivergenceUnknownPosition, DivergenceStalePosition, DivergenceOrphanOrder} {
+		if d, ok := byType[typ]; !ok || !d.Repaired {
+			t.Errorf("%s: expected repaired divergence, got %+v", typ, d)
+		}
+	}
+	if mock.stopLoss["BTCUSDT_LONG"] != 48000 {
+		t.Errorf("stop loss not re-placed: %v", mock.stopLoss)
+	}
+	if len(mock.cancelled) != 1 || mock.cancelled[0] != "SOLUSDT" {
+		t.Errorf("orphan orders not cancelled: %v", mock.cancelled)
+	}
+
+	positions, _ := journal.Positions("t1")
+	if _, ok := positions["XRPUSDT_long"]; ok {
+		t.Error("stale position should be closed in the journal")
+	}
+	if pos := positions["ETHUSDT_short"]; pos == nil || pos.Quantity != 2 {
+		t.Errorf("unknown position should be adopted into the journal, got %+v", pos)
+	}
+	if at.reconciliationHalted() {
+		t.Error("trading should not be halted after a full repair")
+	}
+}
+
+func TestReconcileHaltsUntilAcknowledged(t *testing.T) {
+	mock := &reconcileMockTrader{MockTrader: MockTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0, "entryPrice": 50000.0},
+	}}}
+
+	t.Run("止损价未知时无法修复", func(t *testing.T) {
+		at, _ := newReconcileTestTrader(t, mock, ReconcilePolicyRepair)
+		var alerts []Alert
+		at.config.AlertHandler = func(a Alert) { alerts = append(alerts, a) }
+
+		at.reconcileOnStart()
+		if !at.reconciliationHalted() {
+			t.Fatal("expected trading to be halted")
+		}
+		if len(alerts) != 1 || alerts[0].Severity != AlertSeverityCritical {
+			t.Errorf("expected one critical alert, got %+v", alerts)
+		}
+		if d := divergenceTypes(at.GetReconcileReport())[DivergenceMissingStopLoss]; d.Repaired || d.RepairError == "" {
+			t.Errorf("missing stop loss should be unresolved with an error, got %+v", d)
+		}
+
+		if err := at.AcknowledgeReconciliation("user-1"); err != nil {
+			t.Fatalf("AcknowledgeReconciliation: %v", err)
+		}
+		if at.reconciliationHalted() {
+			t.Error("trading should resume after acknowledgement")
+		}
+		if err := at.AcknowledgeReconciliation("user-1"); err == nil {
+			t.Error("second acknowledgement should fail")
+		}
+	})
+
+	t.Run("halt 策略不修复", func(t *testing.T) {
+		at, journal := newReconcileTestTrader(t, mock, ReconcilePolicyHalt)
+		journal.Record("t1", store.EventFill, "BTCUSDT", "long", store.Fill{Action: "open", Quantity: 1, Price: 50000})
+		journal.Record("t1", store.EventProtection, "BTCUSDT", "long", store.Protection{Kind: "stop_loss", Price: 48000})
+		at.restoreFromJournal()
+		mock.stopLoss = nil
+
+		at.reconcileOnStart()
+		if !at.reconciliationHalted() {
+			t.Fatal("expected halt policy to halt trading")
+		}
+		if len(mock.stopLoss) != 0 {
+			t.Errorf("halt policy must not place orders, got %v", mock.stopLoss)
+		}
+	})
+
+	t.Run("off 策略不对账", func(t *testing.T) {
+		at, _ := newReconcileTestTrader(t, mock, ReconcilePolicyOff)
+		at.reconcileOnStart()
+		if at.GetReconcileReport() != nil || at.reconciliationHalted() {
+			t.Error("off policy should skip reconciliation")
+		}
+	})
+}
@@ -0,0 +1,123 @@
+package trader
+
+import (
+	"fmt"
+	"math/rand"
+	"net/http"
+	"nofx/exchangehttp"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryPolicy 交易所请求的重试策略（指数退避 + 随机抖动）
+type RetryPolicy struct {
+	MaxAttempts int           // 最大尝试次数（含首次请求，1 表示不重试）
+	BaseDelay   time.Duration // 第一次重试前的等待时间，之后每次翻倍
+	MaxDelay    time.Duration // 单次等待上限
+	Jitter      float64       // 抖动比例 [0,1]：实际等待在 [d*(1-Jitter), d] 之间随机，避免多个交易员同时重试
+}
+
+// DefaultRetryPolicy 默认重试策略：最多 3 次，等待约 0.5s、1s
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   500 * time.Millisecond,
+	MaxDelay:    5 * time.Second,
+	Jitter:      0.5,
+}
+
+// retryRand 抖动随机源（测试中替换）
+var retryRand = rand.Float64
+
+// orDefault 未配置（MaxAttempts 为 0）时使用 DefaultRetryPolicy
+func (p RetryPolicy) orDefault() RetryPolicy {
+	if p.MaxAttempts == 0 {
+		return DefaultRetryPolicy
+	}
+	return p
+}
+
+// Backoff 第 retry 次重试（从 1 开始）前的等待时间
+func (p RetryPolicy) Backoff(retry int) time.Duration {
+	if retry < 1 || p.BaseDelay <= 0 {
+		return 0
+	}
+	d := p.BaseDelay
+	for i := 1; i < retry && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if jitter := min(max(p.Jitter, 0), 1); jitter > 0 {
+		d -= time.Duration(float64(d) * jitter * retryRand())
+	}
+	return d
+}
+
+// httpRetry 转为 exchangehttp 的重试配置（只重试临时错误）
+func (p RetryPolicy) httpRetry() exchangehttp.Retry {
+	return exchangehttp.Retry{MaxAttempts: p.MaxAttempts, Backoff: p.Backoff, Retryable: exchangehttp.Temporary}
+}
+
+func (p RetryPolicy) String() string {
+	return fmt.Sprintf("max_attempts=%d,base_delay=%s,max_delay=%s,jitter=%g", p.MaxAttempts, p.BaseDelay, p.MaxDelay, p.Jitter)
+}
+
+// ParseRetryPolicy 解析 "max_attempts=5,base_delay=1s,max_delay=10s,jitter=0.3" 形式的重试策略，
+// 未列出的字段使用 DefaultRetryPolicy；"off" 表示不重试
+func ParseRetryPolicy(s string) (RetryPolicy, error) {
+	p := DefaultRetryPolicy
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return p, nil
+	}
+	if strings.EqualFold(s, "off") {
+		p.MaxAttempts = 1
+		return p, nil
+	}
+	for _, item := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			return RetryPolicy{}, fmt.Errorf("重试策略格式错误: %q（应为 key=value）", item)
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		var err error
+		switch key {
+		case "max_attempts":
+			p.MaxAttempts, err = strconv.Atoi(value)
+			if err == nil && p.MaxAttempts < 1 {
+				err = fmt.Errorf("必须 >= 1")
+			}
+		case "base_delay":
+			p.BaseDelay, err = time.ParseDuration(value)
+		case "max_delay":
+			p.MaxDelay, err = time.ParseDuration(value)
+		case "jitter":
+			p.Jitter, err = strconv.ParseFloat(value, 64)
+			if err == nil && (p.Jitter < 0 || p.Jitter > 1) {
+				err = fmt.Errorf("必须在 [0,1] 之间")
+			}
+		default:
+			return RetryPolicy{}, fmt.Errorf("未知的重试策略字段: %q", key)
+		}
+		if err != nil {
+			return RetryPolicy{}, fmt.Errorf("重试策略 %s=%q 无效: %w", key, value, err)
+		}
+	}
+	return p, nil
+}
+
+// RetryPolicyFromEnv 读取 NOFX_RETRY_POLICY（未设置时使用 DefaultRetryPolicy）
+func RetryPolicyFromEnv() (RetryPolicy, error) {
+	return ParseRetryPolicy(os.Getenv("NOFX_RETRY_POLICY"))
+}
+
+// isIdempotentRequest 请求失败后重发是否安全：
+// GET 查询天然幂等；DELETE 撤单重复发送只会返回订单不存在；
+// POST 不自动重发：clientOrderId 只在挂单中唯一，已成交的订单不能靠它去重，
+// 下单请求的重发由调用方先按 clientOrderId 查询确认订单不存在后再进行
+func isIdempotentRequest(method string) bool {
+	return method == http.MethodGet || method == http.MethodDelete
+}
@@ -0,0 +1,237 @@
+package trader
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	orig := retryRand
+	defer func() { retryRand = orig }()
+	retryRand = func() float64 { return 1 }
+
+	p := RetryPolicy{MaxAttempts: 5, BaseDelay: 100 * time.Millisecond, MaxDelay: 300 * time.Millisecond}
+	want := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, w := range want {
+		if got := p.Backoff(i + 1); got != w {
+			t.Errorf("Backoff(%d) = %v, want %v", i+1, got, w)
+		}
+	}
+
+	p.Jitter = 0.5
+	if got := p.Backoff(1); got != 50*time.Millisecond {
+		t.Errorf("抖动后 Backoff(1) = %v, want 50ms", got)
+	}
+}
+
+func TestParseRetryPolicy(t *testing.T) {
+	p, err := ParseRetryPolicy("max_attempts=5, base_delay=1s")
+	if err != nil {
+		t.Fatalf("ParseRetryPolicy() error = %v", err)
+	}
+	if p.MaxAttempts != 5 || p.BaseDelay != time.Second || p.MaxDelay != DefaultRetryPolicy.MaxDelay {
+		t.Errorf("ParseRetryPolicy() = %+v", p)
+	}
+	if p, _ := ParseRetryPolicy("off"); p.MaxAttempts != 1 {
+		t.Errorf("off 应关闭重试, got %+v", p)
+	}
+	for _, bad := range []string{"max_attempts=0", "jitter=2", "base_delay=abc", "foo=1", "max_attempts"} {
+		if _, err := ParseRetryPolicy(bad); err == nil {
+			t.Errorf("ParseRetryPolicy(%q) 应返回错误", bad)
+		}
+	}
+}
+
+func TestIsIdempotentRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		want   bool
+	}{
+		{http.MethodGet, true},
+		{http.MethodDelete, true},
+		{http.MethodPost, false},
+		{http.MethodPut, false},
+	}
+	for _, tt := range tests {
+		if got := isIdempotentRequest(tt.method); got != tt.want {
+			t.Errorf("isIdempotentRequest(%s) = %v, want %v", tt.method, got, tt.want)
+		}
+	}
+}
+
+// dropConnection 不返回响应直接断开连接（模拟下单请求超时/连接被重置）
+func dropConnection(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+func newRetryTestAsterTrader(t *testing.T, handler http.HandlerFunc) *AsterTrader {
+	t.Helper()
+	server := startIPv4Server(t, handler)
+	t.Cleanup(server.Close)
+	trader, err := NewAsterTrader(
+		"0x1234567890123456789012345678901234567890",
+		"0xabcdefabcdefabcdefabcdefabcdefabcdefabcd",
+		"0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef",
+	)
+	if err != nil {
+		t.Fatalf("NewAsterTrader() error = %v", err)
+	}
+	trader.baseURL = server.URL
+	trader.SetRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond})
+	return trader
+}
+
+func TestAsterRequestDoesNotRetryUnsafePost(t *testing.T) {
+	var calls int32
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		dropConnection(w)
+	})
+
+	if _, err := trader.request(context.Background(), http.MethodPost, "/fapi/v3/leverage", map[string]interface{}{"symbol": "BTCUSDT"}); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := atomic.LoadInt32(&calls); n != 1 {
+		t.Errorf("不带 clientOrderId 的 POST 不应重试, calls = %d", n)
+	}
+}
+
+func TestAsterRequestRetriesGet(t *testing.T) {
+	var calls int32
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			dropConnection(w)
+			return
+		}
+		w.Write([]byte(`[]`))
+	})
+
+	if _, err := trader.request(context.Background(), http.MethodGet, "/fapi/v3/balance", map[string]interface{}{}); err != nil {
+		t.Fatalf("request() error = %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Errorf("calls = %d, want 3", n)
+	}
+}
+
+// writeOrderNotFound 模拟按 clientOrderId 查询时订单不存在（-2013）
+func writeOrderNotFound(w http.ResponseWriter) {
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write([]byte(`{"code":-2013,"msg":"Order does not exist."}`))
+}
+
+func placeTestAsterOrder(trader *AsterTrader) ([]byte, error) {
+	return trader.request(context.Background(), http.MethodPost, "/fapi/v3/order", map[string]interface{}{
+		"symbol":           "BTCUSDT",
+		"newClientOrderId": "nofx-test-1",
+	})
+}
+
+func TestAsterOrderRetryReturnsAcceptedOrder(t *testing.T) {
+	var posts int32
+	var queriedID string
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			atomic.AddInt32(&posts, 1)
+			dropConnection(w) // 已被受理但响应丢失
+		case http.MethodGet:
+			r.ParseForm()
+			queriedID = r.Form.Get("origClientOrderId")
+			w.Write([]byte(`{"orderId":42,"status":"FILLED"}`))
+		}
+	})
+
+	body, err := placeTestAsterOrder(trader)
+	if err != nil {
+		t.Fatalf("request() error = %v", err)
+	}
+	if string(body) != `{"orderId":42,"status":"FILLED"}` || queriedID != "nofx-test-1" {
+		t.Errorf("应按 clientOrderId 查回订单, body=%s queried=%q", body, queriedID)
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Errorf("订单已存在时不应重发, posts = %d", n)
+	}
+}
+
+func TestAsterOrderRetryResendsWhenOrderMissing(t *testing.T) {
+	var posts int32
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			if atomic.AddInt32(&posts, 1) == 1 {
+				dropConnection(w) // 未到达交易所
+				return
+			}
+			w.Write([]byte(`{"orderId":43,"status":"NEW"}`))
+		case http.MethodGet:
+			writeOrderNotFound(w)
+		}
+	})
+
+	body, err := placeTestAsterOrder(trader)
+	if err != nil {
+		t.Fatalf("request() error = %v", err)
+	}
+	if string(body) != `{"orderId":43,"status":"NEW"}` {
+		t.Errorf("body = %s", body)
+	}
+	if n := atomic.LoadInt32(&posts); n != 2 {
+		t.Errorf("确认订单不存在后应重发一次, posts = %d", n)
+	}
+}
+
+func TestAsterOrderRetryStopsWhenStatusUnknown(t *testing.T) {
+	var posts int32
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			atomic.AddInt32(&posts, 1)
+		}
+		dropConnection(w) // 查询同样失败
+	})
+
+	if _, err := placeTestAsterOrder(trader); err == nil {
+		t.Fatal("expected error")
+	}
+	if n := atomic.LoadInt32(&posts); n != 1 {
+		t.Errorf("无法确认订单状态时不应重发, posts = %d", n)
+	}
+}
+
+func TestAsterOrderRetryRecoversDuplicateClientOrderID(t *testing.T) {
+	var posts, gets int32
+	trader := newRetryTestAsterTrader(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			if atomic.AddInt32(&posts, 1) == 1 {
+				dropConnection(w) // 已发出，查询时交易所尚未受理
+				return
+			}
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":-4116,"msg":"ClientOrderId is duplicated."}`))
+		case http.MethodGet:
+			if atomic.AddInt32(&gets, 1) == 1 {
+				writeOrderNotFound(w)
+				return
+			}
+			w.Write([]byte(`{"orderId":42,"status":"NEW"}`))
+		}
+	})
+
+	body, err := placeTestAsterOrder(trader)
+	if err != nil {
+		t.Fatalf("request() error = %v", err)
+	}
+	if string(body) != `{"orderId":42,"status":"NEW"}` {
+		t.Errorf("body = %s", body)
+	}
+	if n := atomic.LoadInt32(&posts); n != 2 {
+		t.Errorf("posts = %d, want 2", n)
+	}
+}
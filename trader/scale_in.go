@@ -0,0 +1,75 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/logger"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ScaleInFromEnv 读取加仓配置：NOFX_ALLOW_SCALE_IN（true/false，默认 false）和
+// NOFX_LOT_MATCHING（部分平仓消耗批次的顺序 fifo/lifo，默认 fifo）
+func ScaleInFromEnv() (bool, string, error) {
+	allow := false
+	if v := strings.TrimSpace(os.Getenv("NOFX_ALLOW_SCALE_IN")); v != "" {
+		var err error
+		if allow, err = strconv.ParseBool(v); err != nil {
+			return false, "", fmt.Errorf("NOFX_ALLOW_SCALE_IN=%q 应为 true 或 false", v)
+		}
+	}
+	method := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_LOT_MATCHING")))
+	switch method {
+	case "", logger.LotMatchFIFO, logger.LotMatchLIFO:
+	default:
+		return false, "", fmt.Errorf("NOFX_LOT_MATCHING=%q 无效（支持 fifo、lifo）", method)
+	}
+	return allow, method, nil
+}
+
+// existingPosition 查找已有同币种同方向持仓，返回其数量及是否存在
+func existingPosition(positions []map[string]interface{}, symbol, side string) (float64, bool) {
+	for _, pos := range positions {
+		if pos["symbol"] == symbol && pos["side"] == side {
+			amt, _ := pos["positionAmt"].(float64)
+			return math.Abs(amt), true
+		}
+	}
+	return 0, false
+}
+
+// checkScaleIn 检查已有同向持仓：未开启 AllowScaleIn 或顺势加仓时拒绝开仓，开启时标记为加仓并返回已有持仓数量
+func (at *AutoTrader) checkScaleIn(positions []map[string]interface{}, symbol, side string, actionRecord *logger.DecisionAction) (float64, error) {
+	existingQty, exists := existingPosition(positions, symbol, side)
+	if !exists {
+		return 0, nil
+	}
+
+	if !at.config.AllowScaleIn && at.config.Pyramiding == nil {
+		if side == "long" {
+			return 0, fmt.Errorf("❌ %s 已有多仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_long 决策", symbol)
+		}
+		return 0, fmt.Errorf("❌ %s 已有空仓，拒绝开仓以防止仓位叠加超限。如需换仓，请先给出 close_short 决策", symbol)
+	}
+
+	actionRecord.ScaleIn = true
+	log.Info("  ➕ 已有同向持仓，本次开仓为加仓", "symbol", symbol, "side", sideLabel(side), "existing_qty", existingQty)
+	return existingQty, nil
+}
+
+// replaceProtectionForScaleIn 加仓后撤销原止损/止盈单，返回合并后持仓的总数量（用于重新设置止损/止盈）
+func (at *AutoTrader) replaceProtectionForScaleIn(symbol string, existingQty, addedQty float64) float64 {
+	if err := at.trader.CancelStopOrders(at.ctx(), symbol); err != nil {
+		log.Warn("  ⚠ 加仓后撤销原止损/止盈失败", "error", err)
+	}
+	return existingQty + addedQty
+}
+
+// sideLabel 持仓方向的中文名
+func sideLabel(side string) string {
+	if side == "short" {
+		return "空"
+	}
+	return "多"
+}
@@ -0,0 +1,69 @@
+package trader
+
+import (
+	"nofx/logger"
+	"testing"
+)
+
+func TestCheckScaleIn(t *testing.T) {
+	positions := []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+		{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0},
+	}
+
+	tests := []struct {
+		name         string
+		allowScaleIn bool
+		symbol       string
+		side         string
+		expectQty    float64
+		expectErr    bool
+		expectMarked bool
+	}{
+		{name: "无持仓_正常开仓", allowScaleIn: false, symbol: "SOLUSDT", side: "long"},
+		{name: "已有多仓_默认拒绝", allowScaleIn: false, symbol: "BTCUSDT", side: "long", expectErr: true},
+		{name: "反向持仓不算加仓", allowScaleIn: true, symbol: "BTCUSDT", side: "short"},
+		{name: "允许加仓_多仓", allowScaleIn: true, symbol: "BTCUSDT", side: "long", expectQty: 0.5, expectMarked: true},
+		{name: "允许加仓_空仓取绝对值", allowScaleIn: true, symbol: "ETHUSDT", side: "short", expectQty: 2.0, expectMarked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at := &AutoTrader{config: AutoTraderConfig{AllowScaleIn: tt.allowScaleIn}}
+			record := &logger.DecisionAction{}
+
+			qty, err := at.checkScaleIn(positions, tt.symbol, tt.side, record)
+			if (err != nil) != tt.expectErr {
+				t.Fatalf("期望错误=%v，实际 %v", tt.expectErr, err)
+			}
+			if qty != tt.expectQty {
+				t.Errorf("期望已有数量 %.4f，实际 %.4f", tt.expectQty, qty)
+			}
+			if record.ScaleIn != tt.expectMarked {
+				t.Errorf("期望 ScaleIn=%v，实际 %v", tt.expectMarked, record.ScaleIn)
+			}
+		})
+	}
+}
+
+func TestScaleInFromEnv(t *testing.T) {
+	if allow, method, err := ScaleInFromEnv(); err != nil || allow || method != "" {
+		t.Fatalf("未配置时应拒绝加仓并使用默认批次顺序: %v %q %v", allow, method, err)
+	}
+
+	t.Setenv("NOFX_ALLOW_SCALE_IN", "true")
+	t.Setenv("NOFX_LOT_MATCHING", "LIFO")
+	if allow, method, err := ScaleInFromEnv(); err != nil || !allow || method != logger.LotMatchLIFO {
+		t.Errorf("配置解析错误: %v %q %v", allow, method, err)
+	}
+
+	t.Setenv("NOFX_LOT_MATCHING", "average")
+	if _, _, err := ScaleInFromEnv(); err == nil {
+		t.Error("未知的批次顺序应返回错误")
+	}
+	t.Setenv("NOFX_LOT_MATCHING", "")
+	t.Setenv("NOFX_ALLOW_SCALE_IN", "maybe")
+	if _, _, err := ScaleInFromEnv(); err == nil {
+		t.Error("无效的 NOFX_ALLOW_SCALE_IN 应返回错误")
+	}
+}
@@ -0,0 +1,297 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"nofx/store"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// scaleOutQtyTolerance 判断止盈单已成交时的数量容差（占初始数量的比例，吸收交易所数量步进取整）
+const scaleOutQtyTolerance = 0.01
+
+// PartialTakeProfitSetter 能按数量挂止盈单的交易器（分批止盈的每一档只平掉部分持仓）
+type PartialTakeProfitSetter interface {
+	// SetPartialTakeProfit 为持仓设置只平掉 quantity 的止盈单
+	SetPartialTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error
+}
+
+// ScaleOutStep 分批止盈的一档
+type ScaleOutStep struct {
+	R        float64 // 目标距离，以风险倍数表示（1R = 开仓价到止损价的距离）
+	ClosePct float64 // 平掉初始数量的百分比
+}
+
+// ScaleOut 分批止盈计划：如 1R 平 50%、2R 平 25%，剩余尾仓由移动止损保护
+type ScaleOut struct {
+	Steps               []ScaleOutStep // 按 R 从小到大排列，ClosePct 合计不超过 100
+	BreakevenAfterFirst bool           // 第一档成交后将止损移至开仓价
+	RunnerTrailPct      float64        // 全部目标成交后，尾仓止损跟随最优价格的回撤百分比（0=不移动）
+}
+
+// ParseScaleOutSteps 解析分批止盈档位，格式 "1R:50,2R:25"（R 倍数:平仓百分比）
+func ParseScaleOutSteps(raw string) ([]ScaleOutStep, error) {
+	var steps []ScaleOutStep
+	total := 0.0
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		rRaw, pctRaw, ok := strings.Cut(part, ":")
+		if !ok {
+			return nil, fmt.Errorf("档位 %q 格式错误（应为 1R:50）", part)
+		}
+		r, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToUpper(strings.TrimSpace(rRaw)), "R"), 64)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("档位 %q 的 R 倍数无效", part)
+		}
+		pct, err := strconv.ParseFloat(strings.TrimSuffix(strings.TrimSpace(pctRaw), "%"), 64)
+		if err != nil || pct <= 0 || pct > 100 {
+			return nil, fmt.Errorf("档位 %q 的平仓百分比无效（0-100）", part)
+		}
+		if n := len(steps); n > 0 && r <= steps[n-1].R {
+			return nil, fmt.Errorf("档位 %q 的 R 倍数必须大于上一档", part)
+		}
+		if total += pct; total > 100+1e-9 {
+			return nil, fmt.Errorf("平仓百分比合计 %.1f%% 超过 100%%", total)
+		}
+		steps = append(steps, ScaleOutStep{R: r, ClosePct: pct})
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("未配置任何档位")
+	}
+	return steps, nil
+}
+
+// ScaleOutFromEnv 读取分批止盈配置（NOFX_SCALE_OUT 未设置时返回 nil）
+func ScaleOutFromEnv() (*ScaleOut, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_SCALE_OUT"))
+	if raw == "" {
+		return nil, nil
+	}
+	steps, err := ParseScaleOutSteps(raw)
+	if err != nil {
+		return nil, fmt.Errorf("NOFX_SCALE_OUT 配置错误: %w", err)
+	}
+	plan := &ScaleOut{Steps: steps, BreakevenAfterFirst: true}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SCALE_OUT_BREAKEVEN")); v != "" {
+		if plan.BreakevenAfterFirst, err = strconv.ParseBool(v); err != nil {
+			return nil, fmt.Errorf("NOFX_SCALE_OUT_BREAKEVEN=%q 无效（true/false）", v)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_SCALE_OUT_TRAIL_PCT")); v != "" {
+		if plan.RunnerTrailPct, err = strconv.ParseFloat(v, 64); err != nil || plan.RunnerTrailPct < 0 || plan.RunnerTrailPct >= 100 {
+			return nil, fmt.Errorf("NOFX_SCALE_OUT_TRAIL_PCT=%q 无效（0-100）", v)
+		}
+	}
+	return plan, nil
+}
+
+// scaleOutTarget 一档止盈的执行状态
+type scaleOutTarget struct {
+	r          float64
+	price      float64
+	quantity   float64
+	cumQty     float64 // 含本档在内的累计平仓数量
+	onExchange bool    // 已挂交易所止盈单（否则由监控触发平仓）
+	hit        bool
+}
+
+// scaleOutState 单个持仓的分批止盈进度
+type scaleOutState struct {
+	side       string // long / short
+	entry      float64
+	risk       float64 // 1R 对应的价格距离
+	initialQty float64
+	targets    []scaleOutTarget
+	breakeven  bool
+	stop       float64 // 当前止损价
+	best       float64 // 开仓以来的最优价格（尾仓移动止损用）
+}
+
+// runnerQty 全部目标成交后剩余的尾仓数量
+func (s *scaleOutState) runnerQty() float64 {
+	return s.initialQty - s.targets[len(s.targets)-1].cumQty
+}
+
+// favorable a 是否比 b 更有利（多单价格更高，空单价格更低）
+func (s *scaleOutState) favorable(a, b float64) bool {
+	if s.side == "short" {
+		return a < b
+	}
+	return a > b
+}
+
+// placeScaleOut 开仓后按分批止盈计划挂各档止盈单，返回最后一档目标价；未启用或止损无效时返回 false（沿用单一止盈）
+func (at *AutoTrader) placeScaleOut(symbol, side string, entry, stop, quantity float64) (float64, bool) {
+	plan := at.config.ScaleOut
+	if plan == nil || len(plan.Steps) == 0 || entry <= 0 || stop <= 0 || quantity <= 0 {
+		return 0, false
+	}
+	direction := 1.0
+	if side == "short" {
+		direction = -1.0
+	}
+	risk := (entry - stop) * direction
+	if risk <= 0 {
+		log.Warn("  ⚠ 止损价不在开仓价的亏损方向，不使用分批止盈", "symbol", symbol, "stop_loss", stop, "entry_price", entry)
+		return 0, false
+	}
+
+	positionSide := strings.ToUpper(side)
+	s := &scaleOutState{side: side, entry: entry, risk: risk, initialQty: quantity, stop: stop, best: entry}
+	payload := store.ScaleOutEvent{Stage: store.ScaleOutStagePlaced, Entry: entry, Risk: risk, Quantity: quantity, Price: stop}
+	cum := 0.0
+	for i, step := range plan.Steps {
+		t := scaleOutTarget{r: step.R, price: entry + direction*step.R*risk, quantity: quantity * step.ClosePct / 100}
+		cum += t.quantity
+		t.cumQty = cum
+		if at.partialTakeProfit != nil {
+			if err := at.partialTakeProfit.SetPartialTakeProfit(at.ctx(), symbol, positionSide, t.quantity, t.price); err != nil {
+				log.Warn("  ⚠ 止盈挂单失败，改由本地监控触发", "symbol", symbol, "step", i+1, "error", err)
+			} else {
+				t.onExchange = true
+			}
+		}
+		s.targets = append(s.targets, t)
+		payload.Targets = append(payload.Targets, store.ScaleOutTarget{R: t.r, Price: t.price, Quantity: t.quantity, OnExchange: t.onExchange})
+		log.Info("  🪜 分批止盈", "symbol", symbol, "side", side, "step", i+1, "r", step.R, "price", at.config.NumberFormat.Price(t.price), "quantity", t.quantity)
+	}
+
+	at.recordScaleOut(symbol, side, payload)
+	at.ladderMutex.Lock()
+	if at.scaleOuts == nil {
+		at.scaleOuts = make(map[string]*scaleOutState)
+	}
+	at.scaleOuts[symbol+"_"+side] = s
+	at.ladderMutex.Unlock()
+	return s.targets[len(s.targets)-1].price, true
+}
+
+// clearScaleOut 持仓平仓或加仓后清除分批止盈进度
+func (at *AutoTrader) clearScaleOut(posKey string) {
+	at.ladderMutex.Lock()
+	defer at.ladderMutex.Unlock()
+	delete(at.scaleOuts, posKey)
+}
+
+// checkScaleOut 由回撤监控调用：检测各档止盈成交（交易所挂单按持仓减少判断，本地档位按价格触发平仓），
+// 第一档成交后止损移至保本，全部成交后尾仓止损跟随最优价格；持仓未启用分批止盈时返回 false
+func (at *AutoTrader) checkScaleOut(symbol, side string, markPrice, quantity float64) bool {
+	posKey := symbol + "_" + side
+	at.ladderMutex.Lock()
+	defer at.ladderMutex.Unlock()
+	s := at.scaleOuts[posKey]
+	if s == nil {
+		return false
+	}
+	plan := at.config.ScaleOut
+	if plan == nil {
+		return true
+	}
+	if s.favorable(markPrice, s.best) {
+		s.best = markPrice
+	}
+
+	tolerance := s.initialQty * scaleOutQtyTolerance
+	last := len(s.targets) - 1
+	for i := range s.targets {
+		t := &s.targets[i]
+		if t.hit {
+			continue
+		}
+		if t.onExchange {
+			if quantity > s.initialQty-t.cumQty+tolerance {
+				break
+			}
+		} else {
+			if s.favorable(t.price, markPrice) {
+				break // 价格尚未到达目标
+			}
+			closeQty := t.quantity
+			if i == last && s.runnerQty() <= tolerance {
+				closeQty = 0 // 最后一档且无尾仓：按交易所持仓全部平掉，避免残留
+			}
+			var err error
+			if side == "long" {
+				_, err = at.trader.CloseLong(at.ctx(), symbol, closeQty)
+			} else {
+				_, err = at.trader.CloseShort(at.ctx(), symbol, closeQty)
+			}
+			if err != nil {
+				log.Error("❌ 分批止盈平仓失败", "step", i+1, "symbol", symbol, "side", side, "error", err)
+				break
+			}
+			quantity -= t.quantity
+		}
+
+		t.hit = true
+		log.Info("🪜 分批止盈成交", "step", i+1, "symbol", symbol, "side", side, "r", t.r, "price", at.config.NumberFormat.Price(t.price), "quantity", t.quantity)
+		at.recordScaleOut(symbol, side, store.ScaleOutEvent{Stage: store.ScaleOutStageTarget, Step: i + 1, Quantity: t.quantity, Price: t.price})
+	}
+
+	remaining := math.Max(quantity, 0)
+	if remaining <= tolerance {
+		return true
+	}
+	if plan.BreakevenAfterFirst && !s.breakeven && s.targets[0].hit && s.favorable(s.entry, s.stop) {
+		if at.replaceStopLoss(symbol, side, remaining, s.entry) {
+			s.stop, s.breakeven = s.entry, true
+			log.Info("🛡️ 第一档止盈已成交，止损移至保本", "symbol", symbol, "side", side, "stop_loss", at.config.NumberFormat.Price(s.entry))
+			at.recordScaleOut(symbol, side, store.ScaleOutEvent{Stage: store.ScaleOutStageBreakeven, Quantity: remaining, Price: s.entry})
+		}
+	}
+	if plan.RunnerTrailPct > 0 && s.targets[last].hit {
+		distance := s.best * plan.RunnerTrailPct / 100
+		candidate := s.best - distance
+		if side == "short" {
+			candidate = s.best + distance
+		}
+		// 每次至少上移回撤距离的 1/4，避免频繁撤单重挂
+		if s.favorable(candidate, s.stop) && math.Abs(candidate-s.stop) >= distance/4 {
+			if at.replaceStopLoss(symbol, side, remaining, candidate) {
+				s.stop = candidate
+				log.Info("📈 尾仓移动止损", "symbol", symbol, "side", side, "stop_loss", at.config.NumberFormat.Price(candidate), "best_price", at.config.NumberFormat.Price(s.best))
+				at.recordScaleOut(symbol, side, store.ScaleOutEvent{Stage: store.ScaleOutStageTrail, Quantity: remaining, Price: candidate})
+			}
+		}
+	}
+	return true
+}
+
+// recordScaleOut 写入分批止盈事件
+func (at *AutoTrader) recordScaleOut(symbol, side string, event store.ScaleOutEvent) {
+	if at.journal == nil {
+		return
+	}
+	if _, err := at.journal.Record(at.id, store.EventScaleOut, symbol, side, event); err != nil {
+		log.Warn("⚠️ 写入分批止盈事件失败", "symbol", symbol, "side", side, "error", err)
+	}
+}
+
+// restoreScaleOut 由事件日志恢复持仓的分批止盈进度（崩溃重启后继续管理剩余档位）
+func (at *AutoTrader) restoreScaleOut(key string, pos *store.PositionState) {
+	so := pos.ScaleOut
+	if so == nil || len(so.Targets) == 0 {
+		return
+	}
+	s := &scaleOutState{side: pos.Side, entry: so.Entry, risk: so.Risk, initialQty: so.InitialQty, stop: so.Stop, breakeven: so.Breakeven, best: so.Entry}
+	cum := 0.0
+	for i, t := range so.Targets {
+		cum += t.Quantity
+		s.targets = append(s.targets, scaleOutTarget{r: t.R, price: t.Price, quantity: t.Quantity, cumQty: cum, onExchange: t.OnExchange, hit: i < so.Hits})
+	}
+	if so.Hits == len(so.Targets) {
+		s.best = s.targets[len(s.targets)-1].price
+	}
+	at.ladderMutex.Lock()
+	defer at.ladderMutex.Unlock()
+	if at.scaleOuts == nil {
+		at.scaleOuts = make(map[string]*scaleOutState)
+	}
+	at.scaleOuts[key] = s
+}
@@ -0,0 +1,123 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+// partialTakeProfitTrader 记录分批止盈挂单的 MockTrader
+type partialTakeProfitTrader struct {
+	stopRecordingTrader
+	takeProfits []string
+}
+
+func (m *partialTakeProfitTrader) SetPartialTakeProfit(ctx context.Context, symbol string, positionSide string, quantity, takeProfitPrice float64) error {
+	m.takeProfits = append(m.takeProfits, fmt.Sprintf("%s_%s:%.2f@%.1f", symbol, positionSide, quantity, takeProfitPrice))
+	return nil
+}
+
+func TestParseScaleOutSteps(t *testing.T) {
+	steps, err := ParseScaleOutSteps("1R:50, 2r:25%")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(steps) != 2 || steps[0] != (ScaleOutStep{R: 1, ClosePct: 50}) || steps[1] != (ScaleOutStep{R: 2, ClosePct: 25}) {
+		t.Errorf("解析结果错误: %+v", steps)
+	}
+
+	for _, raw := range []string{"", "1R", "0R:50", "1R:0", "2R:50,1R:25", "1R:60,2R:50"} {
+		if _, err := ParseScaleOutSteps(raw); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
+
+func TestScaleOutFromEnv(t *testing.T) {
+	if plan, err := ScaleOutFromEnv(); err != nil || plan != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", plan, err)
+	}
+
+	t.Setenv("NOFX_SCALE_OUT", "1R:50,2R:25")
+	t.Setenv("NOFX_SCALE_OUT_TRAIL_PCT", "1.5")
+	plan, err := ScaleOutFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(plan.Steps) != 2 || !plan.BreakevenAfterFirst || plan.RunnerTrailPct != 1.5 {
+		t.Errorf("配置解析错误: %+v", plan)
+	}
+
+	t.Setenv("NOFX_SCALE_OUT_BREAKEVEN", "maybe")
+	if _, err := ScaleOutFromEnv(); err == nil {
+		t.Error("无效的 NOFX_SCALE_OUT_BREAKEVEN 应返回错误")
+	}
+}
+
+func TestScaleOutExchangeTargetsBreakevenAndTrail(t *testing.T) {
+	mock := &partialTakeProfitTrader{}
+	at := &AutoTrader{trader: mock, partialTakeProfit: mock, config: AutoTraderConfig{ScaleOut: &ScaleOut{
+		Steps:               []ScaleOutStep{{R: 1, ClosePct: 50}, {R: 2, ClosePct: 25}},
+		BreakevenAfterFirst: true,
+		RunnerTrailPct:      1,
+	}}}
+
+	lastTarget, ok := at.placeScaleOut("BTCUSDT", "long", 100, 95, 1)
+	if !ok || lastTarget != 110 {
+		t.Fatalf("placeScaleOut = %v, %v", lastTarget, ok)
+	}
+	if fmt.Sprint(mock.takeProfits) != "[BTCUSDT_LONG:0.50@105.0 BTCUSDT_LONG:0.25@110.0]" {
+		t.Fatalf("止盈挂单错误: %v", mock.takeProfits)
+	}
+
+	// 价格接近目标但持仓未减少：交易所止盈单尚未成交
+	at.checkScaleOut("BTCUSDT", "long", 104.9, 1)
+	if len(mock.stops) != 0 {
+		t.Fatalf("第一档成交前不应移动止损: %v", mock.stops)
+	}
+
+	// 第一档成交（持仓减半），止损移至保本
+	at.checkScaleOut("BTCUSDT", "long", 105.5, 0.5)
+	if fmt.Sprint(mock.stops) != "[BTCUSDT_LONG@100.0]" {
+		t.Fatalf("第一档成交后应移至保本: %v", mock.stops)
+	}
+
+	// 第二档成交，尾仓止损跟随最优价 112 回撤 1%
+	at.checkScaleOut("BTCUSDT", "long", 112, 0.25)
+	at.checkScaleOut("BTCUSDT", "long", 112.1, 0.25) // 变动不足回撤距离的 1/4，不重挂
+	if fmt.Sprint(mock.stops) != "[BTCUSDT_LONG@100.0 BTCUSDT_LONG@110.9]" {
+		t.Errorf("尾仓移动止损错误: %v", mock.stops)
+	}
+	if len(mock.closed) != 0 {
+		t.Errorf("交易所止盈单成交时不应再主动平仓: %v", mock.closed)
+	}
+}
+
+func TestScaleOutLocalFallback(t *testing.T) {
+	mock := &stopRecordingTrader{}
+	at := &AutoTrader{trader: mock, config: AutoTraderConfig{ScaleOut: &ScaleOut{
+		Steps: []ScaleOutStep{{R: 1, ClosePct: 50}, {R: 2, ClosePct: 50}},
+	}}}
+
+	if _, ok := at.placeScaleOut("ETHUSDT", "short", 100, 98, 2); ok {
+		t.Fatal("空单止损低于开仓价时不应启用分批止盈")
+	}
+	if _, ok := at.placeScaleOut("ETHUSDT", "short", 100, 104, 2); !ok {
+		t.Fatal("应启用分批止盈")
+	}
+
+	at.checkScaleOut("ETHUSDT", "short", 97, 2)
+	if len(mock.closed) != 0 {
+		t.Fatalf("未到目标不应平仓: %v", mock.closed)
+	}
+	at.checkScaleOut("ETHUSDT", "short", 95.5, 2)
+	at.checkScaleOut("ETHUSDT", "short", 91, 1)
+	if fmt.Sprint(mock.closed) != "[ETHUSDT_short ETHUSDT_short]" || len(mock.stops) != 0 {
+		t.Errorf("应由监控按价格依次平仓且不移动止损: closed=%v stops=%v", mock.closed, mock.stops)
+	}
+
+	at.clearScaleOut("ETHUSDT_short")
+	if at.checkScaleOut("ETHUSDT", "short", 91, 1) {
+		t.Error("清除后不应继续管理该持仓")
+	}
+}
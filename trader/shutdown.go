@@ -0,0 +1,102 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultDrainTimeout 优雅退出时等待进行中订单的默认时间
+const DefaultDrainTimeout = 30 * time.Second
+
+// ShutdownOptions 优雅退出选项
+type ShutdownOptions struct {
+	DrainTimeout           time.Duration // 等待进行中的决策周期和监控操作（下单、平仓）完成的最长时间，超时后中止交易所请求（0=默认 30s）
+	CancelProtectiveOrders bool          // 退出时撤销持仓的止盈止损单（默认保留，进程退出后仓位仍受交易所条件单保护）
+}
+
+// Shutdown 优雅停止交易员：不再开始新的决策周期或执行新决策，等待进行中的订单完成（超时则中止请求），
+// 再按选项保留或撤销持仓的止盈止损单。未运行时直接返回
+func (at *AutoTrader) Shutdown(opts ShutdownOptions) {
+	if !at.isRunning || at.draining.Swap(true) {
+		return
+	}
+	if opts.DrainTimeout <= 0 {
+		opts.DrainTimeout = DefaultDrainTimeout
+	}
+
+	// 等待期间保持 isRunning，进行中的请求仍使用可取消的运行期 ctx
+	// 主循环和监控在当前操作完成后退出；Stop 已先关闭停止信号时由 Stop 完成停止
+	if !at.closeStopMonitor() {
+		return
+	}
+	if !waitTimeout(&at.monitorWg, opts.DrainTimeout) {
+		log.Warn("⚠️ 等待进行中的订单超时，中止交易所请求", "trader", at.name, "timeout", opts.DrainTimeout)
+		at.cancelRun()
+		at.monitorWg.Wait()
+	}
+	at.cancelRun()
+	at.isRunning = false
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.DrainTimeout)
+	defer cancel()
+	if err := at.handleProtectiveOrdersOnShutdown(ctx, opts.CancelProtectiveOrders); err != nil {
+		log.Warn("⚠️ 优雅停止", "trader", at.name, "error", err)
+	}
+	log.Info("⏹ 已优雅停止", "trader", at.name)
+}
+
+// handleProtectiveOrdersOnShutdown 撤销（cancel=true）或保留持仓的止盈止损单
+// 本地模拟止损随进程退出失效，保留模式下提示仍依赖模拟止损的持仓
+func (at *AutoTrader) handleProtectiveOrdersOnShutdown(ctx context.Context, cancel bool) error {
+	at.syntheticStopMutex.Lock()
+	synthetic := len(at.syntheticStops)
+	at.syntheticStopMutex.Unlock()
+
+	if !cancel {
+		if synthetic > 0 {
+			log.Warn("⚠️ 持仓使用本地模拟止损，进程退出后不再受保护", "trader", at.name, "count", synthetic)
+		}
+		return nil
+	}
+
+	positions, err := at.trader.GetPositions(ctx)
+	if err != nil {
+		return fmt.Errorf("获取持仓失败，未撤销止盈止损单: %w", err)
+	}
+	seen := make(map[string]bool)
+	var failed int
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		if symbol == "" || seen[symbol] {
+			continue
+		}
+		seen[symbol] = true
+		if err := at.trader.CancelStopOrders(ctx, symbol); err != nil {
+			failed++
+			log.Warn("⚠️ 撤销止盈止损单失败", "trader", at.name, "symbol", symbol, "error", err)
+			continue
+		}
+		log.Info("🧹 已撤销止盈止损单", "trader", at.name, "symbol", symbol)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 个币种的止盈止损单撤销失败", failed)
+	}
+	return nil
+}
+
+// waitTimeout 等待 wg 完成，超时返回 false
+func waitTimeout(wg *sync.WaitGroup, timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// cancelStopsRecordingTrader 记录撤销止盈止损单调用的 MockTrader
+type cancelStopsRecordingTrader struct {
+	MockTrader
+	cancelled []string
+}
+
+func (m *cancelStopsRecordingTrader) CancelStopOrders(ctx context.Context, symbol string) error {
+	m.cancelled = append(m.cancelled, symbol)
+	return nil
+}
+
+// newShutdownTestTrader 模拟运行中的交易员
+func newShutdownTestTrader(mock Trader) *AutoTrader {
+	at := &AutoTrader{id: "t1", name: "test", trader: mock, isRunning: true, stopMonitorCh: make(chan struct{})}
+	at.runCtx, at.cancelRun = context.WithCancel(context.Background())
+	return at
+}
+
+func TestShutdownWaitsForInFlightOrder(t *testing.T) {
+	mock := &cancelStopsRecordingTrader{}
+	at := newShutdownTestTrader(mock)
+
+	// 进行中的下单在收到停止信号后仍能完成，且请求未被中止
+	finished := make(chan error, 1)
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+		<-at.stopMonitorCh
+		time.Sleep(20 * time.Millisecond)
+		finished <- at.ctx().Err()
+	}()
+
+	at.Shutdown(ShutdownOptions{DrainTimeout: time.Second})
+	select {
+	case err := <-finished:
+		if err != nil {
+			t.Errorf("进行中的订单不应被中止: %v", err)
+		}
+	default:
+		t.Fatal("Shutdown 应等待进行中的订单完成")
+	}
+	if !at.draining.Load() || at.isRunning {
+		t.Error("Shutdown 后应处于停止状态")
+	}
+	if len(mock.cancelled) != 0 {
+		t.Errorf("默认应保留止盈止损单, cancelled=%v", mock.cancelled)
+	}
+	if err := at.runCycle(); err != nil || at.callCount != 0 {
+		t.Errorf("退出中不应开始新的决策周期 (callCount=%d, err=%v)", at.callCount, err)
+	}
+
+	// 重复调用无副作用
+	at.Shutdown(ShutdownOptions{})
+}
+
+func TestShutdownTimeoutAbortsRequests(t *testing.T) {
+	at := newShutdownTestTrader(&MockTrader{})
+
+	aborted := make(chan struct{})
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+		<-at.ctx().Done() // 卡住的交易所请求只能被 ctx 取消
+		close(aborted)
+	}()
+
+	start := time.Now()
+	at.Shutdown(ShutdownOptions{DrainTimeout: 20 * time.Millisecond})
+	select {
+	case <-aborted:
+	default:
+		t.Fatal("超时后应中止进行中的请求")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Shutdown 耗时过长: %v", elapsed)
+	}
+}
+
+func TestStopAndShutdownCloseStopSignalOnce(t *testing.T) {
+	// Stop 已通过检查并关闭停止信号后 Shutdown 才进入：不应再次关闭 stopMonitorCh
+	at := newShutdownTestTrader(&MockTrader{})
+	if !at.closeStopMonitor() {
+		t.Fatal("首次关闭应成功")
+	}
+	at.Shutdown(ShutdownOptions{DrainTimeout: time.Second})
+	if at.closeStopMonitor() {
+		t.Error("停止信号只能关闭一次")
+	}
+
+	// Shutdown 先关闭停止信号：Stop 不再重复停止
+	at = newShutdownTestTrader(&MockTrader{})
+	at.Shutdown(ShutdownOptions{DrainTimeout: time.Second})
+	at.draining.Store(false)
+	at.isRunning = true
+	at.Stop()
+}
+
+func TestShutdownCancelsProtectiveOrders(t *testing.T) {
+	mock := &cancelStopsRecordingTrader{MockTrader: MockTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long"},
+		{"symbol": "BTCUSDT", "side": "short"},
+		{"symbol": "ETHUSDT", "side": "long"},
+	}}}
+	at := newShutdownTestTrader(mock)
+
+	at.Shutdown(ShutdownOptions{DrainTimeout: time.Second, CancelProtectiveOrders: true})
+	if len(mock.cancelled) != 2 || mock.cancelled[0] != "BTCUSDT" || mock.cancelled[1] != "ETHUSDT" {
+		t.Errorf("应按币种撤销一次止盈止损单, got %v", mock.cancelled)
+	}
+}
@@ -0,0 +1,122 @@
+package trader
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"nofx/decision"
+)
+
+// Signal 外部交易信号（如 TradingView 告警），仓位和止损止盈可以只给出提示，由交易员按当前账户换算
+type Signal struct {
+	Symbol          string
+	Action          string  // open_long / open_short / close_long / close_short / partial_close
+	SizeUSD         float64 // 开仓金额（USDT）
+	SizePct         float64 // 或：按账户净值百分比开仓
+	Quantity        float64 // 或：按数量开仓（乘以参考价换算金额）
+	Leverage        int     // 0=按币种使用配置的杠杆
+	StopLoss        float64 // 止损价
+	TakeProfit      float64 // 止盈价
+	StopLossPct     float64 // 或：相对参考价的止损距离（%）
+	TakeProfitPct   float64 // 或：相对参考价的止盈距离（%）
+	ClosePercentage float64 // partial_close 的平仓比例（%）
+	Price           float64 // 参考价（告警触发价，0=当前市价）
+	Reason          string
+	Tag             string // 策略标签（为空时为 signal）
+}
+
+// ExecuteSignal 执行外部交易信号：与决策周期一样受风控暂停、人工暂停、对账异常和账户级风控约束，
+// 换算后的决策经过 AI 决策相同的校验，再走正常的执行流程（资金费率过滤、止损保护、决策日志等）
+func (at *AutoTrader) ExecuteSignal(sig Signal) (decision.Decision, error) {
+	d := decision.Decision{
+		Symbol:          strings.ToUpper(sig.Symbol),
+		Action:          sig.Action,
+		Leverage:        sig.Leverage,
+		StopLoss:        sig.StopLoss,
+		TakeProfit:      sig.TakeProfit,
+		ClosePercentage: sig.ClosePercentage,
+		Reasoning:       sig.Reason,
+		Tag:             sig.Tag,
+	}
+	if d.Tag == "" {
+		d.Tag = "signal"
+	}
+
+	if stopUntil := at.riskStopUntil(); time.Now().Before(stopUntil) {
+		return d, fmt.Errorf("风险控制暂停中，恢复时间: %s", stopUntil.Format(time.RFC3339))
+	}
+	if pause := at.GetPauseState(); pause.Paused {
+		return d, fmt.Errorf("交易已人工暂停: %s", pause.Reason)
+	}
+	if at.reconciliationHalted() {
+		return d, fmt.Errorf("启动对账发现异常，等待确认后恢复交易")
+	}
+
+	ctx, err := at.buildTradingContext()
+	if err != nil {
+		return d, fmt.Errorf("构建交易上下文失败: %w", err)
+	}
+	if reason, triggered := at.enforceRiskLimits(ctx.Account.TotalEquity); triggered {
+		return d, fmt.Errorf("风险控制触发: %s", reason)
+	}
+
+	if d.Action == "open_long" || d.Action == "open_short" {
+		if err := at.resolveSignalOpen(&d, sig, ctx.Account.TotalEquity); err != nil {
+			return d, err
+		}
+	}
+	if err := d.Validate(ctx.Account.TotalEquity, ctx.BTCETHLeverage, ctx.AltcoinLeverage); err != nil {
+		return d, fmt.Errorf("信号未通过校验: %w", err)
+	}
+
+	log.Info("📡 执行外部信号", "trader", at.name, "symbol", d.Symbol, "action", d.Action, "position_size_usd", d.PositionSizeUSD)
+	return d, at.ExecuteDecision(d)
+}
+
+// resolveSignalOpen 把开仓信号的仓位、杠杆和止损止盈提示换算为决策参数
+func (at *AutoTrader) resolveSignalOpen(d *decision.Decision, sig Signal, equity float64) error {
+	price := sig.Price
+	if price <= 0 && (sig.Quantity > 0 || sig.StopLossPct > 0 || sig.TakeProfitPct > 0) {
+		var err error
+		if price, err = at.trader.GetMarketPrice(at.ctx(), d.Symbol); err != nil {
+			return fmt.Errorf("获取 %s 参考价失败: %w", d.Symbol, err)
+		}
+	}
+
+	switch {
+	case sig.SizeUSD > 0:
+		d.PositionSizeUSD = sig.SizeUSD
+	case sig.SizePct > 0:
+		d.PositionSizeUSD = equity * sig.SizePct / 100
+	case sig.Quantity > 0:
+		d.PositionSizeUSD = sig.Quantity * price
+	default:
+		return fmt.Errorf("开仓信号缺少仓位（size_usd / size_pct / quantity）")
+	}
+
+	if d.Leverage <= 0 {
+		btcEthLeverage, altcoinLeverage := at.getLeverages()
+		d.Leverage = altcoinLeverage
+		if d.Symbol == "BTCUSDT" || d.Symbol == "ETHUSDT" {
+			d.Leverage = btcEthLeverage
+		}
+	}
+
+	long := d.Action == "open_long"
+	if d.StopLoss <= 0 && sig.StopLossPct > 0 {
+		if long {
+			d.StopLoss = price * (1 - sig.StopLossPct/100)
+		} else {
+			d.StopLoss = price * (1 + sig.StopLossPct/100)
+		}
+	}
+	if d.TakeProfit <= 0 && sig.TakeProfitPct > 0 {
+		if long {
+			d.TakeProfit = price * (1 + sig.TakeProfitPct/100)
+		} else {
+			d.TakeProfit = price * (1 - sig.TakeProfitPct/100)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package trader
+
+import (
+	"math"
+	"nofx/decision"
+	"testing"
+	"time"
+)
+
+func TestResolveSignalOpen(t *testing.T) {
+	at := &AutoTrader{trader: &MockTrader{}, config: AutoTraderConfig{BTCETHLeverage: 10, AltcoinLeverage: 5}}
+
+	// 按净值百分比开仓，止损止盈按当前市价（50000）换算，杠杆取 BTC 配置
+	d := decision.Decision{Symbol: "BTCUSDT", Action: "open_long"}
+	if err := at.resolveSignalOpen(&d, Signal{SizePct: 10, StopLossPct: 2, TakeProfitPct: 6}, 2000); err != nil {
+		t.Fatalf("换算失败: %v", err)
+	}
+	if d.PositionSizeUSD != 200 || d.Leverage != 10 || math.Abs(d.StopLoss-49000) > 1e-6 || math.Abs(d.TakeProfit-53000) > 1e-6 {
+		t.Errorf("换算结果错误: %+v", d)
+	}
+
+	// 空单按数量和告警价换算，显式止损优先
+	d = decision.Decision{Symbol: "SOLUSDT", Action: "open_short", StopLoss: 110}
+	if err := at.resolveSignalOpen(&d, Signal{Quantity: 3, Price: 100, StopLossPct: 5, TakeProfitPct: 10}, 2000); err != nil {
+		t.Fatalf("换算失败: %v", err)
+	}
+	if d.PositionSizeUSD != 300 || d.Leverage != 5 || d.StopLoss != 110 || math.Abs(d.TakeProfit-90) > 1e-6 {
+		t.Errorf("空单换算结果错误: %+v", d)
+	}
+
+	if err := at.resolveSignalOpen(&decision.Decision{Symbol: "BTCUSDT", Action: "open_long"}, Signal{}, 2000); err == nil {
+		t.Error("缺少仓位时应返回错误")
+	}
+}
+
+func TestExecuteSignalRespectsPauses(t *testing.T) {
+	at := &AutoTrader{trader: &MockTrader{}, stopUntil: time.Now().Add(time.Minute)}
+	if _, err := at.ExecuteSignal(Signal{Symbol: "BTCUSDT", Action: "close_long"}); err == nil {
+		t.Error("风控暂停期间应拒绝信号")
+	}
+	at.stopUntil = time.Time{}
+	at.pause = PauseState{Paused: true, Reason: "维护"}
+	if _, err := at.ExecuteSignal(Signal{Symbol: "BTCUSDT", Action: "close_long"}); err == nil {
+		t.Error("人工暂停期间应拒绝信号")
+	}
+}
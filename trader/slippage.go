@@ -0,0 +1,29 @@
+package trader
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSlippageExceeded 盘口价差已超过允许的最大滑点，拒绝下单
+var ErrSlippageExceeded = errors.New("盘口价差超过最大滑点")
+
+// slippageLimitPrice 按最优买卖价计算滑点保护的 IOC 限价：买入 = 卖一 × (1 + 滑点)，卖出 = 买一 × (1 - 滑点)
+// 价差本身已超过滑点上限时返回 ErrSlippageExceeded
+func slippageLimitPrice(bid, ask float64, buy bool, maxSlippageBps float64) (float64, error) {
+	if bid <= 0 || ask <= 0 || ask < bid {
+		return 0, fmt.Errorf("盘口价格无效: 买一 %v 卖一 %v", bid, ask)
+	}
+
+	mid := (bid + ask) / 2
+	spreadBps := (ask - bid) / mid * 10000
+	if spreadBps > maxSlippageBps {
+		return 0, fmt.Errorf("%w: 价差 %.1f bps（买一 %v 卖一 %v）> 上限 %.1f bps",
+			ErrSlippageExceeded, spreadBps, bid, ask, maxSlippageBps)
+	}
+
+	if buy {
+		return ask * (1 + maxSlippageBps/10000), nil
+	}
+	return bid * (1 - maxSlippageBps/10000), nil
+}
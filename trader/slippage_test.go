@@ -0,0 +1,84 @@
+package trader
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/adshao/go-binance/v2/futures"
+)
+
+func TestSlippageLimitPrice(t *testing.T) {
+	// 价差 (100.02-100)/100.01 ≈ 2 bps，上限 10 bps
+	buy, err := slippageLimitPrice(100, 100.02, true, 10)
+	if err != nil || math.Abs(buy-100.02*1.001) > 1e-9 {
+		t.Errorf("买入限价 = %v, %v; want 卖一 × 1.001", buy, err)
+	}
+	sell, err := slippageLimitPrice(100, 100.02, false, 10)
+	if err != nil || math.Abs(sell-100*0.999) > 1e-9 {
+		t.Errorf("卖出限价 = %v, %v; want 买一 × 0.999", sell, err)
+	}
+
+	// 价差约 20 bps 超过 10 bps 上限
+	if _, err := slippageLimitPrice(100, 100.2, true, 10); !errors.Is(err, ErrSlippageExceeded) {
+		t.Errorf("价差超过上限时 error = %v, want ErrSlippageExceeded", err)
+	}
+	if _, err := slippageLimitPrice(0, 100, true, 10); err == nil {
+		t.Error("盘口价格无效时应返回错误")
+	}
+}
+
+// newSlippageTestTrader 返回设置了滑点上限的币安交易器，ask 为卖一价，orders 收集下单参数
+func newSlippageTestTrader(t *testing.T, ask string, status futures.OrderStatusType, orders *[]url.Values) *FuturesTrader {
+	t.Helper()
+	trader := newFakeBinanceFuturesTrader(t, ask, "market_only", 60, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/fapi/v1/order" {
+			json.NewEncoder(w).Encode(map[string]interface{}{})
+			return
+		}
+		r.ParseForm()
+		*orders = append(*orders, r.Form)
+		json.NewEncoder(w).Encode(&futures.CreateOrderResponse{OrderID: 1, Symbol: "BTCUSDT", Status: status, ExecutedQuantity: "0"})
+	})
+	trader.SetMaxSlippageBps(10)
+	return trader
+}
+
+func TestPlaceMarketOrderSlippageProtection(t *testing.T) {
+	var orders []url.Values
+	trader := newSlippageTestTrader(t, "50001.00", futures.OrderStatusTypeNew, &orders)
+
+	if _, err := trader.placeMarketOrder(context.Background(), "BTCUSDT", futures.SideTypeBuy, futures.PositionSideTypeLong, "0.010"); err != nil {
+		t.Fatalf("placeMarketOrder() error = %v", err)
+	}
+	if len(orders) != 1 {
+		t.Fatalf("下单次数 = %d, want 1", len(orders))
+	}
+	// 50001 × 1.001 = 50051.001 → 按 tickSize 0.01 格式化
+	if o := orders[0]; o.Get("type") != "LIMIT" || o.Get("timeInForce") != "IOC" || o.Get("price") != "50051.00" {
+		t.Errorf("下单参数 = %v, want LIMIT IOC @ 50051.00", o)
+	}
+}
+
+func TestPlaceMarketOrderSlippageRejected(t *testing.T) {
+	var orders []url.Values
+
+	// 价差约 40 bps 超过 10 bps 上限：不下单
+	wide := newSlippageTestTrader(t, "50200.00", futures.OrderStatusTypeNew, &orders)
+	if _, err := wide.placeMarketOrder(context.Background(), "BTCUSDT", futures.SideTypeBuy, futures.PositionSideTypeLong, "0.010"); !errors.Is(err, ErrSlippageExceeded) {
+		t.Errorf("placeMarketOrder() error = %v, want ErrSlippageExceeded", err)
+	}
+	if len(orders) != 0 {
+		t.Errorf("价差超过上限时不应下单, orders = %v", orders)
+	}
+
+	// IOC 未成交即过期：视为下单失败
+	expired := newSlippageTestTrader(t, "50001.00", futures.OrderStatusTypeExpired, &orders)
+	if _, err := expired.placeMarketOrder(context.Background(), "BTCUSDT", futures.SideTypeSell, futures.PositionSideTypeShort, "0.010"); !errors.Is(err, ErrSlippageExceeded) {
+		t.Errorf("IOC 未成交时 error = %v, want ErrSlippageExceeded", err)
+	}
+}
@@ -0,0 +1,89 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/eventbus"
+)
+
+// DecisionExecutor 执行单条交易决策（*AutoTrader 实现了该接口）
+type DecisionExecutor interface {
+	ExecuteDecision(d decision.Decision) error
+}
+
+// SpreadLegs 跨交易所价差套利的两条腿（实现 market.SpreadExecutor）：
+// 按场所名称找到对应的交易员，在价格较低的场所开多、价格较高的场所开空，名义价值相同
+type SpreadLegs struct {
+	Traders    map[string]DecisionExecutor // 场所名称（与 market.SpreadVenue.Name 一致）-> 交易员
+	SizeUSD    float64                     // 每条腿的名义价值（USDT）
+	Leverage   int                         // 每条腿的杠杆（默认 1）
+	ProtectPct float64                     // 每条腿的保护性止损/止盈距离（%，默认 10；对冲持仓只防极端行情）
+}
+
+// OpenSpread 先开多头腿再开空头腿，空头腿失败时平掉多头腿，不留下单边敞口
+func (s *SpreadLegs) OpenSpread(opp eventbus.SpreadOpportunity) error {
+	buy, sell, err := s.legs(opp)
+	if err != nil {
+		return err
+	}
+	if s.SizeUSD <= 0 {
+		return fmt.Errorf("价差套利每条腿的名义价值必须大于 0")
+	}
+	leverage := s.Leverage
+	if leverage <= 0 {
+		leverage = 1
+	}
+	protect := s.ProtectPct
+	if protect <= 0 {
+		protect = 10
+	}
+	protect /= 100
+
+	reason := fmt.Sprintf("跨交易所价差套利：%s %.4f / %s %.4f，扣除成本后 %.2f bps",
+		opp.BuyVenue, opp.BuyPrice, opp.SellVenue, opp.SellPrice, opp.NetBps)
+	longLeg := decision.Decision{Symbol: opp.Symbol, Action: "open_long", Leverage: leverage, PositionSizeUSD: s.SizeUSD,
+		StopLoss: opp.BuyPrice * (1 - protect), TakeProfit: opp.BuyPrice * (1 + protect), Reasoning: reason, Tag: "spread_arb"}
+	shortLeg := decision.Decision{Symbol: opp.Symbol, Action: "open_short", Leverage: leverage, PositionSizeUSD: s.SizeUSD,
+		StopLoss: opp.SellPrice * (1 + protect), TakeProfit: opp.SellPrice * (1 - protect), Reasoning: reason, Tag: "spread_arb"}
+
+	if err := buy.ExecuteDecision(longLeg); err != nil {
+		return fmt.Errorf("%s 开多失败: %w", opp.BuyVenue, err)
+	}
+	if err := sell.ExecuteDecision(shortLeg); err != nil {
+		if uerr := buy.ExecuteDecision(decision.Decision{Symbol: opp.Symbol, Action: "close_long", Reasoning: "价差套利空头腿失败，平掉多头腿"}); uerr != nil {
+			log.Error("🚨 价差套利空头腿失败且多头腿平仓失败，存在单边敞口", "symbol", opp.Symbol, "venue", opp.BuyVenue, "error", uerr)
+			return fmt.Errorf("%s 开空失败: %w；平掉 %s 多头腿也失败: %v", opp.SellVenue, err, opp.BuyVenue, uerr)
+		}
+		return fmt.Errorf("%s 开空失败，已平掉多头腿: %w", opp.SellVenue, err)
+	}
+	return nil
+}
+
+// CloseSpread 平掉两条腿（任一腿失败时返回错误，下一轮检查会重试）
+func (s *SpreadLegs) CloseSpread(opp eventbus.SpreadOpportunity) error {
+	buy, sell, err := s.legs(opp)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	if err := sell.ExecuteDecision(decision.Decision{Symbol: opp.Symbol, Action: "close_short", Reasoning: "价差收敛，平掉空头腿"}); err != nil {
+		errs = append(errs, fmt.Errorf("%s 平空失败: %w", opp.SellVenue, err))
+	}
+	if err := buy.ExecuteDecision(decision.Decision{Symbol: opp.Symbol, Action: "close_long", Reasoning: "价差收敛，平掉多头腿"}); err != nil {
+		errs = append(errs, fmt.Errorf("%s 平多失败: %w", opp.BuyVenue, err))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%s 价差套利平仓失败: %v", opp.Symbol, errs)
+	}
+	return nil
+}
+
+// legs 按场所名称找到两条腿的交易员
+func (s *SpreadLegs) legs(opp eventbus.SpreadOpportunity) (buy, sell DecisionExecutor, err error) {
+	buy, okBuy := s.Traders[opp.BuyVenue]
+	sell, okSell := s.Traders[opp.SellVenue]
+	if !okBuy || !okSell {
+		return nil, nil, fmt.Errorf("价差套利场所 %s / %s 未配置交易员", opp.BuyVenue, opp.SellVenue)
+	}
+	return buy, sell, nil
+}
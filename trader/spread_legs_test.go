@@ -0,0 +1,61 @@
+package trader
+
+import (
+	"errors"
+	"nofx/decision"
+	"nofx/eventbus"
+	"testing"
+)
+
+// legRecorder 记录收到的决策，failOn 中的动作返回错误
+type legRecorder struct {
+	executed []decision.Decision
+	failOn   string
+}
+
+func (r *legRecorder) ExecuteDecision(d decision.Decision) error {
+	r.executed = append(r.executed, d)
+	if d.Action == r.failOn {
+		return errors.New("exchange error")
+	}
+	return nil
+}
+
+func TestSpreadLegsOpenAndClose(t *testing.T) {
+	binance, okx := &legRecorder{}, &legRecorder{}
+	legs := &SpreadLegs{Traders: map[string]DecisionExecutor{"binance": binance, "okx": okx}, SizeUSD: 500}
+	opp := eventbus.SpreadOpportunity{Symbol: "BTCUSDT", BuyVenue: "binance", SellVenue: "okx", BuyPrice: 100, SellPrice: 101}
+
+	if err := legs.OpenSpread(opp); err != nil {
+		t.Fatal(err)
+	}
+	if got := binance.executed[0]; got.Action != "open_long" || got.PositionSizeUSD != 500 || got.StopLoss != 90 || got.Leverage != 1 {
+		t.Errorf("多头腿错误: %+v", got)
+	}
+	if got := okx.executed[0]; got.Action != "open_short" || got.StopLoss <= 101 || got.TakeProfit >= 101 {
+		t.Errorf("空头腿错误: %+v", got)
+	}
+
+	if err := legs.CloseSpread(opp); err != nil {
+		t.Fatal(err)
+	}
+	if binance.executed[1].Action != "close_long" || okx.executed[1].Action != "close_short" {
+		t.Errorf("平仓错误: %+v / %+v", binance.executed, okx.executed)
+	}
+}
+
+func TestSpreadLegsUnwindsOnShortFailure(t *testing.T) {
+	binance, okx := &legRecorder{}, &legRecorder{failOn: "open_short"}
+	legs := &SpreadLegs{Traders: map[string]DecisionExecutor{"binance": binance, "okx": okx}, SizeUSD: 500}
+	opp := eventbus.SpreadOpportunity{Symbol: "BTCUSDT", BuyVenue: "binance", SellVenue: "okx", BuyPrice: 100, SellPrice: 101}
+
+	if err := legs.OpenSpread(opp); err == nil {
+		t.Fatal("空头腿失败应返回错误")
+	}
+	if len(binance.executed) != 2 || binance.executed[1].Action != "close_long" {
+		t.Errorf("空头腿失败后应平掉多头腿: %+v", binance.executed)
+	}
+	if err := legs.OpenSpread(eventbus.SpreadOpportunity{BuyVenue: "bybit", SellVenue: "okx"}); err == nil {
+		t.Error("未配置的场所应返回错误")
+	}
+}
@@ -0,0 +1,13 @@
+package trader
+
+import (
+	"nofx/metrics"
+	"time"
+)
+
+// observeStage 记录决策到下单链路的阶段耗时（nofx_stage_latency_seconds），超出 NOFX_LATENCY_BUDGETS 预算时告警
+func observeStage(name, stage string, elapsed time.Duration) {
+	if metrics.ObserveStage(stage, elapsed) {
+		log.Info("🐢 阶段耗时超出预算", "trader", name, "stage", stage, "elapsed", elapsed.Round(time.Millisecond), "budget", metrics.LatencyBudget(stage))
+	}
+}
@@ -0,0 +1,417 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"nofx/decision"
+	"nofx/market"
+	"nofx/store"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 止损规则动作
+const (
+	StopRuleBreakeven = "breakeven" // 止损移至开仓价
+	StopRuleATR       = "atr"       // 止损移至现价 N 倍 ATR 之外
+	StopRuleSwing     = "swing"     // 止损移至最近 N 根K线的摆动低点（多单）/ 高点（空单）
+)
+
+// 止损规则默认参数
+const (
+	defaultStopRuleInterval      = "15m"
+	defaultStopRuleCheckInterval = time.Minute
+	defaultStopRuleATRMultiple   = 2.0
+	defaultStopRuleSwingLookback = 10
+	stopRuleATRPeriod            = 14
+	stopRuleMinMovePct           = 0.05 // 新止损至少比当前止损收紧标记价格的 0.05%，避免频繁改单
+)
+
+// StopLossAmender 能直接修改止损单的交易器（不经过撤单再挂单，避免两次请求之间持仓没有止损）
+type StopLossAmender interface {
+	// AmendStopLoss 将持仓 positionSide 方向的止损价改为 stopPrice
+	AmendStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error
+}
+
+// StopRule 一条止损收紧规则：浮盈达到 TriggerR 倍风险或价格有利移动 TriggerPct% 时触发
+type StopRule struct {
+	TriggerR      float64 // 触发所需浮盈（风险倍数，1R = 开仓价到初始止损的距离；0=不按 R 触发）
+	TriggerPct    float64 // 触发所需价格有利变动百分比（0=不按百分比触发）
+	Action        string  // breakeven / atr / swing
+	ATRMultiple   float64 // atr：止损距标记价格的 ATR 倍数
+	SwingLookback int     // swing：取最近多少根已收盘K线的极值
+}
+
+// StopRules 持仓止损收紧规则（已触发的规则每次检查都会重新计算，止损只收紧不放宽）
+type StopRules struct {
+	Rules         []StopRule
+	Interval      string        // 计算 ATR/摆动点使用的K线周期（默认 15m）
+	CheckInterval time.Duration // 检查间隔（默认 1m）
+}
+
+// ParseStopRules 解析止损规则，格式 "1R:breakeven,2R:atr:1.5,3%:swing:10"（触发条件:动作[:参数]）
+func ParseStopRules(raw string) ([]StopRule, error) {
+	var rules []StopRule
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fields := strings.Split(part, ":")
+		if len(fields) < 2 || len(fields) > 3 {
+			return nil, fmt.Errorf("规则 %q 格式错误（应为 1R:breakeven、2R:atr:1.5 或 3%%:swing:10）", part)
+		}
+
+		var rule StopRule
+		trigger := strings.ToUpper(strings.TrimSpace(fields[0]))
+		switch {
+		case strings.HasSuffix(trigger, "R"):
+			v, err := strconv.ParseFloat(strings.TrimSuffix(trigger, "R"), 64)
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("规则 %q 的触发倍数无效", part)
+			}
+			rule.TriggerR = v
+		case strings.HasSuffix(trigger, "%"):
+			v, err := strconv.ParseFloat(strings.TrimSuffix(trigger, "%"), 64)
+			if err != nil || v <= 0 {
+				return nil, fmt.Errorf("规则 %q 的触发百分比无效", part)
+			}
+			rule.TriggerPct = v
+		default:
+			return nil, fmt.Errorf("规则 %q 的触发条件应以 R 或 %% 结尾", part)
+		}
+
+		rule.Action = strings.ToLower(strings.TrimSpace(fields[1]))
+		param := ""
+		if len(fields) == 3 {
+			param = strings.TrimSpace(fields[2])
+		}
+		switch rule.Action {
+		case StopRuleBreakeven:
+			if param != "" {
+				return nil, fmt.Errorf("规则 %q: breakeven 不接受参数", part)
+			}
+		case StopRuleATR:
+			rule.ATRMultiple = defaultStopRuleATRMultiple
+			if param != "" {
+				v, err := strconv.ParseFloat(param, 64)
+				if err != nil || v <= 0 {
+					return nil, fmt.Errorf("规则 %q 的 ATR 倍数无效", part)
+				}
+				rule.ATRMultiple = v
+			}
+		case StopRuleSwing:
+			rule.SwingLookback = defaultStopRuleSwingLookback
+			if param != "" {
+				v, err := strconv.Atoi(param)
+				if err != nil || v <= 0 {
+					return nil, fmt.Errorf("规则 %q 的K线数量无效", part)
+				}
+				rule.SwingLookback = v
+			}
+		default:
+			return nil, fmt.Errorf("规则 %q 的动作无效（支持 breakeven、atr、swing）", part)
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("未配置任何规则")
+	}
+	return rules, nil
+}
+
+// StopRulesFromEnv 读取止损收紧规则（NOFX_STOP_RULES 未设置时返回 nil）
+func StopRulesFromEnv() (*StopRules, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_STOP_RULES"))
+	if raw == "" {
+		return nil, nil
+	}
+	rules, err := ParseStopRules(raw)
+	if err != nil {
+		return nil, fmt.Errorf("NOFX_STOP_RULES 配置错误: %w", err)
+	}
+	cfg := &StopRules{Rules: rules, Interval: defaultStopRuleInterval, CheckInterval: defaultStopRuleCheckInterval}
+	if v := strings.TrimSpace(os.Getenv("NOFX_STOP_RULES_INTERVAL")); v != "" {
+		if _, ok := market.TimeframeDuration(v); !ok {
+			return nil, fmt.Errorf("NOFX_STOP_RULES_INTERVAL=%q 不是有效的K线周期（如 5m、15m、1h）", v)
+		}
+		cfg.Interval = v
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_STOP_RULES_CHECK_INTERVAL")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("NOFX_STOP_RULES_CHECK_INTERVAL=%q 无效（如 30s、1m）", v)
+		}
+		cfg.CheckInterval = d
+	}
+	return cfg, nil
+}
+
+// stopRuleKlines 获取计算 ATR/摆动点的K线（测试中可替换）
+var stopRuleKlines = func(symbol, interval string, limit int) ([]market.Kline, error) {
+	snapshot, err := market.GetMultiTimeframe(symbol, []string{interval}, limit)
+	if err != nil {
+		return nil, err
+	}
+	if err := snapshot.Err(interval); err != nil {
+		return nil, err
+	}
+	return snapshot.Klines(interval), nil
+}
+
+// stopRuleState 规则引擎跟踪的单个持仓（首次看到持仓时按交易所止损计算 1R）
+type stopRuleState struct {
+	entry    float64
+	risk     float64 // 1R 对应的价格距离（未找到初始止损时为 0，只按百分比规则触发）
+	quantity float64
+	stop     float64 // 规则引擎最近设置的止损价
+}
+
+// startStopRuleMonitor 启动止损收紧规则监控
+func (at *AutoTrader) startStopRuleMonitor() {
+	cfg := at.config.StopRules
+	if cfg == nil || len(cfg.Rules) == 0 {
+		return
+	}
+	interval := cfg.CheckInterval
+	if interval <= 0 {
+		interval = defaultStopRuleCheckInterval
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		log.Info("🧷 启动止损收紧规则监控", "trader", at.name, "rule_count", len(cfg.Rules), "interval", interval)
+
+		states := make(map[string]*stopRuleState)
+		for {
+			select {
+			case <-ticker.C:
+				if err := at.checkStopRules(cfg, states); err != nil {
+					log.Warn("⚠️ 止损规则检查失败", "error", err)
+				}
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止止损收紧规则监控")
+				return
+			}
+		}
+	}()
+}
+
+// checkStopRules 对每个持仓评估已触发的规则，取最紧的候选止损并修改交易所止损单
+func (at *AutoTrader) checkStopRules(cfg *StopRules, states map[string]*stopRuleState) error {
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	orders, err := at.trader.GetOpenOrders(at.ctx(), "")
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", err)
+	}
+	stops := exchangeStops(orders)
+
+	seen := make(map[string]bool, len(positions))
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		entry := floatValue(pos["entryPrice"])
+		markPrice := floatValue(pos["markPrice"])
+		quantity := math.Abs(floatValue(pos["positionAmt"]))
+		if quantity == 0 || entry <= 0 || markPrice <= 0 {
+			continue
+		}
+		key := symbol + "_" + side
+		seen[key] = true
+		direction := 1.0
+		if side == "short" {
+			direction = -1.0
+		}
+
+		current := stops[symbol+"_"+strings.ToUpper(side)]
+		s := states[key]
+		if s == nil || quantity > s.quantity*(1+scaleOutQtyTolerance) || entry != s.entry {
+			// 新持仓或加仓：按当前止损重新计算 1R
+			s = &stopRuleState{entry: entry, quantity: quantity}
+			if risk := (entry - current) * direction; current > 0 && risk > 0 {
+				s.risk = risk
+			}
+			states[key] = s
+		}
+		s.quantity = quantity
+		if s.stop > 0 && (current == 0 || (s.stop-current)*direction > 0) {
+			current = s.stop // 交易所不返回触发价（如 Hyperliquid）或查询滞后时以最近设置的止损为准
+		}
+
+		target, reason := at.stopRuleTarget(cfg, symbol, side, s, markPrice)
+		if target <= 0 || (target-markPrice)*direction >= 0 {
+			continue // 未触发，或候选止损已越过标记价格
+		}
+		if current > 0 && (target-current)*direction < markPrice*stopRuleMinMovePct/100 {
+			continue // 不比当前止损更紧
+		}
+		if at.replaceStopLoss(symbol, side, quantity, target) {
+			s.stop = target
+			log.Info("🧷 止损收紧", "symbol", symbol, "side", side, "reason", reason, "from", at.config.NumberFormat.Price(current), "to", at.config.NumberFormat.Price(target))
+		}
+	}
+	for key := range states {
+		if !seen[key] {
+			delete(states, key)
+		}
+	}
+	return nil
+}
+
+// stopRuleTarget 计算已触发规则中最紧的止损价（未触发任何规则时返回 0）
+func (at *AutoTrader) stopRuleTarget(cfg *StopRules, symbol, side string, s *stopRuleState, markPrice float64) (float64, string) {
+	direction := 1.0
+	if side == "short" {
+		direction = -1.0
+	}
+	move := (markPrice - s.entry) * direction
+	movePct := move / s.entry * 100
+
+	var klines []market.Kline
+	klinesLoaded := false
+	best, reason := 0.0, ""
+	for _, rule := range cfg.Rules {
+		triggered := (rule.TriggerR > 0 && s.risk > 0 && move >= rule.TriggerR*s.risk) ||
+			(rule.TriggerPct > 0 && movePct >= rule.TriggerPct)
+		if !triggered {
+			continue
+		}
+
+		if rule.Action != StopRuleBreakeven && !klinesLoaded {
+			klinesLoaded = true
+			var err error
+			if klines, err = stopRuleKlines(symbol, cfg.Interval, 100); err != nil {
+				log.Warn("⚠️ 止损规则: 获取K线失败", "symbol", symbol, "interval", cfg.Interval, "error", err)
+			}
+		}
+
+		candidate := 0.0
+		switch rule.Action {
+		case StopRuleBreakeven:
+			candidate = s.entry
+		case StopRuleATR:
+			if atr := market.ATR(klines, stopRuleATRPeriod); atr > 0 {
+				candidate = markPrice - direction*rule.ATRMultiple*atr
+			}
+		case StopRuleSwing:
+			candidate = swingExtreme(klines, rule.SwingLookback, side)
+		}
+		if candidate > 0 && (best == 0 || (candidate-best)*direction > 0) {
+			best, reason = candidate, ruleLabel(rule)
+		}
+	}
+	return best, reason
+}
+
+// swingExtreme 最近 lookback 根已收盘K线的最低价（多单）或最高价（空单），不包含正在形成的最后一根
+func swingExtreme(klines []market.Kline, lookback int, side string) float64 {
+	if len(klines) < 2 {
+		return 0
+	}
+	closed := klines[:len(klines)-1]
+	if len(closed) > lookback {
+		closed = closed[len(closed)-lookback:]
+	}
+	extreme := 0.0
+	for _, k := range closed {
+		if side == "short" {
+			extreme = math.Max(extreme, k.High)
+		} else if extreme == 0 || k.Low < extreme {
+			extreme = k.Low
+		}
+	}
+	return extreme
+}
+
+// ruleLabel 规则的简短描述（日志用）
+func ruleLabel(rule StopRule) string {
+	trigger := fmt.Sprintf("%gR", rule.TriggerR)
+	if rule.TriggerR == 0 {
+		trigger = fmt.Sprintf("%g%%", rule.TriggerPct)
+	}
+	switch rule.Action {
+	case StopRuleATR:
+		return fmt.Sprintf("%s → %g×ATR", trigger, rule.ATRMultiple)
+	case StopRuleSwing:
+		return fmt.Sprintf("%s → %d根K线摆动点", trigger, rule.SwingLookback)
+	}
+	return trigger + " → 保本"
+}
+
+// exchangeStops 从挂单中提取各持仓方向的止损价 (symbol_SIDE -> 止损价)；单向持仓模式（BOTH）按下单方向推断
+func exchangeStops(orders []decision.OpenOrderInfo) map[string]float64 {
+	stops := make(map[string]float64)
+	for _, order := range orders {
+		if order.Type != "STOP_MARKET" && order.Type != "STOP" {
+			continue
+		}
+		positionSide := strings.ToUpper(order.PositionSide)
+		if positionSide == "BOTH" || positionSide == "" {
+			positionSide = "LONG"
+			if strings.EqualFold(order.Side, "BUY") {
+				positionSide = "SHORT"
+			}
+		}
+		stops[order.Symbol+"_"+positionSide] = order.StopPrice
+	}
+	return stops
+}
+
+// replaceStopLoss 将持仓止损改为 stopPrice：交易器支持改单时直接修改，否则撤销该币种止损单后重新设置
+// （撤销作用于该币种的全部方向，需要恢复另一方向的止损）；新止损设置失败时改用本地模拟止损
+func (at *AutoTrader) replaceStopLoss(symbol, side string, quantity, stopPrice float64) bool {
+	positionSide := strings.ToUpper(side)
+	if at.stopAmender != nil {
+		err := at.stopAmender.AmendStopLoss(at.ctx(), symbol, positionSide, quantity, stopPrice)
+		if err == nil {
+			at.recordStopAmend(symbol, side, quantity, stopPrice)
+			return true
+		}
+		log.Warn("⚠️ 修改止损失败，改为撤单重挂", "symbol", symbol, "side", side, "error", err)
+	}
+
+	orders, err := at.trader.GetOpenOrders(at.ctx(), symbol)
+	if err != nil {
+		log.Warn("⚠️ 移动止损: 获取挂单失败", "symbol", symbol, "error", err)
+		return false
+	}
+	if err := at.trader.CancelStopLossOrders(at.ctx(), symbol); err != nil {
+		log.Warn("⚠️ 移动止损: 撤销止损单失败", "symbol", symbol, "error", err)
+		return false
+	}
+	for _, order := range orders {
+		if (order.Type == "STOP_MARKET" || order.Type == "STOP") && order.Symbol == symbol &&
+			order.PositionSide != "" && !strings.EqualFold(order.PositionSide, "BOTH") && !strings.EqualFold(order.PositionSide, positionSide) {
+			if err := at.trader.SetStopLoss(at.ctx(), symbol, strings.ToUpper(order.PositionSide), order.Quantity, order.StopPrice); err != nil {
+				log.Warn("⚠️ 移动止损: 恢复止损失败", "symbol", symbol, "position_side", order.PositionSide, "error", err)
+			}
+		}
+	}
+	if err := at.trader.SetStopLoss(at.ctx(), symbol, positionSide, quantity, stopPrice); err != nil {
+		at.setSyntheticStop(symbol, side, stopPrice)
+		at.notify(AlertSeverityCritical, "移动止损失败",
+			"%s %s 止损移至 %s 失败，已改用本地模拟止损: %v", symbol, side, at.config.NumberFormat.Price(stopPrice), err)
+	}
+	return true
+}
+
+// recordStopAmend 改单绕过了事件日志装饰器，单独写入止损设置事件
+func (at *AutoTrader) recordStopAmend(symbol, side string, quantity, stopPrice float64) {
+	if at.journal == nil {
+		return
+	}
+	if _, err := at.journal.Record(at.id, store.EventProtection, symbol, side, store.Protection{Kind: "stop_loss", Quantity: quantity, Price: stopPrice}); err != nil {
+		log.Warn("⚠️ 写入止损修改事件失败", "symbol", symbol, "side", side, "error", err)
+	}
+}
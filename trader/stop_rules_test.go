@@ -0,0 +1,146 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"nofx/decision"
+	"nofx/market"
+	"testing"
+	"time"
+)
+
+// amendRecordingTrader 支持直接改单的 MockTrader
+type amendRecordingTrader struct {
+	stopRecordingTrader
+	amended []string
+}
+
+func (m *amendRecordingTrader) AmendStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	m.amended = append(m.amended, fmt.Sprintf("%s_%s@%.1f", symbol, positionSide, stopPrice))
+	return nil
+}
+
+func TestParseStopRules(t *testing.T) {
+	rules, err := ParseStopRules("1R:breakeven, 2r:atr:1.5, 3%:swing")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	want := []StopRule{
+		{TriggerR: 1, Action: StopRuleBreakeven},
+		{TriggerR: 2, Action: StopRuleATR, ATRMultiple: 1.5},
+		{TriggerPct: 3, Action: StopRuleSwing, SwingLookback: defaultStopRuleSwingLookback},
+	}
+	if fmt.Sprint(rules) != fmt.Sprint(want) {
+		t.Errorf("rules = %+v, want %+v", rules, want)
+	}
+
+	for _, raw := range []string{"", "1R", "1:breakeven", "1R:trail", "1R:breakeven:1", "2R:atr:-1", "3%:swing:0"} {
+		if _, err := ParseStopRules(raw); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
+
+func TestStopRulesFromEnv(t *testing.T) {
+	if cfg, err := StopRulesFromEnv(); err != nil || cfg != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", cfg, err)
+	}
+
+	t.Setenv("NOFX_STOP_RULES", "1R:breakeven")
+	t.Setenv("NOFX_STOP_RULES_CHECK_INTERVAL", "30s")
+	cfg, err := StopRulesFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if cfg.Interval != defaultStopRuleInterval || cfg.CheckInterval != 30*time.Second {
+		t.Errorf("配置解析错误: %+v", cfg)
+	}
+
+	t.Setenv("NOFX_STOP_RULES_INTERVAL", "7x")
+	if _, err := StopRulesFromEnv(); err == nil {
+		t.Error("无效的 NOFX_STOP_RULES_INTERVAL 应返回错误")
+	}
+}
+
+func TestCheckStopRulesBreakevenThenATR(t *testing.T) {
+	orig := stopRuleKlines
+	defer func() { stopRuleKlines = orig }()
+	stopRuleKlines = func(symbol, interval string, limit int) ([]market.Kline, error) {
+		klines := make([]market.Kline, 30)
+		for i := range klines {
+			klines[i] = market.Kline{High: 111, Low: 109, Close: 110} // ATR = 2
+		}
+		return klines, nil
+	}
+
+	mock := &stopRecordingTrader{flattenRecordingTrader: flattenRecordingTrader{
+		openOrders: []decision.OpenOrderInfo{{Symbol: "BTCUSDT", Type: "STOP_MARKET", PositionSide: "LONG", StopPrice: 95}},
+	}}
+	setMark := func(price float64) {
+		mock.positions = []map[string]interface{}{
+			{"symbol": "BTCUSDT", "side": "long", "positionAmt": 1.0, "entryPrice": 100.0, "markPrice": price},
+		}
+	}
+	at := &AutoTrader{trader: mock}
+	rules, _ := ParseStopRules("1R:breakeven,2R:atr:1")
+	cfg := &StopRules{Rules: rules, Interval: "15m"}
+	states := make(map[string]*stopRuleState)
+
+	setMark(104) // 0.8R
+	if err := at.checkStopRules(cfg, states); err != nil {
+		t.Fatal(err)
+	}
+	if len(mock.stops) != 0 {
+		t.Fatalf("未达到 1R 不应调整止损: %v", mock.stops)
+	}
+
+	setMark(106) // 1.2R → 保本
+	at.checkStopRules(cfg, states)
+	setMark(107) // 仍为保本，不重复改单
+	at.checkStopRules(cfg, states)
+	if fmt.Sprint(mock.stops) != "[BTCUSDT_LONG@100.0]" {
+		t.Fatalf("达到 1R 应移至保本: %v", mock.stops)
+	}
+
+	setMark(111) // 2.2R → 现价 - 1×ATR
+	at.checkStopRules(cfg, states)
+	setMark(110.5) // 回落时止损不放宽
+	at.checkStopRules(cfg, states)
+	if fmt.Sprint(mock.stops) != "[BTCUSDT_LONG@100.0 BTCUSDT_LONG@109.0]" {
+		t.Errorf("达到 2R 应按 ATR 收紧且只收紧不放宽: %v", mock.stops)
+	}
+}
+
+func TestCheckStopRulesPrefersAmend(t *testing.T) {
+	mock := &amendRecordingTrader{stopRecordingTrader: stopRecordingTrader{flattenRecordingTrader: flattenRecordingTrader{
+		MockTrader: MockTrader{positions: []map[string]interface{}{
+			{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0, "entryPrice": 2000.0, "markPrice": 1930.0},
+		}},
+	}}}
+	at := &AutoTrader{trader: mock, stopAmender: mock}
+	rules, _ := ParseStopRules("3%:breakeven")
+
+	at.checkStopRules(&StopRules{Rules: rules}, make(map[string]*stopRuleState))
+	if fmt.Sprint(mock.amended) != "[ETHUSDT_SHORT@2000.0]" || len(mock.cancelled) != 0 || len(mock.stops) != 0 {
+		t.Errorf("应直接改单而不撤单重挂: amended=%v cancelled=%v stops=%v", mock.amended, mock.cancelled, mock.stops)
+	}
+}
+
+func TestSwingExtremeAndExchangeStops(t *testing.T) {
+	klines := []market.Kline{{High: 105, Low: 95}, {High: 103, Low: 97}, {High: 104, Low: 98}, {High: 120, Low: 80}}
+	if got := swingExtreme(klines, 2, "long"); got != 97 {
+		t.Errorf("多单摆动低点 = %v, want 97（不含未收盘K线）", got)
+	}
+	if got := swingExtreme(klines, 3, "short"); got != 105 {
+		t.Errorf("空单摆动高点 = %v, want 105", got)
+	}
+
+	stops := exchangeStops([]decision.OpenOrderInfo{
+		{Symbol: "BTCUSDT", Type: "STOP_MARKET", PositionSide: "BOTH", Side: "BUY", StopPrice: 62000},
+		{Symbol: "ETHUSDT", Type: "STOP_MARKET", PositionSide: "LONG", Side: "SELL", StopPrice: 2900},
+		{Symbol: "ETHUSDT", Type: "TAKE_PROFIT_MARKET", PositionSide: "LONG", StopPrice: 3300},
+	})
+	if len(stops) != 2 || stops["BTCUSDT_SHORT"] != 62000 || stops["ETHUSDT_LONG"] != 2900 {
+		t.Errorf("exchangeStops = %v", stops)
+	}
+}
@@ -0,0 +1,64 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/logger"
+	"time"
+)
+
+// ExecuteDecision 执行外部策略（strategy 包）给出的决策
+// 复用 AI 决策的执行流程（资金费率过滤、开仓确认、止损保护等），并写入决策日志
+// 与交易周期并发执行时持有币种锁，同一币种的操作不会交错
+func (at *AutoTrader) ExecuteDecision(d decision.Decision) error {
+	actionRecord := logger.DecisionAction{
+		Action:    d.Action,
+		Symbol:    d.Symbol,
+		Leverage:  d.Leverage,
+		Timestamp: time.Now(),
+	}
+	record := &logger.DecisionRecord{
+		Exchange:     at.config.Exchange,
+		ExecutionLog: []string{},
+		Success:      true,
+	}
+
+	unlock := at.lockSymbol(d.Symbol)
+	at.recordDecision(&d)
+	err := at.executeDecisionWithRecord(&d, &actionRecord)
+	unlock()
+	if err != nil {
+		at.recordDecisionError(&d, err)
+		actionRecord.Error = err.Error()
+		record.Success = false
+		record.ErrorMessage = fmt.Sprintf("策略决策执行失败: %v", err)
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, err))
+	} else {
+		actionRecord.Success = true
+		record.ExecutionLog = append(record.ExecutionLog, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action))
+	}
+	record.Decisions = append(record.Decisions, actionRecord)
+
+	if logErr := at.decisionLogger.LogDecision(record); logErr != nil {
+		log.Warn("⚠ 保存决策记录失败", "error", logErr)
+	}
+	return err
+}
+
+// StrategyAccount 当前账户信息和持仓（供 strategy 包构建行情快照）
+func (at *AutoTrader) StrategyAccount() (decision.AccountInfo, []decision.PositionInfo, error) {
+	ctx, err := at.buildTradingContext()
+	if err != nil {
+		return decision.AccountInfo{}, nil, err
+	}
+	return ctx.Account, ctx.Positions, nil
+}
+
+// StrategyFundingRate 下次结算的预测资金费率（供 strategy 包的资金费率套利使用）
+func (at *AutoTrader) StrategyFundingRate(symbol string) (float64, error) {
+	funding, err := at.trader.GetFundingRate(at.ctx(), symbol)
+	if err != nil {
+		return 0, err
+	}
+	return funding.Rate, nil
+}
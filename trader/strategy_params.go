@@ -0,0 +1,407 @@
+package trader
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 策略参数类型
+const (
+	ParamTypeInt      = "int"      // 整数
+	ParamTypeFloat    = "float"    // 浮点数
+	ParamTypeDuration = "duration" // 时长（以秒表示）
+)
+
+// StrategyParam 可在运行时调整的策略参数（类型、取值范围与当前值）
+type StrategyParam struct {
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Min         float64 `json:"min"`
+	Max         float64 `json:"max"`
+	Value       float64 `json:"value"`
+	Description string  `json:"description"`
+}
+
+// ParamChange 一次参数调整记录（回滚同样记为一次调整）
+type ParamChange struct {
+	ID        int       `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	Param     string    `json:"param"`
+	OldValue  float64   `json:"old_value"`
+	NewValue  float64   `json:"new_value"`
+	Operator  string    `json:"operator,omitempty"`  // 操作人（用户ID）
+	Reason    string    `json:"reason,omitempty"`    // 调整原因
+	RevertOf  int       `json:"revert_of,omitempty"` // 回滚的调整记录ID（0=普通调整）
+}
+
+// strategyParamDef 参数定义：元数据 + 读写 AutoTraderConfig 对应字段
+type strategyParamDef struct {
+	StrategyParam
+	get func(c *AutoTraderConfig) float64
+	set func(c *AutoTraderConfig, v float64)
+}
+
+// strategyParamDefs 可调参数列表（运行时调整只作用于内存中的配置，重启后恢复为数据库配置）
+var strategyParamDefs = []strategyParamDef{
+	{
+		StrategyParam: StrategyParam{Name: "scan_interval", Type: ParamTypeDuration, Min: 60, Max: 3600, Description: "AI决策周期间隔（秒）"},
+		get:           func(c *AutoTraderConfig) float64 { return c.ScanInterval.Seconds() },
+		set:           func(c *AutoTraderConfig, v float64) { c.ScanInterval = time.Duration(v) * time.Second },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "btc_eth_leverage", Type: ParamTypeInt, Min: 1, Max: 50, Description: "BTC/ETH 杠杆倍数"},
+		get:           func(c *AutoTraderConfig) float64 { return float64(c.BTCETHLeverage) },
+		set:           func(c *AutoTraderConfig, v float64) { c.BTCETHLeverage = int(v) },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "altcoin_leverage", Type: ParamTypeInt, Min: 1, Max: 20, Description: "山寨币杠杆倍数"},
+		get:           func(c *AutoTraderConfig) float64 { return float64(c.AltcoinLeverage) },
+		set:           func(c *AutoTraderConfig, v float64) { c.AltcoinLeverage = int(v) },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "max_daily_loss", Type: ParamTypeFloat, Min: 0, Max: 100, Description: "最大日亏损百分比"},
+		get:           func(c *AutoTraderConfig) float64 { return c.MaxDailyLoss },
+		set:           func(c *AutoTraderConfig, v float64) { c.MaxDailyLoss = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "max_drawdown", Type: ParamTypeFloat, Min: 0, Max: 100, Description: "最大回撤百分比"},
+		get:           func(c *AutoTraderConfig) float64 { return c.MaxDrawdown },
+		set:           func(c *AutoTraderConfig, v float64) { c.MaxDrawdown = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "stop_trading_time", Type: ParamTypeDuration, Min: 0, Max: 86400, Description: "触发风控后暂停时长（秒）"},
+		get:           func(c *AutoTraderConfig) float64 { return c.StopTradingTime.Seconds() },
+		set:           func(c *AutoTraderConfig, v float64) { c.StopTradingTime = time.Duration(v) * time.Second },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "funding_cost_threshold_bps", Type: ParamTypeFloat, Min: 0, Max: 100, Description: "资金费率成本阈值（基点，0=关闭）"},
+		get:           func(c *AutoTraderConfig) float64 { return c.FundingCostThresholdBps },
+		set:           func(c *AutoTraderConfig, v float64) { c.FundingCostThresholdBps = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "margin_ratio_danger_pct", Type: ParamTypeFloat, Min: 0, Max: 100, Description: "保证金率危险阈值百分比（0=关闭）"},
+		get:           func(c *AutoTraderConfig) float64 { return c.MarginRatioDangerPct },
+		set:           func(c *AutoTraderConfig, v float64) { c.MarginRatioDangerPct = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "deleverage_pct", Type: ParamTypeFloat, Min: 1, Max: 100, Description: "保证金率危险时的减仓比例百分比"},
+		get:           func(c *AutoTraderConfig) float64 { return c.DeleveragePct },
+		set:           func(c *AutoTraderConfig, v float64) { c.DeleveragePct = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "limit_price_offset", Type: ParamTypeFloat, Min: -1, Max: 1, Description: "限价单价格偏移百分比"},
+		get:           func(c *AutoTraderConfig) float64 { return c.LimitPriceOffset },
+		set:           func(c *AutoTraderConfig, v float64) { c.LimitPriceOffset = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "limit_timeout_seconds", Type: ParamTypeInt, Min: 5, Max: 600, Description: "限价单转市价前的等待秒数"},
+		get:           func(c *AutoTraderConfig) float64 { return float64(c.LimitTimeoutSeconds) },
+		set:           func(c *AutoTraderConfig, v float64) { c.LimitTimeoutSeconds = int(v) },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "confirm_delay_seconds", Type: ParamTypeInt, Min: 0, Max: 300, Description: "开仓确认等待秒数（0=关闭）"},
+		get:           func(c *AutoTraderConfig) float64 { return float64(c.ConfirmDelaySeconds) },
+		set:           func(c *AutoTraderConfig, v float64) { c.ConfirmDelaySeconds = int(v) },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "confirm_max_deviation_pct", Type: ParamTypeFloat, Min: 0.01, Max: 10, Description: "开仓确认允许的最大价格偏离百分比"},
+		get:           func(c *AutoTraderConfig) float64 { return c.ConfirmMaxDeviationPct },
+		set:           func(c *AutoTraderConfig, v float64) { c.ConfirmMaxDeviationPct = v },
+	},
+	{
+		StrategyParam: StrategyParam{Name: "flatten_warning_minutes", Type: ParamTypeInt, Min: 0, Max: 120, Description: "收盘平仓提前预警分钟数"},
+		get:           func(c *AutoTraderConfig) float64 { return float64(c.FlattenWarningMinutes) },
+		set:           func(c *AutoTraderConfig, v float64) { c.FlattenWarningMinutes = int(v) },
+	},
+}
+
+// findStrategyParam 按名称查找参数定义
+func findStrategyParam(name string) (*strategyParamDef, bool) {
+	for i := range strategyParamDefs {
+		if strategyParamDefs[i].Name == name {
+			return &strategyParamDefs[i], true
+		}
+	}
+	return nil, false
+}
+
+// validate 校验取值的类型与范围
+func (d *strategyParamDef) validate(value float64) error {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return fmt.Errorf("参数 %s 的值无效", d.Name)
+	}
+	if (d.Type == ParamTypeInt || d.Type == ParamTypeDuration) && value != math.Trunc(value) {
+		return fmt.Errorf("参数 %s 必须为整数", d.Name)
+	}
+	if value < d.Min || value > d.Max {
+		return fmt.Errorf("参数 %s 超出范围 [%g, %g]: %g", d.Name, d.Min, d.Max, value)
+	}
+	return nil
+}
+
+// GetStrategyParams 获取全部可调参数及其当前值
+func (at *AutoTrader) GetStrategyParams() []StrategyParam {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+
+	params := make([]StrategyParam, len(strategyParamDefs))
+	for i, d := range strategyParamDefs {
+		params[i] = d.StrategyParam
+		params[i].Value = d.get(&at.config)
+	}
+	return params
+}
+
+// SetStrategyParam 在运行时调整参数（校验类型与范围，并记录到调整日志）
+func (at *AutoTrader) SetStrategyParam(name string, value float64, operator, reason string) (*ParamChange, error) {
+	return at.applyStrategyParam(name, value, operator, reason, 0)
+}
+
+// RevertParamChange 回滚一次调整（把参数恢复为该次调整前的值，回滚本身也会记录）
+func (at *AutoTrader) RevertParamChange(id int, operator string) (*ParamChange, error) {
+	change, ok := at.paramJournal.get(id)
+	if !ok {
+		return nil, fmt.Errorf("调整记录不存在: %d", id)
+	}
+	return at.applyStrategyParam(change.Param, change.OldValue, operator, fmt.Sprintf("回滚调整 #%d", id), id)
+}
+
+// GetParamChanges 获取参数调整记录（按时间正序）
+func (at *AutoTrader) GetParamChanges() []ParamChange {
+	return at.paramJournal.list()
+}
+
+// applyStrategyParam 校验、应用并记录一次调整
+func (at *AutoTrader) applyStrategyParam(name string, value float64, operator, reason string, revertOf int) (*ParamChange, error) {
+	def, ok := findStrategyParam(name)
+	if !ok {
+		return nil, fmt.Errorf("未知的策略参数: %s", name)
+	}
+	if err := def.validate(value); err != nil {
+		return nil, err
+	}
+
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+
+	change := ParamChange{
+		Timestamp: time.Now(),
+		Param:     name,
+		OldValue:  def.get(&at.config),
+		NewValue:  value,
+		Operator:  operator,
+		Reason:    reason,
+		RevertOf:  revertOf,
+	}
+	def.set(&at.config, value)
+
+	change, err := at.paramJournal.append(change)
+	if err != nil {
+		// 参数已生效，日志写入失败只记录警告（内存中的调整记录仍然可用于回滚）
+		log.Warn("⚠️ 参数调整日志写入失败", "trader", at.name, "error", err)
+	}
+
+	log.Info("🎛️ 策略参数已调整", "trader", at.name, "id", change.ID, "param", name, "old_value", change.OldValue, "new_value", value, "operator", operator)
+	return &change, nil
+}
+
+// ValidateStrategyParams 校验一组参数（名称和取值范围），用于批量应用前整体校验
+func ValidateStrategyParams(values map[string]float64) error {
+	for _, name := range sortedParamNames(values) {
+		def, ok := findStrategyParam(name)
+		if !ok {
+			return fmt.Errorf("未知的策略参数: %s", name)
+		}
+		if err := def.validate(values[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ApplyStrategyParams 批量调整参数（先整体校验，任一参数无效时不做任何调整；只记录取值发生变化的参数）
+func (at *AutoTrader) ApplyStrategyParams(values map[string]float64, operator, reason string) ([]ParamChange, error) {
+	if err := ValidateStrategyParams(values); err != nil {
+		return nil, err
+	}
+	var changes []ParamChange
+	for _, name := range sortedParamNames(values) {
+		def, _ := findStrategyParam(name)
+		at.paramMutex.Lock()
+		current := def.get(&at.config)
+		at.paramMutex.Unlock()
+		if current == values[name] {
+			continue
+		}
+		change, err := at.applyStrategyParam(name, values[name], operator, reason, 0)
+		if err != nil {
+			return changes, err
+		}
+		changes = append(changes, *change)
+	}
+	return changes, nil
+}
+
+func sortedParamNames(values map[string]float64) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetDefaultCoins 运行时替换系统默认币种列表（下一个决策周期生效）
+func (at *AutoTrader) SetDefaultCoins(coins []string) {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	at.defaultCoins = append([]string(nil), coins...)
+}
+
+// getDefaultCoins 读取当前系统默认币种列表（可能被配置热更新替换）
+func (at *AutoTrader) getDefaultCoins() []string {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.defaultCoins
+}
+
+// getScanInterval 读取当前扫描间隔（可能被运行时调整）
+func (at *AutoTrader) getScanInterval() time.Duration {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.ScanInterval
+}
+
+// getLeverages 读取 BTC/ETH 与山寨币的配置杠杆倍数（可能被运行时调整）
+func (at *AutoTrader) getLeverages() (btcEth, altcoin int) {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.BTCETHLeverage, at.config.AltcoinLeverage
+}
+
+// getRiskLimits 读取最大日亏损、最大回撤百分比与触发后的暂停时长（可能被运行时调整）
+func (at *AutoTrader) getRiskLimits() (maxDailyLoss, maxDrawdown float64, stopTradingTime time.Duration) {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.MaxDailyLoss, at.config.MaxDrawdown, at.config.StopTradingTime
+}
+
+// getFundingCostThresholdBps 读取资金费率成本阈值（可能被运行时调整）
+func (at *AutoTrader) getFundingCostThresholdBps() float64 {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.FundingCostThresholdBps
+}
+
+// getMarginRatioParams 读取保证金率危险阈值与减仓比例（可能被运行时调整）
+func (at *AutoTrader) getMarginRatioParams() (dangerPct, deleveragePct float64) {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.MarginRatioDangerPct, at.config.DeleveragePct
+}
+
+// getConfirmParams 读取开仓确认等待秒数与允许的最大价格偏离（可能被运行时调整）
+func (at *AutoTrader) getConfirmParams() (delaySeconds int, maxDeviationPct float64) {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.ConfirmDelaySeconds, at.config.ConfirmMaxDeviationPct
+}
+
+// getFlattenWarningMinutes 读取收盘平仓提前预警分钟数（可能被运行时调整）
+func (at *AutoTrader) getFlattenWarningMinutes() int {
+	at.paramMutex.Lock()
+	defer at.paramMutex.Unlock()
+	return at.config.FlattenWarningMinutes
+}
+
+// paramJournal 参数调整日志（内存 + JSONL 文件追加）
+type paramJournal struct {
+	mu      sync.Mutex
+	path    string // 日志文件路径（空=只保存在内存）
+	changes []ParamChange
+}
+
+// newParamJournal 创建调整日志并加载已有记录（用于重启后继续编号与回滚）
+func newParamJournal(path string) *paramJournal {
+	j := &paramJournal{path: path}
+	if path == "" {
+		return j
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warn("⚠️ 读取参数调整日志失败", "error", err)
+		}
+		return j
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var change ParamChange
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			continue
+		}
+		j.changes = append(j.changes, change)
+	}
+	return j
+}
+
+// append 分配ID并写入一条记录
+func (j *paramJournal) append(change ParamChange) (ParamChange, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	change.ID = 1
+	if n := len(j.changes); n > 0 {
+		change.ID = j.changes[n-1].ID + 1
+	}
+	j.changes = append(j.changes, change)
+
+	if j.path == "" {
+		return change, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0700); err != nil {
+		return change, fmt.Errorf("创建日志目录失败: %w", err)
+	}
+	data, err := json.Marshal(change)
+	if err != nil {
+		return change, err
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return change, fmt.Errorf("打开日志文件失败: %w", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return change, fmt.Errorf("写入日志文件失败: %w", err)
+	}
+	return change, nil
+}
+
+// get 按ID查找记录
+func (j *paramJournal) get(id int) (ParamChange, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	for _, c := range j.changes {
+		if c.ID == id {
+			return c, true
+		}
+	}
+	return ParamChange{}, false
+}
+
+// list 返回全部记录的副本
+func (j *paramJournal) list() []ParamChange {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]ParamChange(nil), j.changes...)
+}
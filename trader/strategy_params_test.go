@@ -0,0 +1,183 @@
+package trader
+
+import (
+	"nofx/decision"
+	"nofx/logger"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func newParamTestTrader(t *testing.T) (*AutoTrader, string) {
+	path := filepath.Join(t.TempDir(), "params", "changes.jsonl")
+	at := &AutoTrader{
+		name: "test",
+		config: AutoTraderConfig{
+			ScanInterval:    3 * time.Minute,
+			BTCETHLeverage:  5,
+			AltcoinLeverage: 3,
+		},
+		paramJournal: newParamJournal(path),
+	}
+	return at, path
+}
+
+func TestSetStrategyParam(t *testing.T) {
+	at, _ := newParamTestTrader(t)
+
+	change, err := at.SetStrategyParam("btc_eth_leverage", 10, "user1", "趋势明确")
+	if err != nil {
+		t.Fatalf("调整失败: %v", err)
+	}
+	if change.ID != 1 || change.OldValue != 5 || change.NewValue != 10 || at.config.BTCETHLeverage != 10 {
+		t.Errorf("调整结果错误: %+v, leverage=%d", change, at.config.BTCETHLeverage)
+	}
+
+	if _, err := at.SetStrategyParam("scan_interval", 300, "user1", ""); err != nil {
+		t.Fatalf("调整失败: %v", err)
+	}
+	if at.getScanInterval() != 5*time.Minute {
+		t.Errorf("期望扫描间隔 5m，实际 %v", at.getScanInterval())
+	}
+
+	tests := []struct {
+		name  string
+		param string
+		value float64
+	}{
+		{"超出上限", "btc_eth_leverage", 100},
+		{"低于下限", "scan_interval", 10},
+		{"整数参数不接受小数", "altcoin_leverage", 2.5},
+		{"未知参数", "rsi_threshold", 30},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := at.SetStrategyParam(tt.param, tt.value, "user1", ""); err == nil {
+				t.Error("期望返回错误")
+			}
+		})
+	}
+	if at.config.AltcoinLeverage != 3 || len(at.GetParamChanges()) != 2 {
+		t.Errorf("被拒绝的调整不应生效或记录: leverage=%d, changes=%d", at.config.AltcoinLeverage, len(at.GetParamChanges()))
+	}
+}
+
+func TestRevertParamChange(t *testing.T) {
+	at, path := newParamTestTrader(t)
+
+	at.SetStrategyParam("altcoin_leverage", 5, "user1", "")
+	at.SetStrategyParam("altcoin_leverage", 8, "user1", "")
+
+	change, err := at.RevertParamChange(1, "user2")
+	if err != nil {
+		t.Fatalf("回滚失败: %v", err)
+	}
+	if at.config.AltcoinLeverage != 3 || change.RevertOf != 1 || change.OldValue != 8 || change.ID != 3 {
+		t.Errorf("回滚结果错误: %+v, leverage=%d", change, at.config.AltcoinLeverage)
+	}
+
+	if _, err := at.RevertParamChange(99, "user2"); err == nil {
+		t.Error("不存在的调整记录应返回错误")
+	}
+
+	// 重启后从日志文件恢复记录并继续编号
+	reloaded := newParamJournal(path)
+	if changes := reloaded.list(); len(changes) != 3 || changes[2].Operator != "user2" {
+		t.Fatalf("日志文件内容错误: %+v", changes)
+	}
+	next, _ := reloaded.append(ParamChange{Param: "altcoin_leverage"})
+	if next.ID != 4 {
+		t.Errorf("期望编号 4，实际 %d", next.ID)
+	}
+}
+
+func TestGetStrategyParams(t *testing.T) {
+	at, _ := newParamTestTrader(t)
+
+	params := at.GetStrategyParams()
+	if len(params) != len(strategyParamDefs) {
+		t.Fatalf("期望 %d 个参数，实际 %d", len(strategyParamDefs), len(params))
+	}
+	for _, p := range params {
+		if p.Name == "scan_interval" && (p.Value != 180 || p.Type != ParamTypeDuration) {
+			t.Errorf("scan_interval 错误: %+v", p)
+		}
+		if p.Min > p.Max {
+			t.Errorf("参数 %s 范围无效", p.Name)
+		}
+	}
+}
+
+func TestApplyStrategyParams(t *testing.T) {
+	at, _ := newParamTestTrader(t)
+
+	// 任一参数无效时整体拒绝
+	if _, err := at.ApplyStrategyParams(map[string]float64{"btc_eth_leverage": 8, "altcoin_leverage": 99}, "config", ""); err == nil {
+		t.Fatal("期望返回错误")
+	}
+	if at.config.BTCETHLeverage != 5 {
+		t.Errorf("整体校验失败时不应调整任何参数, leverage=%d", at.config.BTCETHLeverage)
+	}
+
+	// 只记录取值变化的参数
+	changes, err := at.ApplyStrategyParams(map[string]float64{"btc_eth_leverage": 8, "altcoin_leverage": 3}, "config", "配置热更新")
+	if err != nil {
+		t.Fatalf("批量调整失败: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Param != "btc_eth_leverage" || at.config.BTCETHLeverage != 8 {
+		t.Errorf("批量调整结果错误: %+v", changes)
+	}
+
+	at.SetDefaultCoins([]string{"SOLUSDT"})
+	if coins := at.getDefaultCoins(); len(coins) != 1 || coins[0] != "SOLUSDT" {
+		t.Errorf("默认币种未更新: %v", coins)
+	}
+}
+
+// TestTuneParamsDuringCycle 决策周期和监控运行时调整参数（go test -race 检查数据竞争）
+func TestTuneParamsDuringCycle(t *testing.T) {
+	at, _ := newParamTestTrader(t)
+	at.trader = &MockTrader{fundingRate: 0.001, balance: map[string]interface{}{
+		"totalWalletBalance": 1000.0, "availableBalance": 800.0, "totalUnrealizedProfit": 0.0,
+	}}
+	at.decisionLogger = logger.NewDecisionLogger(t.TempDir())
+	at.decisionProvider = decision.ProviderFunc{ProviderName: "test", Fn: func(ctx *decision.Context) (*decision.FullDecision, error) {
+		return &decision.FullDecision{}, nil
+	}}
+	at.defaultCoins = []string{"BTCUSDT"}
+	at.positionFirstSeenTime = make(map[string]int64)
+	at.positionStopLoss = make(map[string]float64)
+	at.positionTakeProfit = make(map[string]float64)
+	at.peakPnLCache = make(map[string]float64)
+	at.lastPositions = make(map[string]decision.PositionInfo)
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			at.SetStrategyParam("btc_eth_leverage", float64(1+i%10), "test", "")
+			at.SetStrategyParam("max_daily_loss", float64(i%50), "test", "")
+			at.SetStrategyParam("funding_cost_threshold_bps", float64(i%5), "test", "")
+			at.SetStrategyParam("margin_ratio_danger_pct", float64(50+i), "test", "")
+			at.SetStrategyParam("confirm_max_deviation_pct", 1, "test", "")
+		}
+	}()
+
+	for i := 0; i < 5; i++ {
+		if err := at.runCycle(); err != nil {
+			t.Fatalf("runCycle() error = %v", err)
+		}
+		at.applyFundingFilter(&decision.Decision{Symbol: "BTCUSDT", Action: "open_long"})
+		if err := at.checkMarginRatio(); err != nil {
+			t.Fatalf("checkMarginRatio() error = %v", err)
+		}
+	}
+	at.activateRiskStop("test")
+	close(stop)
+	<-done
+}
@@ -0,0 +1,75 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/market"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// UniverseFromEnv 读取动态币种池配置（NOFX_UNIVERSE_RANK 未设置时返回 nil，使用静态的系统默认币种）
+func UniverseFromEnv() (*market.UniverseConfig, error) {
+	rank := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_UNIVERSE_RANK")))
+	if rank == "" {
+		return nil, nil
+	}
+	if rank != market.UniverseRankVolume && rank != market.UniverseRankVolatility {
+		return nil, fmt.Errorf("NOFX_UNIVERSE_RANK=%q 应为 %s 或 %s", rank, market.UniverseRankVolume, market.UniverseRankVolatility)
+	}
+	cfg := &market.UniverseConfig{
+		Rank:   rank,
+		Source: strings.TrimSpace(os.Getenv("NOFX_UNIVERSE_SOURCE")),
+		Allow:  splitSymbols(os.Getenv("NOFX_UNIVERSE_ALLOW")),
+		Deny:   splitSymbols(os.Getenv("NOFX_UNIVERSE_DENY")),
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_UNIVERSE_TOP_N")); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("NOFX_UNIVERSE_TOP_N=%q 应为正整数", v)
+		}
+		cfg.TopN = n
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_UNIVERSE_MIN_VOLUME")); v != "" {
+		vol, err := strconv.ParseFloat(v, 64)
+		if err != nil || vol < 0 {
+			return nil, fmt.Errorf("NOFX_UNIVERSE_MIN_VOLUME=%q 应为非负数（USDT）", v)
+		}
+		cfg.MinQuoteVolume = vol
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_UNIVERSE_REFRESH")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("NOFX_UNIVERSE_REFRESH 必须为正的时间间隔（如 24h）: %q", v)
+		}
+		cfg.Refresh = d
+	}
+	return cfg, nil
+}
+
+// splitSymbols 解析逗号分隔的币种列表
+func splitSymbols(raw string) []string {
+	var symbols []string
+	for _, s := range strings.Split(raw, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			symbols = append(symbols, normalizeSymbol(s))
+		}
+	}
+	return symbols
+}
+
+// baseCoins 候选币种的基础列表及来源标签：启用动态币种池时为当前选取结果，
+// 否则（或币种池从未选取成功时）为系统默认币种
+func (at *AutoTrader) baseCoins() ([]string, string) {
+	if at.universe != nil {
+		symbols, err := at.universe.Symbols()
+		if err == nil && len(symbols) > 0 {
+			return symbols, "universe"
+		}
+		if err != nil {
+			log.Warn("⚠️ 动态币种池不可用，使用系统默认币种", "trader", at.name, "error", err)
+		}
+	}
+	return at.getDefaultCoins(), "default"
+}
@@ -0,0 +1,55 @@
+package trader
+
+import (
+	"testing"
+	"time"
+
+	"nofx/market"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUniverseFromEnv(t *testing.T) {
+	t.Setenv("NOFX_UNIVERSE_RANK", "")
+	cfg, err := UniverseFromEnv()
+	require.NoError(t, err)
+	assert.Nil(t, cfg, "未设置时不启用动态币种池")
+
+	t.Setenv("NOFX_UNIVERSE_RANK", "Volatility")
+	t.Setenv("NOFX_UNIVERSE_TOP_N", "15")
+	t.Setenv("NOFX_UNIVERSE_ALLOW", "btc, ETHUSDT,")
+	t.Setenv("NOFX_UNIVERSE_DENY", "usdcusdt")
+	t.Setenv("NOFX_UNIVERSE_MIN_VOLUME", "50000000")
+	t.Setenv("NOFX_UNIVERSE_REFRESH", "12h")
+	cfg, err = UniverseFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, &market.UniverseConfig{
+		Rank:           market.UniverseRankVolatility,
+		TopN:           15,
+		Allow:          []string{"BTCUSDT", "ETHUSDT"},
+		Deny:           []string{"USDCUSDT"},
+		MinQuoteVolume: 5e7,
+		Refresh:        12 * time.Hour,
+	}, cfg)
+
+	for name, value := range map[string]string{
+		"NOFX_UNIVERSE_RANK":       "oi",
+		"NOFX_UNIVERSE_TOP_N":      "0",
+		"NOFX_UNIVERSE_MIN_VOLUME": "-1",
+		"NOFX_UNIVERSE_REFRESH":    "daily",
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv(name, value)
+			_, err := UniverseFromEnv()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestBaseCoinsWithoutUniverse(t *testing.T) {
+	at := &AutoTrader{defaultCoins: []string{"BTCUSDT"}}
+	coins, source := at.baseCoins()
+	assert.Equal(t, []string{"BTCUSDT"}, coins)
+	assert.Equal(t, "default", source)
+}
@@ -0,0 +1,226 @@
+package trader
+
+import (
+	"fmt"
+	"nofx/decision"
+	"nofx/logger"
+	"nofx/logging"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SymbolWorkersFromEnv 读取 NOFX_SYMBOL_WORKERS（每个周期并发执行决策的币种数，未设置返回 0=按顺序执行）
+func SymbolWorkersFromEnv() (int, error) {
+	raw := strings.TrimSpace(os.Getenv("NOFX_SYMBOL_WORKERS"))
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("NOFX_SYMBOL_WORKERS 必须为正整数: %q", raw)
+	}
+	return n, nil
+}
+
+// lockSymbol 获取币种执行锁（交易周期、外部策略和信号对同一币种的操作按顺序执行），返回解锁函数
+func (at *AutoTrader) lockSymbol(symbol string) func() {
+	mu, _ := at.symbolLocks.LoadOrStore(symbol, &sync.Mutex{})
+	m := mu.(*sync.Mutex)
+	m.Lock()
+	return m.Unlock
+}
+
+// executeDecisions 执行已排序的决策并写入决策记录
+// 按 平仓 → 调整止盈止损 → 开仓 分批执行，上一批全部完成后才开始下一批；
+// 配置了 SymbolWorkers 时同一批内不同币种最多由 SymbolWorkers 个协程并发执行，同一币种的决策仍按顺序执行
+func (at *AutoTrader) executeDecisions(sorted []decision.Decision, record *logger.DecisionRecord) {
+	actions := make([]*logger.DecisionAction, len(sorted))
+	lines := make([]string, len(sorted))
+	for _, batch := range decisionBatches(sorted) {
+		if at.config.SymbolWorkers <= 1 {
+			for _, i := range batch {
+				actions[i], lines[i] = at.executeLocked(sorted[i])
+			}
+			continue
+		}
+		at.executeBatchConcurrently(sorted, batch, actions, lines)
+	}
+
+	// 按决策顺序写入记录（与并发完成顺序无关）
+	for i := range sorted {
+		record.ExecutionLog = append(record.ExecutionLog, lines[i])
+		if actions[i] != nil {
+			record.Decisions = append(record.Decisions, *actions[i])
+		}
+	}
+}
+
+// executeBatchConcurrently 同一批决策按币种分组，最多 SymbolWorkers 组同时执行
+func (at *AutoTrader) executeBatchConcurrently(sorted []decision.Decision, batch []int, actions []*logger.DecisionAction, lines []string) {
+	var order []string
+	groups := make(map[string][]int)
+	for _, i := range batch {
+		symbol := sorted[i].Symbol
+		if _, ok := groups[symbol]; !ok {
+			order = append(order, symbol)
+		}
+		groups[symbol] = append(groups[symbol], i)
+	}
+
+	// 并发开仓时各协程读到的可用余额相同，本批已通过检查的保证金需要从可用余额中扣除
+	opening := len(order) > 1 && decisionPriority(sorted[batch[0]].Action) == priorityOpen
+	if opening {
+		at.marginReserve.begin()
+		defer at.marginReserve.end()
+	}
+
+	sem := make(chan struct{}, at.config.SymbolWorkers)
+	var wg sync.WaitGroup
+	for _, symbol := range order {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(indexes []int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			for _, i := range indexes {
+				actions[i], lines[i] = at.executeLocked(sorted[i])
+			}
+		}(groups[symbol])
+	}
+	wg.Wait()
+}
+
+// executeLocked 持有币种锁执行单个决策，返回决策动作记录（正在退出时为 nil）和执行日志
+func (at *AutoTrader) executeLocked(d decision.Decision) (*logger.DecisionAction, string) {
+	if at.draining.Load() {
+		log.Info("⏹ 正在退出，跳过剩余决策", "trader", at.name, "symbol", d.Symbol, "action", d.Action)
+		return nil, fmt.Sprintf("⏹ %s %s 未执行（正在退出）", d.Symbol, d.Action)
+	}
+	unlock := at.lockSymbol(d.Symbol)
+	defer unlock()
+
+	actionRecord := &logger.DecisionAction{
+		Action:    d.Action,
+		Symbol:    d.Symbol,
+		Quantity:  0,
+		Leverage:  d.Leverage,
+		Price:     0,
+		Timestamp: time.Now(),
+		Success:   false,
+		TradeID:   logging.NewTradeID(),
+	}
+
+	at.recordDecision(&d)
+	if err := at.executeDecisionWithRecord(&d, actionRecord); err != nil {
+		at.tradeLog(actionRecord).Error("❌ 执行决策失败", "error", err)
+		at.recordDecisionError(&d, err)
+		actionRecord.Error = err.Error()
+		return actionRecord, fmt.Sprintf("❌ %s %s 失败: %v", d.Symbol, d.Action, err)
+	}
+	actionRecord.Success = true
+	// 成功执行后短暂延迟
+	time.Sleep(executionPause)
+	return actionRecord, fmt.Sprintf("✓ %s %s 成功", d.Symbol, d.Action)
+}
+
+// executionPause 每个决策执行成功后的等待时间（测试中可缩短）
+var executionPause = 1 * time.Second
+
+// decisionBatches 已排序的决策按优先级切分为批次（决策下标）
+func decisionBatches(sorted []decision.Decision) [][]int {
+	var batches [][]int
+	for i, d := range sorted {
+		n := len(batches)
+		if n > 0 && decisionPriority(sorted[batches[n-1][0]].Action) == decisionPriority(d.Action) {
+			batches[n-1] = append(batches[n-1], i)
+			continue
+		}
+		batches = append(batches, []int{i})
+	}
+	return batches
+}
+
+// marginReservation 并发开仓批次中已通过保证金检查、尚未结束的开仓占用的保证金和相关性分组敞口
+// 批次结束前不释放：已成交的开仓会同时反映在交易所余额和持仓中，重复扣除只会更保守
+type marginReservation struct {
+	mu       sync.Mutex
+	active   bool
+	reserved float64
+	notional map[string]float64 // 相关性分组|方向 -> 本批已占用的名义价值
+}
+
+func (r *marginReservation) begin() {
+	r.mu.Lock()
+	r.active, r.reserved, r.notional = true, 0, make(map[string]float64)
+	r.mu.Unlock()
+}
+
+func (r *marginReservation) end() {
+	r.mu.Lock()
+	r.active, r.reserved, r.notional = false, 0, nil
+	r.mu.Unlock()
+}
+
+// reserveMargin 并发开仓时按本批已占用的保证金检查可用余额并占用 required（非并发开仓时不检查）
+func (at *AutoTrader) reserveMargin(symbol string, required, available float64) error {
+	r := &at.marginReserve
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active {
+		return nil
+	}
+	if r.reserved+required > available {
+		return fmt.Errorf("❌ %s 保证金不足: 需要 %.2f USDT，可用 %.2f USDT（本周期并发开仓已占用 %.2f USDT）",
+			symbol, required, available-r.reserved, r.reserved)
+	}
+	r.reserved += required
+	return nil
+}
+
+// releaseMargin 开仓下单失败时释放占用的保证金
+func (at *AutoTrader) releaseMargin(amount float64) {
+	r := &at.marginReserve
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.active {
+		r.reserved -= amount
+	}
+}
+
+// reserveCorrelation 并发开仓时计入本批已占用的名义价值重新检查相关性分组敞口，并占用 notional（非并发开仓时不检查）
+// 各协程读到的持仓缓存相同，只靠 checkCorrelationExposure 会让同组的多笔开仓同时通过
+func (at *AutoTrader) reserveCorrelation(symbol, side string, notional float64, positions []map[string]interface{}, equity float64) error {
+	r := &at.marginReserve
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active {
+		return nil
+	}
+	if err := at.correlationExposure(symbol, side, notional, positions, equity, r.notional, false); err != nil {
+		return fmt.Errorf("%w（含本周期并发开仓）", err)
+	}
+	for _, group := range at.config.CorrelationGroups {
+		if group.MaxExposurePct > 0 && group.contains(symbol) {
+			r.notional[correlationKey(group, side)] += notional
+		}
+	}
+	return nil
+}
+
+// releaseCorrelation 开仓下单失败时释放占用的相关性分组敞口
+func (at *AutoTrader) releaseCorrelation(symbol, side string, notional float64) {
+	r := &at.marginReserve
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.active {
+		return
+	}
+	for _, group := range at.config.CorrelationGroups {
+		if group.MaxExposurePct > 0 && group.contains(symbol) {
+			r.notional[correlationKey(group, side)] -= notional
+		}
+	}
+}
@@ -0,0 +1,239 @@
+package trader
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"nofx/decision"
+	"nofx/logger"
+)
+
+func TestSymbolWorkersFromEnv(t *testing.T) {
+	t.Setenv("NOFX_SYMBOL_WORKERS", "")
+	if n, err := SymbolWorkersFromEnv(); err != nil || n != 0 {
+		t.Errorf("未设置时应按顺序执行: n=%d err=%v", n, err)
+	}
+	t.Setenv("NOFX_SYMBOL_WORKERS", "4")
+	if n, err := SymbolWorkersFromEnv(); err != nil || n != 4 {
+		t.Errorf("n=%d err=%v，期望 4", n, err)
+	}
+	for _, raw := range []string{"0", "-1", "abc"} {
+		t.Setenv("NOFX_SYMBOL_WORKERS", raw)
+		if _, err := SymbolWorkersFromEnv(); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
+
+func TestDecisionBatches(t *testing.T) {
+	sorted := []decision.Decision{
+		{Symbol: "BTCUSDT", Action: "close_long"},
+		{Symbol: "ETHUSDT", Action: "partial_close"},
+		{Symbol: "SOLUSDT", Action: "update_stop_loss"},
+		{Symbol: "BTCUSDT", Action: "open_short"},
+		{Symbol: "ETHUSDT", Action: "open_long"},
+		{Symbol: "SOLUSDT", Action: "hold"},
+	}
+	batches := decisionBatches(sorted)
+	want := [][]int{{0, 1}, {2}, {3, 4}, {5}}
+	if len(batches) != len(want) {
+		t.Fatalf("batches=%v，期望 %v", batches, want)
+	}
+	for i := range want {
+		if len(batches[i]) != len(want[i]) || batches[i][0] != want[i][0] || batches[i][len(want[i])-1] != want[i][len(want[i])-1] {
+			t.Errorf("batches=%v，期望 %v", batches, want)
+		}
+	}
+}
+
+func TestExecuteDecisionsConcurrentKeepsRecordOrder(t *testing.T) {
+	pause := executionPause
+	executionPause = 0
+	defer func() { executionPause = pause }()
+
+	sorted := []decision.Decision{
+		{Symbol: "BTCUSDT", Action: "hold"},
+		{Symbol: "ETHUSDT", Action: "bogus"},
+		{Symbol: "SOLUSDT", Action: "wait"},
+		{Symbol: "BTCUSDT", Action: "wait"},
+	}
+	for _, workers := range []int{0, 3} {
+		at := &AutoTrader{name: "test", config: AutoTraderConfig{SymbolWorkers: workers}, trader: &MockTrader{}}
+		record := &logger.DecisionRecord{}
+		at.executeDecisions(sorted, record)
+
+		if len(record.Decisions) != len(sorted) || len(record.ExecutionLog) != len(sorted) {
+			t.Fatalf("workers=%d: 记录数量错误: %+v", workers, record)
+		}
+		for i, d := range sorted {
+			if got := record.Decisions[i]; got.Symbol != d.Symbol || got.Action != d.Action {
+				t.Errorf("workers=%d: 第 %d 条记录应为 %s %s，得到 %s %s", workers, i, d.Symbol, d.Action, got.Symbol, got.Action)
+			}
+		}
+		if record.Decisions[1].Success || !strings.Contains(record.ExecutionLog[1], "失败") {
+			t.Errorf("workers=%d: 未知动作应记录失败: %+v", workers, record.Decisions[1])
+		}
+	}
+}
+
+func TestLockSymbolSerializesSameSymbol(t *testing.T) {
+	at := &AutoTrader{}
+	var active, maxActive, otherRan atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			unlock := at.lockSymbol("BTCUSDT")
+			defer unlock()
+			n := active.Add(1)
+			if n > maxActive.Load() {
+				maxActive.Store(n)
+			}
+			time.Sleep(5 * time.Millisecond)
+			active.Add(-1)
+		}()
+	}
+
+	// 其他币种不受影响
+	unlock := at.lockSymbol("BTCUSDT")
+	go func() {
+		u := at.lockSymbol("ETHUSDT")
+		otherRan.Store(1)
+		u()
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if otherRan.Load() != 1 {
+		t.Error("其他币种不应等待 BTCUSDT 的锁")
+	}
+	unlock()
+	wg.Wait()
+	if maxActive.Load() != 1 {
+		t.Errorf("同一币种最多同时执行 1 个，得到 %d", maxActive.Load())
+	}
+}
+
+func TestReserveMargin(t *testing.T) {
+	at := &AutoTrader{}
+	if err := at.reserveMargin("BTCUSDT", 900, 100); err != nil {
+		t.Errorf("非并发开仓时不应检查: %v", err)
+	}
+
+	at.marginReserve.begin()
+	if err := at.reserveMargin("BTCUSDT", 600, 1000); err != nil {
+		t.Fatalf("第一笔开仓应通过: %v", err)
+	}
+	// 其他协程读到的可用余额仍为 1000，但本批已占用 600
+	err := at.reserveMargin("ETHUSDT", 600, 1000)
+	if err == nil || !strings.Contains(err.Error(), "保证金不足") {
+		t.Fatalf("超出剩余保证金应拒绝: %v", err)
+	}
+	at.releaseMargin(600)
+	if err := at.reserveMargin("ETHUSDT", 600, 1000); err != nil {
+		t.Errorf("释放后应通过: %v", err)
+	}
+	at.marginReserve.end()
+	if err := at.reserveMargin("SOLUSDT", 2000, 1000); err != nil {
+		t.Errorf("批次结束后不应检查: %v", err)
+	}
+}
+
+func TestReserveCorrelation(t *testing.T) {
+	at := &AutoTrader{config: AutoTraderConfig{CorrelationGroups: []CorrelationGroup{
+		{Name: "btc_beta_alts", Symbols: []string{"SOLUSDT", "AVAXUSDT", "ARBUSDT"}, MaxExposurePct: 30},
+	}}}
+	positions := []map[string]interface{}{
+		{"symbol": "SOLUSDT", "side": "long", "positionAmt": 10.0, "markPrice": 100.0}, // 1000 USDT
+	}
+	if err := at.reserveCorrelation("AVAXUSDT", "long", 5000, positions, 10000); err != nil {
+		t.Errorf("非并发开仓时不应检查: %v", err)
+	}
+
+	at.marginReserve.begin()
+	// 两个协程读到相同的持仓缓存，各自都能通过 checkCorrelationExposure
+	for _, symbol := range []string{"AVAXUSDT", "ARBUSDT"} {
+		if err := at.checkCorrelationExposure(symbol, "long", 1500, positions, 10000); err != nil {
+			t.Fatalf("%s 单独检查应通过: %v", symbol, err)
+		}
+	}
+	if err := at.reserveCorrelation("AVAXUSDT", "long", 1500, positions, 10000); err != nil {
+		t.Fatalf("第一笔开仓应通过: %v", err)
+	}
+	err := at.reserveCorrelation("ARBUSDT", "long", 1500, positions, 10000)
+	if err == nil || !strings.Contains(err.Error(), "btc_beta_alts") {
+		t.Fatalf("计入本批已占用的敞口后应超限: %v", err)
+	}
+	if err := at.reserveCorrelation("ARBUSDT", "short", 1500, positions, 10000); err != nil {
+		t.Errorf("反方向不受影响: %v", err)
+	}
+	at.releaseCorrelation("AVAXUSDT", "long", 1500)
+	if err := at.reserveCorrelation("ARBUSDT", "long", 1500, positions, 10000); err != nil {
+		t.Errorf("释放后应通过: %v", err)
+	}
+	at.marginReserve.end()
+}
+
+// countingTrader 统计余额/持仓查询次数的 MockTrader
+type countingTrader struct {
+	MockTrader
+	balanceCalls   atomic.Int32
+	positionsCalls atomic.Int32
+}
+
+func (m *countingTrader) GetBalance(ctx context.Context) (map[string]interface{}, error) {
+	m.balanceCalls.Add(1)
+	time.Sleep(5 * time.Millisecond)
+	return m.MockTrader.GetBalance(ctx)
+}
+
+func (m *countingTrader) GetPositions(ctx context.Context) ([]map[string]interface{}, error) {
+	m.positionsCalls.Add(1)
+	return m.MockTrader.GetPositions(ctx)
+}
+
+func TestAccountCacheTraderSharesAndInvalidates(t *testing.T) {
+	mock := &countingTrader{}
+	cache := newAccountCacheTrader(mock, time.Minute)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			balance, err := cache.GetBalance(ctx)
+			if err != nil || balance["availableBalance"] != 8000.0 {
+				t.Errorf("balance=%v err=%v", balance, err)
+			}
+			balance["availableBalance"] = 0.0 // 修改返回值不影响缓存
+		}()
+	}
+	wg.Wait()
+	if n := mock.balanceCalls.Load(); n != 1 {
+		t.Errorf("并发查询应只请求交易所一次，得到 %d", n)
+	}
+
+	if _, err := cache.GetPositions(ctx); err != nil {
+		t.Fatal(err)
+	}
+	cache.GetPositions(ctx)
+	if n := mock.positionsCalls.Load(); n != 1 {
+		t.Errorf("空持仓也应缓存，请求次数 %d", n)
+	}
+
+	if _, err := cache.OpenLong(ctx, "BTCUSDT", 1, 5); err != nil {
+		t.Fatal(err)
+	}
+	balance, _ := cache.GetBalance(ctx)
+	cache.GetPositions(ctx)
+	if mock.balanceCalls.Load() != 2 || mock.positionsCalls.Load() != 2 {
+		t.Errorf("下单后缓存应失效: balance=%d positions=%d", mock.balanceCalls.Load(), mock.positionsCalls.Load())
+	}
+	if balance["availableBalance"] != 8000.0 {
+		t.Errorf("balance=%v", balance)
+	}
+}
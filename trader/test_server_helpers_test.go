@@ -1,10 +1,14 @@
 package trader
 
 import (
+	"encoding/json"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+
+	"github.com/adshao/go-binance/v2/futures"
 )
 
 // newTestHTTPServer tries to start a loopback HTTP server. In sandboxed
@@ -28,3 +32,35 @@ func newTestHTTPServer(tb testing.TB, handler http.Handler) *httptest.Server {
 	server.Start()
 	return server
 }
+
+// newFakeBinanceFuturesTrader 返回连接到模拟币安合约接口的交易器：服务器时间、BTCUSDT 盘口（买一 50000.00、卖一 ask）
+// 和精度（stepSize 0.001、tickSize 0.01）由模拟服务器返回，其余请求（下单、撤单、查询订单等）交给 handler
+func newFakeBinanceFuturesTrader(t *testing.T, ask, orderStrategy string, limitTimeoutSeconds int, handler http.HandlerFunc) *FuturesTrader {
+	t.Helper()
+	server := newTestHTTPServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/fapi/v1/time":
+			json.NewEncoder(w).Encode(map[string]interface{}{"serverTime": time.Now().UnixMilli()})
+		case "/fapi/v1/ticker/bookTicker":
+			json.NewEncoder(w).Encode([]map[string]interface{}{{"symbol": "BTCUSDT", "bidPrice": "50000.00", "askPrice": ask}})
+		case "/fapi/v1/exchangeInfo":
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"symbols": []map[string]interface{}{{
+					"symbol": "BTCUSDT",
+					"filters": []map[string]interface{}{
+						{"filterType": "LOT_SIZE", "stepSize": "0.001"},
+						{"filterType": "PRICE_FILTER", "tickSize": "0.01"},
+					},
+				}},
+			})
+		default:
+			handler(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := futures.NewClient("test_key", "test_secret")
+	client.BaseURL = server.URL
+	client.HTTPClient = server.Client()
+	return newFuturesTraderWithClient(client, orderStrategy, 0, limitTimeoutSeconds)
+}
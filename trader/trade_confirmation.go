@@ -0,0 +1,108 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"nofx/market"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultConfirmMaxDeviationPct 默认确认期间允许的最大价格偏离（百分比）
+const defaultConfirmMaxDeviationPct = 0.5
+
+// confirmSleep 确认等待函数（随交易员停止而中断；测试中可替换，避免真实等待）
+var confirmSleep = sleepContext
+
+// TradeConfirmationFromEnv 读取 NOFX_CONFIRM_DELAY_SECONDS（开仓确认等待秒数，未设置=关闭）
+// 和 NOFX_CONFIRM_MAX_DEVIATION_PCT（确认期间允许的最大价格偏离百分比，未设置=默认 0.5）
+func TradeConfirmationFromEnv() (int, float64, error) {
+	delay, maxDeviationPct := 0, 0.0
+	if raw := strings.TrimSpace(os.Getenv("NOFX_CONFIRM_DELAY_SECONDS")); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v < 0 || v > 300 {
+			return 0, 0, fmt.Errorf("NOFX_CONFIRM_DELAY_SECONDS 必须是 [0, 300] 之间的整数: %q", raw)
+		}
+		delay = v
+	}
+	if raw := strings.TrimSpace(os.Getenv("NOFX_CONFIRM_MAX_DEVIATION_PCT")); raw != "" {
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0.01 || v > 10 {
+			return 0, 0, fmt.Errorf("NOFX_CONFIRM_MAX_DEVIATION_PCT 必须在 [0.01, 10] 之间: %q", raw)
+		}
+		maxDeviationPct = v
+	}
+	return delay, maxDeviationPct, nil
+}
+
+// confirmEntrySignal 延迟确认开仓信号
+// 等待 ConfirmDelaySeconds 秒后重新获取行情，验证信号是否仍然成立
+// 返回确认后的最新价格（用于重新计算开仓数量）
+func (at *AutoTrader) confirmEntrySignal(d *decision.Decision, signalPrice float64) (float64, error) {
+	delay, maxDeviationPct := at.getConfirmParams()
+	if delay <= 0 {
+		return signalPrice, nil
+	}
+
+	log.Info("  ⏳ 开仓信号确认中，等待后重新验证...", "symbol", d.Symbol, "delay_sec", delay)
+	if err := confirmSleep(at.ctx(), time.Duration(delay)*time.Second); err != nil {
+		return 0, fmt.Errorf("开仓确认中止：交易员已停止: %w", err)
+	}
+
+	freshData, err := market.Get(d.Symbol, at.timeframes)
+	if err != nil {
+		return 0, fmt.Errorf("开仓确认失败：获取最新行情失败: %w", err)
+	}
+
+	if err := validateEntryConfirmation(d.Action, signalPrice, freshData.CurrentPrice, d.StopLoss, d.TakeProfit, confirmMaxDeviationPct(maxDeviationPct)); err != nil {
+		return 0, err
+	}
+
+	log.Info("  ✓ 开仓信号确认通过", "symbol", d.Symbol, "signal_price", signalPrice, "current_price", freshData.CurrentPrice)
+	return freshData.CurrentPrice, nil
+}
+
+// confirmMaxDeviationPct 确认期间允许的最大价格偏离（未配置时使用默认值）
+func confirmMaxDeviationPct(configured float64) float64 {
+	if configured > 0 {
+		return configured
+	}
+	return defaultConfirmMaxDeviationPct
+}
+
+// validateEntryConfirmation 校验延迟后的最新价格是否仍支持原开仓信号
+// 1. 价格偏离不能超过 maxDeviationPct（过滤瞬时尖刺）
+// 2. 止损/止盈仍需位于最新价格的正确一侧
+func validateEntryConfirmation(action string, signalPrice, freshPrice, stopLoss, takeProfit, maxDeviationPct float64) error {
+	if signalPrice <= 0 || freshPrice <= 0 {
+		return fmt.Errorf("开仓确认失败：价格无效 (信号价 %.4f, 最新价 %.4f)", signalPrice, freshPrice)
+	}
+
+	deviationPct := math.Abs(freshPrice-signalPrice) / signalPrice * 100
+	if deviationPct > maxDeviationPct {
+		return fmt.Errorf("开仓确认失败：确认期间价格偏离 %.2f%% 超过阈值 %.2f%% (信号价 %.4f → 最新价 %.4f)，疑似单根尖刺信号",
+			deviationPct, maxDeviationPct, signalPrice, freshPrice)
+	}
+
+	switch action {
+	case "open_long":
+		if stopLoss > 0 && freshPrice <= stopLoss {
+			return fmt.Errorf("开仓确认失败：最新价 %.4f 已跌破止损价 %.4f", freshPrice, stopLoss)
+		}
+		if takeProfit > 0 && freshPrice >= takeProfit {
+			return fmt.Errorf("开仓确认失败：最新价 %.4f 已达到止盈价 %.4f", freshPrice, takeProfit)
+		}
+	case "open_short":
+		if stopLoss > 0 && freshPrice >= stopLoss {
+			return fmt.Errorf("开仓确认失败：最新价 %.4f 已涨破止损价 %.4f", freshPrice, stopLoss)
+		}
+		if takeProfit > 0 && freshPrice <= takeProfit {
+			return fmt.Errorf("开仓确认失败：最新价 %.4f 已达到止盈价 %.4f", freshPrice, takeProfit)
+		}
+	}
+
+	return nil
+}
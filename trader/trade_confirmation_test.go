@@ -0,0 +1,149 @@
+package trader
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"nofx/decision"
+)
+
+// TestValidateEntryConfirmation 测试延迟确认的价格验证逻辑
+func TestValidateEntryConfirmation(t *testing.T) {
+	tests := []struct {
+		name        string
+		action      string
+		signalPrice float64
+		freshPrice  float64
+		stopLoss    float64
+		takeProfit  float64
+		expectedErr string
+	}{
+		{
+			name:        "多单_价格稳定_通过",
+			action:      "open_long",
+			signalPrice: 50000,
+			freshPrice:  50100,
+			stopLoss:    48000,
+			takeProfit:  52000,
+		},
+		{
+			name:        "多单_尖刺回落_拒绝",
+			action:      "open_long",
+			signalPrice: 50000,
+			freshPrice:  49000,
+			stopLoss:    48000,
+			takeProfit:  52000,
+			expectedErr: "价格偏离",
+		},
+		{
+			name:        "多单_已跌破止损_拒绝",
+			action:      "open_long",
+			signalPrice: 50000,
+			freshPrice:  49990,
+			stopLoss:    49995,
+			takeProfit:  52000,
+			expectedErr: "止损价",
+		},
+		{
+			name:        "空单_价格稳定_通过",
+			action:      "open_short",
+			signalPrice: 50000,
+			freshPrice:  49900,
+			stopLoss:    52000,
+			takeProfit:  48000,
+		},
+		{
+			name:        "空单_已达到止盈_拒绝",
+			action:      "open_short",
+			signalPrice: 50000,
+			freshPrice:  49950,
+			stopLoss:    52000,
+			takeProfit:  49960,
+			expectedErr: "止盈价",
+		},
+		{
+			name:        "无效价格_拒绝",
+			action:      "open_long",
+			signalPrice: 0,
+			freshPrice:  50000,
+			expectedErr: "价格无效",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateEntryConfirmation(tt.action, tt.signalPrice, tt.freshPrice, tt.stopLoss, tt.takeProfit, defaultConfirmMaxDeviationPct)
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("期望通过，实际错误: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("期望错误包含 %q，实际: %v", tt.expectedErr, err)
+			}
+		})
+	}
+}
+
+// TestConfirmEntrySignal_Disabled 测试未配置延迟时直接返回信号价格且不等待
+func TestConfirmEntrySignal_Disabled(t *testing.T) {
+	slept := false
+	confirmSleep = func(context.Context, time.Duration) error { slept = true; return nil }
+	defer func() { confirmSleep = sleepContext }()
+
+	at := &AutoTrader{config: AutoTraderConfig{ConfirmDelaySeconds: 0}}
+	price, err := at.confirmEntrySignal(&decision.Decision{Symbol: "BTCUSDT", Action: "open_long"}, 50000)
+	if err != nil {
+		t.Fatalf("未启用确认时不应返回错误: %v", err)
+	}
+	if price != 50000 {
+		t.Errorf("期望返回信号价格 50000，实际 %.2f", price)
+	}
+	if slept {
+		t.Error("未启用确认时不应等待")
+	}
+}
+
+// TestConfirmEntrySignal_Cancelled 测试交易员停止时立即中止确认等待并放弃开仓
+func TestConfirmEntrySignal_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	at := &AutoTrader{config: AutoTraderConfig{ConfirmDelaySeconds: 300}, runCtx: ctx, isRunning: true}
+
+	start := time.Now()
+	if _, err := at.confirmEntrySignal(&decision.Decision{Symbol: "BTCUSDT", Action: "open_long"}, 50000); err == nil {
+		t.Fatal("交易员停止后应中止开仓")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("停止后不应继续等待，实际等待 %v", elapsed)
+	}
+}
+
+func TestTradeConfirmationFromEnv(t *testing.T) {
+	if delay, dev, err := TradeConfirmationFromEnv(); err != nil || delay != 0 || dev != 0 {
+		t.Fatalf("未配置时应关闭: %v %v %v", delay, dev, err)
+	}
+
+	t.Setenv("NOFX_CONFIRM_DELAY_SECONDS", "15")
+	t.Setenv("NOFX_CONFIRM_MAX_DEVIATION_PCT", "0.8")
+	if delay, dev, err := TradeConfirmationFromEnv(); err != nil || delay != 15 || dev != 0.8 {
+		t.Errorf("配置解析错误: %v %v %v", delay, dev, err)
+	}
+
+	for _, bad := range []struct{ key, value string }{
+		{"NOFX_CONFIRM_DELAY_SECONDS", "1.5"},
+		{"NOFX_CONFIRM_DELAY_SECONDS", "600"},
+		{"NOFX_CONFIRM_MAX_DEVIATION_PCT", "0"},
+		{"NOFX_CONFIRM_MAX_DEVIATION_PCT", "abc"},
+	} {
+		t.Run(bad.key+"="+bad.value, func(t *testing.T) {
+			t.Setenv(bad.key, bad.value)
+			if _, _, err := TradeConfirmationFromEnv(); err == nil {
+				t.Errorf("%s=%q 应返回错误", bad.key, bad.value)
+			}
+		})
+	}
+}
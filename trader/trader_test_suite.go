@@ -1,6 +1,7 @@
 package trader
 
 import (
+	"context"
 	"testing"
 
 	"github.com/agiledragon/gomonkey/v2"
@@ -86,7 +87,7 @@ func (s *TraderTestSuite) TestGetBalance() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.GetBalance()
+			result, err := s.Trader.GetBalance(context.Background())
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -123,7 +124,7 @@ func (s *TraderTestSuite) TestGetPositions() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.GetPositions()
+			result, err := s.Trader.GetPositions(context.Background())
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -162,7 +163,7 @@ func (s *TraderTestSuite) TestGetMarketPrice() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			price, err := s.Trader.GetMarketPrice(tt.symbol)
+			price, err := s.Trader.GetMarketPrice(context.Background(), tt.symbol)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -199,7 +200,7 @@ func (s *TraderTestSuite) TestSetLeverage() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.SetLeverage(tt.symbol, tt.leverage)
+			err := s.Trader.SetLeverage(context.Background(), tt.symbol, tt.leverage)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -233,7 +234,7 @@ func (s *TraderTestSuite) TestSetMarginMode() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.SetMarginMode(tt.symbol, tt.isCrossMargin)
+			err := s.Trader.SetMarginMode(context.Background(), tt.symbol, tt.isCrossMargin)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -303,7 +304,7 @@ func (s *TraderTestSuite) TestCancelAllOrders() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.CancelAllOrders(tt.symbol)
+			err := s.Trader.CancelAllOrders(context.Background(), tt.symbol)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -325,7 +326,7 @@ func (s *TraderTestSuite) TestOpenLong() {
 		quantity  float64
 		leverage  int
 		wantError bool
-		validate  func(*testing.T, map[string]interface{})
+		validate  func(*testing.T, *ExecutionReport)
 	}{
 		{
 			name:      "成功开多仓",
@@ -333,10 +334,9 @@ func (s *TraderTestSuite) TestOpenLong() {
 			quantity:  0.01,
 			leverage:  10,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
-				assert.Contains(t, result, "symbol")
-				assert.Equal(t, "BTCUSDT", result["symbol"])
+				assert.Equal(t, "BTCUSDT", result.Symbol)
 			},
 		},
 		{
@@ -345,7 +345,7 @@ func (s *TraderTestSuite) TestOpenLong() {
 			quantity:  0.004, // 增加到 0.004 以满足 Binance Futures 的 10 USDT 最小订单金额要求 (0.004 * 3000 = 12 USDT)
 			leverage:  5,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
 			},
 		},
@@ -353,7 +353,7 @@ func (s *TraderTestSuite) TestOpenLong() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.OpenLong(tt.symbol, tt.quantity, tt.leverage)
+			result, err := s.Trader.OpenLong(context.Background(), tt.symbol, tt.quantity, tt.leverage)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -374,7 +374,7 @@ func (s *TraderTestSuite) TestOpenShort() {
 		quantity  float64
 		leverage  int
 		wantError bool
-		validate  func(*testing.T, map[string]interface{})
+		validate  func(*testing.T, *ExecutionReport)
 	}{
 		{
 			name:      "成功开空仓",
@@ -382,10 +382,9 @@ func (s *TraderTestSuite) TestOpenShort() {
 			quantity:  0.01,
 			leverage:  10,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
-				assert.Contains(t, result, "symbol")
-				assert.Equal(t, "BTCUSDT", result["symbol"])
+				assert.Equal(t, "BTCUSDT", result.Symbol)
 			},
 		},
 		{
@@ -394,7 +393,7 @@ func (s *TraderTestSuite) TestOpenShort() {
 			quantity:  0.004, // 增加到 0.004 以满足 Binance Futures 的 10 USDT 最小订单金额要求 (0.004 * 3000 = 12 USDT)
 			leverage:  5,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
 			},
 		},
@@ -402,7 +401,7 @@ func (s *TraderTestSuite) TestOpenShort() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.OpenShort(tt.symbol, tt.quantity, tt.leverage)
+			result, err := s.Trader.OpenShort(context.Background(), tt.symbol, tt.quantity, tt.leverage)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -422,16 +421,16 @@ func (s *TraderTestSuite) TestCloseLong() {
 		symbol    string
 		quantity  float64
 		wantError bool
-		validate  func(*testing.T, map[string]interface{})
+		validate  func(*testing.T, *ExecutionReport)
 	}{
 		{
 			name:      "平指定数量",
 			symbol:    "BTCUSDT",
 			quantity:  0.01,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
-				assert.Contains(t, result, "symbol")
+				assert.NotEmpty(t, result.Symbol)
 			},
 		},
 		{
@@ -445,7 +444,7 @@ func (s *TraderTestSuite) TestCloseLong() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.CloseLong(tt.symbol, tt.quantity)
+			result, err := s.Trader.CloseLong(context.Background(), tt.symbol, tt.quantity)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -465,16 +464,16 @@ func (s *TraderTestSuite) TestCloseShort() {
 		symbol    string
 		quantity  float64
 		wantError bool
-		validate  func(*testing.T, map[string]interface{})
+		validate  func(*testing.T, *ExecutionReport)
 	}{
 		{
 			name:      "平指定数量",
 			symbol:    "BTCUSDT",
 			quantity:  0.01,
 			wantError: false,
-			validate: func(t *testing.T, result map[string]interface{}) {
+			validate: func(t *testing.T, result *ExecutionReport) {
 				assert.NotNil(t, result)
-				assert.Contains(t, result, "symbol")
+				assert.NotEmpty(t, result.Symbol)
 			},
 		},
 		{
@@ -488,7 +487,7 @@ func (s *TraderTestSuite) TestCloseShort() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			result, err := s.Trader.CloseShort(tt.symbol, tt.quantity)
+			result, err := s.Trader.CloseShort(context.Background(), tt.symbol, tt.quantity)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -535,7 +534,7 @@ func (s *TraderTestSuite) TestSetStopLoss() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.SetStopLoss(tt.symbol, tt.positionSide, tt.quantity, tt.stopPrice)
+			err := s.Trader.SetStopLoss(context.Background(), tt.symbol, tt.positionSide, tt.quantity, tt.stopPrice)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -575,7 +574,7 @@ func (s *TraderTestSuite) TestSetTakeProfit() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.SetTakeProfit(tt.symbol, tt.positionSide, tt.quantity, tt.takeProfitPrice)
+			err := s.Trader.SetTakeProfit(context.Background(), tt.symbol, tt.positionSide, tt.quantity, tt.takeProfitPrice)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -601,7 +600,7 @@ func (s *TraderTestSuite) TestCancelStopOrders() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.CancelStopOrders(tt.symbol)
+			err := s.Trader.CancelStopOrders(context.Background(), tt.symbol)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -627,7 +626,7 @@ func (s *TraderTestSuite) TestCancelStopLossOrders() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.CancelStopLossOrders(tt.symbol)
+			err := s.Trader.CancelStopLossOrders(context.Background(), tt.symbol)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
@@ -653,7 +652,7 @@ func (s *TraderTestSuite) TestCancelTakeProfitOrders() {
 
 	for _, tt := range tests {
 		s.T.Run(tt.name, func(t *testing.T) {
-			err := s.Trader.CancelTakeProfitOrders(tt.symbol)
+			err := s.Trader.CancelTakeProfitOrders(context.Background(), tt.symbol)
 			if tt.wantError {
 				assert.Error(t, err)
 			} else {
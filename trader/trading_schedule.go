@@ -0,0 +1,567 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"nofx/decision"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 进入事件禁开仓窗口时对已有持仓的处理
+const (
+	BlackoutActionNone    = "none"    // 只禁止开新仓（默认）
+	BlackoutActionTighten = "tighten" // 把止损收紧到距标记价格 TightenPct
+	BlackoutActionFlatten = "flatten" // 撤销挂单并平掉全部持仓
+)
+
+// 事件窗口内的开仓处理
+const (
+	BlackoutEntryBlock  = "block"  // 不开新仓（默认）
+	BlackoutEntryReduce = "reduce" // 按 SizePct 缩小开仓金额
+)
+
+// 事件禁开仓窗口默认参数
+const (
+	defaultBlackoutWindow     = 30 * time.Minute
+	defaultBlackoutTightenPct = 0.5
+	defaultBlackoutSizePct    = 50.0
+	defaultBlackoutNotice     = time.Hour
+)
+
+// TradingSession 允许开新仓的交易时段（按 TradingSchedule.Location 的星期和时刻）
+type TradingSession struct {
+	Days  [7]bool // 按 time.Weekday 索引
+	Start int     // 开始时刻（当天第几分钟）
+	End   int     // 结束时刻（不含；<=Start 表示跨越午夜到次日）
+}
+
+// contains 判断 local 是否落在时段内（local 已转换到配置时区）
+func (s TradingSession) contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+	if s.End > s.Start {
+		return s.Days[day] && minute >= s.Start && minute < s.End
+	}
+	// 跨午夜：当天开始之后，或前一天开始、今天结束之前
+	return (s.Days[day] && minute >= s.Start) || (s.Days[(day+6)%7] && minute < s.End)
+}
+
+// BlackoutEvent 经济日历事件（如 CPI、FOMC），事件前后一段时间内不开新仓
+type BlackoutEvent struct {
+	Name     string
+	Time     time.Time
+	Before   time.Duration // 事件前的禁开仓时长（0=TradingSchedule.Window）
+	After    time.Duration // 事件后的禁开仓时长（0=TradingSchedule.Window）
+	Impact   string        // low / medium / high（空=未标注，视为高影响）
+	Currency string        // 相关货币或国家（如 USD，可为空）
+}
+
+// key 事件唯一标识（保证每个事件只执行一次持仓处理）
+func (e BlackoutEvent) key() string {
+	return e.Name + "@" + e.Time.UTC().Format(time.RFC3339)
+}
+
+// TradingSchedule 交易时段和事件禁开仓窗口
+type TradingSchedule struct {
+	Sessions   []TradingSession // 允许开仓的时段（空=全天候）
+	Location   *time.Location   // 时段所在时区（nil=UTC）
+	Events     []BlackoutEvent  // 事件日历（按时间排序）
+	Feed       *CalendarFeed    // 可选：定时拉取的经济日历，与 Events 合并
+	Window     time.Duration    // 事件前后默认的禁开仓时长
+	Action     string           // 进入禁开仓窗口时对已有持仓的处理（BlackoutAction*）
+	TightenPct float64          // tighten 时止损距标记价格的百分比
+	Entry      string           // 窗口内的开仓处理（BlackoutEntry*，空=block）
+	SizePct    float64          // reduce 时保留的开仓金额百分比
+	Notice     time.Duration    // 窗口开始前多久发送事件提醒（0=不提醒）
+}
+
+// events 本地日历与经济日历数据源的全部事件（按时间排序）
+func (s *TradingSchedule) events() []BlackoutEvent {
+	if s.Feed == nil {
+		return s.Events
+	}
+	feed := s.Feed.Events()
+	if len(feed) == 0 {
+		return s.Events
+	}
+	all := make([]BlackoutEvent, 0, len(s.Events)+len(feed))
+	all = append(append(all, s.Events...), feed...)
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Time.Before(all[j].Time) })
+	return all
+}
+
+// InSession 当前是否在允许开仓的交易时段内
+func (s *TradingSchedule) InSession(now time.Time) bool {
+	if len(s.Sessions) == 0 {
+		return true
+	}
+	location := s.Location
+	if location == nil {
+		location = time.UTC
+	}
+	local := now.In(location)
+	for _, session := range s.Sessions {
+		if session.contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// ActiveBlackout 返回 now 所在的事件禁开仓窗口（窗口开始、结束时刻）
+func (s *TradingSchedule) ActiveBlackout(now time.Time) (event BlackoutEvent, start, end time.Time, ok bool) {
+	for _, e := range s.events() {
+		start, end = e.Time.Add(-s.before(e)), e.Time.Add(s.after(e))
+		if !now.Before(start) && now.Before(end) {
+			return e, start, end, true
+		}
+	}
+	return BlackoutEvent{}, time.Time{}, time.Time{}, false
+}
+
+func (s *TradingSchedule) before(e BlackoutEvent) time.Duration {
+	if e.Before > 0 {
+		return e.Before
+	}
+	return s.Window
+}
+
+func (s *TradingSchedule) after(e BlackoutEvent) time.Duration {
+	if e.After > 0 {
+		return e.After
+	}
+	return s.Window
+}
+
+// CheckEntry 开新仓前检查：不在交易时段内或处于事件禁开仓窗口（Entry=block）时返回错误
+func (s *TradingSchedule) CheckEntry(now time.Time) error {
+	if event, _, end, ok := s.ActiveBlackout(now); ok && s.Entry != BlackoutEntryReduce {
+		return fmt.Errorf("❌ 处于 %s（%s）前后的禁开仓窗口，%s 之前不开新仓",
+			event.Name, event.Time.UTC().Format("2006-01-02 15:04 MST"), end.UTC().Format("15:04 MST"))
+	}
+	if !s.InSession(now) {
+		return fmt.Errorf("❌ 当前不在交易时段内，不开新仓")
+	}
+	return nil
+}
+
+// EntrySizeFactor 事件窗口内开仓金额的缩放比例（Entry=reduce 且处于窗口内时 <1，否则为 1）
+func (s *TradingSchedule) EntrySizeFactor(now time.Time) (float64, BlackoutEvent) {
+	if s.Entry != BlackoutEntryReduce {
+		return 1, BlackoutEvent{}
+	}
+	event, _, _, ok := s.ActiveBlackout(now)
+	if !ok {
+		return 1, BlackoutEvent{}
+	}
+	pct := s.SizePct
+	if pct <= 0 || pct > 100 {
+		pct = defaultBlackoutSizePct
+	}
+	return pct / 100, event
+}
+
+// Upcoming 将在 within 内进入禁开仓窗口（尚未开始）的事件
+func (s *TradingSchedule) Upcoming(now time.Time, within time.Duration) []BlackoutEvent {
+	var out []BlackoutEvent
+	for _, e := range s.events() {
+		start := e.Time.Add(-s.before(e))
+		if now.Before(start) && !now.Add(within).Before(start) {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// weekdayNames 时段配置中的星期名称
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseTradingSessions 解析交易时段，格式 "mon-fri 00:00-24:00,sun 20:00-24:00"
+// 星期支持单日（sat）、范围（mon-fri，可跨周如 fri-mon）和 daily；时刻范围 end<=start 表示跨越午夜
+func ParseTradingSessions(raw string) ([]TradingSession, error) {
+	var sessions []TradingSession
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		days, hours, ok := strings.Cut(item, " ")
+		if !ok {
+			return nil, fmt.Errorf("交易时段格式错误: %q（应为 'mon-fri 08:00-22:00'）", item)
+		}
+		var session TradingSession
+		if err := parseSessionDays(strings.ToLower(strings.TrimSpace(days)), &session.Days); err != nil {
+			return nil, fmt.Errorf("交易时段 %q: %w", item, err)
+		}
+		startRaw, endRaw, ok := strings.Cut(strings.TrimSpace(hours), "-")
+		if !ok {
+			return nil, fmt.Errorf("交易时段 %q: 时刻应为 HH:MM-HH:MM", item)
+		}
+		var err error
+		if session.Start, err = parseSessionMinute(startRaw); err != nil {
+			return nil, fmt.Errorf("交易时段 %q: %w", item, err)
+		}
+		if session.End, err = parseSessionMinute(endRaw); err != nil {
+			return nil, fmt.Errorf("交易时段 %q: %w", item, err)
+		}
+		sessions = append(sessions, session)
+	}
+	return sessions, nil
+}
+
+func parseSessionDays(raw string, days *[7]bool) error {
+	if raw == "daily" {
+		for i := range days {
+			days[i] = true
+		}
+		return nil
+	}
+	startName, endName, isRange := strings.Cut(raw, "-")
+	start, ok := weekdayNames[startName]
+	if !ok {
+		return fmt.Errorf("未知的星期 %q（支持 mon..sun、daily）", startName)
+	}
+	end := start
+	if isRange {
+		if end, ok = weekdayNames[endName]; !ok {
+			return fmt.Errorf("未知的星期 %q（支持 mon..sun、daily）", endName)
+		}
+	}
+	for day := start; ; day = (day + 1) % 7 {
+		days[day] = true
+		if day == end {
+			return nil
+		}
+	}
+}
+
+// parseSessionMinute 解析 HH:MM（允许 24:00 表示当天结束）
+func parseSessionMinute(raw string) (int, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "24:00" {
+		return 24 * 60, nil
+	}
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		return 0, fmt.Errorf("时刻格式错误 %q（应为 HH:MM）", raw)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// blackoutEventFile 事件日历文件中的一项（before/after 为时长字符串，如 "30m"；title/country/date 为 ForexFactory 字段）
+type blackoutEventFile struct {
+	Name     string    `json:"name"`
+	Time     time.Time `json:"time"`
+	Before   string    `json:"before"`
+	After    string    `json:"after"`
+	Impact   string    `json:"impact"`
+	Currency string    `json:"currency"`
+	Title    string    `json:"title"`
+	Country  string    `json:"country"`
+	Date     time.Time `json:"date"`
+}
+
+// LoadBlackoutCalendar 读取事件日历（JSON 数组，如 [{"name":"CPI","time":"2026-11-12T13:30:00Z","after":"60m"}]，或 ICS 文件）
+func LoadBlackoutCalendar(path string) ([]BlackoutEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取事件日历失败: %w", err)
+	}
+	return ParseCalendar(data, path)
+}
+
+// TradingScheduleFromEnv 读取 NOFX_TRADING_SESSIONS、NOFX_TRADING_TIMEZONE、NOFX_BLACKOUT_CALENDAR、
+// NOFX_BLACKOUT_FEED_URL、NOFX_BLACKOUT_FEED_REFRESH、NOFX_BLACKOUT_MIN_IMPACT、NOFX_BLACKOUT_CURRENCIES、
+// NOFX_BLACKOUT_WINDOW、NOFX_BLACKOUT_ACTION、NOFX_BLACKOUT_TIGHTEN_PCT、NOFX_BLACKOUT_ENTRY、NOFX_BLACKOUT_SIZE_PCT、
+// NOFX_BLACKOUT_NOTICE（未设置时段、日历和日历数据源时返回 nil）
+func TradingScheduleFromEnv() (*TradingSchedule, error) {
+	sessionsRaw := strings.TrimSpace(os.Getenv("NOFX_TRADING_SESSIONS"))
+	calendarPath := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_CALENDAR"))
+	feedURL := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_FEED_URL"))
+	if sessionsRaw == "" && calendarPath == "" && feedURL == "" {
+		return nil, nil
+	}
+
+	s := &TradingSchedule{
+		Location:   time.UTC,
+		Window:     defaultBlackoutWindow,
+		Action:     BlackoutActionNone,
+		TightenPct: defaultBlackoutTightenPct,
+		Entry:      BlackoutEntryBlock,
+		SizePct:    defaultBlackoutSizePct,
+		Notice:     defaultBlackoutNotice,
+	}
+	var err error
+	if s.Sessions, err = ParseTradingSessions(sessionsRaw); err != nil {
+		return nil, fmt.Errorf("NOFX_TRADING_SESSIONS 配置错误: %w", err)
+	}
+	if tz := strings.TrimSpace(os.Getenv("NOFX_TRADING_TIMEZONE")); tz != "" {
+		if s.Location, err = time.LoadLocation(tz); err != nil {
+			return nil, fmt.Errorf("NOFX_TRADING_TIMEZONE 无效的时区 %s: %w", tz, err)
+		}
+	}
+	var filter CalendarFilter
+	if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_MIN_IMPACT")); v != "" {
+		if filter.MinImpact, err = ParseImpact(v); err != nil {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_MIN_IMPACT 配置错误: %w", err)
+		}
+	}
+	for _, c := range strings.Split(os.Getenv("NOFX_BLACKOUT_CURRENCIES"), ",") {
+		if c = strings.ToUpper(strings.TrimSpace(c)); c != "" {
+			filter.Currencies = append(filter.Currencies, c)
+		}
+	}
+	if calendarPath != "" {
+		if s.Events, err = LoadBlackoutCalendar(calendarPath); err != nil {
+			return nil, err
+		}
+		s.Events = filter.Apply(s.Events)
+	}
+	if feedURL != "" {
+		refresh := defaultCalendarRefresh
+		if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_FEED_REFRESH")); v != "" {
+			if refresh, err = time.ParseDuration(v); err != nil || refresh <= 0 {
+				return nil, fmt.Errorf("NOFX_BLACKOUT_FEED_REFRESH=%q 无效（如 6h）", v)
+			}
+		}
+		s.Feed = NewCalendarFeed(feedURL, refresh, filter)
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_WINDOW")); v != "" {
+		if s.Window, err = time.ParseDuration(v); err != nil || s.Window < 0 {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_WINDOW=%q 无效（如 30m、1h）", v)
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_ACTION"))); v != "" {
+		switch v {
+		case BlackoutActionNone, BlackoutActionTighten, BlackoutActionFlatten:
+			s.Action = v
+		default:
+			return nil, fmt.Errorf("NOFX_BLACKOUT_ACTION=%q 无效（支持 none、tighten、flatten）", v)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_TIGHTEN_PCT")); v != "" {
+		if s.TightenPct, err = strconv.ParseFloat(v, 64); err != nil || s.TightenPct <= 0 || s.TightenPct >= 100 {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_TIGHTEN_PCT=%q 无效（应为 0-100 之间的百分比）", v)
+		}
+	}
+	if v := strings.ToLower(strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_ENTRY"))); v != "" {
+		if v != BlackoutEntryBlock && v != BlackoutEntryReduce {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_ENTRY=%q 无效（支持 block、reduce）", v)
+		}
+		s.Entry = v
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_SIZE_PCT")); v != "" {
+		if s.SizePct, err = strconv.ParseFloat(v, 64); err != nil || s.SizePct <= 0 || s.SizePct >= 100 {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_SIZE_PCT=%q 无效（应为 0-100 之间的百分比）", v)
+		}
+	}
+	if v := strings.TrimSpace(os.Getenv("NOFX_BLACKOUT_NOTICE")); v != "" {
+		if s.Notice, err = time.ParseDuration(v); err != nil || s.Notice < 0 {
+			return nil, fmt.Errorf("NOFX_BLACKOUT_NOTICE=%q 无效（如 1h，0 表示不提醒）", v)
+		}
+	}
+	return s, nil
+}
+
+// checkTradingSchedule 开新仓前检查交易时段和事件禁开仓窗口（未配置时不限制），Entry=reduce 时缩小开仓金额
+func (at *AutoTrader) checkTradingSchedule(d *decision.Decision, now time.Time) error {
+	schedule := at.config.TradingSchedule
+	if schedule == nil {
+		return nil
+	}
+	if err := schedule.CheckEntry(now); err != nil {
+		return err
+	}
+	if factor, event := schedule.EntrySizeFactor(now); factor < 1 {
+		log.Info("  📅 处于事件窗口，调整开仓金额", "symbol", d.Symbol, "event", event.Name, "from", d.PositionSizeUSD, "to", d.PositionSizeUSD*factor)
+		d.PositionSizeUSD *= factor
+	}
+	return nil
+}
+
+// startTradingScheduleMonitor 启动事件窗口监控：定时刷新经济日历数据源，事件前发送提醒，
+// 进入禁开仓窗口时按 Action 平仓或收紧止损（每个事件只执行一次）
+func (at *AutoTrader) startTradingScheduleMonitor() {
+	schedule := at.config.TradingSchedule
+	if schedule == nil {
+		return
+	}
+	hasAction := schedule.Action != BlackoutActionNone && schedule.Action != ""
+	if schedule.Feed == nil && (len(schedule.Events) == 0 || (!hasAction && schedule.Notice <= 0)) {
+		return
+	}
+
+	at.monitorWg.Add(1)
+	go func() {
+		defer at.monitorWg.Done()
+
+		ticker := time.NewTicker(30 * time.Second)
+		defer ticker.Stop()
+
+		action := schedule.Action
+		if !hasAction {
+			action = BlackoutActionNone
+		}
+		log.Info("📅 启动事件窗口监控", "trader", at.name, "event_count", len(schedule.Events), "calendar_feed", schedule.Feed != nil, "blackout_entry", action)
+
+		handled := make(map[string]bool)
+		noticed := make(map[string]bool)
+		check := func(now time.Time) {
+			at.refreshCalendarFeed(schedule, now)
+			at.checkUpcomingEvents(schedule, now, noticed)
+			if hasAction {
+				at.checkBlackoutAction(schedule, now, handled)
+			}
+		}
+		check(time.Now())
+		for {
+			select {
+			case <-ticker.C:
+				check(time.Now())
+			case <-at.stopMonitorCh:
+				log.Info("⏹ 停止事件窗口监控")
+				return
+			}
+		}
+	}()
+}
+
+// refreshCalendarFeed 到达刷新时间时拉取经济日历（失败时保留上次的事件）
+func (at *AutoTrader) refreshCalendarFeed(schedule *TradingSchedule, now time.Time) {
+	if schedule.Feed == nil {
+		return
+	}
+	fetched, err := schedule.Feed.refreshIfDue(at.ctx(), now)
+	if err != nil {
+		log.Warn("⚠️ 刷新经济日历失败，继续使用上次的事件", "trader", at.name, "error", err)
+		return
+	}
+	if fetched {
+		log.Info("📅 经济日历已刷新", "trader", at.name, "event_count", len(schedule.Feed.Events()))
+	}
+}
+
+// checkUpcomingEvents 事件的禁开仓窗口将在 Notice 内开始时发送一次提醒
+func (at *AutoTrader) checkUpcomingEvents(schedule *TradingSchedule, now time.Time, noticed map[string]bool) {
+	if schedule.Notice <= 0 {
+		return
+	}
+	for _, e := range schedule.Upcoming(now, schedule.Notice) {
+		if noticed[e.key()] {
+			continue
+		}
+		noticed[e.key()] = true
+		entry := "暂停开新仓"
+		if schedule.Entry == BlackoutEntryReduce {
+			entry = fmt.Sprintf("开仓金额缩小到 %.0f%%", schedule.SizePct)
+		}
+		label := e.Name
+		if e.Currency != "" {
+			label = e.Currency + " " + e.Name
+		}
+		at.notify(AlertSeverityInfo, "经济事件提醒", "%s 将于 %s 公布，%s 起%s",
+			label, e.Time.UTC().Format("2006-01-02 15:04 MST"), e.Time.Add(-schedule.before(e)).UTC().Format("15:04 MST"), entry)
+	}
+}
+
+// checkBlackoutAction 处于禁开仓窗口且该事件尚未处理时执行平仓/收紧止损（失败时下次检查重试）
+func (at *AutoTrader) checkBlackoutAction(schedule *TradingSchedule, now time.Time, handled map[string]bool) {
+	event, _, end, ok := schedule.ActiveBlackout(now)
+	if !ok || handled[event.key()] {
+		return
+	}
+
+	var err error
+	switch schedule.Action {
+	case BlackoutActionFlatten:
+		log.Info("📅 进入禁开仓窗口，平掉全部持仓", "trader", at.name, "event", event.Name, "until", end.UTC().Format("15:04 MST"))
+		err = at.flattenAll()
+	case BlackoutActionTighten:
+		log.Info("📅 进入禁开仓窗口，止损收紧", "trader", at.name, "event", event.Name, "until", end.UTC().Format("15:04 MST"), "tighten_pct", schedule.TightenPct)
+		err = at.tightenStops(schedule.TightenPct)
+	}
+	if err != nil {
+		log.Error("❌ 事件窗口处理失败", "trader", at.name, "event", event.Name, "error", err)
+		return
+	}
+	handled[event.key()] = true
+}
+
+// tightenStops 把每个持仓的止损收紧到距标记价格 pct%（已有止损更紧时保持不变）
+func (at *AutoTrader) tightenStops(pct float64) error {
+	positions, err := at.trader.GetPositions(at.ctx())
+	if err != nil {
+		return fmt.Errorf("获取持仓失败: %w", err)
+	}
+	orders, err := at.trader.GetOpenOrders(at.ctx(), "")
+	if err != nil {
+		return fmt.Errorf("获取挂单失败: %w", err)
+	}
+	currentStops := exchangeStops(orders) // symbol_SIDE -> 交易所止损价
+
+	// 按币种处理：撤销止损单会作用于该币种的全部方向，需要重新设置每个方向的止损
+	type stopUpdate struct {
+		side      string
+		quantity  float64
+		stopPrice float64
+	}
+	bySymbol := make(map[string][]stopUpdate)
+	tightened := make(map[string]bool)
+	for _, pos := range positions {
+		symbol, _ := pos["symbol"].(string)
+		side, _ := pos["side"].(string)
+		quantity := math.Abs(floatValue(pos["positionAmt"]))
+		markPrice := floatValue(pos["markPrice"])
+		if quantity == 0 || markPrice <= 0 {
+			continue
+		}
+		positionSide := strings.ToUpper(side)
+		current := currentStops[symbol+"_"+positionSide]
+		target := markPrice * (1 - pct/100)
+		if positionSide == "SHORT" {
+			target = markPrice * (1 + pct/100)
+		}
+		stop := current
+		if current == 0 || (positionSide == "LONG" && target > current) || (positionSide == "SHORT" && target < current) {
+			stop = target
+			tightened[symbol] = true
+		}
+		bySymbol[symbol] = append(bySymbol[symbol], stopUpdate{side: positionSide, quantity: quantity, stopPrice: stop})
+	}
+
+	var failed []string
+	for symbol, updates := range bySymbol {
+		if !tightened[symbol] {
+			continue
+		}
+		if err := at.trader.CancelStopLossOrders(at.ctx(), symbol); err != nil {
+			log.Warn("⚠️ 收紧止损: 撤销止损单失败", "symbol", symbol, "error", err)
+		}
+		for _, u := range updates {
+			if err := at.trader.SetStopLoss(at.ctx(), symbol, u.side, u.quantity, u.stopPrice); err != nil {
+				at.setSyntheticStop(symbol, strings.ToLower(u.side), u.stopPrice)
+				failed = append(failed, symbol+"_"+u.side)
+				continue
+			}
+			log.Info("✅ 收紧止损", "symbol", symbol, "side", u.side, "stop_price", at.config.NumberFormat.Price(u.stopPrice))
+		}
+	}
+	if len(failed) > 0 {
+		at.notify(AlertSeverityCritical, "收紧止损失败",
+			"%s 收紧止损失败，已改用本地模拟止损", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// floatValue 读取持仓字段中的数值（缺失或类型不符时为 0）
+func floatValue(v interface{}) float64 {
+	f, _ := v.(float64)
+	return f
+}
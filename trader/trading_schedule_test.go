@@ -0,0 +1,195 @@
+package trader
+
+import (
+	"context"
+	"fmt"
+	"nofx/decision"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+// stopRecordingTrader 记录止损调整的 MockTrader
+type stopRecordingTrader struct {
+	flattenRecordingTrader
+	stops []string
+}
+
+func (m *stopRecordingTrader) CancelStopLossOrders(ctx context.Context, symbol string) error {
+	m.cancelled = append(m.cancelled, symbol)
+	return nil
+}
+
+func (m *stopRecordingTrader) SetStopLoss(ctx context.Context, symbol string, positionSide string, quantity, stopPrice float64) error {
+	m.stops = append(m.stops, fmt.Sprintf("%s_%s@%.1f", symbol, positionSide, stopPrice))
+	return nil
+}
+
+func TestParseTradingSessions(t *testing.T) {
+	sessions, err := ParseTradingSessions("mon-fri 00:00-24:00, sun 22:00-02:00")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	schedule := &TradingSchedule{Sessions: sessions}
+
+	tests := []struct {
+		now  time.Time
+		want bool
+	}{
+		{time.Date(2024, 7, 3, 12, 0, 0, 0, time.UTC), true},   // 周三
+		{time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC), false},  // 周六
+		{time.Date(2024, 7, 7, 21, 59, 0, 0, time.UTC), false}, // 周日 21:59
+		{time.Date(2024, 7, 7, 23, 0, 0, 0, time.UTC), true},   // 周日 23:00
+	}
+	for _, tt := range tests {
+		if got := schedule.InSession(tt.now); got != tt.want {
+			t.Errorf("InSession(%s) = %v, want %v", tt.now.Format("Mon 15:04"), got, tt.want)
+		}
+	}
+
+	// 跨周范围 fri-mon，跨午夜时段延续到周二凌晨
+	sessions, err = ParseTradingSessions("fri-mon 20:00-01:00")
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	schedule = &TradingSchedule{Sessions: sessions}
+	if !schedule.InSession(time.Date(2024, 7, 9, 0, 30, 0, 0, time.UTC)) {
+		t.Error("周一开始的跨午夜时段应延续到周二 01:00")
+	}
+	if schedule.InSession(time.Date(2024, 7, 3, 0, 30, 0, 0, time.UTC)) {
+		t.Error("周三凌晨不在时段内")
+	}
+
+	for _, raw := range []string{"weekdays 08:00-10:00", "mon 8-10", "mon 08:00-25:00", "mon"} {
+		if _, err := ParseTradingSessions(raw); err == nil {
+			t.Errorf("%q 应返回错误", raw)
+		}
+	}
+}
+
+func TestTradingScheduleTimezone(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("缺少时区数据")
+	}
+	sessions, _ := ParseTradingSessions("mon-fri 09:30-16:00")
+	schedule := &TradingSchedule{Sessions: sessions, Location: ny}
+	// 周五 21:00 UTC = 17:00 纽约（已收盘）；周五 14:00 UTC = 10:00 纽约
+	if schedule.InSession(time.Date(2024, 7, 5, 21, 0, 0, 0, time.UTC)) {
+		t.Error("纽约 17:00 不在时段内")
+	}
+	if !schedule.InSession(time.Date(2024, 7, 5, 14, 0, 0, 0, time.UTC)) {
+		t.Error("纽约 10:00 应在时段内")
+	}
+}
+
+func TestLoadBlackoutCalendar(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calendar.json")
+	data := `[
+		{"name": "FOMC", "time": "2024-07-31T18:00:00Z", "after": "90m"},
+		{"name": "CPI", "time": "2024-07-11T12:30:00Z"}
+	]`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	events, err := LoadBlackoutCalendar(path)
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if len(events) != 2 || events[0].Name != "CPI" || events[1].After != 90*time.Minute {
+		t.Fatalf("事件应按时间排序并解析时长: %+v", events)
+	}
+
+	schedule := &TradingSchedule{Events: events, Window: 30 * time.Minute}
+	cpi := events[0].Time
+	for _, tt := range []struct {
+		now     time.Time
+		blocked bool
+	}{
+		{cpi.Add(-31 * time.Minute), false},
+		{cpi.Add(-30 * time.Minute), true},
+		{cpi.Add(29 * time.Minute), true},
+		{cpi.Add(30 * time.Minute), false},
+		{events[1].Time.Add(60 * time.Minute), true}, // FOMC 之后 90 分钟
+	} {
+		if err := schedule.CheckEntry(tt.now); (err != nil) != tt.blocked {
+			t.Errorf("CheckEntry(%s) = %v, blocked want %v", tt.now.Format(time.RFC3339), err, tt.blocked)
+		}
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"name": "NFP"}]`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadBlackoutCalendar(path); err == nil {
+		t.Error("缺少 time 的事件应返回错误")
+	}
+}
+
+func TestTradingScheduleFromEnv(t *testing.T) {
+	schedule, err := TradingScheduleFromEnv()
+	if err != nil || schedule != nil {
+		t.Fatalf("未配置时应返回 nil: %v, %v", schedule, err)
+	}
+
+	t.Setenv("NOFX_TRADING_SESSIONS", "mon-fri 00:00-24:00")
+	t.Setenv("NOFX_BLACKOUT_ACTION", "tighten")
+	t.Setenv("NOFX_BLACKOUT_TIGHTEN_PCT", "0.3")
+	schedule, err = TradingScheduleFromEnv()
+	if err != nil {
+		t.Fatalf("不应返回错误: %v", err)
+	}
+	if schedule.Action != BlackoutActionTighten || schedule.TightenPct != 0.3 || schedule.Window != defaultBlackoutWindow {
+		t.Errorf("配置解析错误: %+v", schedule)
+	}
+
+	t.Setenv("NOFX_BLACKOUT_ACTION", "panic")
+	if _, err := TradingScheduleFromEnv(); err == nil {
+		t.Error("未知的 NOFX_BLACKOUT_ACTION 应返回错误")
+	}
+}
+
+func TestCheckBlackoutActionTightensStops(t *testing.T) {
+	mock := &stopRecordingTrader{flattenRecordingTrader: flattenRecordingTrader{
+		MockTrader: MockTrader{positions: []map[string]interface{}{
+			{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5, "markPrice": 60000.0},
+			{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0, "markPrice": 3000.0},
+		}},
+		openOrders: []decision.OpenOrderInfo{
+			{Symbol: "BTCUSDT", Type: "STOP_MARKET", PositionSide: "LONG", StopPrice: 55000},
+			{Symbol: "ETHUSDT", Type: "STOP_MARKET", PositionSide: "SHORT", StopPrice: 3005}, // 已比目标更紧
+		},
+	}}
+	at := &AutoTrader{trader: mock}
+	event := BlackoutEvent{Name: "CPI", Time: time.Date(2024, 7, 11, 12, 30, 0, 0, time.UTC)}
+	schedule := &TradingSchedule{Events: []BlackoutEvent{event}, Window: 30 * time.Minute, Action: BlackoutActionTighten, TightenPct: 1}
+	handled := make(map[string]bool)
+
+	at.checkBlackoutAction(schedule, event.Time.Add(-time.Hour), handled)
+	if len(mock.stops) != 0 {
+		t.Fatalf("窗口开始前不应调整止损: %v", mock.stops)
+	}
+
+	at.checkBlackoutAction(schedule, event.Time.Add(-20*time.Minute), handled)
+	at.checkBlackoutAction(schedule, event.Time.Add(-10*time.Minute), handled) // 同一事件只处理一次
+	if fmt.Sprint(mock.stops) != "[BTCUSDT_LONG@59400.0]" || fmt.Sprint(mock.cancelled) != "[BTCUSDT]" {
+		t.Errorf("只应收紧 BTC 多单止损，stops=%v cancelled=%v", mock.stops, mock.cancelled)
+	}
+}
+
+func TestCheckBlackoutActionFlattens(t *testing.T) {
+	mock := &flattenRecordingTrader{MockTrader: MockTrader{positions: []map[string]interface{}{
+		{"symbol": "BTCUSDT", "side": "long", "positionAmt": 0.5},
+		{"symbol": "ETHUSDT", "side": "short", "positionAmt": -2.0},
+	}}}
+	at := &AutoTrader{trader: mock}
+	event := BlackoutEvent{Name: "FOMC", Time: time.Date(2024, 7, 31, 18, 0, 0, 0, time.UTC)}
+	schedule := &TradingSchedule{Events: []BlackoutEvent{event}, Window: 30 * time.Minute, Action: BlackoutActionFlatten}
+
+	at.checkBlackoutAction(schedule, event.Time.Add(-5*time.Minute), map[string]bool{})
+	sort.Strings(mock.closed)
+	if fmt.Sprint(mock.closed) != "[BTCUSDT_long ETHUSDT_short]" {
+		t.Errorf("应平掉全部持仓: %v", mock.closed)
+	}
+}
@@ -47,7 +47,7 @@ interface TraderConfigData {
   taker_fee_rate: number // Taker 费率 (默认 0.0004 = 0.04%)
   maker_fee_rate: number // Maker 费率 (默认 0.0002 = 0.02%)
   timeframes: string // 时间线选择 (逗号分隔，例如: "1m,4h,1d")
-  order_strategy: string // Order strategy: "market_only", "conservative_hybrid", "limit_only"
+  order_strategy: string // Order strategy: "market_only", "conservative_hybrid", "limit_only", "maker_then_taker"
   limit_price_offset: number // Limit order price offset percentage (e.g., -0.03 for -0.03%)
   limit_timeout_seconds: number // Timeout in seconds before converting to market order
 }
@@ -816,7 +816,7 @@ export function TraderConfigModal({
                 <label className="text-sm text-[#EAECEF] block mb-3">
                   📋 订单策略
                 </label>
-                <div className="grid grid-cols-3 gap-3 mb-4">
+                <div className="grid grid-cols-4 gap-3 mb-4">
                   <button
                     type="button"
                     onClick={() =>
@@ -856,33 +856,48 @@ export function TraderConfigModal({
                   >
                     仅限价单
                   </button>
+                  <button
+                    type="button"
+                    onClick={() =>
+                      handleInputChange('order_strategy', 'maker_then_taker')
+                    }
+                    className={`px-3 py-2 rounded text-sm ${
+                      formData.order_strategy === 'maker_then_taker'
+                        ? 'bg-[#F0B90B] text-black'
+                        : 'bg-[#0B0E11] text-[#848E9C] border border-[#2B3139]'
+                    }`}
+                  >
+                    挂单优先
+                  </button>
                 </div>
 
                 {/* 限价偏移和超时设置（仅在非纯市价模式下显示） */}
                 {formData.order_strategy !== 'market_only' && (
                   <div className="grid grid-cols-2 gap-4 mt-3">
-                    <div>
-                      <label className="text-sm text-[#EAECEF] block mb-2">
-                        限价偏移 (%)
-                      </label>
-                      <input
-                        type="number"
-                        value={formData.limit_price_offset}
-                        onChange={(e) =>
-                          handleInputChange(
-                            'limit_price_offset',
-                            Number(e.target.value)
-                          )
-                        }
-                        className="w-full px-3 py-2 bg-[#0B0E11] border border-[#2B3139] rounded text-[#EAECEF] focus:border-[#F0B90B] focus:outline-none"
-                        min="-1"
-                        max="0"
-                        step="0.01"
-                      />
-                      <p className="text-xs text-gray-500 mt-1">
-                        负数表示优于市价（例如 -0.03 = 市价的 -0.03%）
-                      </p>
-                    </div>
+                    {formData.order_strategy !== 'maker_then_taker' && (
+                      <div>
+                        <label className="text-sm text-[#EAECEF] block mb-2">
+                          限价偏移 (%)
+                        </label>
+                        <input
+                          type="number"
+                          value={formData.limit_price_offset}
+                          onChange={(e) =>
+                            handleInputChange(
+                              'limit_price_offset',
+                              Number(e.target.value)
+                            )
+                          }
+                          className="w-full px-3 py-2 bg-[#0B0E11] border border-[#2B3139] rounded text-[#EAECEF] focus:border-[#F0B90B] focus:outline-none"
+                          min="-1"
+                          max="0"
+                          step="0.01"
+                        />
+                        <p className="text-xs text-gray-500 mt-1">
+                          负数表示优于市价（例如 -0.03 = 市价的 -0.03%）
+                        </p>
+                      </div>
+                    )}
                     <div>
                       <label className="text-sm text-[#EAECEF] block mb-2">
                         超时转换 (秒)
@@ -941,6 +956,17 @@ export function TraderConfigModal({
                         不会自动转为市价单。成交率取决于市场流动性和偏移设置
                       </>
                     )}
+                    {formData.order_strategy === 'maker_then_taker' && (
+                      <>
+                        <span className="text-[#F0B90B] font-medium">
+                          挂单优先：
+                        </span>
+                        在最优买/卖价挂只做 Maker 的限价单（Maker 费率{' '}
+                        {(formData.maker_fee_rate * 100).toFixed(2)}%），
+                        {formData.limit_timeout_seconds}
+                        秒内未成交的部分撤单后转为市价单，不会漏单
+                      </>
+                    )}
                   </p>
                 </div>
               </div>